@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX idx_changelog_ulid ON changelog (ulid) LOCK = NONE;
+
+-- +goose Down
+DROP INDEX idx_changelog_ulid ON changelog LOCK = NONE;
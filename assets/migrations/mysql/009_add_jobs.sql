@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE job (
+    store CHAR(26) NOT NULL,
+    ulid CHAR(26) NOT NULL,
+    kind VARCHAR(64) NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    payload LONGBLOB NOT NULL,
+    updated_at TIMESTAMP(6) NOT NULL,
+    PRIMARY KEY (store, ulid)
+);
+
+CREATE INDEX idx_job_done_updated_at ON job (done, updated_at);
+
+-- +goose Down
+DROP TABLE job;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE job_schedule (
+    store CHAR(26) NOT NULL,
+    ulid CHAR(26) NOT NULL,
+    payload LONGBLOB NOT NULL,
+    last_run_at TIMESTAMP(6) NULL,
+    last_job_id CHAR(26),
+    PRIMARY KEY (store, ulid)
+);
+
+CREATE TABLE lease (
+    name VARCHAR(64) PRIMARY KEY,
+    holder VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP(6) NOT NULL
+);
+
+-- +goose Down
+DROP TABLE lease;
+DROP TABLE job_schedule;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE materialized_relation (
+    store CHAR(26) NOT NULL,
+    object_type VARCHAR(128) NOT NULL,
+    relation VARCHAR(50) NOT NULL,
+    authorization_model_id CHAR(26) NOT NULL,
+    continuation_token VARCHAR(256) NOT NULL,
+    updated_at TIMESTAMP(6) NOT NULL,
+    PRIMARY KEY (store, object_type, relation)
+);
+
+CREATE TABLE materialized_permission (
+    store CHAR(26) NOT NULL,
+    object_type VARCHAR(128) NOT NULL,
+    relation VARCHAR(50) NOT NULL,
+    object_id VARCHAR(255) COLLATE utf8mb4_bin NOT NULL,
+    _user VARCHAR(256) NOT NULL,
+    PRIMARY KEY (store, object_type, relation, object_id, _user)
+);
+
+-- +goose Down
+DROP TABLE materialized_permission;
+DROP TABLE materialized_relation;
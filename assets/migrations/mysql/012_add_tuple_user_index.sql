@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE tuple_user_index (
+    store CHAR(26) NOT NULL,
+    _user VARCHAR(256) NOT NULL,
+    object_type VARCHAR(128) NOT NULL,
+    relation VARCHAR(50) NOT NULL,
+    object_id VARCHAR(255) COLLATE utf8mb4_bin NOT NULL,
+    user_type VARCHAR(7) NOT NULL,
+    condition_name VARCHAR(256),
+    condition_context LONGBLOB,
+    ulid CHAR(26) NOT NULL,
+    inserted_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (store, _user, object_type, relation, object_id)
+);
+
+CREATE TABLE tuple_user_index_state (
+    id SMALLINT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL
+);
+
+INSERT INTO tuple_user_index_state (id, enabled) VALUES (1, FALSE);
+
+-- +goose Down
+DROP TABLE tuple_user_index_state;
+DROP TABLE tuple_user_index;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE job_schedule (
+    store CHAR(26) NOT NULL,
+    ulid CHAR(26) NOT NULL,
+    payload BYTEA NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_job_id CHAR(26),
+    PRIMARY KEY (store, ulid)
+);
+
+CREATE TABLE lease (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE lease;
+DROP TABLE job_schedule;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE materialized_relation (
+    store TEXT NOT NULL,
+    object_type TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    authorization_model_id TEXT NOT NULL,
+    continuation_token TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (store, object_type, relation)
+);
+
+CREATE TABLE materialized_permission (
+    store TEXT NOT NULL,
+    object_type TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    object_id TEXT NOT NULL,
+    _user TEXT NOT NULL,
+    PRIMARY KEY (store, object_type, relation, object_id, _user)
+);
+
+-- +goose Down
+DROP TABLE materialized_permission;
+DROP TABLE materialized_relation;
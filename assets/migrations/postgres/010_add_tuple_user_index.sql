@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE tuple_user_index (
+    store TEXT NOT NULL,
+    _user TEXT NOT NULL,
+    object_type TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    object_id TEXT COLLATE "C" NOT NULL,
+    user_type TEXT NOT NULL,
+    condition_name TEXT,
+    condition_context BYTEA,
+    ulid TEXT NOT NULL,
+    inserted_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (store, _user, object_type, relation, object_id)
+);
+
+CREATE TABLE tuple_user_index_state (
+    id SMALLINT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL
+);
+
+INSERT INTO tuple_user_index_state (id, enabled) VALUES (1, FALSE);
+
+-- +goose Down
+DROP TABLE tuple_user_index_state;
+DROP TABLE tuple_user_index;
@@ -0,0 +1,92 @@
+// Package assertioncoverage contains the command that reports the coverage of an authorization
+// model by its assertions.
+package assertioncoverage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/server/assertioncoverage"
+)
+
+const (
+	grpcAddrFlag             = "grpc-addr"
+	grpcTLSFlag              = "grpc-tls"
+	apiTokenFlag             = "api-token"
+	storeIDFlag              = "store-id"
+	authorizationModelIDFlag = "authorization-model-id"
+	minCoverageFlag          = "min-coverage"
+)
+
+// NewAssertionCoverageCommand returns the command that reports the coverage of a model by its
+// assertions, from a server started with the `--assertion-coverage-enabled` flag of `openfga run`.
+func NewAssertionCoverageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assertion-coverage",
+		Short: "Report the relations of an authorization model exercised by its assertions. NOTE: this command is in beta and may be removed in future releases.",
+		Long: "Report which relations of an authorization model, and which branches of their rewrites, are exercised by the assertions of the model,\n" +
+			"as evaluated by a server started with --assertion-coverage-enabled against the tuples of the store.\n" +
+			"The report is written to stdout as JSON, and the command fails if any assertion fails or if the coverage is below --min-coverage,\n" +
+			"so that it can gate the changes of a model.\n" +
+			"NOTE: this command is in beta and may be removed in future releases.",
+		Args: cobra.NoArgs,
+		RunE: runAssertionCoverage,
+	}
+
+	flags := cmd.Flags()
+	flags.String(grpcAddrFlag, "localhost:8081", "the address of the gRPC API of the server")
+	flags.Bool(grpcTLSFlag, false, "connect to the server using TLS")
+	flags.String(apiTokenFlag, "", "the preshared key or OIDC token to authenticate to the server with")
+	flags.String(storeIDFlag, "", "the store of the authorization model")
+	flags.String(authorizationModelIDFlag, "", "the authorization model to report the coverage of, instead of the latest one")
+	flags.Float64(minCoverageFlag, 0, "the minimum ratio, between 0 and 1, of the rewrite branches that the assertions must exercise")
+	_ = cmd.MarkFlagRequired(storeIDFlag)
+
+	return cmd
+}
+
+func runAssertionCoverage(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	grpcAddr, _ := flags.GetString(grpcAddrFlag)
+	grpcTLS, _ := flags.GetBool(grpcTLSFlag)
+	apiToken, _ := flags.GetString(apiTokenFlag)
+	storeID, _ := flags.GetString(storeIDFlag)
+	modelID, _ := flags.GetString(authorizationModelIDFlag)
+	minCoverage, _ := flags.GetFloat64(minCoverageFlag)
+
+	if minCoverage < 0 || minCoverage > 1 {
+		return fmt.Errorf("--%s must be between 0 and 1", minCoverageFlag)
+	}
+
+	conn, err := util.DialServer(grpcAddr, grpcTLS, apiToken)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	report, err := assertioncoverage.GetReport(cmd.Context(), conn, &assertioncoverage.GetReportRequest{
+		StoreID:              storeID,
+		AuthorizationModelID: modelID,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if len(report.FailedAssertions) > 0 {
+		return fmt.Errorf("%d of %d assertions failed", len(report.FailedAssertions), report.Assertions)
+	}
+	if report.Coverage < minCoverage {
+		return fmt.Errorf("the assertions exercise %d of %d branches (%.2f), below the minimum coverage of %.2f",
+			report.ExercisedBranches, report.Branches, report.Coverage, minCoverage)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package generate contains the command that generates synthetic tuples for an authorization
+// model.
+package generate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/pkg/testfixtures/tuplegen"
+)
+
+const (
+	modelFlag          = "model"
+	tuplesOutFlag      = "tuples-out"
+	seedFlag           = "seed"
+	objectsPerTypeFlag = "objects-per-type"
+	usersPerTypeFlag   = "users-per-type"
+	fanOutFlag         = "fan-out"
+	zipfExponentFlag   = "zipf-exponent"
+	nestingDepthFlag   = "nesting-depth"
+	wildcardRatioFlag  = "wildcard-ratio"
+	maxTuplesFlag      = "max-tuples"
+)
+
+// NewGenerateCommand returns the command that generates synthetic tuples for a model.
+func NewGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate synthetic tuples for an authorization model. NOTE: this command is in beta and may be removed in future releases.",
+		Long: "Generate synthetic tuples for an authorization model, for benchmarks and demos, with tunable distributions:\n" +
+			"the number of objects of each type, the fan-out of the relations, the Zipfian popularity of the objects and the\n" +
+			"nesting depth of the usersets of the same type, e.g. of the groups in groups. The generation is deterministic for a seed.\n" +
+			"The tuples are written as newline-delimited JSON, in the format accepted by the OpenFGA tuple import tooling.\n" +
+			"NOTE: this command is in beta and may be removed in future releases.",
+		Example: "openfga generate --model model.fga --objects-per-type 10000 --fan-out 5 --tuples-out tuples.jsonl",
+		Args:    cobra.NoArgs,
+		RunE:    runGenerate,
+	}
+
+	flags := cmd.Flags()
+	flags.String(modelFlag, "", "path to the authorization model, in the modeling language or as JSON (with a .json extension)")
+	flags.String(tuplesOutFlag, "", "path to write the tuples to (defaults to stdout)")
+	flags.Uint64(seedFlag, 0, "the seed of the random generator")
+	flags.Int(objectsPerTypeFlag, tuplegen.DefaultObjectsPerType, "the number of objects of each type that has relations")
+	flags.Int(usersPerTypeFlag, tuplegen.DefaultUsersPerType, "the number of objects of each type without relations, e.g. user")
+	flags.Int(fanOutFlag, tuplegen.DefaultFanOut, "the average number of tuples of each object and directly assignable relation")
+	flags.Float64(zipfExponentFlag, tuplegen.DefaultZipfExponent, "the exponent of the Zipfian popularity of the objects (1 or less for a uniform popularity)")
+	flags.Int(nestingDepthFlag, tuplegen.DefaultNestingDepth, "the maximum depth of the chains of tuples between objects of the same type, e.g. of groups in groups")
+	flags.Float64(wildcardRatioFlag, tuplegen.DefaultWildcardRatio, "the ratio of the objects granted to the public wildcard, for the relations assignable to it")
+	flags.Int(maxTuplesFlag, 0, "the maximum number of tuples to generate (0 for no maximum)")
+	_ = cmd.MarkFlagRequired(modelFlag)
+
+	return cmd
+}
+
+func runGenerate(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	modelPath, _ := flags.GetString(modelFlag)
+	tuplesOut, _ := flags.GetString(tuplesOutFlag)
+	seed, _ := flags.GetUint64(seedFlag)
+	objectsPerType, _ := flags.GetInt(objectsPerTypeFlag)
+	usersPerType, _ := flags.GetInt(usersPerTypeFlag)
+	fanOut, _ := flags.GetInt(fanOutFlag)
+	zipfExponent, _ := flags.GetFloat64(zipfExponentFlag)
+	nestingDepth, _ := flags.GetInt(nestingDepthFlag)
+	wildcardRatio, _ := flags.GetFloat64(wildcardRatioFlag)
+	maxTuples, _ := flags.GetInt(maxTuplesFlag)
+
+	model, err := readModel(modelPath)
+	if err != nil {
+		return err
+	}
+
+	g, err := tuplegen.New(model,
+		tuplegen.WithSeed(seed),
+		tuplegen.WithObjectsPerType(objectsPerType),
+		tuplegen.WithUsersPerType(usersPerType),
+		tuplegen.WithFanOut(fanOut),
+		tuplegen.WithZipfExponent(zipfExponent),
+		tuplegen.WithNestingDepth(nestingDepth),
+		tuplegen.WithWildcardRatio(wildcardRatio),
+		tuplegen.WithMaxTuples(maxTuples),
+	)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if tuplesOut != "" {
+		f, err := os.Create(tuplesOut)
+		if err != nil {
+			return fmt.Errorf("failed to create the tuples file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	tw := importer.NewTupleWriter(out)
+	if err := g.Generate(tw.Write); err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write the tuples: %w", err)
+	}
+
+	if tuplesOut != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "wrote %d tuples to %s\n", tw.Written(), tuplesOut)
+	}
+	return nil
+}
+
+// readModel reads the model at path, as JSON if it has a .json extension, or in the modeling
+// language otherwise.
+func readModel(path string) (*openfgav1.AuthorizationModel, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the model: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		model := &openfgav1.AuthorizationModel{}
+		if err := protojson.Unmarshal(b, model); err != nil {
+			return nil, fmt.Errorf("invalid model: %w", err)
+		}
+		return model, nil
+	}
+
+	model, err := parser.TransformDSLToProto(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+	return model, nil
+}
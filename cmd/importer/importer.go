@@ -0,0 +1,31 @@
+// Package importer contains the commands to import authorization schemas and relationship data
+// from other ReBAC systems.
+package importer
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	schemaFlag        = "schema"
+	relationshipsFlag = "relationships"
+	modelOutFlag      = "model-out"
+	tuplesOutFlag     = "tuples-out"
+)
+
+// NewImportCommand returns the command that groups the importers of the supported source systems.
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Translate schemas and relationships from other authorization systems. NOTE: this command is in beta and may be removed in future releases.",
+		Long: "Translate schemas and relationships from other authorization systems into an OpenFGA authorization model and tuples.\n" +
+			"Constructs that cannot be translated faithfully are left out and reported, so the output never grants more access than the source.\n" +
+			"NOTE: this command is in beta and may be removed in future releases.",
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newSpiceDBCommand())
+	cmd.AddCommand(newZanzibarCommand())
+
+	return cmd
+}
@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/internal/importer/spicedb"
+)
+
+func newSpiceDBCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spicedb",
+		Short: "Translate a SpiceDB schema and relationships",
+		Long: "Translate a SpiceDB schema into an OpenFGA authorization model (JSON) and, optionally, SpiceDB relationships\n" +
+			"(one per line, as produced by `zed relationship read`) into OpenFGA tuples (newline-delimited JSON).",
+		Args: cobra.NoArgs,
+		RunE: runSpiceDB,
+	}
+
+	flags := cmd.Flags()
+	flags.String(schemaFlag, "", "path to the SpiceDB schema file")
+	flags.String(relationshipsFlag, "", "path to the SpiceDB relationships file")
+	flags.String(modelOutFlag, "", "path to write the translated authorization model to (defaults to stdout)")
+	flags.String(tuplesOutFlag, "", "path to write the translated tuples to (required with --relationships)")
+	_ = cmd.MarkFlagRequired(schemaFlag)
+
+	return cmd
+}
+
+func runSpiceDB(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	schemaPath, _ := flags.GetString(schemaFlag)
+	relationshipsPath, _ := flags.GetString(relationshipsFlag)
+	modelOut, _ := flags.GetString(modelOutFlag)
+	tuplesOut, _ := flags.GetString(tuplesOutFlag)
+
+	if relationshipsPath != "" && tuplesOut == "" {
+		return fmt.Errorf("--%s is required with --%s", tuplesOutFlag, relationshipsFlag)
+	}
+
+	schema, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the schema: %w", err)
+	}
+
+	model, issues, err := spicedb.TranslateSchema(string(schema))
+	if err != nil {
+		return err
+	}
+	issues = append(issues, importer.ValidateModel(context.Background(), model)...)
+
+	if err := writeModel(cmd.OutOrStdout(), modelOut, model); err != nil {
+		return err
+	}
+	reportIssues(cmd.ErrOrStderr(), schemaPath, issues)
+
+	if relationshipsPath == "" {
+		return nil
+	}
+
+	in, err := os.Open(relationshipsPath)
+	if err != nil {
+		return fmt.Errorf("failed to open the relationships: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(tuplesOut)
+	if err != nil {
+		return fmt.Errorf("failed to create the tuples file: %w", err)
+	}
+	defer out.Close()
+
+	tw := importer.NewTupleWriter(out)
+	issues, err = spicedb.TranslateRelationships(in, model, tw)
+	if flushErr := tw.Flush(); err == nil {
+		err = flushErr
+	}
+	reportIssues(cmd.ErrOrStderr(), relationshipsPath, issues)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "wrote %d tuples to %s\n", tw.Written(), tuplesOut)
+	return nil
+}
+
+// writeModel writes the model as JSON to path, or to stdout if path is empty.
+func writeModel(stdout io.Writer, path string, model *openfgav1.AuthorizationModel) error {
+	b, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the model: %w", err)
+	}
+	b = append(b, '\n')
+
+	if path == "" {
+		_, err = stdout.Write(b)
+		return err
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write the model: %w", err)
+	}
+	return nil
+}
+
+func reportIssues(w io.Writer, source string, issues []importer.Issue) {
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s: %s\n", source, issue)
+	}
+}
@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/internal/importer/zanzibar"
+)
+
+const (
+	namespacesFlag      = "namespaces"
+	directTypesFlag     = "direct-types"
+	defaultUserTypeFlag = "default-user-type"
+)
+
+func newZanzibarCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "zanzibar",
+		Short: "Translate Zanzibar-style namespace configs",
+		Long: "Translate Zanzibar-style namespace configs (userset rewrite rules in the protobuf text format) into an OpenFGA authorization model (JSON).\n" +
+			"Namespace configs do not restrict the users of a relation, so the user types of directly assignable relations are set with --direct-types.",
+		Example: "openfga import zanzibar --namespaces doc.textproto,group.textproto --direct-types doc#owner=user --direct-types doc#viewer=user,group#member",
+		Args:    cobra.NoArgs,
+		RunE:    runZanzibar,
+	}
+
+	flags := cmd.Flags()
+	flags.StringSlice(namespacesFlag, nil, "paths to the namespace config files")
+	flags.StringArray(directTypesFlag, nil, "the user types of a directly assignable relation, as 'type#relation=user_type[,user_type...]' (can be repeated)")
+	flags.String(defaultUserTypeFlag, "user", "the user type assumed for directly assignable relations without --direct-types (set to empty to leave such relations out)")
+	flags.String(modelOutFlag, "", "path to write the translated authorization model to (defaults to stdout)")
+	_ = cmd.MarkFlagRequired(namespacesFlag)
+
+	return cmd
+}
+
+func runZanzibar(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	paths, _ := flags.GetStringSlice(namespacesFlag)
+	directTypes, _ := flags.GetStringArray(directTypesFlag)
+	defaultUserType, _ := flags.GetString(defaultUserTypeFlag)
+	modelOut, _ := flags.GetString(modelOutFlag)
+
+	opts := zanzibar.Options{
+		DirectTypes:     map[string][]string{},
+		DefaultUserType: defaultUserType,
+	}
+	for _, value := range directTypes {
+		relation, userTypes, ok := strings.Cut(value, "=")
+		if !ok || relation == "" || userTypes == "" {
+			return fmt.Errorf("invalid --%s '%s': expected 'type#relation=user_type[,user_type...]'", directTypesFlag, value)
+		}
+		opts.DirectTypes[relation] = append(opts.DirectTypes[relation], strings.Split(userTypes, ",")...)
+	}
+
+	configs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		config, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the namespace config: %w", err)
+		}
+		configs = append(configs, string(config))
+	}
+
+	model, issues, err := zanzibar.TranslateNamespaces(configs, opts)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, importer.ValidateModel(context.Background(), model)...)
+
+	if err := writeModel(cmd.OutOrStdout(), modelOut, model); err != nil {
+		return err
+	}
+	reportIssues(cmd.ErrOrStderr(), strings.Join(paths, ","), issues)
+	return nil
+}
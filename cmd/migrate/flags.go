@@ -39,5 +39,17 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 
 		util.MustBindPFlag(logTimestampFlag, flags.Lookup(logTimestampFlag))
 		util.MustBindEnv(logTimestampFlag, "OPENFGA_LOG_TIMESTAMP_FORMAT")
+
+		util.MustBindPFlag(storePartitionsFlag, flags.Lookup(storePartitionsFlag))
+		util.MustBindEnv(storePartitionsFlag, "OPENFGA_POSTGRES_STORE_PARTITIONS")
+
+		util.MustBindPFlag(changelogByTimeFlag, flags.Lookup(changelogByTimeFlag))
+		util.MustBindEnv(changelogByTimeFlag, "OPENFGA_PARTITION_CHANGELOG_BY_TIME")
+
+		util.MustBindPFlag(tuneMaintenanceFlag, flags.Lookup(tuneMaintenanceFlag))
+		util.MustBindEnv(tuneMaintenanceFlag, "OPENFGA_TUNE_MAINTENANCE")
+
+		util.MustBindPFlag(reverseUserIndexFlag, flags.Lookup(reverseUserIndexFlag))
+		util.MustBindEnv(reverseUserIndexFlag, "OPENFGA_REVERSE_USER_INDEX")
 	}
 }
@@ -2,6 +2,7 @@
 package migrate
 
 import (
+	"fmt"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver.
@@ -24,6 +25,10 @@ const (
 	logFormatFlag         = "log-format"
 	logLevelFlag          = "log-level"
 	logTimestampFlag      = "log-timestamp-format"
+	storePartitionsFlag   = "postgres-store-partitions"
+	changelogByTimeFlag   = "partition-changelog-by-time"
+	tuneMaintenanceFlag   = "tune-maintenance"
+	reverseUserIndexFlag  = "reverse-user-index"
 )
 
 func NewMigrateCommand() *cobra.Command {
@@ -48,6 +53,10 @@ func NewMigrateCommand() *cobra.Command {
 	flags.String(logFormatFlag, defaultConfig.Log.Format, "the log format to output logs in")
 	flags.String(logLevelFlag, defaultConfig.Log.Level, "the log level to use")
 	flags.String(logTimestampFlag, defaultConfig.Log.TimestampFormat, "the timestamp format to use for log messages")
+	flags.Int(storePartitionsFlag, 0, "the number of hash partitions by store of the tuple and changelog tables of a postgres datastore, applied when migrating to the latest version; the tables are copied into their partitions, so the writes wait for the copy (if omitted the partitioning is left as it is)")
+	flags.Bool(changelogByTimeFlag, false, "partition the changelog of a postgres or mysql datastore by the time of its changes when migrating to the latest version, so that the changelog retention drops whole partitions instead of deleting the changes one by one; the changelog is copied into its partitions, so the writes wait for the copy")
+	flags.String(reverseUserIndexFlag, "", "'enabled' or 'disabled' to enable or disable the reverse user index of a postgres or mysql datastore when migrating to the latest version. It is enabled once the tuples are indexed in batches, and the instances refuse to start unless their datastore-reverse-user-index-enabled matches it; run it again once they are restarted to index the tuples written by the instances that were still running without the index (if omitted the index is left as it is)")
+	flags.Bool(tuneMaintenanceFlag, false, "apply the recommended autovacuum and analyze settings of a postgres datastore, or the statistics settings of a mysql datastore, to the tuple, changelog and reverse user index tables when migrating to the latest version")
 
 	// NOTE: if you add a new flag here, update the function below, too
 
@@ -67,6 +76,19 @@ func runMigration(_ *cobra.Command, _ []string) error {
 	logFormat := viper.GetString(logFormatFlag)
 	logLevel := viper.GetString(logLevelFlag)
 	logTimestamp := viper.GetString(logTimestampFlag)
+	storePartitions := viper.GetInt(storePartitionsFlag)
+	changelogByTime := viper.GetBool(changelogByTimeFlag)
+	tuneMaintenance := viper.GetBool(tuneMaintenanceFlag)
+
+	var reverseUserIndex *bool
+	switch value := viper.GetString(reverseUserIndexFlag); value {
+	case "":
+	case "enabled", "disabled":
+		enabled := value == "enabled"
+		reverseUserIndex = &enabled
+	default:
+		return fmt.Errorf("invalid value for --%s: %q, must be 'enabled' or 'disabled'", reverseUserIndexFlag, value)
+	}
 
 	log := logger.MustNewLogger(logFormat, logLevel, logTimestamp)
 
@@ -79,6 +101,11 @@ func runMigration(_ *cobra.Command, _ []string) error {
 		Username:      username,
 		Password:      password,
 		Logger:        log,
+
+		PostgresStorePartitions:  storePartitions,
+		PartitionChangelogByTime: changelogByTime,
+		TuneMaintenance:          tuneMaintenance,
+		ReverseUserIndex:         reverseUserIndex,
 	}
 	return migrate.RunMigrations(cfg)
 }
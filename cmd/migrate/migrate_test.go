@@ -81,3 +81,13 @@ func TestMigrateCommandConfigIsMerged(t *testing.T) {
 	cmd.SetArgs([]string{"migrate"})
 	require.NoError(t, cmd.Execute())
 }
+
+func TestMigrateCommandInvalidReverseUserIndex(t *testing.T) {
+	util.PrepareTempConfigDir(t)
+	migrateCmd := NewMigrateCommand()
+
+	cmd := cmd.NewRootCommand()
+	cmd.AddCommand(migrateCmd)
+	cmd.SetArgs([]string{"migrate", "--datastore-engine", "postgres", "--reverse-user-index", "true"})
+	require.EqualError(t, cmd.Execute(), `invalid value for --reverse-user-index: "true", must be 'enabled' or 'disabled'`)
+}
@@ -0,0 +1,96 @@
+// Package modelgraph contains the command that renders an authorization model as a graph.
+package modelgraph
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/modelrender"
+)
+
+const (
+	fileFlag                 = "file"
+	formatFlag               = "format"
+	grpcAddrFlag             = "grpc-addr"
+	grpcTLSFlag              = "grpc-tls"
+	apiTokenFlag             = "api-token"
+	storeIDFlag              = "store-id"
+	authorizationModelIDFlag = "authorization-model-id"
+)
+
+// NewModelGraphCommand returns the command that renders an authorization model, read from a file
+// or from the store of a server, as a DOT or Mermaid graph.
+func NewModelGraphCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "model-graph",
+		Short: "Render an authorization model as a DOT or Mermaid graph.",
+		Long: "Render an authorization model as a graph of its types, with the rewrites of their relations as edges annotated with their conditions,\n" +
+			"in the DOT language of Graphviz or as a Mermaid flowchart, for the documentation and the review of complex models.\n" +
+			"The model is read from --file, in the DSL or in JSON, or from the store --store-id of a server.\n" +
+			"The graph is written to stdout.",
+		Args: cobra.NoArgs,
+		RunE: runModelGraph,
+	}
+
+	flags := cmd.Flags()
+	flags.String(fileFlag, "", "the file of the authorization model, in the DSL or in JSON ('-' for stdin)")
+	flags.String(formatFlag, string(modelrender.FormatDOT), "the format of the graph: 'dot' or 'mermaid'")
+	flags.String(grpcAddrFlag, "localhost:8081", "the address of the gRPC API of the server")
+	flags.Bool(grpcTLSFlag, false, "connect to the server using TLS")
+	flags.String(apiTokenFlag, "", "the preshared key or OIDC token to authenticate to the server with")
+	flags.String(storeIDFlag, "", "the store of the authorization model")
+	flags.String(authorizationModelIDFlag, "", "the authorization model to render, instead of the latest one")
+	cmd.MarkFlagsOneRequired(fileFlag, storeIDFlag)
+	cmd.MarkFlagsMutuallyExclusive(fileFlag, storeIDFlag)
+
+	return cmd
+}
+
+func runModelGraph(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	file, _ := flags.GetString(fileFlag)
+	formatName, _ := flags.GetString(formatFlag)
+
+	format, err := modelrender.ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	var model *openfgav1.AuthorizationModel
+	if file != "" {
+		model, err = util.ReadModelFile(cmd, file)
+	} else {
+		model, err = readServerModel(cmd)
+	}
+	if err != nil {
+		return err
+	}
+
+	graph, err := modelrender.Render(model, format)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(cmd.OutOrStdout(), graph)
+	return err
+}
+
+func readServerModel(cmd *cobra.Command) (*openfgav1.AuthorizationModel, error) {
+	flags := cmd.Flags()
+	grpcAddr, _ := flags.GetString(grpcAddrFlag)
+	grpcTLS, _ := flags.GetBool(grpcTLSFlag)
+	apiToken, _ := flags.GetString(apiTokenFlag)
+	storeID, _ := flags.GetString(storeIDFlag)
+	modelID, _ := flags.GetString(authorizationModelIDFlag)
+
+	conn, err := util.DialServer(grpcAddr, grpcTLS, apiToken)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return modelrender.ReadModel(cmd.Context(), conn, storeID, modelID)
+}
@@ -0,0 +1,54 @@
+//go:build js && wasm
+
+// Package main exposes the validation of the authorization models of the server to JavaScript, e.g.
+// to the model editors of web frontends. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o openfga.wasm ./cmd/openfga-wasm
+//
+// and run it with the wasm_exec.js of the Go distribution. It defines the global functions
+// openfgaValidateDSL(dsl) and openfgaValidateJSON(json), which return the JSON of a
+// modelvalidation.Result.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/modelvalidation"
+)
+
+func main() {
+	js.Global().Set("openfgaValidateDSL", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		return validate(args, func(ctx context.Context, model string) (*openfgav1.AuthorizationModel, error) {
+			return modelvalidation.ValidateDSL(ctx, model)
+		})
+	}))
+	js.Global().Set("openfgaValidateJSON", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		return validate(args, func(ctx context.Context, model string) (*openfgav1.AuthorizationModel, error) {
+			return modelvalidation.ValidateJSON(ctx, []byte(model))
+		})
+	}))
+
+	// the functions are served until the page is unloaded
+	select {}
+}
+
+// validate validates the model in the first argument with validateFn and returns the JSON of its
+// result.
+func validate(args []js.Value, validateFn func(context.Context, string) (*openfgav1.AuthorizationModel, error)) any {
+	var result modelvalidation.Result
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		result = modelvalidation.Result{Errors: []string{"expected the model as a single string argument"}}
+	} else {
+		result = modelvalidation.NewResult(validateFn(context.Background(), args[0].String()))
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return `{"valid":false,"errors":["` + err.Error() + `"]}`
+	}
+	return string(data)
+}
@@ -5,8 +5,14 @@ import (
 	"os"
 
 	"github.com/openfga/openfga/cmd"
+	"github.com/openfga/openfga/cmd/assertioncoverage"
+	"github.com/openfga/openfga/cmd/generate"
+	"github.com/openfga/openfga/cmd/importer"
 	"github.com/openfga/openfga/cmd/migrate"
+	"github.com/openfga/openfga/cmd/modelgraph"
+	"github.com/openfga/openfga/cmd/replay"
 	"github.com/openfga/openfga/cmd/run"
+	"github.com/openfga/openfga/cmd/simulatemodel"
 	"github.com/openfga/openfga/cmd/validatemodels"
 )
 
@@ -22,6 +28,24 @@ func main() {
 	validateModelsCmd := validatemodels.NewValidateCommand()
 	rootCmd.AddCommand(validateModelsCmd)
 
+	importCmd := importer.NewImportCommand()
+	rootCmd.AddCommand(importCmd)
+
+	replayCmd := replay.NewReplayCommand()
+	rootCmd.AddCommand(replayCmd)
+
+	assertionCoverageCmd := assertioncoverage.NewAssertionCoverageCommand()
+	rootCmd.AddCommand(assertionCoverageCmd)
+
+	modelGraphCmd := modelgraph.NewModelGraphCommand()
+	rootCmd.AddCommand(modelGraphCmd)
+
+	simulateModelCmd := simulatemodel.NewSimulateModelCommand()
+	rootCmd.AddCommand(simulateModelCmd)
+
+	generateCmd := generate.NewGenerateCommand()
+	rootCmd.AddCommand(generateCmd)
+
 	versionCmd := cmd.NewVersionCommand()
 	rootCmd.AddCommand(versionCmd)
 
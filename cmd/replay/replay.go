@@ -0,0 +1,97 @@
+// Package replay contains the command to replay recorded Check and ListObjects requests against
+// an OpenFGA server.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/recording"
+)
+
+const (
+	recordingFlag            = "recording"
+	grpcAddrFlag             = "grpc-addr"
+	grpcTLSFlag              = "grpc-tls"
+	apiTokenFlag             = "api-token"
+	storeIDFlag              = "store-id"
+	authorizationModelIDFlag = "authorization-model-id"
+	concurrencyFlag          = "concurrency"
+)
+
+// NewReplayCommand returns the command that replays a recording made with the
+// `--request-recording-enabled` flag of `openfga run`.
+func NewReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay recorded Check and ListObjects requests against a server. NOTE: this command is in beta and may be removed in future releases.",
+		Long: "Replay the Check and ListObjects requests recorded by a server started with --request-recording-enabled against another server,\n" +
+			"or another authorization model version, and report the requests whose result changed along with the recorded and replayed latencies.\n" +
+			"The report is written to stdout as JSON, and the command fails if any result changed.\n" +
+			"Anonymized recordings can only be replayed against stores whose tuples were pseudonymized with the same key.\n" +
+			"NOTE: this command is in beta and may be removed in future releases.",
+		Args: cobra.NoArgs,
+		RunE: runReplay,
+	}
+
+	flags := cmd.Flags()
+	flags.String(recordingFlag, "", "path to the recording to replay")
+	flags.String(grpcAddrFlag, "localhost:8081", "the address of the gRPC API of the server to replay the requests against")
+	flags.Bool(grpcTLSFlag, false, "connect to the server using TLS")
+	flags.String(apiTokenFlag, "", "the preshared key or OIDC token to authenticate to the server with")
+	flags.String(storeIDFlag, "", "replay every request against this store instead of the recorded one")
+	flags.String(authorizationModelIDFlag, "", "replay every request against this authorization model instead of the recorded one")
+	flags.Int(concurrencyFlag, 1, "the number of requests replayed concurrently")
+	_ = cmd.MarkFlagRequired(recordingFlag)
+
+	return cmd
+}
+
+func runReplay(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	recordingPath, _ := flags.GetString(recordingFlag)
+	grpcAddr, _ := flags.GetString(grpcAddrFlag)
+	grpcTLS, _ := flags.GetBool(grpcTLSFlag)
+	apiToken, _ := flags.GetString(apiTokenFlag)
+	storeID, _ := flags.GetString(storeIDFlag)
+	modelID, _ := flags.GetString(authorizationModelIDFlag)
+	concurrency, _ := flags.GetInt(concurrencyFlag)
+
+	in, err := os.Open(recordingPath)
+	if err != nil {
+		return fmt.Errorf("failed to open the recording: %w", err)
+	}
+	defer in.Close()
+
+	conn, err := util.DialServer(grpcAddr, grpcTLS, apiToken)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	report, err := recording.Replay(cmd.Context(), openfgav1.NewOpenFGAServiceClient(conn), in,
+		recording.WithStoreID(storeID),
+		recording.WithAuthorizationModelID(modelID),
+		recording.WithConcurrency(concurrency),
+	)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if len(report.Mismatches) > 0 {
+		return fmt.Errorf("%d of %d replayed requests returned a different result", len(report.Mismatches), report.Requests)
+	}
+	return nil
+}
@@ -42,6 +42,39 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("grpc.maxRecvMsgBytes", flags.Lookup("grpc-max-recv-msg-bytes"))
 		util.MustBindEnv("grpc.maxRecvMsgBytes", "OPENFGA_GRPC_MAX_RECV_MSG_BYTES")
 
+		util.MustBindPFlag("grpc.maxSendMsgBytes", flags.Lookup("grpc-max-send-msg-bytes"))
+		util.MustBindEnv("grpc.maxSendMsgBytes", "OPENFGA_GRPC_MAX_SEND_MSG_BYTES")
+
+		util.MustBindPFlag("grpc.compression.enabled", flags.Lookup("grpc-compression-enabled"))
+		util.MustBindEnv("grpc.compression.enabled", "OPENFGA_GRPC_COMPRESSION_ENABLED")
+
+		util.MustBindPFlag("grpc.compression.algorithms", flags.Lookup("grpc-compression-algorithms"))
+		util.MustBindEnv("grpc.compression.algorithms", "OPENFGA_GRPC_COMPRESSION_ALGORITHMS")
+
+		util.MustBindPFlag("grpc.keepalive.time", flags.Lookup("grpc-keepalive-time"))
+		util.MustBindEnv("grpc.keepalive.time", "OPENFGA_GRPC_KEEPALIVE_TIME")
+
+		util.MustBindPFlag("grpc.keepalive.timeout", flags.Lookup("grpc-keepalive-timeout"))
+		util.MustBindEnv("grpc.keepalive.timeout", "OPENFGA_GRPC_KEEPALIVE_TIMEOUT")
+
+		util.MustBindPFlag("grpc.keepalive.minTime", flags.Lookup("grpc-keepalive-min-time"))
+		util.MustBindEnv("grpc.keepalive.minTime", "OPENFGA_GRPC_KEEPALIVE_MIN_TIME")
+
+		util.MustBindPFlag("grpc.keepalive.permitWithoutStream", flags.Lookup("grpc-keepalive-permit-without-stream"))
+		util.MustBindEnv("grpc.keepalive.permitWithoutStream", "OPENFGA_GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM")
+
+		util.MustBindPFlag("grpc.maxConnectionIdle", flags.Lookup("grpc-max-connection-idle"))
+		util.MustBindEnv("grpc.maxConnectionIdle", "OPENFGA_GRPC_MAX_CONNECTION_IDLE")
+
+		util.MustBindPFlag("grpc.maxConnectionAge", flags.Lookup("grpc-max-connection-age"))
+		util.MustBindEnv("grpc.maxConnectionAge", "OPENFGA_GRPC_MAX_CONNECTION_AGE")
+
+		util.MustBindPFlag("grpc.maxConnectionAgeGrace", flags.Lookup("grpc-max-connection-age-grace"))
+		util.MustBindEnv("grpc.maxConnectionAgeGrace", "OPENFGA_GRPC_MAX_CONNECTION_AGE_GRACE")
+
+		util.MustBindPFlag("grpc.maxConcurrentStreams", flags.Lookup("grpc-max-concurrent-streams"))
+		util.MustBindEnv("grpc.maxConcurrentStreams", "OPENFGA_GRPC_MAX_CONCURRENT_STREAMS")
+
 		util.MustBindPFlag("http.enabled", flags.Lookup("http-enabled"))
 		util.MustBindEnv("http.enabled", "OPENFGA_HTTP_ENABLED")
 
@@ -62,15 +95,327 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("http.upstreamTimeout", flags.Lookup("http-upstream-timeout"))
 		util.MustBindEnv("http.upstreamTimeout", "OPENFGA_HTTP_UPSTREAM_TIMEOUT", "OPENFGA_HTTP_UPSTREAMTIMEOUT")
 
+		util.MustBindPFlag("http.maxRequestBodyBytes", flags.Lookup("http-max-request-body-bytes"))
+		util.MustBindEnv("http.maxRequestBodyBytes", "OPENFGA_HTTP_MAX_REQUEST_BODY_BYTES")
+
 		util.MustBindPFlag("http.corsAllowedOrigins", flags.Lookup("http-cors-allowed-origins"))
 		util.MustBindEnv("http.corsAllowedOrigins", "OPENFGA_HTTP_CORS_ALLOWED_ORIGINS", "OPENFGA_HTTP_CORSALLOWEDORIGINS")
 
 		util.MustBindPFlag("http.corsAllowedHeaders", flags.Lookup("http-cors-allowed-headers"))
 		util.MustBindEnv("http.corsAllowedHeaders", "OPENFGA_HTTP_CORS_ALLOWED_HEADERS", "OPENFGA_HTTP_CORSALLOWEDHEADERS")
 
+		util.MustBindPFlag("http.corsAllowedMethods", flags.Lookup("http-cors-allowed-methods"))
+		util.MustBindEnv("http.corsAllowedMethods", "OPENFGA_HTTP_CORS_ALLOWED_METHODS")
+
+		util.MustBindPFlag("http.corsExposedHeaders", flags.Lookup("http-cors-exposed-headers"))
+		util.MustBindEnv("http.corsExposedHeaders", "OPENFGA_HTTP_CORS_EXPOSED_HEADERS")
+
+		util.MustBindPFlag("http.corsMaxAge", flags.Lookup("http-cors-max-age"))
+		util.MustBindEnv("http.corsMaxAge", "OPENFGA_HTTP_CORS_MAX_AGE")
+
+		util.MustBindPFlag("http.corsAllowCredentials", flags.Lookup("http-cors-allow-credentials"))
+		util.MustBindEnv("http.corsAllowCredentials", "OPENFGA_HTTP_CORS_ALLOW_CREDENTIALS")
+
+		util.MustBindPFlag("http.compression.enabled", flags.Lookup("http-compression-enabled"))
+		util.MustBindEnv("http.compression.enabled", "OPENFGA_HTTP_COMPRESSION_ENABLED")
+
+		util.MustBindPFlag("http.compression.algorithms", flags.Lookup("http-compression-algorithms"))
+		util.MustBindEnv("http.compression.algorithms", "OPENFGA_HTTP_COMPRESSION_ALGORITHMS")
+
+		util.MustBindPFlag("http.compression.minSizeBytes", flags.Lookup("http-compression-min-size-bytes"))
+		util.MustBindEnv("http.compression.minSizeBytes", "OPENFGA_HTTP_COMPRESSION_MIN_SIZE_BYTES")
+
+		util.MustBindPFlag("http.serverTimingEnabled", flags.Lookup("http-server-timing-enabled"))
+		util.MustBindEnv("http.serverTimingEnabled", "OPENFGA_HTTP_SERVER_TIMING_ENABLED")
+
 		util.MustBindPFlag("authzen.baseURL", flags.Lookup("authzen-base-url"))
 		util.MustBindEnv("authzen.baseURL", "OPENFGA_AUTHZEN_BASE_URL")
 
+		util.MustBindPFlag("opaBundle.enabled", flags.Lookup("opa-bundle-enabled"))
+		util.MustBindEnv("opaBundle.enabled", "OPENFGA_OPA_BUNDLE_ENABLED")
+
+		util.MustBindPFlag("opaBundle.maxTuples", flags.Lookup("opa-bundle-max-tuples"))
+		util.MustBindEnv("opaBundle.maxTuples", "OPENFGA_OPA_BUNDLE_MAX_TUPLES")
+
+		util.MustBindPFlag("opaBundle.maxDeltaChanges", flags.Lookup("opa-bundle-max-delta-changes"))
+		util.MustBindEnv("opaBundle.maxDeltaChanges", "OPENFGA_OPA_BUNDLE_MAX_DELTA_CHANGES")
+
+		util.MustBindPFlag("changelogRetention.enabled", flags.Lookup("changelog-retention-enabled"))
+		util.MustBindEnv("changelogRetention.enabled", "OPENFGA_CHANGELOG_RETENTION_ENABLED")
+
+		util.MustBindPFlag("changelogRetention.interval", flags.Lookup("changelog-retention-interval"))
+		util.MustBindEnv("changelogRetention.interval", "OPENFGA_CHANGELOG_RETENTION_INTERVAL")
+
+		util.MustBindPFlag("changelogRetention.maxAge", flags.Lookup("changelog-retention-max-age"))
+		util.MustBindEnv("changelogRetention.maxAge", "OPENFGA_CHANGELOG_RETENTION_MAX_AGE")
+
+		util.MustBindPFlag("changelogRetention.maxEntries", flags.Lookup("changelog-retention-max-entries"))
+		util.MustBindEnv("changelogRetention.maxEntries", "OPENFGA_CHANGELOG_RETENTION_MAX_ENTRIES")
+
+		util.MustBindPFlag("changelogRetention.cursorLease", flags.Lookup("changelog-retention-cursor-lease"))
+		util.MustBindEnv("changelogRetention.cursorLease", "OPENFGA_CHANGELOG_RETENTION_CURSOR_LEASE")
+
+		util.MustBindPFlag("changelogRetention.storePolicies", flags.Lookup("changelog-retention-store-policies"))
+		util.MustBindEnv("changelogRetention.storePolicies", "OPENFGA_CHANGELOG_RETENTION_STORE_POLICIES")
+
+		util.MustBindPFlag("orphanedTuples.endpointEnabled", flags.Lookup("orphaned-tuples-endpoint-enabled"))
+		util.MustBindEnv("orphanedTuples.endpointEnabled", "OPENFGA_ORPHANED_TUPLES_ENDPOINT_ENABLED")
+
+		util.MustBindPFlag("orphanedTuples.collectorEnabled", flags.Lookup("orphaned-tuples-collector-enabled"))
+		util.MustBindEnv("orphanedTuples.collectorEnabled", "OPENFGA_ORPHANED_TUPLES_COLLECTOR_ENABLED")
+
+		util.MustBindPFlag("orphanedTuples.collectorInterval", flags.Lookup("orphaned-tuples-collector-interval"))
+		util.MustBindEnv("orphanedTuples.collectorInterval", "OPENFGA_ORPHANED_TUPLES_COLLECTOR_INTERVAL")
+
+		util.MustBindPFlag("orphanedTuples.collectorDryRun", flags.Lookup("orphaned-tuples-collector-dry-run"))
+		util.MustBindEnv("orphanedTuples.collectorDryRun", "OPENFGA_ORPHANED_TUPLES_COLLECTOR_DRY_RUN")
+
+		util.MustBindPFlag("consistencyVerifier.enabled", flags.Lookup("consistency-verifier-enabled"))
+		util.MustBindEnv("consistencyVerifier.enabled", "OPENFGA_CONSISTENCY_VERIFIER_ENABLED")
+
+		util.MustBindPFlag("consistencyVerifier.interval", flags.Lookup("consistency-verifier-interval"))
+		util.MustBindEnv("consistencyVerifier.interval", "OPENFGA_CONSISTENCY_VERIFIER_INTERVAL")
+
+		util.MustBindPFlag("consistencyVerifier.sampleSize", flags.Lookup("consistency-verifier-sample-size"))
+		util.MustBindEnv("consistencyVerifier.sampleSize", "OPENFGA_CONSISTENCY_VERIFIER_SAMPLE_SIZE")
+
+		util.MustBindPFlag("shadowCheck.timeout", flags.Lookup("shadow-check-timeout"))
+		util.MustBindEnv("shadowCheck.timeout", "OPENFGA_SHADOW_CHECK_TIMEOUT")
+
+		util.MustBindPFlag("shadowCheck.samplePercentage", flags.Lookup("shadow-check-sample-percentage"))
+		util.MustBindEnv("shadowCheck.samplePercentage", "OPENFGA_SHADOW_CHECK_SAMPLE_PERCENTAGE")
+
+		util.MustBindPFlag("requestRecording.enabled", flags.Lookup("request-recording-enabled"))
+		util.MustBindEnv("requestRecording.enabled", "OPENFGA_REQUEST_RECORDING_ENABLED")
+
+		util.MustBindPFlag("requestRecording.path", flags.Lookup("request-recording-path"))
+		util.MustBindEnv("requestRecording.path", "OPENFGA_REQUEST_RECORDING_PATH")
+
+		util.MustBindPFlag("requestRecording.samplePercentage", flags.Lookup("request-recording-sample-percentage"))
+		util.MustBindEnv("requestRecording.samplePercentage", "OPENFGA_REQUEST_RECORDING_SAMPLE_PERCENTAGE")
+
+		util.MustBindPFlag("requestRecording.anonymize", flags.Lookup("request-recording-anonymize"))
+		util.MustBindEnv("requestRecording.anonymize", "OPENFGA_REQUEST_RECORDING_ANONYMIZE")
+
+		util.MustBindPFlag("requestRecording.anonymizationKey", flags.Lookup("request-recording-anonymization-key"))
+		util.MustBindEnv("requestRecording.anonymizationKey", "OPENFGA_REQUEST_RECORDING_ANONYMIZATION_KEY")
+
+		util.MustBindPFlag("hotPaths.enabled", flags.Lookup("hot-paths-enabled"))
+		util.MustBindEnv("hotPaths.enabled", "OPENFGA_HOT_PATHS_ENABLED")
+
+		util.MustBindPFlag("hotPaths.interval", flags.Lookup("hot-paths-interval"))
+		util.MustBindEnv("hotPaths.interval", "OPENFGA_HOT_PATHS_INTERVAL")
+
+		util.MustBindPFlag("hotPaths.topN", flags.Lookup("hot-paths-top-n"))
+		util.MustBindEnv("hotPaths.topN", "OPENFGA_HOT_PATHS_TOP_N")
+
+		util.MustBindPFlag("hotPaths.minChecks", flags.Lookup("hot-paths-min-checks"))
+		util.MustBindEnv("hotPaths.minChecks", "OPENFGA_HOT_PATHS_MIN_CHECKS")
+
+		util.MustBindPFlag("hotPaths.maxObjects", flags.Lookup("hot-paths-max-objects"))
+		util.MustBindEnv("hotPaths.maxObjects", "OPENFGA_HOT_PATHS_MAX_OBJECTS")
+
+		util.MustBindPFlag("materializedPermissions.enabled", flags.Lookup("materialized-permissions-enabled"))
+		util.MustBindEnv("materializedPermissions.enabled", "OPENFGA_MATERIALIZED_PERMISSIONS_ENABLED")
+
+		util.MustBindPFlag("materializedPermissions.relations", flags.Lookup("materialized-permissions-relations"))
+		util.MustBindEnv("materializedPermissions.relations", "OPENFGA_MATERIALIZED_PERMISSIONS_RELATIONS")
+
+		util.MustBindPFlag("materializedPermissions.interval", flags.Lookup("materialized-permissions-interval"))
+		util.MustBindEnv("materializedPermissions.interval", "OPENFGA_MATERIALIZED_PERMISSIONS_INTERVAL")
+
+		util.MustBindPFlag("materializedObjectLists.enabled", flags.Lookup("materialized-object-lists-enabled"))
+		util.MustBindEnv("materializedObjectLists.enabled", "OPENFGA_MATERIALIZED_OBJECT_LISTS_ENABLED")
+
+		util.MustBindPFlag("materializedObjectLists.interval", flags.Lookup("materialized-object-lists-interval"))
+		util.MustBindEnv("materializedObjectLists.interval", "OPENFGA_MATERIALIZED_OBJECT_LISTS_INTERVAL")
+
+		util.MustBindPFlag("materializedObjectLists.topN", flags.Lookup("materialized-object-lists-top-n"))
+		util.MustBindEnv("materializedObjectLists.topN", "OPENFGA_MATERIALIZED_OBJECT_LISTS_TOP_N")
+
+		util.MustBindPFlag("materializedObjectLists.minRequests", flags.Lookup("materialized-object-lists-min-requests"))
+		util.MustBindEnv("materializedObjectLists.minRequests", "OPENFGA_MATERIALIZED_OBJECT_LISTS_MIN_REQUESTS")
+
+		util.MustBindPFlag("cluster.peers", flags.Lookup("cluster-peers"))
+		util.MustBindEnv("cluster.peers", "OPENFGA_CLUSTER_PEERS")
+
+		util.MustBindPFlag("cluster.selfAddress", flags.Lookup("cluster-self-address"))
+		util.MustBindEnv("cluster.selfAddress", "OPENFGA_CLUSTER_SELF_ADDRESS")
+
+		util.MustBindPFlag("cluster.singleflightEnabled", flags.Lookup("cluster-singleflight-enabled"))
+		util.MustBindEnv("cluster.singleflightEnabled", "OPENFGA_CLUSTER_SINGLEFLIGHT_ENABLED")
+
+		util.MustBindPFlag("cluster.forwardTimeout", flags.Lookup("cluster-forward-timeout"))
+		util.MustBindEnv("cluster.forwardTimeout", "OPENFGA_CLUSTER_FORWARD_TIMEOUT")
+
+		util.MustBindPFlag("cluster.tlsEnabled", flags.Lookup("cluster-tls-enabled"))
+		util.MustBindEnv("cluster.tlsEnabled", "OPENFGA_CLUSTER_TLS_ENABLED")
+
+		util.MustBindPFlag("cluster.dispatchEnabled", flags.Lookup("cluster-dispatch-enabled"))
+		util.MustBindEnv("cluster.dispatchEnabled", "OPENFGA_CLUSTER_DISPATCH_ENABLED")
+
+		util.MustBindPFlag("cluster.dispatchServiceEnabled", flags.Lookup("cluster-dispatch-service-enabled"))
+		util.MustBindEnv("cluster.dispatchServiceEnabled", "OPENFGA_CLUSTER_DISPATCH_SERVICE_ENABLED")
+
+		util.MustBindPFlag("cluster.dispatchTier", flags.Lookup("cluster-dispatch-tier"))
+		util.MustBindEnv("cluster.dispatchTier", "OPENFGA_CLUSTER_DISPATCH_TIER")
+
+		util.MustBindPFlag("cluster.gossip.bindAddress", flags.Lookup("cluster-gossip-bind-address"))
+		util.MustBindEnv("cluster.gossip.bindAddress", "OPENFGA_CLUSTER_GOSSIP_BIND_ADDRESS")
+
+		util.MustBindPFlag("cluster.gossip.join", flags.Lookup("cluster-gossip-join"))
+		util.MustBindEnv("cluster.gossip.join", "OPENFGA_CLUSTER_GOSSIP_JOIN")
+
+		util.MustBindPFlag("priorityScheduling.enabled", flags.Lookup("priority-scheduling-enabled"))
+		util.MustBindEnv("priorityScheduling.enabled", "OPENFGA_PRIORITY_SCHEDULING_ENABLED")
+
+		util.MustBindPFlag("priorityScheduling.maxInFlight", flags.Lookup("priority-scheduling-max-in-flight"))
+		util.MustBindEnv("priorityScheduling.maxInFlight", "OPENFGA_PRIORITY_SCHEDULING_MAX_IN_FLIGHT")
+
+		util.MustBindPFlag("priorityScheduling.methodClasses", flags.Lookup("priority-scheduling-method-classes"))
+		util.MustBindEnv("priorityScheduling.methodClasses", "OPENFGA_PRIORITY_SCHEDULING_METHOD_CLASSES")
+
+		util.MustBindPFlag("priorityScheduling.storeWeights", flags.Lookup("priority-scheduling-store-weights"))
+		util.MustBindEnv("priorityScheduling.storeWeights", "OPENFGA_PRIORITY_SCHEDULING_STORE_WEIGHTS")
+
+		util.MustBindPFlag("batchCheckAdaptiveLimits.enabled", flags.Lookup("batch-check-adaptive-limits-enabled"))
+		util.MustBindEnv("batchCheckAdaptiveLimits.enabled", "OPENFGA_BATCH_CHECK_ADAPTIVE_LIMITS_ENABLED")
+
+		util.MustBindPFlag("batchCheckAdaptiveLimits.loadThreshold", flags.Lookup("batch-check-adaptive-limits-load-threshold"))
+		util.MustBindEnv("batchCheckAdaptiveLimits.loadThreshold", "OPENFGA_BATCH_CHECK_ADAPTIVE_LIMITS_LOAD_THRESHOLD")
+
+		util.MustBindPFlag("batchCheckAdaptiveLimits.minRatio", flags.Lookup("batch-check-adaptive-limits-min-ratio"))
+		util.MustBindEnv("batchCheckAdaptiveLimits.minRatio", "OPENFGA_BATCH_CHECK_ADAPTIVE_LIMITS_MIN_RATIO")
+
+		util.MustBindPFlag("streamedBatchCheck.enabled", flags.Lookup("streamed-batch-check-enabled"))
+		util.MustBindEnv("streamedBatchCheck.enabled", "OPENFGA_STREAMED_BATCH_CHECK_ENABLED")
+
+		util.MustBindPFlag("bulkWrite.enabled", flags.Lookup("bulk-write-enabled"))
+		util.MustBindEnv("bulkWrite.enabled", "OPENFGA_BULK_WRITE_ENABLED")
+
+		util.MustBindPFlag("bulkWrite.window", flags.Lookup("bulk-write-window"))
+		util.MustBindEnv("bulkWrite.window", "OPENFGA_BULK_WRITE_WINDOW")
+
+		util.MustBindPFlag("storePurge.enabled", flags.Lookup("store-purge-enabled"))
+		util.MustBindEnv("storePurge.enabled", "OPENFGA_STORE_PURGE_ENABLED")
+
+		util.MustBindPFlag("storePurge.jobRetention", flags.Lookup("store-purge-job-retention"))
+		util.MustBindEnv("storePurge.jobRetention", "OPENFGA_STORE_PURGE_JOB_RETENTION")
+
+		util.MustBindPFlag("storeEvents.enabled", flags.Lookup("store-events-enabled"))
+		util.MustBindEnv("storeEvents.enabled", "OPENFGA_STORE_EVENTS_ENABLED")
+
+		util.MustBindPFlag("listObjectsSubscription.enabled", flags.Lookup("list-objects-subscription-enabled"))
+		util.MustBindEnv("listObjectsSubscription.enabled", "OPENFGA_LIST_OBJECTS_SUBSCRIPTION_ENABLED")
+
+		util.MustBindPFlag("listObjectsSubscription.pollInterval", flags.Lookup("list-objects-subscription-poll-interval"))
+		util.MustBindEnv("listObjectsSubscription.pollInterval", "OPENFGA_LIST_OBJECTS_SUBSCRIPTION_POLL_INTERVAL")
+
+		util.MustBindPFlag("cacheHints.enabled", flags.Lookup("cache-hints-enabled"))
+		util.MustBindEnv("cacheHints.enabled", "OPENFGA_CACHE_HINTS_ENABLED")
+
+		util.MustBindPFlag("cacheHints.maxAge", flags.Lookup("cache-hints-max-age"))
+		util.MustBindEnv("cacheHints.maxAge", "OPENFGA_CACHE_HINTS_MAX_AGE")
+
+		util.MustBindPFlag("cacheHints.invalidationPollInterval", flags.Lookup("cache-hints-invalidation-poll-interval"))
+		util.MustBindEnv("cacheHints.invalidationPollInterval", "OPENFGA_CACHE_HINTS_INVALIDATION_POLL_INTERVAL")
+
+		util.MustBindPFlag("sessionTokens.enabled", flags.Lookup("session-tokens-enabled"))
+		util.MustBindEnv("sessionTokens.enabled", "OPENFGA_SESSION_TOKENS_ENABLED")
+
+		util.MustBindPFlag("sessionTokens.commitMargin", flags.Lookup("session-tokens-commit-margin"))
+		util.MustBindEnv("sessionTokens.commitMargin", "OPENFGA_SESSION_TOKENS_COMMIT_MARGIN")
+
+		util.MustBindPFlag("assertionCoverage.enabled", flags.Lookup("assertion-coverage-enabled"))
+		util.MustBindEnv("assertionCoverage.enabled", "OPENFGA_ASSERTION_COVERAGE_ENABLED")
+
+		util.MustBindPFlag("modelGraph.enabled", flags.Lookup("model-graph-enabled"))
+		util.MustBindEnv("modelGraph.enabled", "OPENFGA_MODEL_GRAPH_ENABLED")
+
+		util.MustBindPFlag("costEstimate.enabled", flags.Lookup("cost-estimate-enabled"))
+		util.MustBindEnv("costEstimate.enabled", "OPENFGA_COST_ESTIMATE_ENABLED")
+
+		util.MustBindPFlag("costEstimate.sampleSize", flags.Lookup("cost-estimate-sample-size"))
+		util.MustBindEnv("costEstimate.sampleSize", "OPENFGA_COST_ESTIMATE_SAMPLE_SIZE")
+
+		util.MustBindPFlag("whatIf.enabled", flags.Lookup("what-if-enabled"))
+		util.MustBindEnv("whatIf.enabled", "OPENFGA_WHAT_IF_ENABLED")
+
+		util.MustBindPFlag("modelSimulation.enabled", flags.Lookup("model-simulation-enabled"))
+		util.MustBindEnv("modelSimulation.enabled", "OPENFGA_MODEL_SIMULATION_ENABLED")
+
+		util.MustBindPFlag("accessReview.enabled", flags.Lookup("access-review-enabled"))
+		util.MustBindEnv("accessReview.enabled", "OPENFGA_ACCESS_REVIEW_ENABLED")
+
+		util.MustBindPFlag("accessReview.artifactDir", flags.Lookup("access-review-artifact-dir"))
+		util.MustBindEnv("accessReview.artifactDir", "OPENFGA_ACCESS_REVIEW_ARTIFACT_DIR")
+
+		util.MustBindPFlag("accessReview.jobRetention", flags.Lookup("access-review-job-retention"))
+		util.MustBindEnv("accessReview.jobRetention", "OPENFGA_ACCESS_REVIEW_JOB_RETENTION")
+
+		util.MustBindPFlag("jobs.enabled", flags.Lookup("jobs-enabled"))
+		util.MustBindEnv("jobs.enabled", "OPENFGA_JOBS_ENABLED")
+
+		util.MustBindPFlag("jobs.workers", flags.Lookup("jobs-workers"))
+		util.MustBindEnv("jobs.workers", "OPENFGA_JOBS_WORKERS")
+
+		util.MustBindPFlag("jobs.queueSize", flags.Lookup("jobs-queue-size"))
+		util.MustBindEnv("jobs.queueSize", "OPENFGA_JOBS_QUEUE_SIZE")
+
+		util.MustBindPFlag("jobs.retention", flags.Lookup("jobs-retention"))
+		util.MustBindEnv("jobs.retention", "OPENFGA_JOBS_RETENTION")
+
+		util.MustBindPFlag("jobs.scheduleInterval", flags.Lookup("jobs-schedule-interval"))
+		util.MustBindEnv("jobs.scheduleInterval", "OPENFGA_JOBS_SCHEDULE_INTERVAL")
+
+		util.MustBindPFlag("multiRegion.enabled", flags.Lookup("multi-region-enabled"))
+		util.MustBindEnv("multiRegion.enabled", "OPENFGA_MULTI_REGION_ENABLED")
+
+		util.MustBindPFlag("multiRegion.regionId", flags.Lookup("multi-region-id"))
+		util.MustBindEnv("multiRegion.regionId", "OPENFGA_MULTI_REGION_ID")
+
+		util.MustBindPFlag("writeValidationHook.enabled", flags.Lookup("write-validation-hook-enabled"))
+		util.MustBindEnv("writeValidationHook.enabled", "OPENFGA_WRITE_VALIDATION_HOOK_ENABLED")
+
+		util.MustBindPFlag("writeValidationHook.url", flags.Lookup("write-validation-hook-url"))
+		util.MustBindEnv("writeValidationHook.url", "OPENFGA_WRITE_VALIDATION_HOOK_URL")
+
+		util.MustBindPFlag("writeValidationHook.timeout", flags.Lookup("write-validation-hook-timeout"))
+		util.MustBindEnv("writeValidationHook.timeout", "OPENFGA_WRITE_VALIDATION_HOOK_TIMEOUT")
+
+		util.MustBindPFlag("writeValidationHook.failOpen", flags.Lookup("write-validation-hook-fail-open"))
+		util.MustBindEnv("writeValidationHook.failOpen", "OPENFGA_WRITE_VALIDATION_HOOK_FAIL_OPEN")
+
+		util.MustBindPFlag("contextEnrichmentHook.enabled", flags.Lookup("context-enrichment-hook-enabled"))
+		util.MustBindEnv("contextEnrichmentHook.enabled", "OPENFGA_CONTEXT_ENRICHMENT_HOOK_ENABLED")
+
+		util.MustBindPFlag("contextEnrichmentHook.url", flags.Lookup("context-enrichment-hook-url"))
+		util.MustBindEnv("contextEnrichmentHook.url", "OPENFGA_CONTEXT_ENRICHMENT_HOOK_URL")
+
+		util.MustBindPFlag("contextEnrichmentHook.timeout", flags.Lookup("context-enrichment-hook-timeout"))
+		util.MustBindEnv("contextEnrichmentHook.timeout", "OPENFGA_CONTEXT_ENRICHMENT_HOOK_TIMEOUT")
+
+		util.MustBindPFlag("contextEnrichmentHook.cacheTTL", flags.Lookup("context-enrichment-hook-cache-ttl"))
+		util.MustBindEnv("contextEnrichmentHook.cacheTTL", "OPENFGA_CONTEXT_ENRICHMENT_HOOK_CACHE_TTL")
+
+		util.MustBindPFlag("contextEnrichmentHook.cacheMaxEntries", flags.Lookup("context-enrichment-hook-cache-max-entries"))
+		util.MustBindEnv("contextEnrichmentHook.cacheMaxEntries", "OPENFGA_CONTEXT_ENRICHMENT_HOOK_CACHE_MAX_ENTRIES")
+
+		util.MustBindPFlag("conditionContext.headers", flags.Lookup("condition-context-headers"))
+		util.MustBindEnv("conditionContext.headers", "OPENFGA_CONDITION_CONTEXT_HEADERS")
+
+		util.MustBindPFlag("conditionContext.claims", flags.Lookup("condition-context-claims"))
+		util.MustBindEnv("conditionContext.claims", "OPENFGA_CONDITION_CONTEXT_CLAIMS")
+
+		util.MustBindPFlag("slo.enabled", flags.Lookup("slo-enabled"))
+		util.MustBindEnv("slo.enabled", "OPENFGA_SLO_ENABLED")
+
+		util.MustBindPFlag("slo.objectives", flags.Lookup("slo-objectives"))
+		util.MustBindEnv("slo.objectives", "OPENFGA_SLO_OBJECTIVES")
+
+		util.MustBindPFlag("slo.window", flags.Lookup("slo-window"))
+		util.MustBindEnv("slo.window", "OPENFGA_SLO_WINDOW")
+
 		util.MustBindPFlag("authn.method", flags.Lookup("authn-method"))
 		util.MustBindEnv("authn.method", "OPENFGA_AUTHN_METHOD")
 
@@ -119,6 +464,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("datastore.maxTypesystemCacheSize", flags.Lookup("datastore-max-typesystem-cache-size"))
 		util.MustBindEnv("datastore.maxTypesystemCacheSize", "OPENFGA_DATASTORE_MAX_TYPESYSTEM_CACHE_SIZE", "OPENFGA_DATASTORE_MAXTYPESYSTEMCACHESIZE")
 
+		util.MustBindPFlag("datastore.latestModelCacheTTL", flags.Lookup("datastore-latest-model-cache-ttl"))
+		util.MustBindEnv("datastore.latestModelCacheTTL", "OPENFGA_DATASTORE_LATEST_MODEL_CACHE_TTL", "OPENFGA_DATASTORE_LATESTMODELCACHETTL")
+
+		util.MustBindPFlag("datastore.typesystemCompilationCacheEngine", flags.Lookup("datastore-typesystem-compilation-cache-engine"))
+		util.MustBindEnv("datastore.typesystemCompilationCacheEngine", "OPENFGA_DATASTORE_TYPESYSTEM_COMPILATION_CACHE_ENGINE", "OPENFGA_DATASTORE_TYPESYSTEMCOMPILATIONCACHEENGINE")
+
+		util.MustBindPFlag("datastore.latestModelCacheEngine", flags.Lookup("datastore-latest-model-cache-engine"))
+		util.MustBindEnv("datastore.latestModelCacheEngine", "OPENFGA_DATASTORE_LATEST_MODEL_CACHE_ENGINE", "OPENFGA_DATASTORE_LATESTMODELCACHEENGINE")
+
 		util.MustBindPFlag("datastore.minOpenConns", flags.Lookup("datastore-min-open-conns"))
 		util.MustBindEnv("datastore.minOpenConns", "OPENFGA_DATASTORE_MIN_OPEN_CONNS")
 
@@ -140,6 +494,27 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("datastore.metrics.enabled", flags.Lookup("datastore-metrics-enabled"))
 		util.MustBindEnv("datastore.metrics.enabled", "OPENFGA_DATASTORE_METRICS_ENABLED")
 
+		util.MustBindPFlag("datastore.indexAdvisor.enabled", flags.Lookup("datastore-index-advisor-enabled"))
+		util.MustBindEnv("datastore.indexAdvisor.enabled", "OPENFGA_DATASTORE_INDEX_ADVISOR_ENABLED")
+
+		util.MustBindPFlag("datastore.indexAdvisor.slowQueryThreshold", flags.Lookup("datastore-index-advisor-slow-query-threshold"))
+		util.MustBindEnv("datastore.indexAdvisor.slowQueryThreshold", "OPENFGA_DATASTORE_INDEX_ADVISOR_SLOW_QUERY_THRESHOLD")
+
+		util.MustBindPFlag("datastore.reverseUserIndex.enabled", flags.Lookup("datastore-reverse-user-index-enabled"))
+		util.MustBindEnv("datastore.reverseUserIndex.enabled", "OPENFGA_DATASTORE_REVERSE_USER_INDEX_ENABLED")
+
+		util.MustBindPFlag("datastore.maintenanceTuning.enabled", flags.Lookup("datastore-maintenance-tuning-enabled"))
+		util.MustBindEnv("datastore.maintenanceTuning.enabled", "OPENFGA_DATASTORE_MAINTENANCE_TUNING_ENABLED")
+
+		util.MustBindPFlag("datastore.iamAuth.method", flags.Lookup("datastore-iam-auth-method"))
+		util.MustBindEnv("datastore.iamAuth.method", "OPENFGA_DATASTORE_IAM_AUTH_METHOD")
+
+		util.MustBindPFlag("datastore.iamAuth.region", flags.Lookup("datastore-iam-auth-region"))
+		util.MustBindEnv("datastore.iamAuth.region", "OPENFGA_DATASTORE_IAM_AUTH_REGION")
+
+		util.MustBindPFlag("datastore.transactionPooling.enabled", flags.Lookup("datastore-transaction-pooling-enabled"))
+		util.MustBindEnv("datastore.transactionPooling.enabled", "OPENFGA_DATASTORE_TRANSACTION_POOLING_ENABLED")
+
 		util.MustBindPFlag("playground.enabled", flags.Lookup("playground-enabled"))
 		util.MustBindEnv("playground.enabled", "OPENFGA_PLAYGROUND_ENABLED")
 
@@ -182,6 +557,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("trace.resourceAttributes", flags.Lookup("trace-resource-attributes"))
 		util.MustBindEnv("trace.resourceAttributes", "OTEL_RESOURCE_ATTRIBUTES")
 
+		util.MustBindPFlag("trace.verboseRequestsEnabled", flags.Lookup("trace-verbose-requests-enabled"))
+		util.MustBindEnv("trace.verboseRequestsEnabled", "OPENFGA_TRACE_VERBOSE_REQUESTS_ENABLED")
+
+		util.MustBindPFlag("trace.storeSampleRatios", flags.Lookup("trace-store-sample-ratios"))
+		util.MustBindEnv("trace.storeSampleRatios", "OPENFGA_TRACE_STORE_SAMPLE_RATIOS")
+
+		util.MustBindPFlag("trace.methodSampleRatios", flags.Lookup("trace-method-sample-ratios"))
+		util.MustBindEnv("trace.methodSampleRatios", "OPENFGA_TRACE_METHOD_SAMPLE_RATIOS")
+
 		util.MustBindPFlag("metrics.enabled", flags.Lookup("metrics-enabled"))
 		util.MustBindEnv("metrics.enabled", "OPENFGA_METRICS_ENABLED")
 
@@ -200,6 +584,12 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("maxTuplesPerWrite", flags.Lookup("max-tuples-per-write"))
 		util.MustBindEnv("maxTuplesPerWrite", "OPENFGA_MAX_TUPLES_PER_WRITE", "OPENFGA_MAXTUPLESPERWRITE")
 
+		util.MustBindPFlag("maxContextualTuples", flags.Lookup("max-contextual-tuples"))
+		util.MustBindEnv("maxContextualTuples", "OPENFGA_MAX_CONTEXTUAL_TUPLES")
+
+		util.MustBindPFlag("contextualTuplesValidationMode", flags.Lookup("contextual-tuples-validation-mode"))
+		util.MustBindEnv("contextualTuplesValidationMode", "OPENFGA_CONTEXTUAL_TUPLES_VALIDATION_MODE")
+
 		util.MustBindPFlag("maxTypesPerAuthorizationModel", flags.Lookup("max-types-per-authorization-model"))
 		util.MustBindEnv("maxTypesPerAuthorizationModel", "OPENFGA_MAX_TYPES_PER_AUTHORIZATION_MODEL", "OPENFGA_MAXTYPESPERAUTHORIZATIONMODEL")
 
@@ -218,6 +608,9 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("maxConditionEvaluationCost", flags.Lookup("max-condition-evaluation-cost"))
 		util.MustBindEnv("maxConditionEvaluationCost", "OPENFGA_MAX_CONDITION_EVALUATION_COST", "OPENFGA_MAXCONDITIONEVALUATIONCOST")
 
+		util.MustBindPFlag("conditionExtensions", flags.Lookup("condition-extensions"))
+		util.MustBindEnv("conditionExtensions", "OPENFGA_CONDITION_EXTENSIONS", "OPENFGA_CONDITIONEXTENSIONS")
+
 		util.MustBindPFlag("changelogHorizonOffset", flags.Lookup("changelog-horizon-offset"))
 		util.MustBindEnv("changelogHorizonOffset", "OPENFGA_CHANGELOG_HORIZON_OFFSET", "OPENFGA_CHANGELOGHORIZONOFFSET")
 
@@ -236,6 +629,12 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("listObjectsPipelineEnabled", flags.Lookup("listObjects-pipeline-enabled"))
 		util.MustBindEnv("listObjectsPipelineEnabled", "OPENFGA_LIST_OBJECTS_PIPELINE_ENABLED", "OPENFGA_LISTOBJECTSPIPELINEENABLED")
 
+		util.MustBindPFlag("streamedListObjectsMaxInFlightMessages", flags.Lookup("streamedListObjects-max-in-flight-messages"))
+		util.MustBindEnv("streamedListObjectsMaxInFlightMessages", "OPENFGA_STREAMED_LIST_OBJECTS_MAX_IN_FLIGHT_MESSAGES")
+
+		util.MustBindPFlag("streamedListObjectsMessagesPerSecond", flags.Lookup("streamedListObjects-messages-per-second"))
+		util.MustBindEnv("streamedListObjectsMessagesPerSecond", "OPENFGA_STREAMED_LIST_OBJECTS_MESSAGES_PER_SECOND")
+
 		util.MustBindPFlag("listUsersDeadline", flags.Lookup("listUsers-deadline"))
 		util.MustBindEnv("listUsersDeadline", "OPENFGA_LIST_USERS_DEADLINE", "OPENFGA_LISTUSERSDEADLINE")
 
@@ -245,9 +644,21 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("readChangesMaxPageSize", flags.Lookup("readChanges-max-page-size"))
 		util.MustBindEnv("readChangesMaxPageSize", "OPENFGA_READ_CHANGES_MAX_PAGE_SIZE", "OPENFGA_READCHANGESMAXPAGESIZE")
 
+		util.MustBindPFlag("checkCache.engine", flags.Lookup("check-cache-engine"))
+		util.MustBindEnv("checkCache.engine", "OPENFGA_CHECK_CACHE_ENGINE")
+
 		util.MustBindPFlag("checkCache.limit", flags.Lookup("check-cache-limit"))
 		util.MustBindEnv("checkCache.limit", "OPENFGA_CHECK_CACHE_LIMIT")
 
+		util.MustBindPFlag("checkCache.maxBytes", flags.Lookup("check-cache-max-bytes"))
+		util.MustBindEnv("checkCache.maxBytes", "OPENFGA_CHECK_CACHE_MAX_BYTES")
+
+		util.MustBindPFlag("checkCache.shards", flags.Lookup("check-cache-shards"))
+		util.MustBindEnv("checkCache.shards", "OPENFGA_CHECK_CACHE_SHARDS")
+
+		util.MustBindPFlag("checkCache.diskPath", flags.Lookup("check-cache-disk-path"))
+		util.MustBindEnv("checkCache.diskPath", "OPENFGA_CHECK_CACHE_DISK_PATH")
+
 		// The below configuration is deprecated in favour of OPENFGA_CHECK_CACHE_LIMIT
 		util.MustBindPFlag("cache.limit", flags.Lookup("check-query-cache-limit"))
 		util.MustBindEnv("cache.limit", "OPENFGA_CHECK_QUERY_CACHE_LIMIT")
@@ -258,6 +669,51 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("cacheController.ttl", flags.Lookup("cache-controller-ttl"))
 		util.MustBindEnv("cacheController.ttl", "OPENFGA_CACHE_CONTROLLER_TTL")
 
+		util.MustBindPFlag("cacheInvalidationListener.enabled", flags.Lookup("cache-invalidation-listener-enabled"))
+		util.MustBindEnv("cacheInvalidationListener.enabled", "OPENFGA_CACHE_INVALIDATION_LISTENER_ENABLED")
+
+		util.MustBindPFlag("cacheInvalidationListener.postgresPublication", flags.Lookup("cache-invalidation-listener-postgres-publication"))
+		util.MustBindEnv("cacheInvalidationListener.postgresPublication", "OPENFGA_CACHE_INVALIDATION_LISTENER_POSTGRES_PUBLICATION")
+
+		util.MustBindPFlag("cacheInvalidationListener.pollInterval", flags.Lookup("cache-invalidation-listener-poll-interval"))
+		util.MustBindEnv("cacheInvalidationListener.pollInterval", "OPENFGA_CACHE_INVALIDATION_LISTENER_POLL_INTERVAL")
+
+		util.MustBindPFlag("userIDEncryption.enabled", flags.Lookup("user-id-encryption-enabled"))
+		util.MustBindEnv("userIDEncryption.enabled", "OPENFGA_USER_ID_ENCRYPTION_ENABLED")
+
+		util.MustBindPFlag("userIDEncryption.key", flags.Lookup("user-id-encryption-key"))
+		util.MustBindEnv("userIDEncryption.key", "OPENFGA_USER_ID_ENCRYPTION_KEY")
+
+		util.MustBindPFlag("userIDEncryption.types", flags.Lookup("user-id-encryption-types"))
+		util.MustBindEnv("userIDEncryption.types", "OPENFGA_USER_ID_ENCRYPTION_TYPES")
+
+		util.MustBindPFlag("redaction.mode", flags.Lookup("redaction-mode"))
+		util.MustBindEnv("redaction.mode", "OPENFGA_REDACTION_MODE")
+
+		util.MustBindPFlag("redaction.key", flags.Lookup("redaction-key"))
+		util.MustBindEnv("redaction.key", "OPENFGA_REDACTION_KEY")
+
+		util.MustBindPFlag("redaction.keyRotationPeriod", flags.Lookup("redaction-key-rotation-period"))
+		util.MustBindEnv("redaction.keyRotationPeriod", "OPENFGA_REDACTION_KEY_ROTATION_PERIOD")
+
+		util.MustBindPFlag("secrets.refreshInterval", flags.Lookup("secrets-refresh-interval"))
+		util.MustBindEnv("secrets.refreshInterval", "OPENFGA_SECRETS_REFRESH_INTERVAL")
+
+		util.MustBindPFlag("audit.enabled", flags.Lookup("audit-enabled"))
+		util.MustBindEnv("audit.enabled", "OPENFGA_AUDIT_ENABLED")
+
+		util.MustBindPFlag("audit.otlp.endpoint", flags.Lookup("audit-otlp-endpoint"))
+		util.MustBindEnv("audit.otlp.endpoint", "OPENFGA_AUDIT_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT")
+
+		util.MustBindPFlag("audit.otlp.tls.enabled", flags.Lookup("audit-otlp-tls-enabled"))
+		util.MustBindEnv("audit.otlp.tls.enabled", "OPENFGA_AUDIT_OTLP_TLS_ENABLED")
+
+		util.MustBindPFlag("audit.queueSize", flags.Lookup("audit-queue-size"))
+		util.MustBindEnv("audit.queueSize", "OPENFGA_AUDIT_QUEUE_SIZE")
+
+		util.MustBindPFlag("audit.flushInterval", flags.Lookup("audit-flush-interval"))
+		util.MustBindEnv("audit.flushInterval", "OPENFGA_AUDIT_FLUSH_INTERVAL")
+
 		util.MustBindPFlag("cacheTTLJitterPercentage", flags.Lookup("cache-ttl-jitter-percentage"))
 		util.MustBindEnv("cacheTTLJitterPercentage", "OPENFGA_CACHE_TTL_JITTER_PERCENTAGE")
 
@@ -285,6 +741,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("listObjectsIteratorCache.ttl", flags.Lookup("list-objects-iterator-cache-ttl"))
 		util.MustBindEnv("listObjectsIteratorCache.ttl", "OPENFGA_LIST_OBJECTS_ITERATOR_CACHE_TTL")
 
+		util.MustBindPFlag("listObjectsQueryCache.enabled", flags.Lookup("list-objects-query-cache-enabled"))
+		util.MustBindEnv("listObjectsQueryCache.enabled", "OPENFGA_LIST_OBJECTS_QUERY_CACHE_ENABLED")
+
+		util.MustBindPFlag("listObjectsQueryCache.maxResults", flags.Lookup("list-objects-query-cache-max-results"))
+		util.MustBindEnv("listObjectsQueryCache.maxResults", "OPENFGA_LIST_OBJECTS_QUERY_CACHE_MAX_RESULTS")
+
+		util.MustBindPFlag("listObjectsQueryCache.ttl", flags.Lookup("list-objects-query-cache-ttl"))
+		util.MustBindEnv("listObjectsQueryCache.ttl", "OPENFGA_LIST_OBJECTS_QUERY_CACHE_TTL")
+
 		util.MustBindPFlag("sharedIterator.enabled", flags.Lookup("shared-iterator-enabled"))
 		util.MustBindEnv("sharedIterator.enabled", "OPENFGA_SHARED_ITERATOR_ENABLED")
 
@@ -342,6 +807,39 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("checkDatastoreThrottle.duration", flags.Lookup("check-datastore-throttle-duration"))
 		util.MustBindEnv("checkDatastoreThrottle.duration", "OPENFGA_CHECK_DATASTORE_THROTTLE_DURATION")
 
+		util.MustBindPFlag("checkStoreBulkhead.limit", flags.Lookup("check-store-bulkhead-limit"))
+		util.MustBindEnv("checkStoreBulkhead.limit", "OPENFGA_CHECK_STORE_BULKHEAD_LIMIT")
+
+		util.MustBindPFlag("checkStoreBulkhead.storeLimits", flags.Lookup("check-store-bulkhead-store-limits"))
+		util.MustBindEnv("checkStoreBulkhead.storeLimits", "OPENFGA_CHECK_STORE_BULKHEAD_STORE_LIMITS")
+
+		util.MustBindPFlag("checkCoalescing.enabled", flags.Lookup("check-coalescing-enabled"))
+		util.MustBindEnv("checkCoalescing.enabled", "OPENFGA_CHECK_COALESCING_ENABLED")
+
+		util.MustBindPFlag("checkCoalescing.window", flags.Lookup("check-coalescing-window"))
+		util.MustBindEnv("checkCoalescing.window", "OPENFGA_CHECK_COALESCING_WINDOW")
+
+		util.MustBindPFlag("writeThrottling.tuplesPerSecond", flags.Lookup("write-throttling-tuples-per-second"))
+		util.MustBindEnv("writeThrottling.tuplesPerSecond", "OPENFGA_WRITE_THROTTLING_TUPLES_PER_SECOND")
+
+		util.MustBindPFlag("writeThrottling.changesPerSecond", flags.Lookup("write-throttling-changes-per-second"))
+		util.MustBindEnv("writeThrottling.changesPerSecond", "OPENFGA_WRITE_THROTTLING_CHANGES_PER_SECOND")
+
+		util.MustBindPFlag("writeThrottling.storeTuplesPerSecond", flags.Lookup("write-throttling-store-tuples-per-second"))
+		util.MustBindEnv("writeThrottling.storeTuplesPerSecond", "OPENFGA_WRITE_THROTTLING_STORE_TUPLES_PER_SECOND")
+
+		util.MustBindPFlag("writeThrottling.storeChangesPerSecond", flags.Lookup("write-throttling-store-changes-per-second"))
+		util.MustBindEnv("writeThrottling.storeChangesPerSecond", "OPENFGA_WRITE_THROTTLING_STORE_CHANGES_PER_SECOND")
+
+		util.MustBindPFlag("writeScheduling.enabled", flags.Lookup("write-scheduling-enabled"))
+		util.MustBindEnv("writeScheduling.enabled", "OPENFGA_WRITE_SCHEDULING_ENABLED")
+
+		util.MustBindPFlag("writeScheduling.maxInFlight", flags.Lookup("write-scheduling-max-in-flight"))
+		util.MustBindEnv("writeScheduling.maxInFlight", "OPENFGA_WRITE_SCHEDULING_MAX_IN_FLIGHT")
+
+		util.MustBindPFlag("writeScheduling.storeWeights", flags.Lookup("write-scheduling-store-weights"))
+		util.MustBindEnv("writeScheduling.storeWeights", "OPENFGA_WRITE_SCHEDULING_STORE_WEIGHTS")
+
 		util.MustBindPFlag("listObjectsDatastoreThrottle.threshold", flags.Lookup("listObjects-datastore-throttle-threshold"))
 		util.MustBindEnv("listObjectsDatastoreThrottle.threshold", "OPENFGA_LIST_OBJECTS_DATASTORE_THROTTLE_THRESHOLD")
 
@@ -357,6 +855,9 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("requestTimeout", flags.Lookup("request-timeout"))
 		util.MustBindEnv("requestTimeout", "OPENFGA_REQUEST_TIMEOUT")
 
+		util.MustBindPFlag("methodTimeouts", flags.Lookup("method-timeouts"))
+		util.MustBindEnv("methodTimeouts", "OPENFGA_METHOD_TIMEOUTS")
+
 		util.MustBindPFlag("shutdownTimeout", flags.Lookup("shutdown-timeout"))
 		util.MustBindEnv("shutdownTimeout", "OPENFGA_SHUTDOWN_TIMEOUT")
 
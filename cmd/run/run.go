@@ -4,11 +4,16 @@ package run
 import (
 	"container/list"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/http/pprof"
@@ -39,15 +44,20 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	healthv1pb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -55,33 +65,67 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
 	"github.com/openfga/openfga/assets"
+	"github.com/openfga/openfga/internal/audit"
 	"github.com/openfga/openfga/internal/authn"
 	"github.com/openfga/openfga/internal/authn/oidc"
 	"github.com/openfga/openfga/internal/authn/presharedkey"
 	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/changelog"
+	"github.com/openfga/openfga/internal/cluster"
+	"github.com/openfga/openfga/internal/compression"
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/materialize"
 	authnmw "github.com/openfga/openfga/internal/middleware/authn"
+	"github.com/openfga/openfga/internal/modelrender"
+	"github.com/openfga/openfga/internal/opabundle"
+	"github.com/openfga/openfga/internal/orphans"
 	"github.com/openfga/openfga/internal/planner"
+	"github.com/openfga/openfga/internal/recording"
+	"github.com/openfga/openfga/internal/redaction"
+	"github.com/openfga/openfga/internal/secrets"
 	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/throttler"
+	"github.com/openfga/openfga/pkg/authn/providers"
+	"github.com/openfga/openfga/pkg/checkresolver"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/gateway"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/middleware"
 	httpmiddleware "github.com/openfga/openfga/pkg/middleware/http"
 	"github.com/openfga/openfga/pkg/middleware/logging"
+	"github.com/openfga/openfga/pkg/middleware/priority"
 	"github.com/openfga/openfga/pkg/middleware/recovery"
 	"github.com/openfga/openfga/pkg/middleware/requestid"
+	"github.com/openfga/openfga/pkg/middleware/slo"
 	"github.com/openfga/openfga/pkg/middleware/storeid"
 	"github.com/openfga/openfga/pkg/middleware/validator"
 	"github.com/openfga/openfga/pkg/server"
+	"github.com/openfga/openfga/pkg/server/accessreview"
+	"github.com/openfga/openfga/pkg/server/assertioncoverage"
+	"github.com/openfga/openfga/pkg/server/bulkwrite"
+	"github.com/openfga/openfga/pkg/server/cacheinvalidation"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/server/contexthook"
+	"github.com/openfga/openfga/pkg/server/costestimate"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/server/health"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/server/listobjectssubscription"
+	"github.com/openfga/openfga/pkg/server/modelsimulation"
+	"github.com/openfga/openfga/pkg/server/storeevents"
+	"github.com/openfga/openfga/pkg/server/streamedbatchcheck"
+	"github.com/openfga/openfga/pkg/server/whatif"
+	"github.com/openfga/openfga/pkg/server/writehook"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/caches"
+	"github.com/openfga/openfga/pkg/storage/drivers"
 	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
 	"github.com/openfga/openfga/pkg/storage/sqlite"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
 )
 
 const (
@@ -124,13 +168,63 @@ func updateGRPCCertPool(cert tls.Certificate) {
 	grpcTLSCertPool.Store(pool)
 }
 
-func NewRunCommand() *cobra.Command {
+// ServerContextOption sets the extension points of the ServerContext of the run command, so that a
+// custom main can extend the server without forking OpenFGA.
+type ServerContextOption func(s *ServerContext)
+
+// WithUnaryInterceptors adds interceptors to the unary RPCs of the gRPC server.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) ServerContextOption {
+	return func(s *ServerContext) {
+		s.UnaryInterceptors = append(s.UnaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors adds interceptors to the streaming RPCs of the gRPC server.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) ServerContextOption {
+	return func(s *ServerContext) {
+		s.StreamInterceptors = append(s.StreamInterceptors, interceptors...)
+	}
+}
+
+// WithHTTPMiddlewares adds middlewares to the HTTP server.
+func WithHTTPMiddlewares(middlewares ...func(http.Handler) http.Handler) ServerContextOption {
+	return func(s *ServerContext) {
+		s.HTTPMiddlewares = append(s.HTTPMiddlewares, middlewares...)
+	}
+}
+
+// WithDatastoreWrappers adds wrappers to the datastore of the server.
+func WithDatastoreWrappers(wrappers ...server.DatastoreWrapper) ServerContextOption {
+	return func(s *ServerContext) {
+		s.DatastoreWrappers = append(s.DatastoreWrappers, wrappers...)
+	}
+}
+
+// WithUserIDCodec encodes the IDs of the users of the tuples at rest with codec, e.g. backed by a
+// KMS, instead of the AES key of the config, when the user ID encryption is enabled.
+func WithUserIDCodec(codec storagewrappers.UserIDCodec) ServerContextOption {
+	return func(s *ServerContext) {
+		s.UserIDCodec = codec
+	}
+}
+
+// WithCheckResolvers inserts custom resolvers in the chain of resolvers of the Check sub-problems
+// of the server.
+func WithCheckResolvers(resolvers ...checkresolver.Custom) ServerContextOption {
+	return func(s *ServerContext) {
+		s.CheckResolvers = append(s.CheckResolvers, resolvers...)
+	}
+}
+
+func NewRunCommand(opts ...ServerContextOption) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run the OpenFGA server",
 		Long:  "Run the OpenFGA server.",
-		Run:   run,
-		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			run(opts)
+		},
+		Args: cobra.NoArgs,
 	}
 
 	defaultConfig := serverconfig.DefaultConfig()
@@ -158,6 +252,28 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Int("grpc-max-recv-msg-bytes", defaultConfig.GRPC.MaxRecvMsgBytes, "the maximum size of a received message in bytes")
 
+	flags.Int("grpc-max-send-msg-bytes", defaultConfig.GRPC.MaxSendMsgBytes, "the maximum size of a sent message in bytes, e.g. of an Expand response. The HTTP gateway accepts the responses up to this size too")
+
+	flags.Bool("grpc-compression-enabled", defaultConfig.GRPC.Compression.Enabled, "enable/disable compression of gRPC messages for clients that request it")
+
+	flags.StringSlice("grpc-compression-algorithms", defaultConfig.GRPC.Compression.Algorithms, "the compression algorithms that gRPC clients can use. Allowed values: gzip, zstd")
+
+	flags.Duration("grpc-keepalive-time", defaultConfig.GRPC.Keepalive.Time, "the duration of inactivity of a gRPC connection after which the server pings the client to check that the connection is alive")
+
+	flags.Duration("grpc-keepalive-timeout", defaultConfig.GRPC.Keepalive.Timeout, "the duration the gRPC server waits for the acknowledgement of a keepalive ping before it closes the connection")
+
+	flags.Duration("grpc-keepalive-min-time", defaultConfig.GRPC.Keepalive.MinTime, "the minimum duration that the gRPC clients should wait between their keepalive pings. The connections of the clients that ping more often are closed")
+
+	flags.Bool("grpc-keepalive-permit-without-stream", defaultConfig.GRPC.Keepalive.PermitWithoutStream, "allow the gRPC clients to send keepalive pings when there are no active streams on their connection")
+
+	flags.Duration("grpc-max-connection-idle", defaultConfig.GRPC.MaxConnectionIdle, "the duration after which an idle gRPC connection is closed (0 means never)")
+
+	flags.Duration("grpc-max-connection-age", defaultConfig.GRPC.MaxConnectionAge, "the maximum age of a gRPC connection, after which the client is asked to reconnect, so that the connections are rebalanced over the replicas behind a load balancer (0 means no maximum age)")
+
+	flags.Duration("grpc-max-connection-age-grace", defaultConfig.GRPC.MaxConnectionAgeGrace, "the duration given to the requests in flight on a gRPC connection that reached its maximum age before it is closed (0 means the requests are never interrupted)")
+
+	flags.Uint32("grpc-max-concurrent-streams", defaultConfig.GRPC.MaxConcurrentStreams, "the maximum number of concurrent streams, i.e. requests, of a gRPC connection (0 means no limit)")
+
 	flags.Bool("http-enabled", defaultConfig.HTTP.Enabled, "enable/disable the OpenFGA HTTP server")
 
 	flags.String("http-addr", defaultConfig.HTTP.Addr, "the host:port address to serve the HTTP server on")
@@ -172,13 +288,223 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("http-upstream-timeout", defaultConfig.HTTP.UpstreamTimeout, "the timeout duration for proxying HTTP requests upstream to the grpc endpoint")
 
+	flags.Int("http-max-request-body-bytes", defaultConfig.HTTP.MaxRequestBodyBytes, "the maximum size in bytes of the body of an HTTP request. Larger requests are rejected with a 413 status (0 means no limit other than the maximum size of a received gRPC message)")
+
 	flags.StringSlice("http-cors-allowed-origins", defaultConfig.HTTP.CORSAllowedOrigins, "specifies the CORS allowed origins")
 
 	flags.StringSlice("http-cors-allowed-headers", defaultConfig.HTTP.CORSAllowedHeaders, "specifies the CORS allowed headers")
 
+	flags.StringSlice("http-cors-allowed-methods", defaultConfig.HTTP.CORSAllowedMethods, "specifies the CORS allowed methods")
+
+	flags.StringSlice("http-cors-exposed-headers", defaultConfig.HTTP.CORSExposedHeaders, "specifies the response headers exposed to the CORS requests in addition to the CORS-safelisted ones, e.g. Server-Timing")
+
+	flags.Duration("http-cors-max-age", defaultConfig.HTTP.CORSMaxAge, "the duration, e.g. 10m, for which the browsers cache the responses of the CORS preflight requests, at least 1s (0 means the default of the browsers)")
+
+	flags.Bool("http-cors-allow-credentials", defaultConfig.HTTP.CORSAllowCredentials, "allow the CORS requests to include credentials, e.g. cookies or an Authorization header")
+
+	flags.Bool("http-compression-enabled", defaultConfig.HTTP.Compression.Enabled, "enable/disable compression of HTTP responses for clients that send an Accept-Encoding header")
+
+	flags.StringSlice("http-compression-algorithms", defaultConfig.HTTP.Compression.Algorithms, "the compression algorithms that can be negotiated with HTTP clients, in order of preference. Allowed values: gzip, zstd")
+
+	flags.Int("http-compression-min-size-bytes", defaultConfig.HTTP.Compression.MinSizeBytes, "the minimum size in bytes of an HTTP response body before it is compressed. Streamed responses are always compressed")
+
+	flags.Bool("http-server-timing-enabled", defaultConfig.HTTP.ServerTimingEnabled, "enable/disable the Server-Timing header on Check and BatchCheck responses, reporting the resolution time, the datastore time and the cache status")
+
 	flags.String("authzen-base-url", defaultConfig.Authzen.BaseURL, "the canonical absolute base URL to publish in AuthZEN discovery metadata")
 
-	flags.String("authn-method", defaultConfig.Authn.Method, "the authentication method to use")
+	flags.Bool("opa-bundle-enabled", defaultConfig.OPABundle.Enabled, "enable/disable the HTTP endpoint (/stores/{store_id}/opa/bundle) that exports the tuples of a store as OPA bundles")
+
+	flags.Int("opa-bundle-max-tuples", defaultConfig.OPABundle.MaxTuples, "the maximum number of tuples of a store that can be exported in an OPA bundle snapshot. 0 means no limit")
+
+	flags.Int("opa-bundle-max-delta-changes", defaultConfig.OPABundle.MaxDeltaChanges, "the maximum number of changes served in an OPA delta bundle. A snapshot is served when more tuples changed")
+
+	flags.Bool("changelog-retention-enabled", defaultConfig.ChangelogRetention.Enabled, "enable/disable the background job that deletes old changes from the changelog of each store")
+
+	flags.Duration("changelog-retention-interval", defaultConfig.ChangelogRetention.Interval, "the time between two runs of the changelog retention job")
+
+	flags.Duration("changelog-retention-max-age", defaultConfig.ChangelogRetention.MaxAge, "the age beyond which changes are deleted from the changelog. 0 means no age limit")
+
+	flags.Uint32("changelog-retention-max-entries", defaultConfig.ChangelogRetention.MaxEntries, "the number of most recent changes kept in the changelog of each store. 0 means no limit")
+
+	flags.Duration("changelog-retention-cursor-lease", defaultConfig.ChangelogRetention.CursorLease, "how long after it was handed out a ReadChanges continuation token keeps the changes it has yet to read from being deleted")
+
+	flags.StringSlice("changelog-retention-store-policies", defaultConfig.ChangelogRetention.StorePolicies, "per-store overrides of the changelog retention, in the form <store_id>=<max_age>:<max_entries> (e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=720h:1000)")
+
+	flags.Bool("orphaned-tuples-endpoint-enabled", defaultConfig.OrphanedTuples.EndpointEnabled, "enable/disable the HTTP endpoint that reports or deletes the tuples of a store that reference types, relations or conditions missing from its latest model")
+
+	flags.Bool("orphaned-tuples-collector-enabled", defaultConfig.OrphanedTuples.CollectorEnabled, "enable/disable the background job that collects the tuples of every store that reference types, relations or conditions missing from its latest model")
+
+	flags.Duration("orphaned-tuples-collector-interval", defaultConfig.OrphanedTuples.CollectorInterval, "the time between two runs of the orphaned tuples collector")
+
+	flags.Bool("orphaned-tuples-collector-dry-run", defaultConfig.OrphanedTuples.CollectorDryRun, "only log the orphaned tuples found by the collector instead of deleting them")
+
+	flags.Bool("consistency-verifier-enabled", defaultConfig.ConsistencyVerifier.Enabled, "enable/disable the background job that samples the tuples of each store and reports duplicate tuples, changelog/tuple disagreements and Check cache/datastore disagreements")
+
+	flags.Duration("consistency-verifier-interval", defaultConfig.ConsistencyVerifier.Interval, "the time between two runs of the consistency verifier")
+
+	flags.Int("consistency-verifier-sample-size", defaultConfig.ConsistencyVerifier.SampleSize, "the number of tuples, and the number of changes, verified per store on each run of the consistency verifier")
+
+	flags.Duration("shadow-check-timeout", defaultConfig.ShadowCheck.Timeout, "the amount of time to wait for the shadow evaluation of a Check enabled by the 'shadow_check' or 'shadow_weighted_graph_check' experimental flags")
+
+	flags.Int("shadow-check-sample-percentage", defaultConfig.ShadowCheck.SamplePercentage, "the percentage of the Checks that are also evaluated by the shadow resolvers enabled by the 'shadow_check' or 'shadow_weighted_graph_check' experimental flags")
+
+	flags.Bool("request-recording-enabled", defaultConfig.RequestRecording.Enabled, "enable/disable the recording of a sample of the Check and ListObjects requests, which can be replayed against another server with 'openfga replay'")
+
+	flags.String("request-recording-path", defaultConfig.RequestRecording.Path, "the file the recorded requests are appended to")
+
+	flags.Int("request-recording-sample-percentage", defaultConfig.RequestRecording.SamplePercentage, "the percentage of the Check and ListObjects requests that are recorded")
+
+	flags.Bool("request-recording-anonymize", defaultConfig.RequestRecording.Anonymize, "replace the object and user IDs, and the string values of the condition contexts, of the recorded requests with keyed pseudonyms")
+
+	flags.String("request-recording-anonymization-key", defaultConfig.RequestRecording.AnonymizationKey, "the key the pseudonyms of the recorded requests are derived from. If empty, a random key is generated on startup")
+
+	flags.Bool("hot-paths-enabled", defaultConfig.HotPaths.Enabled, "enable/disable the tracking of the (object type, relation) pairs checked the most in each store, and the background precomputation of their membership, so that their Checks are answered with a single lookup")
+
+	flags.Duration("hot-paths-interval", defaultConfig.HotPaths.Interval, "the time between two precomputations of the hot paths. A precomputed membership is used for up to twice this duration")
+
+	flags.Int("hot-paths-top-n", defaultConfig.HotPaths.TopN, "the number of (object type, relation) pairs per store precomputed on each run")
+
+	flags.Int("hot-paths-min-checks", defaultConfig.HotPaths.MinChecks, "the number of Checks an (object type, relation) pair must receive during an interval to be precomputed")
+
+	flags.Int("hot-paths-max-objects", defaultConfig.HotPaths.MaxObjects, "the maximum number of objects of a type whose membership is precomputed")
+
+	flags.Bool("materialized-permissions-enabled", defaultConfig.MaterializedPermissions.Enabled, "enable/disable the denormalized (user, relation, object) permissions maintained in the datastore for the relations of materialized-permissions-relations, so that their Checks are answered with a single row lookup")
+
+	flags.StringSlice("materialized-permissions-relations", defaultConfig.MaterializedPermissions.Relations, "the relations whose permissions are materialized, in the form <store_id>=<object_type>#<relation>. Only the relations without intersections, exclusions and conditions are materialized")
+
+	flags.Duration("materialized-permissions-interval", defaultConfig.MaterializedPermissions.Interval, "the time between two updates of the materialized permissions from the changelog")
+
+	flags.Bool("materialized-object-lists-enabled", defaultConfig.MaterializedObjectLists.Enabled, "enable/disable the in-memory materialization of the ListObjects results of the (user, relation, object type) triples requested the most in each store, kept up to date from the changelog, so that their ListObjects requests are answered with a single lookup")
+
+	flags.Duration("materialized-object-lists-interval", defaultConfig.MaterializedObjectLists.Interval, "the time between two updates of the materialized object lists from the changelog")
+
+	flags.Int("materialized-object-lists-top-n", defaultConfig.MaterializedObjectLists.TopN, "the number of (user, relation, object type) triples per store materialized on each update")
+
+	flags.Int("materialized-object-lists-min-requests", defaultConfig.MaterializedObjectLists.MinRequests, "the number of ListObjects requests a (user, relation, object type) triple must receive during an interval to be materialized")
+
+	flags.StringSlice("cluster-peers", defaultConfig.Cluster.Peers, "the gRPC addresses of the replicas of the server, e.g. the addresses of the pods of a StatefulSet")
+
+	flags.String("cluster-self-address", defaultConfig.Cluster.SelfAddress, "the gRPC address of this replica, as listed in cluster-peers")
+
+	flags.Bool("cluster-singleflight-enabled", defaultConfig.Cluster.SingleflightEnabled, "enable/disable the forwarding of every Check to the replica of cluster-peers that owns it, so that identical Checks received concurrently by different replicas are resolved once")
+
+	flags.Duration("cluster-forward-timeout", defaultConfig.Cluster.ForwardTimeout, "the maximum duration of a Check forwarded to another replica, after which the Check is resolved locally")
+
+	flags.Bool("cluster-tls-enabled", defaultConfig.Cluster.TLSEnabled, "enable/disable TLS on the connections to the other replicas")
+
+	flags.Bool("cluster-dispatch-enabled", defaultConfig.Cluster.DispatchEnabled, "enable/disable the dispatch of the sub-problems of every Check to the replica that owns them, so that the cache of every replica specializes in the sub-problems it owns")
+
+	flags.Bool("cluster-dispatch-service-enabled", defaultConfig.Cluster.DispatchServiceEnabled, "enable/disable the internal service that resolves the Checks dispatched by the other replicas, e.g. on the replicas of a dispatch tier. It is always enabled with cluster-dispatch-enabled")
+
+	flags.StringSlice("cluster-dispatch-tier", defaultConfig.Cluster.DispatchTier, "the gRPC addresses of the replicas of a dedicated tier that resolves the Checks of this replica through its dispatch service")
+
+	flags.String("cluster-gossip-bind-address", defaultConfig.Cluster.Gossip.BindAddress, "the address on which this replica gossips with the other replicas to discover them, e.g. ':7946'. Gossip is disabled if empty")
+
+	flags.StringSlice("cluster-gossip-join", defaultConfig.Cluster.Gossip.Join, "the gossip addresses of the replicas through which this replica joins the cluster")
+
+	flags.Bool("priority-scheduling-enabled", defaultConfig.PriorityScheduling.Enabled, "enable/disable the scheduling of the requests by priority class when the server is saturated, so that interactive requests are handled before bulk ones")
+
+	flags.Int("priority-scheduling-max-in-flight", defaultConfig.PriorityScheduling.MaxInFlight, "the number of requests handled at once when priority scheduling is enabled. The other requests are queued")
+
+	flags.StringSlice("priority-scheduling-method-classes", defaultConfig.PriorityScheduling.MethodClasses, "the priority class of RPC methods, in the form <method>=<class> where the class is interactive or bulk. The methods not listed are interactive, and a request can set its class with the Openfga-Priority header")
+
+	flags.StringSlice("priority-scheduling-store-weights", defaultConfig.PriorityScheduling.StoreWeights, "the share of the stores among the queued requests of a class, in the form <store_id>=<weight>. The stores not listed have a weight of 1")
+
+	flags.Bool("batch-check-adaptive-limits-enabled", defaultConfig.BatchCheckAdaptiveLimits.Enabled, "enable/disable the reduction of the BatchCheck limits when the load of the priority scheduler is high. The BatchChecks with more checks than the reduced limit fail with a REDUCE_BATCH_SIZE error. Requires priority scheduling")
+
+	flags.Float64("batch-check-adaptive-limits-load-threshold", defaultConfig.BatchCheckAdaptiveLimits.LoadThreshold, "the load of the priority scheduler, i.e. the requests in flight and queued relative to the maximum in flight, above which the BatchCheck limits are reduced")
+
+	flags.Float64("batch-check-adaptive-limits-min-ratio", defaultConfig.BatchCheckAdaptiveLimits.MinRatio, "the ratio of the BatchCheck limits when the priority scheduler is saturated")
+
+	flags.Bool("streamed-batch-check-enabled", defaultConfig.StreamedBatchCheck.Enabled, "enable/disable the streamed BatchCheck service, which streams the outcome of each check of a BatchCheck as soon as it is evaluated, in the order in which they complete, with its duration and the time elapsed since the batch started (also served on '/stores/{store_id}/streamed-batch-check')")
+
+	flags.Bool("bulk-write-enabled", defaultConfig.BulkWrite.Enabled, "enable/disable the bulk write service: its bidirectional streaming Write for bulk loads (gRPC only), on which clients stream chunks of tuples and receive an acknowledgement per chunk, and its DeleteTuples, which deletes the tuples matching a filter in batches (also served on DELETE /stores/{store_id}/tuples)")
+
+	flags.Int("bulk-write-window", defaultConfig.BulkWrite.Window, "the number of chunks of a bulk write stream received ahead of the chunk being written")
+
+	flags.Bool("store-purge-enabled", defaultConfig.StorePurge.Enabled, "enable/disable the purge jobs that delete the tuples of the deleted stores in batches. Requires the jobs service, on which jobs deleting the tuples matching a filter are created too")
+
+	flags.Duration("store-purge-job-retention", defaultConfig.StorePurge.JobRetention, "the duration for which the finished purge jobs are reported")
+	_ = flags.MarkDeprecated("store-purge-job-retention", "the purge jobs are kept for --jobs-retention")
+
+	flags.Bool("store-events-enabled", defaultConfig.StoreEvents.Enabled, "enable/disable the store events service, which reads the changelog of a store including its authorization model writes, assertions writes, creation and deletion along with its tuple changes (also served on '/stores/{store_id}/events')")
+
+	flags.Bool("list-objects-subscription-enabled", defaultConfig.ListObjectsSubscription.Enabled, "enable/disable the ListObjects subscription service, which streams the objects of a ListObjects and then the objects added to and removed from them as the writes change them (also served on '/stores/{store_id}/list-objects/subscribe')")
+
+	flags.Duration("list-objects-subscription-poll-interval", defaultConfig.ListObjectsSubscription.PollInterval, "the time between two reads of the changelog of the store of a ListObjects subscription")
+
+	flags.Bool("cache-hints-enabled", defaultConfig.CacheHints.Enabled, "enable/disable the Cache-Control and Openfga-Invalidation-Keys headers of the Check responses, with which the clients can cache them, and the cache invalidation service, which streams the invalidation keys of the writes to a store (also served on '/stores/{store_id}/cache-invalidations')")
+
+	flags.Duration("cache-hints-max-age", defaultConfig.CacheHints.MaxAge, "the maximum max-age of the Check responses. Their max-age is derived from the rate of the writes to their store")
+
+	flags.Duration("cache-hints-invalidation-poll-interval", defaultConfig.CacheHints.InvalidationPollInterval, "the time between two reads of the changelog of the store of a cache invalidation stream")
+
+	flags.Bool("session-tokens-enabled", defaultConfig.SessionTokens.Enabled, "enable/disable the read-your-writes session tokens. Write returns an Openfga-Session-Token header, and the Read, Check, ListObjects and StreamedListObjects requests of the same store that set it are served with HIGHER_CONSISTENCY until the cache controller has observed a later change of the store. The cache controller then reads the most recent change of a store each time it reads its changelog")
+
+	flags.Duration("session-tokens-commit-margin", defaultConfig.SessionTokens.CommitMargin, "how much the time of the most recent change observed by the cache controller must exceed that of the change of a write for the write to be known to be observed. It must exceed the clock skew between the instances and the duration of the writes")
+
+	flags.Bool("assertion-coverage-enabled", defaultConfig.AssertionCoverage.Enabled, "enable/disable the assertion coverage service, which reports which relations of an authorization model, and which branches of their rewrites, are exercised by its assertions (also served on '/stores/{store_id}/assertion-coverage')")
+
+	flags.Bool("model-graph-enabled", defaultConfig.ModelGraph.Enabled, "enable/disable the model graph endpoint '/stores/{store_id}/model-graph', which renders an authorization model as a DOT or Mermaid graph of its types, with the rewrites of their relations as edges")
+
+	flags.Bool("cost-estimate-enabled", defaultConfig.CostEstimate.Enabled, "enable/disable the cost estimate service, which estimates the number of datastore queries and dispatches of a Check or a ListObjects from the structure of the model and the cardinality of the tuples, without executing it (also served on '/stores/{store_id}/check/estimate' and '/stores/{store_id}/list-objects/estimate')")
+
+	flags.Int("cost-estimate-sample-size", defaultConfig.CostEstimate.SampleSize, "the maximum number of tuples of each type sampled by the cost estimates for the cardinality of its relations")
+
+	flags.Bool("what-if-enabled", defaultConfig.WhatIf.Enabled, "enable/disable the what-if service, which evaluates a Check or a BatchCheck as if a set of tuple writes and deletes had been applied to the store, and reports whether the changes change their results (also served on '/stores/{store_id}/what-if/check' and '/stores/{store_id}/what-if/batch-check')")
+
+	flags.Bool("model-simulation-enabled", defaultConfig.ModelSimulation.Enabled, "enable/disable the model simulation service, which evaluates a set of Check requests, e.g. recorded ones, with both the current authorization model of a store and a proposed one, and reports the checks whose outcome would change (also served on '/stores/{store_id}/model-simulation', see `openfga simulate-model`)")
+
+	flags.Bool("access-review-enabled", defaultConfig.AccessReview.Enabled, "enable/disable the access_review jobs, which export all the users that have a relation on all the objects of a type to downloadable artifacts, and the access review service that downloads them (also served on '/stores/{store_id}/jobs/{job_id}/artifact'). Requires the jobs service")
+
+	flags.String("access-review-artifact-dir", defaultConfig.AccessReview.ArtifactDir, "the directory the artifacts of the access review jobs are written to (defaults to a directory in the temporary directory of the OS)")
+
+	flags.Duration("access-review-job-retention", defaultConfig.AccessReview.JobRetention, "the duration for which the finished access review jobs, and their artifacts, are kept")
+	_ = flags.MarkDeprecated("access-review-job-retention", "the access review jobs and their artifacts are kept for --jobs-retention")
+
+	flags.Bool("jobs-enabled", defaultConfig.Jobs.Enabled, "enable/disable the jobs service, which runs the long operations of the stores, such as purges or the collection of the orphaned tuples, as background jobs whose status is persisted in the datastore, and creates, reports and cancels them (also served on '/stores/{store_id}/jobs')")
+
+	flags.Int("jobs-workers", defaultConfig.Jobs.Workers, "the number of background jobs that each replica runs concurrently")
+
+	flags.Int("jobs-queue-size", defaultConfig.Jobs.QueueSize, "the number of background jobs that each replica queues before refusing to create more")
+
+	flags.Duration("jobs-retention", defaultConfig.Jobs.Retention, "the duration for which the finished background jobs are kept")
+
+	flags.Duration("jobs-schedule-interval", defaultConfig.Jobs.ScheduleInterval, "the interval at which the replica that holds the lease of the scheduler creates the jobs of the due schedules of recurring jobs")
+
+	flags.Bool("multi-region-enabled", defaultConfig.MultiRegion.Enabled, "enable/disable the active-active multi-region mode, in which each region writes to its replica of a datastore replicated in both directions: the tuples are written with last-writer-wins semantics and the ULIDs of their changes encode the region (see docs/multi-region.md)")
+
+	flags.Int("multi-region-id", defaultConfig.MultiRegion.RegionID, "the ID of the region of the deployment, between 1 and 255, unique across the regions writing to the same datastore")
+
+	flags.Bool("write-validation-hook-enabled", defaultConfig.WriteValidationHook.Enabled, "enable/disable the submission of every authorized Write to an external service that can veto it or annotate it according to business rules")
+
+	flags.String("write-validation-hook-url", defaultConfig.WriteValidationHook.URL, "the URL of the write validation hook: an http or https URL to which the Writes are posted as JSON, or a grpc URL (e.g. 'grpc://validator:50051') of a server of the openfga.writehook.v1.WriteValidationService")
+
+	flags.Duration("write-validation-hook-timeout", defaultConfig.WriteValidationHook.Timeout, "the timeout of the calls to the write validation hook")
+
+	flags.Bool("write-validation-hook-fail-open", defaultConfig.WriteValidationHook.FailOpen, "apply the Writes when the write validation hook fails or times out, instead of rejecting them")
+
+	flags.Bool("context-enrichment-hook-enabled", defaultConfig.ContextEnrichmentHook.Enabled, "enable/disable the enrichment of the condition context of the Check and ListObjects requests with the attributes of their user and object fetched from an external service")
+
+	flags.String("context-enrichment-hook-url", defaultConfig.ContextEnrichmentHook.URL, "the URL of the context enrichment hook: an http or https URL to which the users and objects are posted as JSON, or a grpc URL (e.g. 'grpc://attributes:50051') of a server of the openfga.contexthook.v1.ContextEnrichmentService")
+
+	flags.Duration("context-enrichment-hook-timeout", defaultConfig.ContextEnrichmentHook.Timeout, "the timeout of the calls to the context enrichment hook")
+
+	flags.Duration("context-enrichment-hook-cache-ttl", defaultConfig.ContextEnrichmentHook.CacheTTL, "the duration for which the attributes fetched from the context enrichment hook are cached (0 disables the cache)")
+
+	flags.Int64("context-enrichment-hook-cache-max-entries", defaultConfig.ContextEnrichmentHook.CacheMaxEntries, "the maximum number of users and objects whose attributes fetched from the context enrichment hook are cached")
+
+	flags.StringSlice("condition-context-headers", defaultConfig.ConditionContext.Headers, "the HTTP headers, or gRPC metadata, whose values are injected into the condition context of the Check, BatchCheck, ListObjects and ListUsers requests, in the form <header>=<name> (e.g. 'X-Client-Region=client_region')")
+
+	flags.StringSlice("condition-context-claims", defaultConfig.ConditionContext.Claims, "the claims of the principals authenticated with OIDC whose values are injected into the condition context of the Check, BatchCheck, ListObjects and ListUsers requests, in the form <claim>=<name> (e.g. 'department=caller_department') or <claim>")
+
+	flags.Bool("slo-enabled", defaultConfig.SLO.Enabled, "enable/disable the tracking of the service level objectives, exported as error budget and burn rate metrics and summarized on the '/slo' endpoint of the metrics server")
+
+	flags.StringSlice("slo-objectives", defaultConfig.SLO.Objectives, "the service level objectives, in the form <method>=latency:<threshold>:<target> or <method>=availability:<target> where the target is a percentage of the requests, e.g. Check=latency:50ms:99")
+
+	flags.Duration("slo-window", defaultConfig.SLO.Window, "the rolling window of the error budgets of the service level objectives")
+
+	flags.String("authn-method", defaultConfig.Authn.Method, "the authentication method to use: none, preshared, oidc, or the name of an authentication provider registered with the providers package")
 
 	flags.StringSlice("authn-preshared-keys", defaultConfig.Authn.Keys, "one or more preshared keys to use for authentication")
 
@@ -192,7 +518,7 @@ func NewRunCommand() *cobra.Command {
 
 	flags.StringSlice("authn-oidc-client-id-claims", defaultConfig.Authn.ClientIDClaims, "the ClientID claims that will be used to parse the clientID - configure in order of priority (first is highest). Defaults to [`azp`, `client_id`]")
 
-	flags.String("datastore-engine", defaultConfig.Datastore.Engine, "the datastore engine that will be used for persistence")
+	flags.String("datastore-engine", defaultConfig.Datastore.Engine, "the datastore engine that will be used for persistence: memory, postgres, mysql, sqlite, or the name of a storage driver registered with the drivers package")
 
 	flags.String("datastore-uri", defaultConfig.Datastore.URI, "the connection uri to use to connect to the datastore (for any engine other than 'memory')")
 
@@ -210,6 +536,12 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Int("datastore-max-typesystem-cache-size", defaultConfig.Datastore.MaxTypesystemCacheSize, "the maximum number of type system models that will be cached in memory")
 
+	flags.Duration("datastore-latest-model-cache-ttl", defaultConfig.Datastore.LatestModelCacheTTL, "the duration that the ID of the latest authorization model of a store is cached for, so that the requests without an authorization model ID don't look it up in the datastore. Writing a model invalidates the ID of its store. If 0, it is not cached")
+
+	flags.String("datastore-typesystem-compilation-cache-engine", defaultConfig.Datastore.TypesystemCompilationCacheEngine, "a registered check cache engine shared by the instances of OpenFGA, that holds the artifacts of the validation and compilation of the authorization models, so that the instances that start don't compile the large models again. If empty, the models are compiled by each instance")
+
+	flags.String("datastore-latest-model-cache-engine", defaultConfig.Datastore.LatestModelCacheEngine, "the cache that holds the IDs of the latest authorization models: 'memory', or a registered check cache engine shared by the instances of OpenFGA, so that a model written through one of them invalidates the ID in all of them")
+
 	flags.Int("datastore-min-open-conns", defaultConfig.Datastore.MinOpenConns, "the minimum number of open connections to the datastore")
 
 	flags.Int("datastore-max-open-conns", defaultConfig.Datastore.MaxOpenConns, "the maximum number of open connections to the datastore")
@@ -224,6 +556,20 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Bool("datastore-metrics-enabled", defaultConfig.Datastore.Metrics.Enabled, "enable/disable sql metrics")
 
+	flags.Bool("datastore-index-advisor-enabled", defaultConfig.Datastore.IndexAdvisor.Enabled, "enable/disable the index advisor of the postgres and mysql datastores, which EXPLAINs the slow tuple queries and logs the ones that scan a table fully with a suggested composite index")
+
+	flags.Duration("datastore-index-advisor-slow-query-threshold", defaultConfig.Datastore.IndexAdvisor.SlowQueryThreshold, "if datastore-index-advisor-enabled, the duration from which a tuple query is slow and explained")
+
+	flags.Bool("datastore-reverse-user-index-enabled", defaultConfig.Datastore.ReverseUserIndex.Enabled, "enable/disable the reverse user index of the memory, postgres and mysql datastores, which maintains an index of the tuples by user on write so that the reads of the tuples of the users by ListObjects are index lookups. The index of the postgres and mysql datastores is built by 'openfga migrate --reverse-user-index=enabled', and the instances refuse to start unless it matches the datastore")
+
+	flags.Bool("datastore-maintenance-tuning-enabled", defaultConfig.Datastore.MaintenanceTuning.Enabled, "enable/disable applying the recommended autovacuum and analyze settings of postgres, or the statistics settings of mysql, to the tuple, changelog and reverse user index tables on startup. The maintenance state of the tables is served on the metrics server at /datastore/maintenance")
+
+	flags.String("datastore-iam-auth-method", defaultConfig.Datastore.IAMAuth.Method, "the IAM authentication method of the connections of the postgres and mysql datastores, 'aws_rds' or 'gcp_cloudsql'. The connections authenticate with short-lived tokens instead of the password: the RDS IAM authentication tokens signed with the default AWS credential chain, or the access tokens of the service account of the instance from the GCP metadata server. The tokens are refreshed before they expire. MySQL sends them in cleartext, so the connections must use TLS")
+
+	flags.String("datastore-iam-auth-region", defaultConfig.Datastore.IAMAuth.Region, "the AWS region of the database of the 'aws_rds' IAM authentication method (default the region of the AWS configuration, e.g. the AWS_REGION environment variable)")
+
+	flags.Bool("datastore-transaction-pooling-enabled", defaultConfig.Datastore.TransactionPooling.Enabled, "enable/disable the compatibility of the postgres datastore with PgBouncer in transaction pooling mode, by avoiding the session-level state of the connections: the queries are sent with the simple protocol instead of being prepared as statements of the connections. It can't be combined with the cache invalidation listener, whose replication connection isn't pooled")
+
 	flags.Bool("playground-enabled", defaultConfig.Playground.Enabled, "enable/disable the OpenFGA Playground")
 
 	flags.Int("playground-port", defaultConfig.Playground.Port, "the port to serve the local OpenFGA Playground on") //nolint:staticcheck
@@ -252,6 +598,12 @@ func NewRunCommand() *cobra.Command {
 
 	flags.String("trace-resource-attributes", defaultConfig.Trace.ResourceAttributes, "key-value pairs to be used as resource attributes")
 
+	flags.Bool("trace-verbose-requests-enabled", defaultConfig.Trace.VerboseRequestsEnabled, "sample all the spans of the requests with the 'Openfga-Trace-Verbose: true' header, regardless of the sample ratio. The trace ID of these requests is returned in the 'Openfga-Trace-Id' response header")
+
+	flags.StringSlice("trace-store-sample-ratios", defaultConfig.Trace.StoreSampleRatios, "per-store overrides of the trace sample ratio, in the form <store_id>=<ratio> (e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=1)")
+
+	flags.StringSlice("trace-method-sample-ratios", defaultConfig.Trace.MethodSampleRatios, "per-method overrides of the trace sample ratio, in the form <method>=<ratio> (e.g. Check=0.001). The per-store overrides take precedence")
+
 	flags.Bool("metrics-enabled", defaultConfig.Metrics.Enabled, "enable/disable prometheus metrics on the '/metrics' endpoint")
 
 	flags.String("metrics-addr", defaultConfig.Metrics.Addr, "the host:port address to serve the prometheus metrics server on")
@@ -264,6 +616,10 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Int("max-tuples-per-write", defaultConfig.MaxTuplesPerWrite, "the maximum allowed number of tuples per Write transaction")
 
+	flags.Uint32("max-contextual-tuples", defaultConfig.MaxContextualTuples, "the maximum allowed number of contextual tuples per Check, BatchCheck item, Expand, ListObjects or ListUsers request")
+
+	flags.String("contextual-tuples-validation-mode", defaultConfig.ContextualTuplesValidationMode, "how contextual tuples that reference types not defined in the model are handled. 'strict' rejects the request, 'lenient' ignores those tuples")
+
 	flags.Int("max-types-per-authorization-model", defaultConfig.MaxTypesPerAuthorizationModel, "the maximum allowed number of type definitions per authorization model")
 
 	flags.Int("max-authorization-model-size-in-bytes", defaultConfig.MaxAuthorizationModelSizeInBytes, "the maximum size in bytes allowed for persisting an Authorization Model.")
@@ -276,6 +632,8 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Uint64("max-condition-evaluation-cost", defaultConfig.MaxConditionEvaluationCost, "the maximum cost for CEL condition evaluation before a request returns an error")
 
+	flags.StringSlice("condition-extensions", defaultConfig.ConditionExtensions, fmt.Sprintf("a comma-separated list of the optional CEL libraries to make available to the conditions. Allowed values: %s", strings.Join(condition.ExtensionNames(), ", ")))
+
 	flags.Int("changelog-horizon-offset", defaultConfig.ChangelogHorizonOffset, "the offset (in minutes) from the current time. Changes that occur after this offset will not be included in the response of ReadChanges")
 
 	flags.Uint32("resolve-node-limit", defaultConfig.ResolveNodeLimit, "maximum resolution depth to attempt before throwing an error (defines how deeply nested an authorization model can be before a query errors out).")
@@ -288,14 +646,26 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Bool("listObjects-pipeline-enabled", defaultConfig.ListObjectsPipelineEnabled, "enabling the ListObjects pipeline optimization algorithm, which can significantly improve the latency of ListObjects requests. When enabled, the server will attempt to resolve intermediate nodes in the ListObjects resolution tree concurrently. This optimization is most effective for workloads with large and complex authorization models, but may not suit all cases. Can be disabled if it causes increased resource usage.")
 
+	flags.Uint32("streamedListObjects-max-in-flight-messages", defaultConfig.StreamedListObjectsMaxInFlightMessages, "the maximum number of resolved objects that can be buffered before they are sent to a StreamedListObjects client. Once the buffer is full, resolution waits for the client")
+
+	flags.Uint32("streamedListObjects-messages-per-second", defaultConfig.StreamedListObjectsMessagesPerSecond, "the maximum rate at which objects are sent on each StreamedListObjects stream. If 0, the rate is not limited")
+
 	flags.Duration("listUsers-deadline", defaultConfig.ListUsersDeadline, "the timeout deadline for serving ListUsers requests. If 0, there is no deadline")
 
 	flags.Uint32("listUsers-max-results", defaultConfig.ListUsersMaxResults, "the maximum results to return in ListUsers API responses. If 0, all results can be returned")
 
 	flags.Uint32("readChanges-max-page-size", defaultConfig.ReadChangesMaxPageSize, "the maximum page size allowed for ReadChanges API requests")
 
+	flags.String("check-cache-engine", defaultConfig.CheckCache.Engine, "if check-query-cache-enabled or check-iterator-cache-enabled, the cache that holds the cached Check results and iterators: memory, or the name of a cache registered with the caches package")
+
 	flags.Uint32("check-cache-limit", defaultConfig.CheckCache.Limit, "if check-query-cache-enabled or check-iterator-cache-enabled, this is the size limit of the cache")
 
+	flags.Uint64("check-cache-max-bytes", defaultConfig.CheckCache.MaxBytes, "if check-query-cache-enabled or check-iterator-cache-enabled, the maximum memory in bytes held by the entries of the cache. Entries are evicted by size rather than by count, which bounds the memory of caches holding large iterator results. If 0, check-cache-limit applies")
+
+	flags.Uint32("check-cache-shards", defaultConfig.CheckCache.Shards, "if check-query-cache-enabled or check-iterator-cache-enabled, the number of shards of the memory cache, each with its own lock and an equal part of the limit, which reduces the lock contention of the cache at high throughput. If 0, the cache has one shard per CPU")
+
+	flags.String("check-cache-disk-path", defaultConfig.CheckCache.DiskPath, "if check-query-cache-enabled, the file the cached Check results are also persisted to, so that they survive a restart of the server. If empty, the cache is only kept in memory")
+
 	flags.Bool("shared-iterator-enabled", defaultConfig.SharedIterator.Enabled, "enabling sharing of datastore iterators with different consumers. Each iterator is the result of a database query, for example usersets related to a specific object, or objects related to a specific user, up to a certain number of tuples per iterator.")
 
 	flags.Uint32("shared-iterator-limit", defaultConfig.SharedIterator.Limit, "if shared-iterator-enabled is enabled, this is the limit of the number of iterators that can be shared.")
@@ -312,6 +682,12 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("list-objects-iterator-cache-ttl", defaultConfig.ListObjectsIteratorCache.TTL, "if caching of datastore iterators of ListObjects requests is enabled, this is the TTL of each value")
 
+	flags.Bool("list-objects-query-cache-enabled", defaultConfig.ListObjectsQueryCache.Enabled, "enable caching of ListObjects responses. The key is the store, authorization model, user, relation, type, contextual tuples and context of a request, and the value is the list of objects. A cached response is returned until the cache controller, if enabled, finds a write to the store, or until the configured TTL. If the request's consistency is HIGHER_CONSISTENCY, this cache is not used.")
+
+	flags.Uint32("list-objects-query-cache-max-results", defaultConfig.ListObjectsQueryCache.MaxResults, "if caching of ListObjects responses is enabled, this is the limit of objects of a cached response.")
+
+	flags.Duration("list-objects-query-cache-ttl", defaultConfig.ListObjectsQueryCache.TTL, "if caching of ListObjects responses is enabled, this is the TTL of each value")
+
 	flags.Bool("check-query-cache-enabled", defaultConfig.CheckQueryCache.Enabled, "enable caching of Check requests. For example, if you have a relation define viewer: owner or editor, and the query is Check(user:anne, viewer, doc:1), we'll evaluate the owner relation and the editor relation and cache both results: (user:anne, viewer, doc:1) -> allowed=true and (user:anne, owner, doc:1) -> allowed=true. The cache is stored in-memory; the cached values are overwritten on every change in the result, and cleared after the configured TTL. This flag improves latency, but turns Check and ListObjects into eventually consistent APIs. If the request's consistency is HIGHER_CONSISTENCY, this cache is not used.")
 
 	flags.Uint32("check-query-cache-limit", defaultConfig.CheckCache.Limit, "DEPRECATED: Use check-cache-limit instead. If caching of Check and ListObjects calls is enabled, this is the size limit of the cache")
@@ -322,6 +698,36 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("cache-controller-ttl", defaultConfig.CacheController.TTL, "if cache controller is enabled, this is the minimum time interval for Check requests to trigger cache invalidation. List Objects requests may trigger invalidation even sooner if list objects iterator cache is enabled.")
 
+	flags.Bool("cache-invalidation-listener-enabled", defaultConfig.CacheInvalidationListener.Enabled, "enable/disable the invalidation of the caches of the cache controller as soon as the tuple changes are committed, by listening to the changes of the datastore instead of waiting for the cache controller to read them from the changelog table. Requires the cache controller. Supported by the postgres engine, with logical replication, and by the mysql engine, by polling the changelog table.")
+
+	flags.String("cache-invalidation-listener-postgres-publication", defaultConfig.CacheInvalidationListener.PostgresPublication, "if the cache invalidation listener is enabled with the postgres engine, the publication of the tuple table to subscribe to with logical replication")
+
+	flags.Duration("cache-invalidation-listener-poll-interval", defaultConfig.CacheInvalidationListener.PollInterval, "if the cache invalidation listener is enabled with the mysql engine, the interval at which the changelog table of all the stores is polled for new changes")
+
+	flags.Bool("user-id-encryption-enabled", defaultConfig.UserIDEncryption.Enabled, "enable/disable the encryption of the IDs of the users of the tuples at rest, so that they are protected even with a direct access to the database. The types and relations of the users, and the wildcards, are not encrypted.")
+
+	flags.String("user-id-encryption-key", defaultConfig.UserIDEncryption.Key, "the base64 encoded AES key, 16, 24 or 32 bytes long, with which the IDs of the users are encrypted if the user ID encryption is enabled")
+
+	flags.StringSlice("user-id-encryption-types", defaultConfig.UserIDEncryption.Types, "the types of the users whose IDs are encrypted if the user ID encryption is enabled (empty means all the types)")
+
+	flags.String("redaction-mode", defaultConfig.Redaction.Mode, "the redaction of the IDs of the users and the objects in the logs and the traces: 'none', 'redact' to replace them with 'redacted', or 'hash' to replace them with their HMAC, so that they can still be correlated. The types, the relations and the wildcards are kept.")
+
+	flags.String("redaction-key", defaultConfig.Redaction.Key, "the secret of the HMAC with which the IDs are hashed if the redaction mode is 'hash'")
+
+	flags.Duration("redaction-key-rotation-period", defaultConfig.Redaction.KeyRotationPeriod, "if the redaction mode is 'hash', the period of the HMAC keys derived from the redaction key, so that the hashes of an ID can only be correlated within a period (0 disables the rotation)")
+
+	flags.Duration("secrets-refresh-interval", defaultConfig.Secrets.RefreshInterval, "the interval at which the secrets referenced by the configuration are fetched again, so that their rotation is applied without a restart. The datastore username and password, the preshared keys and the TLS certificates and keys can reference a key of a Vault KV secret (vault://secret/openfga#password, with VAULT_ADDR and VAULT_TOKEN), an AWS Secrets Manager secret (awssm://openfga/datastore#password, with the default AWS credential chain), or a file, e.g. of a mounted Kubernetes secret (file:///var/run/secrets/openfga/password)")
+
+	flags.Bool("audit-enabled", defaultConfig.Audit.Enabled, "enable/disable the audit and access events of the API requests, exported as OTLP log records with the trace of the requests and the resource attributes of the traces")
+
+	flags.String("audit-otlp-endpoint", defaultConfig.Audit.OTLP.Endpoint, "the endpoint of the collector to which the audit events are exported")
+
+	flags.Bool("audit-otlp-tls-enabled", defaultConfig.Audit.OTLP.TLS.Enabled, "use TLS connection for the collector of the audit events")
+
+	flags.Int("audit-queue-size", defaultConfig.Audit.QueueSize, "the number of audit events that can be queued to be exported, after which the events are dropped")
+
+	flags.Duration("audit-flush-interval", defaultConfig.Audit.FlushInterval, "the maximum interval between the exports of the queued audit events")
+
 	flags.Uint32("cache-ttl-jitter-percentage", defaultConfig.CacheTTLJitterPercentage, "a percentage (0-100) of the base TTL added as random jitter to each cache entry's TTL, spreading out expirations to prevent thundering herd effects. For example, a value of 10 with a base TTL of 10s means each entry gets a TTL between 10s and 11s. Default is 0 (no jitter).")
 
 	// Unfortunately UintSlice/IntSlice does not work well when used as environment variable, we need to stick with string slice and convert back to integer
@@ -359,6 +765,28 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("check-datastore-throttle-duration", defaultConfig.CheckDatastoreThrottle.Duration, "defines the time for which the datastore request will be suspended for being throttled.")
 
+	flags.Int("check-store-bulkhead-limit", defaultConfig.CheckStoreBulkhead.Limit, "the number of Checks of a store resolved at once, so that a burst of Checks on a store cannot take all the resources of the server. The other Checks of the store wait for their turn. A value of 0 means no limit.")
+
+	flags.StringSlice("check-store-bulkhead-store-limits", defaultConfig.CheckStoreBulkhead.StoreLimits, "per-store overrides of the check store bulkhead limit, in the form <store_id>=<limit> (e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=200)")
+
+	flags.Bool("check-coalescing-enabled", defaultConfig.CheckCoalescing.Enabled, "enable/disable the merging of the identical Checks received within check-coalescing-window of each other into one resolution, so that a burst of Checks on a hot object is resolved once. HIGHER_CONSISTENCY Checks are never merged")
+
+	flags.Duration("check-coalescing-window", defaultConfig.CheckCoalescing.Window, "the time the first of identical Checks waits for the others before it is resolved, when check coalescing is enabled")
+
+	flags.Float64("write-throttling-tuples-per-second", defaultConfig.WriteThrottling.TuplesPerSecond, "the number of tuples a store can write per second, so that a bulk load on a store cannot overwhelm the changelog and the replicas. The writes over the limit are rejected with a RESOURCE_EXHAUSTED error and the time after which they can be retried. A value of 0 means no limit.")
+
+	flags.Float64("write-throttling-changes-per-second", defaultConfig.WriteThrottling.ChangesPerSecond, "the number of changes, i.e. of tuples written and deleted, a store can make per second. A value of 0 means no limit.")
+
+	flags.StringSlice("write-throttling-store-tuples-per-second", defaultConfig.WriteThrottling.StoreTuplesPerSecond, "per-store overrides of the write throttling tuples per second, in the form <store_id>=<rate> (e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=500)")
+
+	flags.StringSlice("write-throttling-store-changes-per-second", defaultConfig.WriteThrottling.StoreChangesPerSecond, "per-store overrides of the write throttling changes per second, in the form <store_id>=<rate> (e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=1000)")
+
+	flags.Bool("write-scheduling-enabled", defaultConfig.WriteScheduling.Enabled, "enable/disable the weighted fair scheduling of the Writes between the stores when the datastore is write-saturated, so that a bulk load on a store doesn't delay the small Writes of the others")
+
+	flags.Int("write-scheduling-max-in-flight", defaultConfig.WriteScheduling.MaxInFlight, "the number of Writes sent to the datastore at once when write scheduling is enabled. The other Writes are queued")
+
+	flags.StringSlice("write-scheduling-store-weights", defaultConfig.WriteScheduling.StoreWeights, "the share of the stores among the queued Writes, in the form <store_id>=<weight>. The stores not listed have a weight of 1")
+
 	flags.Int("listObjects-datastore-throttle-threshold", defaultConfig.ListObjectsDatastoreThrottle.Threshold, "define the number of datastore requests allowed before being throttled.")
 
 	flags.Duration("listObjects-datastore-throttle-duration", defaultConfig.ListObjectsDatastoreThrottle.Duration, "defines the time for which the datastore request will be suspended for being throttled.")
@@ -369,6 +797,8 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("request-timeout", defaultConfig.RequestTimeout, "configures request timeout.  If both HTTP upstream timeout and request timeout are specified, request timeout will be used.")
 
+	flags.StringSlice("method-timeouts", defaultConfig.MethodTimeouts, "per-method overrides of the request timeout, in the form <method>=<default>:<max> (e.g. ListObjects=5s:10s). The default is the timeout of the requests without a deadline, the max bounds the deadline set by the clients. A zero value falls back to the other one")
+
 	flags.Duration("shutdown-timeout", defaultConfig.ShutdownTimeout, "configures how long the server waits for a graceful shutdown.")
 
 	flags.Duration("planner-eviction-threshold", defaultConfig.Planner.EvictionThreshold, "how long a planner key can be unused before being evicted")
@@ -402,7 +832,7 @@ func ReadConfig() (*serverconfig.Config, error) {
 	return config, nil
 }
 
-func run(_ *cobra.Command, _ []string) {
+func run(opts []ServerContextOption) {
 	config, err := ReadConfig()
 	if err != nil {
 		panic(err)
@@ -412,8 +842,22 @@ func run(_ *cobra.Command, _ []string) {
 		panic(err)
 	}
 
-	logger := logger.MustNewLogger(config.Log.Format, config.Log.Level, config.Log.TimestampFormat)
+	redactor, err := redactorConfig(config)
+	if err != nil {
+		panic(err)
+	}
+
+	logger := logger.MustNewLogger(config.Log.Format, config.Log.Level, config.Log.TimestampFormat,
+		logger.WithZapOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return redaction.NewCore(core, redactor)
+		})))
+	if redactor.Enabled() {
+		logger.Info(fmt.Sprintf("the IDs of the users and the objects in the logs and the traces are redacted with the '%s' mode", config.Redaction.Mode))
+	}
 	serverCtx := &ServerContext{Logger: logger}
+	for _, opt := range opts {
+		opt(serverCtx)
+	}
 	if err := serverCtx.Run(context.Background(), config); err != nil {
 		panic(err)
 	}
@@ -421,6 +865,33 @@ func run(_ *cobra.Command, _ []string) {
 
 type ServerContext struct {
 	Logger logger.Logger
+
+	// UnaryInterceptors are added to the unary RPCs of the gRPC server, after the built-in
+	// interceptors, so that they only see the authenticated and valid requests.
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+
+	// StreamInterceptors are added to the streaming RPCs of the gRPC server, after the built-in
+	// interceptors, so that they only see the authenticated and valid requests.
+	StreamInterceptors []grpc.StreamServerInterceptor
+
+	// HTTPMiddlewares wrap the handler of the HTTP server, the first being the outermost. They see
+	// the requests before they are translated to gRPC.
+	HTTPMiddlewares []func(http.Handler) http.Handler
+
+	// DatastoreWrappers wrap the datastore of the server, see [server.WithDatastoreWrappers].
+	DatastoreWrappers []server.DatastoreWrapper
+
+	// UserIDCodec encodes the IDs of the users of the tuples at rest when the user ID encryption
+	// is enabled. If nil, they are encrypted with the AES key of the config.
+	UserIDCodec storagewrappers.UserIDCodec
+
+	// CheckResolvers are inserted in the chain of resolvers of the Check sub-problems, see
+	// [server.WithCheckResolvers].
+	CheckResolvers []checkresolver.Custom
+
+	// secretsWatcher reloads the secrets referenced by the config when they are rotated. It is
+	// created by Run.
+	secretsWatcher *secrets.Watcher
 }
 
 func convertStringArrayToUintArray(stringArray []string) []uint {
@@ -437,8 +908,27 @@ func convertStringArrayToUintArray(stringArray []string) []uint {
 
 // telemetryConfig returns the function that must be called to shut down tracing.
 // The context provided to this function should be error-free, or shut down will be incomplete.
-func (s *ServerContext) telemetryConfig(config *serverconfig.Config) func(context.Context) error {
+func (s *ServerContext) telemetryConfig(config *serverconfig.Config) (func(context.Context) error, *telemetry.RuleSampler, error) {
 	if config.Trace.Enabled {
+		storeRatios, err := telemetry.ParseSamplingRatios(config.Trace.StoreSampleRatios)
+		if err != nil {
+			return nil, nil, fmt.Errorf("config 'trace.storeSampleRatios': %w", err)
+		}
+
+		methodRatios, err := telemetry.ParseSamplingRatios(config.Trace.MethodSampleRatios)
+		if err != nil {
+			return nil, nil, fmt.Errorf("config 'trace.methodSampleRatios': %w", err)
+		}
+
+		sampler, err := telemetry.NewRuleSampler(telemetry.SamplingRatios{
+			Default: config.Trace.SampleRatio,
+			Stores:  storeRatios,
+			Methods: methodRatios,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("config 'trace.sampleRatio': %w", err)
+		}
+
 		endpoint, schemeSecure := telemetry.ParseOTLPEndpoint(config.Trace.OTLP.Endpoint)
 		effectiveTLS := telemetry.ResolveOTLPSecurity(config.Trace.OTLP.TLS.Enabled, schemeSecure)
 
@@ -452,61 +942,222 @@ func (s *ServerContext) telemetryConfig(config *serverconfig.Config) func(contex
 				semconv.ServiceNameKey.String(config.Trace.ServiceName),
 				semconv.ServiceVersionKey.String(build.Version),
 			),
-			telemetry.WithSamplingRatio(config.Trace.SampleRatio),
+			telemetry.WithSampler(sampler),
+		}
+
+		if config.Trace.VerboseRequestsEnabled {
+			options = append(options, telemetry.WithVerboseTracing())
 		}
 
 		if !config.Trace.OTLP.TLS.Enabled {
 			options = append(options, telemetry.WithOTLPInsecure())
 		}
 
+		redactor, err := redactorConfig(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if redactor.Enabled() {
+			options = append(options, telemetry.WithRedactor(redactor))
+		}
+
 		tp := telemetry.MustNewTracerProvider(options...)
 		return func(ctx context.Context) error {
 			// can take up to 5 seconds to complete (https://github.com/open-telemetry/opentelemetry-go/blob/aebcbfcbc2962957a578e9cb3e25dc834125e318/sdk/trace/batch_span_processor.go#L97)
 			return errors.Join(tp.ForceFlush(ctx), tp.Shutdown(ctx))
-		}
+		}, sampler, nil
 	}
 	otel.SetTracerProvider(noop.NewTracerProvider())
 	return func(_ context.Context) error {
 		return nil
+	}, nil, nil
+}
+
+// auditConfig returns the emitter of the audit events and the function that must be called to
+// export the queued events and close the connection to the collector, or nil if the audit events
+// are disabled.
+func (s *ServerContext) auditConfig(config *serverconfig.Config) (*audit.Emitter, cleanup, error) {
+	if !config.Audit.Enabled {
+		return nil, nil, nil
+	}
+
+	endpoint, schemeSecure := telemetry.ParseOTLPEndpoint(config.Audit.OTLP.Endpoint)
+	creds := insecure.NewCredentials()
+	if telemetry.ResolveOTLPSecurity(config.Audit.OTLP.TLS.Enabled, schemeSecure) {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, nil, fmt.Errorf("config 'audit.otlp.endpoint': %w", err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(
+			semconv.ServiceNameKey.String(config.Trace.ServiceName),
+			semconv.ServiceVersionKey.String(build.Version)))
+	if err == nil {
+		res, err = resource.Merge(res, resource.Environment())
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
 	}
+
+	emitter := audit.NewEmitter(audit.NewOTLPExporter(conn, res.Attributes()),
+		audit.WithQueueSize(config.Audit.QueueSize),
+		audit.WithFlushInterval(config.Audit.FlushInterval),
+		audit.WithErrorHandler(func(err error) {
+			s.Logger.Warn("failed to export the audit events", zap.Error(err))
+		}))
+
+	s.Logger.Info(fmt.Sprintf("audit events enabled: sending them to '%s'", endpoint))
+	return emitter, func(ctx context.Context) error {
+		return errors.Join(emitter.Close(ctx), conn.Close())
+	}, nil
 }
 
-func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.OpenFGADatastore, encoder.ContinuationTokenSerializer, error) {
-	// SQL Token Serializer by default
-	tokenSerializer := sqlcommon.NewSQLContinuationTokenSerializer()
-	datastoreOptions := []sqlcommon.DatastoreOption{
-		sqlcommon.WithSecondaryURI(config.Datastore.SecondaryURI),
-		sqlcommon.WithUsername(config.Datastore.Username),
-		sqlcommon.WithPassword(config.Datastore.Password),
-		sqlcommon.WithSecondaryUsername(config.Datastore.SecondaryUsername),
-		sqlcommon.WithSecondaryPassword(config.Datastore.SecondaryPassword),
-		sqlcommon.WithLogger(s.Logger),
-		sqlcommon.WithMaxTuplesPerWrite(config.MaxTuplesPerWrite),
-		sqlcommon.WithMaxTypesPerAuthorizationModel(config.MaxTypesPerAuthorizationModel),
-		sqlcommon.WithMaxOpenConns(config.Datastore.MaxOpenConns),
-		sqlcommon.WithMinOpenConns(config.Datastore.MinOpenConns),
-		sqlcommon.WithMaxIdleConns(config.Datastore.MaxIdleConns),
-		sqlcommon.WithMinIdleConns(config.Datastore.MinIdleConns),
-		sqlcommon.WithConnMaxIdleTime(config.Datastore.ConnMaxIdleTime),
-		sqlcommon.WithConnMaxLifetime(config.Datastore.ConnMaxLifetime),
+// watchSecret resolves a value of the config that may be a secret reference, and calls onChange
+// when the referenced secret is rotated. Without the secrets watcher of Run, the secret is only
+// resolved.
+func (s *ServerContext) watchSecret(ctx context.Context, value string, onChange func(string)) (string, error) {
+	if s.secretsWatcher == nil {
+		return secrets.NewResolver().Resolve(ctx, value)
 	}
+	return s.secretsWatcher.Watch(ctx, value, onChange)
+}
 
-	if config.Datastore.Metrics.Enabled {
-		datastoreOptions = append(datastoreOptions, sqlcommon.WithMetrics())
+// secretValue returns the current secret of a value of the config passed to watchSecret.
+func (s *ServerContext) secretValue(value string) string {
+	if s.secretsWatcher == nil {
+		return value
 	}
+	return s.secretsWatcher.Value(value)
+}
 
-	dsCfg := sqlcommon.NewConfig(datastoreOptions...)
+// secretFile returns the path of a file with the secret of a value of the config, e.g. a TLS
+// certificate, which is the value itself if it is a plain path. The secrets of the secret managers
+// are written to a temporary file, which is rewritten when they are rotated so that the watchers
+// of the file reload it.
+func (s *ServerContext) secretFile(ctx context.Context, value, name string) (string, error) {
+	ref, ok := secrets.ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	if ref.Scheme == secrets.FileScheme && ref.Key == "" {
+		return ref.Path, nil
+	}
 
-	var datastore storage.OpenFGADatastore
-	var err error
-	switch config.Datastore.Engine {
-	case "memory":
-		// override for "memory" datastore
-		tokenSerializer = encoder.NewStringContinuationTokenSerializer()
-		opts := []memory.StorageOption{
+	dir, err := os.MkdirTemp("", "openfga-secrets")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the directory of the secret files: %w", err)
+	}
+	path := filepath.Join(dir, name)
+	secret, err := s.watchSecret(ctx, value, func(secret string) {
+		if err := writeSecretFile(path, secret); err != nil {
+			s.Logger.Error("failed to write the rotated secret", zap.String("path", path), zap.Error(err))
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := writeSecretFile(path, secret); err != nil {
+		return "", fmt.Errorf("failed to write the secret %s: %w", ref, err)
+	}
+	return path, nil
+}
+
+// writeSecretFile replaces the file of a secret atomically, so that it is never read partially
+// written.
+func writeSecretFile(path, secret string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(secret), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.OpenFGADatastore, encoder.ContinuationTokenSerializer, error) {
+	ctx := context.Background()
+	username, err := s.watchSecret(ctx, config.Datastore.Username, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config 'datastore.username': %w", err)
+	}
+	password, err := s.watchSecret(ctx, config.Datastore.Password, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config 'datastore.password': %w", err)
+	}
+	secondaryUsername, err := s.watchSecret(ctx, config.Datastore.SecondaryUsername, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config 'datastore.secondaryUsername': %w", err)
+	}
+	secondaryPassword, err := s.watchSecret(ctx, config.Datastore.SecondaryPassword, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config 'datastore.secondaryPassword': %w", err)
+	}
+
+	// SQL Token Serializer by default
+	tokenSerializer := sqlcommon.NewSQLContinuationTokenSerializer()
+	datastoreOptions := []sqlcommon.DatastoreOption{
+		sqlcommon.WithSecondaryURI(config.Datastore.SecondaryURI),
+		sqlcommon.WithUsername(username),
+		sqlcommon.WithPassword(password),
+		sqlcommon.WithSecondaryUsername(secondaryUsername),
+		sqlcommon.WithSecondaryPassword(secondaryPassword),
+		sqlcommon.WithLogger(s.Logger),
+		sqlcommon.WithMaxTuplesPerWrite(config.MaxTuplesPerWrite),
+		sqlcommon.WithMaxTypesPerAuthorizationModel(config.MaxTypesPerAuthorizationModel),
+		sqlcommon.WithMaxOpenConns(config.Datastore.MaxOpenConns),
+		sqlcommon.WithMinOpenConns(config.Datastore.MinOpenConns),
+		sqlcommon.WithMaxIdleConns(config.Datastore.MaxIdleConns),
+		sqlcommon.WithMinIdleConns(config.Datastore.MinIdleConns),
+		sqlcommon.WithConnMaxIdleTime(config.Datastore.ConnMaxIdleTime),
+		sqlcommon.WithConnMaxLifetime(config.Datastore.ConnMaxLifetime),
+	}
+
+	if config.Datastore.Metrics.Enabled {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithMetrics())
+	}
+
+	if config.Datastore.IndexAdvisor.Enabled {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithIndexAdvisor(config.Datastore.IndexAdvisor.SlowQueryThreshold))
+	}
+
+	if config.Datastore.ReverseUserIndex.Enabled {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithReverseUserIndex())
+	}
+	if config.Datastore.MaintenanceTuning.Enabled {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithMaintenanceTuning())
+	}
+	if config.Datastore.TransactionPooling.Enabled {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithTransactionPooling())
+	}
+	if config.Datastore.IAMAuth.Method != "" {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithIAMAuth(config.Datastore.IAMAuth.Method, config.Datastore.IAMAuth.Region))
+	}
+	if secrets.IsReference(config.Datastore.Password) {
+		// the new connections authenticate with the current password, so that they use the
+		// rotated one once the secret is rotated
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithPasswordProvider(sqlcommon.AuthTokenProviderFunc(
+			func(context.Context, string, int, string) (string, error) {
+				return s.secretValue(config.Datastore.Password), nil
+			})))
+	}
+
+	dsCfg := sqlcommon.NewConfig(datastoreOptions...)
+
+	var datastore storage.OpenFGADatastore
+	switch config.Datastore.Engine {
+	case "memory":
+		// override for "memory" datastore
+		tokenSerializer = encoder.NewStringContinuationTokenSerializer()
+		opts := []memory.StorageOption{
 			memory.WithMaxTypesPerAuthorizationModel(config.MaxTypesPerAuthorizationModel),
 			memory.WithMaxTuplesPerWrite(config.MaxTuplesPerWrite),
 		}
+		if config.Datastore.ReverseUserIndex.Enabled {
+			opts = append(opts, memory.WithReverseUserIndex())
+		}
 		datastore = memory.New(opts...)
 	case "mysql":
 		datastore, err = mysql.New(config.Datastore.URI, dsCfg)
@@ -524,7 +1175,17 @@ func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.Op
 			return nil, nil, fmt.Errorf("initialize sqlite datastore: %w", err)
 		}
 	default:
-		return nil, nil, fmt.Errorf("storage engine '%s' is unsupported", config.Datastore.Engine)
+		driver, ok := drivers.Lookup(config.Datastore.Engine)
+		if !ok {
+			return nil, nil, fmt.Errorf("storage engine '%s' is unsupported", config.Datastore.Engine)
+		}
+		datastore, err = driver.Open(config.Datastore.URI, dsCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("initialize %s datastore: %w", config.Datastore.Engine, err)
+		}
+		if driver.ContinuationTokenSerializer != nil {
+			tokenSerializer = driver.ContinuationTokenSerializer
+		}
 	}
 
 	s.Logger.Info(fmt.Sprintf("using '%v' storage engine", config.Datastore.Engine))
@@ -532,6 +1193,148 @@ func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.Op
 	return datastore, tokenSerializer, nil
 }
 
+// checkCacheConfig returns the check cache registered as the check cache engine, or nil if the
+// engine is the built-in one, which the server creates itself.
+func (s *ServerContext) checkCacheConfig(config *serverconfig.Config) (storage.InMemoryCache[any], error) {
+	if config.CheckCache.Engine == serverconfig.DefaultCheckCacheEngine {
+		return nil, nil
+	}
+
+	cache, ok := caches.Lookup(config.CheckCache.Engine)
+	if !ok {
+		return nil, fmt.Errorf("unsupported check cache engine '%v'", config.CheckCache.Engine)
+	}
+	s.Logger.Info(fmt.Sprintf("using '%s' check cache", config.CheckCache.Engine))
+
+	checkCache, err := cache.New(&config.CheckCache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize check cache: %w", err)
+	}
+	return checkCache, nil
+}
+
+// registeredCacheConfig returns the cache registered as engine with the caches package, constructed
+// with the check cache settings, for the cache named name.
+func (s *ServerContext) registeredCacheConfig(config *serverconfig.Config, engine, name string) (storage.InMemoryCache[any], error) {
+	cache, ok := caches.Lookup(engine)
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s engine '%v'", name, engine)
+	}
+	s.Logger.Info(fmt.Sprintf("using '%s' %s", engine, name))
+
+	registeredCache, err := cache.New(&config.CheckCache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize %s: %w", name, err)
+	}
+	return registeredCache, nil
+}
+
+// latestModelCacheConfig returns the cache registered as the latest model cache engine, or nil if
+// the engine is the built-in one, which the server creates itself.
+func (s *ServerContext) latestModelCacheConfig(config *serverconfig.Config) (storage.InMemoryCache[any], error) {
+	if config.Datastore.LatestModelCacheEngine == serverconfig.DefaultLatestModelCacheEngine {
+		return nil, nil
+	}
+	return s.registeredCacheConfig(config, config.Datastore.LatestModelCacheEngine, "latest model cache")
+}
+
+// typesystemCompilationCacheConfig returns the cache registered as the typesystem compilation
+// cache engine, or nil if it is not set.
+func (s *ServerContext) typesystemCompilationCacheConfig(config *serverconfig.Config) (storage.InMemoryCache[any], error) {
+	if config.Datastore.TypesystemCompilationCacheEngine == "" {
+		return nil, nil
+	}
+	return s.registeredCacheConfig(config, config.Datastore.TypesystemCompilationCacheEngine, "typesystem compilation cache")
+}
+
+// redactorConfig returns the redactor of the IDs in the logs and the traces.
+func redactorConfig(config *serverconfig.Config) (*redaction.Redactor, error) {
+	mode, err := redaction.ParseMode(config.Redaction.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("config 'redaction.mode': %w", err)
+	}
+	redactor, err := redaction.NewRedactor(mode, []byte(config.Redaction.Key), config.Redaction.KeyRotationPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("config 'redaction': %w", err)
+	}
+	return redactor, nil
+}
+
+// userIDCodecConfig returns the codec of the user ID encryption: the one of the ServerContext, or
+// one with the AES key of the config.
+func (s *ServerContext) userIDCodecConfig(config *serverconfig.Config) (storagewrappers.UserIDCodec, error) {
+	if s.UserIDCodec != nil {
+		return s.UserIDCodec, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(config.UserIDEncryption.Key)
+	if err != nil {
+		return nil, fmt.Errorf("config 'userIDEncryption.key' must be base64 encoded: %w", err)
+	}
+	codec, err := storagewrappers.NewAESUserIDCodec(key)
+	if err != nil {
+		return nil, fmt.Errorf("config 'userIDEncryption.key': %w", err)
+	}
+	return codec, nil
+}
+
+// changeListenerConfig returns the listener of the changes of the datastore that invalidates the
+// caches of the cache controller, or nil if the cache invalidation listener is disabled.
+func (s *ServerContext) changeListenerConfig(config *serverconfig.Config, datastore storage.OpenFGADatastore) (storage.ChangeListener, error) {
+	if !config.CacheInvalidationListener.Enabled {
+		return nil, nil
+	}
+
+	switch config.Datastore.Engine {
+	case "postgres":
+		dsCfg := sqlcommon.NewConfig(
+			sqlcommon.WithUsername(config.Datastore.Username),
+			sqlcommon.WithPassword(config.Datastore.Password),
+			sqlcommon.WithLogger(s.Logger),
+		)
+		s.Logger.Info(fmt.Sprintf("cache invalidation listener is enabled with the postgres publication '%s'", config.CacheInvalidationListener.PostgresPublication))
+		return postgres.NewReplicationListener(config.Datastore.URI, dsCfg,
+			postgres.WithReplicationPublication(config.CacheInvalidationListener.PostgresPublication)), nil
+	case "mysql":
+		ds, ok := datastore.(*mysql.Datastore)
+		if !ok {
+			return nil, fmt.Errorf("config 'cacheInvalidationListener.enabled' requires a mysql datastore")
+		}
+		s.Logger.Info(fmt.Sprintf("cache invalidation listener is enabled, polling the changelog every %s", config.CacheInvalidationListener.PollInterval))
+		return ds.NewChangelogListener(sqlcommon.WithChangelogListenerInterval(config.CacheInvalidationListener.PollInterval)), nil
+	default:
+		return nil, fmt.Errorf("config 'cacheInvalidationListener.enabled' is not supported by the '%s' storage engine", config.Datastore.Engine)
+	}
+}
+
+// presharedKeyAuthenticatorConfig returns the preshared key authenticator of the keys of the
+// config, whose secret references are reloaded when they are rotated.
+func (s *ServerContext) presharedKeyAuthenticatorConfig(config *serverconfig.Config) (*presharedkey.PresharedKeyAuthenticator, error) {
+	var authenticator *presharedkey.PresharedKeyAuthenticator
+	onChange := func(string) {
+		keys := make([]string, 0, len(config.Authn.Keys))
+		for _, key := range config.Authn.Keys {
+			keys = append(keys, s.secretValue(key))
+		}
+		if err := authenticator.SetKeys(keys); err != nil {
+			s.Logger.Error("failed to reload the rotated preshared keys", zap.Error(err))
+		}
+	}
+
+	keys := make([]string, 0, len(config.Authn.Keys))
+	for _, key := range config.Authn.Keys {
+		secret, err := s.watchSecret(context.Background(), key, onChange)
+		if err != nil {
+			return nil, fmt.Errorf("config 'authn.preshared.keys': %w", err)
+		}
+		keys = append(keys, secret)
+	}
+
+	var err error
+	authenticator, err = presharedkey.NewPresharedKeyAuthenticator(keys)
+	return authenticator, err
+}
+
 func (s *ServerContext) authenticatorConfig(config *serverconfig.Config) (authn.Authenticator, error) {
 	var authenticator authn.Authenticator
 	var err error
@@ -542,12 +1345,17 @@ func (s *ServerContext) authenticatorConfig(config *serverconfig.Config) (authn.
 		authenticator = authn.NoopAuthenticator{}
 	case "preshared":
 		s.Logger.Info("using 'preshared' authentication")
-		authenticator, err = presharedkey.NewPresharedKeyAuthenticator(config.Authn.Keys)
+		authenticator, err = s.presharedKeyAuthenticatorConfig(config)
 	case "oidc":
 		s.Logger.Info("using 'oidc' authentication")
 		authenticator, err = oidc.NewRemoteOidcAuthenticator(config.Authn.Issuer, config.Authn.IssuerAliases, config.Authn.Audience, config.Authn.Subjects, config.Authn.ClientIDClaims)
 	default:
-		return nil, fmt.Errorf("unsupported authentication method '%v'", config.Authn.Method)
+		provider, ok := providers.Lookup(config.Authn.Method)
+		if !ok {
+			return nil, fmt.Errorf("unsupported authentication method '%v'", config.Authn.Method)
+		}
+		s.Logger.Info(fmt.Sprintf("using '%s' authentication", config.Authn.Method))
+		authenticator, err = provider.New(&config.Authn)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize authenticator: %w", err)
@@ -555,9 +1363,22 @@ func (s *ServerContext) authenticatorConfig(config *serverconfig.Config) (authn.
 	return authenticator, nil
 }
 
-func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfig.Config, authenticator authn.Authenticator) ([]grpc.ServerOption, *grpc_prometheus.ServerMetrics, error) {
+func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfig.Config, authenticator authn.Authenticator, priorityScheduler *priority.Scheduler, auditEmitter *audit.Emitter) ([]grpc.ServerOption, *grpc_prometheus.ServerMetrics, error) {
 	serverOpts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(config.GRPC.MaxRecvMsgBytes),
+		grpc.MaxSendMsgSize(config.GRPC.MaxSendMsgBytes),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     config.GRPC.MaxConnectionIdle,
+			MaxConnectionAge:      config.GRPC.MaxConnectionAge,
+			MaxConnectionAgeGrace: config.GRPC.MaxConnectionAgeGrace,
+			Time:                  config.GRPC.Keepalive.Time,
+			Timeout:               config.GRPC.Keepalive.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             config.GRPC.Keepalive.MinTime,
+			PermitWithoutStream: config.GRPC.Keepalive.PermitWithoutStream,
+		}),
+		grpc.MaxConcurrentStreams(config.GRPC.MaxConcurrentStreams), // 0 means no limit
 		grpc.ChainUnaryInterceptor(
 			[]grpc.UnaryServerInterceptor{
 				grpc_recovery.UnaryServerInterceptor( // panic middleware must be 1st in chain
@@ -565,8 +1386,9 @@ func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfi
 						recovery.PanicRecoveryHandler(s.Logger),
 					),
 				),
-				grpc_ctxtags.UnaryServerInterceptor(), // needed for logging
-				requestid.NewUnaryInterceptor(),       // add request_id to ctxtags
+				serverErrors.NewErrorInfoUnaryInterceptor(), // attach google.rpc.ErrorInfo to the errors
+				grpc_ctxtags.UnaryServerInterceptor(),       // needed for logging
+				requestid.NewUnaryInterceptor(),             // add request_id to ctxtags
 			}...,
 		),
 		grpc.ChainStreamInterceptor(
@@ -576,14 +1398,20 @@ func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfi
 						recovery.PanicRecoveryHandler(s.Logger),
 					),
 				),
-				grpc_ctxtags.StreamServerInterceptor(), // needed for logging
-				requestid.NewStreamingInterceptor(),    // add request_id to ctxtags
+				serverErrors.NewErrorInfoStreamingInterceptor(), // attach google.rpc.ErrorInfo to the errors
+				grpc_ctxtags.StreamServerInterceptor(),          // needed for logging
+				requestid.NewStreamingInterceptor(),             // add request_id to ctxtags
 			}...,
 		),
 	}
 
-	if config.RequestTimeout > 0 {
-		timeoutMiddleware := middleware.NewTimeoutInterceptor(config.RequestTimeout, s.Logger)
+	methodTimeouts, err := middleware.ParseMethodTimeouts(config.MethodTimeouts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config 'methodTimeouts': %w", err)
+	}
+
+	if config.RequestTimeout > 0 || len(methodTimeouts) > 0 {
+		timeoutMiddleware := middleware.NewTimeoutInterceptor(config.RequestTimeout, s.Logger, middleware.WithMethodTimeouts(methodTimeouts))
 
 		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(timeoutMiddleware.NewUnaryTimeoutInterceptor()))
 		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(timeoutMiddleware.NewStreamTimeoutInterceptor()))
@@ -594,12 +1422,12 @@ func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfi
 			[]grpc.UnaryServerInterceptor{
 				storeid.NewUnaryInterceptor(),           // if available, add store_id to ctxtags
 				logging.NewLoggingInterceptor(s.Logger), // needed to log invalid requests
-				validator.UnaryServerInterceptor(),
+				validator.UnaryServerInterceptor(validator.WithMaxContextualTuples(int(config.MaxContextualTuples))),
 			}...,
 		),
 		grpc.ChainStreamInterceptor(
 			[]grpc.StreamServerInterceptor{
-				validator.StreamServerInterceptor(),
+				validator.StreamServerInterceptor(validator.WithMaxContextualTuples(int(config.MaxContextualTuples))),
 			}...,
 		),
 	)
@@ -621,15 +1449,26 @@ func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfi
 
 	if config.Trace.Enabled {
 		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+
+		if config.Trace.VerboseRequestsEnabled {
+			serverOpts = append(serverOpts,
+				grpc.ChainUnaryInterceptor(telemetry.NewVerboseTracingUnaryInterceptor()),
+				grpc.ChainStreamInterceptor(telemetry.NewVerboseTracingStreamingInterceptor()))
+		}
+	}
+
+	authFunc := authnmw.AuthFunc(authenticator)
+	if auditEmitter != nil {
+		authFunc = audit.NewAuthFunc(authFunc, auditEmitter)
 	}
 
 	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(
 		[]grpc.UnaryServerInterceptor{
-			grpcauth.UnaryServerInterceptor(authnmw.AuthFunc(authenticator)),
+			grpcauth.UnaryServerInterceptor(authFunc),
 		}...),
 		grpc.ChainStreamInterceptor(
 			[]grpc.StreamServerInterceptor{
-				grpcauth.StreamServerInterceptor(authnmw.AuthFunc(authenticator)),
+				grpcauth.StreamServerInterceptor(authFunc),
 				// The following interceptors wrap the server stream with our own
 				// wrapper and must come last.
 				storeid.NewStreamingInterceptor(),
@@ -638,11 +1477,50 @@ func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfi
 		),
 	)
 
+	if auditEmitter != nil {
+		redactor, err := redactorConfig(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(audit.NewUnaryInterceptor(auditEmitter, redactor)),
+			grpc.ChainStreamInterceptor(audit.NewStreamingInterceptor(auditEmitter, redactor)))
+	}
+
+	if priorityScheduler != nil {
+		methodClasses, err := priority.ParseMethodClasses(config.PriorityScheduling.MethodClasses)
+		if err != nil {
+			return nil, nil, fmt.Errorf("config 'priorityScheduling.methodClasses': %w", err)
+		}
+
+		priorityMiddleware := priority.NewInterceptor(priorityScheduler, methodClasses)
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(priorityMiddleware.NewUnaryInterceptor()),
+			grpc.ChainStreamInterceptor(priorityMiddleware.NewStreamingInterceptor()))
+
+		s.Logger.Info(fmt.Sprintf("priority scheduling is enabled with %d requests in flight", config.PriorityScheduling.MaxInFlight))
+	}
+
+	if config.GRPC.Compression.Enabled {
+		if err := compression.RegisterGRPCCompressors(config.GRPC.Compression.Algorithms); err != nil {
+			return nil, prometheusMetrics, fmt.Errorf("failed to register gRPC compressors: %w", err)
+		}
+		s.Logger.Info(fmt.Sprintf("gRPC compression is enabled with algorithms %v", config.GRPC.Compression.Algorithms))
+	}
+
 	if config.GRPC.TLS.Enabled {
 		if config.GRPC.TLS.CertPath == "" || config.GRPC.TLS.KeyPath == "" {
 			return nil, prometheusMetrics, errors.New("'grpc.tls.cert' and 'grpc.tls.key' configs must be set")
 		}
-		grpcGetCertificate, err := watchAndLoadCertificateWithCertWatcher(ctx, config.GRPC.TLS.CertPath, config.GRPC.TLS.KeyPath, s.Logger, updateGRPCCertPool)
+		certPath, err := s.secretFile(ctx, config.GRPC.TLS.CertPath, "grpc-tls.crt")
+		if err != nil {
+			return nil, prometheusMetrics, fmt.Errorf("config 'grpc.tls.cert': %w", err)
+		}
+		keyPath, err := s.secretFile(ctx, config.GRPC.TLS.KeyPath, "grpc-tls.key")
+		if err != nil {
+			return nil, prometheusMetrics, fmt.Errorf("config 'grpc.tls.key': %w", err)
+		}
+		grpcGetCertificate, err := watchAndLoadCertificateWithCertWatcher(ctx, certPath, keyPath, s.Logger, updateGRPCCertPool)
 		if err != nil {
 			return nil, prometheusMetrics, err
 		}
@@ -659,6 +1537,30 @@ func (s *ServerContext) buildServerOpts(ctx context.Context, config *serverconfi
 	return serverOpts, prometheusMetrics, nil
 }
 
+func (s *ServerContext) requestRecorderConfig(config *serverconfig.Config) (*recording.Recorder, error) {
+	opts := []recording.RecorderOption{
+		recording.WithSamplePercentage(config.RequestRecording.SamplePercentage),
+		recording.WithLogger(s.Logger),
+	}
+	if config.RequestRecording.Anonymize {
+		key := []byte(config.RequestRecording.AnonymizationKey)
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("failed to generate the request recording anonymization key: %w", err)
+			}
+		}
+		opts = append(opts, recording.WithAnonymizer(recording.NewAnonymizer(key)))
+	}
+
+	recorder, err := recording.NewFileRecorder(config.RequestRecording.Path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.Logger.Info(fmt.Sprintf("📼 recording %d%% of the Check and ListObjects requests to '%s'", config.RequestRecording.SamplePercentage, config.RequestRecording.Path))
+	return recorder, nil
+}
+
 func (s *ServerContext) dialLocalGrpc(network, address string, config *serverconfig.Config) *grpc.ClientConn {
 	const loopback = "localhost"
 
@@ -683,7 +1585,11 @@ func (s *ServerContext) dialLocalGrpc(network, address string, config *servercon
 		addr = net.JoinHostPort(host, port)
 	}
 
-	dialOpts := []grpc.DialOption{}
+	dialOpts := []grpc.DialOption{
+		// the gateway accepts any response that the server sends, instead of the default limit of
+		// the clients of 4MB
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(config.GRPC.MaxSendMsgBytes)),
+	}
 
 	if config.GRPC.TLS.Enabled {
 		var creds credentials.TransportCredentials
@@ -721,105 +1627,947 @@ func (s *ServerContext) dialLocalGrpc(network, address string, config *servercon
 					return nil
 				},
 			}
-			creds = credentials.NewTLS(&tlsConf)
+			creds = credentials.NewTLS(&tlsConf)
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if config.Trace.Enabled {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+
+	conn, err := grpc.NewClient(addr, dialOpts...)
+	if err != nil {
+		s.Logger.Fatal("failed to create gRPC client connection", zap.Error(err))
+	}
+	return conn
+}
+
+func (s *ServerContext) runHTTPServer(ctx context.Context, config *serverconfig.Config, grpcConn *grpc.ClientConn, conditionContextHeaders map[string]string) (*http.Server, error) {
+	muxOpts := []grpc_runtime.ServeMuxOption{
+		grpc_runtime.WithForwardResponseOption(httpmiddleware.HTTPResponseModifier),
+		grpc_runtime.WithErrorHandler(func(c context.Context, sr *grpc_runtime.ServeMux, mm grpc_runtime.Marshaler, w http.ResponseWriter, r *http.Request, e error) {
+			st := status.Convert(e)
+			if reason := serverErrors.ErrorReason(st); reason != "" {
+				w.Header().Set(serverErrors.ErrorReasonHeader, reason)
+			}
+			intCode := serverErrors.ConvertToEncodedErrorCode(st)
+			encodedErr := serverErrors.NewEncodedError(intCode, e.Error())
+			if retryDelay, ok := serverErrors.RetryDelay(st); ok {
+				// the request was throttled rather than failed
+				w.Header().Set(serverErrors.RetryAfterHeader, strconv.FormatInt(int64(math.Ceil(retryDelay.Seconds())), 10))
+				encodedErr.HTTPStatusCode = http.StatusTooManyRequests
+			}
+			httpmiddleware.CustomHTTPErrorHandler(c, w, r, encodedErr)
+		}),
+		grpc_runtime.WithStreamErrorHandler(func(ctx context.Context, e error) *status.Status {
+			intCode := serverErrors.ConvertToEncodedErrorCode(status.Convert(e))
+			encodedErr := serverErrors.NewEncodedError(intCode, e.Error())
+			return status.Convert(encodedErr)
+		}),
+		grpc_runtime.WithHealthzEndpoint(healthv1pb.NewHealthClient(grpcConn)),
+		grpc_runtime.WithOutgoingHeaderMatcher(func(s string) (string, bool) { return s, true }),
+		grpc_runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			// Forward Openfga-Authorization-Model-Id header to gRPC metadata for AuthZEN endpoints.
+			if strings.EqualFold(key, server.AuthorizationModelIDHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Fields header to gRPC metadata for Read and ReadChanges field masks.
+			if strings.EqualFold(key, server.FieldsHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-List-Users-Wildcards header to gRPC metadata for ListUsers wildcard handling.
+			if strings.EqualFold(key, server.ListUsersWildcardsHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Batch-Check-Item-Metadata header to gRPC metadata for per-check BatchCheck metadata.
+			if strings.EqualFold(key, server.BatchCheckItemMetadataHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Check-Reason header to gRPC metadata for the reasons of allowed Checks.
+			if strings.EqualFold(key, server.CheckReasonHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Check-Trace header to gRPC metadata for the resolution trees of Checks.
+			if strings.EqualFold(key, server.CheckTraceHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-List-Objects-Explain header to gRPC metadata for the explanations of ListObjects.
+			if strings.EqualFold(key, server.ListObjectsExplainHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Id-Prefix header to gRPC metadata for the ID prefix filters of ListObjects and ListUsers.
+			if strings.EqualFold(key, server.IDPrefixHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Read-Conditions and Openfga-Read-User-Type headers to gRPC metadata for the filters of Read.
+			if strings.EqualFold(key, server.ReadConditionsHeader) || strings.EqualFold(key, server.ReadUserTypeHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Session-Token header to gRPC metadata for the read-your-writes session tokens.
+			if strings.EqualFold(key, server.SessionTokenHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Max-Resolution-Depth header to gRPC metadata for the per-request resolution depth limits.
+			if strings.EqualFold(key, server.MaxResolutionDepthHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Priority header to gRPC metadata for priority scheduling.
+			if strings.EqualFold(key, priority.Header) {
+				return strings.ToLower(key), true
+			}
+			// Forward Openfga-Trace-Verbose header to gRPC metadata for per-request verbose tracing.
+			if strings.EqualFold(key, telemetry.VerboseTracingHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward If-None-Match header to gRPC metadata for conditional authorization model reads.
+			if strings.EqualFold(key, server.IfNoneMatchHeader) {
+				return strings.ToLower(key), true
+			}
+			// Forward the headers injected into the condition context of the requests.
+			if _, ok := conditionContextHeaders[strings.ToLower(key)]; ok {
+				return strings.ToLower(key), true
+			}
+			// Use default behavior for other headers
+			return grpc_runtime.DefaultHeaderMatcher(key)
+		}),
+	}
+	mux := grpc_runtime.NewServeMux(muxOpts...)
+	if err := openfgav1.RegisterOpenFGAServiceHandler(ctx, mux, grpcConn); err != nil {
+		return nil, err
+	}
+	if err := authzenv1.RegisterAuthZenServiceHandler(ctx, mux, grpcConn); err != nil {
+		return nil, err
+	}
+	if config.OPABundle.Enabled {
+		if err := registerOPABundleHandler(mux, grpcConn, config.OPABundle); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("OPA bundle endpoint is enabled on '/stores/{store_id}/opa/bundle'")
+	}
+	if config.OrphanedTuples.EndpointEnabled {
+		if err := registerOrphanedTuplesHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("orphaned tuples endpoint is enabled on '/stores/{store_id}/orphaned-tuples'")
+	}
+	if config.StreamedBatchCheck.Enabled {
+		if err := registerStreamedBatchCheckHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("streamed batch check endpoint is enabled on '/stores/{store_id}/streamed-batch-check'")
+	}
+	if config.BulkWrite.Enabled {
+		if err := registerDeleteTuplesHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("delete tuples endpoint is enabled on '/stores/{store_id}/tuples'")
+	}
+	if config.StoreEvents.Enabled {
+		if err := registerStoreEventsHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("store events endpoint is enabled on '/stores/{store_id}/events'")
+	}
+	if config.ListObjectsSubscription.Enabled {
+		if err := registerListObjectsSubscriptionHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("ListObjects subscription endpoint is enabled on '/stores/{store_id}/list-objects/subscribe'")
+	}
+	if config.CacheHints.Enabled {
+		if err := registerCacheInvalidationsHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("cache invalidations endpoint is enabled on '/stores/{store_id}/cache-invalidations'")
+	}
+	if config.AssertionCoverage.Enabled {
+		if err := registerAssertionCoverageHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("assertion coverage endpoint is enabled on '/stores/{store_id}/assertion-coverage'")
+	}
+	if config.ModelGraph.Enabled {
+		if err := registerModelGraphHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("model graph endpoint is enabled on '/stores/{store_id}/model-graph'")
+	}
+	if config.CostEstimate.Enabled {
+		if err := registerCostEstimateHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("cost estimate endpoints are enabled on '/stores/{store_id}/check/estimate' and '/stores/{store_id}/list-objects/estimate'")
+	}
+	if config.WhatIf.Enabled {
+		if err := registerWhatIfHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("what-if endpoints are enabled on '/stores/{store_id}/what-if/check' and '/stores/{store_id}/what-if/batch-check'")
+	}
+	if config.ModelSimulation.Enabled {
+		if err := registerModelSimulationHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("model simulation endpoint is enabled on '/stores/{store_id}/model-simulation'")
+	}
+	if config.AccessReview.Enabled {
+		if err := registerAccessReviewArtifactHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("access review artifact endpoint is enabled on '/stores/{store_id}/jobs/{job_id}/artifact'")
+	}
+	if config.Jobs.Enabled {
+		if err := registerJobsHandler(mux, grpcConn); err != nil {
+			return nil, err
+		}
+		s.Logger.Info("jobs endpoint is enabled on '/stores/{store_id}/jobs' and '/stores/{store_id}/job-schedules'")
+	}
+	handler := httpmiddleware.NewBodylessStatusHandler(mux)
+
+	if config.HTTP.MaxRequestBodyBytes > 0 {
+		handler = httpmiddleware.NewMaxRequestBodyHandler(handler, int64(config.HTTP.MaxRequestBodyBytes))
+	}
+
+	if config.HTTP.Compression.Enabled {
+		handler = httpmiddleware.NewCompressionHandler(handler,
+			httpmiddleware.WithCompressionAlgorithms(config.HTTP.Compression.Algorithms...),
+			httpmiddleware.WithCompressionMinSizeBytes(config.HTTP.Compression.MinSizeBytes),
+		)
+	}
+
+	for i := len(s.HTTPMiddlewares) - 1; i >= 0; i-- {
+		handler = s.HTTPMiddlewares[i](handler)
+	}
+
+	if config.Trace.Enabled {
+		handler = otelhttp.NewHandler(handler, "grpc-gateway")
+		handler = telemetry.NewStoreSamplingHTTPMiddleware(handler)
+
+		if config.Trace.VerboseRequestsEnabled {
+			handler = telemetry.NewVerboseTracingHTTPMiddleware(handler)
+		}
+	}
+
+	httpServer := &http.Server{
+		Addr: config.HTTP.Addr,
+		Handler: recovery.HTTPPanicRecoveryHandler(cors.New(cors.Options{
+			AllowedOrigins:   config.HTTP.CORSAllowedOrigins,
+			AllowCredentials: config.HTTP.CORSAllowCredentials,
+			AllowedHeaders:   config.HTTP.CORSAllowedHeaders,
+			AllowedMethods:   config.HTTP.CORSAllowedMethods,
+			ExposedHeaders:   config.HTTP.CORSExposedHeaders,
+			MaxAge:           int(config.HTTP.CORSMaxAge.Seconds()),
+		}).Handler(handler), s.Logger),
+	}
+
+	listener, err := net.Listen("tcp", config.HTTP.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.HTTP.TLS.Enabled {
+		if config.HTTP.TLS.CertPath == "" || config.HTTP.TLS.KeyPath == "" {
+			s.Logger.Fatal("'http.tls.cert' and 'http.tls.key' configs must be set")
+		}
+		certPath, err := s.secretFile(ctx, config.HTTP.TLS.CertPath, "http-tls.crt")
+		if err != nil {
+			return nil, fmt.Errorf("config 'http.tls.cert': %w", err)
+		}
+		keyPath, err := s.secretFile(ctx, config.HTTP.TLS.KeyPath, "http-tls.key")
+		if err != nil {
+			return nil, fmt.Errorf("config 'http.tls.key': %w", err)
+		}
+		httpGetCertificate, err := watchAndLoadCertificateWithCertWatcher(ctx, certPath, keyPath, s.Logger, nil)
+		if err != nil {
+			return nil, err
+		}
+		listener = tls.NewListener(listener, &tls.Config{
+			GetCertificate: httpGetCertificate,
+		})
+
+		s.Logger.Info("HTTP TLS is enabled, serving connections using the provided certificate")
+	} else {
+		s.Logger.Warn("HTTP TLS is disabled, serving connections using insecure plaintext")
+	}
+
+	go func() {
+		s.Logger.Info(fmt.Sprintf("🚀 starting HTTP server on '%s'...", httpServer.Addr))
+		if err := httpServer.Serve(listener); err != nil {
+			if !errors.Is(err, http.ErrServerClosed) {
+				s.Logger.Fatal("HTTP server closed with unexpected error", zap.Error(err))
+			}
+		}
+		s.Logger.Info("HTTP server shut down.")
+	}()
+	return httpServer, nil
+}
+
+// registerOPABundleHandler serves OPA bundles of the tuples of a store. Bundles are read through the
+// gRPC API, so that bundle requests are authenticated and authorized like any other Read.
+func registerOPABundleHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn, config serverconfig.OPABundleConfig) error {
+	exporter := opabundle.NewExporter(openfgav1.NewOpenFGAServiceClient(grpcConn),
+		opabundle.WithMaxTuples(config.MaxTuples),
+		opabundle.WithMaxDeltaChanges(config.MaxDeltaChanges),
+	)
+
+	return mux.HandlePath(http.MethodGet, "/stores/{store_id}/opa/bundle", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, openfgav1.OpenFGAService_Read_FullMethodName)
+		if err == nil {
+			err = exporter.ServeBundle(w, r.WithContext(ctx), pathParams["store_id"])
+		}
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+		}
+	})
+}
+
+// registerOrphanedTuplesHandler serves the orphaned tuples of a store. GET reports them and DELETE
+// deletes them. Tuples are read and deleted through the gRPC API, so that requests are authenticated
+// and authorized like any other Read or Write.
+func registerOrphanedTuplesHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	store := orphans.NewClientStore(openfgav1.NewOpenFGAServiceClient(grpcConn))
+
+	handler := func(method string, dryRun bool) grpc_runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+			ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, method)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			report, err := orphans.Collect(ctx, store, pathParams["store_id"], dryRun)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(report)
+		}
+	}
+
+	if err := mux.HandlePath(http.MethodGet, "/stores/{store_id}/orphaned-tuples", handler(openfgav1.OpenFGAService_Read_FullMethodName, true)); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodDelete, "/stores/{store_id}/orphaned-tuples", handler(openfgav1.OpenFGAService_Write_FullMethodName, false))
+}
+
+// registerStreamedBatchCheckHandler serves the streamed BatchCheck service on POST
+// /stores/{store_id}/streamed-batch-check, whose JSON body is a BatchCheckRequest without its store.
+// The response has one streamedbatchcheck.Outcome per line, in the order in which the checks
+// complete, and ends with an {"error":{...}} line if the stream fails after its first outcome. It
+// calls the gRPC method, so that requests are authenticated and authorized like any other.
+func registerStreamedBatchCheckHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodPost, "/stores/{store_id}/streamed-batch-check", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		inboundMarshaler, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, streamedbatchcheck.BatchCheckMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		req := &openfgav1.BatchCheckRequest{}
+		if err := inboundMarshaler.NewDecoder(r.Body).Decode(req); err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		req.StoreId = pathParams["store_id"]
+
+		// the headers are written with the first outcome, so that the errors returned before it are
+		// still reported with their status
+		written := false
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		err = streamedbatchcheck.BatchCheck(ctx, grpcConn, req, func(outcome *streamedbatchcheck.Outcome) error {
+			if !written {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+				written = true
+			}
+			if err := enc.Encode(outcome); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			return
+		}
+		if !written {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		st := status.Convert(err)
+		_ = enc.Encode(map[string]any{"error": map[string]any{"code": st.Code(), "message": st.Message()}})
+	})
+}
+
+// registerDeleteTuplesHandler serves the DeleteTuples method of the bulk write service as DELETE
+// /stores/{store_id}/tuples, with the object, relation and user of the filter as query parameters.
+// It calls the gRPC method, so that requests are authenticated and authorized like any other.
+func registerDeleteTuplesHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodDelete, "/stores/{store_id}/tuples", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, bulkwrite.DeleteTuplesMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		query := r.URL.Query()
+		deleted, err := bulkwrite.DeleteTuples(ctx, grpcConn, &openfgav1.ReadRequest{
+			StoreId: pathParams["store_id"],
+			TupleKey: &openfgav1.ReadRequestTupleKey{
+				Object:   query.Get("object"),
+				Relation: query.Get("relation"),
+				User:     query.Get("user"),
+			},
+		})
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]uint64{"deleted_count": deleted})
+	})
+}
+
+// registerAssertionCoverageHandler serves the assertion coverage service on GET
+// /stores/{store_id}/assertion-coverage, for the model of the authorization_model_id query
+// parameter or the latest model. It calls the gRPC method, so that requests are authenticated and
+// authorized like any other.
+func registerAssertionCoverageHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodGet, "/stores/{store_id}/assertion-coverage", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, assertioncoverage.GetReportMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		report, err := assertioncoverage.GetReport(ctx, grpcConn, &assertioncoverage.GetReportRequest{
+			StoreID:              pathParams["store_id"],
+			AuthorizationModelID: r.URL.Query().Get("authorization_model_id"),
+		})
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// registerModelGraphHandler serves on GET /stores/{store_id}/model-graph the model of the
+// authorization_model_id query parameter, or the latest model, rendered in the format query
+// parameter: 'dot' (the default) or 'mermaid'. It reads the model with the gRPC API, so that
+// requests are authenticated and authorized like any other.
+func registerModelGraphHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodGet, "/stores/{store_id}/model-graph", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		modelID := r.URL.Query().Get("authorization_model_id")
+		method := openfgav1.OpenFGAService_ReadAuthorizationModels_FullMethodName
+		if modelID != "" {
+			method = openfgav1.OpenFGAService_ReadAuthorizationModel_FullMethodName
+		}
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, method)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		format, err := modelrender.ParseFormat(r.URL.Query().Get("format"))
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		model, err := modelrender.ReadModel(ctx, grpcConn, pathParams["store_id"], modelID)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		graph, err := modelrender.Render(model, format)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		_, _ = io.WriteString(w, graph)
+	})
+}
+
+// registerCostEstimateHandler serves the cost estimate service on POST
+// /stores/{store_id}/check/estimate and POST /stores/{store_id}/list-objects/estimate, whose JSON
+// bodies are the ones of Check and ListObjects. It calls the gRPC methods, so that requests are
+// authenticated and authorized like any other.
+func registerCostEstimateHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	handle := func(method string, estimate func(ctx context.Context, storeID string, body []byte) (*costestimate.Estimate, error)) grpc_runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+			ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, method)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+				return
+			}
+
+			res, err := estimate(ctx, pathParams["store_id"], body)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(res)
+		}
+	}
+
+	check := handle(costestimate.EstimateCheckMethod, func(ctx context.Context, storeID string, body []byte) (*costestimate.Estimate, error) {
+		req := &openfgav1.CheckRequest{}
+		if err := protojson.Unmarshal(body, req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return costestimate.EstimateCheck(ctx, grpcConn, &costestimate.EstimateCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: req.GetAuthorizationModelId(),
+			Object:               req.GetTupleKey().GetObject(),
+			Relation:             req.GetTupleKey().GetRelation(),
+			User:                 req.GetTupleKey().GetUser(),
+		})
+	})
+	if err := mux.HandlePath(http.MethodPost, "/stores/{store_id}/check/estimate", check); err != nil {
+		return err
+	}
+
+	listObjects := handle(costestimate.EstimateListObjectsMethod, func(ctx context.Context, storeID string, body []byte) (*costestimate.Estimate, error) {
+		req := &openfgav1.ListObjectsRequest{}
+		if err := protojson.Unmarshal(body, req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return costestimate.EstimateListObjects(ctx, grpcConn, &costestimate.EstimateListObjectsRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: req.GetAuthorizationModelId(),
+			Type:                 req.GetType(),
+			Relation:             req.GetRelation(),
+			User:                 req.GetUser(),
+		})
+	})
+	return mux.HandlePath(http.MethodPost, "/stores/{store_id}/list-objects/estimate", listObjects)
+}
+
+// registerWhatIfHandler serves the what-if service on POST /stores/{store_id}/what-if/check and
+// POST /stores/{store_id}/what-if/batch-check, whose JSON bodies are a whatif.CheckRequest and a
+// whatif.BatchCheckRequest without their store. It calls the gRPC methods, so that requests are
+// authenticated and authorized like any other.
+func registerWhatIfHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	handle := func(method string, call func(ctx context.Context, storeID string, body []byte) (any, error)) grpc_runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+			ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, method)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+				return
+			}
+
+			res, err := call(ctx, pathParams["store_id"], body)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(res)
+		}
+	}
+
+	check := handle(whatif.CheckMethod, func(ctx context.Context, storeID string, body []byte) (any, error) {
+		req := &whatif.CheckRequest{}
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		req.StoreID = storeID
+		return whatif.WhatIfCheck(ctx, grpcConn, req)
+	})
+	if err := mux.HandlePath(http.MethodPost, "/stores/{store_id}/what-if/check", check); err != nil {
+		return err
+	}
+
+	batchCheck := handle(whatif.BatchCheckMethod, func(ctx context.Context, storeID string, body []byte) (any, error) {
+		req := &whatif.BatchCheckRequest{}
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		req.StoreID = storeID
+		return whatif.WhatIfBatchCheck(ctx, grpcConn, req)
+	})
+	return mux.HandlePath(http.MethodPost, "/stores/{store_id}/what-if/batch-check", batchCheck)
+}
+
+// registerModelSimulationHandler serves the model simulation service on POST
+// /stores/{store_id}/model-simulation, whose JSON body is a modelsimulation.SimulateRequest
+// without its store. It calls the gRPC method, so that requests are authenticated and authorized
+// like any other.
+func registerModelSimulationHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodPost, "/stores/{store_id}/model-simulation", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, modelsimulation.SimulateMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		req := &modelsimulation.SimulateRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		req.StoreID = pathParams["store_id"]
+
+		res, err := modelsimulation.Simulate(ctx, grpcConn, req)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
+
+// registerAccessReviewArtifactHandler serves the access review service on GET
+// /stores/{store_id}/jobs/{job_id}/artifact, which downloads the artifact of a succeeded
+// access_review job. It calls the gRPC method, so that requests are authenticated and authorized
+// like any other.
+func registerAccessReviewArtifactHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodGet, "/stores/{store_id}/jobs/{job_id}/artifact", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, accessreview.DownloadMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		// the headers are written with the first chunk, so that the errors returned before it are
+		// still reported with their status
+		artifact := &artifactResponseWriter{ResponseWriter: w, jobID: pathParams["job_id"]}
+		err = accessreview.Download(ctx, grpcConn, &accessreview.DownloadRequest{
+			StoreID: pathParams["store_id"],
+			JobID:   pathParams["job_id"],
+		}, artifact)
+		if err != nil && !artifact.written {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+		if !artifact.written {
+			// the artifact is empty
+			artifact.writeHeader()
+		}
+	})
+}
+
+// registerJobsHandler serves the jobs service. POST /stores/{store_id}/jobs creates a job, whose
+// JSON body is a jobs.CreateRequest without its store, and GET lists the jobs of the store, or
+// returns one of them with GET /stores/{store_id}/jobs/{job_id}. POST
+// /stores/{store_id}/jobs/{job_id}/cancel requests the cancellation of a job. Likewise, POST
+// /stores/{store_id}/job-schedules creates a schedule of recurring jobs from a
+// jobs.CreateScheduleRequest without its store, GET lists or returns them, and DELETE
+// /stores/{store_id}/job-schedules/{schedule_id} deletes one. It calls the gRPC methods, so that
+// requests are authenticated and authorized like any other.
+func registerJobsHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	handle := func(method string, statusCode int, serve func(ctx context.Context, r *http.Request, pathParams map[string]string) (any, error)) grpc_runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+			ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, method)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			res, err := serve(ctx, r, pathParams)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(statusCode)
+			_ = json.NewEncoder(w).Encode(res)
 		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
-	} else {
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
-	if config.Trace.Enabled {
-		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	create := handle(jobs.CreateMethod, http.StatusAccepted, func(ctx context.Context, r *http.Request, pathParams map[string]string) (any, error) {
+		req := &jobs.CreateRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		req.StoreID = pathParams["store_id"]
+		return jobs.Create(ctx, grpcConn, req)
+	})
+	if err := mux.HandlePath(http.MethodPost, "/stores/{store_id}/jobs", create); err != nil {
+		return err
 	}
 
-	conn, err := grpc.NewClient(addr, dialOpts...)
-	if err != nil {
-		s.Logger.Fatal("failed to create gRPC client connection", zap.Error(err))
+	list := handle(jobs.ListMethod, http.StatusOK, func(ctx context.Context, _ *http.Request, pathParams map[string]string) (any, error) {
+		return jobs.List(ctx, grpcConn, &jobs.ListRequest{StoreID: pathParams["store_id"]})
+	})
+	if err := mux.HandlePath(http.MethodGet, "/stores/{store_id}/jobs", list); err != nil {
+		return err
 	}
-	return conn
-}
 
-func (s *ServerContext) runHTTPServer(ctx context.Context, config *serverconfig.Config, grpcConn *grpc.ClientConn) (*http.Server, error) {
-	muxOpts := []grpc_runtime.ServeMuxOption{
-		grpc_runtime.WithForwardResponseOption(httpmiddleware.HTTPResponseModifier),
-		grpc_runtime.WithErrorHandler(func(c context.Context, sr *grpc_runtime.ServeMux, mm grpc_runtime.Marshaler, w http.ResponseWriter, r *http.Request, e error) {
-			intCode := serverErrors.ConvertToEncodedErrorCode(status.Convert(e))
-			httpmiddleware.CustomHTTPErrorHandler(c, w, r, serverErrors.NewEncodedError(intCode, e.Error()))
-		}),
-		grpc_runtime.WithStreamErrorHandler(func(ctx context.Context, e error) *status.Status {
-			intCode := serverErrors.ConvertToEncodedErrorCode(status.Convert(e))
-			encodedErr := serverErrors.NewEncodedError(intCode, e.Error())
-			return status.Convert(encodedErr)
-		}),
-		grpc_runtime.WithHealthzEndpoint(healthv1pb.NewHealthClient(grpcConn)),
-		grpc_runtime.WithOutgoingHeaderMatcher(func(s string) (string, bool) { return s, true }),
-		grpc_runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
-			// Forward Openfga-Authorization-Model-Id header to gRPC metadata for AuthZEN endpoints.
-			if strings.EqualFold(key, server.AuthorizationModelIDHeader) {
-				return strings.ToLower(key), true
-			}
-			// Use default behavior for other headers
-			return grpc_runtime.DefaultHeaderMatcher(key)
-		}),
+	get := handle(jobs.GetMethod, http.StatusOK, func(ctx context.Context, _ *http.Request, pathParams map[string]string) (any, error) {
+		return jobs.Get(ctx, grpcConn, &jobs.GetRequest{
+			StoreID: pathParams["store_id"],
+			JobID:   pathParams["job_id"],
+		})
+	})
+	if err := mux.HandlePath(http.MethodGet, "/stores/{store_id}/jobs/{job_id}", get); err != nil {
+		return err
 	}
-	mux := grpc_runtime.NewServeMux(muxOpts...)
-	if err := openfgav1.RegisterOpenFGAServiceHandler(ctx, mux, grpcConn); err != nil {
-		return nil, err
+
+	cancel := handle(jobs.CancelMethod, http.StatusAccepted, func(ctx context.Context, _ *http.Request, pathParams map[string]string) (any, error) {
+		return jobs.Cancel(ctx, grpcConn, &jobs.CancelRequest{
+			StoreID: pathParams["store_id"],
+			JobID:   pathParams["job_id"],
+		})
+	})
+	if err := mux.HandlePath(http.MethodPost, "/stores/{store_id}/jobs/{job_id}/cancel", cancel); err != nil {
+		return err
 	}
-	if err := authzenv1.RegisterAuthZenServiceHandler(ctx, mux, grpcConn); err != nil {
-		return nil, err
+
+	createSchedule := handle(jobs.CreateScheduleMethod, http.StatusCreated, func(ctx context.Context, r *http.Request, pathParams map[string]string) (any, error) {
+		req := &jobs.CreateScheduleRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		req.StoreID = pathParams["store_id"]
+		return jobs.CreateSchedule(ctx, grpcConn, req)
+	})
+	if err := mux.HandlePath(http.MethodPost, "/stores/{store_id}/job-schedules", createSchedule); err != nil {
+		return err
 	}
-	handler := http.Handler(mux)
 
-	if config.Trace.Enabled {
-		handler = otelhttp.NewHandler(handler, "grpc-gateway")
+	listSchedules := handle(jobs.ListSchedulesMethod, http.StatusOK, func(ctx context.Context, _ *http.Request, pathParams map[string]string) (any, error) {
+		return jobs.ListSchedules(ctx, grpcConn, &jobs.ListSchedulesRequest{StoreID: pathParams["store_id"]})
+	})
+	if err := mux.HandlePath(http.MethodGet, "/stores/{store_id}/job-schedules", listSchedules); err != nil {
+		return err
 	}
 
-	httpServer := &http.Server{
-		Addr: config.HTTP.Addr,
-		Handler: recovery.HTTPPanicRecoveryHandler(cors.New(cors.Options{
-			AllowedOrigins:   config.HTTP.CORSAllowedOrigins,
-			AllowCredentials: true,
-			AllowedHeaders:   config.HTTP.CORSAllowedHeaders,
-			AllowedMethods: []string{
-				http.MethodGet, http.MethodPost,
-				http.MethodHead, http.MethodPatch, http.MethodDelete, http.MethodPut,
-			},
-		}).Handler(handler), s.Logger),
+	getSchedule := handle(jobs.GetScheduleMethod, http.StatusOK, func(ctx context.Context, _ *http.Request, pathParams map[string]string) (any, error) {
+		return jobs.GetSchedule(ctx, grpcConn, &jobs.GetScheduleRequest{
+			StoreID:    pathParams["store_id"],
+			ScheduleID: pathParams["schedule_id"],
+		})
+	})
+	if err := mux.HandlePath(http.MethodGet, "/stores/{store_id}/job-schedules/{schedule_id}", getSchedule); err != nil {
+		return err
 	}
 
-	listener, err := net.Listen("tcp", config.HTTP.Addr)
-	if err != nil {
-		return nil, err
+	deleteSchedule := handle(jobs.DeleteScheduleMethod, http.StatusOK, func(ctx context.Context, _ *http.Request, pathParams map[string]string) (any, error) {
+		return &jobs.DeleteScheduleResponse{}, jobs.DeleteSchedule(ctx, grpcConn, &jobs.DeleteScheduleRequest{
+			StoreID:    pathParams["store_id"],
+			ScheduleID: pathParams["schedule_id"],
+		})
+	})
+	return mux.HandlePath(http.MethodDelete, "/stores/{store_id}/job-schedules/{schedule_id}", deleteSchedule)
+}
+
+// artifactResponseWriter writes the headers of an access review artifact before its first chunk.
+type artifactResponseWriter struct {
+	http.ResponseWriter
+	jobID   string
+	written bool
+}
+
+func (w *artifactResponseWriter) writeHeader() {
+	w.written = true
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "access-review-"+w.jobID+".ndjson"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (w *artifactResponseWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.writeHeader()
 	}
+	return w.ResponseWriter.Write(p)
+}
 
-	if config.HTTP.TLS.Enabled {
-		if config.HTTP.TLS.CertPath == "" || config.HTTP.TLS.KeyPath == "" {
-			s.Logger.Fatal("'http.tls.cert' and 'http.tls.key' configs must be set")
+// registerStoreEventsHandler serves the store events service on GET /stores/{store_id}/events.
+// The types of the events are selected with the repeated type query parameter, and the page with
+// the page_size and continuation_token query parameters. It calls the gRPC method, so that
+// requests are authenticated and authorized like any other.
+func registerStoreEventsHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodGet, "/stores/{store_id}/events", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, storeevents.ReadEventsMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		query := r.URL.Query()
+		req := &storeevents.ReadRequest{
+			StoreID:           pathParams["store_id"],
+			Types:             query["type"],
+			ContinuationToken: query.Get("continuation_token"),
+		}
+		if pageSize := query.Get("page_size"); pageSize != "" {
+			parsed, err := strconv.ParseInt(pageSize, 10, 32)
+			if err != nil {
+				grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r,
+					status.Errorf(codes.InvalidArgument, "invalid page_size '%s'", pageSize))
+				return
+			}
+			req.PageSize = int32(parsed)
+		}
+
+		res, err := storeevents.ReadEvents(ctx, grpcConn, req)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
 		}
-		httpGetCertificate, err := watchAndLoadCertificateWithCertWatcher(ctx, config.HTTP.TLS.CertPath, config.HTTP.TLS.KeyPath, s.Logger, nil)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
+
+// registerListObjectsSubscriptionHandler serves the ListObjects subscription service on POST
+// /stores/{store_id}/list-objects/subscribe, whose JSON body is a ListObjectsRequest without its
+// store. The response has one listobjectssubscription.Delta per line, and ends with an
+// {"error":{...}} line if the subscription fails after its first delta. It calls the gRPC method,
+// so that requests are authenticated and authorized like any other.
+func registerListObjectsSubscriptionHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodPost, "/stores/{store_id}/list-objects/subscribe", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		inboundMarshaler, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, listobjectssubscription.SubscribeMethod)
 		if err != nil {
-			return nil, err
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
 		}
-		listener = tls.NewListener(listener, &tls.Config{
-			GetCertificate: httpGetCertificate,
+
+		req := &openfgav1.ListObjectsRequest{}
+		if err := inboundMarshaler.NewDecoder(r.Body).Decode(req); err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		req.StoreId = pathParams["store_id"]
+
+		// the headers are written with the first delta, so that the errors returned before it are
+		// still reported with their status
+		written := false
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		err = listobjectssubscription.Subscribe(ctx, grpcConn, req, func(delta *listobjectssubscription.Delta) error {
+			if !written {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+				written = true
+			}
+			if err := enc.Encode(delta); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
 		})
+		if err == nil || r.Context().Err() != nil {
+			return
+		}
+		if !written {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
 
-		s.Logger.Info("HTTP TLS is enabled, serving connections using the provided certificate")
-	} else {
-		s.Logger.Warn("HTTP TLS is disabled, serving connections using insecure plaintext")
-	}
+		st := status.Convert(err)
+		_ = enc.Encode(map[string]any{"error": map[string]any{"code": st.Code(), "message": st.Message()}})
+	})
+}
 
-	go func() {
-		s.Logger.Info(fmt.Sprintf("🚀 starting HTTP server on '%s'...", httpServer.Addr))
-		if err := httpServer.Serve(listener); err != nil {
-			if !errors.Is(err, http.ErrServerClosed) {
-				s.Logger.Fatal("HTTP server closed with unexpected error", zap.Error(err))
+// registerCacheInvalidationsHandler serves the cache invalidation service on GET
+// /stores/{store_id}/cache-invalidations, resumed from the continuation_token query parameter. The
+// response has one cacheinvalidation.Invalidation per line, and ends with an {"error":{...}} line if
+// the stream fails after it started. It calls the gRPC method, so that requests are authenticated
+// and authorized like any other.
+func registerCacheInvalidationsHandler(mux *grpc_runtime.ServeMux, grpcConn *grpc.ClientConn) error {
+	return mux.HandlePath(http.MethodGet, "/stores/{store_id}/cache-invalidations", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := grpc_runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := grpc_runtime.AnnotateContext(r.Context(), mux, r, cacheinvalidation.WatchMethod)
+		if err != nil {
+			grpc_runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		req := &cacheinvalidation.WatchRequest{
+			StoreID:           pathParams["store_id"],
+			ContinuationToken: r.URL.Query().Get("continuation_token"),
+		}
+
+		// the invalidations are sent as the writes happen, so the headers are written before the
+		// first one, and the errors returned after them are reported in the body
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		err = cacheinvalidation.Watch(ctx, grpcConn, req, func(invalidation *cacheinvalidation.Invalidation) error {
+			if err := enc.Encode(invalidation); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
 			}
+			return nil
+		})
+		if err == nil || r.Context().Err() != nil {
+			return
 		}
-		s.Logger.Info("HTTP server shut down.")
-	}()
-	return httpServer, nil
+
+		st := status.Convert(err)
+		_ = enc.Encode(map[string]any{"error": map[string]any{"code": st.Code(), "message": st.Message()}})
+	})
 }
 
 func (s *ServerContext) runPlaygroundServer(config *serverconfig.Config) (*http.Server, error) {
@@ -960,9 +2708,20 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		s.Logger.Info("graceful shutdown completed successfully")
 	}()
 
-	tracerProviderCloser := s.telemetryConfig(config)
+	tracerProviderCloser, traceSampler, err := s.telemetryConfig(config)
+	if err != nil {
+		return err
+	}
 	cleanups.PushFront(cleanupWithMessage(tracerProviderCloser, "tracing"))
 
+	auditEmitter, auditCloser, err := s.auditConfig(config)
+	if err != nil {
+		return err
+	}
+	if auditEmitter != nil {
+		cleanups.PushFront(cleanupWithMessage(auditCloser, "audit events"))
+	}
+
 	// Added temporarily to allow us to enable experimental features by default without allowing the user to disable them,
 	// eg for pipeline_list_objects.
 	config.Experimentals = append(serverconfig.DefaultConfig().Experimentals, config.Experimentals...)
@@ -975,6 +2734,13 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		s.Logger.Warn("AuthZEN experimental is enabled but 'authzen.baseURL' is not configured. The discovery endpoint (/.well-known/authzen-configuration/{store_id}) will not work. Set --authzen-base-url or OPENFGA_AUTHZEN_BASE_URL to fix this.")
 	}
 
+	if err := condition.RegisterExtensions(config.ConditionExtensions); err != nil {
+		return fmt.Errorf("config 'conditionExtensions': %w", err)
+	}
+
+	s.secretsWatcher = secrets.NewWatcher(secrets.NewResolver(), config.Secrets.RefreshInterval, s.Logger)
+	go s.secretsWatcher.Run(ctx)
+
 	datastore, continuationTokenSerializer, err := s.datastoreConfig(config)
 	if err != nil {
 		return err
@@ -986,7 +2752,58 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 	}
 	cleanups.PushFront(cleanupFromPlainFunc(authenticator.Close, "authenticator"))
 
-	serverOpts, prometheusMetrics, err := s.buildServerOpts(ctx, config, authenticator)
+	// the priority scheduler is shared by the interceptors and the adaptive limits of BatchCheck
+	var priorityScheduler *priority.Scheduler
+	var batchCheckLoadSignal server.BatchCheckLoadSignal
+	if config.PriorityScheduling.Enabled {
+		storeWeights, err := priority.ParseStoreWeights(config.PriorityScheduling.StoreWeights)
+		if err != nil {
+			return fmt.Errorf("config 'priorityScheduling.storeWeights': %w", err)
+		}
+		priorityScheduler = priority.NewScheduler(config.PriorityScheduling.MaxInFlight, storeWeights)
+
+		if config.BatchCheckAdaptiveLimits.Enabled {
+			batchCheckLoadSignal = priorityScheduler
+			s.Logger.Info(fmt.Sprintf("batch check adaptive limits are enabled above a load of %v", config.BatchCheckAdaptiveLimits.LoadThreshold))
+		}
+	}
+
+	conditionContextHeaders, err := server.ParseConditionContextHeaders(config.ConditionContext.Headers)
+	if err != nil {
+		return fmt.Errorf("config 'conditionContext.headers': %w", err)
+	}
+	conditionContextClaims, err := server.ParseConditionContextClaims(config.ConditionContext.Claims)
+	if err != nil {
+		return fmt.Errorf("config 'conditionContext.claims': %w", err)
+	}
+
+	// the server closes the hook
+	var writeValidationHook writehook.Hook
+	if config.WriteValidationHook.Enabled {
+		writeValidationHook, err = writehook.New(config.WriteValidationHook.URL)
+		if err != nil {
+			return fmt.Errorf("config 'writeValidationHook.url': %w", err)
+		}
+		s.Logger.Info(fmt.Sprintf("write validation hook is enabled on '%s' (fail open: %t)", config.WriteValidationHook.URL, config.WriteValidationHook.FailOpen))
+	}
+
+	// the server closes the hook
+	var contextEnrichmentHook contexthook.Hook
+	if config.ContextEnrichmentHook.Enabled {
+		contextEnrichmentHook, err = contexthook.New(config.ContextEnrichmentHook.URL)
+		if err != nil {
+			return fmt.Errorf("config 'contextEnrichmentHook.url': %w", err)
+		}
+		if config.ContextEnrichmentHook.CacheTTL > 0 {
+			contextEnrichmentHook, err = contexthook.NewCachedHook(contextEnrichmentHook, config.ContextEnrichmentHook.CacheTTL, config.ContextEnrichmentHook.CacheMaxEntries)
+			if err != nil {
+				return fmt.Errorf("config 'contextEnrichmentHook.cacheMaxEntries': %w", err)
+			}
+		}
+		s.Logger.Info(fmt.Sprintf("context enrichment hook is enabled on '%s' (cache TTL: %s)", config.ContextEnrichmentHook.URL, config.ContextEnrichmentHook.CacheTTL))
+	}
+
+	serverOpts, prometheusMetrics, err := s.buildServerOpts(ctx, config, authenticator, priorityScheduler, auditEmitter)
 	if prometheusMetrics != nil {
 		defer prometheus.Unregister(prometheusMetrics)
 	}
@@ -994,6 +2811,41 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		return err
 	}
 
+	var sloTracker *slo.Tracker
+	if config.SLO.Enabled {
+		objectives, err := slo.ParseObjectives(config.SLO.Objectives)
+		if err != nil {
+			return fmt.Errorf("config 'slo.objectives': %w", err)
+		}
+
+		sloTracker = slo.NewTracker(objectives, config.SLO.Window)
+		prometheus.MustRegister(sloTracker)
+		defer prometheus.Unregister(sloTracker)
+
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(sloTracker.NewUnaryInterceptor()),
+			grpc.ChainStreamInterceptor(sloTracker.NewStreamingInterceptor()))
+
+		s.Logger.Info(fmt.Sprintf("service level objectives are tracked over a window of %s", config.SLO.Window))
+	}
+
+	if len(s.UnaryInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(s.UnaryInterceptors...))
+	}
+	if len(s.StreamInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(s.StreamInterceptors...))
+	}
+
+	if config.RequestRecording.Enabled {
+		recorder, err := s.requestRecorderConfig(config)
+		if err != nil {
+			return err
+		}
+		// the recorder must be the last interceptor, so that only authenticated and valid requests are recorded
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(recorder.UnaryServerInterceptor()))
+		cleanups.PushFront(cleanupWithMessage(func(context.Context) error { return recorder.Close() }, "request recorder"))
+	}
+
 	var profilerServer *http.Server
 	if config.Profiler.Enabled {
 		mux := http.NewServeMux()
@@ -1023,6 +2875,15 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 	if config.Metrics.Enabled {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", promhttp.Handler())
+		if sloTracker != nil {
+			mux.Handle("/slo", sloTracker)
+		}
+		if traceSampler != nil {
+			mux.Handle("/trace/sampling", traceSampler)
+		}
+		if reporter, ok := datastore.(sqlcommon.MaintenanceReporter); ok {
+			mux.Handle("/datastore/maintenance", sqlcommon.NewMaintenanceHandler(reporter))
+		}
 
 		metricsServer = &http.Server{Addr: config.Metrics.Addr, Handler: mux}
 
@@ -1039,20 +2900,177 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		cleanups.PushFront(cleanupWithMessage(metricsServer.Shutdown, "prometheus metrics server"))
 	}
 
+	changelogRetentionStorePolicies, err := changelog.ParseStorePolicies(config.ChangelogRetention.StorePolicies)
+	if err != nil {
+		return fmt.Errorf("config 'changelogRetention.storePolicies': %w", err)
+	}
+
+	checkStoreBulkheadLimits, err := graph.ParseStoreBulkheadLimits(config.CheckStoreBulkhead.StoreLimits)
+	if err != nil {
+		return fmt.Errorf("config 'checkStoreBulkhead.storeLimits': %w", err)
+	}
+
+	writeThrottlingStoreTuples, err := throttler.ParseStoreWriteRates(config.WriteThrottling.StoreTuplesPerSecond)
+	if err != nil {
+		return fmt.Errorf("config 'writeThrottling.storeTuplesPerSecond': %w", err)
+	}
+
+	writeThrottlingStoreChanges, err := throttler.ParseStoreWriteRates(config.WriteThrottling.StoreChangesPerSecond)
+	if err != nil {
+		return fmt.Errorf("config 'writeThrottling.storeChangesPerSecond': %w", err)
+	}
+
+	var checkCoalescingWindow time.Duration
+	if config.CheckCoalescing.Enabled {
+		checkCoalescingWindow = config.CheckCoalescing.Window
+		s.Logger.Info(fmt.Sprintf("check coalescing is enabled with a window of %s", checkCoalescingWindow))
+	}
+
+	var writeSchedulingMaxInFlight int
+	var writeSchedulingStoreWeights map[string]float64
+	if config.WriteScheduling.Enabled {
+		writeSchedulingStoreWeights, err = priority.ParseStoreWeights(config.WriteScheduling.StoreWeights)
+		if err != nil {
+			return fmt.Errorf("config 'writeScheduling.storeWeights': %w", err)
+		}
+		writeSchedulingMaxInFlight = config.WriteScheduling.MaxInFlight
+		s.Logger.Info(fmt.Sprintf("write scheduling is enabled with %d writes in flight", writeSchedulingMaxInFlight))
+	}
+
+	var materializedRelations []materialize.Relation
+	if config.MaterializedPermissions.Enabled {
+		materializedRelations, err = materialize.ParseRelations(config.MaterializedPermissions.Relations)
+		if err != nil {
+			return fmt.Errorf("config 'materializedPermissions.relations': %w", err)
+		}
+		s.Logger.Info(fmt.Sprintf("materialized permissions are enabled for %d relations", len(materializedRelations)))
+	}
+
+	checkCache, err := s.checkCacheConfig(config)
+	if err != nil {
+		return err
+	}
+
+	latestModelCache, err := s.latestModelCacheConfig(config)
+	if err != nil {
+		return err
+	}
+
+	typesystemCompilationCache, err := s.typesystemCompilationCacheConfig(config)
+	if err != nil {
+		return err
+	}
+
+	changeListener, err := s.changeListenerConfig(config, datastore)
+	if err != nil {
+		return err
+	}
+
+	datastoreWrappers := s.DatastoreWrappers
+	if config.UserIDEncryption.Enabled {
+		codec, err := s.userIDCodecConfig(config)
+		if err != nil {
+			return err
+		}
+		var encryptedOpts []storagewrappers.EncryptedDatastoreOpt
+		if config.Datastore.Engine == "mysql" {
+			// the user column of the tuples of MySQL is a VARCHAR(256)
+			encryptedOpts = append(encryptedOpts, storagewrappers.WithMaxEncodedUserBytes(256))
+		}
+		// inside the custom wrappers, so that they see the users decoded
+		datastoreWrappers = append([]server.DatastoreWrapper{func(ds storage.OpenFGADatastore) storage.OpenFGADatastore {
+			return storagewrappers.NewEncryptedDatastore(ds, codec, config.UserIDEncryption.Types, encryptedOpts...)
+		}}, datastoreWrappers...)
+		s.Logger.Info(fmt.Sprintf("the IDs of the users of the types %v are encrypted at rest", config.UserIDEncryption.Types))
+	}
+	if config.Datastore.Metrics.Enabled {
+		// the innermost wrapper, to time the queries that reach the datastore
+		datastoreWrappers = append([]server.DatastoreWrapper{func(ds storage.OpenFGADatastore) storage.OpenFGADatastore {
+			return storagewrappers.NewInstrumentedDatastore(ds, config.Datastore.Engine)
+		}}, datastoreWrappers...)
+	}
+
+	var multiRegionID uint8
+	if config.MultiRegion.Enabled {
+		multiRegionID = uint8(config.MultiRegion.RegionID) // verified to be between 1 and 255
+	}
+
 	svr := server.MustNewServerWithOpts(
 		server.WithDatastore(datastore),
+		server.WithDatastoreWrappers(datastoreWrappers...),
+		server.WithCheckResolvers(s.CheckResolvers...),
+		server.WithCheckCache(checkCache),
+		server.WithChangeListener(changeListener),
 		server.WithAuthzenBaseURL(config.Authzen.BaseURL),
 		server.WithContinuationTokenSerializer(continuationTokenSerializer),
 		server.WithAuthorizationModelCacheSize(config.Datastore.MaxCacheSize),
 		server.WithTypesystemCacheSize(config.Datastore.MaxTypesystemCacheSize),
+		server.WithLatestAuthorizationModelCacheTTL(config.Datastore.LatestModelCacheTTL),
+		server.WithLatestAuthorizationModelCache(latestModelCache),
+		server.WithTypesystemCompilationCache(typesystemCompilationCache),
 		server.WithLogger(s.Logger),
 		server.WithTransport(gateway.NewRPCTransport(s.Logger)),
 		server.WithResolveNodeLimit(config.ResolveNodeLimit),
 		server.WithResolveNodeBreadthLimit(config.ResolveNodeBreadthLimit),
 		server.WithChangelogHorizonOffset(config.ChangelogHorizonOffset),
+		server.WithChangelogRetentionEnabled(config.ChangelogRetention.Enabled),
+		server.WithChangelogRetentionInterval(config.ChangelogRetention.Interval),
+		server.WithChangelogRetentionPolicy(config.ChangelogRetention.MaxAge, config.ChangelogRetention.MaxEntries),
+		server.WithChangelogRetentionStorePolicies(changelogRetentionStorePolicies),
+		server.WithChangelogRetentionCursorLease(config.ChangelogRetention.CursorLease),
+		server.WithOrphanedTuplesCollectorEnabled(config.OrphanedTuples.CollectorEnabled),
+		server.WithOrphanedTuplesCollectorInterval(config.OrphanedTuples.CollectorInterval),
+		server.WithOrphanedTuplesCollectorDryRun(config.OrphanedTuples.CollectorDryRun),
+		server.WithStorePurgeEnabled(config.StorePurge.Enabled),
+		server.WithStoreEventsEnabled(config.StoreEvents.Enabled),
+		server.WithListObjectsSubscriptionPollInterval(config.ListObjectsSubscription.PollInterval),
+		server.WithCacheHintsEnabled(config.CacheHints.Enabled),
+		server.WithCacheHintsMaxAge(config.CacheHints.MaxAge),
+		server.WithCacheInvalidationPollInterval(config.CacheHints.InvalidationPollInterval),
+		server.WithSessionTokensEnabled(config.SessionTokens.Enabled),
+		server.WithSessionTokensCommitMargin(config.SessionTokens.CommitMargin),
+		server.WithAssertionCoverageEnabled(config.AssertionCoverage.Enabled),
+		server.WithCostEstimateEnabled(config.CostEstimate.Enabled),
+		server.WithCostEstimateSampleSize(config.CostEstimate.SampleSize),
+		server.WithWhatIfEnabled(config.WhatIf.Enabled),
+		server.WithModelSimulationEnabled(config.ModelSimulation.Enabled),
+		server.WithAccessReviewEnabled(config.AccessReview.Enabled),
+		server.WithAccessReviewArtifactDir(config.AccessReview.ArtifactDir),
+		server.WithJobsEnabled(config.Jobs.Enabled),
+		server.WithJobsWorkers(config.Jobs.Workers),
+		server.WithJobsQueueSize(config.Jobs.QueueSize),
+		server.WithJobsRetention(config.Jobs.Retention),
+		server.WithJobsScheduleInterval(config.Jobs.ScheduleInterval),
+		server.WithMultiRegionID(multiRegionID),
+		server.WithShadowCheckResolverTimeout(config.ShadowCheck.Timeout),
+		server.WithShadowCheckResolverSamplePercentage(config.ShadowCheck.SamplePercentage),
+		server.WithConsistencyVerifierEnabled(config.ConsistencyVerifier.Enabled),
+		server.WithConsistencyVerifierInterval(config.ConsistencyVerifier.Interval),
+		server.WithConsistencyVerifierSampleSize(config.ConsistencyVerifier.SampleSize),
+		server.WithHotPathsEnabled(config.HotPaths.Enabled),
+		server.WithHotPathsInterval(config.HotPaths.Interval),
+		server.WithHotPathsTopN(config.HotPaths.TopN),
+		server.WithHotPathsMinChecks(config.HotPaths.MinChecks),
+		server.WithHotPathsMaxObjects(config.HotPaths.MaxObjects),
+		server.WithMaterializedPermissions(materializedRelations, config.MaterializedPermissions.Interval),
+		server.WithMaterializedObjectListsEnabled(config.MaterializedObjectLists.Enabled),
+		server.WithMaterializedObjectListsInterval(config.MaterializedObjectLists.Interval),
+		server.WithMaterializedObjectListsTopN(config.MaterializedObjectLists.TopN),
+		server.WithMaterializedObjectListsMinRequests(config.MaterializedObjectLists.MinRequests),
+		server.WithClusterPeers(config.Cluster.SelfAddress, config.Cluster.Peers),
+		server.WithClusterSingleflightEnabled(config.Cluster.SingleflightEnabled),
+		server.WithClusterForwardTimeout(config.Cluster.ForwardTimeout),
+		server.WithClusterTLSEnabled(config.Cluster.TLSEnabled),
+		server.WithClusterDispatchEnabled(config.Cluster.DispatchEnabled),
+		server.WithClusterGossip(config.Cluster.Gossip.BindAddress, config.Cluster.Gossip.Join),
+		server.WithClusterDispatchTier(config.Cluster.DispatchTier),
+		server.WithServerTimingEnabled(config.HTTP.ServerTimingEnabled),
 		server.WithReadChangesMaxPageSize(config.ReadChangesMaxPageSize),
 		server.WithListObjectsDeadline(config.ListObjectsDeadline),
 		server.WithListObjectsMaxResults(config.ListObjectsMaxResults),
+		server.WithStreamedListObjectsMaxInFlightMessages(config.StreamedListObjectsMaxInFlightMessages),
+		server.WithStreamedListObjectsMessagesPerSecond(config.StreamedListObjectsMessagesPerSecond),
+		server.WithContextualTuplesValidationMode(config.ContextualTuplesValidationMode),
 		server.WithListObjectsPipelineEnabled(config.ListObjectsPipelineEnabled),
 		server.WithListUsersDeadline(config.ListUsersDeadline),
 		server.WithListUsersMaxResults(config.ListUsersMaxResults),
@@ -1062,6 +3080,9 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithCacheControllerEnabled(config.CacheController.Enabled),
 		server.WithCacheControllerTTL(config.CacheController.TTL),
 		server.WithCheckCacheLimit(config.CheckCache.Limit),
+		server.WithCheckCacheMaxBytes(config.CheckCache.MaxBytes),
+		server.WithCheckCacheShards(config.CheckCache.Shards),
+		server.WithCheckCacheDiskPath(config.CheckCache.DiskPath),
 		server.WithCheckIteratorCacheEnabled(config.CheckIteratorCache.Enabled),
 		server.WithCheckIteratorCacheMaxResults(config.CheckIteratorCache.MaxResults),
 		server.WithCheckIteratorCacheTTL(config.CheckIteratorCache.TTL),
@@ -1084,14 +3105,29 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithListUsersDispatchThrottlingThreshold(config.ListUsersDispatchThrottling.Threshold),
 		server.WithListUsersDispatchThrottlingMaxThreshold(config.ListUsersDispatchThrottling.MaxThreshold),
 		server.WithCheckDatabaseThrottle(config.CheckDatastoreThrottle.Threshold, config.CheckDatastoreThrottle.Duration),
+		server.WithCheckStoreBulkheads(config.CheckStoreBulkhead.Limit, checkStoreBulkheadLimits),
+		server.WithCheckCoalescingWindow(checkCoalescingWindow),
+		server.WithWriteThrottling(throttler.WriteRates{
+			TuplesPerSecond:  config.WriteThrottling.TuplesPerSecond,
+			ChangesPerSecond: config.WriteThrottling.ChangesPerSecond,
+		}, writeThrottlingStoreTuples, writeThrottlingStoreChanges),
+		server.WithWriteScheduling(writeSchedulingMaxInFlight, writeSchedulingStoreWeights),
 		server.WithListObjectsDatabaseThrottle(config.ListObjectsDatastoreThrottle.Threshold, config.ListObjectsDatastoreThrottle.Duration),
 		server.WithListUsersDatabaseThrottle(config.ListUsersDatastoreThrottle.Threshold, config.ListUsersDatastoreThrottle.Duration),
 		server.WithListObjectsIteratorCacheEnabled(config.ListObjectsIteratorCache.Enabled),
 		server.WithListObjectsIteratorCacheMaxResults(config.ListObjectsIteratorCache.MaxResults),
 		server.WithListObjectsIteratorCacheTTL(config.ListObjectsIteratorCache.TTL),
+		server.WithListObjectsQueryCacheEnabled(config.ListObjectsQueryCache.Enabled),
+		server.WithListObjectsQueryCacheMaxResults(config.ListObjectsQueryCache.MaxResults),
+		server.WithListObjectsQueryCacheTTL(config.ListObjectsQueryCache.TTL),
 		server.WithCacheTTLJitterPercentage(config.CacheTTLJitterPercentage),
 		server.WithMaxChecksPerBatchCheck(config.MaxChecksPerBatchCheck),
 		server.WithMaxConcurrentChecksPerBatchCheck(config.MaxConcurrentChecksPerBatchCheck),
+		server.WithBatchCheckAdaptiveLimits(batchCheckLoadSignal, config.BatchCheckAdaptiveLimits.LoadThreshold, config.BatchCheckAdaptiveLimits.MinRatio),
+		server.WithWriteValidationHook(writeValidationHook, config.WriteValidationHook.Timeout, config.WriteValidationHook.FailOpen),
+		server.WithContextEnrichmentHook(contextEnrichmentHook, config.ContextEnrichmentHook.Timeout),
+		server.WithConditionContextHeaders(conditionContextHeaders),
+		server.WithConditionContextClaims(conditionContextClaims),
 		server.WithSharedIteratorEnabled(config.SharedIterator.Enabled),
 		server.WithSharedIteratorLimit(config.SharedIterator.Limit),
 		server.WithPlanner(planner.New(&planner.Config{
@@ -1121,6 +3157,59 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 	grpcServer := grpc.NewServer(serverOpts...)
 	openfgav1.RegisterOpenFGAServiceServer(grpcServer, svr)
 	authzenv1.RegisterAuthZenServiceServer(grpcServer, svr)
+	if config.Cluster.DispatchEnabled || config.Cluster.DispatchServiceEnabled {
+		cluster.RegisterDispatchServer(grpcServer, svr)
+	}
+	if config.StreamedBatchCheck.Enabled {
+		streamedbatchcheck.RegisterServer(grpcServer, svr)
+		s.Logger.Info("streamed batch check service is enabled")
+	}
+	if config.BulkWrite.Enabled {
+		bulkwrite.RegisterServer(grpcServer, svr, bulkwrite.WithWindow(config.BulkWrite.Window))
+		s.Logger.Info(fmt.Sprintf("bulk write service is enabled with a window of %d chunks", config.BulkWrite.Window))
+	}
+	if config.StorePurge.Enabled {
+		s.Logger.Info("store purge is enabled")
+	}
+	if config.StoreEvents.Enabled {
+		storeevents.RegisterServer(grpcServer, svr)
+		s.Logger.Info("store events service is enabled")
+	}
+	if config.ListObjectsSubscription.Enabled {
+		listobjectssubscription.RegisterServer(grpcServer, svr)
+		s.Logger.Info("ListObjects subscription service is enabled")
+	}
+	if config.CacheHints.Enabled {
+		cacheinvalidation.RegisterServer(grpcServer, svr)
+		s.Logger.Info("cache invalidation service is enabled")
+	}
+	if config.AssertionCoverage.Enabled {
+		assertioncoverage.RegisterServer(grpcServer, svr)
+		s.Logger.Info("assertion coverage service is enabled")
+	}
+	if config.CostEstimate.Enabled {
+		costestimate.RegisterServer(grpcServer, svr)
+		s.Logger.Info("cost estimate service is enabled")
+	}
+	if config.WhatIf.Enabled {
+		whatif.RegisterServer(grpcServer, svr)
+		s.Logger.Info("what-if service is enabled")
+	}
+	if config.ModelSimulation.Enabled {
+		modelsimulation.RegisterServer(grpcServer, svr)
+		s.Logger.Info("model simulation service is enabled")
+	}
+	if config.AccessReview.Enabled {
+		accessreview.RegisterServer(grpcServer, svr)
+		s.Logger.Info("access review service is enabled")
+	}
+	if config.Jobs.Enabled {
+		jobs.RegisterServer(grpcServer, svr)
+		s.Logger.Info("jobs service is enabled")
+	}
+	if config.MultiRegion.Enabled {
+		s.Logger.Info(fmt.Sprintf("multi-region mode is enabled in region %d", config.MultiRegion.RegionID))
+	}
 	healthServer := &health.Checker{TargetService: svr, TargetServiceName: openfgav1.OpenFGAService_ServiceDesc.ServiceName}
 	healthv1pb.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
@@ -1178,7 +3267,7 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 			_ = grpcConn.Close()
 		}, "internal grpc client connection"))
 
-		httpServer, err = s.runHTTPServer(ctx, config, grpcConn)
+		httpServer, err = s.runHTTPServer(ctx, config, grpcConn, conditionContextHeaders)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,7 @@
 package run
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
@@ -21,10 +22,13 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/oklog/ulid/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spf13/cobra"
@@ -33,7 +37,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -41,14 +49,33 @@ import (
 
 	"github.com/openfga/openfga/cmd"
 	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/authn"
+	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/orphans"
+	"github.com/openfga/openfga/internal/recording"
+	"github.com/openfga/openfga/internal/secrets"
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/pkg/authclaims"
+	"github.com/openfga/openfga/pkg/authn/providers"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/middleware/requestid"
+	"github.com/openfga/openfga/pkg/middleware/slo"
 	"github.com/openfga/openfga/pkg/middleware/storeid"
 	"github.com/openfga/openfga/pkg/server"
+	"github.com/openfga/openfga/pkg/server/bulkwrite"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/server/contexthook"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/server/listobjectssubscription"
+	"github.com/openfga/openfga/pkg/server/storeevents"
+	"github.com/openfga/openfga/pkg/server/writehook"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/caches"
+	"github.com/openfga/openfga/pkg/storage/drivers"
+	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
 	"github.com/openfga/openfga/pkg/storage/sqlite"
 	storagefixtures "github.com/openfga/openfga/pkg/testfixtures/storage"
@@ -192,13 +219,16 @@ type authTest struct {
 	expectedStatusCode    int
 }
 
-func runServer(ctx context.Context, cfg *serverconfig.Config) error {
+func runServer(ctx context.Context, cfg *serverconfig.Config, opts ...ServerContextOption) error {
 	if err := cfg.Verify(); err != nil {
 		return err
 	}
 
 	logger := logger.MustNewLogger(cfg.Log.Format, cfg.Log.Level, cfg.Log.TimestampFormat)
 	serverCtx := &ServerContext{Logger: logger}
+	for _, opt := range opts {
+		opt(serverCtx)
+	}
 	return serverCtx.Run(ctx, cfg)
 }
 
@@ -214,6 +244,81 @@ func TestBuildServiceWithPresharedKeyAuthenticationFailsIfZeroKeys(t *testing.T)
 	require.EqualError(t, err, "failed to initialize authenticator: invalid auth configuration, please specify at least one key")
 }
 
+func TestBuildServiceWithUnknownConditionExtensionFails(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.ConditionExtensions = []string{"strings", "unknown"}
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorIs(t, err, condition.ErrUnknownExtension)
+	require.ErrorContains(t, err, "config 'conditionExtensions'")
+}
+
+func TestBuildServiceWithInvalidTraceSampleRatiosFails(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Trace.Enabled = true
+	cfg.Trace.StoreSampleRatios = []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV=2"}
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorIs(t, err, telemetry.ErrInvalidSamplingRatio)
+	require.ErrorContains(t, err, "config 'trace.storeSampleRatios'")
+}
+
+func TestBuildServiceWithInvalidWriteValidationHookURLFails(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.WriteValidationHook.Enabled = true
+	cfg.WriteValidationHook.URL = "validator:50051"
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorIs(t, err, writehook.ErrInvalidURL)
+	require.ErrorContains(t, err, "config 'writeValidationHook.url'")
+}
+
+func TestBuildServiceWithInvalidContextEnrichmentHookURLFails(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.ContextEnrichmentHook.Enabled = true
+	cfg.ContextEnrichmentHook.URL = "attributes:50051"
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorIs(t, err, contexthook.ErrInvalidURL)
+	require.ErrorContains(t, err, "config 'contextEnrichmentHook.url'")
+}
+
+func TestBuildServiceWithInvalidUserIDEncryptionKeyFails(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.UserIDEncryption.Enabled = true
+	cfg.UserIDEncryption.Key = "c2hvcnQ=" // 5 bytes
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorContains(t, err, "config 'userIDEncryption.key'")
+}
+
+func TestBuildServiceWithInvalidConditionContextHeadersFails(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.ConditionContext.Headers = []string{"X-Client-Region"}
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorIs(t, err, server.ErrInvalidConditionContextHeader)
+	require.ErrorContains(t, err, "config 'conditionContext.headers'")
+}
+
 func TestBuildServiceWithNoAuth(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -298,167 +403,205 @@ func TestBuildServiceWithPresharedKeyAuthentication(t *testing.T) {
 	}
 }
 
-func TestBuildServiceWithTracingEnabled(t *testing.T) {
+// tokenAuthenticator authenticates the requests with the bearer token 'test-token'.
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) Authenticate(ctx context.Context) (*authclaims.AuthClaims, error) {
+	token, err := grpcauth.AuthFromMD(ctx, "Bearer")
+	if err != nil {
+		return nil, providers.ErrMissingBearerToken
+	}
+	if token != "test-token" {
+		return nil, providers.ErrUnauthenticated
+	}
+	return &authclaims.AuthClaims{Subject: "test-subject"}, nil
+}
+
+func (tokenAuthenticator) Close() {}
+
+var registerTestProvidersOnce sync.Once
+
+// registerTestProviders registers the authentication providers of the tests once, since providers
+// cannot be registered twice.
+func registerTestProviders() {
+	registerTestProvidersOnce.Do(func() {
+		providers.Register("test-token", providers.Provider{
+			New: func(*serverconfig.AuthnConfig) (providers.Authenticator, error) {
+				return tokenAuthenticator{}, nil
+			},
+		})
+		providers.Register("test-failing", providers.Provider{
+			New: func(*serverconfig.AuthnConfig) (providers.Authenticator, error) {
+				return nil, errors.New("cannot connect to the authentication service")
+			},
+		})
+	})
+}
+
+func TestBuildServiceWithAuthenticationProvider(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
-	// create mock OTLP server
-	otlpServerPort, otlpServerPortReleaser := testutils.TCPRandomPort()
-	localOTLPServerURL := fmt.Sprintf("localhost:%d", otlpServerPort)
-	otlpServerPortReleaser()
-	otlpServer := mocks.NewMockTracingServer(t, otlpServerPort)
+	registerTestProviders()
+
+	t.Run("unknown_provider_fails", func(t *testing.T) {
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.Authn.Method = "test-unknown"
+
+		err := runServer(context.Background(), cfg)
+		require.EqualError(t, err, "unsupported authentication method 'test-unknown'")
+	})
+
+	t.Run("failing_provider_fails", func(t *testing.T) {
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.Authn.Method = "test-failing"
+
+		err := runServer(context.Background(), cfg)
+		require.EqualError(t, err, "failed to initialize authenticator: cannot connect to the authentication service")
+	})
 
-	// create OpenFGA server with tracing enabled
 	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Trace.Enabled = true
-	cfg.Trace.SampleRatio = 1
-	cfg.Trace.OTLP.Endpoint = localOTLPServerURL
-	cfg.Trace.OTLP.TLS.Enabled = false
+	cfg.Authn.Method = "test-token"
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	serverDone := make(chan error)
 	go func() {
-		serverDone <- runServer(ctx, cfg)
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
 	}()
+
 	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
-	// attempt a random request
-	client := retryablehttp.NewClient()
-	t.Cleanup(client.HTTPClient.CloseIdleConnections)
-	response, err := client.Get(fmt.Sprintf("http://%s/healthz", cfg.HTTP.Addr))
-	require.NoError(t, err)
+	tests := []authTest{{
+		_name:      "Header_with_incorrect_token_fails",
+		authHeader: "Bearer incorrecttoken",
+		expectedErrorResponse: &serverErrors.ErrorResponse{
+			Code:    "unauthenticated",
+			Message: "unauthenticated",
+		},
+		expectedStatusCode: 401,
+	}, {
+		_name:      "Missing_header_fails",
+		authHeader: "",
+		expectedErrorResponse: &serverErrors.ErrorResponse{
+			Code:    "bearer_token_missing",
+			Message: "missing bearer token",
+		},
+		expectedStatusCode: 401,
+	}, {
+		_name:              "Correct_token_succeeds",
+		authHeader:         "Bearer test-token",
+		expectedStatusCode: 200,
+	}}
+
+	retryClient := retryablehttp.NewClient()
+	for _, test := range tests {
+		t.Run(test._name, func(t *testing.T) {
+			tryGetStores(t, test, cfg.HTTP.Addr, retryClient)
+		})
+	}
+}
 
+func TestServerWithBulkWrite(t *testing.T) {
 	t.Cleanup(func() {
-		err := response.Body.Close()
-		require.NoError(t, err)
+		goleak.VerifyNone(t)
 	})
 
-	cancel()
-	select {
-	case <-time.After(5 * time.Second):
-		require.Fail(t, "timed out")
-	case err := <-serverDone:
-		require.NoError(t, err)
-	}
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.BulkWrite.Enabled = true
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
 
-	// at this point, all spans should have been forcefully exported
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
 
-	require.Equal(t, 1, otlpServer.GetExportCount())
-}
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
-func tryStreamingListObjects(t *testing.T, test authTest, httpAddr string, retryClient *retryablehttp.Client, validToken string) {
-	// create a store
-	createStorePayload := strings.NewReader(`{"name": "some-store-name"}`)
-	req, err := retryablehttp.NewRequest("POST", fmt.Sprintf("http://%s/stores", httpAddr), createStorePayload)
-	require.NoError(t, err, "Failed to construct create store request")
-	req.Header.Set("content-type", "application/json")
-	req.Header.Set("authorization", fmt.Sprintf("Bearer %s", validToken))
-	res, err := retryClient.Do(req)
-	require.NoError(t, err, "Failed to execute create store request")
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	require.NoError(t, err, "Failed to read create store response")
-	var createStoreResponse openfgav1.CreateStoreResponse
-	err = protojson.Unmarshal(body, &createStoreResponse)
-	require.NoError(t, err, "Failed to unmarshal create store response")
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
 
-	// create an authorization model
-	authModelPayload := strings.NewReader(`{
-  "type_definitions": [
-    {
-      "type": "user",
-      "relations": {}
-    },
-    {
-      "type": "document",
-      "relations": {
-        "owner": {
-          "this": {}
-        }
-      },
-      "metadata": {
-        "relations": {
-          "owner": {
-            "directly_related_user_types": [
-              {
-                "type": "user"
-              }
-            ]
-          }
-        }
-      }
-    }
-  ],
-  "schema_version": "1.1"
-}`)
-	req, err = retryablehttp.NewRequest("POST", fmt.Sprintf("http://%s/stores/%s/authorization-models", httpAddr, createStoreResponse.GetId()), authModelPayload)
-	require.NoError(t, err, "Failed to construct create authorization model request")
-	req.Header.Set("content-type", "application/json")
-	req.Header.Set("authorization", fmt.Sprintf("Bearer %s", validToken))
-	response, err := retryClient.Do(req)
-	require.NoError(t, err, "Failed to execute create authorization model request")
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "bulk"})
+	require.NoError(t, err)
 
-	t.Cleanup(func() {
-		err := response.Body.Close()
-		require.NoError(t, err)
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
 	})
+	require.NoError(t, err)
 
-	// call one streaming endpoint
-	listObjectsPayload := strings.NewReader(`{"type": "document", "user": "user:anne", "relation": "owner"}`)
-	req, err = retryablehttp.NewRequest("POST", fmt.Sprintf("http://%s/stores/%s/streamed-list-objects", httpAddr, createStoreResponse.GetId()), listObjectsPayload)
-	require.NoError(t, err, "Failed to construct request")
-	req.Header.Set("content-type", "application/json")
-	req.Header.Set("authorization", test.authHeader)
-	res, err = retryClient.Do(req)
-	require.NoError(t, err, "Failed to execute streaming request")
-	defer res.Body.Close()
-	require.Equal(t, test.expectedStatusCode, res.StatusCode)
-	body, err = io.ReadAll(res.Body)
-	require.NoError(t, err, "Failed to read response")
+	stream, err := bulkwrite.NewStream(ctx, conn)
+	require.NoError(t, err)
 
-	if test.expectedErrorResponse != nil {
-		require.Contains(t, string(body), fmt.Sprintf(",\"message\":\"%s\"", test.expectedErrorResponse.Message))
+	chunks := [][]*openfgav1.TupleKey{
+		{tuple.NewTupleKey("document:1", "viewer", "user:anne"), tuple.NewTupleKey("document:2", "viewer", "user:anne")},
+		{tuple.NewTupleKey("document:1", "editor", "user:anne")},
+		{tuple.NewTupleKey("document:3", "viewer", "user:anne")},
 	}
-}
+	for _, tupleKeys := range chunks {
+		require.NoError(t, stream.Send(&openfgav1.WriteRequest{
+			StoreId: store.GetId(),
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: tupleKeys},
+		}))
+	}
+	require.NoError(t, stream.CloseSend())
 
-func tryGetStores(t *testing.T, test authTest, httpAddr string, retryClient *retryablehttp.Client) {
-	req, err := retryablehttp.NewRequest("GET", fmt.Sprintf("http://%s/stores", httpAddr), nil)
-	require.NoError(t, err, "Failed to construct request")
-	req.Header.Set("content-type", "application/json")
-	req.Header.Set("authorization", test.authHeader)
+	require.NoError(t, stream.Recv())
+	var chunkErr *bulkwrite.ChunkError
+	require.ErrorAs(t, stream.Recv(), &chunkErr)
+	require.NoError(t, stream.Recv())
+	require.ErrorIs(t, stream.Recv(), io.EOF)
 
-	res, err := retryClient.Do(req)
-	require.NoError(t, err, "Failed to execute request")
-	defer res.Body.Close()
-	require.Equal(t, test.expectedStatusCode, res.StatusCode)
-	body, err := io.ReadAll(res.Body)
-	require.NoError(t, err, "Failed to read response")
+	read, err := client.Read(ctx, &openfgav1.ReadRequest{StoreId: store.GetId()})
+	require.NoError(t, err)
+	require.Len(t, read.GetTuples(), 3)
 
-	if test.expectedErrorResponse != nil {
-		var actualErrorResponse serverErrors.ErrorResponse
-		err = json.Unmarshal(body, &actualErrorResponse)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("http://%s/stores/%s/tuples?object=document:1", cfg.HTTP.Addr, store.GetId()), nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"deleted_count":1}`, string(body))
 
-		require.NoError(t, err, "Failed to unmarshal response")
+	deleted, err := bulkwrite.DeleteTuples(ctx, conn, &openfgav1.ReadRequest{
+		StoreId:  store.GetId(),
+		TupleKey: &openfgav1.ReadRequestTupleKey{User: "user:anne"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), deleted)
 
-		require.Equal(t, test.expectedErrorResponse, &actualErrorResponse)
-	}
+	_, err = bulkwrite.DeleteTuples(ctx, conn, &openfgav1.ReadRequest{StoreId: store.GetId()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	read, err = client.Read(ctx, &openfgav1.ReadRequest{StoreId: store.GetId()})
+	require.NoError(t, err)
+	require.Empty(t, read.GetTuples())
 }
 
-func TestHTTPServerWithCORS(t *testing.T) {
+func TestServerWithStoreEvents(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
-	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Authn.Method = "preshared"
-	cfg.Authn.AuthnPresharedKeyConfig = &serverconfig.AuthnPresharedKeyConfig{
-		Keys: []string{"KEYONE", "KEYTWO"},
-	}
-	cfg.HTTP.CORSAllowedOrigins = []string{"http://openfga.dev", "http://localhost"}
-	cfg.HTTP.CORSAllowedHeaders = []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization", "X-Custom-Header"}
 
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.StoreEvents.Enabled = true
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	t.Cleanup(cancel)
 
 	go func() {
 		if err := runServer(ctx, cfg); err != nil {
@@ -468,109 +611,61 @@ func TestHTTPServerWithCORS(t *testing.T) {
 
 	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
-	type args struct {
-		origin string
-		header string
-	}
-	type want struct {
-		origin string
-		header string
-	}
-	tests := []struct {
-		name string
-		args args
-		want want
-	}{
-		{
-			name: "origin_allowed",
-			args: args{
-				origin: "http://localhost",
-				// must be lowercase, see https://github.com/rs/cors/issues/174#issuecomment-2082098921
-				header: "authorization,x-custom-header",
-			},
-			want: want{
-				origin: "http://localhost",
-				header: "authorization,x-custom-header",
-			},
-		},
-		{
-			name: "origin_forbidden",
-			args: args{
-				origin: "http://openfga.example",
-				header: "X-Custom-Header",
-			},
-			want: want{
-				origin: "",
-				header: "",
-			},
-		},
-		{
-			name: "origin_allowed_but_header_forbidden",
-			args: args{
-				origin: "http://localhost",
-				header: "Bad-Custom-Header",
-			},
-			want: want{
-				origin: "",
-				header: "",
-			},
-		},
-	}
-
-	client := retryablehttp.NewClient()
-	t.Cleanup(client.HTTPClient.CloseIdleConnections)
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			payload := strings.NewReader(`{"name": "some-store-name"}`)
-			req, err := retryablehttp.NewRequest("OPTIONS", fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr), payload)
-			require.NoError(t, err, "Failed to construct request")
-			req.Header.Set("content-type", "application/json")
-			req.Header.Set("Origin", test.args.origin)
-			req.Header.Set("Access-Control-Request-Method", "OPTIONS")
-			req.Header.Set("Access-Control-Request-Headers", test.args.header)
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "events"})
+	require.NoError(t, err)
 
-			res, err := client.Do(req)
-			require.NoError(t, err, "Failed to execute request")
-			defer res.Body.Close()
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	writeModel, err := client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
 
-			origin := res.Header.Get("Access-Control-Allow-Origin")
-			acceptedHeader := res.Header.Get("Access-Control-Allow-Headers")
-			require.Equal(t, test.want.origin, origin)
+	get := func(query string, expectedStatus int) *storeevents.ReadResponse {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/stores/%s/events?%s", cfg.HTTP.Addr, store.GetId(), query), nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, expectedStatus, resp.StatusCode)
+		if expectedStatus != http.StatusOK {
+			return nil
+		}
+		var res storeevents.ReadResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return &res
+	}
 
-			require.Equal(t, test.want.header, acceptedHeader)
+	res := get("type=authorization_model_write&type=store_create", http.StatusOK)
+	require.Len(t, res.Events, 2)
+	require.Equal(t, storeevents.EventTypeStoreCreate, res.Events[0].Type)
+	require.Equal(t, storeevents.EventTypeAuthorizationModelWrite, res.Events[1].Type)
+	require.Equal(t, writeModel.GetAuthorizationModelId(), res.Events[1].AuthorizationModelID)
 
-			_, err = io.ReadAll(res.Body)
-			require.NoError(t, err, "Failed to read response")
-		})
-	}
+	get("type=unknown", http.StatusBadRequest)
+	get("page_size=invalid", http.StatusBadRequest)
 }
 
-func TestBuildServerWithOIDCAuthentication(t *testing.T) {
+func TestServerWithListObjectsSubscription(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
-	oidcServerPort, oidcServerPortReleaser := testutils.TCPRandomPort()
-	localOIDCServerURL := fmt.Sprintf("http://localhost:%d", oidcServerPort)
 
 	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Authn.Method = "oidc"
-	cfg.Authn.AuthnOIDCConfig = &serverconfig.AuthnOIDCConfig{
-		Audience: "openfga.dev",
-		Issuer:   localOIDCServerURL,
-	}
-
-	oidcServerPortReleaser()
-
-	trustedIssuerServer, err := mocks.NewMockOidcServer(localOIDCServerURL)
-	require.NoError(t, err)
-	t.Cleanup(trustedIssuerServer.Stop)
-
-	trustedToken, err := trustedIssuerServer.GetToken("openfga.dev", "some-user")
-	require.NoError(t, err)
-
+	cfg.ListObjectsSubscription.Enabled = true
+	cfg.ListObjectsSubscription.PollInterval = 10 * time.Millisecond
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	t.Cleanup(cancel)
 
 	go func() {
 		if err := runServer(ctx, cfg); err != nil {
@@ -580,78 +675,68 @@ func TestBuildServerWithOIDCAuthentication(t *testing.T) {
 
 	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
-	tests := []authTest{
-		{
-			_name:      "Header_with_invalid_token_fails",
-			authHeader: "Bearer incorrecttoken",
-			expectedErrorResponse: &serverErrors.ErrorResponse{
-				Code:    "invalid_claims",
-				Message: "invalid claims",
-			},
-			expectedStatusCode: 401,
-		},
-		{
-			_name:      "Missing_header_fails",
-			authHeader: "",
-			expectedErrorResponse: &serverErrors.ErrorResponse{
-				Code:    "bearer_token_missing",
-				Message: "missing bearer token",
-			},
-			expectedStatusCode: 401,
-		},
-		{
-			_name:              "Correct_token_succeeds",
-			authHeader:         "Bearer " + trustedToken,
-			expectedStatusCode: 200,
-		},
-	}
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
 
-	retryClient := retryablehttp.NewClient()
-	t.Cleanup(retryClient.HTTPClient.CloseIdleConnections)
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "subscription"})
+	require.NoError(t, err)
 
-	for _, test := range tests {
-		t.Run(test._name, func(t *testing.T) {
-			tryGetStores(t, test, cfg.HTTP.Addr, retryClient)
-		})
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
 
-		t.Run(test._name+"/streaming", func(t *testing.T) {
-			tryStreamingListObjects(t, test, cfg.HTTP.Addr, retryClient, trustedToken)
+	write := func(object string) {
+		_, err := client.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: store.GetId(),
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey(object, "viewer", "user:anne"),
+			}},
 		})
+		require.NoError(t, err)
 	}
+	write("document:1")
+
+	subscribeCtx, cancelSubscription := context.WithCancel(ctx)
+	defer cancelSubscription()
+	req, err := http.NewRequestWithContext(subscribeCtx, http.MethodPost, fmt.Sprintf("http://%s/stores/%s/list-objects/subscribe", cfg.HTTP.Addr, store.GetId()),
+		strings.NewReader(`{"type": "document", "relation": "viewer", "user": "user:anne"}`))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dec := json.NewDecoder(resp.Body)
+	var delta listobjectssubscription.Delta
+	require.NoError(t, dec.Decode(&delta))
+	require.Equal(t, listobjectssubscription.Delta{Initial: true, Added: []string{"document:1"}, Removed: []string{}}, delta)
+
+	write("document:2")
+	delta = listobjectssubscription.Delta{}
+	require.NoError(t, dec.Decode(&delta))
+	require.Equal(t, listobjectssubscription.Delta{Added: []string{"document:2"}, Removed: []string{}}, delta)
 }
 
-func TestBuildServerWithOIDCAuthenticationAlias(t *testing.T) {
+func TestServerWithStorePurge(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
-	oidcServerPort1, oidcServerPortReleaser1 := testutils.TCPRandomPort()
-	oidcServerPort2, oidcServerPortReleaser2 := testutils.TCPRandomPort()
-	oidcServerURL1 := fmt.Sprintf("http://localhost:%d", oidcServerPort1)
-	oidcServerURL2 := fmt.Sprintf("http://localhost:%d", oidcServerPort2)
 
 	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Authn.Method = "oidc"
-	cfg.Authn.AuthnOIDCConfig = &serverconfig.AuthnOIDCConfig{
-		Audience:      "openfga.dev",
-		Issuer:        oidcServerURL1,
-		IssuerAliases: []string{oidcServerURL2},
-	}
-
-	oidcServerPortReleaser1()
-	oidcServerPortReleaser2()
-
-	trustedIssuerServer1, err := mocks.NewMockOidcServer(oidcServerURL1)
-	require.NoError(t, err)
-	t.Cleanup(trustedIssuerServer1.Stop)
-
-	trustedIssuerServer2 := trustedIssuerServer1.NewAliasMockServer(oidcServerURL2)
-	t.Cleanup(trustedIssuerServer2.Stop)
-
-	trustedTokenFromAlias, err := trustedIssuerServer2.GetToken("openfga.dev", "some-user")
-	require.NoError(t, err)
-
+	cfg.Jobs.Enabled = true
+	cfg.StorePurge.Enabled = true
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	t.Cleanup(cancel)
 
 	go func() {
 		if err := runServer(ctx, cfg); err != nil {
@@ -661,565 +746,1627 @@ func TestBuildServerWithOIDCAuthenticationAlias(t *testing.T) {
 
 	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
-	retryClient := retryablehttp.NewClient()
-	t.Cleanup(retryClient.HTTPClient.CloseIdleConnections)
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
 
-	test := authTest{
-		_name:              "Token_with_issuer_equal_to_alias_is_accepted",
-		authHeader:         "Bearer " + trustedTokenFromAlias,
-		expectedStatusCode: 200,
-	}
-	t.Run(test._name, func(t *testing.T) {
-		tryGetStores(t, test, cfg.HTTP.Addr, retryClient)
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "purge"})
+	require.NoError(t, err)
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
 	})
+	require.NoError(t, err)
 
-	t.Run(test._name+"/streaming", func(t *testing.T) {
-		tryStreamingListObjects(t, test, cfg.HTTP.Addr, retryClient, trustedTokenFromAlias)
+	_, err = client.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: store.GetId(),
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		}},
 	})
+	require.NoError(t, err)
+
+	do := func(method, path, body string, expectedStatus int, out any) {
+		req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("http://%s/stores/%s/%s", cfg.HTTP.Addr, store.GetId(), path), strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, expectedStatus, resp.StatusCode)
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+	}
+
+	var job jobs.Job
+	do(http.MethodPost, "jobs", `{"kind":"purge","params":{"object":"document:1"}}`, http.StatusAccepted, &job)
+
+	require.Eventually(t, func() bool {
+		do(http.MethodGet, "jobs/"+job.ID, "", http.StatusOK, &job)
+		return job.State.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, jobs.StateSucceeded, job.State)
+	require.JSONEq(t, `{"deleted":1}`, string(job.Result))
+
+	_, err = client.DeleteStore(ctx, &openfgav1.DeleteStoreRequest{StoreId: store.GetId()})
+	require.NoError(t, err)
+
+	var list jobs.ListResponse
+	do(http.MethodGet, "jobs", "", http.StatusOK, &list)
+	require.Len(t, list.Jobs, 2)
+	for _, j := range list.Jobs {
+		require.Equal(t, server.JobKindPurge, j.Kind)
+	}
 }
 
-func TestHTTPServingTLS(t *testing.T) {
+func TestServerWithRegisteredCheckCache(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
-	t.Run("enable_HTTP_TLS_is_false,_even_with_keys_set,_will_serve_plaintext", func(t *testing.T) {
-		certsAndKeys := createCertsAndKeys(t)
-		defer certsAndKeys.Clean()
+	registerTestCaches()
 
-		cfg := testutils.MustDefaultConfigWithRandomPorts()
-		cfg.HTTP.TLS = &serverconfig.TLSConfig{
-			CertPath: certsAndKeys.serverCertFile,
-			KeyPath:  certsAndKeys.serverKeyFile,
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.CheckCache.Engine = "test-counting"
+	cfg.CheckQueryCache.Enabled = true
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
 		}
-		cfg.HTTP.Addr = "localhost:8080"
+	}()
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
-		go func() {
-			if err := runServer(ctx, cfg); err != nil {
-				log.Fatal(err)
-			}
-		}()
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
 
-		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "cache"})
+	require.NoError(t, err)
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user] or editor`)
+	writeModelResp, err := client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
 	})
+	require.NoError(t, err)
 
-	t.Run("enable_HTTP_TLS_is_true_will_serve_HTTP_TLS", func(t *testing.T) {
-		certsAndKeys := createCertsAndKeys(t)
-		defer certsAndKeys.Clean()
+	_, err = client.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: store.GetId(),
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
 
-		cfg := testutils.MustDefaultConfigWithRandomPorts()
-		cfg.HTTP.TLS = &serverconfig.TLSConfig{
-			Enabled:  true,
-			CertPath: certsAndKeys.serverCertFile,
-			KeyPath:  certsAndKeys.serverKeyFile,
-		}
-		cfg.HTTP.Addr = "localhost:8080"
+	check, err := client.Check(ctx, &openfgav1.CheckRequest{
+		StoreId:              store.GetId(),
+		AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+		TupleKey:             tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+	})
+	require.NoError(t, err)
+	require.True(t, check.GetAllowed())
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	require.Eventually(t, func() bool {
+		return testCountingCache.Load().sets.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
 
-		go func() {
+func TestBuildServiceWithTracingEnabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	// create mock OTLP server
+	otlpServerPort, otlpServerPortReleaser := testutils.TCPRandomPort()
+	localOTLPServerURL := fmt.Sprintf("localhost:%d", otlpServerPort)
+	otlpServerPortReleaser()
+	otlpServer := mocks.NewMockTracingServer(t, otlpServerPort)
+
+	// create OpenFGA server with tracing enabled
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Trace.Enabled = true
+	cfg.Trace.SampleRatio = 1
+	cfg.Trace.OTLP.Endpoint = localOTLPServerURL
+	cfg.Trace.OTLP.TLS.Enabled = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	serverDone := make(chan error)
+	go func() {
+		serverDone <- runServer(ctx, cfg)
+	}()
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	// attempt a random request
+	client := retryablehttp.NewClient()
+	t.Cleanup(client.HTTPClient.CloseIdleConnections)
+	response, err := client.Get(fmt.Sprintf("http://%s/healthz", cfg.HTTP.Addr))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := response.Body.Close()
+		require.NoError(t, err)
+	})
+
+	cancel()
+	select {
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out")
+	case err := <-serverDone:
+		require.NoError(t, err)
+	}
+
+	// at this point, all spans should have been forcefully exported
+
+	require.Equal(t, 1, otlpServer.GetExportCount())
+}
+
+func tryStreamingListObjects(t *testing.T, test authTest, httpAddr string, retryClient *retryablehttp.Client, validToken string) {
+	// create a store
+	createStorePayload := strings.NewReader(`{"name": "some-store-name"}`)
+	req, err := retryablehttp.NewRequest("POST", fmt.Sprintf("http://%s/stores", httpAddr), createStorePayload)
+	require.NoError(t, err, "Failed to construct create store request")
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("authorization", fmt.Sprintf("Bearer %s", validToken))
+	res, err := retryClient.Do(req)
+	require.NoError(t, err, "Failed to execute create store request")
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err, "Failed to read create store response")
+	var createStoreResponse openfgav1.CreateStoreResponse
+	err = protojson.Unmarshal(body, &createStoreResponse)
+	require.NoError(t, err, "Failed to unmarshal create store response")
+
+	// create an authorization model
+	authModelPayload := strings.NewReader(`{
+  "type_definitions": [
+    {
+      "type": "user",
+      "relations": {}
+    },
+    {
+      "type": "document",
+      "relations": {
+        "owner": {
+          "this": {}
+        }
+      },
+      "metadata": {
+        "relations": {
+          "owner": {
+            "directly_related_user_types": [
+              {
+                "type": "user"
+              }
+            ]
+          }
+        }
+      }
+    }
+  ],
+  "schema_version": "1.1"
+}`)
+	req, err = retryablehttp.NewRequest("POST", fmt.Sprintf("http://%s/stores/%s/authorization-models", httpAddr, createStoreResponse.GetId()), authModelPayload)
+	require.NoError(t, err, "Failed to construct create authorization model request")
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("authorization", fmt.Sprintf("Bearer %s", validToken))
+	response, err := retryClient.Do(req)
+	require.NoError(t, err, "Failed to execute create authorization model request")
+
+	t.Cleanup(func() {
+		err := response.Body.Close()
+		require.NoError(t, err)
+	})
+
+	// call one streaming endpoint
+	listObjectsPayload := strings.NewReader(`{"type": "document", "user": "user:anne", "relation": "owner"}`)
+	req, err = retryablehttp.NewRequest("POST", fmt.Sprintf("http://%s/stores/%s/streamed-list-objects", httpAddr, createStoreResponse.GetId()), listObjectsPayload)
+	require.NoError(t, err, "Failed to construct request")
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("authorization", test.authHeader)
+	res, err = retryClient.Do(req)
+	require.NoError(t, err, "Failed to execute streaming request")
+	defer res.Body.Close()
+	require.Equal(t, test.expectedStatusCode, res.StatusCode)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(t, err, "Failed to read response")
+
+	if test.expectedErrorResponse != nil {
+		require.Contains(t, string(body), fmt.Sprintf(",\"message\":\"%s\"", test.expectedErrorResponse.Message))
+	}
+}
+
+func tryGetStores(t *testing.T, test authTest, httpAddr string, retryClient *retryablehttp.Client) {
+	req, err := retryablehttp.NewRequest("GET", fmt.Sprintf("http://%s/stores", httpAddr), nil)
+	require.NoError(t, err, "Failed to construct request")
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("authorization", test.authHeader)
+
+	res, err := retryClient.Do(req)
+	require.NoError(t, err, "Failed to execute request")
+	defer res.Body.Close()
+	require.Equal(t, test.expectedStatusCode, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err, "Failed to read response")
+
+	if test.expectedErrorResponse != nil {
+		var actualErrorResponse serverErrors.ErrorResponse
+		err = json.Unmarshal(body, &actualErrorResponse)
+
+		require.NoError(t, err, "Failed to unmarshal response")
+
+		require.Equal(t, test.expectedErrorResponse, &actualErrorResponse)
+	}
+}
+
+func TestHTTPServerWithCORS(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Authn.Method = "preshared"
+	cfg.Authn.AuthnPresharedKeyConfig = &serverconfig.AuthnPresharedKeyConfig{
+		Keys: []string{"KEYONE", "KEYTWO"},
+	}
+	cfg.HTTP.CORSAllowedOrigins = []string{"http://openfga.dev", "http://localhost"}
+	cfg.HTTP.CORSAllowedHeaders = []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization", "X-Custom-Header"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	type args struct {
+		origin string
+		header string
+	}
+	type want struct {
+		origin string
+		header string
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "origin_allowed",
+			args: args{
+				origin: "http://localhost",
+				// must be lowercase, see https://github.com/rs/cors/issues/174#issuecomment-2082098921
+				header: "authorization,x-custom-header",
+			},
+			want: want{
+				origin: "http://localhost",
+				header: "authorization,x-custom-header",
+			},
+		},
+		{
+			name: "origin_forbidden",
+			args: args{
+				origin: "http://openfga.example",
+				header: "X-Custom-Header",
+			},
+			want: want{
+				origin: "",
+				header: "",
+			},
+		},
+		{
+			name: "origin_allowed_but_header_forbidden",
+			args: args{
+				origin: "http://localhost",
+				header: "Bad-Custom-Header",
+			},
+			want: want{
+				origin: "",
+				header: "",
+			},
+		},
+	}
+
+	client := retryablehttp.NewClient()
+	t.Cleanup(client.HTTPClient.CloseIdleConnections)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload := strings.NewReader(`{"name": "some-store-name"}`)
+			req, err := retryablehttp.NewRequest("OPTIONS", fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr), payload)
+			require.NoError(t, err, "Failed to construct request")
+			req.Header.Set("content-type", "application/json")
+			req.Header.Set("Origin", test.args.origin)
+			req.Header.Set("Access-Control-Request-Method", "OPTIONS")
+			req.Header.Set("Access-Control-Request-Headers", test.args.header)
+
+			res, err := client.Do(req)
+			require.NoError(t, err, "Failed to execute request")
+			defer res.Body.Close()
+
+			origin := res.Header.Get("Access-Control-Allow-Origin")
+			acceptedHeader := res.Header.Get("Access-Control-Allow-Headers")
+			require.Equal(t, test.want.origin, origin)
+
+			require.Equal(t, test.want.header, acceptedHeader)
+
+			_, err = io.ReadAll(res.Body)
+			require.NoError(t, err, "Failed to read response")
+		})
+	}
+}
+
+func TestHTTPServerWithCORSOptions(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.HTTP.CORSAllowedOrigins = []string{"http://localhost"}
+	cfg.HTTP.CORSAllowedMethods = []string{http.MethodGet, http.MethodPost}
+	cfg.HTTP.CORSExposedHeaders = []string{"Server-Timing"}
+	cfg.HTTP.CORSMaxAge = 10 * time.Minute
+	cfg.HTTP.CORSAllowCredentials = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	client := retryablehttp.NewClient()
+	t.Cleanup(client.HTTPClient.CloseIdleConnections)
+
+	preflight := func(t *testing.T, method string) *http.Response {
+		req, err := retryablehttp.NewRequest(http.MethodOptions, fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr), nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "http://localhost")
+		req.Header.Set("Access-Control-Request-Method", method)
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	t.Run("method_allowed", func(t *testing.T) {
+		res := preflight(t, http.MethodPost)
+		require.Equal(t, "http://localhost", res.Header.Get("Access-Control-Allow-Origin"))
+		require.Equal(t, http.MethodPost, res.Header.Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "600", res.Header.Get("Access-Control-Max-Age"))
+		require.Empty(t, res.Header.Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("method_forbidden", func(t *testing.T) {
+		res := preflight(t, http.MethodDelete)
+		require.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+		require.Empty(t, res.Header.Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("headers_exposed", func(t *testing.T) {
+		req, err := retryablehttp.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr), nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "http://localhost")
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, "http://localhost", res.Header.Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "Server-Timing", res.Header.Get("Access-Control-Expose-Headers"))
+	})
+}
+
+func TestBuildServerWithOIDCAuthentication(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	oidcServerPort, oidcServerPortReleaser := testutils.TCPRandomPort()
+	localOIDCServerURL := fmt.Sprintf("http://localhost:%d", oidcServerPort)
+
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Authn.Method = "oidc"
+	cfg.Authn.AuthnOIDCConfig = &serverconfig.AuthnOIDCConfig{
+		Audience: "openfga.dev",
+		Issuer:   localOIDCServerURL,
+	}
+
+	oidcServerPortReleaser()
+
+	trustedIssuerServer, err := mocks.NewMockOidcServer(localOIDCServerURL)
+	require.NoError(t, err)
+	t.Cleanup(trustedIssuerServer.Stop)
+
+	trustedToken, err := trustedIssuerServer.GetToken("openfga.dev", "some-user")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	tests := []authTest{
+		{
+			_name:      "Header_with_invalid_token_fails",
+			authHeader: "Bearer incorrecttoken",
+			expectedErrorResponse: &serverErrors.ErrorResponse{
+				Code:    "invalid_claims",
+				Message: "invalid claims",
+			},
+			expectedStatusCode: 401,
+		},
+		{
+			_name:      "Missing_header_fails",
+			authHeader: "",
+			expectedErrorResponse: &serverErrors.ErrorResponse{
+				Code:    "bearer_token_missing",
+				Message: "missing bearer token",
+			},
+			expectedStatusCode: 401,
+		},
+		{
+			_name:              "Correct_token_succeeds",
+			authHeader:         "Bearer " + trustedToken,
+			expectedStatusCode: 200,
+		},
+	}
+
+	retryClient := retryablehttp.NewClient()
+	t.Cleanup(retryClient.HTTPClient.CloseIdleConnections)
+
+	for _, test := range tests {
+		t.Run(test._name, func(t *testing.T) {
+			tryGetStores(t, test, cfg.HTTP.Addr, retryClient)
+		})
+
+		t.Run(test._name+"/streaming", func(t *testing.T) {
+			tryStreamingListObjects(t, test, cfg.HTTP.Addr, retryClient, trustedToken)
+		})
+	}
+}
+
+func TestBuildServerWithOIDCAuthenticationAlias(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	oidcServerPort1, oidcServerPortReleaser1 := testutils.TCPRandomPort()
+	oidcServerPort2, oidcServerPortReleaser2 := testutils.TCPRandomPort()
+	oidcServerURL1 := fmt.Sprintf("http://localhost:%d", oidcServerPort1)
+	oidcServerURL2 := fmt.Sprintf("http://localhost:%d", oidcServerPort2)
+
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Authn.Method = "oidc"
+	cfg.Authn.AuthnOIDCConfig = &serverconfig.AuthnOIDCConfig{
+		Audience:      "openfga.dev",
+		Issuer:        oidcServerURL1,
+		IssuerAliases: []string{oidcServerURL2},
+	}
+
+	oidcServerPortReleaser1()
+	oidcServerPortReleaser2()
+
+	trustedIssuerServer1, err := mocks.NewMockOidcServer(oidcServerURL1)
+	require.NoError(t, err)
+	t.Cleanup(trustedIssuerServer1.Stop)
+
+	trustedIssuerServer2 := trustedIssuerServer1.NewAliasMockServer(oidcServerURL2)
+	t.Cleanup(trustedIssuerServer2.Stop)
+
+	trustedTokenFromAlias, err := trustedIssuerServer2.GetToken("openfga.dev", "some-user")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	retryClient := retryablehttp.NewClient()
+	t.Cleanup(retryClient.HTTPClient.CloseIdleConnections)
+
+	test := authTest{
+		_name:              "Token_with_issuer_equal_to_alias_is_accepted",
+		authHeader:         "Bearer " + trustedTokenFromAlias,
+		expectedStatusCode: 200,
+	}
+	t.Run(test._name, func(t *testing.T) {
+		tryGetStores(t, test, cfg.HTTP.Addr, retryClient)
+	})
+
+	t.Run(test._name+"/streaming", func(t *testing.T) {
+		tryStreamingListObjects(t, test, cfg.HTTP.Addr, retryClient, trustedTokenFromAlias)
+	})
+}
+
+func TestHTTPServingTLS(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	t.Run("enable_HTTP_TLS_is_false,_even_with_keys_set,_will_serve_plaintext", func(t *testing.T) {
+		certsAndKeys := createCertsAndKeys(t)
+		defer certsAndKeys.Clean()
+
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.HTTP.TLS = &serverconfig.TLSConfig{
+			CertPath: certsAndKeys.serverCertFile,
+			KeyPath:  certsAndKeys.serverKeyFile,
+		}
+		cfg.HTTP.Addr = "localhost:8080"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := runServer(ctx, cfg); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+	})
+
+	t.Run("enable_HTTP_TLS_is_true_will_serve_HTTP_TLS", func(t *testing.T) {
+		certsAndKeys := createCertsAndKeys(t)
+		defer certsAndKeys.Clean()
+
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.HTTP.TLS = &serverconfig.TLSConfig{
+			Enabled:  true,
+			CertPath: certsAndKeys.serverCertFile,
+			KeyPath:  certsAndKeys.serverKeyFile,
+		}
+		cfg.HTTP.Addr = "localhost:8080"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := runServer(ctx, cfg); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		certPool := x509.NewCertPool()
+		certPool.AddCert(certsAndKeys.caCert)
+		client := retryablehttp.NewClient()
+		t.Cleanup(client.HTTPClient.CloseIdleConnections)
+		client.HTTPClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: certPool,
+			},
+		}
+
+		resp, err := client.Get(fmt.Sprintf("https://%s/healthz", cfg.HTTP.Addr))
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+}
+
+func TestGRPCServingTLS(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	t.Run("enable_grpc_TLS_is_false,_even_with_keys_set,_will_serve_plaintext", func(t *testing.T) {
+		certsAndKeys := createCertsAndKeys(t)
+		defer certsAndKeys.Clean()
+
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.HTTP.Enabled = false
+		cfg.GRPC.TLS = &serverconfig.TLSConfig{
+			CertPath: certsAndKeys.serverCertFile,
+			KeyPath:  certsAndKeys.serverKeyFile,
+		}
+		cfg.GRPC.Addr = "localhost:8082"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := runServer(ctx, cfg); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, "", nil)
+	})
+
+	t.Run("enable_grpc_TLS_is_true_will_serve_grpc_TLS", func(t *testing.T) {
+		certsAndKeys := createCertsAndKeys(t)
+		defer certsAndKeys.Clean()
+
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.HTTP.Enabled = false
+		cfg.GRPC.TLS = &serverconfig.TLSConfig{
+			Enabled:  true,
+			CertPath: certsAndKeys.serverCertFile,
+			KeyPath:  certsAndKeys.serverKeyFile,
+		}
+		cfg.GRPC.Addr = "localhost:8082"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := runServer(ctx, cfg); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		certPool := x509.NewCertPool()
+		certPool.AddCert(certsAndKeys.caCert)
+		creds := credentials.NewClientTLSFromCert(certPool, "")
+
+		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, "", creds)
+	})
+}
+
+func TestHTTPServerWithGRPCTLSEnabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	t.Run("http_gateway_proxies_to_grpc_server_with_tls", func(t *testing.T) {
+		certsAndKeys := createCertsAndKeys(t)
+		defer certsAndKeys.Clean()
+
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.GRPC.TLS = &serverconfig.TLSConfig{
+			Enabled:  true,
+			CertPath: certsAndKeys.serverCertFile,
+			KeyPath:  certsAndKeys.serverKeyFile,
+		}
+		cfg.GRPC.Addr = "localhost:8082"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := runServer(ctx, cfg); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		certPool := x509.NewCertPool()
+		certPool.AddCert(certsAndKeys.caCert)
+		creds := credentials.NewClientTLSFromCert(certPool, "")
+
+		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, creds)
+
+		// Verify the HTTP gateway can proxy requests to the gRPC server over TLS.
+		// This validates that the gateway's internal gRPC client uses dynamic TLS
+		// credentials (via certwatcher) rather than a one-time static load.
+		client := retryablehttp.NewClient()
+		t.Cleanup(client.HTTPClient.CloseIdleConnections)
+
+		resp, err := client.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("http_gateway_handles_grpc_certificate_rotation", func(t *testing.T) {
+		certsAndKeys := createCertsAndKeys(t)
+		defer certsAndKeys.Clean()
+
+		cfg := testutils.MustDefaultConfigWithRandomPorts()
+		cfg.GRPC.TLS = &serverconfig.TLSConfig{
+			Enabled:  true,
+			CertPath: certsAndKeys.serverCertFile,
+			KeyPath:  certsAndKeys.serverKeyFile,
+		}
+		cfg.GRPC.Addr = "localhost:8082"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
 			if err := runServer(ctx, cfg); err != nil {
 				log.Fatal(err)
 			}
 		}()
 
-		certPool := x509.NewCertPool()
-		certPool.AddCert(certsAndKeys.caCert)
-		client := retryablehttp.NewClient()
-		t.Cleanup(client.HTTPClient.CloseIdleConnections)
-		client.HTTPClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool,
+		certPool := x509.NewCertPool()
+		certPool.AddCert(certsAndKeys.caCert)
+		creds := credentials.NewClientTLSFromCert(certPool, "")
+
+		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, creds)
+
+		// Make initial request to verify connectivity
+		client := retryablehttp.NewClient()
+		t.Cleanup(client.HTTPClient.CloseIdleConnections)
+
+		resp, err := client.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Simulate certificate rotation by generating new certs and overwriting files
+		newCertsAndKeys := createCertsAndKeys(t)
+		defer newCertsAndKeys.Clean()
+
+		// Overwrite the cert and key files with new ones
+		err = os.WriteFile(certsAndKeys.serverCertFile, readFile(t, newCertsAndKeys.serverCertFile), 0600)
+		require.NoError(t, err)
+		err = os.WriteFile(certsAndKeys.serverKeyFile, readFile(t, newCertsAndKeys.serverKeyFile), 0600)
+		require.NoError(t, err)
+
+		// Wait for certwatcher to detect and reload the certificate
+		time.Sleep(2 * time.Second)
+
+		// Verify the HTTP gateway can still proxy requests after certificate rotation.
+		// This validates that the gateway's internal gRPC client dynamically updates
+		// its certificate pool via the certwatcher callback.
+		resp2, err := client.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
+		require.NoError(t, err)
+		defer resp2.Body.Close()
+		require.Equal(t, http.StatusOK, resp2.StatusCode)
+	})
+}
+
+func TestServerMetricsReporting(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	const nonPgxPrometheusMetrics = "go_sql_idle_connections"
+	const pgxPrometheusMetrics = "pgxpool_idle_conns"
+	t.Run("mysql", func(t *testing.T) {
+		testServerMetricsReporting(t, "mysql", nonPgxPrometheusMetrics)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		testServerMetricsReporting(t, "postgres", pgxPrometheusMetrics)
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		testServerMetricsReporting(t, "sqlite", nonPgxPrometheusMetrics)
+	})
+}
+
+func testServerMetricsReporting(t *testing.T, engine string, connectionMetricName string) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, engine)
+
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Datastore.Engine = engine
+	cfg.Datastore.URI = testDatastore.GetConnectionURI(true)
+	cfg.Datastore.Metrics.Enabled = true
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.EnableRPCHistograms = true
+	metricsPort, metricsPortReleaser := testutils.TCPRandomPort()
+	metricsPortReleaser()
+
+	cfg.Metrics.Addr = fmt.Sprintf("localhost:%d", metricsPort)
+
+	cfg.MaxConcurrentReadsForCheck = 30
+	cfg.MaxConcurrentReadsForListObjects = 30
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+
+	client := openfgav1.NewOpenFGAServiceClient(conn)
+
+	createStoreResp, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{
+		Name: "test-store",
+	})
+	require.NoError(t, err)
+
+	storeID := createStoreResp.GetId()
+
+	_, err = client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId: storeID,
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{
+				Type: "user",
+			},
+			{
+				Type: "document",
+				Relations: map[string]*openfgav1.Userset{
+					"allowed": typesystem.This(),
+					"editor":  typesystem.This(),
+					"viewer": typesystem.Union(
+						typesystem.This(),
+						typesystem.Intersection(
+							typesystem.ComputedUserset("editor"),
+							typesystem.ComputedUserset("allowed"))),
+				},
+				Metadata: &openfgav1.Metadata{
+					Relations: map[string]*openfgav1.RelationMetadata{
+						"allowed": {
+							DirectlyRelatedUserTypes: []*openfgav1.RelationReference{
+								{Type: "user"},
+							},
+						},
+						"editor": {
+							DirectlyRelatedUserTypes: []*openfgav1.RelationReference{
+								{Type: "user"},
+							},
+						},
+						"viewer": {
+							DirectlyRelatedUserTypes: []*openfgav1.RelationReference{
+								{Type: "user", Condition: "condx"},
+							},
+						},
+					},
+				},
+			},
+		},
+		Conditions: map[string]*openfgav1.Condition{
+			"condx": {
+				Name: "condx",
+				Parameters: map[string]*openfgav1.ConditionParamTypeRef{
+					"x": {
+						TypeName: openfgav1.ConditionParamTypeRef_TYPE_NAME_INT,
+					},
+				},
+				Expression: "x < 100",
+			},
+		},
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:jon", "condx", nil),
+				{Object: "document:2", Relation: "editor", User: "user:jon"},
+				{Object: "document:2", Relation: "allowed", User: "user:jon"},
 			},
-		}
+		},
+	})
+	require.NoError(t, err)
 
-		resp, err := client.Get(fmt.Sprintf("https://%s/healthz", cfg.HTTP.Addr))
-		require.NoError(t, err)
-		resp.Body.Close()
+	checkResp, err := client.Check(ctx, &openfgav1.CheckRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+		Context: testutils.MustNewStruct(t, map[string]interface{}{
+			"x": 10,
+		}),
+	})
+	require.NoError(t, err)
+	require.True(t, checkResp.GetAllowed())
+
+	listObjectsResp, err := client.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+		StoreId:  storeID,
+		Type:     "document",
+		Relation: "viewer",
+		User:     "user:jon",
+		Context: testutils.MustNewStruct(t, map[string]interface{}{
+			"x": 10,
+		}),
 	})
+	require.NoError(t, err)
+	require.Len(t, listObjectsResp.GetObjects(), 2)
+	require.Contains(t, listObjectsResp.GetObjects(), "document:1")
+	require.Contains(t, listObjectsResp.GetObjects(), "document:2")
+
+	resp, err := retryablehttp.Get(fmt.Sprintf("http://%s/metrics", cfg.Metrics.Addr))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	defer resp.Body.Close()
+
+	expectedMetrics := []string{
+		"openfga_datastore_query_count",
+		"openfga_request_duration_ms",
+		"grpc_server_handling_seconds",
+		"openfga_list_objects_further_eval_required_count",
+		"openfga_list_objects_no_further_eval_required_count",
+		"openfga_condition_evaluation_cost",
+		"openfga_condition_compilation_duration_ms",
+		"openfga_condition_evaluation_duration_ms",
+	}
+
+	expectedMetrics = append(expectedMetrics, connectionMetricName)
+
+	for _, metric := range expectedMetrics {
+		count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, metric)
+		require.NoError(t, err)
+		require.GreaterOrEqualf(t, count, 1, "expected at least 1 reported value for '%s'", metric)
+	}
 }
 
-func TestGRPCServingTLS(t *testing.T) {
+func TestHTTPServerDisabled(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
-	t.Run("enable_grpc_TLS_is_false,_even_with_keys_set,_will_serve_plaintext", func(t *testing.T) {
-		certsAndKeys := createCertsAndKeys(t)
-		defer certsAndKeys.Clean()
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.HTTP.Enabled = false
 
-		cfg := testutils.MustDefaultConfigWithRandomPorts()
-		cfg.HTTP.Enabled = false
-		cfg.GRPC.TLS = &serverconfig.TLSConfig{
-			CertPath: certsAndKeys.serverCertFile,
-			KeyPath:  certsAndKeys.serverKeyFile,
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
 		}
-		cfg.GRPC.Addr = "localhost:8082"
+	}()
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, "", nil)
 
-		go func() {
-			if err := runServer(ctx, cfg); err != nil {
-				log.Fatal(err)
-			}
-		}()
+	_, err := http.Get(fmt.Sprintf("http://%s/healthz", cfg.HTTP.Addr))
+	require.Error(t, err)
+	require.ErrorContains(t, err, "connect: connection refused")
+}
 
-		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, "", nil)
+func TestHTTPServerEnabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
 	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
 
-	t.Run("enable_grpc_TLS_is_true_will_serve_grpc_TLS", func(t *testing.T) {
-		certsAndKeys := createCertsAndKeys(t)
-		defer certsAndKeys.Clean()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		cfg := testutils.MustDefaultConfigWithRandomPorts()
-		cfg.HTTP.Enabled = false
-		cfg.GRPC.TLS = &serverconfig.TLSConfig{
-			Enabled:  true,
-			CertPath: certsAndKeys.serverCertFile,
-			KeyPath:  certsAndKeys.serverKeyFile,
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
 		}
-		cfg.GRPC.Addr = "localhost:8082"
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+}
+
+func TestPlaygroundEnabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Playground.Enabled = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	t.Cleanup(func() {
+		wg.Wait()
+	})
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	c := retryablehttp.NewClient()
+	t.Cleanup(c.HTTPClient.CloseIdleConnections)
+
+	playgroundAddr := cfg.Playground.PlaygroundAddr()
+	resp, err := c.Get(fmt.Sprintf("http://%s/playground", playgroundAddr))
+	require.NoError(t, err, "http playground endpoint not healthy")
+	t.Cleanup(func() {
+		err := resp.Body.Close()
+		require.NoError(t, err)
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code received from server")
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg, err := ReadConfig()
+	require.NoError(t, err)
+
+	_, basepath, _, _ := runtime.Caller(0)
+	jsonSchema, err := os.ReadFile(path.Join(filepath.Dir(basepath), "..", "..", ".config-schema.json"))
+	require.NoError(t, err)
+
+	res := gjson.ParseBytes(jsonSchema)
+
+	val := res.Get("properties.datastore.properties.engine.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.Engine)
+
+	val = res.Get("properties.datastore.properties.maxCacheSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.MaxCacheSize)
+
+	val = res.Get("properties.datastore.properties.maxTypesystemCacheSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.MaxTypesystemCacheSize)
+
+	val = res.Get("properties.datastore.properties.typesystemCompilationCacheEngine.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.TypesystemCompilationCacheEngine)
+
+	val = res.Get("properties.datastore.properties.latestModelCacheTTL.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.LatestModelCacheTTL.String())
+
+	val = res.Get("properties.datastore.properties.latestModelCacheEngine.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.LatestModelCacheEngine)
+
+	val = res.Get("properties.datastore.properties.maxIdleConns.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.MaxIdleConns)
+
+	val = res.Get("properties.datastore.properties.minIdleConns.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.MinIdleConns)
+
+	val = res.Get("properties.datastore.properties.maxOpenConns.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.MaxOpenConns)
+
+	val = res.Get("properties.datastore.properties.minOpenConns.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.MinOpenConns)
+
+	val = res.Get("properties.datastore.properties.connMaxIdleTime.default")
+	require.True(t, val.Exists())
+
+	val = res.Get("properties.datastore.properties.connMaxLifetime.default")
+	require.True(t, val.Exists())
+
+	val = res.Get("properties.datastore.properties.metrics.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.False(t, val.Bool())
+
+	val = res.Get("properties.datastore.properties.indexAdvisor.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Datastore.IndexAdvisor.Enabled)
+
+	val = res.Get("properties.datastore.properties.indexAdvisor.properties.slowQueryThreshold.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.IndexAdvisor.SlowQueryThreshold.String())
+
+	val = res.Get("properties.datastore.properties.reverseUserIndex.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Datastore.ReverseUserIndex.Enabled)
+
+	val = res.Get("properties.datastore.properties.maintenanceTuning.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Datastore.MaintenanceTuning.Enabled)
+
+	val = res.Get("properties.datastore.properties.transactionPooling.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Datastore.TransactionPooling.Enabled)
+
+	val = res.Get("properties.datastore.properties.iamAuth.properties.method.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.IAMAuth.Method)
+
+	val = res.Get("properties.datastore.properties.iamAuth.properties.region.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.IAMAuth.Region)
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	val = res.Get("properties.grpc.properties.addr.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.GRPC.Addr)
 
-		go func() {
-			if err := runServer(ctx, cfg); err != nil {
-				log.Fatal(err)
-			}
-		}()
+	val = res.Get("properties.grpc.properties.maxRecvMsgBytes.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.GRPC.MaxRecvMsgBytes)
 
-		certPool := x509.NewCertPool()
-		certPool.AddCert(certsAndKeys.caCert)
-		creds := credentials.NewClientTLSFromCert(certPool, "")
+	val = res.Get("properties.grpc.properties.maxSendMsgBytes.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.GRPC.MaxSendMsgBytes)
 
-		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, "", creds)
-	})
-}
+	val = res.Get("properties.grpc.properties.keepalive.properties.time.default")
+	require.True(t, val.Exists())
+	grpcKeepaliveTime, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, grpcKeepaliveTime, cfg.GRPC.Keepalive.Time)
 
-func TestHTTPServerWithGRPCTLSEnabled(t *testing.T) {
-	t.Cleanup(func() {
-		goleak.VerifyNone(t)
-	})
-	t.Run("http_gateway_proxies_to_grpc_server_with_tls", func(t *testing.T) {
-		certsAndKeys := createCertsAndKeys(t)
-		defer certsAndKeys.Clean()
+	val = res.Get("properties.grpc.properties.keepalive.properties.timeout.default")
+	require.True(t, val.Exists())
+	grpcKeepaliveTimeout, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, grpcKeepaliveTimeout, cfg.GRPC.Keepalive.Timeout)
 
-		cfg := testutils.MustDefaultConfigWithRandomPorts()
-		cfg.GRPC.TLS = &serverconfig.TLSConfig{
-			Enabled:  true,
-			CertPath: certsAndKeys.serverCertFile,
-			KeyPath:  certsAndKeys.serverKeyFile,
-		}
-		cfg.GRPC.Addr = "localhost:8082"
+	val = res.Get("properties.grpc.properties.keepalive.properties.minTime.default")
+	require.True(t, val.Exists())
+	grpcKeepaliveMinTime, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, grpcKeepaliveMinTime, cfg.GRPC.Keepalive.MinTime)
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	val = res.Get("properties.grpc.properties.keepalive.properties.permitWithoutStream.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.GRPC.Keepalive.PermitWithoutStream)
 
-		go func() {
-			if err := runServer(ctx, cfg); err != nil {
-				log.Fatal(err)
-			}
-		}()
+	val = res.Get("properties.grpc.properties.maxConnectionIdle.default")
+	require.True(t, val.Exists())
+	grpcMaxConnectionIdle, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, grpcMaxConnectionIdle, cfg.GRPC.MaxConnectionIdle)
 
-		certPool := x509.NewCertPool()
-		certPool.AddCert(certsAndKeys.caCert)
-		creds := credentials.NewClientTLSFromCert(certPool, "")
+	val = res.Get("properties.grpc.properties.maxConnectionAge.default")
+	require.True(t, val.Exists())
+	grpcMaxConnectionAge, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, grpcMaxConnectionAge, cfg.GRPC.MaxConnectionAge)
 
-		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, creds)
+	val = res.Get("properties.grpc.properties.maxConnectionAgeGrace.default")
+	require.True(t, val.Exists())
+	grpcMaxConnectionAgeGrace, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, grpcMaxConnectionAgeGrace, cfg.GRPC.MaxConnectionAgeGrace)
 
-		// Verify the HTTP gateway can proxy requests to the gRPC server over TLS.
-		// This validates that the gateway's internal gRPC client uses dynamic TLS
-		// credentials (via certwatcher) rather than a one-time static load.
-		client := retryablehttp.NewClient()
-		t.Cleanup(client.HTTPClient.CloseIdleConnections)
+	val = res.Get("properties.grpc.properties.maxConcurrentStreams.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.GRPC.MaxConcurrentStreams)
 
-		resp, err := client.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
-		require.NoError(t, err)
-		defer resp.Body.Close()
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-	})
+	val = res.Get("properties.http.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.HTTP.Enabled)
 
-	t.Run("http_gateway_handles_grpc_certificate_rotation", func(t *testing.T) {
-		certsAndKeys := createCertsAndKeys(t)
-		defer certsAndKeys.Clean()
+	val = res.Get("properties.http.properties.addr.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.HTTP.Addr)
 
-		cfg := testutils.MustDefaultConfigWithRandomPorts()
-		cfg.GRPC.TLS = &serverconfig.TLSConfig{
-			Enabled:  true,
-			CertPath: certsAndKeys.serverCertFile,
-			KeyPath:  certsAndKeys.serverKeyFile,
-		}
-		cfg.GRPC.Addr = "localhost:8082"
+	val = res.Get("properties.authzen.properties.baseURL.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Authzen.BaseURL)
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	val = res.Get("properties.opaBundle.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.OPABundle.Enabled)
 
-		go func() {
-			if err := runServer(ctx, cfg); err != nil {
-				log.Fatal(err)
-			}
-		}()
+	val = res.Get("properties.opaBundle.properties.maxTuples.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.OPABundle.MaxTuples)
 
-		certPool := x509.NewCertPool()
-		certPool.AddCert(certsAndKeys.caCert)
-		creds := credentials.NewClientTLSFromCert(certPool, "")
+	val = res.Get("properties.opaBundle.properties.maxDeltaChanges.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.OPABundle.MaxDeltaChanges)
 
-		testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, creds)
+	val = res.Get("properties.changelogRetention.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ChangelogRetention.Enabled)
 
-		// Make initial request to verify connectivity
-		client := retryablehttp.NewClient()
-		t.Cleanup(client.HTTPClient.CloseIdleConnections)
+	val = res.Get("properties.changelogRetention.properties.interval.default")
+	require.True(t, val.Exists())
+	interval, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, interval, cfg.ChangelogRetention.Interval)
 
-		resp, err := client.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
-		require.NoError(t, err)
-		defer resp.Body.Close()
-		require.Equal(t, http.StatusOK, resp.StatusCode)
+	val = res.Get("properties.changelogRetention.properties.maxAge.default")
+	require.True(t, val.Exists())
+	maxAge, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, maxAge, cfg.ChangelogRetention.MaxAge)
 
-		// Simulate certificate rotation by generating new certs and overwriting files
-		newCertsAndKeys := createCertsAndKeys(t)
-		defer newCertsAndKeys.Clean()
+	val = res.Get("properties.changelogRetention.properties.maxEntries.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ChangelogRetention.MaxEntries)
 
-		// Overwrite the cert and key files with new ones
-		err = os.WriteFile(certsAndKeys.serverCertFile, readFile(t, newCertsAndKeys.serverCertFile), 0600)
-		require.NoError(t, err)
-		err = os.WriteFile(certsAndKeys.serverKeyFile, readFile(t, newCertsAndKeys.serverKeyFile), 0600)
-		require.NoError(t, err)
+	val = res.Get("properties.changelogRetention.properties.cursorLease.default")
+	require.True(t, val.Exists())
+	cursorLease, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, cursorLease, cfg.ChangelogRetention.CursorLease)
 
-		// Wait for certwatcher to detect and reload the certificate
-		time.Sleep(2 * time.Second)
+	val = res.Get("properties.changelogRetention.properties.storePolicies.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.ChangelogRetention.StorePolicies))
 
-		// Verify the HTTP gateway can still proxy requests after certificate rotation.
-		// This validates that the gateway's internal gRPC client dynamically updates
-		// its certificate pool via the certwatcher callback.
-		resp2, err := client.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
-		require.NoError(t, err)
-		defer resp2.Body.Close()
-		require.Equal(t, http.StatusOK, resp2.StatusCode)
-	})
-}
+	val = res.Get("properties.orphanedTuples.properties.endpointEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.OrphanedTuples.EndpointEnabled)
 
-func TestServerMetricsReporting(t *testing.T) {
-	t.Cleanup(func() {
-		goleak.VerifyNone(t)
-	})
-	const nonPgxPrometheusMetrics = "go_sql_idle_connections"
-	const pgxPrometheusMetrics = "pgxpool_idle_conns"
-	t.Run("mysql", func(t *testing.T) {
-		testServerMetricsReporting(t, "mysql", nonPgxPrometheusMetrics)
-	})
-	t.Run("postgres", func(t *testing.T) {
-		testServerMetricsReporting(t, "postgres", pgxPrometheusMetrics)
-	})
-	t.Run("sqlite", func(t *testing.T) {
-		testServerMetricsReporting(t, "sqlite", nonPgxPrometheusMetrics)
-	})
-}
+	val = res.Get("properties.orphanedTuples.properties.collectorEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.OrphanedTuples.CollectorEnabled)
 
-func testServerMetricsReporting(t *testing.T, engine string, connectionMetricName string) {
-	t.Cleanup(func() {
-		goleak.VerifyNone(t)
-	})
-	testDatastore := storagefixtures.RunDatastoreTestContainer(t, engine)
+	val = res.Get("properties.orphanedTuples.properties.collectorInterval.default")
+	require.True(t, val.Exists())
+	collectorInterval, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, collectorInterval, cfg.OrphanedTuples.CollectorInterval)
 
-	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Datastore.Engine = engine
-	cfg.Datastore.URI = testDatastore.GetConnectionURI(true)
-	cfg.Datastore.Metrics.Enabled = true
-	cfg.Metrics.Enabled = true
-	cfg.Metrics.EnableRPCHistograms = true
-	metricsPort, metricsPortReleaser := testutils.TCPRandomPort()
-	metricsPortReleaser()
+	val = res.Get("properties.orphanedTuples.properties.collectorDryRun.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.OrphanedTuples.CollectorDryRun)
 
-	cfg.Metrics.Addr = fmt.Sprintf("localhost:%d", metricsPort)
+	val = res.Get("properties.consistencyVerifier.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ConsistencyVerifier.Enabled)
 
-	cfg.MaxConcurrentReadsForCheck = 30
-	cfg.MaxConcurrentReadsForListObjects = 30
+	val = res.Get("properties.consistencyVerifier.properties.interval.default")
+	require.True(t, val.Exists())
+	verifierInterval, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, verifierInterval, cfg.ConsistencyVerifier.Interval)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	val = res.Get("properties.consistencyVerifier.properties.sampleSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ConsistencyVerifier.SampleSize)
 
-	go func() {
-		if err := runServer(ctx, cfg); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	val = res.Get("properties.shadowCheck.properties.timeout.default")
+	require.True(t, val.Exists())
+	shadowCheckTimeout, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, shadowCheckTimeout, cfg.ShadowCheck.Timeout)
 
-	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+	val = res.Get("properties.shadowCheck.properties.samplePercentage.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ShadowCheck.SamplePercentage)
 
-	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	val = res.Get("properties.requestRecording.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.RequestRecording.Enabled)
 
-	client := openfgav1.NewOpenFGAServiceClient(conn)
+	val = res.Get("properties.requestRecording.properties.samplePercentage.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.RequestRecording.SamplePercentage)
 
-	createStoreResp, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{
-		Name: "test-store",
-	})
+	val = res.Get("properties.requestRecording.properties.anonymize.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.RequestRecording.Anonymize)
+
+	val = res.Get("properties.hotPaths.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.HotPaths.Enabled)
+
+	val = res.Get("properties.hotPaths.properties.interval.default")
+	require.True(t, val.Exists())
+	hotPathsInterval, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
+	require.Equal(t, hotPathsInterval, cfg.HotPaths.Interval)
 
-	storeID := createStoreResp.GetId()
+	val = res.Get("properties.hotPaths.properties.topN.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.HotPaths.TopN)
 
-	_, err = client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
-		StoreId: storeID,
-		TypeDefinitions: []*openfgav1.TypeDefinition{
-			{
-				Type: "user",
-			},
-			{
-				Type: "document",
-				Relations: map[string]*openfgav1.Userset{
-					"allowed": typesystem.This(),
-					"editor":  typesystem.This(),
-					"viewer": typesystem.Union(
-						typesystem.This(),
-						typesystem.Intersection(
-							typesystem.ComputedUserset("editor"),
-							typesystem.ComputedUserset("allowed"))),
-				},
-				Metadata: &openfgav1.Metadata{
-					Relations: map[string]*openfgav1.RelationMetadata{
-						"allowed": {
-							DirectlyRelatedUserTypes: []*openfgav1.RelationReference{
-								{Type: "user"},
-							},
-						},
-						"editor": {
-							DirectlyRelatedUserTypes: []*openfgav1.RelationReference{
-								{Type: "user"},
-							},
-						},
-						"viewer": {
-							DirectlyRelatedUserTypes: []*openfgav1.RelationReference{
-								{Type: "user", Condition: "condx"},
-							},
-						},
-					},
-				},
-			},
-		},
-		Conditions: map[string]*openfgav1.Condition{
-			"condx": {
-				Name: "condx",
-				Parameters: map[string]*openfgav1.ConditionParamTypeRef{
-					"x": {
-						TypeName: openfgav1.ConditionParamTypeRef_TYPE_NAME_INT,
-					},
-				},
-				Expression: "x < 100",
-			},
-		},
-		SchemaVersion: typesystem.SchemaVersion1_1,
-	})
+	val = res.Get("properties.hotPaths.properties.minChecks.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.HotPaths.MinChecks)
+
+	val = res.Get("properties.hotPaths.properties.maxObjects.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.HotPaths.MaxObjects)
+
+	val = res.Get("properties.materializedPermissions.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.MaterializedPermissions.Enabled)
+
+	val = res.Get("properties.materializedPermissions.properties.relations.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.MaterializedPermissions.Relations))
+
+	val = res.Get("properties.materializedPermissions.properties.interval.default")
+	require.True(t, val.Exists())
+	materializedPermissionsInterval, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
+	require.Equal(t, materializedPermissionsInterval, cfg.MaterializedPermissions.Interval)
 
-	_, err = client.Write(ctx, &openfgav1.WriteRequest{
-		StoreId: storeID,
-		Writes: &openfgav1.WriteRequestWrites{
-			TupleKeys: []*openfgav1.TupleKey{
-				tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:jon", "condx", nil),
-				{Object: "document:2", Relation: "editor", User: "user:jon"},
-				{Object: "document:2", Relation: "allowed", User: "user:jon"},
-			},
-		},
-	})
+	val = res.Get("properties.materializedObjectLists.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.MaterializedObjectLists.Enabled)
+
+	val = res.Get("properties.materializedObjectLists.properties.interval.default")
+	require.True(t, val.Exists())
+	materializedObjectListsInterval, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
+	require.Equal(t, materializedObjectListsInterval, cfg.MaterializedObjectLists.Interval)
 
-	checkResp, err := client.Check(ctx, &openfgav1.CheckRequest{
-		StoreId:  storeID,
-		TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
-		Context: testutils.MustNewStruct(t, map[string]interface{}{
-			"x": 10,
-		}),
-	})
+	val = res.Get("properties.materializedObjectLists.properties.topN.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.MaterializedObjectLists.TopN)
+
+	val = res.Get("properties.materializedObjectLists.properties.minRequests.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.MaterializedObjectLists.MinRequests)
+
+	val = res.Get("properties.cluster.properties.singleflightEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Cluster.SingleflightEnabled)
+
+	val = res.Get("properties.cluster.properties.forwardTimeout.default")
+	require.True(t, val.Exists())
+	clusterForwardTimeout, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
-	require.True(t, checkResp.GetAllowed())
+	require.Equal(t, clusterForwardTimeout, cfg.Cluster.ForwardTimeout)
 
-	listObjectsResp, err := client.ListObjects(ctx, &openfgav1.ListObjectsRequest{
-		StoreId:  storeID,
-		Type:     "document",
-		Relation: "viewer",
-		User:     "user:jon",
-		Context: testutils.MustNewStruct(t, map[string]interface{}{
-			"x": 10,
-		}),
-	})
+	val = res.Get("properties.cluster.properties.tlsEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Cluster.TLSEnabled)
+
+	val = res.Get("properties.cluster.properties.dispatchEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Cluster.DispatchEnabled)
+
+	val = res.Get("properties.cluster.properties.dispatchServiceEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Cluster.DispatchServiceEnabled)
+
+	val = res.Get("properties.priorityScheduling.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.PriorityScheduling.Enabled)
+
+	val = res.Get("properties.priorityScheduling.properties.maxInFlight.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.PriorityScheduling.MaxInFlight)
+
+	val = res.Get("properties.priorityScheduling.properties.methodClasses.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.PriorityScheduling.MethodClasses))
+
+	val = res.Get("properties.batchCheckAdaptiveLimits.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.BatchCheckAdaptiveLimits.Enabled)
+
+	val = res.Get("properties.batchCheckAdaptiveLimits.properties.loadThreshold.default")
+	require.True(t, val.Exists())
+	require.InDelta(t, val.Float(), cfg.BatchCheckAdaptiveLimits.LoadThreshold, 0)
+
+	val = res.Get("properties.batchCheckAdaptiveLimits.properties.minRatio.default")
+	require.True(t, val.Exists())
+	require.InDelta(t, val.Float(), cfg.BatchCheckAdaptiveLimits.MinRatio, 0)
+
+	val = res.Get("properties.streamedBatchCheck.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.StreamedBatchCheck.Enabled)
+
+	val = res.Get("properties.bulkWrite.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.BulkWrite.Enabled)
+
+	val = res.Get("properties.bulkWrite.properties.window.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.BulkWrite.Window)
+
+	val = res.Get("properties.storePurge.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.StorePurge.Enabled)
+
+	val = res.Get("properties.storePurge.properties.jobRetention.default")
+	require.True(t, val.Exists())
+	jobRetention, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
-	require.Len(t, listObjectsResp.GetObjects(), 2)
-	require.Contains(t, listObjectsResp.GetObjects(), "document:1")
-	require.Contains(t, listObjectsResp.GetObjects(), "document:2")
+	require.Equal(t, jobRetention, cfg.StorePurge.JobRetention)
 
-	resp, err := retryablehttp.Get(fmt.Sprintf("http://%s/metrics", cfg.Metrics.Addr))
+	val = res.Get("properties.storeEvents.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.StoreEvents.Enabled)
+
+	val = res.Get("properties.listObjectsSubscription.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ListObjectsSubscription.Enabled)
+
+	val = res.Get("properties.listObjectsSubscription.properties.pollInterval.default")
+	require.True(t, val.Exists())
+	listObjectsSubscriptionPollInterval, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
-	require.Equal(t, http.StatusOK, resp.StatusCode)
-	defer resp.Body.Close()
+	require.Equal(t, listObjectsSubscriptionPollInterval, cfg.ListObjectsSubscription.PollInterval)
 
-	expectedMetrics := []string{
-		"openfga_datastore_query_count",
-		"openfga_request_duration_ms",
-		"grpc_server_handling_seconds",
-		"openfga_list_objects_further_eval_required_count",
-		"openfga_list_objects_no_further_eval_required_count",
-		"openfga_condition_evaluation_cost",
-		"openfga_condition_compilation_duration_ms",
-		"openfga_condition_evaluation_duration_ms",
-	}
+	val = res.Get("properties.cacheHints.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.CacheHints.Enabled)
 
-	expectedMetrics = append(expectedMetrics, connectionMetricName)
+	val = res.Get("properties.cacheHints.properties.maxAge.default")
+	require.True(t, val.Exists())
+	cacheHintsMaxAge, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, cacheHintsMaxAge, cfg.CacheHints.MaxAge)
 
-	for _, metric := range expectedMetrics {
-		count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, metric)
-		require.NoError(t, err)
-		require.GreaterOrEqualf(t, count, 1, "expected at least 1 reported value for '%s'", metric)
-	}
-}
+	val = res.Get("properties.cacheHints.properties.invalidationPollInterval.default")
+	require.True(t, val.Exists())
+	cacheHintsInvalidationPollInterval, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, cacheHintsInvalidationPollInterval, cfg.CacheHints.InvalidationPollInterval)
 
-func TestHTTPServerDisabled(t *testing.T) {
-	t.Cleanup(func() {
-		goleak.VerifyNone(t)
-	})
-	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.HTTP.Enabled = false
+	val = res.Get("properties.sessionTokens.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.SessionTokens.Enabled)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	val = res.Get("properties.sessionTokens.properties.commitMargin.default")
+	require.True(t, val.Exists())
+	sessionTokensCommitMargin, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, sessionTokensCommitMargin, cfg.SessionTokens.CommitMargin)
 
-	go func() {
-		if err := runServer(ctx, cfg); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	val = res.Get("properties.assertionCoverage.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.AssertionCoverage.Enabled)
 
-	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, "", nil)
+	val = res.Get("properties.modelGraph.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ModelGraph.Enabled)
 
-	_, err := http.Get(fmt.Sprintf("http://%s/healthz", cfg.HTTP.Addr))
-	require.Error(t, err)
-	require.ErrorContains(t, err, "connect: connection refused")
-}
+	val = res.Get("properties.costEstimate.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.CostEstimate.Enabled)
 
-func TestHTTPServerEnabled(t *testing.T) {
-	t.Cleanup(func() {
-		goleak.VerifyNone(t)
-	})
-	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	val = res.Get("properties.costEstimate.properties.sampleSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.CostEstimate.SampleSize)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	val = res.Get("properties.whatIf.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.WhatIf.Enabled)
 
-	go func() {
-		if err := runServer(ctx, cfg); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	val = res.Get("properties.modelSimulation.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ModelSimulation.Enabled)
 
-	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
-}
+	val = res.Get("properties.accessReview.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.AccessReview.Enabled)
 
-func TestPlaygroundEnabled(t *testing.T) {
-	t.Cleanup(func() {
-		goleak.VerifyNone(t)
-	})
-	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Playground.Enabled = true
+	val = res.Get("properties.accessReview.properties.artifactDir.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.AccessReview.ArtifactDir)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	val = res.Get("properties.accessReview.properties.jobRetention.default")
+	require.True(t, val.Exists())
+	accessReviewJobRetention, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, accessReviewJobRetention, cfg.AccessReview.JobRetention)
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := runServer(ctx, cfg); err != nil {
-			log.Fatal(err)
-		}
-	}()
-	t.Cleanup(func() {
-		wg.Wait()
-	})
+	val = res.Get("properties.jobs.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Jobs.Enabled)
 
-	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+	val = res.Get("properties.jobs.properties.workers.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Jobs.Workers)
 
-	c := retryablehttp.NewClient()
-	t.Cleanup(c.HTTPClient.CloseIdleConnections)
+	val = res.Get("properties.jobs.properties.queueSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Jobs.QueueSize)
 
-	playgroundAddr := cfg.Playground.PlaygroundAddr()
-	resp, err := c.Get(fmt.Sprintf("http://%s/playground", playgroundAddr))
-	require.NoError(t, err, "http playground endpoint not healthy")
-	t.Cleanup(func() {
-		err := resp.Body.Close()
-		require.NoError(t, err)
-	})
-	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code received from server")
-}
+	val = res.Get("properties.jobs.properties.retention.default")
+	require.True(t, val.Exists())
+	jobsRetention, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, jobsRetention, cfg.Jobs.Retention)
 
-func TestDefaultConfig(t *testing.T) {
-	cfg, err := ReadConfig()
+	val = res.Get("properties.jobs.properties.scheduleInterval.default")
+	require.True(t, val.Exists())
+	jobsScheduleInterval, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
+	require.Equal(t, jobsScheduleInterval, cfg.Jobs.ScheduleInterval)
 
-	_, basepath, _, _ := runtime.Caller(0)
-	jsonSchema, err := os.ReadFile(path.Join(filepath.Dir(basepath), "..", "..", ".config-schema.json"))
+	val = res.Get("properties.multiRegion.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.MultiRegion.Enabled)
+
+	val = res.Get("properties.multiRegion.properties.regionId.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.MultiRegion.RegionID)
+
+	val = res.Get("properties.writeValidationHook.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.WriteValidationHook.Enabled)
+
+	val = res.Get("properties.writeValidationHook.properties.url.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.WriteValidationHook.URL)
+
+	val = res.Get("properties.writeValidationHook.properties.timeout.default")
+	require.True(t, val.Exists())
+	writeValidationHookTimeout, err := time.ParseDuration(val.String())
 	require.NoError(t, err)
+	require.Equal(t, writeValidationHookTimeout, cfg.WriteValidationHook.Timeout)
 
-	res := gjson.ParseBytes(jsonSchema)
+	val = res.Get("properties.writeValidationHook.properties.failOpen.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.WriteValidationHook.FailOpen)
 
-	val := res.Get("properties.datastore.properties.engine.default")
+	val = res.Get("properties.contextEnrichmentHook.properties.enabled.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.String(), cfg.Datastore.Engine)
+	require.Equal(t, val.Bool(), cfg.ContextEnrichmentHook.Enabled)
 
-	val = res.Get("properties.datastore.properties.maxCacheSize.default")
+	val = res.Get("properties.contextEnrichmentHook.properties.url.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.Datastore.MaxCacheSize)
+	require.Equal(t, val.String(), cfg.ContextEnrichmentHook.URL)
 
-	val = res.Get("properties.datastore.properties.maxTypesystemCacheSize.default")
+	val = res.Get("properties.contextEnrichmentHook.properties.timeout.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.Datastore.MaxTypesystemCacheSize)
+	contextEnrichmentHookTimeout, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, contextEnrichmentHookTimeout, cfg.ContextEnrichmentHook.Timeout)
 
-	val = res.Get("properties.datastore.properties.maxIdleConns.default")
+	val = res.Get("properties.contextEnrichmentHook.properties.cacheTTL.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.Datastore.MaxIdleConns)
+	contextEnrichmentHookCacheTTL, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, contextEnrichmentHookCacheTTL, cfg.ContextEnrichmentHook.CacheTTL)
 
-	val = res.Get("properties.datastore.properties.minIdleConns.default")
+	val = res.Get("properties.contextEnrichmentHook.properties.cacheMaxEntries.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.Datastore.MinIdleConns)
+	require.EqualValues(t, val.Int(), cfg.ContextEnrichmentHook.CacheMaxEntries)
 
-	val = res.Get("properties.datastore.properties.maxOpenConns.default")
+	val = res.Get("properties.conditionContext.properties.headers.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.Datastore.MaxOpenConns)
+	require.Len(t, val.Array(), len(cfg.ConditionContext.Headers))
 
-	val = res.Get("properties.datastore.properties.minOpenConns.default")
+	val = res.Get("properties.conditionContext.properties.claims.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.Datastore.MinOpenConns)
+	require.Len(t, val.Array(), len(cfg.ConditionContext.Claims))
 
-	val = res.Get("properties.datastore.properties.connMaxIdleTime.default")
+	val = res.Get("properties.slo.properties.enabled.default")
 	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.SLO.Enabled)
 
-	val = res.Get("properties.datastore.properties.connMaxLifetime.default")
+	val = res.Get("properties.slo.properties.objectives.default")
 	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.SLO.Objectives))
 
-	val = res.Get("properties.datastore.properties.metrics.properties.enabled.default")
+	val = res.Get("properties.slo.properties.window.default")
 	require.True(t, val.Exists())
-	require.False(t, val.Bool())
+	window, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, window, cfg.SLO.Window)
 
-	val = res.Get("properties.grpc.properties.addr.default")
+	val = res.Get("properties.http.properties.maxRequestBodyBytes.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.String(), cfg.GRPC.Addr)
+	require.EqualValues(t, val.Int(), cfg.HTTP.MaxRequestBodyBytes)
 
-	val = res.Get("properties.grpc.properties.maxRecvMsgBytes.default")
+	val = res.Get("properties.http.properties.corsAllowedMethods.default")
 	require.True(t, val.Exists())
-	require.EqualValues(t, val.Int(), cfg.GRPC.MaxRecvMsgBytes)
+	methods := []string{}
+	for _, method := range val.Array() {
+		methods = append(methods, method.String())
+	}
+	require.Equal(t, methods, cfg.HTTP.CORSAllowedMethods)
 
-	val = res.Get("properties.http.properties.enabled.default")
+	val = res.Get("properties.http.properties.corsExposedHeaders.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.Bool(), cfg.HTTP.Enabled)
+	require.Len(t, val.Array(), len(cfg.HTTP.CORSExposedHeaders))
 
-	val = res.Get("properties.http.properties.addr.default")
+	val = res.Get("properties.http.properties.corsMaxAge.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.String(), cfg.HTTP.Addr)
+	corsMaxAge, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, corsMaxAge, cfg.HTTP.CORSMaxAge)
 
-	val = res.Get("properties.authzen.properties.baseURL.default")
+	val = res.Get("properties.http.properties.corsAllowCredentials.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.String(), cfg.Authzen.BaseURL)
+	require.Equal(t, val.Bool(), cfg.HTTP.CORSAllowCredentials)
+
+	val = res.Get("properties.http.properties.serverTimingEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.HTTP.ServerTimingEnabled)
 
 	val = res.Get("properties.playground.properties.enabled.default")
 	require.True(t, val.Exists())
@@ -1249,6 +2396,14 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.MaxTuplesPerWrite)
 
+	val = res.Get("properties.maxContextualTuples.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.MaxContextualTuples)
+
+	val = res.Get("properties.contextualTuplesValidationMode.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ContextualTuplesValidationMode)
+
 	val = res.Get("properties.maxTypesPerAuthorizationModel.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.MaxTypesPerAuthorizationModel)
@@ -1273,6 +2428,10 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.Equal(t, val.Uint(), cfg.MaxConditionEvaluationCost)
 
+	val = res.Get("properties.conditionExtensions.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.ConditionExtensions))
+
 	val = res.Get("properties.maxConcurrentReadsForListUsers.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.MaxConcurrentReadsForListUsers)
@@ -1305,6 +2464,14 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.ListObjectsMaxResults)
 
+	val = res.Get("properties.streamedListObjectsMaxInFlightMessages.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.StreamedListObjectsMaxInFlightMessages)
+
+	val = res.Get("properties.streamedListObjectsMessagesPerSecond.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.StreamedListObjectsMessagesPerSecond)
+
 	val = res.Get("properties.listObjectsPipelineEnabled.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.Bool(), cfg.ListObjectsPipelineEnabled)
@@ -1339,11 +2506,23 @@ func TestDefaultConfig(t *testing.T) {
 
 	val = res.Get("properties.trace.properties.serviceName.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.String(), cfg.Trace.ServiceName)
+	require.Equal(t, val.String(), cfg.Trace.ServiceName)
+
+	val = res.Get("properties.trace.properties.resourceAttributes.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Trace.ResourceAttributes)
+
+	val = res.Get("properties.trace.properties.verboseRequestsEnabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Trace.VerboseRequestsEnabled)
+
+	val = res.Get("properties.trace.properties.storeSampleRatios.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.Trace.StoreSampleRatios))
 
-	val = res.Get("properties.trace.properties.resourceAttributes.default")
+	val = res.Get("properties.trace.properties.methodSampleRatios.default")
 	require.True(t, val.Exists())
-	require.Equal(t, val.String(), cfg.Trace.ResourceAttributes)
+	require.Len(t, val.Array(), len(cfg.Trace.MethodSampleRatios))
 
 	val = res.Get("properties.trace.properties.otlp.properties.endpoint.default")
 	require.True(t, val.Exists())
@@ -1353,10 +2532,22 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.Equal(t, val.Bool(), cfg.Trace.OTLP.TLS.Enabled)
 
+	val = res.Get("properties.checkCache.properties.engine.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.CheckCache.Engine)
+
 	val = res.Get("properties.checkCache.properties.limit.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.CheckCache.Limit)
 
+	val = res.Get("properties.checkCache.properties.maxBytes.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.CheckCache.MaxBytes)
+
+	val = res.Get("properties.checkCache.properties.shards.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.CheckCache.Shards)
+
 	val = res.Get("properties.checkQueryCache.properties.enabled.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.Bool(), cfg.CheckQueryCache.Enabled)
@@ -1389,6 +2580,18 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.ListObjectsIteratorCache.TTL.String())
 
+	val = res.Get("properties.listObjectsQueryCache.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ListObjectsQueryCache.Enabled)
+
+	val = res.Get("properties.listObjectsQueryCache.properties.maxResults.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ListObjectsQueryCache.MaxResults)
+
+	val = res.Get("properties.listObjectsQueryCache.properties.ttl.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ListObjectsQueryCache.TTL.String())
+
 	val = res.Get("properties.cacheController.properties.enabled.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.Bool(), cfg.CacheController.Enabled)
@@ -1397,6 +2600,71 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.CacheController.TTL.String())
 
+	val = res.Get("properties.cacheInvalidationListener.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.CacheInvalidationListener.Enabled)
+
+	val = res.Get("properties.cacheInvalidationListener.properties.postgresPublication.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.CacheInvalidationListener.PostgresPublication)
+
+	val = res.Get("properties.cacheInvalidationListener.properties.pollInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.CacheInvalidationListener.PollInterval.String())
+
+	val = res.Get("properties.userIDEncryption.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.UserIDEncryption.Enabled)
+
+	val = res.Get("properties.userIDEncryption.properties.key.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.UserIDEncryption.Key)
+
+	val = res.Get("properties.userIDEncryption.properties.types.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.UserIDEncryption.Types))
+	require.Equal(t, val.Array()[0].String(), cfg.UserIDEncryption.Types[0])
+
+	val = res.Get("properties.redaction.properties.mode.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Redaction.Mode)
+
+	val = res.Get("properties.redaction.properties.key.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Redaction.Key)
+
+	val = res.Get("properties.redaction.properties.keyRotationPeriod.default")
+	require.True(t, val.Exists())
+	keyRotationPeriod, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, keyRotationPeriod, cfg.Redaction.KeyRotationPeriod)
+
+	val = res.Get("properties.secrets.properties.refreshInterval.default")
+	require.True(t, val.Exists())
+	refreshInterval, err := time.ParseDuration(val.String())
+	require.NoError(t, err)
+	require.Equal(t, refreshInterval, cfg.Secrets.RefreshInterval)
+
+	val = res.Get("properties.audit.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Audit.Enabled)
+
+	val = res.Get("properties.audit.properties.otlp.properties.endpoint.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Audit.OTLP.Endpoint)
+
+	val = res.Get("properties.audit.properties.otlp.properties.tls.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Audit.OTLP.TLS.Enabled)
+
+	val = res.Get("properties.audit.properties.queueSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Audit.QueueSize)
+
+	val = res.Get("properties.audit.properties.flushInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Audit.FlushInterval.String())
+
 	val = res.Get("properties.sharedIterator.properties.enabled.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.Bool(), cfg.SharedIterator.Enabled)
@@ -1479,6 +2747,46 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.CheckDatastoreThrottle.Duration.String())
 
+	val = res.Get("properties.checkStoreBulkhead.properties.limit.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.CheckStoreBulkhead.Limit)
+
+	val = res.Get("properties.checkCoalescing.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.CheckCoalescing.Enabled)
+
+	val = res.Get("properties.checkCoalescing.properties.window.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.CheckCoalescing.Window.String())
+
+	val = res.Get("properties.writeThrottling.properties.tuplesPerSecond.default")
+	require.True(t, val.Exists())
+	require.InDelta(t, val.Float(), cfg.WriteThrottling.TuplesPerSecond, 0)
+
+	val = res.Get("properties.writeThrottling.properties.changesPerSecond.default")
+	require.True(t, val.Exists())
+	require.InDelta(t, val.Float(), cfg.WriteThrottling.ChangesPerSecond, 0)
+
+	val = res.Get("properties.writeThrottling.properties.storeTuplesPerSecond.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.WriteThrottling.StoreTuplesPerSecond))
+
+	val = res.Get("properties.writeThrottling.properties.storeChangesPerSecond.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.WriteThrottling.StoreChangesPerSecond))
+
+	val = res.Get("properties.writeScheduling.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.WriteScheduling.Enabled)
+
+	val = res.Get("properties.writeScheduling.properties.maxInFlight.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.WriteScheduling.MaxInFlight)
+
+	val = res.Get("properties.writeScheduling.properties.storeWeights.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.WriteScheduling.StoreWeights))
+
 	val = res.Get("properties.listObjectsDatastoreThrottle.properties.threshold.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.ListObjectsDatastoreThrottle.Threshold)
@@ -1499,6 +2807,10 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.RequestTimeout.String())
 
+	val = res.Get("properties.methodTimeouts.default")
+	require.True(t, val.Exists())
+	require.Len(t, val.Array(), len(cfg.MethodTimeouts))
+
 	val = res.Get("properties.shutdownTimeout.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.ShutdownTimeout.String())
@@ -1662,20 +2974,310 @@ func TestRunCommandConfigIsMerged(t *testing.T) {
 		require.True(t, viper.GetBool("shared-iterator-enabled"))
 		require.Equal(t, uint32(950), viper.GetUint32("shared-iterator-limit"))
 
-		return nil
+		return nil
+	}
+
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.AddCommand(runCmd)
+	rootCmd.SetArgs([]string{"run"})
+	require.NoError(t, rootCmd.Execute())
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+
+	client := openfgav1.NewOpenFGAServiceClient(conn)
+
+	createStoreResp, err := client.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-demo",
+	})
+	require.NoError(t, err)
+
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := client.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustTransformDSLToProto(`
+			model
+				schema 1.1
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	authorizationModelID := writeModelResp.GetAuthorizationModelId()
+
+	httpClient := retryablehttp.NewClient()
+	t.Cleanup(httpClient.HTTPClient.CloseIdleConnections)
+
+	testCases := map[string]struct {
+		httpVerb      string
+		httpPath      string
+		httpJSONBody  string
+		expectedError bool
+	}{
+		`check`: {
+			httpVerb:     "POST",
+			httpPath:     fmt.Sprintf("http://%s/stores/%s/check", cfg.HTTP.Addr, storeID),
+			httpJSONBody: `{"tuple_key": {"user": "user:anne",  "relation": "viewer", "object": "document:1"}}`,
+		},
+		`listobjects`: {
+			httpVerb:     "POST",
+			httpPath:     fmt.Sprintf("http://%s/stores/%s/list-objects", cfg.HTTP.Addr, storeID),
+			httpJSONBody: `{"type": "document", "user": "user:anne", "relation": "viewer"}`,
+		},
+		`streamed-list-objects`: {
+			httpVerb:     "POST",
+			httpPath:     fmt.Sprintf("http://%s/stores/%s/streamed-list-objects", cfg.HTTP.Addr, storeID),
+			httpJSONBody: `{"type": "document", "user": "user:anne", "relation": "viewer"}`,
+		},
+		`listusers`: {
+			httpVerb:     "POST",
+			httpPath:     fmt.Sprintf("http://%s/stores/%s/list-users", cfg.HTTP.Addr, storeID),
+			httpJSONBody: `{"object": { "type": "document", "id": "1" } , "relation": "viewer", "user_filters": [ {"type":"user"} ]}`,
+		},
+		`expand`: {
+			httpVerb:     "POST",
+			httpPath:     fmt.Sprintf("http://%s/stores/%s/expand", cfg.HTTP.Addr, storeID),
+			httpJSONBody: `{"tuple_key": {"user": "user:anne",  "relation": "viewer", "object": "document:1"}}`,
+		},
+		`write`: {
+			httpVerb:     "POST",
+			httpPath:     fmt.Sprintf("http://%s/stores/%s/write", cfg.HTTP.Addr, storeID),
+			httpJSONBody: `{"writes": { "tuple_keys": [{"user": "user:anne",  "relation": "viewer", "object": "document:1"}]}}`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req, err := retryablehttp.NewRequest(test.httpVerb, test.httpPath, strings.NewReader(test.httpJSONBody))
+			require.NoError(t, err, "Failed to construct request")
+
+			httpResponse, err := httpClient.Do(req)
+			require.NoError(t, err)
+			defer httpResponse.Body.Close()
+
+			// These are set in the server RPCs
+			require.Len(t, httpResponse.Header[server.AuthorizationModelIDHeader], 1)
+			require.Equal(t, authorizationModelID, httpResponse.Header[server.AuthorizationModelIDHeader][0])
+
+			// These are set in middlewares
+			require.Len(t, httpResponse.Header[storeid.StoreIDHeader], 1)
+			require.Equal(t, storeID, httpResponse.Header[storeid.StoreIDHeader][0])
+			require.Len(t, httpResponse.Header[requestid.RequestIDHeader], 1)
+			require.NotEmpty(t, httpResponse.Header[requestid.RequestIDHeader][0])
+
+			httpResponse.Body.Close()
+		})
+	}
+
+	t.Run("authorization_model_not_modified", func(t *testing.T) {
+		path := fmt.Sprintf("http://%s/stores/%s/authorization-models/%s", cfg.HTTP.Addr, storeID, authorizationModelID)
+		req, err := retryablehttp.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		httpResponse, err := httpClient.Do(req)
+		require.NoError(t, err)
+		defer httpResponse.Body.Close()
+		require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+		etag := httpResponse.Header.Get(server.ETagHeader)
+		require.NotEmpty(t, etag)
+
+		req, err = retryablehttp.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		req.Header.Set(server.IfNoneMatchHeader, etag)
+		notModified, err := httpClient.Do(req)
+		require.NoError(t, err)
+		defer notModified.Body.Close()
+		require.Equal(t, http.StatusNotModified, notModified.StatusCode)
+		require.Equal(t, etag, notModified.Header.Get(server.ETagHeader))
+		body, err := io.ReadAll(notModified.Body)
+		require.NoError(t, err)
+		require.Empty(t, body)
+	})
+}
+
+func TestOPABundleEndpoint(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.OPABundle.Enabled = true
+	cfg.Authn.Method = "preshared"
+	cfg.Authn.AuthnPresharedKeyConfig = &serverconfig.AuthnPresharedKeyConfig{Keys: []string{"KEYONE"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	httpClient := retryablehttp.NewClient()
+	t.Cleanup(httpClient.HTTPClient.CloseIdleConnections)
+
+	do := func(method, path, body string, header http.Header) *http.Response {
+		req, err := retryablehttp.NewRequest(method, fmt.Sprintf("http://%s%s", cfg.HTTP.Addr, path), strings.NewReader(body))
+		require.NoError(t, err)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if req.Header.Get("Authorization") == "" {
+			req.Header.Set("Authorization", "Bearer KEYONE")
+		}
+		resp, err := httpClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/stores", `{"name": "bundle"}`, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var store openfgav1.CreateStoreResponse
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(body, &store))
+
+	resp = do(http.MethodPost, "/stores/"+store.GetId()+"/authorization-models",
+		`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "document", "relations": {"viewer": {"this": {}}}, "metadata": {"relations": {"viewer": {"directly_related_user_types": [{"type": "user"}]}}}}]}`, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/stores/"+store.GetId()+"/write", `{"writes": {"tuple_keys": [{"user": "user:anne", "relation": "viewer", "object": "document:1"}]}}`, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	bundlePath := "/stores/" + store.GetId() + "/opa/bundle"
+
+	resp = do(http.MethodGet, bundlePath, "", http.Header{"Authorization": {"Bearer wrong"}})
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = do(http.MethodGet, bundlePath, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/gzip", resp.Header.Get("Content-Type"))
+	etag := resp.Header.Get("Etag")
+	require.NotEmpty(t, etag)
+
+	resp = do(http.MethodGet, bundlePath, "", http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, resp.StatusCode, "the tuple written after the snapshot revision is sent as a delta")
+	etag = resp.Header.Get("Etag")
+
+	resp = do(http.MethodGet, bundlePath, "", http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestOrphanedTuplesEndpoint(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.OrphanedTuples.EndpointEnabled = true
+	cfg.Authn.Method = "preshared"
+	cfg.Authn.AuthnPresharedKeyConfig = &serverconfig.AuthnPresharedKeyConfig{Keys: []string{"KEYONE"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	httpClient := retryablehttp.NewClient()
+	t.Cleanup(httpClient.HTTPClient.CloseIdleConnections)
+
+	do := func(method, path, body, key string) *http.Response {
+		req, err := retryablehttp.NewRequest(method, fmt.Sprintf("http://%s%s", cfg.HTTP.Addr, path), strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := httpClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/stores", `{"name": "orphans"}`, "KEYONE")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var store openfgav1.CreateStoreResponse
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(body, &store))
+
+	resp = do(http.MethodPost, "/stores/"+store.GetId()+"/authorization-models",
+		`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "document", "relations": {"viewer": {"this": {}}, "editor": {"this": {}}}, "metadata": {"relations": {"viewer": {"directly_related_user_types": [{"type": "user"}]}, "editor": {"directly_related_user_types": [{"type": "user"}]}}}}]}`, "KEYONE")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/stores/"+store.GetId()+"/write", `{"writes": {"tuple_keys": [{"user": "user:anne", "relation": "viewer", "object": "document:1"}, {"user": "user:anne", "relation": "editor", "object": "document:1"}]}}`, "KEYONE")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/stores/"+store.GetId()+"/authorization-models",
+		`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "document", "relations": {"viewer": {"this": {}}}, "metadata": {"relations": {"viewer": {"directly_related_user_types": [{"type": "user"}]}}}}]}`, "KEYONE")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	orphansPath := "/stores/" + store.GetId() + "/orphaned-tuples"
+
+	resp = do(http.MethodGet, orphansPath, "", "wrong")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	report := func(resp *http.Response) orphans.Report {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var report orphans.Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return report
 	}
 
-	rootCmd := cmd.NewRootCommand()
-	rootCmd.AddCommand(runCmd)
-	rootCmd.SetArgs([]string{"run"})
-	require.NoError(t, rootCmd.Execute())
+	dryRun := report(do(http.MethodGet, orphansPath, "", "KEYONE"))
+	require.True(t, dryRun.DryRun)
+	require.Equal(t, 2, dryRun.ScannedTuples)
+	require.Equal(t, []orphans.OrphanedTuple{{
+		Object:   "document:1",
+		Relation: "editor",
+		User:     "user:anne",
+		Reason:   "relation 'document#editor' is not defined",
+	}}, dryRun.OrphanedTuples)
+	require.Zero(t, dryRun.DeletedTuples)
+
+	deleted := report(do(http.MethodDelete, orphansPath, "", "KEYONE"))
+	require.False(t, deleted.DryRun)
+	require.Equal(t, 1, deleted.DeletedTuples)
+
+	after := report(do(http.MethodGet, orphansPath, "", "KEYONE"))
+	require.Equal(t, 1, after.ScannedTuples)
+	require.Empty(t, after.OrphanedTuples)
 }
 
-func TestHTTPHeaders(t *testing.T) {
+func TestRequestRecording(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
 	})
 	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.RequestRecording.Enabled = true
+	cfg.RequestRecording.Path = filepath.Join(t.TempDir(), "recording.jsonl")
+	cfg.RequestRecording.SamplePercentage = 100
+	cfg.RequestRecording.Anonymize = false
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
@@ -1688,99 +3290,233 @@ func TestHTTPHeaders(t *testing.T) {
 	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
 
 	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
-
 	client := openfgav1.NewOpenFGAServiceClient(conn)
 
-	createStoreResp, err := client.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
-		Name: "openfga-demo",
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "recording"})
+	require.NoError(t, err)
+	writeModel := func(dsl string) string {
+		model := parser.MustTransformDSLToProto(dsl)
+		resp, err := client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         store.GetId(),
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+	directModelID := writeModel(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user] or editor`)
+	_, err = client.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: store.GetId(),
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		}},
 	})
 	require.NoError(t, err)
 
-	storeID := createStoreResp.GetId()
+	check, err := client.Check(ctx, &openfgav1.CheckRequest{
+		StoreId:              store.GetId(),
+		AuthorizationModelId: directModelID,
+		TupleKey:             tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+	})
+	require.NoError(t, err)
+	require.True(t, check.GetAllowed())
 
-	writeModelResp, err := client.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
-		StoreId:       storeID,
-		SchemaVersion: typesystem.SchemaVersion1_1,
-		TypeDefinitions: parser.MustTransformDSLToProto(`
-			model
-				schema 1.1
-			type user
+	var recordingFile []byte
+	require.Eventually(t, func() bool {
+		recordingFile, err = os.ReadFile(cfg.RequestRecording.Path)
+		return err == nil && bytes.Count(recordingFile, []byte("\n")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
 
-			type document
-				relations
-					define viewer: [user]`).GetTypeDefinitions(),
+	report, err := recording.Replay(ctx, client, bytes.NewReader(recordingFile))
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Requests)
+	require.Empty(t, report.Mismatches)
+
+	// the new version of the model no longer grants viewer to editors
+	viewerOnlyModelID := writeModel(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user]`)
+	report, err = recording.Replay(ctx, client, bytes.NewReader(recordingFile), recording.WithAuthorizationModelID(viewerOnlyModelID))
+	require.NoError(t, err)
+	require.Len(t, report.Mismatches, 1)
+	require.Equal(t, "allowed=true", report.Mismatches[0].Recorded)
+	require.Equal(t, "allowed=false", report.Mismatches[0].Replayed)
+}
+
+func TestSLOEndpoint(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
 	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Metrics.Enabled = true
+	metricsPort, metricsPortReleaser := testutils.TCPRandomPort()
+	metricsPortReleaser()
+	cfg.Metrics.Addr = fmt.Sprintf("localhost:%d", metricsPort)
+	cfg.SLO.Enabled = true
+	cfg.SLO.Objectives = []string{"GetStore=latency:10s:99", "GetStore=availability:99.9"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
+
+	store, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "slo"})
+	require.NoError(t, err)
+	_, err = client.GetStore(ctx, &openfgav1.GetStoreRequest{StoreId: store.GetId()})
 	require.NoError(t, err)
+	_, err = client.GetStore(ctx, &openfgav1.GetStoreRequest{StoreId: ulid.Make().String()})
+	require.Error(t, err)
 
-	authorizationModelID := writeModelResp.GetAuthorizationModelId()
+	c := retryablehttp.NewClient()
+	t.Cleanup(c.HTTPClient.CloseIdleConnections)
 
-	httpClient := retryablehttp.NewClient()
-	t.Cleanup(httpClient.HTTPClient.CloseIdleConnections)
+	resp, err := c.Get(fmt.Sprintf("http://%s/slo", cfg.Metrics.Addr))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, resp.Body.Close())
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	testCases := map[string]struct {
-		httpVerb      string
-		httpPath      string
-		httpJSONBody  string
-		expectedError bool
-	}{
-		`check`: {
-			httpVerb:     "POST",
-			httpPath:     fmt.Sprintf("http://%s/stores/%s/check", cfg.HTTP.Addr, storeID),
-			httpJSONBody: `{"tuple_key": {"user": "user:anne",  "relation": "viewer", "object": "document:1"}}`,
-		},
-		`listobjects`: {
-			httpVerb:     "POST",
-			httpPath:     fmt.Sprintf("http://%s/stores/%s/list-objects", cfg.HTTP.Addr, storeID),
-			httpJSONBody: `{"type": "document", "user": "user:anne", "relation": "viewer"}`,
-		},
-		`streamed-list-objects`: {
-			httpVerb:     "POST",
-			httpPath:     fmt.Sprintf("http://%s/stores/%s/streamed-list-objects", cfg.HTTP.Addr, storeID),
-			httpJSONBody: `{"type": "document", "user": "user:anne", "relation": "viewer"}`,
-		},
-		`listusers`: {
-			httpVerb:     "POST",
-			httpPath:     fmt.Sprintf("http://%s/stores/%s/list-users", cfg.HTTP.Addr, storeID),
-			httpJSONBody: `{"object": { "type": "document", "id": "1" } , "relation": "viewer", "user_filters": [ {"type":"user"} ]}`,
-		},
-		`expand`: {
-			httpVerb:     "POST",
-			httpPath:     fmt.Sprintf("http://%s/stores/%s/expand", cfg.HTTP.Addr, storeID),
-			httpJSONBody: `{"tuple_key": {"user": "user:anne",  "relation": "viewer", "object": "document:1"}}`,
-		},
-		`write`: {
-			httpVerb:     "POST",
-			httpPath:     fmt.Sprintf("http://%s/stores/%s/write", cfg.HTTP.Addr, storeID),
-			httpJSONBody: `{"writes": { "tuple_keys": [{"user": "user:anne",  "relation": "viewer", "object": "document:1"}]}}`,
-		},
+	var body struct {
+		Objectives []slo.ObjectiveSummary `json:"objectives"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Objectives, 2)
+	for _, objective := range body.Objectives {
+		// the store that is not found is an error of the request, not of the server
+		require.Equal(t, uint64(2), objective.Requests)
+		require.Equal(t, uint64(0), objective.BadRequests)
+		require.InDelta(t, 1, objective.ErrorBudgetRemaining, 1e-9)
 	}
 
-	for name, test := range testCases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-			req, err := retryablehttp.NewRequest(test.httpVerb, test.httpPath, strings.NewReader(test.httpJSONBody))
-			require.NoError(t, err, "Failed to construct request")
+	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "openfga_slo_burn_rate")
+	require.NoError(t, err)
+	require.Positive(t, count)
+}
 
-			httpResponse, err := httpClient.Do(req)
-			require.NoError(t, err)
-			defer httpResponse.Body.Close()
+func TestServerContextExtensions(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
 
-			// These are set in the server RPCs
-			require.Len(t, httpResponse.Header[server.AuthorizationModelIDHeader], 1)
-			require.Equal(t, authorizationModelID, httpResponse.Header[server.AuthorizationModelIDHeader][0])
+	var unaryMethods, streamMethods, httpPaths []string
+	var mu sync.Mutex
+	record := func(calls *[]string, call string) {
+		mu.Lock()
+		defer mu.Unlock()
+		*calls = append(*calls, call)
+	}
+	var wrapped atomic.Bool
 
-			// These are set in middlewares
-			require.Len(t, httpResponse.Header[storeid.StoreIDHeader], 1)
-			require.Equal(t, storeID, httpResponse.Header[storeid.StoreIDHeader][0])
-			require.Len(t, httpResponse.Header[requestid.RequestIDHeader], 1)
-			require.NotEmpty(t, httpResponse.Header[requestid.RequestIDHeader][0])
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
 
-			httpResponse.Body.Close()
+	go func() {
+		err := runServer(ctx, cfg,
+			WithUnaryInterceptors(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				record(&unaryMethods, info.FullMethod)
+				return handler(ctx, req)
+			}),
+			WithStreamInterceptors(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				record(&streamMethods, info.FullMethod)
+				return handler(srv, stream)
+			}),
+			WithHTTPMiddlewares(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					record(&httpPaths, r.URL.Path)
+					next.ServeHTTP(w, r)
+				})
+			}),
+			WithDatastoreWrappers(func(ds storage.OpenFGADatastore) storage.OpenFGADatastore {
+				wrapped.Store(true)
+				return ds
+			}),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+	require.True(t, wrapped.Load())
+
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
+
+	_, err := client.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "extensions"})
+	require.NoError(t, err)
+
+	stream, err := client.StreamedListObjects(ctx, &openfgav1.StreamedListObjectsRequest{
+		StoreId:  ulid.Make().String(),
+		Type:     "document",
+		Relation: "viewer",
+		User:     "user:anne",
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Error(t, err)
+
+	c := retryablehttp.NewClient()
+	t.Cleanup(c.HTTPClient.CloseIdleConnections)
+	resp, err := c.Get(fmt.Sprintf("http://%s/stores", cfg.HTTP.Addr))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Contains(t, unaryMethods, openfgav1.OpenFGAService_CreateStore_FullMethodName)
+	require.Contains(t, unaryMethods, openfgav1.OpenFGAService_ListStores_FullMethodName)
+	require.Contains(t, streamMethods, openfgav1.OpenFGAService_StreamedListObjects_FullMethodName)
+	require.Contains(t, httpPaths, "/stores")
+}
+
+var registerTestDriversOnce sync.Once
+
+// registerTestDrivers registers the storage drivers of the tests once, since drivers cannot be
+// registered twice.
+func registerTestDrivers() {
+	registerTestDriversOnce.Do(func() {
+		openMemory := func(string, *sqlcommon.Config) (storage.OpenFGADatastore, error) {
+			return memory.New(), nil
+		}
+		drivers.Register("test-driver", drivers.Driver{
+			Open:                        openMemory,
+			ContinuationTokenSerializer: encoder.NewStringContinuationTokenSerializer(),
 		})
-	}
+		drivers.Register("test-driver-sql-tokens", drivers.Driver{Open: openMemory})
+		drivers.Register("test-driver-failing", drivers.Driver{
+			Open: func(uri string, _ *sqlcommon.Config) (storage.OpenFGADatastore, error) {
+				return nil, fmt.Errorf("cannot open '%s'", uri)
+			},
+		})
+	})
 }
 
 func TestServerContext_datastoreConfig(t *testing.T) {
+	registerTestDrivers()
+
 	tests := []struct {
 		name           string
 		config         *serverconfig.Config
@@ -1850,6 +3586,52 @@ func TestServerContext_datastoreConfig(t *testing.T) {
 			wantSerializer: nil,
 			wantErr:        errors.New("storage engine 'unsupported' is unsupported"),
 		},
+		{
+			name: "registered_driver",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "test-driver",
+				},
+			},
+			wantDSType:     &memory.MemoryBackend{},
+			wantSerializer: encoder.NewStringContinuationTokenSerializer(),
+			wantErr:        nil,
+		},
+		{
+			name: "registered_driver_without_serializer",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "test-driver-sql-tokens",
+				},
+			},
+			wantDSType:     &memory.MemoryBackend{},
+			wantSerializer: &sqlcommon.SQLContinuationTokenSerializer{},
+			wantErr:        nil,
+		},
+		{
+			name: "registered_driver_open_error",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "test-driver-failing",
+					URI:    "test://failing",
+				},
+			},
+			wantDSType:     nil,
+			wantSerializer: nil,
+			wantErr:        errors.New("initialize test-driver-failing datastore: cannot open 'test://failing'"),
+		},
+		{
+			name: "password_secret_not_found",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine:   "postgres",
+					Password: "file:///nonexistent/password",
+				},
+			},
+			wantDSType:     nil,
+			wantSerializer: nil,
+			wantErr:        errors.New("config 'datastore.password': failed to fetch the secret file:///nonexistent/password"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1870,3 +3652,154 @@ func TestServerContext_datastoreConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestServerContext_secrets(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "keys.json")
+	require.NoError(t, os.WriteFile(keyPath, []byte(`{"key": "first", "cert": "certificate"}`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s := &ServerContext{
+		Logger:         logger.NewNoopLogger(),
+		secretsWatcher: secrets.NewWatcher(secrets.NewResolver(), 10*time.Millisecond, logger.NewNoopLogger()),
+	}
+	go s.secretsWatcher.Run(ctx)
+
+	t.Run("preshared_keys", func(t *testing.T) {
+		config := serverconfig.DefaultConfig()
+		config.Authn.Method = "preshared"
+		config.Authn.Keys = []string{"plain", "file://" + keyPath + "#key"}
+		authenticator, err := s.presharedKeyAuthenticatorConfig(config)
+		require.NoError(t, err)
+
+		authenticate := func(key string) error {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+key))
+			_, err := authenticator.Authenticate(ctx)
+			return err
+		}
+		require.NoError(t, authenticate("plain"))
+		require.NoError(t, authenticate("first"))
+
+		require.NoError(t, os.WriteFile(keyPath, []byte(`{"key": "second", "cert": "certificate"}`), 0o600))
+		require.Eventually(t, func() bool {
+			return authenticate("second") == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		require.ErrorIs(t, authenticate("first"), authn.ErrUnauthenticated)
+		require.NoError(t, authenticate("plain"))
+	})
+
+	t.Run("secret_files", func(t *testing.T) {
+		path, err := s.secretFile(ctx, "/etc/openfga/tls.crt", "tls.crt")
+		require.NoError(t, err)
+		require.Equal(t, "/etc/openfga/tls.crt", path)
+
+		path, err = s.secretFile(ctx, "file://"+keyPath, "tls.crt")
+		require.NoError(t, err)
+		require.Equal(t, keyPath, path)
+
+		path, err = s.secretFile(ctx, "file://"+keyPath+"#cert", "tls.crt")
+		require.NoError(t, err)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "certificate", string(content))
+
+		require.NoError(t, os.WriteFile(keyPath, []byte(`{"key": "second", "cert": "rotated"}`), 0o600))
+		require.Eventually(t, func() bool {
+			content, err := os.ReadFile(path)
+			return err == nil && string(content) == "rotated"
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+// countingCache is a check cache that counts the entries set in it.
+type countingCache struct {
+	storage.InMemoryCache[any]
+	sets atomic.Int64
+}
+
+func (c *countingCache) Set(key string, value any, ttl time.Duration) {
+	c.sets.Add(1)
+	c.InMemoryCache.Set(key, value, ttl)
+}
+
+var (
+	registerTestCachesOnce sync.Once
+	testCountingCache      atomic.Pointer[countingCache]
+)
+
+// registerTestCaches registers the check caches of the tests once, since caches cannot be
+// registered twice.
+func registerTestCaches() {
+	registerTestCachesOnce.Do(func() {
+		caches.Register("test-counting", caches.Cache{
+			New: func(cfg *serverconfig.CheckCacheConfig) (storage.InMemoryCache[any], error) {
+				lru, err := storage.NewInMemoryLRUCache(storage.WithMaxCacheSize[any](int64(cfg.Limit)))
+				if err != nil {
+					return nil, err
+				}
+				cache := &countingCache{InMemoryCache: lru}
+				testCountingCache.Store(cache)
+				return cache, nil
+			},
+		})
+		caches.Register("test-failing", caches.Cache{
+			New: func(*serverconfig.CheckCacheConfig) (storage.InMemoryCache[any], error) {
+				return nil, errors.New("cannot connect to the cache")
+			},
+		})
+	})
+}
+
+func TestServerContext_checkCacheConfig(t *testing.T) {
+	registerTestCaches()
+
+	tests := []struct {
+		name      string
+		engine    string
+		wantCache bool
+		wantErr   string
+	}{
+		{
+			name:   "memory",
+			engine: "memory",
+		},
+		{
+			name:      "registered_cache",
+			engine:    "test-counting",
+			wantCache: true,
+		},
+		{
+			name:    "unknown_cache",
+			engine:  "test-unknown",
+			wantErr: "unsupported check cache engine 'test-unknown'",
+		},
+		{
+			name:    "registered_cache_new_error",
+			engine:  "test-failing",
+			wantErr: "failed to initialize check cache: cannot connect to the cache",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &ServerContext{
+				Logger: logger.NewNoopLogger(),
+			}
+			cfg := serverconfig.DefaultConfig()
+			cfg.CheckCache.Engine = tt.engine
+
+			checkCache, err := s.checkCacheConfig(cfg)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantCache {
+				require.NotNil(t, checkCache)
+				checkCache.Stop()
+			} else {
+				require.Nil(t, checkCache)
+			}
+		})
+	}
+}
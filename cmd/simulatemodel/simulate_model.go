@@ -0,0 +1,218 @@
+// Package simulatemodel contains the command that simulates a change of the authorization model
+// of a store with recorded or supplied Check requests.
+package simulatemodel
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/recording"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/server/modelsimulation"
+)
+
+const (
+	modelFlag                = "model"
+	recordingFlag            = "recording"
+	checksFlag               = "checks"
+	grpcAddrFlag             = "grpc-addr"
+	grpcTLSFlag              = "grpc-tls"
+	apiTokenFlag             = "api-token"
+	storeIDFlag              = "store-id"
+	authorizationModelIDFlag = "authorization-model-id"
+	batchSizeFlag            = "batch-size"
+)
+
+// maxLineSize is the maximum size of a line of a recording or of a file of checks.
+const maxLineSize = 16 * 1024 * 1024
+
+// NewSimulateModelCommand returns the command that simulates a model change on a server started
+// with the `--model-simulation-enabled` flag of `openfga run`.
+func NewSimulateModelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate-model",
+		Short: "Report the Check requests whose outcome would change with a proposed authorization model. NOTE: this command is in beta and may be removed in future releases.",
+		Long: "Evaluate Check requests with both the current authorization model of a store and the proposed model of --model, which is not written,\n" +
+			"on a server started with --model-simulation-enabled, and report every check whose outcome would change, to de-risk the rollout of the model.\n" +
+			"The checks are the Check requests of a recording made with --request-recording-enabled (--recording),\n" +
+			"or the Check requests of a file with one Check request per line in the JSON of the Check API (--checks).\n" +
+			"The report is written to stdout as JSON, and the command fails if any outcome would change.\n" +
+			"NOTE: this command is in beta and may be removed in future releases.",
+		Args: cobra.NoArgs,
+		RunE: runSimulateModel,
+	}
+
+	flags := cmd.Flags()
+	flags.String(modelFlag, "", "the file of the proposed authorization model, in the DSL or in JSON ('-' for stdin)")
+	flags.String(recordingFlag, "", "path to a recording whose Check requests are simulated")
+	flags.String(checksFlag, "", "path to a file of Check requests to simulate, one per line in the JSON of the Check API")
+	flags.String(grpcAddrFlag, "localhost:8081", "the address of the gRPC API of the server")
+	flags.Bool(grpcTLSFlag, false, "connect to the server using TLS")
+	flags.String(apiTokenFlag, "", "the preshared key or OIDC token to authenticate to the server with")
+	flags.String(storeIDFlag, "", "the store whose tuples the checks are evaluated with")
+	flags.String(authorizationModelIDFlag, "", "the current authorization model, instead of the latest one")
+	flags.Int(batchSizeFlag, serverconfig.DefaultMaxChecksPerBatchCheck, "the number of checks simulated per request, at most the maximum number of checks per BatchCheck of the server")
+	_ = cmd.MarkFlagRequired(modelFlag)
+	_ = cmd.MarkFlagRequired(storeIDFlag)
+	cmd.MarkFlagsOneRequired(recordingFlag, checksFlag)
+	cmd.MarkFlagsMutuallyExclusive(recordingFlag, checksFlag)
+
+	return cmd
+}
+
+// change is a check whose outcome would change, with the line of its file.
+type change struct {
+	Line     int                     `json:"line"`
+	Request  json.RawMessage         `json:"request"`
+	Current  modelsimulation.Outcome `json:"current"`
+	Proposed modelsimulation.Outcome `json:"proposed"`
+}
+
+// report is the report of the command.
+type report struct {
+	AuthorizationModelID string    `json:"authorization_model_id"`
+	Checks               int       `json:"checks"`
+	Changes              []*change `json:"changes"`
+}
+
+// check is a Check request of a file, with its line.
+type check struct {
+	line    int
+	request json.RawMessage
+}
+
+func runSimulateModel(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	modelFile, _ := flags.GetString(modelFlag)
+	recordingPath, _ := flags.GetString(recordingFlag)
+	checksPath, _ := flags.GetString(checksFlag)
+	grpcAddr, _ := flags.GetString(grpcAddrFlag)
+	grpcTLS, _ := flags.GetBool(grpcTLSFlag)
+	apiToken, _ := flags.GetString(apiTokenFlag)
+	storeID, _ := flags.GetString(storeIDFlag)
+	modelID, _ := flags.GetString(authorizationModelIDFlag)
+	batchSize, _ := flags.GetInt(batchSizeFlag)
+
+	if batchSize < 1 {
+		return fmt.Errorf("--%s must be greater than zero", batchSizeFlag)
+	}
+
+	model, err := util.ReadModelFile(cmd, modelFile)
+	if err != nil {
+		return err
+	}
+	proposedModel, err := protojson.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	var checks []check
+	if recordingPath != "" {
+		checks, err = readChecks(recordingPath, true)
+	} else {
+		checks, err = readChecks(checksPath, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	conn, err := util.DialServer(grpcAddr, grpcTLS, apiToken)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rep, err := simulate(cmd, conn, &modelsimulation.SimulateRequest{
+		StoreID:              storeID,
+		AuthorizationModelID: modelID,
+		ProposedModel:        proposedModel,
+	}, checks, batchSize)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(rep); err != nil {
+		return err
+	}
+
+	if len(rep.Changes) > 0 {
+		return fmt.Errorf("%d of %d checks would change outcome with the proposed model", len(rep.Changes), rep.Checks)
+	}
+	return nil
+}
+
+// simulate simulates checks in batches of batchSize with the store and the models of req.
+func simulate(cmd *cobra.Command, conn grpc.ClientConnInterface, req *modelsimulation.SimulateRequest, checks []check, batchSize int) (*report, error) {
+	rep := &report{Changes: []*change{}}
+	for start := 0; start < len(checks); start += batchSize {
+		batch := checks[start:min(start+batchSize, len(checks))]
+		req.Checks = make([]json.RawMessage, 0, len(batch))
+		for _, c := range batch {
+			req.Checks = append(req.Checks, c.request)
+		}
+
+		res, err := modelsimulation.Simulate(cmd.Context(), conn, req)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", batch[0].line, err)
+		}
+		// the batches are evaluated with the same model, even if a model is written meanwhile
+		req.AuthorizationModelID = res.AuthorizationModelID
+		rep.AuthorizationModelID = res.AuthorizationModelID
+		rep.Checks += res.Checks
+		for _, c := range res.Changes {
+			rep.Changes = append(rep.Changes, &change{
+				Line:     batch[c.Index].line,
+				Request:  c.Request,
+				Current:  c.Current,
+				Proposed: c.Proposed,
+			})
+		}
+	}
+	return rep, nil
+}
+
+// readChecks reads the Check requests of the file at path, which is a recording if recorded.
+func readChecks(path string, recorded bool) ([]check, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the checks: %w", err)
+	}
+	defer f.Close()
+	return scanChecks(f, recorded)
+}
+
+func scanChecks(r io.Reader, recorded bool) ([]check, error) {
+	var checks []check
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		request := json.RawMessage(append([]byte(nil), scanner.Bytes()...))
+		if recorded {
+			record := &recording.Record{}
+			if err := json.Unmarshal(request, record); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			// the recordings also hold the ListObjects requests
+			if record.Method != recording.MethodCheck {
+				continue
+			}
+			request = record.Request
+		}
+		checks = append(checks, check{line: line, request: request})
+	}
+	return checks, scanner.Err()
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/modelvalidation"
+)
+
+// ReadModelFile reads and validates the authorization model of file, in JSON if it is an object
+// and in the DSL otherwise. The model is read from the input of cmd if file is '-'.
+func ReadModelFile(cmd *cobra.Command, file string) (*openfgav1.AuthorizationModel, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the authorization model: %w", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return modelvalidation.ValidateJSON(cmd.Context(), data)
+	}
+	return modelvalidation.ValidateDSL(cmd.Context(), string(data))
+}
@@ -0,0 +1,205 @@
+// Package audit contains code that emits the audit and access events of the API requests as
+// OpenTelemetry log records, with the trace and the span of the requests and the attributes of
+// the resource of the server, so that they land in the same observability backend as the traces
+// and the metrics.
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+const (
+	// AuditEventName is the name of the events of the requests that change the state of the
+	// server, e.g. Write or CreateStore.
+	AuditEventName = "openfga.audit"
+
+	// AccessEventName is the name of the events of the requests that read the state of the
+	// server, e.g. Check or Read.
+	AccessEventName = "openfga.access"
+
+	DefaultQueueSize     = 10000
+	DefaultFlushInterval = 5 * time.Second
+
+	// maxBatchSize is the maximum number of events exported at once.
+	maxBatchSize = 512
+)
+
+var droppedEventsCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "audit_events_dropped_count",
+	Help:      "The total number of audit and access events dropped because the queue of the events to export was full.",
+})
+
+// Event is an audit or an access event.
+type Event struct {
+	Name string
+	Time time.Time
+
+	// Failed is whether the request failed, so that the event is exported with the warning severity.
+	Failed bool
+
+	// SpanContext is the span of the request, if it is traced.
+	SpanContext trace.SpanContext
+	Attributes  []attribute.KeyValue
+}
+
+// Exporter exports the events, e.g. to an OTLP collector.
+type Exporter interface {
+	Export(ctx context.Context, events []*Event) error
+}
+
+// ExporterFunc is an Exporter that calls the function.
+type ExporterFunc func(ctx context.Context, events []*Event) error
+
+// Export see [Exporter].Export.
+func (f ExporterFunc) Export(ctx context.Context, events []*Event) error {
+	return f(ctx, events)
+}
+
+type EmitterOption func(e *Emitter)
+
+// WithQueueSize sets the number of events that can be queued to be exported, after which the
+// events are dropped.
+func WithQueueSize(size int) EmitterOption {
+	return func(e *Emitter) {
+		e.queueSize = size
+	}
+}
+
+// WithFlushInterval sets the maximum interval between the exports of the queued events.
+func WithFlushInterval(interval time.Duration) EmitterOption {
+	return func(e *Emitter) {
+		e.flushInterval = interval
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the exports, whose events are dropped.
+func WithErrorHandler(handler func(error)) EmitterOption {
+	return func(e *Emitter) {
+		e.errorHandler = handler
+	}
+}
+
+// Emitter queues the events and exports them in batches in the background, so that the requests
+// don't wait for the exports. The events are dropped when the queue is full.
+type Emitter struct {
+	exporter      Exporter
+	queueSize     int
+	flushInterval time.Duration
+	errorHandler  func(error)
+
+	events    chan *Event
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewEmitter returns an Emitter that exports the events with exporter until it is closed.
+func NewEmitter(exporter Exporter, opts ...EmitterOption) *Emitter {
+	e := &Emitter{
+		exporter:      exporter,
+		queueSize:     DefaultQueueSize,
+		flushInterval: DefaultFlushInterval,
+		errorHandler:  func(error) {},
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.events = make(chan *Event, e.queueSize)
+
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// Emit queues an event to be exported, or drops it if the queue is full or the Emitter is closed.
+func (e *Emitter) Emit(event *Event) {
+	select {
+	case <-e.done:
+		droppedEventsCounter.Inc()
+		return
+	default:
+	}
+
+	select {
+	case e.events <- event:
+	default:
+		droppedEventsCounter.Inc()
+	}
+}
+
+// Close exports the queued events and stops the Emitter. It returns when the events are exported
+// or ctx is done.
+func (e *Emitter) Close(ctx context.Context) error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Emitter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Event, 0, maxBatchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.flushInterval)
+		defer cancel()
+		if err := e.exporter.Export(ctx, batch); err != nil {
+			e.errorHandler(err)
+		}
+		batch = make([]*Event, 0, maxBatchSize)
+	}
+
+	for {
+		select {
+		case event := <-e.events:
+			batch = append(batch, event)
+			if len(batch) == maxBatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case <-e.done:
+			for {
+				select {
+				case event := <-e.events:
+					batch = append(batch, event)
+					if len(batch) == maxBatchSize {
+						export()
+					}
+				default:
+					export()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/redaction"
+	"github.com/openfga/openfga/pkg/authclaims"
+)
+
+type recordingExporter struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (e *recordingExporter) Export(_ context.Context, events []*Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, events...)
+	return nil
+}
+
+func (e *recordingExporter) Events() []*Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.events
+}
+
+func attributes(event *Event) map[string]attribute.Value {
+	attrs := map[string]attribute.Value{}
+	for _, attr := range event.Attributes {
+		attrs[string(attr.Key)] = attr.Value
+	}
+	return attrs
+}
+
+func TestEmitter(t *testing.T) {
+	t.Run("exports_the_events_on_flush_interval", func(t *testing.T) {
+		exporter := &recordingExporter{}
+		emitter := NewEmitter(exporter, WithFlushInterval(10*time.Millisecond))
+		t.Cleanup(func() { _ = emitter.Close(context.Background()) })
+
+		emitter.Emit(&Event{Name: AccessEventName})
+		require.Eventually(t, func() bool { return len(exporter.Events()) == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("exports_the_queued_events_on_close", func(t *testing.T) {
+		exporter := &recordingExporter{}
+		emitter := NewEmitter(exporter, WithFlushInterval(time.Hour))
+		for range 1000 {
+			emitter.Emit(&Event{Name: AuditEventName})
+		}
+
+		require.NoError(t, emitter.Close(context.Background()))
+		require.Len(t, exporter.Events(), 1000)
+
+		emitter.Emit(&Event{Name: AuditEventName})
+		require.Len(t, exporter.Events(), 1000)
+	})
+
+	t.Run("drops_the_events_when_the_queue_is_full", func(t *testing.T) {
+		exported := make(chan struct{})
+		exporter := ExporterFunc(func(ctx context.Context, _ []*Event) error {
+			<-exported
+			return nil
+		})
+		emitter := NewEmitter(exporter, WithQueueSize(1), WithFlushInterval(10*time.Millisecond))
+
+		for range 10 {
+			emitter.Emit(&Event{Name: AuditEventName})
+		}
+		close(exported)
+		require.NoError(t, emitter.Close(context.Background()))
+	})
+
+	t.Run("reports_the_export_errors", func(t *testing.T) {
+		errs := make(chan error, 1)
+		exporter := ExporterFunc(func(context.Context, []*Event) error {
+			return errors.New("unavailable")
+		})
+		emitter := NewEmitter(exporter, WithErrorHandler(func(err error) { errs <- err }))
+
+		emitter.Emit(&Event{Name: AuditEventName})
+		require.NoError(t, emitter.Close(context.Background()))
+		require.EqualError(t, <-errs, "unavailable")
+	})
+}
+
+type logsServer struct {
+	collectorlogs.UnimplementedLogsServiceServer
+	requests chan *collectorlogs.ExportLogsServiceRequest
+}
+
+func (s *logsServer) Export(_ context.Context, req *collectorlogs.ExportLogsServiceRequest) (*collectorlogs.ExportLogsServiceResponse, error) {
+	s.requests <- req
+	return &collectorlogs.ExportLogsServiceResponse{}, nil
+}
+
+func TestOTLPExporter(t *testing.T) {
+	server := &logsServer{requests: make(chan *collectorlogs.ExportLogsServiceRequest, 1)}
+	grpcServer := grpc.NewServer()
+	collectorlogs.RegisterLogsServiceServer(grpcServer, server)
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	exporter := NewOTLPExporter(conn, []attribute.KeyValue{attribute.String("service.name", "openfga")})
+	err = exporter.Export(context.Background(), []*Event{{
+		Name:        AuditEventName,
+		Time:        time.Unix(10, 0),
+		Failed:      true,
+		SpanContext: spanContext,
+		Attributes:  []attribute.KeyValue{attribute.String(rpcMethodKey, "Write"), attribute.Int(rpcStatusCodeKey, 3)},
+	}})
+	require.NoError(t, err)
+
+	req := <-server.requests
+	require.Len(t, req.GetResourceLogs(), 1)
+	resourceLogs := req.GetResourceLogs()[0]
+	require.Equal(t, "service.name", resourceLogs.GetResource().GetAttributes()[0].GetKey())
+	require.Equal(t, "openfga", resourceLogs.GetResource().GetAttributes()[0].GetValue().GetStringValue())
+
+	record := resourceLogs.GetScopeLogs()[0].GetLogRecords()[0]
+	require.Equal(t, AuditEventName, record.GetEventName())
+	require.Equal(t, uint64(10*time.Second), record.GetTimeUnixNano())
+	require.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, record.GetSeverityNumber())
+	require.Equal(t, spanContext.TraceID().String(), trace.TraceID(record.GetTraceId()).String())
+	require.Equal(t, spanContext.SpanID().String(), trace.SpanID(record.GetSpanId()).String())
+	require.Equal(t, "Write", record.GetAttributes()[0].GetValue().GetStringValue())
+	require.Equal(t, int64(3), record.GetAttributes()[1].GetValue().GetIntValue())
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	exporter := &recordingExporter{}
+	emitter := NewEmitter(exporter)
+	redactor, err := redaction.NewRedactor(redaction.ModeRedact, nil, 0)
+	require.NoError(t, err)
+	interceptor := NewUnaryInterceptor(emitter, redactor)
+
+	ctx := authclaims.ContextWithAuthClaims(context.Background(), &authclaims.AuthClaims{Subject: "anne", ClientID: "app"})
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+	ctx = trace.ContextWithSpanContext(ctx, spanContext)
+
+	req := &openfgav1.CheckRequest{
+		StoreId:              "01H0",
+		AuthorizationModelId: "01H1",
+		TupleKey:             &openfgav1.CheckRequestTupleKey{Object: "document:1", Relation: "viewer", User: "user:anne"},
+	}
+	_, err = interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"},
+		func(context.Context, any) (any, error) {
+			return &openfgav1.CheckResponse{Allowed: true}, nil
+		})
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, &openfgav1.WriteRequest{StoreId: "01H0"}, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Write"},
+		func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.InvalidArgument, "invalid")
+		})
+	require.Error(t, err)
+
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, emitter.Close(context.Background()))
+	events := exporter.Events()
+	require.Len(t, events, 2)
+
+	require.Equal(t, AccessEventName, events[0].Name)
+	require.False(t, events[0].Failed)
+	require.Equal(t, spanContext, events[0].SpanContext)
+	attrs := attributes(events[0])
+	require.Equal(t, "Check", attrs[rpcMethodKey].AsString())
+	require.Equal(t, "anne", attrs[subjectKey].AsString())
+	require.Equal(t, "app", attrs[clientIDKey].AsString())
+	require.Equal(t, "01H0", attrs[storeIDKey].AsString())
+	require.Equal(t, "01H1", attrs[modelIDKey].AsString())
+	require.True(t, attrs[allowedKey].AsBool())
+	require.JSONEq(t, `{"store_id":"01H0","authorization_model_id":"01H1","tuple_key":{"object":"document:redacted","relation":"viewer","user":"user:redacted"}}`, attrs[requestKey].AsString())
+
+	require.Equal(t, AuditEventName, events[1].Name)
+	require.True(t, events[1].Failed)
+	require.Equal(t, int64(codes.InvalidArgument), attributes(events[1])[rpcStatusCodeKey].AsInt64())
+}
+
+type serverTransportStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s *serverTransportStream) Method() string {
+	return s.method
+}
+
+func TestAuthFunc(t *testing.T) {
+	exporter := &recordingExporter{}
+	emitter := NewEmitter(exporter)
+
+	authFunc := NewAuthFunc(func(ctx context.Context) (context.Context, error) {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}, emitter)
+	var _ grpcauth.AuthFunc = authFunc
+
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), &serverTransportStream{method: "/openfga.v1.OpenFGAService/Check"})
+	_, err := authFunc(ctx)
+	require.Error(t, err)
+
+	require.NoError(t, emitter.Close(context.Background()))
+	events := exporter.Events()
+	require.Len(t, events, 1)
+	require.Equal(t, AuditEventName, events[0].Name)
+	require.True(t, events[0].Failed)
+	require.Equal(t, int64(codes.Unauthenticated), attributes(events[0])[rpcStatusCodeKey].AsInt64())
+}
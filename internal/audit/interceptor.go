@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/internal/redaction"
+	"github.com/openfga/openfga/pkg/authclaims"
+)
+
+const (
+	rpcServiceKey      = "rpc.service"
+	rpcMethodKey       = "rpc.method"
+	rpcStatusCodeKey   = "rpc.grpc.status_code"
+	userAgentKey       = "user_agent.original"
+	subjectKey         = "enduser.id"
+	clientIDKey        = "openfga.client_id"
+	storeIDKey         = "openfga.store_id"
+	modelIDKey         = "openfga.authorization_model_id"
+	durationKey        = "openfga.duration_ms"
+	requestKey         = "openfga.request"
+	allowedKey         = "openfga.allowed"
+	gatewayUserAgentMD = "grpcgateway-user-agent"
+	userAgentMD        = "user-agent"
+)
+
+// excludedServices are the services whose requests are not audited: the health checks and the
+// requests between the replicas.
+var excludedServices = []string{"grpc.health.v1.Health", "grpc.reflection.", "openfga.cluster.v1."}
+
+// auditedMethodPrefixes are the prefixes of the methods that change the state of the server, whose
+// events are audit events. The events of the other methods are access events.
+var auditedMethodPrefixes = []string{
+	"Write", "Create", "Delete", "Update", "Import", "Start", "Cancel", "Restore", "Pause", "Resume",
+}
+
+// NewUnaryInterceptor returns an interceptor that emits an event for each request, after it is
+// authenticated. The IDs of the users and the objects in the requests are redacted by redactor.
+func NewUnaryInterceptor(emitter *Emitter, redactor *redaction.Redactor) grpc.UnaryServerInterceptor {
+	return interceptors.UnaryServerInterceptor(reportable(emitter, redactor))
+}
+
+// NewStreamingInterceptor returns an interceptor that emits an event for each streaming request,
+// after it is authenticated.
+func NewStreamingInterceptor(emitter *Emitter, redactor *redaction.Redactor) grpc.StreamServerInterceptor {
+	return interceptors.StreamServerInterceptor(reportable(emitter, redactor))
+}
+
+// NewAuthFunc returns an AuthFunc that emits an audit event for each request that authFunc fails
+// to authenticate, since they don't reach the interceptors.
+func NewAuthFunc(authFunc grpcauth.AuthFunc, emitter *Emitter) grpcauth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		authCtx, err := authFunc(ctx)
+		if err != nil {
+			if fullMethod, ok := grpc.Method(ctx); ok {
+				service, method := splitFullMethod(fullMethod)
+				if !excluded(service) {
+					r := newReporter(ctx, emitter, nil, service, method)
+					r.eventName = AuditEventName
+					r.PostCall(err, 0)
+				}
+			}
+		}
+		return authCtx, err
+	}
+}
+
+func splitFullMethod(fullMethod string) (string, string) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service, method
+}
+
+func excluded(service string) bool {
+	for _, prefix := range excludedServices {
+		if strings.HasPrefix(service, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func eventName(method string) string {
+	for _, prefix := range auditedMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return AuditEventName
+		}
+	}
+	return AccessEventName
+}
+
+type reporter struct {
+	ctx       context.Context
+	emitter   *Emitter
+	redactor  *redaction.Redactor
+	start     time.Time
+	eventName string
+	attrs     []attribute.KeyValue
+
+	request proto.Message
+	allowed *bool
+}
+
+func newReporter(ctx context.Context, emitter *Emitter, redactor *redaction.Redactor, service, method string) *reporter {
+	attrs := []attribute.KeyValue{
+		attribute.String(rpcServiceKey, service),
+		attribute.String(rpcMethodKey, method),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgent := append(md.Get(gatewayUserAgentMD), md.Get(userAgentMD)...); len(userAgent) > 0 {
+			attrs = append(attrs, attribute.String(userAgentKey, userAgent[0]))
+		}
+	}
+	return &reporter{
+		ctx:       ctx,
+		emitter:   emitter,
+		redactor:  redactor,
+		start:     time.Now(),
+		eventName: eventName(method),
+		attrs:     attrs,
+	}
+}
+
+func reportable(emitter *Emitter, redactor *redaction.Redactor) interceptors.CommonReportableFunc {
+	return func(ctx context.Context, c interceptors.CallMeta) (interceptors.Reporter, context.Context) {
+		if excluded(c.Service) {
+			return interceptors.NoopReporter{}, ctx
+		}
+		return newReporter(ctx, emitter, redactor, c.Service, c.Method), ctx
+	}
+}
+
+// PostMsgReceive keeps the first request, i.e. the only one of the server streams.
+func (r *reporter) PostMsgReceive(msg interface{}, _ error, _ time.Duration) {
+	if request, ok := msg.(proto.Message); ok && r.request == nil {
+		r.request = request
+	}
+}
+
+// PostMsgSend keeps whether the request is allowed, e.g. of Check.
+func (r *reporter) PostMsgSend(msg interface{}, err error, _ time.Duration) {
+	if response, ok := msg.(interface{ GetAllowed() bool }); ok && err == nil {
+		allowed := response.GetAllowed()
+		r.allowed = &allowed
+	}
+}
+
+// PostCall emits the event of the request.
+func (r *reporter) PostCall(err error, duration time.Duration) {
+	code := status.Code(err)
+	attrs := append(r.attrs,
+		attribute.Int(rpcStatusCodeKey, int(code)),
+		attribute.Int64(durationKey, duration.Milliseconds()))
+
+	if claims, ok := authclaims.AuthClaimsFromContext(r.ctx); ok && claims != nil {
+		if claims.Subject != "" {
+			attrs = append(attrs, attribute.String(subjectKey, claims.Subject))
+		}
+		if claims.ClientID != "" {
+			attrs = append(attrs, attribute.String(clientIDKey, claims.ClientID))
+		}
+	}
+
+	if request, ok := r.request.(interface{ GetStoreId() string }); ok && request.GetStoreId() != "" {
+		attrs = append(attrs, attribute.String(storeIDKey, request.GetStoreId()))
+	}
+	if request, ok := r.request.(interface{ GetAuthorizationModelId() string }); ok && request.GetAuthorizationModelId() != "" {
+		attrs = append(attrs, attribute.String(modelIDKey, request.GetAuthorizationModelId()))
+	}
+	if r.request != nil {
+		if request, err := protojson.Marshal(r.request); err == nil {
+			attrs = append(attrs, attribute.String(requestKey, string(r.redactor.JSON(request))))
+		}
+	}
+	if r.allowed != nil {
+		attrs = append(attrs, attribute.Bool(allowedKey, *r.allowed))
+	}
+
+	r.emitter.Emit(&Event{
+		Name:        r.eventName,
+		Time:        r.start,
+		Failed:      code != codes.OK,
+		SpanContext: trace.SpanContextFromContext(r.ctx),
+		Attributes:  attrs,
+	})
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+// scopeName is the instrumentation scope of the log records of the events.
+const scopeName = "github.com/openfga/openfga/internal/audit"
+
+type otlpExporter struct {
+	client   collectorlogs.LogsServiceClient
+	resource *resourcepb.Resource
+}
+
+// NewOTLPExporter returns an Exporter that exports the events as OTLP log records to the logs
+// service of conn, e.g. an OpenTelemetry collector, with the attributes of the resource of the
+// server, e.g. service.name.
+func NewOTLPExporter(conn grpc.ClientConnInterface, resource []attribute.KeyValue) Exporter {
+	return &otlpExporter{
+		client:   collectorlogs.NewLogsServiceClient(conn),
+		resource: &resourcepb.Resource{Attributes: keyValues(resource)},
+	}
+}
+
+// Export see [Exporter].Export.
+func (e *otlpExporter) Export(ctx context.Context, events []*Event) error {
+	records := make([]*logspb.LogRecord, 0, len(events))
+	for _, event := range events {
+		records = append(records, logRecord(event))
+	}
+
+	res, err := e.client.Export(ctx, &collectorlogs.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: e.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: scopeName, Version: build.Version},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export %d audit events: %w", len(events), err)
+	}
+	if rejected := res.GetPartialSuccess().GetRejectedLogRecords(); rejected > 0 {
+		return fmt.Errorf("the collector rejected %d audit events: %s", rejected, res.GetPartialSuccess().GetErrorMessage())
+	}
+	return nil
+}
+
+func logRecord(event *Event) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(event.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(event.Time.UnixNano()),
+		SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+		SeverityText:         "INFO",
+		EventName:            event.Name,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: event.Name}},
+		Attributes:           keyValues(event.Attributes),
+	}
+	if event.Failed {
+		record.SeverityNumber = logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+		record.SeverityText = "WARN"
+	}
+	if event.SpanContext.IsValid() {
+		traceID, spanID := event.SpanContext.TraceID(), event.SpanContext.SpanID()
+		record.TraceId = traceID[:]
+		record.SpanId = spanID[:]
+		record.Flags = uint32(event.SpanContext.TraceFlags())
+	}
+	return record
+}
+
+func keyValues(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+	keyValues := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		keyValues = append(keyValues, &commonpb.KeyValue{Key: string(attr.Key), Value: anyValue(attr.Value)})
+	}
+	return keyValues
+}
+
+func anyValue(value attribute.Value) *commonpb.AnyValue {
+	switch value.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: value.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: value.AsFloat64()}}
+	case attribute.STRINGSLICE:
+		values := make([]*commonpb.AnyValue, 0, len(value.AsStringSlice()))
+		for _, s := range value.AsStringSlice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}})
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value.Emit()}}
+	}
+}
@@ -151,6 +151,7 @@ func (oidc *RemoteOidcAuthenticator) Authenticate(requestContext context.Context
 		Subject:  subject,
 		Scopes:   make(map[string]bool),
 		ClientID: clientID,
+		Claims:   claims,
 	}
 
 	// optional scopes
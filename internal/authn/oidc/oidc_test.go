@@ -451,6 +451,8 @@ func TestRemoteOidcAuthenticator_Authenticate(t *testing.T) {
 				require.Equal(t, customClientID, authClaims.ClientID)
 			}
 
+			require.Equal(t, config.jwtClaims["sub"], authClaims.Claims["sub"])
+
 			scopesList := strings.Split(scopes, " ")
 			require.Len(t, authClaims.Scopes, len(scopesList))
 			for _, scope := range scopesList {
@@ -3,6 +3,7 @@ package presharedkey
 import (
 	"context"
 	"errors"
+	"sync"
 
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 
@@ -11,21 +12,35 @@ import (
 )
 
 type PresharedKeyAuthenticator struct {
-	ValidKeys map[string]struct{}
+	mu        sync.RWMutex
+	ValidKeys map[string]struct{} // GUARDED_BY(mu)
 }
 
 var _ authn.Authenticator = (*PresharedKeyAuthenticator)(nil)
 
 func NewPresharedKeyAuthenticator(validKeys []string) (*PresharedKeyAuthenticator, error) {
+	pka := &PresharedKeyAuthenticator{}
+	if err := pka.SetKeys(validKeys); err != nil {
+		return nil, err
+	}
+
+	return pka, nil
+}
+
+// SetKeys replaces the valid keys, e.g. when the secrets of the keys are rotated.
+func (pka *PresharedKeyAuthenticator) SetKeys(validKeys []string) error {
 	if len(validKeys) < 1 {
-		return nil, errors.New("invalid auth configuration, please specify at least one key")
+		return errors.New("invalid auth configuration, please specify at least one key")
 	}
 	vKeys := make(map[string]struct{})
 	for _, k := range validKeys {
 		vKeys[k] = struct{}{}
 	}
 
-	return &PresharedKeyAuthenticator{ValidKeys: vKeys}, nil
+	pka.mu.Lock()
+	defer pka.mu.Unlock()
+	pka.ValidKeys = vKeys
+	return nil
 }
 
 func (pka *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (*authclaims.AuthClaims, error) {
@@ -34,7 +49,10 @@ func (pka *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (*authcl
 		return nil, authn.ErrMissingBearerToken
 	}
 
-	if _, found := pka.ValidKeys[authHeader]; found {
+	pka.mu.RLock()
+	_, found := pka.ValidKeys[authHeader]
+	pka.mu.RUnlock()
+	if found {
 		return &authclaims.AuthClaims{
 			Subject: "", // no user information in this auth method
 		}, nil
@@ -69,6 +69,24 @@ type CacheController interface {
 	InvalidateIfNeeded(context.Context, string)
 }
 
+// ChangeObserver is implemented by the cache controllers that record the most recent change of the
+// stores they read from the changelog.
+type ChangeObserver interface {
+	// LastObservedChangeID returns the ULID of the most recent change of the store the cache
+	// controller has read from the changelog and invalidated the caches for, or the empty string.
+	// Like DetermineInvalidationTime, it triggers InvalidateIfNeeded() if the changelog was not
+	// read recently.
+	LastObservedChangeID(context.Context, string) string
+}
+
+// ChangeInvalidator is implemented by the cache controllers that can invalidate the caches with the
+// changes pushed by a storage.ChangeListener.
+type ChangeInvalidator interface {
+	// InvalidateChanges invalidates the cache records of a store affected by changes, which were
+	// just committed.
+	InvalidateChanges(storeID string, changes []*openfgav1.TupleChange)
+}
+
 type NoopCacheController struct{}
 
 func (c *NoopCacheController) DetermineInvalidationTime(_ context.Context, _ string) time.Time {
@@ -93,6 +111,15 @@ func WithLogger(logger logger.Logger) InMemoryCacheControllerOpt {
 	}
 }
 
+// WithChangeObservation makes InMemoryCacheController record the most recent change of the stores
+// it reads from the changelog, which costs an additional ReadChanges each time it reads the
+// changelog of a store, so that it implements ChangeObserver.
+func WithChangeObservation() InMemoryCacheControllerOpt {
+	return func(inm *InMemoryCacheController) {
+		inm.observeChanges = true
+	}
+}
+
 // InMemoryCacheController will invalidate cache iterator (InMemoryCache) and sub problem cache (CachedCheckResolver) entries
 // that are more recent than the last write for the specified store.
 // Note that the invalidation is done asynchronously, triggered by Check requests,
@@ -110,6 +137,7 @@ type InMemoryCacheController struct {
 	iteratorCacheTTL        time.Duration
 	inflightInvalidations   sync.Map
 	logger                  logger.Logger
+	observeChanges          bool
 
 	// for testing purposes
 	wg sync.WaitGroup
@@ -191,6 +219,35 @@ func (c *InMemoryCacheController) DetermineInvalidationTime(
 	return entry.LastModified
 }
 
+// LastObservedChangeID see [ChangeObserver].LastObservedChangeID. It returns the empty string unless
+// the cache controller was created WithChangeObservation.
+func (c *InMemoryCacheController) LastObservedChangeID(ctx context.Context, storeID string) string {
+	if !c.observeChanges {
+		return ""
+	}
+	entry, _ := c.cache.Get(storage.GetChangelogCacheKey(storeID)).(*storage.ChangelogCacheEntry)
+	if entry == nil || time.Since(entry.LastChecked) > c.minInvalidationInterval {
+		c.InvalidateIfNeeded(ctx, storeID) // async
+	}
+	if entry == nil {
+		return ""
+	}
+	return entry.LastChangeID
+}
+
+// findLastChangeID returns the ULID of the most recent change of the store, which ReadChanges
+// returns as the continuation token of its last change.
+func (c *InMemoryCacheController) findLastChangeID(ctx context.Context, storeID string) (string, error) {
+	opts := storage.ReadChangesOptions{
+		SortDesc: true,
+		Pagination: storage.PaginationOptions{
+			PageSize: 1,
+		},
+	}
+	_, lastChangeID, err := c.ds.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, opts)
+	return lastChangeID, err
+}
+
 // findChangesDescending is a wrapper on ReadChanges. If there are 0 changes to be returned, ReadChanges will actually return an error.
 func (c *InMemoryCacheController) findChangesDescending(ctx context.Context, storeID string) ([]*openfgav1.TupleChange, string, error) {
 	opts := storage.ReadChangesOptions{
@@ -228,8 +285,9 @@ func (c *InMemoryCacheController) InvalidateIfNeeded(ctx context.Context, storeI
 }
 
 type changelogResultMsg struct {
-	err     error
-	changes []*openfgav1.TupleChange
+	err          error
+	changes      []*openfgav1.TupleChange
+	lastChangeID string
 }
 
 // findChangesAndInvalidateIfNecessary checks the most recent entry in this store's changelog against the most
@@ -247,6 +305,7 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 	changelogCacheKey := storage.GetChangelogCacheKey(storeID)
 	lastCacheRecord := c.cache.Get(changelogCacheKey)
 	lastChangeTimeCached := time.Time{}
+	lastChangeIDCached := ""
 
 	if lastCacheRecord != nil {
 		if decodedRecord, ok := lastCacheRecord.(*storage.ChangelogCacheEntry); ok {
@@ -254,6 +313,7 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 			// time to have better consistency. Otherwise, the lastChangeTimeCached will
 			// be the beginning of time which imply the need to invalidate all records.
 			lastChangeTimeCached = decodedRecord.LastModified
+			lastChangeIDCached = decodedRecord.LastChangeID
 		} else {
 			c.logger.Error("Unable to cast lastCacheRecord properly", zap.String("changelogCacheKey", changelogCacheKey))
 		}
@@ -265,12 +325,23 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 
 	c.wg.Add(1)
 	go func() {
-		changes, _, err := c.findChangesDescending(ctx, storeID)
-		concurrency.TrySendThroughChannel(ctx, changelogResultMsg{err: err, changes: changes}, done)
+		var lastChangeID string
+		var err error
+		if c.observeChanges {
+			// the most recent change is read before the changes to invalidate, so that it is never
+			// recorded as observed before the caches are invalidated for it
+			lastChangeID, err = c.findLastChangeID(ctx, storeID)
+		}
+		var changes []*openfgav1.TupleChange
+		if err == nil && (lastChangeID == "" || lastChangeID != lastChangeIDCached) {
+			changes, _, err = c.findChangesDescending(ctx, storeID)
+		}
+		concurrency.TrySendThroughChannel(ctx, changelogResultMsg{err: err, changes: changes, lastChangeID: lastChangeID}, done)
 		c.wg.Done()
 	}()
 
 	var changes []*openfgav1.TupleChange
+	var lastChangeID string
 	select {
 	case <-ctx.Done():
 		// no need to modify changelogCacheKey as a new attempt will be done once the inflight validation is cleared
@@ -283,12 +354,26 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 			return
 		}
 		changes = msg.changes
+		lastChangeID = msg.lastChangeID
+	}
+
+	if len(changes) == 0 {
+		// the most recent change was already observed, so there is nothing to invalidate
+		c.cache.Set(changelogCacheKey, &storage.ChangelogCacheEntry{
+			LastModified: lastChangeTimeCached,
+			LastChecked:  time.Now(),
+			LastChangeID: lastChangeID,
+		}, c.queryCacheTTL)
+		span.SetAttributes(attribute.String("invalidationType", "none"))
+		findChangesAndInvalidateHistogram.WithLabelValues("none").Observe(float64(time.Since(start).Milliseconds()))
+		return
 	}
 
 	lastChangeTimeActual := changes[0].GetTimestamp().AsTime()
 	entry := &storage.ChangelogCacheEntry{
 		LastModified: lastChangeTimeActual,
 		LastChecked:  time.Now(),
+		LastChangeID: lastChangeID,
 	}
 
 	// The changelog cache entry is only used to compare against a cached Check
@@ -356,6 +441,39 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 	findChangesAndInvalidateHistogram.WithLabelValues(invalidationType).Observe(float64(time.Since(start).Milliseconds()))
 }
 
+// InvalidateChanges invalidates the cached iterators affected by changes, which were just committed
+// to the store, and records the store as modified, without reading its changelog. The changelog
+// is read again once the cache controller TTL has elapsed, which catches the changes that were not
+// pushed.
+func (c *InMemoryCacheController) InvalidateChanges(storeID string, changes []*openfgav1.TupleChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, change := range changes {
+		t := change.GetTupleKey()
+		c.invalidateIteratorCacheByObjectRelation(storeID, t.GetObject(), t.GetRelation(), now)
+		c.invalidateIteratorCacheByUserAndObjectType(storeID, t.GetUser(), tuple.GetType(t.GetObject()), now)
+	}
+	// the pushed changes have no ULID, so the most recent change read from the changelog is kept
+	// until the changelog is read again
+	var lastChangeID string
+	if entry, ok := c.cache.Get(storage.GetChangelogCacheKey(storeID)).(*storage.ChangelogCacheEntry); ok {
+		lastChangeID = entry.LastChangeID
+	}
+	c.cache.Set(storage.GetChangelogCacheKey(storeID), &storage.ChangelogCacheEntry{
+		LastModified: now,
+		LastChecked:  now,
+		LastChangeID: lastChangeID,
+	}, c.queryCacheTTL)
+
+	cacheInvalidationCounter.Inc()
+	c.logger.Debug("InMemoryCacheController InvalidateChanges invalidation",
+		zap.String("store_id", storeID),
+		zap.Int("changes", len(changes)))
+}
+
 // invalidateIteratorCache writes a new key to the cache with a very long TTL.
 // An alternative implementation could delete invalid keys, but this approach is faster (see storagewrappers.findInCache).
 func (c *InMemoryCacheController) invalidateIteratorCache(storeID string) {
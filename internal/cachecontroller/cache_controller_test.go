@@ -293,11 +293,18 @@ func TestInMemoryCacheController_findChangesAndInvalidateIfNecessary(t *testing.
 			PageSize: storage.DefaultPageSize,
 			From:     "",
 		}}
+	lastChangeID := "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+	expectedReadLastChangeOpts := storage.ReadChangesOptions{
+		SortDesc: true,
+		Pagination: storage.PaginationOptions{
+			PageSize: 1,
+		}}
 
 	tests := []struct {
-		name     string
-		storeID  string
-		setMocks func(cache *mocks.MockInMemoryCache[any], datastore *mocks.MockOpenFGADatastore)
+		name           string
+		storeID        string
+		observeChanges bool
+		setMocks       func(cache *mocks.MockInMemoryCache[any], datastore *mocks.MockOpenFGADatastore)
 	}{
 		{
 			name:    "timeout_changelog",
@@ -536,6 +543,44 @@ func TestInMemoryCacheController_findChangesAndInvalidateIfNecessary(t *testing.
 				)
 			},
 		},
+		{
+			name:           "last_change_observed",
+			storeID:        "11",
+			observeChanges: true,
+			setMocks: func(cache *mocks.MockInMemoryCache[any], datastore *mocks.MockOpenFGADatastore) {
+				gomock.InOrder(
+					cache.EXPECT().Get(storage.GetChangelogCacheKey("11")).Return(nil),
+					datastore.EXPECT().ReadChanges(gomock.Any(), "11", gomock.Any(), expectedReadLastChangeOpts).Return(nil, lastChangeID, nil),
+					datastore.EXPECT().ReadChanges(gomock.Any(), "11", gomock.Any(), expectedReadChangesOpts).Return(
+						generateChanges("test", "relation", "user", 1), "", nil),
+					cache.EXPECT().Set(storage.GetChangelogCacheKey("11"), gomock.Any(), gomock.Any()).Do(
+						func(_ string, entry *storage.ChangelogCacheEntry, _ time.Duration) {
+							require.Equal(t, lastChangeID, entry.LastChangeID)
+						},
+					),
+					cache.EXPECT().Set(storage.GetInvalidIteratorCacheKey("11"), gomock.Any(), gomock.Any()),
+				)
+			},
+		},
+		{
+			name:           "last_change_already_observed",
+			storeID:        "12",
+			observeChanges: true,
+			setMocks: func(cache *mocks.MockInMemoryCache[any], datastore *mocks.MockOpenFGADatastore) {
+				lastModified := time.Now().Add(-20 * time.Second)
+				gomock.InOrder(
+					cache.EXPECT().Get(storage.GetChangelogCacheKey("12")).Return(&storage.ChangelogCacheEntry{LastModified: lastModified, LastChangeID: lastChangeID}),
+					datastore.EXPECT().ReadChanges(gomock.Any(), "12", gomock.Any(), expectedReadLastChangeOpts).Return(nil, lastChangeID, nil),
+					// the changes are not read and nothing is invalidated
+					cache.EXPECT().Set(storage.GetChangelogCacheKey("12"), gomock.Any(), gomock.Any()).Do(
+						func(_ string, entry *storage.ChangelogCacheEntry, _ time.Duration) {
+							require.Equal(t, lastModified, entry.LastModified)
+							require.Equal(t, lastChangeID, entry.LastChangeID)
+						},
+					),
+				)
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -557,9 +602,94 @@ func TestInMemoryCacheController_findChangesAndInvalidateIfNecessary(t *testing.
 				iteratorCacheTTL:        30 * time.Second,
 				inflightInvalidations:   sync.Map{},
 				logger:                  logger.NewNoopLogger(),
+				observeChanges:          test.observeChanges,
 			}
 			cacheController.findChangesAndInvalidateIfNecessary(context.Background(), test.storeID)
 			cacheController.wg.Wait()
 		})
 	}
 }
+
+func TestInMemoryCacheController_InvalidateChanges(t *testing.T) {
+	cache, err := storage.NewInMemoryLRUCache[any]()
+	require.NoError(t, err)
+	t.Cleanup(cache.Stop)
+
+	ctrl := gomock.NewController(t)
+	ds := mocks.NewMockOpenFGADatastore(ctrl)
+	cacheController := NewCacheController(ds, cache, 10*time.Second, 10*time.Second, 10*time.Second).(*InMemoryCacheController)
+
+	before := time.Now()
+	cacheController.InvalidateChanges("store", []*openfgav1.TupleChange{{
+		Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
+		TupleKey:  &openfgav1.TupleKey{Object: "document:1", Relation: "viewer", User: "user:anne"},
+	}})
+
+	// the pushed change is recorded without reading the changelog
+	entry, ok := cache.Get(storage.GetChangelogCacheKey("store")).(*storage.ChangelogCacheEntry)
+	require.True(t, ok)
+	require.False(t, entry.LastModified.Before(before))
+	require.Equal(t, entry.LastModified, cacheController.DetermineInvalidationTime(context.Background(), "store"))
+
+	invalid, ok := cache.Get(storage.GetInvalidIteratorByObjectRelationCacheKey("store", "document:1", "viewer")).(*storage.InvalidEntityCacheEntry)
+	require.True(t, ok)
+	require.False(t, invalid.LastModified.Before(before))
+
+	invalid, ok = cache.Get(storage.GetInvalidIteratorByUserObjectTypeCacheKeys("store", []string{"user:anne"}, "document")[0]).(*storage.InvalidEntityCacheEntry)
+	require.True(t, ok)
+	require.False(t, invalid.LastModified.Before(before))
+
+	require.Nil(t, cache.Get(storage.GetChangelogCacheKey("other")))
+}
+
+func TestInMemoryCacheController_LastObservedChangeID(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cache, err := storage.NewInMemoryLRUCache[any]()
+	require.NoError(t, err)
+	t.Cleanup(cache.Stop)
+
+	ctrl := gomock.NewController(t)
+	ds := mocks.NewMockOpenFGADatastore(ctrl)
+	cacheController := NewCacheController(ds, cache, 10*time.Second, 10*time.Second, 10*time.Second, WithChangeObservation()).(*InMemoryCacheController)
+
+	lastChangeID := "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+	gomock.InOrder(
+		ds.EXPECT().ReadChanges(gomock.Any(), "store", gomock.Any(), storage.ReadChangesOptions{
+			SortDesc:   true,
+			Pagination: storage.PaginationOptions{PageSize: 1},
+		}).Return(nil, lastChangeID, nil),
+		ds.EXPECT().ReadChanges(gomock.Any(), "store", gomock.Any(), gomock.Any()).Return(
+			generateChanges("document:1", "viewer", "user:anne", 1), lastChangeID, nil),
+	)
+
+	// the first call triggers the read of the changelog
+	require.Empty(t, cacheController.LastObservedChangeID(context.Background(), "store"))
+	cacheController.wg.Wait()
+	require.Equal(t, lastChangeID, cacheController.LastObservedChangeID(context.Background(), "store"))
+
+	// the pushed changes have no ULID, so the last observed change is kept
+	cacheController.InvalidateChanges("store", generateChanges("document:2", "viewer", "user:anne", 1))
+	require.Equal(t, lastChangeID, cacheController.LastObservedChangeID(context.Background(), "store"))
+	cacheController.wg.Wait()
+}
+
+func TestInMemoryCacheController_LastObservedChangeIDWithoutChangeObservation(t *testing.T) {
+	cache, err := storage.NewInMemoryLRUCache[any]()
+	require.NoError(t, err)
+	t.Cleanup(cache.Stop)
+
+	// the changelog is read without the additional ReadChanges of the most recent change
+	ctrl := gomock.NewController(t)
+	ds := mocks.NewMockOpenFGADatastore(ctrl)
+	ds.EXPECT().ReadChanges(gomock.Any(), "store", gomock.Any(), storage.ReadChangesOptions{
+		SortDesc:   true,
+		Pagination: storage.PaginationOptions{PageSize: storage.DefaultPageSize},
+	}).Return(generateChanges("document:1", "viewer", "user:anne", 1), "01ARZ3NDEKTSV4RRFFQ69G5FAV", nil)
+	cacheController := NewCacheController(ds, cache, 10*time.Second, 10*time.Second, 10*time.Second).(*InMemoryCacheController)
+
+	cacheController.DetermineInvalidationTime(context.Background(), "store")
+	cacheController.wg.Wait()
+	require.Empty(t, cacheController.LastObservedChangeID(context.Background(), "store"))
+}
@@ -0,0 +1,124 @@
+package cachecontroller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var pushedChangesCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "cachecontroller_pushed_changes_count",
+	Help:      "The total number of tuple changes pushed by the cache invalidation listener.",
+})
+
+// SubscriberOpt defines an option that can be used to change the behavior of a Subscriber.
+type SubscriberOpt func(*Subscriber)
+
+// WithSubscriberLogger sets the logger of a Subscriber.
+func WithSubscriberLogger(logger logger.Logger) SubscriberOpt {
+	return func(s *Subscriber) {
+		s.logger = logger
+	}
+}
+
+// WithSubscriberMaxBackoff sets the maximum interval between two attempts to subscribe to the
+// listener after it failed. Defaults to 30 seconds.
+func WithSubscriberMaxBackoff(maxBackoff time.Duration) SubscriberOpt {
+	return func(s *Subscriber) {
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// Subscriber invalidates the caches with the changes pushed by a storage.ChangeListener as soon as
+// they are committed, instead of waiting for the cache controllers to find them in the changelog.
+// It subscribes to the listener again, with an exponential backoff, whenever it fails.
+type Subscriber struct {
+	listener     storage.ChangeListener
+	invalidators []ChangeInvalidator
+	maxBackoff   time.Duration
+	logger       logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSubscriber returns a Subscriber that invalidates the caches of the invalidators with the
+// changes pushed by listener.
+func NewSubscriber(listener storage.ChangeListener, invalidators []ChangeInvalidator, opts ...SubscriberOpt) *Subscriber {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Subscriber{
+		listener:     listener,
+		invalidators: invalidators,
+		maxBackoff:   30 * time.Second,
+		logger:       logger.NewNoopLogger(),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start subscribes to the listener in the background until Stop is called.
+func (s *Subscriber) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		policy := backoff.NewExponentialBackOff(
+			backoff.WithMaxElapsedTime(0),
+			backoff.WithMaxInterval(s.maxBackoff),
+		)
+		for {
+			started := time.Now()
+			err := s.listener.Listen(s.ctx, s.invalidate)
+			if s.ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) > s.maxBackoff {
+				policy.Reset()
+			}
+
+			wait := policy.NextBackOff()
+			s.logger.Error("cache invalidation listener failed, the caches are invalidated by the cache controller until it subscribes again",
+				zap.Error(err),
+				zap.Duration("retry_in", wait))
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the subscription started by Start.
+func (s *Subscriber) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Subscriber) invalidate(storeID string, changes []*openfgav1.TupleChange) {
+	pushedChangesCounter.Add(float64(len(changes)))
+	for _, invalidator := range s.invalidators {
+		invalidator.InvalidateChanges(storeID, changes)
+	}
+}
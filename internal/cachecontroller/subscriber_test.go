@@ -0,0 +1,73 @@
+package cachecontroller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// flakyListener fails on its first subscription, and pushes changes on the next ones.
+type flakyListener struct {
+	mu            sync.Mutex
+	subscriptions int
+}
+
+func (l *flakyListener) Listen(ctx context.Context, handle func(string, []*openfgav1.TupleChange)) error {
+	l.mu.Lock()
+	l.subscriptions++
+	subscriptions := l.subscriptions
+	l.mu.Unlock()
+
+	if subscriptions == 1 {
+		return errors.New("connection refused")
+	}
+	handle("store", []*openfgav1.TupleChange{{
+		TupleKey: &openfgav1.TupleKey{Object: "document:1", Relation: "viewer", User: "user:anne"},
+	}})
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type recordingInvalidator struct {
+	mu     sync.Mutex
+	stores []string
+}
+
+func (r *recordingInvalidator) InvalidateChanges(storeID string, _ []*openfgav1.TupleChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stores = append(r.stores, storeID)
+}
+
+func (r *recordingInvalidator) invalidatedStores() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.stores...)
+}
+
+func TestSubscriber(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	listener := &flakyListener{}
+	invalidator1, invalidator2 := &recordingInvalidator{}, &recordingInvalidator{}
+	subscriber := NewSubscriber(listener, []ChangeInvalidator{invalidator1, invalidator2},
+		WithSubscriberMaxBackoff(10*time.Millisecond))
+	subscriber.Start()
+
+	require.Eventually(t, func() bool {
+		return len(invalidator1.invalidatedStores()) == 1 && len(invalidator2.invalidatedStores()) == 1
+	}, time.Second, time.Millisecond)
+	require.Equal(t, []string{"store"}, invalidator1.invalidatedStores())
+
+	subscriber.Stop()
+	require.Equal(t, 2, listener.subscriptions)
+}
@@ -0,0 +1,146 @@
+// Package cachehints derives the hints with which the clients can cache the Check responses: the
+// time for which a response is expected to remain valid given the rate of the writes to its store,
+// and the keys of the writes that invalidate it.
+package cachehints
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openfga/openfga/internal/hotpath"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	// AnyKey is the invalidation key of a response that any tuple write to its store invalidates.
+	AnyKey = "*"
+
+	// ModelKey is the invalidation key of a response that the writes of the authorization models
+	// of its store invalidate, because it was resolved with the latest model.
+	ModelKey = "#model"
+
+	// maxAgeRatio is the ratio of the mean time between two writes to a store used as the max-age,
+	// so that a write is expected during the max-age of about one response in ten.
+	maxAgeRatio = 0.1
+
+	// smoothing is the weight of the latest time between two writes in their mean.
+	smoothing = 0.2
+)
+
+// writeRate estimates the mean time between the writes of a kind to a store.
+type writeRate struct {
+	last     time.Time
+	interval time.Duration
+}
+
+func (r *writeRate) observe(at time.Time) {
+	if !at.After(r.last) {
+		return
+	}
+	if !r.last.IsZero() {
+		interval := at.Sub(r.last)
+		if r.interval == 0 {
+			r.interval = interval
+		} else {
+			r.interval = time.Duration(smoothing*float64(interval) + (1-smoothing)*float64(r.interval))
+		}
+	}
+	r.last = at
+}
+
+// maxAge returns the max-age of a response given the writes, at most limit.
+func (r *writeRate) maxAge(now time.Time, limit time.Duration) time.Duration {
+	if r.last.IsZero() || r.interval == 0 {
+		return limit
+	}
+	// the longer the store has not been written to, the less frequent its writes are
+	interval := max(r.interval, now.Sub(r.last))
+	return min(time.Duration(maxAgeRatio*float64(interval)), limit)
+}
+
+type storeWrites struct {
+	tuples writeRate
+	models writeRate
+}
+
+// Tracker estimates the rate of the tuple and authorization model writes to each store from the
+// writes it observes, to derive the max-age of the Check responses. The writes are observed by
+// every replica, e.g. from the changelog, so the estimates are shared by the replicas.
+type Tracker struct {
+	maxAge time.Duration
+
+	mu     sync.Mutex
+	stores map[string]*storeWrites
+}
+
+// NewTracker returns a Tracker whose max-ages are at most maxAge.
+func NewTracker(maxAge time.Duration) *Tracker {
+	return &Tracker{
+		maxAge: maxAge,
+		stores: make(map[string]*storeWrites),
+	}
+}
+
+func (t *Tracker) store(storeID string) *storeWrites {
+	writes, ok := t.stores[storeID]
+	if !ok {
+		writes = &storeWrites{}
+		t.stores[storeID] = writes
+	}
+	return writes
+}
+
+// ObserveTupleWrite records a write of tuples to the store at the given time. The writes older than
+// the latest one observed are ignored.
+func (t *Tracker) ObserveTupleWrite(storeID string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store(storeID).tuples.observe(at)
+}
+
+// ObserveModelWrite records a write of an authorization model to the store at the given time.
+func (t *Tracker) ObserveModelWrite(storeID string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store(storeID).models.observe(at)
+}
+
+// MaxAge returns the time for which a Check response of the store is expected to remain valid: a
+// tenth of the mean time between its writes, and of its authorization model writes unless the
+// request pinned its model, at most the maximum max-age.
+func (t *Tracker) MaxAge(storeID string, latestModel bool) time.Duration {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	writes, ok := t.stores[storeID]
+	if !ok {
+		return t.maxAge
+	}
+	maxAge := writes.tuples.maxAge(now, t.maxAge)
+	if latestModel {
+		maxAge = min(maxAge, writes.models.maxAge(now, t.maxAge))
+	}
+	return maxAge
+}
+
+// Keys returns the invalidation keys of a Check of the relation of the object type: the object
+// types whose tuples can change its result, or AnyKey if its relation involves intersections,
+// exclusions or conditions, and ModelKey if it was resolved with the latest model.
+func Keys(typesys *typesystem.TypeSystem, objectType, relation string, latestModel bool) []string {
+	var keys []string
+	deps, err := hotpath.Analyze(typesys, objectType, relation)
+	if err != nil {
+		keys = []string{AnyKey}
+	} else {
+		for dep := range deps.ObjectTypes {
+			keys = append(keys, dep)
+		}
+		sort.Strings(keys)
+	}
+	if latestModel {
+		keys = append(keys, ModelKey)
+	}
+	return keys
+}
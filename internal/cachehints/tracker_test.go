@@ -0,0 +1,65 @@
+package cachehints
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+
+	t.Run("without_writes", func(t *testing.T) {
+		tracker := NewTracker(time.Minute)
+		require.Equal(t, time.Minute, tracker.MaxAge("store", true))
+
+		tracker.ObserveTupleWrite("store", now)
+		require.Equal(t, time.Minute, tracker.MaxAge("store", true))
+	})
+
+	t.Run("derives_the_max_age_from_the_writes", func(t *testing.T) {
+		tracker := NewTracker(time.Minute)
+		tracker.ObserveTupleWrite("store", now.Add(-20*time.Second))
+		tracker.ObserveTupleWrite("store", now.Add(-10*time.Second))
+		tracker.ObserveTupleWrite("store", now.Add(-15*time.Second)) // ignored, as it is older
+		require.InDelta(t, time.Second, tracker.MaxAge("store", true), float64(10*time.Millisecond))
+
+		tracker.ObserveModelWrite("store", now.Add(-3*time.Second))
+		tracker.ObserveModelWrite("store", now.Add(-2*time.Second))
+		require.InDelta(t, 200*time.Millisecond, tracker.MaxAge("store", true), float64(10*time.Millisecond))
+		require.InDelta(t, time.Second, tracker.MaxAge("store", false), float64(10*time.Millisecond))
+		require.Equal(t, time.Minute, tracker.MaxAge("other", true))
+	})
+
+	t.Run("at_most_the_max_age", func(t *testing.T) {
+		tracker := NewTracker(time.Second)
+		tracker.ObserveTupleWrite("store", now.Add(-2*time.Hour))
+		tracker.ObserveTupleWrite("store", now.Add(-time.Hour))
+		require.Equal(t, time.Second, tracker.MaxAge("store", true))
+	})
+}
+
+func TestKeys(t *testing.T) {
+	typesys, err := typesystem.New(testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user]
+				define viewer: [user] or viewer from parent
+				define restricted: [user] but not owner`))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"document", "folder", ModelKey}, Keys(typesys, "document", "viewer", true))
+	require.Equal(t, []string{"document"}, Keys(typesys, "document", "owner", false))
+	require.Equal(t, []string{AnyKey}, Keys(typesys, "document", "restricted", false))
+}
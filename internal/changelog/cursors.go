@@ -0,0 +1,120 @@
+package changelog
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTrackedCursors bounds the number of cursors tracked per store. When the bound is reached,
+// the cursor that was observed the longest time ago stops being tracked.
+const maxTrackedCursors = 10_000
+
+// CursorTracker records the positions of the ReadChanges cursors handed out to clients, so that
+// the changes an active reader has yet to consume are not pruned. A cursor is active for the
+// duration of its lease after it was last observed.
+//
+// Cursors are tracked in memory, so each server instance only knows about the cursors it handed
+// out itself.
+type CursorTracker struct {
+	lease time.Duration
+
+	mu      sync.Mutex
+	cursors map[string]map[string]time.Time // store => position => last observed
+}
+
+// NewCursorTracker returns a CursorTracker whose cursors stay active for the given lease.
+func NewCursorTracker(lease time.Duration) *CursorTracker {
+	return &CursorTracker{
+		lease:   lease,
+		cursors: make(map[string]map[string]time.Time),
+	}
+}
+
+// Observe records that a cursor at the given position (a change ULID) was handed out for store.
+func (c *CursorTracker) Observe(store, position string) {
+	if c == nil || position == "" || c.lease <= 0 {
+		return
+	}
+
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	positions, ok := c.cursors[store]
+	if !ok {
+		positions = make(map[string]time.Time)
+		c.cursors[store] = positions
+	}
+
+	if _, ok := positions[position]; !ok && len(positions) >= maxTrackedCursors {
+		c.expire(store, now)
+		if len(positions) >= maxTrackedCursors {
+			var oldest string
+			for p, seen := range positions {
+				if oldest == "" || seen.Before(positions[oldest]) {
+					oldest = p
+				}
+			}
+			delete(positions, oldest)
+		}
+	}
+	positions[position] = now
+}
+
+// Horizon returns the position of the oldest active cursor of store, or an empty string if no
+// cursor of the store is active.
+func (c *CursorTracker) Horizon(store string) string {
+	if c == nil {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(store, time.Now())
+
+	var horizon string
+	for position := range c.cursors[store] {
+		if horizon == "" || position < horizon {
+			horizon = position
+		}
+	}
+	return horizon
+}
+
+// OldestHorizon returns the position of the oldest active cursor of any store, or an empty string
+// if no cursor is active.
+func (c *CursorTracker) OldestHorizon() string {
+	if c == nil {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var horizon string
+	for store, positions := range c.cursors {
+		c.expire(store, now)
+		for position := range positions {
+			if horizon == "" || position < horizon {
+				horizon = position
+			}
+		}
+	}
+	return horizon
+}
+
+// expire stops tracking the cursors of store whose lease has ended. It must be called with mu held.
+func (c *CursorTracker) expire(store string, now time.Time) {
+	positions := c.cursors[store]
+	for position, seen := range positions {
+		if now.Sub(seen) > c.lease {
+			delete(positions, position)
+		}
+	}
+	if len(positions) == 0 {
+		delete(c.cursors, store)
+	}
+}
@@ -0,0 +1,36 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorTracker(t *testing.T) {
+	t.Run("horizon_is_oldest_position", func(t *testing.T) {
+		c := NewCursorTracker(time.Hour)
+		c.Observe("store", "01J00000000000000000000002")
+		c.Observe("store", "01J00000000000000000000001")
+		c.Observe("store", "01J00000000000000000000003")
+		c.Observe("other", "01J00000000000000000000000")
+
+		require.Equal(t, "01J00000000000000000000001", c.Horizon("store"))
+		require.Empty(t, c.Horizon("unknown"))
+	})
+
+	t.Run("expired_cursors_are_ignored", func(t *testing.T) {
+		c := NewCursorTracker(time.Millisecond)
+		c.Observe("store", "01J00000000000000000000001")
+
+		require.Eventually(t, func() bool {
+			return c.Horizon("store") == ""
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("nil_tracker", func(t *testing.T) {
+		var c *CursorTracker
+		c.Observe("store", "01J00000000000000000000001")
+		require.Empty(t, c.Horizon("store"))
+	})
+}
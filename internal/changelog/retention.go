@@ -0,0 +1,324 @@
+// Package changelog contains the background job that enforces the retention of the changelog of
+// each store.
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var (
+	tracer = otel.Tracer("internal/changelog")
+
+	prunedChangesCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "changelog_retention_pruned_changes_count",
+		Help:      "The total number of changes deleted from the changelog by the retention job.",
+	})
+
+	droppedPartitionsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "changelog_retention_dropped_partitions_count",
+		Help:      "The total number of partitions of the changelog partitioned by time dropped by the retention job.",
+	})
+)
+
+// ErrInvalidStorePolicy is returned when a per-store retention policy cannot be parsed.
+var ErrInvalidStorePolicy = errors.New("invalid changelog retention store policy")
+
+// Policy bounds the changelog of a store. A change is deleted when it is older than MaxAge or
+// when it is not one of the MaxEntries most recent changes. A zero value disables a bound.
+type Policy struct {
+	MaxAge     time.Duration
+	MaxEntries uint32
+}
+
+// IsZero reports whether the policy keeps every change.
+func (p Policy) IsZero() bool {
+	return p.MaxAge <= 0 && p.MaxEntries == 0
+}
+
+// ParseStorePolicies parses per-store policies in the form <store_id>=<max_age>:<max_entries>,
+// e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=720h:1000. A max age of 0 or a max entries of 0 disables that bound.
+func ParseStorePolicies(values []string) (map[string]Policy, error) {
+	policies := make(map[string]Policy, len(values))
+	for _, value := range values {
+		storeID, rawPolicy, ok := strings.Cut(value, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("%w '%s': expected <store_id>=<max_age>:<max_entries>", ErrInvalidStorePolicy, value)
+		}
+
+		rawMaxAge, rawMaxEntries, ok := strings.Cut(rawPolicy, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w '%s': expected <store_id>=<max_age>:<max_entries>", ErrInvalidStorePolicy, value)
+		}
+
+		maxAge, err := time.ParseDuration(rawMaxAge)
+		if err != nil || maxAge < 0 {
+			return nil, fmt.Errorf("%w '%s': invalid max age '%s'", ErrInvalidStorePolicy, value, rawMaxAge)
+		}
+
+		maxEntries, err := strconv.ParseUint(rawMaxEntries, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': invalid max entries '%s'", ErrInvalidStorePolicy, value, rawMaxEntries)
+		}
+
+		policies[storeID] = Policy{MaxAge: maxAge, MaxEntries: uint32(maxEntries)}
+	}
+	return policies, nil
+}
+
+// RetainerOption defines an option that can be used to change the behavior of a Retainer.
+type RetainerOption func(*Retainer)
+
+// WithDefaultPolicy sets the policy of the stores without a policy of their own.
+func WithDefaultPolicy(policy Policy) RetainerOption {
+	return func(r *Retainer) {
+		r.defaultPolicy = policy
+	}
+}
+
+// WithStorePolicies sets the policies of specific stores, overriding the default policy.
+func WithStorePolicies(policies map[string]Policy) RetainerOption {
+	return func(r *Retainer) {
+		r.storePolicies = policies
+	}
+}
+
+// WithCursorTracker makes the Retainer keep the changes that active ReadChanges cursors have yet to consume.
+func WithCursorTracker(cursors *CursorTracker) RetainerOption {
+	return func(r *Retainer) {
+		r.cursors = cursors
+	}
+}
+
+// WithInterval sets the time between two runs of the Retainer.
+func WithInterval(interval time.Duration) RetainerOption {
+	return func(r *Retainer) {
+		r.interval = interval
+	}
+}
+
+// WithLogger sets the logger of the Retainer.
+func WithLogger(l logger.Logger) RetainerOption {
+	return func(r *Retainer) {
+		r.logger = l
+	}
+}
+
+// Datastore is the subset of the datastore used by the Retainer.
+type Datastore interface {
+	storage.StoresBackend
+	storage.ChangelogPruner
+}
+
+// Retainer periodically deletes, for every store, the changes that fall outside of the retention
+// policy of the store.
+//
+// If the changelog of the datastore is partitioned by time, the Retainer creates its partitions in
+// advance, and enforces the longest max age of the policies by dropping the partitions whose
+// changes are all older than it and than the oldest active cursor, rather than by deleting the
+// changes one by one. The changes are then kept until their whole partition expires. The policies
+// with a shorter max age and the max entries are still enforced by deleting the changes, and no
+// partition is dropped if a policy has no max age.
+type Retainer struct {
+	datastore     Datastore
+	defaultPolicy Policy
+	storePolicies map[string]Policy
+	cursors       *CursorTracker
+	interval      time.Duration
+	logger        logger.Logger
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// NewRetainer returns a Retainer that prunes the changelog of the stores of datastore.
+func NewRetainer(datastore Datastore, opts ...RetainerOption) *Retainer {
+	r := &Retainer{
+		datastore: datastore,
+		interval:  time.Hour,
+		logger:    logger.NewNoopLogger(),
+		stop:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Start runs the Retainer in the background every interval until Stop is called.
+func (r *Retainer) Start() {
+	ticker := time.NewTicker(r.interval)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Run(context.Background()); err != nil {
+					r.logger.Error("changelog retention failed", zap.Error(err))
+				}
+			case <-r.stop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background routine started by Start.
+func (r *Retainer) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// Run prunes the changelog of every store once.
+func (r *Retainer) Run(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "changelog.Retainer.Run")
+	defer span.End()
+
+	partitionMaxAge, errs := r.dropPartitions(ctx)
+
+	var continuationToken string
+	var pruned int64
+	for {
+		stores, token, err := r.datastore.ListStores(ctx, storage.ListStoresOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if err != nil {
+			return errors.Join(errs, err)
+		}
+
+		for _, store := range stores {
+			n, err := r.pruneStore(ctx, store.GetId(), partitionMaxAge)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("store '%s': %w", store.GetId(), err))
+				continue
+			}
+			pruned += n
+		}
+
+		if token == "" {
+			break
+		}
+		continuationToken = token
+	}
+
+	span.SetAttributes(attribute.Int64("pruned_changes", pruned))
+	return errs
+}
+
+// PruneStore deletes the changes of store that fall outside of its retention policy and returns
+// how many changes were deleted.
+func (r *Retainer) PruneStore(ctx context.Context, store string) (int64, error) {
+	return r.pruneStore(ctx, store, 0)
+}
+
+// pruneStore deletes the changes of store that fall outside of its retention policy, except for a
+// max age of partitionMaxAge, which is enforced by dropping the partitions of the changelog.
+func (r *Retainer) pruneStore(ctx context.Context, store string, partitionMaxAge time.Duration) (int64, error) {
+	policy, ok := r.storePolicies[store]
+	if !ok {
+		policy = r.defaultPolicy
+	}
+	if policy.IsZero() {
+		return 0, nil
+	}
+
+	options := storage.PruneChangesOptions{
+		KeepLatest: policy.MaxEntries,
+		Horizon:    r.cursors.Horizon(store),
+	}
+	if policy.MaxAge > 0 && (partitionMaxAge == 0 || policy.MaxAge < partitionMaxAge) {
+		options.OlderThan = time.Now().Add(-policy.MaxAge)
+	}
+	if options.OlderThan.IsZero() && options.KeepLatest == 0 {
+		return 0, nil
+	}
+
+	n, err := r.datastore.PruneChanges(ctx, store, options)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		prunedChangesCounter.Add(float64(n))
+		r.logger.Debug("pruned changelog",
+			zap.String("store_id", store),
+			zap.Int64("pruned_changes", n),
+		)
+	}
+	return n, nil
+}
+
+// partitionMaxAge returns the longest max age of the policies, after which the changes of every
+// store can be dropped, or 0 if a policy has no max age.
+func (r *Retainer) partitionMaxAge() time.Duration {
+	maxAge := r.defaultPolicy.MaxAge
+	for _, policy := range r.storePolicies {
+		if policy.MaxAge <= 0 {
+			return 0
+		}
+		maxAge = max(maxAge, policy.MaxAge)
+	}
+	if r.defaultPolicy.MaxAge <= 0 {
+		return 0
+	}
+	return maxAge
+}
+
+// dropPartitions creates the partitions of the changelog of the datastore in advance and drops
+// its expired partitions, if it is partitioned by time. It returns the max age it enforced by
+// dropping the partitions, or 0 if it didn't.
+func (r *Retainer) dropPartitions(ctx context.Context) (time.Duration, error) {
+	partitioner, ok := r.datastore.(storage.ChangelogPartitioner)
+	if !ok {
+		return 0, nil
+	}
+
+	now := time.Now()
+	err := partitioner.CreateChangelogPartitions(ctx, now.Add(2*storage.ChangelogPartitionInterval))
+	if errors.Is(err, errors.ErrUnsupported) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create changelog partitions: %w", err)
+	}
+
+	maxAge := r.partitionMaxAge()
+	if maxAge == 0 {
+		return 0, nil
+	}
+	before := now.Add(-maxAge)
+	if horizon, err := ulid.Parse(r.cursors.OldestHorizon()); err == nil && ulid.Time(horizon.Time()).Before(before) {
+		before = ulid.Time(horizon.Time())
+	}
+
+	dropped, err := partitioner.DropChangelogPartitions(ctx, before)
+	if dropped > 0 {
+		droppedPartitionsCounter.Add(float64(dropped))
+		r.logger.Debug("dropped changelog partitions", zap.Int("dropped_partitions", dropped))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop changelog partitions: %w", err)
+	}
+	return maxAge, nil
+}
@@ -0,0 +1,220 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestParseStorePolicies(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		policies, err := ParseStorePolicies([]string{"store-a=720h:1000", "store-b=0s:10", "store-c=24h:0"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]Policy{
+			"store-a": {MaxAge: 720 * time.Hour, MaxEntries: 1000},
+			"store-b": {MaxEntries: 10},
+			"store-c": {MaxAge: 24 * time.Hour},
+		}, policies)
+	})
+
+	for _, value := range []string{"store-a", "=24h:10", "store-a=24h", "store-a=forever:10", "store-a=-1h:10", "store-a=24h:-1"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := ParseStorePolicies([]string{value})
+			require.ErrorIs(t, err, ErrInvalidStorePolicy)
+		})
+	}
+}
+
+func writeChanges(t *testing.T, ds storage.OpenFGADatastore, storeID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func countChanges(t *testing.T, ds storage.OpenFGADatastore, storeID string) int {
+	t.Helper()
+	changes, _, err := ds.ReadChanges(context.Background(), storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+	})
+	if err != nil {
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	}
+	return len(changes)
+}
+
+func TestRetainer(t *testing.T) {
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	retained := ds.(Datastore)
+
+	createStore := func(t *testing.T) string {
+		t.Helper()
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "retention"})
+		require.NoError(t, err)
+		return store.GetId()
+	}
+
+	t.Run("default_and_store_policies", func(t *testing.T) {
+		defaultStore := createStore(t)
+		overriddenStore := createStore(t)
+		writeChanges(t, ds, defaultStore, 5)
+		writeChanges(t, ds, overriddenStore, 5)
+
+		r := NewRetainer(retained,
+			WithDefaultPolicy(Policy{MaxEntries: 3}),
+			WithStorePolicies(map[string]Policy{overriddenStore: {MaxEntries: 1}}),
+		)
+		require.NoError(t, r.Run(ctx))
+
+		require.Equal(t, 3, countChanges(t, ds, defaultStore))
+		require.Equal(t, 1, countChanges(t, ds, overriddenStore))
+	})
+
+	t.Run("zero_policy_keeps_everything", func(t *testing.T) {
+		store := createStore(t)
+		writeChanges(t, ds, store, 5)
+
+		r := NewRetainer(retained, WithStorePolicies(map[string]Policy{store: {}}))
+		pruned, err := r.PruneStore(ctx, store)
+		require.NoError(t, err)
+		require.Zero(t, pruned)
+	})
+
+	t.Run("active_cursor_is_respected", func(t *testing.T) {
+		store := createStore(t)
+		writeChanges(t, ds, store, 5)
+
+		_, position, err := ds.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(2, ""),
+		})
+		require.NoError(t, err)
+
+		cursors := NewCursorTracker(time.Hour)
+		cursors.Observe(store, position)
+
+		r := NewRetainer(retained, WithDefaultPolicy(Policy{MaxEntries: 1}), WithCursorTracker(cursors))
+		pruned, err := r.PruneStore(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), pruned)
+		require.Equal(t, 4, countChanges(t, ds, store))
+	})
+
+	t.Run("start_and_stop", func(t *testing.T) {
+		store := createStore(t)
+		writeChanges(t, ds, store, 5)
+
+		r := NewRetainer(retained, WithDefaultPolicy(Policy{MaxEntries: 2}), WithInterval(time.Millisecond))
+		r.Start()
+		require.Eventually(t, func() bool {
+			return countChanges(t, ds, store) == 2
+		}, time.Second, 5*time.Millisecond)
+		r.Stop()
+	})
+}
+
+// partitionedDatastore records the calls to the partitioning of its changelog.
+type partitionedDatastore struct {
+	Datastore
+
+	until  time.Time
+	before time.Time
+}
+
+func (d *partitionedDatastore) CreateChangelogPartitions(_ context.Context, until time.Time) error {
+	d.until = until
+	return nil
+}
+
+func (d *partitionedDatastore) DropChangelogPartitions(_ context.Context, before time.Time) (int, error) {
+	d.before = before
+	return 1, nil
+}
+
+func TestRetainerWithPartitionedChangelog(t *testing.T) {
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	createStore := func(t *testing.T) string {
+		t.Helper()
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "retention"})
+		require.NoError(t, err)
+		return store.GetId()
+	}
+
+	t.Run("drops_the_partitions_of_the_longest_max_age", func(t *testing.T) {
+		defaultStore := createStore(t)
+		shorterStore := createStore(t)
+		writeChanges(t, ds, defaultStore, 3)
+		writeChanges(t, ds, shorterStore, 3)
+		time.Sleep(5 * time.Millisecond)
+
+		partitioned := &partitionedDatastore{Datastore: ds.(Datastore)}
+		r := NewRetainer(partitioned,
+			WithDefaultPolicy(Policy{MaxAge: time.Hour}),
+			WithStorePolicies(map[string]Policy{shorterStore: {MaxAge: time.Millisecond}}),
+		)
+		start := time.Now()
+		require.NoError(t, r.Run(ctx))
+
+		require.True(t, partitioned.until.After(start.Add(storage.ChangelogPartitionInterval)))
+		require.WithinDuration(t, start.Add(-time.Hour), partitioned.before, time.Second)
+
+		// the longest max age is enforced by the partitions, the shorter ones by deleting the changes
+		require.Equal(t, 3, countChanges(t, ds, defaultStore))
+		require.Zero(t, countChanges(t, ds, shorterStore))
+	})
+
+	t.Run("keeps_the_partitions_of_the_active_cursors", func(t *testing.T) {
+		store := createStore(t)
+		writeChanges(t, ds, store, 3)
+
+		_, position, err := ds.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(1, ""),
+		})
+		require.NoError(t, err)
+		cursors := NewCursorTracker(time.Hour)
+		cursors.Observe(store, position)
+		time.Sleep(5 * time.Millisecond)
+
+		partitioned := &partitionedDatastore{Datastore: ds.(Datastore)}
+		r := NewRetainer(partitioned, WithDefaultPolicy(Policy{MaxAge: time.Millisecond}), WithCursorTracker(cursors))
+		require.NoError(t, r.Run(ctx))
+
+		id, err := ulid.Parse(position)
+		require.NoError(t, err)
+		require.Equal(t, ulid.Time(id.Time()), partitioned.before)
+	})
+
+	t.Run("no_partition_is_dropped_without_max_age", func(t *testing.T) {
+		store := createStore(t)
+		writeChanges(t, ds, store, 3)
+
+		partitioned := &partitionedDatastore{Datastore: ds.(Datastore)}
+		r := NewRetainer(partitioned,
+			WithDefaultPolicy(Policy{MaxAge: time.Hour}),
+			WithStorePolicies(map[string]Policy{store: {MaxEntries: 1}}),
+		)
+		require.NoError(t, r.Run(ctx))
+
+		require.True(t, partitioned.before.IsZero())
+		require.Equal(t, 1, countChanges(t, ds, store))
+	})
+}
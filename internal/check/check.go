@@ -42,6 +42,7 @@ type Config struct {
 	Datastore                 storage.RelationshipTupleReader
 	Cache                     storage.InMemoryCache[any]
 	CacheTTL                  time.Duration
+	CacheTTLJitterPercentage  uint32
 	LastCacheInvalidationTime time.Time
 	Planner                   planner.Manager
 	ConcurrencyLimit          int
@@ -55,6 +56,7 @@ type Resolver struct {
 	datastore                 storage.RelationshipTupleReader
 	cache                     storage.InMemoryCache[any]
 	cacheTTL                  time.Duration
+	cacheTTLJitterPercentage  uint32
 	lastCacheInvalidationTime time.Time
 	planner                   planner.Manager
 	concurrencyLimit          int
@@ -70,6 +72,7 @@ func New(cfg Config) *Resolver {
 		datastore:                 cfg.Datastore,
 		cache:                     cfg.Cache,
 		cacheTTL:                  cfg.CacheTTL,
+		cacheTTLJitterPercentage:  cfg.CacheTTLJitterPercentage,
 		lastCacheInvalidationTime: cfg.LastCacheInvalidationTime,
 		planner:                   cfg.Planner,
 		concurrencyLimit:          cfg.ConcurrencyLimit,
@@ -199,7 +202,7 @@ func (r *Resolver) ResolveUnionEdges(ctx context.Context, req *Request, edges []
 			// otherwise the subproblem should be sufficient
 			if err == nil && edge.GetRelationDefinition() != objectRelation {
 				entry := &ResponseCacheEntry{Res: res, LastModified: time.Now()}
-				r.cache.Set(id, entry, r.cacheTTL)
+				r.cache.Set(id, entry, storage.JitteredTTL(r.cacheTTL, r.cacheTTLJitterPercentage))
 			}
 			concurrency.TrySendThroughChannel(ctx, ResponseMsg{ID: id, Res: res, Err: err}, out)
 			return nil
@@ -220,7 +223,7 @@ func (r *Resolver) ResolveUnionEdges(ctx context.Context, req *Request, edges []
 			if msg.Res.GetAllowed() {
 				// Short-circuit: In a union, if any branch returns true, we can immediately return.
 				entry := &ResponseCacheEntry{Res: msg.Res, LastModified: time.Now()}
-				r.cache.Set(req.GetCacheKey(), entry, r.cacheTTL)
+				r.cache.Set(req.GetCacheKey(), entry, storage.JitteredTTL(r.cacheTTL, r.cacheTTLJitterPercentage))
 				return msg.Res, nil
 			}
 		}
@@ -231,7 +234,7 @@ func (r *Resolver) ResolveUnionEdges(ctx context.Context, req *Request, edges []
 	}
 	res := &Response{Allowed: false}
 	entry := &ResponseCacheEntry{Res: res, LastModified: time.Now()}
-	r.cache.Set(req.GetCacheKey(), entry, r.cacheTTL)
+	r.cache.Set(req.GetCacheKey(), entry, storage.JitteredTTL(r.cacheTTL, r.cacheTTLJitterPercentage))
 	return res, nil
 }
 
@@ -154,9 +154,8 @@ func NewRequest(p RequestParams) (*Request, error) {
 		userWildcard: tuple.IsTypedWildcard(p.TupleKey.GetUser()),
 	}
 
-	keyBuilder := &strings.Builder{}
 	// TODO: we really should refactor this method to not depend on storage package and improve the implementation overall
-	err := storage.WriteInvariantCheckCacheKey(keyBuilder, &storage.CheckCacheKeyParams{
+	invariantHash, err := storage.InvariantCheckCacheKeyHash(&storage.CheckCacheKeyParams{
 		StoreID:              p.StoreID,
 		AuthorizationModelID: modelID,
 		ContextualTuples:     p.ContextualTuples,
@@ -165,18 +164,11 @@ func NewRequest(p RequestParams) (*Request, error) {
 	if err != nil {
 		return nil, err
 	}
+	r.invariantCacheKey = strconv.FormatUint(invariantHash, 10)
 
-	hasher := xxhash.New()
-	_, _ = hasher.WriteString(keyBuilder.String())
-	r.invariantCacheKey = strconv.FormatUint(hasher.Sum64(), 10)
-
-	tup := tuple.From(r.GetTupleKey())
-	hasher = xxhash.New()
-	_, _ = hasher.WriteString(r.GetInvariantCacheKey())
-	_, _ = hasher.WriteString(tup.String())
-
-	cacheKey := cacheKeyPrefix + strconv.FormatUint(hasher.Sum64(), 10)
-	r.cacheKey = cacheKey
+	tk := r.GetTupleKey()
+	hash := storage.HashCacheKeyParts(r.GetInvariantCacheKey(), tk.GetObject(), "#", tk.GetRelation(), "@", tk.GetUser())
+	r.cacheKey = cacheKeyPrefix + strconv.FormatUint(hash, 10)
 
 	r.buildContextualTupleMaps()
 
@@ -0,0 +1,289 @@
+// Package cluster coordinates the replicas of a server. Every replica knows the addresses of all
+// the replicas, either from a static list or by gossiping with them, and assigns each Check to one
+// of them with rendezvous hashing, so that all the replicas agree on the replica that owns a Check
+// without communicating.
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+// ForwardedHeader is the gRPC metadata key set on the requests forwarded to another replica, which
+// resolves them locally instead of forwarding them again.
+const ForwardedHeader = "openfga-cluster-forwarded"
+
+// authorizationHeader is forwarded along with the requests, so that the replica that resolves them
+// authenticates the original caller.
+const authorizationHeader = "authorization"
+
+// Option defines an option that can be used to change the behavior of a Cluster.
+type Option func(*Cluster)
+
+// WithForwardTimeout sets the maximum duration of a request forwarded to another replica.
+func WithForwardTimeout(timeout time.Duration) Option {
+	return func(c *Cluster) {
+		c.forwardTimeout = timeout
+	}
+}
+
+// WithTLS makes the connections to the other replicas use TLS.
+func WithTLS(enabled bool) Option {
+	return func(c *Cluster) {
+		c.tlsEnabled = enabled
+	}
+}
+
+// WithGossip makes the replicas discover each other by gossiping on bindAddress, in addition to the
+// static list of peers. The local replica joins the cluster through the replicas listening for
+// gossip at the join addresses.
+func WithGossip(bindAddress string, join []string) Option {
+	return func(c *Cluster) {
+		c.gossipBindAddress = bindAddress
+		c.gossipJoin = join
+	}
+}
+
+// WithLogger sets the logger of the Cluster.
+func WithLogger(l logger.Logger) Option {
+	return func(c *Cluster) {
+		c.logger = l
+	}
+}
+
+// Cluster is the set of replicas of the server. It implements graph.ClusterPeers and
+// graph.CheckDispatcher.
+type Cluster struct {
+	self              string
+	forwardTimeout    time.Duration
+	tlsEnabled        bool
+	gossipBindAddress string
+	gossipJoin        []string
+	logger            logger.Logger
+
+	staticPeers []string
+	peers       atomic.Pointer[[]string]
+	gossip      *gossip
+	closeOnce   sync.Once
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+	creds credentials.TransportCredentials
+}
+
+var (
+	_ graph.ClusterPeers    = (*Cluster)(nil)
+	_ graph.CheckDispatcher = (*Cluster)(nil)
+)
+
+// New returns the Cluster of the replicas at the gRPC addresses peers, where self is the address
+// of the local replica. The connections to the other replicas are established lazily.
+func New(self string, peers []string, opts ...Option) (*Cluster, error) {
+	if self == "" {
+		return nil, errors.New("the address of the local replica is required")
+	}
+	return newCluster(self, peers, opts...)
+}
+
+// NewTier returns the Cluster of the replicas at the gRPC addresses of a dedicated tier that the
+// local replica is not part of, e.g. the tier that resolves the Checks of the API-facing replicas.
+// The replicas of a tier are not discovered by gossip.
+func NewTier(addresses []string, opts ...Option) (*Cluster, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("the addresses of the replicas of the tier are required")
+	}
+	return newCluster("", addresses, append(opts, WithGossip("", nil))...)
+}
+
+func newCluster(self string, peers []string, opts ...Option) (*Cluster, error) {
+	c := &Cluster{
+		self:           self,
+		forwardTimeout: 3 * time.Second,
+		logger:         logger.NewNoopLogger(),
+		conns:          make(map[string]*grpc.ClientConn),
+		creds:          insecure.NewCredentials(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.tlsEnabled {
+		c.creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	if self != "" {
+		c.staticPeers = append(c.staticPeers, self)
+	}
+	for _, peer := range peers {
+		if slices.Contains(c.staticPeers, peer) {
+			continue
+		}
+		// create the connections to the static peers upfront to report invalid addresses
+		if _, err := c.conn(peer); err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.staticPeers = append(c.staticPeers, peer)
+	}
+	c.setPeers(nil)
+
+	if c.gossipBindAddress != "" {
+		var err error
+		c.gossip, err = newGossip(c)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Peers returns the addresses of all the replicas, starting with the local one.
+func (c *Cluster) Peers() []string {
+	return slices.Clone(*c.peers.Load())
+}
+
+// setPeers sets the replicas to the static peers and the replicas discovered by gossip.
+func (c *Cluster) setPeers(discovered []string) {
+	peers := slices.Clone(c.staticPeers)
+	for _, peer := range discovered {
+		if !slices.Contains(peers, peer) {
+			peers = append(peers, peer)
+		}
+	}
+	c.peers.Store(&peers)
+}
+
+// conn returns the connection to the replica at address peer, creating it if needed.
+func (c *Cluster) conn(peer string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[peer]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.NewClient(peer,
+		grpc.WithTransportCredentials(c.creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a client for the replica '%s': %w", peer, err)
+	}
+	c.conns[peer] = conn
+	return conn, nil
+}
+
+// Owner returns the address of the replica that owns the key, or an empty string if the local
+// replica owns it or if the request of ctx was forwarded by another replica.
+func (c *Cluster) Owner(ctx context.Context, key string) string {
+	if IsForwarded(ctx) {
+		return ""
+	}
+	if owner := c.owner(key); owner != c.self {
+		return owner
+	}
+	return ""
+}
+
+// owner returns the replica with the highest hash of its address and the key.
+func (c *Cluster) owner(key string) string {
+	var (
+		owner     string
+		bestScore uint64
+	)
+	for _, peer := range *c.peers.Load() {
+		digest := xxhash.New()
+		_, _ = digest.WriteString(peer)
+		_, _ = digest.WriteString("|")
+		_, _ = digest.WriteString(key)
+		if score := digest.Sum64(); owner == "" || score > bestScore {
+			owner, bestScore = peer, score
+		}
+	}
+	return owner
+}
+
+// Forward resolves the Check of req on the replica at address peer, with the credentials of the
+// request of ctx.
+func (c *Cluster) Forward(ctx context.Context, peer string, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+	if !slices.Contains(*c.peers.Load(), peer) {
+		return nil, fmt.Errorf("unknown replica '%s'", peer)
+	}
+	conn, err := c.conn(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.forwardTimeout)
+	defer cancel()
+	ctx = outgoingContext(ctx, ForwardedHeader)
+
+	tk := req.GetTupleKey()
+	resp, err := openfgav1.NewOpenFGAServiceClient(conn).Check(ctx, &openfgav1.CheckRequest{
+		StoreId:              req.GetStoreID(),
+		AuthorizationModelId: req.GetAuthorizationModelID(),
+		TupleKey: &openfgav1.CheckRequestTupleKey{
+			Object:   tk.GetObject(),
+			Relation: tk.GetRelation(),
+			User:     tk.GetUser(),
+		},
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: req.GetContextualTuples()},
+		Context:          req.GetContext(),
+		Consistency:      req.GetConsistency(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &graph.ResolveCheckResponse{Allowed: resp.GetAllowed()}, nil
+}
+
+// Close leaves the gossip and closes the connections to the other replicas.
+func (c *Cluster) Close() {
+	c.closeOnce.Do(func() {
+		if c.gossip != nil {
+			c.gossip.close()
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, conn := range c.conns {
+			_ = conn.Close()
+		}
+	})
+}
+
+// outgoingContext returns ctx with the header that marks a request sent to another replica, and
+// the credentials of the request of ctx.
+func outgoingContext(ctx context.Context, header string) context.Context {
+	md := metadata.Pairs(header, "true")
+	if authorization := metadata.ValueFromIncomingContext(ctx, authorizationHeader); len(authorization) > 0 {
+		md.Set(authorizationHeader, authorization...)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// IsForwarded reports whether the request of ctx was forwarded by another replica.
+func IsForwarded(ctx context.Context) bool {
+	return len(metadata.ValueFromIncomingContext(ctx, ForwardedHeader)) > 0
+}
@@ -0,0 +1,269 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type checkServer struct {
+	openfgav1.UnimplementedOpenFGAServiceServer
+	requests chan *openfgav1.CheckRequest
+	md       chan metadata.MD
+}
+
+func (s *checkServer) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.requests <- req
+	s.md <- md
+	return &openfgav1.CheckResponse{Allowed: true}, nil
+}
+
+type dispatchServer struct {
+	requests chan *graph.ResolveCheckRequest
+	owners   chan string
+	cluster  *Cluster
+}
+
+func (s *dispatchServer) ResolveDispatchedCheck(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+	s.requests <- req
+	s.owners <- s.cluster.DispatchOwner(ctx, graph.BuildCacheKey(*req))
+	return &graph.ResolveCheckResponse{
+		Allowed:            true,
+		ResolutionMetadata: graph.ResolveCheckResponseMetadata{DatastoreQueryCount: 2},
+	}, nil
+}
+
+func TestClusterOwner(t *testing.T) {
+	peers := []string{"a:8081", "b:8081", "c:8081"}
+	clusters := make([]*Cluster, 0, len(peers))
+	for _, self := range peers {
+		c, err := New(self, peers)
+		require.NoError(t, err)
+		t.Cleanup(c.Close)
+		clusters = append(clusters, c)
+	}
+
+	owned := make(map[string]int)
+	for i := range 300 {
+		key := strconv.Itoa(i)
+		owner := clusters[0].owner(key)
+		for _, c := range clusters {
+			require.Equal(t, owner, c.owner(key), "the replicas agree on the owner")
+			if c.self == owner {
+				require.Empty(t, c.Owner(context.Background(), key))
+			} else {
+				require.Equal(t, owner, c.Owner(context.Background(), key))
+			}
+		}
+		owned[owner]++
+	}
+	for _, peer := range peers {
+		require.Greater(t, owned[peer], 50, "the keys are spread across the replicas")
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ForwardedHeader, "true"))
+	for i := range 10 {
+		require.Empty(t, clusters[0].Owner(ctx, strconv.Itoa(i)), "forwarded requests are resolved locally")
+	}
+}
+
+func TestClusterForward(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &checkServer{requests: make(chan *openfgav1.CheckRequest, 1), md: make(chan metadata.MD, 1)}
+	grpcServer := grpc.NewServer()
+	openfgav1.RegisterOpenFGAServiceServer(grpcServer, srv)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	peer := lis.Addr().String()
+	c, err := New("localhost:0", []string{peer})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:              "store",
+		AuthorizationModelID: "model",
+		TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		ContextualTuples:     []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "owner", "user:anne")},
+		Consistency:          openfgav1.ConsistencyPreference_MINIMIZE_LATENCY,
+	})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer key"))
+	resp, err := c.Forward(ctx, peer, req)
+	require.NoError(t, err)
+	require.True(t, resp.GetAllowed())
+
+	forwarded := <-srv.requests
+	require.Equal(t, "store", forwarded.GetStoreId())
+	require.Equal(t, "model", forwarded.GetAuthorizationModelId())
+	require.Equal(t, "document:1", forwarded.GetTupleKey().GetObject())
+	require.Len(t, forwarded.GetContextualTuples().GetTupleKeys(), 1)
+	require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, forwarded.GetConsistency())
+
+	md := <-srv.md
+	require.Equal(t, []string{"true"}, md.Get(ForwardedHeader))
+	require.Equal(t, []string{"Bearer key"}, md.Get("authorization"))
+
+	_, err = c.Forward(ctx, "unknown:8081", req)
+	require.Error(t, err)
+}
+
+func TestClusterDispatch(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	peer := lis.Addr().String()
+
+	remote, err := New(peer, []string{"localhost:0"})
+	require.NoError(t, err)
+	t.Cleanup(remote.Close)
+
+	srv := &dispatchServer{requests: make(chan *graph.ResolveCheckRequest, 1), owners: make(chan string, 1), cluster: remote}
+	grpcServer := grpc.NewServer()
+	RegisterDispatchServer(grpcServer, srv)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	c, err := New("localhost:0", []string{peer})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:              "store",
+		AuthorizationModelID: "model",
+		TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		ContextualTuples:     []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "owner", "user:anne")},
+		Consistency:          openfgav1.ConsistencyPreference_MINIMIZE_LATENCY,
+	})
+	require.NoError(t, err)
+	req.GetRequestMetadata().Depth = 3
+	req.VisitedPaths["document:1#viewer@user:anne"] = struct{}{}
+
+	resp, err := c.Dispatch(context.Background(), peer, req)
+	require.NoError(t, err)
+	require.True(t, resp.GetAllowed())
+	require.Equal(t, uint32(2), resp.GetResolutionMetadata().DatastoreQueryCount)
+
+	dispatched := <-srv.requests
+	require.Equal(t, "store", dispatched.GetStoreID())
+	require.Equal(t, "model", dispatched.GetAuthorizationModelID())
+	require.Equal(t, "document:1", dispatched.GetTupleKey().GetObject())
+	require.Len(t, dispatched.GetContextualTuples(), 1)
+	require.Equal(t, uint32(3), dispatched.GetRequestMetadata().Depth)
+	require.Contains(t, dispatched.GetVisitedPaths(), "document:1#viewer@user:anne")
+	require.Equal(t, graph.BuildCacheKey(*req), graph.BuildCacheKey(*dispatched))
+	require.Empty(t, <-srv.owners, "dispatched sub-problems are resolved locally")
+
+	_, err = c.Dispatch(context.Background(), "unknown:8081", req)
+	require.Error(t, err)
+}
+
+func TestClusterGossip(t *testing.T) {
+	first, err := New("a:8081", nil, WithGossip("127.0.0.1:0", nil))
+	require.NoError(t, err)
+	t.Cleanup(first.Close)
+
+	join := net.JoinHostPort("127.0.0.1", strconv.Itoa(int(first.gossip.list.LocalNode().Port)))
+	second, err := New("b:8081", nil, WithGossip("127.0.0.1:0", []string{join}))
+	require.NoError(t, err)
+	t.Cleanup(second.Close)
+
+	for _, c := range []*Cluster{first, second} {
+		require.Eventually(t, func() bool {
+			return len(c.Peers()) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		require.ElementsMatch(t, []string{"a:8081", "b:8081"}, c.Peers())
+	}
+
+	second.Close()
+	require.Eventually(t, func() bool {
+		return len(first.Peers()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRemoteCheckResolver(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	peer := lis.Addr().String()
+
+	remote, err := New(peer, nil)
+	require.NoError(t, err)
+	t.Cleanup(remote.Close)
+
+	srv := &dispatchServer{requests: make(chan *graph.ResolveCheckRequest, 1), owners: make(chan string, 1), cluster: remote}
+	grpcServer := grpc.NewServer()
+	RegisterDispatchServer(grpcServer, srv)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:              "store",
+		AuthorizationModelID: "model",
+		TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	t.Run("resolves_on_the_tier", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := graph.NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		tier, err := NewTier([]string{peer})
+		require.NoError(t, err)
+		t.Cleanup(tier.Close)
+
+		resolver := NewRemoteCheckResolver(tier)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, "document:1", (<-srv.requests).GetTupleKey().GetObject())
+		<-srv.owners
+	})
+
+	t.Run("resolves_locally_when_the_tier_fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := graph.NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&graph.ResolveCheckResponse{Allowed: false}, nil)
+
+		unused, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, unused.Close())
+
+		tier, err := NewTier([]string{unused.Addr().String()}, WithForwardTimeout(time.Second))
+		require.NoError(t, err)
+		t.Cleanup(tier.Close)
+
+		resolver := NewRemoteCheckResolver(tier)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	_, err = NewTier(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,271 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+)
+
+// DispatchedHeader is the gRPC metadata key set on the Check sub-problems dispatched to another
+// replica.
+const DispatchedHeader = "openfga-cluster-dispatched"
+
+const (
+	dispatchServiceName = "openfga.cluster.v1.DispatchService"
+	resolveCheckMethod  = "/" + dispatchServiceName + "/ResolveCheck"
+
+	// dispatchCodecName is the content-subtype of the dispatch requests. The dispatch service is
+	// internal to the cluster, so its messages are encoded as JSON instead of generated protobufs.
+	dispatchCodecName = "openfga-cluster-json"
+)
+
+func init() {
+	encoding.RegisterCodec(dispatchCodec{})
+}
+
+type dispatchCodec struct{}
+
+func (dispatchCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (dispatchCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (dispatchCodec) Name() string {
+	return dispatchCodecName
+}
+
+// dispatchRequest is a Check sub-problem dispatched to another replica. The protobuf fields are
+// encoded in the protobuf wire format.
+type dispatchRequest struct {
+	StoreID                   string                          `json:"store_id"`
+	AuthorizationModelID      string                          `json:"authorization_model_id"`
+	TupleKey                  []byte                          `json:"tuple_key"`
+	ContextualTuples          [][]byte                        `json:"contextual_tuples,omitempty"`
+	Context                   []byte                          `json:"context,omitempty"`
+	Consistency               openfgav1.ConsistencyPreference `json:"consistency"`
+	LastCacheInvalidationTime time.Time                       `json:"last_cache_invalidation_time"`
+	Depth                     uint32                          `json:"depth"`
+	VisitedPaths              []string                        `json:"visited_paths,omitempty"`
+	SelectedStrategy          string                          `json:"selected_strategy,omitempty"`
+}
+
+// GetStoreId returns the store of the sub-problem, so that the server interceptors tag it.
+func (r *dispatchRequest) GetStoreId() string {
+	return r.StoreID
+}
+
+type dispatchResponse struct {
+	Allowed             bool   `json:"allowed"`
+	DatastoreQueryCount uint32 `json:"datastore_query_count"`
+	DatastoreItemCount  uint64 `json:"datastore_item_count"`
+	CycleDetected       bool   `json:"cycle_detected"`
+}
+
+func newDispatchRequest(req *graph.ResolveCheckRequest) (*dispatchRequest, error) {
+	tupleKey, err := proto.Marshal(req.GetTupleKey())
+	if err != nil {
+		return nil, err
+	}
+
+	contextualTuples := make([][]byte, 0, len(req.GetContextualTuples()))
+	for _, tk := range req.GetContextualTuples() {
+		data, err := proto.Marshal(tk)
+		if err != nil {
+			return nil, err
+		}
+		contextualTuples = append(contextualTuples, data)
+	}
+
+	var reqContext []byte
+	if req.GetContext() != nil {
+		if reqContext, err = proto.Marshal(req.GetContext()); err != nil {
+			return nil, err
+		}
+	}
+
+	visitedPaths := make([]string, 0, len(req.GetVisitedPaths()))
+	for path := range req.GetVisitedPaths() {
+		visitedPaths = append(visitedPaths, path)
+	}
+
+	return &dispatchRequest{
+		StoreID:                   req.GetStoreID(),
+		AuthorizationModelID:      req.GetAuthorizationModelID(),
+		TupleKey:                  tupleKey,
+		ContextualTuples:          contextualTuples,
+		Context:                   reqContext,
+		Consistency:               req.GetConsistency(),
+		LastCacheInvalidationTime: req.GetLastCacheInvalidationTime(),
+		Depth:                     req.GetRequestMetadata().Depth,
+		VisitedPaths:              visitedPaths,
+		SelectedStrategy:          req.GetSelectedStrategy(),
+	}, nil
+}
+
+func (r *dispatchRequest) resolveCheckRequest() (*graph.ResolveCheckRequest, error) {
+	tupleKey := &openfgav1.TupleKey{}
+	if err := proto.Unmarshal(r.TupleKey, tupleKey); err != nil {
+		return nil, err
+	}
+
+	contextualTuples := make([]*openfgav1.TupleKey, 0, len(r.ContextualTuples))
+	for _, data := range r.ContextualTuples {
+		tk := &openfgav1.TupleKey{}
+		if err := proto.Unmarshal(data, tk); err != nil {
+			return nil, err
+		}
+		contextualTuples = append(contextualTuples, tk)
+	}
+
+	var reqContext *structpb.Struct
+	if r.Context != nil {
+		reqContext = &structpb.Struct{}
+		if err := proto.Unmarshal(r.Context, reqContext); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:                   r.StoreID,
+		AuthorizationModelID:      r.AuthorizationModelID,
+		TupleKey:                  tupleKey,
+		ContextualTuples:          contextualTuples,
+		Context:                   reqContext,
+		Consistency:               r.Consistency,
+		LastCacheInvalidationTime: r.LastCacheInvalidationTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req.GetRequestMetadata().Depth = r.Depth
+	req.SelectedStrategy = r.SelectedStrategy
+	for _, path := range r.VisitedPaths {
+		req.VisitedPaths[path] = struct{}{}
+	}
+	return req, nil
+}
+
+// DispatchServer resolves the Check sub-problems dispatched by the other replicas.
+type DispatchServer interface {
+	ResolveDispatchedCheck(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error)
+}
+
+// RegisterDispatchServer registers the service that resolves the Check sub-problems dispatched by
+// the other replicas on registrar.
+func RegisterDispatchServer(registrar grpc.ServiceRegistrar, srv DispatchServer) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: dispatchServiceName,
+		HandlerType: (*DispatchServer)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "ResolveCheck",
+				Handler:    resolveCheckHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "internal/cluster/dispatch.go",
+	}, srv)
+}
+
+func resolveCheckHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &dispatchRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return resolveDispatchedCheck(ctx, srv.(DispatchServer), req.(*dispatchRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: resolveCheckMethod}, handler)
+}
+
+type dispatchedKeyCtxKey struct{}
+
+func resolveDispatchedCheck(ctx context.Context, srv DispatchServer, in *dispatchRequest) (*dispatchResponse, error) {
+	req, err := in.resolveCheckRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	// the sub-problem is resolved here even if the replica disagrees on its owner, e.g. while the
+	// replicas are joining, so that a sub-problem is dispatched at most once
+	ctx = context.WithValue(ctx, dispatchedKeyCtxKey{}, graph.BuildCacheKey(*req))
+
+	resp, err := srv.ResolveDispatchedCheck(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := resp.GetResolutionMetadata()
+	return &dispatchResponse{
+		Allowed:             resp.GetAllowed(),
+		DatastoreQueryCount: metadata.DatastoreQueryCount,
+		DatastoreItemCount:  metadata.DatastoreItemCount,
+		CycleDetected:       metadata.CycleDetected,
+	}, nil
+}
+
+// DispatchOwner returns the address of the replica that owns the Check sub-problem key, or an
+// empty string if the local replica owns it or if it was dispatched to the local replica.
+func (c *Cluster) DispatchOwner(ctx context.Context, key string) string {
+	if dispatched, _ := ctx.Value(dispatchedKeyCtxKey{}).(string); dispatched == key {
+		return ""
+	}
+	if owner := c.owner(key); owner != c.self {
+		return owner
+	}
+	return ""
+}
+
+// Dispatch resolves the Check sub-problem of req on the replica at address peer, with the
+// credentials of the request of ctx.
+func (c *Cluster) Dispatch(ctx context.Context, peer string, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+	if !slices.Contains(*c.peers.Load(), peer) {
+		return nil, fmt.Errorf("unknown replica '%s'", peer)
+	}
+	conn, err := c.conn(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := newDispatchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.forwardTimeout)
+	defer cancel()
+	ctx = outgoingContext(ctx, DispatchedHeader)
+
+	out := &dispatchResponse{}
+	if err := conn.Invoke(ctx, resolveCheckMethod, in, out, grpc.CallContentSubtype(dispatchCodecName)); err != nil {
+		return nil, err
+	}
+
+	return &graph.ResolveCheckResponse{
+		Allowed: out.Allowed,
+		ResolutionMetadata: graph.ResolveCheckResponseMetadata{
+			DatastoreQueryCount: out.DatastoreQueryCount,
+			DatastoreItemCount:  out.DatastoreItemCount,
+			CycleDetected:       out.CycleDetected,
+		},
+	}, nil
+}
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"go.uber.org/zap"
+)
+
+const gossipLeaveTimeout = time.Second
+
+// gossip discovers the replicas of the cluster with the memberlist gossip protocol. Every replica
+// advertises the address of its gRPC server in the metadata of its member.
+type gossip struct {
+	cluster *Cluster
+	list    *memberlist.Memberlist
+
+	mu      sync.Mutex
+	members map[string]string // member name => gRPC address
+}
+
+var (
+	_ memberlist.Delegate      = (*gossip)(nil)
+	_ memberlist.EventDelegate = (*gossip)(nil)
+)
+
+func newGossip(c *Cluster) (*gossip, error) {
+	host, portStr, err := net.SplitHostPort(c.gossipBindAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gossip bind address '%s': %w", c.gossipBindAddress, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gossip bind address '%s': %w", c.gossipBindAddress, err)
+	}
+	if host == "" {
+		host = "0.0.0.0"
+	}
+
+	g := &gossip{
+		cluster: c,
+		members: make(map[string]string),
+	}
+
+	config := memberlist.DefaultLANConfig()
+	config.Name = c.self
+	config.BindAddr = host
+	config.BindPort = port
+	config.AdvertisePort = port
+	config.Delegate = g
+	config.Events = g
+	config.LogOutput = io.Discard
+
+	g.list, err = memberlist.Create(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the gossip on '%s': %w", c.gossipBindAddress, err)
+	}
+
+	if len(c.gossipJoin) > 0 {
+		// the replicas that are not started yet join this one later
+		if _, err := g.list.Join(c.gossipJoin); err != nil {
+			c.logger.Warn("failed to join the cluster through the gossip", zap.Strings("join", c.gossipJoin), zap.Error(err))
+		}
+	}
+
+	return g, nil
+}
+
+// NodeMeta returns the address of the gRPC server of the local replica.
+func (g *gossip) NodeMeta(limit int) []byte {
+	return []byte(g.cluster.self)
+}
+
+// NotifyMsg is a noop, the replicas only exchange their membership.
+func (g *gossip) NotifyMsg([]byte) {}
+
+// GetBroadcasts is a noop, the replicas only exchange their membership.
+func (g *gossip) GetBroadcasts(overhead, limit int) [][]byte {
+	return nil
+}
+
+// LocalState is a noop, the replicas only exchange their membership.
+func (g *gossip) LocalState(join bool) []byte {
+	return nil
+}
+
+// MergeRemoteState is a noop, the replicas only exchange their membership.
+func (g *gossip) MergeRemoteState(buf []byte, join bool) {}
+
+// NotifyJoin adds the replica of the member.
+func (g *gossip) NotifyJoin(node *memberlist.Node) {
+	g.update(node.Name, string(node.Meta))
+}
+
+// NotifyLeave removes the replica of the member.
+func (g *gossip) NotifyLeave(node *memberlist.Node) {
+	g.update(node.Name, "")
+}
+
+// NotifyUpdate updates the address of the replica of the member.
+func (g *gossip) NotifyUpdate(node *memberlist.Node) {
+	g.update(node.Name, string(node.Meta))
+}
+
+// update sets the gRPC address of the member name, or removes it if address is empty, and updates
+// the replicas of the cluster.
+func (g *gossip) update(name, address string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if address == "" {
+		delete(g.members, name)
+	} else {
+		g.members[name] = address
+	}
+
+	discovered := make([]string, 0, len(g.members))
+	for _, address := range g.members {
+		discovered = append(discovered, address)
+	}
+	g.cluster.setPeers(discovered)
+}
+
+// close notifies the other replicas that the local replica leaves, and stops the gossip.
+func (g *gossip) close() {
+	if err := g.list.Leave(gossipLeaveTimeout); err != nil {
+		g.cluster.logger.Warn("failed to leave the cluster", zap.Error(err))
+	}
+	_ = g.list.Shutdown()
+}
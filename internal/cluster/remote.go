@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+var remoteCheckCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_remote_resolution_count",
+	Help:      "The total number of Checks sent to the replicas of the dispatch tier, by outcome: resolved remotely, or resolved locally after a failed dispatch.",
+}, []string{"outcome"})
+
+// RemoteCheckResolverOpt defines an option that can be used to change the behavior of
+// RemoteCheckResolver instance.
+type RemoteCheckResolverOpt func(*RemoteCheckResolver)
+
+// WithRemoteCheckResolverLogger sets the logger of the RemoteCheckResolver.
+func WithRemoteCheckResolverLogger(l logger.Logger) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.logger = l
+	}
+}
+
+// RemoteCheckResolver resolves the Checks on the replicas of a dedicated tier through their
+// dispatch service, so that the replicas serving the API do not resolve the sub-problems of the
+// Checks themselves. Every Check is sent to the replica of the tier that owns it, so that the cache
+// of every replica of the tier specializes in the Checks it owns. The Checks are resolved by the
+// delegate when the tier does not answer.
+type RemoteCheckResolver struct {
+	delegate graph.CheckResolver
+	tier     *Cluster
+	logger   logger.Logger
+}
+
+var _ graph.CheckResolver = (*RemoteCheckResolver)(nil)
+
+// NewRemoteCheckResolver constructs a CheckResolver that resolves the Checks on the replicas of
+// tier. The connections to tier are not closed by Close.
+func NewRemoteCheckResolver(tier *Cluster, opts ...RemoteCheckResolverOpt) *RemoteCheckResolver {
+	r := &RemoteCheckResolver{
+		tier:   tier,
+		logger: logger.NewNoopLogger(),
+	}
+	r.delegate = r
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// SetDelegate sets this RemoteCheckResolver's dispatch delegate.
+func (r *RemoteCheckResolver) SetDelegate(delegate graph.CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this RemoteCheckResolver's dispatch delegate.
+func (r *RemoteCheckResolver) GetDelegate() graph.CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop.
+func (r *RemoteCheckResolver) Close() {}
+
+func (r *RemoteCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *graph.ResolveCheckRequest,
+) (*graph.ResolveCheckResponse, error) {
+	peer := r.tier.owner(graph.BuildCacheKey(*req))
+
+	resp, err := r.tier.Dispatch(ctx, peer, req)
+	if err == nil {
+		remoteCheckCounter.WithLabelValues("remote").Inc()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("dispatch_tier_replica", peer))
+		return resp, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	remoteCheckCounter.WithLabelValues("remote_failed").Inc()
+	r.logger.WarnWithContext(ctx, "failed to resolve check on the dispatch tier, resolving it locally",
+		zap.String("peer", peer),
+		zap.Error(err))
+	return r.delegate.ResolveCheck(ctx, req)
+}
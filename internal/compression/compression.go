@@ -0,0 +1,163 @@
+// Package compression provides the payload compression algorithms that can be negotiated
+// by clients of the OpenFGA gRPC and HTTP servers.
+package compression
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// Gzip is the name of the gzip compression algorithm, as used in the HTTP
+	// Content-Encoding header and the gRPC grpc-encoding header.
+	Gzip = "gzip"
+
+	// Zstd is the name of the Zstandard compression algorithm, as used in the HTTP
+	// Content-Encoding header and the gRPC grpc-encoding header.
+	Zstd = "zstd"
+)
+
+var ErrUnsupportedAlgorithm = errors.New("unsupported compression algorithm")
+
+// Writer is a compressing io.WriteCloser that can be reset to write to a new destination.
+type Writer interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// Validate returns an error if any of the provided algorithms is not supported.
+func Validate(algorithms []string) error {
+	for _, algorithm := range algorithms {
+		if _, ok := writerPools[algorithm]; !ok {
+			return fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, algorithm)
+		}
+	}
+	return nil
+}
+
+var writerPools = map[string]*sync.Pool{
+	Gzip: {
+		New: func() any {
+			return gzip.NewWriter(io.Discard)
+		},
+	},
+	Zstd: {
+		New: func() any {
+			// The only possible error is an invalid option.
+			w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+			return w
+		},
+	},
+}
+
+var zstdDecoderPool = sync.Pool{
+	New: func() any {
+		// The only possible error is an invalid option.
+		d, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		return d
+	},
+}
+
+// GetWriter returns a pooled Writer for the given algorithm that writes to w.
+// The Writer must be handed back with PutWriter once it has been closed.
+func GetWriter(algorithm string, w io.Writer) (Writer, error) {
+	pool, ok := writerPools[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, algorithm)
+	}
+	cw := pool.Get().(Writer)
+	cw.Reset(w)
+	return cw, nil
+}
+
+// PutWriter returns a Writer obtained with GetWriter to its pool.
+func PutWriter(algorithm string, cw Writer) {
+	if pool, ok := writerPools[algorithm]; ok {
+		cw.Reset(io.Discard)
+		pool.Put(cw)
+	}
+}
+
+// RegisterGRPCCompressors registers the given algorithms as gRPC compressors so that
+// the server decompresses requests and compresses responses for clients that ask for them.
+// gRPC compressors are registered globally, and registering one is not reversible.
+func RegisterGRPCCompressors(algorithms []string) error {
+	if err := Validate(algorithms); err != nil {
+		return err
+	}
+	for _, algorithm := range algorithms {
+		encoding.RegisterCompressor(&grpcCompressor{name: algorithm})
+	}
+	return nil
+}
+
+// grpcCompressor adapts the pooled writers to the gRPC encoding.Compressor interface.
+type grpcCompressor struct {
+	name string
+}
+
+var _ encoding.Compressor = (*grpcCompressor)(nil)
+
+func (c *grpcCompressor) Name() string {
+	return c.name
+}
+
+func (c *grpcCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	cw, err := GetWriter(c.name, w)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledWriteCloser{Writer: cw, algorithm: c.name}, nil
+}
+
+func (c *grpcCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	switch c.name {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		d := zstdDecoderPool.Get().(*zstd.Decoder)
+		if err := d.Reset(r); err != nil {
+			zstdDecoderPool.Put(d)
+			return nil, err
+		}
+		return &pooledZstdReader{Decoder: d}, nil
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, c.name)
+	}
+}
+
+type pooledWriteCloser struct {
+	Writer
+	algorithm string
+}
+
+func (p *pooledWriteCloser) Close() error {
+	err := p.Writer.Close()
+	PutWriter(p.algorithm, p.Writer)
+	return err
+}
+
+// pooledZstdReader returns its decoder to the pool once the stream has been fully read.
+type pooledZstdReader struct {
+	*zstd.Decoder
+}
+
+func (p *pooledZstdReader) Read(b []byte) (int, error) {
+	if p.Decoder == nil {
+		return 0, io.EOF
+	}
+	n, err := p.Decoder.Read(b)
+	if errors.Is(err, io.EOF) {
+		_ = p.Decoder.Reset(nil)
+		zstdDecoderPool.Put(p.Decoder)
+		p.Decoder = nil
+	}
+	return n, err
+}
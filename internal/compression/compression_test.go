@@ -0,0 +1,44 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(nil))
+	require.NoError(t, Validate([]string{Gzip, Zstd}))
+	require.ErrorIs(t, Validate([]string{Gzip, "br"}), ErrUnsupportedAlgorithm)
+}
+
+func TestGRPCCompressorRoundTrip(t *testing.T) {
+	payload := strings.Repeat("document:1#viewer@user:anne\n", 200)
+
+	for _, algorithm := range []string{Gzip, Zstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			c := &grpcCompressor{name: algorithm}
+			require.Equal(t, algorithm, c.Name())
+
+			// Run twice to exercise pooled writers and readers.
+			for i := 0; i < 2; i++ {
+				var buf bytes.Buffer
+				w, err := c.Compress(&buf)
+				require.NoError(t, err)
+				_, err = io.WriteString(w, payload)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+				require.Less(t, buf.Len(), len(payload))
+
+				r, err := c.Decompress(&buf)
+				require.NoError(t, err)
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.Equal(t, payload, string(data))
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/cel-go/cel"
@@ -12,6 +13,8 @@ import (
 	celtypes "github.com/google/cel-go/common/types"
 	"go.opentelemetry.io/otel"
 	"golang.org/x/exp/maps"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -22,7 +25,11 @@ import (
 
 var tracer = otel.Tracer("openfga/internal/condition")
 
-var celBaseEnv *cel.Env
+// celBaseEnv is the CEL environment that the environments of the conditions extend.
+var celBaseEnv atomic.Pointer[cel.Env]
+
+// registerMu serializes the extensions of celBaseEnv.
+var registerMu sync.Mutex
 
 func init() {
 	var envOpts []cel.EnvOption
@@ -37,7 +44,24 @@ func init() {
 		panic(fmt.Sprintf("failed to construct CEL base env: %v", err))
 	}
 
-	celBaseEnv = env
+	celBaseEnv.Store(env)
+}
+
+// RegisterEnvOptions extends the base CEL environment of the conditions with opts, e.g. with
+// additional functions or types. The conditions compiled afterward, including the ones validated
+// when writing a model, can use them. It is meant to be called before the server starts, since
+// the conditions that were already compiled are not affected.
+func RegisterEnvOptions(opts ...cel.EnvOption) error {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	env, err := celBaseEnv.Load().Extend(opts...)
+	if err != nil {
+		return fmt.Errorf("failed to extend CEL base env: %w", err)
+	}
+
+	celBaseEnv.Store(env)
+	return nil
 }
 
 var emptyEvaluationResult = EvaluationResult{}
@@ -57,8 +81,12 @@ type EvaluableCondition struct {
 
 	celProgramOpts []cel.ProgramOption
 	celEnv         *cel.Env
+	celAst         *cel.Ast
 	celProgram     cel.Program
 	compileOnce    sync.Once
+
+	// checkedExpr is the checked expression that the condition is compiled from, if any.
+	checkedExpr []byte
 }
 
 // Compile compiles a condition expression with a CEL environment
@@ -105,7 +133,7 @@ func (e *EvaluableCondition) compile() error {
 		envOpts = append(envOpts, cel.Variable(paramName, paramType.CelType()))
 	}
 
-	env, err := celBaseEnv.Extend(envOpts...)
+	env, err := celBaseEnv.Load().Extend(envOpts...)
 	if err != nil {
 		return &CompilationError{
 			Condition: e.Name,
@@ -113,13 +141,17 @@ func (e *EvaluableCondition) compile() error {
 		}
 	}
 
-	source := common.NewStringSource(e.Expression, e.Name)
-	ast, issues := env.CompileSource(source)
-	if issues != nil {
-		if err = issues.Err(); err != nil {
-			return &CompilationError{
-				Condition: e.Name,
-				Cause:     err,
+	ast := e.checkedAst()
+	if ast == nil {
+		source := common.NewStringSource(e.Expression, e.Name)
+		var issues *cel.Issues
+		ast, issues = env.CompileSource(source)
+		if issues != nil {
+			if err = issues.Err(); err != nil {
+				return &CompilationError{
+					Condition: e.Name,
+					Cause:     err,
+				}
 			}
 		}
 	}
@@ -142,10 +174,40 @@ func (e *EvaluableCondition) compile() error {
 	}
 
 	e.celEnv = env
+	e.celAst = ast
 	e.celProgram = prg
 	return nil
 }
 
+// checkedAst returns the AST of the checked expression of the condition, or nil if it has none or
+// if it can't be decoded, in which case the condition is compiled from its source.
+func (e *EvaluableCondition) checkedAst() *cel.Ast {
+	if len(e.checkedExpr) == 0 {
+		return nil
+	}
+
+	var checked exprpb.CheckedExpr
+	if err := proto.Unmarshal(e.checkedExpr, &checked); err != nil {
+		return nil
+	}
+	return cel.CheckedExprToAst(&checked)
+}
+
+// CheckedExpression returns the parsed and type-checked expression of the compiled condition,
+// which WithCheckedExpression compiles the condition from without parsing and checking its source
+// again. It returns an error if the condition is not compiled.
+func (e *EvaluableCondition) CheckedExpression() ([]byte, error) {
+	if e.celAst == nil {
+		return nil, fmt.Errorf("condition '%s' is not compiled", e.Name)
+	}
+
+	checked, err := cel.AstToCheckedExpr(e.celAst)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(checked)
+}
+
 // CastContextToTypedParameters converts the provided context to typed condition
 // parameters and returns an error if any additional context fields are provided
 // that are not defined by the evaluable condition.
@@ -315,6 +377,15 @@ func (e *EvaluableCondition) WithInterruptCheckFrequency(checkFrequency uint) *E
 	return e
 }
 
+// WithCheckedExpression compiles the condition from checked, as returned by CheckedExpression,
+// e.g. by another instance of OpenFGA, instead of parsing and checking its source. The checked
+// expression must be the one of the condition, since it replaces its source. If it can't be
+// decoded, the condition is compiled from its source.
+func (e *EvaluableCondition) WithCheckedExpression(checked []byte) *EvaluableCondition {
+	e.checkedExpr = checked
+	return e
+}
+
 // NewUncompiled returns a new EvaluableCondition that has not
 // validated and compiled its expression.
 func NewUncompiled(condition *openfgav1.Condition) *EvaluableCondition {
@@ -109,6 +109,46 @@ func TestNewCompiled(t *testing.T) {
 	}
 }
 
+func TestCheckedExpression(t *testing.T) {
+	cond := &openfgav1.Condition{
+		Name:       "condition1",
+		Expression: "param1 == 'ok'",
+		Parameters: map[string]*openfgav1.ConditionParamTypeRef{
+			"param1": {
+				TypeName: openfgav1.ConditionParamTypeRef_TYPE_NAME_STRING,
+			},
+		},
+	}
+
+	_, err := condition.NewUncompiled(cond).CheckedExpression()
+	require.Error(t, err)
+
+	compiled, err := condition.NewCompiled(cond)
+	require.NoError(t, err)
+	checked, err := compiled.CheckedExpression()
+	require.NoError(t, err)
+	require.NotEmpty(t, checked)
+
+	// the source is not parsed: an invalid one shows that the checked expression is compiled
+	fromChecked := condition.NewUncompiled(&openfgav1.Condition{
+		Name:       cond.GetName(),
+		Expression: "invalid",
+		Parameters: cond.GetParameters(),
+	}).WithCheckedExpression(checked)
+	require.NoError(t, fromChecked.Compile())
+
+	for value, met := range map[string]bool{"ok": true, "ko": false} {
+		contextStruct, err := structpb.NewStruct(map[string]interface{}{"param1": value})
+		require.NoError(t, err)
+		result, err := fromChecked.Evaluate(context.Background(), contextStruct.GetFields())
+		require.NoError(t, err)
+		require.Equal(t, met, result.ConditionMet)
+	}
+
+	// a checked expression that can't be decoded falls back to the source
+	require.NoError(t, condition.NewUncompiled(cond).WithCheckedExpression([]byte("invalid")).Compile())
+}
+
 func TestEvaluate(t *testing.T) {
 	var tests = []struct {
 		name      string
@@ -0,0 +1,52 @@
+package condition
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// ErrUnknownExtension is returned when an extension of the conditions is not one of Extensions.
+var ErrUnknownExtension = errors.New("unknown condition extension")
+
+// Extensions are the optional CEL libraries that operators can make available to the conditions.
+// They only contain pure functions whose evaluation cost is tracked, so that they are safe to
+// enable without any code.
+var Extensions = map[string]func() cel.EnvOption{
+	"strings":  func() cel.EnvOption { return ext.Strings() },
+	"math":     func() cel.EnvOption { return ext.Math() },
+	"lists":    func() cel.EnvOption { return ext.Lists() },
+	"sets":     func() cel.EnvOption { return ext.Sets() },
+	"encoders": func() cel.EnvOption { return ext.Encoders() },
+}
+
+// ExtensionNames returns the sorted names of the Extensions.
+func ExtensionNames() []string {
+	names := make([]string, 0, len(Extensions))
+	for name := range Extensions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RegisterExtensions makes the Extensions names available to the conditions. The models whose
+// conditions use an extension cannot be evaluated once the extension is no longer registered.
+func RegisterExtensions(names []string) error {
+	opts := make([]cel.EnvOption, 0, len(names))
+	for _, name := range names {
+		extension, ok := Extensions[name]
+		if !ok {
+			return fmt.Errorf("%w '%s': expected one of %v", ErrUnknownExtension, name, ExtensionNames())
+		}
+		opts = append(opts, extension())
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+
+	return RegisterEnvOptions(opts...)
+}
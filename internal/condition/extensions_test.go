@@ -0,0 +1,92 @@
+package condition_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/condition"
+)
+
+func TestRegisterExtensions(t *testing.T) {
+	splitCondition := &openfgav1.Condition{
+		Name:       "condition1",
+		Expression: `"b" in param1.split(",")`,
+		Parameters: map[string]*openfgav1.ConditionParamTypeRef{
+			"param1": {
+				TypeName: openfgav1.ConditionParamTypeRef_TYPE_NAME_STRING,
+			},
+		},
+	}
+
+	_, err := condition.NewCompiled(splitCondition)
+	require.ErrorContains(t, err, "undeclared reference to 'split'")
+
+	t.Run("unknown_extension", func(t *testing.T) {
+		err := condition.RegisterExtensions([]string{"strings", "unknown"})
+		require.ErrorIs(t, err, condition.ErrUnknownExtension)
+
+		// none of the extensions are registered if one of them is unknown
+		_, err = condition.NewCompiled(splitCondition)
+		require.Error(t, err)
+	})
+
+	require.NoError(t, condition.RegisterExtensions(nil))
+	require.NoError(t, condition.RegisterExtensions([]string{"strings"}))
+	// registering an extension twice is a no-op
+	require.NoError(t, condition.RegisterExtensions([]string{"strings"}))
+
+	compiledCondition, err := condition.NewCompiled(splitCondition)
+	require.NoError(t, err)
+
+	contextStruct, err := structpb.NewStruct(map[string]interface{}{"param1": "a,b,c"})
+	require.NoError(t, err)
+
+	result, err := compiledCondition.Evaluate(context.Background(), contextStruct.GetFields())
+	require.NoError(t, err)
+	require.True(t, result.ConditionMet)
+}
+
+func TestRegisterEnvOptions(t *testing.T) {
+	err := condition.RegisterEnvOptions(cel.Function("test_has_prefix",
+		cel.Overload("test_has_prefix_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+				return types.Bool(strings.HasPrefix(string(lhs.(types.String)), string(rhs.(types.String))))
+			}),
+		),
+	))
+	require.NoError(t, err)
+
+	compiledCondition, err := condition.NewCompiled(&openfgav1.Condition{
+		Name:       "condition1",
+		Expression: `test_has_prefix(param1, "eu-")`,
+		Parameters: map[string]*openfgav1.ConditionParamTypeRef{
+			"param1": {
+				TypeName: openfgav1.ConditionParamTypeRef_TYPE_NAME_STRING,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	contextStruct, err := structpb.NewStruct(map[string]interface{}{"param1": "eu-west-1"})
+	require.NoError(t, err)
+
+	result, err := compiledCondition.Evaluate(context.Background(), contextStruct.GetFields())
+	require.NoError(t, err)
+	require.True(t, result.ConditionMet)
+
+	t.Run("conflicting_overload", func(t *testing.T) {
+		err := condition.RegisterEnvOptions(cel.Function("test_has_prefix",
+			cel.Overload("test_has_prefix_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.IntType),
+		))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+// Package fieldmask prunes protobuf messages down to a set of requested field paths,
+// following the semantics of google.protobuf.FieldMask.
+package fieldmask
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Mask is a validated set of field paths that can be applied to messages of a single type.
+type Mask struct {
+	root *node
+}
+
+type node struct {
+	children map[protoreflect.Name]*node
+}
+
+// Parse parses a comma-separated list of field paths (e.g. "key.object,key.relation") and
+// validates them against the descriptor of msg. An empty string returns a nil Mask, which
+// keeps every field.
+func Parse(paths string, msg proto.Message) (*Mask, error) {
+	if strings.TrimSpace(paths) == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			fields = append(fields, path)
+		}
+	}
+
+	fm, err := fieldmaskpb.New(msg, fields...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field mask for '%s': %w", msg.ProtoReflect().Descriptor().FullName(), err)
+	}
+	fm.Normalize()
+
+	root := &node{}
+	for _, path := range fm.GetPaths() {
+		current := root
+		for _, name := range strings.Split(path, ".") {
+			if current.children == nil {
+				current.children = make(map[protoreflect.Name]*node)
+			}
+			child, ok := current.children[protoreflect.Name(name)]
+			if !ok {
+				child = &node{}
+				current.children[protoreflect.Name(name)] = child
+			}
+			current = child
+		}
+	}
+
+	return &Mask{root: root}, nil
+}
+
+// Apply clears every field of msg that is not covered by the mask. A nil Mask is a no-op.
+func (m *Mask) Apply(msg proto.Message) {
+	if m == nil || msg == nil {
+		return
+	}
+	prune(msg.ProtoReflect(), m.root)
+}
+
+func prune(msg protoreflect.Message, n *node) {
+	if n == nil || len(n.children) == 0 {
+		// A leaf path keeps the whole sub-message.
+		return
+	}
+
+	var cleared []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		child, ok := n.children[fd.Name()]
+		switch {
+		case !ok:
+			cleared = append(cleared, fd)
+		case fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			prune(v.Message(), child)
+		}
+		return true
+	})
+
+	for _, fd := range cleared {
+		msg.Clear(fd)
+	}
+}
@@ -0,0 +1,86 @@
+package fieldmask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("empty_paths_returns_nil_mask", func(t *testing.T) {
+		mask, err := Parse(" ", &openfgav1.Tuple{})
+		require.NoError(t, err)
+		require.Nil(t, mask)
+	})
+
+	t.Run("invalid_path", func(t *testing.T) {
+		_, err := Parse("key.unknown", &openfgav1.Tuple{})
+		require.ErrorContains(t, err, "invalid field mask for 'openfga.v1.Tuple'")
+	})
+
+	t.Run("valid_paths", func(t *testing.T) {
+		mask, err := Parse("key.object, key.relation", &openfgav1.Tuple{})
+		require.NoError(t, err)
+		require.NotNil(t, mask)
+	})
+}
+
+func TestApply(t *testing.T) {
+	newTuple := func() *openfgav1.Tuple {
+		condition := &openfgav1.RelationshipCondition{Name: "cond", Context: &structpb.Struct{}}
+		return &openfgav1.Tuple{
+			Key:       tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", condition.GetName(), condition.GetContext()),
+			Timestamp: timestamppb.Now(),
+		}
+	}
+
+	tests := map[string]struct {
+		paths    string
+		expected func(*openfgav1.Tuple) *openfgav1.Tuple
+	}{
+		`nested_fields`: {
+			paths: "key.object,key.relation",
+			expected: func(t *openfgav1.Tuple) *openfgav1.Tuple {
+				return &openfgav1.Tuple{Key: &openfgav1.TupleKey{Object: t.GetKey().GetObject(), Relation: t.GetKey().GetRelation()}}
+			},
+		},
+		`whole_sub_message`: {
+			paths: "key",
+			expected: func(t *openfgav1.Tuple) *openfgav1.Tuple {
+				return &openfgav1.Tuple{Key: t.GetKey()}
+			},
+		},
+		`redundant_paths_are_normalized`: {
+			paths: "key,key.user,timestamp",
+			expected: func(t *openfgav1.Tuple) *openfgav1.Tuple {
+				return t
+			},
+		},
+		`no_mask`: {
+			paths: "",
+			expected: func(t *openfgav1.Tuple) *openfgav1.Tuple {
+				return t
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mask, err := Parse(test.paths, &openfgav1.Tuple{})
+			require.NoError(t, err)
+
+			original := newTuple()
+			actual := proto.Clone(original).(*openfgav1.Tuple)
+			mask.Apply(actual)
+
+			require.True(t, proto.Equal(test.expected(original), actual), "got %v", actual)
+		})
+	}
+}
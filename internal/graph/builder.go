@@ -10,6 +10,16 @@ type CheckResolverOrderedBuilder struct {
 	cachedCheckResolverOptions             []CachedCheckResolverOpt
 	dispatchThrottlingCheckResolverEnabled bool
 	dispatchThrottlingCheckResolverOptions []DispatchThrottlingCheckResolverOpt
+	hotPathIndex                           HotPathIndex
+	materializedPermissions                MaterializedPermissions
+	clusterPeers                           ClusterPeers
+	clusterSingleflightOptions             []ClusterSingleflightCheckResolverOpt
+	clusterDispatcher                      CheckDispatcher
+	clusterDispatchOptions                 []ClusterDispatchCheckResolverOpt
+	remoteCheckResolver                    CheckResolver
+	storeBulkheads                         *StoreBulkheads
+	checkCoalescer                         *CheckCoalescer
+	customResolvers                        []CustomCheckResolver
 }
 
 type CheckResolverOrderedBuilderOpt func(checkResolver *CheckResolverOrderedBuilder)
@@ -55,6 +65,74 @@ func WithDispatchThrottlingCheckResolverOpts(enabled bool, opts ...DispatchThrot
 	}
 }
 
+// WithHotPathIndex adds a HotPathCheckResolver backed by index at the head of the list.
+// A nil index leaves it out.
+func WithHotPathIndex(index HotPathIndex) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.hotPathIndex = index
+	}
+}
+
+// WithMaterializedPermissions adds a MaterializedCheckResolver backed by permissions at the head of
+// the list, before the HotPathCheckResolver. A nil permissions leaves it out.
+func WithMaterializedPermissions(permissions MaterializedPermissions) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.materializedPermissions = permissions
+	}
+}
+
+// WithClusterSingleflight adds a ClusterSingleflightCheckResolver that forwards the Checks to the
+// replica of peers that owns them after the CachedCheckResolver. A nil peers leaves it out.
+func WithClusterSingleflight(peers ClusterPeers, opts ...ClusterSingleflightCheckResolverOpt) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.clusterPeers = peers
+		r.clusterSingleflightOptions = opts
+	}
+}
+
+// WithClusterDispatch adds a ClusterDispatchCheckResolver that dispatches the sub-problems to the
+// replica of dispatcher that owns them before the CachedCheckResolver, so that only the owner caches
+// them. A nil dispatcher leaves it out.
+func WithClusterDispatch(dispatcher CheckDispatcher, opts ...ClusterDispatchCheckResolverOpt) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.clusterDispatcher = dispatcher
+		r.clusterDispatchOptions = opts
+	}
+}
+
+// WithRemoteCheckResolver adds a CheckResolver that resolves the Checks remotely before the
+// LocalChecker, which resolves the Checks the remote resolver delegates to it. A nil resolver leaves
+// it out.
+func WithRemoteCheckResolver(resolver CheckResolver) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.remoteCheckResolver = resolver
+	}
+}
+
+// WithStoreBulkheads adds a BulkheadCheckResolver backed by bulkheads after the CachedCheckResolver,
+// so that the cached Checks never wait. A nil bulkheads leaves it out.
+func WithStoreBulkheads(bulkheads *StoreBulkheads) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.storeBulkheads = bulkheads
+	}
+}
+
+// WithCheckCoalescer adds a CoalescingCheckResolver backed by coalescer after the
+// CachedCheckResolver and before the BulkheadCheckResolver, so that the cached Checks never wait and
+// the merged Checks take one slot of the bulkhead of their store. A nil coalescer leaves it out.
+func WithCheckCoalescer(coalescer *CheckCoalescer) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.checkCoalescer = coalescer
+	}
+}
+
+// WithCustomCheckResolvers inserts the resolvers at their position in the list.
+func WithCustomCheckResolvers(resolvers ...CustomCheckResolver) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.customResolvers = append(r.customResolvers, resolvers...)
+	}
+}
+
 func NewOrderedCheckResolvers(opts ...CheckResolverOrderedBuilderOpt) *CheckResolverOrderedBuilder {
 	checkResolverBuilder := &CheckResolverOrderedBuilder{}
 	for _, opt := range opts {
@@ -73,6 +151,20 @@ func NewOrderedCheckResolvers(opts ...CheckResolverOrderedBuilderOpt) *CheckReso
 func (c *CheckResolverOrderedBuilder) Build() (CheckResolver, CheckResolverCloser, error) {
 	c.resolvers = []CheckResolver{}
 
+	c.appendCustomResolvers(CheckResolverPositionFirst)
+
+	if c.materializedPermissions != nil {
+		c.resolvers = append(c.resolvers, NewMaterializedCheckResolver(c.materializedPermissions))
+	}
+
+	if c.hotPathIndex != nil {
+		c.resolvers = append(c.resolvers, NewHotPathCheckResolver(c.hotPathIndex))
+	}
+
+	if c.clusterDispatcher != nil {
+		c.resolvers = append(c.resolvers, NewClusterDispatchCheckResolver(c.clusterDispatcher, c.clusterDispatchOptions...))
+	}
+
 	if c.cachedCheckResolverEnabled {
 		cachedCheckResolver, err := NewCachedCheckResolver(c.cachedCheckResolverOptions...)
 		if err != nil {
@@ -82,10 +174,30 @@ func (c *CheckResolverOrderedBuilder) Build() (CheckResolver, CheckResolverClose
 		c.resolvers = append(c.resolvers, cachedCheckResolver)
 	}
 
+	if c.checkCoalescer != nil {
+		c.resolvers = append(c.resolvers, NewCoalescingCheckResolver(c.checkCoalescer))
+	}
+
+	if c.storeBulkheads != nil {
+		c.resolvers = append(c.resolvers, NewBulkheadCheckResolver(c.storeBulkheads))
+	}
+
+	c.appendCustomResolvers(CheckResolverPositionAfterCache)
+
+	if c.clusterPeers != nil {
+		c.resolvers = append(c.resolvers, NewClusterSingleflightCheckResolver(c.clusterPeers, c.clusterSingleflightOptions...))
+	}
+
 	if c.dispatchThrottlingCheckResolverEnabled {
 		c.resolvers = append(c.resolvers, NewDispatchThrottlingCheckResolver(c.dispatchThrottlingCheckResolverOptions...))
 	}
 
+	if c.remoteCheckResolver != nil {
+		c.resolvers = append(c.resolvers, c.remoteCheckResolver)
+	}
+
+	c.appendCustomResolvers(CheckResolverPositionLast)
+
 	if c.shadowResolverEnabled {
 		main := NewLocalChecker(c.localCheckerOptions...)
 		shadow := NewLocalChecker(c.shadowLocalCheckerOptions...)
@@ -105,6 +217,15 @@ func (c *CheckResolverOrderedBuilder) Build() (CheckResolver, CheckResolverClose
 	return c.resolvers[0], c.close, nil
 }
 
+// appendCustomResolvers constructs the custom resolvers at position and appends them to the list.
+func (c *CheckResolverOrderedBuilder) appendCustomResolvers(position CheckResolverPosition) {
+	for _, resolver := range c.customResolvers {
+		if resolver.Position == position {
+			c.resolvers = append(c.resolvers, resolver.New())
+		}
+	}
+}
+
 // close will ensure all the CheckResolver constructed are closed.
 func (c *CheckResolverOrderedBuilder) close() {
 	for _, resolver := range c.resolvers {
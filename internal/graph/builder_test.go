@@ -13,6 +13,13 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 		CachedCheckResolverEnabled             bool
 		DispatchThrottlingCheckResolverEnabled bool
 		ShadowResolverEnabled                  bool
+		HotPathIndex                           HotPathIndex
+		MaterializedPermissions                MaterializedPermissions
+		ClusterPeers                           ClusterPeers
+		ClusterDispatcher                      CheckDispatcher
+		RemoteCheckResolver                    CheckResolver
+		StoreBulkheads                         *StoreBulkheads
+		CustomResolvers                        []CustomCheckResolver
 		expectedResolverOrder                  []CheckResolver
 	}
 
@@ -44,6 +51,57 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 			ShadowResolverEnabled:                  true,
 			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &DispatchThrottlingCheckResolver{}, &ShadowResolver{main: &LocalChecker{}, shadow: &LocalChecker{}}},
 		},
+		{
+			name:                       "when_hot_path_and_cache_are_enabled",
+			CachedCheckResolverEnabled: true,
+			HotPathIndex:               &fakeHotPathIndex{},
+			expectedResolverOrder:      []CheckResolver{&HotPathCheckResolver{}, &CachedCheckResolver{}, &LocalChecker{}},
+		},
+		{
+			name:                       "when_materialized_permissions_and_hot_path_are_enabled",
+			CachedCheckResolverEnabled: true,
+			HotPathIndex:               &fakeHotPathIndex{},
+			MaterializedPermissions:    &fakeMaterializedPermissions{},
+			expectedResolverOrder:      []CheckResolver{&MaterializedCheckResolver{}, &HotPathCheckResolver{}, &CachedCheckResolver{}, &LocalChecker{}},
+		},
+		{
+			name:                                   "when_cluster_singleflight_is_enabled",
+			CachedCheckResolverEnabled:             true,
+			DispatchThrottlingCheckResolverEnabled: true,
+			ClusterPeers:                           &fakeClusterPeers{},
+			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &ClusterSingleflightCheckResolver{}, &DispatchThrottlingCheckResolver{}, &LocalChecker{}},
+		},
+		{
+			name:                       "when_cluster_dispatch_is_enabled",
+			CachedCheckResolverEnabled: true,
+			ClusterDispatcher:          &fakeCheckDispatcher{},
+			expectedResolverOrder:      []CheckResolver{&ClusterDispatchCheckResolver{}, &CachedCheckResolver{}, &LocalChecker{}},
+		},
+		{
+			name:                                   "when_remote_resolver_is_set",
+			CachedCheckResolverEnabled:             true,
+			DispatchThrottlingCheckResolverEnabled: true,
+			RemoteCheckResolver:                    &ClusterDispatchCheckResolver{},
+			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &DispatchThrottlingCheckResolver{}, &ClusterDispatchCheckResolver{}, &LocalChecker{}},
+		},
+		{
+			name:                                   "when_store_bulkheads_are_set",
+			CachedCheckResolverEnabled:             true,
+			DispatchThrottlingCheckResolverEnabled: true,
+			StoreBulkheads:                         NewStoreBulkheads(1, nil),
+			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &BulkheadCheckResolver{}, &DispatchThrottlingCheckResolver{}, &LocalChecker{}},
+		},
+		{
+			name:                                   "when_custom_resolvers_are_set",
+			CachedCheckResolverEnabled:             true,
+			DispatchThrottlingCheckResolverEnabled: true,
+			CustomResolvers: []CustomCheckResolver{
+				{Position: CheckResolverPositionLast, New: CheckResolverMiddleware(nil)},
+				{Position: CheckResolverPositionAfterCache, New: func() CheckResolver { return NewHotPathCheckResolver(&fakeHotPathIndex{}) }},
+				{Position: CheckResolverPositionFirst, New: CheckResolverMiddleware(nil)},
+			},
+			expectedResolverOrder: []CheckResolver{&MiddlewareCheckResolver{}, &CachedCheckResolver{}, &HotPathCheckResolver{}, &DispatchThrottlingCheckResolver{}, &MiddlewareCheckResolver{}, &LocalChecker{}},
+		},
 	}
 
 	for _, test := range tests {
@@ -52,11 +110,19 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 				WithCachedCheckResolverOpts(test.CachedCheckResolverEnabled),
 				WithDispatchThrottlingCheckResolverOpts(test.DispatchThrottlingCheckResolverEnabled),
 				WithShadowResolverEnabled(test.ShadowResolverEnabled),
+				WithHotPathIndex(test.HotPathIndex),
+				WithMaterializedPermissions(test.MaterializedPermissions),
+				WithClusterSingleflight(test.ClusterPeers),
+				WithClusterDispatch(test.ClusterDispatcher),
+				WithRemoteCheckResolver(test.RemoteCheckResolver),
+				WithStoreBulkheads(test.StoreBulkheads),
+				WithCustomCheckResolvers(test.CustomResolvers...),
 			}...)
 			checkResolver, checkResolverCloser, err := builder.Build()
 			require.NoError(t, err)
 			t.Cleanup(checkResolverCloser)
 
+			require.Len(t, builder.resolvers, len(test.expectedResolverOrder))
 			for i, resolver := range builder.resolvers {
 				require.Equal(t, reflect.TypeOf(test.expectedResolverOrder[i]), reflect.TypeOf(resolver))
 			}
@@ -0,0 +1,201 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+var storeBulkheadWaitHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace:                       build.ProjectName,
+	Name:                            "check_store_bulkhead_wait_duration_ms",
+	Help:                            "The time in milliseconds that the Checks waited for the bulkhead of their store, when it was full.",
+	Buckets:                         []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+	NativeHistogramBucketFactor:     1.1,
+	NativeHistogramMaxBucketNumber:  100,
+	NativeHistogramMinResetDuration: time.Hour,
+})
+
+// ErrInvalidStoreBulkheadLimit is returned when a per-store bulkhead limit cannot be parsed.
+var ErrInvalidStoreBulkheadLimit = errors.New("invalid store bulkhead limit")
+
+// ParseStoreBulkheadLimits parses per-store bulkhead limits in the form <store_id>=<limit>, e.g.
+// 01ARZ3NDEKTSV4RRFFQ69G5FAV=200. A limit of 0 lifts the bound of the store.
+func ParseStoreBulkheadLimits(values []string) (map[string]int, error) {
+	limits := make(map[string]int, len(values))
+	for _, value := range values {
+		storeID, rawLimit, ok := strings.Cut(value, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("%w '%s': expected <store_id>=<limit>", ErrInvalidStoreBulkheadLimit, value)
+		}
+
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("%w '%s': invalid limit '%s'", ErrInvalidStoreBulkheadLimit, value, rawLimit)
+		}
+		limits[storeID] = limit
+	}
+	return limits, nil
+}
+
+// StoreBulkheads bounds the number of Checks of every store resolved at once, so that a burst of
+// Checks on a store cannot take all the resources of the server. It is shared by the CheckResolvers
+// built for every request.
+type StoreBulkheads struct {
+	defaultLimit int
+	storeLimits  map[string]int
+
+	mu     sync.Mutex
+	stores map[string]*storeBulkhead
+}
+
+type storeBulkhead struct {
+	inFlight int
+	waiters  []chan struct{}
+}
+
+// NewStoreBulkheads constructs StoreBulkheads that let defaultLimit Checks of every store be
+// resolved at once, or the limit in storeLimits for the stores it lists. A limit of 0 lifts the bound.
+func NewStoreBulkheads(defaultLimit int, storeLimits map[string]int) *StoreBulkheads {
+	return &StoreBulkheads{
+		defaultLimit: defaultLimit,
+		storeLimits:  storeLimits,
+		stores:       make(map[string]*storeBulkhead),
+	}
+}
+
+func (b *StoreBulkheads) limit(storeID string) int {
+	if limit, ok := b.storeLimits[storeID]; ok {
+		return limit
+	}
+	return b.defaultLimit
+}
+
+// acquire waits until a Check of storeID can be resolved, or until ctx is done. It returns true as
+// its second value if the Check had to wait. The returned function must be called when the Check is
+// resolved.
+func (b *StoreBulkheads) acquire(ctx context.Context, storeID string) (func(), bool, error) {
+	limit := b.limit(storeID)
+	if limit <= 0 {
+		return func() {}, false, nil
+	}
+
+	b.mu.Lock()
+	bulkhead, ok := b.stores[storeID]
+	if !ok {
+		bulkhead = &storeBulkhead{}
+		b.stores[storeID] = bulkhead
+	}
+	if bulkhead.inFlight < limit {
+		bulkhead.inFlight++
+		b.mu.Unlock()
+		return func() { b.release(storeID) }, false, nil
+	}
+
+	ready := make(chan struct{})
+	bulkhead.waiters = append(bulkhead.waiters, ready)
+	b.mu.Unlock()
+
+	select {
+	case <-ready:
+		return func() { b.release(storeID) }, true, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, waiter := range bulkhead.waiters {
+			if waiter == ready {
+				bulkhead.waiters = append(bulkhead.waiters[:i], bulkhead.waiters[i+1:]...)
+				return nil, true, ctx.Err()
+			}
+		}
+		// the Check was let in at the same time, hand its slot over
+		b.releaseLocked(storeID)
+		return nil, true, ctx.Err()
+	}
+}
+
+func (b *StoreBulkheads) release(storeID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.releaseLocked(storeID)
+}
+
+// releaseLocked hands the slot of a resolved Check over to the first waiting Check of the store, if
+// any. It must be called with b.mu held.
+func (b *StoreBulkheads) releaseLocked(storeID string) {
+	bulkhead := b.stores[storeID]
+	if len(bulkhead.waiters) > 0 {
+		close(bulkhead.waiters[0])
+		bulkhead.waiters = bulkhead.waiters[1:]
+		return
+	}
+
+	bulkhead.inFlight--
+	if bulkhead.inFlight == 0 {
+		delete(b.stores, storeID)
+	}
+}
+
+// BulkheadCheckResolver bounds the number of Checks of every store resolved at once with
+// StoreBulkheads. Only the Checks of the requests count, their sub-problems are resolved within
+// the slot of their Check.
+type BulkheadCheckResolver struct {
+	delegate  CheckResolver
+	bulkheads *StoreBulkheads
+}
+
+var _ CheckResolver = (*BulkheadCheckResolver)(nil)
+
+// NewBulkheadCheckResolver constructs a CheckResolver that waits for the bulkhead of the store of
+// a Check before delegating it.
+func NewBulkheadCheckResolver(bulkheads *StoreBulkheads) *BulkheadCheckResolver {
+	r := &BulkheadCheckResolver{bulkheads: bulkheads}
+	r.delegate = r
+	return r
+}
+
+// SetDelegate sets this BulkheadCheckResolver's dispatch delegate.
+func (r *BulkheadCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this BulkheadCheckResolver's dispatch delegate.
+func (r *BulkheadCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop, the StoreBulkheads outlive the resolver.
+func (r *BulkheadCheckResolver) Close() {}
+
+func (r *BulkheadCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	if req.GetRequestMetadata().Depth > 0 {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	start := time.Now()
+	release, waited, err := r.bulkheads.acquire(ctx, req.GetStoreID())
+	if waited {
+		storeBulkheadWaitHistogram.Observe(float64(time.Since(start).Milliseconds()))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("store_bulkhead_waited", true))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return r.delegate.ResolveCheck(ctx, req)
+}
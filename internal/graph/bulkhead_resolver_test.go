@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestParseStoreBulkheadLimits(t *testing.T) {
+	limits, err := ParseStoreBulkheadLimits([]string{"a=10", "b=0"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"a": 10, "b": 0}, limits)
+
+	for _, value := range []string{"a", "=10", "a=x", "a=-1"} {
+		_, err := ParseStoreBulkheadLimits([]string{value})
+		require.ErrorIs(t, err, ErrInvalidStoreBulkheadLimit, value)
+	}
+}
+
+func TestBulkheadCheckResolver(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	newRequest := func(t *testing.T, storeID string) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              storeID,
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		return req
+	}
+
+	t.Run("bounds_the_checks_of_a_store", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+
+		resolver := NewBulkheadCheckResolver(NewStoreBulkheads(1, map[string]int{"unbounded": 0}))
+		resolver.SetDelegate(mockResolver)
+
+		resolving := make(chan struct{})
+		unblock := make(chan struct{})
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+				if req.GetStoreID() == "a" && req.GetRequestMetadata().Depth == 0 {
+					resolving <- struct{}{}
+					<-unblock
+				}
+				return &ResolveCheckResponse{Allowed: true}, nil
+			}).Times(4)
+
+		go func() {
+			_, _ = resolver.ResolveCheck(context.Background(), newRequest(t, "a"))
+		}()
+		<-resolving
+
+		// the bulkhead of store a is full
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := resolver.ResolveCheck(ctx, newRequest(t, "a"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// the sub-problems and the other stores are not bounded
+		subproblem := newRequest(t, "a")
+		subproblem.GetRequestMetadata().Depth = 1
+		_, err = resolver.ResolveCheck(context.Background(), subproblem)
+		require.NoError(t, err)
+		_, err = resolver.ResolveCheck(context.Background(), newRequest(t, "b"))
+		require.NoError(t, err)
+
+		// the next check of store a waits for its turn
+		done := make(chan error)
+		go func() {
+			_, err := resolver.ResolveCheck(context.Background(), newRequest(t, "a"))
+			done <- err
+		}()
+		unblock <- struct{}{}
+		<-resolving
+		unblock <- struct{}{}
+		require.NoError(t, <-done)
+	})
+
+	t.Run("unbounded_store", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: true}, nil).Times(1)
+
+		resolver := NewBulkheadCheckResolver(NewStoreBulkheads(0, nil))
+		resolver.SetDelegate(mockResolver)
+
+		_, err := resolver.ResolveCheck(context.Background(), newRequest(t, "a"))
+		require.NoError(t, err)
+		require.Empty(t, resolver.bulkheads.stores)
+	})
+}
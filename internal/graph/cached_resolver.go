@@ -2,10 +2,11 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"time"
+	"unsafe"
 
-	"github.com/cespare/xxhash/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.opentelemetry.io/otel/attribute"
@@ -18,7 +19,6 @@ import (
 	"github.com/openfga/openfga/internal/telemetry"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
-	"github.com/openfga/openfga/pkg/tuple"
 )
 
 const (
@@ -46,7 +46,13 @@ var (
 	})
 )
 
-var _ storage.CacheItem = (*CheckResponseCacheEntry)(nil)
+var (
+	_ storage.PersistentCacheItem = (*CheckResponseCacheEntry)(nil)
+	_ storage.SizedCacheItem      = (*CheckResponseCacheEntry)(nil)
+)
+
+// CheckResponseCacheEntityType is the entity type of the CheckResponseCacheEntry.
+const CheckResponseCacheEntityType = "check_response"
 
 type CheckResponseCacheEntry struct {
 	LastModified  time.Time
@@ -54,7 +60,49 @@ type CheckResponseCacheEntry struct {
 }
 
 func (c *CheckResponseCacheEntry) CacheEntityType() string {
-	return "check_response"
+	return CheckResponseCacheEntityType
+}
+
+func (c *CheckResponseCacheEntry) CacheItemSize() int64 {
+	return int64(unsafe.Sizeof(*c) + unsafe.Sizeof(ResolveCheckResponse{}))
+}
+
+// checkResponseCacheRecord is the representation of a CheckResponseCacheEntry persisted by a
+// storage.DiskBackedCache.
+type checkResponseCacheRecord struct {
+	LastModified        time.Time `json:"last_modified"`
+	Allowed             bool      `json:"allowed"`
+	DatastoreQueryCount uint32    `json:"datastore_query_count,omitempty"`
+	DatastoreItemCount  uint64    `json:"datastore_item_count,omitempty"`
+}
+
+// MarshalBinary encodes the entry so that it can be persisted by a storage.DiskBackedCache.
+func (c *CheckResponseCacheEntry) MarshalBinary() ([]byte, error) {
+	return json.Marshal(checkResponseCacheRecord{
+		LastModified:        c.LastModified,
+		Allowed:             c.CheckResponse.GetAllowed(),
+		DatastoreQueryCount: c.CheckResponse.GetResolutionMetadata().DatastoreQueryCount,
+		DatastoreItemCount:  c.CheckResponse.GetResolutionMetadata().DatastoreItemCount,
+	})
+}
+
+// DecodeCheckResponseCacheEntry decodes an entry encoded by CheckResponseCacheEntry.MarshalBinary.
+// It is the storage.CacheItemDecoder of the CheckResponseCacheEntityType.
+func DecodeCheckResponseCacheEntry(data []byte) (any, error) {
+	var record checkResponseCacheRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &CheckResponseCacheEntry{
+		LastModified: record.LastModified,
+		CheckResponse: &ResolveCheckResponse{
+			Allowed: record.Allowed,
+			ResolutionMetadata: ResolveCheckResponseMetadata{
+				DatastoreQueryCount: record.DatastoreQueryCount,
+				DatastoreItemCount:  record.DatastoreItemCount,
+			},
+		},
+	}, nil
 }
 
 // CachedCheckResolver attempts to resolve check sub-problems via prior computations before
@@ -183,7 +231,9 @@ func (c *CachedCheckResolver) ResolveCheck(
 			if isValid {
 				checkCacheHitCounter.Inc()
 				// return a copy to avoid races across goroutines
-				return res.CheckResponse.clone(), nil
+				cloned := res.CheckResponse.clone()
+				cloned.ResolutionMetadata.CacheHit = true
+				return cloned, nil
 			}
 
 			// we tried the cache and hit an invalid entry
@@ -216,19 +266,16 @@ func (c *CachedCheckResolver) ResolveCheck(
 	}
 
 	clonedResp := resp.clone()
+	clonedResp.ResolutionMetadata.CacheHit = false
 
 	c.cache.Set(cacheKey, &CheckResponseCacheEntry{LastModified: time.Now(), CheckResponse: clonedResp}, storage.JitteredTTL(c.cacheTTL, c.jitterPercentage))
 	return resp, nil
 }
 
 func BuildCacheKey(req ResolveCheckRequest) string {
-	tup := tuple.From(req.GetTupleKey())
-	cacheKeyString := tup.String() + req.GetInvariantCacheKey()
-
-	hasher := xxhash.New()
-
-	// Digest.WriteString returns int and a nil error, ignoring
-	_, _ = hasher.WriteString(cacheKeyString)
-
-	return strconv.FormatUint(hasher.Sum64(), 10)
+	tk := req.GetTupleKey()
+	// the key is hashed in parts rather than as tuple.String() + req.GetInvariantCacheKey(),
+	// which would allocate it
+	hash := storage.HashCacheKeyParts(tk.GetObject(), "#", tk.GetRelation(), "@", tk.GetUser(), req.GetInvariantCacheKey())
+	return strconv.FormatUint(hash, 10)
 }
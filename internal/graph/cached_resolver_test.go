@@ -612,6 +612,61 @@ func TestCachedCheckResolver_FieldsInResponse(t *testing.T) {
 	require.True(t, resp.GetResolutionMetadata().CycleDetected)
 }
 
+func TestCachedCheckResolver_CacheHitInResponse(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cachedCheckResolver, err := NewCachedCheckResolver()
+	require.NoError(t, err)
+	defer cachedCheckResolver.Close()
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockCheckResolver := NewMockCheckResolver(mockCtrl)
+	cachedCheckResolver.SetDelegate(mockCheckResolver)
+
+	mockCheckResolver.EXPECT().
+		ResolveCheck(gomock.Any(), gomock.Any()).
+		Return(&ResolveCheckResponse{Allowed: true}, nil).Times(1)
+
+	req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+		StoreID:              "12",
+		AuthorizationModelID: "33",
+		TupleKey:             tuple.NewTupleKey("document:abc", "reader", "user:XYZ"),
+	})
+	require.NoError(t, err)
+
+	resp, err := cachedCheckResolver.ResolveCheck(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, resp.GetResolutionMetadata().CacheHit)
+
+	resp, err = cachedCheckResolver.ResolveCheck(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.GetAllowed())
+	require.True(t, resp.GetResolutionMetadata().CacheHit)
+}
+
+func TestCheckResponseCacheEntryMarshalBinary(t *testing.T) {
+	entry := &CheckResponseCacheEntry{
+		LastModified: time.Unix(1700000000, 0).UTC(),
+		CheckResponse: &ResolveCheckResponse{
+			Allowed: true,
+			ResolutionMetadata: ResolveCheckResponseMetadata{
+				DatastoreQueryCount: 3,
+				DatastoreItemCount:  5,
+			},
+		},
+	}
+
+	data, err := entry.MarshalBinary()
+	require.NoError(t, err)
+
+	decoded, err := DecodeCheckResponseCacheEntry(data)
+	require.NoError(t, err)
+	require.Equal(t, entry, decoded)
+}
+
 func TestBuildCacheKey(t *testing.T) {
 	req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
 		StoreID: "abc123",
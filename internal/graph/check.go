@@ -130,6 +130,15 @@ func (c *LocalChecker) GetDelegate() CheckResolver {
 	return c.delegate
 }
 
+// maxDepth returns the maximum resolution depth of the request of ctx: the one of the checker, or
+// the lower one of the request set with ContextWithMaxResolutionDepth.
+func (c *LocalChecker) maxDepth(ctx context.Context) uint32 {
+	if depth, ok := MaxResolutionDepthFromContext(ctx); ok && depth < c.maxResolutionDepth {
+		return depth
+	}
+	return c.maxResolutionDepth
+}
+
 // CheckHandlerFunc defines a function that evaluates a CheckResponse or returns an error
 // otherwise.
 type CheckHandlerFunc func(ctx context.Context) (*ResolveCheckResponse, error)
@@ -407,7 +416,7 @@ func (c *LocalChecker) ResolveCheck(
 	))
 	defer span.End()
 
-	if req.GetRequestMetadata().Depth == c.maxResolutionDepth {
+	if req.GetRequestMetadata().Depth >= c.maxDepth(ctx) {
 		return nil, ErrResolutionDepthExceeded
 	}
 
@@ -830,8 +839,7 @@ func (c *LocalChecker) checkComputedUserset(_ context.Context, req *ResolveCheck
 		req.GetTupleKey().GetUser(),
 	)
 
-	childRequest := req.clone()
-	childRequest.TupleKey = rewrittenTupleKey
+	childRequest := req.cloneWithTupleKey(rewrittenTupleKey)
 
 	return func(ctx context.Context) (*ResolveCheckResponse, error) {
 		ctx, span := tracer.Start(ctx, "checkComputedUserset")
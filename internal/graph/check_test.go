@@ -2868,3 +2868,48 @@ func TestSelectedStrategySkipsPlannerOnDispatch(t *testing.T) {
 		require.True(t, resp.Allowed)
 	})
 }
+
+// BenchmarkResolveCheck resolves a Check through a union, a tuple to userset and a userset, to
+// measure the allocations of the sub-problems of a Check.
+func BenchmarkResolveCheck(b *testing.B) {
+	ds := memory.New()
+	b.Cleanup(ds.Close)
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type folder
+			relations
+				define viewer: [user, group#member]
+		type document
+			relations
+				define parent: [folder]
+				define editor: [user]
+				define viewer: [user] or editor or viewer from parent`)
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(b, err)
+	require.NoError(b, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "parent", "folder:a"),
+		tuple.NewTupleKey("folder:a", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+	}))
+	checker := NewLocalChecker()
+	b.Cleanup(checker.Close)
+	ctx := setRequestContext(context.Background(), typesys, ds, nil)
+	b.ReportAllocs()
+	for b.Loop() {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              storeID,
+			AuthorizationModelID: model.GetId(),
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(b, err)
+		resp, err := checker.ResolveCheck(ctx, req)
+		require.NoError(b, err)
+		require.True(b, resp.GetAllowed())
+	}
+}
@@ -0,0 +1,234 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+var clusterSingleflightCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_cluster_singleflight_count",
+	Help:      "The total number of Checks coordinated across the replicas of the cluster, by outcome: resolved locally, shared with an identical Check in flight, forwarded to the replica that owns them, or resolved locally after a failed forward.",
+}, []string{"outcome"})
+
+// ClusterPeers assigns every Check to one replica of the cluster, and forwards the Checks to it.
+type ClusterPeers interface {
+	// Owner returns the address of the replica that owns the key, or an empty string if the Check
+	// must be resolved locally, either because this replica owns it or because it was forwarded.
+	Owner(ctx context.Context, key string) string
+
+	// Forward resolves the Check on the replica at address peer.
+	Forward(ctx context.Context, peer string, req *ResolveCheckRequest) (*ResolveCheckResponse, error)
+}
+
+// ClusterSingleflightCheckResolverOpt defines an option that can be used to change the behavior of
+// ClusterSingleflightCheckResolver instance.
+type ClusterSingleflightCheckResolverOpt func(*ClusterSingleflightCheckResolver)
+
+// WithClusterSingleflightLogger sets the logger of the ClusterSingleflightCheckResolver.
+func WithClusterSingleflightLogger(l logger.Logger) ClusterSingleflightCheckResolverOpt {
+	return func(r *ClusterSingleflightCheckResolver) {
+		r.logger = l
+	}
+}
+
+// ClusterSingleflightCheckResolver resolves identical Checks once across the replicas of a cluster.
+// Every Check is forwarded to the replica that owns it, which coalesces the identical Checks it
+// resolves concurrently. Only the top-level Checks are coordinated, their sub-problems are
+// resolved by the replica that owns the Check.
+type ClusterSingleflightCheckResolver struct {
+	delegate CheckResolver
+	peers    ClusterPeers
+	group    singleflight.Group
+	logger   logger.Logger
+}
+
+var _ CheckResolver = (*ClusterSingleflightCheckResolver)(nil)
+
+// NewClusterSingleflightCheckResolver constructs a CheckResolver that forwards the Checks to the
+// replica of peers that owns them.
+func NewClusterSingleflightCheckResolver(peers ClusterPeers, opts ...ClusterSingleflightCheckResolverOpt) *ClusterSingleflightCheckResolver {
+	r := &ClusterSingleflightCheckResolver{
+		peers:  peers,
+		logger: logger.NewNoopLogger(),
+	}
+	r.delegate = r
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// SetDelegate sets this ClusterSingleflightCheckResolver's dispatch delegate.
+func (r *ClusterSingleflightCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this ClusterSingleflightCheckResolver's dispatch delegate.
+func (r *ClusterSingleflightCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop.
+func (r *ClusterSingleflightCheckResolver) Close() {}
+
+func (r *ClusterSingleflightCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	// a Check that started before a write may not observe it, so HIGHER_CONSISTENCY Checks are never shared
+	if req.GetRequestMetadata().Depth > 0 || req.GetConsistency() == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	key := BuildCacheKey(*req)
+
+	if peer := r.peers.Owner(ctx, key); peer != "" {
+		resp, err := r.peers.Forward(ctx, peer, req)
+		if err == nil {
+			clusterSingleflightCounter.WithLabelValues("forwarded").Inc()
+			span.SetAttributes(attribute.String("cluster_owner", peer))
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		clusterSingleflightCounter.WithLabelValues("forward_failed").Inc()
+		r.logger.WarnWithContext(ctx, "failed to forward check to the replica that owns it, resolving it locally",
+			zap.String("peer", peer),
+			zap.Error(err))
+	}
+
+	res, err, shared := r.group.Do(key, func() (any, error) {
+		return r.delegate.ResolveCheck(ctx, req)
+	})
+	if shared && err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && ctx.Err() == nil {
+		// the request that resolved the Check was cancelled, not this one
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if shared {
+		clusterSingleflightCounter.WithLabelValues("shared").Inc()
+		span.SetAttributes(attribute.Bool("cluster_singleflight_shared", true))
+		// return a copy to avoid races across goroutines
+		return res.(*ResolveCheckResponse).clone(), nil
+	}
+	clusterSingleflightCounter.WithLabelValues("local").Inc()
+	return res.(*ResolveCheckResponse), nil
+}
+
+var clusterDispatchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_cluster_dispatch_count",
+	Help:      "The total number of Check sub-problems dispatched to the replica of the cluster that owns them, by outcome: dispatched, or resolved locally after a failed dispatch.",
+}, []string{"outcome"})
+
+// CheckDispatcher assigns every Check sub-problem to one replica of the cluster, and dispatches the
+// sub-problems to it.
+type CheckDispatcher interface {
+	// DispatchOwner returns the address of the replica that owns the key, or an empty string if the
+	// sub-problem must be resolved locally, either because this replica owns it or because it was
+	// dispatched to this replica.
+	DispatchOwner(ctx context.Context, key string) string
+
+	// Dispatch resolves the sub-problem on the replica at address peer.
+	Dispatch(ctx context.Context, peer string, req *ResolveCheckRequest) (*ResolveCheckResponse, error)
+}
+
+// ClusterDispatchCheckResolverOpt defines an option that can be used to change the behavior of
+// ClusterDispatchCheckResolver instance.
+type ClusterDispatchCheckResolverOpt func(*ClusterDispatchCheckResolver)
+
+// WithClusterDispatchLogger sets the logger of the ClusterDispatchCheckResolver.
+func WithClusterDispatchLogger(l logger.Logger) ClusterDispatchCheckResolverOpt {
+	return func(r *ClusterDispatchCheckResolver) {
+		r.logger = l
+	}
+}
+
+// ClusterDispatchCheckResolver dispatches the sub-problems of a Check to the replica of the cluster
+// that owns them, so that every replica caches the results of the sub-problems it owns instead of
+// every replica caching the results of all of them.
+type ClusterDispatchCheckResolver struct {
+	delegate   CheckResolver
+	dispatcher CheckDispatcher
+	logger     logger.Logger
+}
+
+var _ CheckResolver = (*ClusterDispatchCheckResolver)(nil)
+
+// NewClusterDispatchCheckResolver constructs a CheckResolver that dispatches the sub-problems to
+// the replica of dispatcher that owns them.
+func NewClusterDispatchCheckResolver(dispatcher CheckDispatcher, opts ...ClusterDispatchCheckResolverOpt) *ClusterDispatchCheckResolver {
+	r := &ClusterDispatchCheckResolver{
+		dispatcher: dispatcher,
+		logger:     logger.NewNoopLogger(),
+	}
+	r.delegate = r
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// SetDelegate sets this ClusterDispatchCheckResolver's dispatch delegate.
+func (r *ClusterDispatchCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this ClusterDispatchCheckResolver's dispatch delegate.
+func (r *ClusterDispatchCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop.
+func (r *ClusterDispatchCheckResolver) Close() {}
+
+func (r *ClusterDispatchCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	// the top-level Checks are resolved by the replica that received them
+	if req.GetRequestMetadata().Depth == 0 {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	peer := r.dispatcher.DispatchOwner(ctx, BuildCacheKey(*req))
+	if peer == "" {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	resp, err := r.dispatcher.Dispatch(ctx, peer, req)
+	if err == nil {
+		clusterDispatchCounter.WithLabelValues("dispatched").Inc()
+		return resp, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	clusterDispatchCounter.WithLabelValues("dispatch_failed").Inc()
+	r.logger.WarnWithContext(ctx, "failed to dispatch check sub-problem to the replica that owns it, resolving it locally",
+		zap.String("peer", peer),
+		zap.Error(err))
+	return r.delegate.ResolveCheck(ctx, req)
+}
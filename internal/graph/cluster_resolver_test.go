@@ -0,0 +1,219 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type fakeClusterPeers struct {
+	owner     string
+	forwarded int
+	err       error
+}
+
+func (f *fakeClusterPeers) Owner(ctx context.Context, key string) string {
+	return f.owner
+}
+
+func (f *fakeClusterPeers) Forward(ctx context.Context, peer string, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	f.forwarded++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ResolveCheckResponse{Allowed: true}, nil
+}
+
+type fakeCheckDispatcher struct {
+	owner      string
+	dispatched int
+	err        error
+}
+
+func (f *fakeCheckDispatcher) DispatchOwner(ctx context.Context, key string) string {
+	return f.owner
+}
+
+func (f *fakeCheckDispatcher) Dispatch(ctx context.Context, peer string, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	f.dispatched++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ResolveCheckResponse{Allowed: true}, nil
+}
+
+func TestClusterSingleflightCheckResolver(t *testing.T) {
+	ctx := context.Background()
+
+	newRequest := func(t *testing.T, consistency openfgav1.ConsistencyPreference) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			Consistency:          consistency,
+		})
+		require.NoError(t, err)
+		return req
+	}
+
+	t.Run("forwards_to_the_owner", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		peers := &fakeClusterPeers{owner: "peer:8081"}
+		resolver := NewClusterSingleflightCheckResolver(peers)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, 1, peers.forwarded)
+	})
+
+	t.Run("resolves_locally_when_the_forward_fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&ResolveCheckResponse{Allowed: false}, nil)
+
+		peers := &fakeClusterPeers{owner: "peer:8081", err: errors.New("unavailable")}
+		resolver := NewClusterSingleflightCheckResolver(peers)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("does_not_coordinate_sub_problems_and_higher_consistency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(2).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		peers := &fakeClusterPeers{owner: "peer:8081"}
+		resolver := NewClusterSingleflightCheckResolver(peers)
+		resolver.SetDelegate(mockResolver)
+
+		_, err := resolver.ResolveCheck(ctx, newRequest(t, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+		require.NoError(t, err)
+
+		req := newRequest(t, openfgav1.ConsistencyPreference_UNSPECIFIED)
+		req.GetRequestMetadata().Depth = 1
+		_, err = resolver.ResolveCheck(ctx, req)
+		require.NoError(t, err)
+		require.Zero(t, peers.forwarded)
+	})
+
+	t.Run("coalesces_identical_local_checks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+
+		release := make(chan struct{})
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+			func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+				<-release
+				return &ResolveCheckResponse{Allowed: true}, nil
+			})
+
+		resolver := NewClusterSingleflightCheckResolver(&fakeClusterPeers{})
+		resolver.SetDelegate(mockResolver)
+
+		const concurrency = 5
+		var wg sync.WaitGroup
+		results := make(chan bool, concurrency)
+		for range concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := resolver.ResolveCheck(ctx, newRequest(t, openfgav1.ConsistencyPreference_UNSPECIFIED))
+				if err == nil {
+					results <- resp.GetAllowed()
+				}
+			}()
+		}
+
+		// let the goroutines join the resolution in flight
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(results)
+
+		var allowed int
+		for result := range results {
+			require.True(t, result)
+			allowed++
+		}
+		require.Equal(t, concurrency, allowed)
+	})
+}
+
+func TestClusterDispatchCheckResolver(t *testing.T) {
+	ctx := context.Background()
+
+	newRequest := func(t *testing.T, depth uint32) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		req.GetRequestMetadata().Depth = depth
+		return req
+	}
+
+	t.Run("dispatches_sub_problems_to_the_owner", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		dispatcher := &fakeCheckDispatcher{owner: "peer:8081"}
+		resolver := NewClusterDispatchCheckResolver(dispatcher)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, 1))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, 1, dispatcher.dispatched)
+	})
+
+	t.Run("resolves_locally_when_the_dispatch_fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&ResolveCheckResponse{Allowed: false}, nil)
+
+		dispatcher := &fakeCheckDispatcher{owner: "peer:8081", err: errors.New("unavailable")}
+		resolver := NewClusterDispatchCheckResolver(dispatcher)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, 1))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("resolves_locally_top_level_checks_and_owned_sub_problems", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(2).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		dispatcher := &fakeCheckDispatcher{owner: "peer:8081"}
+		resolver := NewClusterDispatchCheckResolver(dispatcher)
+		resolver.SetDelegate(mockResolver)
+
+		_, err := resolver.ResolveCheck(ctx, newRequest(t, 0))
+		require.NoError(t, err)
+
+		dispatcher.owner = ""
+		_, err = resolver.ResolveCheck(ctx, newRequest(t, 2))
+		require.NoError(t, err)
+		require.Zero(t, dispatcher.dispatched)
+	})
+}
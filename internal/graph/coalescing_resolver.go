@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+var checkCoalescingCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_coalescing_count",
+	Help:      "The total number of Checks that went through the coalescing window, by outcome: resolved, or shared with an identical Check received within the window or while it was resolved.",
+}, []string{"outcome"})
+
+// CheckCoalescer merges the identical Checks received within a window into one resolution, so that
+// a burst of Checks on a hot object is resolved once rather than once per request. It is shared by
+// the CheckResolvers built for every request.
+type CheckCoalescer struct {
+	window time.Duration
+	group  singleflight.Group
+}
+
+// NewCheckCoalescer constructs a CheckCoalescer that waits window after the first of identical
+// Checks before resolving them.
+func NewCheckCoalescer(window time.Duration) *CheckCoalescer {
+	return &CheckCoalescer{window: window}
+}
+
+// CoalescingCheckResolver merges the identical Checks with a CheckCoalescer. Only the Checks of the
+// requests are merged, their sub-problems are shared through the check cache.
+type CoalescingCheckResolver struct {
+	delegate  CheckResolver
+	coalescer *CheckCoalescer
+}
+
+var _ CheckResolver = (*CoalescingCheckResolver)(nil)
+
+// NewCoalescingCheckResolver constructs a CheckResolver that merges the identical Checks with
+// coalescer before delegating them.
+func NewCoalescingCheckResolver(coalescer *CheckCoalescer) *CoalescingCheckResolver {
+	r := &CoalescingCheckResolver{coalescer: coalescer}
+	r.delegate = r
+	return r
+}
+
+// SetDelegate sets this CoalescingCheckResolver's dispatch delegate.
+func (r *CoalescingCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this CoalescingCheckResolver's dispatch delegate.
+func (r *CoalescingCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop, the CheckCoalescer outlives the resolver.
+func (r *CoalescingCheckResolver) Close() {}
+
+func (r *CoalescingCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	// a Check that started before a write may not observe it, so HIGHER_CONSISTENCY Checks are never merged
+	if req.GetRequestMetadata().Depth > 0 || req.GetConsistency() == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	ch := r.coalescer.group.DoChan(BuildCacheKey(*req), func() (any, error) {
+		timer := time.NewTimer(r.coalescer.window)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return r.delegate.ResolveCheck(ctx, req)
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if res.Shared && res.Err != nil && (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) && ctx.Err() == nil {
+		// the request that resolved the Check was cancelled, not this one
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	if res.Shared {
+		checkCoalescingCounter.WithLabelValues("shared").Inc()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("check_coalesced", true))
+		// return a copy to avoid races across goroutines
+		return res.Val.(*ResolveCheckResponse).clone(), nil
+	}
+	checkCoalescingCounter.WithLabelValues("resolved").Inc()
+	return res.Val.(*ResolveCheckResponse), nil
+}
@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestCoalescingCheckResolver(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	newRequest := func(t *testing.T, user string) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", user),
+		})
+		require.NoError(t, err)
+		return req
+	}
+
+	resolveConcurrently := func(t *testing.T, resolver CheckResolver, reqs ...*ResolveCheckRequest) {
+		var wg sync.WaitGroup
+		for _, req := range reqs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := resolver.ResolveCheck(context.Background(), req)
+				require.NoError(t, err)
+				require.True(t, resp.GetAllowed())
+			}()
+		}
+		wg.Wait()
+	}
+
+	t.Run("merges_the_identical_checks_of_the_window", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		resolver := NewCoalescingCheckResolver(NewCheckCoalescer(50 * time.Millisecond))
+		resolver.SetDelegate(mockResolver)
+
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&ResolveCheckResponse{Allowed: true}, nil).Times(2)
+
+		resolveConcurrently(t, resolver,
+			newRequest(t, "user:anne"), newRequest(t, "user:anne"), newRequest(t, "user:anne"),
+			newRequest(t, "user:bob"), newRequest(t, "user:bob"),
+		)
+	})
+
+	t.Run("never_merges_sub_problems_nor_higher_consistency_checks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		resolver := NewCoalescingCheckResolver(NewCheckCoalescer(50 * time.Millisecond))
+		resolver.SetDelegate(mockResolver)
+
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&ResolveCheckResponse{Allowed: true}, nil).Times(4)
+
+		subproblem := func() *ResolveCheckRequest {
+			req := newRequest(t, "user:anne")
+			req.GetRequestMetadata().Depth = 1
+			return req
+		}
+		higherConsistency := func() *ResolveCheckRequest {
+			req := newRequest(t, "user:anne")
+			req.Consistency = openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY
+			return req
+		}
+		resolveConcurrently(t, resolver, subproblem(), subproblem(), higherConsistency(), higherConsistency())
+	})
+
+	t.Run("stops_waiting_when_the_context_is_done", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		resolver := NewCoalescingCheckResolver(NewCheckCoalescer(time.Second))
+		resolver.SetDelegate(mockResolver)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := resolver.ResolveCheck(ctx, newRequest(t, "user:anne"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("resolves_again_when_the_merged_check_was_cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		resolver := NewCoalescingCheckResolver(NewCheckCoalescer(50 * time.Millisecond))
+		resolver.SetDelegate(mockResolver)
+
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&ResolveCheckResponse{Allowed: true}, nil).Times(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelled := make(chan error)
+		go func() {
+			_, err := resolver.ResolveCheck(ctx, newRequest(t, "user:anne"))
+			cancelled <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		done := make(chan error)
+		go func() {
+			_, err := resolver.ResolveCheck(context.Background(), newRequest(t, "user:anne"))
+			done <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		require.ErrorIs(t, <-cancelled, context.Canceled)
+		require.NoError(t, <-done)
+	})
+}
@@ -87,8 +87,7 @@ func (c *LocalChecker) defaultUserset(_ context.Context, req *ResolveCheckReques
 // prepareChildRequest creates a clone of the parent request and updates its fields
 // to create a child request for dispatching.
 func (c *LocalChecker) prepareChildRequest(parentReq *ResolveCheckRequest, tk *openfgav1.TupleKey, strategy string) *ResolveCheckRequest {
-	childRequest := parentReq.clone()
-	childRequest.TupleKey = tk
+	childRequest := parentReq.cloneWithTupleKey(tk)
 	childRequest.SelectedStrategy = strategy
 	return childRequest
 }
@@ -16,7 +16,8 @@ import (
 type ctxKey string
 
 const (
-	resolutionDepthCtxKey ctxKey = "resolution-depth"
+	resolutionDepthCtxKey    ctxKey = "resolution-depth"
+	maxResolutionDepthCtxKey ctxKey = "max-resolution-depth"
 )
 
 var (
@@ -41,6 +42,19 @@ func ResolutionDepthFromContext(ctx context.Context) (uint32, bool) {
 	return depth, ok
 }
 
+// ContextWithMaxResolutionDepth attaches a limit on the graph resolution depth of a request to
+// the parent context. It lowers, for the request, the limit of the resolvers.
+func ContextWithMaxResolutionDepth(parent context.Context, depth uint32) context.Context {
+	return context.WithValue(parent, maxResolutionDepthCtxKey, depth)
+}
+
+// MaxResolutionDepthFromContext returns the limit on the graph resolution depth of the request
+// from the provided context (if any).
+func MaxResolutionDepthFromContext(ctx context.Context) (uint32, bool) {
+	depth, ok := ctx.Value(maxResolutionDepthCtxKey).(uint32)
+	return depth, ok
+}
+
 type RelationshipEdgeType int
 
 const (
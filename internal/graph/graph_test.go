@@ -1614,3 +1614,16 @@ func TestResolutionDepthContext(t *testing.T) {
 	require.False(t, ok)
 	require.Equal(t, uint32(0), depth)
 }
+
+func TestMaxResolutionDepthContext(t *testing.T) {
+	ctx := ContextWithMaxResolutionDepth(context.Background(), 5)
+
+	depth, ok := MaxResolutionDepthFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, uint32(5), depth)
+
+	checker := NewLocalChecker(WithMaxResolutionDepth(10))
+	require.Equal(t, uint32(5), checker.maxDepth(ctx))
+	require.Equal(t, uint32(10), checker.maxDepth(context.Background()))
+	require.Equal(t, uint32(10), checker.maxDepth(ContextWithMaxResolutionDepth(context.Background(), 20)))
+}
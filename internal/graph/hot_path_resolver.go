@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var hotPathHitCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_hot_path_hit_count",
+	Help:      "The total number of calls to ResolveCheck (including any recursive calls) answered from a precomputed hot path membership.",
+})
+
+// HotPathIndex counts the Check sub-problems resolved per (object type, relation) and holds the
+// memberships precomputed for the most frequent ones.
+type HotPathIndex interface {
+	// Observe counts one Check of the relation of an object of objectType.
+	Observe(storeID, modelID, objectType, relation string)
+
+	// Lookup answers the Check of tk from a membership precomputed after notBefore. It returns
+	// false as its second value if no such membership can answer the Check.
+	Lookup(storeID, modelID string, tk *openfgav1.TupleKey, notBefore time.Time) (allowed bool, ok bool)
+}
+
+// HotPathCheckResolver records the (object type, relation) of every Check sub-problem in a
+// HotPathIndex, and answers the sub-problems whose membership was precomputed without delegating them.
+type HotPathCheckResolver struct {
+	delegate CheckResolver
+	index    HotPathIndex
+}
+
+var _ CheckResolver = (*HotPathCheckResolver)(nil)
+
+// NewHotPathCheckResolver constructs a CheckResolver that looks up the Check sub-problems in index
+// before delegating them.
+func NewHotPathCheckResolver(index HotPathIndex) *HotPathCheckResolver {
+	r := &HotPathCheckResolver{index: index}
+	r.delegate = r
+	return r
+}
+
+// SetDelegate sets this HotPathCheckResolver's dispatch delegate.
+func (r *HotPathCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this HotPathCheckResolver's dispatch delegate.
+func (r *HotPathCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop.
+func (r *HotPathCheckResolver) Close() {}
+
+func (r *HotPathCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	tk := req.GetTupleKey()
+	r.index.Observe(req.GetStoreID(), req.GetAuthorizationModelID(), tuple.GetType(tk.GetObject()), tk.GetRelation())
+
+	// the precomputed memberships ignore the contextual tuples and may lag behind the latest writes
+	if req.GetConsistency() != openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY && len(req.GetContextualTuples()) == 0 {
+		if allowed, ok := r.index.Lookup(req.GetStoreID(), req.GetAuthorizationModelID(), tk, req.GetLastCacheInvalidationTime()); ok {
+			hotPathHitCounter.Inc()
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("hot_path", true))
+			return &ResolveCheckResponse{Allowed: allowed}, nil
+		}
+	}
+
+	return r.delegate.ResolveCheck(ctx, req)
+}
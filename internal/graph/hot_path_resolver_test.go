@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type fakeHotPathIndex struct {
+	observed []string
+	members  map[string]bool
+	builtAt  time.Time
+}
+
+func (f *fakeHotPathIndex) Observe(storeID, modelID, objectType, relation string) {
+	f.observed = append(f.observed, objectType+"#"+relation)
+}
+
+func (f *fakeHotPathIndex) Lookup(storeID, modelID string, tk *openfgav1.TupleKey, notBefore time.Time) (bool, bool) {
+	if f.members == nil || !f.builtAt.After(notBefore) {
+		return false, false
+	}
+	return f.members[tuple.TupleKeyToString(tk)], true
+}
+
+func TestHotPathCheckResolver(t *testing.T) {
+	ctx := context.Background()
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	newRequest := func(t *testing.T, params ResolveCheckRequestParams) *ResolveCheckRequest {
+		params.StoreID = "store"
+		params.AuthorizationModelID = "model"
+		params.TupleKey = tk
+		req, err := NewResolveCheckRequest(params)
+		require.NoError(t, err)
+		return req
+	}
+
+	t.Run("answers_from_the_index", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		index := &fakeHotPathIndex{members: map[string]bool{tuple.TupleKeyToString(tk): true}, builtAt: time.Now()}
+		resolver := NewHotPathCheckResolver(index)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, ResolveCheckRequestParams{}))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, []string{"document#viewer"}, index.observed)
+	})
+
+	t.Run("delegates_without_precomputed_membership", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		index := &fakeHotPathIndex{}
+		resolver := NewHotPathCheckResolver(index)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, ResolveCheckRequestParams{}))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Len(t, index.observed, 1)
+	})
+
+	t.Run("delegates_requests_the_index_cannot_answer", func(t *testing.T) {
+		tests := map[string]ResolveCheckRequestParams{
+			"higher_consistency": {Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+			"contextual_tuples":  {ContextualTuples: []*openfgav1.TupleKey{tuple.NewTupleKey("document:2", "viewer", "user:anne")}},
+			"invalidated":        {LastCacheInvalidationTime: time.Now().Add(time.Minute)},
+		}
+		for name, params := range tests {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				mockResolver := NewMockCheckResolver(ctrl)
+				mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&ResolveCheckResponse{Allowed: false}, nil)
+
+				index := &fakeHotPathIndex{members: map[string]bool{tuple.TupleKeyToString(tk): true}, builtAt: time.Now()}
+				resolver := NewHotPathCheckResolver(index)
+				resolver.SetDelegate(mockResolver)
+
+				resp, err := resolver.ResolveCheck(ctx, newRequest(t, params))
+				require.NoError(t, err)
+				require.False(t, resp.GetAllowed())
+			})
+		}
+	})
+}
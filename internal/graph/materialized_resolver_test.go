@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type fakeMaterializedPermissions struct {
+	permissions map[string]bool
+	updatedAt   time.Time
+	err         error
+}
+
+func (f *fakeMaterializedPermissions) Lookup(_ context.Context, storeID, modelID string, tk *openfgav1.TupleKey, notBefore time.Time) (bool, bool, error) {
+	if f.err != nil {
+		return false, false, f.err
+	}
+	if f.permissions == nil || !f.updatedAt.After(notBefore) {
+		return false, false, nil
+	}
+	return f.permissions[tuple.TupleKeyToString(tk)], true, nil
+}
+
+func TestMaterializedCheckResolver(t *testing.T) {
+	ctx := context.Background()
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	newRequest := func(t *testing.T, params ResolveCheckRequestParams) *ResolveCheckRequest {
+		params.StoreID = "store"
+		params.AuthorizationModelID = "model"
+		params.TupleKey = tk
+		req, err := NewResolveCheckRequest(params)
+		require.NoError(t, err)
+		return req
+	}
+
+	t.Run("answers_from_the_permissions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		resolver := NewMaterializedCheckResolver(&fakeMaterializedPermissions{permissions: map[string]bool{}, updatedAt: time.Now()})
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, ResolveCheckRequestParams{}))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("delegates_requests_the_permissions_cannot_answer", func(t *testing.T) {
+		tests := map[string]struct {
+			params      ResolveCheckRequestParams
+			permissions *fakeMaterializedPermissions
+		}{
+			"not_materialized": {
+				permissions: &fakeMaterializedPermissions{},
+			},
+			"higher_consistency": {
+				params:      ResolveCheckRequestParams{Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+				permissions: &fakeMaterializedPermissions{permissions: map[string]bool{}, updatedAt: time.Now()},
+			},
+			"contextual_tuples": {
+				params:      ResolveCheckRequestParams{ContextualTuples: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")}},
+				permissions: &fakeMaterializedPermissions{permissions: map[string]bool{}, updatedAt: time.Now()},
+			},
+			"outdated": {
+				params:      ResolveCheckRequestParams{LastCacheInvalidationTime: time.Now().Add(time.Minute)},
+				permissions: &fakeMaterializedPermissions{permissions: map[string]bool{}, updatedAt: time.Now()},
+			},
+			"error": {
+				permissions: &fakeMaterializedPermissions{err: errors.New("boom")},
+			},
+		}
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				mockResolver := NewMockCheckResolver(ctrl)
+				mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+				resolver := NewMaterializedCheckResolver(test.permissions)
+				resolver.SetDelegate(mockResolver)
+
+				resp, err := resolver.ResolveCheck(ctx, newRequest(t, test.params))
+				require.NoError(t, err)
+				require.True(t, resp.GetAllowed())
+			})
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+)
+
+// CheckResolverPosition is a point of the chain of CheckResolver built by the
+// CheckResolverOrderedBuilder where custom resolvers are inserted.
+type CheckResolverPosition int
+
+const (
+	// CheckResolverPositionFirst is before all the built-in resolvers, so that the custom
+	// resolvers see every Check sub-problem, including the cached ones.
+	CheckResolverPositionFirst CheckResolverPosition = iota
+
+	// CheckResolverPositionAfterCache is after the CachedCheckResolver and the
+	// BulkheadCheckResolver, so that the custom resolvers only see the sub-problems that are not
+	// cached, and their results are cached.
+	CheckResolverPositionAfterCache
+
+	// CheckResolverPositionLast is right before the LocalChecker, after the throttling and the
+	// dispatch of the sub-problems to the other replicas.
+	CheckResolverPositionLast
+)
+
+// CheckResolverFactory constructs a custom CheckResolver. The chain of CheckResolver is built for
+// every request, so it must return a new resolver every time.
+type CheckResolverFactory func() CheckResolver
+
+// CustomCheckResolver is a custom CheckResolver inserted in the chain of CheckResolver at Position.
+// The custom resolvers at the same position are inserted in order.
+type CustomCheckResolver struct {
+	Position CheckResolverPosition
+	New      CheckResolverFactory
+}
+
+// CheckResolverMiddlewareFunc resolves a Check sub-problem, e.g. by short-circuiting it, or
+// delegates it to next.
+type CheckResolverMiddlewareFunc func(ctx context.Context, req *ResolveCheckRequest, next CheckResolver) (*ResolveCheckResponse, error)
+
+// MiddlewareCheckResolver resolves the Check sub-problems with a CheckResolverMiddlewareFunc.
+type MiddlewareCheckResolver struct {
+	delegate CheckResolver
+	fn       CheckResolverMiddlewareFunc
+}
+
+var _ CheckResolver = (*MiddlewareCheckResolver)(nil)
+
+// NewMiddlewareCheckResolver constructs a CheckResolver that resolves the Check sub-problems
+// with fn.
+func NewMiddlewareCheckResolver(fn CheckResolverMiddlewareFunc) *MiddlewareCheckResolver {
+	r := &MiddlewareCheckResolver{fn: fn}
+	r.delegate = r
+	return r
+}
+
+// CheckResolverMiddleware returns a CheckResolverFactory of MiddlewareCheckResolver with fn.
+func CheckResolverMiddleware(fn CheckResolverMiddlewareFunc) CheckResolverFactory {
+	return func() CheckResolver {
+		return NewMiddlewareCheckResolver(fn)
+	}
+}
+
+// SetDelegate sets this MiddlewareCheckResolver's dispatch delegate.
+func (r *MiddlewareCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this MiddlewareCheckResolver's dispatch delegate.
+func (r *MiddlewareCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop.
+func (r *MiddlewareCheckResolver) Close() {}
+
+func (r *MiddlewareCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	return r.fn(ctx, req, r.delegate)
+}
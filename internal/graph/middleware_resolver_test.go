@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestMiddlewareCheckResolver(t *testing.T) {
+	ctx := context.Background()
+
+	// allowlist answers the Checks of user:admin without delegating them
+	allowlist := func(ctx context.Context, req *ResolveCheckRequest, next CheckResolver) (*ResolveCheckResponse, error) {
+		if req.GetTupleKey().GetUser() == "user:admin" {
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}
+		return next.ResolveCheck(ctx, req)
+	}
+
+	newRequest := func(t *testing.T, user string) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", user),
+		})
+		require.NoError(t, err)
+		return req
+	}
+
+	t.Run("short_circuits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		resolver := CheckResolverMiddleware(allowlist)()
+		resolver.SetDelegate(mockResolver)
+		require.Equal(t, mockResolver, resolver.GetDelegate())
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, "user:admin"))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("delegates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(&ResolveCheckResponse{Allowed: false}, nil)
+
+		resolver := NewMiddlewareCheckResolver(allowlist)
+		resolver.SetDelegate(mockResolver)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(t, "user:anne"))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+}
@@ -274,7 +274,7 @@ ConsumerLoop:
 // Note that both group:2#member and group:3#member has group:a#member. However, they are not cycles.
 func (c *LocalChecker) breadthFirstRecursiveMatch(ctx context.Context, req *ResolveCheckRequest, mapping *recursiveMapping, visitedUserset *sync.Map, currentUsersetLevel *hashset.Set, usersetFromUser *hashset.Set, checkOutcomeChan chan checkOutcome) {
 	req.GetRequestMetadata().Depth++
-	if req.GetRequestMetadata().Depth == c.maxResolutionDepth {
+	if req.GetRequestMetadata().Depth >= c.maxDepth(ctx) {
 		concurrency.TrySendThroughChannel(ctx, checkOutcome{err: ErrResolutionDepthExceeded}, checkOutcomeChan)
 		close(checkOutcomeChan)
 		return
@@ -297,8 +297,7 @@ func (c *LocalChecker) breadthFirstRecursiveMatch(ctx context.Context, req *Reso
 		if visited {
 			continue
 		}
-		newReq := req.clone()
-		newReq.TupleKey = tuple.NewTupleKey(userset, relation, user)
+		newReq := req.cloneWithTupleKey(tuple.NewTupleKey(userset, relation, user))
 		mapper, err := buildRecursiveMapper(ctx, newReq, mapping)
 
 		if err != nil {
@@ -112,6 +112,12 @@ func NewResolveCheckRequest(
 		userType:   userType,
 	}
 
+	if len(params.ContextualTuples) == 0 && params.Context == nil {
+		// the invariant cache key of a request without contextual tuples nor context is its model ID
+		r.invariantCacheKey = params.AuthorizationModelID
+		return r, nil
+	}
+
 	keyBuilder := &strings.Builder{}
 	err := storage.WriteInvariantCheckCacheKey(keyBuilder, &storage.CheckCacheKeyParams{
 		StoreID:              params.StoreID,
@@ -128,27 +134,42 @@ func NewResolveCheckRequest(
 	return r, nil
 }
 
+// clonedResolveCheckRequest allocates a cloned request along with its metadata, which a request
+// doesn't share with its sub-problems, so that a clone costs a single allocation.
+type clonedResolveCheckRequest struct {
+	request  ResolveCheckRequest
+	metadata ResolveCheckRequestMetadata
+}
+
 func (r *ResolveCheckRequest) clone() *ResolveCheckRequest {
+	var tupleKey *openfgav1.TupleKey
+	if origTupleKey := r.GetTupleKey(); origTupleKey != nil {
+		tupleKey = cloneTupleKey(origTupleKey)
+	}
+	return r.cloneWithTupleKey(tupleKey)
+}
+
+// cloneWithTupleKey clones the request for the sub-problem of tk. Unlike clone, it doesn't copy the
+// tuple key of the request, for the callers that replace it.
+func (r *ResolveCheckRequest) cloneWithTupleKey(tk *openfgav1.TupleKey) *ResolveCheckRequest {
+	c := &clonedResolveCheckRequest{}
+
 	var requestMetadata *ResolveCheckRequestMetadata
 	origRequestMetadata := r.GetRequestMetadata()
 	if origRequestMetadata != nil {
-		requestMetadata = &ResolveCheckRequestMetadata{
+		c.metadata = ResolveCheckRequestMetadata{
 			DispatchCounter:    origRequestMetadata.DispatchCounter,
 			Depth:              origRequestMetadata.Depth,
 			DispatchThrottled:  origRequestMetadata.DispatchThrottled,
 			DatastoreThrottled: origRequestMetadata.DatastoreThrottled,
 		}
+		requestMetadata = &c.metadata
 	}
 
-	var tupleKey *openfgav1.TupleKey
-	if origTupleKey := r.GetTupleKey(); origTupleKey != nil {
-		tupleKey = proto.Clone(origTupleKey).(*openfgav1.TupleKey)
-	}
-
-	cloned := &ResolveCheckRequest{
+	c.request = ResolveCheckRequest{
 		StoreID:                   r.GetStoreID(),
 		AuthorizationModelID:      r.GetAuthorizationModelID(),
-		TupleKey:                  tupleKey,
+		TupleKey:                  tk,
 		ContextualTuples:          r.GetContextualTuples(),
 		Context:                   r.GetContext(),
 		RequestMetadata:           requestMetadata,
@@ -160,6 +181,20 @@ func (r *ResolveCheckRequest) clone() *ResolveCheckRequest {
 		objectType:                r.GetObjectType(),
 		userType:                  r.GetUserType(),
 	}
+	return &c.request
+}
+
+// cloneTupleKey returns a deep copy of tk. It copies the fields of tk instead of using proto.Clone,
+// which is reflective.
+func cloneTupleKey(tk *openfgav1.TupleKey) *openfgav1.TupleKey {
+	cloned := &openfgav1.TupleKey{
+		Object:   tk.GetObject(),
+		Relation: tk.GetRelation(),
+		User:     tk.GetUser(),
+	}
+	if condition := tk.GetCondition(); condition != nil {
+		cloned.Condition = proto.Clone(condition).(*openfgav1.RelationshipCondition)
+	}
 	return cloned
 }
 
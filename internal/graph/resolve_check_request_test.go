@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/tuple"
 )
 
@@ -210,4 +212,49 @@ func TestNewResolveCheckRequest(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("invariant_cache_key_without_context", func(t *testing.T) {
+		params := ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		}
+		req, err := NewResolveCheckRequest(params)
+		require.NoError(t, err)
+
+		var key strings.Builder
+		require.NoError(t, storage.WriteInvariantCheckCacheKey(&key, &storage.CheckCacheKeyParams{
+			StoreID:              params.StoreID,
+			AuthorizationModelID: params.AuthorizationModelID,
+		}))
+		require.Equal(t, key.String(), req.GetInvariantCacheKey())
+	})
+}
+
+func TestCloneWithTupleKey(t *testing.T) {
+	conditionContext, err := structpb.NewStruct(map[string]interface{}{
+		"x": 10,
+	})
+	require.NoError(t, err)
+
+	orig := &ResolveCheckRequest{
+		StoreID:              "12",
+		AuthorizationModelID: "33",
+		TupleKey:             tuple.NewTupleKeyWithCondition("document:abc", "reader", "user:XYZ", "cond", conditionContext),
+		RequestMetadata:      NewCheckRequestMetadata(),
+	}
+
+	tk := cloneTupleKey(orig.GetTupleKey())
+	require.Equal(t, orig.GetTupleKey().String(), tk.String())
+	require.NotSame(t, orig.GetTupleKey().GetCondition(), tk.GetCondition())
+
+	tk.Relation = "writer"
+	cloned := orig.cloneWithTupleKey(tk)
+	require.Equal(t, "reader", orig.GetTupleKey().GetRelation())
+	require.Same(t, tk, cloned.GetTupleKey())
+	require.Equal(t, "12", cloned.GetStoreID())
+
+	cloned.GetRequestMetadata().Depth++
+	require.Equal(t, uint32(0), orig.GetRequestMetadata().Depth)
+	require.Same(t, orig.GetRequestMetadata().DispatchCounter, cloned.GetRequestMetadata().DispatchCounter)
 }
@@ -14,6 +14,12 @@ type ResolveCheckResponseMetadata struct {
 	CycleDetected bool
 	// The total time it took to resolve the check request.
 	Duration time.Duration
+
+	// The cumulative time spent in the datastore to resolve the check request.
+	DatastoreDuration time.Duration
+
+	// Indicates if the response was served from the check query cache.
+	CacheHit bool
 }
 
 // clone clones the provided ResolveCheckResponse.
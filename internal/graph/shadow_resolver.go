@@ -2,16 +2,69 @@ package graph
 
 import (
 	"context"
+	"math/rand/v2"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 
+	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/pkg/logger"
 )
 
 const Hundred = 100
 
+// The outcomes of the comparison of a shadow Check with its main Check.
+const (
+	ShadowCheckOutcomeMatch    = "match"
+	ShadowCheckOutcomeMismatch = "mismatch"
+	ShadowCheckOutcomeError    = "error"
+)
+
+var (
+	shadowCheckComparisonCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "shadow_check_comparison_count",
+		Help:      "The total number of Check evaluations compared with a shadow evaluation, labeled by shadow resolver and by whether the shadow result matched the main result, differed from it or errored.",
+	}, []string{"resolver", "outcome"})
+
+	shadowCheckDurationHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "shadow_check_duration_ms",
+		Help:                            "The duration (in ms) of the Check evaluations compared with a shadow evaluation, labeled by shadow resolver and by whether the main or the shadow resolver evaluated it.",
+		Buckets:                         []float64{1, 5, 10, 25, 50, 80, 100, 150, 200, 300, 1000, 2000, 5000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"resolver", "path"})
+)
+
+// ShouldShadow reports whether a Check should be evaluated by a shadow resolver, so that
+// samplePercentage percent of the Checks are.
+func ShouldShadow(samplePercentage int) bool {
+	return samplePercentage >= Hundred || rand.IntN(Hundred) < samplePercentage
+}
+
+// ObserveShadowCheck records the comparison of a shadow Check evaluation with the main evaluation.
+// shadowErr is the error of the shadow evaluation, if any.
+func ObserveShadowCheck(resolver string, mainAllowed bool, mainDuration time.Duration, shadowAllowed bool, shadowDuration time.Duration, shadowErr error) {
+	outcome := ShadowCheckOutcomeMatch
+	switch {
+	case shadowErr != nil:
+		outcome = ShadowCheckOutcomeError
+	case mainAllowed != shadowAllowed:
+		outcome = ShadowCheckOutcomeMismatch
+	}
+	shadowCheckComparisonCounter.WithLabelValues(resolver, outcome).Inc()
+
+	shadowCheckDurationHistogram.WithLabelValues(resolver, "main").Observe(float64(mainDuration.Milliseconds()))
+	if shadowErr == nil {
+		shadowCheckDurationHistogram.WithLabelValues(resolver, "shadow").Observe(float64(shadowDuration.Milliseconds()))
+	}
+}
+
 type ShadowResolverOpt func(*ShadowResolver)
 
 func ShadowResolverWithName(name string) ShadowResolverOpt {
@@ -32,12 +85,22 @@ func ShadowResolverWithLogger(logger logger.Logger) ShadowResolverOpt {
 	}
 }
 
+// ShadowResolverWithSamplePercentage sets the percentage of the Checks that are also evaluated by
+// the shadow resolver. By default, every Check is.
+func ShadowResolverWithSamplePercentage(percentage int) ShadowResolverOpt {
+	return func(shadowResolver *ShadowResolver) {
+		shadowResolver.samplePercentage = percentage
+	}
+}
+
 type ShadowResolver struct {
 	name          string
 	main          CheckResolver
 	shadow        CheckResolver
 	shadowTimeout time.Duration
 	logger        logger.Logger
+	// samplePercentage is the percentage of the Checks evaluated by the shadow resolver
+	samplePercentage int
 	// only used for testing signals
 	wg *sync.WaitGroup
 }
@@ -53,6 +116,10 @@ func (s ShadowResolver) ResolveCheck(ctx context.Context, req *ResolveCheckReque
 		return nil, err
 	}
 
+	if !ShouldShadow(s.samplePercentage) {
+		return res, nil
+	}
+
 	resClone := res.clone()
 	reqClone := req.clone()
 	reqClone.VisitedPaths = nil // reset completely for evaluation
@@ -78,6 +145,7 @@ func (s ShadowResolver) ResolveCheck(ctx context.Context, req *ResolveCheckReque
 		shadowStart := time.Now()
 		shadowRes, err := s.shadow.ResolveCheck(ctx, reqClone)
 		shadowDuration := time.Since(shadowStart)
+		ObserveShadowCheck(s.name, resClone.GetAllowed(), mainDuration, shadowRes.GetAllowed(), shadowDuration, err)
 		if err != nil {
 			s.logger.WarnWithContext(ctx, "shadow check errored",
 				zap.String("resolver", s.name),
@@ -127,7 +195,7 @@ func (s ShadowResolver) GetDelegate() CheckResolver {
 }
 
 func NewShadowChecker(main CheckResolver, shadow CheckResolver, opts ...ShadowResolverOpt) *ShadowResolver {
-	r := &ShadowResolver{name: "check", main: main, shadow: shadow, wg: &sync.WaitGroup{}}
+	r := &ShadowResolver{name: "check", main: main, shadow: shadow, samplePercentage: Hundred, wg: &sync.WaitGroup{}}
 
 	for _, opt := range opts {
 		opt(r)
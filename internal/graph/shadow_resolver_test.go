@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
@@ -112,4 +113,53 @@ func TestShadowResolver_ResolveCheck(t *testing.T) {
 		require.False(t, res.Allowed)
 		checker.wg.Wait()
 	})
+	t.Run("should_not_shadow_unsampled_checks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		main := NewMockCheckResolver(ctrl)
+		main.EXPECT().Close().MaxTimes(1)
+		shadow := NewMockCheckResolver(ctrl)
+		shadow.EXPECT().Close().MaxTimes(1)
+		checker := NewShadowChecker(main, shadow, ShadowResolverWithSamplePercentage(0))
+		defer checker.Close()
+		main.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{
+			Allowed: true,
+		}, nil)
+		shadow.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+		res, err := checker.ResolveCheck(context.Background(), &ResolveCheckRequest{})
+		checker.wg.Wait()
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	})
+	t.Run("should_record_comparison_metrics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		main := NewMockCheckResolver(ctrl)
+		main.EXPECT().Close().MaxTimes(1)
+		shadow := NewMockCheckResolver(ctrl)
+		shadow.EXPECT().Close().MaxTimes(1)
+		logger := mocks.NewMockLogger(ctrl)
+		logger.EXPECT().InfoWithContext(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		checker := NewShadowChecker(main, shadow, ShadowResolverWithName("metrics_test"), ShadowResolverWithLogger(logger), ShadowResolverWithTimeout(1*time.Second))
+		defer checker.Close()
+		main.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: true}, nil).Times(2)
+		shadow.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: true}, nil)
+		shadow.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: false}, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := checker.ResolveCheck(context.Background(), &ResolveCheckRequest{})
+			require.NoError(t, err)
+			checker.wg.Wait()
+		}
+
+		require.InDelta(t, 1, testutil.ToFloat64(shadowCheckComparisonCounter.WithLabelValues("metrics_test", ShadowCheckOutcomeMatch)), 0)
+		require.InDelta(t, 1, testutil.ToFloat64(shadowCheckComparisonCounter.WithLabelValues("metrics_test", ShadowCheckOutcomeMismatch)), 0)
+	})
+}
+
+func TestShouldShadow(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		require.True(t, ShouldShadow(Hundred))
+		require.False(t, ShouldShadow(0))
+	}
 }
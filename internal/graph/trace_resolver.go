@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// DefaultMaxTraceNodes is the default maximum number of nodes of a ResolutionTrace.
+const DefaultMaxTraceNodes = 500
+
+// ResolutionTraceNode is a sub-problem of the resolution of a Check: whether User has Relation
+// on Object. Its children are the sub-problems that it dispatched.
+type ResolutionTraceNode struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	User     string `json:"user"`
+	Allowed  bool   `json:"allowed"`
+
+	// Error is the error of the sub-problem, e.g. a cancellation once a sibling resolved the
+	// Check of its parent.
+	Error string `json:"error,omitempty"`
+
+	// DurationMicros is the wall time of the resolution of the sub-problem, its children included.
+	DurationMicros int64 `json:"duration_us"`
+
+	Children []*ResolutionTraceNode `json:"children,omitempty"`
+
+	start time.Time
+}
+
+// ResolutionTrace is the tree of the sub-problems of the resolution of a Check.
+type ResolutionTrace struct {
+	Root *ResolutionTraceNode `json:"root"`
+
+	// Truncated reports that the resolution had more sub-problems than the maximum number of
+	// nodes of the trace, and that the ones beyond it were left out.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type traceNodeCtxKey struct{}
+
+// TracingCheckResolver records the ResolveCheck calls of the resolution of a single Check, with
+// their timing, as a ResolutionTrace. It must be the delegate of the resolvers of the chain, so
+// that it observes every sub-problem, and must not be shared across Checks.
+//
+// It selects the default strategy of the LocalChecker for the requests that it traces, instead of
+// the ones of the planner, so that every sub-problem is dispatched and the shape of the trace
+// doesn't depend on the strategies sampled by the planner.
+type TracingCheckResolver struct {
+	delegate CheckResolver
+	maxNodes int
+
+	mu    sync.Mutex
+	nodes int
+	trace ResolutionTrace
+}
+
+var _ CheckResolver = (*TracingCheckResolver)(nil)
+
+type TracingCheckResolverOpt func(*TracingCheckResolver)
+
+// WithMaxTraceNodes bounds the number of nodes of the trace, DefaultMaxTraceNodes by default.
+func WithMaxTraceNodes(maxNodes int) TracingCheckResolverOpt {
+	return func(r *TracingCheckResolver) {
+		r.maxNodes = maxNodes
+	}
+}
+
+// NewTracingCheckResolver constructs a CheckResolver that traces the sub-problems that it
+// delegates.
+func NewTracingCheckResolver(opts ...TracingCheckResolverOpt) *TracingCheckResolver {
+	r := &TracingCheckResolver{maxNodes: DefaultMaxTraceNodes}
+	r.delegate = r
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// SetDelegate sets this TracingCheckResolver's dispatch delegate.
+func (r *TracingCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this TracingCheckResolver's dispatch delegate.
+func (r *TracingCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop.
+func (r *TracingCheckResolver) Close() {}
+
+// Trace returns the trace of the resolution. It must be called once the Check has returned.
+func (r *TracingCheckResolver) Trace() *ResolutionTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &r.trace
+}
+
+func (r *TracingCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	tk := req.GetTupleKey()
+	node := &ResolutionTraceNode{
+		Object:   tk.GetObject(),
+		Relation: tk.GetRelation(),
+		User:     tk.GetUser(),
+		start:    time.Now(),
+	}
+
+	req.SelectedStrategy = defaultResolver
+
+	parent, _ := ctx.Value(traceNodeCtxKey{}).(*ResolutionTraceNode)
+	if !r.addNode(parent, node) {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	resp, err := r.delegate.ResolveCheck(context.WithValue(ctx, traceNodeCtxKey{}, node), req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	node.DurationMicros = time.Since(node.start).Microseconds()
+	node.Allowed = resp.GetAllowed()
+	if err != nil {
+		node.Error = err.Error()
+	}
+	return resp, err
+}
+
+// addNode adds node to the children of parent, or as the root of the trace if parent is nil. It
+// returns false if the trace is full.
+func (r *TracingCheckResolver) addNode(parent, node *ResolutionTraceNode) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nodes >= r.maxNodes {
+		r.trace.Truncated = true
+		return false
+	}
+	r.nodes++
+	if parent == nil {
+		r.trace.Root = node
+	} else {
+		parent.Children = append(parent.Children, node)
+	}
+	return true
+}
+
+// DOT renders the trace in the DOT language of Graphviz, from the Check to its sub-problems. The
+// allowed sub-problems are green and the failed ones red.
+func (t *ResolutionTrace) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph {\n")
+	b.WriteString("graph [rankdir=TB];\n")
+	b.WriteString("node [shape=box];\n")
+
+	var id int
+	var writeNode func(node *ResolutionTraceNode) int
+	writeNode = func(node *ResolutionTraceNode) int {
+		nodeID := id
+		id++
+
+		label := fmt.Sprintf("%s\n%s\n%s", tuple.ToObjectRelationString(node.Object, node.Relation), node.User, time.Duration(node.DurationMicros)*time.Microsecond)
+		attrs := ""
+		switch {
+		case node.Error != "":
+			attrs = ", color=red"
+		case node.Allowed:
+			attrs = ", color=green"
+		}
+		fmt.Fprintf(&b, "n%d [label=%q%s];\n", nodeID, label, attrs)
+
+		for _, child := range node.Children {
+			fmt.Fprintf(&b, "n%d -> n%d;\n", nodeID, writeNode(child))
+		}
+		return nodeID
+	}
+	if t.Root != nil {
+		writeNode(t.Root)
+	}
+	if t.Truncated {
+		b.WriteString("truncated [shape=plaintext, label=\"truncated\"];\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
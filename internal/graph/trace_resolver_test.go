@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestTracingCheckResolver(t *testing.T) {
+	ctx := context.Background()
+
+	newRequest := func(t *testing.T, object string) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey(object, "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		return req
+	}
+
+	// newResolver returns a TracingCheckResolver whose delegate dispatches the Check of document:1
+	// to the ones of folder:a and folder:b, through the TracingCheckResolver.
+	newResolver := func(t *testing.T, opts ...TracingCheckResolverOpt) *TracingCheckResolver {
+		ctrl := gomock.NewController(t)
+		mockResolver := NewMockCheckResolver(ctrl)
+		resolver := NewTracingCheckResolver(opts...)
+		resolver.SetDelegate(mockResolver)
+
+		mockResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			require.Equal(t, defaultResolver, req.GetSelectedStrategy())
+			switch req.GetTupleKey().GetObject() {
+			case "document:1":
+				_, errA := resolver.ResolveCheck(ctx, newRequest(t, "folder:a"))
+				respB, errB := resolver.ResolveCheck(ctx, newRequest(t, "folder:b"))
+				return respB, errors.Join(errA, errB)
+			case "folder:a":
+				return nil, context.Canceled
+			default:
+				return &ResolveCheckResponse{Allowed: true}, nil
+			}
+		}).AnyTimes()
+		return resolver
+	}
+
+	t.Run("records_the_tree", func(t *testing.T) {
+		resolver := newResolver(t)
+		_, err := resolver.ResolveCheck(ctx, newRequest(t, "document:1"))
+		require.ErrorIs(t, err, context.Canceled)
+
+		trace := resolver.Trace()
+		require.False(t, trace.Truncated)
+		require.Equal(t, "document:1", trace.Root.Object)
+		require.Len(t, trace.Root.Children, 2)
+		require.Equal(t, "folder:a", trace.Root.Children[0].Object)
+		require.Equal(t, context.Canceled.Error(), trace.Root.Children[0].Error)
+		require.Equal(t, "folder:b", trace.Root.Children[1].Object)
+		require.True(t, trace.Root.Children[1].Allowed)
+
+		dot := trace.DOT()
+		require.Contains(t, dot, `n0 -> n1;`)
+		require.Contains(t, dot, `n0 -> n2;`)
+		require.Contains(t, dot, `n1 [label="folder:a#viewer\nuser:anne\n`)
+		require.Contains(t, dot, `color=red`)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		resolver := newResolver(t, WithMaxTraceNodes(2))
+		_, err := resolver.ResolveCheck(ctx, newRequest(t, "document:1"))
+		require.ErrorIs(t, err, context.Canceled)
+
+		trace := resolver.Trace()
+		require.True(t, trace.Truncated)
+		require.Len(t, trace.Root.Children, 1)
+		require.Contains(t, trace.DOT(), "truncated")
+	})
+}
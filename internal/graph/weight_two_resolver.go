@@ -217,13 +217,12 @@ func produceLeftChannels(
 		if err != nil {
 			continue
 		}
-		r := req.clone()
-		r.TupleKey = &openfgav1.TupleKey{
+		r := req.cloneWithTupleKey(&openfgav1.TupleKey{
 			Object: tuple.BuildObject(parentType.GetType(), "ignore"),
 			// depending on relationFunc, it will return the parentType's relation (userset) or computedRelation (TTU)
 			Relation: relation,
-			User:     r.GetTupleKey().GetUser(),
-		}
+			User:     req.GetTupleKey().GetUser(),
+		})
 		leftChan, err := fastPathRewrite(ctx, r, rel.GetRewrite())
 		if err != nil {
 			// if the resolver already started it needs to be drained
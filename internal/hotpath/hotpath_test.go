@@ -0,0 +1,175 @@
+package hotpath
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const model = `
+	model
+		schema 1.1
+	type user
+	type group
+		relations
+			define member: [user, group#member]
+	type folder
+		relations
+			define viewer: [user, user:*, group#member]
+	type document
+		relations
+			define parent: [folder]
+			define owner: [user]
+			define viewer: [user] or owner or viewer from parent
+			define restricted: [user] but not owner`
+
+func setup(t *testing.T) (storage.OpenFGADatastore, string, string) {
+	t.Helper()
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	authzModel := testutils.MustTransformDSLToProtoWithID(model)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, authzModel))
+
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+		tuple.NewTupleKey("folder:eng", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("folder:public", "viewer", "user:*"),
+		tuple.NewTupleKey("document:1", "parent", "folder:eng"),
+		tuple.NewTupleKey("document:2", "owner", "user:bob"),
+		tuple.NewTupleKey("document:3", "parent", "folder:public"),
+	}))
+
+	return ds, storeID, authzModel.GetId()
+}
+
+func typesystemResolver(ds storage.OpenFGADatastore) typesystem.TypesystemResolverFunc {
+	return func(ctx context.Context, storeID, modelID string) (*typesystem.TypeSystem, error) {
+		model, err := ds.ReadAuthorizationModel(ctx, storeID, modelID)
+		if err != nil {
+			return nil, err
+		}
+		return typesystem.NewAndValidate(ctx, model)
+	}
+}
+
+func TestIndexHottest(t *testing.T) {
+	index := NewIndex()
+	for range 3 {
+		index.Observe("store1", "model", "document", "viewer")
+	}
+	for range 2 {
+		index.Observe("store1", "model", "folder", "viewer")
+		index.Observe("store2", "model", "document", "viewer")
+	}
+	index.Observe("store1", "model", "group", "member")
+
+	require.Equal(t, []Pair{
+		{StoreID: "store1", ModelID: "model", ObjectType: "document", Relation: "viewer", Checks: 3},
+		{StoreID: "store1", ModelID: "model", ObjectType: "folder", Relation: "viewer", Checks: 2},
+	}, filterStore(index.Hottest(2, 2), "store1"))
+	require.Len(t, filterStore(index.Hottest(2, 2), "store2"), 0, "the counts are reset")
+}
+
+func filterStore(pairs []Pair, storeID string) []Pair {
+	var filtered []Pair
+	for _, pair := range pairs {
+		if pair.StoreID == storeID {
+			filtered = append(filtered, pair)
+		}
+	}
+	return filtered
+}
+
+func TestPrecomputer(t *testing.T) {
+	ctx := context.Background()
+
+	precompute := func(t *testing.T, ds storage.OpenFGADatastore, storeID, modelID string, opts ...PrecomputerOption) *Index {
+		t.Helper()
+		index := NewIndex()
+		index.Observe(storeID, modelID, "document", "viewer")
+		index.Observe(storeID, modelID, "document", "restricted")
+		opts = append(opts, WithMinChecks(1))
+		require.NoError(t, NewPrecomputer(index, ds, typesystemResolver(ds), opts...).Run(ctx))
+		return index
+	}
+
+	t.Run("answers_checks", func(t *testing.T) {
+		ds, storeID, modelID := setup(t)
+		index := precompute(t, ds, storeID, modelID)
+
+		tests := map[string]bool{
+			"document:1#viewer@user:anne":    true,
+			"document:1#viewer@user:bob":     false,
+			"document:2#viewer@user:bob":     true,
+			"document:3#viewer@user:charlie": true,
+			"document:4#viewer@user:anne":    false,
+		}
+		for key, expected := range tests {
+			allowed, ok := index.Lookup(storeID, modelID, tuple.MustParseTupleString(key), time.Time{})
+			require.True(t, ok, key)
+			require.Equal(t, expected, allowed, key)
+		}
+	})
+
+	t.Run("does_not_answer", func(t *testing.T) {
+		ds, storeID, modelID := setup(t)
+		index := precompute(t, ds, storeID, modelID)
+
+		for _, key := range []string{
+			"document:1#viewer@group:eng#member",
+			"document:1#restricted@user:anne",
+			"document:1#owner@user:anne",
+		} {
+			_, ok := index.Lookup(storeID, modelID, tuple.MustParseTupleString(key), time.Time{})
+			require.False(t, ok, key)
+		}
+
+		_, ok := index.Lookup(storeID, modelID, tuple.NewTupleKey("document:1", "viewer", "user:anne"), time.Now())
+		require.False(t, ok, "precomputed before the last invalidation")
+	})
+
+	t.Run("invalidates_on_relevant_writes", func(t *testing.T) {
+		ds, storeID, modelID := setup(t)
+		tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+		index := precompute(t, ds, storeID, modelID)
+		index.Invalidate(storeID, "user")
+		_, ok := index.Lookup(storeID, modelID, tk, time.Time{})
+		require.True(t, ok)
+
+		index.Invalidate(storeID, "group")
+		_, ok = index.Lookup(storeID, modelID, tk, time.Time{})
+		require.False(t, ok)
+	})
+
+	t.Run("skips_types_with_too_many_objects", func(t *testing.T) {
+		ds, storeID, modelID := setup(t)
+		index := precompute(t, ds, storeID, modelID, WithMaxObjects(2))
+
+		_, ok := index.Lookup(storeID, modelID, tuple.NewTupleKey("document:1", "viewer", "user:anne"), time.Time{})
+		require.False(t, ok)
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		ds, storeID, modelID := setup(t)
+		index := precompute(t, ds, storeID, modelID)
+		index.ttl = 0
+
+		_, ok := index.Lookup(storeID, modelID, tuple.NewTupleKey("document:1", "viewer", "user:anne"), time.Time{})
+		require.False(t, ok)
+	})
+}
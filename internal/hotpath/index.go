@@ -0,0 +1,217 @@
+// Package hotpath tracks the (object type, relation) pairs checked the most in each store, and
+// precomputes their membership in the background so that the Checks of these pairs are answered
+// with a single lookup instead of a traversal of the graph.
+package hotpath
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// Pair is an (object type, relation) pair of an authorization model, and the number of Checks of it.
+type Pair struct {
+	StoreID    string
+	ModelID    string
+	ObjectType string
+	Relation   string
+	Checks     uint64
+}
+
+type pairKey struct {
+	storeID    string
+	modelID    string
+	objectType string
+	relation   string
+}
+
+// membership is the precomputed set of users related to every object of a pair.
+type membership struct {
+	builtAt time.Time
+
+	// objectTypes are the types of the objects whose tuples can change the membership.
+	objectTypes map[string]struct{}
+
+	// userTypes are the types of the users the membership was computed for.
+	userTypes map[string]struct{}
+
+	// users maps each object to its related users, including the typed wildcards.
+	users map[string]map[string]struct{}
+}
+
+// IndexOption defines an option that can be used to change the behavior of an Index.
+type IndexOption func(*Index)
+
+// WithTTL sets how long a precomputed membership is used for. Defaults to 2 minutes.
+func WithTTL(ttl time.Duration) IndexOption {
+	return func(i *Index) {
+		i.ttl = ttl
+	}
+}
+
+// Index counts the Checks of every pair and holds the precomputed memberships. It implements
+// graph.HotPathIndex.
+type Index struct {
+	ttl time.Duration
+
+	counts sync.Map // pairKey => *atomic.Uint64
+
+	mu          sync.RWMutex
+	memberships map[pairKey]*membership
+	invalidated map[string]map[string]time.Time // store => object type => time of the last write
+}
+
+var _ graph.HotPathIndex = (*Index)(nil)
+
+// NewIndex returns an empty Index.
+func NewIndex(opts ...IndexOption) *Index {
+	i := &Index{
+		ttl:         2 * time.Minute,
+		memberships: make(map[pairKey]*membership),
+		invalidated: make(map[string]map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Observe counts one Check of the relation of an object of objectType.
+func (i *Index) Observe(storeID, modelID, objectType, relation string) {
+	key := pairKey{storeID: storeID, modelID: modelID, objectType: objectType, relation: relation}
+	counter, ok := i.counts.Load(key)
+	if !ok {
+		counter, _ = i.counts.LoadOrStore(key, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+// Hottest returns, for every store, the n pairs checked the most, and at least minChecks times,
+// since the previous call. The counts are reset.
+func (i *Index) Hottest(n int, minChecks uint64) []Pair {
+	byStore := make(map[string][]Pair)
+	i.counts.Range(func(k, v any) bool {
+		key := k.(pairKey)
+		checks := v.(*atomic.Uint64).Swap(0)
+		if checks == 0 {
+			// not checked since the previous call
+			i.counts.Delete(key)
+			return true
+		}
+		if checks >= minChecks {
+			byStore[key.storeID] = append(byStore[key.storeID], Pair{
+				StoreID:    key.storeID,
+				ModelID:    key.modelID,
+				ObjectType: key.objectType,
+				Relation:   key.relation,
+				Checks:     checks,
+			})
+		}
+		return true
+	})
+
+	var hottest []Pair
+	for _, pairs := range byStore {
+		slices.SortFunc(pairs, func(a, b Pair) int {
+			if c := cmp.Compare(b.Checks, a.Checks); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ObjectType+"#"+a.Relation, b.ObjectType+"#"+b.Relation)
+		})
+		hottest = append(hottest, pairs[:min(n, len(pairs))]...)
+	}
+	return hottest
+}
+
+// Lookup answers the Check of tk from the membership of its pair, if it was precomputed after
+// notBefore and is still fresh. Checks of usersets and wildcards are never answered.
+func (i *Index) Lookup(storeID, modelID string, tk *openfgav1.TupleKey, notBefore time.Time) (bool, bool) {
+	user := tk.GetUser()
+	if tuple.IsObjectRelation(user) || tuple.IsTypedWildcard(user) {
+		return false, false
+	}
+	userType := tuple.GetType(user)
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	m, ok := i.memberships[pairKey{storeID: storeID, modelID: modelID, objectType: tuple.GetType(tk.GetObject()), relation: tk.GetRelation()}]
+	if !ok || !m.builtAt.After(notBefore) || time.Since(m.builtAt) > i.ttl {
+		return false, false
+	}
+	if _, ok := m.userTypes[userType]; !ok {
+		return false, false
+	}
+
+	users := m.users[tk.GetObject()]
+	if _, ok := users[user]; ok {
+		return true, true
+	}
+	_, ok = users[tuple.TypedPublicWildcard(userType)]
+	return ok, true
+}
+
+// Invalidate drops the memberships of the store that depend on the tuples of objects of the
+// objectTypes, as these tuples were just written or deleted.
+func (i *Index) Invalidate(storeID string, objectTypes ...string) {
+	if len(objectTypes) == 0 {
+		return
+	}
+	now := time.Now()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	invalidated, ok := i.invalidated[storeID]
+	if !ok {
+		invalidated = make(map[string]time.Time)
+		i.invalidated[storeID] = invalidated
+	}
+	for _, objectType := range objectTypes {
+		invalidated[objectType] = now
+	}
+
+	for key, m := range i.memberships {
+		if key.storeID != storeID {
+			continue
+		}
+		for _, objectType := range objectTypes {
+			if _, ok := m.objectTypes[objectType]; ok {
+				delete(i.memberships, key)
+				break
+			}
+		}
+	}
+}
+
+// set stores the membership of a pair, unless one of the tuples it depends on was written after
+// it started being computed. It reports whether the membership was stored.
+func (i *Index) set(key pairKey, m *membership) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for objectType := range m.objectTypes {
+		if writtenAt, ok := i.invalidated[key.storeID][objectType]; ok && !m.builtAt.After(writtenAt) {
+			return false
+		}
+	}
+
+	// drop the expired memberships, whose pair is no longer hot
+	for k, existing := range i.memberships {
+		if time.Since(existing.builtAt) > i.ttl {
+			delete(i.memberships, k)
+		}
+	}
+
+	i.memberships[key] = m
+	return true
+}
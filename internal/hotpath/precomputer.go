@@ -0,0 +1,377 @@
+package hotpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var (
+	// ErrNotEligible is returned when the membership of a pair cannot be precomputed, either
+	// because its relation involves an intersection, an exclusion or a condition, or because its
+	// type has too many objects.
+	ErrNotEligible = errors.New("membership cannot be precomputed")
+
+	tracer = otel.Tracer("internal/hotpath")
+
+	precomputedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "hot_path_precomputations_count",
+		Help:      "The total number of hot path memberships computed in the background, by outcome.",
+	}, []string{"outcome"})
+)
+
+// PrecomputerOption defines an option that can be used to change the behavior of a Precomputer.
+type PrecomputerOption func(*Precomputer)
+
+// WithInterval sets the time between two runs of the Precomputer.
+func WithInterval(interval time.Duration) PrecomputerOption {
+	return func(p *Precomputer) {
+		p.interval = interval
+	}
+}
+
+// WithTopN sets the number of pairs per store precomputed on each run.
+func WithTopN(n int) PrecomputerOption {
+	return func(p *Precomputer) {
+		p.topN = n
+	}
+}
+
+// WithMinChecks sets the number of Checks a pair must receive between two runs to be precomputed.
+func WithMinChecks(minChecks uint64) PrecomputerOption {
+	return func(p *Precomputer) {
+		p.minChecks = minChecks
+	}
+}
+
+// WithMaxObjects sets the maximum number of objects of a type whose membership is precomputed.
+func WithMaxObjects(maxObjects int) PrecomputerOption {
+	return func(p *Precomputer) {
+		p.maxObjects = maxObjects
+	}
+}
+
+// WithLogger sets the logger of the Precomputer.
+func WithLogger(l logger.Logger) PrecomputerOption {
+	return func(p *Precomputer) {
+		p.logger = l
+	}
+}
+
+// Precomputer periodically computes the membership of the hottest pairs of an Index.
+type Precomputer struct {
+	index              *Index
+	datastore          storage.RelationshipTupleReader
+	typesystemResolver typesystem.TypesystemResolverFunc
+	interval           time.Duration
+	topN               int
+	minChecks          uint64
+	maxObjects         int
+	logger             logger.Logger
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// NewPrecomputer returns a Precomputer of the memberships of index, read from datastore.
+func NewPrecomputer(index *Index, datastore storage.RelationshipTupleReader, typesystemResolver typesystem.TypesystemResolverFunc, opts ...PrecomputerOption) *Precomputer {
+	p := &Precomputer{
+		index:              index,
+		datastore:          datastore,
+		typesystemResolver: typesystemResolver,
+		interval:           time.Minute,
+		topN:               10,
+		minChecks:          100,
+		maxObjects:         1000,
+		logger:             logger.NewNoopLogger(),
+		stop:               make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Start runs the Precomputer in the background every interval until Stop is called.
+func (p *Precomputer) Start() {
+	ticker := time.NewTicker(p.interval)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-p.stop
+			cancel()
+		}()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.Run(ctx); err != nil && ctx.Err() == nil {
+					p.logger.Error("hot path precomputation failed", zap.Error(err))
+				}
+			case <-p.stop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background routine started by Start.
+func (p *Precomputer) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// Run precomputes the membership of the hottest pairs of every store once.
+func (p *Precomputer) Run(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "hotpath.Precomputer.Run")
+	defer span.End()
+
+	pairs := p.index.Hottest(p.topN, p.minChecks)
+	span.SetAttributes(attribute.Int("pairs", len(pairs)))
+
+	var errs error
+	for _, pair := range pairs {
+		err := p.Precompute(ctx, pair)
+		switch {
+		case err == nil:
+			precomputedCounter.WithLabelValues("precomputed").Inc()
+		case errors.Is(err, ErrNotEligible):
+			precomputedCounter.WithLabelValues("not_eligible").Inc()
+			p.logger.Debug("hot path not precomputed",
+				zap.String("store_id", pair.StoreID),
+				zap.String("authorization_model_id", pair.ModelID),
+				zap.String("userset", pair.ObjectType+"#"+pair.Relation),
+				zap.Error(err))
+		default:
+			precomputedCounter.WithLabelValues("error").Inc()
+			errs = errors.Join(errs, fmt.Errorf("store '%s', '%s#%s': %w", pair.StoreID, pair.ObjectType, pair.Relation, err))
+		}
+	}
+	return errs
+}
+
+// Precompute computes the users related to every object of the pair, and stores them in the Index.
+func (p *Precomputer) Precompute(ctx context.Context, pair Pair) error {
+	ctx, span := tracer.Start(ctx, "hotpath.Precomputer.Precompute", trace.WithAttributes(
+		attribute.String("store_id", pair.StoreID),
+		attribute.String("userset", pair.ObjectType+"#"+pair.Relation),
+	))
+	defer span.End()
+
+	typesys, err := p.typesystemResolver(ctx, pair.StoreID, pair.ModelID)
+	if err != nil {
+		return err
+	}
+
+	builtAt := time.Now()
+	deps, err := Analyze(typesys, pair.ObjectType, pair.Relation)
+	if err != nil {
+		return err
+	}
+	m := &membership{
+		builtAt:     builtAt,
+		objectTypes: deps.ObjectTypes,
+		userTypes:   deps.UserTypes,
+		users:       make(map[string]map[string]struct{}),
+	}
+
+	objects, err := p.readObjects(ctx, pair.StoreID, pair.ObjectType)
+	if err != nil {
+		return err
+	}
+
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+	for _, object := range objects {
+		objectType, objectID := tuple.SplitObject(object)
+		users := make(map[string]struct{})
+		for userType := range m.userTypes {
+			resp, err := listusers.NewListUsersQuery(p.datastore, nil,
+				listusers.WithListUsersMaxResults(0),
+				listusers.WithListUsersDeadline(0),
+				listusers.WithListUsersQueryLogger(p.logger),
+			).ListUsers(ctx, &openfgav1.ListUsersRequest{
+				StoreId:              pair.StoreID,
+				AuthorizationModelId: pair.ModelID,
+				Object:               &openfgav1.Object{Type: objectType, Id: objectID},
+				Relation:             pair.Relation,
+				UserFilters:          []*openfgav1.UserTypeFilter{{Type: userType}},
+			})
+			if err != nil {
+				return err
+			}
+			// ListUsers returns partial results when its context is done
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for _, user := range resp.Users {
+				users[tuple.UserProtoToString(user)] = struct{}{}
+			}
+		}
+		if len(users) > 0 {
+			m.users[object] = users
+		}
+	}
+
+	if !p.index.set(pairKey{storeID: pair.StoreID, modelID: pair.ModelID, objectType: pair.ObjectType, relation: pair.Relation}, m) {
+		span.SetAttributes(attribute.Bool("invalidated", true))
+	}
+	return nil
+}
+
+// readObjects returns the objects of objectType that have at least one tuple. The objects without
+// tuples are related to no user.
+func (p *Precomputer) readObjects(ctx context.Context, storeID, objectType string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var objects []string
+	var continuationToken string
+	for {
+		tuples, token, err := p.datastore.ReadPage(ctx, storeID, storage.ReadFilter{Object: objectType + ":"}, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tuples {
+			object := t.GetKey().GetObject()
+			if _, ok := seen[object]; ok {
+				continue
+			}
+			if len(objects) == p.maxObjects {
+				return nil, fmt.Errorf("%w: more than %d objects of type '%s'", ErrNotEligible, p.maxObjects, objectType)
+			}
+			seen[object] = struct{}{}
+			objects = append(objects, object)
+		}
+
+		if token == "" {
+			return objects, nil
+		}
+		continuationToken = token
+	}
+}
+
+// Dependencies are what the users related to an object by a relation are computed from.
+type Dependencies struct {
+	// ObjectTypes are the types of the objects whose tuples are read to resolve the relation.
+	ObjectTypes map[string]struct{}
+
+	// UserTypes are the types of the users the relation can relate to.
+	UserTypes map[string]struct{}
+
+	// CrossObject reports whether the relation of an object is resolved from the tuples of other
+	// objects, through a tupleset or a userset, rather than from its own tuples only.
+	CrossObject bool
+}
+
+// Analyze returns the Dependencies of the relation of objectType. It returns ErrNotEligible if the
+// relation involves an intersection, an exclusion or a condition.
+func Analyze(typesys *typesystem.TypeSystem, objectType, relation string) (*Dependencies, error) {
+	deps := &Dependencies{
+		ObjectTypes: make(map[string]struct{}),
+		UserTypes:   make(map[string]struct{}),
+	}
+	if err := analyze(typesys, deps, objectType, relation, make(map[string]struct{})); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// analyze walks the rewrite of the relation of objectType, and collects its dependencies in deps.
+func analyze(typesys *typesystem.TypeSystem, deps *Dependencies, objectType, relation string, visited map[string]struct{}) error {
+	userset := tuple.ToObjectRelationString(objectType, relation)
+	if _, ok := visited[userset]; ok {
+		return nil
+	}
+	visited[userset] = struct{}{}
+
+	rel, err := typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return err
+	}
+	return analyzeRewrite(typesys, deps, objectType, relation, rel.GetRewrite(), visited)
+}
+
+func analyzeRewrite(typesys *typesystem.TypeSystem, deps *Dependencies, objectType, relation string, rewrite *openfgav1.Userset, visited map[string]struct{}) error {
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		deps.ObjectTypes[objectType] = struct{}{}
+		refs, err := typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if ref.GetCondition() != "" {
+				return fmt.Errorf("%w: '%s#%s' is conditional", ErrNotEligible, objectType, relation)
+			}
+			if ref.GetRelation() != "" {
+				deps.CrossObject = true
+				if err := analyze(typesys, deps, ref.GetType(), ref.GetRelation(), visited); err != nil {
+					return err
+				}
+				continue
+			}
+			deps.UserTypes[ref.GetType()] = struct{}{}
+		}
+		return nil
+	case *openfgav1.Userset_ComputedUserset:
+		return analyze(typesys, deps, objectType, rw.ComputedUserset.GetRelation(), visited)
+	case *openfgav1.Userset_TupleToUserset:
+		deps.ObjectTypes[objectType] = struct{}{}
+		deps.CrossObject = true
+		tupleset := rw.TupleToUserset.GetTupleset().GetRelation()
+		computed := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		refs, err := typesys.GetDirectlyRelatedUserTypes(objectType, tupleset)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if ref.GetCondition() != "" {
+				return fmt.Errorf("%w: '%s#%s' is conditional", ErrNotEligible, objectType, tupleset)
+			}
+			if _, err := typesys.GetRelation(ref.GetType(), computed); err != nil {
+				// the relation is only defined on some of the types of the tupleset
+				continue
+			}
+			if err := analyze(typesys, deps, ref.GetType(), computed, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			if err := analyzeRewrite(typesys, deps, objectType, relation, child, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: '%s#%s' has an intersection or an exclusion", ErrNotEligible, objectType, relation)
+	}
+}
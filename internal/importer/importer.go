@@ -0,0 +1,87 @@
+// Package importer contains the building blocks shared by the tools that translate authorization
+// schemas and relationship data from other ReBAC systems into OpenFGA models and tuples.
+package importer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// Issue describes a construct of the source system that could not be translated, or that was
+// translated with a loss of semantics. Constructs that cannot be translated faithfully are
+// always left out of the output, so that an import never grants more access than the source.
+type Issue struct {
+	// Line is the 1-based line of the source input where the construct was found, or 0 if unknown.
+	Line int `json:"line,omitempty"`
+
+	// Construct is a short description of the untranslatable construct (e.g. "permission document#view").
+	Construct string `json:"construct"`
+
+	// Message explains why the construct was not translated.
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", i.Line, i.Construct, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Construct, i.Message)
+}
+
+// ValidateModel runs the OpenFGA model validations against a translated model and returns the
+// validation error, if any, as an Issue.
+func ValidateModel(ctx context.Context, model *openfgav1.AuthorizationModel) []Issue {
+	if _, err := typesystem.NewAndValidate(ctx, model); err != nil {
+		return []Issue{{
+			Construct: "authorization model",
+			Message:   fmt.Sprintf("the translated model is not valid: %s", err),
+		}}
+	}
+	return nil
+}
+
+// TupleWriter writes tuple keys as a stream of newline-delimited JSON objects, in the format
+// accepted by the OpenFGA tuple import tooling.
+type TupleWriter struct {
+	w       *bufio.Writer
+	written int
+}
+
+// NewTupleWriter returns a TupleWriter that writes to w.
+func NewTupleWriter(w io.Writer) *TupleWriter {
+	return &TupleWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes a single tuple key.
+func (t *TupleWriter) Write(tk *openfgav1.TupleKey) error {
+	b, err := protojson.Marshal(tk)
+	if err != nil {
+		return fmt.Errorf("marshal tuple: %w", err)
+	}
+	if _, err := t.w.Write(b); err != nil {
+		return err
+	}
+	if err := t.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	t.written++
+	return nil
+}
+
+// Written returns the number of tuples written so far.
+func (t *TupleWriter) Written() int {
+	return t.written
+}
+
+// Flush writes any buffered tuples to the underlying writer.
+func (t *TupleWriter) Flush() error {
+	return t.w.Flush()
+}
@@ -0,0 +1,103 @@
+package spicedb
+
+import (
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// expression is a node of a parsed SpiceDB permission expression.
+type expression interface {
+	isExpression()
+}
+
+// relationExpression references another relation or permission of the same definition.
+type relationExpression string
+
+// arrowExpression walks a relation and computes a permission on the resulting objects
+// (`tupleset->computed`, `tupleset.any(computed)` or `tupleset.all(computed)`).
+type arrowExpression struct {
+	tupleset string
+	computed string
+	function string
+}
+
+// nilExpression is the SpiceDB `nil` expression, which never matches any subject.
+type nilExpression struct{}
+
+type binaryExpression struct {
+	op            string
+	left, right   expression
+	parenthesized bool
+}
+
+func (relationExpression) isExpression() {}
+func (*arrowExpression) isExpression()   {}
+func (nilExpression) isExpression()      {}
+func (*binaryExpression) isExpression()  {}
+
+// translateExpression translates a permission expression into an OpenFGA userset rewrite.
+// If the expression cannot be translated, a nil rewrite and the reason are returned.
+func translateExpression(e expression) (*openfgav1.Userset, string) {
+	switch e := e.(type) {
+	case relationExpression:
+		return typesystem.ComputedUserset(string(e)), ""
+	case *arrowExpression:
+		if e.function == "all" {
+			return nil, fmt.Sprintf("the intersection arrow '%s.all(%s)' has no OpenFGA equivalent", e.tupleset, e.computed)
+		}
+		return typesystem.TupleToUserset(e.tupleset, e.computed), ""
+	case nilExpression:
+		return nil, "the 'nil' expression has no OpenFGA equivalent"
+	case *binaryExpression:
+		left, reason := translateExpression(e.left)
+		if left == nil {
+			return nil, reason
+		}
+		right, reason := translateExpression(e.right)
+		if right == nil {
+			return nil, reason
+		}
+
+		switch e.op {
+		case "+":
+			return typesystem.Union(append(flatten(left, "+"), flatten(right, "+")...)...), ""
+		case "&":
+			return typesystem.Intersection(append(flatten(left, "&"), flatten(right, "&")...)...), ""
+		default:
+			return typesystem.Difference(left, right), ""
+		}
+	default:
+		return nil, fmt.Sprintf("unknown expression %T", e)
+	}
+}
+
+// flatten returns the children of a union or intersection rewrite of the same kind as op,
+// so that chains such as `a + b + c` translate into a single union with three children.
+func flatten(u *openfgav1.Userset, op string) []*openfgav1.Userset {
+	switch {
+	case op == "+" && u.GetUnion() != nil:
+		return u.GetUnion().GetChild()
+	case op == "&" && u.GetIntersection() != nil:
+		return u.GetIntersection().GetChild()
+	default:
+		return []*openfgav1.Userset{u}
+	}
+}
+
+// hasMixedOperators reports whether a binary expression combines different operators without
+// parentheses, in which case the translation relies on operator precedence.
+func hasMixedOperators(e expression) bool {
+	b, ok := e.(*binaryExpression)
+	if !ok {
+		return false
+	}
+	for _, child := range []expression{b.left, b.right} {
+		if c, ok := child.(*binaryExpression); ok && !c.parenthesized && c.op != b.op {
+			return true
+		}
+	}
+	return hasMixedOperators(b.left) || hasMixedOperators(b.right)
+}
@@ -0,0 +1,144 @@
+package spicedb
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenPunct
+	tokenEllipsis
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+	// offset is the byte offset of the token in the input.
+	offset int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("'%s'", t.value)
+}
+
+// lexer splits a SpiceDB schema into tokens. Comments are skipped.
+type lexer struct {
+	input string
+	pos   int
+	line  int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: input, line: 1}
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == ';':
+			l.pos++
+		case strings.HasPrefix(l.input[l.pos:], "//"):
+			end := strings.IndexByte(l.input[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.input)
+			} else {
+				l.pos += end
+			}
+		case strings.HasPrefix(l.input[l.pos:], "/*"):
+			end := strings.Index(l.input[l.pos+2:], "*/")
+			if end < 0 {
+				return fmt.Errorf("line %d: unterminated comment", l.line)
+			}
+			l.line += strings.Count(l.input[l.pos:l.pos+2+end], "\n")
+			l.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '/' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// next returns the next token of the input.
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF, line: l.line, offset: l.pos}, nil
+	}
+
+	start := l.pos
+	switch {
+	case strings.HasPrefix(l.input[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenEllipsis, value: "...", line: l.line, offset: start}, nil
+	case strings.HasPrefix(l.input[l.pos:], "->"):
+		l.pos += 2
+		return token{kind: tokenPunct, value: "->", line: l.line, offset: start}, nil
+	}
+
+	r := rune(l.input[l.pos])
+	if isIdentRune(r) {
+		for l.pos < len(l.input) && isIdentRune(rune(l.input[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenIdent, value: l.input[start:l.pos], line: l.line, offset: start}, nil
+	}
+
+	if strings.ContainsRune("{}()<>:|#*,+-&=.", r) {
+		l.pos++
+		return token{kind: tokenPunct, value: string(r), line: l.line, offset: start}, nil
+	}
+
+	return token{}, fmt.Errorf("line %d: unexpected character '%c'", l.line, r)
+}
+
+// rawBlock returns the raw text up to the closing brace matching an opening brace that has
+// already been consumed. Braces inside string literals are ignored.
+func (l *lexer) rawBlock() (string, error) {
+	start := l.pos
+	depth := 1
+	var quote byte
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				l.pos++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				body := l.input[start:l.pos]
+				l.line += strings.Count(body, "\n")
+				l.pos++
+				return strings.TrimSpace(body), nil
+			}
+		}
+		l.pos++
+	}
+	return "", fmt.Errorf("line %d: unterminated block", l.line)
+}
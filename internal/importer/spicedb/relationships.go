@@ -0,0 +1,167 @@
+package spicedb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var ErrInvalidRelationship = errors.New("invalid SpiceDB relationship")
+
+// maxRelationshipLineBytes bounds the length of a single relationship line, including caveat context.
+const maxRelationshipLineBytes = 1 << 20
+
+// TranslateRelationships reads SpiceDB relationships, one per line in the
+// `resource:id#relation@subject:id[#relation][[caveat:{context}]]` format, and writes the
+// equivalent tuples to w. Blank lines and lines starting with `//` are ignored.
+//
+// Relationships whose relation or caveat is not part of the translated model, and relationships
+// with an expiration, are left out and reported as issues.
+func TranslateRelationships(r io.Reader, model *openfgav1.AuthorizationModel, w *importer.TupleWriter) ([]importer.Issue, error) {
+	relations := map[string]bool{}
+	for _, td := range model.GetTypeDefinitions() {
+		for relation, metadata := range td.GetMetadata().GetRelations() {
+			if len(metadata.GetDirectlyRelatedUserTypes()) > 0 {
+				relations[tuple.ToObjectRelationString(td.GetType(), relation)] = true
+			}
+		}
+	}
+
+	var issues []importer.Issue
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRelationshipLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+
+		rel, err := parseRelationship(text)
+		if err != nil {
+			return issues, fmt.Errorf("%w: line %d: %w", ErrInvalidRelationship, line, err)
+		}
+
+		switch {
+		case rel.expiration != "":
+			issues = append(issues, importer.Issue{Line: line, Construct: text, Message: "relationship expiration has no OpenFGA equivalent, so the relationship was left out"})
+			continue
+		case !relations[tuple.ToObjectRelationString(rel.objectType, rel.relation)]:
+			issues = append(issues, importer.Issue{Line: line, Construct: text, Message: fmt.Sprintf("the relation '%s#%s' is not part of the translated model, so the relationship was left out", rel.objectType, rel.relation)})
+			continue
+		case rel.caveat != "" && model.GetConditions()[rel.caveat] == nil:
+			issues = append(issues, importer.Issue{Line: line, Construct: text, Message: fmt.Sprintf("the caveat '%s' is not part of the translated model, so the relationship was left out", rel.caveat)})
+			continue
+		}
+
+		tk := tuple.NewTupleKey(tuple.BuildObject(rel.objectType, rel.objectID), rel.relation, rel.user)
+		if rel.caveat != "" {
+			tk.Condition = &openfgav1.RelationshipCondition{Name: rel.caveat, Context: rel.context}
+		}
+		if err := w.Write(tk); err != nil {
+			return issues, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return issues, fmt.Errorf("read relationships: %w", err)
+	}
+
+	return issues, nil
+}
+
+type relationship struct {
+	objectType string
+	objectID   string
+	relation   string
+	user       string
+	caveat     string
+	context    *structpb.Struct
+	expiration string
+}
+
+// parseRelationship parses a single relationship in the SpiceDB text format.
+func parseRelationship(text string) (*relationship, error) {
+	rel := &relationship{}
+
+	// Optional traits such as `[caveat:{...}]` and `[expiration:...]` follow the subject.
+	for strings.HasSuffix(text, "]") {
+		start := strings.LastIndex(text, "[")
+		if start < 0 {
+			return nil, fmt.Errorf("unbalanced ']' in '%s'", text)
+		}
+		// A caveat context may contain brackets, so look for the start of the trait instead.
+		for start > 0 && !isTraitStart(text[start:]) {
+			start = strings.LastIndex(text[:start], "[")
+		}
+		if start < 0 || !isTraitStart(text[start:]) {
+			return nil, fmt.Errorf("malformed trait in '%s'", text)
+		}
+
+		trait := text[start+1 : len(text)-1]
+		text = strings.TrimSpace(text[:start])
+
+		name, value, _ := strings.Cut(trait, ":")
+		if name == "expiration" {
+			rel.expiration = value
+			continue
+		}
+		rel.caveat = name
+		if value != "" {
+			ctx := &structpb.Struct{}
+			if err := ctx.UnmarshalJSON([]byte(value)); err != nil {
+				return nil, fmt.Errorf("invalid context for caveat '%s': %w", name, err)
+			}
+			rel.context = ctx
+		}
+	}
+
+	resource, subject, ok := strings.Cut(text, "@")
+	if !ok {
+		return nil, fmt.Errorf("missing '@' in '%s'", text)
+	}
+
+	object, relation, ok := strings.Cut(resource, "#")
+	if !ok || relation == "" {
+		return nil, fmt.Errorf("missing relation in '%s'", resource)
+	}
+	objectType, objectID, ok := strings.Cut(object, ":")
+	if !ok || objectType == "" || objectID == "" {
+		return nil, fmt.Errorf("invalid resource '%s'", object)
+	}
+	rel.objectType, rel.objectID, rel.relation = objectType, objectID, relation
+
+	subjectObject, subjectRelation, _ := strings.Cut(subject, "#")
+	subjectType, subjectID, ok := strings.Cut(subjectObject, ":")
+	if !ok || subjectType == "" || subjectID == "" {
+		return nil, fmt.Errorf("invalid subject '%s'", subject)
+	}
+	rel.user = tuple.BuildObject(subjectType, subjectID)
+	if subjectRelation != "" && subjectRelation != "..." {
+		rel.user = tuple.ToObjectRelationString(rel.user, subjectRelation)
+	}
+
+	return rel, nil
+}
+
+func isTraitStart(s string) bool {
+	name, _, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"), ":")
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !isIdentRune(r) {
+			return false
+		}
+	}
+	return true
+}
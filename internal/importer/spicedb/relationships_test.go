@@ -0,0 +1,72 @@
+package spicedb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/internal/importer"
+)
+
+func TestTranslateRelationships(t *testing.T) {
+	model, _, err := TranslateSchema(`
+definition user {}
+caveat on_weekday(day string) { day != "sunday" }
+definition group {
+	relation member: user | group#member
+}
+definition document {
+	relation viewer: user | group#member | user with on_weekday
+	permission view = viewer
+}
+`)
+	require.NoError(t, err)
+
+	relationships := `
+// comment
+document:1#viewer@user:anne
+document:1#viewer@group:eng#member
+group:eng#member@user:bob#...
+document:2#viewer@user:carl[on_weekday:{"day":"monday","tags":[1,2]}]
+document:3#viewer@user:dan[expiration:2030-01-01T00:00:00Z]
+document:3#view@user:erin
+document:3#viewer@user:fay[unknown_caveat]
+`
+	var out bytes.Buffer
+	w := importer.NewTupleWriter(&out)
+	issues, err := TranslateRelationships(strings.NewReader(relationships), model, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	require.Equal(t, 4, w.Written())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	require.JSONEq(t, `{"object":"document:1","relation":"viewer","user":"user:anne"}`, lines[0])
+	require.JSONEq(t, `{"object":"document:1","relation":"viewer","user":"group:eng#member"}`, lines[1])
+	require.JSONEq(t, `{"object":"group:eng","relation":"member","user":"user:bob"}`, lines[2])
+	require.JSONEq(t, `{"object":"document:2","relation":"viewer","user":"user:carl","condition":{"name":"on_weekday","context":{"day":"monday","tags":[1,2]}}}`, lines[3])
+
+	require.Len(t, issues, 3)
+	require.Equal(t, 7, issues[0].Line)
+	require.Contains(t, issues[0].Message, "expiration")
+	require.Equal(t, 8, issues[1].Line)
+	require.Contains(t, issues[1].Message, "'document#view' is not part of the translated model")
+	require.Equal(t, 9, issues[2].Line)
+	require.Contains(t, issues[2].Message, "'unknown_caveat'")
+}
+
+func TestTranslateRelationshipsErrors(t *testing.T) {
+	for name, relationship := range map[string]string{
+		"missing_subject":  `document:1#viewer`,
+		"missing_relation": `document:1@user:anne`,
+		"invalid_subject":  `document:1#viewer@anne`,
+		"invalid_context":  `document:1#viewer@user:anne[caveat:{not json}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := TranslateRelationships(strings.NewReader(relationship), nil, importer.NewTupleWriter(&bytes.Buffer{}))
+			require.ErrorIs(t, err, ErrInvalidRelationship)
+		})
+	}
+}
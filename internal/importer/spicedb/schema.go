@@ -0,0 +1,508 @@
+// Package spicedb translates SpiceDB schemas and relationships into OpenFGA authorization
+// models and tuples.
+package spicedb
+
+import (
+	"errors"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var ErrInvalidSchema = errors.New("invalid SpiceDB schema")
+
+// caveatParamTypes maps SpiceDB caveat parameter types to OpenFGA condition parameter types.
+var caveatParamTypes = map[string]openfgav1.ConditionParamTypeRef_TypeName{
+	"any":       openfgav1.ConditionParamTypeRef_TYPE_NAME_ANY,
+	"bool":      openfgav1.ConditionParamTypeRef_TYPE_NAME_BOOL,
+	"string":    openfgav1.ConditionParamTypeRef_TYPE_NAME_STRING,
+	"int":       openfgav1.ConditionParamTypeRef_TYPE_NAME_INT,
+	"uint":      openfgav1.ConditionParamTypeRef_TYPE_NAME_UINT,
+	"double":    openfgav1.ConditionParamTypeRef_TYPE_NAME_DOUBLE,
+	"duration":  openfgav1.ConditionParamTypeRef_TYPE_NAME_DURATION,
+	"timestamp": openfgav1.ConditionParamTypeRef_TYPE_NAME_TIMESTAMP,
+	"map":       openfgav1.ConditionParamTypeRef_TYPE_NAME_MAP,
+	"list":      openfgav1.ConditionParamTypeRef_TYPE_NAME_LIST,
+	"ipaddress": openfgav1.ConditionParamTypeRef_TYPE_NAME_IPADDRESS,
+}
+
+// TranslateSchema parses a SpiceDB schema and translates it into an OpenFGA authorization model.
+// Constructs that have no OpenFGA equivalent are left out of the model and reported as issues.
+// The returned error is non-nil only if the schema cannot be parsed.
+func TranslateSchema(schema string) (*openfgav1.AuthorizationModel, []importer.Issue, error) {
+	p := &parser{lex: newLexer(schema)}
+	model := &openfgav1.AuthorizationModel{
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+
+	for {
+		tok, err := p.advance()
+		if err != nil {
+			return nil, nil, err
+		}
+		if tok.kind == tokenEOF {
+			break
+		}
+		if tok.kind != tokenIdent {
+			return nil, nil, p.errorf(tok, "expected 'definition' or 'caveat', got %s", tok)
+		}
+
+		switch tok.value {
+		case "definition":
+			td, err := p.parseDefinition()
+			if err != nil {
+				return nil, nil, err
+			}
+			model.TypeDefinitions = append(model.TypeDefinitions, td)
+		case "caveat":
+			cond, err := p.parseCaveat()
+			if err != nil {
+				return nil, nil, err
+			}
+			if cond != nil {
+				if model.Conditions == nil {
+					model.Conditions = map[string]*openfgav1.Condition{}
+				}
+				model.Conditions[cond.GetName()] = cond
+			}
+		case "use":
+			feature, err := p.expectIdent()
+			if err != nil {
+				return nil, nil, err
+			}
+			p.issue(tok.line, "use "+feature.value, "schema feature flags have no OpenFGA equivalent and were ignored")
+		default:
+			return nil, nil, p.errorf(tok, "expected 'definition' or 'caveat', got %s", tok)
+		}
+	}
+
+	return model, p.issues, nil
+}
+
+type parser struct {
+	lex    *lexer
+	peeked *token
+	issues []importer.Issue
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidSchema, tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) issue(line int, construct, message string) {
+	p.issues = append(p.issues, importer.Issue{Line: line, Construct: construct, Message: message})
+}
+
+func (p *parser) peek() (token, error) {
+	if p.peeked == nil {
+		tok, err := p.lex.next()
+		if err != nil {
+			return token{}, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+		p.peeked = &tok
+	}
+	return *p.peeked, nil
+}
+
+func (p *parser) advance() (token, error) {
+	tok, err := p.peek()
+	p.peeked = nil
+	return tok, err
+}
+
+func (p *parser) accept(punct string) (bool, error) {
+	tok, err := p.peek()
+	if err != nil {
+		return false, err
+	}
+	if tok.kind == tokenPunct && tok.value == punct {
+		p.peeked = nil
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *parser) expect(punct string) (token, error) {
+	tok, err := p.advance()
+	if err != nil {
+		return tok, err
+	}
+	if tok.kind != tokenPunct || tok.value != punct {
+		return tok, p.errorf(tok, "expected '%s', got %s", punct, tok)
+	}
+	return tok, nil
+}
+
+func (p *parser) expectIdent() (token, error) {
+	tok, err := p.advance()
+	if err != nil {
+		return tok, err
+	}
+	if tok.kind != tokenIdent {
+		return tok, p.errorf(tok, "expected an identifier, got %s", tok)
+	}
+	return tok, nil
+}
+
+// parseDefinition parses `definition name { (relation | permission)* }`.
+func (p *parser) parseDefinition() (*openfgav1.TypeDefinition, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	td := &openfgav1.TypeDefinition{Type: name.value}
+	addRelation := func(relation string, rewrite *openfgav1.Userset, directTypes []*openfgav1.RelationReference) {
+		if td.Relations == nil {
+			td.Relations = map[string]*openfgav1.Userset{}
+			td.Metadata = &openfgav1.Metadata{Relations: map[string]*openfgav1.RelationMetadata{}}
+		}
+		td.Relations[relation] = rewrite
+		td.Metadata.Relations[relation] = &openfgav1.RelationMetadata{DirectlyRelatedUserTypes: directTypes}
+	}
+
+	for {
+		tok, err := p.advance()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokenPunct && tok.value == "}" {
+			return td, nil
+		}
+		if tok.kind != tokenIdent {
+			return nil, p.errorf(tok, "expected 'relation' or 'permission', got %s", tok)
+		}
+
+		switch tok.value {
+		case "relation":
+			relation, directTypes, err := p.parseRelation(td.GetType())
+			if err != nil {
+				return nil, err
+			}
+			if len(directTypes) == 0 {
+				p.issue(tok.line, fmt.Sprintf("relation %s#%s", td.GetType(), relation), "none of the allowed subject types could be translated, so the relation was left out")
+				continue
+			}
+			addRelation(relation, typesystem.This(), directTypes)
+		case "permission":
+			permission, e, err := p.parsePermission(td.GetType())
+			if err != nil {
+				return nil, err
+			}
+			rewrite, reason := translateExpression(e)
+			if rewrite == nil {
+				p.issue(tok.line, fmt.Sprintf("permission %s#%s", td.GetType(), permission), reason+", so the permission was left out")
+				continue
+			}
+			addRelation(permission, rewrite, nil)
+		default:
+			return nil, p.errorf(tok, "expected 'relation' or 'permission', got %s", tok)
+		}
+	}
+}
+
+// parseRelation parses `relation name: subject (| subject)*`, where a subject is
+// `type[:*][#relation][ with caveat[ and expiration]]`.
+func (p *parser) parseRelation(objectType string) (string, []*openfgav1.RelationReference, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := p.expect(":"); err != nil {
+		return "", nil, err
+	}
+
+	var directTypes []*openfgav1.RelationReference
+	for {
+		subjectType, err := p.expectIdent()
+		if err != nil {
+			return "", nil, err
+		}
+
+		ref := typesystem.DirectRelationReference(subjectType.value, "")
+		display := subjectType.value
+		if ok, err := p.accept(":"); err != nil {
+			return "", nil, err
+		} else if ok {
+			if _, err := p.expect("*"); err != nil {
+				return "", nil, err
+			}
+			ref = typesystem.WildcardRelationReference(subjectType.value)
+			display += ":*"
+		} else if ok, err := p.accept("#"); err != nil {
+			return "", nil, err
+		} else if ok {
+			relation, err := p.advance()
+			if err != nil {
+				return "", nil, err
+			}
+			switch relation.kind {
+			case tokenIdent:
+				ref = typesystem.DirectRelationReference(subjectType.value, relation.value)
+				display += "#" + relation.value
+			case tokenEllipsis:
+				// `type#...` is the same as a plain `type` subject.
+			default:
+				return "", nil, p.errorf(relation, "expected a relation, got %s", relation)
+			}
+		}
+
+		supported := true
+		if tok, err := p.peek(); err != nil {
+			return "", nil, err
+		} else if tok.kind == tokenIdent && tok.value == "with" {
+			p.peeked = nil
+			supported, err = p.parseSubjectTraits(objectType, name.value, display, ref)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		if supported {
+			directTypes = append(directTypes, ref)
+		}
+
+		if ok, err := p.accept("|"); err != nil {
+			return "", nil, err
+		} else if !ok {
+			return name.value, directTypes, nil
+		}
+	}
+}
+
+// parseSubjectTraits parses the traits following `with` in a subject type and sets the
+// condition on ref. It reports whether the subject type can be translated.
+func (p *parser) parseSubjectTraits(objectType, relation, display string, ref *openfgav1.RelationReference) (bool, error) {
+	trait, err := p.expectIdent()
+	if err != nil {
+		return false, err
+	}
+
+	supported := true
+	for {
+		if trait.value == "expiration" {
+			p.issue(trait.line, fmt.Sprintf("relation %s#%s subject %s", objectType, relation, display), "relationship expiration has no OpenFGA equivalent, so the subject type was left out")
+			supported = false
+		} else {
+			ref.Condition = trait.value
+		}
+
+		tok, err := p.peek()
+		if err != nil {
+			return false, err
+		}
+		if tok.kind != tokenIdent || tok.value != "and" {
+			return supported, nil
+		}
+		p.peeked = nil
+		if trait, err = p.expectIdent(); err != nil {
+			return false, err
+		}
+	}
+}
+
+// parsePermission parses `permission name = expression`.
+func (p *parser) parsePermission(objectType string) (string, expression, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := p.expect("="); err != nil {
+		return "", nil, err
+	}
+	e, err := p.parseExpression(0)
+	if err != nil {
+		return "", nil, err
+	}
+	if hasMixedOperators(e) {
+		p.issue(name.line, fmt.Sprintf("permission %s#%s", objectType, name.value), "the expression mixes operators without parentheses and was translated using SpiceDB operator precedence; review the translated relation")
+	}
+	return name.value, e, nil
+}
+
+// operatorPrecedence follows SpiceDB, where union binds tighter than intersection, which binds
+// tighter than exclusion (e.g. `a - b + c` is `a - (b + c)`).
+var operatorPrecedence = map[string]int{
+	"-": 1,
+	"&": 2,
+	"+": 3,
+}
+
+func (p *parser) parseExpression(minPrecedence int) (expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		precedence, ok := operatorPrecedence[tok.value]
+		if tok.kind != tokenPunct || !ok || precedence <= minPrecedence {
+			return left, nil
+		}
+		p.peeked = nil
+
+		right, err := p.parseExpression(precedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpression{op: tok.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseTerm() (expression, error) {
+	if ok, err := p.accept("("); err != nil {
+		return nil, err
+	} else if ok {
+		e, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if b, ok := e.(*binaryExpression); ok {
+			b.parenthesized = true
+		}
+		return e, nil
+	}
+
+	ref, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if ref.value == "nil" {
+		return nilExpression{}, nil
+	}
+
+	if ok, err := p.accept("->"); err != nil {
+		return nil, err
+	} else if ok {
+		computed, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		return &arrowExpression{tupleset: ref.value, computed: computed.value, function: "any"}, nil
+	}
+
+	if ok, err := p.accept("."); err != nil {
+		return nil, err
+	} else if ok {
+		function, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if function.value != "any" && function.value != "all" {
+			return nil, p.errorf(function, "unknown arrow function '%s'", function.value)
+		}
+		if _, err := p.expect("("); err != nil {
+			return nil, err
+		}
+		computed, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &arrowExpression{tupleset: ref.value, computed: computed.value, function: function.value}, nil
+	}
+
+	return relationExpression(ref.value), nil
+}
+
+// parseCaveat parses `caveat name(param type, ...) { expression }` into an OpenFGA condition.
+// A nil condition is returned if a parameter type cannot be translated.
+func (p *parser) parseCaveat() (*openfgav1.Condition, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	cond := &openfgav1.Condition{
+		Name:       name.value,
+		Parameters: map[string]*openfgav1.ConditionParamTypeRef{},
+	}
+	supported := true
+	for {
+		param, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		paramType, ok, err := p.parseCaveatParamType()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			p.issue(param.line, fmt.Sprintf("caveat %s parameter %s", name.value, param.value), "the parameter type has no OpenFGA equivalent, so the caveat was left out")
+			supported = false
+		}
+		cond.Parameters[param.value] = paramType
+
+		if ok, err := p.accept(","); err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+	}
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	body, err := p.lex.rawBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	cond.Expression = body
+
+	if !supported {
+		return nil, nil
+	}
+	return cond, nil
+}
+
+func (p *parser) parseCaveatParamType() (*openfgav1.ConditionParamTypeRef, bool, error) {
+	typeName, err := p.expectIdent()
+	if err != nil {
+		return nil, false, err
+	}
+	name, supported := caveatParamTypes[typeName.value]
+	ref := &openfgav1.ConditionParamTypeRef{TypeName: name}
+
+	if ok, err := p.accept("<"); err != nil {
+		return nil, false, err
+	} else if ok {
+		for {
+			generic, genericSupported, err := p.parseCaveatParamType()
+			if err != nil {
+				return nil, false, err
+			}
+			supported = supported && genericSupported
+			ref.GenericTypes = append(ref.GenericTypes, generic)
+			if ok, err := p.accept(","); err != nil {
+				return nil, false, err
+			} else if !ok {
+				break
+			}
+		}
+		if _, err := p.expect(">"); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return ref, supported, nil
+}
@@ -0,0 +1,149 @@
+package spicedb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestTranslateSchema(t *testing.T) {
+	schema := `
+/** user is a user */
+definition user {}
+
+caveat ip_allowed(user_ip ipaddress, cidr string) {
+	user_ip.in_cidr(cidr)
+}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation viewer: user | user:* | group#member with ip_allowed
+}
+
+definition document {
+	relation parent: folder
+	relation owner: user
+	relation editor: user | group#...
+	relation banned: user
+	// permissions
+	permission edit = owner + editor
+	permission view = (edit + parent->viewer + parent.any(viewer)) - banned
+	permission both = owner & editor
+}
+`
+	model, issues, err := TranslateSchema(schema)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	require.Empty(t, importer.ValidateModel(context.Background(), model))
+
+	require.Len(t, model.GetTypeDefinitions(), 4)
+	require.Contains(t, model.GetConditions(), "ip_allowed")
+	require.Equal(t, "user_ip.in_cidr(cidr)", model.GetConditions()["ip_allowed"].GetExpression())
+	require.Equal(t, openfgav1.ConditionParamTypeRef_TYPE_NAME_IPADDRESS, model.GetConditions()["ip_allowed"].GetParameters()["user_ip"].GetTypeName())
+
+	folder := model.GetTypeDefinitions()[2]
+	require.Equal(t, []*openfgav1.RelationReference{
+		typesystem.DirectRelationReference("user", ""),
+		typesystem.WildcardRelationReference("user"),
+		typesystem.ConditionedRelationReference(typesystem.DirectRelationReference("group", "member"), "ip_allowed"),
+	}, folder.GetMetadata().GetRelations()["viewer"].GetDirectlyRelatedUserTypes())
+
+	document := model.GetTypeDefinitions()[3]
+	require.Equal(t, []*openfgav1.RelationReference{
+		typesystem.DirectRelationReference("user", ""),
+		typesystem.DirectRelationReference("group", ""),
+	}, document.GetMetadata().GetRelations()["editor"].GetDirectlyRelatedUserTypes())
+	require.Equal(t, typesystem.Union(
+		typesystem.ComputedUserset("owner"),
+		typesystem.ComputedUserset("editor"),
+	), document.GetRelations()["edit"])
+	require.Equal(t, typesystem.Difference(
+		typesystem.Union(
+			typesystem.ComputedUserset("edit"),
+			typesystem.TupleToUserset("parent", "viewer"),
+			typesystem.TupleToUserset("parent", "viewer"),
+		),
+		typesystem.ComputedUserset("banned"),
+	), document.GetRelations()["view"])
+	require.Equal(t, typesystem.Intersection(
+		typesystem.ComputedUserset("owner"),
+		typesystem.ComputedUserset("editor"),
+	), document.GetRelations()["both"])
+}
+
+func TestTranslateSchemaReportsUntranslatableConstructs(t *testing.T) {
+	schema := `
+use expiration
+
+definition user {}
+
+caveat unsupported(value bytes) {
+	value == b"x"
+}
+
+definition document {
+	relation owner: user
+	relation expiring: user with expiration
+	relation mixed: user | user with expiration
+	permission all_owners = owner.all(owner)
+	permission nothing = nil
+	permission precedence = owner - mixed + owner
+}
+`
+	model, issues, err := TranslateSchema(schema)
+	require.NoError(t, err)
+
+	document := model.GetTypeDefinitions()[1]
+	require.Contains(t, document.GetRelations(), "owner")
+	require.Contains(t, document.GetRelations(), "mixed")
+	require.Len(t, document.GetMetadata().GetRelations()["mixed"].GetDirectlyRelatedUserTypes(), 1)
+	require.NotContains(t, document.GetRelations(), "expiring")
+	require.NotContains(t, document.GetRelations(), "all_owners")
+	require.NotContains(t, document.GetRelations(), "nothing")
+	require.Equal(t, typesystem.Difference(
+		typesystem.ComputedUserset("owner"),
+		typesystem.Union(typesystem.ComputedUserset("mixed"), typesystem.ComputedUserset("owner")),
+	), document.GetRelations()["precedence"])
+	require.Empty(t, model.GetConditions())
+
+	var constructs []string
+	for _, issue := range issues {
+		require.Positive(t, issue.Line)
+		constructs = append(constructs, issue.Construct)
+	}
+	require.Equal(t, []string{
+		"use expiration",
+		"caveat unsupported parameter value",
+		"relation document#expiring subject user",
+		"relation document#expiring",
+		"relation document#mixed subject user",
+		"permission document#all_owners",
+		"permission document#nothing",
+		"permission document#precedence",
+	}, constructs)
+}
+
+func TestTranslateSchemaErrors(t *testing.T) {
+	for name, schema := range map[string]string{
+		"unknown_keyword":     `type user {}`,
+		"missing_brace":       `definition user {`,
+		"missing_colon":       `definition doc { relation viewer user }`,
+		"bad_arrow_function":  `definition doc { relation parent: doc permission view = parent.some(view) }`,
+		"unterminated_caveat": `caveat c(a int) { a == 1`,
+		"bad_character":       `definition doc { relation viewer: user ! }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := TranslateSchema(schema)
+			require.ErrorIs(t, err, ErrInvalidSchema)
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Package zanzibar translates Zanzibar-style namespace configurations, written in the protobuf
+// text format, into OpenFGA authorization models.
+package zanzibar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var ErrInvalidNamespaceConfig = errors.New("invalid namespace config")
+
+// tupleUsersetObject is the placeholder used by tuple_to_userset rewrites for the objects
+// found through the tupleset.
+const tupleUsersetObject = "$TUPLE_USERSET_OBJECT"
+
+// Options configures the translation of namespace configurations.
+type Options struct {
+	// DirectTypes maps an `object_type#relation` to the user types that can be directly related
+	// to it (e.g. "user", "user:*" or "group#member"). Zanzibar namespaces do not restrict the
+	// users of a relation, while OpenFGA requires it for every directly assignable relation.
+	DirectTypes map[string][]string
+
+	// DefaultUserType is assumed for directly assignable relations missing from DirectTypes.
+	// If empty, such relations are left out.
+	DefaultUserType string
+}
+
+// TranslateNamespaces translates namespace configurations, one per input, into a single OpenFGA
+// authorization model. An input may also hold several configurations as repeated `namespace`
+// or `config` fields. Rewrites that have no OpenFGA equivalent are left out of the model and
+// reported as issues. The returned error is non-nil only if an input cannot be parsed.
+func TranslateNamespaces(configs []string, opts Options) (*openfgav1.AuthorizationModel, []importer.Issue, error) {
+	model := &openfgav1.AuthorizationModel{
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+	t := &translator{opts: opts, userTypes: map[string]bool{}}
+
+	for _, config := range configs {
+		root, err := parseTextProto(config)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		namespaces := append(root.all("namespace"), root.all("config")...)
+		if len(namespaces) == 0 {
+			namespaces = []*field{{msg: root, line: root.line}}
+		}
+		for _, ns := range namespaces {
+			if ns.msg == nil {
+				return nil, nil, fmt.Errorf("%w: line %d: expected a namespace message", ErrInvalidNamespaceConfig, ns.line)
+			}
+			td, err := t.translateNamespace(ns.msg)
+			if err != nil {
+				return nil, nil, err
+			}
+			model.TypeDefinitions = append(model.TypeDefinitions, td)
+		}
+	}
+
+	// Declare the user types that are referenced by type restrictions but have no namespace.
+	defined := map[string]bool{}
+	for _, td := range model.GetTypeDefinitions() {
+		defined[td.GetType()] = true
+	}
+	var missing []string
+	for userType := range t.userTypes {
+		if !defined[userType] {
+			missing = append(missing, userType)
+		}
+	}
+	sort.Strings(missing)
+	for _, userType := range missing {
+		model.TypeDefinitions = append(model.TypeDefinitions, &openfgav1.TypeDefinition{Type: userType})
+	}
+
+	return model, t.issues, nil
+}
+
+type translator struct {
+	opts      Options
+	issues    []importer.Issue
+	userTypes map[string]bool
+}
+
+func (t *translator) issue(line int, construct, message string) {
+	t.issues = append(t.issues, importer.Issue{Line: line, Construct: construct, Message: message})
+}
+
+func (t *translator) translateNamespace(ns *message) (*openfgav1.TypeDefinition, error) {
+	name := ns.get("name")
+	if name == nil || name.value == "" {
+		return nil, fmt.Errorf("%w: line %d: namespace without a name", ErrInvalidNamespaceConfig, ns.line)
+	}
+	td := &openfgav1.TypeDefinition{Type: name.value}
+
+	for _, f := range ns.fields {
+		switch f.name {
+		case "name":
+		case "relation":
+			if f.msg == nil {
+				return nil, fmt.Errorf("%w: line %d: expected a relation message", ErrInvalidNamespaceConfig, f.line)
+			}
+			if err := t.translateRelation(td, f.msg); err != nil {
+				return nil, err
+			}
+		default:
+			t.issue(f.line, fmt.Sprintf("namespace %s field %s", td.GetType(), f.name), "the field has no OpenFGA equivalent and was ignored")
+		}
+	}
+
+	return td, nil
+}
+
+func (t *translator) translateRelation(td *openfgav1.TypeDefinition, rel *message) error {
+	name := rel.get("name")
+	if name == nil || name.value == "" {
+		return fmt.Errorf("%w: line %d: relation without a name", ErrInvalidNamespaceConfig, rel.line)
+	}
+	objectRelation := tuple.ToObjectRelationString(td.GetType(), name.value)
+	construct := "relation " + objectRelation
+
+	// A relation without a rewrite only holds its direct relationships.
+	rewrite, usesThis := typesystem.This(), true
+	if r := rel.get("userset_rewrite"); r != nil {
+		var reason string
+		usesThis = false
+		rewrite, reason = t.translateRewrite(r, &usesThis)
+		if rewrite == nil {
+			t.issue(r.line, construct, reason+", so the relation was left out")
+			return nil
+		}
+	}
+
+	var directTypes []*openfgav1.RelationReference
+	if usesThis {
+		userTypes, ok := t.opts.DirectTypes[objectRelation]
+		if !ok {
+			if t.opts.DefaultUserType == "" {
+				t.issue(rel.line, construct, "the relation is directly assignable but has no direct types configured, so the relation was left out")
+				return nil
+			}
+			userTypes = []string{t.opts.DefaultUserType}
+			t.issue(rel.line, construct, fmt.Sprintf("the relation is directly assignable but has no direct types configured, so '%s' was assumed; review the translated relation", t.opts.DefaultUserType))
+		}
+		for _, userType := range userTypes {
+			ref, err := parseRelationReference(userType)
+			if err != nil {
+				return fmt.Errorf("%s: %w", construct, err)
+			}
+			t.userTypes[ref.GetType()] = true
+			directTypes = append(directTypes, ref)
+		}
+	}
+
+	if td.Relations == nil {
+		td.Relations = map[string]*openfgav1.Userset{}
+		td.Metadata = &openfgav1.Metadata{Relations: map[string]*openfgav1.RelationMetadata{}}
+	}
+	td.Relations[name.value] = rewrite
+	td.Metadata.Relations[name.value] = &openfgav1.RelationMetadata{DirectlyRelatedUserTypes: directTypes}
+	return nil
+}
+
+// translateRewrite translates a userset rewrite (or a child of a set operation) into an OpenFGA
+// rewrite. If it cannot be translated, a nil rewrite and the reason are returned. usesThis is
+// set if the rewrite references the direct relationships of the relation.
+func (t *translator) translateRewrite(f *field, usesThis *bool) (*openfgav1.Userset, string) {
+	if f.msg == nil || len(f.msg.fields) != 1 {
+		return nil, fmt.Sprintf("line %d: '%s' must hold exactly one rewrite", f.line, f.name)
+	}
+	child := f.msg.fields[0]
+
+	switch child.name {
+	case "_this", "this":
+		*usesThis = true
+		return typesystem.This(), ""
+	case "computed_userset":
+		relation, reason := computedRelation(child, false)
+		if relation == "" {
+			return nil, reason
+		}
+		return typesystem.ComputedUserset(relation), ""
+	case "tuple_to_userset":
+		if child.msg == nil {
+			return nil, fmt.Sprintf("line %d: malformed tuple_to_userset", child.line)
+		}
+		tupleset, computed := child.msg.get("tupleset"), child.msg.get("computed_userset")
+		if tupleset == nil || computed == nil {
+			return nil, fmt.Sprintf("line %d: tuple_to_userset requires a tupleset and a computed_userset", child.line)
+		}
+		tuplesetRelation, reason := computedRelation(tupleset, false)
+		if tuplesetRelation == "" {
+			return nil, reason
+		}
+		computedRel, reason := computedRelation(computed, true)
+		if computedRel == "" {
+			return nil, reason
+		}
+		return typesystem.TupleToUserset(tuplesetRelation, computedRel), ""
+	case "userset_rewrite":
+		return t.translateRewrite(child, usesThis)
+	case "union", "intersection":
+		if child.msg == nil {
+			return nil, fmt.Sprintf("line %d: malformed %s", child.line, child.name)
+		}
+		var children []*openfgav1.Userset
+		for _, c := range child.msg.fields {
+			if c.name != "child" {
+				return nil, fmt.Sprintf("line %d: unknown field '%s' in %s", c.line, c.name, child.name)
+			}
+			rewrite, reason := t.translateRewrite(c, usesThis)
+			if rewrite == nil {
+				return nil, reason
+			}
+			children = append(children, rewrite)
+		}
+		if len(children) == 0 {
+			return nil, fmt.Sprintf("line %d: %s without children", child.line, child.name)
+		}
+		if child.name == "union" {
+			return typesystem.Union(children...), ""
+		}
+		return typesystem.Intersection(children...), ""
+	case "exclusion":
+		if child.msg == nil {
+			return nil, fmt.Sprintf("line %d: malformed exclusion", child.line)
+		}
+		operands := child.msg.all("child")
+		if base, subtract := child.msg.get("base"), child.msg.get("subtract"); base != nil && subtract != nil {
+			operands = []*field{base, subtract}
+		}
+		if len(operands) != 2 || len(child.msg.fields) != 2 {
+			return nil, fmt.Sprintf("line %d: exclusion requires exactly two operands", child.line)
+		}
+		base, reason := t.translateRewrite(operands[0], usesThis)
+		if base == nil {
+			return nil, reason
+		}
+		subtract, reason := t.translateRewrite(operands[1], usesThis)
+		if subtract == nil {
+			return nil, reason
+		}
+		return typesystem.Difference(base, subtract), ""
+	default:
+		return nil, fmt.Sprintf("line %d: the rewrite '%s' has no OpenFGA equivalent", child.line, child.name)
+	}
+}
+
+// computedRelation returns the relation of a computed_userset or tupleset. OpenFGA can only
+// compute relations of the object itself or, within a tuple_to_userset, of the tupleset objects.
+func computedRelation(f *field, tupleUserset bool) (string, string) {
+	if f.msg == nil {
+		return "", fmt.Sprintf("line %d: malformed %s", f.line, f.name)
+	}
+	relation := f.msg.get("relation")
+	if relation == nil || relation.value == "" {
+		return "", fmt.Sprintf("line %d: %s without a relation", f.line, f.name)
+	}
+	for _, other := range f.msg.fields {
+		switch {
+		case other.name == "relation":
+		case other.name == "object" && tupleUserset && other.value == tupleUsersetObject:
+		default:
+			return "", fmt.Sprintf("line %d: '%s' in %s has no OpenFGA equivalent", other.line, other.name, f.name)
+		}
+	}
+	return relation.value, ""
+}
+
+// parseRelationReference parses a user type of the form `type`, `type:*` or `type#relation`.
+func parseRelationReference(userType string) (*openfgav1.RelationReference, error) {
+	switch {
+	case strings.HasSuffix(userType, ":*"):
+		return typesystem.WildcardRelationReference(strings.TrimSuffix(userType, ":*")), nil
+	case strings.Contains(userType, "#"):
+		objectType, relation, _ := strings.Cut(userType, "#")
+		if objectType == "" || relation == "" {
+			return nil, fmt.Errorf("invalid user type '%s'", userType)
+		}
+		return typesystem.DirectRelationReference(objectType, relation), nil
+	case userType == "" || strings.ContainsAny(userType, ":# "):
+		return nil, fmt.Errorf("invalid user type '%s'", userType)
+	default:
+		return typesystem.DirectRelationReference(userType, ""), nil
+	}
+}
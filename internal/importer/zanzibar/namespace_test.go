@@ -0,0 +1,185 @@
+package zanzibar
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/importer"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// docConfig is the example namespace config of the Zanzibar paper.
+const docConfig = `
+name: "doc"
+
+relation { name: "owner" }
+
+relation {
+  name: "editor"
+  userset_rewrite {
+    union {
+      child { _this {} }
+      child { computed_userset { relation: "owner" } }
+    }
+  }
+}
+
+relation { name: "parent" }
+
+relation {
+  name: "viewer"
+  userset_rewrite {
+    union {
+      child { _this {} }
+      child { computed_userset { relation: "editor" } }
+      child { tuple_to_userset {
+        tupleset { relation: "parent" }
+        computed_userset {
+          object: $TUPLE_USERSET_OBJECT  # parent folder
+          relation: "viewer"
+        }
+      } }
+    }
+  }
+}
+
+relation {
+  name: "reader"
+  userset_rewrite {
+    exclusion {
+      base { computed_userset { relation: "viewer" } }
+      subtract { computed_userset { relation: "banned" } }
+    }
+  }
+}
+
+relation { name: "banned" }
+`
+
+const folderConfig = `
+name: 'folder'
+relation < name: 'viewer' >
+`
+
+func TestTranslateNamespaces(t *testing.T) {
+	model, issues, err := TranslateNamespaces([]string{docConfig, folderConfig}, Options{
+		DirectTypes: map[string][]string{
+			"doc#owner":     {"user"},
+			"doc#editor":    {"user", "group#member"},
+			"doc#parent":    {"folder"},
+			"doc#viewer":    {"user", "user:*"},
+			"doc#banned":    {"user"},
+			"folder#viewer": {"user"},
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	require.Len(t, model.GetTypeDefinitions(), 4)
+	require.Equal(t, "group", model.GetTypeDefinitions()[2].GetType())
+	require.Equal(t, "user", model.GetTypeDefinitions()[3].GetType())
+
+	doc := model.GetTypeDefinitions()[0]
+	require.Equal(t, typesystem.This(), doc.GetRelations()["owner"])
+	require.Equal(t, typesystem.Union(typesystem.This(), typesystem.ComputedUserset("owner")), doc.GetRelations()["editor"])
+	require.Equal(t, typesystem.Union(
+		typesystem.This(),
+		typesystem.ComputedUserset("editor"),
+		typesystem.TupleToUserset("parent", "viewer"),
+	), doc.GetRelations()["viewer"])
+	require.Equal(t, typesystem.Difference(
+		typesystem.ComputedUserset("viewer"),
+		typesystem.ComputedUserset("banned"),
+	), doc.GetRelations()["reader"])
+	require.Equal(t, []*openfgav1.RelationReference{
+		typesystem.DirectRelationReference("user", ""),
+		typesystem.WildcardRelationReference("user"),
+	}, doc.GetMetadata().GetRelations()["viewer"].GetDirectlyRelatedUserTypes())
+	require.Empty(t, doc.GetMetadata().GetRelations()["reader"].GetDirectlyRelatedUserTypes())
+
+	// group#member is referenced but not defined
+	require.NotEmpty(t, importer.ValidateModel(context.Background(), model))
+}
+
+func TestTranslateNamespacesDefaultUserType(t *testing.T) {
+	config := `
+namespace {
+  name: "doc"
+  relation { name: "owner" }
+  relation {
+    name: "viewer"
+    userset_rewrite { computed_userset { relation: "owner" } }
+  }
+}
+namespace {
+  name: "user"
+}
+`
+	model, issues, err := TranslateNamespaces([]string{config}, Options{DefaultUserType: "user"})
+	require.NoError(t, err)
+	require.Len(t, model.GetTypeDefinitions(), 2)
+	require.Empty(t, importer.ValidateModel(context.Background(), model))
+	require.Len(t, issues, 1)
+	require.Equal(t, "relation doc#owner", issues[0].Construct)
+	require.Equal(t, 4, issues[0].Line)
+
+	model, issues, err = TranslateNamespaces([]string{config}, Options{})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.NotContains(t, model.GetTypeDefinitions()[0].GetRelations(), "owner")
+}
+
+func TestTranslateNamespacesReportsUntranslatableRewrites(t *testing.T) {
+	config := `
+name: "doc"
+relation { name: "owner" }
+relation {
+  name: "other_object"
+  userset_rewrite { computed_userset { object: "doc:readme" relation: "owner" } }
+}
+relation {
+  name: "unknown"
+  userset_rewrite { something_else {} }
+}
+relation {
+  name: "exclusion"
+  userset_rewrite { exclusion { child { _this {} } } }
+}
+`
+	model, issues, err := TranslateNamespaces([]string{config}, Options{DefaultUserType: "user"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"owner"}, slices.Collect(maps.Keys(model.GetTypeDefinitions()[0].GetRelations())))
+
+	var constructs []string
+	for _, issue := range issues {
+		constructs = append(constructs, issue.Construct)
+	}
+	require.Equal(t, []string{
+		"relation doc#owner",
+		"relation doc#other_object",
+		"relation doc#unknown",
+		"relation doc#exclusion",
+	}, constructs)
+}
+
+func TestTranslateNamespacesErrors(t *testing.T) {
+	for name, config := range map[string]string{
+		"missing_name":        `relation { name: "owner" }`,
+		"missing_brace":       `name: "doc" relation { name: "owner"`,
+		"unterminated_string": `name: "doc`,
+		"missing_value":       `name`,
+		"bad_character":       `name: "doc" }`,
+		"relation_scalar":     `name: "doc" relation: "owner"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := TranslateNamespaces([]string{config}, Options{})
+			require.ErrorIs(t, err, ErrInvalidNamespaceConfig)
+		})
+	}
+}
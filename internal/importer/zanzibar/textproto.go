@@ -0,0 +1,210 @@
+package zanzibar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// message is a schema-less protobuf text format message: the fields in the order they appear.
+type message struct {
+	fields []*field
+	line   int
+}
+
+// field is a single field of a text format message. Exactly one of value and msg is set.
+type field struct {
+	name  string
+	value string
+	msg   *message
+	line  int
+}
+
+// get returns the first field named name, or nil.
+func (m *message) get(name string) *field {
+	for _, f := range m.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// all returns every field named name.
+func (m *message) all(name string) []*field {
+	var fields []*field
+	for _, f := range m.fields {
+		if f.name == name {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// parseTextProto parses the protobuf text format without a schema. Scalars are kept as strings,
+// with quoted strings unquoted.
+func parseTextProto(input string) (*message, error) {
+	p := &textParser{input: input, line: 1}
+	root, err := p.parseFields("")
+	if err != nil {
+		return nil, err
+	}
+	root.line = 1
+	return root, nil
+}
+
+type textParser struct {
+	input string
+	pos   int
+	line  int
+}
+
+func (p *textParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidNamespaceConfig, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *textParser) skipSpaceAndComments() {
+	for p.pos < len(p.input) {
+		switch c := p.input[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',' || c == ';':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c == '-' || c == '+' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// parseFields parses fields until the closing delimiter, or until the end of the input if
+// closing is empty.
+func (p *textParser) parseFields(closing string) (*message, error) {
+	msg := &message{line: p.line}
+	for {
+		p.skipSpaceAndComments()
+		if p.pos >= len(p.input) {
+			if closing != "" {
+				return nil, p.errorf("expected '%s', got end of input", closing)
+			}
+			return msg, nil
+		}
+		if closing != "" && strings.HasPrefix(p.input[p.pos:], closing) {
+			p.pos++
+			return msg, nil
+		}
+
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		msg.fields = append(msg.fields, f)
+	}
+}
+
+func (p *textParser) parseField() (*field, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isNameByte(p.input[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, p.errorf("unexpected character '%c'", p.input[p.pos])
+	}
+	f := &field{name: p.input[start:p.pos], line: p.line}
+
+	p.skipSpaceAndComments()
+	hasColon := p.pos < len(p.input) && p.input[p.pos] == ':'
+	if hasColon {
+		p.pos++
+		p.skipSpaceAndComments()
+	}
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("missing value for field '%s'", f.name)
+	}
+
+	switch p.input[p.pos] {
+	case '{', '<':
+		closing := "}"
+		if p.input[p.pos] == '<' {
+			closing = ">"
+		}
+		line := p.line
+		p.pos++
+		msg, err := p.parseFields(closing)
+		if err != nil {
+			return nil, err
+		}
+		msg.line = line
+		f.msg = msg
+		return f, nil
+	}
+
+	if !hasColon {
+		return nil, p.errorf("expected ':' or '{' after field '%s'", f.name)
+	}
+	value, err := p.parseScalar()
+	if err != nil {
+		return nil, err
+	}
+	f.value = value
+	return f, nil
+}
+
+func (p *textParser) parseScalar() (string, error) {
+	if c := p.input[p.pos]; c == '"' || c == '\'' {
+		// Adjacent string literals are concatenated.
+		var value strings.Builder
+		for p.pos < len(p.input) && (p.input[p.pos] == '"' || p.input[p.pos] == '\'') {
+			s, err := p.parseString()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(s)
+			p.skipSpaceAndComments()
+		}
+		return value.String(), nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && isNameByte(p.input[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("unexpected character '%c'", p.input[p.pos])
+	}
+	return p.input[start:p.pos], nil
+}
+
+func (p *textParser) parseString() (string, error) {
+	quote := p.input[p.pos]
+	end := p.pos + 1
+	for ; end < len(p.input); end++ {
+		switch p.input[end] {
+		case '\\':
+			end++
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case quote:
+			raw := p.input[p.pos+1 : end]
+			p.pos = end + 1
+			if quote == '\'' {
+				raw = strings.ReplaceAll(strings.ReplaceAll(raw, `\'`, `'`), `"`, `\"`)
+			}
+			s, err := strconv.Unquote(`"` + raw + `"`)
+			if err != nil {
+				return "", p.errorf("invalid string: %s", err)
+			}
+			return s, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
@@ -0,0 +1,497 @@
+// Package materialize maintains the denormalized (user, relation, object) permissions of the
+// relations designated for it, so that their Checks are answered with a single row lookup instead
+// of a traversal of the graph. The permissions are built with ListUsers and kept up to date from
+// the changelog of their store by a background routine.
+package materialize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/hotpath"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	// materializerLease is the name of the lease held by the replica that updates the permissions.
+	materializerLease = "materialized-permissions"
+
+	// missedIntervals is the number of intervals after which the lease of a replica that stopped
+	// renewing it can be acquired by another one.
+	missedIntervals = 3
+)
+
+var (
+	// ErrInvalidRelation is returned when a designated relation cannot be parsed.
+	ErrInvalidRelation = errors.New("invalid materialized relation")
+
+	tracer = otel.Tracer("internal/materialize")
+
+	updateCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "materialized_permissions_updates_count",
+		Help:      "The total number of updates of the materialized permissions of a relation, by kind.",
+	}, []string{"kind"})
+)
+
+// Relation is a relation of an object type of a store designated for materialization.
+type Relation struct {
+	StoreID    string
+	ObjectType string
+	Relation   string
+}
+
+func (r Relation) String() string {
+	return r.StoreID + "=" + tuple.ToObjectRelationString(r.ObjectType, r.Relation)
+}
+
+// ParseRelations parses designated relations in the form <store_id>=<object_type>#<relation>,
+// e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV=document#viewer.
+func ParseRelations(values []string) ([]Relation, error) {
+	relations := make([]Relation, 0, len(values))
+	for _, value := range values {
+		storeID, userset, ok := strings.Cut(value, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("%w '%s': expected <store_id>=<object_type>#<relation>", ErrInvalidRelation, value)
+		}
+		objectType, relation, ok := strings.Cut(userset, "#")
+		if !ok || objectType == "" || relation == "" {
+			return nil, fmt.Errorf("%w '%s': expected <store_id>=<object_type>#<relation>", ErrInvalidRelation, value)
+		}
+		relations = append(relations, Relation{StoreID: storeID, ObjectType: objectType, Relation: relation})
+	}
+	return relations, nil
+}
+
+// Option defines an option that can be used to change the behavior of a Materializer.
+type Option func(*Materializer)
+
+// WithInterval sets the time between two updates of the permissions. Defaults to 10 seconds.
+func WithInterval(interval time.Duration) Option {
+	return func(m *Materializer) {
+		m.interval = interval
+	}
+}
+
+// WithHorizonOffset sets how far behind the present the changelog is read, so that the changes of
+// the transactions still being committed are not skipped.
+func WithHorizonOffset(offset time.Duration) Option {
+	return func(m *Materializer) {
+		m.horizonOffset = offset
+	}
+}
+
+// WithLogger sets the logger of the Materializer.
+func WithLogger(l logger.Logger) Option {
+	return func(m *Materializer) {
+		m.logger = l
+	}
+}
+
+// Materializer keeps the permissions of the designated relations up to date, and answers the
+// Checks of them. Only the replica that holds the lease of the Materializer updates the
+// permissions, while every replica refreshes which relations are up to date. It implements
+// graph.MaterializedPermissions.
+type Materializer struct {
+	datastore          storage.OpenFGADatastore
+	backend            storage.MaterializedPermissionBackend
+	typesystemResolver typesystem.TypesystemResolverFunc
+	relations          []Relation
+	interval           time.Duration
+	horizonOffset      time.Duration
+	logger             logger.Logger
+
+	// holder identifies this replica as the holder of the lease of the Materializer.
+	holder string
+
+	mu    sync.RWMutex
+	ready map[Relation]storage.MaterializedRelation
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+var _ graph.MaterializedPermissions = (*Materializer)(nil)
+
+// NewMaterializer returns a Materializer of the permissions of relations, persisted in backend and
+// computed from the tuples of datastore.
+func NewMaterializer(datastore storage.OpenFGADatastore, backend storage.MaterializedPermissionBackend, typesystemResolver typesystem.TypesystemResolverFunc, relations []Relation, opts ...Option) *Materializer {
+	m := &Materializer{
+		datastore:          datastore,
+		backend:            backend,
+		typesystemResolver: typesystemResolver,
+		relations:          relations,
+		interval:           10 * time.Second,
+		logger:             logger.NewNoopLogger(),
+		holder:             ulid.Make().String(),
+		ready:              make(map[Relation]storage.MaterializedRelation),
+		stop:               make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Start runs the Materializer in the background every interval until Stop is called.
+func (m *Materializer) Start() {
+	ticker := time.NewTicker(m.interval)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-m.stop
+			cancel()
+		}()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.Run(ctx); err != nil && ctx.Err() == nil {
+					m.logger.Error("materialized permissions update failed", zap.Error(err))
+				}
+			case <-m.stop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background routine started by Start.
+func (m *Materializer) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// Run updates the permissions of every relation once if this replica holds the lease of the
+// Materializer, and refreshes which relations are up to date.
+func (m *Materializer) Run(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "materialize.Materializer.Run")
+	defer span.End()
+
+	leader, err := m.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Bool("leader", leader))
+
+	var errs error
+	for _, relation := range m.relations {
+		if leader {
+			if err := m.Update(ctx, relation); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("'%s': %w", relation, err))
+			}
+		}
+		if err := m.refresh(ctx, relation); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("'%s': %w", relation, err))
+		}
+	}
+	return errs
+}
+
+// acquireLease reports whether this replica holds the lease of the Materializer. The datastores
+// that don't support leases are assumed to be used by a single replica.
+func (m *Materializer) acquireLease(ctx context.Context) (bool, error) {
+	leases, ok := m.datastore.(storage.ScheduleBackend)
+	if !ok {
+		return true, nil
+	}
+	leader, err := leases.AcquireLease(ctx, materializerLease, m.holder, missedIntervals*m.interval)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return true, nil
+	}
+	return leader, err
+}
+
+// refresh reads the state of the permissions of relation, so that its Checks are answered from
+// them once they are built.
+func (m *Materializer) refresh(ctx context.Context, relation Relation) error {
+	state, err := m.backend.ReadMaterializedRelation(ctx, relation.StoreID, relation.ObjectType, relation.Relation)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state == nil {
+		delete(m.ready, relation)
+		return nil
+	}
+	m.ready[relation] = *state
+	return nil
+}
+
+// Update brings the permissions of relation up to date with the latest authorization model and
+// the changelog of its store. The permissions are rebuilt when the model changes or when the
+// changes can affect the permissions of other objects than their own, and only the permissions of
+// the changed objects are recomputed otherwise.
+func (m *Materializer) Update(ctx context.Context, relation Relation) error {
+	ctx, span := tracer.Start(ctx, "materialize.Materializer.Update", trace.WithAttributes(
+		attribute.String("store_id", relation.StoreID),
+		attribute.String("userset", tuple.ToObjectRelationString(relation.ObjectType, relation.Relation)),
+	))
+	defer span.End()
+
+	typesys, err := m.typesystemResolver(ctx, relation.StoreID, "")
+	if err != nil {
+		return err
+	}
+	deps, err := hotpath.Analyze(typesys, relation.ObjectType, relation.Relation)
+	if errors.Is(err, hotpath.ErrNotEligible) {
+		// the permissions of the previous models are not used for the Checks of the latest one
+		updateCounter.WithLabelValues("not_eligible").Inc()
+		m.logger.Warn("relation cannot be materialized",
+			zap.String("store_id", relation.StoreID),
+			zap.String("authorization_model_id", typesys.GetAuthorizationModelID()),
+			zap.String("userset", tuple.ToObjectRelationString(relation.ObjectType, relation.Relation)),
+			zap.Error(err))
+		return m.backend.DeleteMaterializedRelation(ctx, relation.StoreID, relation.ObjectType, relation.Relation)
+	}
+	if err != nil {
+		return err
+	}
+
+	state, err := m.backend.ReadMaterializedRelation(ctx, relation.StoreID, relation.ObjectType, relation.Relation)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && state.ModelID != typesys.GetAuthorizationModelID()) {
+		span.SetAttributes(attribute.String("kind", "build"))
+		return m.build(ctx, relation, typesys, deps)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the changes after the returned time may be read by the next update
+	updatedAt := time.Now().Add(-m.horizonOffset)
+	changed, token, err := m.readChanges(ctx, relation.StoreID, state.ContinuationToken)
+	if err != nil {
+		return err
+	}
+
+	var objectIDs []string
+	for objectType, ids := range changed {
+		if _, ok := deps.ObjectTypes[objectType]; !ok {
+			continue
+		}
+		if objectType != relation.ObjectType || deps.CrossObject {
+			span.SetAttributes(attribute.String("kind", "build"))
+			return m.build(ctx, relation, typesys, deps)
+		}
+		for id := range ids {
+			objectIDs = append(objectIDs, id)
+		}
+	}
+	span.SetAttributes(attribute.String("kind", "incremental"), attribute.Int("objects", len(objectIDs)))
+
+	permissions, err := m.compute(ctx, relation, typesys, deps, objectIDs)
+	if err != nil {
+		return err
+	}
+	state.ContinuationToken = token
+	state.UpdatedAt = updatedAt
+	err = m.backend.WriteMaterializedPermissions(ctx, state, storage.MaterializedPermissionsUpdate{
+		ObjectIDs:   objectIDs,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return err
+	}
+	updateCounter.WithLabelValues("incremental").Inc()
+	return nil
+}
+
+// build computes the permissions of every object of relation, and replaces the previous ones.
+func (m *Materializer) build(ctx context.Context, relation Relation, typesys *typesystem.TypeSystem, deps *hotpath.Dependencies) error {
+	// the changes made while the permissions are built are applied by the next update
+	updatedAt := time.Now().Add(-m.horizonOffset)
+	token, err := m.latestChange(ctx, relation.StoreID)
+	if err != nil {
+		return err
+	}
+
+	objectIDs, err := m.readObjectIDs(ctx, relation.StoreID, relation.ObjectType)
+	if err != nil {
+		return err
+	}
+	permissions, err := m.compute(ctx, relation, typesys, deps, objectIDs)
+	if err != nil {
+		return err
+	}
+
+	err = m.backend.WriteMaterializedPermissions(ctx, &storage.MaterializedRelation{
+		Store:             relation.StoreID,
+		ObjectType:        relation.ObjectType,
+		Relation:          relation.Relation,
+		ModelID:           typesys.GetAuthorizationModelID(),
+		ContinuationToken: token,
+		UpdatedAt:         updatedAt,
+	}, storage.MaterializedPermissionsUpdate{Reset: true, Permissions: permissions})
+	if err != nil {
+		return err
+	}
+	updateCounter.WithLabelValues("build").Inc()
+	return nil
+}
+
+// compute returns the users related to each of the objects of objectIDs by relation, including the
+// typed wildcards.
+func (m *Materializer) compute(ctx context.Context, relation Relation, typesys *typesystem.TypeSystem, deps *hotpath.Dependencies, objectIDs []string) ([]storage.MaterializedPermission, error) {
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+	var permissions []storage.MaterializedPermission
+	for _, objectID := range objectIDs {
+		for userType := range deps.UserTypes {
+			resp, err := listusers.NewListUsersQuery(m.datastore, nil,
+				listusers.WithListUsersMaxResults(0),
+				listusers.WithListUsersDeadline(0),
+				listusers.WithListUsersQueryLogger(m.logger),
+			).ListUsers(ctx, &openfgav1.ListUsersRequest{
+				StoreId:              relation.StoreID,
+				AuthorizationModelId: typesys.GetAuthorizationModelID(),
+				Object:               &openfgav1.Object{Type: relation.ObjectType, Id: objectID},
+				Relation:             relation.Relation,
+				UserFilters:          []*openfgav1.UserTypeFilter{{Type: userType}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			// ListUsers returns partial results when its context is done
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for _, user := range resp.GetUsers() {
+				permissions = append(permissions, storage.MaterializedPermission{ObjectID: objectID, User: tuple.UserProtoToString(user)})
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// latestChange returns the continuation token of the latest change of the changelog of a store,
+// or an empty token if it has none.
+func (m *Materializer) latestChange(ctx context.Context, storeID string) (string, error) {
+	_, token, err := m.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{HorizonOffset: m.horizonOffset}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(1, ""),
+		SortDesc:   true,
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	return token, err
+}
+
+// readChanges returns the IDs of the objects changed after the continuation token, by type, and
+// the continuation token of the last change.
+func (m *Materializer) readChanges(ctx context.Context, storeID, continuationToken string) (map[string]map[string]struct{}, string, error) {
+	changed := make(map[string]map[string]struct{})
+	for {
+		changes, token, err := m.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{HorizonOffset: m.horizonOffset}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			return changed, continuationToken, nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, change := range changes {
+			objectType, objectID := tuple.SplitObject(change.GetTupleKey().GetObject())
+			ids, ok := changed[objectType]
+			if !ok {
+				ids = make(map[string]struct{})
+				changed[objectType] = ids
+			}
+			ids[objectID] = struct{}{}
+		}
+
+		if token == "" || len(changes) < storage.DefaultPageSize {
+			if token != "" {
+				continuationToken = token
+			}
+			return changed, continuationToken, nil
+		}
+		continuationToken = token
+	}
+}
+
+// readObjectIDs returns the IDs of the objects of objectType that have at least one tuple. The
+// objects without tuples are related to no user.
+func (m *Materializer) readObjectIDs(ctx context.Context, storeID, objectType string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var objectIDs []string
+	var continuationToken string
+	for {
+		tuples, token, err := m.datastore.ReadPage(ctx, storeID, storage.ReadFilter{Object: objectType + ":"}, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tuples {
+			_, objectID := tuple.SplitObject(t.GetKey().GetObject())
+			if _, ok := seen[objectID]; ok {
+				continue
+			}
+			seen[objectID] = struct{}{}
+			objectIDs = append(objectIDs, objectID)
+		}
+
+		if token == "" {
+			return objectIDs, nil
+		}
+		continuationToken = token
+	}
+}
+
+// Lookup see [graph.MaterializedPermissions].Lookup. The Checks of usersets are never answered.
+func (m *Materializer) Lookup(ctx context.Context, storeID, modelID string, tk *openfgav1.TupleKey, notBefore time.Time) (bool, bool, error) {
+	user := tk.GetUser()
+	if tuple.IsObjectRelation(user) {
+		return false, false, nil
+	}
+	objectType, objectID := tuple.SplitObject(tk.GetObject())
+	relation := Relation{StoreID: storeID, ObjectType: objectType, Relation: tk.GetRelation()}
+
+	m.mu.RLock()
+	state, ok := m.ready[relation]
+	m.mu.RUnlock()
+	if !ok || state.ModelID != modelID || !state.UpdatedAt.After(notBefore) {
+		return false, false, nil
+	}
+
+	users := []string{user}
+	if !tuple.IsTypedWildcard(user) {
+		users = append(users, tuple.TypedPublicWildcard(tuple.GetType(user)))
+	}
+	allowed, err := m.backend.ReadMaterializedPermission(ctx, storeID, objectType, objectID, relation.Relation, users)
+	if err != nil {
+		return false, false, err
+	}
+	return allowed, true, nil
+}
@@ -0,0 +1,207 @@
+package materialize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const model = `
+	model
+		schema 1.1
+	type user
+	type folder
+		relations
+			define viewer: [user, user:*]
+	type document
+		relations
+			define parent: [folder]
+			define owner: [user]
+			define editor: [user] or owner
+			define viewer: [user] or editor or viewer from parent
+			define restricted: [user] but not owner`
+
+func setup(t *testing.T) (storage.OpenFGADatastore, string, typesystem.TypesystemResolverFunc) {
+	t.Helper()
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, testutils.MustTransformDSLToProtoWithID(model)))
+
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("folder:public", "viewer", "user:*"),
+		tuple.NewTupleKey("document:1", "owner", "user:anne"),
+		tuple.NewTupleKey("document:2", "editor", "user:bob"),
+		tuple.NewTupleKey("document:3", "parent", "folder:public"),
+	}))
+
+	resolver, stop, err := typesystem.MemoizedTypesystemResolverFunc(ds, 10)
+	require.NoError(t, err)
+	t.Cleanup(stop)
+	return ds, storeID, resolver
+}
+
+func TestParseRelations(t *testing.T) {
+	relations, err := ParseRelations([]string{"01ARZ3NDEKTSV4RRFFQ69G5FAV=document#viewer"})
+	require.NoError(t, err)
+	require.Equal(t, []Relation{{StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV", ObjectType: "document", Relation: "viewer"}}, relations)
+
+	for _, value := range []string{"document#viewer", "=document#viewer", "store=document", "store=#viewer", "store=document#"} {
+		_, err := ParseRelations([]string{value})
+		require.ErrorIs(t, err, ErrInvalidRelation, value)
+	}
+}
+
+func TestMaterializer(t *testing.T) {
+	ctx := context.Background()
+
+	lookup := func(t *testing.T, m *Materializer, storeID, modelID, object, relation, user string) (bool, bool) {
+		t.Helper()
+		allowed, ok, err := m.Lookup(ctx, storeID, modelID, tuple.NewTupleKey(object, relation, user), time.Time{})
+		require.NoError(t, err)
+		return allowed, ok
+	}
+
+	t.Run("builds_and_updates_the_permissions", func(t *testing.T) {
+		ds, storeID, resolver := setup(t)
+		latest, err := ds.FindLatestAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+		modelID := latest.GetId()
+
+		editors := Relation{StoreID: storeID, ObjectType: "document", Relation: "editor"}
+		viewers := Relation{StoreID: storeID, ObjectType: "document", Relation: "viewer"}
+		m := NewMaterializer(ds, ds.(storage.MaterializedPermissionBackend), resolver, []Relation{editors, viewers})
+
+		_, ok := lookup(t, m, storeID, modelID, "document:1", "editor", "user:anne")
+		require.False(t, ok)
+
+		require.NoError(t, m.Run(ctx))
+
+		tests := []struct {
+			object, relation, user string
+			allowed                bool
+		}{
+			{"document:1", "editor", "user:anne", true},
+			{"document:2", "editor", "user:bob", true},
+			{"document:2", "editor", "user:anne", false},
+			{"document:3", "viewer", "user:carl", true},
+			{"document:3", "viewer", "user:*", true},
+			{"document:1", "viewer", "user:anne", true},
+			{"document:4", "viewer", "user:anne", false},
+		}
+		for _, test := range tests {
+			allowed, ok := lookup(t, m, storeID, modelID, test.object, test.relation, test.user)
+			require.True(t, ok, test)
+			require.Equal(t, test.allowed, allowed, test)
+		}
+
+		// the relations that are not materialized, the usersets and the other models are not answered
+		_, ok = lookup(t, m, storeID, modelID, "document:1", "owner", "user:anne")
+		require.False(t, ok)
+		_, ok = lookup(t, m, storeID, modelID, "document:1", "editor", "document:2#editor")
+		require.False(t, ok)
+		_, ok = lookup(t, m, storeID, ulid.Make().String(), "document:1", "editor", "user:anne")
+		require.False(t, ok)
+
+		// nor are those invalidated by a later write
+		_, ok, err = m.Lookup(ctx, storeID, modelID, tuple.NewTupleKey("document:1", "editor", "user:anne"), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.NoError(t, ds.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "owner", "user:anne"))},
+			[]*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:2", "owner", "user:carl"),
+				tuple.NewTupleKey("folder:public", "viewer", "user:dan"),
+			}))
+		require.NoError(t, m.Run(ctx))
+
+		tests = []struct {
+			object, relation, user string
+			allowed                bool
+		}{
+			{"document:1", "editor", "user:anne", false},
+			{"document:2", "editor", "user:bob", true},
+			{"document:2", "editor", "user:carl", true},
+			{"document:1", "viewer", "user:anne", false},
+			{"document:2", "viewer", "user:carl", true},
+			{"document:3", "viewer", "user:dan", true},
+		}
+		for _, test := range tests {
+			allowed, ok := lookup(t, m, storeID, modelID, test.object, test.relation, test.user)
+			require.True(t, ok, test)
+			require.Equal(t, test.allowed, allowed, test)
+		}
+	})
+
+	t.Run("rebuilds_the_permissions_of_a_new_model", func(t *testing.T) {
+		ds, storeID, resolver := setup(t)
+		editors := Relation{StoreID: storeID, ObjectType: "document", Relation: "editor"}
+		m := NewMaterializer(ds, ds.(storage.MaterializedPermissionBackend), resolver, []Relation{editors})
+		require.NoError(t, m.Run(ctx))
+
+		newModel := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define owner: [user]
+					define editor: [user]`)
+		require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, newModel))
+		require.NoError(t, m.Run(ctx))
+
+		allowed, ok := lookup(t, m, storeID, newModel.GetId(), "document:1", "editor", "user:anne")
+		require.True(t, ok)
+		require.False(t, allowed)
+		allowed, ok = lookup(t, m, storeID, newModel.GetId(), "document:2", "editor", "user:bob")
+		require.True(t, ok)
+		require.True(t, allowed)
+	})
+
+	t.Run("drops_the_relations_that_cannot_be_materialized", func(t *testing.T) {
+		ds, storeID, resolver := setup(t)
+		restricted := Relation{StoreID: storeID, ObjectType: "document", Relation: "restricted"}
+		m := NewMaterializer(ds, ds.(storage.MaterializedPermissionBackend), resolver, []Relation{restricted})
+		require.NoError(t, m.Run(ctx))
+
+		_, err := ds.(storage.MaterializedPermissionBackend).ReadMaterializedRelation(ctx, storeID, "document", "restricted")
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("only_the_holder_of_the_lease_updates_the_permissions", func(t *testing.T) {
+		ds, storeID, resolver := setup(t)
+		editors := Relation{StoreID: storeID, ObjectType: "document", Relation: "editor"}
+		first := NewMaterializer(ds, ds.(storage.MaterializedPermissionBackend), resolver, []Relation{editors})
+		second := NewMaterializer(ds, ds.(storage.MaterializedPermissionBackend), resolver, []Relation{editors})
+
+		require.NoError(t, first.Run(ctx))
+		state, err := ds.(storage.MaterializedPermissionBackend).ReadMaterializedRelation(ctx, storeID, "document", "editor")
+		require.NoError(t, err)
+
+		require.NoError(t, second.Run(ctx))
+		read, err := ds.(storage.MaterializedPermissionBackend).ReadMaterializedRelation(ctx, storeID, "document", "editor")
+		require.NoError(t, err)
+		require.Equal(t, state.UpdatedAt, read.UpdatedAt)
+
+		// the other replicas answer from the permissions too
+		latest, err := ds.FindLatestAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+		allowed, ok := lookup(t, second, storeID, latest.GetId(), "document:1", "editor", "user:anne")
+		require.True(t, ok)
+		require.True(t, allowed)
+	})
+}
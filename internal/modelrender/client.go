@@ -0,0 +1,40 @@
+package modelrender
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+// ReadModel reads the authorization model modelID of a store from the server of conn, or its
+// latest model if modelID is empty.
+func ReadModel(ctx context.Context, conn grpc.ClientConnInterface, storeID, modelID string) (*openfgav1.AuthorizationModel, error) {
+	client := openfgav1.NewOpenFGAServiceClient(conn)
+	if modelID != "" {
+		res, err := client.ReadAuthorizationModel(ctx, &openfgav1.ReadAuthorizationModelRequest{
+			StoreId: storeID,
+			Id:      modelID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.GetAuthorizationModel(), nil
+	}
+
+	// the models are read from the latest one
+	res, err := client.ReadAuthorizationModels(ctx, &openfgav1.ReadAuthorizationModelsRequest{
+		StoreId:  storeID,
+		PageSize: wrapperspb.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.GetAuthorizationModels()) == 0 {
+		return nil, serverErrors.LatestAuthorizationModelNotFound(storeID)
+	}
+	return res.GetAuthorizationModels()[0], nil
+}
@@ -0,0 +1,256 @@
+// Package modelrender renders authorization models as graphs, in the DOT language of Graphviz or
+// as Mermaid flowcharts, for the documentation and the review of complex models.
+package modelrender
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// Format is the output format of a rendered model.
+type Format string
+
+const (
+	// FormatDOT renders a model in the DOT language of Graphviz.
+	FormatDOT Format = "dot"
+
+	// FormatMermaid renders a model as a Mermaid flowchart.
+	FormatMermaid Format = "mermaid"
+)
+
+// ParseFormat returns the Format named s, or FormatDOT if s is empty.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatDOT:
+		return FormatDOT, nil
+	case FormatMermaid:
+		return FormatMermaid, nil
+	default:
+		return "", fmt.Errorf("unsupported model graph format '%s': expected '%s' or '%s'", s, FormatDOT, FormatMermaid)
+	}
+}
+
+// ContentType returns the media type of the models rendered in f.
+func (f Format) ContentType() string {
+	if f == FormatMermaid {
+		return "text/vnd.mermaid; charset=utf-8"
+	}
+	return "text/vnd.graphviz; charset=utf-8"
+}
+
+// edgeStyle is how an edge combines with the other branches of its rewrite.
+type edgeStyle int
+
+const (
+	edgeUnion        edgeStyle = iota // a branch of a union, or the only branch of a rewrite
+	edgeIntersection                  // a branch of an intersection
+	edgeExclusion                     // the subtracted branch of an exclusion
+)
+
+// edge is a relation between the users of type from and the objects of type to.
+type edge struct {
+	from, to string
+	label    string
+	style    edgeStyle
+}
+
+// Render renders model in format. Its types are the nodes of the graph, and the branches of the
+// rewrites of their relations are its edges, from the type of the users to the type of the
+// objects, labeled with the relation they define and with their condition, if any. The
+// intersections are rendered in bold and the subtracted branches of the exclusions are dashed.
+// The conditions are listed with their expression.
+func Render(model *openfgav1.AuthorizationModel, format Format) (string, error) {
+	types := make([]string, 0, len(model.GetTypeDefinitions()))
+	for _, td := range model.GetTypeDefinitions() {
+		types = append(types, td.GetType())
+	}
+
+	edges := modelEdges(model)
+	switch format {
+	case FormatDOT:
+		return renderDOT(types, edges, model.GetConditions()), nil
+	case FormatMermaid:
+		return renderMermaid(types, edges, model.GetConditions()), nil
+	default:
+		return "", fmt.Errorf("unsupported model graph format '%s'", format)
+	}
+}
+
+func modelEdges(model *openfgav1.AuthorizationModel) []edge {
+	var edges []edge
+	for _, td := range model.GetTypeDefinitions() {
+		relations := make([]string, 0, len(td.GetRelations()))
+		for relation := range td.GetRelations() {
+			relations = append(relations, relation)
+		}
+		sort.Strings(relations)
+
+		for _, relation := range relations {
+			edges = rewriteEdges(edges, td, relation, td.GetRelations()[relation], edgeUnion)
+		}
+	}
+	return edges
+}
+
+// rewriteEdges appends the edges of the branches of the rewrite of relation of td to edges.
+func rewriteEdges(edges []edge, td *openfgav1.TypeDefinition, relation string, rewrite *openfgav1.Userset, style edgeStyle) []edge {
+	objectType := td.GetType()
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		for _, ref := range td.GetMetadata().GetRelations()[relation].GetDirectlyRelatedUserTypes() {
+			label := relation
+			switch {
+			case ref.GetWildcard() != nil:
+				label += " (" + tuple.TypedPublicWildcard(ref.GetType()) + ")"
+			case ref.GetRelation() != "":
+				label += " (" + tuple.ToObjectRelationString(ref.GetType(), ref.GetRelation()) + ")"
+			}
+			if ref.GetCondition() != "" {
+				label += " with " + ref.GetCondition()
+			}
+			edges = append(edges, edge{from: ref.GetType(), to: objectType, label: label, style: style})
+		}
+	case *openfgav1.Userset_ComputedUserset:
+		edges = append(edges, edge{
+			from:  objectType,
+			to:    objectType,
+			label: relation + " := " + rw.ComputedUserset.GetRelation(),
+			style: style,
+		})
+	case *openfgav1.Userset_TupleToUserset:
+		tupleset := rw.TupleToUserset.GetTupleset().GetRelation()
+		computed := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		var parentTypes []string
+		for _, ref := range td.GetMetadata().GetRelations()[tupleset].GetDirectlyRelatedUserTypes() {
+			if !slices.Contains(parentTypes, ref.GetType()) {
+				parentTypes = append(parentTypes, ref.GetType())
+			}
+		}
+		for _, parentType := range parentTypes {
+			edges = append(edges, edge{
+				from:  parentType,
+				to:    objectType,
+				label: relation + " := " + computed + " from " + tupleset,
+				style: style,
+			})
+		}
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			edges = rewriteEdges(edges, td, relation, child, style)
+		}
+	case *openfgav1.Userset_Intersection:
+		for _, child := range rw.Intersection.GetChild() {
+			edges = rewriteEdges(edges, td, relation, child, edgeIntersection)
+		}
+	case *openfgav1.Userset_Difference:
+		edges = rewriteEdges(edges, td, relation, rw.Difference.GetBase(), style)
+		edges = rewriteEdges(edges, td, relation, rw.Difference.GetSubtract(), edgeExclusion)
+	}
+	return edges
+}
+
+// conditionNames returns the sorted names of conditions.
+func conditionNames(conditions map[string]*openfgav1.Condition) []string {
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// conditionSignature returns the name and the sorted parameters of c, e.g. 'in_region(region: string)'.
+func conditionSignature(c *openfgav1.Condition) string {
+	params := make([]string, 0, len(c.GetParameters()))
+	for name, param := range c.GetParameters() {
+		params = append(params, name+": "+paramTypeName(param))
+	}
+	sort.Strings(params)
+	return c.GetName() + "(" + strings.Join(params, ", ") + ")"
+}
+
+// paramTypeName returns the name of the type of a condition parameter as written in the DSL, e.g.
+// 'list<string>'.
+func paramTypeName(param *openfgav1.ConditionParamTypeRef) string {
+	name := strings.ToLower(strings.TrimPrefix(param.GetTypeName().String(), "TYPE_NAME_"))
+	if len(param.GetGenericTypes()) == 0 {
+		return name
+	}
+	generics := make([]string, 0, len(param.GetGenericTypes()))
+	for _, generic := range param.GetGenericTypes() {
+		generics = append(generics, paramTypeName(generic))
+	}
+	return name + "<" + strings.Join(generics, ", ") + ">"
+}
+
+func renderDOT(types []string, edges []edge, conditions map[string]*openfgav1.Condition) string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph {\n")
+	b.WriteString("graph [rankdir=LR];\n")
+	for _, t := range types {
+		fmt.Fprintf(&b, "%s [shape=box];\n", quote(t))
+	}
+	for _, e := range edges {
+		attrs := "label=" + quote(e.label)
+		switch e.style {
+		case edgeIntersection:
+			attrs += ", style=bold"
+		case edgeExclusion:
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "%s -> %s [%s];\n", quote(e.from), quote(e.to), attrs)
+	}
+	for _, name := range conditionNames(conditions) {
+		c := conditions[name]
+		fmt.Fprintf(&b, "%s [shape=note, label=%s];\n", quote("condition "+name), quote(conditionSignature(c)+"\n"+strings.TrimSpace(c.GetExpression())))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func renderMermaid(types []string, edges []edge, conditions map[string]*openfgav1.Condition) string {
+	// the names of the types are not all valid Mermaid identifiers
+	ids := make(map[string]string, len(types))
+	id := func(t string) string {
+		if _, ok := ids[t]; !ok {
+			ids[t] = fmt.Sprintf("t%d", len(ids))
+		}
+		return ids[t]
+	}
+	// the '#' of the usersets would otherwise start an entity code, and the '<' of the generic
+	// types an HTML tag
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer("#", "#35;", `"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>").Replace(s) + `"`
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, t := range types {
+		fmt.Fprintf(&b, "    %s[%s]\n", id(t), quote(t))
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		switch e.style {
+		case edgeIntersection:
+			arrow = "==>"
+		case edgeExclusion:
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "    %s %s|%s| %s\n", id(e.from), arrow, quote(e.label), id(e.to))
+	}
+	for i, name := range conditionNames(conditions) {
+		c := conditions[name]
+		fmt.Fprintf(&b, "    c%d>%s]\n", i, quote(conditionSignature(c)+"\n"+strings.TrimSpace(c.GetExpression())))
+	}
+	return b.String()
+}
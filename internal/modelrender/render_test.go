@@ -0,0 +1,78 @@
+package modelrender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestRender(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define viewer: [user:*, group#member with in_region]
+		type document
+			relations
+				define parent: [folder]
+				define blocked: [user]
+				define editor: [user] and viewer
+				define viewer: viewer from parent but not blocked
+		condition in_region(region: string, allowed: list<string>) {
+			region in allowed
+		}`)
+
+	t.Run("dot", func(t *testing.T) {
+		graph, err := Render(model, FormatDOT)
+		require.NoError(t, err)
+		require.Equal(t, `digraph {
+graph [rankdir=LR];
+"user" [shape=box];
+"group" [shape=box];
+"folder" [shape=box];
+"document" [shape=box];
+"user" -> "group" [label="member"];
+"group" -> "group" [label="member (group#member)"];
+"user" -> "folder" [label="viewer (user:*)"];
+"group" -> "folder" [label="viewer (group#member) with in_region"];
+"user" -> "document" [label="blocked"];
+"user" -> "document" [label="editor", style=bold];
+"document" -> "document" [label="editor := viewer", style=bold];
+"folder" -> "document" [label="parent"];
+"folder" -> "document" [label="viewer := viewer from parent"];
+"document" -> "document" [label="viewer := blocked", style=dashed];
+"condition in_region" [shape=note, label="in_region(allowed: list<string>, region: string)\nregion in allowed"];
+}
+`, graph)
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		graph, err := Render(model, FormatMermaid)
+		require.NoError(t, err)
+		require.Contains(t, graph, "flowchart LR\n    t0[\"user\"]\n")
+		require.Contains(t, graph, `    t1 -->|"viewer (group#35;member) with in_region"| t2`)
+		require.Contains(t, graph, `    t0 ==>|"editor"| t3`)
+		require.Contains(t, graph, `    t3 -.->|"viewer := blocked"| t3`)
+		require.Contains(t, graph, `    c0>"in_region(allowed: list#lt;string#gt;, region: string)<br/>region in allowed"]`)
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	require.Equal(t, FormatDOT, format)
+
+	format, err = ParseFormat("mermaid")
+	require.NoError(t, err)
+	require.Equal(t, FormatMermaid, format)
+
+	_, err = ParseFormat("svg")
+	require.ErrorContains(t, err, "unsupported model graph format")
+}
@@ -0,0 +1,523 @@
+// Package objectlists materializes the ListObjects results of the (user, relation, object type)
+// triples requested the most in each store, a "tiger cache", and keeps them up to date from the
+// changelog of their store in the background, so that the repeated ListObjects requests, e.g. of
+// the dashboards, are answered with a single lookup instead of a traversal of the graph.
+package objectlists
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/hotpath"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var (
+	// ErrNotEligible is returned when the objects of a triple cannot be materialized, either
+	// because its relation involves an intersection, an exclusion or a condition, or because they
+	// could not all be listed before the ListObjects deadline.
+	ErrNotEligible = errors.New("object list cannot be materialized")
+
+	tracer = otel.Tracer("internal/objectlists")
+
+	materializedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "materialized_object_lists_count",
+		Help:      "The total number of object lists materialized in the background, by outcome.",
+	}, []string{"outcome"})
+)
+
+// Key is a (user, relation, object type) triple of an authorization model of a store.
+type Key struct {
+	StoreID    string
+	ModelID    string
+	ObjectType string
+	Relation   string
+	User       string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("store '%s', '%s' of '%s'", k.StoreID, tuple.ToObjectRelationString(k.ObjectType, k.Relation), k.User)
+}
+
+// ListObjectsFunc lists the objects of a triple, as of the latest writes.
+type ListObjectsFunc func(ctx context.Context, key Key) ([]string, error)
+
+// objectList is the materialized ListObjects result of a triple.
+type objectList struct {
+	objects []string
+
+	// syncedAt is the time up to which the writes are reflected in the objects.
+	syncedAt time.Time
+
+	// lastRequested is the time of the last interval in which the triple was requested.
+	lastRequested time.Time
+
+	// objectTypes are the types of the objects whose tuples can change the objects.
+	objectTypes map[string]struct{}
+}
+
+// Option defines an option that can be used to change the behavior of a Cache.
+type Option func(*Cache)
+
+// WithInterval sets the time between two updates of the object lists. Defaults to 10 seconds.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Cache) {
+		c.interval = interval
+	}
+}
+
+// WithTopN sets the number of triples per store materialized on each update. Defaults to 10.
+func WithTopN(n int) Option {
+	return func(c *Cache) {
+		c.topN = n
+	}
+}
+
+// WithMinRequests sets the number of requests a triple must receive between two updates to be
+// materialized. Defaults to 10.
+func WithMinRequests(minRequests uint64) Option {
+	return func(c *Cache) {
+		c.minRequests = minRequests
+	}
+}
+
+// WithIdleTTL sets how long the object list of a triple that is no longer requested is kept.
+// Defaults to 5 minutes.
+func WithIdleTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.idleTTL = ttl
+	}
+}
+
+// WithHorizonOffset sets how far behind the present the changelog is read, so that the changes of
+// the transactions still being committed are not skipped.
+func WithHorizonOffset(offset time.Duration) Option {
+	return func(c *Cache) {
+		c.horizonOffset = offset
+	}
+}
+
+// WithLogger sets the logger of the Cache.
+func WithLogger(l logger.Logger) Option {
+	return func(c *Cache) {
+		c.logger = l
+	}
+}
+
+// Cache counts the ListObjects requests of every triple, holds the object lists of the hottest
+// ones, and updates them in the background. Each replica materializes the triples it is requested.
+type Cache struct {
+	datastore          storage.ChangelogBackend
+	typesystemResolver typesystem.TypesystemResolverFunc
+	listObjects        ListObjectsFunc
+	interval           time.Duration
+	topN               int
+	minRequests        uint64
+	idleTTL            time.Duration
+	horizonOffset      time.Duration
+	logger             logger.Logger
+
+	counts sync.Map // Key => *atomic.Uint64
+
+	mu          sync.RWMutex
+	lists       map[Key]*objectList
+	tokens      map[string]string               // store => continuation token of the changelog
+	invalidated map[string]map[string]time.Time // store => object type => time of the last write
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// NewCache returns a Cache of the object lists computed with listObjects, and kept up to date from
+// the changelog of datastore.
+func NewCache(datastore storage.ChangelogBackend, typesystemResolver typesystem.TypesystemResolverFunc, listObjects ListObjectsFunc, opts ...Option) *Cache {
+	c := &Cache{
+		datastore:          datastore,
+		typesystemResolver: typesystemResolver,
+		listObjects:        listObjects,
+		interval:           10 * time.Second,
+		topN:               10,
+		minRequests:        10,
+		idleTTL:            5 * time.Minute,
+		logger:             logger.NewNoopLogger(),
+		lists:              make(map[Key]*objectList),
+		tokens:             make(map[string]string),
+		invalidated:        make(map[string]map[string]time.Time),
+		stop:               make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Observe counts one ListObjects request of the triple.
+func (c *Cache) Observe(key Key) {
+	counter, ok := c.counts.Load(key)
+	if !ok {
+		counter, _ = c.counts.LoadOrStore(key, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+// Lookup returns the objects of the triple, if they reflect the writes made until notBefore.
+func (c *Cache) Lookup(key Key, notBefore time.Time) ([]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list, ok := c.lists[key]
+	if !ok || !list.syncedAt.After(notBefore) {
+		return nil, false
+	}
+	// return a copy, as the response may be modified
+	return slices.Clone(list.objects), true
+}
+
+// Invalidate drops the object lists of the store that depend on the tuples of objects of the
+// objectTypes, as these tuples were just written or deleted.
+func (c *Cache) Invalidate(storeID string, objectTypes ...string) {
+	if len(objectTypes) == 0 {
+		return
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	invalidated, ok := c.invalidated[storeID]
+	if !ok {
+		invalidated = make(map[string]time.Time)
+		c.invalidated[storeID] = invalidated
+	}
+	for _, objectType := range objectTypes {
+		invalidated[objectType] = now
+	}
+
+	for key, list := range c.lists {
+		if key.StoreID == storeID && list.dependsOn(objectTypes) {
+			delete(c.lists, key)
+		}
+	}
+}
+
+func (l *objectList) dependsOn(objectTypes []string) bool {
+	for _, objectType := range objectTypes {
+		if _, ok := l.objectTypes[objectType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Start runs the updates of the Cache in the background every interval until Stop is called.
+func (c *Cache) Start() {
+	ticker := time.NewTicker(c.interval)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-c.stop
+			cancel()
+		}()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.Run(ctx); err != nil && ctx.Err() == nil {
+					c.logger.Error("materialized object lists update failed", zap.Error(err))
+				}
+			case <-c.stop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background routine started by Start.
+func (c *Cache) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+// Run updates the Cache once: the object lists that are no longer requested are dropped, the ones
+// affected by the changes of the changelog since the previous update are recomputed, and the
+// hottest triples that are not materialized yet are.
+func (c *Cache) Run(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "objectlists.Cache.Run")
+	defer span.End()
+
+	hottest := c.expire()
+	span.SetAttributes(attribute.Int("hottest", len(hottest)))
+
+	stores := make(map[string][]Key)
+	c.mu.RLock()
+	for key := range c.lists {
+		stores[key.StoreID] = append(stores[key.StoreID], key)
+	}
+	c.mu.RUnlock()
+	for _, key := range hottest {
+		if _, ok := stores[key.StoreID]; !ok {
+			stores[key.StoreID] = nil
+		}
+	}
+
+	var errs error
+	for storeID, keys := range stores {
+		if err := c.update(ctx, storeID, keys); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("store '%s': %w", storeID, err))
+		}
+	}
+
+	for _, key := range hottest {
+		c.mu.RLock()
+		_, ok := c.lists[key]
+		c.mu.RUnlock()
+		if ok {
+			continue
+		}
+		if err := c.materialize(ctx, key); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// expire returns, for every store, the topN triples requested the most, and at least minRequests
+// times, since the previous call, and drops the object lists not requested for the idle TTL. The
+// counts are reset.
+func (c *Cache) expire() []Key {
+	now := time.Now()
+	byStore := make(map[string][]Key)
+	requests := make(map[Key]uint64)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts.Range(func(k, v any) bool {
+		key := k.(Key)
+		count := v.(*atomic.Uint64).Swap(0)
+		if count == 0 {
+			// not requested since the previous call
+			c.counts.Delete(key)
+			return true
+		}
+		if list, ok := c.lists[key]; ok {
+			list.lastRequested = now
+		}
+		if count >= c.minRequests {
+			byStore[key.StoreID] = append(byStore[key.StoreID], key)
+			requests[key] = count
+		}
+		return true
+	})
+
+	for key, list := range c.lists {
+		if now.Sub(list.lastRequested) > c.idleTTL {
+			delete(c.lists, key)
+		}
+	}
+
+	var hottest []Key
+	for _, keys := range byStore {
+		slices.SortFunc(keys, func(a, b Key) int {
+			if c := cmp.Compare(requests[b], requests[a]); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.String(), b.String())
+		})
+		hottest = append(hottest, keys[:min(c.topN, len(keys))]...)
+	}
+	return hottest
+}
+
+// update reads the changelog of the store since the previous update, and recomputes the object
+// lists of keys that depend on the changed objects. The other object lists are up to date as of
+// the changes read.
+func (c *Cache) update(ctx context.Context, storeID string, keys []Key) error {
+	ctx, span := tracer.Start(ctx, "objectlists.Cache.update", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+	))
+	defer span.End()
+
+	// the changes made before syncedAt are visible in the changelog
+	syncedAt := time.Now().Add(-c.horizonOffset)
+
+	c.mu.RLock()
+	token, ok := c.tokens[storeID]
+	c.mu.RUnlock()
+
+	var changed []string
+	var err error
+	if ok {
+		changed, token, err = c.readChanges(ctx, storeID, token)
+	} else {
+		// the object lists of the store are computed after its latest change
+		token, err = c.latestChange(ctx, storeID)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.tokens[storeID] = token
+	var stale []Key
+	for _, key := range keys {
+		list, ok := c.lists[key]
+		if !ok {
+			continue
+		}
+		if list.dependsOn(changed) {
+			stale = append(stale, key)
+			continue
+		}
+		list.syncedAt = syncedAt
+	}
+	c.mu.Unlock()
+
+	var errs error
+	for _, key := range stale {
+		if err := c.materialize(ctx, key); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// materialize computes the object list of the triple, and stores it unless one of the tuples it
+// depends on was written to this replica after it started being computed.
+func (c *Cache) materialize(ctx context.Context, key Key) error {
+	ctx, span := tracer.Start(ctx, "objectlists.Cache.materialize", trace.WithAttributes(
+		attribute.String("store_id", key.StoreID),
+		attribute.String("userset", tuple.ToObjectRelationString(key.ObjectType, key.Relation)),
+	))
+	defer span.End()
+
+	c.mu.Lock()
+	delete(c.lists, key)
+	c.mu.Unlock()
+
+	err := c.compute(ctx, key)
+	switch {
+	case err == nil:
+		materializedCounter.WithLabelValues("materialized").Inc()
+		return nil
+	case errors.Is(err, ErrNotEligible):
+		materializedCounter.WithLabelValues("not_eligible").Inc()
+		c.logger.Debug("object list not materialized",
+			zap.String("store_id", key.StoreID),
+			zap.String("authorization_model_id", key.ModelID),
+			zap.String("userset", tuple.ToObjectRelationString(key.ObjectType, key.Relation)),
+			zap.Error(err))
+		return nil
+	default:
+		materializedCounter.WithLabelValues("error").Inc()
+		return err
+	}
+}
+
+func (c *Cache) compute(ctx context.Context, key Key) error {
+	typesys, err := c.typesystemResolver(ctx, key.StoreID, key.ModelID)
+	if err != nil {
+		return err
+	}
+	deps, err := hotpath.Analyze(typesys, key.ObjectType, key.Relation)
+	if errors.Is(err, hotpath.ErrNotEligible) {
+		return fmt.Errorf("%w: %w", ErrNotEligible, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	objects, err := c.listObjects(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for objectType := range deps.ObjectTypes {
+		if writtenAt, ok := c.invalidated[key.StoreID][objectType]; ok && !start.After(writtenAt) {
+			// recomputed on the next update
+			return nil
+		}
+	}
+	c.lists[key] = &objectList{
+		objects:       objects,
+		syncedAt:      start,
+		lastRequested: start,
+		objectTypes:   deps.ObjectTypes,
+	}
+	return nil
+}
+
+// latestChange returns the continuation token of the latest change of the changelog of a store,
+// or an empty token if it has none.
+func (c *Cache) latestChange(ctx context.Context, storeID string) (string, error) {
+	_, token, err := c.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{HorizonOffset: c.horizonOffset}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(1, ""),
+		SortDesc:   true,
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	return token, err
+}
+
+// readChanges returns the types of the objects changed after the continuation token, and the
+// continuation token of the last change.
+func (c *Cache) readChanges(ctx context.Context, storeID, continuationToken string) ([]string, string, error) {
+	changed := make(map[string]struct{})
+	for {
+		changes, token, err := c.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{HorizonOffset: c.horizonOffset}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, change := range changes {
+			changed[tuple.GetType(change.GetTupleKey().GetObject())] = struct{}{}
+		}
+
+		if token != "" {
+			continuationToken = token
+		}
+		if token == "" || len(changes) < storage.DefaultPageSize {
+			break
+		}
+	}
+
+	objectTypes := make([]string, 0, len(changed))
+	for objectType := range changed {
+		objectTypes = append(objectTypes, objectType)
+	}
+	return objectTypes, continuationToken, nil
+}
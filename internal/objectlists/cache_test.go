@@ -0,0 +1,189 @@
+package objectlists
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const model = `
+	model
+		schema 1.1
+	type user
+	type folder
+		relations
+			define viewer: [user]
+	type document
+		relations
+			define owner: [user]
+			define editor: [user] or owner
+			define restricted: [user] but not owner`
+
+func setup(t *testing.T) (storage.OpenFGADatastore, Key, typesystem.TypesystemResolverFunc) {
+	t.Helper()
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	authorizationModel := testutils.MustTransformDSLToProtoWithID(model)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, authorizationModel))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "owner", "user:anne"),
+	}))
+
+	resolver, stop, err := typesystem.MemoizedTypesystemResolverFunc(ds, 10)
+	require.NoError(t, err)
+	t.Cleanup(stop)
+
+	key := Key{StoreID: storeID, ModelID: authorizationModel.GetId(), ObjectType: "document", Relation: "editor", User: "user:anne"}
+	return ds, key, resolver
+}
+
+// listObjects lists the objects of the tuples of the key's relation and user, and counts its calls.
+func listObjects(ds storage.OpenFGADatastore, calls *atomic.Int32) ListObjectsFunc {
+	return func(ctx context.Context, key Key) ([]string, error) {
+		calls.Add(1)
+		iter, err := ds.ReadStartingWithUser(ctx, key.StoreID, storage.ReadStartingWithUserFilter{
+			ObjectType: key.ObjectType,
+			Relation:   "owner",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: key.User}},
+		}, storage.ReadStartingWithUserOptions{})
+		if err != nil {
+			return nil, err
+		}
+		defer iter.Stop()
+
+		var objects []string
+		for {
+			t, err := iter.Next(ctx)
+			if err != nil {
+				break
+			}
+			objects = append(objects, t.GetKey().GetObject())
+		}
+		return objects, nil
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("materializes_the_hottest_triples", func(t *testing.T) {
+		ds, key, resolver := setup(t)
+		var calls atomic.Int32
+		c := NewCache(ds, resolver, listObjects(ds, &calls), WithTopN(1), WithMinRequests(2))
+
+		other := key
+		other.User = "user:bob"
+		c.Observe(key)
+		c.Observe(key)
+		c.Observe(other)
+		c.Observe(other)
+		c.Observe(other)
+		require.NoError(t, c.Run(ctx))
+
+		_, ok := c.Lookup(key, time.Time{})
+		require.False(t, ok)
+		objects, ok := c.Lookup(other, time.Time{})
+		require.True(t, ok)
+		require.Empty(t, objects)
+
+		c.Observe(key)
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+		objects, ok = c.Lookup(key, time.Time{})
+		require.True(t, ok)
+		require.Equal(t, []string{"document:1"}, objects)
+		require.Equal(t, int32(2), calls.Load())
+
+		_, ok = c.Lookup(key, time.Now())
+		require.False(t, ok)
+	})
+
+	t.Run("recomputes_the_triples_affected_by_the_changes", func(t *testing.T) {
+		ds, key, resolver := setup(t)
+		var calls atomic.Int32
+		c := NewCache(ds, resolver, listObjects(ds, &calls), WithMinRequests(1))
+
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+		require.Equal(t, int32(1), calls.Load())
+
+		require.NoError(t, ds.Write(ctx, key.StoreID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+		}))
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+		require.Equal(t, int32(1), calls.Load())
+
+		require.NoError(t, ds.Write(ctx, key.StoreID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:2", "owner", "user:anne"),
+		}))
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+		require.Equal(t, int32(2), calls.Load())
+
+		objects, ok := c.Lookup(key, time.Time{})
+		require.True(t, ok)
+		require.ElementsMatch(t, []string{"document:1", "document:2"}, objects)
+	})
+
+	t.Run("drops_the_invalidated_triples", func(t *testing.T) {
+		ds, key, resolver := setup(t)
+		var calls atomic.Int32
+		c := NewCache(ds, resolver, listObjects(ds, &calls), WithMinRequests(1))
+
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+
+		c.Invalidate(key.StoreID, "folder")
+		_, ok := c.Lookup(key, time.Time{})
+		require.True(t, ok)
+
+		c.Invalidate(key.StoreID, "document")
+		_, ok = c.Lookup(key, time.Time{})
+		require.False(t, ok)
+	})
+
+	t.Run("drops_the_idle_triples", func(t *testing.T) {
+		ds, key, resolver := setup(t)
+		var calls atomic.Int32
+		c := NewCache(ds, resolver, listObjects(ds, &calls), WithMinRequests(1), WithIdleTTL(time.Millisecond))
+
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+		_, ok := c.Lookup(key, time.Time{})
+		require.True(t, ok)
+
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, c.Run(ctx))
+		_, ok = c.Lookup(key, time.Time{})
+		require.False(t, ok)
+	})
+
+	t.Run("skips_the_ineligible_triples", func(t *testing.T) {
+		ds, key, resolver := setup(t)
+		var calls atomic.Int32
+		c := NewCache(ds, resolver, listObjects(ds, &calls), WithMinRequests(1))
+
+		key.Relation = "restricted"
+		c.Observe(key)
+		require.NoError(t, c.Run(ctx))
+		_, ok := c.Lookup(key, time.Time{})
+		require.False(t, ok)
+		require.Zero(t, calls.Load())
+	})
+}
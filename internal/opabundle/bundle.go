@@ -0,0 +1,170 @@
+// Package opabundle exports the tuples of a store as Open Policy Agent (OPA) bundles, so that OPA
+// agents can evaluate coarse-grained policies against authorization data managed by OpenFGA.
+//
+// The tuples of a store are published under the `openfga/<store_id>` bundle root, keyed by
+// object type, object ID, relation and user:
+//
+//	data.openfga[store_id][object_type][object_id][relation][user]
+//
+// The value is `true` for unconditional tuples, an object holding the condition name and context
+// for conditional tuples, and `false` for tuples deleted since the bundle snapshot was taken.
+// Policies must therefore compare values with `true` rather than relying on their presence.
+package opabundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// RootPrefix is the data path under which the tuples of every store are published.
+const RootPrefix = "openfga"
+
+type manifest struct {
+	Revision string   `json:"revision"`
+	Roots    []string `json:"roots"`
+}
+
+type patchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+type patch struct {
+	Data []patchOperation `json:"data"`
+}
+
+// snapshot holds the tuples of a store in the nested layout published to OPA.
+type snapshot map[string]map[string]map[string]map[string]any
+
+func (s snapshot) add(key *openfgav1.TupleKey) {
+	objectType, objectID := tuple.SplitObject(key.GetObject())
+	objects, ok := s[objectType]
+	if !ok {
+		objects = map[string]map[string]map[string]any{}
+		s[objectType] = objects
+	}
+	relations, ok := objects[objectID]
+	if !ok {
+		relations = map[string]map[string]any{}
+		objects[objectID] = relations
+	}
+	users, ok := relations[key.GetRelation()]
+	if !ok {
+		users = map[string]any{}
+		relations[key.GetRelation()] = users
+	}
+	users[key.GetUser()] = tupleValue(key)
+}
+
+// tupleValue returns the value published for a tuple that exists.
+func tupleValue(key *openfgav1.TupleKey) any {
+	if key.GetCondition().GetName() == "" {
+		return true
+	}
+	value := map[string]any{"condition": key.GetCondition().GetName()}
+	if ctx := key.GetCondition().GetContext(); len(ctx.GetFields()) > 0 {
+		value["context"] = ctx.AsMap()
+	}
+	return value
+}
+
+// delta holds the changes to a store as a list of upserts. Each tuple appears once, with the
+// value of its latest change, in the order the tuples were first changed.
+type delta struct {
+	paths  []string
+	values map[string]any
+}
+
+func (d *delta) add(storeID string, change *openfgav1.TupleChange) {
+	key := change.GetTupleKey()
+	objectType, objectID := tuple.SplitObject(key.GetObject())
+	segments := []string{RootPrefix, storeID, objectType, objectID, key.GetRelation(), key.GetUser()}
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	path := "/" + strings.Join(segments, "/")
+
+	var value any = false
+	if change.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_WRITE {
+		value = tupleValue(&openfgav1.TupleKey{
+			Object:    key.GetObject(),
+			Relation:  key.GetRelation(),
+			User:      key.GetUser(),
+			Condition: key.GetCondition(),
+		})
+	}
+
+	if d.values == nil {
+		d.values = map[string]any{}
+	}
+	if _, ok := d.values[path]; !ok {
+		d.paths = append(d.paths, path)
+	}
+	d.values[path] = value
+}
+
+func (d *delta) len() int {
+	return len(d.paths)
+}
+
+// writeSnapshot writes a gzipped bundle holding every tuple of the store.
+func writeSnapshot(w io.Writer, storeID, revision string, tuples snapshot) error {
+	data := map[string]any{RootPrefix: map[string]any{storeID: tuples}}
+	return writeBundle(w, storeID, revision, "data.json", data)
+}
+
+// writeDelta writes a gzipped delta bundle that patches a previous bundle of the store.
+func writeDelta(w io.Writer, storeID, revision string, d *delta) error {
+	p := patch{Data: make([]patchOperation, 0, len(d.paths))}
+	for _, path := range d.paths {
+		p.Data = append(p.Data, patchOperation{Op: "upsert", Path: path, Value: d.values[path]})
+	}
+	return writeBundle(w, storeID, revision, "patch.json", p)
+}
+
+func writeBundle(w io.Writer, storeID, revision, name string, content any) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	files := []struct {
+		name    string
+		content any
+	}{
+		{".manifest", manifest{Revision: revision, Roots: []string{RootPrefix + "/" + storeID}}},
+		{name, content},
+	}
+	for _, file := range files {
+		b, err := json.Marshal(file.content)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", file.name, err)
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     "/" + file.name,
+			Mode:     0o600,
+			Typeflag: tar.TypeReg,
+			Size:     int64(len(b)),
+			ModTime:  time.Unix(0, 0),
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(b); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,231 @@
+package opabundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+const (
+	// pageSize is the page size used to read tuples and changes.
+	pageSize = 100
+
+	// snapshotClockSkew is subtracted from the time a snapshot starts when recording its revision,
+	// so that changes written by servers with a slightly different clock are not missed. Replaying
+	// changes that are already part of the snapshot is harmless, because deltas only hold upserts.
+	snapshotClockSkew = time.Minute
+
+	changeTokenPrefix = "c."
+	timePrefix        = "t."
+
+	contentType = "application/gzip"
+)
+
+// Client is the subset of the OpenFGA API used to export bundles. Using the API, rather than the
+// datastore, ensures that bundle requests go through authentication and access control.
+type Client interface {
+	Read(ctx context.Context, in *openfgav1.ReadRequest, opts ...grpc.CallOption) (*openfgav1.ReadResponse, error)
+	ReadChanges(ctx context.Context, in *openfgav1.ReadChangesRequest, opts ...grpc.CallOption) (*openfgav1.ReadChangesResponse, error)
+}
+
+// Exporter serves the tuples of a store as OPA bundles. The revision of a bundle is returned as its
+// ETag: when OPA polls again with the previous ETag in If-None-Match, the exporter replies with
+// 304 Not Modified if the store did not change, or with a delta bundle holding the changes since
+// that revision. A full snapshot is served when no usable revision is provided or the delta would
+// be too large.
+type Exporter struct {
+	client          Client
+	maxTuples       int
+	maxDeltaChanges int
+	now             func() time.Time
+}
+
+// ExporterOption configures an Exporter.
+type ExporterOption func(*Exporter)
+
+// WithMaxTuples sets the maximum number of tuples in a snapshot bundle. Stores with more tuples
+// cannot be exported. Zero means no limit.
+func WithMaxTuples(n int) ExporterOption {
+	return func(e *Exporter) {
+		e.maxTuples = n
+	}
+}
+
+// WithMaxDeltaChanges sets the maximum number of changes in a delta bundle. A snapshot is served
+// instead when more tuples changed since the revision known by the client.
+func WithMaxDeltaChanges(n int) ExporterOption {
+	return func(e *Exporter) {
+		e.maxDeltaChanges = n
+	}
+}
+
+// NewExporter returns an Exporter that reads the tuples through client.
+func NewExporter(client Client, opts ...ExporterOption) *Exporter {
+	e := &Exporter{
+		client:          client,
+		maxDeltaChanges: 10_000,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ServeBundle writes the bundle of a store to w. The request context must carry the credentials
+// used to call the OpenFGA API. Errors are returned before anything is written to w, so that the
+// caller can encode them.
+func (e *Exporter) ServeBundle(w http.ResponseWriter, r *http.Request, storeID string) error {
+	ctx := r.Context()
+
+	if revision, ok := e.knownRevision(r); ok {
+		changes, newRevision, err := e.readDelta(ctx, storeID, revision)
+		switch {
+		case err != nil && !isInvalidRevision(err):
+			return err
+		case err != nil:
+			// The revision is unknown to this store (e.g. a token of another store): fall back to
+			// a snapshot.
+		case changes.len() == 0:
+			w.Header().Set("ETag", strconv.Quote(newRevision))
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		case e.maxDeltaChanges <= 0 || changes.len() <= e.maxDeltaChanges:
+			return writeResponse(w, newRevision, func(buf *bytes.Buffer) error {
+				return writeDelta(buf, storeID, newRevision, changes)
+			})
+		}
+	}
+
+	tuples, revision, err := e.readSnapshot(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	return writeResponse(w, revision, func(buf *bytes.Buffer) error {
+		return writeSnapshot(buf, storeID, revision, tuples)
+	})
+}
+
+// knownRevision returns the revision from the If-None-Match header of a bundle request.
+func (e *Exporter) knownRevision(r *http.Request) (string, bool) {
+	value := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	value = strings.TrimPrefix(value, "W/")
+	revision, err := strconv.Unquote(value)
+	if err != nil {
+		return "", false
+	}
+	return revision, strings.HasPrefix(revision, changeTokenPrefix) || strings.HasPrefix(revision, timePrefix)
+}
+
+// readSnapshot reads every tuple of the store. The returned revision is a point in time before the
+// snapshot started, so that no change made while reading is missed by the following deltas.
+func (e *Exporter) readSnapshot(ctx context.Context, storeID string) (snapshot, string, error) {
+	revision := timePrefix + strconv.FormatInt(e.now().Add(-snapshotClockSkew).UnixNano(), 10)
+
+	tuples := snapshot{}
+	count := 0
+	token := ""
+	for {
+		resp, err := e.client.Read(ctx, &openfgav1.ReadRequest{
+			StoreId:           storeID,
+			PageSize:          wrapperspb.Int32(pageSize),
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		for _, t := range resp.GetTuples() {
+			tuples.add(t.GetKey())
+		}
+		count += len(resp.GetTuples())
+		if e.maxTuples > 0 && count > e.maxTuples {
+			return nil, "", status.Errorf(codes.FailedPrecondition, "the store has more than %d tuples and cannot be exported as a bundle", e.maxTuples)
+		}
+
+		token = resp.GetContinuationToken()
+		if token == "" {
+			return tuples, revision, nil
+		}
+	}
+}
+
+// readDelta reads the changes since a revision and returns them with the new revision.
+func (e *Exporter) readDelta(ctx context.Context, storeID, revision string) (*delta, string, error) {
+	req := &openfgav1.ReadChangesRequest{
+		StoreId:  storeID,
+		PageSize: wrapperspb.Int32(pageSize),
+	}
+	if token, ok := strings.CutPrefix(revision, changeTokenPrefix); ok {
+		req.ContinuationToken = token
+	} else {
+		nanos, err := strconv.ParseInt(strings.TrimPrefix(revision, timePrefix), 10, 64)
+		if err != nil {
+			return nil, "", status.Error(codes.InvalidArgument, "invalid bundle revision")
+		}
+		req.StartTime = timestamppb.New(time.Unix(0, nanos))
+	}
+
+	changes := &delta{}
+	for {
+		resp, err := e.client.ReadChanges(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, change := range resp.GetChanges() {
+			changes.add(storeID, change)
+		}
+		if token := resp.GetContinuationToken(); token != "" {
+			revision = changeTokenPrefix + token
+		}
+
+		if len(resp.GetChanges()) < pageSize || resp.GetContinuationToken() == "" {
+			return changes, revision, nil
+		}
+		if e.maxDeltaChanges > 0 && changes.len() > e.maxDeltaChanges {
+			// The client will receive a snapshot, so there is no need to read further.
+			return changes, revision, nil
+		}
+		req.StartTime = nil
+		req.ContinuationToken = resp.GetContinuationToken()
+	}
+}
+
+// isInvalidRevision reports whether err is caused by a revision that cannot be used to read changes.
+func isInvalidRevision(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument,
+		codes.Code(openfgav1.ErrorCode_invalid_continuation_token),
+		codes.Code(openfgav1.ErrorCode_invalid_start_time),
+		codes.Code(openfgav1.ErrorCode_query_string_type_continuation_token_mismatch):
+		return true
+	default:
+		return false
+	}
+}
+
+func writeResponse(w http.ResponseWriter, revision string, write func(*bytes.Buffer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", strconv.Quote(revision))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	// The status has already been sent, so a failure to write the body cannot be reported.
+	_, _ = buf.WriteTo(w)
+	return nil
+}
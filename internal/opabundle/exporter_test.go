@@ -0,0 +1,171 @@
+package opabundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type fakeClient struct {
+	tuples  []*openfgav1.Tuple
+	changes []*openfgav1.TupleChange
+}
+
+func (f *fakeClient) Read(_ context.Context, in *openfgav1.ReadRequest, _ ...grpc.CallOption) (*openfgav1.ReadResponse, error) {
+	start := 0
+	if in.GetContinuationToken() != "" {
+		start, _ = strconv.Atoi(in.GetContinuationToken())
+	}
+	end := min(start+int(in.GetPageSize().GetValue()), len(f.tuples))
+	resp := &openfgav1.ReadResponse{Tuples: f.tuples[start:end]}
+	if end < len(f.tuples) {
+		resp.ContinuationToken = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) ReadChanges(_ context.Context, in *openfgav1.ReadChangesRequest, _ ...grpc.CallOption) (*openfgav1.ReadChangesResponse, error) {
+	start := 0
+	if token := in.GetContinuationToken(); token != "" {
+		var err error
+		if start, err = strconv.Atoi(token); err != nil {
+			return nil, status.Error(codes.Code(openfgav1.ErrorCode_invalid_continuation_token), "Invalid continuation token")
+		}
+	}
+	if start >= len(f.changes) {
+		return &openfgav1.ReadChangesResponse{ContinuationToken: in.GetContinuationToken()}, nil
+	}
+	end := min(start+int(in.GetPageSize().GetValue()), len(f.changes))
+	return &openfgav1.ReadChangesResponse{
+		Changes:           f.changes[start:end],
+		ContinuationToken: strconv.Itoa(end),
+	}, nil
+}
+
+func readBundle(t *testing.T, body io.Reader) map[string]any {
+	t.Helper()
+	gr, err := gzip.NewReader(body)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	files := map[string]any{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		var content any
+		require.NoError(t, json.NewDecoder(tr).Decode(&content))
+		files[header.Name] = content
+	}
+}
+
+func serve(t *testing.T, exporter *Exporter, ifNoneMatch string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/stores/store1/opa/bundle", nil)
+	if ifNoneMatch != "" {
+		r.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	require.NoError(t, exporter.ServeBundle(w, r, "store1"))
+	return w
+}
+
+func TestServeBundle(t *testing.T) {
+	client := &fakeClient{}
+	for i := 0; i < 150; i++ {
+		client.tuples = append(client.tuples, &openfgav1.Tuple{Key: tuple.NewTupleKey("document:"+strconv.Itoa(i), "viewer", "user:anne")})
+	}
+	client.tuples = append(client.tuples, &openfgav1.Tuple{Key: tuple.NewTupleKeyWithCondition("document:1", "editor", "group:eng#member", "in_office", nil)})
+
+	exporter := NewExporter(client)
+	exporter.now = func() time.Time { return time.Unix(100, 0) }
+
+	t.Run("snapshot", func(t *testing.T) {
+		w := serve(t, exporter, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, contentType, w.Header().Get("Content-Type"))
+		etag := w.Header().Get("ETag")
+		require.Equal(t, strconv.Quote("t."+strconv.FormatInt(time.Unix(40, 0).UnixNano(), 10)), etag)
+
+		files := readBundle(t, w.Body)
+		require.Equal(t, map[string]any{
+			"revision": "t." + strconv.FormatInt(time.Unix(40, 0).UnixNano(), 10),
+			"roots":    []any{"openfga/store1"},
+		}, files["/.manifest"])
+
+		store := files["/data.json"].(map[string]any)["openfga"].(map[string]any)["store1"].(map[string]any)
+		documents := store["document"].(map[string]any)
+		require.Len(t, documents, 150)
+		require.Equal(t, map[string]any{
+			"viewer": map[string]any{"user:anne": true},
+			"editor": map[string]any{"group:eng#member": map[string]any{"condition": "in_office"}},
+		}, documents["1"])
+	})
+
+	t.Run("not_modified", func(t *testing.T) {
+		etag := serve(t, exporter, "").Header().Get("ETag")
+		w := serve(t, exporter, etag)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("delta", func(t *testing.T) {
+		client.changes = []*openfgav1.TupleChange{
+			{TupleKey: tuple.NewTupleKey("document:1", "viewer", "user:bob"), Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE},
+			{TupleKey: tuple.NewTupleKey("document:1", "viewer", "user:anne"), Operation: openfgav1.TupleOperation_TUPLE_OPERATION_DELETE},
+			{TupleKey: tuple.NewTupleKey("document:1", "viewer", "user:bob"), Operation: openfgav1.TupleOperation_TUPLE_OPERATION_DELETE},
+			{TupleKey: tuple.NewTupleKey("folder:a/b", "viewer", "group:eng#member"), Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE},
+		}
+		etag := serve(t, exporter, "").Header().Get("ETag")
+
+		w := serve(t, exporter, etag)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, strconv.Quote("c.4"), w.Header().Get("ETag"))
+		files := readBundle(t, w.Body)
+		require.NotContains(t, files, "/data.json")
+		require.Equal(t, map[string]any{"data": []any{
+			map[string]any{"op": "upsert", "path": "/openfga/store1/document/1/viewer/user:bob", "value": false},
+			map[string]any{"op": "upsert", "path": "/openfga/store1/document/1/viewer/user:anne", "value": false},
+			map[string]any{"op": "upsert", "path": "/openfga/store1/folder/a%2Fb/viewer/group:eng%23member", "value": true},
+		}}, files["/patch.json"])
+
+		w = serve(t, exporter, `W/"c.4"`)
+		require.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("snapshot_when_delta_is_too_large", func(t *testing.T) {
+		w := serve(t, NewExporter(client, WithMaxDeltaChanges(1)), `"c.0"`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, readBundle(t, w.Body), "/data.json")
+	})
+
+	t.Run("snapshot_when_revision_is_invalid", func(t *testing.T) {
+		w := serve(t, exporter, `"c.invalid"`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, readBundle(t, w.Body), "/data.json")
+	})
+
+	t.Run("max_tuples", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/stores/store1/opa/bundle", nil)
+		err := NewExporter(client, WithMaxTuples(100)).ServeBundle(httptest.NewRecorder(), r, "store1")
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
@@ -0,0 +1,136 @@
+package orphans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// CollectorOption defines an option that can be used to change the behavior of a Collector.
+type CollectorOption func(*Collector)
+
+// WithInterval sets the time between two runs of the Collector.
+func WithInterval(interval time.Duration) CollectorOption {
+	return func(c *Collector) {
+		c.interval = interval
+	}
+}
+
+// WithDryRun makes the Collector only report the orphaned tuples it finds, without deleting them.
+func WithDryRun(dryRun bool) CollectorOption {
+	return func(c *Collector) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithLogger sets the logger of the Collector.
+func WithLogger(l logger.Logger) CollectorOption {
+	return func(c *Collector) {
+		c.logger = l
+	}
+}
+
+// Collector periodically collects the orphaned tuples of every store.
+type Collector struct {
+	store    Store
+	stores   storage.StoresBackend
+	interval time.Duration
+	dryRun   bool
+	logger   logger.Logger
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// NewCollector returns a Collector that collects the orphaned tuples of the stores listed by stores.
+// By default, the Collector runs every 24 hours in dry-run mode.
+func NewCollector(store Store, stores storage.StoresBackend, opts ...CollectorOption) *Collector {
+	c := &Collector{
+		store:    store,
+		stores:   stores,
+		interval: 24 * time.Hour,
+		dryRun:   true,
+		logger:   logger.NewNoopLogger(),
+		stop:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Start runs the Collector in the background every interval until Stop is called.
+func (c *Collector) Start() {
+	ticker := time.NewTicker(c.interval)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.Run(context.Background()); err != nil {
+					c.logger.Error("orphaned tuples collection failed", zap.Error(err))
+				}
+			case <-c.stop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background routine started by Start.
+func (c *Collector) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+// Run collects the orphaned tuples of every store once.
+func (c *Collector) Run(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "orphans.Collector.Run")
+	defer span.End()
+
+	var continuationToken string
+	var errs error
+	for {
+		stores, token, err := c.stores.ListStores(ctx, storage.ListStoresOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if err != nil {
+			return errors.Join(errs, err)
+		}
+
+		for _, store := range stores {
+			report, err := Collect(ctx, c.store, store.GetId(), c.dryRun)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("store '%s': %w", store.GetId(), err))
+				continue
+			}
+			if len(report.OrphanedTuples) > 0 {
+				c.logger.Info("orphaned tuples found",
+					zap.String("store_id", report.StoreID),
+					zap.String("authorization_model_id", report.AuthorizationModelID),
+					zap.Bool("dry_run", report.DryRun),
+					zap.Int("orphaned_tuples", len(report.OrphanedTuples)),
+					zap.Int("deleted_tuples", report.DeletedTuples),
+				)
+			}
+		}
+
+		if token == "" {
+			break
+		}
+		continuationToken = token
+	}
+
+	return errs
+}
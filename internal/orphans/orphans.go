@@ -0,0 +1,181 @@
+// Package orphans finds, and optionally deletes, the tuples of a store that reference types,
+// relations or conditions that are not defined in the latest authorization model of the store.
+package orphans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var (
+	tracer = otel.Tracer("internal/orphans")
+
+	orphanedTuplesFoundCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "orphaned_tuples_found_count",
+		Help:      "The total number of tuples found referencing types, relations or conditions that are not defined in the latest model of their store.",
+	})
+
+	orphanedTuplesDeletedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "orphaned_tuples_deleted_count",
+		Help:      "The total number of orphaned tuples deleted.",
+	})
+)
+
+// Store gives access to the tuples and models of the stores.
+type Store interface {
+	// LatestModel returns the latest authorization model of a store, or storage.ErrNotFound if
+	// the store has no model.
+	LatestModel(ctx context.Context, storeID string) (*openfgav1.AuthorizationModel, error)
+
+	// ReadPage returns a page of the tuples of a store and the token of the next page, which is
+	// empty on the last page.
+	ReadPage(ctx context.Context, storeID, continuationToken string) ([]*openfgav1.Tuple, string, error)
+
+	// Delete deletes tuples from a store. It is never called with more than
+	// storage.DefaultMaxTuplesPerWrite tuples.
+	Delete(ctx context.Context, storeID string, tks []*openfgav1.TupleKeyWithoutCondition) error
+}
+
+// OrphanedTuple is a tuple that references a type, relation or condition that is not defined.
+type OrphanedTuple struct {
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+	User      string `json:"user"`
+	Condition string `json:"condition,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// Report is the result of a collection of the orphaned tuples of a store.
+type Report struct {
+	StoreID              string          `json:"store_id"`
+	AuthorizationModelID string          `json:"authorization_model_id"`
+	DryRun               bool            `json:"dry_run"`
+	ScannedTuples        int             `json:"scanned_tuples"`
+	OrphanedTuples       []OrphanedTuple `json:"orphaned_tuples"`
+	DeletedTuples        int             `json:"deleted_tuples"`
+}
+
+// Reason returns why the tuple is orphaned in the given model, or an empty string if every type,
+// relation and condition that the tuple references is defined.
+func Reason(typesys *typesystem.TypeSystem, tk *openfgav1.TupleKey) string {
+	objectType, _ := tuple.SplitObject(tk.GetObject())
+	if _, ok := typesys.GetTypeDefinition(objectType); !ok {
+		return fmt.Sprintf("type '%s' is not defined", objectType)
+	}
+	if _, err := typesys.GetRelation(objectType, tk.GetRelation()); err != nil {
+		return fmt.Sprintf("relation '%s#%s' is not defined", objectType, tk.GetRelation())
+	}
+
+	userType, _, userRelation := tuple.ToUserParts(tk.GetUser())
+	if _, ok := typesys.GetTypeDefinition(userType); !ok {
+		return fmt.Sprintf("type '%s' is not defined", userType)
+	}
+	if userRelation != "" {
+		if _, err := typesys.GetRelation(userType, userRelation); err != nil {
+			return fmt.Sprintf("relation '%s#%s' is not defined", userType, userRelation)
+		}
+	}
+
+	if name := tk.GetCondition().GetName(); name != "" {
+		if _, ok := typesys.GetCondition(name); !ok {
+			return fmt.Sprintf("condition '%s' is not defined", name)
+		}
+	}
+	return ""
+}
+
+// Collect finds the orphaned tuples of a store and, unless dryRun is set, deletes them. Stores
+// without a model, or whose latest model uses a schema older than 1.1, have no orphaned tuples.
+func Collect(ctx context.Context, store Store, storeID string, dryRun bool) (*Report, error) {
+	ctx, span := tracer.Start(ctx, "orphans.Collect", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.Bool("dry_run", dryRun),
+	))
+	defer span.End()
+
+	report := &Report{StoreID: storeID, DryRun: dryRun, OrphanedTuples: []OrphanedTuple{}}
+
+	model, err := store.LatestModel(ctx, storeID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return report, nil
+		}
+		return nil, err
+	}
+	report.AuthorizationModelID = model.GetId()
+	if !typesystem.IsSchemaVersionSupported(model.GetSchemaVersion()) {
+		return report, nil
+	}
+
+	typesys, err := typesystem.New(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphaned []*openfgav1.TupleKeyWithoutCondition
+	var continuationToken string
+	for {
+		tuples, token, err := store.ReadPage(ctx, storeID, continuationToken)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tuples {
+			report.ScannedTuples++
+			tk := t.GetKey()
+			reason := Reason(typesys, tk)
+			if reason == "" {
+				continue
+			}
+			report.OrphanedTuples = append(report.OrphanedTuples, OrphanedTuple{
+				Object:    tk.GetObject(),
+				Relation:  tk.GetRelation(),
+				User:      tk.GetUser(),
+				Condition: tk.GetCondition().GetName(),
+				Reason:    reason,
+			})
+			orphaned = append(orphaned, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+		}
+
+		if token == "" {
+			break
+		}
+		continuationToken = token
+	}
+	orphanedTuplesFoundCounter.Add(float64(len(orphaned)))
+
+	if !dryRun {
+		// Deleting while reading could make the datastore skip tuples, so the tuples are only
+		// deleted once the store was fully scanned.
+		for start := 0; start < len(orphaned); start += storage.DefaultMaxTuplesPerWrite {
+			end := min(start+storage.DefaultMaxTuplesPerWrite, len(orphaned))
+			if err := store.Delete(ctx, storeID, orphaned[start:end]); err != nil {
+				return nil, err
+			}
+			report.DeletedTuples += end - start
+			orphanedTuplesDeletedCounter.Add(float64(end - start))
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("orphaned_tuples", len(report.OrphanedTuples)),
+		attribute.Int("deleted_tuples", report.DeletedTuples),
+	)
+	return report, nil
+}
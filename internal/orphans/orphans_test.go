@@ -0,0 +1,195 @@
+package orphans
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	oldModel = `
+	model
+		schema 1.1
+	type user
+	type team
+		relations
+			define member: [user]
+	type folder
+		relations
+			define viewer: [user]
+	type document
+		relations
+			define viewer: [user, team#member, user with in_office]
+			define editor: [user]
+
+	condition in_office(ip: ipaddress) {
+		ip.in_cidr("10.0.0.0/8")
+	}`
+
+	newModel = `
+	model
+		schema 1.1
+	type user
+	type team
+		relations
+			define lead: [user]
+	type document
+		relations
+			define viewer: [user, team#lead]`
+)
+
+func writeModel(t *testing.T, ds storage.OpenFGADatastore, storeID, dsl string) *openfgav1.AuthorizationModel {
+	t.Helper()
+	model := testutils.MustTransformDSLToProtoWithID(dsl)
+	require.NoError(t, ds.WriteAuthorizationModel(context.Background(), storeID, model))
+	return model
+}
+
+func TestReason(t *testing.T) {
+	typesys, err := typesystem.New(parser.MustTransformDSLToProto(newModel))
+	require.NoError(t, err)
+
+	tests := []struct {
+		tuple  *openfgav1.TupleKey
+		reason string
+	}{
+		{tuple.NewTupleKey("document:1", "viewer", "user:anne"), ""},
+		{tuple.NewTupleKey("document:1", "viewer", "team:eng#lead"), ""},
+		{tuple.NewTupleKey("folder:1", "viewer", "user:anne"), "type 'folder' is not defined"},
+		{tuple.NewTupleKey("document:1", "editor", "user:anne"), "relation 'document#editor' is not defined"},
+		{tuple.NewTupleKey("document:1", "viewer", "group:eng#member"), "type 'group' is not defined"},
+		{tuple.NewTupleKey("document:1", "viewer", "team:eng#member"), "relation 'team#member' is not defined"},
+		{tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "in_office", nil), "condition 'in_office' is not defined"},
+	}
+
+	for _, test := range tests {
+		t.Run(tuple.TupleKeyToString(test.tuple), func(t *testing.T) {
+			require.Equal(t, test.reason, Reason(typesys, test.tuple))
+		})
+	}
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	store := NewDatastoreStore(ds)
+
+	t.Run("store_without_model", func(t *testing.T) {
+		report, err := Collect(ctx, store, ulid.Make().String(), false)
+		require.NoError(t, err)
+		require.Empty(t, report.AuthorizationModelID)
+		require.Empty(t, report.OrphanedTuples)
+	})
+
+	storeID := ulid.Make().String()
+	writeModel(t, ds, storeID, oldModel)
+
+	var writes []*openfgav1.TupleKey
+	for i := 0; i < 150; i++ {
+		writes = append(writes, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne"))
+	}
+	writes = append(writes,
+		tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:1", "viewer", "team:eng#member"),
+		tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:bob", "in_office", nil),
+	)
+	for start := 0; start < len(writes); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(writes))
+		require.NoError(t, ds.Write(ctx, storeID, nil, writes[start:end]))
+	}
+
+	report, err := Collect(ctx, store, storeID, true)
+	require.NoError(t, err)
+	require.Empty(t, report.OrphanedTuples, "every tuple is valid in the model it was written with")
+
+	model := writeModel(t, ds, storeID, newModel)
+
+	t.Run("dry_run", func(t *testing.T) {
+		report, err := Collect(ctx, store, storeID, true)
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), report.AuthorizationModelID)
+		require.True(t, report.DryRun)
+		require.Equal(t, len(writes), report.ScannedTuples)
+		require.ElementsMatch(t, []OrphanedTuple{
+			{Object: "document:1", Relation: "editor", User: "user:anne", Reason: "relation 'document#editor' is not defined"},
+			{Object: "folder:1", Relation: "viewer", User: "user:anne", Reason: "type 'folder' is not defined"},
+			{Object: "document:1", Relation: "viewer", User: "team:eng#member", Reason: "relation 'team#member' is not defined"},
+			{Object: "document:1", Relation: "viewer", User: "user:bob", Condition: "in_office", Reason: "condition 'in_office' is not defined"},
+		}, report.OrphanedTuples)
+		require.Zero(t, report.DeletedTuples)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		report, err := Collect(ctx, store, storeID, false)
+		require.NoError(t, err)
+		require.Len(t, report.OrphanedTuples, 4)
+		require.Equal(t, 4, report.DeletedTuples)
+
+		report, err = Collect(ctx, store, storeID, true)
+		require.NoError(t, err)
+		require.Equal(t, len(writes)-4, report.ScannedTuples)
+		require.Empty(t, report.OrphanedTuples)
+	})
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	var storeIDs []string
+	for i := 0; i < 3; i++ {
+		storeID := ulid.Make().String()
+		_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: storeID})
+		require.NoError(t, err)
+		writeModel(t, ds, storeID, oldModel)
+		require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		}))
+		writeModel(t, ds, storeID, newModel)
+		storeIDs = append(storeIDs, storeID)
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		collector := NewCollector(NewDatastoreStore(ds), ds)
+		require.NoError(t, collector.Run(ctx))
+
+		for _, storeID := range storeIDs {
+			report, err := Collect(ctx, NewDatastoreStore(ds), storeID, true)
+			require.NoError(t, err)
+			require.Len(t, report.OrphanedTuples, 1)
+		}
+	})
+
+	t.Run("background", func(t *testing.T) {
+		collector := NewCollector(NewDatastoreStore(ds), ds, WithDryRun(false), WithInterval(10*time.Millisecond))
+		collector.Start()
+		t.Cleanup(collector.Stop)
+
+		require.Eventually(t, func() bool {
+			for _, storeID := range storeIDs {
+				report, err := Collect(ctx, NewDatastoreStore(ds), storeID, true)
+				if err != nil || len(report.OrphanedTuples) > 0 {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,95 @@
+package orphans
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// pageSize is the page size used to read tuples.
+const pageSize = 100
+
+type datastoreStore struct {
+	datastore storage.OpenFGADatastore
+}
+
+// NewDatastoreStore returns a Store that reads and deletes tuples directly in the datastore.
+func NewDatastoreStore(datastore storage.OpenFGADatastore) Store {
+	return &datastoreStore{datastore: datastore}
+}
+
+func (s *datastoreStore) LatestModel(ctx context.Context, storeID string) (*openfgav1.AuthorizationModel, error) {
+	return s.datastore.FindLatestAuthorizationModel(ctx, storeID)
+}
+
+func (s *datastoreStore) ReadPage(ctx context.Context, storeID, continuationToken string) ([]*openfgav1.Tuple, string, error) {
+	return s.datastore.ReadPage(ctx, storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+		Pagination:  storage.NewPaginationOptions(pageSize, continuationToken),
+		Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+	})
+}
+
+func (s *datastoreStore) Delete(ctx context.Context, storeID string, tks []*openfgav1.TupleKeyWithoutCondition) error {
+	return s.datastore.Write(ctx, storeID, tks, nil, storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore))
+}
+
+// Client is the subset of the OpenFGA API used to collect orphaned tuples. Using the API, rather
+// than the datastore, ensures that on-demand collections go through authentication and access
+// control.
+type Client interface {
+	ReadAuthorizationModels(ctx context.Context, in *openfgav1.ReadAuthorizationModelsRequest, opts ...grpc.CallOption) (*openfgav1.ReadAuthorizationModelsResponse, error)
+	Read(ctx context.Context, in *openfgav1.ReadRequest, opts ...grpc.CallOption) (*openfgav1.ReadResponse, error)
+	Write(ctx context.Context, in *openfgav1.WriteRequest, opts ...grpc.CallOption) (*openfgav1.WriteResponse, error)
+}
+
+type clientStore struct {
+	client Client
+}
+
+// NewClientStore returns a Store that reads and deletes tuples through the OpenFGA API.
+func NewClientStore(client Client) Store {
+	return &clientStore{client: client}
+}
+
+func (s *clientStore) LatestModel(ctx context.Context, storeID string) (*openfgav1.AuthorizationModel, error) {
+	resp, err := s.client.ReadAuthorizationModels(ctx, &openfgav1.ReadAuthorizationModelsRequest{
+		StoreId:  storeID,
+		PageSize: wrapperspb.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.GetAuthorizationModels()) == 0 {
+		return nil, storage.ErrNotFound
+	}
+	return resp.GetAuthorizationModels()[0], nil
+}
+
+func (s *clientStore) ReadPage(ctx context.Context, storeID, continuationToken string) ([]*openfgav1.Tuple, string, error) {
+	resp, err := s.client.Read(ctx, &openfgav1.ReadRequest{
+		StoreId:           storeID,
+		PageSize:          wrapperspb.Int32(pageSize),
+		ContinuationToken: continuationToken,
+		Consistency:       openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.GetTuples(), resp.GetContinuationToken(), nil
+}
+
+func (s *clientStore) Delete(ctx context.Context, storeID string, tks []*openfgav1.TupleKeyWithoutCondition) error {
+	_, err := s.client.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Deletes: &openfgav1.WriteRequestDeletes{
+			TupleKeys: tks,
+			OnMissing: "ignore",
+		},
+	})
+	return err
+}
@@ -0,0 +1,114 @@
+package recording
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// pseudonymLength is the number of hex characters of the HMAC kept in a pseudonym.
+const pseudonymLength = 16
+
+// Anonymizer replaces the object and user IDs, and the string values of the condition contexts, of
+// the recorded requests with keyed pseudonyms. Types, relations and condition names are kept, so
+// the shape of the traffic is preserved, and the same ID always maps to the same pseudonym for a
+// given key.
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer returns an Anonymizer whose pseudonyms are derived from key.
+func NewAnonymizer(key []byte) *Anonymizer {
+	return &Anonymizer{key: key}
+}
+
+// Pseudonym returns the pseudonym of s.
+func (a *Anonymizer) Pseudonym(s string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
+
+// Object anonymizes an object such as "document:roadmap". Wildcards are kept as is.
+func (a *Anonymizer) Object(object string) string {
+	objectType, objectID := tuple.SplitObject(object)
+	if objectType == "" || objectID == "" || tuple.IsWildcard(objectID) {
+		return object
+	}
+	return tuple.BuildObject(objectType, a.Pseudonym(objectID))
+}
+
+// User anonymizes a user such as "user:anne", "group:eng#member" or "user:*".
+func (a *Anonymizer) User(user string) string {
+	userObject, userRelation := tuple.SplitObjectRelation(user)
+	if userRelation == "" {
+		return a.Object(userObject)
+	}
+	return tuple.ToObjectRelationString(a.Object(userObject), userRelation)
+}
+
+// TupleKey anonymizes a tuple key in place.
+func (a *Anonymizer) TupleKey(tk *openfgav1.TupleKey) {
+	if tk == nil {
+		return
+	}
+	tk.Object = a.Object(tk.GetObject())
+	tk.User = a.User(tk.GetUser())
+	if tk.GetCondition() != nil {
+		a.Struct(tk.GetCondition().GetContext())
+	}
+}
+
+// Struct anonymizes the string values of a condition context in place.
+func (a *Anonymizer) Struct(s *structpb.Struct) {
+	for _, v := range s.GetFields() {
+		a.value(v)
+	}
+}
+
+func (a *Anonymizer) value(v *structpb.Value) {
+	switch kind := v.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		kind.StringValue = a.Pseudonym(kind.StringValue)
+	case *structpb.Value_StructValue:
+		a.Struct(kind.StructValue)
+	case *structpb.Value_ListValue:
+		for _, item := range kind.ListValue.GetValues() {
+			a.value(item)
+		}
+	}
+}
+
+// CheckRequest anonymizes a Check request in place.
+func (a *Anonymizer) CheckRequest(req *openfgav1.CheckRequest) {
+	if tk := req.GetTupleKey(); tk != nil {
+		tk.Object = a.Object(tk.GetObject())
+		tk.User = a.User(tk.GetUser())
+	}
+	for _, tk := range req.GetContextualTuples().GetTupleKeys() {
+		a.TupleKey(tk)
+	}
+	a.Struct(req.GetContext())
+}
+
+// ListObjectsRequest anonymizes a ListObjects request in place.
+func (a *Anonymizer) ListObjectsRequest(req *openfgav1.ListObjectsRequest) {
+	req.User = a.User(req.GetUser())
+	for _, tk := range req.GetContextualTuples().GetTupleKeys() {
+		a.TupleKey(tk)
+	}
+	a.Struct(req.GetContext())
+}
+
+// ListObjectsResponse anonymizes a ListObjects response in place.
+func (a *Anonymizer) ListObjectsResponse(res *openfgav1.ListObjectsResponse) {
+	for i, object := range res.GetObjects() {
+		res.Objects[i] = a.Object(object)
+	}
+}
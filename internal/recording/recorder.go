@@ -0,0 +1,242 @@
+// Package recording samples Check and ListObjects requests, together with their outcome and
+// latency, to newline-delimited JSON, and replays them against another server to detect
+// regressions in results or latency.
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+// The methods that can be recorded.
+const (
+	MethodCheck       = "Check"
+	MethodListObjects = "ListObjects"
+)
+
+const (
+	// defaultBufferSize is the number of records that can wait to be written before new records
+	// are dropped.
+	defaultBufferSize = 1024
+)
+
+var (
+	recordedRequestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "recorded_requests_count",
+		Help:      "The total number of requests recorded by the request recorder.",
+	}, []string{"method"})
+
+	droppedRecordsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "recorded_requests_dropped_count",
+		Help:      "The total number of sampled requests that were not recorded because the recorder could not keep up.",
+	})
+)
+
+// Record is a recorded request, one per line of a recording.
+type Record struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Request    json.RawMessage `json:"request"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Code       string          `json:"code"`
+	DurationMs float64         `json:"duration_ms"`
+}
+
+// Duration returns the recorded latency of the request.
+func (r *Record) Duration() time.Duration {
+	return time.Duration(r.DurationMs * float64(time.Millisecond))
+}
+
+// RecorderOption defines an option that can be used to change the behavior of a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithSamplePercentage sets the percentage of the Check and ListObjects requests that are recorded.
+func WithSamplePercentage(percentage int) RecorderOption {
+	return func(r *Recorder) {
+		r.samplePercentage = percentage
+	}
+}
+
+// WithAnonymizer makes the Recorder anonymize the requests and responses before writing them.
+func WithAnonymizer(a *Anonymizer) RecorderOption {
+	return func(r *Recorder) {
+		r.anonymizer = a
+	}
+}
+
+// WithLogger sets the logger of the Recorder.
+func WithLogger(l logger.Logger) RecorderOption {
+	return func(r *Recorder) {
+		r.logger = l
+	}
+}
+
+// Recorder samples the Check and ListObjects requests it intercepts and writes them as Records.
+// Records are written in the background, so recording never slows down the requests; records that
+// cannot be buffered are dropped.
+type Recorder struct {
+	w                io.WriteCloser
+	samplePercentage int
+	anonymizer       *Anonymizer
+	logger           logger.Logger
+
+	records chan *Record
+	wg      sync.WaitGroup
+}
+
+// NewRecorder returns a Recorder that writes to w, which is closed by Close. By default, every
+// request is recorded and nothing is anonymized.
+func NewRecorder(w io.WriteCloser, opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		w:                w,
+		samplePercentage: 100,
+		logger:           logger.NewNoopLogger(),
+		records:          make(chan *Record, defaultBufferSize),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.wg.Add(1)
+	go r.write()
+
+	return r
+}
+
+// NewFileRecorder returns a Recorder that appends to the file at path, creating it if needed.
+func NewFileRecorder(path string, opts ...RecorderOption) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the recording file: %w", err)
+	}
+	return NewRecorder(f, opts...), nil
+}
+
+func (r *Recorder) write() {
+	defer r.wg.Done()
+	encoder := json.NewEncoder(r.w)
+	for record := range r.records {
+		if err := encoder.Encode(record); err != nil {
+			r.logger.Error("failed to write a recorded request", zap.Error(err))
+		}
+	}
+}
+
+// Close writes the buffered records and closes the underlying writer. The Recorder must not be
+// used after Close.
+func (r *Recorder) Close() error {
+	close(r.records)
+	r.wg.Wait()
+	return r.w.Close()
+}
+
+// UnaryServerInterceptor returns an interceptor that records a sample of the Check and
+// ListObjects requests. It should come after the authentication and validation interceptors, so
+// that only the requests that reach the server are recorded.
+func (r *Recorder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var method string
+		switch info.FullMethod {
+		case openfgav1.OpenFGAService_Check_FullMethodName:
+			method = MethodCheck
+		case openfgav1.OpenFGAService_ListObjects_FullMethodName:
+			method = MethodListObjects
+		default:
+			return handler(ctx, req)
+		}
+		if r.samplePercentage < 100 && rand.IntN(100) >= r.samplePercentage {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		duration := time.Since(start)
+
+		record, recordErr := r.newRecord(method, req, resp, err, start, duration)
+		if recordErr != nil {
+			r.logger.Error("failed to record a request", zap.String("method", method), zap.Error(recordErr))
+			return resp, err
+		}
+
+		select {
+		case r.records <- record:
+			recordedRequestsCounter.WithLabelValues(method).Inc()
+		default:
+			droppedRecordsCounter.Inc()
+		}
+
+		return resp, err
+	}
+}
+
+func (r *Recorder) newRecord(method string, req, resp interface{}, err error, start time.Time, duration time.Duration) (*Record, error) {
+	reqMsg, ok := req.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request type %T", req)
+	}
+	reqMsg = proto.Clone(reqMsg)
+
+	var respMsg proto.Message
+	if m, ok := resp.(proto.Message); ok && err == nil {
+		respMsg = proto.Clone(m)
+	}
+
+	switch req := reqMsg.(type) {
+	case *openfgav1.CheckRequest:
+		// the trace is only returned on demand, and it would not match on replay
+		req.Trace = false
+		if resp, ok := respMsg.(*openfgav1.CheckResponse); ok {
+			resp.Resolution = ""
+		}
+		if r.anonymizer != nil {
+			r.anonymizer.CheckRequest(req)
+		}
+	case *openfgav1.ListObjectsRequest:
+		if r.anonymizer != nil {
+			r.anonymizer.ListObjectsRequest(req)
+			if resp, ok := respMsg.(*openfgav1.ListObjectsResponse); ok {
+				r.anonymizer.ListObjectsResponse(resp)
+			}
+		}
+	}
+
+	record := &Record{
+		Time:       start.UTC(),
+		Method:     method,
+		Code:       status.Code(err).String(),
+		DurationMs: float64(duration.Microseconds()) / 1000,
+	}
+
+	var marshalErr error
+	if record.Request, marshalErr = protojson.Marshal(reqMsg); marshalErr != nil {
+		return nil, marshalErr
+	}
+	if respMsg != nil {
+		if record.Response, marshalErr = protojson.Marshal(respMsg); marshalErr != nil {
+			return nil, marshalErr
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,208 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func record(t *testing.T, r *Recorder, fullMethod string, req, resp interface{}, err error) {
+	t.Helper()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return resp, err
+	}
+	got, gotErr := r.UnaryServerInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+	require.Equal(t, resp, got)
+	require.Equal(t, err, gotErr)
+}
+
+func readRecordLines(t *testing.T, b *bytes.Buffer) []*Record {
+	t.Helper()
+	var records []*Record
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		r := &Record{}
+		require.NoError(t, json.Unmarshal([]byte(line), r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestAnonymizer(t *testing.T) {
+	a := NewAnonymizer([]byte("key"))
+
+	require.Equal(t, a.Object("document:1"), a.Object("document:1"))
+	require.NotEqual(t, a.Object("document:1"), a.Object("document:2"))
+	require.NotEqual(t, a.Object("document:1"), NewAnonymizer([]byte("other")).Object("document:1"))
+	require.True(t, strings.HasPrefix(a.Object("document:1"), "document:"))
+	require.Equal(t, "user:*", a.User("user:*"))
+
+	userObject, userRelation := tuple.SplitObjectRelation(a.User("group:eng#member"))
+	require.Equal(t, a.Object("group:eng"), userObject)
+	require.Equal(t, "member", userRelation)
+
+	req := &openfgav1.CheckRequest{
+		TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		}},
+		Context: testutils.MustNewStruct(t, map[string]interface{}{"ip": "10.0.0.1", "count": 3}),
+	}
+	a.CheckRequest(req)
+	require.Equal(t, a.Object("document:1"), req.GetTupleKey().GetObject())
+	require.Equal(t, "viewer", req.GetTupleKey().GetRelation())
+	require.Equal(t, a.User("user:anne"), req.GetTupleKey().GetUser())
+	require.Equal(t, a.User("group:eng#member"), req.GetContextualTuples().GetTupleKeys()[0].GetUser())
+	require.Equal(t, a.Pseudonym("10.0.0.1"), req.GetContext().GetFields()["ip"].GetStringValue())
+	require.InDelta(t, 3, req.GetContext().GetFields()["count"].GetNumberValue(), 0)
+}
+
+func TestRecorder(t *testing.T) {
+	checkReq := &openfgav1.CheckRequest{
+		StoreId:  "01H0H015178Y2V4CX10C2KGHF4",
+		TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		Trace:    true,
+	}
+	listObjectsReq := &openfgav1.ListObjectsRequest{
+		StoreId:  "01H0H015178Y2V4CX10C2KGHF4",
+		Type:     "document",
+		Relation: "viewer",
+		User:     "user:anne",
+	}
+
+	t.Run("records_check_and_list_objects", func(t *testing.T) {
+		var b bytes.Buffer
+		r := NewRecorder(nopCloser{&b})
+		record(t, r, openfgav1.OpenFGAService_Check_FullMethodName, checkReq, &openfgav1.CheckResponse{Allowed: true, Resolution: "trace"}, nil)
+		record(t, r, openfgav1.OpenFGAService_ListObjects_FullMethodName, listObjectsReq, &openfgav1.ListObjectsResponse{Objects: []string{"document:1"}}, nil)
+		record(t, r, openfgav1.OpenFGAService_Read_FullMethodName, &openfgav1.ReadRequest{}, &openfgav1.ReadResponse{}, nil)
+		record(t, r, openfgav1.OpenFGAService_Check_FullMethodName, checkReq, nil, status.Error(codes.InvalidArgument, "invalid"))
+		require.NoError(t, r.Close())
+
+		records := readRecordLines(t, &b)
+		require.Len(t, records, 3)
+
+		require.Equal(t, MethodCheck, records[0].Method)
+		require.Equal(t, codes.OK.String(), records[0].Code)
+		req := &openfgav1.CheckRequest{}
+		require.NoError(t, protojson.Unmarshal(records[0].Request, req))
+		require.Equal(t, "document:1", req.GetTupleKey().GetObject())
+		require.False(t, req.GetTrace())
+		resp := &openfgav1.CheckResponse{}
+		require.NoError(t, protojson.Unmarshal(records[0].Response, resp))
+		require.True(t, resp.GetAllowed())
+		require.Empty(t, resp.GetResolution())
+
+		require.Equal(t, MethodListObjects, records[1].Method)
+
+		require.Equal(t, MethodCheck, records[2].Method)
+		require.Equal(t, codes.InvalidArgument.String(), records[2].Code)
+		require.Empty(t, records[2].Response)
+	})
+
+	t.Run("anonymizes", func(t *testing.T) {
+		var b bytes.Buffer
+		a := NewAnonymizer([]byte("key"))
+		r := NewRecorder(nopCloser{&b}, WithAnonymizer(a))
+		record(t, r, openfgav1.OpenFGAService_ListObjects_FullMethodName, listObjectsReq, &openfgav1.ListObjectsResponse{Objects: []string{"document:1"}}, nil)
+		require.NoError(t, r.Close())
+
+		records := readRecordLines(t, &b)
+		require.Len(t, records, 1)
+		require.NotContains(t, string(records[0].Request), "anne")
+		require.NotContains(t, string(records[0].Response), "document:1")
+		require.Equal(t, "user:anne", listObjectsReq.GetUser(), "the request served is left untouched")
+
+		resp := &openfgav1.ListObjectsResponse{}
+		require.NoError(t, protojson.Unmarshal(records[0].Response, resp))
+		require.Equal(t, []string{a.Object("document:1")}, resp.GetObjects())
+	})
+
+	t.Run("samples", func(t *testing.T) {
+		var b bytes.Buffer
+		r := NewRecorder(nopCloser{&b}, WithSamplePercentage(0))
+		record(t, r, openfgav1.OpenFGAService_Check_FullMethodName, checkReq, &openfgav1.CheckResponse{}, nil)
+		require.NoError(t, r.Close())
+		require.Empty(t, b.String())
+	})
+}
+
+type fakeClient struct {
+	openfgav1.OpenFGAServiceClient
+	allowed map[string]bool
+	objects []string
+}
+
+func (f *fakeClient) Check(ctx context.Context, in *openfgav1.CheckRequest, opts ...grpc.CallOption) (*openfgav1.CheckResponse, error) {
+	if in.GetAuthorizationModelId() == "missing" {
+		return nil, status.Error(codes.NotFound, "model not found")
+	}
+	return &openfgav1.CheckResponse{Allowed: f.allowed[in.GetTupleKey().GetObject()]}, nil
+}
+
+func (f *fakeClient) ListObjects(ctx context.Context, in *openfgav1.ListObjectsRequest, opts ...grpc.CallOption) (*openfgav1.ListObjectsResponse, error) {
+	return &openfgav1.ListObjectsResponse{Objects: f.objects}, nil
+}
+
+func TestReplay(t *testing.T) {
+	var b bytes.Buffer
+	r := NewRecorder(nopCloser{&b})
+	for _, object := range []string{"document:1", "document:2", "document:3"} {
+		req := &openfgav1.CheckRequest{TupleKey: tuple.NewCheckRequestTupleKey(object, "viewer", "user:anne")}
+		record(t, r, openfgav1.OpenFGAService_Check_FullMethodName, req, &openfgav1.CheckResponse{Allowed: object != "document:3"}, nil)
+	}
+	record(t, r, openfgav1.OpenFGAService_ListObjects_FullMethodName, &openfgav1.ListObjectsRequest{Type: "document", Relation: "viewer", User: "user:anne"},
+		&openfgav1.ListObjectsResponse{Objects: []string{"document:1", "document:2"}}, nil)
+	require.NoError(t, r.Close())
+	recording := b.String()
+
+	client := &fakeClient{
+		allowed: map[string]bool{"document:1": true, "document:3": true},
+		objects: []string{"document:2", "document:1"},
+	}
+
+	t.Run("reports_mismatches", func(t *testing.T) {
+		report, err := Replay(context.Background(), client, strings.NewReader(recording), WithConcurrency(2))
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Requests)
+		require.Len(t, report.Mismatches, 2)
+		require.Equal(t, 2, report.Mismatches[0].Line)
+		require.Equal(t, "allowed=true", report.Mismatches[0].Recorded)
+		require.Equal(t, "allowed=false", report.Mismatches[0].Replayed)
+		require.Equal(t, 3, report.Mismatches[1].Line)
+	})
+
+	t.Run("overrides_model", func(t *testing.T) {
+		report, err := Replay(context.Background(), client, strings.NewReader(recording), WithAuthorizationModelID("missing"))
+		require.NoError(t, err)
+		require.Len(t, report.Mismatches, 3)
+		require.Equal(t, codes.NotFound.String(), report.Mismatches[0].Replayed)
+	})
+
+	t.Run("invalid_recording", func(t *testing.T) {
+		_, err := Replay(context.Background(), client, io.MultiReader(strings.NewReader(recording), strings.NewReader("{")))
+		require.ErrorContains(t, err, "line 5")
+	})
+}
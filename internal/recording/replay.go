@@ -0,0 +1,287 @@
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// maxRecordSize is the maximum size of a line of a recording.
+const maxRecordSize = 16 * 1024 * 1024
+
+// ReplayOption defines an option that can be used to change the behavior of Replay.
+type ReplayOption func(*replayer)
+
+// WithStoreID replays every request against the given store instead of the recorded one.
+func WithStoreID(storeID string) ReplayOption {
+	return func(r *replayer) {
+		r.storeID = storeID
+	}
+}
+
+// WithAuthorizationModelID replays every request against the given authorization model instead of
+// the recorded one, so that the results of two model versions can be compared.
+func WithAuthorizationModelID(modelID string) ReplayOption {
+	return func(r *replayer) {
+		r.modelID = modelID
+	}
+}
+
+// WithConcurrency sets the number of requests replayed concurrently.
+func WithConcurrency(concurrency int) ReplayOption {
+	return func(r *replayer) {
+		r.concurrency = concurrency
+	}
+}
+
+// Mismatch is a replayed request whose outcome differs from the recorded one.
+type Mismatch struct {
+	Line     int    `json:"line"`
+	Method   string `json:"method"`
+	Request  string `json:"request"`
+	Recorded string `json:"recorded"`
+	Replayed string `json:"replayed"`
+}
+
+// LatencySummary summarizes the latencies of a set of requests.
+type LatencySummary struct {
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+// ReplayReport is the result of a replay.
+type ReplayReport struct {
+	Requests        int            `json:"requests"`
+	Mismatches      []Mismatch     `json:"mismatches"`
+	RecordedLatency LatencySummary `json:"recorded_latency"`
+	ReplayedLatency LatencySummary `json:"replayed_latency"`
+}
+
+type replayer struct {
+	client      openfgav1.OpenFGAServiceClient
+	storeID     string
+	modelID     string
+	concurrency int
+}
+
+type replayItem struct {
+	line   int
+	record *Record
+}
+
+type replayResult struct {
+	recorded time.Duration
+	replayed time.Duration
+	mismatch *Mismatch
+}
+
+// Replay re-executes the requests recorded in r against client and reports the requests whose
+// outcome changed, along with the recorded and replayed latencies. An outcome is the gRPC code of
+// the response and, for successful requests, the Check result or the set of ListObjects objects.
+// Since ListObjects may return a partial result when it times out, a replay should use the same
+// ListObjects deadline and max results as the recorded server.
+func Replay(ctx context.Context, client openfgav1.OpenFGAServiceClient, r io.Reader, opts ...ReplayOption) (*ReplayReport, error) {
+	rp := &replayer{client: client, concurrency: 1}
+	for _, opt := range opts {
+		opt(rp)
+	}
+	if rp.concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be greater than zero, got %d", rp.concurrency)
+	}
+
+	items := make(chan replayItem)
+	results := make(chan replayResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < rp.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				results <- rp.replay(ctx, item)
+			}
+		}()
+	}
+
+	report := &ReplayReport{Mismatches: []Mismatch{}}
+	var recorded, replayed []time.Duration
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for result := range results {
+			report.Requests++
+			recorded = append(recorded, result.recorded)
+			replayed = append(replayed, result.replayed)
+			if result.mismatch != nil {
+				report.Mismatches = append(report.Mismatches, *result.mismatch)
+			}
+		}
+	}()
+
+	readErr := readRecords(ctx, r, items)
+	close(items)
+	wg.Wait()
+	close(results)
+	<-collected
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		return report.Mismatches[i].Line < report.Mismatches[j].Line
+	})
+	report.RecordedLatency = summarize(recorded)
+	report.ReplayedLatency = summarize(replayed)
+	return report, nil
+}
+
+func readRecords(ctx context.Context, r io.Reader, items chan<- replayItem) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.Method != MethodCheck && record.Method != MethodListObjects {
+			return fmt.Errorf("line %d: unsupported method '%s'", line, record.Method)
+		}
+
+		select {
+		case items <- replayItem{line: line, record: record}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return scanner.Err()
+}
+
+func (rp *replayer) replay(ctx context.Context, item replayItem) replayResult {
+	record := item.record
+	result := replayResult{recorded: record.Duration()}
+
+	var recordedOutcome, replayedOutcome string
+	var err error
+	switch record.Method {
+	case MethodCheck:
+		recordedOutcome, replayedOutcome, result.replayed, err = rp.replayCheck(ctx, record)
+	case MethodListObjects:
+		recordedOutcome, replayedOutcome, result.replayed, err = rp.replayListObjects(ctx, record)
+	}
+	if err != nil {
+		replayedOutcome = fmt.Sprintf("invalid record: %v", err)
+	}
+
+	if recordedOutcome != replayedOutcome {
+		result.mismatch = &Mismatch{
+			Line:     item.line,
+			Method:   record.Method,
+			Request:  string(record.Request),
+			Recorded: recordedOutcome,
+			Replayed: replayedOutcome,
+		}
+	}
+	return result
+}
+
+func (rp *replayer) replayCheck(ctx context.Context, record *Record) (string, string, time.Duration, error) {
+	req := &openfgav1.CheckRequest{}
+	if err := protojson.Unmarshal(record.Request, req); err != nil {
+		return "", "", 0, err
+	}
+	rp.override(&req.StoreId, &req.AuthorizationModelId)
+
+	recorded := &openfgav1.CheckResponse{}
+	if len(record.Response) > 0 {
+		if err := protojson.Unmarshal(record.Response, recorded); err != nil {
+			return "", "", 0, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := rp.client.Check(ctx, req)
+	duration := time.Since(start)
+
+	return checkOutcome(record.Code, recorded), checkOutcome(status.Code(err).String(), resp), duration, nil
+}
+
+func (rp *replayer) replayListObjects(ctx context.Context, record *Record) (string, string, time.Duration, error) {
+	req := &openfgav1.ListObjectsRequest{}
+	if err := protojson.Unmarshal(record.Request, req); err != nil {
+		return "", "", 0, err
+	}
+	rp.override(&req.StoreId, &req.AuthorizationModelId)
+
+	recorded := &openfgav1.ListObjectsResponse{}
+	if len(record.Response) > 0 {
+		if err := protojson.Unmarshal(record.Response, recorded); err != nil {
+			return "", "", 0, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := rp.client.ListObjects(ctx, req)
+	duration := time.Since(start)
+
+	return listObjectsOutcome(record.Code, recorded), listObjectsOutcome(status.Code(err).String(), resp), duration, nil
+}
+
+func (rp *replayer) override(storeID, modelID *string) {
+	if rp.storeID != "" {
+		*storeID = rp.storeID
+	}
+	if rp.modelID != "" {
+		*modelID = rp.modelID
+	}
+}
+
+func checkOutcome(code string, resp *openfgav1.CheckResponse) string {
+	if code != codes.OK.String() {
+		return code
+	}
+	return fmt.Sprintf("allowed=%t", resp.GetAllowed())
+}
+
+func listObjectsOutcome(code string, resp *openfgav1.ListObjectsResponse) string {
+	if code != codes.OK.String() {
+		return code
+	}
+	objects := slices.Clone(resp.GetObjects())
+	slices.Sort(objects)
+	return fmt.Sprintf("objects=%v", objects)
+}
+
+func summarize(durations []time.Duration) LatencySummary {
+	if len(durations) == 0 {
+		return LatencySummary{}
+	}
+	slices.Sort(durations)
+	percentile := func(p float64) float64 {
+		i := int(p * float64(len(durations)-1))
+		return float64(durations[i].Microseconds()) / 1000
+	}
+	return LatencySummary{
+		P50Ms: percentile(0.50),
+		P95Ms: percentile(0.95),
+		P99Ms: percentile(0.99),
+		MaxMs: percentile(1),
+	}
+}
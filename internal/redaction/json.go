@@ -0,0 +1,59 @@
+package redaction
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// JSON redacts the IDs in a JSON encoded request or response of the API, i.e. the 'user',
+// 'object', 'users' and 'objects' strings, and the 'id' of the '{"type": ..., "id": ...}' objects
+// of ListUsers and AuthZEN. The fields are walked at any depth, so that the tuple keys and the
+// contextual tuples are redacted too. The JSON that can't be decoded is returned as is.
+func (r *Redactor) JSON(data []byte) []byte {
+	if !r.Enabled() || len(data) == 0 {
+		return data
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+
+	redacted, err := json.Marshal(r.jsonValue("", value))
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+func (r *Redactor) jsonValue(key string, value any) any {
+	switch v := value.(type) {
+	case string:
+		switch key {
+		case "user", "object", "users", "objects":
+			return r.Object(v)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = r.jsonValue(key, item)
+		}
+		return v
+	case map[string]any:
+		_, hasType := v["type"].(string)
+		if id, ok := v["id"].(string); ok && hasType {
+			v["id"] = r.ID(id)
+		}
+		for k, item := range v {
+			if k == "id" {
+				continue
+			}
+			v[k] = r.jsonValue(k, item)
+		}
+		return v
+	default:
+		return v
+	}
+}
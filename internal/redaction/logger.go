@@ -0,0 +1,70 @@
+package redaction
+
+import (
+	"encoding/json"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type core struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+// NewCore returns a zapcore.Core that redacts the IDs of the fields logged to inner, i.e. the
+// fields that hold IDs by key (e.g. 'user' or 'tuple_key'), the JSON encoded requests and
+// responses, and the protobuf messages. The messages of the entries and the errors are not
+// redacted.
+func NewCore(inner zapcore.Core, redactor *Redactor) zapcore.Core {
+	if !redactor.Enabled() {
+		return inner
+	}
+	return &core{Core: inner, redactor: redactor}
+}
+
+// With see [zapcore.Core].With.
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(c.redactor.fields(fields)), redactor: c.redactor}
+}
+
+// Check see [zapcore.Core].Check.
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write see [zapcore.Core].Write.
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redactor.fields(fields))
+}
+
+func (r *Redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.field(field)
+	}
+	return redacted
+}
+
+func (r *Redactor) field(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = r.Field(field.Key, field.String)
+	case zapcore.ReflectType, zapcore.StringerType:
+		switch v := field.Interface.(type) {
+		case json.RawMessage:
+			return zap.Any(field.Key, json.RawMessage(r.JSON(v)))
+		case proto.Message:
+			data, err := protojson.Marshal(v)
+			if err == nil {
+				return zap.Any(field.Key, json.RawMessage(r.JSON(data)))
+			}
+		}
+	}
+	return field
+}
@@ -0,0 +1,209 @@
+// Package redaction contains code that redacts or hashes the IDs of the users and the objects in
+// the logs and the traces, e.g. for the deployments that must not retain personal data.
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// Mode is how the IDs are redacted.
+type Mode string
+
+const (
+	// ModeNone keeps the IDs as is.
+	ModeNone Mode = "none"
+
+	// ModeRedact replaces the IDs with RedactedID.
+	ModeRedact Mode = "redact"
+
+	// ModeHash replaces the IDs with their truncated HMAC, so that the same ID can be correlated
+	// across the logs and the traces of a key rotation period.
+	ModeHash Mode = "hash"
+)
+
+// RedactedID replaces the IDs in ModeRedact.
+const RedactedID = "redacted"
+
+// hashedIDPrefix marks the hashed IDs, so that they are not mistaken for the IDs themselves.
+const hashedIDPrefix = "h."
+
+// hashedIDLength is the number of bytes of the HMAC kept in the hashed IDs.
+const hashedIDLength = 8
+
+var (
+	ErrInvalidMode = errors.New("invalid redaction mode")
+	ErrMissingKey  = errors.New("the key is required to hash the IDs")
+)
+
+// Modes returns the supported modes.
+func Modes() []Mode {
+	return []Mode{ModeNone, ModeRedact, ModeHash}
+}
+
+// ParseMode returns the Mode named s.
+func ParseMode(s string) (Mode, error) {
+	for _, mode := range Modes() {
+		if s == string(mode) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("%w '%s', must be one of %v", ErrInvalidMode, s, Modes())
+}
+
+// Redactor redacts or hashes the IDs of the users and the objects. The types, the relations and
+// the wildcards are kept, e.g. 'group:eng#member' is hashed to 'group:h.1f0c...#member', so that
+// the logs and the traces remain useful to troubleshoot the models. A nil Redactor keeps the IDs
+// as is.
+type Redactor struct {
+	mode           Mode
+	key            []byte
+	rotationPeriod time.Duration
+	now            func() time.Time
+
+	mu           sync.Mutex
+	period       int64
+	periodMACKey []byte
+}
+
+// NewRedactor returns a Redactor in the given mode. In ModeHash, the IDs are hashed with an HMAC
+// key derived from key and the current rotation period, so that the hashes of an ID can only be
+// correlated within a period. A rotationPeriod of zero disables the rotation.
+func NewRedactor(mode Mode, key []byte, rotationPeriod time.Duration) (*Redactor, error) {
+	if _, err := ParseMode(string(mode)); err != nil {
+		return nil, err
+	}
+	if mode == ModeHash && len(key) == 0 {
+		return nil, ErrMissingKey
+	}
+	if rotationPeriod < 0 {
+		return nil, fmt.Errorf("the key rotation period must be non-negative, got %s", rotationPeriod)
+	}
+	return &Redactor{
+		mode:           mode,
+		key:            key,
+		rotationPeriod: rotationPeriod,
+		now:            time.Now,
+		period:         -1,
+	}, nil
+}
+
+// Enabled returns whether the Redactor changes the IDs.
+func (r *Redactor) Enabled() bool {
+	return r != nil && r.mode != ModeNone
+}
+
+// macKey returns the HMAC key of the current rotation period.
+func (r *Redactor) macKey() []byte {
+	var period int64
+	if r.rotationPeriod > 0 {
+		period = r.now().UnixNano() / int64(r.rotationPeriod)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if period != r.period {
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte("openfga redaction"))
+		mac.Write(binary.BigEndian.AppendUint64(nil, uint64(period)))
+		r.period, r.periodMACKey = period, mac.Sum(nil)
+	}
+	return r.periodMACKey
+}
+
+// ID redacts a bare ID, e.g. the 'anne' of 'user:anne'. The wildcard is kept.
+func (r *Redactor) ID(id string) string {
+	if !r.Enabled() || id == "" || id == tuple.Wildcard {
+		return id
+	}
+	if r.mode == ModeRedact {
+		return RedactedID
+	}
+	mac := hmac.New(sha256.New, r.macKey())
+	mac.Write([]byte(id))
+	return hashedIDPrefix + hex.EncodeToString(mac.Sum(nil)[:hashedIDLength])
+}
+
+// Object redacts the ID of an object or of a user, i.e. 'type:id' or 'type:id#relation'. The
+// strings without a type, e.g. 'type#relation', are kept, since they hold no ID.
+func (r *Redactor) Object(object string) string {
+	if !r.Enabled() {
+		return object
+	}
+	objectType, rest, ok := strings.Cut(object, ":")
+	if !ok {
+		return object
+	}
+	id, relation, hasRelation := strings.Cut(rest, "#")
+	redacted := objectType + ":" + r.ID(id)
+	if hasRelation {
+		redacted += "#" + relation
+	}
+	return redacted
+}
+
+// TupleKey redacts the IDs of a tuple key formatted by [tuple.TupleKeyToString] or
+// [tuple.TupleKeyWithConditionToString].
+func (r *Redactor) TupleKey(tupleKey string) string {
+	if !r.Enabled() {
+		return tupleKey
+	}
+	if strings.Contains(tupleKey, `:"`) {
+		// the tuple key is formatted as text by its String method
+		return r.protoText(tupleKey)
+	}
+	// the IDs can't contain '#' or spaces, and the relations can't contain '@'
+	object, rest, ok := strings.Cut(tupleKey, "#")
+	if !ok {
+		return r.Object(tupleKey)
+	}
+	relation, rest, ok := strings.Cut(rest, "@")
+	if !ok {
+		return r.Object(object) + "#" + rest
+	}
+	user, condition, hasCondition := strings.Cut(rest, " ")
+	redacted := r.Object(object) + "#" + relation + "@" + r.Object(user)
+	if hasCondition {
+		redacted += " " + condition
+	}
+	return redacted
+}
+
+// protoTextFieldRegex matches the 'user' and 'object' fields of a message formatted as text.
+var protoTextFieldRegex = regexp.MustCompile(`\b(user|object):(\s*)"([^"]*)"`)
+
+func (r *Redactor) protoText(text string) string {
+	return protoTextFieldRegex.ReplaceAllStringFunc(text, func(field string) string {
+		match := protoTextFieldRegex.FindStringSubmatch(field)
+		return match[1] + ":" + match[2] + `"` + r.Object(match[3]) + `"`
+	})
+}
+
+// fieldRedactors are the redactions of the log fields and the span attributes that hold IDs, by key.
+var fieldRedactors = map[string]func(*Redactor, string) string{
+	"user":        (*Redactor).Object,
+	"object":      (*Redactor).Object,
+	"source.user": (*Redactor).Object,
+	"userset":     (*Redactor).Object,
+	"subject_id":  (*Redactor).ID,
+	"tuple_key":   (*Redactor).TupleKey,
+	"request":     (*Redactor).protoText,
+}
+
+// Field redacts the value of the log field or span attribute with the given key, if it holds IDs.
+func (r *Redactor) Field(key, value string) string {
+	if redact, ok := fieldRedactors[key]; ok && r.Enabled() {
+		return redact(r, value)
+	}
+	return value
+}
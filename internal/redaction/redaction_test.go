@@ -0,0 +1,149 @@
+package redaction
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestNewRedactor(t *testing.T) {
+	_, err := NewRedactor("mask", nil, 0)
+	require.ErrorIs(t, err, ErrInvalidMode)
+
+	_, err = NewRedactor(ModeHash, nil, 0)
+	require.ErrorIs(t, err, ErrMissingKey)
+
+	_, err = NewRedactor(ModeHash, []byte("secret"), -time.Hour)
+	require.Error(t, err)
+
+	redactor, err := NewRedactor(ModeNone, nil, 0)
+	require.NoError(t, err)
+	require.False(t, redactor.Enabled())
+	require.Equal(t, "document:1#viewer@user:anne", redactor.TupleKey("document:1#viewer@user:anne"))
+
+	var nilRedactor *Redactor
+	require.False(t, nilRedactor.Enabled())
+	require.Equal(t, "user:anne", nilRedactor.Object("user:anne"))
+}
+
+func TestRedactMode(t *testing.T) {
+	redactor, err := NewRedactor(ModeRedact, nil, 0)
+	require.NoError(t, err)
+
+	require.Equal(t, "user:redacted", redactor.Object("user:anne"))
+	require.Equal(t, "group:redacted#member", redactor.Object("group:eng#member"))
+	require.Equal(t, "user:*", redactor.Object("user:*"))
+	require.Equal(t, "group#member", redactor.Object("group#member"))
+	require.Equal(t, "document:redacted#viewer@user:redacted (condition in_region)",
+		redactor.TupleKey(tuple.TupleKeyWithConditionToString(tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "in_region", nil))))
+	require.Equal(t, `object:"document:redacted" relation:"viewer" user:"user:redacted"`,
+		redactor.TupleKey(`object:"document:1" relation:"viewer" user:"user:anne"`))
+}
+
+func TestHashMode(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	redactor, err := NewRedactor(ModeHash, []byte("secret"), 24*time.Hour)
+	require.NoError(t, err)
+	redactor.now = func() time.Time { return now }
+
+	hashed := redactor.Object("user:anne")
+	require.True(t, strings.HasPrefix(hashed, "user:h."))
+	require.NotContains(t, hashed, "anne")
+	require.Equal(t, hashed, redactor.Object("user:anne"), "the hashes must be correlateable")
+	require.NotEqual(t, hashed, redactor.Object("user:bob"))
+	require.Equal(t, strings.TrimPrefix(hashed, "user:")+"#member", strings.TrimPrefix(redactor.Object("group:anne#member"), "group:"))
+
+	now = now.Add(20 * time.Hour)
+	require.NotEqual(t, hashed, redactor.Object("user:anne"), "the key must rotate")
+
+	other, err := NewRedactor(ModeHash, []byte("other secret"), 24*time.Hour)
+	require.NoError(t, err)
+	other.now = func() time.Time { return now }
+	require.NotEqual(t, redactor.Object("user:anne"), other.Object("user:anne"))
+}
+
+func TestJSON(t *testing.T) {
+	redactor, err := NewRedactor(ModeRedact, nil, 0)
+	require.NoError(t, err)
+
+	redacted := redactor.JSON([]byte(`{
+		"store_id": "01H0",
+		"tuple_key": {"object": "document:1", "relation": "viewer", "user": "user:anne"},
+		"contextual_tuples": {"tuple_keys": [{"object": "group:eng", "relation": "member", "user": "user:*"}]},
+		"objects": ["document:1", "document:2"],
+		"users": [{"object": {"type": "user", "id": "anne"}}, {"userset": {"type": "group", "id": "eng", "relation": "member"}}],
+		"limit": 10
+	}`))
+	require.JSONEq(t, `{
+		"store_id": "01H0",
+		"tuple_key": {"object": "document:redacted", "relation": "viewer", "user": "user:redacted"},
+		"contextual_tuples": {"tuple_keys": [{"object": "group:redacted", "relation": "member", "user": "user:*"}]},
+		"objects": ["document:redacted", "document:redacted"],
+		"users": [{"object": {"type": "user", "id": "redacted"}}, {"userset": {"type": "group", "id": "redacted", "relation": "member"}}],
+		"limit": 10
+	}`, string(redacted))
+
+	require.Equal(t, "not json", string(redactor.JSON([]byte("not json"))))
+}
+
+func TestNewCore(t *testing.T) {
+	redactor, err := NewRedactor(ModeRedact, nil, 0)
+	require.NoError(t, err)
+
+	observerCore, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(observerCore, redactor)).With(zap.String("object", "document:1"))
+	logger.Debug("ignored", zap.String("user", "user:anne"))
+	logger.Info("logged",
+		zap.String("user", "user:anne"),
+		zap.String("store_id", "01H0"),
+		zap.Any("raw_request", json.RawMessage(`{"user":"user:anne"}`)),
+		zap.Any("request", tuple.NewTupleKey("document:1", "viewer", "user:anne")))
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "document:redacted", fields["object"])
+	require.Equal(t, "user:redacted", fields["user"])
+	require.Equal(t, "01H0", fields["store_id"])
+
+	raw := map[string]string{}
+	for _, field := range logs.All()[0].Context {
+		if message, ok := field.Interface.(json.RawMessage); ok {
+			raw[field.Key] = string(message)
+		}
+	}
+	require.JSONEq(t, `{"user":"user:redacted"}`, raw["raw_request"])
+	require.JSONEq(t, `{"object":"document:redacted","relation":"viewer","user":"user:redacted"}`, raw["request"])
+}
+
+func TestNewSpanExporter(t *testing.T) {
+	redactor, err := NewRedactor(ModeRedact, nil, 0)
+	require.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewSpanExporter(exporter, redactor)))
+	_, span := tp.Tracer("test").Start(context.Background(), "check")
+	span.SetAttributes(
+		attribute.String("tuple_key", "document:1#viewer@user:anne"),
+		attribute.String("store_id", "01H0"),
+		attribute.Int("depth", 3))
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("tuple_key", "document:redacted#viewer@user:redacted"),
+		attribute.String("store_id", "01H0"),
+		attribute.Int("depth", 3),
+	}, spans[0].Attributes)
+}
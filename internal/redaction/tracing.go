@@ -0,0 +1,53 @@
+package redaction
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type spanExporter struct {
+	sdktrace.SpanExporter
+	redactor *Redactor
+}
+
+// NewSpanExporter returns a sdktrace.SpanExporter that redacts the IDs of the attributes of the
+// spans exported to inner, e.g. 'user' or 'tuple_key'. The events of the spans, e.g. the recorded
+// errors, are not redacted.
+func NewSpanExporter(inner sdktrace.SpanExporter, redactor *Redactor) sdktrace.SpanExporter {
+	if !redactor.Enabled() {
+		return inner
+	}
+	return &spanExporter{SpanExporter: inner, redactor: redactor}
+}
+
+// ExportSpans see [sdktrace.SpanExporter].ExportSpans.
+func (e *spanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		redacted[i] = &redactedSpan{ReadOnlySpan: span, attributes: e.redactor.attributes(span.Attributes())}
+	}
+	return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	attributes []attribute.KeyValue
+}
+
+// Attributes see [sdktrace.ReadOnlySpan].Attributes.
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+func (r *Redactor) attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	redacted := make([]attribute.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		if attr.Value.Type() == attribute.STRING {
+			attr = attribute.String(string(attr.Key), r.Field(string(attr.Key), attr.Value.AsString()))
+		}
+		redacted[i] = attr
+	}
+	return redacted
+}
@@ -0,0 +1,160 @@
+// Package secrets resolves the references to the secrets of the secret managers in the
+// configuration, e.g. a datastore password of vault://secret/openfga#password, and reloads them
+// when they are rotated.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// The schemes of the secret references.
+const (
+	// FileScheme references a file, e.g. file:///var/run/secrets/openfga/password for a
+	// Kubernetes secret mounted as a volume, which Kubernetes updates when the secret changes.
+	FileScheme = "file"
+
+	// VaultScheme references a key of a secret of a HashiCorp Vault KV version 2 secrets engine,
+	// e.g. vault://secret/openfga#password for the password key of the openfga secret of the
+	// engine mounted at secret. Vault is configured with the VAULT_ADDR, VAULT_TOKEN and
+	// VAULT_NAMESPACE environment variables.
+	VaultScheme = "vault"
+
+	// AWSSecretsManagerScheme references a secret of AWS Secrets Manager by its name or ARN,
+	// e.g. awssm://openfga/datastore#password for the password key of a JSON secret, or
+	// awssm://openfga/preshared-key for the whole secret. The secrets are fetched with the
+	// credentials of the default AWS credential chain, e.g. the web identity of an EKS service
+	// account, the role of an ECS task or the instance profile of an EC2 instance.
+	AWSSecretsManagerScheme = "awssm"
+)
+
+// Reference is a reference to a secret, of the form scheme://path#key.
+type Reference struct {
+	Scheme string
+	Path   string
+	// Key is the key of the value in the secret, if any.
+	Key string
+}
+
+func (r Reference) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// ParseReference parses a value of the configuration as a secret reference, and returns false if
+// it is a plain value, i.e. it doesn't start with the scheme of a secret reference.
+func ParseReference(value string) (Reference, bool) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok {
+		return Reference{}, false
+	}
+	switch scheme {
+	case FileScheme, VaultScheme, AWSSecretsManagerScheme:
+	default:
+		return Reference{}, false
+	}
+
+	path, key, _ := strings.Cut(rest, "#")
+	return Reference{Scheme: scheme, Path: path, Key: key}, true
+}
+
+// IsReference reports whether a value of the configuration is a secret reference.
+func IsReference(value string) bool {
+	_, ok := ParseReference(value)
+	return ok
+}
+
+// Source fetches the secrets of the references of a scheme.
+type Source interface {
+	Fetch(ctx context.Context, ref Reference) (string, error)
+}
+
+// Resolver resolves the secret references with the Source of their scheme.
+type Resolver struct {
+	sources map[string]Source
+}
+
+// ResolverOption defines a function type used for configuring a Resolver.
+type ResolverOption func(*Resolver)
+
+// WithSource returns a ResolverOption that sets the Source of the references of a scheme.
+func WithSource(scheme string, source Source) ResolverOption {
+	return func(r *Resolver) {
+		r.sources[scheme] = source
+	}
+}
+
+// NewResolver returns a Resolver of the files, Vault and AWS Secrets Manager references,
+// configured by the environment.
+func NewResolver(opts ...ResolverOption) *Resolver {
+	r := &Resolver{
+		sources: map[string]Source{
+			FileScheme:              fileSource{},
+			VaultScheme:             newVaultSourceFromEnv(),
+			AWSSecretsManagerScheme: newAWSSecretsManagerSource(),
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve returns the secret of a value of the configuration if it is a secret reference, or the
+// value itself if it is a plain value.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	ref, ok := ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	source, ok := r.sources[ref.Scheme]
+	if !ok {
+		return "", fmt.Errorf("no source of the %s secrets", ref.Scheme)
+	}
+	secret, err := source.Fetch(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the secret %s: %w", ref, err)
+	}
+	return secret, nil
+}
+
+// fileSource reads the secrets from files, without their trailing newline.
+type fileSource struct{}
+
+func (fileSource) Fetch(_ context.Context, ref Reference) (string, error) {
+	content, err := os.ReadFile(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return secretKey(strings.TrimRight(string(content), "\r\n"), ref.Key)
+}
+
+// secretKey returns the value of the key of a JSON secret, or the whole secret without key.
+func secretKey(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("the secret with key %q is not a JSON object: %w", key, err)
+	}
+	return stringKey(values, key)
+}
+
+func stringKey(values map[string]any, key string) (string, error) {
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("the secret has no key %q", key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("the key %q of the secret is not a string", key)
+	}
+	return s, nil
+}
@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// vaultSource fetches the secrets of the KV version 2 secrets engines of HashiCorp Vault.
+type vaultSource struct {
+	client    *http.Client
+	addr      string
+	token     string
+	namespace string
+}
+
+func newVaultSourceFromEnv() *vaultSource {
+	return &vaultSource{
+		client:    http.DefaultClient,
+		addr:      strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+}
+
+func (s *vaultSource) Fetch(ctx context.Context, ref Reference) (string, error) {
+	if s.addr == "" {
+		return "", errors.New("the Vault address is not set, set VAULT_ADDR")
+	}
+	if ref.Key == "" {
+		return "", errors.New("the key of the Vault secret is not set")
+	}
+	mount, path, ok := strings.Cut(ref.Path, "/")
+	if !ok || path == "" {
+		return "", fmt.Errorf("the Vault secret %q is not of the form mount/path", ref.Path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.addr+"/v1/"+mount+"/data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(s.client, req, &secret); err != nil {
+		return "", err
+	}
+	return stringKey(secret.Data.Data, ref.Key)
+}
+
+// awsSecretsManagerSource fetches the secrets of AWS Secrets Manager with its GetSecretValue API,
+// with the credentials of the default AWS credential chain.
+type awsSecretsManagerSource struct {
+	mu     sync.Mutex
+	config *aws.Config // GUARDED_BY(mu)
+}
+
+func newAWSSecretsManagerSource() *awsSecretsManagerSource {
+	return &awsSecretsManagerSource{}
+}
+
+// awsConfig loads the AWS configuration on the first fetch, so that the servers without AWS
+// secret references don't load it.
+func (s *awsSecretsManagerSource) awsConfig(ctx context.Context) (aws.Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config == nil {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("failed to load the AWS configuration: %w", err)
+		}
+		s.config = &cfg
+	}
+	return *s.config, nil
+}
+
+func (s *awsSecretsManagerSource) Fetch(ctx context.Context, ref Reference) (string, error) {
+	cfg, err := s.awsConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	// the ARNs of the secrets include their region, e.g. arn:aws:secretsmanager:us-east-1:...
+	region := cfg.Region
+	if arn := strings.Split(ref.Path, ":"); len(arn) > 3 && arn[0] == "arn" {
+		region = arn[3]
+	}
+	if region == "" {
+		return "", errors.New("the AWS region is not set, set AWS_REGION")
+	}
+
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		o.Region = region
+	})
+	secret, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(ref.Path)})
+	if err != nil {
+		return "", err
+	}
+	if secret.SecretString == nil {
+		return secretKey(string(secret.SecretBinary), ref.Key)
+	}
+	return secretKey(*secret.SecretString, ref.Key)
+}
+
+// doJSON sends a request and decodes its JSON response, or returns the status and the body of
+// the response if it is not successful.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
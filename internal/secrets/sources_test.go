@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		require.Equal(t, "/v1/secret/data/openfga/datastore", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"secret"},"metadata":{"version":2}}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL+"/")
+	t.Setenv("VAULT_TOKEN", "token")
+	t.Setenv("VAULT_NAMESPACE", "team")
+	resolver := NewResolver()
+	ctx := context.Background()
+
+	secret, err := resolver.Resolve(ctx, "vault://secret/openfga/datastore#password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", secret)
+
+	_, err = resolver.Resolve(ctx, "vault://secret/openfga/datastore")
+	require.ErrorContains(t, err, "the key of the Vault secret is not set")
+
+	t.Setenv("VAULT_TOKEN", "expired")
+	_, err = NewResolver().Resolve(ctx, "vault://secret/openfga/datastore#password")
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestAWSSecretsManagerSource(t *testing.T) {
+	var regions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		authorization := r.Header.Get("Authorization")
+		require.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/"), authorization)
+		regions = append(regions, strings.Split(authorization, "/")[2])
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		switch {
+		case strings.Contains(string(body), `"openfga/datastore"`):
+			_, _ = w.Write([]byte(`{"Name":"openfga/datastore","SecretString":"{\"password\":\"secret\"}"}`))
+		case strings.Contains(string(body), `secret:openfga-key`):
+			_, _ = w.Write([]byte(`{"SecretString":"preshared-key"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", server.URL)
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	resolver := NewResolver()
+	ctx := context.Background()
+
+	secret, err := resolver.Resolve(ctx, "awssm://openfga/datastore#password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", secret)
+
+	secret, err = resolver.Resolve(ctx, "awssm://arn:aws:secretsmanager:eu-west-1:123456789012:secret:openfga-key")
+	require.NoError(t, err)
+	require.Equal(t, "preshared-key", secret)
+	require.Equal(t, []string{"us-east-1", "eu-west-1"}, regions)
+
+	_, err = resolver.Resolve(ctx, "awssm://missing")
+	require.ErrorContains(t, err, "ResourceNotFoundException")
+}
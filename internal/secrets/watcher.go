@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+var (
+	rotatedSecretsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "secrets_rotated_count",
+		Help:      "The total number of rotations of the secrets referenced by the configuration observed by the secrets watcher.",
+	})
+
+	refreshErrorsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "secrets_refresh_errors_count",
+		Help:      "The total number of failures to refresh the secrets referenced by the configuration, which keep their previous value.",
+	})
+)
+
+type watchedSecret struct {
+	value     string
+	callbacks []func(string)
+}
+
+// Watcher resolves the secret references of the configuration and polls them, so that the
+// secrets rotated in their secret manager are reloaded without a restart.
+type Watcher struct {
+	resolver *Resolver
+	interval time.Duration
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	secrets map[string]*watchedSecret // GUARDED_BY(mu)
+}
+
+// NewWatcher returns a Watcher of the secrets of the resolver, polled at the given interval.
+func NewWatcher(resolver *Resolver, interval time.Duration, logger logger.Logger) *Watcher {
+	return &Watcher{
+		resolver: resolver,
+		interval: interval,
+		logger:   logger,
+		secrets:  make(map[string]*watchedSecret),
+	}
+}
+
+// Watch resolves a value of the configuration, and calls onChange, if not nil, with the new
+// secret whenever the secret it references is rotated. The plain values are returned as they are
+// and never change.
+func (w *Watcher) Watch(ctx context.Context, value string, onChange func(string)) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	secret, ok := w.secrets[value]
+	if !ok {
+		resolved, err := w.resolver.Resolve(ctx, value)
+		if err != nil {
+			return "", err
+		}
+		secret = &watchedSecret{value: resolved}
+		w.secrets[value] = secret
+	}
+	if onChange != nil {
+		secret.callbacks = append(secret.callbacks, onChange)
+	}
+	return secret.value, nil
+}
+
+// Value returns the current secret of a value of the configuration passed to Watch, or the value
+// itself if it is a plain value.
+func (w *Watcher) Value(value string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if secret, ok := w.secrets[value]; ok {
+		return secret.value
+	}
+	return value
+}
+
+// Run polls the watched secrets until the context is done.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.refresh(ctx)
+		}
+	}
+}
+
+// refresh resolves the watched secrets again, and calls the callbacks of the rotated ones. The
+// secrets that fail to resolve keep their previous value.
+func (w *Watcher) refresh(ctx context.Context) {
+	w.mu.Lock()
+	values := make([]string, 0, len(w.secrets))
+	for value := range w.secrets {
+		values = append(values, value)
+	}
+	w.mu.Unlock()
+
+	for _, value := range values {
+		resolved, err := w.resolver.Resolve(ctx, value)
+		if err != nil {
+			refreshErrorsCounter.Inc()
+			w.logger.Warn("failed to refresh secret", zap.Error(err))
+			continue
+		}
+
+		w.mu.Lock()
+		secret := w.secrets[value]
+		if secret.value == resolved {
+			w.mu.Unlock()
+			continue
+		}
+		secret.value = resolved
+		callbacks := slices.Clone(secret.callbacks)
+		w.mu.Unlock()
+
+		rotatedSecretsCounter.Inc()
+		w.logger.Info("secret rotated", zap.String("reference", value))
+		for _, callback := range callbacks {
+			callback(resolved)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("first"), 0o600))
+	reference := "file://" + path
+
+	watcher := NewWatcher(NewResolver(), time.Millisecond, logger.NewNoopLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	value, err := watcher.Watch(ctx, "plain", func(string) { t.Fatal("plain values never change") })
+	require.NoError(t, err)
+	require.Equal(t, "plain", value)
+
+	rotated := make(chan string, 1)
+	value, err = watcher.Watch(ctx, reference, func(secret string) { rotated <- secret })
+	require.NoError(t, err)
+	require.Equal(t, "first", value)
+	require.Equal(t, "first", watcher.Value(reference))
+
+	_, err = watcher.Watch(ctx, "file://"+filepath.Join(t.TempDir(), "missing"), nil)
+	require.Error(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.Run(ctx)
+	}()
+
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+	select {
+	case secret := <-rotated:
+		require.Equal(t, "second", secret)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rotation of the secret was not observed")
+	}
+	require.Equal(t, "second", watcher.Value(reference))
+
+	// the secrets that fail to refresh keep their value
+	require.NoError(t, os.Remove(path))
+	watcher.refresh(ctx)
+	require.Equal(t, "second", watcher.Value(reference))
+
+	cancel()
+	<-done
+}
@@ -2,6 +2,7 @@ package shared
 
 import (
 	"context"
+	"math"
 	"sync"
 	"time"
 
@@ -45,6 +46,23 @@ func WithCheckCache(c storage.InMemoryCache[any]) SharedDatastoreResourcesOpt {
 	}
 }
 
+// WithDiskCacheOpts sets the options of the disk-backed check cache, which is created when
+// CacheSettings.CheckCacheDiskPath is set. These options must register the decoders of the cache
+// items to persist.
+func WithDiskCacheOpts(opts ...storage.DiskBackedCacheOpt) SharedDatastoreResourcesOpt {
+	return func(s *SharedDatastoreResources) {
+		s.diskCacheOptions = opts
+	}
+}
+
+// WithCacheControllerOpts sets the additional options of the cache controller created in
+// NewSharedDatastoreResources(), e.g. cachecontroller.WithChangeObservation.
+func WithCacheControllerOpts(opts ...cachecontroller.InMemoryCacheControllerOpt) SharedDatastoreResourcesOpt {
+	return func(s *SharedDatastoreResources) {
+		s.cacheControllerOptions = opts
+	}
+}
+
 // SharedDatastoreResources contains resources that can be shared across Check requests.
 type SharedDatastoreResources struct {
 	SingleflightGroup     *singleflight.Group
@@ -62,6 +80,9 @@ type SharedDatastoreResources struct {
 	V2IteratorCacheTTL     time.Duration
 	V2IteratorCacheMaxSize int
 	V2IteratorDrainTimeout time.Duration // Timeout for background iterator drain operations
+
+	diskCacheOptions       []storage.DiskBackedCacheOpt
+	cacheControllerOptions []cachecontroller.InMemoryCacheControllerOpt
 }
 
 func NewSharedDatastoreResources(
@@ -97,17 +118,26 @@ func NewSharedDatastoreResources(
 
 	if settings.ShouldCreateNewCache() && s.CheckCache == nil {
 		var err error
-		s.CheckCache, err = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[any]{
-			storage.WithMaxCacheSize[any](int64(settings.CheckCacheLimit)),
-		}...)
+		s.CheckCache, err = storage.NewInMemoryLRUCache(checkCacheOptions(settings)...)
 		if err != nil {
 			return nil, err
 		}
+
+		if settings.CheckCacheDiskPath != "" && settings.ShouldCacheCheckQueries() {
+			diskCacheOptions := append([]storage.DiskBackedCacheOpt{storage.WithDiskCacheLogger(s.Logger)}, s.diskCacheOptions...)
+			diskCache, err := storage.NewDiskBackedCache(s.CheckCache, settings.CheckCacheDiskPath, diskCacheOptions...)
+			if err != nil {
+				s.CheckCache.Stop()
+				return nil, err
+			}
+			s.CheckCache = diskCache
+		}
 	}
 
 	// Only create a cache controller if it wasn't already set via opts.
 	if settings.ShouldCreateCacheController() && s.CacheController == defaultCacheController {
-		s.CacheController = cachecontroller.NewCacheController(ds, s.CheckCache, settings.CacheControllerTTL, settings.CheckQueryCacheTTL, settings.CheckIteratorCacheTTL, cachecontroller.WithLogger(s.Logger))
+		cacheControllerOptions := append([]cachecontroller.InMemoryCacheControllerOpt{cachecontroller.WithLogger(s.Logger)}, s.cacheControllerOptions...)
+		s.CacheController = cachecontroller.NewCacheController(ds, s.CheckCache, settings.CacheControllerTTL, settings.QueryCacheTTL(), settings.CheckIteratorCacheTTL, cacheControllerOptions...)
 	}
 
 	// The default behavior is to use the same cache instance for both the
@@ -124,9 +154,7 @@ func NewSharedDatastoreResources(
 
 	if settings.ShouldCreateShadowNewCache() {
 		var err error
-		s.ShadowCheckCache, err = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[any]{
-			storage.WithMaxCacheSize[any](int64(settings.CheckCacheLimit)),
-		}...)
+		s.ShadowCheckCache, err = storage.NewInMemoryLRUCache(checkCacheOptions(settings)...)
 		if err != nil {
 			return nil, err
 		}
@@ -134,12 +162,25 @@ func NewSharedDatastoreResources(
 
 	// Only create a shadow cache controller if it wasn't already set via opts.
 	if settings.ShouldCreateShadowCacheController() && s.ShadowCacheController == s.CacheController {
-		s.ShadowCacheController = cachecontroller.NewCacheController(ds, s.ShadowCheckCache, settings.CacheControllerTTL, settings.CheckQueryCacheTTL, settings.CheckIteratorCacheTTL, cachecontroller.WithLogger(s.Logger))
+		s.ShadowCacheController = cachecontroller.NewCacheController(ds, s.ShadowCheckCache, settings.CacheControllerTTL, settings.QueryCacheTTL(), settings.CheckIteratorCacheTTL, cachecontroller.WithLogger(s.Logger))
 	}
 
 	return s, nil
 }
 
+// checkCacheOptions limits the check cache by the memory held by its entries if
+// CacheSettings.CheckCacheMaxBytes is set, and by their number otherwise.
+func checkCacheOptions(settings serverconfig.CacheSettings) []storage.InMemoryLRUCacheOpt[any] {
+	opts := []storage.InMemoryLRUCacheOpt[any]{
+		storage.WithMaxCacheSize[any](int64(settings.CheckCacheLimit)),
+		storage.WithCacheShards[any](int(settings.CheckCacheShards)),
+	}
+	if settings.CheckCacheMaxBytes > 0 {
+		opts = append(opts, storage.WithMaxCacheBytes[any](int64(min(settings.CheckCacheMaxBytes, math.MaxInt64))))
+	}
+	return opts
+}
+
 func (s *SharedDatastoreResources) Close() {
 	// wait for any goroutines still in flight before
 	// closing the cache instance to avoid data races
@@ -2,6 +2,7 @@ package shared
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +12,7 @@ import (
 	"github.com/openfga/openfga/internal/cachecontroller"
 	mockstorage "github.com/openfga/openfga/internal/mocks"
 	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
 )
 
 func TestSharedDatastoreResources(t *testing.T) {
@@ -148,4 +150,21 @@ func TestSharedDatastoreResources(t *testing.T) {
 
 		require.Equal(t, customShadowController, s.ShadowCacheController)
 	})
+
+	t.Run("with_disk_cache", func(t *testing.T) {
+		settings := config.CacheSettings{
+			CheckCacheLimit:        1,
+			CheckQueryCacheEnabled: true,
+			CheckCacheDiskPath:     filepath.Join(t.TempDir(), "cache.db"),
+		}
+
+		s, err := NewSharedDatastoreResources(sharedCtx, sharedSf, mockDatastore, settings)
+		require.NoError(t, err)
+		t.Cleanup(s.Close)
+
+		_, ok := s.CheckCache.(*storage.DiskBackedCache)
+		require.True(t, ok)
+		_, ok = s.ShadowCheckCache.(*storage.DiskBackedCache)
+		require.False(t, ok)
+	})
 }
@@ -0,0 +1,213 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/pkg/middleware/storeid"
+)
+
+// ErrInvalidSamplingRatio is returned when a sampling ratio cannot be parsed or is not between 0
+// and 1.
+var ErrInvalidSamplingRatio = errors.New("invalid sampling ratio")
+
+// ParseSamplingRatios parses sampling ratios in the form <key>=<ratio>, e.g.
+// 01ARZ3NDEKTSV4RRFFQ69G5FAV=1 or Check=0.001.
+func ParseSamplingRatios(values []string) (map[string]float64, error) {
+	ratios := make(map[string]float64, len(values))
+	for _, value := range values {
+		key, rawRatio, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w '%s': expected <key>=<ratio>", ErrInvalidSamplingRatio, value)
+		}
+
+		ratio, err := strconv.ParseFloat(rawRatio, 64)
+		if err != nil || !validRatio(ratio) {
+			return nil, fmt.Errorf("%w '%s': the ratio must be between 0 and 1", ErrInvalidSamplingRatio, value)
+		}
+		ratios[key] = ratio
+	}
+	return ratios, nil
+}
+
+func validRatio(ratio float64) bool {
+	return ratio >= 0 && ratio <= 1
+}
+
+// SamplingRatios are the ratios of the traces sampled by a RuleSampler.
+type SamplingRatios struct {
+	// Default is the ratio of the traces of the stores and methods not listed in Stores and Methods.
+	Default float64 `json:"default"`
+
+	// Stores are the ratios of the traces of the requests on a store, by store ID. They take
+	// precedence over Methods.
+	Stores map[string]float64 `json:"stores"`
+
+	// Methods are the ratios of the traces of the requests of an RPC, by the name of the RPC
+	// without its service, e.g. Check.
+	Methods map[string]float64 `json:"methods"`
+}
+
+func (r SamplingRatios) validate() error {
+	if !validRatio(r.Default) {
+		return fmt.Errorf("%w: the default ratio must be between 0 and 1", ErrInvalidSamplingRatio)
+	}
+	for storeID, ratio := range r.Stores {
+		if !validRatio(ratio) {
+			return fmt.Errorf("%w: the ratio of store '%s' must be between 0 and 1", ErrInvalidSamplingRatio, storeID)
+		}
+	}
+	for method, ratio := range r.Methods {
+		if !validRatio(ratio) {
+			return fmt.Errorf("%w: the ratio of method '%s' must be between 0 and 1", ErrInvalidSamplingRatio, method)
+		}
+	}
+	return nil
+}
+
+// RuleSampler samples the traces with a ratio that depends on the store and the RPC method of the
+// request. The spans of the sampled local parents are always sampled, so that the traces are
+// complete. The ratios can be changed at runtime with SetRatios or through its HTTP handler.
+//
+// The store of a gRPC request is only known once its message is received, so the store ratio
+// applies to the spans started by the handler of the request, and the span of the RPC itself is
+// sampled with the method ratio.
+type RuleSampler struct {
+	mu     sync.RWMutex
+	ratios SamplingRatios
+}
+
+var (
+	_ sdktrace.Sampler = (*RuleSampler)(nil)
+	_ http.Handler     = (*RuleSampler)(nil)
+)
+
+// NewRuleSampler constructs a RuleSampler with ratios.
+func NewRuleSampler(ratios SamplingRatios) (*RuleSampler, error) {
+	s := &RuleSampler{}
+	if err := s.SetRatios(ratios); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Ratios returns the current ratios of the sampler.
+func (s *RuleSampler) Ratios() SamplingRatios {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ratios
+}
+
+// SetRatios replaces the ratios of the sampler.
+func (s *RuleSampler) SetRatios(ratios SamplingRatios) error {
+	if err := ratios.validate(); err != nil {
+		return err
+	}
+	if ratios.Stores == nil {
+		ratios.Stores = map[string]float64{}
+	}
+	if ratios.Methods == nil {
+		ratios.Methods = map[string]float64{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ratios = ratios
+	return nil
+}
+
+func (s *RuleSampler) ratio(ctx context.Context, name string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if storeID, ok := storeIDFromContext(ctx); ok {
+		if ratio, ok := s.ratios.Stores[storeID]; ok {
+			return ratio
+		}
+	}
+	// the spans of the RPCs are named after their full method, e.g. openfga.v1.OpenFGAService/Check
+	if ratio, ok := s.ratios.Methods[path.Base(name)]; ok {
+		return ratio
+	}
+	return s.ratios.Default
+}
+
+func (s *RuleSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	if parent.IsSampled() && !parent.IsRemote() {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: parent.TraceState(),
+		}
+	}
+	return sdktrace.TraceIDRatioBased(s.ratio(p.ParentContext, p.Name)).ShouldSample(p)
+}
+
+func (s *RuleSampler) Description() string {
+	return "RuleSampler"
+}
+
+// ServeHTTP serves the ratios of the sampler as JSON, and replaces them with the ratios of the
+// body of the PUT requests.
+func (s *RuleSampler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var ratios SamplingRatios
+		if err := json.NewDecoder(r.Body).Decode(&ratios); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.SetRatios(ratios); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.Ratios())
+}
+
+type storeIDContextKey struct{}
+
+func storeIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	if storeID, ok := ctx.Value(storeIDContextKey{}).(string); ok {
+		return storeID, true
+	}
+	if storeID, ok := storeid.StoreIDFromContext(ctx); ok && storeID != "" {
+		return storeID, true
+	}
+	return "", false
+}
+
+// NewStoreSamplingHTTPMiddleware returns a middleware that makes the store of the HTTP requests on
+// /stores/{store_id} known to the RuleSampler. It must wrap the tracing handler, so that the span
+// of the HTTP request is sampled with the ratio of the store.
+func NewStoreSamplingHTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rest, ok := strings.CutPrefix(r.URL.Path, "/stores/"); ok {
+			storeID, _, _ := strings.Cut(rest, "/")
+			if storeID != "" {
+				r = r.WithContext(context.WithValue(r.Context(), storeIDContextKey{}, storeID))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
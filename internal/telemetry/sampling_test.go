@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestParseSamplingRatios(t *testing.T) {
+	ratios, err := ParseSamplingRatios([]string{"01ARZ3NDEKTSV4RRFFQ69G5FAV=1", "Check=0.001"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"01ARZ3NDEKTSV4RRFFQ69G5FAV": 1, "Check": 0.001}, ratios)
+
+	for _, value := range []string{"Check", "=0.5", "Check=abc", "Check=-0.1", "Check=1.5"} {
+		_, err := ParseSamplingRatios([]string{value})
+		require.ErrorIs(t, err, ErrInvalidSamplingRatio, value)
+	}
+}
+
+func TestRuleSampler(t *testing.T) {
+	sampler, err := NewRuleSampler(SamplingRatios{
+		Default: 0,
+		Stores:  map[string]float64{"store": 1},
+		Methods: map[string]float64{"Check": 1, "Write": 0},
+	})
+	require.NoError(t, err)
+
+	decision := func(ctx context.Context, name string) sdktrace.SamplingDecision {
+		return sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: ctx,
+			TraceID:       trace.TraceID{1},
+			Name:          name,
+		}).Decision
+	}
+
+	t.Run("default_ratio", func(t *testing.T) {
+		require.Equal(t, sdktrace.Drop, decision(context.Background(), "openfga.v1.OpenFGAService/Read"))
+	})
+
+	t.Run("method_ratio", func(t *testing.T) {
+		require.Equal(t, sdktrace.RecordAndSample, decision(context.Background(), "openfga.v1.OpenFGAService/Check"))
+	})
+
+	t.Run("store_ratio_takes_precedence", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), storeIDContextKey{}, "store")
+		require.Equal(t, sdktrace.RecordAndSample, decision(ctx, "openfga.v1.OpenFGAService/Write"))
+	})
+
+	t.Run("sampled_local_parent", func(t *testing.T) {
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+		}))
+		require.Equal(t, sdktrace.RecordAndSample, decision(ctx, "ReadStartingWithUser"))
+	})
+
+	t.Run("sampled_remote_parent", func(t *testing.T) {
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+		}))
+		require.Equal(t, sdktrace.Drop, decision(ctx, "openfga.v1.OpenFGAService/Read"))
+	})
+
+	t.Run("set_ratios", func(t *testing.T) {
+		require.ErrorIs(t, sampler.SetRatios(SamplingRatios{Default: 2}), ErrInvalidSamplingRatio)
+
+		require.NoError(t, sampler.SetRatios(SamplingRatios{Default: 1}))
+		require.Equal(t, sdktrace.RecordAndSample, decision(context.Background(), "openfga.v1.OpenFGAService/Read"))
+		require.Empty(t, sampler.Ratios().Stores)
+	})
+}
+
+func TestRuleSamplerServeHTTP(t *testing.T) {
+	sampler, err := NewRuleSampler(SamplingRatios{Default: 0.2})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	sampler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trace/sampling", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"default":0.2,"stores":{},"methods":{}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	sampler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/trace/sampling", strings.NewReader(`{"default":0.001,"stores":{"store":1}}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"default":0.001,"stores":{"store":1},"methods":{}}`, rec.Body.String())
+	require.InDelta(t, 1, sampler.Ratios().Stores["store"], 0)
+
+	rec = httptest.NewRecorder()
+	sampler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/trace/sampling", strings.NewReader(`{"default":3}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.InDelta(t, 0.001, sampler.Ratios().Default, 0)
+
+	rec = httptest.NewRecorder()
+	sampler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/trace/sampling", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestStoreSamplingHTTPMiddleware(t *testing.T) {
+	var storeID string
+	handler := NewStoreSamplingHTTPMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		storeID, _ = storeIDFromContext(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/stores/01ARZ3NDEKTSV4RRFFQ69G5FAV/check", nil))
+	require.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", storeID)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stores", nil))
+	require.Empty(t, storeID)
+}
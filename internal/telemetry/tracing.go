@@ -17,6 +17,8 @@ import (
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/redaction"
 )
 
 type TracerOption func(d *customTracer)
@@ -39,18 +41,44 @@ func WithSamplingRatio(samplingRatio float64) TracerOption {
 	}
 }
 
+// WithSampler sets the sampler of the traces, which takes precedence over the sampling ratio.
+func WithSampler(sampler sdktrace.Sampler) TracerOption {
+	return func(d *customTracer) {
+		d.sampler = sampler
+	}
+}
+
+// WithVerboseTracing samples all the spans of the requests with the VerboseTracingHeader,
+// regardless of the sampling ratio.
+func WithVerboseTracing() TracerOption {
+	return func(d *customTracer) {
+		d.verbose = true
+	}
+}
+
 func WithAttributes(attrs ...attribute.KeyValue) TracerOption {
 	return func(d *customTracer) {
 		d.attributes = attrs
 	}
 }
 
+// WithRedactor redacts the IDs of the attributes of the exported spans, see [redaction.NewSpanExporter].
+func WithRedactor(redactor *redaction.Redactor) TracerOption {
+	return func(d *customTracer) {
+		d.redactor = redactor
+	}
+}
+
 type customTracer struct {
 	endpoint   string
 	insecure   bool
 	attributes []attribute.KeyValue
 
 	samplingRatio float64
+	sampler       sdktrace.Sampler
+	verbose       bool
+
+	redactor *redaction.Redactor
 }
 
 // ParseOTLPEndpoint strips the scheme from an endpoint string that may contain
@@ -137,9 +165,18 @@ func MustNewTracerProvider(opts ...TracerOption) *sdktrace.TracerProvider {
 	if err != nil {
 		panic(fmt.Sprintf("failed to establish a connection with the otlp exporter: %v", err))
 	}
+	exp = redaction.NewSpanExporter(exp, tracer.redactor)
+
+	sampler := tracer.sampler
+	if sampler == nil {
+		sampler = sdktrace.TraceIDRatioBased(tracer.samplingRatio)
+	}
+	if tracer.verbose {
+		sampler = NewVerboseSampler(sampler)
+	}
 
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(tracer.samplingRatio)),
+		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
 		sdktrace.WithSpanProcessor(sdktrace.NewBatchSpanProcessor(exp)),
 	)
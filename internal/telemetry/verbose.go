@@ -0,0 +1,127 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// VerboseTracingHeader is the request header that elevates the tracing of a request: when it
+	// is "true", all the spans of the request are sampled, regardless of the sampling ratio.
+	VerboseTracingHeader = "Openfga-Trace-Verbose"
+
+	// TraceIDHeader is the response header set to the trace ID of the requests with verbose
+	// tracing, so that the trace can be found in the trace collector.
+	TraceIDHeader = "Openfga-Trace-Id"
+)
+
+type verboseTracingContextKey struct{}
+
+// ContextWithVerboseTracing returns a context whose spans are all sampled.
+func ContextWithVerboseTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verboseTracingContextKey{}, true)
+}
+
+// VerboseTracingFromContext returns whether the spans of ctx are all sampled, i.e. whether ctx
+// was returned by ContextWithVerboseTracing or is the context of a gRPC request with the
+// VerboseTracingHeader.
+func VerboseTracingFromContext(ctx context.Context) bool {
+	if verbose, ok := ctx.Value(verboseTracingContextKey{}).(bool); ok {
+		return verbose
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(VerboseTracingHeader))
+	return len(values) > 0 && isTrue(values[0])
+}
+
+func isTrue(value string) bool {
+	verbose, err := strconv.ParseBool(strings.TrimSpace(value))
+	return err == nil && verbose
+}
+
+// verboseSampler samples all the spans of the contexts with verbose tracing, and delegates the
+// sampling of the other spans to its base sampler.
+type verboseSampler struct {
+	base sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*verboseSampler)(nil)
+
+// NewVerboseSampler returns a sampler that samples all the spans of the contexts with verbose
+// tracing, and the other spans with base.
+func NewVerboseSampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return &verboseSampler{base: base}
+}
+
+func (s *verboseSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if p.ParentContext != nil && VerboseTracingFromContext(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+func (s *verboseSampler) Description() string {
+	return "VerboseSampler{" + s.base.Description() + "}"
+}
+
+// NewVerboseTracingHTTPMiddleware returns a middleware that enables the verbose tracing of the
+// HTTP requests with the VerboseTracingHeader. It must wrap the tracing handler, so that the span
+// of the HTTP request is sampled too.
+func NewVerboseTracingHTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isTrue(r.Header.Get(VerboseTracingHeader)) {
+			r = r.WithContext(ContextWithVerboseTracing(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NewVerboseTracingUnaryInterceptor returns a grpc.UnaryServerInterceptor that sets the
+// TraceIDHeader of the responses of the requests with verbose tracing.
+func NewVerboseTracingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := traceIDHeader(ctx); ok {
+			_ = grpc.SetHeader(ctx, md)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewVerboseTracingStreamingInterceptor returns a grpc.StreamServerInterceptor that sets the
+// TraceIDHeader of the responses of the streams with verbose tracing.
+func NewVerboseTracingStreamingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if md, ok := traceIDHeader(stream.Context()); ok {
+			_ = stream.SetHeader(md)
+		}
+		return handler(srv, stream)
+	}
+}
+
+// traceIDHeader returns the TraceIDHeader of the response of the request of ctx, if it has verbose
+// tracing.
+func traceIDHeader(ctx context.Context) (metadata.MD, bool) {
+	if !VerboseTracingFromContext(ctx) {
+		return nil, false
+	}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return nil, false
+	}
+	return metadata.Pairs(strings.ToLower(TraceIDHeader), spanContext.TraceID().String()), true
+}
@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestVerboseTracingFromContext(t *testing.T) {
+	t.Run("context_without_verbose_tracing", func(t *testing.T) {
+		require.False(t, VerboseTracingFromContext(context.Background()))
+	})
+
+	t.Run("context_with_verbose_tracing", func(t *testing.T) {
+		require.True(t, VerboseTracingFromContext(ContextWithVerboseTracing(context.Background())))
+	})
+
+	t.Run("incoming_metadata_with_header", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("openfga-trace-verbose", "true"))
+		require.True(t, VerboseTracingFromContext(ctx))
+	})
+
+	t.Run("incoming_metadata_with_false_header", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("openfga-trace-verbose", "false"))
+		require.False(t, VerboseTracingFromContext(ctx))
+	})
+
+	t.Run("incoming_metadata_with_invalid_header", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("openfga-trace-verbose", "yes please"))
+		require.False(t, VerboseTracingFromContext(ctx))
+	})
+}
+
+func TestVerboseSampler(t *testing.T) {
+	sampler := NewVerboseSampler(sdktrace.TraceIDRatioBased(0))
+	params := func(ctx context.Context) sdktrace.SamplingParameters {
+		return sdktrace.SamplingParameters{
+			ParentContext: ctx,
+			TraceID:       trace.TraceID{1},
+			Name:          "span",
+		}
+	}
+
+	require.Equal(t, sdktrace.Drop, sampler.ShouldSample(params(context.Background())).Decision)
+	require.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(params(ContextWithVerboseTracing(context.Background()))).Decision)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("openfga-trace-verbose", "true"))
+	require.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(params(ctx)).Decision)
+
+	require.Contains(t, sampler.Description(), "VerboseSampler")
+}
+
+func TestVerboseTracingHTTPMiddleware(t *testing.T) {
+	var verbose bool
+	handler := NewVerboseTracingHTTPMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		verbose = VerboseTracingFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/stores/1/check", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.False(t, verbose)
+
+	req = httptest.NewRequest(http.MethodPost, "/stores/1/check", nil)
+	req.Header.Set(VerboseTracingHeader, "true")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.True(t, verbose)
+}
@@ -0,0 +1,186 @@
+package throttler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+// writeLimiterSweepInterval is the interval at which the buckets of the idle stores are dropped.
+const writeLimiterSweepInterval = time.Minute
+
+var writeThrottledCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "write_throttled_count",
+	Help:      "The total number of writes rejected because their store exceeded its write throughput limit, by limit.",
+}, []string{"limit"})
+
+// ErrInvalidStoreWriteRate is returned when a per-store write rate cannot be parsed.
+var ErrInvalidStoreWriteRate = errors.New("invalid store write rate")
+
+// ParseStoreWriteRates parses per-store write rates in the form <store_id>=<rate>, e.g.
+// 01ARZ3NDEKTSV4RRFFQ69G5FAV=500. A rate of 0 lifts the limit of the store.
+func ParseStoreWriteRates(values []string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(values))
+	for _, value := range values {
+		storeID, rawRate, ok := strings.Cut(value, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("%w '%s': expected <store_id>=<rate>", ErrInvalidStoreWriteRate, value)
+		}
+
+		r, err := strconv.ParseFloat(rawRate, 64)
+		if err != nil || r < 0 {
+			return nil, fmt.Errorf("%w '%s': invalid rate '%s'", ErrInvalidStoreWriteRate, value, rawRate)
+		}
+		rates[storeID] = r
+	}
+	return rates, nil
+}
+
+// WriteRates are the write throughput limits of a store. A rate of 0 means no limit.
+type WriteRates struct {
+	// TuplesPerSecond is the number of tuples a store can write per second.
+	TuplesPerSecond float64
+
+	// ChangesPerSecond is the number of changes a store can make per second, i.e. of tuples
+	// written and deleted, each of which is an entry of the changelog.
+	ChangesPerSecond float64
+}
+
+// StoreWriteLimiter limits the write throughput of every store with token buckets, so that a bulk
+// load on a store cannot overwhelm the changelog and the replicas. Each bucket holds one second of
+// its rate, so that a write larger than that is allowed once the bucket is full.
+type StoreWriteLimiter struct {
+	defaults              WriteRates
+	storeTuplesPerSecond  map[string]float64
+	storeChangesPerSecond map[string]float64
+	now                   func() time.Time
+
+	mu        sync.Mutex
+	stores    map[string]*storeWriteBuckets
+	lastSweep time.Time
+}
+
+type storeWriteBuckets struct {
+	tuples  *rate.Limiter
+	changes *rate.Limiter
+}
+
+// NewStoreWriteLimiter constructs a StoreWriteLimiter that limits every store to defaults, or to
+// the rates in storeTuplesPerSecond and storeChangesPerSecond for the stores they list.
+func NewStoreWriteLimiter(defaults WriteRates, storeTuplesPerSecond, storeChangesPerSecond map[string]float64) *StoreWriteLimiter {
+	return &StoreWriteLimiter{
+		defaults:              defaults,
+		storeTuplesPerSecond:  storeTuplesPerSecond,
+		storeChangesPerSecond: storeChangesPerSecond,
+		now:                   time.Now,
+		stores:                make(map[string]*storeWriteBuckets),
+	}
+}
+
+// Enabled returns whether any store has a limit.
+func (l *StoreWriteLimiter) Enabled() bool {
+	return l.defaults.TuplesPerSecond > 0 || l.defaults.ChangesPerSecond > 0 ||
+		len(l.storeTuplesPerSecond) > 0 || len(l.storeChangesPerSecond) > 0
+}
+
+func (l *StoreWriteLimiter) rates(storeID string) WriteRates {
+	rates := l.defaults
+	if r, ok := l.storeTuplesPerSecond[storeID]; ok {
+		rates.TuplesPerSecond = r
+	}
+	if r, ok := l.storeChangesPerSecond[storeID]; ok {
+		rates.ChangesPerSecond = r
+	}
+	return rates
+}
+
+func newBucket(perSecond float64) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), int(math.Max(1, math.Ceil(perSecond))))
+}
+
+// Allow takes tuples and changes tokens from the buckets of storeID. If a bucket has too few
+// tokens, it takes none and returns false and the time after which the write can be retried.
+func (l *StoreWriteLimiter) Allow(storeID string, tuples, changes int) (time.Duration, bool) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	buckets, ok := l.stores[storeID]
+	if !ok {
+		rates := l.rates(storeID)
+		buckets = &storeWriteBuckets{tuples: newBucket(rates.TuplesPerSecond), changes: newBucket(rates.ChangesPerSecond)}
+		l.stores[storeID] = buckets
+	}
+
+	tuplesReservation := reserve(buckets.tuples, now, tuples)
+	changesReservation := reserve(buckets.changes, now, changes)
+	tuplesDelay, changesDelay := delay(tuplesReservation, now), delay(changesReservation, now)
+	if tuplesDelay == 0 && changesDelay == 0 {
+		return 0, true
+	}
+
+	cancel(tuplesReservation, now)
+	cancel(changesReservation, now)
+	if tuplesDelay >= changesDelay {
+		writeThrottledCounter.WithLabelValues("tuples").Inc()
+		return tuplesDelay, false
+	}
+	writeThrottledCounter.WithLabelValues("changes").Inc()
+	return changesDelay, false
+}
+
+func reserve(bucket *rate.Limiter, now time.Time, n int) *rate.Reservation {
+	if bucket == nil || n <= 0 {
+		return nil
+	}
+	return bucket.ReserveN(now, min(n, bucket.Burst()))
+}
+
+func delay(reservation *rate.Reservation, now time.Time) time.Duration {
+	if reservation == nil {
+		return 0
+	}
+	return reservation.DelayFrom(now)
+}
+
+func cancel(reservation *rate.Reservation, now time.Time) {
+	if reservation != nil {
+		reservation.CancelAt(now)
+	}
+}
+
+// sweep drops the buckets of the stores that are full, which are the same as new ones, so that
+// the idle stores don't hold memory.
+func (l *StoreWriteLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < writeLimiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for storeID, buckets := range l.stores {
+		if full(buckets.tuples, now) && full(buckets.changes, now) {
+			delete(l.stores, storeID)
+		}
+	}
+}
+
+func full(bucket *rate.Limiter, now time.Time) bool {
+	return bucket == nil || bucket.TokensAt(now) >= float64(bucket.Burst())
+}
@@ -0,0 +1,112 @@
+package throttler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStoreWriteRates(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		rates, err := ParseStoreWriteRates([]string{"store1=500", "store2=0.5", "store3=0"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{"store1": 500, "store2": 0.5, "store3": 0}, rates)
+	})
+
+	for _, value := range []string{"store1", "=500", "store1=", "store1=abc", "store1=-1"} {
+		t.Run("invalid_"+value, func(t *testing.T) {
+			_, err := ParseStoreWriteRates([]string{value})
+			require.ErrorIs(t, err, ErrInvalidStoreWriteRate)
+		})
+	}
+}
+
+func newTestStoreWriteLimiter(defaults WriteRates, storeTuplesPerSecond, storeChangesPerSecond map[string]float64) (*StoreWriteLimiter, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewStoreWriteLimiter(defaults, storeTuplesPerSecond, storeChangesPerSecond)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestStoreWriteLimiter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		limiter := NewStoreWriteLimiter(WriteRates{}, nil, nil)
+		require.False(t, limiter.Enabled())
+
+		_, ok := limiter.Allow("store1", 1000000, 1000000)
+		require.True(t, ok)
+	})
+
+	t.Run("rejects_writes_over_the_tuples_rate", func(t *testing.T) {
+		limiter, now := newTestStoreWriteLimiter(WriteRates{TuplesPerSecond: 10}, nil, nil)
+		require.True(t, limiter.Enabled())
+
+		_, ok := limiter.Allow("store1", 10, 10)
+		require.True(t, ok)
+
+		retryAfter, ok := limiter.Allow("store1", 5, 5)
+		require.False(t, ok)
+		require.Equal(t, 500*time.Millisecond, retryAfter)
+
+		// the rejected write took no tokens
+		*now = now.Add(retryAfter)
+		_, ok = limiter.Allow("store1", 5, 5)
+		require.True(t, ok)
+	})
+
+	t.Run("rejects_writes_over_the_changes_rate", func(t *testing.T) {
+		limiter, _ := newTestStoreWriteLimiter(WriteRates{TuplesPerSecond: 100, ChangesPerSecond: 4}, nil, nil)
+
+		_, ok := limiter.Allow("store1", 1, 4)
+		require.True(t, ok)
+
+		retryAfter, ok := limiter.Allow("store1", 1, 2)
+		require.False(t, ok)
+		require.Equal(t, 500*time.Millisecond, retryAfter)
+	})
+
+	t.Run("stores_have_their_own_buckets", func(t *testing.T) {
+		limiter, _ := newTestStoreWriteLimiter(WriteRates{TuplesPerSecond: 1}, nil, nil)
+
+		_, ok := limiter.Allow("store1", 1, 1)
+		require.True(t, ok)
+		_, ok = limiter.Allow("store2", 1, 1)
+		require.True(t, ok)
+		_, ok = limiter.Allow("store1", 1, 1)
+		require.False(t, ok)
+	})
+
+	t.Run("store_overrides", func(t *testing.T) {
+		limiter, _ := newTestStoreWriteLimiter(
+			WriteRates{TuplesPerSecond: 1, ChangesPerSecond: 1},
+			map[string]float64{"bulk": 100},
+			map[string]float64{"bulk": 0},
+		)
+		require.True(t, limiter.Enabled())
+
+		_, ok := limiter.Allow("bulk", 100, 1000)
+		require.True(t, ok)
+		_, ok = limiter.Allow("bulk", 1, 1)
+		require.False(t, ok)
+
+		_, ok = limiter.Allow("other", 2, 2)
+		require.True(t, ok, "a write larger than the bucket is allowed once it is full")
+		_, ok = limiter.Allow("other", 1, 1)
+		require.False(t, ok)
+	})
+
+	t.Run("idle_stores_are_swept", func(t *testing.T) {
+		limiter, now := newTestStoreWriteLimiter(WriteRates{TuplesPerSecond: 10}, nil, nil)
+
+		_, ok := limiter.Allow("store1", 10, 10)
+		require.True(t, ok)
+		require.Len(t, limiter.stores, 1)
+
+		*now = now.Add(writeLimiterSweepInterval)
+		_, ok = limiter.Allow("store2", 1, 1)
+		require.True(t, ok)
+		require.Len(t, limiter.stores, 1)
+		require.Contains(t, limiter.stores, "store2")
+	})
+}
@@ -283,6 +283,39 @@ func FilterInvalidTuples(typesys *typesystem.TypeSystem) storage.TupleKeyFilterF
 	}
 }
 
+// DropTuplesWithUndefinedTypes returns the tuples whose object type and user type are defined in
+// the provided model, along with the number of tuples that were dropped. It is used to ignore,
+// rather than reject, contextual tuples that reference types the model does not know about.
+func DropTuplesWithUndefinedTypes(typesys *typesystem.TypeSystem, tks []*openfgav1.TupleKey) ([]*openfgav1.TupleKey, int) {
+	var kept []*openfgav1.TupleKey
+	for i, tk := range tks {
+		defined := hasTypeDefinition(typesys, tuple.GetType(tk.GetObject())) &&
+			hasTypeDefinition(typesys, tuple.GetType(tk.GetUser()))
+
+		switch {
+		case defined && kept != nil:
+			kept = append(kept, tk)
+		case !defined && kept == nil:
+			kept = make([]*openfgav1.TupleKey, 0, len(tks)-1)
+			kept = append(kept, tks[:i]...)
+		}
+	}
+
+	if kept == nil {
+		return tks, 0
+	}
+	return kept, len(tks) - len(kept)
+}
+
+func hasTypeDefinition(typesys *typesystem.TypeSystem, objectType string) bool {
+	if objectType == "" {
+		// untyped users (e.g. '*' in 1.0 models) are left to the regular validations
+		return true
+	}
+	_, ok := typesys.GetTypeDefinition(objectType)
+	return ok
+}
+
 // ValidateObject validates the provided object string 'type:id' against the provided
 // model. An object is considered valid if it validates against one of the type
 // definitions included in the provided model.
@@ -1279,3 +1279,49 @@ func BenchmarkValidateTupleForRead(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestDropTuplesWithUndefinedTypes(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type document
+			relations
+				define viewer: [user, group#member]`)
+	typesys, err := typesystem.New(model)
+	require.NoError(t, err)
+
+	t.Run("all_types_defined", func(t *testing.T) {
+		tks := []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		}
+		kept, dropped := DropTuplesWithUndefinedTypes(typesys, tks)
+		require.Equal(t, tks, kept)
+		require.Zero(t, dropped)
+	})
+
+	t.Run("undefined_types_dropped", func(t *testing.T) {
+		tks := []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("folder:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:1", "viewer", "team:eng#member"),
+			tuple.NewTupleKey("document:2", "viewer", "user:maria"),
+		}
+		kept, dropped := DropTuplesWithUndefinedTypes(typesys, tks)
+		require.Equal(t, []*openfgav1.TupleKey{tks[0], tks[3]}, kept)
+		require.Equal(t, 2, dropped)
+	})
+
+	t.Run("undefined_relation_kept", func(t *testing.T) {
+		tks := []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "editor", "user:jon"),
+		}
+		kept, dropped := DropTuplesWithUndefinedTypes(typesys, tks)
+		require.Equal(t, tks, kept)
+		require.Zero(t, dropped)
+	})
+}
@@ -0,0 +1,458 @@
+// Package verifier contains the background job that samples the tuples of each store and checks
+// that the datastore and the Check cache still satisfy their invariants.
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// The invariants checked by the Verifier.
+const (
+	// InvariantDuplicateTuple is violated when a tuple is stored more than once.
+	InvariantDuplicateTuple = "duplicate_tuple"
+
+	// InvariantChangelogAgreement is violated when the latest change of a tuple in the changelog
+	// does not match whether the tuple exists.
+	InvariantChangelogAgreement = "changelog_agreement"
+
+	// InvariantCacheAgreement is violated when the Check cache denies a direct relationship that
+	// existed before the cached result was computed.
+	InvariantCacheAgreement = "cache_agreement"
+)
+
+// cacheClockSkew is the margin applied when comparing the time a tuple was written with the time a
+// Check result was cached, as the two clocks can differ.
+const cacheClockSkew = 5 * time.Second
+
+var (
+	tracer = otel.Tracer("internal/verifier")
+
+	violationsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "consistency_verifier_violations_count",
+		Help:      "The total number of storage invariant violations found by the consistency verifier.",
+	}, []string{"invariant"})
+
+	sampledTuplesCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "consistency_verifier_sampled_tuples_count",
+		Help:      "The total number of tuples and changes sampled by the consistency verifier.",
+	})
+)
+
+// Violation describes a tuple that breaks an invariant.
+type Violation struct {
+	StoreID   string
+	Invariant string
+	TupleKey  *openfgav1.TupleKey
+	Detail    string
+}
+
+// Option defines an option that can be used to change the behavior of a Verifier.
+type Option func(*Verifier)
+
+// WithInterval sets the time between two runs of the Verifier.
+func WithInterval(interval time.Duration) Option {
+	return func(v *Verifier) {
+		v.interval = interval
+	}
+}
+
+// WithSampleSize sets the number of tuples, and the number of changes, sampled per store on each run.
+func WithSampleSize(size int) Option {
+	return func(v *Verifier) {
+		v.sampleSize = size
+	}
+}
+
+// WithCheckCache makes the Verifier compare the Check results cached for the sampled tuples with
+// the tuples themselves.
+func WithCheckCache(cache storage.InMemoryCache[any]) Option {
+	return func(v *Verifier) {
+		v.checkCache = cache
+	}
+}
+
+// WithLogger sets the logger of the Verifier.
+func WithLogger(l logger.Logger) Option {
+	return func(v *Verifier) {
+		v.logger = l
+	}
+}
+
+// Verifier periodically samples the tuples of every store and reports the violations of the
+// storage invariants. Consecutive runs sample consecutive pages of tuples, so that every tuple of a
+// store is eventually verified.
+type Verifier struct {
+	datastore  storage.OpenFGADatastore
+	checkCache storage.InMemoryCache[any]
+	interval   time.Duration
+	sampleSize int
+	logger     logger.Logger
+
+	mu     sync.Mutex
+	tokens map[string]string // store => continuation token of the next sample
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// NewVerifier returns a Verifier of the stores of datastore.
+func NewVerifier(datastore storage.OpenFGADatastore, opts ...Option) *Verifier {
+	v := &Verifier{
+		datastore:  datastore,
+		interval:   5 * time.Minute,
+		sampleSize: 100,
+		logger:     logger.NewNoopLogger(),
+		tokens:     make(map[string]string),
+		stop:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Start runs the Verifier in the background every interval until Stop is called.
+func (v *Verifier) Start() {
+	ticker := time.NewTicker(v.interval)
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := v.Run(context.Background()); err != nil {
+					v.logger.Error("consistency verification failed", zap.Error(err))
+				}
+			case <-v.stop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background routine started by Start.
+func (v *Verifier) Stop() {
+	close(v.stop)
+	v.wg.Wait()
+}
+
+// Run verifies a sample of every store once and returns the violations found.
+func (v *Verifier) Run(ctx context.Context) ([]Violation, error) {
+	ctx, span := tracer.Start(ctx, "verifier.Verifier.Run")
+	defer span.End()
+
+	var continuationToken string
+	var violations []Violation
+	var errs error
+	for {
+		stores, token, err := v.datastore.ListStores(ctx, storage.ListStoresOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if err != nil {
+			return violations, errors.Join(errs, err)
+		}
+
+		for _, store := range stores {
+			found, err := v.VerifyStore(ctx, store.GetId())
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("store '%s': %w", store.GetId(), err))
+			}
+			violations = append(violations, found...)
+		}
+
+		if token == "" {
+			break
+		}
+		continuationToken = token
+	}
+
+	span.SetAttributes(attribute.Int("violations", len(violations)))
+	return violations, errs
+}
+
+// VerifyStore verifies the next sample of the tuples of store, and its most recent changes.
+func (v *Verifier) VerifyStore(ctx context.Context, storeID string) ([]Violation, error) {
+	ctx, span := tracer.Start(ctx, "verifier.Verifier.VerifyStore")
+	defer span.End()
+	span.SetAttributes(attribute.String("store_id", storeID))
+
+	tupleViolations, err := v.verifyTuples(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	changeViolations, err := v.verifyChanges(ctx, storeID)
+	if err != nil {
+		return tupleViolations, err
+	}
+
+	violations := append(tupleViolations, changeViolations...)
+	for _, violation := range violations {
+		violationsCounter.WithLabelValues(violation.Invariant).Inc()
+		v.logger.Error("storage invariant violated",
+			zap.String("store_id", violation.StoreID),
+			zap.String("invariant", violation.Invariant),
+			zap.String("tuple_key", tuple.TupleKeyToString(violation.TupleKey)),
+			zap.String("detail", violation.Detail),
+		)
+	}
+	return violations, nil
+}
+
+// verifyTuples checks that the tuples of the next sample are not duplicated and that the Check
+// cache agrees with them.
+func (v *Verifier) verifyTuples(ctx context.Context, storeID string) ([]Violation, error) {
+	v.mu.Lock()
+	continuationToken := v.tokens[storeID]
+	v.mu.Unlock()
+
+	tuples, token, err := v.datastore.ReadPage(ctx, storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+		Pagination:  storage.NewPaginationOptions(int32(v.sampleSize), continuationToken),
+		Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	v.tokens[storeID] = token
+	v.mu.Unlock()
+	sampledTuplesCounter.Add(float64(len(tuples)))
+
+	var typesys *typesystem.TypeSystem
+	if v.checkCache != nil {
+		model, err := v.datastore.FindLatestAuthorizationModel(ctx, storeID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		if model != nil && typesystem.IsSchemaVersionSupported(model.GetSchemaVersion()) {
+			typesys, err = typesystem.New(model)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var violations []Violation
+	for _, t := range tuples {
+		tk := t.GetKey()
+
+		count, err := v.countTuples(ctx, storeID, tk)
+		if err != nil {
+			return nil, err
+		}
+		if count > 1 {
+			violations = append(violations, Violation{
+				StoreID:   storeID,
+				Invariant: InvariantDuplicateTuple,
+				TupleKey:  tk,
+				Detail:    fmt.Sprintf("the tuple is stored %d times", count),
+			})
+		}
+
+		if typesys != nil {
+			if violation, ok := v.verifyCachedCheck(storeID, typesys, t); ok {
+				violations = append(violations, violation)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// countTuples returns the number of stored tuples with the same object, relation and user as tk.
+func (v *Verifier) countTuples(ctx context.Context, storeID string, tk *openfgav1.TupleKey) (int, error) {
+	iter, err := v.datastore.Read(ctx, storeID, storage.ReadFilter{
+		Object:   tk.GetObject(),
+		Relation: tk.GetRelation(),
+		User:     tk.GetUser(),
+	}, storage.ReadOptions{
+		Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Stop()
+
+	var count int
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return count, nil
+			}
+			return 0, err
+		}
+		if tuple.TupleKeyToString(t.GetKey()) == tuple.TupleKeyToString(tk) {
+			count++
+		}
+	}
+}
+
+// verifyCachedCheck reports a violation when the Check result cached for the relationship of an
+// unconditional tuple on a directly assignable relation denies it, although the result was cached
+// after the tuple was written.
+func (v *Verifier) verifyCachedCheck(storeID string, typesys *typesystem.TypeSystem, t *openfgav1.Tuple) (Violation, bool) {
+	tk := t.GetKey()
+	if tk.GetCondition().GetName() != "" || tuple.IsObjectRelation(tk.GetUser()) || tuple.IsTypedWildcard(tk.GetUser()) {
+		return Violation{}, false
+	}
+
+	objectType := tuple.GetType(tk.GetObject())
+	directlyRelated, err := typesys.IsDirectlyRelated(
+		typesystem.DirectRelationReference(objectType, tk.GetRelation()),
+		typesystem.DirectRelationReference(tuple.GetType(tk.GetUser()), ""),
+	)
+	if err != nil || !directlyRelated {
+		return Violation{}, false
+	}
+	relation, err := typesys.GetRelation(objectType, tk.GetRelation())
+	if err != nil || !isUnionOfThis(relation.GetRewrite()) {
+		return Violation{}, false
+	}
+
+	req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:              storeID,
+		AuthorizationModelID: typesys.GetAuthorizationModelID(),
+		TupleKey:             tuple.NewTupleKey(tk.GetObject(), tk.GetRelation(), tk.GetUser()),
+	})
+	if err != nil {
+		return Violation{}, false
+	}
+
+	cached, ok := v.checkCache.Get(graph.BuildCacheKey(*req)).(*graph.CheckResponseCacheEntry)
+	if !ok || cached.CheckResponse.GetAllowed() {
+		return Violation{}, false
+	}
+	if !cached.LastModified.After(t.GetTimestamp().AsTime().Add(cacheClockSkew)) {
+		return Violation{}, false
+	}
+
+	return Violation{
+		StoreID:   storeID,
+		Invariant: InvariantCacheAgreement,
+		TupleKey:  tk,
+		Detail:    fmt.Sprintf("the Check cache denies the relationship in model '%s'", typesys.GetAuthorizationModelID()),
+	}, true
+}
+
+// isUnionOfThis reports whether the rewrite grants the relation to every directly related user.
+func isUnionOfThis(rewrite *openfgav1.Userset) bool {
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		return true
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			if isUnionOfThis(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifyChanges checks that the latest change of each tuple among the most recent changes of the
+// store agrees with whether the tuple exists.
+func (v *Verifier) verifyChanges(ctx context.Context, storeID string) ([]Violation, error) {
+	latest, err := v.latestChanges(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	sampledTuplesCounter.Add(float64(len(latest)))
+
+	var mismatches []*openfgav1.TupleChange
+	for _, change := range latest {
+		tk := change.GetTupleKey()
+		_, err := v.datastore.ReadUserTuple(ctx, storeID, storage.ReadUserTupleFilter{
+			Object:   tk.GetObject(),
+			Relation: tk.GetRelation(),
+			User:     tk.GetUser(),
+		}, storage.ReadUserTupleOptions{
+			Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+		})
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+
+		exists := err == nil
+		if exists != (change.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_WRITE) {
+			mismatches = append(mismatches, change)
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil, nil
+	}
+
+	// The tuples may have changed since the changes were read, so a mismatch is only reported when
+	// the latest change of the tuple is still the same.
+	current, err := v.latestChanges(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, change := range mismatches {
+		key := tuple.TupleKeyToString(change.GetTupleKey())
+		if c, ok := current[key]; !ok || !c.GetTimestamp().AsTime().Equal(change.GetTimestamp().AsTime()) {
+			continue
+		}
+
+		detail := "the latest change writes the tuple but the tuple does not exist"
+		if change.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_DELETE {
+			detail = "the latest change deletes the tuple but the tuple exists"
+		}
+		violations = append(violations, Violation{
+			StoreID:   storeID,
+			Invariant: InvariantChangelogAgreement,
+			TupleKey:  tuple.NewTupleKey(change.GetTupleKey().GetObject(), change.GetTupleKey().GetRelation(), change.GetTupleKey().GetUser()),
+			Detail:    detail,
+		})
+	}
+	return violations, nil
+}
+
+// latestChanges returns, for each tuple among the most recent changes of the store, its latest change.
+func (v *Verifier) latestChanges(ctx context.Context, storeID string) (map[string]*openfgav1.TupleChange, error) {
+	changes, _, err := v.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(int32(v.sampleSize), ""),
+		SortDesc:   true,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	latest := make(map[string]*openfgav1.TupleChange, len(changes))
+	for _, change := range changes {
+		key := tuple.TupleKeyToString(change.GetTupleKey())
+		if _, ok := latest[key]; !ok {
+			latest[key] = change
+		}
+	}
+	return latest, nil
+}
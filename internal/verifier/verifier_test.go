@@ -0,0 +1,163 @@
+package verifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const model = `
+	model
+		schema 1.1
+	type user
+	type document
+		relations
+			define viewer: [user]`
+
+// corruptedDatastore returns every tuple of duplicated twice from Read, and reports the tuples of
+// missing as not found from ReadUserTuple.
+type corruptedDatastore struct {
+	storage.OpenFGADatastore
+	duplicated *openfgav1.TupleKey
+	missing    *openfgav1.TupleKey
+}
+
+func (c *corruptedDatastore) Read(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadOptions) (storage.TupleIterator, error) {
+	if c.duplicated != nil && filter.Object == c.duplicated.GetObject() && filter.Relation == c.duplicated.GetRelation() && filter.User == c.duplicated.GetUser() {
+		t := &openfgav1.Tuple{Key: c.duplicated}
+		return storage.NewStaticTupleIterator([]*openfgav1.Tuple{t, t}), nil
+	}
+	return c.OpenFGADatastore.Read(ctx, store, filter, options)
+}
+
+func (c *corruptedDatastore) ReadUserTuple(ctx context.Context, store string, filter storage.ReadUserTupleFilter, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	if c.missing != nil && filter.Object == c.missing.GetObject() && filter.Relation == c.missing.GetRelation() && filter.User == c.missing.GetUser() {
+		return nil, storage.ErrNotFound
+	}
+	return c.OpenFGADatastore.ReadUserTuple(ctx, store, filter, options)
+}
+
+func setup(t *testing.T) (storage.OpenFGADatastore, string, string) {
+	t.Helper()
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "verifier"})
+	require.NoError(t, err)
+
+	authzModel := testutils.MustTransformDSLToProtoWithID(model)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, authzModel))
+
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+	}))
+	require.NoError(t, ds.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+		tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:3", "viewer", "user:anne")),
+	}, nil))
+
+	return ds, storeID, authzModel.GetId()
+}
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("consistent", func(t *testing.T) {
+		ds, _, _ := setup(t)
+
+		violations, err := NewVerifier(ds).Run(ctx)
+		require.NoError(t, err)
+		require.Empty(t, violations)
+	})
+
+	t.Run("duplicate_tuple", func(t *testing.T) {
+		ds, storeID, _ := setup(t)
+		duplicated := tuple.NewTupleKey("document:2", "viewer", "user:anne")
+
+		violations, err := NewVerifier(&corruptedDatastore{OpenFGADatastore: ds, duplicated: duplicated}).VerifyStore(ctx, storeID)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		require.Equal(t, InvariantDuplicateTuple, violations[0].Invariant)
+		require.Equal(t, tuple.TupleKeyToString(duplicated), tuple.TupleKeyToString(violations[0].TupleKey))
+	})
+
+	t.Run("changelog_agreement", func(t *testing.T) {
+		ds, storeID, _ := setup(t)
+		missing := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+		violations, err := NewVerifier(&corruptedDatastore{OpenFGADatastore: ds, missing: missing}).VerifyStore(ctx, storeID)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		require.Equal(t, InvariantChangelogAgreement, violations[0].Invariant)
+		require.Equal(t, tuple.TupleKeyToString(missing), tuple.TupleKeyToString(violations[0].TupleKey))
+	})
+
+	t.Run("cache_agreement", func(t *testing.T) {
+		ds, storeID, modelID := setup(t)
+
+		cache, err := storage.NewInMemoryLRUCache[any]()
+		require.NoError(t, err)
+		t.Cleanup(cache.Stop)
+
+		cacheCheck := func(object string, allowed bool, lastModified time.Time) {
+			req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+				StoreID:              storeID,
+				AuthorizationModelID: modelID,
+				TupleKey:             tuple.NewTupleKey(object, "viewer", "user:anne"),
+			})
+			require.NoError(t, err)
+			cache.Set(graph.BuildCacheKey(*req), &graph.CheckResponseCacheEntry{
+				LastModified:  lastModified,
+				CheckResponse: &graph.ResolveCheckResponse{Allowed: allowed},
+			}, time.Minute)
+		}
+		cacheCheck("document:1", false, time.Now().Add(time.Minute))
+		cacheCheck("document:2", false, time.Now().Add(-time.Minute)) // cached before the tuple was written
+
+		violations, err := NewVerifier(ds, WithCheckCache(cache)).VerifyStore(ctx, storeID)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		require.Equal(t, InvariantCacheAgreement, violations[0].Invariant)
+		require.Equal(t, "document:1", violations[0].TupleKey.GetObject())
+	})
+
+	t.Run("samples_consecutive_pages", func(t *testing.T) {
+		ds, storeID, _ := setup(t)
+		duplicated := tuple.NewTupleKey("document:2", "viewer", "user:anne")
+		v := NewVerifier(&corruptedDatastore{OpenFGADatastore: ds, duplicated: duplicated}, WithSampleSize(1))
+
+		var found int
+		for i := 0; i < 2; i++ {
+			violations, err := v.VerifyStore(ctx, storeID)
+			require.NoError(t, err)
+			for _, violation := range violations {
+				if violation.Invariant == InvariantDuplicateTuple {
+					found++
+				}
+			}
+		}
+		require.Equal(t, 1, found)
+	})
+
+	t.Run("background", func(t *testing.T) {
+		ds, _, _ := setup(t)
+		v := NewVerifier(ds, WithInterval(time.Millisecond))
+		v.Start()
+		time.Sleep(10 * time.Millisecond)
+		v.Stop()
+	})
+}
@@ -17,6 +17,10 @@ type AuthClaims struct {
 	Subject  string
 	Scopes   map[string]bool
 	ClientID string
+
+	// Claims are all the validated claims of the token of the principal, e.g. of a JWT, or nil if
+	// the principal was authenticated without a token.
+	Claims map[string]any
 }
 
 // ContextWithAuthClaims creates a copy of the parent context with the provided AuthClaims.
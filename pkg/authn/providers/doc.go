@@ -0,0 +1,4 @@
+// Package providers contains the registry of the third-party authentication providers, which a
+// custom main registers so that the run command authenticates the requests with them, e.g. with an
+// internal authentication service or with SPIFFE identities, without forking OpenFGA.
+package providers
@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/openfga/openfga/internal/authn"
+	"github.com/openfga/openfga/pkg/authclaims"
+	"github.com/openfga/openfga/pkg/server/config"
+)
+
+var (
+	// ErrUnauthenticated is the error that the authenticators return for the requests whose
+	// credentials are invalid.
+	ErrUnauthenticated = authn.ErrUnauthenticated
+
+	// ErrMissingBearerToken is the error that the authenticators return for the requests without
+	// credentials.
+	ErrMissingBearerToken = authn.ErrMissingBearerToken
+)
+
+// builtins are the authentication methods served by OpenFGA itself, which cannot be registered.
+var builtins = []string{"none", "preshared", "oidc"}
+
+// Authenticator authenticates the requests to the server.
+type Authenticator interface {
+	// Authenticate returns the claims of the subject of the request in ctx if it is authenticated,
+	// or an error otherwise, e.g. ErrUnauthenticated. The claims are available to the handlers of
+	// the request with authclaims.AuthClaimsFromContext.
+	Authenticate(ctx context.Context) (*authclaims.AuthClaims, error)
+
+	// Close releases the resources of the authenticator when the server stops.
+	Close()
+}
+
+// NewFunc constructs the authenticator with the authentication settings of the configuration of
+// the server in cfg, e.g. its audience.
+type NewFunc func(cfg *config.AuthnConfig) (Authenticator, error)
+
+// Provider is a third-party authentication provider.
+type Provider struct {
+	// New constructs the authenticator of the provider. It is required.
+	New NewFunc
+}
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]Provider)
+)
+
+// Register makes the provider available as the authentication method name. It is meant to be
+// called from the init function of the provider package, or from a custom main before the run
+// command is executed, e.g.
+//
+//	func main() {
+//		providers.Register("spiffe", providers.Provider{New: spiffe.NewAuthenticator})
+//
+//		rootCmd := cmd.NewRootCommand()
+//		rootCmd.AddCommand(run.NewRunCommand())
+//		...
+//	}
+//
+// It panics if the name is the one of a built-in method or of a provider already registered, or if
+// the provider has no New function.
+func Register(name string, provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider.New == nil {
+		panic(fmt.Sprintf("providers: Register of '%s' without a New function", name))
+	}
+	if slices.Contains(builtins, name) {
+		panic(fmt.Sprintf("providers: Register of the built-in method '%s'", name))
+	}
+	if _, ok := providers[name]; ok {
+		panic(fmt.Sprintf("providers: Register called twice for provider '%s'", name))
+	}
+	providers[name] = provider
+}
+
+// Lookup returns the provider registered as name, if any.
+func Lookup(name string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// Names returns the sorted names of the registered providers.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/internal/authn"
+	"github.com/openfga/openfga/pkg/server/config"
+)
+
+func newNoop(*config.AuthnConfig) (Authenticator, error) {
+	return authn.NoopAuthenticator{}, nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("lookup_registered_provider", func(t *testing.T) {
+		Register("test-registered", Provider{New: newNoop})
+
+		provider, ok := Lookup("test-registered")
+		require.True(t, ok)
+
+		authenticator, err := provider.New(&config.AuthnConfig{})
+		require.NoError(t, err)
+		t.Cleanup(authenticator.Close)
+
+		_, err = authenticator.Authenticate(context.Background())
+		require.NoError(t, err)
+		require.Contains(t, Names(), "test-registered")
+	})
+
+	t.Run("lookup_unknown_provider", func(t *testing.T) {
+		_, ok := Lookup("test-unknown")
+		require.False(t, ok)
+	})
+
+	t.Run("register_twice_panics", func(t *testing.T) {
+		Register("test-twice", Provider{New: newNoop})
+		require.Panics(t, func() {
+			Register("test-twice", Provider{New: newNoop})
+		})
+	})
+
+	t.Run("register_builtin_panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			Register("oidc", Provider{New: newNoop})
+		})
+	})
+
+	t.Run("register_without_new_panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			Register("test-without-new", Provider{})
+		})
+		_, ok := Lookup("test-without-new")
+		require.False(t, ok)
+	})
+}
+
+func TestNames(t *testing.T) {
+	Register("test-names-b", Provider{New: newNoop})
+	Register("test-names-a", Provider{New: newNoop})
+
+	names := Names()
+	require.IsNonDecreasing(t, names)
+	require.Subset(t, names, []string{"test-names-a", "test-names-b"})
+}
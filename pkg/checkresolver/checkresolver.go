@@ -0,0 +1,71 @@
+package checkresolver
+
+import (
+	"github.com/openfga/openfga/internal/graph"
+)
+
+type (
+	// CheckResolver resolves a Check sub-problem, or delegates it to the next resolver of the chain.
+	// The chain is circular: the last resolver delegates the nested sub-problems to the first one.
+	CheckResolver = graph.CheckResolver
+
+	// Request is a Check sub-problem.
+	Request = graph.ResolveCheckRequest
+
+	// Response is the result of a Check sub-problem.
+	Response = graph.ResolveCheckResponse
+
+	// ResponseMetadata is the metadata of the resolution of a Check sub-problem.
+	ResponseMetadata = graph.ResolveCheckResponseMetadata
+
+	// Position is a point of the chain where custom resolvers are inserted.
+	Position = graph.CheckResolverPosition
+
+	// Factory constructs a custom resolver. The chain is built for every request, so it must
+	// return a new resolver every time.
+	Factory = graph.CheckResolverFactory
+
+	// Custom is a custom resolver inserted in the chain at Position. The custom resolvers at the
+	// same position are inserted in order.
+	Custom = graph.CustomCheckResolver
+
+	// MiddlewareFunc resolves a Check sub-problem, e.g. by short-circuiting it, or delegates it to
+	// next.
+	MiddlewareFunc = graph.CheckResolverMiddlewareFunc
+)
+
+// The positions of the custom resolvers in the chain, which is, in order:
+//
+//	PositionFirst, hot paths, cluster dispatch, cache, bulkheads,
+//	PositionAfterCache, cluster singleflight, dispatch throttling, remote dispatch,
+//	PositionLast, local checker.
+const (
+	// PositionFirst is before all the built-in resolvers, so that the custom resolvers see every
+	// Check sub-problem, including the cached ones.
+	PositionFirst = graph.CheckResolverPositionFirst
+
+	// PositionAfterCache is after the cache, so that the custom resolvers only see the
+	// sub-problems that are not cached, and their results are cached.
+	PositionAfterCache = graph.CheckResolverPositionAfterCache
+
+	// PositionLast is right before the local checker, which resolves the sub-problems against the
+	// datastore.
+	PositionLast = graph.CheckResolverPositionLast
+)
+
+// Middleware returns a Factory of resolvers that resolve the Check sub-problems with fn, e.g.
+//
+//	allowlist := checkresolver.Middleware(func(ctx context.Context, req *checkresolver.Request, next checkresolver.CheckResolver) (*checkresolver.Response, error) {
+//		if req.GetTupleKey().GetUser() == "user:admin" {
+//			return &checkresolver.Response{Allowed: true}, nil
+//		}
+//		return next.ResolveCheck(ctx, req)
+//	})
+//
+//	svr := server.MustNewServerWithOpts(
+//		server.WithCheckResolvers(checkresolver.Custom{Position: checkresolver.PositionFirst, New: allowlist}),
+//		...
+//	)
+func Middleware(fn MiddlewareFunc) Factory {
+	return graph.CheckResolverMiddleware(fn)
+}
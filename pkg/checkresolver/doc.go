@@ -0,0 +1,4 @@
+// Package checkresolver exposes the chain of resolvers of the Check sub-problems, so that an
+// embedder can insert custom resolvers at a defined point of the chain, e.g. an allowlist that
+// answers the Checks of some users without resolving them.
+package checkresolver
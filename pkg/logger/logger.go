@@ -107,6 +107,7 @@ type OptionsLogger struct {
 	level           string
 	timestampFormat string
 	outputPaths     []string
+	zapOptions      []zap.Option
 }
 
 type OptionLogger func(ol *OptionsLogger)
@@ -147,6 +148,14 @@ func WithOutputPaths(paths ...string) OptionLogger {
 	}
 }
 
+// WithZapOptions sets options of the underlying zap logger, e.g. zap.WrapCore to filter the
+// fields of the entries.
+func WithZapOptions(options ...zap.Option) OptionLogger {
+	return func(ol *OptionsLogger) {
+		ol.zapOptions = append(ol.zapOptions, options...)
+	}
+}
+
 func NewLogger(options ...OptionLogger) (*ZapLogger, error) {
 	logOptions := &OptionsLogger{
 		level:           "info",
@@ -187,7 +196,7 @@ func NewLogger(options ...OptionLogger) (*ZapLogger, error) {
 		}
 	}
 
-	log, err := cfg.Build()
+	log, err := cfg.Build(logOptions.zapOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -199,11 +208,11 @@ func NewLogger(options ...OptionLogger) (*ZapLogger, error) {
 	return &ZapLogger{log}, nil
 }
 
-func MustNewLogger(logFormat, logLevel, logTimestampFormat string) *ZapLogger {
-	logger, err := NewLogger(
+func MustNewLogger(logFormat, logLevel, logTimestampFormat string, options ...OptionLogger) *ZapLogger {
+	logger, err := NewLogger(append([]OptionLogger{
 		WithFormat(logFormat),
 		WithLevel(logLevel),
-		WithTimestampFormat(logTimestampFormat))
+		WithTimestampFormat(logTimestampFormat)}, options...)...)
 	if err != nil {
 		panic(err)
 	}
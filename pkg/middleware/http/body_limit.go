@@ -0,0 +1,45 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/errors"
+)
+
+// NewMaxRequestBodyHandler returns a handler that rejects the requests whose body is larger than
+// maxBytes with a 413 status, before they are decoded. The bodies of unknown length are read up to
+// the limit to find out.
+func NewMaxRequestBodyHandler(next http.Handler, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			writeRequestBodyTooLarge(w, r, maxBytes)
+			return
+		}
+
+		if r.ContentLength < 0 && r.Body != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if int64(len(body)) > maxBytes {
+				writeRequestBodyTooLarge(w, r, maxBytes)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeRequestBodyTooLarge(w http.ResponseWriter, r *http.Request, maxBytes int64) {
+	err := errors.NewEncodedError(int32(openfgav1.ErrorCode_exceeded_entity_limit), fmt.Sprintf("the request body exceeds the limit of %d bytes", maxBytes))
+	err.HTTPStatusCode = http.StatusRequestEntityTooLarge
+	CustomHTTPErrorHandler(r.Context(), w, r, err)
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRequestBodyHandler(t *testing.T) {
+	handler := NewMaxRequestBodyHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		_, _ = w.Write(body)
+	}), 8)
+
+	tests := map[string]struct {
+		body          string
+		contentLength int64
+		status        int
+	}{
+		`under_the_limit`:             {body: "12345678", contentLength: 8, status: http.StatusOK},
+		`over_the_limit`:              {body: "123456789", contentLength: 9, status: http.StatusRequestEntityTooLarge},
+		`unknown_length_under`:        {body: "1234", contentLength: -1, status: http.StatusOK},
+		`unknown_length_over`:         {body: "123456789", contentLength: -1, status: http.StatusRequestEntityTooLarge},
+		`unknown_length_at_the_limit`: {body: "12345678", contentLength: -1, status: http.StatusOK},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/stores/1/check", strings.NewReader(test.body))
+			req.ContentLength = test.contentLength
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, test.status, rec.Code)
+			if test.status == http.StatusOK {
+				require.Equal(t, test.body, rec.Body.String())
+			} else {
+				require.JSONEq(t, `{"code":"exceeded_entity_limit","message":"the request body exceeds the limit of 8 bytes"}`, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package http
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/openfga/openfga/internal/compression"
+)
+
+const (
+	acceptEncodingHeader  = "Accept-Encoding"
+	contentEncodingHeader = "Content-Encoding"
+	contentLengthHeader   = "Content-Length"
+	varyHeader            = "Vary"
+)
+
+// CompressionOption configures the handler returned by NewCompressionHandler.
+type CompressionOption func(*compressionHandler)
+
+// WithCompressionAlgorithms sets the algorithms that can be negotiated, in order of server
+// preference. Unsupported algorithms are ignored.
+func WithCompressionAlgorithms(algorithms ...string) CompressionOption {
+	return func(h *compressionHandler) {
+		h.algorithms = algorithms
+	}
+}
+
+// WithCompressionMinSizeBytes sets the minimum size of a response body before it is compressed.
+// Responses that are flushed before the end of the handler (e.g. streamed responses) are always
+// compressed.
+func WithCompressionMinSizeBytes(size int) CompressionOption {
+	return func(h *compressionHandler) {
+		h.minSizeBytes = size
+	}
+}
+
+type compressionHandler struct {
+	next         http.Handler
+	algorithms   []string
+	minSizeBytes int
+}
+
+// NewCompressionHandler returns a handler that compresses response bodies using the algorithm
+// negotiated through the request's Accept-Encoding header.
+func NewCompressionHandler(next http.Handler, opts ...CompressionOption) http.Handler {
+	h := &compressionHandler{
+		next:       next,
+		algorithms: []string{compression.Gzip, compression.Zstd},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *compressionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add(varyHeader, acceptEncodingHeader)
+
+	algorithm := negotiateEncoding(r.Header.Get(acceptEncodingHeader), h.algorithms)
+	if algorithm == "" || r.Method == http.MethodHead {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	cw := &compressResponseWriter{
+		ResponseWriter: w,
+		algorithm:      algorithm,
+		minSizeBytes:   h.minSizeBytes,
+		statusCode:     http.StatusOK,
+	}
+	defer cw.close()
+
+	h.next.ServeHTTP(cw, r)
+}
+
+// negotiateEncoding returns the first of the supported algorithms accepted by the client with
+// a non-zero quality value, or an empty string if none is acceptable.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		allowed := true
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				allowed = err == nil && q > 0
+			}
+		}
+
+		if name == "*" {
+			wildcard = allowed
+			continue
+		}
+		accepted[name] = allowed
+	}
+
+	for _, algorithm := range supported {
+		if compression.Validate([]string{algorithm}) != nil {
+			continue
+		}
+		allowed, listed := accepted[algorithm]
+		if allowed || (!listed && wildcard) {
+			return algorithm
+		}
+	}
+	return ""
+}
+
+// compressResponseWriter buffers the beginning of the response until it is known whether the
+// body is large enough to be worth compressing.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	algorithm    string
+	minSizeBytes int
+
+	statusCode    int
+	headerWritten bool
+	buf           []byte
+	writer        compression.Writer
+	passthrough   bool
+}
+
+var (
+	_ http.Flusher  = (*compressResponseWriter)(nil)
+	_ http.Hijacker = (*compressResponseWriter)(nil)
+)
+
+func (cw *compressResponseWriter) WriteHeader(statusCode int) {
+	if cw.headerWritten {
+		return
+	}
+	cw.headerWritten = true
+	cw.statusCode = statusCode
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.headerWritten {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(b)
+	}
+	if cw.writer != nil {
+		return cw.writer.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.minSizeBytes {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (cw *compressResponseWriter) Flush() {
+	if cw.writer == nil && !cw.passthrough {
+		if !cw.headerWritten {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if err := cw.start(true); err != nil {
+			return
+		}
+	}
+	if cw.writer != nil {
+		_ = cw.writer.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(cw.ResponseWriter).Hijack()
+}
+
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// start writes the response header and any buffered body, compressing it if allowed.
+func (cw *compressResponseWriter) start(compress bool) error {
+	header := cw.ResponseWriter.Header()
+	if !compress ||
+		header.Get(contentEncodingHeader) != "" ||
+		cw.statusCode < http.StatusOK ||
+		cw.statusCode == http.StatusNoContent ||
+		cw.statusCode == http.StatusNotModified {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(cw.statusCode)
+		_, err := cw.ResponseWriter.Write(cw.buf)
+		cw.buf = nil
+		return err
+	}
+
+	// the writer is obtained before the header is written, so that the body is written as is
+	// without a Content-Encoding if it can't be compressed
+	writer, err := compression.GetWriter(cw.algorithm, cw.ResponseWriter)
+	if err != nil {
+		return cw.start(false)
+	}
+	cw.writer = writer
+
+	header.Set(contentEncodingHeader, cw.algorithm)
+	header.Del(contentLengthHeader)
+	cw.ResponseWriter.WriteHeader(cw.statusCode)
+
+	_, err = cw.writer.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+func (cw *compressResponseWriter) close() {
+	if cw.writer == nil && !cw.passthrough {
+		if !cw.headerWritten && len(cw.buf) == 0 {
+			// Nothing was written by the handler, let net/http write the default response.
+			return
+		}
+		// The body is smaller than the minimum size and is written as is.
+		_ = cw.start(false)
+		return
+	}
+	if cw.writer != nil {
+		_ = cw.writer.Close()
+		compression.PutWriter(cw.algorithm, cw.writer)
+		cw.writer = nil
+	}
+}
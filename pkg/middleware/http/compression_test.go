@@ -0,0 +1,175 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/internal/compression"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{compression.Gzip, compression.Zstd}
+
+	tests := map[string]struct {
+		acceptEncoding string
+		supported      []string
+		expected       string
+	}{
+		`empty`:                    {acceptEncoding: "", supported: supported, expected: ""},
+		`identity_only`:            {acceptEncoding: "identity", supported: supported, expected: ""},
+		`gzip`:                     {acceptEncoding: "gzip", supported: supported, expected: compression.Gzip},
+		`zstd`:                     {acceptEncoding: "zstd", supported: supported, expected: compression.Zstd},
+		`server_preference_wins`:   {acceptEncoding: "zstd, gzip", supported: supported, expected: compression.Gzip},
+		`q_zero_is_rejected`:       {acceptEncoding: "gzip;q=0, zstd", supported: supported, expected: compression.Zstd},
+		`wildcard`:                 {acceptEncoding: "*", supported: supported, expected: compression.Gzip},
+		`wildcard_with_exclusions`: {acceptEncoding: "*, gzip;q=0", supported: supported, expected: compression.Zstd},
+		`case_insensitive`:         {acceptEncoding: "GZIP", supported: supported, expected: compression.Gzip},
+		`not_configured`:           {acceptEncoding: "gzip", supported: []string{compression.Zstd}, expected: ""},
+		`unknown_algorithm_ignore`: {acceptEncoding: "br", supported: []string{"br"}, expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, negotiateEncoding(test.acceptEncoding, test.supported))
+		})
+	}
+}
+
+func TestCompressionHandler(t *testing.T) {
+	largeBody := strings.Repeat(`{"object":"document:1"}`, 100)
+
+	handler := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, r.URL.Query().Get("body"))
+	}), WithCompressionMinSizeBytes(1024))
+
+	serve := func(acceptEncoding, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		q := req.URL.Query()
+		q.Set("body", body)
+		req.URL.RawQuery = q.Encode()
+		if acceptEncoding != "" {
+			req.Header.Set(acceptEncodingHeader, acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	t.Run("gzip", func(t *testing.T) {
+		res := serve("gzip", largeBody)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, compression.Gzip, res.Header.Get(contentEncodingHeader))
+		require.Equal(t, acceptEncodingHeader, res.Header.Get(varyHeader))
+
+		reader, err := gzip.NewReader(res.Body)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, largeBody, string(data))
+	})
+
+	t.Run("zstd", func(t *testing.T) {
+		res := serve("zstd", largeBody)
+		defer res.Body.Close()
+
+		require.Equal(t, compression.Zstd, res.Header.Get(contentEncodingHeader))
+
+		reader, err := zstd.NewReader(res.Body)
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, largeBody, string(data))
+	})
+
+	t.Run("small_body_is_not_compressed", func(t *testing.T) {
+		res := serve("gzip", "small")
+		defer res.Body.Close()
+
+		require.Empty(t, res.Header.Get(contentEncodingHeader))
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "small", string(data))
+	})
+
+	t.Run("no_accept_encoding", func(t *testing.T) {
+		res := serve("", largeBody)
+		defer res.Body.Close()
+
+		require.Empty(t, res.Header.Get(contentEncodingHeader))
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, largeBody, string(data))
+	})
+}
+
+func TestCompressionHandlerPreservesStatusCode(t *testing.T) {
+	handler := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, strings.Repeat("a", 2048))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(acceptEncodingHeader, "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, compression.Gzip, res.Header.Get(contentEncodingHeader))
+}
+
+func TestCompressionHandlerFlushCompressesStreams(t *testing.T) {
+	handler := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(w, `{"result":{"object":"document:1"}}`+"\n")
+			w.(http.Flusher).Flush()
+		}
+	}), WithCompressionMinSizeBytes(1024))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(acceptEncodingHeader, "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	require.True(t, w.Flushed)
+	require.Equal(t, compression.Gzip, res.Header.Get(contentEncodingHeader))
+
+	reader, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, 3, strings.Count(string(data), "document:1"))
+}
+
+func TestCompressResponseWriterUnsupportedAlgorithm(t *testing.T) {
+	w := httptest.NewRecorder()
+	cw := &compressResponseWriter{
+		ResponseWriter: w,
+		algorithm:      "unknown",
+		statusCode:     http.StatusOK,
+	}
+	_, err := io.WriteString(cw, "body")
+	require.NoError(t, err)
+	cw.close()
+
+	res := w.Result()
+	defer res.Body.Close()
+	require.Empty(t, res.Header.Get(contentEncodingHeader))
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "body", string(data))
+}
@@ -42,6 +42,55 @@ func HTTPResponseModifier(ctx context.Context, w http.ResponseWriter, p proto.Me
 	return nil
 }
 
+// NewBodylessStatusHandler returns a handler that discards the response bodies of the statuses that
+// must not have one, such as the 304 Not Modified set with XHttpCode, whose response message the
+// gateway would otherwise marshal and write.
+func NewBodylessStatusHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&bodylessStatusResponseWriter{ResponseWriter: w}, r)
+	})
+}
+
+type bodylessStatusResponseWriter struct {
+	http.ResponseWriter
+	headerWritten bool
+	discard       bool
+}
+
+var _ http.Flusher = (*bodylessStatusResponseWriter)(nil)
+
+func (w *bodylessStatusResponseWriter) WriteHeader(statusCode int) {
+	if !w.headerWritten {
+		w.headerWritten = true
+		w.discard = statusCode == http.StatusNoContent || statusCode == http.StatusNotModified ||
+			(statusCode >= http.StatusContinue && statusCode < http.StatusOK)
+		if w.discard {
+			w.Header().Del("Content-Length")
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *bodylessStatusResponseWriter) Write(b []byte) (int, error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodylessStatusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *bodylessStatusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func requestAcceptsTrailers(req *http.Request) bool {
 	te := req.Header.Get("TE")
 	return strings.Contains(strings.ToLower(te), "trailers")
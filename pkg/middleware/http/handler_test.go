@@ -74,3 +74,44 @@ func TestCustomHTTPErrorHandlerSpecialEncoding(t *testing.T) {
 	expectedData := "{\"code\":\"assertions_too_many_items\",\"message\":\"invalid character '<' looking for beginning of value,\"}"
 	require.Equal(t, expectedData, strings.TrimSpace(string(data)))
 }
+
+func TestBodylessStatusHandler(t *testing.T) {
+	mux := runtime.NewServeMux(runtime.WithForwardResponseOption(HTTPResponseModifier))
+	handler := NewBodylessStatusHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := r.URL.Query().Get("code")
+		md := runtime.ServerMetadata{HeaderMD: metadata.Pairs(XHttpCode, code)}
+		ctx := runtime.NewServerMetadataContext(r.Context(), md)
+		_, marshaler := runtime.MarshalerForRequest(mux, r)
+		runtime.ForwardResponseMessage(ctx, mux, marshaler, w, r, &openfgav1.ReadAuthorizationModelResponse{
+			AuthorizationModel: &openfgav1.AuthorizationModel{Id: "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		}, mux.GetForwardResponseOptions()...)
+	}))
+
+	do := func(code string) (*http.Response, string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?code="+code, nil))
+		res := w.Result()
+		t.Cleanup(func() { res.Body.Close() })
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(data)
+	}
+
+	t.Run("not_modified", func(t *testing.T) {
+		res, body := do("304")
+		require.Equal(t, http.StatusNotModified, res.StatusCode)
+		require.Empty(t, body)
+	})
+
+	t.Run("no_content", func(t *testing.T) {
+		res, body := do("204")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.Empty(t, body)
+	})
+
+	t.Run("created", func(t *testing.T) {
+		res, body := do("201")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.Contains(t, body, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	})
+}
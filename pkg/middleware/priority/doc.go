@@ -0,0 +1,3 @@
+// Package priority contains middleware to schedule the requests by priority class when the server
+// is saturated, with weighted fair queueing between the stores of a class.
+package priority
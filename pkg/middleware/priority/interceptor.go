@@ -0,0 +1,149 @@
+package priority
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+var (
+	queuedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "priority_scheduler_queued_requests",
+		Help:      "The number of requests waiting for the priority scheduler, by priority class.",
+	}, []string{"class"})
+
+	queueDurationHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "priority_scheduler_queue_duration_ms",
+		Help:                            "The time in milliseconds that the requests waited for the priority scheduler, by priority class.",
+		Buckets:                         []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"class"})
+)
+
+// scheduled reports whether the RPC fullMethod is scheduled. Only the methods of the OpenFGA API
+// are, so that e.g. the health checks and the requests between replicas are never queued.
+func scheduled(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+openfgav1.OpenFGAService_ServiceDesc.ServiceName+"/")
+}
+
+// Interceptor schedules the requests of the OpenFGA API with a Scheduler.
+type Interceptor struct {
+	scheduler     *Scheduler
+	methodClasses map[string]Class
+}
+
+// NewInterceptor constructs an Interceptor that schedules the requests with scheduler. The class
+// of a request is the one set by its Header, or else the class of its method in methodClasses, or
+// else Interactive.
+func NewInterceptor(scheduler *Scheduler, methodClasses map[string]Class) *Interceptor {
+	return &Interceptor{
+		scheduler:     scheduler,
+		methodClasses: methodClasses,
+	}
+}
+
+func (i *Interceptor) class(ctx context.Context, fullMethod string) Class {
+	if class, ok := classFromContext(ctx); ok {
+		return class
+	}
+	if class, ok := i.methodClasses[path.Base(fullMethod)]; ok {
+		return class
+	}
+	return Interactive
+}
+
+func (i *Interceptor) acquire(ctx context.Context, class Class, req any) (func(), error) {
+	var storeID string
+	if r, ok := req.(hasGetStoreID); ok {
+		storeID = r.GetStoreId()
+	}
+
+	start := time.Now()
+	release, err := i.scheduler.Acquire(ctx, class, storeID)
+	queueDurationHistogram.WithLabelValues(class.String()).Observe(float64(time.Since(start).Milliseconds()))
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return release, nil
+}
+
+type hasGetStoreID interface {
+	GetStoreId() string
+}
+
+// NewUnaryInterceptor returns an interceptor that waits for the Scheduler before handling a request.
+func (i *Interceptor) NewUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !scheduled(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		release, err := i.acquire(ctx, i.class(ctx, info.FullMethod), req)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamingInterceptor returns an interceptor that waits for the Scheduler when the first
+// message of a stream is received, so that the request is scheduled with its store.
+func (i *Interceptor) NewStreamingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !scheduled(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		wrapper := &scheduledStream{
+			ServerStream: stream,
+			interceptor:  i,
+			class:        i.class(stream.Context(), info.FullMethod),
+		}
+		defer wrapper.release()
+		return handler(srv, wrapper)
+	}
+}
+
+type scheduledStream struct {
+	grpc.ServerStream
+	interceptor *Interceptor
+	class       Class
+
+	once      sync.Once
+	releaseFn func()
+}
+
+// RecvMsg receives a message, and waits for the Scheduler after the first one.
+func (s *scheduledStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	var err error
+	s.once.Do(func() {
+		s.releaseFn, err = s.interceptor.acquire(s.Context(), s.class, m)
+	})
+	return err
+}
+
+func (s *scheduledStream) release() {
+	if s.releaseFn != nil {
+		s.releaseFn()
+	}
+}
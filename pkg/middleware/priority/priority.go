@@ -0,0 +1,112 @@
+package priority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the request header that sets the priority class of a request, overriding the class of
+// its method.
+const Header = "Openfga-Priority"
+
+// ErrInvalidPriority is returned when a priority class, a method class or a store weight cannot be parsed.
+var ErrInvalidPriority = errors.New("invalid priority")
+
+// Class is the priority class of a request. When the server is saturated, the queued requests of a
+// class are scheduled before the ones of the classes after it.
+type Class int
+
+const (
+	// Interactive is the class of the requests that a user is waiting on, e.g. Check.
+	Interactive Class = iota
+	// Bulk is the class of the expensive requests of background jobs, e.g. ListObjects or Read.
+	Bulk
+
+	numClasses = int(Bulk) + 1
+)
+
+// String converts the Class to its string representation.
+func (c Class) String() string {
+	switch c {
+	case Interactive:
+		return "interactive"
+	case Bulk:
+		return "bulk"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseClass parses the string representation of a Class.
+func ParseClass(value string) (Class, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "interactive":
+		return Interactive, nil
+	case "bulk":
+		return Bulk, nil
+	default:
+		return 0, fmt.Errorf("%w class '%s': expected interactive or bulk", ErrInvalidPriority, value)
+	}
+}
+
+// ParseMethodClasses parses the classes of RPC methods in the form <method>=<class>, e.g.
+// ListObjects=bulk. The method is the name of the RPC, without its service.
+func ParseMethodClasses(values []string) (map[string]Class, error) {
+	classes := make(map[string]Class, len(values))
+	for _, value := range values {
+		method, rawClass, ok := strings.Cut(value, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("%w method class '%s': expected <method>=<class>", ErrInvalidPriority, value)
+		}
+
+		class, err := ParseClass(rawClass)
+		if err != nil {
+			return nil, err
+		}
+		classes[method] = class
+	}
+	return classes, nil
+}
+
+// ParseStoreWeights parses the weights of stores in the form <store_id>=<weight>, e.g.
+// 01ARZ3NDEKTSV4RRFFQ69G5FAV=4. A store is scheduled in proportion to its weight among the
+// stores with queued requests of the same class. The stores without a weight have a weight of 1.
+func ParseStoreWeights(values []string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(values))
+	for _, value := range values {
+		storeID, rawWeight, ok := strings.Cut(value, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("%w store weight '%s': expected <store_id>=<weight>", ErrInvalidPriority, value)
+		}
+
+		weight, err := strconv.ParseFloat(rawWeight, 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("%w store weight '%s': the weight must be greater than 0", ErrInvalidPriority, value)
+		}
+		weights[storeID] = weight
+	}
+	return weights, nil
+}
+
+// classFromContext returns the class set by the Header of the request of ctx, if valid.
+func classFromContext(ctx context.Context) (Class, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(Header))
+	if len(values) == 0 {
+		return 0, false
+	}
+	class, err := ParseClass(values[0])
+	if err != nil {
+		return 0, false
+	}
+	return class, true
+}
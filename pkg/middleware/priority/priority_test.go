@@ -0,0 +1,67 @@
+package priority
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+func TestParseMethodClasses(t *testing.T) {
+	classes, err := ParseMethodClasses([]string{"ListObjects=bulk", "Check=Interactive"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]Class{"ListObjects": Bulk, "Check": Interactive}, classes)
+
+	for _, value := range []string{"ListObjects", "=bulk", "ListObjects=urgent"} {
+		_, err := ParseMethodClasses([]string{value})
+		require.ErrorIs(t, err, ErrInvalidPriority, value)
+	}
+}
+
+func TestParseStoreWeights(t *testing.T) {
+	weights, err := ParseStoreWeights([]string{"a=2", "b=0.5"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"a": 2, "b": 0.5}, weights)
+
+	for _, value := range []string{"a", "=2", "a=x", "a=0", "a=-1"} {
+		_, err := ParseStoreWeights([]string{value})
+		require.ErrorIs(t, err, ErrInvalidPriority, value)
+	}
+}
+
+func TestInterceptorClass(t *testing.T) {
+	interceptor := NewInterceptor(NewScheduler(1, nil), map[string]Class{"ListObjects": Bulk})
+
+	require.Equal(t, Bulk, interceptor.class(context.Background(), "/openfga.v1.OpenFGAService/ListObjects"))
+	require.Equal(t, Interactive, interceptor.class(context.Background(), "/openfga.v1.OpenFGAService/Check"))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "interactive"))
+	require.Equal(t, Interactive, interceptor.class(ctx, "/openfga.v1.OpenFGAService/ListObjects"))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "unknown"))
+	require.Equal(t, Bulk, interceptor.class(ctx, "/openfga.v1.OpenFGAService/ListObjects"))
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	scheduler := NewScheduler(1, nil)
+	interceptor := NewInterceptor(scheduler, nil).NewUnaryInterceptor()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		require.Equal(t, 1, scheduler.inFlightCount())
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), &openfgav1.CheckRequest{StoreId: "a"}, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"}, handler)
+	require.NoError(t, err)
+	require.Zero(t, scheduler.inFlightCount())
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, func(ctx context.Context, req any) (any, error) {
+		require.Zero(t, scheduler.inFlightCount())
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,194 @@
+package priority
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Scheduler bounds the number of requests in flight. When the bound is reached, the requests are
+// queued and scheduled by class, and by start-time fair queueing between the stores of a class:
+// every store is served in proportion to its weight, whatever the number of requests it queues.
+type Scheduler struct {
+	maxInFlight  int
+	storeWeights map[string]float64
+	queuedGauges [numClasses]prometheus.Gauge
+
+	mu       sync.Mutex
+	inFlight int
+	seq      uint64
+	queues   [numClasses]classQueue
+}
+
+// SchedulerOption configures a Scheduler.
+type SchedulerOption func(*Scheduler)
+
+// WithQueuedGauge sets the gauge of the number of queued requests of every class, so that a
+// Scheduler that is not the one of the API requests has its own metric.
+func WithQueuedGauge(gauge prometheus.Gauge) SchedulerOption {
+	return func(s *Scheduler) {
+		for i := range s.queuedGauges {
+			s.queuedGauges[i] = gauge
+		}
+	}
+}
+
+// NewScheduler constructs a Scheduler that lets maxInFlight requests run at once.
+func NewScheduler(maxInFlight int, storeWeights map[string]float64, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		maxInFlight:  maxInFlight,
+		storeWeights: storeWeights,
+	}
+	for i := range s.queues {
+		s.queues[i].lastFinish = make(map[string]float64)
+		s.queuedGauges[i] = queuedGauge.WithLabelValues(Class(i).String())
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// classQueue holds the queued requests of a class, ordered by virtual start time.
+type classQueue struct {
+	waiters     waiterHeap
+	virtualTime float64
+	lastFinish  map[string]float64 // store ID => virtual finish time of its last queued request
+}
+
+type waiter struct {
+	start float64
+	seq   uint64
+	ready chan struct{}
+	index int
+}
+
+// Acquire waits until the request of the store and class can run, or until ctx is done. The
+// returned function must be called when the request completes.
+func (s *Scheduler) Acquire(ctx context.Context, class Class, storeID string) (func(), error) {
+	return s.AcquireN(ctx, class, storeID, 1)
+}
+
+// AcquireN is Acquire for a request of the given cost, e.g. the number of tuples of a Write. The
+// stores are served in proportion to their weight in cost rather than in requests, so that the
+// large requests of a store don't delay the small ones of the others more than their share.
+func (s *Scheduler) AcquireN(ctx context.Context, class Class, storeID string, cost float64) (func(), error) {
+	s.mu.Lock()
+	if s.inFlight < s.maxInFlight && s.queued() == 0 {
+		s.inFlight++
+		s.mu.Unlock()
+		return s.release, nil
+	}
+
+	q := &s.queues[class]
+	weight, ok := s.storeWeights[storeID]
+	if !ok {
+		weight = 1
+	}
+	start := max(q.virtualTime, q.lastFinish[storeID])
+	q.lastFinish[storeID] = start + max(cost, 1)/weight
+
+	s.seq++
+	w := &waiter{start: start, seq: s.seq, ready: make(chan struct{})}
+	heap.Push(&q.waiters, w)
+	s.queuedGauges[class].Inc()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.index >= 0 {
+			heap.Remove(&q.waiters, w.index)
+			s.queuedGauges[class].Dec()
+			return nil, ctx.Err()
+		}
+		// the request was scheduled at the same time, hand its slot over
+		s.releaseLocked()
+		return nil, ctx.Err()
+	}
+}
+
+// Load returns the number of requests in flight and queued relative to the number of requests that
+// can run at once: it is 1 when the scheduler is saturated, and greater than 1 when requests are
+// queued.
+func (s *Scheduler) Load() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return float64(s.inFlight+s.queued()) / float64(s.maxInFlight)
+}
+
+// queued returns the number of queued requests. It must be called with s.mu held.
+func (s *Scheduler) queued() int {
+	n := 0
+	for i := range s.queues {
+		n += s.queues[i].waiters.Len()
+	}
+	return n
+}
+
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+// releaseLocked hands the slot of a completed request over to the next queued request, if any. It
+// must be called with s.mu held.
+func (s *Scheduler) releaseLocked() {
+	for class := range s.queues {
+		q := &s.queues[class]
+		if q.waiters.Len() == 0 {
+			continue
+		}
+
+		w := heap.Pop(&q.waiters).(*waiter)
+		s.queuedGauges[class].Dec()
+		q.virtualTime = w.start
+		if q.waiters.Len() == 0 {
+			// every store is even again
+			q.virtualTime = 0
+			clear(q.lastFinish)
+		}
+		close(w.ready)
+		return
+	}
+	s.inFlight--
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].start != h[j].start {
+		return h[i].start < h[j].start
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
@@ -0,0 +1,180 @@
+package priority
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Scheduler) inFlightCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+func (s *Scheduler) queuedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queued()
+}
+
+// enqueue queues a request and waits until it is queued. The name of the request is sent on
+// scheduled when it is scheduled, and the request completes when it reads from done.
+func enqueue(t *testing.T, s *Scheduler, class Class, storeID, name string, scheduled chan<- string, done <-chan struct{}) {
+	t.Helper()
+	queued := s.queuedCount()
+	go func() {
+		release, err := s.Acquire(context.Background(), class, storeID)
+		if err != nil {
+			return
+		}
+		scheduled <- name
+		<-done
+		release()
+	}()
+	require.Eventually(t, func() bool { return s.queuedCount() == queued+1 }, time.Second, time.Millisecond)
+}
+
+// order completes the running request and returns the names of the queued requests in the order
+// they are scheduled.
+func order(t *testing.T, release func(), scheduled <-chan string, done chan<- struct{}, n int) []string {
+	t.Helper()
+	release()
+	names := make([]string, 0, n)
+	for range n {
+		select {
+		case name := <-scheduled:
+			names = append(names, name)
+			done <- struct{}{}
+		case <-time.After(time.Second):
+			require.FailNow(t, "request not scheduled")
+		}
+	}
+	return names
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("runs_without_queueing_under_the_limit", func(t *testing.T) {
+		s := NewScheduler(2, nil)
+		release1, err := s.Acquire(context.Background(), Bulk, "a")
+		require.NoError(t, err)
+		release2, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+		release1()
+		release2()
+		require.Zero(t, s.inFlight)
+	})
+
+	t.Run("schedules_interactive_before_bulk", func(t *testing.T) {
+		s := NewScheduler(1, nil)
+		release, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+
+		scheduled := make(chan string)
+		done := make(chan struct{})
+		enqueue(t, s, Bulk, "a", "bulk", scheduled, done)
+		enqueue(t, s, Interactive, "a", "interactive", scheduled, done)
+
+		require.Equal(t, []string{"interactive", "bulk"}, order(t, release, scheduled, done, 2))
+		require.Eventually(t, func() bool { return s.inFlightCount() == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("schedules_stores_fairly", func(t *testing.T) {
+		s := NewScheduler(1, nil)
+		release, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+
+		scheduled := make(chan string)
+		done := make(chan struct{})
+		enqueue(t, s, Interactive, "a", "a1", scheduled, done)
+		enqueue(t, s, Interactive, "a", "a2", scheduled, done)
+		enqueue(t, s, Interactive, "a", "a3", scheduled, done)
+		enqueue(t, s, Interactive, "b", "b1", scheduled, done)
+
+		require.Equal(t, []string{"a1", "b1", "a2", "a3"}, order(t, release, scheduled, done, 4))
+	})
+
+	t.Run("schedules_stores_by_weight", func(t *testing.T) {
+		s := NewScheduler(1, map[string]float64{"a": 2})
+		release, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+
+		scheduled := make(chan string)
+		done := make(chan struct{})
+		enqueue(t, s, Interactive, "a", "a1", scheduled, done)
+		enqueue(t, s, Interactive, "a", "a2", scheduled, done)
+		enqueue(t, s, Interactive, "a", "a3", scheduled, done)
+		enqueue(t, s, Interactive, "b", "b1", scheduled, done)
+		enqueue(t, s, Interactive, "b", "b2", scheduled, done)
+
+		require.Equal(t, []string{"a1", "b1", "a2", "a3", "b2"}, order(t, release, scheduled, done, 5))
+	})
+
+	t.Run("schedules_stores_by_cost", func(t *testing.T) {
+		s := NewScheduler(1, nil)
+		release, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+
+		scheduled := make(chan string)
+		done := make(chan struct{})
+		enqueueN := func(storeID, name string, cost float64) {
+			queued := s.queuedCount()
+			go func() {
+				release, err := s.AcquireN(context.Background(), Interactive, storeID, cost)
+				if err != nil {
+					return
+				}
+				scheduled <- name
+				<-done
+				release()
+			}()
+			require.Eventually(t, func() bool { return s.queuedCount() == queued+1 }, time.Second, time.Millisecond)
+		}
+		enqueueN("bulk", "bulk1", 100)
+		enqueueN("bulk", "bulk2", 100)
+		enqueueN("a", "a1", 1)
+		enqueueN("b", "b1", 1)
+		enqueueN("a", "a2", 1)
+
+		require.Equal(t, []string{"bulk1", "a1", "b1", "a2", "bulk2"}, order(t, release, scheduled, done, 5))
+	})
+
+	t.Run("reports_its_load", func(t *testing.T) {
+		s := NewScheduler(2, nil)
+		require.Zero(t, s.Load())
+
+		release1, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+		require.InDelta(t, 0.5, s.Load(), 0)
+
+		release2, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+		require.InDelta(t, 1, s.Load(), 0)
+
+		scheduled := make(chan string)
+		done := make(chan struct{})
+		enqueue(t, s, Bulk, "a", "bulk", scheduled, done)
+		require.InDelta(t, 1.5, s.Load(), 0)
+
+		require.Equal(t, []string{"bulk"}, order(t, release1, scheduled, done, 1))
+		release2()
+		require.Eventually(t, func() bool { return s.Load() == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("stops_waiting_when_the_context_is_done", func(t *testing.T) {
+		s := NewScheduler(1, nil)
+		release, err := s.Acquire(context.Background(), Interactive, "a")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = s.Acquire(ctx, Bulk, "a")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Zero(t, s.queuedCount())
+
+		release()
+		require.Zero(t, s.inFlight)
+	})
+}
@@ -57,6 +57,6 @@ func PanicRecoveryHandler(logger logger.Logger) grpc_recovery.RecoveryHandlerFun
 			zap.ByteString("stacktrace", debug.Stack()),
 		)
 
-		return status.Errorf(codes.Internal, errors.InternalServerErrorMsg)
+		return errors.WithErrorInfo(status.Errorf(codes.Internal, errors.InternalServerErrorMsg))
 	}
 }
@@ -0,0 +1,3 @@
+// Package slo contains middleware to track the service level objectives of the RPC methods, and
+// to export their error budget and burn rates.
+package slo
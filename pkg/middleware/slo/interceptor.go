@@ -0,0 +1,48 @@
+package slo
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// tracked reports whether the RPC fullMethod is tracked. Only the methods of the OpenFGA API are,
+// so that e.g. the requests between replicas do not count against the objectives of the API.
+func tracked(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+openfgav1.OpenFGAService_ServiceDesc.ServiceName+"/")
+}
+
+// NewUnaryInterceptor returns a grpc.UnaryServerInterceptor that records the requests in the
+// Tracker.
+func (t *Tracker) NewUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !tracked(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		t.Record(path.Base(info.FullMethod), time.Since(start), err)
+		return resp, err
+	}
+}
+
+// NewStreamingInterceptor returns a grpc.StreamServerInterceptor that records the streams in the
+// Tracker. The latency of a stream is the time until its last message.
+func (t *Tracker) NewStreamingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !tracked(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		start := time.Now()
+		err := handler(srv, stream)
+		t.Record(path.Base(info.FullMethod), time.Since(start), err)
+		return err
+	}
+}
@@ -0,0 +1,140 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+// ErrInvalidObjective is returned when an objective cannot be parsed.
+var ErrInvalidObjective = errors.New("invalid service level objective")
+
+// Kind is the kind of the indicator of an Objective.
+type Kind int
+
+const (
+	// Availability objectives count the requests that failed with a server error as bad.
+	Availability Kind = iota
+	// Latency objectives count the requests slower than the threshold of the objective as bad.
+	Latency
+)
+
+// String converts the Kind to its string representation.
+func (k Kind) String() string {
+	switch k {
+	case Availability:
+		return "availability"
+	case Latency:
+		return "latency"
+	default:
+		return "unknown"
+	}
+}
+
+// Objective is a service level objective of an RPC method, e.g. 99% of the Checks are served in
+// less than 50ms.
+type Objective struct {
+	// Method is the name of the RPC, without its service, e.g. Check.
+	Method string
+	Kind   Kind
+	// Threshold is the latency under which a request is good, for the Latency objectives.
+	Threshold time.Duration
+	// Target is the fraction of the requests that must be good, e.g. 0.99.
+	Target float64
+}
+
+// Name returns the name of the objective, which identifies it in the metrics, e.g.
+// Check_latency_50ms.
+func (o Objective) Name() string {
+	if o.Kind == Latency {
+		return fmt.Sprintf("%s_%s_%s", o.Method, o.Kind, o.Threshold)
+	}
+	return fmt.Sprintf("%s_%s", o.Method, o.Kind)
+}
+
+// bad reports whether the request that took duration and failed with err, if any, counts against
+// the objective. The requests that failed with a server error only count against the Availability
+// objectives.
+func (o Objective) bad(duration time.Duration, err error) bool {
+	serverError := isServerError(err)
+	if o.Kind == Availability {
+		return serverError
+	}
+	return !serverError && duration > o.Threshold
+}
+
+// counted reports whether the request that failed with err, if any, is counted by the objective.
+func (o Objective) counted(err error) bool {
+	return o.Kind == Availability || !isServerError(err)
+}
+
+// isServerError reports whether err is an error of the server rather than of the request, i.e.
+// whether it is served with a 5xx HTTP status code.
+func isServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := serverErrors.ConvertToEncodedErrorCode(status.Convert(err))
+	return serverErrors.NewEncodedError(code, "").HTTPStatus() >= 500
+}
+
+// ParseObjectives parses objectives in the form <method>=latency:<threshold>:<target> or
+// <method>=availability:<target>, where the target is a percentage of the requests, e.g.
+// Check=latency:50ms:99 or Check=availability:99.95.
+func ParseObjectives(values []string) ([]Objective, error) {
+	objectives := make([]Objective, 0, len(values))
+	names := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		objective, err := parseObjective(value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := objective.Name()
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("%w '%s': duplicate objective '%s'", ErrInvalidObjective, value, name)
+		}
+		names[name] = struct{}{}
+		objectives = append(objectives, objective)
+	}
+	return objectives, nil
+}
+
+func parseObjective(value string) (Objective, error) {
+	method, spec, ok := strings.Cut(value, "=")
+	if !ok || method == "" {
+		return Objective{}, fmt.Errorf("%w '%s': expected <method>=latency:<threshold>:<target> or <method>=availability:<target>", ErrInvalidObjective, value)
+	}
+
+	parts := strings.Split(spec, ":")
+	objective := Objective{Method: method}
+	var rawTarget string
+	switch {
+	case parts[0] == Availability.String() && len(parts) == 2:
+		objective.Kind = Availability
+		rawTarget = parts[1]
+	case parts[0] == Latency.String() && len(parts) == 3:
+		threshold, err := time.ParseDuration(parts[1])
+		if err != nil || threshold <= 0 {
+			return Objective{}, fmt.Errorf("%w '%s': the threshold must be a positive duration", ErrInvalidObjective, value)
+		}
+		objective.Kind = Latency
+		objective.Threshold = threshold
+		rawTarget = parts[2]
+	default:
+		return Objective{}, fmt.Errorf("%w '%s': expected <method>=latency:<threshold>:<target> or <method>=availability:<target>", ErrInvalidObjective, value)
+	}
+
+	target, err := strconv.ParseFloat(rawTarget, 64)
+	if err != nil || target <= 0 || target >= 100 {
+		return Objective{}, fmt.Errorf("%w '%s': the target must be a percentage between 0 and 100, exclusive", ErrInvalidObjective, value)
+	}
+	objective.Target = target / 100
+	return objective, nil
+}
@@ -0,0 +1,116 @@
+package slo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+func TestParseObjectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []Objective
+		err      bool
+	}{
+		{
+			name:     "empty",
+			expected: []Objective{},
+		},
+		{
+			name:   "latency_and_availability",
+			values: []string{"Check=latency:50ms:99", "Check=availability:99.95"},
+			expected: []Objective{
+				{Method: "Check", Kind: Latency, Threshold: 50 * time.Millisecond, Target: 0.99},
+				{Method: "Check", Kind: Availability, Target: 0.9995},
+			},
+		},
+		{
+			name:   "two_latency_thresholds",
+			values: []string{"Check=latency:50ms:90", "Check=latency:200ms:99"},
+			expected: []Objective{
+				{Method: "Check", Kind: Latency, Threshold: 50 * time.Millisecond, Target: 0.9},
+				{Method: "Check", Kind: Latency, Threshold: 200 * time.Millisecond, Target: 0.99},
+			},
+		},
+		{
+			name:   "missing_method",
+			values: []string{"=availability:99"},
+			err:    true,
+		},
+		{
+			name:   "unknown_kind",
+			values: []string{"Check=throughput:99"},
+			err:    true,
+		},
+		{
+			name:   "latency_without_threshold",
+			values: []string{"Check=latency:99"},
+			err:    true,
+		},
+		{
+			name:   "invalid_threshold",
+			values: []string{"Check=latency:fast:99"},
+			err:    true,
+		},
+		{
+			name:   "target_out_of_range",
+			values: []string{"Check=availability:100"},
+			err:    true,
+		},
+		{
+			name:   "duplicate",
+			values: []string{"Check=availability:99", "Check=availability:99.9"},
+			err:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objectives, err := ParseObjectives(test.values)
+			if test.err {
+				require.ErrorIs(t, err, ErrInvalidObjective)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, objectives, len(test.expected))
+			for i, expected := range test.expected {
+				require.Equal(t, expected.Method, objectives[i].Method)
+				require.Equal(t, expected.Kind, objectives[i].Kind)
+				require.Equal(t, expected.Threshold, objectives[i].Threshold)
+				require.InDelta(t, expected.Target, objectives[i].Target, 1e-9)
+			}
+		})
+	}
+}
+
+func TestObjectiveName(t *testing.T) {
+	require.Equal(t, "Check_latency_50ms", Objective{Method: "Check", Kind: Latency, Threshold: 50 * time.Millisecond}.Name())
+	require.Equal(t, "Check_availability", Objective{Method: "Check", Kind: Availability}.Name())
+}
+
+func TestObjectiveBad(t *testing.T) {
+	latency := Objective{Method: "Check", Kind: Latency, Threshold: 50 * time.Millisecond}
+	availability := Objective{Method: "Check", Kind: Availability}
+
+	serverError := serverErrors.NewInternalError("", errors.New("internal"))
+	clientError := serverErrors.ValidationError(errors.New("invalid"))
+
+	require.False(t, latency.bad(10*time.Millisecond, nil))
+	require.True(t, latency.bad(100*time.Millisecond, nil))
+	require.True(t, latency.bad(100*time.Millisecond, clientError))
+	require.False(t, latency.counted(serverError))
+	require.True(t, latency.counted(clientError))
+
+	require.False(t, availability.bad(100*time.Millisecond, nil))
+	require.False(t, availability.bad(time.Millisecond, clientError))
+	require.True(t, availability.bad(time.Millisecond, serverError))
+	require.True(t, availability.bad(time.Millisecond, status.Error(codes.Unavailable, "unavailable")))
+	require.True(t, availability.counted(serverError))
+}
@@ -0,0 +1,283 @@
+package slo
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+const (
+	// fineBucketWidth is the width of the buckets of the burn rate windows.
+	fineBucketWidth = time.Minute
+
+	// coarseBuckets is the number of buckets of the error budget window.
+	coarseBuckets = 720
+)
+
+// burnRateWindows are the windows over which the burn rates are computed. They are the windows of
+// the usual multiwindow burn rate alerts, e.g. a fast burn alert on the 5m and 1h windows and a
+// slow burn alert on the 30m and 6h windows.
+var burnRateWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+var (
+	requestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(build.ProjectName, "", "slo_requests_total"),
+		"The total number of requests counted by the service level objective.",
+		[]string{"objective"}, nil)
+
+	badRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(build.ProjectName, "", "slo_bad_requests_total"),
+		"The total number of requests that count against the service level objective.",
+		[]string{"objective"}, nil)
+
+	targetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(build.ProjectName, "", "slo_target_ratio"),
+		"The fraction of the requests that must be good to meet the service level objective.",
+		[]string{"objective"}, nil)
+
+	burnRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(build.ProjectName, "", "slo_burn_rate"),
+		"The rate at which the error budget of the service level objective is consumed over the window, where 1 consumes exactly the budget over the error budget window.",
+		[]string{"objective", "window"}, nil)
+
+	budgetRemainingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(build.ProjectName, "", "slo_error_budget_remaining_ratio"),
+		"The fraction of the error budget of the service level objective that remains over the error budget window. It is negative when the budget is exceeded.",
+		[]string{"objective"}, nil)
+)
+
+// counts are the requests counted by an objective over a period.
+type counts struct {
+	total uint64
+	bad   uint64
+}
+
+// ring counts the requests in buckets of a fixed width over a rolling span.
+type ring struct {
+	width   time.Duration
+	buckets []counts
+	epochs  []int64 // the index of the period of width counted by each bucket
+}
+
+func newRing(width, span time.Duration) *ring {
+	n := int(span / width)
+	if n < 1 {
+		n = 1
+	}
+	return &ring{
+		width:   width,
+		buckets: make([]counts, n),
+		epochs:  make([]int64, n),
+	}
+}
+
+func (r *ring) add(now time.Time, bad bool) {
+	epoch := now.UnixNano() / int64(r.width)
+	i := epoch % int64(len(r.buckets))
+	if r.epochs[i] != epoch {
+		r.epochs[i] = epoch
+		r.buckets[i] = counts{}
+	}
+	r.buckets[i].total++
+	if bad {
+		r.buckets[i].bad++
+	}
+}
+
+// sum returns the requests counted over the last span.
+func (r *ring) sum(now time.Time, span time.Duration) counts {
+	n := min(int(span/r.width), len(r.buckets))
+	epoch := now.UnixNano() / int64(r.width)
+
+	var sum counts
+	for j := 0; j < n; j++ {
+		i := (epoch - int64(j)) % int64(len(r.buckets))
+		if r.epochs[i] == epoch-int64(j) {
+			sum.total += r.buckets[i].total
+			sum.bad += r.buckets[i].bad
+		}
+	}
+	return sum
+}
+
+type objectiveState struct {
+	Objective
+
+	fine     *ring // the requests of the burn rate windows
+	coarse   *ring // the requests of the error budget window
+	lifetime counts
+}
+
+// Tracker tracks the service level objectives of the RPC methods. It exports their error budget
+// and burn rates as Prometheus metrics, and summarizes them over HTTP.
+type Tracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	states   []*objectiveState
+	byMethod map[string][]*objectiveState
+}
+
+var (
+	_ prometheus.Collector = (*Tracker)(nil)
+	_ http.Handler         = (*Tracker)(nil)
+)
+
+// NewTracker constructs a Tracker of objectives, with an error budget over the rolling window.
+func NewTracker(objectives []Objective, window time.Duration) *Tracker {
+	t := &Tracker{
+		window:   window,
+		now:      time.Now,
+		byMethod: make(map[string][]*objectiveState, len(objectives)),
+	}
+
+	fineSpan := burnRateWindows[len(burnRateWindows)-1]
+	coarseWidth := max(window/coarseBuckets, fineBucketWidth)
+	for _, objective := range objectives {
+		state := &objectiveState{
+			Objective: objective,
+			fine:      newRing(fineBucketWidth, fineSpan),
+			coarse:    newRing(coarseWidth, window),
+		}
+		t.states = append(t.states, state)
+		t.byMethod[objective.Method] = append(t.byMethod[objective.Method], state)
+	}
+	return t
+}
+
+// Record records a request of the RPC method that took duration and failed with err, if any.
+func (t *Tracker) Record(method string, duration time.Duration, err error) {
+	states, ok := t.byMethod[method]
+	if !ok {
+		return
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, state := range states {
+		if !state.counted(err) {
+			continue
+		}
+		bad := state.bad(duration, err)
+		state.fine.add(now, bad)
+		state.coarse.add(now, bad)
+		state.lifetime.total++
+		if bad {
+			state.lifetime.bad++
+		}
+	}
+}
+
+// ObjectiveSummary summarizes the current state of a service level objective.
+type ObjectiveSummary struct {
+	Objective string `json:"objective"`
+	Method    string `json:"method"`
+	Kind      string `json:"kind"`
+	Threshold string `json:"threshold,omitempty"`
+	// Target is the fraction of the requests that must be good.
+	Target float64 `json:"target"`
+	// Window is the window of the error budget.
+	Window      string `json:"window"`
+	Requests    uint64 `json:"requests"`
+	BadRequests uint64 `json:"bad_requests"`
+	// Ratio is the fraction of the requests of the window that were good, or 1 if there were none.
+	Ratio float64 `json:"ratio"`
+	// ErrorBudgetRemaining is the fraction of the error budget of the window that remains. It is
+	// negative when the budget is exceeded.
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	// BurnRates are the burn rates over the windows of burnRateWindows, by window.
+	BurnRates map[string]float64 `json:"burn_rates"`
+}
+
+// Summary summarizes the current state of the objectives.
+func (t *Tracker) Summary() []ObjectiveSummary {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summaries := make([]ObjectiveSummary, 0, len(t.states))
+	for _, state := range t.states {
+		windowCounts := state.coarse.sum(now, t.window)
+
+		summary := ObjectiveSummary{
+			Objective:            state.Name(),
+			Method:               state.Method,
+			Kind:                 state.Kind.String(),
+			Target:               state.Target,
+			Window:               t.window.String(),
+			Requests:             windowCounts.total,
+			BadRequests:          windowCounts.bad,
+			Ratio:                1,
+			ErrorBudgetRemaining: 1 - burnRate(windowCounts, state.Target),
+			BurnRates:            make(map[string]float64, len(burnRateWindows)),
+		}
+		if state.Kind == Latency {
+			summary.Threshold = state.Threshold.String()
+		}
+		if windowCounts.total > 0 {
+			summary.Ratio = 1 - float64(windowCounts.bad)/float64(windowCounts.total)
+		}
+		for _, window := range burnRateWindows {
+			summary.BurnRates[window.String()] = burnRate(state.fine.sum(now, window), state.Target)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// burnRate returns the ratio between the fraction of bad requests of c and the fraction allowed
+// by target.
+func burnRate(c counts, target float64) float64 {
+	if c.total == 0 {
+		return 0
+	}
+	return float64(c.bad) / float64(c.total) / (1 - target)
+}
+
+// Describe implements prometheus.Collector.
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- requestsDesc
+	ch <- badRequestsDesc
+	ch <- targetDesc
+	ch <- burnRateDesc
+	ch <- budgetRemainingDesc
+}
+
+// Collect implements prometheus.Collector.
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	summaries := t.Summary()
+
+	t.mu.Lock()
+	lifetimes := make([]counts, 0, len(t.states))
+	for _, state := range t.states {
+		lifetimes = append(lifetimes, state.lifetime)
+	}
+	t.mu.Unlock()
+
+	for i, summary := range summaries {
+		ch <- prometheus.MustNewConstMetric(requestsDesc, prometheus.CounterValue, float64(lifetimes[i].total), summary.Objective)
+		ch <- prometheus.MustNewConstMetric(badRequestsDesc, prometheus.CounterValue, float64(lifetimes[i].bad), summary.Objective)
+		ch <- prometheus.MustNewConstMetric(targetDesc, prometheus.GaugeValue, summary.Target, summary.Objective)
+		ch <- prometheus.MustNewConstMetric(budgetRemainingDesc, prometheus.GaugeValue, summary.ErrorBudgetRemaining, summary.Objective)
+		for window, rate := range summary.BurnRates {
+			ch <- prometheus.MustNewConstMetric(burnRateDesc, prometheus.GaugeValue, rate, summary.Objective, window)
+		}
+	}
+}
+
+// ServeHTTP serves the Summary of the objectives as JSON.
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		Objectives []ObjectiveSummary `json:"objectives"`
+	}{
+		Objectives: t.Summary(),
+	})
+}
@@ -0,0 +1,136 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+func TestRing(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	r := newRing(time.Minute, time.Hour)
+
+	r.add(now, false)
+	r.add(now, true)
+	r.add(now.Add(-30*time.Minute), true)
+	require.Equal(t, counts{total: 2, bad: 1}, r.sum(now, 5*time.Minute))
+	require.Equal(t, counts{total: 3, bad: 2}, r.sum(now, time.Hour))
+
+	// the buckets older than the span of the ring are reused
+	later := now.Add(time.Hour)
+	r.add(later, false)
+	require.Equal(t, counts{total: 1}, r.sum(later, time.Hour))
+	require.Equal(t, counts{total: 1}, r.sum(later, 2*time.Hour))
+}
+
+func TestTracker(t *testing.T) {
+	objectives, err := ParseObjectives([]string{"Check=latency:50ms:90", "Check=availability:99"})
+	require.NoError(t, err)
+
+	now := time.Unix(1_000_000, 0)
+	tracker := NewTracker(objectives, 24*time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 97; i++ {
+		tracker.Record("Check", 10*time.Millisecond, nil)
+	}
+	tracker.Record("Check", 100*time.Millisecond, nil)
+	tracker.Record("Check", 10*time.Millisecond, serverErrors.NewInternalError("", errors.New("internal")))
+	tracker.Record("Check", 10*time.Millisecond, serverErrors.ValidationError(errors.New("invalid")))
+	tracker.Record("ListObjects", time.Second, nil)
+
+	summaries := tracker.Summary()
+	require.Len(t, summaries, 2)
+
+	latency := summaries[0]
+	require.Equal(t, "Check_latency_50ms", latency.Objective)
+	require.Equal(t, "50ms", latency.Threshold)
+	require.Equal(t, uint64(99), latency.Requests)
+	require.Equal(t, uint64(1), latency.BadRequests)
+	require.InDelta(t, 1-1.0/99, latency.Ratio, 1e-9)
+	require.InDelta(t, 1-(1.0/99)/0.1, latency.ErrorBudgetRemaining, 1e-9)
+	require.InDelta(t, (1.0/99)/0.1, latency.BurnRates["5m0s"], 1e-9)
+
+	availability := summaries[1]
+	require.Equal(t, "Check_availability", availability.Objective)
+	require.Equal(t, uint64(100), availability.Requests)
+	require.Equal(t, uint64(1), availability.BadRequests)
+	require.InDelta(t, 0, availability.ErrorBudgetRemaining, 1e-9)
+	require.InDelta(t, 1, availability.BurnRates["1h0m0s"], 1e-9)
+
+	// the burn rate windows forget the requests before the error budget window does
+	now = now.Add(2 * time.Hour)
+	summaries = tracker.Summary()
+	require.Equal(t, uint64(100), summaries[1].Requests)
+	require.Zero(t, summaries[1].BurnRates["1h0m0s"])
+	require.InDelta(t, 1, summaries[1].BurnRates["6h0m0s"], 1e-9)
+
+	t.Run("metrics", func(t *testing.T) {
+		expected := `
+# HELP openfga_slo_bad_requests_total The total number of requests that count against the service level objective.
+# TYPE openfga_slo_bad_requests_total counter
+openfga_slo_bad_requests_total{objective="Check_availability"} 1
+openfga_slo_bad_requests_total{objective="Check_latency_50ms"} 1
+`
+		require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(expected), "openfga_slo_bad_requests_total"))
+	})
+
+	t.Run("http", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		tracker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slo", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var body struct {
+			Objectives []ObjectiveSummary `json:"objectives"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Objectives, 2)
+		require.Equal(t, "Check_availability", body.Objectives[1].Objective)
+		require.Equal(t, uint64(100), body.Objectives[1].Requests)
+	})
+}
+
+func TestTrackerInterceptors(t *testing.T) {
+	objectives, err := ParseObjectives([]string{"Check=availability:99", "StreamedListObjects=availability:99", "ResolveCheck=availability:99"})
+	require.NoError(t, err)
+	tracker := NewTracker(objectives, time.Hour)
+
+	unary := tracker.NewUnaryInterceptor()
+	_, err = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: openfgav1.OpenFGAService_Check_FullMethodName}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	// the methods of the other services are not tracked
+	_, err = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.cluster.v1.DispatchService/ResolveCheck"}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	streaming := tracker.NewStreamingInterceptor()
+	err = streaming(nil, nil, &grpc.StreamServerInfo{FullMethod: openfgav1.OpenFGAService_StreamedListObjects_FullMethodName}, func(srv any, stream grpc.ServerStream) error {
+		return serverErrors.NewInternalError("", errors.New("internal"))
+	})
+	require.Error(t, err)
+
+	summaries := tracker.Summary()
+	require.Equal(t, uint64(1), summaries[0].Requests)
+	require.Equal(t, uint64(0), summaries[0].BadRequests)
+	require.Equal(t, uint64(1), summaries[1].Requests)
+	require.Equal(t, uint64(1), summaries[1].BadRequests)
+	require.Equal(t, uint64(0), summaries[2].Requests)
+}
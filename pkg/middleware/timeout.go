@@ -2,6 +2,10 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
 	"time"
 
 	grpcvalidator "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
@@ -10,19 +14,105 @@ import (
 	"github.com/openfga/openfga/pkg/logger"
 )
 
+// ErrInvalidMethodTimeout is returned when a per-method timeout cannot be parsed.
+var ErrInvalidMethodTimeout = errors.New("invalid method timeout")
+
+// MethodTimeout overrides the timeout of the requests of an RPC method. Default is the timeout of
+// the requests without a deadline, and Max bounds the deadline of the other requests. A zero value
+// falls back to the other one, or to the timeout of the TimeoutInterceptor if both are zero.
+type MethodTimeout struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// ParseMethodTimeouts parses per-method timeouts in the form <method>=<default>:<max>, e.g.
+// ListObjects=5s:10s. The method is the name of the RPC, without its service.
+func ParseMethodTimeouts(values []string) (map[string]MethodTimeout, error) {
+	timeouts := make(map[string]MethodTimeout, len(values))
+	for _, value := range values {
+		method, rawTimeout, ok := strings.Cut(value, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("%w '%s': expected <method>=<default>:<max>", ErrInvalidMethodTimeout, value)
+		}
+
+		rawDefault, rawMax, ok := strings.Cut(rawTimeout, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w '%s': expected <method>=<default>:<max>", ErrInvalidMethodTimeout, value)
+		}
+
+		defaultTimeout, err := time.ParseDuration(rawDefault)
+		if err != nil || defaultTimeout < 0 {
+			return nil, fmt.Errorf("%w '%s': invalid default '%s'", ErrInvalidMethodTimeout, value, rawDefault)
+		}
+
+		maxTimeout, err := time.ParseDuration(rawMax)
+		if err != nil || maxTimeout < 0 {
+			return nil, fmt.Errorf("%w '%s': invalid max '%s'", ErrInvalidMethodTimeout, value, rawMax)
+		}
+
+		if maxTimeout > 0 && defaultTimeout > maxTimeout {
+			return nil, fmt.Errorf("%w '%s': the default cannot be greater than the max", ErrInvalidMethodTimeout, value)
+		}
+
+		timeouts[method] = MethodTimeout{Default: defaultTimeout, Max: maxTimeout}
+	}
+	return timeouts, nil
+}
+
+// TimeoutInterceptorOption defines an option that can be used to change the behavior of a
+// TimeoutInterceptor.
+type TimeoutInterceptorOption func(*TimeoutInterceptor)
+
+// WithMethodTimeouts overrides the timeout of the requests of specific RPC methods, keyed by the
+// name of the RPC without its service.
+func WithMethodTimeouts(timeouts map[string]MethodTimeout) TimeoutInterceptorOption {
+	return func(h *TimeoutInterceptor) {
+		h.methodTimeouts = timeouts
+	}
+}
+
 // TimeoutInterceptor sets the timeout in each request.
 type TimeoutInterceptor struct {
-	timeout time.Duration
-	logger  logger.Logger
+	timeout        time.Duration
+	methodTimeouts map[string]MethodTimeout
+	logger         logger.Logger
 }
 
 // NewTimeoutInterceptor returns new TimeoutInterceptor that timeouts request if it
 // exceeds the timeout value.
-func NewTimeoutInterceptor(timeout time.Duration, logger logger.Logger) *TimeoutInterceptor {
-	return &TimeoutInterceptor{
+func NewTimeoutInterceptor(timeout time.Duration, logger logger.Logger, opts ...TimeoutInterceptorOption) *TimeoutInterceptor {
+	h := &TimeoutInterceptor{
 		timeout: timeout,
 		logger:  logger,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// withTimeout bounds the deadline of the request of ctx to the RPC fullMethod. The deadline set
+// by the client is kept if it is earlier.
+func (h *TimeoutInterceptor) withTimeout(ctx context.Context, fullMethod string) (context.Context, context.CancelFunc) {
+	timeout := h.timeout
+	if methodTimeout, ok := h.methodTimeouts[path.Base(fullMethod)]; ok {
+		_, hasDeadline := ctx.Deadline()
+		switch {
+		case !hasDeadline && methodTimeout.Default > 0:
+			timeout = methodTimeout.Default
+		case methodTimeout.Max > 0:
+			timeout = methodTimeout.Max
+		case methodTimeout.Default > 0:
+			timeout = methodTimeout.Default
+		}
+	}
+
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // NewUnaryTimeoutInterceptor returns an interceptor that will timeout according to the configured timeout.
@@ -30,7 +120,7 @@ func NewTimeoutInterceptor(timeout time.Duration, logger logger.Logger) *Timeout
 // to return proper error code.
 func (h *TimeoutInterceptor) NewUnaryTimeoutInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		ctx, cancel := context.WithTimeout(ctx, h.timeout)
+		ctx, cancel := h.withTimeout(ctx, fullMethod(info))
 		defer cancel()
 		return handler(ctx, req)
 	}
@@ -43,7 +133,7 @@ func (h *TimeoutInterceptor) NewStreamTimeoutInterceptor() grpc.StreamServerInte
 	validator := grpcvalidator.StreamServerInterceptor()
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		return validator(srv, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
-			ctx, cancel := context.WithTimeout(stream.Context(), h.timeout)
+			ctx, cancel := h.withTimeout(stream.Context(), streamFullMethod(info))
 			defer cancel()
 
 			return handler(srv, &recvWrapper{
@@ -54,6 +144,20 @@ func (h *TimeoutInterceptor) NewStreamTimeoutInterceptor() grpc.StreamServerInte
 	}
 }
 
+func fullMethod(info *grpc.UnaryServerInfo) string {
+	if info == nil {
+		return ""
+	}
+	return info.FullMethod
+}
+
+func streamFullMethod(info *grpc.StreamServerInfo) string {
+	if info == nil {
+		return ""
+	}
+	return info.FullMethod
+}
+
 type recvWrapper struct {
 	ctx context.Context
 	grpc.ServerStream
@@ -73,3 +73,58 @@ func TestNewStreamTimeoutInterceptor(t *testing.T) {
 	err := interceptor(nil, mockServerGRPCStream{ctx: context.Background()}, nil, handler)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
+
+func TestParseMethodTimeouts(t *testing.T) {
+	timeouts, err := ParseMethodTimeouts([]string{"Check=1s:2s", "ListObjects=0:10s", "Read=5s:0"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]MethodTimeout{
+		"Check":       {Default: time.Second, Max: 2 * time.Second},
+		"ListObjects": {Max: 10 * time.Second},
+		"Read":        {Default: 5 * time.Second},
+	}, timeouts)
+
+	for _, value := range []string{"Check", "=1s:2s", "Check=1s", "Check=x:2s", "Check=1s:x", "Check=-1s:2s", "Check=3s:2s"} {
+		_, err := ParseMethodTimeouts([]string{value})
+		require.ErrorIs(t, err, ErrInvalidMethodTimeout, value)
+	}
+}
+
+func TestMethodTimeouts(t *testing.T) {
+	timeoutInterceptor := NewTimeoutInterceptor(time.Hour, logger.NewNoopLogger(), WithMethodTimeouts(map[string]MethodTimeout{
+		"Check":       {Default: time.Minute, Max: 2 * time.Minute},
+		"ListObjects": {Max: 3 * time.Minute},
+	}))
+
+	deadline := func(ctx context.Context, method string) time.Duration {
+		var remaining time.Duration
+		handler := func(ctx context.Context, req any) (any, error) {
+			d, ok := ctx.Deadline()
+			require.True(t, ok)
+			remaining = time.Until(d)
+			return nil, nil
+		}
+		_, err := timeoutInterceptor.NewUnaryTimeoutInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/" + method}, handler)
+		require.NoError(t, err)
+		return remaining
+	}
+
+	withDeadline := func(timeout time.Duration) context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		t.Cleanup(cancel)
+		return ctx
+	}
+
+	t.Run("default_without_client_deadline", func(t *testing.T) {
+		require.InDelta(t, time.Minute, deadline(context.Background(), "Check"), float64(time.Second))
+		require.InDelta(t, 3*time.Minute, deadline(context.Background(), "ListObjects"), float64(time.Second))
+	})
+
+	t.Run("max_bounds_client_deadline", func(t *testing.T) {
+		require.InDelta(t, 2*time.Minute, deadline(withDeadline(10*time.Minute), "Check"), float64(time.Second))
+		require.InDelta(t, 90*time.Second, deadline(withDeadline(90*time.Second), "Check"), float64(time.Second))
+	})
+
+	t.Run("request_timeout_for_other_methods", func(t *testing.T) {
+		require.InDelta(t, time.Hour, deadline(context.Background(), "Read"), float64(time.Second))
+	})
+}
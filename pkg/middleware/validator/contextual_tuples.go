@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// DefaultMaxContextualTuples is the maximum number of contextual tuples per request (or per
+// BatchCheck item) enforced by the API definition.
+const DefaultMaxContextualTuples = 100
+
+// validateRequest runs the validations of the API definition against req, except that the
+// number of contextual tuples is checked against maxContextualTuples.
+func validateRequest(req interface{}, maxContextualTuples int) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return validate(req)
+	}
+
+	lists := contextualTuples(msg)
+	exceedsDefault := false
+	for _, tks := range lists {
+		if len(tks) > maxContextualTuples {
+			return fmt.Errorf("invalid %s.ContextualTuples: value must contain no more than %d item(s)",
+				msg.ProtoReflect().Descriptor().Name(), maxContextualTuples)
+		}
+		if len(tks) > DefaultMaxContextualTuples {
+			exceedsDefault = true
+		}
+	}
+
+	if !exceedsDefault {
+		return validate(req)
+	}
+
+	// The generated validations would reject the request, so validate a copy of the request
+	// without its contextual tuples, and the contextual tuples one by one.
+	stripped := proto.Clone(msg)
+	detachContextualTuples(stripped)
+	if err := validate(stripped); err != nil {
+		return err
+	}
+
+	for _, tks := range lists {
+		for _, tk := range tks {
+			if err := tk.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validate(req interface{}) error {
+	if v, ok := req.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// contextualTuples returns the lists of contextual tuples carried by req. BatchCheck requests
+// carry one list per item.
+func contextualTuples(req proto.Message) [][]*openfgav1.TupleKey {
+	switch r := req.(type) {
+	case *openfgav1.CheckRequest:
+		return [][]*openfgav1.TupleKey{r.GetContextualTuples().GetTupleKeys()}
+	case *openfgav1.ExpandRequest:
+		return [][]*openfgav1.TupleKey{r.GetContextualTuples().GetTupleKeys()}
+	case *openfgav1.ListObjectsRequest:
+		return [][]*openfgav1.TupleKey{r.GetContextualTuples().GetTupleKeys()}
+	case *openfgav1.StreamedListObjectsRequest:
+		return [][]*openfgav1.TupleKey{r.GetContextualTuples().GetTupleKeys()}
+	case *openfgav1.ListUsersRequest:
+		return [][]*openfgav1.TupleKey{r.GetContextualTuples()}
+	case *openfgav1.BatchCheckRequest:
+		lists := make([][]*openfgav1.TupleKey, 0, len(r.GetChecks()))
+		for _, item := range r.GetChecks() {
+			lists = append(lists, item.GetContextualTuples().GetTupleKeys())
+		}
+		return lists
+	}
+	return nil
+}
+
+// detachContextualTuples removes the contextual tuples from req.
+func detachContextualTuples(req proto.Message) {
+	switch r := req.(type) {
+	case *openfgav1.CheckRequest:
+		r.ContextualTuples = nil
+	case *openfgav1.ExpandRequest:
+		r.ContextualTuples = nil
+	case *openfgav1.ListObjectsRequest:
+		r.ContextualTuples = nil
+	case *openfgav1.StreamedListObjectsRequest:
+		r.ContextualTuples = nil
+	case *openfgav1.ListUsersRequest:
+		r.ContextualTuples = nil
+	case *openfgav1.BatchCheckRequest:
+		for _, item := range r.GetChecks() {
+			item.ContextualTuples = nil
+		}
+	}
+}
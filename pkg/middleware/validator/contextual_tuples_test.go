@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const testStoreID = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+
+func contextualTupleKeys(n int) []*openfgav1.TupleKey {
+	tks := make([]*openfgav1.TupleKey, 0, n)
+	for i := 0; i < n; i++ {
+		tks = append(tks, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:jon"))
+	}
+	return tks
+}
+
+func TestValidateRequestContextualTuples(t *testing.T) {
+	checkRequest := func(n int) *openfgav1.CheckRequest {
+		return &openfgav1.CheckRequest{
+			StoreId:          testStoreID,
+			TupleKey:         tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+			ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: contextualTupleKeys(n)},
+		}
+	}
+
+	t.Run("default_limit_uses_api_validations", func(t *testing.T) {
+		require.NoError(t, validateRequest(checkRequest(100), DefaultMaxContextualTuples))
+		require.ErrorContains(t, validateRequest(checkRequest(101), DefaultMaxContextualTuples), "no more than 100 item(s)")
+	})
+
+	t.Run("lower_limit", func(t *testing.T) {
+		require.NoError(t, validateRequest(checkRequest(10), 10))
+		require.ErrorContains(t, validateRequest(checkRequest(11), 10), "invalid CheckRequest.ContextualTuples: value must contain no more than 10 item(s)")
+	})
+
+	t.Run("higher_limit", func(t *testing.T) {
+		req := checkRequest(150)
+		require.NoError(t, validateRequest(req, 200))
+		require.Len(t, req.GetContextualTuples().GetTupleKeys(), 150)
+
+		require.ErrorContains(t, validateRequest(checkRequest(201), 200), "no more than 200 item(s)")
+	})
+
+	t.Run("higher_limit_still_validates_the_request", func(t *testing.T) {
+		req := checkRequest(150)
+		req.StoreId = "invalid"
+		require.ErrorContains(t, validateRequest(req, 200), "StoreId")
+
+		req = checkRequest(150)
+		req.ContextualTuples.TupleKeys[120].Relation = "invalid relation"
+		require.ErrorContains(t, validateRequest(req, 200), "Relation")
+	})
+
+	t.Run("higher_limit_batch_check", func(t *testing.T) {
+		req := &openfgav1.BatchCheckRequest{
+			StoreId: testStoreID,
+			Checks: []*openfgav1.BatchCheckItem{
+				{
+					TupleKey:         tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+					ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: contextualTupleKeys(150)},
+					CorrelationId:    "1",
+				},
+			},
+		}
+		require.NoError(t, validateRequest(req, 200))
+		require.Len(t, req.GetChecks()[0].GetContextualTuples().GetTupleKeys(), 150)
+
+		req.Checks[0].ContextualTuples.TupleKeys = contextualTupleKeys(201)
+		require.ErrorContains(t, validateRequest(req, 200), "no more than 200 item(s)")
+	})
+
+	t.Run("higher_limit_list_users", func(t *testing.T) {
+		req := &openfgav1.ListUsersRequest{
+			StoreId:          testStoreID,
+			Object:           &openfgav1.Object{Type: "document", Id: "1"},
+			Relation:         "viewer",
+			UserFilters:      []*openfgav1.UserTypeFilter{{Type: "user"}},
+			ContextualTuples: contextualTupleKeys(150),
+		}
+		require.NoError(t, validateRequest(req, 200))
+		require.ErrorContains(t, validateRequest(req, 120), "invalid ListUsersRequest.ContextualTuples")
+	})
+}
+
+func TestUnaryServerInterceptorMaxContextualTuples(t *testing.T) {
+	interceptor := UnaryServerInterceptor(WithMaxContextualTuples(5))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		require.True(t, RequestIsValidatedFromContext(ctx))
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), &openfgav1.CheckRequest{
+		StoreId:          testStoreID,
+		TupleKey:         tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: contextualTupleKeys(6)},
+	}, &grpc.UnaryServerInfo{}, handler)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = interceptor(context.Background(), &openfgav1.CheckRequest{
+		StoreId:          testStoreID,
+		TupleKey:         tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: contextualTupleKeys(5)},
+	}, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+}
@@ -5,6 +5,8 @@ import (
 
 	grpcvalidator "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ctxKey string
@@ -13,6 +15,29 @@ var (
 	requestIsValidatedCtxKey = ctxKey("request-validated")
 )
 
+// Option configures the validation interceptors.
+type Option func(*options)
+
+type options struct {
+	maxContextualTuples int
+}
+
+// WithMaxContextualTuples overrides the maximum number of contextual tuples accepted per request
+// (or per BatchCheck item). By default, the limit of the API definition (DefaultMaxContextualTuples) applies.
+func WithMaxContextualTuples(n int) Option {
+	return func(o *options) {
+		o.maxContextualTuples = n
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{maxContextualTuples: DefaultMaxContextualTuples}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 func contextWithRequestIsValidated(ctx context.Context) context.Context {
 	return context.WithValue(ctx, requestIsValidatedCtxKey, true)
 }
@@ -26,27 +51,49 @@ func RequestIsValidatedFromContext(ctx context.Context) bool {
 
 // UnaryServerInterceptor returns a new unary server interceptor that runs request validations
 // and injects a bool in the context indicating that validation has been run.
-func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
-	validator := grpcvalidator.UnaryServerInterceptor()
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	if o.maxContextualTuples == DefaultMaxContextualTuples {
+		validator := grpcvalidator.UnaryServerInterceptor()
+
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return validator(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return handler(contextWithRequestIsValidated(ctx), req)
+			})
+		}
+	}
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		return validator(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
-			return handler(contextWithRequestIsValidated(ctx), req)
-		})
+		if err := validateRequest(req, o.maxContextualTuples); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(contextWithRequestIsValidated(ctx), req)
 	}
 }
 
 // StreamServerInterceptor returns a new streaming server interceptor that runs request validations
 // and injects a bool in the context indicating that validation has been run.
-func StreamServerInterceptor() grpc.StreamServerInterceptor {
-	validator := grpcvalidator.StreamServerInterceptor()
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	if o.maxContextualTuples == DefaultMaxContextualTuples {
+		validator := grpcvalidator.StreamServerInterceptor()
 
-	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		return validator(srv, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
-			return handler(srv, &recvWrapper{
-				ctx:          contextWithRequestIsValidated(stream.Context()),
-				ServerStream: ss,
+		return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return validator(srv, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+				return handler(srv, &recvWrapper{
+					ctx:          contextWithRequestIsValidated(stream.Context()),
+					ServerStream: ss,
+				})
 			})
+		}
+	}
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &recvWrapper{
+			ctx:          contextWithRequestIsValidated(stream.Context()),
+			ServerStream: stream,
+			validate:     true,
+			options:      o,
 		})
 	}
 }
@@ -54,9 +101,29 @@ func StreamServerInterceptor() grpc.StreamServerInterceptor {
 type recvWrapper struct {
 	ctx context.Context
 	grpc.ServerStream
+
+	// validate is set when the wrapper validates the received messages itself.
+	validate bool
+	options  *options
 }
 
 // Context returns the context associated with the recvWrapper.
 func (r *recvWrapper) Context() context.Context {
 	return r.ctx
 }
+
+// RecvMsg receives a message from the stream and, if configured to do so, validates it.
+func (r *recvWrapper) RecvMsg(m interface{}) error {
+	if err := r.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if !r.validate {
+		return nil
+	}
+
+	if err := validateRequest(m, r.options.maxContextualTuples); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Package modelvalidation validates authorization models with the validation of the server, but
+// without its dependencies on the datastores, so that other programs can embed it. It compiles to
+// WebAssembly, see cmd/openfga-wasm.
+package modelvalidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openfga/language/pkg/go/transformer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// validationID is the ID of the store and of the model that the models are validated with, which
+// are not part of the validation of a model.
+const validationID = "00000000000000000000000000"
+
+type options struct {
+	maxTypesPerAuthorizationModel    int
+	maxAuthorizationModelSizeInBytes int
+}
+
+// Option defines an option of the validation.
+type Option func(*options)
+
+// WithMaxTypesPerAuthorizationModel sets the maximum number of type definitions of a model, which
+// is serverconfig.DefaultMaxTypesPerAuthorizationModel by default.
+func WithMaxTypesPerAuthorizationModel(maxTypes int) Option {
+	return func(o *options) {
+		o.maxTypesPerAuthorizationModel = maxTypes
+	}
+}
+
+// WithMaxAuthorizationModelSizeInBytes sets the maximum size of the wire-format encoding of a
+// model, which is serverconfig.DefaultMaxAuthorizationModelSizeInBytes by default.
+func WithMaxAuthorizationModelSizeInBytes(maxSize int) Option {
+	return func(o *options) {
+		o.maxAuthorizationModelSizeInBytes = maxSize
+	}
+}
+
+// ValidateDSL parses the model in the DSL and validates it as the WriteAuthorizationModel API
+// does, and returns it.
+func ValidateDSL(ctx context.Context, dsl string, opts ...Option) (*openfgav1.AuthorizationModel, error) {
+	model, err := transformer.TransformDSLToProto(dsl)
+	if err != nil {
+		return nil, err
+	}
+	return model, Validate(ctx, model, opts...)
+}
+
+// ValidateJSON decodes the model in JSON and validates it as the WriteAuthorizationModel API
+// does, and returns it.
+func ValidateJSON(ctx context.Context, data []byte, opts ...Option) (*openfgav1.AuthorizationModel, error) {
+	model := &openfgav1.AuthorizationModel{}
+	if err := protojson.Unmarshal(data, model); err != nil {
+		return nil, fmt.Errorf("invalid authorization model JSON: %w", err)
+	}
+	return model, Validate(ctx, model, opts...)
+}
+
+// Validate validates model as the WriteAuthorizationModel API does. The schema version of the
+// model is set to 1.1 if it is empty, as the API does.
+func Validate(ctx context.Context, model *openfgav1.AuthorizationModel, opts ...Option) error {
+	o := &options{
+		maxTypesPerAuthorizationModel:    serverconfig.DefaultMaxTypesPerAuthorizationModel,
+		maxAuthorizationModelSizeInBytes: serverconfig.DefaultMaxAuthorizationModelSizeInBytes,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if model.GetSchemaVersion() == "" {
+		model.SchemaVersion = typesystem.SchemaVersion1_1
+	}
+
+	req := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         validationID,
+		TypeDefinitions: model.GetTypeDefinitions(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		Conditions:      model.GetConditions(),
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	if len(model.GetTypeDefinitions()) > o.maxTypesPerAuthorizationModel {
+		return fmt.Errorf("exceeded entity limit: %d type definitions in an authorization model", o.maxTypesPerAuthorizationModel)
+	}
+
+	// the model is validated with an ID, as it is written
+	sized := &openfgav1.AuthorizationModel{
+		Id:              validationID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	}
+	if modelSize := proto.Size(sized); modelSize > o.maxAuthorizationModelSizeInBytes {
+		return fmt.Errorf("model exceeds size limit: %d bytes vs %d bytes", modelSize, o.maxAuthorizationModelSizeInBytes)
+	}
+
+	_, err := typesystem.NewAndValidate(ctx, model)
+	return err
+}
+
+// Result is the result of a validation, in a form that can be encoded in JSON, e.g. to be returned
+// to JavaScript.
+type Result struct {
+	Valid bool `json:"valid"`
+
+	// Errors are the errors of an invalid model, e.g. one per syntax error of its DSL.
+	Errors []string `json:"errors,omitempty"`
+
+	// Model is the JSON of a valid model.
+	Model string `json:"model,omitempty"`
+}
+
+// NewResult returns the Result of the validation of model, which returned err.
+func NewResult(model *openfgav1.AuthorizationModel, err error) Result {
+	if err != nil {
+		result := Result{}
+		for _, e := range splitErrors(err) {
+			result.Errors = append(result.Errors, e.Error())
+		}
+		return result
+	}
+
+	data, err := protojson.Marshal(model)
+	if err != nil {
+		return Result{Errors: []string{err.Error()}}
+	}
+	return Result{Valid: true, Model: string(data)}
+}
+
+// splitErrors returns the errors joined in err, e.g. the syntax errors of a DSL.
+func splitErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) && len(joined.Unwrap()) > 0 {
+		return joined.Unwrap()
+	}
+	var wrapped interface{ WrappedErrors() []error }
+	if errors.As(err, &wrapped) && len(wrapped.WrappedErrors()) > 0 {
+		return wrapped.WrappedErrors()
+	}
+	return []error{err}
+}
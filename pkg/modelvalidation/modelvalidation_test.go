@@ -0,0 +1,81 @@
+package modelvalidation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestValidateDSL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid", func(t *testing.T) {
+		model, err := ValidateDSL(ctx, `
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user with in_region]
+			condition in_region(region: string) {
+				region == "eu"
+			}`)
+		require.NoError(t, err)
+
+		result := NewResult(model, err)
+		require.True(t, result.Valid)
+		require.Empty(t, result.Errors)
+		require.Contains(t, result.Model, `"type":"document"`)
+	})
+
+	t.Run("syntax_errors", func(t *testing.T) {
+		model, err := ValidateDSL(ctx, `
+			model
+				schema 1.1
+			type user
+				relations
+					define viewer [user]
+					define editor [user]`)
+		require.Error(t, err)
+
+		result := NewResult(model, err)
+		require.False(t, result.Valid)
+		require.Len(t, result.Errors, 2)
+	})
+
+	t.Run("invalid_model", func(t *testing.T) {
+		_, err := ValidateDSL(ctx, `
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user] or editor`)
+		require.ErrorIs(t, err, typesystem.ErrRelationUndefined)
+	})
+
+	t.Run("too_many_types", func(t *testing.T) {
+		_, err := ValidateDSL(ctx, `
+			model
+				schema 1.1
+			type user
+			type document`, WithMaxTypesPerAuthorizationModel(1))
+		require.ErrorContains(t, err, "exceeded entity limit")
+	})
+}
+
+func TestValidateJSON(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := ValidateJSON(ctx, []byte(`{"schema_version":"1.1","type_definitions":[{"type":"user"}]}`))
+	require.NoError(t, err)
+
+	_, err = ValidateJSON(ctx, []byte(`{"schema_version":"1.1","type_definitions":[]}`))
+	require.Error(t, err)
+
+	_, err = ValidateJSON(ctx, []byte(`not json`))
+	require.ErrorContains(t, err, "invalid authorization model JSON")
+}
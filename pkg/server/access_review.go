@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/accessreview"
+	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ErrAccessReviewDisabled is returned by the access review methods when the access review
+// exports are not enabled with WithAccessReviewEnabled.
+var ErrAccessReviewDisabled = status.Error(codes.Unimplemented, "access review exports are not enabled")
+
+// accessReviewUserFilters returns the user filters of the params of an access_review job.
+func accessReviewUserFilters(params *accessreview.Params) []*openfgav1.UserTypeFilter {
+	userFilters := make([]*openfgav1.UserTypeFilter, 0, len(params.UserFilters))
+	for _, filter := range params.UserFilters {
+		filterType, filterRelation := tuple.SplitObjectRelation(filter)
+		userFilters = append(userFilters, &openfgav1.UserTypeFilter{Type: filterType, Relation: filterRelation})
+	}
+	return userFilters
+}
+
+// accessReviewListUsersRequests returns the ListUsers requests of an access_review job of storeID
+// with params, with a placeholder object ID.
+func accessReviewListUsersRequests(storeID string, params *accessreview.Params) []*openfgav1.ListUsersRequest {
+	userFilters := accessReviewUserFilters(params)
+	listUsersRequests := make([]*openfgav1.ListUsersRequest, 0, len(userFilters))
+	for _, filter := range userFilters {
+		listUsersRequests = append(listUsersRequests, &openfgav1.ListUsersRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: params.AuthorizationModelID,
+			Object:               &openfgav1.Object{Type: params.ObjectType, Id: "access-review"},
+			Relation:             params.Relation,
+			UserFilters:          []*openfgav1.UserTypeFilter{filter},
+		})
+	}
+	return listUsersRequests
+}
+
+// validateAccessReviewParams validates the params of an access_review job of storeID like its
+// ListUsers requests, without its authorization model.
+func validateAccessReviewParams(storeID string, params *accessreview.Params) error {
+	if len(params.UserFilters) == 0 {
+		return status.Error(codes.InvalidArgument, "user_filters must contain at least one filter")
+	}
+	for _, listUsersReq := range accessReviewListUsersRequests(storeID, params) {
+		if err := listUsersReq.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return nil
+}
+
+// runAccessReviewJob validates the params of an access_review job with their authorization model,
+// which may have changed since the job was created, then exports the users to the artifact of the job.
+func (s *Server) runAccessReviewJob(ctx context.Context, storeID string, data json.RawMessage, progress jobs.ProgressFunc) (any, error) {
+	params, err := accessReviewJobParams(data)
+	if err != nil {
+		return nil, err
+	}
+	typesys, err := s.resolveAccessReviewTypesystem(ctx, storeID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.accessReviewExporter.Export(ctx, storeID, jobs.JobIDFromContext(ctx), params.ObjectType, params.Relation,
+		accessReviewUserFilters(params), s.accessReviewListUsers(storeID, params.Relation, typesys), accessreview.ProgressFunc(progress))
+	if err != nil {
+		return nil, err
+	}
+	result.AuthorizationModelID = typesys.GetAuthorizationModelID()
+	return result, nil
+}
+
+// resolveAccessReviewTypesystem resolves the authorization model of the params of an access_review
+// job of storeID, and validates its ListUsers requests with it.
+func (s *Server) resolveAccessReviewTypesystem(ctx context.Context, storeID string, params *accessreview.Params) (*typesystem.TypeSystem, error) {
+	typesys, err := s.resolveTypesystem(ctx, storeID, params.AuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+	for _, listUsersReq := range accessReviewListUsersRequests(storeID, params) {
+		if err := listusers.ValidateListUsersRequest(ctx, listUsersReq, typesys); err != nil {
+			return nil, err
+		}
+	}
+	return typesys, nil
+}
+
+// accessReviewListUsers returns the ListUsersFunc of the access review jobs of storeID, which
+// lists all the users that have relation on an object with the model of typesys.
+func (s *Server) accessReviewListUsers(storeID, relation string, typesys *typesystem.TypeSystem) accessreview.ListUsersFunc {
+	return func(ctx context.Context, object string, userFilter *openfgav1.UserTypeFilter) ([]*openfgav1.User, error) {
+		objectType, objectID := tuple.SplitObject(object)
+		ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+		// the jobs export all the users, however long it takes
+		listUsersQuery := listusers.NewListUsersQuery(s.datastore,
+			nil,
+			listusers.WithResolveNodeLimit(s.resolveNodeLimit),
+			listusers.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+			listusers.WithListUsersQueryLogger(s.logger),
+			listusers.WithListUsersMaxResults(0),
+			listusers.WithListUsersDeadline(0),
+			listusers.WithListUsersMaxConcurrentReads(s.maxConcurrentReadsForListUsers),
+		)
+		resp, err := listUsersQuery.ListUsers(ctx, &openfgav1.ListUsersRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: typesys.GetAuthorizationModelID(),
+			Object:               &openfgav1.Object{Type: objectType, Id: objectID},
+			Relation:             relation,
+			UserFilters:          []*openfgav1.UserTypeFilter{userFilter},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.GetUsers(), nil
+	}
+}
+
+// DownloadAccessReview sends the artifact of a succeeded access_review job in chunks with send. The
+// artifact is only sent by the replica that ran the job.
+func (s *Server) DownloadAccessReview(ctx context.Context, req *accessreview.DownloadRequest, send func(*accessreview.Chunk) error) error {
+	ctx, span := tracer.Start(ctx, "DownloadAccessReview", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("job_id", req.JobID),
+	))
+	defer span.End()
+
+	if s.accessReviewExporter == nil {
+		return ErrAccessReviewDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.ListUsers.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.ListUsers)
+	if err != nil {
+		return err
+	}
+
+	job, err := s.jobsManager.Get(ctx, req.StoreID, req.JobID)
+	if err != nil {
+		return jobsError(err, req.JobID)
+	}
+	if job.Kind != JobKindAccessReview {
+		return status.Error(codes.NotFound, fmt.Sprintf("access review job '%s' not found", req.JobID))
+	}
+	if job.State != jobs.StateSucceeded {
+		return status.Error(codes.FailedPrecondition, fmt.Sprintf("access review job '%s' has not succeeded", req.JobID))
+	}
+
+	artifact, err := s.accessReviewExporter.OpenArtifact(req.JobID)
+	switch {
+	case errors.Is(err, accessreview.ErrArtifactNotFound):
+		return status.Error(codes.NotFound, fmt.Sprintf("the artifact of access review job '%s' is not on this replica, or its retention elapsed", req.JobID))
+	case err != nil:
+		return serverErrors.HandleError("", err)
+	}
+	defer artifact.Close()
+
+	return accessreview.SendArtifact(artifact, send)
+}
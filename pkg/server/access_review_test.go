@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/accessreview"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestAccessReview(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithJobsEnabled(true), WithAccessReviewEnabled(true), WithAccessReviewArtifactDir(t.TempDir()))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "access-review"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user, user:*, group#member] or editor`)
+	written, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "editor", "user:anne"),
+				tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+				tuple.NewTupleKey("group:eng", "member", "user:bob"),
+				tuple.NewTupleKey("document:2", "viewer", "user:*"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	waitForJob := func(t *testing.T, jobID string) *jobs.Job {
+		t.Helper()
+		var job *jobs.Job
+		require.Eventually(t, func() bool {
+			job, err = s.GetJob(ctx, &jobs.GetRequest{StoreID: storeID, JobID: jobID})
+			require.NoError(t, err)
+			return job.State.Finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	download := func(jobID string) ([]byte, error) {
+		var artifact bytes.Buffer
+		err := s.DownloadAccessReview(ctx, &accessreview.DownloadRequest{StoreID: storeID, JobID: jobID}, func(chunk *accessreview.Chunk) error {
+			artifact.Write(chunk.Data)
+			return nil
+		})
+		return artifact.Bytes(), err
+	}
+
+	t.Run("export", func(t *testing.T) {
+		created, err := s.CreateJob(ctx, &jobs.CreateRequest{
+			StoreID: storeID,
+			Kind:    JobKindAccessReview,
+			Params:  json.RawMessage(`{"object_type":"document","relation":"viewer","user_filters":["user"]}`),
+		})
+		require.NoError(t, err)
+
+		job := waitForJob(t, created.ID)
+		require.Equal(t, jobs.StateSucceeded, job.State, job.Error)
+		require.Equal(t, uint64(2), job.Completed)
+
+		var result accessreview.Result
+		require.NoError(t, json.Unmarshal(job.Result, &result))
+		require.Equal(t, written.GetAuthorizationModelId(), result.AuthorizationModelID)
+		require.Equal(t, uint64(2), result.Objects)
+		require.Equal(t, uint64(3), result.Rows)
+
+		artifact, err := download(created.ID)
+		require.NoError(t, err)
+
+		lines := bytes.Split(bytes.TrimSpace(artifact), []byte("\n"))
+		require.Len(t, lines, 3)
+		require.ElementsMatch(t, []string{
+			`{"object":"document:1","relation":"viewer","user":"user:anne"}`,
+			`{"object":"document:1","relation":"viewer","user":"user:bob"}`,
+		}, []string{string(lines[0]), string(lines[1])})
+		require.JSONEq(t, `{"object":"document:2","relation":"viewer","user":"user:*"}`, string(lines[2]))
+	})
+
+	t.Run("invalid_user_filter", func(t *testing.T) {
+		_, err := s.CreateJob(ctx, &jobs.CreateRequest{
+			StoreID: storeID,
+			Kind:    JobKindAccessReview,
+			Params:  json.RawMessage(`{"object_type":"document","relation":"viewer","user_filters":["folder"]}`),
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_type_not_found), status.Code(err))
+	})
+
+	t.Run("not_an_access_review_job", func(t *testing.T) {
+		created, err := s.CreateJob(ctx, &jobs.CreateRequest{
+			StoreID: storeID,
+			Kind:    JobKindCollectOrphans,
+			Params:  json.RawMessage(`{"dry_run":true}`),
+		})
+		require.NoError(t, err)
+		waitForJob(t, created.ID)
+
+		_, err = download(created.ID)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("unknown_job", func(t *testing.T) {
+		_, err := download("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestAccessReviewDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithJobsEnabled(true))
+	t.Cleanup(s.Close)
+
+	_, err := s.CreateJob(context.Background(), &jobs.CreateRequest{
+		StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		Kind:    JobKindAccessReview,
+		Params:  json.RawMessage(`{"object_type":"document","relation":"viewer"}`),
+	})
+	require.ErrorIs(t, err, ErrAccessReviewDisabled)
+
+	err = s.DownloadAccessReview(context.Background(), &accessreview.DownloadRequest{StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV", JobID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"}, func(*accessreview.Chunk) error {
+		return nil
+	})
+	require.ErrorIs(t, err, ErrAccessReviewDisabled)
+
+	_, err = NewServerWithOpts(WithDatastore(ds), WithAccessReviewEnabled(true))
+	require.EqualError(t, err, "access review requires the background jobs to be enabled")
+}
@@ -0,0 +1,271 @@
+package accessreview
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// DefaultArtifactRetention is the default duration for which the artifacts are kept.
+const DefaultArtifactRetention = 24 * time.Hour
+
+const (
+	// artifactExtension is the extension of the files of the artifacts.
+	artifactExtension = ".ndjson"
+
+	// listObjectsPageSize is the number of tuples read per page to list the objects of a type.
+	listObjectsPageSize = 1000
+)
+
+// ErrArtifactNotFound is returned for the artifacts that don't exist on this replica, e.g. because
+// another replica ran their job, or whose retention elapsed.
+var ErrArtifactNotFound = errors.New("access review artifact not found")
+
+// Row is a line of an artifact: User has Relation on Object.
+type Row struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	User     string `json:"user"`
+}
+
+// Params are the params of an access review job, which exports the users of the user filters
+// that have the relation on the objects of the type, with a model of its store, or its latest
+// model if AuthorizationModelID is empty. The user filters are types, such as "user", or usersets,
+// such as "group#member".
+type Params struct {
+	AuthorizationModelID string   `json:"authorization_model_id,omitempty"`
+	ObjectType           string   `json:"object_type"`
+	Relation             string   `json:"relation"`
+	UserFilters          []string `json:"user_filters"`
+}
+
+// Result is the result of an access review job.
+type Result struct {
+	// AuthorizationModelID is the ID of the model that the users were listed with.
+	AuthorizationModelID string `json:"authorization_model_id"`
+
+	// Objects is the number of objects of the type with tuples, whose users were exported.
+	Objects uint64 `json:"objects"`
+
+	// Rows is the number of rows of the artifact.
+	Rows uint64 `json:"rows"`
+}
+
+// ListUsersFunc lists the users of userFilter that have the relation of a job on object, without
+// bounding their number or the duration of the listing.
+type ListUsersFunc func(ctx context.Context, object string, userFilter *openfgav1.UserTypeFilter) ([]*openfgav1.User, error)
+
+// ProgressFunc reports the number of objects whose users are exported, and the number of objects.
+type ProgressFunc func(reviewed, objects uint64)
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithLogger sets the logger of the artifacts that fail to be deleted.
+func WithLogger(l logger.Logger) Option {
+	return func(e *Exporter) {
+		e.logger = l
+	}
+}
+
+// WithArtifactRetention sets the duration for which the artifacts are kept. It defaults to
+// DefaultArtifactRetention.
+func WithArtifactRetention(retention time.Duration) Option {
+	return func(e *Exporter) {
+		e.retention = retention
+	}
+}
+
+// Exporter exports the users that have a relation on the objects of a type to artifacts in a
+// directory, one per job, which are kept until their retention elapses.
+type Exporter struct {
+	datastore storage.RelationshipTupleReader
+	dir       string
+	logger    logger.Logger
+	retention time.Duration
+
+	mu sync.Mutex
+	// exporting are the IDs of the jobs whose artifacts are being written, which are not pruned
+	exporting map[string]struct{}
+}
+
+// NewExporter returns an Exporter that lists the objects of datastore and writes the artifacts to
+// dir, which is created if needed.
+func NewExporter(datastore storage.RelationshipTupleReader, dir string, opts ...Option) (*Exporter, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the access review artifact directory: %w", err)
+	}
+
+	e := &Exporter{
+		datastore: datastore,
+		dir:       dir,
+		logger:    logger.NewNoopLogger(),
+		retention: DefaultArtifactRetention,
+		exporting: map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Export writes the users of userFilters that have relation on the objects of objectType in
+// storeID, listed with listUsers, to the artifact of the job jobID until it is done or ctx is
+// canceled, reporting its progress, and returns the number of objects and rows of the artifact.
+// The artifact is deleted if the export fails.
+func (e *Exporter) Export(ctx context.Context, storeID, jobID, objectType, relation string, userFilters []*openfgav1.UserTypeFilter, listUsers ListUsersFunc, progress ProgressFunc) (*Result, error) {
+	path, ok := e.artifactPath(jobID)
+	if !ok {
+		return nil, fmt.Errorf("invalid access review job ID '%s'", jobID)
+	}
+	e.prune()
+
+	e.mu.Lock()
+	e.exporting[jobID] = struct{}{}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.exporting, jobID)
+		e.mu.Unlock()
+	}()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the access review artifact: %w", err)
+	}
+
+	result, err := e.export(ctx, f, storeID, objectType, relation, userFilters, listUsers, progress)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return result, nil
+}
+
+// OpenArtifact opens the artifact of the succeeded job jobID.
+func (e *Exporter) OpenArtifact(jobID string) (io.ReadCloser, error) {
+	path, ok := e.artifactPath(jobID)
+	if !ok {
+		return nil, ErrArtifactNotFound
+	}
+	e.prune()
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArtifactNotFound
+	}
+	return f, err
+}
+
+// artifactPath returns the path of the artifact of the job jobID, unless it is not a ULID.
+func (e *Exporter) artifactPath(jobID string) (string, bool) {
+	if _, err := ulid.ParseStrict(jobID); err != nil {
+		return "", false
+	}
+	return filepath.Join(e.dir, jobID+artifactExtension), true
+}
+
+// prune deletes the artifacts that were last written longer than the retention ago.
+func (e *Exporter) prune() {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		e.logger.Error("failed to list the access review artifacts", zap.Error(err))
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, entry := range entries {
+		jobID, ok := strings.CutSuffix(entry.Name(), artifactExtension)
+		if !ok {
+			continue
+		}
+		if _, ok := e.exporting[jobID]; ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) <= e.retention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(e.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			e.logger.Error("failed to delete an access review artifact", zap.String("job_id", jobID), zap.Error(err))
+		}
+	}
+}
+
+func (e *Exporter) export(ctx context.Context, f *os.File, storeID, objectType, relation string, userFilters []*openfgav1.UserTypeFilter, listUsers ListUsersFunc, progress ProgressFunc) (*Result, error) {
+	objects, err := e.listObjects(ctx, storeID, objectType)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Objects: uint64(len(objects))}
+	progress(0, result.Objects)
+
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	for i, object := range objects {
+		for _, filter := range userFilters {
+			users, err := listUsers(ctx, object, filter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list the users of '%s': %w", object, err)
+			}
+			for _, user := range users {
+				row := &Row{Object: object, Relation: relation, User: tuple.UserProtoToString(user)}
+				if err := encoder.Encode(row); err != nil {
+					return nil, err
+				}
+			}
+			result.Rows += uint64(len(users))
+		}
+		progress(uint64(i+1), result.Objects)
+	}
+	return result, w.Flush()
+}
+
+// listObjects returns the objects of objectType that have tuples in storeID, in the order of
+// their first tuple.
+func (e *Exporter) listObjects(ctx context.Context, storeID, objectType string) ([]string, error) {
+	var objects []string
+	seen := map[string]struct{}{}
+	token := ""
+	for {
+		tuples, next, err := e.datastore.ReadPage(ctx, storeID, storage.ReadFilter{Object: objectType + ":"}, storage.ReadPageOptions{
+			Pagination:  storage.NewPaginationOptions(listObjectsPageSize, token),
+			Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tuples {
+			object := t.GetKey().GetObject()
+			if _, ok := seen[object]; !ok {
+				seen[object] = struct{}{}
+				objects = append(objects, object)
+			}
+		}
+		if next == "" || len(tuples) == 0 {
+			return objects, nil
+		}
+		token = next
+	}
+}
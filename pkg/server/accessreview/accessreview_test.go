@@ -0,0 +1,155 @@
+package accessreview
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func readArtifact(t *testing.T, e *Exporter, jobID string) []Row {
+	t.Helper()
+
+	artifact, err := e.OpenArtifact(jobID)
+	require.NoError(t, err)
+	defer artifact.Close()
+
+	var rows []Row
+	scanner := bufio.NewScanner(artifact)
+	for scanner.Scan() {
+		var row Row
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		rows = append(rows, row)
+	}
+	require.NoError(t, scanner.Err())
+	return rows
+}
+
+func TestExporter(t *testing.T) {
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.Write(ctx, storeID, nil, storage.Writes{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:1", "editor", "user:bob"),
+		tuple.NewTupleKey("document:2", "viewer", "user:bob"),
+		tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+	}))
+
+	// lists the users of the viewer tuples, with a wildcard on document:2
+	listUsers := func(_ context.Context, object string, userFilter *openfgav1.UserTypeFilter) ([]*openfgav1.User, error) {
+		require.Equal(t, "user", userFilter.GetType())
+		switch object {
+		case "document:1":
+			return []*openfgav1.User{{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "anne"}}}}, nil
+		case "document:2":
+			return []*openfgav1.User{
+				{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "bob"}}},
+				{User: &openfgav1.User_Wildcard{Wildcard: &openfgav1.TypedWildcard{Type: "user"}}},
+			}, nil
+		}
+		return nil, errors.New("unexpected object " + object)
+	}
+
+	dir := t.TempDir()
+	e, err := NewExporter(ds, dir)
+	require.NoError(t, err)
+
+	userFilters := []*openfgav1.UserTypeFilter{{Type: "user"}}
+
+	t.Run("succeeded", func(t *testing.T) {
+		jobID := ulid.Make().String()
+		var progress [][2]uint64
+		result, err := e.Export(ctx, storeID, jobID, "document", "viewer", userFilters, listUsers, func(reviewed, objects uint64) {
+			progress = append(progress, [2]uint64{reviewed, objects})
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), result.Objects)
+		require.Equal(t, uint64(3), result.Rows)
+		require.Equal(t, [][2]uint64{{0, 2}, {1, 2}, {2, 2}}, progress)
+
+		require.Equal(t, []Row{
+			{Object: "document:1", Relation: "viewer", User: "user:anne"},
+			{Object: "document:2", Relation: "viewer", User: "user:bob"},
+			{Object: "document:2", Relation: "viewer", User: "user:*"},
+		}, readArtifact(t, e, jobID))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		failing := func(context.Context, string, *openfgav1.UserTypeFilter) ([]*openfgav1.User, error) {
+			return nil, errors.New("boom")
+		}
+		jobID := ulid.Make().String()
+		_, err := e.Export(ctx, storeID, jobID, "document", "viewer", userFilters, failing, func(uint64, uint64) {})
+		require.ErrorContains(t, err, "boom")
+
+		_, err = e.OpenArtifact(jobID)
+		require.ErrorIs(t, err, ErrArtifactNotFound)
+		_, err = os.Stat(filepath.Join(dir, jobID+artifactExtension))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		_, err := e.OpenArtifact(ulid.Make().String())
+		require.ErrorIs(t, err, ErrArtifactNotFound)
+
+		_, err = e.OpenArtifact("../" + filepath.Base(dir))
+		require.ErrorIs(t, err, ErrArtifactNotFound)
+	})
+}
+
+func TestExporterRetention(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	e, err := NewExporter(ds, t.TempDir(), WithArtifactRetention(time.Millisecond))
+	require.NoError(t, err)
+
+	// the store has no documents, so the export succeeds without listing users
+	jobID := ulid.Make().String()
+	_, err = e.Export(context.Background(), ulid.Make().String(), jobID, "document", "viewer", []*openfgav1.UserTypeFilter{{Type: "user"}}, nil, func(uint64, uint64) {})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := e.OpenArtifact(jobID)
+		return errors.Is(err, ErrArtifactNotFound)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSendArtifact(t *testing.T) {
+	data := make([]byte, chunkSize+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	var chunks [][]byte
+	err := SendArtifact(bytes.NewReader(data), func(chunk *Chunk) error {
+		chunks = append(chunks, append([]byte(nil), chunk.Data...))
+		return nil
+	})
+	require.NoError(t, err)
+
+	var received []byte
+	for _, chunk := range chunks {
+		require.LessOrEqual(t, len(chunk), chunkSize)
+		received = append(received, chunk...)
+	}
+	require.Equal(t, data, received)
+}
@@ -0,0 +1,19 @@
+// Package accessreview exports all the users that have a relation on all the objects of a type,
+// for the access reviews of compliance audits, whose size exceeds what a synchronous ListUsers can
+// return.
+//
+// The exports run as the access_review jobs of the jobs package, so they are persisted in the
+// datastore and their state, progress and estimated completion are reported by the jobs service of
+// any replica. Each job lists the objects of the type that have tuples, then runs an exhaustive
+// ListUsers for each of them and each of its user filters, with the authorization model that was
+// the latest one when the job started. The users are written to an artifact, one row per line in
+// JSON:
+//
+//	{"object":"document:roadmap","relation":"viewer","user":"user:anne"}
+//
+// The artifact of a succeeded job is streamed by the access review service, whose messages are
+// encoded as JSON: /openfga.accessreview.v1.AccessReviewService/Download, also served over HTTP as
+// GET /stores/{store_id}/jobs/{job_id}/artifact. The artifacts are written to the disk of the
+// replica that ran their job, which is the only one that serves them, and are deleted when their
+// retention elapses.
+package accessreview
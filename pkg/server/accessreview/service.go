@@ -0,0 +1,142 @@
+package accessreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the access review service.
+	ServiceName = "openfga.accessreview.v1.AccessReviewService"
+
+	// DownloadMethod is the full name of the server streaming method that streams the artifact of
+	// an access review job.
+	DownloadMethod = "/" + ServiceName + "/Download"
+
+	// codecName is the content-subtype of the access review requests. The access review service is
+	// not part of the OpenFGA API, so its messages are encoded as JSON instead of generated
+	// protobufs.
+	codecName = "openfga-accessreview-json"
+
+	// chunkSize is the size of the chunks of the streamed artifacts.
+	chunkSize = 256 * 1024
+)
+
+var downloadStreamDesc = grpc.StreamDesc{
+	StreamName:    "Download",
+	ServerStreams: true,
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// DownloadRequest requests the artifact of a succeeded access review job of a store.
+type DownloadRequest struct {
+	StoreID string `json:"store_id"`
+	JobID   string `json:"job_id"`
+}
+
+// Chunk is a chunk of a streamed artifact.
+type Chunk struct {
+	Data []byte `json:"data"`
+}
+
+// Server streams the artifacts of the access review jobs. It is implemented by the OpenFGA server.
+type Server interface {
+	DownloadAccessReview(ctx context.Context, req *DownloadRequest, send func(*Chunk) error) error
+}
+
+// RegisterServer registers the access review service, which streams the artifacts of the jobs
+// with srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	desc := downloadStreamDesc
+	desc.Handler = downloadHandler
+
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods:     []grpc.MethodDesc{},
+		Streams:     []grpc.StreamDesc{desc},
+		Metadata:    "pkg/server/accessreview/service.go",
+	}, srv)
+}
+
+func downloadHandler(srv any, stream grpc.ServerStream) error {
+	in := &DownloadRequest{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(Server).DownloadAccessReview(stream.Context(), in, func(chunk *Chunk) error {
+		return stream.SendMsg(chunk)
+	})
+}
+
+// SendArtifact sends the artifact read from r in chunks with send.
+func SendArtifact(r io.Reader, send func(*Chunk) error) error {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if sendErr := send(&Chunk{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Download writes the artifact of an access review job streamed on conn to w.
+func Download(ctx context.Context, conn grpc.ClientConnInterface, req *DownloadRequest, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &downloadStreamDesc, DownloadMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		chunk := &Chunk{}
+		err := stream.RecvMsg(chunk)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+}
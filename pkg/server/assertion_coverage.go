@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/assertioncoverage"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+// ErrAssertionCoverageDisabled is returned by GetAssertionCoverage when the assertion coverage is
+// not enabled with WithAssertionCoverageEnabled.
+var ErrAssertionCoverageDisabled = status.Error(codes.Unimplemented, "assertion coverage is not enabled")
+
+// GetAssertionCoverage reports which relations of a model, and which branches of their rewrites,
+// are exercised by the assertions of the model, or of the latest model of the store if req doesn't
+// set one. It is authorized like ReadAssertions.
+func (s *Server) GetAssertionCoverage(ctx context.Context, req *assertioncoverage.GetReportRequest) (*assertioncoverage.Report, error) {
+	ctx, span := tracer.Start(ctx, "GetAssertionCoverage", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.KeyValue{Key: "authorization_model_id", Value: attribute.StringValue(req.AuthorizationModelID)},
+	))
+	defer span.End()
+
+	if !s.assertionCoverageEnabled {
+		return nil, ErrAssertionCoverageDisabled
+	}
+
+	var err error
+	if req.AuthorizationModelID == "" {
+		err = (&openfgav1.ReadAuthorizationModelsRequest{StoreId: req.StoreID}).Validate()
+	} else {
+		err = (&openfgav1.ReadAssertionsRequest{StoreId: req.StoreID, AuthorizationModelId: req.AuthorizationModelID}).Validate()
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.ReadAssertions.String(),
+	})
+
+	err = s.checkAuthz(ctx, req.StoreID, apimethod.ReadAssertions)
+	if err != nil {
+		return nil, err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, req.StoreID, req.AuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := commands.NewReadAssertionsQuery(s.datastore, commands.WithReadAssertionsQueryLogger(s.logger))
+	assertions, err := q.Execute(ctx, req.StoreID, typesys.GetAuthorizationModelID())
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := assertioncoverage.Compute(ctx, s.datastore, typesys, req.StoreID, assertions.GetAssertions(),
+		assertioncoverage.WithResolveNodeLimit(s.resolveNodeLimit),
+	)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/assertioncoverage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestGetAssertionCoverage(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithAssertionCoverageEnabled(true))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "coverage"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user] or editor`)
+	writeModel, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.WriteAssertions(ctx, &openfgav1.WriteAssertionsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: writeModel.GetAuthorizationModelId(),
+		Assertions: []*openfgav1.Assertion{
+			{
+				TupleKey:         &openfgav1.AssertionTupleKey{Object: "document:1", Relation: "viewer", User: "user:anne"},
+				Expectation:      true,
+				ContextualTuples: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("latest_model", func(t *testing.T) {
+		report, err := s.GetAssertionCoverage(ctx, &assertioncoverage.GetReportRequest{StoreID: storeID})
+		require.NoError(t, err)
+		require.Equal(t, writeModel.GetAuthorizationModelId(), report.AuthorizationModelID)
+		require.Equal(t, 1, report.Assertions)
+		require.Empty(t, report.FailedAssertions)
+		require.Equal(t, 3, report.Branches)
+		require.Equal(t, 1, report.ExercisedBranches)
+	})
+
+	t.Run("unknown_model", func(t *testing.T) {
+		_, err := s.GetAssertionCoverage(ctx, &assertioncoverage.GetReportRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid_store_id", func(t *testing.T) {
+		_, err := s.GetAssertionCoverage(ctx, &assertioncoverage.GetReportRequest{StoreID: "invalid"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestGetAssertionCoverageDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	_, err := s.GetAssertionCoverage(context.Background(), &assertioncoverage.GetReportRequest{StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"})
+	require.ErrorIs(t, err, ErrAssertionCoverageDisabled)
+}
@@ -0,0 +1,489 @@
+package assertioncoverage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/checkutil"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ErrResolveDepthExceeded fails the evaluation of an assertion whose resolution is deeper than the
+// resolve node limit.
+var ErrResolveDepthExceeded = errors.New("the resolution of the assertion exceeds the resolve node limit")
+
+// Report is the coverage of the relations of an authorization model by its assertions.
+type Report struct {
+	AuthorizationModelID string `json:"authorization_model_id"`
+
+	// Assertions is the number of assertions of the model.
+	Assertions int `json:"assertions"`
+
+	// FailedAssertions are the assertions whose expectation is not met by the tuples of the store.
+	FailedAssertions []*FailedAssertion `json:"failed_assertions,omitempty"`
+
+	// Branches is the number of rewrite branches of the model, and ExercisedBranches the number of
+	// those exercised by the assertions.
+	Branches          int `json:"branches"`
+	ExercisedBranches int `json:"exercised_branches"`
+
+	// Coverage is the ratio of the exercised branches, between 0 and 1. It is 1 for the models
+	// without relations.
+	Coverage float64 `json:"coverage"`
+
+	// Relations are the relations of the model, sorted by type and relation.
+	Relations []*RelationCoverage `json:"relations"`
+}
+
+// RelationCoverage is the coverage of a relation of the model.
+type RelationCoverage struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation"`
+
+	// Asserted is true if an assertion is about the relation itself.
+	Asserted bool `json:"asserted"`
+
+	// Branches are the branches of the rewrite of the relation, in the order of the model.
+	Branches []*BranchCoverage `json:"branches"`
+}
+
+// BranchCoverage is the coverage of a branch of the rewrite of a relation: its direct
+// assignments, a computed relation, a tuple to userset, or a subtracted relation of an exclusion.
+type BranchCoverage struct {
+	// Rewrite is the branch in the modeling language, e.g. "[user, group#member]", "editor",
+	// "viewer from parent" or "but not blocked".
+	Rewrite string `json:"rewrite"`
+
+	// Exercised is true if the branch determines the result of an assertion: it is on the path
+	// that grants the relation of an allowed assertion, or, for a subtracted relation, on the path
+	// that denies it.
+	Exercised bool `json:"exercised"`
+}
+
+// FailedAssertion is an assertion whose expectation is not met, or that can't be evaluated.
+type FailedAssertion struct {
+	Object      string `json:"object"`
+	Relation    string `json:"relation"`
+	User        string `json:"user"`
+	Expectation bool   `json:"expectation"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Option is an option of Compute.
+type Option func(*analyzer)
+
+// WithResolveNodeLimit bounds the depth of the resolution of the assertions.
+func WithResolveNodeLimit(limit uint32) Option {
+	return func(a *analyzer) {
+		a.resolveNodeLimit = limit
+	}
+}
+
+// WithConsistency sets the consistency preference of the reads of the tuples.
+func WithConsistency(consistency openfgav1.ConsistencyPreference) Option {
+	return func(a *analyzer) {
+		a.consistency = consistency
+	}
+}
+
+// evidence is the set of the branches that determine the result of an assertion.
+type evidence map[*BranchCoverage]struct{}
+
+func (e evidence) merge(other evidence) evidence {
+	if e == nil {
+		e = evidence{}
+	}
+	for b := range other {
+		e[b] = struct{}{}
+	}
+	return e
+}
+
+type analyzer struct {
+	datastore        storage.RelationshipTupleReader
+	typesys          *typesystem.TypeSystem
+	storeID          string
+	resolveNodeLimit uint32
+	consistency      openfgav1.ConsistencyPreference
+
+	// branches are the branches of the rewrites of the model, by their node
+	branches map[*openfgav1.Userset]*BranchCoverage
+}
+
+// Compute evaluates the assertions of the model of typesys against the tuples of the store and
+// returns the branches of the rewrites of the model that they exercise. The assertions are
+// evaluated like Check, with their contextual tuples and context.
+func Compute(ctx context.Context, datastore storage.RelationshipTupleReader, typesys *typesystem.TypeSystem, storeID string, assertions []*openfgav1.Assertion, opts ...Option) (*Report, error) {
+	a := &analyzer{
+		datastore:        datastore,
+		typesys:          typesys,
+		storeID:          storeID,
+		resolveNodeLimit: serverconfig.DefaultResolveNodeLimit,
+		branches:         map[*openfgav1.Userset]*BranchCoverage{},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	report := &Report{
+		AuthorizationModelID: typesys.GetAuthorizationModelID(),
+		Assertions:           len(assertions),
+		Relations:            []*RelationCoverage{},
+	}
+	relations := map[string]*RelationCoverage{}
+
+	allRelations := typesys.GetAllRelations()
+	types := make([]string, 0, len(allRelations))
+	for objectType := range allRelations {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+	for _, objectType := range types {
+		names := make([]string, 0, len(allRelations[objectType]))
+		for name := range allRelations[objectType] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rc := &RelationCoverage{Type: objectType, Relation: name, Branches: []*BranchCoverage{}}
+			a.collectBranches(rc, objectType, name, allRelations[objectType][name].GetRewrite(), "")
+			relations[tuple.ToObjectRelationString(objectType, name)] = rc
+			report.Relations = append(report.Relations, rc)
+		}
+	}
+
+	for _, assertion := range assertions {
+		tk := assertion.GetTupleKey()
+		if rc, ok := relations[tuple.ToObjectRelationString(tuple.GetType(tk.GetObject()), tk.GetRelation())]; ok {
+			rc.Asserted = true
+		}
+
+		e := &evaluation{
+			analyzer: a,
+			reader:   storagewrappers.NewCombinedTupleReader(datastore, assertion.GetContextualTuples()),
+			context:  assertion.GetContext(),
+			visiting: map[string]struct{}{},
+		}
+		allowed, ev, err := e.check(ctx, tk.GetObject(), tk.GetRelation(), tk.GetUser(), 0)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil || allowed != assertion.GetExpectation() {
+			failed := &FailedAssertion{
+				Object:      tk.GetObject(),
+				Relation:    tk.GetRelation(),
+				User:        tk.GetUser(),
+				Expectation: assertion.GetExpectation(),
+			}
+			if err != nil {
+				failed.Error = err.Error()
+			}
+			report.FailedAssertions = append(report.FailedAssertions, failed)
+			continue
+		}
+		for b := range ev {
+			b.Exercised = true
+		}
+	}
+
+	for _, rc := range report.Relations {
+		for _, b := range rc.Branches {
+			report.Branches++
+			if b.Exercised {
+				report.ExercisedBranches++
+			}
+		}
+	}
+	report.Coverage = 1
+	if report.Branches > 0 {
+		report.Coverage = float64(report.ExercisedBranches) / float64(report.Branches)
+	}
+
+	return report, nil
+}
+
+// collectBranches adds the leaves of rewrite to the branches of rc. The prefix is prepended to the
+// rewrites of the branches, e.g. "but not " for the subtracted relations.
+func (a *analyzer) collectBranches(rc *RelationCoverage, objectType, relation string, rewrite *openfgav1.Userset, prefix string) {
+	var branch string
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		refs, _ := a.typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+		assignable := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			assignable = append(assignable, formatRelationReference(ref))
+		}
+		branch = "[" + strings.Join(assignable, ", ") + "]"
+	case *openfgav1.Userset_ComputedUserset:
+		branch = rw.ComputedUserset.GetRelation()
+	case *openfgav1.Userset_TupleToUserset:
+		branch = rw.TupleToUserset.GetComputedUserset().GetRelation() + " from " + rw.TupleToUserset.GetTupleset().GetRelation()
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			a.collectBranches(rc, objectType, relation, child, prefix)
+		}
+		return
+	case *openfgav1.Userset_Intersection:
+		for _, child := range rw.Intersection.GetChild() {
+			a.collectBranches(rc, objectType, relation, child, prefix)
+		}
+		return
+	case *openfgav1.Userset_Difference:
+		a.collectBranches(rc, objectType, relation, rw.Difference.GetBase(), prefix)
+		a.collectBranches(rc, objectType, relation, rw.Difference.GetSubtract(), "but not ")
+		return
+	default:
+		return
+	}
+
+	b := &BranchCoverage{Rewrite: prefix + branch}
+	a.branches[rewrite] = b
+	rc.Branches = append(rc.Branches, b)
+}
+
+func formatRelationReference(ref *openfgav1.RelationReference) string {
+	s := ref.GetType()
+	switch {
+	case ref.GetWildcard() != nil:
+		s = tuple.TypedPublicWildcard(s)
+	case ref.GetRelation() != "":
+		s = tuple.ToObjectRelationString(s, ref.GetRelation())
+	}
+	if ref.GetCondition() != "" {
+		s += " with " + ref.GetCondition()
+	}
+	return s
+}
+
+// evaluation holds the state of the evaluation of an assertion.
+type evaluation struct {
+	*analyzer
+	reader   storage.RelationshipTupleReader
+	context  *structpb.Struct
+	visiting map[string]struct{}
+}
+
+// check returns whether the user has the relation on the object, and the branches that determine
+// the result.
+func (e *evaluation) check(ctx context.Context, object, relation, user string, depth uint32) (bool, evidence, error) {
+	if tuple.ToObjectRelationString(object, relation) == user {
+		return true, nil, nil
+	}
+	if depth >= e.resolveNodeLimit {
+		return false, nil, ErrResolveDepthExceeded
+	}
+
+	key := tuple.ToObjectRelationString(object, relation) + "@" + user
+	if _, ok := e.visiting[key]; ok {
+		return false, nil, nil
+	}
+	e.visiting[key] = struct{}{}
+	defer delete(e.visiting, key)
+
+	rel, err := e.typesys.GetRelation(tuple.GetType(object), relation)
+	if err != nil {
+		return false, nil, err
+	}
+	return e.rewrite(ctx, object, relation, rel.GetRewrite(), user, depth)
+}
+
+func (e *evaluation) rewrite(ctx context.Context, object, relation string, rewrite *openfgav1.Userset, user string, depth uint32) (bool, evidence, error) {
+	var (
+		ok  bool
+		ev  evidence
+		err error
+	)
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		ok, ev, err = e.direct(ctx, object, relation, user, depth)
+	case *openfgav1.Userset_ComputedUserset:
+		ok, ev, err = e.check(ctx, object, rw.ComputedUserset.GetRelation(), user, depth+1)
+	case *openfgav1.Userset_TupleToUserset:
+		ok, ev, err = e.tupleToUserset(ctx, object, rw.TupleToUserset, user, depth)
+	case *openfgav1.Userset_Union:
+		var denied evidence
+		for _, child := range rw.Union.GetChild() {
+			ok, ev, err := e.rewrite(ctx, object, relation, child, user, depth)
+			if err != nil || ok {
+				return ok, ev, err
+			}
+			denied = denied.merge(ev)
+		}
+		return false, denied, nil
+	case *openfgav1.Userset_Intersection:
+		allowed := true
+		var all evidence
+		for _, child := range rw.Intersection.GetChild() {
+			ok, ev, err := e.rewrite(ctx, object, relation, child, user, depth)
+			if err != nil {
+				return false, nil, err
+			}
+			allowed = allowed && ok
+			all = all.merge(ev)
+		}
+		return allowed, all, nil
+	case *openfgav1.Userset_Difference:
+		ok, base, err := e.rewrite(ctx, object, relation, rw.Difference.GetBase(), user, depth)
+		if err != nil || !ok {
+			return false, base, err
+		}
+		excluded, subtract, err := e.rewrite(ctx, object, relation, rw.Difference.GetSubtract(), user, depth)
+		if err != nil {
+			return false, nil, err
+		}
+		if excluded {
+			return false, base.merge(subtract), nil
+		}
+		return true, base, nil
+	default:
+		return false, nil, fmt.Errorf("unsupported rewrite %T", rw)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	// the leaves of the rewrites are exercised when they grant the relation
+	if ok {
+		if b, found := e.branches[rewrite]; found {
+			ev = ev.merge(evidence{b: {}})
+		}
+	}
+	return ok, ev, nil
+}
+
+// direct finds a tuple of the relation with the user, the public wildcard of its type, or a
+// userset that grants it the relation.
+func (e *evaluation) direct(ctx context.Context, object, relation, user string, depth uint32) (bool, evidence, error) {
+	users := []string{user}
+	// the usersets and the wildcards are not granted the relation by the public wildcards
+	if !tuple.IsObjectRelation(user) && !tuple.IsTypedWildcard(user) {
+		users = append(users, tuple.TypedPublicWildcard(tuple.GetType(user)))
+	}
+
+	for _, u := range users {
+		t, err := e.reader.ReadUserTuple(ctx, e.storeID, storage.ReadUserTupleFilter{
+			Object:   object,
+			Relation: relation,
+			User:     u,
+		}, storage.ReadUserTupleOptions{
+			Consistency: storage.ConsistencyOptions{Preference: e.consistency},
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return false, nil, err
+		}
+		ok, err := e.conditionMet(ctx, t.GetKey())
+		if err != nil || ok {
+			return ok, nil, err
+		}
+	}
+
+	iter, err := e.reader.ReadUsersetTuples(ctx, e.storeID, storage.ReadUsersetTuplesFilter{
+		Object:   object,
+		Relation: relation,
+	}, storage.ReadUsersetTuplesOptions{
+		Consistency: storage.ConsistencyOptions{Preference: e.consistency},
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	defer iter.Stop()
+
+	var denied evidence
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return false, denied, nil
+			}
+			return false, nil, err
+		}
+
+		tk := t.GetKey()
+		if !tuple.IsObjectRelation(tk.GetUser()) {
+			continue
+		}
+		ok, err := e.conditionMet(ctx, tk)
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		usersetObject, usersetRelation := tuple.SplitObjectRelation(tk.GetUser())
+		ok, ev, err := e.check(ctx, usersetObject, usersetRelation, user, depth+1)
+		if err != nil || ok {
+			return ok, ev, err
+		}
+		denied = denied.merge(ev)
+	}
+}
+
+// tupleToUserset finds a tuple of the tupleset relation whose object grants the user the computed
+// relation.
+func (e *evaluation) tupleToUserset(ctx context.Context, object string, ttu *openfgav1.TupleToUserset, user string, depth uint32) (bool, evidence, error) {
+	iter, err := e.reader.Read(ctx, e.storeID, storage.ReadFilter{
+		Object:   object,
+		Relation: ttu.GetTupleset().GetRelation(),
+	}, storage.ReadOptions{
+		Consistency: storage.ConsistencyOptions{Preference: e.consistency},
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	defer iter.Stop()
+
+	computedRelation := ttu.GetComputedUserset().GetRelation()
+	var denied evidence
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return false, denied, nil
+			}
+			return false, nil, err
+		}
+
+		tk := t.GetKey()
+		tuplesetObject := tk.GetUser()
+		if tuple.IsObjectRelation(tuplesetObject) || tuple.IsTypedWildcard(tuplesetObject) {
+			continue
+		}
+		// the computed relation is not defined on all the types of the tupleset
+		if _, err := e.typesys.GetRelation(tuple.GetType(tuplesetObject), computedRelation); err != nil {
+			continue
+		}
+
+		ok, err := e.conditionMet(ctx, tk)
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		ok, ev, err := e.check(ctx, tuplesetObject, computedRelation, user, depth+1)
+		if err != nil || ok {
+			return ok, ev, err
+		}
+		denied = denied.merge(ev)
+	}
+}
+
+func (e *evaluation) conditionMet(ctx context.Context, tk *openfgav1.TupleKey) (bool, error) {
+	return checkutil.BuildTupleKeyConditionFilter(ctx, e.context, e.typesys)(tk)
+}
@@ -0,0 +1,168 @@
+package assertioncoverage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// exercised returns the exercised branches of the report, by relation.
+func exercised(report *Report) map[string][]string {
+	res := map[string][]string{}
+	for _, rc := range report.Relations {
+		for _, b := range rc.Branches {
+			if b.Exercised {
+				key := tuple.ToObjectRelationString(rc.Type, rc.Relation)
+				res[key] = append(res[key], b.Rewrite)
+			}
+		}
+	}
+	return res
+}
+
+func assertion(object, relation, user string, expectation bool, contextualTuples ...*openfgav1.TupleKey) *openfgav1.Assertion {
+	return &openfgav1.Assertion{
+		TupleKey: &openfgav1.AssertionTupleKey{
+			Object:   object,
+			Relation: relation,
+			User:     user,
+		},
+		Expectation:      expectation,
+		ContextualTuples: contextualTuples,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define allowed: [user with in_network]
+				define blocked: [user]
+				define editor: [user]
+				define viewer: ([user] or editor or viewer from parent) but not blocked
+				define can_share: editor and allowed
+		condition in_network(ip: string) {
+			ip == "10.0.0.1"
+		}`)
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	require.NoError(t, err)
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	storeID := ulid.Make().String()
+
+	err = ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		tuple.NewTupleKey("document:1", "parent", "folder:x"),
+		tuple.NewTupleKey("folder:x", "viewer", "user:bob"),
+		tuple.NewTupleKey("document:1", "viewer", "user:charlie"),
+		tuple.NewTupleKey("document:1", "blocked", "user:charlie"),
+		tuple.NewTupleKeyWithCondition("document:1", "allowed", "user:anne", "in_network", nil),
+	})
+	require.NoError(t, err)
+
+	t.Run("without_assertions", func(t *testing.T) {
+		report, err := Compute(ctx, ds, typesys, storeID, nil)
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), report.AuthorizationModelID)
+		require.Equal(t, 11, report.Branches)
+		require.Zero(t, report.ExercisedBranches)
+		require.Zero(t, report.Coverage)
+
+		require.Len(t, report.Relations, 7)
+		viewer := report.Relations[5]
+		require.Equal(t, "document", viewer.Type)
+		require.Equal(t, "viewer", viewer.Relation)
+		require.False(t, viewer.Asserted)
+		rewrites := make([]string, 0, len(viewer.Branches))
+		for _, b := range viewer.Branches {
+			rewrites = append(rewrites, b.Rewrite)
+		}
+		require.Equal(t, []string{"[user]", "editor", "viewer from parent", "but not blocked"}, rewrites)
+		require.Equal(t, "[user with in_network]", report.Relations[0].Branches[0].Rewrite)
+	})
+
+	t.Run("allowed_assertions_exercise_the_granting_path", func(t *testing.T) {
+		report, err := Compute(ctx, ds, typesys, storeID, []*openfgav1.Assertion{
+			assertion("document:1", "viewer", "user:anne", true),
+			assertion("document:1", "viewer", "user:dave", true, tuple.NewTupleKey("folder:x", "viewer", "user:dave")),
+		})
+		require.NoError(t, err)
+		require.Empty(t, report.FailedAssertions)
+		require.Equal(t, map[string][]string{
+			"document#viewer": {"editor", "viewer from parent"},
+			"document#editor": {"[user]"},
+			"folder#viewer":   {"[user]"},
+		}, exercised(report))
+		require.Equal(t, 4, report.ExercisedBranches)
+		require.InDelta(t, 4.0/11, report.Coverage, 0.001)
+	})
+
+	t.Run("denied_assertions_exercise_the_exclusions", func(t *testing.T) {
+		report, err := Compute(ctx, ds, typesys, storeID, []*openfgav1.Assertion{
+			assertion("document:1", "viewer", "user:charlie", false),
+			assertion("document:1", "viewer", "user:erin", false),
+		})
+		require.NoError(t, err)
+		require.Empty(t, report.FailedAssertions)
+		require.Equal(t, map[string][]string{
+			"document#viewer":  {"[user]", "but not blocked"},
+			"document#blocked": {"[user]"},
+		}, exercised(report))
+	})
+
+	t.Run("conditions_are_evaluated_with_the_context_of_the_assertions", func(t *testing.T) {
+		inNetwork := assertion("document:1", "can_share", "user:anne", true)
+		inNetwork.Context = testutils.MustNewStruct(t, map[string]any{"ip": "10.0.0.1"})
+
+		report, err := Compute(ctx, ds, typesys, storeID, []*openfgav1.Assertion{inNetwork})
+		require.NoError(t, err)
+		require.Empty(t, report.FailedAssertions)
+		require.Equal(t, map[string][]string{
+			"document#can_share": {"editor", "allowed"},
+			"document#editor":    {"[user]"},
+			"document#allowed":   {"[user with in_network]"},
+		}, exercised(report))
+		require.Equal(t, "can_share", report.Relations[2].Relation)
+		require.True(t, report.Relations[2].Asserted)
+	})
+
+	t.Run("failed_assertions_are_reported", func(t *testing.T) {
+		outOfNetwork := assertion("document:1", "can_share", "user:anne", true)
+		outOfNetwork.Context = &structpb.Struct{}
+
+		report, err := Compute(ctx, ds, typesys, storeID, []*openfgav1.Assertion{
+			assertion("document:1", "viewer", "user:bob", false),
+			outOfNetwork,
+		})
+		require.NoError(t, err)
+		require.Len(t, report.FailedAssertions, 2)
+		require.Equal(t, "user:bob", report.FailedAssertions[0].User)
+		require.Empty(t, report.FailedAssertions[0].Error)
+		require.NotEmpty(t, report.FailedAssertions[1].Error)
+		require.Empty(t, exercised(report))
+	})
+}
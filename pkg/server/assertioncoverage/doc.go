@@ -0,0 +1,15 @@
+// Package assertioncoverage reports which relations of an authorization model, and which branches
+// of their rewrites, are exercised by the assertions of the model, to gate the changes of a model
+// on the coverage of its tests.
+//
+// The assertions are evaluated against the tuples of the store, with their contextual tuples and
+// context. A branch is exercised when it determines the result of an assertion that passes: it is
+// on the path of tuples that grants the relation of an allowed assertion, or, for the subtracted
+// relation of an exclusion, on the path that denies it. The assertions that fail are reported and
+// don't exercise any branch.
+//
+// The report is served by the assertion coverage service, whose messages are encoded as JSON:
+// /openfga.assertioncoverage.v1.AssertionCoverageService/GetReport. It is also served over HTTP as
+// GET /stores/{store_id}/assertion-coverage, and printed by the `openfga assertion-coverage`
+// command.
+package assertioncoverage
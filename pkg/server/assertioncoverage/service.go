@@ -0,0 +1,93 @@
+package assertioncoverage
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the assertion coverage service.
+	ServiceName = "openfga.assertioncoverage.v1.AssertionCoverageService"
+
+	// GetReportMethod is the full name of the method that reports the coverage of a model.
+	GetReportMethod = "/" + ServiceName + "/GetReport"
+
+	// codecName is the content-subtype of the assertion coverage requests. The assertion coverage
+	// service is not part of the OpenFGA API, so its messages are encoded as JSON instead of
+	// generated protobufs.
+	codecName = "openfga-assertioncoverage-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// GetReportRequest requests the coverage of a model of a store by its assertions, or of the
+// latest model if AuthorizationModelID is empty.
+type GetReportRequest struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+}
+
+// Server reports the coverage of the models. It is implemented by the OpenFGA server.
+type Server interface {
+	GetAssertionCoverage(ctx context.Context, req *GetReportRequest) (*Report, error)
+}
+
+// RegisterServer registers the assertion coverage service, which reports the coverage with srv,
+// on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "GetReport",
+				Handler:    getReportHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/assertioncoverage/service.go",
+	}, srv)
+}
+
+func getReportHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &GetReportRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).GetAssertionCoverage(ctx, req.(*GetReportRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: GetReportMethod}, handler)
+}
+
+// GetReport reports the coverage of a model on conn.
+func GetReport(ctx context.Context, conn grpc.ClientConnInterface, req *GetReportRequest) (*Report, error) {
+	out := &Report{}
+	if err := conn.Invoke(ctx, GetReportMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
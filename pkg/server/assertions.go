@@ -59,6 +59,10 @@ func (s *Server) WriteAssertions(ctx context.Context, req *openfgav1.WriteAssert
 		return nil, err
 	}
 
+	if s.storeEventsRecorder != nil {
+		s.storeEventsRecorder.RecordAssertionsWrite(storeID, req.GetAuthorizationModelId())
+	}
+
 	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusNoContent))
 
 	return res, nil
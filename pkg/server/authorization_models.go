@@ -43,7 +43,16 @@ func (s *Server) ReadAuthorizationModel(ctx context.Context, req *openfgav1.Read
 	}
 
 	q := commands.NewReadAuthorizationModelQuery(s.datastore, commands.WithReadAuthModelQueryLogger(s.logger))
-	return q.Execute(ctx, req)
+	res, err := q.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.setETag(ctx, authorizationModelETag(res.GetAuthorizationModel().GetId())) {
+		return &openfgav1.ReadAuthorizationModelResponse{}, nil
+	}
+
+	return res, nil
 }
 
 func (s *Server) WriteAuthorizationModel(ctx context.Context, req *openfgav1.WriteAuthorizationModelRequest) (*openfgav1.WriteAuthorizationModelResponse, error) {
@@ -108,5 +117,18 @@ func (s *Server) ReadAuthorizationModels(ctx context.Context, req *openfgav1.Rea
 		commands.WithReadAuthModelsQueryLogger(s.logger),
 		commands.WithReadAuthModelsQueryEncoder(s.encoder),
 	)
-	return c.Execute(ctx, req)
+	res, err := c.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	modelIDs := make([]string, 0, len(res.GetAuthorizationModels()))
+	for _, model := range res.GetAuthorizationModels() {
+		modelIDs = append(modelIDs, model.GetId())
+	}
+	if s.setETag(ctx, authorizationModelsETag(modelIDs, res.GetContinuationToken())) {
+		return &openfgav1.ReadAuthorizationModelsResponse{}, nil
+	}
+
+	return res, nil
 }
@@ -0,0 +1,476 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/orphans"
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/accessreview"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/server/purge"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// JobKindPurge is the kind of the jobs that delete the tuples of a store matching a filter. Its
+	// params are the object, relation and user of the filter, validated like the tuple key of a Read
+	// except that any combination of them can be set, and at least one of them must be set. The
+	// purge jobs of the deleted stores, created by DeleteStore, have no filter.
+	JobKindPurge = "purge"
+
+	// JobKindCollectOrphans is the kind of the jobs that collect the orphaned tuples of a store,
+	// whose result is the report of the collection. Its only param is dry_run.
+	JobKindCollectOrphans = "collect_orphans"
+
+	// JobKindAccessReview is the kind of the jobs that export the users that have a relation on all
+	// the objects of a type, registered when the access review exports are enabled. Its params are
+	// an accessreview.Params, and its result an accessreview.Result. The artifact of the export is
+	// downloaded with DownloadAccessReview.
+	JobKindAccessReview = "access_review"
+)
+
+// ErrJobsDisabled is returned by the jobs methods when the background jobs are not enabled with
+// WithJobsEnabled.
+var ErrJobsDisabled = status.Error(codes.Unimplemented, "background jobs are not enabled")
+
+type purgeJobParams struct {
+	Object   string `json:"object,omitempty"`
+	Relation string `json:"relation,omitempty"`
+	User     string `json:"user,omitempty"`
+}
+
+type collectOrphansJobParams struct {
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// accessReviewJobParams decodes the params of an access_review job.
+func accessReviewJobParams(data json.RawMessage) (*accessreview.Params, error) {
+	var params accessreview.Params
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// createStorePurgeJob creates the purge job that deletes the tuples of a deleted store. The
+// deletion of the store succeeded, so the failure to create it is only logged.
+func (s *Server) createStorePurgeJob(ctx context.Context, storeID string) {
+	params, err := json.Marshal(&purgeJobParams{})
+	if err == nil {
+		_, err = s.jobsManager.Create(ctx, storeID, JobKindPurge, params)
+	}
+	if err != nil {
+		s.logger.ErrorWithContext(ctx, "failed to create the purge job of a deleted store",
+			zap.String("store_id", storeID),
+			zap.Error(err),
+		)
+	}
+}
+
+// registerJobKinds registers the kinds of background jobs of the server on the jobs manager.
+func (s *Server) registerJobKinds() {
+	s.jobsManager.Register(JobKindPurge, func(ctx context.Context, storeID string, params json.RawMessage, progress jobs.ProgressFunc) (any, error) {
+		var p purgeJobParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		var deleted uint64
+		err := purge.Purge(ctx, s.datastore, storeID, storage.ReadFilter{
+			Object:   p.Object,
+			Relation: p.Relation,
+			User:     p.User,
+		}, func(batch, total uint64) {
+			deleted += batch
+			progress(deleted, total)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]uint64{"deleted": deleted}, nil
+	})
+
+	s.jobsManager.Register(JobKindCollectOrphans, func(ctx context.Context, storeID string, params json.RawMessage, _ jobs.ProgressFunc) (any, error) {
+		var p collectOrphansJobParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+		}
+		return orphans.Collect(ctx, orphans.NewDatastoreStore(s.datastore), storeID, p.DryRun)
+	})
+
+	if s.accessReviewExporter != nil {
+		s.jobsManager.Register(JobKindAccessReview, s.runAccessReviewJob)
+	}
+}
+
+// validateJobParams validates the params of a job of kind. The purge jobs are validated like
+// DeleteTuples, and the access_review jobs like ListUsers.
+func (s *Server) validateJobParams(storeID, kind string, params json.RawMessage) error {
+	switch kind {
+	case JobKindPurge:
+		var p purgeJobParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid params of a %s job: %s", kind, err))
+		}
+		readReq := &openfgav1.ReadRequest{
+			StoreId: storeID,
+			TupleKey: &openfgav1.ReadRequestTupleKey{
+				Object:   p.Object,
+				Relation: p.Relation,
+				User:     p.User,
+			},
+		}
+		if err := readReq.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if p.Object == "" && p.Relation == "" && p.User == "" {
+			return ErrDeleteTuplesFilterRequired
+		}
+	case JobKindCollectOrphans:
+		if len(params) == 0 {
+			return nil
+		}
+		var p collectOrphansJobParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid params of a %s job: %s", kind, err))
+		}
+	case JobKindAccessReview:
+		if s.accessReviewExporter == nil {
+			return ErrAccessReviewDisabled
+		}
+		p, err := accessReviewJobParams(params)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid params of a %s job: %s", kind, err))
+		}
+		return validateAccessReviewParams(storeID, p)
+	default:
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("%s '%s'", jobs.ErrUnknownKind, kind))
+	}
+	return nil
+}
+
+// CreateJob creates a background job of the kind of req for a store, and returns it. The jobs
+// modify the tuples of the store, so they are authorized like Write.
+func (s *Server) CreateJob(ctx context.Context, req *jobs.CreateRequest) (*jobs.Job, error) {
+	ctx, span := tracer.Start(ctx, "CreateJob", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("kind", req.Kind),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.validateJobParams(req.StoreID, req.Kind, req.Params); err != nil {
+		return nil, err
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Write.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Kind == JobKindAccessReview {
+		// fail fast rather than in the job when the params do not fit the model
+		params, err := accessReviewJobParams(req.Params)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid params of a %s job: %s", req.Kind, err))
+		}
+		if _, err := s.resolveAccessReviewTypesystem(ctx, req.StoreID, params); err != nil {
+			return nil, err
+		}
+	}
+
+	job, err := s.jobsManager.Create(ctx, req.StoreID, req.Kind, req.Params)
+	if err != nil {
+		return nil, jobsError(err, "")
+	}
+	span.SetAttributes(attribute.String("job_id", job.ID))
+	return job, nil
+}
+
+// GetJob returns a background job of a store.
+func (s *Server) GetJob(ctx context.Context, req *jobs.GetRequest) (*jobs.Job, error) {
+	ctx, span := tracer.Start(ctx, "GetJob", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("job_id", req.JobID),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Read.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobsManager.Get(ctx, req.StoreID, req.JobID)
+	if err != nil {
+		return nil, jobsError(err, req.JobID)
+	}
+	return job, nil
+}
+
+// ListJobs lists the background jobs of a store that are not finished or finished within the job
+// retention.
+func (s *Server) ListJobs(ctx context.Context, req *jobs.ListRequest) (*jobs.ListResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListJobs", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Read.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.jobsManager.List(ctx, req.StoreID)
+	if err != nil {
+		return nil, jobsError(err, "")
+	}
+	return &jobs.ListResponse{Jobs: list}, nil
+}
+
+// CancelJob requests the cancellation of a background job of a store, and returns it. It is
+// authorized like Write.
+func (s *Server) CancelJob(ctx context.Context, req *jobs.CancelRequest) (*jobs.Job, error) {
+	ctx, span := tracer.Start(ctx, "CancelJob", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("job_id", req.JobID),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Write.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobsManager.Cancel(ctx, req.StoreID, req.JobID)
+	if err != nil {
+		return nil, jobsError(err, req.JobID)
+	}
+	return job, nil
+}
+
+// CreateJobSchedule creates a schedule of recurring background jobs of the kind of req for a
+// store, and returns it. Like CreateJob, it is authorized like Write.
+func (s *Server) CreateJobSchedule(ctx context.Context, req *jobs.CreateScheduleRequest) (*jobs.Schedule, error) {
+	ctx, span := tracer.Start(ctx, "CreateJobSchedule", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("kind", req.Kind),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.validateJobParams(req.StoreID, req.Kind, req.Params); err != nil {
+		return nil, err
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Write.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.jobsManager.CreateSchedule(ctx, req.StoreID, req.Kind, req.Cron, req.Params)
+	if err != nil {
+		return nil, schedulesError(err, "")
+	}
+	span.SetAttributes(attribute.String("schedule_id", schedule.ID))
+	return schedule, nil
+}
+
+// GetJobSchedule returns a schedule of recurring background jobs of a store.
+func (s *Server) GetJobSchedule(ctx context.Context, req *jobs.GetScheduleRequest) (*jobs.Schedule, error) {
+	ctx, span := tracer.Start(ctx, "GetJobSchedule", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("schedule_id", req.ScheduleID),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Read.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.jobsManager.GetSchedule(ctx, req.StoreID, req.ScheduleID)
+	if err != nil {
+		return nil, schedulesError(err, req.ScheduleID)
+	}
+	return schedule, nil
+}
+
+// ListJobSchedules lists the schedules of recurring background jobs of a store.
+func (s *Server) ListJobSchedules(ctx context.Context, req *jobs.ListSchedulesRequest) (*jobs.ListSchedulesResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListJobSchedules", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Read.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.jobsManager.ListSchedules(ctx, req.StoreID)
+	if err != nil {
+		return nil, schedulesError(err, "")
+	}
+	return &jobs.ListSchedulesResponse{Schedules: list}, nil
+}
+
+// DeleteJobSchedule deletes a schedule of recurring background jobs of a store. The jobs it
+// created are not canceled. It is authorized like Write.
+func (s *Server) DeleteJobSchedule(ctx context.Context, req *jobs.DeleteScheduleRequest) (*jobs.DeleteScheduleResponse, error) {
+	ctx, span := tracer.Start(ctx, "DeleteJobSchedule", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("schedule_id", req.ScheduleID),
+	))
+	defer span.End()
+
+	if s.jobsManager == nil {
+		return nil, ErrJobsDisabled
+	}
+
+	if err := (&openfgav1.GetStoreRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Write.String(),
+	})
+
+	err := s.checkAuthz(ctx, req.StoreID, apimethod.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.jobsManager.DeleteSchedule(ctx, req.StoreID, req.ScheduleID); err != nil {
+		return nil, schedulesError(err, req.ScheduleID)
+	}
+	return &jobs.DeleteScheduleResponse{}, nil
+}
+
+// schedulesError maps the errors of the schedules of the jobs manager to gRPC errors.
+func schedulesError(err error, scheduleID string) error {
+	switch {
+	case errors.Is(err, jobs.ErrScheduleNotFound):
+		return status.Error(codes.NotFound, fmt.Sprintf("schedule '%s' not found", scheduleID))
+	case errors.Is(err, jobs.ErrInvalidCron):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, jobs.ErrSchedulesDisabled):
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	return jobsError(err, "")
+}
+
+// jobsError maps the errors of the jobs manager to gRPC errors.
+func jobsError(err error, jobID string) error {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return status.Error(codes.NotFound, fmt.Sprintf("job '%s' not found", jobID))
+	case errors.Is(err, jobs.ErrUnknownKind):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, jobs.ErrQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return serverErrors.HandleError("", err)
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/accessreview"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestJobs(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithJobsEnabled(true),
+		WithAccessReviewEnabled(true),
+		WithAccessReviewArtifactDir(t.TempDir()),
+	)
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "jobs"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: "1.1",
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{Type: "user"},
+			{
+				Type: "document",
+				Relations: map[string]*openfgav1.Userset{
+					"viewer": {Userset: &openfgav1.Userset_This{}},
+				},
+				Metadata: &openfgav1.Metadata{
+					Relations: map[string]*openfgav1.RelationMetadata{
+						"viewer": {DirectlyRelatedUserTypes: []*openfgav1.RelationReference{{Type: "user"}}},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:2", "viewer", "user:bob"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("purge", func(t *testing.T) {
+		created, err := s.CreateJob(ctx, &jobs.CreateRequest{
+			StoreID: storeID,
+			Kind:    JobKindPurge,
+			Params:  json.RawMessage(`{"user":"user:anne"}`),
+		})
+		require.NoError(t, err)
+
+		var job *jobs.Job
+		require.Eventually(t, func() bool {
+			job, err = s.GetJob(ctx, &jobs.GetRequest{StoreID: storeID, JobID: created.ID})
+			require.NoError(t, err)
+			return job.State.Finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, jobs.StateSucceeded, job.State, job.Error)
+		require.Equal(t, uint64(2), job.Total)
+		require.JSONEq(t, `{"deleted":2}`, string(job.Result))
+
+		res, err := s.Read(ctx, &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		require.Len(t, res.GetTuples(), 1)
+
+		list, err := s.ListJobs(ctx, &jobs.ListRequest{StoreID: storeID})
+		require.NoError(t, err)
+		require.Len(t, list.Jobs, 1)
+	})
+
+	t.Run("access_review", func(t *testing.T) {
+		created, err := s.CreateJob(ctx, &jobs.CreateRequest{
+			StoreID: storeID,
+			Kind:    JobKindAccessReview,
+			Params:  json.RawMessage(`{"object_type":"document","relation":"viewer","user_filters":["user"]}`),
+		})
+		require.NoError(t, err)
+
+		var job *jobs.Job
+		require.Eventually(t, func() bool {
+			job, err = s.GetJob(ctx, &jobs.GetRequest{StoreID: storeID, JobID: created.ID})
+			require.NoError(t, err)
+			return job.State.Finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, jobs.StateSucceeded, job.State, job.Error)
+
+		var result accessreview.Result
+		require.NoError(t, json.Unmarshal(job.Result, &result))
+		require.Equal(t, uint64(1), result.Objects)
+		require.Equal(t, uint64(1), result.Rows)
+
+		_, err = s.CreateJob(ctx, &jobs.CreateRequest{
+			StoreID: storeID,
+			Kind:    JobKindAccessReview,
+			Params:  json.RawMessage(`{"object_type":"document","relation":"viewer"}`),
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("schedules", func(t *testing.T) {
+		schedule, err := s.CreateJobSchedule(ctx, &jobs.CreateScheduleRequest{
+			StoreID: storeID,
+			Kind:    JobKindCollectOrphans,
+			Cron:    "0 3 * * 1",
+			Params:  json.RawMessage(`{"dry_run":true}`),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, schedule.NextRunAt)
+
+		read, err := s.GetJobSchedule(ctx, &jobs.GetScheduleRequest{StoreID: storeID, ScheduleID: schedule.ID})
+		require.NoError(t, err)
+		require.Equal(t, schedule.Cron, read.Cron)
+
+		list, err := s.ListJobSchedules(ctx, &jobs.ListSchedulesRequest{StoreID: storeID})
+		require.NoError(t, err)
+		require.Len(t, list.Schedules, 1)
+
+		_, err = s.DeleteJobSchedule(ctx, &jobs.DeleteScheduleRequest{StoreID: storeID, ScheduleID: schedule.ID})
+		require.NoError(t, err)
+		_, err = s.GetJobSchedule(ctx, &jobs.GetScheduleRequest{StoreID: storeID, ScheduleID: schedule.ID})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = s.CreateJobSchedule(ctx, &jobs.CreateScheduleRequest{StoreID: storeID, Kind: JobKindCollectOrphans, Cron: "0 3 * *"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = s.CreateJobSchedule(ctx, &jobs.CreateScheduleRequest{StoreID: storeID, Kind: JobKindPurge, Cron: "@daily", Params: json.RawMessage(`{}`)})
+		require.ErrorIs(t, err, ErrDeleteTuplesFilterRequired)
+	})
+
+	t.Run("invalid_params", func(t *testing.T) {
+		_, err := s.CreateJob(ctx, &jobs.CreateRequest{StoreID: storeID, Kind: JobKindPurge, Params: json.RawMessage(`{}`)})
+		require.ErrorIs(t, err, ErrDeleteTuplesFilterRequired)
+
+		_, err = s.CreateJob(ctx, &jobs.CreateRequest{StoreID: storeID, Kind: "unknown"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("unknown_job", func(t *testing.T) {
+		_, err := s.GetJob(ctx, &jobs.GetRequest{StoreID: storeID, JobID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = s.CancelJob(ctx, &jobs.CancelRequest{StoreID: storeID, JobID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestJobsDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	_, err := s.CreateJob(context.Background(), &jobs.CreateRequest{StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV", Kind: JobKindPurge})
+	require.ErrorIs(t, err, ErrJobsDisabled)
+}
@@ -2,12 +2,18 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -22,6 +28,53 @@ import (
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 )
 
+// BatchCheckItemMetadataHeader requests the metadata of the evaluation of each check of a
+// BatchCheck. When a request sets it to "true", the response carries a header of the same name
+// with a JSON object that maps every correlation ID to the number of datastore queries, the number
+// of items read from the datastore and the duration in milliseconds of its check.
+const BatchCheckItemMetadataHeader = "Openfga-Batch-Check-Item-Metadata"
+
+// batchCheckItemMetadata is the metadata of a check in the BatchCheckItemMetadataHeader.
+type batchCheckItemMetadata struct {
+	DatastoreQueryCount uint32  `json:"datastore_query_count"`
+	DatastoreItemCount  uint64  `json:"datastore_item_count"`
+	DurationMs          float64 `json:"duration_ms"`
+}
+
+// BatchCheckLoadSignal reports the load of the server for the adaptive limits of BatchCheck, see
+// WithBatchCheckAdaptiveLimits.
+type BatchCheckLoadSignal interface {
+	// Load returns 1 when the server is saturated, and less than 1 when it has spare capacity.
+	Load() float64
+}
+
+// batchCheckLimits returns the maximum number of checks of a BatchCheck and the number of checks it
+// evaluates concurrently under the current load.
+func (s *Server) batchCheckLimits() (uint32, uint32) {
+	if s.batchCheckLoadSignal == nil {
+		return s.maxChecksPerBatchCheck, s.maxConcurrentChecksPerBatch
+	}
+
+	ratio := adaptiveLimitRatio(s.batchCheckLoadSignal.Load(), s.batchCheckLoadThreshold, s.batchCheckMinLimitRatio)
+	return scaleLimit(s.maxChecksPerBatchCheck, ratio), scaleLimit(s.maxConcurrentChecksPerBatch, ratio)
+}
+
+// adaptiveLimitRatio returns the ratio of the limits at load: 1 up to threshold, then decreasing
+// linearly down to minRatio at a load of 1.
+func adaptiveLimitRatio(load, threshold, minRatio float64) float64 {
+	if load <= threshold {
+		return 1
+	}
+	if load >= 1 {
+		return minRatio
+	}
+	return 1 - (1-minRatio)*(load-threshold)/(1-threshold)
+}
+
+func scaleLimit(limit uint32, ratio float64) uint32 {
+	return max(1, uint32(math.Ceil(float64(limit)*ratio)))
+}
+
 func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckRequest) (*openfgav1.BatchCheckResponse, error) {
 	ctx, span := tracer.Start(ctx, apimethod.BatchCheck.String(), trace.WithAttributes(
 		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(req.GetStoreId())},
@@ -41,58 +94,112 @@ func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckReques
 		Method:  apimethod.BatchCheck.String(),
 	})
 
+	ctx, cmd, closer, err := s.newBatchCheckCommand(ctx, req, span)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	startTime := time.Now()
+
+	result, metadata, err := cmd.Execute(ctx, &commands.BatchCheckCommandParams{
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		Checks:               req.GetChecks(),
+		Consistency:          req.GetConsistency(),
+		StoreID:              req.GetStoreId(),
+	})
+	if err != nil {
+		return nil, batchCheckError(span, err)
+	}
+
+	methodName := "batchcheck"
+	s.observeBatchCheckMetadata(ctx, span, methodName, metadata)
+
+	batchResult := map[string]*openfgav1.BatchCheckSingleResult{}
+	for correlationID, outcome := range result {
+		batchResult[string(correlationID)] = transformCheckResultToProto(outcome)
+		s.emitCheckDurationMetric(outcome.CheckResponse.GetResolutionMetadata(), methodName)
+	}
+
+	s.setServerTiming(ctx, time.Since(startTime), metadata.DatastoreDuration, s.batchCheckCacheStatus(req.GetConsistency(), result))
+
+	if batchCheckItemMetadataRequested(ctx) {
+		s.setBatchCheckItemMetadataHeader(ctx, metadata.Items)
+	}
+
+	return &openfgav1.BatchCheckResponse{Result: batchResult}, nil
+}
+
+// newBatchCheckCommand authorizes req and resolves its model, and returns the command that
+// evaluates its checks with opts, the context to execute it with and the closer of its check
+// resolver.
+func (s *Server) newBatchCheckCommand(ctx context.Context, req *openfgav1.BatchCheckRequest, span trace.Span, opts ...commands.BatchCheckQueryOption) (context.Context, *commands.BatchCheckQuery, func(), error) {
 	storeID := req.GetStoreId()
 	err := s.checkAuthz(ctx, storeID, apimethod.BatchCheck)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+
+	ctx, _, err = s.contextWithMaxResolutionDepth(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	maxChecks, maxConcurrentChecks := s.batchCheckLimits()
+	span.SetAttributes(attribute.Int("max_checks", int(maxChecks)))
+	if batchSize := len(req.GetChecks()); batchSize > int(maxChecks) && batchSize <= int(s.maxChecksPerBatchCheck) {
+		throttledRequestCounter.WithLabelValues(s.serviceName, "batchcheck", throttleTypeLoad).Inc()
+		return nil, nil, nil, serverErrors.ReduceBatchSize(batchSize, maxChecks)
 	}
 
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	for _, item := range req.GetChecks() {
+		s.dropUndefinedContextualTupleKeys(ctx, typesys, item.GetContextualTuples())
+		item.Context = s.injectConditionContext(ctx, item.GetContext())
+	}
 
 	builder := s.getCheckResolverBuilder(req.GetStoreId())
 	checkResolver, checkResolverCloser, err := builder.Build()
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	defer checkResolverCloser()
 
 	cmd := commands.NewBatchCheckCommand(
 		s.datastore,
 		checkResolver,
 		typesys,
-		commands.WithBatchCheckCacheOptions(s.sharedDatastoreResources, s.cacheSettings),
-		commands.WithBatchCheckCommandLogger(s.logger),
-		commands.WithBatchCheckMaxChecksPerBatch(s.maxChecksPerBatchCheck),
-		commands.WithBatchCheckMaxConcurrentChecks(s.maxConcurrentChecksPerBatch),
-		commands.WithBatchCheckDatastoreThrottler(
-			s.featureFlagClient.Boolean(config.ExperimentalDatastoreThrottling, storeID),
-			s.checkDatastoreThrottleThreshold,
-			s.checkDatastoreThrottleDuration,
-		),
+		append([]commands.BatchCheckQueryOption{
+			commands.WithBatchCheckCacheOptions(s.sharedDatastoreResources, s.cacheSettings),
+			commands.WithBatchCheckCommandLogger(s.logger),
+			commands.WithBatchCheckMaxChecksPerBatch(s.maxChecksPerBatchCheck),
+			commands.WithBatchCheckMaxConcurrentChecks(maxConcurrentChecks),
+			commands.WithBatchCheckDatastoreThrottler(
+				s.featureFlagClient.Boolean(config.ExperimentalDatastoreThrottling, storeID),
+				s.checkDatastoreThrottleThreshold,
+				s.checkDatastoreThrottleDuration,
+			),
+		}, opts...)...,
 	)
+	return ctx, cmd, checkResolverCloser, nil
+}
 
-	result, metadata, err := cmd.Execute(ctx, &commands.BatchCheckCommandParams{
-		AuthorizationModelID: req.GetAuthorizationModelId(),
-		Checks:               req.GetChecks(),
-		Consistency:          req.GetConsistency(),
-		StoreID:              storeID,
-	})
-	if err != nil {
-		telemetry.TraceError(span, err)
-		var batchValidationError *commands.BatchCheckValidationError
-		if errors.As(err, &batchValidationError) {
-			return nil, serverErrors.ValidationError(err)
-		}
-
-		return nil, err
+// batchCheckError returns the error of a failed batch check command.
+func batchCheckError(span trace.Span, err error) error {
+	telemetry.TraceError(span, err)
+	var batchValidationError *commands.BatchCheckValidationError
+	if errors.As(err, &batchValidationError) {
+		return serverErrors.ValidationError(err)
 	}
+	return err
+}
 
-	methodName := "batchcheck"
-
+// observeBatchCheckMetadata records the metrics, the span attributes and the log tags of the
+// metadata of a batch check.
+func (s *Server) observeBatchCheckMetadata(ctx context.Context, span trace.Span, methodName string, metadata *commands.BatchCheckMetadata) {
 	dispatchCount := float64(metadata.DispatchCount)
 	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
 	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
@@ -131,16 +238,41 @@ func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckReques
 	span.SetAttributes(attribute.Int(duplicateChecks, metadata.DuplicateCheckCount))
 	grpc_ctxtags.Extract(ctx).Set(duplicateChecks, metadata.DuplicateCheckCount)
 
-	batchResult := map[string]*openfgav1.BatchCheckSingleResult{}
-	for correlationID, outcome := range result {
-		batchResult[string(correlationID)] = transformCheckResultToProto(outcome)
-		s.emitCheckDurationMetric(outcome.CheckResponse.GetResolutionMetadata(), methodName)
-	}
-
 	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, metadata.DatastoreQueryCount)
 	grpc_ctxtags.Extract(ctx).Set(datastoreItemCountHistogramName, metadata.DatastoreItemCount)
+}
 
-	return &openfgav1.BatchCheckResponse{Result: batchResult}, nil
+// batchCheckItemMetadataRequested reports whether the request sets the BatchCheckItemMetadataHeader.
+func batchCheckItemMetadataRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(BatchCheckItemMetadataHeader))
+	if len(values) == 0 {
+		return false
+	}
+	requested, _ := strconv.ParseBool(strings.TrimSpace(values[0]))
+	return requested
+}
+
+// setBatchCheckItemMetadataHeader returns the metadata of each check in the BatchCheckItemMetadataHeader.
+func (s *Server) setBatchCheckItemMetadataHeader(ctx context.Context, items map[commands.CorrelationID]commands.BatchCheckItemMetadata) {
+	header := make(map[commands.CorrelationID]batchCheckItemMetadata, len(items))
+	for id, item := range items {
+		header[id] = batchCheckItemMetadata{
+			DatastoreQueryCount: item.DatastoreQueryCount,
+			DatastoreItemCount:  item.DatastoreItemCount,
+			DurationMs:          float64(item.Duration.Microseconds()) / 1000,
+		}
+	}
+
+	value, err := json.Marshal(header)
+	if err != nil {
+		return
+	}
+	s.transport.SetHeader(ctx, BatchCheckItemMetadataHeader, string(value))
 }
 
 // transformCheckResultToProto transforms the internal BatchCheckOutcome into the external-facing
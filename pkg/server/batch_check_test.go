@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
@@ -17,6 +20,7 @@ import (
 	"github.com/openfga/openfga/internal/graph"
 	"github.com/openfga/openfga/pkg/server/commands"
 	"github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/testutils"
 	"github.com/openfga/openfga/pkg/tuple"
 )
@@ -107,6 +111,77 @@ func TestBatchCheckUsesTypesystemModel(t *testing.T) {
 	}
 }
 
+func TestBatchCheckItemMetadataHeader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-test",
+	})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]
+	`)
+	_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	batchCheckRequest := &openfgav1.BatchCheckRequest{
+		StoreId: storeID,
+		Checks: []*openfgav1.BatchCheckItem{
+			{
+				TupleKey:      &openfgav1.CheckRequestTupleKey{User: "user:anne", Relation: "viewer", Object: "document:1"},
+				CorrelationId: "id1",
+			},
+			{
+				TupleKey:      &openfgav1.CheckRequestTupleKey{User: "user:bob", Relation: "viewer", Object: "document:1"},
+				CorrelationId: "id2",
+			},
+		},
+	}
+
+	t.Run("not_returned_unless_requested", func(t *testing.T) {
+		transport.reset()
+		_, err := s.BatchCheck(context.Background(), batchCheckRequest)
+		require.NoError(t, err)
+		require.Empty(t, transport.get(BatchCheckItemMetadataHeader))
+	})
+
+	t.Run("returned_for_each_correlation_id", func(t *testing.T) {
+		transport.reset()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(BatchCheckItemMetadataHeader, "true"))
+		_, err := s.BatchCheck(ctx, batchCheckRequest)
+		require.NoError(t, err)
+
+		var items map[string]batchCheckItemMetadata
+		require.NoError(t, json.Unmarshal([]byte(transport.get(BatchCheckItemMetadataHeader)), &items))
+		require.Len(t, items, 2)
+		for _, id := range []string{"id1", "id2"} {
+			require.Contains(t, items, id)
+			require.Positive(t, items[id].DatastoreQueryCount)
+		}
+	})
+}
+
 func TestBatchCheckValidatesInboundRequest(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -223,6 +298,103 @@ func TestBatchCheckFailsIfTooManyChecks(t *testing.T) {
 	)
 	require.ErrorContains(t, err, msg)
 }
+
+type fixedLoad float64
+
+func (l *fixedLoad) Load() float64 {
+	return float64(*l)
+}
+
+func TestBatchCheckAdaptiveLimits(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+	load := fixedLoad(1)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithMaxChecksPerBatchCheck(20),
+		WithBatchCheckAdaptiveLimits(&load, 0.5, 0.1),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-test",
+	})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]
+	`)
+	_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	batch := func(n int) *openfgav1.BatchCheckRequest {
+		checks := make([]*openfgav1.BatchCheckItem, n)
+		for i := range checks {
+			checks[i] = &openfgav1.BatchCheckItem{
+				TupleKey: &openfgav1.CheckRequestTupleKey{
+					Object:   "document:1",
+					Relation: "viewer",
+					User:     fmt.Sprintf("user:%d", i),
+				},
+				CorrelationId: fmt.Sprintf("id%d", i),
+			}
+		}
+		return &openfgav1.BatchCheckRequest{StoreId: storeID, Checks: checks}
+	}
+
+	t.Run("saturated_server_accepts_the_minimum_ratio", func(t *testing.T) {
+		_, err := s.BatchCheck(context.Background(), batch(3))
+		st := status.Convert(err)
+		require.Equal(t, serverErrors.ReasonReduceBatchSize, serverErrors.ErrorReason(st))
+		require.Contains(t, st.Message(), "the maximum allowed while the server is under load is 2")
+
+		_, err = s.BatchCheck(context.Background(), batch(2))
+		require.NoError(t, err)
+	})
+
+	t.Run("batches_over_the_configured_limit_fail_validation", func(t *testing.T) {
+		_, err := s.BatchCheck(context.Background(), batch(21))
+		require.ErrorContains(t, err, "the maximum allowed is 20")
+	})
+
+	t.Run("limits_recover_with_the_load", func(t *testing.T) {
+		load = 0.75
+		_, err := s.BatchCheck(context.Background(), batch(12))
+		require.ErrorContains(t, err, "under load is 11")
+
+		load = 0.5
+		_, err = s.BatchCheck(context.Background(), batch(20))
+		require.NoError(t, err)
+	})
+}
+
+func TestAdaptiveLimitRatio(t *testing.T) {
+	require.InDelta(t, 1, adaptiveLimitRatio(0, 0.8, 0.1), 1e-9)
+	require.InDelta(t, 1, adaptiveLimitRatio(0.8, 0.8, 0.1), 1e-9)
+	require.InDelta(t, 0.55, adaptiveLimitRatio(0.9, 0.8, 0.1), 1e-9)
+	require.InDelta(t, 0.1, adaptiveLimitRatio(1, 0.8, 0.1), 1e-9)
+	require.InDelta(t, 0.1, adaptiveLimitRatio(3, 0.8, 0.1), 1e-9)
+
+	require.Equal(t, uint32(1), scaleLimit(50, 0))
+	require.Equal(t, uint32(5), scaleLimit(50, 0.1))
+	require.Equal(t, uint32(50), scaleLimit(50, 1))
+}
+
 func TestTransformCheckCommandErrorToBatchCheckError(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
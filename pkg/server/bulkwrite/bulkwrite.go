@@ -0,0 +1,227 @@
+package bulkwrite
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+const (
+	// ServiceName is the name of the bulk write service.
+	ServiceName = "openfga.bulk.v1.BulkWriteService"
+
+	// WriteMethod is the full name of the bidirectional streaming Write method.
+	WriteMethod = "/" + ServiceName + "/Write"
+
+	// DeleteTuplesMethod is the full name of the DeleteTuples method.
+	DeleteTuplesMethod = "/" + ServiceName + "/DeleteTuples"
+
+	// DefaultWindow is the default number of chunks received ahead of the chunk being written.
+	DefaultWindow = 8
+)
+
+var writeStreamDesc = grpc.StreamDesc{
+	StreamName:    "Write",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// Writer writes a chunk of tuples. It is implemented by the OpenFGA server.
+type Writer interface {
+	Write(ctx context.Context, req *openfgav1.WriteRequest) (*openfgav1.WriteResponse, error)
+}
+
+// Server writes the chunks of tuples and deletes the tuples matching a filter. It is implemented
+// by the OpenFGA server.
+type Server interface {
+	Writer
+	DeleteTuples(ctx context.Context, req *openfgav1.ReadRequest) (uint64, error)
+}
+
+// Option configures the bulk write service.
+type Option func(*options)
+
+type options struct {
+	window int
+}
+
+// WithWindow sets the number of chunks received ahead of the chunk being written. It defaults to
+// DefaultWindow.
+func WithWindow(window int) Option {
+	return func(o *options) {
+		o.window = window
+	}
+}
+
+// RegisterServer registers the bulk write service, which writes the chunks and deletes the tuples
+// with srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server, opts ...Option) {
+	o := &options{window: DefaultWindow}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	desc := writeStreamDesc
+	desc.Handler = func(srv any, stream grpc.ServerStream) error {
+		return serveWrite(srv.(Writer), stream, o.window)
+	}
+
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "DeleteTuples",
+				Handler:    deleteTuplesHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{desc},
+		Metadata: "pkg/server/bulkwrite/bulkwrite.go",
+	}, srv)
+}
+
+func deleteTuplesHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &openfgav1.ReadRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		deleted, err := srv.(Server).DeleteTuples(ctx, req.(*openfgav1.ReadRequest))
+		if err != nil {
+			return nil, err
+		}
+		return wrapperspb.UInt64(deleted), nil
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: DeleteTuplesMethod}, handler)
+}
+
+// chunk is a received chunk, or the error of a chunk that could not be received, e.g. because it
+// is invalid.
+type chunk struct {
+	req *openfgav1.WriteRequest
+	err error
+}
+
+func serveWrite(w Writer, stream grpc.ServerStream, window int) error {
+	ctx := stream.Context()
+	chunks := make(chan chunk, window)
+	var recvErr error
+
+	go func() {
+		defer close(chunks)
+		for {
+			c := chunk{req: &openfgav1.WriteRequest{}}
+			if err := stream.RecvMsg(c.req); err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if status.Code(err) != codes.InvalidArgument {
+					recvErr = err
+					return
+				}
+				// the chunk was received but is invalid, the next ones may be valid
+				c = chunk{err: err}
+			}
+
+			select {
+			case chunks <- c:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for c := range chunks {
+		err := c.err
+		if err == nil {
+			_, err = w.Write(ctx, c.req)
+		}
+		if err := stream.SendMsg(ackOf(err)); err != nil {
+			return err
+		}
+	}
+	// the receiving goroutine sets recvErr before closing chunks
+	return recvErr
+}
+
+// ackOf returns the acknowledgement of a chunk written with err.
+func ackOf(err error) *spb.Status {
+	return status.Convert(serverErrors.WithErrorInfo(err)).Proto()
+}
+
+// DeleteTuples deletes the tuples of the store of req matching its tuple key on conn, and returns
+// the number of deleted tuples.
+func DeleteTuples(ctx context.Context, conn grpc.ClientConnInterface, req *openfgav1.ReadRequest, opts ...grpc.CallOption) (uint64, error) {
+	out := &wrapperspb.UInt64Value{}
+	if err := conn.Invoke(ctx, DeleteTuplesMethod, req, out, opts...); err != nil {
+		return 0, err
+	}
+	return out.GetValue(), nil
+}
+
+// Stream is the client side of a bidirectional streaming Write.
+type Stream struct {
+	grpc.ClientStream
+}
+
+// NewStream opens a bidirectional streaming Write on conn.
+func NewStream(ctx context.Context, conn grpc.ClientConnInterface, opts ...grpc.CallOption) (*Stream, error) {
+	stream, err := conn.NewStream(ctx, &writeStreamDesc, WriteMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{ClientStream: stream}, nil
+}
+
+// Send sends a chunk of tuples.
+func (s *Stream) Send(req *openfgav1.WriteRequest) error {
+	return s.SendMsg(req)
+}
+
+// Recv receives the acknowledgement of the next chunk: nil if the chunk was written, or the error
+// of the chunk. It returns io.EOF once all the chunks are acknowledged, or the error of the stream.
+// The errors of the chunks are wrapped in a *ChunkError, so that they can be told apart from the
+// error of the stream.
+func (s *Stream) Recv() error {
+	ack := &spb.Status{}
+	if err := s.RecvMsg(ack); err != nil {
+		return err
+	}
+	if err := status.FromProto(ack).Err(); err != nil {
+		return &ChunkError{err: err}
+	}
+	return nil
+}
+
+// ChunkError is the error of a chunk that was not written. The next chunks of the stream are
+// still written.
+type ChunkError struct {
+	err error
+}
+
+func (e *ChunkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the status of the chunk.
+func (e *ChunkError) GRPCStatus() *status.Status {
+	return status.Convert(e.err)
+}
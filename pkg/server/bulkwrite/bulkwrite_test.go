@@ -0,0 +1,200 @@
+package bulkwrite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	storeID        = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+	unknownModelID = "01ARZ3NDEKTSV4RRFFQ69G5FAW"
+)
+
+type recordingWriter struct {
+	mu      sync.Mutex
+	written []*openfgav1.WriteRequest
+	deleted []*openfgav1.ReadRequestTupleKey
+}
+
+func (w *recordingWriter) Write(_ context.Context, req *openfgav1.WriteRequest) (*openfgav1.WriteResponse, error) {
+	if req.GetAuthorizationModelId() == unknownModelID {
+		return nil, serverErrors.AuthorizationModelNotFound(unknownModelID)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = append(w.written, req)
+	return &openfgav1.WriteResponse{}, nil
+}
+
+func (w *recordingWriter) DeleteTuples(_ context.Context, req *openfgav1.ReadRequest) (uint64, error) {
+	if req.GetTupleKey() == nil {
+		return 0, status.Error(codes.InvalidArgument, "a filter is required")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deleted = append(w.deleted, req.GetTupleKey())
+	return 3, nil
+}
+
+func newClient(t *testing.T, w Server) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(validator.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(validator.StreamServerInterceptor()),
+	)
+	RegisterServer(srv, w, WithWindow(2))
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		srv.Stop()
+		_ = listener.Close()
+	})
+	return conn
+}
+
+func chunkOf(modelID string, objects ...string) *openfgav1.WriteRequest {
+	tupleKeys := make([]*openfgav1.TupleKey, 0, len(objects))
+	for _, object := range objects {
+		tupleKeys = append(tupleKeys, tuple.NewTupleKey(object, "viewer", "user:anne"))
+	}
+	return &openfgav1.WriteRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		Writes:               &openfgav1.WriteRequestWrites{TupleKeys: tupleKeys},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	w := &recordingWriter{}
+	conn := newClient(t, w)
+
+	stream, err := NewStream(context.Background(), conn)
+	require.NoError(t, err)
+
+	chunks := []*openfgav1.WriteRequest{
+		chunkOf("", "document:1", "document:2"),
+		chunkOf(unknownModelID, "document:3"),
+		{StoreId: "invalid"},
+		chunkOf("", "document:4"),
+	}
+	go func() {
+		for _, chunk := range chunks {
+			if err := stream.Send(chunk); err != nil {
+				return
+			}
+		}
+		_ = stream.CloseSend()
+	}()
+
+	var acks []error
+	for {
+		err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		acks = append(acks, err)
+		if len(acks) > len(chunks) {
+			require.FailNow(t, "too many acknowledgements")
+		}
+	}
+
+	require.Len(t, acks, 4)
+	require.NoError(t, acks[0])
+
+	var chunkErr *ChunkError
+	require.ErrorAs(t, acks[1], &chunkErr)
+	require.Equal(t, serverErrors.ReasonModelNotFound, serverErrors.ErrorReason(status.Convert(acks[1])))
+
+	require.ErrorAs(t, acks[2], &chunkErr)
+	require.Equal(t, codes.InvalidArgument, status.Code(acks[2]))
+
+	require.NoError(t, acks[3])
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	require.Len(t, w.written, 2)
+	require.Len(t, w.written[0].GetWrites().GetTupleKeys(), 2)
+	require.Equal(t, "document:4", w.written[1].GetWrites().GetTupleKeys()[0].GetObject())
+}
+
+func TestWriteStopsWhenTheClientCancels(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	conn := newClient(t, &recordingWriter{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := NewStream(ctx, conn)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(chunkOf("", "document:1")))
+	require.NoError(t, stream.Recv())
+
+	cancel()
+	require.Equal(t, codes.Canceled, status.Code(stream.Recv()))
+}
+
+func TestDeleteTuples(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	w := &recordingWriter{}
+	conn := newClient(t, w)
+
+	deleted, err := DeleteTuples(context.Background(), conn, &openfgav1.ReadRequest{
+		StoreId:  storeID,
+		TupleKey: &openfgav1.ReadRequestTupleKey{Object: "document:"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), deleted)
+
+	_, err = DeleteTuples(context.Background(), conn, &openfgav1.ReadRequest{StoreId: storeID})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = DeleteTuples(context.Background(), conn, &openfgav1.ReadRequest{StoreId: "invalid"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	require.Len(t, w.deleted, 1)
+	require.Equal(t, "document:", w.deleted[0].GetObject())
+}
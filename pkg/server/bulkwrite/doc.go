@@ -0,0 +1,17 @@
+// Package bulkwrite contains the service for bulk loads and deletes.
+//
+// The service is not part of the OpenFGA API. Its methods use the messages of the API: the client
+// streams chunks of tuples as openfga.v1.WriteRequest messages on
+// /openfga.bulk.v1.BulkWriteService/Write, and the server writes every chunk as one Write and
+// streams back a google.rpc.Status message per chunk, in the order of the chunks. A chunk that
+// fails does not stop the stream, so the client can retry it or skip it while the next chunks
+// are written.
+//
+// The server receives at most a window of chunks ahead of the one it writes, so a client that
+// sends faster than the datastore writes is slowed down by the flow control of gRPC.
+//
+// The unary /openfga.bulk.v1.BulkWriteService/DeleteTuples method deletes all the tuples matching
+// the tuple key of an openfga.v1.ReadRequest message in batches on the server, and returns their
+// number as a google.protobuf.UInt64Value message. Unlike Write, it is also served over HTTP as
+// DELETE /stores/{store_id}/tuples.
+package bulkwrite
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/cachehints"
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/cacheinvalidation"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	// CacheControlHeader is the response header with the time for which the clients can cache a
+	// Check response, derived from the rate of the writes to its store.
+	CacheControlHeader = "Cache-Control"
+
+	// InvalidationKeysHeader is the response header with the invalidation keys of a Check
+	// response, i.e. the keys of the writes streamed by the cache invalidation service that
+	// invalidate it.
+	InvalidationKeysHeader = "Openfga-Invalidation-Keys"
+)
+
+// ErrCacheHintsDisabled is returned by WatchCacheInvalidations when the cache hints are not
+// enabled with WithCacheHintsEnabled.
+var ErrCacheHintsDisabled = status.Error(codes.Unimplemented, "cache hints are not enabled")
+
+// setCheckCacheHints sets the Cache-Control and the invalidation keys headers of a Check response,
+// if enabled. latestModel reports whether the request was resolved with the latest authorization
+// model of the store rather than a pinned one. With HIGHER_CONSISTENCY, the response must not be
+// reused without being revalidated.
+func (s *Server) setCheckCacheHints(ctx context.Context, req *openfgav1.CheckRequest, typesys *typesystem.TypeSystem, latestModel bool) {
+	if s.cacheHints == nil {
+		return
+	}
+	storeID := req.GetStoreId()
+
+	// the writes of the other replicas are observed through the cache controller, which follows
+	// the changelog of the store, and through the latest model, whose id encodes its write time
+	if watermark := s.sharedDatastoreResources.CacheController.DetermineInvalidationTime(ctx, storeID); !watermark.IsZero() {
+		s.cacheHints.ObserveTupleWrite(storeID, watermark)
+	}
+	if latestModel {
+		if id, err := ulid.Parse(typesys.GetAuthorizationModelID()); err == nil {
+			s.cacheHints.ObserveModelWrite(storeID, ulid.Time(id.Time()))
+		}
+	}
+
+	cacheControl := "no-cache"
+	if req.GetConsistency() != openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		if maxAge := s.cacheHints.MaxAge(storeID, latestModel); maxAge >= time.Second {
+			cacheControl = fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+		}
+	}
+	s.transport.SetHeader(ctx, CacheControlHeader, cacheControl)
+
+	tk := req.GetTupleKey()
+	keys := cachehints.Keys(typesys, tuple.GetType(tk.GetObject()), tk.GetRelation(), latestModel)
+	s.transport.SetHeader(ctx, InvalidationKeysHeader, strings.Join(keys, ", "))
+}
+
+// WatchCacheInvalidations sends with send the invalidation keys of the writes to a store, read from
+// its changelog every poll interval, until ctx is done or send fails. When the stream is resumed
+// from a continuation token, its first invalidation includes the model key, as the authorization
+// models written in the meantime are not known.
+func (s *Server) WatchCacheInvalidations(ctx context.Context, req *cacheinvalidation.WatchRequest, send func(*cacheinvalidation.Invalidation) error) error {
+	ctx, span := tracer.Start(ctx, "WatchCacheInvalidations", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+	))
+	defer span.End()
+
+	if s.cacheHints == nil {
+		return ErrCacheHintsDisabled
+	}
+
+	if err := (&openfgav1.ReadChangesRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.ReadChanges.String(),
+	})
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.ReadChanges); err != nil {
+		return err
+	}
+
+	token := req.ContinuationToken
+	modelID := ""
+	if token == "" {
+		var err error
+		if token, err = s.latestTupleChange(ctx, req.StoreID); err != nil {
+			return serverErrors.HandleError("", err)
+		}
+		if modelID, err = s.latestModelID(ctx, req.StoreID); err != nil {
+			return serverErrors.HandleError("", err)
+		}
+	}
+
+	ticker := time.NewTicker(s.cacheInvalidationPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		changed, latestToken, err := s.readChangedObjectTypes(ctx, req.StoreID, token)
+		if err != nil {
+			return serverErrors.HandleError("", err)
+		}
+		latestModelID, err := s.latestModelID(ctx, req.StoreID)
+		if err != nil {
+			return serverErrors.HandleError("", err)
+		}
+
+		keys := make([]string, 0, len(changed)+1)
+		for objectType := range changed {
+			keys = append(keys, objectType)
+		}
+		slices.Sort(keys)
+		if latestModelID != modelID {
+			keys = append(keys, cachehints.ModelKey)
+		}
+		token, modelID = latestToken, latestModelID
+		if len(keys) == 0 {
+			continue
+		}
+		if err := send(&cacheinvalidation.Invalidation{Keys: keys, ContinuationToken: token}); err != nil {
+			return err
+		}
+	}
+}
+
+// latestModelID returns the id of the latest authorization model of a store, or an empty id if it
+// has none.
+func (s *Server) latestModelID(ctx context.Context, storeID string) (string, error) {
+	model, err := s.datastore.FindLatestAuthorizationModel(ctx, storeID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return model.GetId(), nil
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/server/cacheinvalidation"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestCacheHints(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithTransport(transport),
+		WithCacheHintsEnabled(true),
+		WithCacheHintsMaxAge(time.Minute),
+		WithCacheInvalidationPollInterval(10*time.Millisecond),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	writeModel := func() string {
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+	modelID := writeModel()
+
+	check := func(modelID string, consistency openfgav1.ConsistencyPreference) {
+		transport.reset()
+		_, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+			Consistency:          consistency,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("check", func(t *testing.T) {
+		check("", openfgav1.ConsistencyPreference_UNSPECIFIED)
+		require.Equal(t, "private, max-age=60", transport.get(CacheControlHeader))
+		require.Equal(t, "document, #model", transport.get(InvalidationKeysHeader))
+
+		check(modelID, openfgav1.ConsistencyPreference_UNSPECIFIED)
+		require.Equal(t, "private, max-age=60", transport.get(CacheControlHeader))
+		require.Equal(t, "document", transport.get(InvalidationKeysHeader))
+
+		check(modelID, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY)
+		require.Equal(t, "no-cache", transport.get(CacheControlHeader))
+	})
+
+	var token string
+	t.Run("watch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		invalidations := make(chan *cacheinvalidation.Invalidation)
+		done := make(chan error, 1)
+		go func() {
+			done <- s.WatchCacheInvalidations(ctx, &cacheinvalidation.WatchRequest{StoreID: storeID}, func(invalidation *cacheinvalidation.Invalidation) error {
+				invalidations <- invalidation
+				return nil
+			})
+		}()
+		// the stream starts from the latest write once it reads it
+		time.Sleep(50 * time.Millisecond)
+
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+		invalidation := <-invalidations
+		require.Equal(t, []string{"document"}, invalidation.Keys)
+		require.NotEmpty(t, invalidation.ContinuationToken)
+		token = invalidation.ContinuationToken
+
+		writeModel()
+		require.Equal(t, []string{"#model"}, (<-invalidations).Keys)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("resumes_with_the_model_key", func(t *testing.T) {
+		var keys []string
+		err := s.WatchCacheInvalidations(ctx, &cacheinvalidation.WatchRequest{StoreID: storeID, ContinuationToken: token}, func(invalidation *cacheinvalidation.Invalidation) error {
+			keys = invalidation.Keys
+			return context.Canceled
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, []string{"#model"}, keys)
+	})
+}
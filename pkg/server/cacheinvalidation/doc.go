@@ -0,0 +1,15 @@
+// Package cacheinvalidation serves the invalidation keys of the writes to a store as a stream, so
+// that the clients caching the Check responses can drop the responses the writes invalidate
+// instead of waiting for their max-age.
+//
+// The Check responses carry their invalidation keys in the Openfga-Invalidation-Keys header: the
+// object types whose tuples can change them, "*" if any tuple write can change them, and "#model"
+// if they were resolved with the latest authorization model of the store. Each invalidation of the
+// stream has the object types of the tuples written or deleted, and "#model" when an authorization
+// model is written, and the continuation token from which to resume the stream.
+//
+// The invalidations are served by the cache invalidation service, a server streaming method whose
+// messages are encoded as JSON: /openfga.cacheinvalidation.v1.CacheInvalidationService/Watch. It is
+// also served over HTTP as GET /stores/{store_id}/cache-invalidations, whose response has one
+// invalidation per line.
+package cacheinvalidation
@@ -0,0 +1,124 @@
+package cacheinvalidation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the cache invalidation service.
+	ServiceName = "openfga.cacheinvalidation.v1.CacheInvalidationService"
+
+	// WatchMethod is the full name of the server streaming method that streams the invalidations
+	// of a store.
+	WatchMethod = "/" + ServiceName + "/Watch"
+
+	// codecName is the content-subtype of the cache invalidation requests. The cache invalidation
+	// service is not part of the OpenFGA API, so its messages are encoded as JSON instead of
+	// generated protobufs.
+	codecName = "openfga-cacheinvalidation-json"
+)
+
+var watchStreamDesc = grpc.StreamDesc{
+	StreamName:    "Watch",
+	ServerStreams: true,
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// WatchRequest watches the invalidations of a store after the continuation token, or after its
+// latest write if no continuation token is given.
+type WatchRequest struct {
+	StoreID           string `json:"store_id"`
+	ContinuationToken string `json:"continuation_token,omitempty"`
+}
+
+// Invalidation has the invalidation keys of writes to a store, and the continuation token from
+// which to watch the next invalidations.
+type Invalidation struct {
+	Keys              []string `json:"keys"`
+	ContinuationToken string   `json:"continuation_token"`
+}
+
+// Server streams the invalidations of the stores. It is implemented by the OpenFGA server.
+type Server interface {
+	WatchCacheInvalidations(ctx context.Context, req *WatchRequest, send func(*Invalidation) error) error
+}
+
+// RegisterServer registers the cache invalidation service, which streams the invalidations with
+// srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	desc := watchStreamDesc
+	desc.Handler = watchHandler
+
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods:     []grpc.MethodDesc{},
+		Streams:     []grpc.StreamDesc{desc},
+		Metadata:    "pkg/server/cacheinvalidation/service.go",
+	}, srv)
+}
+
+func watchHandler(srv any, stream grpc.ServerStream) error {
+	in := &WatchRequest{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(Server).WatchCacheInvalidations(stream.Context(), in, func(invalidation *Invalidation) error {
+		return stream.SendMsg(invalidation)
+	})
+}
+
+// Watch calls recv with each invalidation of the store of req streamed on conn, until ctx is done
+// or the stream fails. An error returned by recv ends the stream and is returned.
+func Watch(ctx context.Context, conn grpc.ClientConnInterface, req *WatchRequest, recv func(*Invalidation) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &watchStreamDesc, WatchMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		invalidation := &Invalidation{}
+		err := stream.RecvMsg(invalidation)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := recv(invalidation); err != nil {
+			return err
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
 	"github.com/openfga/openfga/internal/cachecontroller"
+	"github.com/openfga/openfga/internal/cluster"
 	"github.com/openfga/openfga/internal/graph"
 	"github.com/openfga/openfga/internal/modelgraph"
 	"github.com/openfga/openfga/internal/telemetry"
@@ -68,12 +69,43 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 	if err != nil {
 		return nil, err
 	}
+	req.Consistency = s.sessionConsistency(ctx, req.GetStoreId(), req.GetConsistency())
+
+	ctx, _, err = s.contextWithMaxResolutionDepth(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	storeID := req.GetStoreId()
+	latestModel := req.GetAuthorizationModelId() == ""
+
+	req.Context = s.injectConditionContext(ctx, req.GetContext())
+	req.Context, err = s.enrichContext(ctx, methodName, storeID, tk.GetUser(), tk.GetObject(), req.GetContext())
+	if err != nil {
+		return nil, err
+	}
 
 	if s.featureFlagClient.Boolean(serverconfig.ExperimentalWeightedGraphCheck, storeID) {
 		// TODO: This path is missing some of the metrics/tracing information reported below
+		if s.contextualTuplesValidationMode == serverconfig.ContextualTuplesValidationModeLenient {
+			typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+			if err != nil {
+				return nil, err
+			}
+			s.dropUndefinedContextualTupleKeys(ctx, typesys, req.GetContextualTuples())
+		}
 		res, _, err := s.v2Check(ctx, req, s.sharedDatastoreResources.CheckCache, s.sharedDatastoreResources.CacheController, s.authzModelGraphResolver)
+		if res.GetAllowed() && checkReasonRequested(ctx) {
+			s.setCheckReasonHeader(ctx, req)
+		}
+		if format, ok := checkTraceRequested(ctx); ok && err == nil {
+			s.setCheckTraceHeader(ctx, req, format)
+		}
+		if err == nil && s.cacheHints != nil {
+			if typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId()); err == nil {
+				s.setCheckCacheHints(ctx, req, typesys, latestModel)
+			}
+		}
 		return res, err
 	}
 
@@ -82,6 +114,7 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 		return nil, err
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	s.dropUndefinedContextualTupleKeys(ctx, typesys, req.GetContextualTuples())
 
 	checkQuery := commands.NewCheckCommand(
 		s.datastore,
@@ -199,7 +232,17 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 		Allowed: resp.Allowed,
 	}
 
-	if s.featureFlagClient.Boolean(serverconfig.ExperimentalShadowWeightedGraphCheck, storeID) {
+	s.setServerTiming(ctx, time.Since(startTime), resp.GetResolutionMetadata().DatastoreDuration, s.checkCacheStatus(req.GetConsistency(), resp.GetResolutionMetadata()))
+
+	if res.GetAllowed() && checkReasonRequested(ctx) {
+		s.setCheckReasonHeader(ctx, req)
+	}
+	if format, ok := checkTraceRequested(ctx); ok {
+		s.setCheckTraceHeader(ctx, req, format)
+	}
+	s.setCheckCacheHints(ctx, req, typesys, latestModel)
+
+	if s.featureFlagClient.Boolean(serverconfig.ExperimentalShadowWeightedGraphCheck, storeID) && graph.ShouldShadow(s.shadowCheckResolverSamplePercent) {
 		go s.shadowV2Check(ctx, req, res, endTime,
 			resp.GetResolutionMetadata().DatastoreQueryCount,
 			resp.GetResolutionMetadata().DatastoreItemCount)
@@ -221,6 +264,9 @@ func (s *Server) shadowV2Check(ctx context.Context, req *openfgav1.CheckRequest,
 	if recoveredErr != nil {
 		err = recoveredErr.AsError()
 	}
+	if !errors.Is(err, modelgraph.ErrInvalidModel) {
+		graph.ObserveShadowCheck("weighted_graph_check", mainRes.GetAllowed(), time.Duration(mainTook)*time.Millisecond, res.GetAllowed(), time.Since(start), err)
+	}
 	if err != nil {
 		if errors.Is(err, modelgraph.ErrInvalidModel) {
 			s.logger.InfoWithContext(ctx, "invalid model graph check request")
@@ -288,6 +334,7 @@ func (s *Server) v2Check(
 		commands.WithCheckQueryV2Cache(cache),
 		commands.WithCheckQueryV2QueryCacheEnabled(s.cacheSettings.ShouldCacheCheckQueries()),
 		commands.WithCheckQueryV2QueryCacheTTL(s.cacheSettings.CheckQueryCacheTTL),
+		commands.WithCheckQueryV2CacheTTLJitterPercentage(s.cacheSettings.CacheTTLJitterPercentage),
 		commands.WithCheckQueryV2Planner(s.planner),
 		commands.WithCheckQueryV2LastCacheInvalidationTime(cacheInvalidationTime),
 		commands.WithCheckQueryV2ConcurrencyLimit(int(s.resolveNodeBreadthLimit)),
@@ -324,9 +371,81 @@ func (s *Server) v2Check(
 	return res, metadata, nil
 }
 
+// ResolveDispatchedCheck resolves a Check sub-problem dispatched by another replica of the cluster.
+func (s *Server) ResolveDispatchedCheck(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+	ctx, span := tracer.Start(ctx, "ResolveDispatchedCheck", trace.WithAttributes(
+		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(req.GetStoreID())},
+		attribute.KeyValue{Key: "tuple_key", Value: attribute.StringValue(req.GetTupleKey().String())},
+	))
+	defer span.End()
+
+	storeID := req.GetStoreID()
+	if err := s.checkAuthz(ctx, storeID, apimethod.Check); err != nil {
+		return nil, err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelID())
+	if err != nil {
+		return nil, err
+	}
+
+	checkResolver, checkResolverCloser, err := s.getCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	checkQuery := commands.NewCheckCommand(
+		s.datastore,
+		checkResolver,
+		typesys,
+		commands.WithCheckCommandLogger(s.logger),
+		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+		commands.WithCheckCommandCache(s.sharedDatastoreResources, s.cacheSettings),
+		commands.WithCheckDatastoreThrottler(
+			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, storeID),
+			s.checkDatastoreThrottleThreshold,
+			s.checkDatastoreThrottleDuration,
+		),
+	)
+
+	resp, err := checkQuery.ExecuteDispatched(ctx, req)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, commands.CheckCommandErrorToServerError(err)
+	}
+	return resp, nil
+}
+
 func (s *Server) getCheckResolverBuilder(storeID string) *graph.CheckResolverOrderedBuilder {
 	checkCacheOptions, checkDispatchThrottlingOptions := s.getCheckResolverOptions()
 
+	var hotPathIndex graph.HotPathIndex
+	if s.hotPathIndex != nil {
+		hotPathIndex = s.hotPathIndex
+	}
+
+	var materializedPermissions graph.MaterializedPermissions
+	if s.materializer != nil {
+		materializedPermissions = s.materializer
+	}
+
+	var (
+		clusterPeers      graph.ClusterPeers
+		clusterDispatcher graph.CheckDispatcher
+	)
+	if s.cluster != nil && s.clusterSingleflightEnabled {
+		clusterPeers = s.cluster
+	}
+	if s.cluster != nil && s.clusterDispatchEnabled {
+		clusterDispatcher = s.cluster
+	}
+
+	var remoteCheckResolver graph.CheckResolver
+	if s.dispatchTier != nil {
+		remoteCheckResolver = cluster.NewRemoteCheckResolver(s.dispatchTier, cluster.WithRemoteCheckResolverLogger(s.logger))
+	}
+
 	return graph.NewOrderedCheckResolvers([]graph.CheckResolverOrderedBuilderOpt{
 		graph.WithLocalCheckerOpts([]graph.LocalCheckerOption{
 			graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
@@ -346,8 +465,17 @@ func (s *Server) getCheckResolverBuilder(storeID string) *graph.CheckResolverOrd
 		graph.WithShadowResolverOpts([]graph.ShadowResolverOpt{
 			graph.ShadowResolverWithLogger(s.logger),
 			graph.ShadowResolverWithTimeout(s.shadowCheckResolverTimeout),
+			graph.ShadowResolverWithSamplePercentage(s.shadowCheckResolverSamplePercent),
 		}...),
 		graph.WithCachedCheckResolverOpts(s.cacheSettings.ShouldCacheCheckQueries(), checkCacheOptions...),
 		graph.WithDispatchThrottlingCheckResolverOpts(s.checkDispatchThrottlingEnabled, checkDispatchThrottlingOptions...),
+		graph.WithHotPathIndex(hotPathIndex),
+		graph.WithMaterializedPermissions(materializedPermissions),
+		graph.WithClusterSingleflight(clusterPeers, graph.WithClusterSingleflightLogger(s.logger)),
+		graph.WithClusterDispatch(clusterDispatcher, graph.WithClusterDispatchLogger(s.logger)),
+		graph.WithRemoteCheckResolver(remoteCheckResolver),
+		graph.WithStoreBulkheads(s.checkStoreBulkheads),
+		graph.WithCheckCoalescer(s.checkCoalescer),
+		graph.WithCustomCheckResolvers(s.customCheckResolvers...),
 	}...)
 }
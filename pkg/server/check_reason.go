@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/pkg/server/commands"
+)
+
+// CheckReasonHeader requests the reason of an allowed Check. When a request sets it to "true",
+// the response of the Check, if it is allowed, carries a header of the same name with a JSON
+// array of the tuples of a path that grants the relation, ordered from the user to the object.
+// For instance, the path of user:anne, editor, document:1 can be that user:anne is a member of
+// group:eng, whose members are editors of document:1:
+//
+//	[{"object":"group:eng","relation":"member","user":"user:anne"},
+//	 {"object":"document:1","relation":"editor","user":"group:eng#member"}]
+const CheckReasonHeader = "Openfga-Check-Reason"
+
+// checkReasonTuple is a tuple of the path in the CheckReasonHeader.
+type checkReasonTuple struct {
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+	User      string `json:"user"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// newCheckReason returns the tuples of path in the format of the CheckReasonHeader.
+func newCheckReason(path []*openfgav1.TupleKey) []checkReasonTuple {
+	reason := make([]checkReasonTuple, 0, len(path))
+	for _, tk := range path {
+		reason = append(reason, checkReasonTuple{
+			Object:    tk.GetObject(),
+			Relation:  tk.GetRelation(),
+			User:      tk.GetUser(),
+			Condition: tk.GetCondition().GetName(),
+		})
+	}
+	return reason
+}
+
+// checkReasonRequested reports whether the request sets the CheckReasonHeader.
+func checkReasonRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(CheckReasonHeader))
+	if len(values) == 0 {
+		return false
+	}
+	requested, _ := strconv.ParseBool(strings.TrimSpace(values[0]))
+	return requested
+}
+
+// setCheckReasonHeader returns the path of tuples that grants the relation of the allowed req in
+// the CheckReasonHeader. The failures to find the path are logged, and leave the header unset.
+func (s *Server) setCheckReasonHeader(ctx context.Context, req *openfgav1.CheckRequest) {
+	ctx, span := tracer.Start(ctx, "checkReason")
+	defer span.End()
+
+	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return
+	}
+
+	q := commands.NewCheckReasonQuery(s.datastore, typesys,
+		commands.WithCheckReasonResolveNodeLimit(s.resolveNodeLimit),
+	)
+	path, ok, err := q.Execute(ctx, &commands.CheckReasonParams{
+		StoreID:          req.GetStoreId(),
+		TupleKey:         req.GetTupleKey(),
+		ContextualTuples: req.GetContextualTuples(),
+		Context:          req.GetContext(),
+		Consistency:      req.GetConsistency(),
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		s.logger.WarnWithContext(ctx, "failed to find the reason of an allowed check", zap.Error(err))
+		return
+	}
+	if !ok {
+		// e.g. the tuples that granted the relation were deleted since the check was cached
+		return
+	}
+
+	value, err := json.Marshal(newCheckReason(path))
+	if err != nil {
+		return
+	}
+	s.transport.SetHeader(ctx, CheckReasonHeader, string(value))
+}
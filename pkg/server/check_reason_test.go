@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestCheckReasonHeader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user]
+
+		type document
+			relations
+				define editor: [user, group#member]
+				define viewer: editor
+	`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("group:eng", "member", "user:anne"),
+			tuple.NewTupleKey("document:1", "editor", "group:eng#member"),
+		}},
+	})
+	require.NoError(t, err)
+
+	check := func(ctx context.Context, user string) *openfgav1.CheckResponse {
+		transport.reset()
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", user),
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("not_returned_unless_requested", func(t *testing.T) {
+		require.True(t, check(ctx, "user:anne").GetAllowed())
+		require.Empty(t, transport.get(CheckReasonHeader))
+	})
+
+	t.Run("returned_for_allowed_checks", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(CheckReasonHeader, "true"))
+		require.True(t, check(ctx, "user:anne").GetAllowed())
+
+		var reason []checkReasonTuple
+		require.NoError(t, json.Unmarshal([]byte(transport.get(CheckReasonHeader)), &reason))
+		require.Equal(t, []checkReasonTuple{
+			{Object: "group:eng", Relation: "member", User: "user:anne"},
+			{Object: "document:1", Relation: "editor", User: "group:eng#member"},
+		}, reason)
+	})
+
+	t.Run("not_returned_for_denied_checks", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(CheckReasonHeader, "true"))
+		require.False(t, check(ctx, "user:bob").GetAllowed())
+		require.Empty(t, transport.get(CheckReasonHeader))
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
@@ -734,3 +735,50 @@ func TestV2CheckQueryCacheEnabled(t *testing.T) {
 		require.Empty(t, checkCache.setKeysWithPrefix("c."), "cache should have no subproblem entries when query cache is disabled")
 	})
 }
+
+func TestCheck_ContextualTuplesValidationMode(t *testing.T) {
+	contextualTuples := &openfgav1.ContextualTupleKeys{
+		TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("folder:1", "viewer", "user:bob"),
+			tuple.NewTupleKey("document:1", "viewer", "user:bob"),
+		},
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		s, req := setupCheckServer(t, "", nil)
+		req.TupleKey.User = "user:bob"
+		req.ContextualTuples = proto.Clone(contextualTuples).(*openfgav1.ContextualTupleKeys)
+
+		_, err := s.Check(context.Background(), req)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_tuple), status.Code(err))
+		require.ErrorContains(t, err, "type 'folder' not found")
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		s, req := setupCheckServer(t, "", nil,
+			WithContextualTuplesValidationMode(serverconfig.ContextualTuplesValidationModeLenient))
+		req.TupleKey.User = "user:bob"
+		req.ContextualTuples = proto.Clone(contextualTuples).(*openfgav1.ContextualTupleKeys)
+
+		resp, err := s.Check(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("lenient_still_rejects_undefined_relations", func(t *testing.T) {
+		s, req := setupCheckServer(t, "", nil,
+			WithContextualTuplesValidationMode(serverconfig.ContextualTuplesValidationModeLenient))
+		req.ContextualTuples = &openfgav1.ContextualTupleKeys{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "editor", "user:bob")},
+		}
+
+		_, err := s.Check(context.Background(), req)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid_mode", func(t *testing.T) {
+		_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+		_, err := NewServerWithOpts(WithDatastore(ds), WithContextualTuplesValidationMode("relaxed"))
+		require.ErrorContains(t, err, "invalid contextual tuples validation mode 'relaxed'")
+	})
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/pkg/server/commands"
+)
+
+// CheckTraceHeader requests the resolution tree of a Check, for the tools that draw flame-graph
+// views of a single Check. When a request sets it to "json" or "dot", the response of the Check
+// carries a header of the same name with the tree of the sub-problems of its resolution and the
+// time that each took, in JSON (a graph.ResolutionTrace) or in the DOT language of Graphviz.
+//
+// The tree is the one of a second resolution of the Check, without the caches of the server and
+// with the default strategies of the resolution, so that each sub-problem is a node of the tree.
+// It is bounded to graph.DefaultMaxTraceNodes nodes.
+const CheckTraceHeader = "Openfga-Check-Trace"
+
+const (
+	checkTraceFormatJSON = "json"
+	checkTraceFormatDOT  = "dot"
+)
+
+// checkTraceRequested returns the format of the CheckTraceHeader of the request, or false if the
+// request doesn't set it to a supported format.
+func checkTraceRequested(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(CheckTraceHeader))
+	if len(values) == 0 {
+		return "", false
+	}
+	switch format := strings.ToLower(strings.TrimSpace(values[0])); format {
+	case checkTraceFormatJSON, checkTraceFormatDOT:
+		return format, true
+	default:
+		return "", false
+	}
+}
+
+// setCheckTraceHeader resolves req again with a graph.TracingCheckResolver and returns its
+// resolution tree in the CheckTraceHeader, in format. The failures of the resolution are logged,
+// and leave the header unset.
+func (s *Server) setCheckTraceHeader(ctx context.Context, req *openfgav1.CheckRequest, format string) {
+	ctx, span := tracer.Start(ctx, "checkTrace")
+	defer span.End()
+
+	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return
+	}
+
+	tracingResolver := graph.NewTracingCheckResolver()
+	localChecker := graph.NewLocalChecker(
+		graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		graph.WithMaxResolutionDepth(s.resolveNodeLimit),
+		graph.WithUpstreamTimeout(s.requestTimeout),
+		graph.WithLocalCheckerLogger(s.logger),
+	)
+	defer localChecker.Close()
+	tracingResolver.SetDelegate(localChecker)
+	localChecker.SetDelegate(tracingResolver)
+
+	checkQuery := commands.NewCheckCommand(
+		s.datastore,
+		tracingResolver,
+		typesys,
+		commands.WithCheckCommandLogger(s.logger),
+		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+	)
+	_, _, err = checkQuery.Execute(ctx, &commands.CheckCommandParams{
+		StoreID:          req.GetStoreId(),
+		TupleKey:         req.GetTupleKey(),
+		ContextualTuples: req.GetContextualTuples(),
+		Context:          req.GetContext(),
+		Consistency:      req.GetConsistency(),
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		s.logger.WarnWithContext(ctx, "failed to trace the resolution of a check", zap.Error(err))
+		return
+	}
+
+	trace := tracingResolver.Trace()
+	if format == checkTraceFormatDOT {
+		// the header values can't span several lines
+		s.transport.SetHeader(ctx, CheckTraceHeader, strings.ReplaceAll(trace.DOT(), "\n", " "))
+		return
+	}
+	value, err := json.Marshal(trace)
+	if err != nil {
+		return
+	}
+	s.transport.SetHeader(ctx, CheckTraceHeader, string(value))
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestCheckTraceHeader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define blocked: [user]
+				define member: [user] but not blocked
+
+		type document
+			relations
+				define viewer: [group#member]
+	`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+			tuple.NewTupleKey("group:eng", "member", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	check := func(ctx context.Context, user string) *openfgav1.CheckResponse {
+		transport.reset()
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", user),
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("not_returned_unless_requested", func(t *testing.T) {
+		require.True(t, check(ctx, "user:anne").GetAllowed())
+		require.Empty(t, transport.get(CheckTraceHeader))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(CheckTraceHeader, "json"))
+		require.False(t, check(ctx, "user:bob").GetAllowed())
+
+		var trace graph.ResolutionTrace
+		require.NoError(t, json.Unmarshal([]byte(transport.get(CheckTraceHeader)), &trace))
+		require.False(t, trace.Truncated)
+		require.Equal(t, "document:1", trace.Root.Object)
+		require.Equal(t, "viewer", trace.Root.Relation)
+		require.Equal(t, "user:bob", trace.Root.User)
+		require.False(t, trace.Root.Allowed)
+		require.Len(t, trace.Root.Children, 1)
+		member := trace.Root.Children[0]
+		require.Equal(t, "group:eng", member.Object)
+		require.Equal(t, "member", member.Relation)
+		require.Equal(t, "user:bob", member.User)
+		require.False(t, member.Allowed)
+		require.GreaterOrEqual(t, trace.Root.DurationMicros, member.DurationMicros)
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(CheckTraceHeader, "dot"))
+		require.True(t, check(ctx, "user:anne").GetAllowed())
+
+		trace := transport.get(CheckTraceHeader)
+		require.NotContains(t, trace, "\n")
+		require.Contains(t, trace, "digraph {")
+		require.Contains(t, trace, `n0 -> n1;`)
+		require.Contains(t, trace, `color=green`)
+	})
+
+	t.Run("unsupported_format", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(CheckTraceHeader, "svg"))
+		require.True(t, check(ctx, "user:anne").GetAllowed())
+		require.Empty(t, transport.get(CheckTraceHeader))
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/cespare/xxhash/v2"
 	"go.uber.org/zap"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -34,6 +33,7 @@ type BatchCheckQuery struct {
 	datastoreThrottlingEnabled bool
 	datastoreThrottleThreshold int
 	datastoreThrottleDuration  time.Duration
+	onCompletion               BatchCheckCompletionFunc
 }
 
 type BatchCheckCommandParams struct {
@@ -55,8 +55,27 @@ type BatchCheckMetadata struct {
 	DatastoreItemCount     uint64
 	DatastoreThrottleCount uint32
 	DuplicateCheckCount    int
+
+	// DatastoreDuration is the cumulative time spent in the datastore by the checks of the batch.
+	DatastoreDuration time.Duration
+
+	// Items holds the metadata of the check of each correlation ID. Duplicate checks share the
+	// metadata of the check that was evaluated for all of them.
+	Items map[CorrelationID]BatchCheckItemMetadata
 }
 
+// BatchCheckItemMetadata is the metadata of the evaluation of one check of a batch.
+type BatchCheckItemMetadata struct {
+	DatastoreQueryCount uint32
+	DatastoreItemCount  uint64
+	Duration            time.Duration
+}
+
+// BatchCheckCompletionFunc is called with the outcome of each check of a batch as soon as it is
+// evaluated, with the correlation IDs of all the duplicate checks that share it. It is called
+// concurrently by the checks evaluated concurrently.
+type BatchCheckCompletionFunc func(ids []CorrelationID, outcome *BatchCheckOutcome, metadata BatchCheckItemMetadata)
+
 type BatchCheckValidationError struct {
 	Message string
 }
@@ -108,6 +127,14 @@ func WithBatchCheckDatastoreThrottler(enabled bool, threshold int, duration time
 	}
 }
 
+// WithBatchCheckCompletionFunc calls fn with the outcome of each check as soon as it is evaluated,
+// in the order of completion, before Execute returns them all.
+func WithBatchCheckCompletionFunc(fn BatchCheckCompletionFunc) BatchCheckQueryOption {
+	return func(bq *BatchCheckQuery) {
+		bq.onCompletion = fn
+	}
+}
+
 func NewBatchCheckCommand(datastore storage.RelationshipTupleReader, checkResolver graph.CheckResolver, typesys *typesystem.TypeSystem, opts ...BatchCheckQueryOption) *BatchCheckQuery {
 	cmd := &BatchCheckQuery{
 		logger:              logger.NewNoopLogger(),
@@ -171,6 +198,7 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 	var dispatchThrottleCount atomic.Uint32
 	var totalItemCount atomic.Uint64
 	var datastoreThrottleCount atomic.Uint32
+	var totalDatastoreDuration atomic.Int64
 
 	pool := concurrency.NewPool(ctx, int(bq.maxConcurrentChecks))
 	for key, item := range cacheKeyMap {
@@ -178,9 +206,11 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 		pool.Go(func(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
-				resultMap.Store(key, &BatchCheckOutcome{
+				outcome := &BatchCheckOutcome{
 					Err: ctx.Err(),
-				})
+				}
+				resultMap.Store(key, outcome)
+				bq.complete(item.CorrelationIDs, outcome)
 				return nil
 			default:
 			}
@@ -208,10 +238,12 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 
 			response, metadata, err := checkQuery.Execute(ctx, checkParams)
 
-			resultMap.Store(key, &BatchCheckOutcome{
+			outcome := &BatchCheckOutcome{
 				CheckResponse: response,
 				Err:           err,
-			})
+			}
+			resultMap.Store(key, outcome)
+			bq.complete(item.CorrelationIDs, outcome)
 
 			if metadata != nil {
 				if metadata.DispatchThrottled.Load() {
@@ -226,6 +258,7 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 
 			totalQueryCount.Add(response.GetResolutionMetadata().DatastoreQueryCount)
 			totalItemCount.Add(response.GetResolutionMetadata().DatastoreItemCount)
+			totalDatastoreDuration.Add(int64(response.GetResolutionMetadata().DatastoreDuration))
 
 			return nil
 		})
@@ -234,6 +267,7 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 	_ = pool.Wait()
 
 	results := map[CorrelationID]*BatchCheckOutcome{}
+	items := make(map[CorrelationID]BatchCheckItemMetadata, len(params.Checks))
 
 	// Each cacheKey can have > 1 associated CorrelationID
 	for cacheKey, checkItem := range cacheKeyMap {
@@ -243,6 +277,7 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 		for _, id := range checkItem.CorrelationIDs {
 			// map all associated CorrelationIDs to this outcome
 			results[id] = outcome
+			items[id] = itemMetadata(outcome)
 		}
 	}
 
@@ -253,9 +288,27 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 		DatastoreThrottleCount: datastoreThrottleCount.Load(),
 		DispatchCount:          totalDispatchCount.Load(),
 		DuplicateCheckCount:    len(params.Checks) - len(cacheKeyMap),
+		DatastoreDuration:      time.Duration(totalDatastoreDuration.Load()),
+		Items:                  items,
 	}, nil
 }
 
+// complete reports the outcome of the check of ids to the BatchCheckCompletionFunc, if any.
+func (bq *BatchCheckQuery) complete(ids []CorrelationID, outcome *BatchCheckOutcome) {
+	if bq.onCompletion != nil {
+		bq.onCompletion(ids, outcome, itemMetadata(outcome))
+	}
+}
+
+func itemMetadata(outcome *BatchCheckOutcome) BatchCheckItemMetadata {
+	resolutionMetadata := outcome.CheckResponse.GetResolutionMetadata()
+	return BatchCheckItemMetadata{
+		DatastoreQueryCount: resolutionMetadata.DatastoreQueryCount,
+		DatastoreItemCount:  resolutionMetadata.DatastoreItemCount,
+		Duration:            resolutionMetadata.Duration,
+	}
+}
+
 func validateCorrelationIDs(checks []*openfgav1.BatchCheckItem) error {
 	seen := map[string]struct{}{}
 
@@ -281,7 +334,7 @@ func validateCorrelationIDs(checks []*openfgav1.BatchCheckItem) error {
 
 func generateCacheKeyFromCheck(check *openfgav1.BatchCheckItem, storeID string, authModelID string) (CacheKey, error) {
 	tupleKey := check.GetTupleKey()
-	cacheKeyParams := &storage.CheckCacheKeyParams{
+	hash, err := storage.CheckCacheKeyHash(&storage.CheckCacheKeyParams{
 		StoreID:              storeID,
 		AuthorizationModelID: authModelID,
 		TupleKey: &openfgav1.TupleKey{
@@ -291,14 +344,10 @@ func generateCacheKeyFromCheck(check *openfgav1.BatchCheckItem, storeID string,
 		},
 		ContextualTuples: check.GetContextualTuples().GetTupleKeys(),
 		Context:          check.GetContext(),
-	}
-
-	hasher := xxhash.New()
-	err := storage.WriteCheckCacheKey(hasher, cacheKeyParams)
+	})
 	if err != nil {
 		return "", err
 	}
 
-	keyStr := strconv.FormatUint(hasher.Sum64(), 10)
-	return CacheKey(keyStr), nil
+	return CacheKey(strconv.FormatUint(hash, 10)), nil
 }
@@ -3,7 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
@@ -117,6 +119,7 @@ func TestBatchCheckCommand(t *testing.T) {
 		// Quantity of correlation IDs should be equal
 		require.Len(t, result, len(ids))
 		require.Equal(t, 9, meta.DuplicateCheckCount)
+		require.Len(t, meta.Items, len(ids))
 
 		// And each ID should appear in the response
 		for _, id := range ids {
@@ -421,6 +424,47 @@ func TestBatchCheckCommand(t *testing.T) {
 		// DatastoreThrottleCount should be 0 since no actual datastore throttling occurred
 		require.Equal(t, uint32(0), meta.DatastoreThrottleCount)
 	})
+
+	t.Run("completion_func_called_in_completion_order", func(t *testing.T) {
+		mockCheckResolver := graph.NewMockCheckResolver(mockController)
+
+		var completed [][]CorrelationID
+		var mu sync.Mutex
+		cmd := NewBatchCheckCommand(ds, mockCheckResolver, ts, WithBatchCheckCompletionFunc(
+			func(ids []CorrelationID, outcome *BatchCheckOutcome, metadata BatchCheckItemMetadata) {
+				mu.Lock()
+				defer mu.Unlock()
+				require.NoError(t, outcome.Err)
+				completed = append(completed, ids)
+			},
+		))
+
+		mockCheckResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ any, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+				if req.GetTupleKey().GetObject() == "doc:slow" {
+					time.Sleep(50 * time.Millisecond)
+				}
+				return &graph.ResolveCheckResponse{Allowed: true}, nil
+			})
+
+		checks := []*openfgav1.BatchCheckItem{
+			{TupleKey: &openfgav1.CheckRequestTupleKey{Object: "doc:slow", Relation: "viewer", User: "user:anne"}, CorrelationId: "slow"},
+			{TupleKey: &openfgav1.CheckRequestTupleKey{Object: "doc:fast", Relation: "viewer", User: "user:anne"}, CorrelationId: "fast"},
+			{TupleKey: &openfgav1.CheckRequestTupleKey{Object: "doc:fast", Relation: "viewer", User: "user:anne"}, CorrelationId: "fast_duplicate"},
+		}
+
+		result, _, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+			AuthorizationModelID: ts.GetAuthorizationModelID(),
+			Checks:               checks,
+			StoreID:              ulid.Make().String(),
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+
+		// the duplicate checks complete together
+		require.Equal(t, [][]CorrelationID{{"fast", "fast_duplicate"}, {"slow"}}, completed)
+	})
 }
 
 func TestGenerateCacheKeyFromCheck(t *testing.T) {
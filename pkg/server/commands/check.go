@@ -31,6 +31,7 @@ type CheckQueryV2 struct {
 	cache                     storage.InMemoryCache[any]
 	queryCacheEnabled         bool
 	queryCacheTTL             time.Duration
+	cacheTTLJitterPercentage  uint32
 	lastCacheInvalidationTime time.Time
 	planner                   planner.Manager
 	concurrencyLimit          int
@@ -78,6 +79,14 @@ func WithCheckQueryV2QueryCacheTTL(ttl time.Duration) CheckQueryV2Option {
 	}
 }
 
+// WithCheckQueryV2CacheTTLJitterPercentage sets the percentage (0-100) of the TTLs added as random
+// jitter to the TTL of each cached Check result and iterator.
+func WithCheckQueryV2CacheTTLJitterPercentage(pct uint32) CheckQueryV2Option {
+	return func(cmd *CheckQueryV2) {
+		cmd.cacheTTLJitterPercentage = pct
+	}
+}
+
 func WithCheckQueryV2LastCacheInvalidationTime(t time.Time) CheckQueryV2Option {
 	return func(cmd *CheckQueryV2) {
 		cmd.lastCacheInvalidationTime = t
@@ -177,6 +186,7 @@ func (q *CheckQueryV2) Execute(ctx context.Context, req *openfgav1.CheckRequest)
 			q.sharedResources.SingleflightGroup, // SHARED across requests
 			q.sharedResources.WaitGroup,         // SHARED across requests
 			q.sharedResources.V2IteratorDrainTimeout,
+			storagewrappers.WithCachedTupleReaderJitterPercentage(q.cacheTTLJitterPercentage),
 		)
 	}
 
@@ -190,6 +200,7 @@ func (q *CheckQueryV2) Execute(ctx context.Context, req *openfgav1.CheckRequest)
 		Datastore:                 datastore,
 		Cache:                     queryCache,
 		CacheTTL:                  q.queryCacheTTL,
+		CacheTTLJitterPercentage:  q.cacheTTLJitterPercentage,
 		LastCacheInvalidationTime: q.lastCacheInvalidationTime,
 		Planner:                   q.planner,
 		ConcurrencyLimit:          q.concurrencyLimit,
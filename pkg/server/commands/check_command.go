@@ -127,22 +127,7 @@ func (c *CheckQuery) Execute(ctx context.Context, params *CheckCommandParams) (*
 		return nil, nil, err
 	}
 
-	datastoreWithTupleCache := storagewrappers.NewRequestStorageWrapperWithCache(
-		c.datastore,
-		params.ContextualTuples.GetTupleKeys(),
-		&storagewrappers.Operation{
-			Method:            apimethod.Check,
-			Concurrency:       c.maxConcurrentReads,
-			ThrottlingEnabled: c.datastoreThrottlingEnabled,
-			ThrottleThreshold: c.datastoreThrottleThreshold,
-			ThrottleDuration:  c.datastoreThrottleDuration,
-		},
-		storagewrappers.DataResourceConfiguration{
-			Resources:      c.sharedCheckResources,
-			CacheSettings:  c.cacheSettings,
-			UseShadowCache: false,
-		},
-	)
+	datastoreWithTupleCache := c.requestDatastore(params.ContextualTuples.GetTupleKeys())
 
 	ctx = typesystem.ContextWithTypesystem(ctx, c.typesys)
 	ctx = storage.ContextWithRelationshipTupleReader(ctx, datastoreWithTupleCache)
@@ -166,6 +151,7 @@ func (c *CheckQuery) Execute(ctx context.Context, params *CheckCommandParams) (*
 	dsMeta := datastoreWithTupleCache.GetMetadata()
 	resp.ResolutionMetadata.DatastoreQueryCount = dsMeta.DatastoreQueryCount
 	resp.ResolutionMetadata.DatastoreItemCount = dsMeta.DatastoreItemCount
+	resp.ResolutionMetadata.DatastoreDuration = dsMeta.DatastoreDuration
 
 	resolveCheckRequest.GetRequestMetadata().DatastoreThrottled.Store(dsMeta.WasThrottled)
 
@@ -182,6 +168,46 @@ func (c *CheckQuery) Execute(ctx context.Context, params *CheckCommandParams) (*
 	return resp, resolveCheckRequest.GetRequestMetadata(), nil
 }
 
+// ExecuteDispatched resolves a Check sub-problem dispatched by another replica of the cluster.
+// Unlike Execute, it does not validate the request, which was validated by the replica that
+// received the Check.
+func (c *CheckQuery) ExecuteDispatched(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+	datastoreWithTupleCache := c.requestDatastore(req.GetContextualTuples())
+
+	ctx = typesystem.ContextWithTypesystem(ctx, c.typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, datastoreWithTupleCache)
+
+	resp, err := c.checkResolver.ResolveCheck(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	dsMeta := datastoreWithTupleCache.GetMetadata()
+	resp.ResolutionMetadata.DatastoreQueryCount = dsMeta.DatastoreQueryCount
+	resp.ResolutionMetadata.DatastoreItemCount = dsMeta.DatastoreItemCount
+	return resp, nil
+}
+
+// requestDatastore returns the datastore of a Check with the contextual tuples.
+func (c *CheckQuery) requestDatastore(contextualTuples []*openfgav1.TupleKey) *storagewrappers.RequestStorageWrapper {
+	return storagewrappers.NewRequestStorageWrapperWithCache(
+		c.datastore,
+		contextualTuples,
+		&storagewrappers.Operation{
+			Method:            apimethod.Check,
+			Concurrency:       c.maxConcurrentReads,
+			ThrottlingEnabled: c.datastoreThrottlingEnabled,
+			ThrottleThreshold: c.datastoreThrottleThreshold,
+			ThrottleDuration:  c.datastoreThrottleDuration,
+		},
+		storagewrappers.DataResourceConfiguration{
+			Resources:      c.sharedCheckResources,
+			CacheSettings:  c.cacheSettings,
+			UseShadowCache: false,
+		},
+	)
+}
+
 func validateCheckRequest(
 	typesys *typesystem.TypeSystem,
 	tupleKey *openfgav1.CheckRequestTupleKey,
@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/checkutil"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ErrCheckReasonDepthExceeded is returned by CheckReasonQuery when the path that grants the
+// relation is deeper than the resolve node limit.
+var ErrCheckReasonDepthExceeded = errors.New("the path of the check reason exceeds the resolve node limit")
+
+// CheckReasonQuery finds the path of tuples that grants a user a relation on an object, to answer
+// why a Check is allowed. For instance, the path of user:anne, editor, document:1 can be that
+// user:anne is a member of group:eng, whose members are editors of document:1.
+//
+// The query follows the rewrites of the model like Check does, and returns the first path that it
+// finds. The subtracted relations of the exclusions are not part of the paths, since they are
+// not satisfied for the Checks that are allowed.
+type CheckReasonQuery struct {
+	datastore        storage.RelationshipTupleReader
+	typesys          *typesystem.TypeSystem
+	resolveNodeLimit uint32
+}
+
+type CheckReasonQueryOption func(*CheckReasonQuery)
+
+// WithCheckReasonResolveNodeLimit bounds the depth of the paths that the query follows.
+func WithCheckReasonResolveNodeLimit(limit uint32) CheckReasonQueryOption {
+	return func(q *CheckReasonQuery) {
+		q.resolveNodeLimit = limit
+	}
+}
+
+// NewCheckReasonQuery returns a CheckReasonQuery that reads the tuples of the model of typesys
+// from datastore.
+func NewCheckReasonQuery(datastore storage.RelationshipTupleReader, typesys *typesystem.TypeSystem, opts ...CheckReasonQueryOption) *CheckReasonQuery {
+	q := &CheckReasonQuery{
+		datastore:        datastore,
+		typesys:          typesys,
+		resolveNodeLimit: serverconfig.DefaultResolveNodeLimit,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// CheckReasonParams are the parameters of a CheckReasonQuery, those of the Check to explain.
+type CheckReasonParams struct {
+	StoreID          string
+	TupleKey         *openfgav1.CheckRequestTupleKey
+	ContextualTuples *openfgav1.ContextualTupleKeys
+	Context          *structpb.Struct
+	Consistency      openfgav1.ConsistencyPreference
+}
+
+// checkReasonResolution holds the state of the resolution of a CheckReasonQuery.
+type checkReasonResolution struct {
+	*CheckReasonQuery
+	params   *CheckReasonParams
+	reader   storage.RelationshipTupleReader
+	visiting map[string]struct{}
+}
+
+// Execute returns the path of tuples that grants the relation, ordered from the user to the
+// object, and false if there is none. The path is empty when the user is the object and relation
+// themselves, e.g. group:eng#member is a member of group:eng.
+func (q *CheckReasonQuery) Execute(ctx context.Context, params *CheckReasonParams) ([]*openfgav1.TupleKey, bool, error) {
+	r := &checkReasonResolution{
+		CheckReasonQuery: q,
+		params:           params,
+		reader:           storagewrappers.NewCombinedTupleReader(q.datastore, params.ContextualTuples.GetTupleKeys()),
+		visiting:         map[string]struct{}{},
+	}
+	tk := params.TupleKey
+	return r.resolve(ctx, tk.GetObject(), tk.GetRelation(), tk.GetUser(), 0)
+}
+
+func (r *checkReasonResolution) resolve(ctx context.Context, object, relation, user string, depth uint32) ([]*openfgav1.TupleKey, bool, error) {
+	if tuple.ToObjectRelationString(object, relation) == user {
+		return []*openfgav1.TupleKey{}, true, nil
+	}
+	if depth >= r.resolveNodeLimit {
+		return nil, false, ErrCheckReasonDepthExceeded
+	}
+
+	// the paths that go through a tuple cycle are not shorter than the paths that don't
+	key := tuple.ToObjectRelationString(object, relation) + "@" + user
+	if _, ok := r.visiting[key]; ok {
+		return nil, false, nil
+	}
+	r.visiting[key] = struct{}{}
+	defer delete(r.visiting, key)
+
+	rel, err := r.typesys.GetRelation(tuple.GetType(object), relation)
+	if err != nil {
+		return nil, false, err
+	}
+	return r.resolveRewrite(ctx, object, relation, rel.GetRewrite(), user, depth)
+}
+
+func (r *checkReasonResolution) resolveRewrite(ctx context.Context, object, relation string, rewrite *openfgav1.Userset, user string, depth uint32) ([]*openfgav1.TupleKey, bool, error) {
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		return r.resolveDirect(ctx, object, relation, user, depth)
+	case *openfgav1.Userset_ComputedUserset:
+		return r.resolve(ctx, object, rw.ComputedUserset.GetRelation(), user, depth+1)
+	case *openfgav1.Userset_TupleToUserset:
+		return r.resolveTupleToUserset(ctx, object, rw.TupleToUserset, user, depth)
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			path, ok, err := r.resolveRewrite(ctx, object, relation, child, user, depth)
+			if err != nil || ok {
+				return path, ok, err
+			}
+		}
+		return nil, false, nil
+	case *openfgav1.Userset_Intersection:
+		var path []*openfgav1.TupleKey
+		for _, child := range rw.Intersection.GetChild() {
+			childPath, ok, err := r.resolveRewrite(ctx, object, relation, child, user, depth)
+			if err != nil || !ok {
+				return nil, false, err
+			}
+			path = append(path, childPath...)
+		}
+		return path, true, nil
+	case *openfgav1.Userset_Difference:
+		return r.resolveRewrite(ctx, object, relation, rw.Difference.GetBase(), user, depth)
+	default:
+		return nil, false, fmt.Errorf("unsupported rewrite %T", rw)
+	}
+}
+
+// resolveDirect finds a tuple of the relation with the user, the public wildcard of its type, or
+// a userset that grants it the relation.
+func (r *checkReasonResolution) resolveDirect(ctx context.Context, object, relation, user string, depth uint32) ([]*openfgav1.TupleKey, bool, error) {
+	users := []string{user}
+	// the usersets and the wildcards are not granted the relation by the public wildcards
+	if !tuple.IsObjectRelation(user) && !tuple.IsTypedWildcard(user) {
+		users = append(users, tuple.TypedPublicWildcard(tuple.GetType(user)))
+	}
+
+	for _, u := range users {
+		t, err := r.reader.ReadUserTuple(ctx, r.params.StoreID, storage.ReadUserTupleFilter{
+			Object:   object,
+			Relation: relation,
+			User:     u,
+		}, storage.ReadUserTupleOptions{
+			Consistency: storage.ConsistencyOptions{Preference: r.params.Consistency},
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, false, err
+		}
+		ok, err := r.conditionMet(ctx, t.GetKey())
+		if err != nil || ok {
+			return []*openfgav1.TupleKey{t.GetKey()}, ok, err
+		}
+	}
+
+	iter, err := r.reader.ReadUsersetTuples(ctx, r.params.StoreID, storage.ReadUsersetTuplesFilter{
+		Object:   object,
+		Relation: relation,
+	}, storage.ReadUsersetTuplesOptions{
+		Consistency: storage.ConsistencyOptions{Preference: r.params.Consistency},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	defer iter.Stop()
+
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+
+		tk := t.GetKey()
+		if !tuple.IsObjectRelation(tk.GetUser()) {
+			continue
+		}
+		ok, err := r.conditionMet(ctx, tk)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+
+		usersetObject, usersetRelation := tuple.SplitObjectRelation(tk.GetUser())
+		path, ok, err := r.resolve(ctx, usersetObject, usersetRelation, user, depth+1)
+		if err != nil || ok {
+			return append(path, tk), ok, err
+		}
+	}
+}
+
+// resolveTupleToUserset finds a tuple of the tupleset relation whose object grants the user the
+// computed relation.
+func (r *checkReasonResolution) resolveTupleToUserset(ctx context.Context, object string, ttu *openfgav1.TupleToUserset, user string, depth uint32) ([]*openfgav1.TupleKey, bool, error) {
+	iter, err := r.reader.Read(ctx, r.params.StoreID, storage.ReadFilter{
+		Object:   object,
+		Relation: ttu.GetTupleset().GetRelation(),
+	}, storage.ReadOptions{
+		Consistency: storage.ConsistencyOptions{Preference: r.params.Consistency},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	defer iter.Stop()
+
+	computedRelation := ttu.GetComputedUserset().GetRelation()
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+
+		tk := t.GetKey()
+		tuplesetObject := tk.GetUser()
+		if tuple.IsObjectRelation(tuplesetObject) || tuple.IsTypedWildcard(tuplesetObject) {
+			continue
+		}
+		// the computed relation is not defined on all the types of the tupleset
+		if _, err := r.typesys.GetRelation(tuple.GetType(tuplesetObject), computedRelation); err != nil {
+			continue
+		}
+
+		ok, err := r.conditionMet(ctx, tk)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+
+		path, ok, err := r.resolve(ctx, tuplesetObject, computedRelation, user, depth+1)
+		if err != nil || ok {
+			return append(path, tk), ok, err
+		}
+	}
+}
+
+func (r *checkReasonResolution) conditionMet(ctx context.Context, tk *openfgav1.TupleKey) (bool, error) {
+	return checkutil.BuildTupleKeyConditionFilter(ctx, r.params.Context, r.typesys)(tk)
+}
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestCheckReasonQuery(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define allowed: [user]
+				define blocked: [user]
+				define editor: [user, user:*, group#member, user with in_network]
+				define viewer: (editor or viewer from parent) but not blocked
+				define can_share: editor and allowed
+		condition in_network(ip: string) {
+			ip == "10.0.0.1"
+		}`)
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	require.NoError(t, err)
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	storeID := ulid.Make().String()
+
+	err = ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		tuple.NewTupleKey("document:1", "allowed", "user:anne"),
+		tuple.NewTupleKey("group:eng", "member", "user:bob"),
+		tuple.NewTupleKey("group:all", "member", "group:eng#member"),
+		tuple.NewTupleKey("document:1", "editor", "group:all#member"),
+		tuple.NewTupleKey("folder:x", "viewer", "user:carl"),
+		tuple.NewTupleKey("document:1", "parent", "folder:x"),
+		tuple.NewTupleKey("document:2", "editor", "user:*"),
+		tuple.NewTupleKeyWithCondition("document:3", "editor", "user:dave", "in_network", nil),
+		// a cycle of groups
+		tuple.NewTupleKey("group:a", "member", "group:b#member"),
+		tuple.NewTupleKey("group:b", "member", "group:a#member"),
+	})
+	require.NoError(t, err)
+
+	query := NewCheckReasonQuery(ds, typesys)
+	explain := func(t *testing.T, params *CheckReasonParams) ([]string, bool) {
+		params.StoreID = storeID
+		path, ok, err := query.Execute(ctx, params)
+		require.NoError(t, err)
+
+		tuples := make([]string, 0, len(path))
+		for _, tk := range path {
+			tuples = append(tuples, tuple.TupleKeyToString(tk))
+		}
+		return tuples, ok
+	}
+
+	tests := map[string]struct {
+		tupleKey *openfgav1.CheckRequestTupleKey
+		expected []string
+	}{
+		"direct": {
+			tupleKey: tuple.NewCheckRequestTupleKey("document:1", "editor", "user:anne"),
+			expected: []string{"document:1#editor@user:anne"},
+		},
+		"nested_groups": {
+			tupleKey: tuple.NewCheckRequestTupleKey("document:1", "editor", "user:bob"),
+			expected: []string{
+				"group:eng#member@user:bob",
+				"group:all#member@group:eng#member",
+				"document:1#editor@group:all#member",
+			},
+		},
+		"computed_userset": {
+			tupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+			expected: []string{"document:1#editor@user:anne"},
+		},
+		"tuple_to_userset": {
+			tupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:carl"),
+			expected: []string{"folder:x#viewer@user:carl", "document:1#parent@folder:x"},
+		},
+		"intersection": {
+			tupleKey: tuple.NewCheckRequestTupleKey("document:1", "can_share", "user:anne"),
+			expected: []string{"document:1#editor@user:anne", "document:1#allowed@user:anne"},
+		},
+		"public_wildcard": {
+			tupleKey: tuple.NewCheckRequestTupleKey("document:2", "editor", "user:erin"),
+			expected: []string{"document:2#editor@user:*"},
+		},
+		"userset_of_itself": {
+			tupleKey: tuple.NewCheckRequestTupleKey("group:eng", "member", "group:eng#member"),
+			expected: []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, ok := explain(t, &CheckReasonParams{TupleKey: test.tupleKey})
+			require.True(t, ok)
+			require.Equal(t, test.expected, path)
+		})
+	}
+
+	t.Run("not_granted", func(t *testing.T) {
+		_, ok := explain(t, &CheckReasonParams{TupleKey: tuple.NewCheckRequestTupleKey("document:1", "can_share", "user:bob")})
+		require.False(t, ok)
+
+		_, ok = explain(t, &CheckReasonParams{TupleKey: tuple.NewCheckRequestTupleKey("group:a", "member", "user:anne")})
+		require.False(t, ok)
+	})
+
+	t.Run("condition", func(t *testing.T) {
+		tk := tuple.NewCheckRequestTupleKey("document:3", "editor", "user:dave")
+
+		inNetwork, err := structpb.NewStruct(map[string]any{"ip": "10.0.0.1"})
+		require.NoError(t, err)
+		path, ok := explain(t, &CheckReasonParams{TupleKey: tk, Context: inNetwork})
+		require.True(t, ok)
+		require.Equal(t, []string{"document:3#editor@user:dave"}, path)
+
+		outOfNetwork, err := structpb.NewStruct(map[string]any{"ip": "10.0.0.2"})
+		require.NoError(t, err)
+		_, ok = explain(t, &CheckReasonParams{TupleKey: tk, Context: outOfNetwork})
+		require.False(t, ok)
+	})
+
+	t.Run("contextual_tuples", func(t *testing.T) {
+		path, ok := explain(t, &CheckReasonParams{
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "editor", "user:frank"),
+			ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("group:eng", "member", "user:frank"),
+			}},
+		})
+		require.True(t, ok)
+		require.Equal(t, []string{
+			"group:eng#member@user:frank",
+			"group:all#member@group:eng#member",
+			"document:1#editor@group:all#member",
+		}, path)
+	})
+
+	t.Run("resolve_node_limit", func(t *testing.T) {
+		query := NewCheckReasonQuery(ds, typesys, WithCheckReasonResolveNodeLimit(2))
+		_, _, err := query.Execute(ctx, &CheckReasonParams{
+			StoreID:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "editor", "user:bob"),
+		})
+		require.ErrorIs(t, err, ErrCheckReasonDepthExceeded)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -37,7 +38,9 @@ import (
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
-const streamedBufferSize = 100
+// defaultStreamedMaxInFlight is the default number of resolved objects that can be buffered
+// before they are sent to a StreamedListObjects client.
+const defaultStreamedMaxInFlight = 100
 
 var (
 	furtherEvalRequiredCounter = promauto.NewCounter(prometheus.CounterOpts{
@@ -78,6 +81,13 @@ type ListObjectsQuery struct {
 
 	pipelineEnabled bool // Indicates whether to run with the pipeline optimized code
 	pipelineConfig  pipeline.Config
+
+	sqlPushdownEnabled bool // Indicates whether eligible relations are evaluated by a single datastore query
+
+	streamedMaxInFlight       uint32
+	streamedMessagesPerSecond uint32
+
+	objectIDPrefix string // Only the objects whose ID starts with it are returned
 }
 
 type ListObjectsResolver interface {
@@ -127,6 +137,22 @@ func WithListObjectsDeadline(deadline time.Duration) ListObjectsQueryOption {
 	}
 }
 
+// WithStreamedListObjectsMaxInFlight see server.WithStreamedListObjectsMaxInFlightMessages.
+func WithStreamedListObjectsMaxInFlight(n uint32) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		if n > 0 {
+			d.streamedMaxInFlight = n
+		}
+	}
+}
+
+// WithStreamedListObjectsMessagesPerSecond see server.WithStreamedListObjectsMessagesPerSecond.
+func WithStreamedListObjectsMessagesPerSecond(n uint32) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.streamedMessagesPerSecond = n
+	}
+}
+
 func WithDispatchThrottlerConfig(config threshold.Config) ListObjectsQueryOption {
 	return func(d *ListObjectsQuery) {
 		d.dispatchThrottlerConfig = config
@@ -222,6 +248,15 @@ func WithListObjectsNumProcs(value int) ListObjectsQueryOption {
 	}
 }
 
+// WithListObjectsObjectIDPrefix returns only the objects whose ID starts with prefix, e.g. the
+// documents under a folder path encoded in their IDs. The objects that don't match are dropped
+// before they count towards the maximum number of results. The empty prefix matches all objects.
+func WithListObjectsObjectIDPrefix(prefix string) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.objectIDPrefix = prefix
+	}
+}
+
 func NewListObjectsQuery(
 	ds storage.RelationshipTupleReader,
 	checkResolver graph.CheckResolver,
@@ -260,6 +295,7 @@ func NewListObjectsQuery(
 		useShadowCache:       false,
 		ff:                   featureflags.NewDefaultClient([]string{serverconfig.ExperimentalPipelineListObjects}),
 		pipelineConfig:       pipeline.DefaultConfig(),
+		streamedMaxInFlight:  defaultStreamedMaxInFlight,
 	}
 
 	for _, opt := range opts {
@@ -274,6 +310,10 @@ func NewListObjectsQuery(
 		query.pipelineEnabled = false
 	}
 
+	if query.ff.Boolean(serverconfig.ExperimentalListObjectsSQLPushdown, storeID) {
+		query.sqlPushdownEnabled = true
+	}
+
 	return query, nil
 }
 
@@ -432,6 +472,10 @@ func (q *ListObjectsQuery) evaluate(
 					break ConsumerReadLoop
 				}
 
+				if !q.matchesObjectIDPrefix(res.Object) {
+					continue
+				}
+
 				if res.ResultStatus == reverseexpand.NoFurtherEvalStatus {
 					noFurtherEvalRequiredCounter.Inc()
 					trySendObject(ctx, res.Object, &objectsFound, maxResults, resultsChan)
@@ -502,6 +546,15 @@ func trySendObject(ctx context.Context, object string, objectsFound *atomic.Uint
 	concurrency.TrySendThroughChannel(ctx, ListObjectsResult{ObjectID: object}, resultsChan)
 }
 
+// matchesObjectIDPrefix reports whether the ID of object starts with the objectIDPrefix of q.
+func (q *ListObjectsQuery) matchesObjectIDPrefix(object string) bool {
+	if q.objectIDPrefix == "" {
+		return true
+	}
+	_, objectID := tuple.SplitObject(object)
+	return strings.HasPrefix(objectID, q.objectIDPrefix)
+}
+
 // Execute the ListObjectsQuery, returning a list of object IDs up to a maximum of q.listObjectsMaxResults
 // or until q.listObjectsDeadline is hit, whichever happens first.
 func (q *ListObjectsQuery) Execute(
@@ -564,6 +617,16 @@ func (q *ListObjectsQuery) Execute(
 		}
 	}
 
+	var pushdownResponse ListObjectsResponse
+	objects, ok, err := q.evaluateWithSetOperation(timeoutCtx, typesys, req, maxResults, &pushdownResponse.ResolutionMetadata)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+	if ok {
+		pushdownResponse.Objects = objects
+		return &pushdownResponse, nil
+	}
+
 	wgraph := typesys.GetWeightedGraph()
 
 	if wgraph != nil && subjectRelation == "" && subjectIdentifier != "*" && q.pipelineEnabled {
@@ -624,6 +687,10 @@ func (q *ListObjectsQuery) Execute(
 				break
 			}
 
+			if !q.matchesObjectIDPrefix(value) {
+				continue
+			}
+
 			res.Objects = append(res.Objects, value)
 
 			// Check if we've reached the max results limit
@@ -703,6 +770,10 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 
 	var resolutionMetadata ListObjectsResolutionMetadata
 
+	if q.streamedMessagesPerSecond > 0 {
+		srv = newRateLimitedListObjectsStream(srv, q.streamedMessagesPerSecond)
+	}
+
 	targetObjectType := req.GetType()
 	targetRelation := req.GetRelation()
 
@@ -740,6 +811,19 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 		return nil, serverErrors.ValidationError(fmt.Errorf("invalid 'user' value: %w", err))
 	}
 
+	objects, ok, err := q.evaluateWithSetOperation(timeoutCtx, typesys, req, 0, &resolutionMetadata)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+	if ok {
+		for _, object := range objects {
+			if err := srv.Send(&openfgav1.StreamedListObjectsResponse{Object: object}); err != nil {
+				return nil, serverErrors.HandleError("", err)
+			}
+		}
+		return &resolutionMetadata, nil
+	}
+
 	wgraph := typesys.GetWeightedGraph()
 
 	if wgraph != nil && subjectRelation == "" && subjectIdentifier != "*" && q.pipelineEnabled {
@@ -803,6 +887,10 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 				break
 			}
 
+			if !q.matchesObjectIDPrefix(value) {
+				continue
+			}
+
 			if errRx = srv.Send(&openfgav1.StreamedListObjectsResponse{Object: value}); errRx != nil {
 				break
 			}
@@ -835,7 +923,7 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 	// --------- OLD ALGORITHM FALLBACK -----------
 
 	// make a buffered channel so that writer goroutines aren't blocked when attempting to send a result
-	resultsChan := make(chan ListObjectsResult, streamedBufferSize)
+	resultsChan := make(chan ListObjectsResult, q.streamedMaxInFlight)
 
 	err = q.evaluate(timeoutCtx, req, resultsChan, maxResults, &resolutionMetadata)
 	if err != nil {
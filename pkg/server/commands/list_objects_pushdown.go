@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// setOperationFilterFor returns the filter that a [storage.SetOperationReader] can evaluate to
+// answer a ListObjects request for the given object type, relation and user, and true if the
+// relation is eligible. A relation is eligible when it is a union of direct relations, optionally
+// minus another union of direct relations, none of which allows usersets or conditions. Only
+// relations that need a set operation are eligible; a single direct relation is already served
+// by a single query.
+func setOperationFilterFor(typesys *typesystem.TypeSystem, objectType, relation, user string) (storage.SetOperationFilter, bool) {
+	userType, userID, userRelation := tuple.ToUserParts(user)
+	if userRelation != "" || userID == tuple.Wildcard {
+		return storage.SetOperationFilter{}, false
+	}
+
+	rel, err := typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return storage.SetOperationFilter{}, false
+	}
+
+	c := &setOperationCollector{
+		typesys:  typesys,
+		object:   objectType,
+		userType: userType,
+		user:     user,
+	}
+
+	filter := storage.SetOperationFilter{ObjectType: objectType}
+	rewrite := rel.GetRewrite()
+	if difference := rewrite.GetDifference(); difference != nil {
+		if filter.Union, err = c.collect(relation, difference.GetBase(), map[string]struct{}{}); err != nil {
+			return storage.SetOperationFilter{}, false
+		}
+		if filter.Except, err = c.collect(relation, difference.GetSubtract(), map[string]struct{}{}); err != nil {
+			return storage.SetOperationFilter{}, false
+		}
+	} else if filter.Union, err = c.collect(relation, rewrite, map[string]struct{}{}); err != nil {
+		return storage.SetOperationFilter{}, false
+	}
+
+	if len(filter.Union) == 0 || (len(filter.Union) == 1 && len(filter.Except) == 0) {
+		return storage.SetOperationFilter{}, false
+	}
+	return filter, true
+}
+
+var errSetOperationIneligible = errors.New("relation cannot be evaluated as a set operation")
+
+type setOperationCollector struct {
+	typesys  *typesystem.TypeSystem
+	object   string
+	userType string
+	user     string
+}
+
+// collect returns the terms whose union is the set of objects related through the rewrite of
+// relation, or errSetOperationIneligible.
+func (c *setOperationCollector) collect(relation string, rewrite *openfgav1.Userset, visited map[string]struct{}) ([]storage.SetOperationTerm, error) {
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		directTypes, err := c.typesys.GetDirectlyRelatedUserTypes(c.object, relation)
+		if err != nil {
+			return nil, err
+		}
+		var users []string
+		for _, ref := range directTypes {
+			if ref.GetCondition() != "" || ref.GetRelation() != "" {
+				return nil, errSetOperationIneligible
+			}
+			if ref.GetType() != c.userType {
+				continue
+			}
+			if ref.GetWildcard() != nil {
+				users = append(users, tuple.TypedPublicWildcard(c.userType))
+			} else {
+				users = append(users, c.user)
+			}
+		}
+		if len(users) == 0 {
+			return nil, nil
+		}
+		return []storage.SetOperationTerm{{Relation: relation, Users: users}}, nil
+	case *openfgav1.Userset_ComputedUserset:
+		computed := rw.ComputedUserset.GetRelation()
+		if _, ok := visited[computed]; ok {
+			return nil, nil
+		}
+		visited[computed] = struct{}{}
+		rel, err := c.typesys.GetRelation(c.object, computed)
+		if err != nil {
+			return nil, err
+		}
+		return c.collect(computed, rel.GetRewrite(), visited)
+	case *openfgav1.Userset_Union:
+		var terms []storage.SetOperationTerm
+		for _, child := range rw.Union.GetChild() {
+			childTerms, err := c.collect(relation, child, visited)
+			if err != nil {
+				return nil, err
+			}
+			terms = append(terms, childTerms...)
+		}
+		return terms, nil
+	default:
+		return nil, errSetOperationIneligible
+	}
+}
+
+// evaluateWithSetOperation evaluates the request with a single datastore query when SQL pushdown
+// is enabled, the datastore supports it and the relation is eligible. The boolean result is false
+// when the request must be evaluated by the regular algorithm.
+func (q *ListObjectsQuery) evaluateWithSetOperation(
+	ctx context.Context,
+	typesys *typesystem.TypeSystem,
+	req listObjectsRequest,
+	limit uint32,
+	resolutionMetadata *ListObjectsResolutionMetadata,
+) ([]string, bool, error) {
+	if !q.sqlPushdownEnabled || len(req.GetContextualTuples().GetTupleKeys()) > 0 {
+		return nil, false, nil
+	}
+
+	reader, ok := q.datastore.(storage.SetOperationReader)
+	if !ok {
+		return nil, false, nil
+	}
+
+	filter, ok := setOperationFilterFor(typesys, req.GetType(), req.GetRelation(), req.GetUser())
+	if !ok {
+		return nil, false, nil
+	}
+
+	readLimit := limit
+	if q.objectIDPrefix != "" {
+		// the limit applies to the object IDs that match the prefix
+		readLimit = 0
+	}
+
+	objectIDs, err := reader.ReadObjectIDsWithSetOperation(ctx, req.GetStoreId(), filter, storage.ReadSetOperationOptions{
+		Consistency: storage.ConsistencyOptions{Preference: req.GetConsistency()},
+		Limit:       readLimit,
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrUnsupported) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	resolutionMetadata.DatastoreQueryCount.Add(1)
+	resolutionMetadata.DatastoreItemCount.Add(uint64(len(objectIDs)))
+
+	objects := make([]string, 0, len(objectIDs))
+	for _, id := range objectIDs {
+		if !strings.HasPrefix(id, q.objectIDPrefix) {
+			continue
+		}
+		if limit > 0 && uint32(len(objects)) >= limit {
+			break
+		}
+		objects = append(objects, tuple.BuildObject(req.GetType(), id))
+	}
+	return objects, true, nil
+}
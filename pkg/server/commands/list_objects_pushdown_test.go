@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/featureflags"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
+	storagefixtures "github.com/openfga/openfga/pkg/testfixtures/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const pushdownTestModel = `
+	model
+		schema 1.1
+	type user
+	type group
+		relations
+			define member: [user]
+	type document
+		relations
+			define owner: [user]
+			define editor: [user, user:*]
+			define blocked: [user]
+			define viewer: owner or editor
+			define allowed: viewer but not blocked
+			define group_viewer: [user, group#member]
+			define conditional_viewer: [user with cond] or owner
+			define parent: [group]
+			define parent_viewer: viewer or member from parent
+			define owner_alias: owner
+
+	condition cond(x: int) {
+		x < 100
+	}`
+
+func TestSetOperationFilterFor(t *testing.T) {
+	typesys, err := typesystem.New(parser.MustTransformDSLToProto(pushdownTestModel))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		relation string
+		user     string
+		expected *storage.SetOperationFilter
+	}{
+		{
+			name:     "union_of_direct_relations",
+			relation: "viewer",
+			user:     "user:anne",
+			expected: &storage.SetOperationFilter{
+				ObjectType: "document",
+				Union: []storage.SetOperationTerm{
+					{Relation: "owner", Users: []string{"user:anne"}},
+					{Relation: "editor", Users: []string{"user:anne", "user:*"}},
+				},
+			},
+		},
+		{
+			name:     "difference",
+			relation: "allowed",
+			user:     "user:anne",
+			expected: &storage.SetOperationFilter{
+				ObjectType: "document",
+				Union: []storage.SetOperationTerm{
+					{Relation: "owner", Users: []string{"user:anne"}},
+					{Relation: "editor", Users: []string{"user:anne", "user:*"}},
+				},
+				Except: []storage.SetOperationTerm{
+					{Relation: "blocked", Users: []string{"user:anne"}},
+				},
+			},
+		},
+		{
+			name:     "single_direct_relation",
+			relation: "owner_alias",
+			user:     "user:anne",
+		},
+		{
+			name:     "userset_type_restriction",
+			relation: "group_viewer",
+			user:     "user:anne",
+		},
+		{
+			name:     "conditional_type_restriction",
+			relation: "conditional_viewer",
+			user:     "user:anne",
+		},
+		{
+			name:     "tuple_to_userset",
+			relation: "parent_viewer",
+			user:     "user:anne",
+		},
+		{
+			name:     "userset_user",
+			relation: "viewer",
+			user:     "group:eng#member",
+		},
+		{
+			name:     "wildcard_user",
+			relation: "viewer",
+			user:     "user:*",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, ok := setOperationFilterFor(typesys, "document", test.relation, test.user)
+			if test.expected == nil {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, *test.expected, filter)
+		})
+	}
+}
+
+func TestListObjectsWithSQLPushdown(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "sqlite")
+	ds, err := sqlite.New(testDatastore.GetConnectionURI(true), sqlcommon.NewConfig())
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	model := parser.MustTransformDSLToProto(pushdownTestModel)
+	typesys, err := typesystem.New(model)
+	require.NoError(t, err)
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+	err = ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "owner", "user:anne"),
+		tuple.NewTupleKey("document:10", "owner", "user:anne"),
+		tuple.NewTupleKey("document:2", "editor", "user:anne"),
+		tuple.NewTupleKey("document:3", "editor", "user:*"),
+		tuple.NewTupleKey("document:4", "editor", "user:bob"),
+		tuple.NewTupleKey("document:2", "blocked", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	for _, relation := range []string{"viewer", "allowed"} {
+		t.Run(relation, func(t *testing.T) {
+			req := &openfgav1.ListObjectsRequest{
+				StoreId:  storeID,
+				Type:     "document",
+				Relation: relation,
+				User:     "user:anne",
+			}
+
+			baseline, err := NewListObjectsQuery(ds, checkResolver, storeID)
+			require.NoError(t, err)
+			expected, err := baseline.Execute(ctx, req)
+			require.NoError(t, err)
+
+			pushdown, err := NewListObjectsQuery(ds, checkResolver, storeID,
+				WithFeatureFlagClient(featureflags.NewDefaultClient([]string{serverconfig.ExperimentalListObjectsSQLPushdown})),
+			)
+			require.NoError(t, err)
+			actual, err := pushdown.Execute(ctx, req)
+			require.NoError(t, err)
+
+			require.ElementsMatch(t, expected.Objects, actual.Objects)
+			require.Equal(t, uint32(1), actual.ResolutionMetadata.DatastoreQueryCount.Load())
+		})
+	}
+
+	t.Run("object_id_prefix", func(t *testing.T) {
+		req := &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:anne",
+		}
+
+		pushdown, err := NewListObjectsQuery(ds, checkResolver, storeID,
+			WithFeatureFlagClient(featureflags.NewDefaultClient([]string{serverconfig.ExperimentalListObjectsSQLPushdown})),
+			WithListObjectsObjectIDPrefix("1"),
+		)
+		require.NoError(t, err)
+		actual, err := pushdown.Execute(ctx, req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"document:1", "document:10"}, actual.Objects)
+
+		pushdown, err = NewListObjectsQuery(ds, checkResolver, storeID,
+			WithFeatureFlagClient(featureflags.NewDefaultClient([]string{serverconfig.ExperimentalListObjectsSQLPushdown})),
+			WithListObjectsObjectIDPrefix("1"),
+			WithListObjectsMaxResults(1),
+		)
+		require.NoError(t, err)
+		actual, err = pushdown.Execute(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, actual.Objects, 1)
+		require.Contains(t, []string{"document:1", "document:10"}, actual.Objects[0])
+	})
+}
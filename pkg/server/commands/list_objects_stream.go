@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"golang.org/x/time/rate"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// rateLimitedListObjectsStream limits the rate at which objects are sent to a StreamedListObjects
+// client. While a send waits for the limiter, resolution stops once the in-flight buffer is full,
+// so a slow stream cannot cause unbounded buffering of resolved objects.
+type rateLimitedListObjectsStream struct {
+	openfgav1.OpenFGAService_StreamedListObjectsServer
+	limiter *rate.Limiter
+}
+
+func newRateLimitedListObjectsStream(srv openfgav1.OpenFGAService_StreamedListObjectsServer, messagesPerSecond uint32) openfgav1.OpenFGAService_StreamedListObjectsServer {
+	return &rateLimitedListObjectsStream{
+		OpenFGAService_StreamedListObjectsServer: srv,
+		limiter:                                  rate.NewLimiter(rate.Limit(messagesPerSecond), int(messagesPerSecond)),
+	}
+}
+
+func (s *rateLimitedListObjectsStream) Send(resp *openfgav1.StreamedListObjectsResponse) error {
+	if err := s.limiter.Wait(s.Context()); err != nil {
+		return err
+	}
+	return s.OpenFGAService_StreamedListObjectsServer.Send(resp)
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+type recordingListObjectsStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []string
+}
+
+func (s *recordingListObjectsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordingListObjectsStream) Send(resp *openfgav1.StreamedListObjectsResponse) error {
+	s.sent = append(s.sent, resp.GetObject())
+	return nil
+}
+
+func TestRateLimitedListObjectsStream(t *testing.T) {
+	t.Run("limits_send_rate", func(t *testing.T) {
+		srv := &recordingListObjectsStream{ctx: context.Background()}
+		stream := newRateLimitedListObjectsStream(srv, 20)
+
+		start := time.Now()
+		for i := 0; i < 25; i++ {
+			require.NoError(t, stream.Send(&openfgav1.StreamedListObjectsResponse{Object: "document:1"}))
+		}
+
+		// The first 20 objects are sent immediately, the remaining 5 at 20 per second.
+		require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		require.Len(t, srv.sent, 25)
+	})
+
+	t.Run("stops_when_stream_is_done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		srv := &recordingListObjectsStream{ctx: ctx}
+		stream := newRateLimitedListObjectsStream(srv, 1)
+
+		require.NoError(t, stream.Send(&openfgav1.StreamedListObjectsResponse{Object: "document:1"}))
+		cancel()
+		require.Error(t, stream.Send(&openfgav1.StreamedListObjectsResponse{Object: "document:2"}))
+		require.Len(t, srv.sent, 1)
+	})
+}
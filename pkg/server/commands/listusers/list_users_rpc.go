@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -52,6 +53,8 @@ type listUsersQuery struct {
 	datastoreThrottlingEnabled bool
 	datastoreThrottleThreshold int
 	datastoreThrottleDuration  time.Duration
+	wildcardHandling           WildcardHandling
+	userIDPrefix               string
 }
 
 type expandResponse struct {
@@ -110,6 +113,16 @@ func WithListUsersDeadline(t time.Duration) ListUsersQueryOption {
 	}
 }
 
+// WithListUsersUserIDPrefix returns only the users whose ID starts with prefix. Wildcards (e.g.
+// user:*) are still returned, since they stand for the matching users too. The users that don't
+// match are dropped before they count towards the maximum number of results. The empty prefix
+// matches all users.
+func WithListUsersUserIDPrefix(prefix string) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.userIDPrefix = prefix
+	}
+}
+
 // WithResolveNodeLimit see server.WithResolveNodeLimit.
 func WithResolveNodeLimit(limit uint32) ListUsersQueryOption {
 	return func(d *listUsersQuery) {
@@ -177,6 +190,7 @@ func NewListUsersQuery(ds storage.RelationshipTupleReader, contextualTuples []*o
 		wasDispatchThrottled:    new(atomic.Bool),
 		wasDatastoreThrottled:   new(atomic.Bool),
 		expandDirectDispatch:    expandDirectDispatch,
+		wildcardHandling:        WildcardHandlingInclude,
 	}
 
 	for _, opt := range opts {
@@ -247,6 +261,10 @@ func (l *listUsersQuery) ListUsers(
 	doneWithFoundUsersCh := make(chan struct{}, 1)
 	go func() {
 		for foundUser := range foundUsersCh {
+			if !l.matchesUserIDPrefix(foundUser.user) {
+				continue
+			}
+
 			foundUsersUnique[tuple.UserProtoToString(foundUser.user)] = foundUser
 
 			if l.maxResults > 0 {
@@ -295,15 +313,32 @@ func (l *listUsersQuery) ListUsers(
 
 	cancelCtx()
 
+	wildcardKey := tuple.TypedPublicWildcard(userFilter.GetType())
+
 	foundUsers := make([]*openfgav1.User, 0, len(foundUsersUnique))
 	for foundUserKey, foundUser := range foundUsersUnique {
 		if foundUser.relationshipStatus == NoRelationship {
 			continue
 		}
 
+		if foundUserKey == wildcardKey && l.wildcardHandling != WildcardHandlingInclude {
+			continue
+		}
+
 		foundUsers = append(foundUsers, tuple.StringToUserProto(foundUserKey))
 	}
 
+	if wildcard, ok := foundUsersUnique[wildcardKey]; ok && wildcard.relationshipStatus == HasRelationship &&
+		l.wildcardHandling == WildcardHandlingExpand {
+		expanded, err := l.expandWildcardResult(ctx, req, wildcard, foundUsersUnique, len(foundUsers))
+		if err != nil {
+			telemetry.TraceError(span, err)
+			return nil, err
+		}
+		span.SetAttributes(attribute.Int("wildcard_expanded_count", len(expanded)))
+		foundUsers = append(foundUsers, expanded...)
+	}
+
 	span.SetAttributes(attribute.Int("result_count", len(foundUsers)))
 
 	dsMeta := l.datastore.GetMetadata()
@@ -979,6 +1014,22 @@ func (l *listUsersQuery) buildResultsChannel() chan foundUser {
 	return foundUsersCh
 }
 
+// matchesUserIDPrefix reports whether the ID of user starts with the userIDPrefix of l. Wildcards
+// always match.
+func (l *listUsersQuery) matchesUserIDPrefix(user *openfgav1.User) bool {
+	if l.userIDPrefix == "" {
+		return true
+	}
+	switch u := user.GetUser().(type) {
+	case *openfgav1.User_Object:
+		return strings.HasPrefix(u.Object.GetId(), l.userIDPrefix)
+	case *openfgav1.User_Userset:
+		return strings.HasPrefix(u.Userset.GetId(), l.userIDPrefix)
+	default:
+		return true
+	}
+}
+
 func panicError(recovered *panics.Recovered) error {
 	return fmt.Errorf("%w: %w", ErrPanic, recovered.AsError())
 }
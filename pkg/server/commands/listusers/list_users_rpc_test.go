@@ -2120,6 +2120,103 @@ func TestListUsersWildcards(t *testing.T) {
 	tests.runListUsersTestCases(t)
 }
 
+func TestListUsersWildcardHandling(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	model := `
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define blocked: [user]
+				define viewer: [user:*,user] but not blocked`
+
+	withWildcardHandling := func(h WildcardHandling) NewListUsersQueryHandler {
+		return func(ds storage.RelationshipTupleReader, contextualTuples []*openfgav1.TupleKey, opts ...ListUsersQueryOption) *listUsersQuery {
+			return NewListUsersQuery(ds, contextualTuples, append(opts, WithWildcardHandling(h))...)
+		}
+	}
+
+	req := func() *openfgav1.ListUsersRequest {
+		return &openfgav1.ListUsersRequest{
+			Object:   &openfgav1.Object{Type: "document", Id: "1"},
+			Relation: "viewer",
+			UserFilters: []*openfgav1.UserTypeFilter{
+				{
+					Type: "user",
+				},
+			},
+			ContextualTuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:3", "blocked", "user:anne"),
+			},
+		}
+	}
+
+	tuples := []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:*"),
+		tuple.NewTupleKey("document:1", "viewer", "user:will"),
+		tuple.NewTupleKey("document:1", "blocked", "user:maria"),
+		tuple.NewTupleKey("document:2", "viewer", "user:jon"),
+	}
+
+	tests := ListUsersTests{
+		{
+			name:              "include",
+			req:               req(),
+			model:             model,
+			tuples:            tuples,
+			expectedUsers:     []string{"user:*", "user:will"},
+			newListUsersQuery: withWildcardHandling(WildcardHandlingInclude),
+		},
+		{
+			name:              "exclude",
+			req:               req(),
+			model:             model,
+			tuples:            tuples,
+			expectedUsers:     []string{"user:will"},
+			newListUsersQuery: withWildcardHandling(WildcardHandlingExclude),
+		},
+		{
+			name:              "expand",
+			req:               req(),
+			model:             model,
+			tuples:            tuples,
+			expectedUsers:     []string{"user:will", "user:jon", "user:anne"},
+			newListUsersQuery: withWildcardHandling(WildcardHandlingExpand),
+		},
+		{
+			name:  "expand_without_wildcard",
+			req:   req(),
+			model: model,
+			tuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:will"),
+				tuple.NewTupleKey("document:2", "viewer", "user:jon"),
+			},
+			expectedUsers:     []string{"user:will"},
+			newListUsersQuery: withWildcardHandling(WildcardHandlingExpand),
+		},
+	}
+	tests.runListUsersTestCases(t)
+}
+
+func TestParseWildcardHandling(t *testing.T) {
+	h, err := ParseWildcardHandling("")
+	require.NoError(t, err)
+	require.Equal(t, WildcardHandlingInclude, h)
+
+	h, err = ParseWildcardHandling("expand")
+	require.NoError(t, err)
+	require.Equal(t, WildcardHandlingExpand, h)
+
+	_, err = ParseWildcardHandling("all")
+	require.ErrorIs(t, err, ErrInvalidWildcardHandling)
+}
+
 func TestListUsersEdgePruning(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -0,0 +1,145 @@
+package listusers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// WildcardHandling controls how type-bound public access (e.g. user:*) is returned by ListUsers.
+type WildcardHandling string
+
+const (
+	// WildcardHandlingInclude returns public access as a single wildcard entry. This is the default.
+	WildcardHandlingInclude WildcardHandling = "include"
+
+	// WildcardHandlingExpand replaces the wildcard entry with the concrete users of the wildcard
+	// type that appear in the tuples of the store (or in the contextual tuples). Users explicitly
+	// excluded from the relation are not returned.
+	WildcardHandlingExpand WildcardHandling = "expand"
+
+	// WildcardHandlingExclude leaves the wildcard entry out of the results.
+	WildcardHandlingExclude WildcardHandling = "exclude"
+)
+
+// ErrInvalidWildcardHandling is returned when parsing an unknown wildcard handling value.
+var ErrInvalidWildcardHandling = errors.New("invalid wildcard handling")
+
+// ParseWildcardHandling parses a wildcard handling value. The empty string maps to WildcardHandlingInclude.
+func ParseWildcardHandling(s string) (WildcardHandling, error) {
+	switch h := WildcardHandling(s); h {
+	case "":
+		return WildcardHandlingInclude, nil
+	case WildcardHandlingInclude, WildcardHandlingExpand, WildcardHandlingExclude:
+		return h, nil
+	default:
+		return "", fmt.Errorf("%w '%s': must be one of '%s', '%s' or '%s'", ErrInvalidWildcardHandling, s,
+			WildcardHandlingInclude, WildcardHandlingExpand, WildcardHandlingExclude)
+	}
+}
+
+// WithWildcardHandling sets how type-bound public access is returned. See WildcardHandling.
+func WithWildcardHandling(h WildcardHandling) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.wildcardHandling = h
+	}
+}
+
+// expandWildcardResult expands the wildcard found by ListUsers into concrete users, skipping the
+// users that were already found (or explicitly excluded) and honoring the maximum number of results.
+func (l *listUsersQuery) expandWildcardResult(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	wildcard foundUser,
+	foundUsers map[tuple.UserString]foundUser,
+	resultCount int,
+) ([]*openfgav1.User, error) {
+	limit := 0
+	if l.maxResults > 0 {
+		limit = int(l.maxResults) - resultCount
+		if limit <= 0 {
+			return nil, nil
+		}
+	}
+
+	excluded := make(map[tuple.UserString]struct{}, len(wildcard.excludedUsers))
+	for _, u := range wildcard.excludedUsers {
+		excluded[tuple.UserProtoToString(u)] = struct{}{}
+	}
+
+	return l.expandWildcard(ctx, req, tuple.GetType(tuple.UserProtoToString(wildcard.user)), func(u tuple.UserString) bool {
+		_, found := foundUsers[u]
+		_, isExcluded := excluded[u]
+		return found || isExcluded || !l.matchesUserIDPrefix(tuple.StringToUserProto(u))
+	}, limit)
+}
+
+// expandWildcard returns the concrete users of the given type found in the store and in the
+// contextual tuples, either as the user or as the object of a tuple. The users for which skip
+// returns true are left out, and at most limit users are returned if limit is greater than 0.
+func (l *listUsersQuery) expandWildcard(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	userType string,
+	skip func(tuple.UserString) bool,
+	limit int,
+) ([]*openfgav1.User, error) {
+	opts := storage.ReadOptions{
+		Consistency: storage.ConsistencyOptions{
+			Preference: req.GetConsistency(),
+		},
+	}
+	iter, err := l.datastore.Read(ctx, req.GetStoreId(), storage.ReadFilter{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Stop()
+
+	seen := map[tuple.UserString]struct{}{}
+	var users []*openfgav1.User
+
+	add := func(candidate string) {
+		if limit > 0 && len(users) >= limit {
+			return
+		}
+		if tuple.IsObjectRelation(candidate) || tuple.IsTypedWildcard(candidate) {
+			return
+		}
+		objectType, objectID := tuple.SplitObject(candidate)
+		if objectType != userType || objectID == "" {
+			return
+		}
+		if _, ok := seen[candidate]; ok {
+			return
+		}
+		seen[candidate] = struct{}{}
+		if skip(candidate) {
+			return
+		}
+		users = append(users, tuple.StringToUserProto(candidate))
+	}
+
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				break
+			}
+			return nil, err
+		}
+
+		add(t.GetKey().GetUser())
+		add(t.GetKey().GetObject())
+
+		if limit > 0 && len(users) >= limit {
+			break
+		}
+	}
+
+	return users, nil
+}
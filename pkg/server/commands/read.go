@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
@@ -23,6 +24,8 @@ type ReadQuery struct {
 	logger          logger.Logger
 	encoder         encoder.Encoder
 	tokenSerializer encoder.ContinuationTokenSerializer
+	conditions      []string
+	userType        string
 }
 
 type ReadQueryOption func(*ReadQuery)
@@ -45,6 +48,22 @@ func WithReadQueryTokenSerializer(serializer encoder.ContinuationTokenSerializer
 	}
 }
 
+// WithReadQueryConditions returns only the tuples with one of the given condition names.
+func WithReadQueryConditions(conditions []string) ReadQueryOption {
+	return func(rq *ReadQuery) {
+		rq.conditions = conditions
+	}
+}
+
+// WithReadQueryUserType returns only the tuples whose user is of the given type, including
+// usersets and wildcards of that type, e.g. all the group subjects. It cannot be combined with
+// the user of the tuple key.
+func WithReadQueryUserType(userType string) ReadQueryOption {
+	return func(rq *ReadQuery) {
+		rq.userType = userType
+	}
+}
+
 // NewReadQuery creates a ReadQuery using the provided OpenFGA datastore implementation.
 func NewReadQuery(datastore storage.OpenFGADatastore, opts ...ReadQueryOption) *ReadQuery {
 	rq := &ReadQuery{
@@ -61,15 +80,30 @@ func NewReadQuery(datastore storage.OpenFGADatastore, opts ...ReadQueryOption) *
 }
 
 // Execute the ReadQuery, returning paginated `openfga.Tuple`(s) that match the tuple. Return all tuples if the tuple is
-// nil or empty.
+// nil or empty. The tuples are returned in a stable order across all types, so that the continuation tokens can be
+// used to page through the whole store, e.g. to sync it.
 func (q *ReadQuery) Execute(ctx context.Context, req *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error) {
 	store := req.GetStoreId()
 	tk := req.GetTupleKey()
 
+	user := tk.GetUser()
+	if q.userType != "" {
+		if user != "" {
+			return nil, serverErrors.ValidationError(
+				fmt.Errorf("the user type filter cannot be combined with the user field of the 'tuple_key'"),
+			)
+		}
+		if strings.ContainsAny(q.userType, ":#@ ") {
+			return nil, serverErrors.ValidationError(fmt.Errorf("invalid user type '%s'", q.userType))
+		}
+		user = q.userType + ":"
+	}
+
 	// Restrict our reads due to some compatibility issues in one of our storage implementations.
-	if tk != nil {
+	// A tuple key without an object reads the tuples of all types, filtered by relation or user.
+	if tk.GetObject() != "" {
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
-		if objectType == "" || (objectID == "" && tk.GetUser() == "") {
+		if objectType == "" || (objectID == "" && user == "") {
 			return nil, serverErrors.ValidationError(
 				fmt.Errorf("the 'tuple_key' field was provided but the object type field is required and both the object id and user cannot be empty"),
 			)
@@ -94,13 +128,11 @@ func (q *ReadQuery) Execute(ctx context.Context, req *openfgav1.ReadRequest) (*o
 		Consistency: storage.ConsistencyOptions{Preference: req.GetConsistency()},
 	}
 
-	filter := storage.ReadFilter{}
-	if tk != nil {
-		filter = storage.ReadFilter{
-			Object:   tk.GetObject(),
-			Relation: tk.GetRelation(),
-			User:     tk.GetUser(),
-		}
+	filter := storage.ReadFilter{
+		Object:     tk.GetObject(),
+		Relation:   tk.GetRelation(),
+		User:       user,
+		Conditions: q.conditions,
 	}
 
 	tuples, contUlid, err := q.datastore.ReadPage(ctx, store, filter, opts)
@@ -9,6 +9,7 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/internal/changelog"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
@@ -22,6 +23,7 @@ type ReadChangesQuery struct {
 	encoder         encoder.Encoder
 	tokenSerializer encoder.ContinuationTokenSerializer
 	horizonOffset   time.Duration
+	cursors         *changelog.CursorTracker
 }
 
 type ReadChangesQueryOption func(*ReadChangesQuery)
@@ -52,6 +54,14 @@ func WithContinuationTokenSerializer(tokenSerializer encoder.ContinuationTokenSe
 	}
 }
 
+// WithReadChangesCursorTracker records the cursors handed out to clients in the given tracker, so
+// that the changelog retention keeps the changes they have yet to read.
+func WithReadChangesCursorTracker(cursors *changelog.CursorTracker) ReadChangesQueryOption {
+	return func(rq *ReadChangesQuery) {
+		rq.cursors = cursors
+	}
+}
+
 // NewReadChangesQuery creates a ReadChangesQuery with specified `ChangelogBackend`.
 func NewReadChangesQuery(backend storage.ChangelogBackend, opts ...ReadChangesQueryOption) *ReadChangesQuery {
 	rq := &ReadChangesQuery{
@@ -112,6 +122,7 @@ func (q *ReadChangesQuery) Execute(ctx context.Context, req *openfgav1.ReadChang
 	changes, contUlid, err := q.backend.ReadChanges(ctx, req.GetStoreId(), filter, opts)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
+			q.cursors.Observe(req.GetStoreId(), fromUlid)
 			return &openfgav1.ReadChangesResponse{
 				ContinuationToken: req.GetContinuationToken(),
 			}, nil
@@ -126,6 +137,8 @@ func (q *ReadChangesQuery) Execute(ctx context.Context, req *openfgav1.ReadChang
 		}, nil
 	}
 
+	q.cursors.Observe(req.GetStoreId(), contUlid)
+
 	contToken, err := q.tokenSerializer.Serialize(contUlid, req.GetType())
 	if err != nil {
 		return nil, serverErrors.HandleError("", err)
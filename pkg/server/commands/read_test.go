@@ -38,14 +38,6 @@ func TestReadCommand(t *testing.T) {
 					},
 				},
 			},
-			`missing_object_type_and_id_2`: {
-				request: &openfgav1.ReadRequest{
-					TupleKey: &openfgav1.ReadRequestTupleKey{
-						Relation: "admin",
-						User:     "github|jonallie",
-					},
-				},
-			},
 			`missing_object_id`: {
 				request: &openfgav1.ReadRequest{
 					TupleKey: &openfgav1.ReadRequestTupleKey{
@@ -54,13 +46,6 @@ func TestReadCommand(t *testing.T) {
 					},
 				},
 			},
-			`missing_user_and_object_id`: {
-				request: &openfgav1.ReadRequest{
-					TupleKey: &openfgav1.ReadRequestTupleKey{
-						Relation: "writer",
-					},
-				},
-			},
 		}
 		for name, test := range testCases {
 			t.Run(name, func(t *testing.T) {
@@ -77,6 +62,43 @@ func TestReadCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("reads_across_types_without_object", func(t *testing.T) {
+		testCases := map[string]struct {
+			tupleKey *openfgav1.ReadRequestTupleKey
+			filter   storage.ReadFilter
+		}{
+			`relation_and_user`: {
+				tupleKey: &openfgav1.ReadRequestTupleKey{Relation: "admin", User: "user:jon"},
+				filter:   storage.ReadFilter{Relation: "admin", User: "user:jon"},
+			},
+			`relation`: {
+				tupleKey: &openfgav1.ReadRequestTupleKey{Relation: "writer"},
+				filter:   storage.ReadFilter{Relation: "writer"},
+			},
+			`user`: {
+				tupleKey: &openfgav1.ReadRequestTupleKey{User: "user:jon"},
+				filter:   storage.ReadFilter{User: "user:jon"},
+			},
+		}
+		for name, test := range testCases {
+			t.Run(name, func(t *testing.T) {
+				mockController := gomock.NewController(t)
+				defer mockController.Finish()
+
+				storeID := ulid.Make().String()
+				mockDatastore := mocks.NewMockOpenFGADatastore(mockController)
+				mockDatastore.EXPECT().ReadPage(gomock.Any(), storeID, test.filter, gomock.Any()).Times(1)
+
+				cmd := NewReadQuery(mockDatastore)
+				_, err := cmd.Execute(context.Background(), &openfgav1.ReadRequest{
+					StoreId:  storeID,
+					TupleKey: test.tupleKey,
+				})
+				require.NoError(t, err)
+			})
+		}
+	})
+
 	t.Run("calls_storage_read_page", func(t *testing.T) {
 		mockController := gomock.NewController(t)
 		defer mockController.Finish()
@@ -25,6 +25,8 @@ type WriteCommand struct {
 	logger                    logger.Logger
 	datastore                 storage.OpenFGADatastore
 	conditionContextByteLimit int
+	region                    uint8
+	onLastChangeID            func(id string)
 }
 
 type WriteCommandOption func(*WriteCommand)
@@ -41,6 +43,24 @@ func WithConditionContextByteLimit(limit int) WriteCommandOption {
 	}
 }
 
+// WithWriteCmdRegion sets the region of a multi-region deployment the writes originate from. The
+// tuples are then written with last-writer-wins semantics: writing an existing tuple replaces it
+// if its condition differs and deleting a missing tuple is a no-op, whatever the on_duplicate and
+// on_missing options of the request.
+func WithWriteCmdRegion(region uint8) WriteCommandOption {
+	return func(wc *WriteCommand) {
+		wc.region = region
+	}
+}
+
+// WithWriteCmdOnLastChangeID sets the function called with the ULID of the last change of the
+// write in the changelog, see [storage.WithOnLastChangeID].
+func WithWriteCmdOnLastChangeID(onLastChangeID func(id string)) WriteCommandOption {
+	return func(wc *WriteCommand) {
+		wc.onLastChangeID = onLastChangeID
+	}
+}
+
 // NewWriteCommand creates a WriteCommand with specified storage.OpenFGADatastore to use for storage.
 func NewWriteCommand(datastore storage.OpenFGADatastore, opts ...WriteCommandOption) *WriteCommand {
 	cmd := &WriteCommand{
@@ -93,6 +113,11 @@ func (c *WriteCommand) Execute(ctx context.Context, req *openfgav1.WriteRequest)
 		return nil, err
 	}
 
+	if c.region != 0 {
+		onDuplicateInsert = storage.OnDuplicateInsertOverwrite
+		onEmptyDelete = storage.OnMissingDeleteIgnore
+	}
+
 	err = c.datastore.Write(
 		ctx,
 		req.GetStoreId(),
@@ -100,6 +125,8 @@ func (c *WriteCommand) Execute(ctx context.Context, req *openfgav1.WriteRequest)
 		req.GetWrites().GetTupleKeys(),
 		storage.WithOnMissingDelete(onEmptyDelete),
 		storage.WithOnDuplicateInsert(onDuplicateInsert),
+		storage.WithRegion(c.region),
+		storage.WithOnLastChangeID(c.onLastChangeID),
 	)
 	if err != nil {
 		if errors.Is(err, storage.ErrTransactionalWriteFailed) {
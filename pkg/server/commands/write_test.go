@@ -25,15 +25,17 @@ import (
 type writeOptionsMatcher struct {
 	expectedOnDuplicateInsert storage.OnDuplicateInsert
 	expectedOnMissingDelete   storage.OnMissingDelete
+	expectedRegion            uint8
 }
 
 func (w *writeOptionsMatcher) Matches(x interface{}) bool {
 	opts, ok := x.([]storage.TupleWriteOption)
-	if !ok || len(opts) != 2 {
+	if !ok || len(opts) != 4 {
 		return false
 	}
 	dut := storage.NewTupleWriteOptions(opts...)
-	return dut.OnMissingDelete == w.expectedOnMissingDelete && dut.OnDuplicateInsert == w.expectedOnDuplicateInsert
+	return dut.OnMissingDelete == w.expectedOnMissingDelete && dut.OnDuplicateInsert == w.expectedOnDuplicateInsert &&
+		dut.Region == w.expectedRegion
 }
 
 func (w *writeOptionsMatcher) String() string {
@@ -980,3 +982,43 @@ func TestWriteCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteCommandWithRegion(t *testing.T) {
+	const (
+		storeID = "01JCC8Z5S039R3X661KQGTNAFG"
+		modelID = "01JCC8ZD4X84K2W0H0ZA5AQ947"
+	)
+
+	model := parser.MustTransformDSLToProto(`
+	model
+		schema 1.1
+	type user
+	type document
+		relations
+			define viewer: [user]`)
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().MaxTuplesPerWrite().AnyTimes().Return(10)
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, modelID).Return(model, nil)
+	mockDatastore.EXPECT().Write(gomock.Any(), storeID, gomock.Any(), gomock.Any(),
+		&writeOptionsMatcher{
+			expectedOnDuplicateInsert: storage.OnDuplicateInsertOverwrite,
+			expectedOnMissingDelete:   storage.OnMissingDeleteIgnore,
+			expectedRegion:            2,
+		}).Return(nil)
+
+	_, err := NewWriteCommand(mockDatastore, WithWriteCmdRegion(2)).Execute(context.Background(), &openfgav1.WriteRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys:   []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:maria")},
+			OnDuplicate: "error",
+		},
+		Deletes: &openfgav1.WriteRequestDeletes{
+			TupleKeys: []*openfgav1.TupleKeyWithoutCondition{{Object: "document:2", Relation: "viewer", User: "user:maria"}},
+		},
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/authclaims"
+)
+
+var (
+	// ErrInvalidConditionContextHeader is returned when a mapping of a header to a condition
+	// context parameter cannot be parsed.
+	ErrInvalidConditionContextHeader = errors.New("invalid condition context header")
+
+	// ErrInvalidConditionContextClaim is returned when a mapping of a claim to a condition context
+	// parameter cannot be parsed.
+	ErrInvalidConditionContextClaim = errors.New("invalid condition context claim")
+)
+
+// ParseConditionContextHeaders parses mappings in the form <header>=<name>, e.g.
+// X-Client-Region=client_region, into a map of the lowercase names of the headers to the names of
+// their parameters in the condition context, as expected by WithConditionContextHeaders.
+func ParseConditionContextHeaders(mappings []string) (map[string]string, error) {
+	headers := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		header, name, ok := strings.Cut(mapping, "=")
+		header, name = strings.TrimSpace(header), strings.TrimSpace(name)
+		if !ok || header == "" || name == "" {
+			return nil, fmt.Errorf("%w '%s': must be in the form <header>=<name>", ErrInvalidConditionContextHeader, mapping)
+		}
+		header = strings.ToLower(header)
+		if _, ok := headers[header]; ok {
+			return nil, fmt.Errorf("%w '%s': the header is mapped more than once", ErrInvalidConditionContextHeader, mapping)
+		}
+		headers[header] = name
+	}
+	return headers, nil
+}
+
+// ParseConditionContextClaims parses mappings in the form <claim>=<name>, e.g.
+// department=caller_department, or <claim> when the parameter is named after the claim, into a map
+// of the names of the claims to the names of their parameters in the condition context, as
+// expected by WithConditionContextClaims.
+func ParseConditionContextClaims(mappings []string) (map[string]string, error) {
+	claims := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		claim, name, ok := strings.Cut(mapping, "=")
+		claim, name = strings.TrimSpace(claim), strings.TrimSpace(name)
+		if !ok {
+			name = claim
+		}
+		if claim == "" || name == "" {
+			return nil, fmt.Errorf("%w '%s': must be in the form <claim>=<name> or <claim>", ErrInvalidConditionContextClaim, mapping)
+		}
+		if _, ok := claims[claim]; ok {
+			return nil, fmt.Errorf("%w '%s': the claim is mapped more than once", ErrInvalidConditionContextClaim, mapping)
+		}
+		claims[claim] = name
+	}
+	return claims, nil
+}
+
+// injectConditionContext returns the condition context of a request with the values of the
+// headers mapped by WithConditionContextHeaders and of the claims of the principal mapped by
+// WithConditionContextClaims, or requestContext as is if there is nothing to inject nor to remove.
+// The mapped parameters are removed from the context of the request even when their header or
+// claim is missing, so that the attributes set by a trusted proxy or identity provider can't be
+// set by the clients, and the claims replace the headers. requestContext is not modified.
+func (s *Server) injectConditionContext(ctx context.Context, requestContext *structpb.Struct) *structpb.Struct {
+	var merged *structpb.Struct
+	copyRequestContext := func() {
+		if merged == nil {
+			merged = &structpb.Struct{Fields: make(map[string]*structpb.Value, len(requestContext.GetFields())+1)}
+			for key, value := range requestContext.GetFields() {
+				merged.Fields[key] = value
+			}
+		}
+	}
+	set := func(name string, value *structpb.Value) {
+		copyRequestContext()
+		merged.Fields[name] = value
+	}
+	remove := func(name string) {
+		if _, ok := requestContext.GetFields()[name]; ok {
+			copyRequestContext()
+			delete(merged.Fields, name)
+		}
+	}
+
+	for _, name := range s.conditionContextHeaders {
+		remove(name)
+	}
+	for _, name := range s.conditionContextClaims {
+		remove(name)
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for header, name := range s.conditionContextHeaders {
+			// grpc-gateway converts header names to lowercase
+			if values := md.Get(header); len(values) > 0 {
+				set(name, structpb.NewStringValue(values[0]))
+			}
+		}
+	}
+
+	if claims, ok := authclaims.AuthClaimsFromContext(ctx); ok {
+		for claim, name := range s.conditionContextClaims {
+			raw, ok := claims.Claims[claim]
+			if !ok {
+				continue
+			}
+			value, err := structpb.NewValue(raw)
+			if err != nil {
+				// the claims decoded from JSON are always valid values
+				s.logger.Debug("failed to inject a claim into the condition context", zap.String("claim", claim), zap.Error(err))
+				continue
+			}
+			set(name, value)
+		}
+	}
+
+	if merged == nil {
+		return requestContext
+	}
+	return merged
+}
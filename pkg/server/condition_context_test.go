@@ -0,0 +1,294 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/authclaims"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestParseConditionContextHeaders(t *testing.T) {
+	headers, err := ParseConditionContextHeaders([]string{"X-Client-Region=client_region", " X-Tenant = tenant "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"x-client-region": "client_region", "x-tenant": "tenant"}, headers)
+
+	for _, mapping := range []string{"X-Client-Region", "=client_region", "X-Client-Region="} {
+		_, err := ParseConditionContextHeaders([]string{mapping})
+		require.ErrorIs(t, err, ErrInvalidConditionContextHeader, mapping)
+	}
+
+	_, err = ParseConditionContextHeaders([]string{"X-Client-Region=region", "x-client-region=client_region"})
+	require.ErrorIs(t, err, ErrInvalidConditionContextHeader)
+}
+
+func TestParseConditionContextClaims(t *testing.T) {
+	claims, err := ParseConditionContextClaims([]string{"department=caller_department", "groups"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"department": "caller_department", "groups": "groups"}, claims)
+
+	for _, mapping := range []string{"", "=caller_department", "department="} {
+		_, err := ParseConditionContextClaims([]string{mapping})
+		require.ErrorIs(t, err, ErrInvalidConditionContextClaim, mapping)
+	}
+
+	_, err = ParseConditionContextClaims([]string{"department", "department=caller_department"})
+	require.ErrorIs(t, err, ErrInvalidConditionContextClaim)
+}
+
+func TestConditionContextHeaders(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	headers, err := ParseConditionContextHeaders([]string{"X-Client-Region=client_region"})
+	require.NoError(t, err)
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithConditionContextHeaders(headers),
+	)
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "condition-context-headers"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user with in_region]
+		condition in_region(client_region: string) {
+			client_region == "eu"
+		}`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "in_region", nil),
+		}},
+	})
+	require.NoError(t, err)
+
+	withRegion := func(region string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs("x-client-region", region))
+	}
+
+	t.Run("check", func(t *testing.T) {
+		resp, err := s.Check(withRegion("eu"), &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+
+		resp, err = s.Check(withRegion("us"), &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("header_replaces_request_context", func(t *testing.T) {
+		requestContext, err := structpb.NewStruct(map[string]any{"client_region": "eu"})
+		require.NoError(t, err)
+
+		resp, err := s.Check(withRegion("us"), &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+			Context:  requestContext,
+		})
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		// the context of the request is not modified
+		require.Equal(t, "eu", requestContext.GetFields()["client_region"].GetStringValue())
+
+	})
+
+	t.Run("request_context_without_header_is_removed", func(t *testing.T) {
+		requestContext, err := structpb.NewStruct(map[string]any{"client_region": "eu"})
+		require.NoError(t, err)
+
+		_, err = s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+			Context:  requestContext,
+		})
+		require.ErrorContains(t, err, "missing context parameters")
+		require.Equal(t, "eu", requestContext.GetFields()["client_region"].GetStringValue())
+	})
+
+	t.Run("batch_check", func(t *testing.T) {
+		resp, err := s.BatchCheck(withRegion("eu"), &openfgav1.BatchCheckRequest{
+			StoreId: storeID,
+			Checks: []*openfgav1.BatchCheckItem{{
+				TupleKey:      &openfgav1.CheckRequestTupleKey{Object: "document:1", Relation: "viewer", User: "user:anne"},
+				CorrelationId: "1",
+			}},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetResult()["1"].GetAllowed())
+	})
+
+	t.Run("list_objects", func(t *testing.T) {
+		resp, err := s.ListObjects(withRegion("eu"), &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1"}, resp.GetObjects())
+	})
+
+	t.Run("list_users", func(t *testing.T) {
+		resp, err := s.ListUsers(withRegion("eu"), &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "document", Id: "1"},
+			Relation:    "viewer",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetUsers(), 1)
+
+		resp, err = s.ListUsers(withRegion("us"), &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "document", Id: "1"},
+			Relation:    "viewer",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetUsers())
+	})
+}
+
+func TestConditionContextClaims(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	headers, err := ParseConditionContextHeaders([]string{"X-Department=caller_department"})
+	require.NoError(t, err)
+	claims, err := ParseConditionContextClaims([]string{"department=caller_department", "groups"})
+	require.NoError(t, err)
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithConditionContextHeaders(headers),
+		WithConditionContextClaims(claims),
+	)
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "condition-context-claims"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user:* with same_department, user:* with in_group]
+		condition same_department(caller_department: string, department: string) {
+			caller_department == department
+		}
+		condition in_group(groups: list<string>) {
+			"admins" in groups
+		}`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+	require.NoError(t, err)
+
+	department, err := structpb.NewStruct(map[string]any{"department": "eng"})
+	require.NoError(t, err)
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:*", "same_department", department),
+			tuple.NewTupleKeyWithCondition("document:2", "viewer", "user:*", "in_group", nil),
+		}},
+	})
+	require.NoError(t, err)
+
+	withClaims := func(ctx context.Context, claims map[string]any) context.Context {
+		return authclaims.ContextWithAuthClaims(ctx, &authclaims.AuthClaims{Subject: "anne", Claims: claims})
+	}
+	check := func(ctx context.Context, object string, requestContext *structpb.Struct) bool {
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey(object, "viewer", "user:anne"),
+			Context:  requestContext,
+		})
+		require.NoError(t, err)
+		return resp.GetAllowed()
+	}
+
+	t.Run("check", func(t *testing.T) {
+		require.True(t, check(withClaims(ctx, map[string]any{"department": "eng"}), "document:1", nil))
+		require.False(t, check(withClaims(ctx, map[string]any{"department": "sales"}), "document:1", nil))
+	})
+
+	t.Run("list_claim", func(t *testing.T) {
+		require.True(t, check(withClaims(ctx, map[string]any{"groups": []any{"devs", "admins"}}), "document:2", nil))
+	})
+
+	t.Run("claims_replace_the_request_context_and_the_headers", func(t *testing.T) {
+		requestContext, err := structpb.NewStruct(map[string]any{"caller_department": "eng"})
+		require.NoError(t, err)
+		require.False(t, check(withClaims(ctx, map[string]any{"department": "sales"}), "document:1", requestContext))
+
+		headerCtx := metadata.NewIncomingContext(ctx, metadata.Pairs("x-department", "eng"))
+		require.False(t, check(withClaims(headerCtx, map[string]any{"department": "sales"}), "document:1", nil))
+	})
+
+	t.Run("request_context_without_claim_is_removed", func(t *testing.T) {
+		requestContext, err := structpb.NewStruct(map[string]any{"groups": []any{"admins"}})
+		require.NoError(t, err)
+
+		_, err = s.Check(withClaims(ctx, map[string]any{"department": "eng"}), &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:2", "viewer", "user:anne"),
+			Context:  requestContext,
+		})
+		require.ErrorContains(t, err, "missing context parameters")
+	})
+
+	t.Run("claims_not_allowed_are_not_injected", func(t *testing.T) {
+		_, err := s.Check(withClaims(ctx, map[string]any{"caller_department": "eng"}), &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.ErrorContains(t, err, "missing context parameters")
+	})
+}
@@ -6,6 +6,9 @@ import (
 
 type CacheSettings struct {
 	CheckCacheLimit                    uint32
+	CheckCacheDiskPath                 string
+	CheckCacheMaxBytes                 uint64
+	CheckCacheShards                   uint32
 	CacheControllerEnabled             bool
 	CacheControllerTTL                 time.Duration
 	CheckQueryCacheEnabled             bool
@@ -21,6 +24,13 @@ type CacheSettings struct {
 	SharedIteratorLimit                uint32
 	SharedIteratorTTL                  time.Duration
 
+	// ListObjectsQueryCacheEnabled enables the cache of the ListObjects responses of up to
+	// ListObjectsQueryCacheMaxResults objects, which are valid until the next write to their store
+	// is found by the cache controller, or for ListObjectsQueryCacheTTL.
+	ListObjectsQueryCacheEnabled    bool
+	ListObjectsQueryCacheMaxResults uint32
+	ListObjectsQueryCacheTTL        time.Duration
+
 	// CacheTTLJitterPercentage is a percentage (0-100) of the base TTL that is used
 	// as the upper bound for a random jitter added to each cache entry's TTL.
 	// This spreads out cache expirations to prevent thundering herd effects
@@ -45,6 +55,9 @@ func NewDefaultCacheSettings() CacheSettings {
 		ListObjectsIteratorCacheEnabled:    DefaultListObjectsIteratorCacheEnabled,
 		ListObjectsIteratorCacheMaxResults: DefaultListObjectsIteratorCacheMaxResults,
 		ListObjectsIteratorCacheTTL:        DefaultListObjectsIteratorCacheTTL,
+		ListObjectsQueryCacheEnabled:       DefaultListObjectsQueryCacheEnabled,
+		ListObjectsQueryCacheMaxResults:    DefaultListObjectsQueryCacheMaxResults,
+		ListObjectsQueryCacheTTL:           DefaultListObjectsQueryCacheTTL,
 		SharedIteratorEnabled:              DefaultSharedIteratorEnabled,
 		SharedIteratorLimit:                DefaultSharedIteratorLimit,
 		SharedIteratorTTL:                  DefaultSharedIteratorTTL,
@@ -53,7 +66,7 @@ func NewDefaultCacheSettings() CacheSettings {
 }
 
 func (c CacheSettings) ShouldCreateNewCache() bool {
-	return c.ShouldCacheCheckQueries() || c.ShouldCacheCheckIterators() || c.ShouldCacheListObjectsIterators()
+	return c.ShouldCacheCheckQueries() || c.ShouldCacheCheckIterators() || c.ShouldCacheListObjectsIterators() || c.ShouldCacheListObjectsQueries()
 }
 
 func (c CacheSettings) ShouldCreateCacheController() bool {
@@ -72,6 +85,20 @@ func (c CacheSettings) ShouldCacheListObjectsIterators() bool {
 	return c.ListObjectsIteratorCacheEnabled && c.ListObjectsIteratorCacheMaxResults > 0
 }
 
+// ShouldCacheListObjectsQueries returns true if the ListObjects responses should be cached.
+func (c CacheSettings) ShouldCacheListObjectsQueries() bool {
+	return c.ListObjectsQueryCacheEnabled && c.ListObjectsQueryCacheMaxResults > 0
+}
+
+// QueryCacheTTL returns the longest TTL of the cached Check and ListObjects responses, for which
+// the cache controller keeps the last write to a store.
+func (c CacheSettings) QueryCacheTTL() time.Duration {
+	if c.ShouldCacheListObjectsQueries() {
+		return max(c.CheckQueryCacheTTL, c.ListObjectsQueryCacheTTL)
+	}
+	return c.CheckQueryCacheTTL
+}
+
 func (c CacheSettings) ShouldCreateShadowNewCache() bool {
 	return c.ShouldCreateNewCache()
 }
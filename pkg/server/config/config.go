@@ -6,16 +6,23 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/internal/compression"
+	"github.com/openfga/openfga/internal/redaction"
+	"github.com/openfga/openfga/pkg/middleware"
+	"github.com/openfga/openfga/pkg/middleware/priority"
 )
 
 const (
 	DefaultMaxRPCMessageSizeInBytes         = 512 * 1_204 // 512 KB
+	DefaultMaxRPCSendMessageSizeInBytes     = math.MaxInt32
 	DefaultMaxTuplesPerWrite                = 100
 	DefaultMaxTypesPerAuthorizationModel    = 100
 	DefaultMaxAuthorizationModelSizeInBytes = 256 * 1_024
@@ -33,9 +40,159 @@ const (
 	DefaultMaxConcurrentReadsForListUsers   = math.MaxUint32
 	DefaultReadChangesMaxPageSize           = 100
 
+	DefaultDatastoreIndexAdvisorEnabled            = false
+	DefaultDatastoreIndexAdvisorSlowQueryThreshold = 200 * time.Millisecond
+	DefaultDatastoreReverseUserIndexEnabled        = false
+	DefaultDatastoreMaintenanceTuningEnabled       = false
+	DefaultDatastoreTransactionPoolingEnabled      = false
+
+	DefaultLatestModelCacheTTL    = 0
+	DefaultLatestModelCacheEngine = "memory"
+
+	DefaultStreamedListObjectsMaxInFlightMessages = 100
+	DefaultStreamedListObjectsMessagesPerSecond   = 0
+
 	DefaultWriteContextByteLimit = 32 * 1_024 // 32KB
 
-	DefaultCheckCacheLimit = 10000
+	DefaultMaxContextualTuples            = 100
+	DefaultContextualTuplesValidationMode = ContextualTuplesValidationModeStrict
+
+	DefaultCompressionEnabled      = false
+	DefaultCompressionMinSizeBytes = 1_024
+
+	// The defaults of the keepalive and connection settings of the gRPC server are the ones of
+	// grpc-go: the connections are never closed because of their age or idleness, and the number
+	// of concurrent streams per connection is not limited.
+	DefaultGRPCKeepaliveTime                = 2 * time.Hour
+	DefaultGRPCKeepaliveTimeout             = 20 * time.Second
+	DefaultGRPCKeepaliveMinTime             = 5 * time.Minute
+	DefaultGRPCKeepalivePermitWithoutStream = false
+	DefaultGRPCMaxConnectionIdle            = 0
+	DefaultGRPCMaxConnectionAge             = 0
+	DefaultGRPCMaxConnectionAgeGrace        = 0
+	DefaultGRPCMaxConcurrentStreams         = 0
+
+	DefaultHTTPServerTimingEnabled = false
+	DefaultHTTPMaxRequestBodyBytes = 0
+
+	DefaultHTTPCORSMaxAge           = 0
+	DefaultHTTPCORSAllowCredentials = true
+
+	DefaultOPABundleEnabled         = false
+	DefaultOPABundleMaxTuples       = 100_000
+	DefaultOPABundleMaxDeltaChanges = 10_000
+
+	DefaultChangelogRetentionEnabled     = false
+	DefaultChangelogRetentionInterval    = time.Hour
+	DefaultChangelogRetentionMaxAge      = 30 * 24 * time.Hour
+	DefaultChangelogRetentionMaxEntries  = 0
+	DefaultChangelogRetentionCursorLease = 24 * time.Hour
+
+	DefaultOrphanedTuplesEndpointEnabled   = false
+	DefaultOrphanedTuplesCollectorEnabled  = false
+	DefaultOrphanedTuplesCollectorInterval = 24 * time.Hour
+	DefaultOrphanedTuplesCollectorDryRun   = true
+
+	DefaultConsistencyVerifierEnabled    = false
+	DefaultConsistencyVerifierInterval   = 5 * time.Minute
+	DefaultConsistencyVerifierSampleSize = 100
+
+	DefaultRequestRecordingEnabled          = false
+	DefaultRequestRecordingSamplePercentage = 1
+	DefaultRequestRecordingAnonymize        = true
+
+	DefaultHotPathsEnabled    = false
+	DefaultHotPathsInterval   = time.Minute
+	DefaultHotPathsTopN       = 10
+	DefaultHotPathsMinChecks  = 100
+	DefaultHotPathsMaxObjects = 1000
+
+	DefaultMaterializedPermissionsEnabled  = false
+	DefaultMaterializedPermissionsInterval = 10 * time.Second
+
+	DefaultMaterializedObjectListsEnabled     = false
+	DefaultMaterializedObjectListsInterval    = 10 * time.Second
+	DefaultMaterializedObjectListsTopN        = 10
+	DefaultMaterializedObjectListsMinRequests = 10
+
+	DefaultClusterSingleflightEnabled    = false
+	DefaultClusterForwardTimeout         = 3 * time.Second
+	DefaultClusterTLSEnabled             = false
+	DefaultClusterDispatchEnabled        = false
+	DefaultClusterDispatchServiceEnabled = false
+
+	DefaultPrioritySchedulingEnabled     = false
+	DefaultPrioritySchedulingMaxInFlight = 500
+
+	DefaultCheckCoalescingEnabled = false
+	DefaultCheckCoalescingWindow  = 2 * time.Millisecond
+
+	DefaultWriteSchedulingEnabled     = false
+	DefaultWriteSchedulingMaxInFlight = 50
+
+	DefaultBatchCheckAdaptiveLimitsEnabled       = false
+	DefaultBatchCheckAdaptiveLimitsLoadThreshold = 0.8
+	DefaultBatchCheckAdaptiveLimitsMinRatio      = 0.1
+
+	DefaultStreamedBatchCheckEnabled = false
+
+	DefaultBulkWriteEnabled = false
+	DefaultBulkWriteWindow  = 8
+
+	DefaultStorePurgeEnabled      = false
+	DefaultStorePurgeJobRetention = time.Hour
+
+	DefaultStoreEventsEnabled = false
+
+	DefaultListObjectsSubscriptionEnabled      = false
+	DefaultListObjectsSubscriptionPollInterval = time.Second
+
+	DefaultCacheHintsEnabled                  = false
+	DefaultCacheHintsMaxAge                   = time.Minute
+	DefaultCacheHintsInvalidationPollInterval = time.Second
+
+	DefaultSessionTokensEnabled      = false
+	DefaultSessionTokensCommitMargin = 5 * time.Second
+
+	DefaultAssertionCoverageEnabled = false
+
+	DefaultModelGraphEnabled = false
+
+	DefaultCostEstimateEnabled    = false
+	DefaultCostEstimateSampleSize = 1000
+
+	DefaultWhatIfEnabled = false
+
+	DefaultModelSimulationEnabled = false
+
+	DefaultAccessReviewEnabled      = false
+	DefaultAccessReviewArtifactDir  = ""
+	DefaultAccessReviewJobRetention = 24 * time.Hour
+
+	DefaultJobsEnabled   = false
+	DefaultJobsWorkers   = 4
+	DefaultJobsQueueSize = 100
+	DefaultJobsRetention = 24 * time.Hour
+
+	DefaultJobsScheduleInterval = 30 * time.Second
+
+	DefaultMultiRegionEnabled  = false
+	DefaultMultiRegionRegionID = 0
+
+	DefaultWriteValidationHookEnabled  = false
+	DefaultWriteValidationHookTimeout  = time.Second
+	DefaultWriteValidationHookFailOpen = false
+
+	DefaultContextEnrichmentHookEnabled         = false
+	DefaultContextEnrichmentHookTimeout         = time.Second
+	DefaultContextEnrichmentHookCacheTTL        = time.Minute
+	DefaultContextEnrichmentHookCacheMaxEntries = 10000
+
+	DefaultSLOEnabled = false
+	DefaultSLOWindow  = 30 * 24 * time.Hour
+
+	DefaultCheckCacheLimit  = 10000
+	DefaultCheckCacheEngine = "memory"
 
 	DefaultCacheControllerEnabled = false
 	DefaultCacheControllerTTL     = 10 * time.Second
@@ -52,13 +209,34 @@ const (
 	DefaultListObjectsIteratorCacheMaxResults = 10000
 	DefaultListObjectsIteratorCacheTTL        = 10 * time.Second
 
+	DefaultListObjectsQueryCacheEnabled    = false
+	DefaultListObjectsQueryCacheMaxResults = 1000
+	DefaultListObjectsQueryCacheTTL        = 10 * time.Second
+
 	DefaultListObjectsPipelineEnabled      = true
 	DefaultListObjectsOptimizationsEnabled = false
 
 	DefaultCacheControllerConfigEnabled = false
 	DefaultCacheControllerConfigTTL     = 10 * time.Second
 
-	DefaultShadowCheckResolverTimeout = 1 * time.Second
+	DefaultCacheInvalidationListenerEnabled             = false
+	DefaultCacheInvalidationListenerPostgresPublication = "openfga_tuple_changes"
+	DefaultCacheInvalidationListenerPollInterval        = time.Second
+
+	DefaultUserIDEncryptionEnabled = false
+
+	DefaultRedactionMode              = "none"
+	DefaultRedactionKeyRotationPeriod = 24 * time.Hour
+
+	DefaultSecretsRefreshInterval = time.Minute
+
+	DefaultAuditEnabled       = false
+	DefaultAuditOTLPEndpoint  = "0.0.0.0:4317"
+	DefaultAuditQueueSize     = 10000
+	DefaultAuditFlushInterval = 5 * time.Second
+
+	DefaultShadowCheckResolverTimeout          = 1 * time.Second
+	DefaultShadowCheckResolverSamplePercentage = 100
 
 	DefaultShadowListObjectsQueryTimeout       = 1 * time.Second
 	DefaultShadowListObjectsQueryMaxDeltaItems = 100
@@ -115,6 +293,13 @@ const (
 	ExperimentalShadowWeightedGraphCheck = "shadow_weighted_graph_check"
 	ExperimentalWeightedGraphCheck       = "weighted_graph_check"
 	ExperimentalAuthZen                  = "authzen"
+	ExperimentalListObjectsSQLPushdown   = "list_objects_sql_pushdown"
+)
+
+// Modes for the validation of contextual tuples that reference types not defined in the model.
+const (
+	ContextualTuplesValidationModeStrict  = "strict"
+	ContextualTuplesValidationModeLenient = "lenient"
 )
 
 type DatastoreMetricsConfig struct {
@@ -122,6 +307,59 @@ type DatastoreMetricsConfig struct {
 	Enabled bool
 }
 
+// DatastoreIndexAdvisorConfig defines configuration for the index advisor of the SQL datastores.
+type DatastoreIndexAdvisorConfig struct {
+	// Enabled enables the index advisor, which EXPLAINs the slow tuple queries and logs the ones
+	// that scan a table fully with a suggested composite index. It is only available in
+	// PostgreSQL and MySQL.
+	Enabled bool
+
+	// SlowQueryThreshold is the duration from which a query is slow.
+	SlowQueryThreshold time.Duration
+}
+
+// DatastoreReverseUserIndexConfig defines configuration for the reverse user index of the tuples.
+type DatastoreReverseUserIndexConfig struct {
+	// Enabled maintains an index of the tuples by user on write, so that the reads of the tuples
+	// of the users, e.g. by ListObjects, are index lookups instead of scans on the large stores.
+	// It is only available in memory, PostgreSQL and MySQL. The index of the SQL datastores is
+	// built and enabled in the datastore by 'openfga migrate --reverse-user-index=enabled', and the
+	// instances refuse to start unless it matches their configuration.
+	Enabled bool
+}
+
+// DatastoreMaintenanceTuningConfig defines configuration for the maintenance settings of the
+// tables of the SQL datastores.
+type DatastoreMaintenanceTuningConfig struct {
+	// Enabled applies the recommended autovacuum and analyze settings of PostgreSQL, or the
+	// statistics settings of MySQL, to the tuple, changelog and reverse user index tables when
+	// the datastore is opened. They can also be applied by the migrations.
+	Enabled bool
+}
+
+// DatastoreTransactionPoolingConfig defines configuration for the compatibility of the datastore
+// with connection poolers in transaction pooling mode.
+type DatastoreTransactionPoolingConfig struct {
+	// Enabled avoids the session-level state of the connections, like the prepared statements, so
+	// that the datastore works behind PgBouncer in transaction pooling mode. It is only available
+	// in PostgreSQL, and the queries are sent with the simple protocol instead of being prepared.
+	Enabled bool
+}
+
+// DatastoreIAMAuthConfig defines configuration for the IAM authentication of the connections of
+// the SQL datastores.
+type DatastoreIAMAuthConfig struct {
+	// Method is the IAM authentication method of the connections, 'aws_rds' or 'gcp_cloudsql', or
+	// empty to authenticate with the password. The connections authenticate with short-lived tokens
+	// of the identity of the server instead, which are refreshed before they expire. It is only
+	// available in PostgreSQL and MySQL.
+	Method string
+
+	// Region is the AWS region of the database of the 'aws_rds' method, which defaults to the
+	// region of the AWS configuration, e.g. the AWS_REGION environment variable.
+	Region string
+}
+
 // DatastoreConfig defines OpenFGA server configurations for datastore specific settings.
 type DatastoreConfig struct {
 	// Engine is the datastore engine to use (e.g. 'memory', 'postgres', 'mysql', 'sqlite')
@@ -139,6 +377,24 @@ type DatastoreConfig struct {
 	// MaxTypesystemCacheSize is the maximum number of type system models that will be cached in memory
 	MaxTypesystemCacheSize int
 
+	// TypesystemCompilationCacheEngine is the name of a check cache engine registered with the
+	// caches package, shared by the instances of OpenFGA, that holds the artifacts of the
+	// validation and compilation of the authorization models, so that the instances that start
+	// don't compile the large models again. It is constructed with the check cache settings. If
+	// empty, the models are compiled by each instance.
+	TypesystemCompilationCacheEngine string
+
+	// LatestModelCacheTTL is the duration that the ID of the latest authorization model of a store
+	// is cached for, so that the requests without an authorization model ID don't look it up in the
+	// datastore. Writing a model invalidates the ID of its store. If 0, it is not cached.
+	LatestModelCacheTTL time.Duration
+
+	// LatestModelCacheEngine is the cache that holds the IDs of the latest authorization models:
+	// 'memory', or the name of a check cache engine registered with the caches package, e.g. a
+	// cache shared by the instances of OpenFGA so that a model written through one of them
+	// invalidates the ID in all of them. It is constructed with the check cache settings.
+	LatestModelCacheEngine string
+
 	// MaxOpenConns is the maximum number of open connections to the database.
 	MaxOpenConns int
 
@@ -162,6 +418,36 @@ type DatastoreConfig struct {
 
 	// Metrics is configuration for the Datastore metrics.
 	Metrics DatastoreMetricsConfig
+
+	// IndexAdvisor is configuration for the index advisor of the SQL datastores.
+	IndexAdvisor DatastoreIndexAdvisorConfig
+
+	// ReverseUserIndex is configuration for the reverse user index of the tuples.
+	ReverseUserIndex DatastoreReverseUserIndexConfig
+
+	// MaintenanceTuning is configuration for the maintenance settings of the SQL datastores.
+	MaintenanceTuning DatastoreMaintenanceTuningConfig
+
+	// TransactionPooling is configuration for the compatibility with connection poolers in
+	// transaction pooling mode.
+	TransactionPooling DatastoreTransactionPoolingConfig
+
+	// IAMAuth is configuration for the IAM authentication of the SQL datastores.
+	IAMAuth DatastoreIAMAuthConfig
+}
+
+// CompressionConfig defines configuration for compressing response payloads.
+type CompressionConfig struct {
+	// Enabled enables compression of responses for clients that request it.
+	Enabled bool
+
+	// Algorithms is the list of compression algorithms that can be negotiated, in order of
+	// server preference (e.g. 'gzip', 'zstd').
+	Algorithms []string
+
+	// MinSizeBytes is the minimum size of a response body before it is compressed. This is
+	// only used by the HTTP server.
+	MinSizeBytes int
 }
 
 // GRPCConfig defines OpenFGA server configurations for grpc server specific settings.
@@ -169,6 +455,49 @@ type GRPCConfig struct {
 	Addr            string
 	TLS             *TLSConfig
 	MaxRecvMsgBytes int
+	Compression     CompressionConfig
+	Keepalive       GRPCKeepaliveConfig
+
+	// MaxSendMsgBytes is the maximum size of a sent message, e.g. of an Expand response. The HTTP
+	// gateway accepts the responses up to this size too.
+	MaxSendMsgBytes int
+
+	// MaxConnectionIdle is the duration after which an idle connection is closed. 0 means that
+	// the idle connections are never closed.
+	MaxConnectionIdle time.Duration
+
+	// MaxConnectionAge is the maximum age of a connection, after which the clients are asked to
+	// reconnect, so that their new connections are balanced over the current replicas, e.g. the
+	// ones added by a scale out. 0 means that the connections have no maximum age.
+	MaxConnectionAge time.Duration
+
+	// MaxConnectionAgeGrace is the duration given to the requests in flight on a connection that
+	// reached MaxConnectionAge before it is closed. 0 means that they are never interrupted.
+	MaxConnectionAgeGrace time.Duration
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams, i.e. requests, of a
+	// connection. 0 means no limit.
+	MaxConcurrentStreams uint32
+}
+
+// GRPCKeepaliveConfig defines the keepalive pings of the gRPC server, and the enforcement of the
+// pings of its clients.
+type GRPCKeepaliveConfig struct {
+	// Time is the duration of inactivity of a connection after which the server pings the client
+	// to check that the connection is alive.
+	Time time.Duration
+
+	// Timeout is the duration the server waits for the acknowledgement of a ping before it closes
+	// the connection.
+	Timeout time.Duration
+
+	// MinTime is the minimum duration that the clients should wait between their pings. The
+	// connections of the clients that ping more often are closed.
+	MinTime time.Duration
+
+	// PermitWithoutStream allows the clients to ping when there are no active streams on their
+	// connection. Otherwise, such pings count as too frequent.
+	PermitWithoutStream bool
 }
 
 // HTTPConfig defines OpenFGA server configurations for HTTP server specific settings.
@@ -181,8 +510,34 @@ type HTTPConfig struct {
 	// to the grpc endpoint. It cannot be smaller than Config.ListObjectsDeadline.
 	UpstreamTimeout time.Duration
 
+	// MaxRequestBodyBytes is the maximum size of the body of a request, before it is decoded. 0
+	// means no limit other than GRPCConfig.MaxRecvMsgBytes, which applies to the decoded request.
+	MaxRequestBodyBytes int
+
 	CORSAllowedOrigins []string
 	CORSAllowedHeaders []string
+
+	// CORSAllowedMethods are the methods allowed in the CORS requests.
+	CORSAllowedMethods []string
+
+	// CORSExposedHeaders are the response headers that the browsers expose to the CORS requests,
+	// in addition to the CORS-safelisted ones, e.g. Server-Timing.
+	CORSExposedHeaders []string
+
+	// CORSMaxAge is the duration, e.g. 10m, for which the browsers cache the responses of the
+	// preflight requests, sent to them in whole seconds. 0 means that the browsers use their
+	// default.
+	CORSMaxAge time.Duration
+
+	// CORSAllowCredentials allows the CORS requests to include credentials, e.g. cookies or an
+	// Authorization header.
+	CORSAllowCredentials bool
+
+	Compression CompressionConfig
+
+	// ServerTimingEnabled enables the Server-Timing header on the Check and BatchCheck
+	// responses, which reports the resolution time, the datastore time and the cache status.
+	ServerTimingEnabled bool
 }
 
 // AuthzenConfig defines configuration for the AuthZEN discovery endpoint.
@@ -192,6 +547,448 @@ type AuthzenConfig struct {
 	BaseURL string `mapstructure:"baseURL"`
 }
 
+// OPABundleConfig defines configuration for the OPA bundle endpoint, which exports the tuples of a
+// store as Open Policy Agent bundles.
+type OPABundleConfig struct {
+	Enabled bool
+
+	// MaxTuples is the maximum number of tuples of a store that can be exported in a bundle snapshot.
+	MaxTuples int
+
+	// MaxDeltaChanges is the maximum number of changes served as a delta bundle. When more tuples
+	// changed since the revision known by the client, a snapshot is served instead.
+	MaxDeltaChanges int
+}
+
+// ChangelogRetentionConfig defines configuration for the background job that deletes old changes
+// from the changelog of each store.
+type ChangelogRetentionConfig struct {
+	Enabled bool
+
+	// Interval is the time between two runs of the job.
+	Interval time.Duration
+
+	// MaxAge is the age beyond which changes are deleted. 0 means no age limit.
+	MaxAge time.Duration
+
+	// MaxEntries is the number of most recent changes kept for each store. 0 means no limit.
+	MaxEntries uint32
+
+	// CursorLease is how long after it was handed out a ReadChanges continuation token keeps the
+	// changes it has yet to read from being deleted.
+	CursorLease time.Duration
+
+	// StorePolicies overrides MaxAge and MaxEntries for specific stores. Each entry has the form
+	// <store_id>=<max_age>:<max_entries>.
+	StorePolicies []string
+}
+
+// OrphanedTuplesConfig defines configuration for finding, and optionally deleting, the tuples that
+// reference types, relations or conditions that are not defined in the latest model of their store.
+type OrphanedTuplesConfig struct {
+	// EndpointEnabled enables the HTTP endpoint that reports or deletes the orphaned tuples of a store on demand.
+	EndpointEnabled bool
+
+	// CollectorEnabled enables the background job that periodically collects the orphaned tuples of every store.
+	CollectorEnabled bool
+
+	// CollectorInterval is the time between two runs of the background job.
+	CollectorInterval time.Duration
+
+	// CollectorDryRun makes the background job only report the orphaned tuples it finds, without deleting them.
+	CollectorDryRun bool
+}
+
+// ShadowCheckConfig defines configuration for the shadow Check resolvers enabled through the
+// `shadow_check` and `shadow_weighted_graph_check` experimental flags, which evaluate a sample of the
+// Checks a second time in the background and report how their results and latencies compare.
+type ShadowCheckConfig struct {
+	// Timeout is the amount of time to wait for a shadow evaluation.
+	Timeout time.Duration
+
+	// SamplePercentage is the percentage of the Checks that are also evaluated by the shadow resolvers.
+	SamplePercentage int
+}
+
+// ConsistencyVerifierConfig defines configuration for the background job that samples the tuples of
+// each store and reports the violations of the storage invariants.
+type ConsistencyVerifierConfig struct {
+	Enabled bool
+
+	// Interval is the time between two runs of the job.
+	Interval time.Duration
+
+	// SampleSize is the number of tuples, and the number of changes, verified per store on each run.
+	SampleSize int
+}
+
+// RequestRecordingConfig defines configuration for the recorder that samples Check and ListObjects
+// requests to a file, from which they can be replayed with `openfga replay`.
+type RequestRecordingConfig struct {
+	Enabled bool
+
+	// Path is the file the requests are appended to.
+	Path string
+
+	// SamplePercentage is the percentage of the Check and ListObjects requests that are recorded.
+	SamplePercentage int
+
+	// Anonymize replaces the object and user IDs, and the string values of the condition contexts,
+	// with keyed pseudonyms.
+	Anonymize bool
+
+	// AnonymizationKey is the key the pseudonyms are derived from. If empty, a random key is
+	// generated on startup, so the pseudonyms of two runs of the server differ.
+	AnonymizationKey string `json:"-"` // private field, won't be logged
+}
+
+// HotPathsConfig defines configuration for the tracking of the (object type, relation) pairs checked
+// the most in each store, and for the background precomputation of their membership.
+type HotPathsConfig struct {
+	Enabled bool
+
+	// Interval is the time between two precomputations. A precomputed membership is used for up to
+	// twice this duration, unless a relevant tuple is written to this server in the meantime.
+	Interval time.Duration
+
+	// TopN is the number of pairs per store precomputed on each run.
+	TopN int
+
+	// MinChecks is the number of Checks a pair must receive during an interval to be precomputed.
+	MinChecks int
+
+	// MaxObjects is the maximum number of objects of a type whose membership is precomputed.
+	MaxObjects int
+}
+
+// MaterializedPermissionsConfig defines configuration for the denormalized (user, relation, object)
+// permissions maintained in the datastore for designated relations.
+type MaterializedPermissionsConfig struct {
+	Enabled bool
+
+	// Relations are the relations whose permissions are materialized, in the form
+	// <store_id>=<object_type>#<relation>.
+	Relations []string
+
+	// Interval is the time between two updates of the permissions from the changelog.
+	Interval time.Duration
+}
+
+// MaterializedObjectListsConfig defines configuration for the ListObjects results of the (user,
+// relation, object type) triples requested the most in each store, materialized in memory.
+type MaterializedObjectListsConfig struct {
+	Enabled bool
+
+	// Interval is the time between two updates of the object lists from the changelog.
+	Interval time.Duration
+
+	// TopN is the number of triples per store materialized on each update.
+	TopN int
+
+	// MinRequests is the number of ListObjects requests a triple must receive during an interval
+	// to be materialized.
+	MinRequests int
+}
+
+// ClusterConfig defines configuration for the coordination of the replicas of the server.
+type ClusterConfig struct {
+	// Peers are the gRPC addresses of the replicas of the server.
+	Peers []string
+
+	// SelfAddress is the gRPC address of this replica, as listed in Peers.
+	SelfAddress string
+
+	// SingleflightEnabled forwards every Check to the replica that owns it, so that identical
+	// Checks received concurrently by different replicas are resolved once.
+	SingleflightEnabled bool
+
+	// ForwardTimeout is the maximum duration of a Check forwarded to another replica, after which
+	// the Check is resolved locally.
+	ForwardTimeout time.Duration
+
+	// TLSEnabled makes the connections to the other replicas use TLS.
+	TLSEnabled bool
+
+	// DispatchEnabled dispatches the sub-problems of every Check to the replica that owns them, so
+	// that the cache of every replica specializes in the sub-problems it owns.
+	DispatchEnabled bool
+
+	// DispatchServiceEnabled serves the internal service that resolves the Checks dispatched by
+	// the other replicas, e.g. on the replicas of a dispatch tier. It is always served if
+	// DispatchEnabled is set.
+	DispatchServiceEnabled bool
+
+	// DispatchTier are the gRPC addresses of the replicas of a dedicated tier that resolves the
+	// Checks of this replica through its dispatch service.
+	DispatchTier []string
+
+	Gossip ClusterGossipConfig
+}
+
+// ClusterGossipConfig defines configuration for the discovery of the replicas of the server by gossip.
+type ClusterGossipConfig struct {
+	// BindAddress is the address on which this replica gossips with the other replicas. Gossip is
+	// disabled if it is empty.
+	BindAddress string
+
+	// Join are the gossip addresses of the replicas through which this replica joins the cluster.
+	Join []string
+}
+
+// PrioritySchedulingConfig defines configuration for the scheduling of the requests by priority
+// class when the server is saturated.
+type PrioritySchedulingConfig struct {
+	Enabled bool
+
+	// MaxInFlight is the number of requests handled at once. The other requests are queued, and
+	// the interactive ones are scheduled before the bulk ones.
+	MaxInFlight int
+
+	// MethodClasses sets the priority class of RPC methods, in the form <method>=<class>, where the
+	// class is interactive or bulk. The methods not listed are interactive. A request can set its
+	// class with the Openfga-Priority header.
+	MethodClasses []string
+
+	// StoreWeights sets the share of the stores among the queued requests of a class, in the form
+	// <store_id>=<weight>. The stores not listed have a weight of 1.
+	StoreWeights []string
+}
+
+// BatchCheckAdaptiveLimitsConfig defines configuration for the reduction of the BatchCheck limits
+// when the server is under load. The load is the one of the priority scheduler, i.e. the number of
+// requests in flight and queued relative to priorityScheduling.maxInFlight.
+type BatchCheckAdaptiveLimitsConfig struct {
+	Enabled bool
+
+	// LoadThreshold is the load above which MaxChecksPerBatchCheck and
+	// MaxConcurrentChecksPerBatchCheck are reduced. The BatchChecks with more checks than the
+	// reduced limit fail with a REDUCE_BATCH_SIZE error.
+	LoadThreshold float64
+
+	// MinRatio is the ratio of the limits when the scheduler is saturated. The limits decrease
+	// linearly from LoadThreshold.
+	MinRatio float64
+}
+
+// StreamedBatchCheckConfig defines configuration for the streamed BatchCheck service, which streams
+// the outcomes of the checks of a BatchCheck in the order in which they complete.
+type StreamedBatchCheckConfig struct {
+	Enabled bool
+}
+
+// BulkWriteConfig defines configuration for the bulk write service: its bidirectional streaming
+// Write for bulk loads, and its DeleteTuples for deletes by filter.
+type BulkWriteConfig struct {
+	Enabled bool
+
+	// Window is the number of chunks of a stream received ahead of the chunk being written.
+	Window int
+}
+
+// StorePurgeConfig defines configuration for the background jobs that delete the tuples of the
+// deleted stores in batches.
+type StorePurgeConfig struct {
+	// Enabled creates a purge job when a store is deleted. It requires the jobs service.
+	Enabled bool
+
+	// JobRetention is the duration for which the finished jobs are reported.
+	//
+	// Deprecated: JobRetention has no effect, the purge jobs are kept for 'jobs.retention'.
+	JobRetention time.Duration
+}
+
+// StoreEventsConfig defines configuration for the store events service, which reads the changelog
+// of a store including the changes of its authorization models, assertions and lifecycle.
+type StoreEventsConfig struct {
+	Enabled bool
+}
+
+// ListObjectsSubscriptionConfig defines configuration for the ListObjects subscription service,
+// which streams the objects added to and removed from the objects of a ListObjects as the writes
+// change them.
+type ListObjectsSubscriptionConfig struct {
+	Enabled bool
+
+	// PollInterval is the time between two reads of the changelog of the store of a subscription.
+	PollInterval time.Duration
+}
+
+// CacheHintsConfig defines configuration for the hints with which the clients can cache the Check
+// responses: their Cache-Control and invalidation keys headers, and the cache invalidation service,
+// which streams the invalidation keys of the writes to a store.
+type CacheHintsConfig struct {
+	Enabled bool
+
+	// MaxAge is the maximum max-age of the Check responses. Their max-age is derived from the rate
+	// of the writes to their store.
+	MaxAge time.Duration
+
+	// InvalidationPollInterval is the time between two reads of the changelog of the store of a
+	// cache invalidation stream.
+	InvalidationPollInterval time.Duration
+}
+
+// SessionTokensConfig defines configuration for the read-your-writes session tokens returned by
+// Write, with which the reads of the same store observe the write.
+type SessionTokensConfig struct {
+	Enabled bool
+
+	// CommitMargin is how much the time of the most recent change observed by the cache controller
+	// must exceed that of the change of a write for the write to be known to be observed. The
+	// changes are ordered by their ULIDs, which are generated before they are committed, so the
+	// margin must exceed the clock skew between the instances and the duration of the writes.
+	CommitMargin time.Duration
+}
+
+// AssertionCoverageConfig defines configuration for the assertion coverage service, which reports
+// the relations of a model, and the branches of their rewrites, exercised by its assertions.
+type AssertionCoverageConfig struct {
+	Enabled bool
+}
+
+// ModelGraphConfig defines configuration for the model graph endpoint, which renders an
+// authorization model as a DOT or Mermaid graph.
+type ModelGraphConfig struct {
+	Enabled bool
+}
+
+// CostEstimateConfig defines configuration for the cost estimate service, which estimates the
+// number of datastore queries and dispatches of a Check or a ListObjects without executing it.
+type CostEstimateConfig struct {
+	Enabled bool
+
+	// SampleSize is the maximum number of tuples of each type sampled for the cardinality of its
+	// relations.
+	SampleSize int
+}
+
+// WhatIfConfig defines configuration for the what-if service, which evaluates Checks as if a set
+// of tuple writes and deletes had been applied to a store.
+type WhatIfConfig struct {
+	Enabled bool
+}
+
+// ModelSimulationConfig defines configuration for the model simulation service, which reports the
+// checks whose outcome would change with a proposed authorization model.
+type ModelSimulationConfig struct {
+	Enabled bool
+}
+
+// AccessReviewConfig defines configuration for the background jobs that export all the users that
+// have a relation on all the objects of a type to downloadable artifacts, for access reviews.
+type AccessReviewConfig struct {
+	// Enabled enables the access_review jobs and the download of their artifacts. It requires the
+	// jobs service, whose retention applies to the artifacts too.
+	Enabled bool
+
+	// ArtifactDir is the directory the artifacts are written to. It defaults to a directory in the
+	// temporary directory of the OS.
+	ArtifactDir string
+
+	// JobRetention is the duration for which the finished jobs, and their artifacts, are kept.
+	//
+	// Deprecated: JobRetention has no effect, the access_review jobs and their artifacts are kept
+	// for 'jobs.retention'.
+	JobRetention time.Duration
+}
+
+// JobsConfig defines configuration for the background jobs, which run the long operations of the
+// stores, such as purges or the collection of the orphaned tuples, and persist their status in the
+// datastore so that any replica reports or cancels them.
+type JobsConfig struct {
+	Enabled bool
+
+	// Workers is the number of jobs that each replica runs concurrently.
+	Workers int
+
+	// QueueSize is the number of jobs that each replica queues before refusing to create more.
+	QueueSize int
+
+	// Retention is the duration for which the finished jobs are kept.
+	Retention time.Duration
+
+	// ScheduleInterval is the interval at which the replica that holds the lease of the scheduler
+	// creates the jobs of the due schedules.
+	ScheduleInterval time.Duration
+}
+
+// MultiRegionConfig defines configuration for the active-active deployments in several regions,
+// each writing to its replica of a datastore replicated in both directions. The tuple writes of
+// such deployments are resolved with last-writer-wins semantics, and the ULIDs of their changes
+// encode the region that wrote them. See docs/multi-region.md for its consistency implications.
+type MultiRegionConfig struct {
+	Enabled bool
+
+	// RegionID identifies the region of the deployment, between 1 and 255. It must be unique
+	// across the regions writing to the same datastore.
+	RegionID int
+}
+
+// WriteValidationHookConfig defines configuration for the external service that validates the
+// Writes against business rules, and can veto or annotate them.
+type WriteValidationHookConfig struct {
+	Enabled bool
+
+	// URL is the URL of the hook: an http or https URL to which the Writes are posted as JSON, or
+	// a grpc URL, e.g. "grpc://validator:50051", of a server of the WriteValidationService.
+	URL string
+
+	// Timeout bounds the calls to the hook.
+	Timeout time.Duration
+
+	// FailOpen applies the Writes when the hook fails or times out, instead of rejecting them.
+	FailOpen bool
+}
+
+// ContextEnrichmentHookConfig defines configuration for the external service that provides the
+// condition context attributes of the users and objects of the Check and ListObjects requests.
+type ContextEnrichmentHookConfig struct {
+	Enabled bool
+
+	// URL is the URL of the hook: an http or https URL to which the users and objects are posted
+	// as JSON, or a grpc URL, e.g. "grpc://attributes:50051", of a server of the
+	// ContextEnrichmentService.
+	URL string
+
+	// Timeout bounds the calls to the hook.
+	Timeout time.Duration
+
+	// CacheTTL is the duration for which the attributes are cached. 0 disables the cache.
+	CacheTTL time.Duration
+
+	// CacheMaxEntries bounds the number of users and objects whose attributes are cached.
+	CacheMaxEntries int64
+}
+
+// ConditionContextConfig defines configuration for the attributes injected into the condition
+// context of the Check, BatchCheck, ListObjects and ListUsers requests.
+type ConditionContextConfig struct {
+	// Headers are the HTTP headers, or gRPC metadata, whose values are injected into the condition
+	// context, in the form <header>=<name>, e.g. X-Client-Region=client_region. The values of the
+	// headers replace the ones of the requests.
+	Headers []string
+
+	// Claims are the allowed claims of the principals authenticated with OIDC whose values are
+	// injected into the condition context, in the form <claim>=<name>, e.g.
+	// department=caller_department, or <claim> when the parameter is named after the claim. The
+	// values of the claims replace the ones of the requests and of the headers.
+	Claims []string
+}
+
+// SLOConfig defines configuration for the tracking of the service level objectives of the RPC
+// methods.
+type SLOConfig struct {
+	Enabled bool
+
+	// Objectives are the service level objectives, in the form <method>=latency:<threshold>:<target>
+	// or <method>=availability:<target>, where the target is a percentage of the requests, e.g.
+	// Check=latency:50ms:99 or Check=availability:99.95.
+	Objectives []string
+
+	// Window is the rolling window of the error budgets of the objectives.
+	Window time.Duration
+}
+
 // TLSConfig defines configuration specific to Transport Layer Security (TLS) settings.
 type TLSConfig struct {
 	Enabled  bool
@@ -202,7 +999,7 @@ type TLSConfig struct {
 // AuthnConfig defines OpenFGA server configurations for authentication specific settings.
 type AuthnConfig struct {
 	// Method is the authentication method that should be enforced (e.g. 'none', 'preshared',
-	// 'oidc')
+	// 'oidc', or the name of a registered authentication provider)
 	Method                   string
 	*AuthnOIDCConfig         `mapstructure:"oidc"`
 	*AuthnPresharedKeyConfig `mapstructure:"preshared"`
@@ -242,6 +1039,19 @@ type TraceConfig struct {
 	SampleRatio        float64
 	ServiceName        string
 	ResourceAttributes string
+
+	// VerboseRequestsEnabled samples all the spans of the requests with the
+	// 'Openfga-Trace-Verbose: true' header, regardless of SampleRatio, so that a detailed trace of a
+	// single request can be captured on demand.
+	VerboseRequestsEnabled bool
+
+	// StoreSampleRatios overrides SampleRatio for the requests on specific stores. Each entry has
+	// the form <store_id>=<ratio>.
+	StoreSampleRatios []string
+
+	// MethodSampleRatios overrides SampleRatio for specific RPC methods. Each entry has the form
+	// <method>=<ratio>, e.g. Check=0.001. StoreSampleRatios take precedence.
+	MethodSampleRatios []string
 }
 
 type OTLPTraceConfig struct {
@@ -301,7 +1111,23 @@ type CheckQueryCache struct {
 
 // CheckCacheConfig defines configuration for a cache that is shared across Check requests.
 type CheckCacheConfig struct {
+	// Engine is the cache that holds the cached Check subproblems and iterators: 'memory', or the
+	// name of a cache registered with the caches package.
+	Engine string
+
 	Limit uint32
+
+	// DiskPath is the file the cached Check results are persisted to, so that they survive a
+	// restart. If empty, the cache is only kept in memory.
+	DiskPath string
+
+	// MaxBytes limits the cache by the memory held by its entries instead of by their number.
+	// If 0, the cache is limited to Limit entries.
+	MaxBytes uint64
+
+	// Shards is the number of shards of the memory cache, each with its own lock and an equal
+	// part of the limit. If 0, the cache has one shard per CPU.
+	Shards uint32
 }
 
 // IteratorCacheConfig defines configuration to cache storage iterator results.
@@ -321,7 +1147,79 @@ type SharedIteratorConfig struct {
 // there are recent tuple changes to specified store.
 type CacheControllerConfig struct {
 	Enabled bool
-	TTL     time.Duration
+	TTL     time.Duration
+}
+
+// CacheInvalidationListenerConfig defines configuration to invalidate the caches of the cache controller as
+// soon as the tuple changes are committed, by listening to the changes of the datastore.
+type CacheInvalidationListenerConfig struct {
+	Enabled bool
+
+	// PostgresPublication is the publication of the tuple table that the listener subscribes to with
+	// logical replication when the datastore engine is Postgres.
+	PostgresPublication string
+
+	// PollInterval is the interval at which the listener polls the changelog when the datastore
+	// engine cannot push its changes, i.e. MySQL.
+	PollInterval time.Duration
+}
+
+// UserIDEncryptionConfig defines configuration for the encryption of the IDs of the users of the
+// tuples at rest.
+type UserIDEncryptionConfig struct {
+	Enabled bool
+
+	// Key is the base64 encoded AES key of the encryption, 16, 24 or 32 bytes long. Changing it
+	// makes the tuples written with the previous key unreadable.
+	Key string `json:"-"` // private field, won't be logged
+
+	// Types are the types of the users whose IDs are encrypted, e.g. user. If empty, the IDs of
+	// the users of all the types are encrypted.
+	Types []string
+}
+
+// RedactionConfig defines configuration for the redaction of the IDs of the users and the objects
+// in the logs and the traces.
+type RedactionConfig struct {
+	// Mode is 'none', 'redact' to replace the IDs with 'redacted', or 'hash' to replace them with
+	// their HMAC, so that the same ID can still be correlated across the logs and the traces.
+	Mode string
+
+	// Key is the secret of the HMAC of the 'hash' mode.
+	Key string `json:"-"` // private field, won't be logged
+
+	// KeyRotationPeriod is the period of the HMAC keys derived from Key, so that the hashes of an
+	// ID can only be correlated within a period. Zero disables the rotation.
+	KeyRotationPeriod time.Duration
+}
+
+// SecretsConfig defines configuration for the secrets referenced by the configuration, e.g. a
+// datastore password of vault://secret/openfga#password, awssm://openfga/datastore#password or
+// file:///var/run/secrets/openfga/password. The datastore username and password, the preshared
+// keys and the TLS certificates and keys can reference secrets.
+type SecretsConfig struct {
+	// RefreshInterval is the interval at which the referenced secrets are fetched again, so that
+	// their rotation is applied without a restart: the new connections of the datastore use the
+	// rotated password, the rotated preshared keys are accepted, and the rotated TLS certificates
+	// are served.
+	RefreshInterval time.Duration
+}
+
+// AuditConfig defines configuration for the audit and access events of the API requests.
+type AuditConfig struct {
+	// Enabled emits an event for each API request as an OTLP log record, with the trace of the
+	// request and the resource attributes of the traces, e.g. trace.serviceName.
+	Enabled bool
+
+	// OTLP is the collector whose logs service the events are exported to.
+	OTLP OTLPTraceConfig `mapstructure:"otlp"`
+
+	// QueueSize is the number of events that can be queued to be exported, after which the events
+	// are dropped.
+	QueueSize int
+
+	// FlushInterval is the maximum interval between the exports of the queued events.
+	FlushInterval time.Duration
 }
 
 // DispatchThrottlingConfig defines configurations for dispatch throttling.
@@ -339,6 +1237,59 @@ type DatastoreThrottleConfig struct {
 	Duration  time.Duration
 }
 
+// StoreBulkheadConfig defines configuration for bounding the number of requests of every store
+// resolved at once.
+type StoreBulkheadConfig struct {
+	// Limit is the number of requests of a store resolved at once. 0 means no limit.
+	Limit int
+
+	// StoreLimits overrides Limit for specific stores. Each entry has the form <store_id>=<limit>.
+	StoreLimits []string
+}
+
+// CheckCoalescingConfig defines configuration for merging the identical Checks received within a
+// window into one resolution.
+type CheckCoalescingConfig struct {
+	Enabled bool
+
+	// Window is the time the first of identical Checks waits for the others before it is resolved.
+	Window time.Duration
+}
+
+// WriteThrottlingConfig defines configuration for limiting the write throughput of every store
+// with token buckets.
+type WriteThrottlingConfig struct {
+	// TuplesPerSecond is the number of tuples a store can write per second. 0 means no limit.
+	TuplesPerSecond float64
+
+	// ChangesPerSecond is the number of changes, i.e. of tuples written and deleted, a store can
+	// make per second. 0 means no limit.
+	ChangesPerSecond float64
+
+	// StoreTuplesPerSecond overrides TuplesPerSecond for specific stores. Each entry has the form
+	// <store_id>=<rate>.
+	StoreTuplesPerSecond []string
+
+	// StoreChangesPerSecond overrides ChangesPerSecond for specific stores. Each entry has the
+	// form <store_id>=<rate>.
+	StoreChangesPerSecond []string
+}
+
+// WriteSchedulingConfig defines configuration for the weighted fair scheduling of the Writes
+// between the stores when the datastore is write-saturated.
+type WriteSchedulingConfig struct {
+	Enabled bool
+
+	// MaxInFlight is the number of Writes sent to the datastore at once. The other Writes are
+	// queued, and the stores are scheduled in proportion to their weight and to the number of
+	// tuples their Writes change.
+	MaxInFlight int
+
+	// StoreWeights sets the share of the stores among the queued Writes, in the form
+	// <store_id>=<weight>. The stores not listed have a weight of 1.
+	StoreWeights []string
+}
+
 // AccessControlConfig is the configuration for the access control feature.
 type AccessControlConfig struct {
 	Enabled bool
@@ -368,6 +1319,15 @@ type Config struct {
 	// algorithm is enabled.
 	ListObjectsPipelineEnabled bool
 
+	// StreamedListObjectsMaxInFlightMessages defines the maximum number of resolved objects that
+	// can be buffered before they are sent to a StreamedListObjects client. This protects the server
+	// from slow consumers.
+	StreamedListObjectsMaxInFlightMessages uint32
+
+	// StreamedListObjectsMessagesPerSecond defines the maximum rate at which objects are sent on
+	// each StreamedListObjects stream. If 0, the rate is not limited.
+	StreamedListObjectsMessagesPerSecond uint32
+
 	// ListUsersDeadline defines the maximum amount of time to accumulate ListUsers results
 	// before the server will respond. This is to protect the server from misuse of the
 	// ListUsers endpoints. It cannot be larger than the configured server's request timeout (RequestTimeout or HTTPConfig.UpstreamTimeout).
@@ -389,6 +1349,15 @@ type Config struct {
 	// that can be passed in each BatchCheck request.
 	MaxChecksPerBatchCheck uint32
 
+	// MaxContextualTuples defines the maximum number of contextual tuples per request
+	// (or per BatchCheck item) for the Check, BatchCheck, Expand, ListObjects and ListUsers endpoints.
+	MaxContextualTuples uint32
+
+	// ContextualTuplesValidationMode defines how contextual tuples that reference types not defined
+	// in the authorization model are handled. In 'strict' mode the request is rejected, in 'lenient'
+	// mode those contextual tuples are ignored.
+	ContextualTuplesValidationMode string
+
 	// MaxConcurrentChecksPerBatchCheck defines the maximum number of checks
 	// that can be run in simultaneously
 	MaxConcurrentChecksPerBatchCheck uint32
@@ -416,6 +1385,10 @@ type Config struct {
 	// MaxConditionEvaluationCost defines the maximum cost for CEL condition evaluation before a request returns an error
 	MaxConditionEvaluationCost uint64
 
+	// ConditionExtensions is a list of the optional CEL libraries to make available to the
+	// conditions, e.g. "strings" or "math".
+	ConditionExtensions []string
+
 	// ChangelogHorizonOffset is an offset in minutes from the current time. Changes that occur
 	// after this offset will not be included in the response of ReadChanges.
 	ChangelogHorizonOffset int
@@ -438,6 +1411,11 @@ type Config struct {
 	// request timeout will be prioritized
 	RequestTimeout time.Duration
 
+	// MethodTimeouts overrides RequestTimeout for specific RPC methods. Each entry has the form
+	// <method>=<default>:<max>, where the default is the timeout of the requests without a deadline
+	// and the max bounds the deadline set by the clients.
+	MethodTimeouts []string
+
 	// ShutdownTimeout configures how long the server waits for a graceful shutdown.
 	ShutdownTimeout time.Duration
 
@@ -448,7 +1426,38 @@ type Config struct {
 	Datastore                     DatastoreConfig
 	GRPC                          GRPCConfig
 	HTTP                          HTTPConfig
-	Authzen                       AuthzenConfig `mapstructure:"authzen"`
+	Authzen                       AuthzenConfig   `mapstructure:"authzen"`
+	OPABundle                     OPABundleConfig `mapstructure:"opaBundle"`
+	ChangelogRetention            ChangelogRetentionConfig
+	OrphanedTuples                OrphanedTuplesConfig
+	ConsistencyVerifier           ConsistencyVerifierConfig
+	ShadowCheck                   ShadowCheckConfig
+	RequestRecording              RequestRecordingConfig
+	HotPaths                      HotPathsConfig
+	MaterializedPermissions       MaterializedPermissionsConfig
+	MaterializedObjectLists       MaterializedObjectListsConfig
+	Cluster                       ClusterConfig
+	PriorityScheduling            PrioritySchedulingConfig
+	BatchCheckAdaptiveLimits      BatchCheckAdaptiveLimitsConfig
+	StreamedBatchCheck            StreamedBatchCheckConfig
+	BulkWrite                     BulkWriteConfig
+	StorePurge                    StorePurgeConfig
+	StoreEvents                   StoreEventsConfig
+	ListObjectsSubscription       ListObjectsSubscriptionConfig
+	CacheHints                    CacheHintsConfig
+	SessionTokens                 SessionTokensConfig
+	AssertionCoverage             AssertionCoverageConfig
+	ModelGraph                    ModelGraphConfig
+	CostEstimate                  CostEstimateConfig
+	WhatIf                        WhatIfConfig
+	ModelSimulation               ModelSimulationConfig
+	AccessReview                  AccessReviewConfig
+	Jobs                          JobsConfig
+	MultiRegion                   MultiRegionConfig
+	WriteValidationHook           WriteValidationHookConfig
+	ContextEnrichmentHook         ContextEnrichmentHookConfig
+	ConditionContext              ConditionContextConfig
+	SLO                           SLOConfig
 	Authn                         AuthnConfig
 	Log                           LogConfig
 	Trace                         TraceConfig
@@ -459,14 +1468,24 @@ type Config struct {
 	CheckIteratorCache            IteratorCacheConfig
 	CheckQueryCache               CheckQueryCache
 	CacheController               CacheControllerConfig
+	CacheInvalidationListener     CacheInvalidationListenerConfig
+	UserIDEncryption              UserIDEncryptionConfig
+	Redaction                     RedactionConfig
+	Secrets                       SecretsConfig
+	Audit                         AuditConfig
 	CacheTTLJitterPercentage      uint32
 	CheckDispatchThrottling       DispatchThrottlingConfig
 	ListObjectsDispatchThrottling DispatchThrottlingConfig
 	ListUsersDispatchThrottling   DispatchThrottlingConfig
 	CheckDatastoreThrottle        DatastoreThrottleConfig
+	CheckStoreBulkhead            StoreBulkheadConfig
+	CheckCoalescing               CheckCoalescingConfig
+	WriteThrottling               WriteThrottlingConfig
+	WriteScheduling               WriteSchedulingConfig
 	ListObjectsDatastoreThrottle  DatastoreThrottleConfig
 	ListUsersDatastoreThrottle    DatastoreThrottleConfig
 	ListObjectsIteratorCache      IteratorCacheConfig
+	ListObjectsQueryCache         IteratorCacheConfig
 	SharedIterator                SharedIteratorConfig
 	Planner                       PlannerConfig
 
@@ -490,6 +1509,137 @@ func (cfg *Config) VerifyServerSettings() error {
 		return fmt.Errorf("config 'grpc.maxRecvMsgBytes' must be greater than 0")
 	}
 
+	if cfg.GRPC.MaxSendMsgBytes <= 0 {
+		return fmt.Errorf("config 'grpc.maxSendMsgBytes' must be greater than 0")
+	}
+
+	if cfg.HTTP.MaxRequestBodyBytes < 0 {
+		return fmt.Errorf("config 'http.maxRequestBodyBytes' must be non-negative")
+	}
+
+	if cfg.HTTP.CORSMaxAge < 0 {
+		return fmt.Errorf("config 'http.corsMaxAge' must be non-negative")
+	}
+
+	// the max age is sent in seconds, so a shorter one, e.g. a bare number of nanoseconds, would
+	// silently be sent as 0
+	if cfg.HTTP.CORSMaxAge > 0 && cfg.HTTP.CORSMaxAge < time.Second {
+		return fmt.Errorf("config 'http.corsMaxAge' must be 0 or a duration of at least 1s, e.g. 10m, got %s", cfg.HTTP.CORSMaxAge)
+	}
+
+	if err := cfg.verifyCompressionConfig(); err != nil {
+		return err
+	}
+
+	if err := cfg.verifyGRPCKeepaliveConfig(); err != nil {
+		return err
+	}
+
+	if cfg.OPABundle.Enabled && cfg.OPABundle.MaxTuples < 0 {
+		return errors.New("config 'opaBundle.maxTuples' must be non-negative")
+	}
+	if cfg.OPABundle.Enabled && cfg.OPABundle.MaxDeltaChanges <= 0 {
+		return errors.New("config 'opaBundle.maxDeltaChanges' must be greater than 0")
+	}
+
+	if err := cfg.verifyChangelogRetentionConfig(); err != nil {
+		return err
+	}
+
+	if cfg.OrphanedTuples.CollectorEnabled && cfg.OrphanedTuples.CollectorInterval <= 0 {
+		return errors.New("config 'orphanedTuples.collectorInterval' must be greater than 0")
+	}
+
+	if cfg.ShadowCheck.Timeout <= 0 {
+		return errors.New("config 'shadowCheck.timeout' must be greater than 0")
+	}
+	if cfg.ShadowCheck.SamplePercentage < 0 || cfg.ShadowCheck.SamplePercentage > 100 {
+		return errors.New("config 'shadowCheck.samplePercentage' must be between 0 and 100")
+	}
+
+	if cfg.ConsistencyVerifier.Enabled {
+		if cfg.ConsistencyVerifier.Interval <= 0 {
+			return errors.New("config 'consistencyVerifier.interval' must be greater than 0")
+		}
+		if cfg.ConsistencyVerifier.SampleSize <= 0 {
+			return errors.New("config 'consistencyVerifier.sampleSize' must be greater than 0")
+		}
+	}
+
+	if cfg.RequestRecording.Enabled {
+		if cfg.RequestRecording.Path == "" {
+			return errors.New("config 'requestRecording.path' must be set")
+		}
+		if cfg.RequestRecording.SamplePercentage < 0 || cfg.RequestRecording.SamplePercentage > 100 {
+			return errors.New("config 'requestRecording.samplePercentage' must be between 0 and 100")
+		}
+	}
+
+	if cfg.HotPaths.Enabled {
+		if cfg.HotPaths.Interval <= 0 {
+			return errors.New("config 'hotPaths.interval' must be greater than 0")
+		}
+		if cfg.HotPaths.TopN <= 0 || cfg.HotPaths.MaxObjects <= 0 {
+			return errors.New("config 'hotPaths.topN' and 'hotPaths.maxObjects' must be greater than 0")
+		}
+	}
+
+	if cfg.MaterializedPermissions.Enabled {
+		if cfg.MaterializedPermissions.Interval <= 0 {
+			return errors.New("config 'materializedPermissions.interval' must be greater than 0")
+		}
+		if len(cfg.MaterializedPermissions.Relations) == 0 {
+			return errors.New("config 'materializedPermissions.relations' must not be empty")
+		}
+	}
+
+	if cfg.ListObjectsSubscription.Enabled && cfg.ListObjectsSubscription.PollInterval <= 0 {
+		return errors.New("config 'listObjectsSubscription.pollInterval' must be greater than 0")
+	}
+
+	if cfg.CacheHints.Enabled {
+		if cfg.CacheHints.MaxAge < 0 {
+			return errors.New("config 'cacheHints.maxAge' must be non-negative")
+		}
+		if cfg.CacheHints.InvalidationPollInterval <= 0 {
+			return errors.New("config 'cacheHints.invalidationPollInterval' must be greater than 0")
+		}
+	}
+
+	if cfg.SessionTokens.Enabled && cfg.SessionTokens.CommitMargin < 0 {
+		return errors.New("config 'sessionTokens.commitMargin' must be non-negative")
+	}
+
+	if cfg.MaterializedObjectLists.Enabled {
+		if cfg.MaterializedObjectLists.Interval <= 0 {
+			return errors.New("config 'materializedObjectLists.interval' must be greater than 0")
+		}
+		if cfg.MaterializedObjectLists.TopN <= 0 {
+			return errors.New("config 'materializedObjectLists.topN' must be greater than 0")
+		}
+	}
+
+	if cfg.CostEstimate.Enabled && cfg.CostEstimate.SampleSize <= 0 {
+		return errors.New("config 'costEstimate.sampleSize' must be greater than 0")
+	}
+
+	if cfg.MultiRegion.Enabled && (cfg.MultiRegion.RegionID < 1 || cfg.MultiRegion.RegionID > 255) {
+		return errors.New("config 'multiRegion.regionId' must be between 1 and 255")
+	}
+
+	if cfg.Cluster.SingleflightEnabled || cfg.Cluster.DispatchEnabled {
+		if cfg.Cluster.SelfAddress == "" {
+			return errors.New("config 'cluster.selfAddress' must be set")
+		}
+		if cfg.Cluster.ForwardTimeout <= 0 {
+			return errors.New("config 'cluster.forwardTimeout' must be greater than 0")
+		}
+	}
+
+	if len(cfg.Cluster.DispatchTier) > 0 && cfg.Cluster.ForwardTimeout <= 0 {
+		return errors.New("config 'cluster.forwardTimeout' must be greater than 0")
+	}
+
 	if cfg.MaxConcurrentReadsForListUsers == 0 {
 		return fmt.Errorf("config 'maxConcurrentReadsForListUsers' cannot be 0")
 	}
@@ -524,6 +1674,20 @@ func (cfg *Config) VerifyServerSettings() error {
 		return err
 	}
 
+	if cfg.MaxContextualTuples == 0 {
+		return errors.New("config 'maxContextualTuples' cannot be 0")
+	}
+
+	if cfg.ContextualTuplesValidationMode != ContextualTuplesValidationModeStrict &&
+		cfg.ContextualTuplesValidationMode != ContextualTuplesValidationModeLenient {
+		return fmt.Errorf("config 'contextualTuplesValidationMode' must be one of '%s' or '%s'",
+			ContextualTuplesValidationModeStrict, ContextualTuplesValidationModeLenient)
+	}
+
+	if cfg.StreamedListObjectsMaxInFlightMessages == 0 {
+		return errors.New("config 'streamedListObjectsMaxInFlightMessages' cannot be 0")
+	}
+
 	if cfg.ListObjectsDeadline < 0 {
 		return errors.New("listObjectsDeadline must be non-negative time duration")
 	}
@@ -544,6 +1708,32 @@ func (cfg *Config) VerifyServerSettings() error {
 		return errors.New("datastore MinOpenConns must not be less than datastore MinIdleConns")
 	}
 
+	if cfg.Datastore.IndexAdvisor.Enabled && cfg.Datastore.IndexAdvisor.SlowQueryThreshold <= 0 {
+		return errors.New("datastore indexAdvisor slowQueryThreshold must be greater than zero")
+	}
+
+	if cfg.Datastore.ReverseUserIndex.Enabled {
+		switch cfg.Datastore.Engine {
+		case "memory", "postgres", "mysql":
+		default:
+			return fmt.Errorf("datastore reverseUserIndex is only available with the memory, postgres and mysql engines, got '%s'", cfg.Datastore.Engine)
+		}
+	}
+
+	switch cfg.Datastore.IAMAuth.Method {
+	case "":
+	case "aws_rds", "gcp_cloudsql":
+		if cfg.Datastore.Engine != "postgres" && cfg.Datastore.Engine != "mysql" {
+			return errors.New("datastore iamAuth is only available with the postgres and mysql engines")
+		}
+	default:
+		return fmt.Errorf("datastore iamAuth method must be one of 'aws_rds' or 'gcp_cloudsql', got '%s'", cfg.Datastore.IAMAuth.Method)
+	}
+
+	if cfg.Datastore.LatestModelCacheTTL < 0 {
+		return errors.New("datastore latestModelCacheTTL must not be negative")
+	}
+
 	return nil
 }
 
@@ -602,6 +1792,107 @@ func (cfg *Config) VerifyBinarySettings() error {
 		return errors.New("requestTimeout must be a non-negative time duration")
 	}
 
+	if _, err := middleware.ParseMethodTimeouts(cfg.MethodTimeouts); err != nil {
+		return fmt.Errorf("config 'methodTimeouts': %w", err)
+	}
+
+	if cfg.PriorityScheduling.Enabled {
+		if cfg.PriorityScheduling.MaxInFlight <= 0 {
+			return errors.New("config 'priorityScheduling.maxInFlight' must be greater than 0")
+		}
+		if _, err := priority.ParseMethodClasses(cfg.PriorityScheduling.MethodClasses); err != nil {
+			return fmt.Errorf("config 'priorityScheduling.methodClasses': %w", err)
+		}
+		if _, err := priority.ParseStoreWeights(cfg.PriorityScheduling.StoreWeights); err != nil {
+			return fmt.Errorf("config 'priorityScheduling.storeWeights': %w", err)
+		}
+	}
+
+	if cfg.WriteScheduling.Enabled {
+		if cfg.WriteScheduling.MaxInFlight <= 0 {
+			return errors.New("config 'writeScheduling.maxInFlight' must be greater than 0")
+		}
+		if _, err := priority.ParseStoreWeights(cfg.WriteScheduling.StoreWeights); err != nil {
+			return fmt.Errorf("config 'writeScheduling.storeWeights': %w", err)
+		}
+	}
+
+	if cfg.BatchCheckAdaptiveLimits.Enabled {
+		if !cfg.PriorityScheduling.Enabled {
+			return errors.New("config 'batchCheckAdaptiveLimits.enabled' requires 'priorityScheduling.enabled'")
+		}
+		if cfg.BatchCheckAdaptiveLimits.LoadThreshold < 0 || cfg.BatchCheckAdaptiveLimits.LoadThreshold >= 1 {
+			return errors.New("config 'batchCheckAdaptiveLimits.loadThreshold' must be at least 0 and less than 1")
+		}
+		if cfg.BatchCheckAdaptiveLimits.MinRatio <= 0 || cfg.BatchCheckAdaptiveLimits.MinRatio > 1 {
+			return errors.New("config 'batchCheckAdaptiveLimits.minRatio' must be greater than 0 and at most 1")
+		}
+	}
+
+	if cfg.BulkWrite.Enabled && cfg.BulkWrite.Window <= 0 {
+		return errors.New("config 'bulkWrite.window' must be greater than 0")
+	}
+
+	if cfg.StorePurge.Enabled && !cfg.Jobs.Enabled {
+		return errors.New("config 'storePurge.enabled' requires 'jobs.enabled'")
+	}
+
+	if cfg.AccessReview.Enabled && !cfg.Jobs.Enabled {
+		return errors.New("config 'accessReview.enabled' requires 'jobs.enabled'")
+	}
+
+	if cfg.Jobs.Enabled {
+		if cfg.Jobs.Workers <= 0 {
+			return errors.New("config 'jobs.workers' must be greater than 0")
+		}
+		if cfg.Jobs.QueueSize <= 0 {
+			return errors.New("config 'jobs.queueSize' must be greater than 0")
+		}
+		if cfg.Jobs.Retention <= 0 {
+			return errors.New("config 'jobs.retention' must be greater than 0")
+		}
+		if cfg.Jobs.ScheduleInterval <= 0 {
+			return errors.New("config 'jobs.scheduleInterval' must be greater than 0")
+		}
+	}
+
+	if cfg.WriteValidationHook.Enabled {
+		if cfg.WriteValidationHook.URL == "" {
+			return errors.New("config 'writeValidationHook.url' is required when the write validation hook is enabled")
+		}
+		if cfg.WriteValidationHook.Timeout <= 0 {
+			return errors.New("config 'writeValidationHook.timeout' must be greater than 0")
+		}
+	}
+
+	if cfg.ContextEnrichmentHook.Enabled {
+		if cfg.ContextEnrichmentHook.URL == "" {
+			return errors.New("config 'contextEnrichmentHook.url' is required when the context enrichment hook is enabled")
+		}
+		if cfg.ContextEnrichmentHook.Timeout <= 0 {
+			return errors.New("config 'contextEnrichmentHook.timeout' must be greater than 0")
+		}
+		if cfg.ContextEnrichmentHook.CacheTTL < 0 {
+			return errors.New("config 'contextEnrichmentHook.cacheTTL' must be greater than or equal to 0")
+		}
+		if cfg.ContextEnrichmentHook.CacheTTL > 0 && cfg.ContextEnrichmentHook.CacheMaxEntries <= 0 {
+			return errors.New("config 'contextEnrichmentHook.cacheMaxEntries' must be greater than 0")
+		}
+	}
+
+	if len(cfg.ConditionContext.Claims) > 0 && cfg.Authn.Method != "oidc" {
+		return errors.New("config 'conditionContext.claims' requires 'authn.method' to be 'oidc'")
+	}
+
+	if cfg.SLO.Enabled {
+		if !cfg.Metrics.Enabled {
+			return errors.New("config 'slo.enabled' requires 'metrics.enabled'")
+		}
+		if cfg.SLO.Window <= 0 {
+			return errors.New("config 'slo.window' must be greater than 0")
+		}
+	}
+
 	if cfg.RequestTimeout == 0 && cfg.HTTP.Enabled && cfg.HTTP.UpstreamTimeout < 0 {
 		return errors.New("http.upstreamTimeout must be a non-negative time duration")
 	}
@@ -610,6 +1901,10 @@ func (cfg *Config) VerifyBinarySettings() error {
 		return errors.New("shutdownTimeout must be greater than 0")
 	}
 
+	if cfg.CheckCache.DiskPath != "" && cfg.CheckCache.Engine != DefaultCheckCacheEngine {
+		return fmt.Errorf("config 'checkCache.diskPath' requires 'checkCache.engine' %s", DefaultCheckCacheEngine)
+	}
+
 	if viper.IsSet("cache.limit") && !viper.IsSet("checkCache.limit") {
 		fmt.Println("WARNING: flag `check-query-cache-limit` is deprecated. Please set --check-cache-limit instead.")
 	}
@@ -618,12 +1913,17 @@ func (cfg *Config) VerifyBinarySettings() error {
 }
 
 // DefaultContextTimeout returns the runtime DefaultContextTimeout.
-// If requestTimeout > 0, we should let the middleware take care of the timeout and the
-// runtime.DefaultContextTimeout is used as last resort.
+// If requestTimeout or methodTimeouts are set, we should let the middleware take care of the
+// timeout and the runtime.DefaultContextTimeout is used as last resort.
 // Otherwise, use the http upstream timeout if http is enabled.
 func DefaultContextTimeout(config *Config) time.Duration {
-	if config.RequestTimeout > 0 {
-		return config.RequestTimeout + additionalUpstreamTimeout
+	timeout := config.RequestTimeout
+	methodTimeouts, _ := middleware.ParseMethodTimeouts(config.MethodTimeouts)
+	for _, methodTimeout := range methodTimeouts {
+		timeout = max(timeout, methodTimeout.Default, methodTimeout.Max)
+	}
+	if timeout > 0 {
+		return timeout + additionalUpstreamTimeout
 	}
 	if config.HTTP.Enabled && config.HTTP.UpstreamTimeout > 0 {
 		return config.HTTP.UpstreamTimeout
@@ -682,6 +1982,18 @@ func (cfg *Config) VerifyDatastoreThrottlesConfig() error {
 	if cfg.ListUsersDatastoreThrottle.Threshold > 0 && cfg.ListUsersDatastoreThrottle.Duration <= 0 {
 		return errors.New("'listUsersDatastoreThrottler.duration' must be greater than zero if threshold > 0")
 	}
+	if cfg.CheckStoreBulkhead.Limit < 0 {
+		return errors.New("'checkStoreBulkhead.limit' must be non-negative")
+	}
+	if cfg.CheckCoalescing.Enabled && cfg.CheckCoalescing.Window <= 0 {
+		return errors.New("'checkCoalescing.window' must be greater than zero if enabled")
+	}
+	if cfg.WriteThrottling.TuplesPerSecond < 0 {
+		return errors.New("'writeThrottling.tuplesPerSecond' must be non-negative")
+	}
+	if cfg.WriteThrottling.ChangesPerSecond < 0 {
+		return errors.New("'writeThrottling.changesPerSecond' must be non-negative")
+	}
 	return nil
 }
 
@@ -740,12 +2052,117 @@ func (cfg *Config) verifyCacheConfig() error {
 			return errors.New("'listObjectsIteratorCache.maxResults' must be greater than zero")
 		}
 	}
+	if cfg.ListObjectsQueryCache.Enabled {
+		if cfg.ListObjectsQueryCache.TTL <= 0 {
+			return errors.New("'listObjectsQueryCache.ttl' must be greater than zero")
+		}
+		if cfg.ListObjectsQueryCache.MaxResults <= 0 {
+			return errors.New("'listObjectsQueryCache.maxResults' must be greater than zero")
+		}
+	}
 	if cfg.CacheController.Enabled && cfg.CacheController.TTL <= 0 {
 		return errors.New("'cacheController.ttl' must be greater than zero")
 	}
+	if cfg.CacheInvalidationListener.Enabled {
+		if !cfg.CacheController.Enabled || !(cfg.CheckQueryCache.Enabled || cfg.CheckIteratorCache.Enabled || cfg.ListObjectsIteratorCache.Enabled || cfg.ListObjectsQueryCache.Enabled) {
+			return errors.New("'cacheInvalidationListener.enabled' requires 'cacheController.enabled' and a check query, check iterator, list objects iterator or list objects query cache")
+		}
+		if cfg.CacheInvalidationListener.PostgresPublication == "" {
+			return errors.New("'cacheInvalidationListener.postgresPublication' must be set")
+		}
+		if cfg.CacheInvalidationListener.PollInterval <= 0 {
+			return errors.New("'cacheInvalidationListener.pollInterval' must be greater than zero")
+		}
+	}
 	if cfg.CacheTTLJitterPercentage > 100 {
 		return errors.New("'cacheTTLJitterPercentage' must be between 0 and 100")
 	}
+	if cfg.Datastore.TransactionPooling.Enabled && cfg.CacheInvalidationListener.Enabled && cfg.Datastore.Engine == "postgres" {
+		// the listener opens a replication connection to the datastore, which PgBouncer doesn't pool
+		return errors.New("'datastore.transactionPooling.enabled' can't be combined with 'cacheInvalidationListener.enabled' with the postgres engine")
+	}
+	if cfg.UserIDEncryption.Enabled && cfg.CacheInvalidationListener.Enabled {
+		// the listener reads the encrypted users of the changes from the datastore directly
+		return errors.New("'userIDEncryption.enabled' can't be combined with 'cacheInvalidationListener.enabled'")
+	}
+	if _, err := redaction.ParseMode(cfg.Redaction.Mode); err != nil {
+		return fmt.Errorf("config 'redaction.mode': %w", err)
+	}
+	if cfg.Redaction.Mode == string(redaction.ModeHash) && cfg.Redaction.Key == "" {
+		return errors.New("config 'redaction.key' is required if 'redaction.mode' is 'hash'")
+	}
+	if cfg.Redaction.KeyRotationPeriod < 0 {
+		return errors.New("config 'redaction.keyRotationPeriod' must be non-negative")
+	}
+	if cfg.Secrets.RefreshInterval <= 0 {
+		return errors.New("config 'secrets.refreshInterval' must be greater than zero")
+	}
+	if cfg.Audit.Enabled {
+		if cfg.Audit.OTLP.Endpoint == "" {
+			return errors.New("config 'audit.otlp.endpoint' must be set")
+		}
+		if cfg.Audit.QueueSize <= 0 {
+			return errors.New("config 'audit.queueSize' must be greater than zero")
+		}
+		if cfg.Audit.FlushInterval <= 0 {
+			return errors.New("config 'audit.flushInterval' must be greater than zero")
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) verifyCompressionConfig() error {
+	if cfg.GRPC.Compression.Enabled {
+		if err := compression.Validate(cfg.GRPC.Compression.Algorithms); err != nil {
+			return fmt.Errorf("config 'grpc.compression.algorithms': %w", err)
+		}
+	}
+	if cfg.HTTP.Compression.Enabled {
+		if err := compression.Validate(cfg.HTTP.Compression.Algorithms); err != nil {
+			return fmt.Errorf("config 'http.compression.algorithms': %w", err)
+		}
+		if cfg.HTTP.Compression.MinSizeBytes < 0 {
+			return errors.New("config 'http.compression.minSizeBytes' must be non-negative")
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) verifyGRPCKeepaliveConfig() error {
+	if cfg.GRPC.Keepalive.Time <= 0 {
+		return errors.New("config 'grpc.keepalive.time' must be greater than 0")
+	}
+	if cfg.GRPC.Keepalive.Timeout <= 0 {
+		return errors.New("config 'grpc.keepalive.timeout' must be greater than 0")
+	}
+	if cfg.GRPC.Keepalive.MinTime < 0 {
+		return errors.New("config 'grpc.keepalive.minTime' must be non-negative")
+	}
+	if cfg.GRPC.MaxConnectionIdle < 0 {
+		return errors.New("config 'grpc.maxConnectionIdle' must be non-negative")
+	}
+	if cfg.GRPC.MaxConnectionAge < 0 {
+		return errors.New("config 'grpc.maxConnectionAge' must be non-negative")
+	}
+	if cfg.GRPC.MaxConnectionAgeGrace < 0 {
+		return errors.New("config 'grpc.maxConnectionAgeGrace' must be non-negative")
+	}
+	return nil
+}
+
+func (cfg *Config) verifyChangelogRetentionConfig() error {
+	if !cfg.ChangelogRetention.Enabled {
+		return nil
+	}
+	if cfg.ChangelogRetention.Interval <= 0 {
+		return errors.New("config 'changelogRetention.interval' must be greater than 0")
+	}
+	if cfg.ChangelogRetention.MaxAge < 0 {
+		return errors.New("config 'changelogRetention.maxAge' must be non-negative")
+	}
+	if cfg.ChangelogRetention.CursorLease < 0 {
+		return errors.New("config 'changelogRetention.cursorLease' must be non-negative")
+	}
 	return nil
 }
 
@@ -807,6 +2224,8 @@ func MaxConditionEvaluationCost() uint64 {
 func DefaultConfig() *Config {
 	return &Config{
 		MaxTuplesPerWrite:                         DefaultMaxTuplesPerWrite,
+		MaxContextualTuples:                       DefaultMaxContextualTuples,
+		ContextualTuplesValidationMode:            DefaultContextualTuplesValidationMode,
 		MaxTypesPerAuthorizationModel:             DefaultMaxTypesPerAuthorizationModel,
 		MaxAuthorizationModelSizeInBytes:          DefaultMaxAuthorizationModelSizeInBytes,
 		MaxChecksPerBatchCheck:                    DefaultMaxChecksPerBatchCheck,
@@ -815,6 +2234,7 @@ func DefaultConfig() *Config {
 		MaxConcurrentReadsForListObjects:          DefaultMaxConcurrentReadsForListObjects,
 		MaxConcurrentReadsForListUsers:            DefaultMaxConcurrentReadsForListUsers,
 		MaxConditionEvaluationCost:                DefaultMaxConditionEvaluationCost,
+		ConditionExtensions:                       []string{},
 		ChangelogHorizonOffset:                    DefaultChangelogHorizonOffset,
 		ResolveNodeLimit:                          DefaultResolveNodeLimit,
 		ResolveNodeBreadthLimit:                   DefaultResolveNodeBreadthLimit,
@@ -823,6 +2243,8 @@ func DefaultConfig() *Config {
 		ListObjectsDeadline:                       DefaultListObjectsDeadline,
 		ListObjectsMaxResults:                     DefaultListObjectsMaxResults,
 		ListObjectsPipelineEnabled:                DefaultListObjectsPipelineEnabled,
+		StreamedListObjectsMaxInFlightMessages:    DefaultStreamedListObjectsMaxInFlightMessages,
+		StreamedListObjectsMessagesPerSecond:      DefaultStreamedListObjectsMessagesPerSecond,
 		ListUsersMaxResults:                       DefaultListUsersMaxResults,
 		ListUsersDeadline:                         DefaultListUsersDeadline,
 		ReadChangesMaxPageSize:                    DefaultReadChangesMaxPageSize,
@@ -832,27 +2254,234 @@ func DefaultConfig() *Config {
 			Engine:                 "memory",
 			MaxCacheSize:           DefaultMaxAuthorizationModelCacheSize,
 			MaxTypesystemCacheSize: DefaultMaxTypesystemCacheSize,
+			LatestModelCacheTTL:    DefaultLatestModelCacheTTL,
+			LatestModelCacheEngine: DefaultLatestModelCacheEngine,
 			MinIdleConns:           0,
 			MaxIdleConns:           10,
 			MinOpenConns:           0,
 			MaxOpenConns:           30,
+			IndexAdvisor: DatastoreIndexAdvisorConfig{
+				Enabled:            DefaultDatastoreIndexAdvisorEnabled,
+				SlowQueryThreshold: DefaultDatastoreIndexAdvisorSlowQueryThreshold,
+			},
+			ReverseUserIndex: DatastoreReverseUserIndexConfig{
+				Enabled: DefaultDatastoreReverseUserIndexEnabled,
+			},
+			MaintenanceTuning: DatastoreMaintenanceTuningConfig{
+				Enabled: DefaultDatastoreMaintenanceTuningEnabled,
+			},
+			TransactionPooling: DatastoreTransactionPoolingConfig{
+				Enabled: DefaultDatastoreTransactionPoolingEnabled,
+			},
 		},
 		GRPC: GRPCConfig{
 			Addr:            "0.0.0.0:8081",
 			TLS:             &TLSConfig{Enabled: false},
 			MaxRecvMsgBytes: DefaultMaxRPCMessageSizeInBytes,
+			MaxSendMsgBytes: DefaultMaxRPCSendMessageSizeInBytes,
+			Compression: CompressionConfig{
+				Enabled:    DefaultCompressionEnabled,
+				Algorithms: []string{compression.Gzip, compression.Zstd},
+			},
+			Keepalive: GRPCKeepaliveConfig{
+				Time:                DefaultGRPCKeepaliveTime,
+				Timeout:             DefaultGRPCKeepaliveTimeout,
+				MinTime:             DefaultGRPCKeepaliveMinTime,
+				PermitWithoutStream: DefaultGRPCKeepalivePermitWithoutStream,
+			},
+			MaxConnectionIdle:     DefaultGRPCMaxConnectionIdle,
+			MaxConnectionAge:      DefaultGRPCMaxConnectionAge,
+			MaxConnectionAgeGrace: DefaultGRPCMaxConnectionAgeGrace,
+			MaxConcurrentStreams:  DefaultGRPCMaxConcurrentStreams,
 		},
 		HTTP: HTTPConfig{
-			Enabled:            true,
-			Addr:               "0.0.0.0:8080",
-			TLS:                &TLSConfig{Enabled: false},
-			UpstreamTimeout:    5 * time.Second,
-			CORSAllowedOrigins: []string{"*"},
-			CORSAllowedHeaders: []string{"*"},
+			Enabled:             true,
+			Addr:                "0.0.0.0:8080",
+			TLS:                 &TLSConfig{Enabled: false},
+			UpstreamTimeout:     5 * time.Second,
+			MaxRequestBodyBytes: DefaultHTTPMaxRequestBodyBytes,
+			CORSAllowedOrigins:  []string{"*"},
+			CORSAllowedHeaders:  []string{"*"},
+			CORSAllowedMethods: []string{
+				http.MethodGet, http.MethodPost,
+				http.MethodHead, http.MethodPatch, http.MethodDelete, http.MethodPut,
+			},
+			CORSExposedHeaders:   []string{},
+			CORSMaxAge:           DefaultHTTPCORSMaxAge,
+			CORSAllowCredentials: DefaultHTTPCORSAllowCredentials,
+			Compression: CompressionConfig{
+				Enabled:      DefaultCompressionEnabled,
+				Algorithms:   []string{compression.Gzip, compression.Zstd},
+				MinSizeBytes: DefaultCompressionMinSizeBytes,
+			},
+			ServerTimingEnabled: DefaultHTTPServerTimingEnabled,
 		},
 		Authzen: AuthzenConfig{
 			BaseURL: "",
 		},
+		OPABundle: OPABundleConfig{
+			Enabled:         DefaultOPABundleEnabled,
+			MaxTuples:       DefaultOPABundleMaxTuples,
+			MaxDeltaChanges: DefaultOPABundleMaxDeltaChanges,
+		},
+		ChangelogRetention: ChangelogRetentionConfig{
+			Enabled:       DefaultChangelogRetentionEnabled,
+			Interval:      DefaultChangelogRetentionInterval,
+			MaxAge:        DefaultChangelogRetentionMaxAge,
+			MaxEntries:    DefaultChangelogRetentionMaxEntries,
+			CursorLease:   DefaultChangelogRetentionCursorLease,
+			StorePolicies: []string{},
+		},
+		OrphanedTuples: OrphanedTuplesConfig{
+			EndpointEnabled:   DefaultOrphanedTuplesEndpointEnabled,
+			CollectorEnabled:  DefaultOrphanedTuplesCollectorEnabled,
+			CollectorInterval: DefaultOrphanedTuplesCollectorInterval,
+			CollectorDryRun:   DefaultOrphanedTuplesCollectorDryRun,
+		},
+		ConsistencyVerifier: ConsistencyVerifierConfig{
+			Enabled:    DefaultConsistencyVerifierEnabled,
+			Interval:   DefaultConsistencyVerifierInterval,
+			SampleSize: DefaultConsistencyVerifierSampleSize,
+		},
+		ShadowCheck: ShadowCheckConfig{
+			Timeout:          DefaultShadowCheckResolverTimeout,
+			SamplePercentage: DefaultShadowCheckResolverSamplePercentage,
+		},
+		RequestRecording: RequestRecordingConfig{
+			Enabled:          DefaultRequestRecordingEnabled,
+			SamplePercentage: DefaultRequestRecordingSamplePercentage,
+			Anonymize:        DefaultRequestRecordingAnonymize,
+		},
+		HotPaths: HotPathsConfig{
+			Enabled:    DefaultHotPathsEnabled,
+			Interval:   DefaultHotPathsInterval,
+			TopN:       DefaultHotPathsTopN,
+			MinChecks:  DefaultHotPathsMinChecks,
+			MaxObjects: DefaultHotPathsMaxObjects,
+		},
+		MaterializedPermissions: MaterializedPermissionsConfig{
+			Enabled:   DefaultMaterializedPermissionsEnabled,
+			Relations: []string{},
+			Interval:  DefaultMaterializedPermissionsInterval,
+		},
+		MaterializedObjectLists: MaterializedObjectListsConfig{
+			Enabled:     DefaultMaterializedObjectListsEnabled,
+			Interval:    DefaultMaterializedObjectListsInterval,
+			TopN:        DefaultMaterializedObjectListsTopN,
+			MinRequests: DefaultMaterializedObjectListsMinRequests,
+		},
+		Cluster: ClusterConfig{
+			Peers:                  []string{},
+			SingleflightEnabled:    DefaultClusterSingleflightEnabled,
+			ForwardTimeout:         DefaultClusterForwardTimeout,
+			TLSEnabled:             DefaultClusterTLSEnabled,
+			DispatchEnabled:        DefaultClusterDispatchEnabled,
+			DispatchServiceEnabled: DefaultClusterDispatchServiceEnabled,
+			DispatchTier:           []string{},
+			Gossip: ClusterGossipConfig{
+				Join: []string{},
+			},
+		},
+		PriorityScheduling: PrioritySchedulingConfig{
+			Enabled:     DefaultPrioritySchedulingEnabled,
+			MaxInFlight: DefaultPrioritySchedulingMaxInFlight,
+			MethodClasses: []string{
+				"ListObjects=bulk",
+				"StreamedListObjects=bulk",
+				"ListUsers=bulk",
+				"Read=bulk",
+				"ReadChanges=bulk",
+			},
+			StoreWeights: []string{},
+		},
+		BatchCheckAdaptiveLimits: BatchCheckAdaptiveLimitsConfig{
+			Enabled:       DefaultBatchCheckAdaptiveLimitsEnabled,
+			LoadThreshold: DefaultBatchCheckAdaptiveLimitsLoadThreshold,
+			MinRatio:      DefaultBatchCheckAdaptiveLimitsMinRatio,
+		},
+		StreamedBatchCheck: StreamedBatchCheckConfig{
+			Enabled: DefaultStreamedBatchCheckEnabled,
+		},
+		BulkWrite: BulkWriteConfig{
+			Enabled: DefaultBulkWriteEnabled,
+			Window:  DefaultBulkWriteWindow,
+		},
+		StorePurge: StorePurgeConfig{
+			Enabled:      DefaultStorePurgeEnabled,
+			JobRetention: DefaultStorePurgeJobRetention,
+		},
+		StoreEvents: StoreEventsConfig{
+			Enabled: DefaultStoreEventsEnabled,
+		},
+		ListObjectsSubscription: ListObjectsSubscriptionConfig{
+			Enabled:      DefaultListObjectsSubscriptionEnabled,
+			PollInterval: DefaultListObjectsSubscriptionPollInterval,
+		},
+		CacheHints: CacheHintsConfig{
+			Enabled:                  DefaultCacheHintsEnabled,
+			MaxAge:                   DefaultCacheHintsMaxAge,
+			InvalidationPollInterval: DefaultCacheHintsInvalidationPollInterval,
+		},
+		SessionTokens: SessionTokensConfig{
+			Enabled:      DefaultSessionTokensEnabled,
+			CommitMargin: DefaultSessionTokensCommitMargin,
+		},
+		AssertionCoverage: AssertionCoverageConfig{
+			Enabled: DefaultAssertionCoverageEnabled,
+		},
+		ModelGraph: ModelGraphConfig{
+			Enabled: DefaultModelGraphEnabled,
+		},
+		CostEstimate: CostEstimateConfig{
+			Enabled:    DefaultCostEstimateEnabled,
+			SampleSize: DefaultCostEstimateSampleSize,
+		},
+		WhatIf: WhatIfConfig{
+			Enabled: DefaultWhatIfEnabled,
+		},
+		ModelSimulation: ModelSimulationConfig{
+			Enabled: DefaultModelSimulationEnabled,
+		},
+		AccessReview: AccessReviewConfig{
+			Enabled:      DefaultAccessReviewEnabled,
+			ArtifactDir:  DefaultAccessReviewArtifactDir,
+			JobRetention: DefaultAccessReviewJobRetention,
+		},
+		Jobs: JobsConfig{
+			Enabled:   DefaultJobsEnabled,
+			Workers:   DefaultJobsWorkers,
+			QueueSize: DefaultJobsQueueSize,
+			Retention: DefaultJobsRetention,
+
+			ScheduleInterval: DefaultJobsScheduleInterval,
+		},
+		MultiRegion: MultiRegionConfig{
+			Enabled:  DefaultMultiRegionEnabled,
+			RegionID: DefaultMultiRegionRegionID,
+		},
+		WriteValidationHook: WriteValidationHookConfig{
+			Enabled:  DefaultWriteValidationHookEnabled,
+			Timeout:  DefaultWriteValidationHookTimeout,
+			FailOpen: DefaultWriteValidationHookFailOpen,
+		},
+		ContextEnrichmentHook: ContextEnrichmentHookConfig{
+			Enabled:         DefaultContextEnrichmentHookEnabled,
+			Timeout:         DefaultContextEnrichmentHookTimeout,
+			CacheTTL:        DefaultContextEnrichmentHookCacheTTL,
+			CacheMaxEntries: DefaultContextEnrichmentHookCacheMaxEntries,
+		},
+		ConditionContext: ConditionContextConfig{
+			Headers: []string{},
+			Claims:  []string{},
+		},
+		SLO: SLOConfig{
+			Enabled: DefaultSLOEnabled,
+			Objectives: []string{
+				"Check=latency:50ms:99",
+				"Check=availability:99.95",
+			},
+			Window: DefaultSLOWindow,
+		},
 		Authn: AuthnConfig{
 			Method:                  "none",
 			AuthnPresharedKeyConfig: &AuthnPresharedKeyConfig{},
@@ -871,9 +2500,12 @@ func DefaultConfig() *Config {
 					Enabled: false,
 				},
 			},
-			SampleRatio:        0.2,
-			ServiceName:        "openfga",
-			ResourceAttributes: "",
+			SampleRatio:            0.2,
+			ServiceName:            "openfga",
+			ResourceAttributes:     "",
+			VerboseRequestsEnabled: false,
+			StoreSampleRatios:      []string{},
+			MethodSampleRatios:     []string{},
 		},
 		Playground: PlaygroundConfig{
 			Enabled: false,
@@ -898,7 +2530,8 @@ func DefaultConfig() *Config {
 			TTL:     DefaultCheckQueryCacheTTL,
 		},
 		CheckCache: CheckCacheConfig{
-			Limit: DefaultCheckCacheLimit,
+			Engine: DefaultCheckCacheEngine,
+			Limit:  DefaultCheckCacheLimit,
 		},
 		SharedIterator: SharedIteratorConfig{
 			Enabled: DefaultSharedIteratorEnabled,
@@ -908,6 +2541,30 @@ func DefaultConfig() *Config {
 			Enabled: DefaultCacheControllerConfigEnabled,
 			TTL:     DefaultCacheControllerConfigTTL,
 		},
+		CacheInvalidationListener: CacheInvalidationListenerConfig{
+			Enabled:             DefaultCacheInvalidationListenerEnabled,
+			PostgresPublication: DefaultCacheInvalidationListenerPostgresPublication,
+			PollInterval:        DefaultCacheInvalidationListenerPollInterval,
+		},
+		UserIDEncryption: UserIDEncryptionConfig{
+			Enabled: DefaultUserIDEncryptionEnabled,
+			Types:   []string{"user"},
+		},
+		Redaction: RedactionConfig{
+			Mode:              DefaultRedactionMode,
+			KeyRotationPeriod: DefaultRedactionKeyRotationPeriod,
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: DefaultSecretsRefreshInterval,
+		},
+		Audit: AuditConfig{
+			Enabled: DefaultAuditEnabled,
+			OTLP: OTLPTraceConfig{
+				Endpoint: DefaultAuditOTLPEndpoint,
+			},
+			QueueSize:     DefaultAuditQueueSize,
+			FlushInterval: DefaultAuditFlushInterval,
+		},
 		CacheTTLJitterPercentage: DefaultCacheTTLJitterPercentage,
 		CheckDispatchThrottling: DispatchThrottlingConfig{
 			Enabled:      DefaultCheckDispatchThrottlingEnabled,
@@ -932,10 +2589,34 @@ func DefaultConfig() *Config {
 			MaxResults: DefaultListObjectsIteratorCacheMaxResults,
 			TTL:        DefaultListObjectsIteratorCacheTTL,
 		},
+		ListObjectsQueryCache: IteratorCacheConfig{
+			Enabled:    DefaultListObjectsQueryCacheEnabled,
+			MaxResults: DefaultListObjectsQueryCacheMaxResults,
+			TTL:        DefaultListObjectsQueryCacheTTL,
+		},
 		CheckDatastoreThrottle: DatastoreThrottleConfig{
 			Threshold: 0,
 			Duration:  0,
 		},
+		CheckStoreBulkhead: StoreBulkheadConfig{
+			Limit:       0,
+			StoreLimits: []string{},
+		},
+		CheckCoalescing: CheckCoalescingConfig{
+			Enabled: DefaultCheckCoalescingEnabled,
+			Window:  DefaultCheckCoalescingWindow,
+		},
+		WriteThrottling: WriteThrottlingConfig{
+			TuplesPerSecond:       0,
+			ChangesPerSecond:      0,
+			StoreTuplesPerSecond:  []string{},
+			StoreChangesPerSecond: []string{},
+		},
+		WriteScheduling: WriteSchedulingConfig{
+			Enabled:      DefaultWriteSchedulingEnabled,
+			MaxInFlight:  DefaultWriteSchedulingMaxInFlight,
+			StoreWeights: []string{},
+		},
 		ListObjectsDatastoreThrottle: DatastoreThrottleConfig{
 			Threshold: 0,
 			Duration:  0,
@@ -945,6 +2626,7 @@ func DefaultConfig() *Config {
 			Duration:  0,
 		},
 		RequestTimeout:                DefaultRequestTimeout,
+		MethodTimeouts:                []string{},
 		ShutdownTimeout:               DefaultShutdownTimeout,
 		ContextPropagationToDatastore: false,
 		Planner: PlannerConfig{
@@ -9,6 +9,9 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/middleware"
+	"github.com/openfga/openfga/pkg/middleware/priority"
 )
 
 func TestVerifyConfig(t *testing.T) {
@@ -40,6 +43,261 @@ func TestVerifyConfig(t *testing.T) {
 		require.EqualError(t, err, "config 'maxConcurrentReadsForListUsers' cannot be 0")
 	})
 
+	t.Run("streamedListObjectsMaxInFlightMessages_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.StreamedListObjectsMaxInFlightMessages = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'streamedListObjectsMaxInFlightMessages' cannot be 0")
+	})
+
+	t.Run("maxContextualTuples_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MaxContextualTuples = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'maxContextualTuples' cannot be 0")
+	})
+
+	t.Run("contextualTuplesValidationMode_unknown", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ContextualTuplesValidationMode = "relaxed"
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'contextualTuplesValidationMode' must be one of 'strict' or 'lenient'")
+	})
+
+	t.Run("changelogRetention_interval_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ChangelogRetention.Enabled = true
+		cfg.ChangelogRetention.Interval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'changelogRetention.interval' must be greater than 0")
+	})
+
+	t.Run("orphanedTuples_collectorInterval_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.OrphanedTuples.CollectorEnabled = true
+		cfg.OrphanedTuples.CollectorInterval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'orphanedTuples.collectorInterval' must be greater than 0")
+	})
+
+	t.Run("consistencyVerifier_sampleSize_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ConsistencyVerifier.Enabled = true
+		cfg.ConsistencyVerifier.SampleSize = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'consistencyVerifier.sampleSize' must be greater than 0")
+	})
+
+	t.Run("shadowCheck_samplePercentage_out_of_range", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ShadowCheck.SamplePercentage = 101
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'shadowCheck.samplePercentage' must be between 0 and 100")
+	})
+
+	t.Run("requestRecording_path_required", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.RequestRecording.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'requestRecording.path' must be set")
+	})
+
+	t.Run("hotPaths_interval_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.HotPaths.Enabled = true
+		cfg.HotPaths.Interval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'hotPaths.interval' must be greater than 0")
+	})
+
+	t.Run("materializedPermissions_interval_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MaterializedPermissions.Enabled = true
+		cfg.MaterializedPermissions.Relations = []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV=document#viewer"}
+		cfg.MaterializedPermissions.Interval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'materializedPermissions.interval' must be greater than 0")
+	})
+
+	t.Run("materializedPermissions_relations_not_empty", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MaterializedPermissions.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'materializedPermissions.relations' must not be empty")
+	})
+
+	t.Run("costEstimate_sampleSize_positive", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CostEstimate.Enabled = true
+		cfg.CostEstimate.SampleSize = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'costEstimate.sampleSize' must be greater than 0")
+	})
+
+	t.Run("multiRegion_regionId_in_range", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MultiRegion.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'multiRegion.regionId' must be between 1 and 255")
+
+		cfg.MultiRegion.RegionID = 256
+		err = cfg.Verify()
+		require.EqualError(t, err, "config 'multiRegion.regionId' must be between 1 and 255")
+
+		cfg.MultiRegion.RegionID = 2
+		require.NoError(t, cfg.Verify())
+	})
+
+	t.Run("cluster_selfAddress_required", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Cluster.SingleflightEnabled = true
+		cfg.Cluster.Peers = []string{"openfga-0:8081", "openfga-1:8081"}
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'cluster.selfAddress' must be set")
+	})
+
+	t.Run("cluster_dispatch_requires_selfAddress", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Cluster.DispatchEnabled = true
+		cfg.Cluster.Gossip.BindAddress = ":7946"
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'cluster.selfAddress' must be set")
+	})
+
+	t.Run("batchCheckAdaptiveLimits", func(t *testing.T) {
+		t.Run("requires_priority_scheduling", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.BatchCheckAdaptiveLimits.Enabled = true
+
+			err := cfg.Verify()
+			require.EqualError(t, err, "config 'batchCheckAdaptiveLimits.enabled' requires 'priorityScheduling.enabled'")
+		})
+
+		t.Run("load_threshold_out_of_range", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.PriorityScheduling.Enabled = true
+			cfg.BatchCheckAdaptiveLimits.Enabled = true
+			cfg.BatchCheckAdaptiveLimits.LoadThreshold = 1
+
+			err := cfg.Verify()
+			require.EqualError(t, err, "config 'batchCheckAdaptiveLimits.loadThreshold' must be at least 0 and less than 1")
+		})
+
+		t.Run("min_ratio_out_of_range", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.PriorityScheduling.Enabled = true
+			cfg.BatchCheckAdaptiveLimits.Enabled = true
+			cfg.BatchCheckAdaptiveLimits.MinRatio = 0
+
+			err := cfg.Verify()
+			require.EqualError(t, err, "config 'batchCheckAdaptiveLimits.minRatio' must be greater than 0 and at most 1")
+		})
+	})
+
+	t.Run("bulkWrite_window_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.BulkWrite.Enabled = true
+		cfg.BulkWrite.Window = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'bulkWrite.window' must be greater than 0")
+	})
+
+	t.Run("storePurge_requires_jobs", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.StorePurge.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'storePurge.enabled' requires 'jobs.enabled'")
+	})
+
+	t.Run("accessReview_requires_jobs", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.AccessReview.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'accessReview.enabled' requires 'jobs.enabled'")
+	})
+
+	t.Run("writeValidationHook_url_required", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.WriteValidationHook.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'writeValidationHook.url' is required when the write validation hook is enabled")
+	})
+
+	t.Run("writeValidationHook_timeout_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.WriteValidationHook.Enabled = true
+		cfg.WriteValidationHook.URL = "http://localhost:8000/validate"
+		cfg.WriteValidationHook.Timeout = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'writeValidationHook.timeout' must be greater than 0")
+	})
+
+	t.Run("contextEnrichmentHook_url_required", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ContextEnrichmentHook.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'contextEnrichmentHook.url' is required when the context enrichment hook is enabled")
+	})
+
+	t.Run("contextEnrichmentHook_timeout_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ContextEnrichmentHook.Enabled = true
+		cfg.ContextEnrichmentHook.URL = "http://localhost:8000/enrich"
+		cfg.ContextEnrichmentHook.Timeout = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'contextEnrichmentHook.timeout' must be greater than 0")
+	})
+
+	t.Run("contextEnrichmentHook_negative_cache_ttl", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ContextEnrichmentHook.Enabled = true
+		cfg.ContextEnrichmentHook.URL = "http://localhost:8000/enrich"
+		cfg.ContextEnrichmentHook.CacheTTL = -time.Second
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'contextEnrichmentHook.cacheTTL' must be greater than or equal to 0")
+	})
+
+	t.Run("contextEnrichmentHook_cache_max_entries_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ContextEnrichmentHook.Enabled = true
+		cfg.ContextEnrichmentHook.URL = "http://localhost:8000/enrich"
+		cfg.ContextEnrichmentHook.CacheMaxEntries = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'contextEnrichmentHook.cacheMaxEntries' must be greater than 0")
+	})
+
+	t.Run("negative_check_store_bulkhead_limit", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CheckStoreBulkhead.Limit = -1
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'checkStoreBulkhead.limit' must be non-negative")
+	})
+
 	t.Run("grpc.MaxRecvMsgBytes_not_zero", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.GRPC.MaxRecvMsgBytes = 0
@@ -48,6 +306,227 @@ func TestVerifyConfig(t *testing.T) {
 		require.EqualError(t, err, "config 'grpc.maxRecvMsgBytes' must be greater than 0")
 	})
 
+	t.Run("grpc.MaxSendMsgBytes_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.GRPC.MaxSendMsgBytes = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'grpc.maxSendMsgBytes' must be greater than 0")
+	})
+
+	t.Run("http.MaxRequestBodyBytes_not_negative", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.HTTP.MaxRequestBodyBytes = -1
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'http.maxRequestBodyBytes' must be non-negative")
+	})
+
+	t.Run("userIDEncryption_with_cache_invalidation_listener", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CacheController.Enabled = true
+		cfg.CheckQueryCache.Enabled = true
+		cfg.CacheInvalidationListener.Enabled = true
+		cfg.UserIDEncryption.Enabled = true
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'userIDEncryption.enabled' can't be combined with 'cacheInvalidationListener.enabled'")
+	})
+
+	t.Run("datastore_iam_auth", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.IAMAuth.Method = "aws_rds"
+		require.EqualError(t, cfg.Verify(), "datastore iamAuth is only available with the postgres and mysql engines")
+
+		cfg.Datastore.Engine = "postgres"
+		require.NoError(t, cfg.Verify())
+
+		cfg.Datastore.IAMAuth.Method = "azure"
+		require.EqualError(t, cfg.Verify(), "datastore iamAuth method must be one of 'aws_rds' or 'gcp_cloudsql', got 'azure'")
+	})
+
+	t.Run("datastore_reverse_user_index", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.ReverseUserIndex.Enabled = true
+		require.NoError(t, cfg.Verify())
+
+		cfg.Datastore.Engine = "sqlite"
+		require.EqualError(t, cfg.Verify(), "datastore reverseUserIndex is only available with the memory, postgres and mysql engines, got 'sqlite'")
+	})
+
+	t.Run("secrets_refresh_interval", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Secrets.RefreshInterval = 0
+		require.EqualError(t, cfg.Verify(), "config 'secrets.refreshInterval' must be greater than zero")
+	})
+
+	t.Run("transaction_pooling_with_cache_invalidation_listener", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CacheController.Enabled = true
+		cfg.CheckQueryCache.Enabled = true
+		cfg.CacheInvalidationListener.Enabled = true
+		cfg.Datastore.TransactionPooling.Enabled = true
+
+		cfg.Datastore.Engine = "mysql"
+		require.NoError(t, cfg.Verify())
+
+		cfg.Datastore.Engine = "postgres"
+		err := cfg.Verify()
+		require.EqualError(t, err, "'datastore.transactionPooling.enabled' can't be combined with 'cacheInvalidationListener.enabled' with the postgres engine")
+	})
+
+	t.Run("non_positive_check_coalescing_window", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CheckCoalescing.Enabled = true
+		cfg.CheckCoalescing.Window = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'checkCoalescing.window' must be greater than zero if enabled")
+	})
+
+	t.Run("negative_write_throttling_rates", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.WriteThrottling.TuplesPerSecond = -1
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'writeThrottling.tuplesPerSecond' must be non-negative")
+
+		cfg = DefaultConfig()
+		cfg.WriteThrottling.ChangesPerSecond = -1
+
+		err = cfg.Verify()
+		require.EqualError(t, err, "'writeThrottling.changesPerSecond' must be non-negative")
+	})
+
+	t.Run("invalid_redaction_mode", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Redaction.Mode = "mask"
+
+		err := cfg.Verify()
+		require.ErrorContains(t, err, "config 'redaction.mode': invalid redaction mode 'mask'")
+	})
+
+	t.Run("redaction_hash_mode_requires_key", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Redaction.Mode = "hash"
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'redaction.key' is required if 'redaction.mode' is 'hash'")
+	})
+
+	t.Run("audit_without_otlp_endpoint", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Audit.Enabled = true
+		cfg.Audit.OTLP.Endpoint = ""
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'audit.otlp.endpoint' must be set")
+	})
+
+	t.Run("audit_with_zero_queue_size", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Audit.Enabled = true
+		cfg.Audit.QueueSize = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'audit.queueSize' must be greater than zero")
+	})
+
+	t.Run("audit_with_zero_flush_interval", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Audit.Enabled = true
+		cfg.Audit.FlushInterval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'audit.flushInterval' must be greater than zero")
+	})
+
+	t.Run("negative_redaction_key_rotation_period", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Redaction.KeyRotationPeriod = -time.Hour
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'redaction.keyRotationPeriod' must be non-negative")
+	})
+
+	t.Run("conditionContext.claims_requires_oidc", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ConditionContext.Claims = []string{"department"}
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'conditionContext.claims' requires 'authn.method' to be 'oidc'")
+	})
+
+	t.Run("http.CORSMaxAge_not_negative", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.HTTP.CORSMaxAge = -time.Second
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'http.corsMaxAge' must be non-negative")
+	})
+
+	t.Run("http.CORSMaxAge_at_least_a_second", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.HTTP.CORSMaxAge = 600
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "config 'http.corsMaxAge' must be 0 or a duration of at least 1s, e.g. 10m, got 600ns")
+
+		cfg.HTTP.CORSMaxAge = 10 * time.Minute
+		require.NoError(t, cfg.Verify())
+	})
+
+	t.Run("grpc_keepalive", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.GRPC.Keepalive.Time = 0
+		require.EqualError(t, cfg.Verify(), "config 'grpc.keepalive.time' must be greater than 0")
+
+		cfg = DefaultConfig()
+		cfg.GRPC.MaxConnectionAge = -time.Second
+		require.EqualError(t, cfg.Verify(), "config 'grpc.maxConnectionAge' must be non-negative")
+
+		cfg = DefaultConfig()
+		cfg.GRPC.MaxConnectionAge = 30 * time.Minute
+		cfg.GRPC.MaxConnectionAgeGrace = time.Minute
+		require.NoError(t, cfg.Verify())
+	})
+
+	t.Run("compression", func(t *testing.T) {
+		t.Run("unsupported_grpc_algorithm", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.GRPC.Compression.Enabled = true
+			cfg.GRPC.Compression.Algorithms = []string{"br"}
+
+			err := cfg.Verify()
+			require.EqualError(t, err, "config 'grpc.compression.algorithms': unsupported compression algorithm: 'br'")
+		})
+
+		t.Run("unsupported_http_algorithm", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.HTTP.Compression.Enabled = true
+			cfg.HTTP.Compression.Algorithms = []string{"gzip", "br"}
+
+			err := cfg.Verify()
+			require.EqualError(t, err, "config 'http.compression.algorithms': unsupported compression algorithm: 'br'")
+		})
+
+		t.Run("negative_http_min_size", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.HTTP.Compression.Enabled = true
+			cfg.HTTP.Compression.MinSizeBytes = -1
+
+			err := cfg.Verify()
+			require.EqualError(t, err, "config 'http.compression.minSizeBytes' must be non-negative")
+		})
+
+		t.Run("unsupported_algorithm_while_disabled", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.HTTP.Compression.Algorithms = []string{"br"}
+
+			require.NoError(t, cfg.Verify())
+		})
+	})
+
 	t.Run("failing_to_set_http_cert_path_will_not_allow_server_to_start", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.HTTP.TLS = &TLSConfig{
@@ -569,6 +1048,49 @@ func TestVerifyConfig(t *testing.T) {
 		})
 	})
 
+	t.Run("cache_invalidation_listener", func(t *testing.T) {
+		t.Run("enable_without_cache_controller", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CacheInvalidationListener.Enabled = true
+			cfg.CheckQueryCache.Enabled = true
+			err := cfg.Verify()
+			require.ErrorContains(t, err, "'cacheInvalidationListener.enabled' requires 'cacheController.enabled'")
+		})
+		t.Run("enable_without_cache", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CacheInvalidationListener.Enabled = true
+			cfg.CacheController.Enabled = true
+			err := cfg.Verify()
+			require.ErrorContains(t, err, "'cacheInvalidationListener.enabled' requires 'cacheController.enabled'")
+		})
+		t.Run("enable_without_publication", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CacheInvalidationListener.Enabled = true
+			cfg.CacheInvalidationListener.PostgresPublication = ""
+			cfg.CacheController.Enabled = true
+			cfg.CheckQueryCache.Enabled = true
+			err := cfg.Verify()
+			require.EqualError(t, err, "'cacheInvalidationListener.postgresPublication' must be set")
+		})
+		t.Run("enable_with_poll_interval_zero", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CacheInvalidationListener.Enabled = true
+			cfg.CacheInvalidationListener.PollInterval = 0
+			cfg.CacheController.Enabled = true
+			cfg.CheckQueryCache.Enabled = true
+			err := cfg.Verify()
+			require.EqualError(t, err, "'cacheInvalidationListener.pollInterval' must be greater than zero")
+		})
+		t.Run("enable_with_cache_controller", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CacheInvalidationListener.Enabled = true
+			cfg.CacheController.Enabled = true
+			cfg.CheckQueryCache.Enabled = true
+			err := cfg.Verify()
+			require.NoError(t, err)
+		})
+	})
+
 	t.Run("cache_ttl_jitter_percentage", func(t *testing.T) {
 		t.Run("valid_zero", func(t *testing.T) {
 			cfg := DefaultConfig()
@@ -940,6 +1462,24 @@ func TestVerifyServerSettings(t *testing.T) {
 			require.NoError(t, err)
 		})
 	})
+
+	t.Run("index_advisor_slow_query_threshold_must_be_positive", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.IndexAdvisor.Enabled = true
+		require.NoError(t, cfg.VerifyServerSettings())
+
+		cfg.Datastore.IndexAdvisor.SlowQueryThreshold = 0
+		require.Error(t, cfg.VerifyServerSettings())
+	})
+
+	t.Run("latest_model_cache_ttl_must_not_be_negative", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.LatestModelCacheTTL = time.Minute
+		require.NoError(t, cfg.VerifyServerSettings())
+
+		cfg.Datastore.LatestModelCacheTTL = -time.Minute
+		require.Error(t, cfg.VerifyServerSettings())
+	})
 }
 
 func TestVerifyBinarySettings(t *testing.T) {
@@ -1011,6 +1551,74 @@ func TestVerifyBinarySettings(t *testing.T) {
 		require.EqualError(t, err, "requestTimeout must be a non-negative time duration")
 	})
 
+	t.Run("invalid_method_timeouts", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MethodTimeouts = []string{"Check=5s:2s"}
+
+		err := cfg.VerifyBinarySettings()
+		require.ErrorIs(t, err, middleware.ErrInvalidMethodTimeout)
+	})
+
+	t.Run("invalid_priority_scheduling_method_classes", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.PriorityScheduling.Enabled = true
+		cfg.PriorityScheduling.MethodClasses = []string{"ListObjects=urgent"}
+
+		err := cfg.VerifyBinarySettings()
+		require.ErrorIs(t, err, priority.ErrInvalidPriority)
+	})
+
+	t.Run("invalid_write_scheduling", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.WriteScheduling.Enabled = true
+		cfg.WriteScheduling.MaxInFlight = 0
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "config 'writeScheduling.maxInFlight' must be greater than 0")
+
+		cfg = DefaultConfig()
+		cfg.WriteScheduling.Enabled = true
+		cfg.WriteScheduling.StoreWeights = []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV=0"}
+
+		err = cfg.VerifyBinarySettings()
+		require.ErrorIs(t, err, priority.ErrInvalidPriority)
+	})
+
+	t.Run("non_positive_slo_window", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.SLO.Enabled = true
+		cfg.SLO.Window = 0
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "config 'slo.window' must be greater than 0")
+	})
+
+	t.Run("check_cache_disk_path_with_registered_engine", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CheckCache.Engine = "groupcache"
+		cfg.CheckCache.DiskPath = "/tmp/check-cache"
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "config 'checkCache.diskPath' requires 'checkCache.engine' memory")
+	})
+
+	t.Run("slo_without_metrics", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.SLO.Enabled = true
+		cfg.Metrics.Enabled = false
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "config 'slo.enabled' requires 'metrics.enabled'")
+	})
+
+	t.Run("method_timeouts_extend_default_context_timeout", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.RequestTimeout = 3 * time.Second
+		cfg.MethodTimeouts = []string{"ListObjects=5s:10s"}
+
+		require.Equal(t, 10*time.Second+additionalUpstreamTimeout, DefaultContextTimeout(cfg))
+	})
+
 	t.Run("negative_http_upstream_timeout", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.RequestTimeout = 0
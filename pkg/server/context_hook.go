@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/pkg/server/contexthook"
+)
+
+// The results of the calls to the context enrichment hook, as labeled by
+// contextEnrichmentHookCounter.
+const (
+	contextEnrichmentSucceeded = "succeeded"
+	contextEnrichmentFailed    = "failed"
+)
+
+// ErrContextEnrichmentHookFailed is returned by Check and ListObjects when the context enrichment
+// hook fails.
+var ErrContextEnrichmentHookFailed = status.Error(codes.Unavailable, "the context enrichment hook failed")
+
+// enrichContext returns the condition context of a request of methodName enriched with the
+// attributes of user and object fetched from the context enrichment hook, or requestContext as is
+// if the hook is not configured. The object is empty for ListObjects.
+func (s *Server) enrichContext(ctx context.Context, methodName, storeID, user, object string, requestContext *structpb.Struct) (*structpb.Struct, error) {
+	if s.contextEnrichmentHook == nil {
+		return requestContext, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "enrichContext")
+	defer span.End()
+
+	hookCtx, cancel := context.WithTimeout(ctx, s.contextEnrichmentHookTimeout)
+	defer cancel()
+
+	res, err := s.contextEnrichmentHook.Enrich(hookCtx, &contexthook.Request{
+		StoreID: storeID,
+		User:    user,
+		Object:  object,
+	})
+	if err == nil {
+		requestContext, err = contexthook.Merge(requestContext, res.Attributes)
+	}
+	if err != nil {
+		telemetry.TraceError(span, err)
+		contextEnrichmentHookCounter.WithLabelValues(methodName, contextEnrichmentFailed).Inc()
+		s.logger.ErrorWithContext(ctx, "context enrichment hook failed", zap.Error(err))
+		return nil, ErrContextEnrichmentHookFailed
+	}
+
+	contextEnrichmentHookCounter.WithLabelValues(methodName, contextEnrichmentSucceeded).Inc()
+	return requestContext, nil
+}
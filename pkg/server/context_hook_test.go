@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/server/contexthook"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// fakeContextHook returns the department of the users: eng for user:anne and sales for the
+// others. It fails for user:broken.
+type fakeContextHook struct {
+	mu       sync.Mutex
+	requests []*contexthook.Request
+	closed   bool
+}
+
+func (h *fakeContextHook) Enrich(_ context.Context, req *contexthook.Request) (*contexthook.Response, error) {
+	h.mu.Lock()
+	h.requests = append(h.requests, req)
+	h.mu.Unlock()
+
+	switch req.User {
+	case "user:anne":
+		return &contexthook.Response{Attributes: map[string]any{"department": "eng"}}, nil
+	case "user:broken":
+		return nil, errors.New("unreachable")
+	default:
+		return &contexthook.Response{Attributes: map[string]any{"department": "sales"}}, nil
+	}
+}
+
+func (h *fakeContextHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	return nil
+}
+
+func TestContextEnrichmentHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	hook := &fakeContextHook{}
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithContextEnrichmentHook(hook, time.Second),
+	)
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "context-hook"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user:* with in_department]
+		condition in_department(department: string) {
+			department == "eng"
+		}`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:*", "in_department", nil),
+		}},
+	})
+	require.NoError(t, err)
+
+	check := func(user string, requestContext *structpb.Struct) (bool, error) {
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", user),
+			Context:  requestContext,
+		})
+		return resp.GetAllowed(), err
+	}
+
+	t.Run("check", func(t *testing.T) {
+		allowed, err := check("user:anne", nil)
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = check("user:bob", nil)
+		require.NoError(t, err)
+		require.False(t, allowed)
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		require.Equal(t, &contexthook.Request{StoreID: storeID, User: "user:anne", Object: "document:1"}, hook.requests[0])
+	})
+
+	t.Run("request_context_takes_precedence", func(t *testing.T) {
+		requestContext, err := structpb.NewStruct(map[string]any{"department": "eng"})
+		require.NoError(t, err)
+
+		allowed, err := check("user:bob", requestContext)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("list_objects", func(t *testing.T) {
+		resp, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1"}, resp.GetObjects())
+
+		resp, err = s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:bob",
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetObjects())
+	})
+
+	t.Run("hook_failure", func(t *testing.T) {
+		_, err := check("user:broken", nil)
+		require.ErrorIs(t, err, ErrContextEnrichmentHookFailed)
+
+		_, err = s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:broken",
+		})
+		require.ErrorIs(t, err, ErrContextEnrichmentHookFailed)
+	})
+
+	t.Run("closed_with_the_server", func(t *testing.T) {
+		hook := &fakeContextHook{}
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		s := MustNewServerWithOpts(WithDatastore(ds), WithContextEnrichmentHook(hook, time.Second))
+		s.Close()
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		require.True(t, hook.closed)
+	})
+}
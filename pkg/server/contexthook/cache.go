@@ -0,0 +1,64 @@
+package contexthook
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// CachedHook caches the responses of a Hook by store, user and object, so that the attributes of
+// the users and objects that are checked repeatedly are not fetched on every request. The errors
+// are not cached.
+type CachedHook struct {
+	hook  Hook
+	cache *storage.InMemoryLRUCache[*Response]
+	ttl   time.Duration
+}
+
+var _ Hook = (*CachedHook)(nil)
+
+// NewCachedHook returns the Hook that caches the responses of hook for ttl, up to maxEntries.
+func NewCachedHook(hook Hook, ttl time.Duration, maxEntries int64) (*CachedHook, error) {
+	cache, err := storage.NewInMemoryLRUCache(storage.WithMaxCacheSize[*Response](maxEntries))
+	if err != nil {
+		return nil, err
+	}
+	return &CachedHook{
+		hook:  hook,
+		cache: cache,
+		ttl:   ttl,
+	}, nil
+}
+
+// Enrich see [Hook].Enrich.
+func (h *CachedHook) Enrich(ctx context.Context, req *Request) (*Response, error) {
+	key := cacheKey(req)
+	if res := h.cache.Get(key); res != nil {
+		return res, nil
+	}
+
+	res, err := h.hook.Enrich(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	h.cache.Set(key, res, h.ttl)
+	return res, nil
+}
+
+// Close see [Hook].Close.
+func (h *CachedHook) Close() error {
+	h.cache.Stop()
+	return h.hook.Close()
+}
+
+func cacheKey(req *Request) string {
+	var b strings.Builder
+	b.WriteString(req.StoreID)
+	b.WriteString("/")
+	b.WriteString(req.User)
+	b.WriteString("#")
+	b.WriteString(req.Object)
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package contexthook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrInvalidURL is returned by New when the URL of the hook is neither an HTTP nor a gRPC URL.
+var ErrInvalidURL = errors.New("the URL of the context enrichment hook must have the http, https or grpc scheme")
+
+// Request identifies the user and the object whose attributes are fetched from the hook.
+type Request struct {
+	StoreID string `json:"store_id"`
+	User    string `json:"user"`
+
+	// Object is the object of a Check. It is empty for ListObjects, whose objects are not known
+	// upfront.
+	Object string `json:"object,omitempty"`
+}
+
+// Response holds the attributes that the hook adds to the condition context of a request.
+type Response struct {
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Hook fetches condition context attributes from an external source.
+type Hook interface {
+	// Enrich returns the attributes of the user and the object of req, or an error if the hook is
+	// unreachable or fails.
+	Enrich(ctx context.Context, req *Request) (*Response, error)
+
+	// Close releases the resources of the hook.
+	Close() error
+}
+
+// New returns the Hook that calls out to rawURL: an HTTP hook for the http and https schemes, or
+// a gRPC hook for the grpc scheme, e.g. "grpc://attributes:50051".
+func New(rawURL string) (Hook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return NewHTTPHook(rawURL), nil
+	case "grpc":
+		if u.Host == "" {
+			return nil, ErrInvalidURL
+		}
+		return NewGRPCHook(u.Host)
+	default:
+		return nil, ErrInvalidURL
+	}
+}
+
+// Merge returns the condition context of a request enriched with attributes. The attributes that
+// are also set in the context of the request are ignored, so that clients can override the
+// attributes of the hook.
+func Merge(requestContext *structpb.Struct, attributes map[string]any) (*structpb.Struct, error) {
+	if len(attributes) == 0 {
+		return requestContext, nil
+	}
+
+	merged, err := structpb.NewStruct(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attributes of the context enrichment hook: %w", err)
+	}
+	for key, value := range requestContext.GetFields() {
+		merged.Fields[key] = value
+	}
+	return merged, nil
+}
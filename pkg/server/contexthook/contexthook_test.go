@@ -0,0 +1,181 @@
+package contexthook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// departmentEnricher returns the department of the users, and the department of the objects
+// when they are known.
+type departmentEnricher struct {
+	calls atomic.Int32
+}
+
+func (e *departmentEnricher) Enrich(_ context.Context, req *Request) (*Response, error) {
+	e.calls.Add(1)
+	if req.User == "user:broken" {
+		return nil, errors.New("unreachable")
+	}
+
+	attributes := map[string]any{"user_department": "eng"}
+	if req.Object != "" {
+		attributes["object_department"] = "sales"
+	}
+	return &Response{Attributes: attributes}, nil
+}
+
+func (e *departmentEnricher) Close() error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	hook, err := New("https://attributes.example.com/enrich")
+	require.NoError(t, err)
+	require.IsType(t, &HTTPHook{}, hook)
+	require.NoError(t, hook.Close())
+
+	hook, err = New("grpc://attributes:50051")
+	require.NoError(t, err)
+	require.IsType(t, &GRPCHook{}, hook)
+	require.NoError(t, hook.Close())
+
+	for _, rawURL := range []string{"attributes:50051", "ftp://attributes", "grpc:///path", "://"} {
+		_, err = New(rawURL)
+		require.ErrorIs(t, err, ErrInvalidURL, rawURL)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	requestContext, err := structpb.NewStruct(map[string]any{"user_department": "sales", "ip": "10.0.0.1"})
+	require.NoError(t, err)
+
+	merged, err := Merge(requestContext, map[string]any{"user_department": "eng", "level": 3})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"user_department": "sales", "ip": "10.0.0.1", "level": float64(3)}, merged.AsMap())
+
+	merged, err = Merge(nil, map[string]any{"level": 3})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"level": float64(3)}, merged.AsMap())
+
+	merged, err = Merge(requestContext, nil)
+	require.NoError(t, err)
+	require.Same(t, requestContext, merged)
+
+	_, err = Merge(nil, map[string]any{"invalid": struct{}{}})
+	require.Error(t, err)
+}
+
+func TestHTTPHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	enricher := &departmentEnricher{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		res, err := enricher.Enrich(r.Context(), req)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+
+	hook := NewHTTPHook(srv.URL)
+	t.Cleanup(func() {
+		_ = hook.Close()
+	})
+
+	res, err := hook.Enrich(context.Background(), &Request{StoreID: "store", User: "user:anne", Object: "document:1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"user_department": "eng", "object_department": "sales"}, res.Attributes)
+
+	_, err = hook.Enrich(context.Background(), &Request{StoreID: "store", User: "user:broken"})
+	require.ErrorContains(t, err, "status 500")
+}
+
+func TestGRPCHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	RegisterServer(srv, &departmentEnricher{})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	hook, err := NewGRPCHook("passthrough:///bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = hook.Close()
+	})
+
+	res, err := hook.Enrich(context.Background(), &Request{StoreID: "store", User: "user:anne"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"user_department": "eng"}, res.Attributes)
+
+	_, err = hook.Enrich(context.Background(), &Request{StoreID: "store", User: "user:broken"})
+	require.ErrorContains(t, err, "unreachable")
+}
+
+func TestCachedHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	enricher := &departmentEnricher{}
+	hook, err := NewCachedHook(enricher, time.Minute, 100)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = hook.Close()
+	})
+
+	for range 3 {
+		res, err := hook.Enrich(ctx, &Request{StoreID: "store", User: "user:anne", Object: "document:1"})
+		require.NoError(t, err)
+		require.Equal(t, "sales", res.Attributes["object_department"])
+	}
+	require.Equal(t, int32(1), enricher.calls.Load())
+
+	// the responses are cached by store, user and object
+	_, err = hook.Enrich(ctx, &Request{StoreID: "store", User: "user:anne"})
+	require.NoError(t, err)
+	_, err = hook.Enrich(ctx, &Request{StoreID: "other-store", User: "user:anne", Object: "document:1"})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), enricher.calls.Load())
+
+	// the errors are not cached
+	for range 2 {
+		_, err = hook.Enrich(ctx, &Request{StoreID: "store", User: "user:broken"})
+		require.Error(t, err)
+	}
+	require.Equal(t, int32(5), enricher.calls.Load())
+}
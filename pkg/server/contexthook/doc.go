@@ -0,0 +1,16 @@
+// Package contexthook calls out to an external service that provides condition context
+// attributes, so that the clients do not need to send all the attributes that the conditions of
+// the model evaluate in every request.
+//
+// On Check and ListObjects, the server fetches the attributes of the user, and of the object for
+// Check, from the hook, and merges them into the context of the request. The attributes set in
+// the context of the request take precedence over those of the hook.
+//
+// The hooks are called over HTTP, with a JSON POST of the Request to which the hook responds with
+// the Response as JSON, or over gRPC, with the Enrich method of the
+// openfga.contexthook.v1.ContextEnrichmentService, whose messages are encoded as JSON too. The
+// servers of the gRPC hooks written in Go can register the service with RegisterServer.
+//
+// The responses can be cached with a CachedHook. The server bounds the calls with a timeout, and
+// the requests fail when the hook fails.
+package contexthook
@@ -0,0 +1,66 @@
+package contexthook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxResponseBytes bounds the size of the responses of the HTTP hooks.
+const maxResponseBytes = 1 << 20
+
+// HTTPHook posts the Requests as JSON to a URL, which responds with the Response as JSON and a
+// 200 status. Any other status is an error.
+type HTTPHook struct {
+	url    string
+	client *http.Client
+}
+
+var _ Hook = (*HTTPHook)(nil)
+
+// NewHTTPHook returns the Hook that posts the Requests to url.
+func NewHTTPHook(url string) *HTTPHook {
+	return &HTTPHook{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+// Enrich see [Hook].Enrich.
+func (h *HTTPHook) Enrich(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpRes, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the context enrichment hook responded with status %d", httpRes.StatusCode)
+	}
+
+	res := &Response{}
+	if err := json.NewDecoder(io.LimitReader(httpRes.Body, maxResponseBytes)).Decode(res); err != nil {
+		return nil, fmt.Errorf("invalid response of the context enrichment hook: %w", err)
+	}
+	return res, nil
+}
+
+// Close see [Hook].Close.
+func (h *HTTPHook) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/costestimate"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ErrCostEstimateDisabled is returned by EstimateCheckCost and EstimateListObjectsCost when the
+// cost estimates are not enabled with WithCostEstimateEnabled.
+var ErrCostEstimateDisabled = status.Error(codes.Unimplemented, "cost estimate is not enabled")
+
+// EstimateCheckCost estimates the number of datastore queries and dispatches of a Check without
+// executing it. It is authorized like Check.
+func (s *Server) EstimateCheckCost(ctx context.Context, req *costestimate.EstimateCheckRequest) (*costestimate.Estimate, error) {
+	ctx, span := tracer.Start(ctx, "EstimateCheckCost", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("object", req.Object),
+		attribute.String("relation", req.Relation),
+	))
+	defer span.End()
+
+	if !s.costEstimateEnabled {
+		return nil, ErrCostEstimateDisabled
+	}
+
+	err := (&openfgav1.CheckRequest{
+		StoreId:              req.StoreID,
+		AuthorizationModelId: req.AuthorizationModelID,
+		TupleKey:             tuple.NewCheckRequestTupleKey(req.Object, req.Relation, req.User),
+	}).Validate()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	typesys, err := s.resolveCostEstimateTypesystem(ctx, req.StoreID, req.AuthorizationModelID, apimethod.Check)
+	if err != nil {
+		return nil, err
+	}
+
+	objectType := tuple.GetType(req.Object)
+	if _, err := typesys.GetRelation(objectType, req.Relation); err != nil {
+		return nil, serverErrors.ValidationError(err)
+	}
+
+	estimate, err := costestimate.ComputeCheck(ctx, s.datastore, typesys, req.StoreID, objectType, req.Relation, s.costEstimateOptions()...)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+	return estimate, nil
+}
+
+// EstimateListObjectsCost estimates the number of datastore queries and dispatches of a
+// ListObjects, and the number of objects it returns, without executing it. It is authorized like
+// ListObjects.
+func (s *Server) EstimateListObjectsCost(ctx context.Context, req *costestimate.EstimateListObjectsRequest) (*costestimate.Estimate, error) {
+	ctx, span := tracer.Start(ctx, "EstimateListObjectsCost", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("object_type", req.Type),
+		attribute.String("relation", req.Relation),
+	))
+	defer span.End()
+
+	if !s.costEstimateEnabled {
+		return nil, ErrCostEstimateDisabled
+	}
+
+	err := (&openfgav1.ListObjectsRequest{
+		StoreId:              req.StoreID,
+		AuthorizationModelId: req.AuthorizationModelID,
+		Type:                 req.Type,
+		Relation:             req.Relation,
+		User:                 req.User,
+	}).Validate()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	typesys, err := s.resolveCostEstimateTypesystem(ctx, req.StoreID, req.AuthorizationModelID, apimethod.ListObjects)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := typesys.GetRelation(req.Type, req.Relation); err != nil {
+		return nil, serverErrors.ValidationError(err)
+	}
+
+	estimate, err := costestimate.ComputeListObjects(ctx, s.datastore, typesys, req.StoreID, req.Type, req.Relation, req.User, s.costEstimateOptions()...)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+	return estimate, nil
+}
+
+// resolveCostEstimateTypesystem authorizes the estimate of the cost of method and returns the
+// model of the estimate.
+func (s *Server) resolveCostEstimateTypesystem(ctx context.Context, storeID, modelID string, method apimethod.APIMethod) (*typesystem.TypeSystem, error) {
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  method.String(),
+	})
+
+	if err := s.checkAuthz(ctx, storeID, method); err != nil {
+		return nil, err
+	}
+	return s.resolveTypesystem(ctx, storeID, modelID)
+}
+
+func (s *Server) costEstimateOptions() []costestimate.Option {
+	return []costestimate.Option{
+		costestimate.WithSampleSize(s.costEstimateSampleSize),
+		costestimate.WithResolveNodeLimit(s.resolveNodeLimit),
+	}
+}
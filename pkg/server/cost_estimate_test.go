@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/costestimate"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestEstimateCost(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithCostEstimateEnabled(true))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "estimate"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent`)
+	writeModel, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "parent", "folder:a"),
+				tuple.NewTupleKey("document:2", "parent", "folder:a"),
+				tuple.NewTupleKey("folder:a", "viewer", "user:anne"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("check", func(t *testing.T) {
+		estimate, err := s.EstimateCheckCost(ctx, &costestimate.EstimateCheckRequest{
+			StoreID:  storeID,
+			Object:   "document:1",
+			Relation: "viewer",
+			User:     "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, writeModel.GetAuthorizationModelId(), estimate.AuthorizationModelID)
+		require.InDelta(t, 3, estimate.DatastoreQueries, 0.01)
+		require.InDelta(t, 1, estimate.Dispatches, 0.01)
+		require.Nil(t, estimate.Objects)
+	})
+
+	t.Run("list_objects", func(t *testing.T) {
+		estimate, err := s.EstimateListObjectsCost(ctx, &costestimate.EstimateListObjectsRequest{
+			StoreID:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:anne",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, estimate.Objects)
+	})
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		_, err := s.EstimateCheckCost(ctx, &costestimate.EstimateCheckRequest{
+			StoreID:  storeID,
+			Object:   "document:1",
+			Relation: "owner",
+			User:     "user:anne",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	t.Run("invalid_store_id", func(t *testing.T) {
+		_, err := s.EstimateListObjectsCost(ctx, &costestimate.EstimateListObjectsRequest{
+			StoreID:  "invalid",
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:anne",
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestEstimateCostDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	_, err := s.EstimateCheckCost(context.Background(), &costestimate.EstimateCheckRequest{
+		StoreID:  "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		Object:   "document:1",
+		Relation: "viewer",
+		User:     "user:anne",
+	})
+	require.ErrorIs(t, err, ErrCostEstimateDisabled)
+}
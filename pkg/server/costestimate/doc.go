@@ -0,0 +1,18 @@
+// Package costestimate estimates the cost of a Check or a ListObjects request without executing
+// it, to catch the expensive access patterns of a model in design review.
+//
+// The cost is the number of datastore queries and of dispatches, i.e. of the sub-problems that
+// the request resolves recursively, of the request. It is derived from the structure of the model
+// and from the cardinality of the tuples of the store, which are sampled per type: e.g. a
+// "viewer from parent" costs a query for the parents of the object, and then a dispatch and the
+// cost of the viewers of each parent, for the average number of parents of the objects of the
+// type.
+//
+// The estimates are upper bounds of the work of the server: they don't account for the branches
+// of a union that are skipped once a branch allows the request, nor for the caches.
+//
+// The estimates are served by the cost estimate service, whose messages are encoded as JSON:
+// /openfga.costestimate.v1.CostEstimateService/EstimateCheck and
+// /openfga.costestimate.v1.CostEstimateService/EstimateListObjects. They are also served over HTTP
+// as POST /stores/{store_id}/check/estimate and POST /stores/{store_id}/list-objects/estimate.
+package costestimate
@@ -0,0 +1,600 @@
+package costestimate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// DefaultSampleSize is the default maximum number of tuples of each type sampled for the
+// cardinality of its relations.
+const DefaultSampleSize = 1000
+
+// samplePageSize is the size of the pages of tuples read to sample a type.
+const samplePageSize = 100
+
+// Estimate is the estimated cost of a request.
+type Estimate struct {
+	AuthorizationModelID string `json:"authorization_model_id"`
+
+	// DatastoreQueries is the estimated number of queries to the datastore.
+	DatastoreQueries float64 `json:"datastore_queries"`
+
+	// Dispatches is the estimated number of sub-problems resolved recursively, e.g. a computed
+	// relation or the relation of each parent of a "viewer from parent".
+	Dispatches float64 `json:"dispatches"`
+
+	// Depth is the maximum depth of the dispatches, which is bounded by the resolve node limit.
+	Depth uint32 `json:"depth"`
+
+	// Objects is the estimated number of objects returned by a ListObjects.
+	Objects *float64 `json:"objects,omitempty"`
+
+	// Warnings are the approximations of the estimate, e.g. a recursive relation.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Types and Relations are the cardinality statistics of the sampled types that the estimate
+	// is based on, sorted by type, relation and user type.
+	Types     []*TypeStats     `json:"types"`
+	Relations []*RelationStats `json:"relations"`
+}
+
+// TypeStats are the cardinality statistics of the sampled tuples of a type.
+type TypeStats struct {
+	Type string `json:"type"`
+
+	// Tuples is the number of sampled tuples of the objects of the type, and Objects the number
+	// of distinct objects of these tuples.
+	Tuples  int `json:"tuples"`
+	Objects int `json:"objects"`
+
+	// Truncated is true if the type has more tuples than the sample.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RelationStats are the cardinality statistics of the sampled tuples of a relation of a type,
+// for a type of users.
+type RelationStats struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation"`
+
+	// UserType is the type of the users of the tuples, e.g. "user", "user:*" or "group#member".
+	UserType string `json:"user_type"`
+
+	// Tuples is the number of sampled tuples, and Objects and Users the number of their distinct
+	// objects and users.
+	Tuples  int `json:"tuples"`
+	Objects int `json:"objects"`
+	Users   int `json:"users"`
+}
+
+// Option is an option of ComputeCheck and ComputeListObjects.
+type Option func(*estimator)
+
+// WithSampleSize sets the maximum number of tuples of each type sampled for the cardinality of its
+// relations. Defaults to DefaultSampleSize.
+func WithSampleSize(size int) Option {
+	return func(e *estimator) {
+		e.sampleSize = size
+	}
+}
+
+// WithResolveNodeLimit bounds the depth of the dispatches.
+func WithResolveNodeLimit(limit uint32) Option {
+	return func(e *estimator) {
+		e.resolveNodeLimit = limit
+	}
+}
+
+// cost is the number of queries and dispatches of a (sub-)problem.
+type cost struct {
+	queries    float64
+	dispatches float64
+	depth      uint32
+}
+
+func (c cost) add(other cost) cost {
+	return cost{
+		queries:    c.queries + other.queries,
+		dispatches: c.dispatches + other.dispatches,
+		depth:      max(c.depth, other.depth),
+	}
+}
+
+// dispatched returns the cost of c resolved n times by a dispatch.
+func (c cost) dispatched(n float64) cost {
+	return cost{
+		queries:    c.queries * n,
+		dispatches: (c.dispatches + 1) * n,
+		depth:      c.depth,
+	}
+}
+
+type typeSample struct {
+	stats     *TypeStats
+	relations map[string]*RelationStats // by relation and user type
+}
+
+type estimator struct {
+	ctx              context.Context
+	datastore        storage.RelationshipTupleReader
+	typesys          *typesystem.TypeSystem
+	storeID          string
+	sampleSize       int
+	resolveNodeLimit uint32
+
+	samples  map[string]*typeSample
+	visiting map[string]struct{}
+	warnings []string
+}
+
+func newEstimator(ctx context.Context, datastore storage.RelationshipTupleReader, typesys *typesystem.TypeSystem, storeID string, opts ...Option) *estimator {
+	e := &estimator{
+		ctx:              ctx,
+		datastore:        datastore,
+		typesys:          typesys,
+		storeID:          storeID,
+		sampleSize:       DefaultSampleSize,
+		resolveNodeLimit: serverconfig.DefaultResolveNodeLimit,
+		samples:          map[string]*typeSample{},
+		visiting:         map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ComputeCheck estimates the cost of a Check of the relation of objects of objectType, with the
+// model of typesys and the tuples of the store.
+func ComputeCheck(ctx context.Context, datastore storage.RelationshipTupleReader, typesys *typesystem.TypeSystem, storeID, objectType, relation string, opts ...Option) (*Estimate, error) {
+	e := newEstimator(ctx, datastore, typesys, storeID, opts...)
+	c, err := e.check(objectType, relation, 0)
+	if err != nil {
+		return nil, err
+	}
+	return e.estimate(c), nil
+}
+
+// ComputeListObjects estimates the cost of a ListObjects of the objects of objectType on which
+// user has the relation, with the model of typesys and the tuples of the store.
+func ComputeListObjects(ctx context.Context, datastore storage.RelationshipTupleReader, typesys *typesystem.TypeSystem, storeID, objectType, relation, user string, opts ...Option) (*Estimate, error) {
+	e := newEstimator(ctx, datastore, typesys, storeID, opts...)
+	userType, userID, userRelation := tuple.ToUserParts(user)
+	c, objects, err := e.listObjects(objectType, relation, userRef{
+		objectType: userType,
+		relation:   userRelation,
+		wildcard:   userID == tuple.Wildcard,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := e.estimate(c)
+	objects = round(objects)
+	estimate.Objects = &objects
+	return estimate, nil
+}
+
+func (e *estimator) estimate(c cost) *Estimate {
+	estimate := &Estimate{
+		AuthorizationModelID: e.typesys.GetAuthorizationModelID(),
+		DatastoreQueries:     round(c.queries),
+		Dispatches:           round(c.dispatches),
+		Depth:                c.depth,
+		Warnings:             e.warnings,
+		Types:                []*TypeStats{},
+		Relations:            []*RelationStats{},
+	}
+	for _, sample := range e.samples {
+		estimate.Types = append(estimate.Types, sample.stats)
+		for _, rs := range sample.relations {
+			estimate.Relations = append(estimate.Relations, rs)
+		}
+	}
+	sort.Slice(estimate.Types, func(i, j int) bool {
+		return estimate.Types[i].Type < estimate.Types[j].Type
+	})
+	sort.Slice(estimate.Relations, func(i, j int) bool {
+		a, b := estimate.Relations[i], estimate.Relations[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Relation != b.Relation {
+			return a.Relation < b.Relation
+		}
+		return a.UserType < b.UserType
+	})
+	return estimate
+}
+
+func (e *estimator) warn(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	for _, w := range e.warnings {
+		if w == warning {
+			return
+		}
+	}
+	e.warnings = append(e.warnings, warning)
+}
+
+// enter marks the resolution of key at depth, and returns false if it must not be resolved,
+// because it exceeds the resolve node limit or is already being resolved.
+func (e *estimator) enter(key string, depth uint32) bool {
+	if depth >= e.resolveNodeLimit {
+		e.warn("the resolution of %s exceeds the resolve node limit", key)
+		return false
+	}
+	if _, ok := e.visiting[key]; ok {
+		e.warn("%s is recursive: the estimate counts a single level of recursion", key)
+		return false
+	}
+	e.visiting[key] = struct{}{}
+	return true
+}
+
+// check returns the cost of the Check of the relation of an object of objectType.
+func (e *estimator) check(objectType, relation string, depth uint32) (cost, error) {
+	key := tuple.ToObjectRelationString(objectType, relation)
+	if !e.enter("check "+key, depth) {
+		return cost{depth: depth}, nil
+	}
+	defer delete(e.visiting, "check "+key)
+
+	rel, err := e.typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return cost{}, err
+	}
+	return e.checkRewrite(objectType, relation, rel.GetRewrite(), depth)
+}
+
+func (e *estimator) checkRewrite(objectType, relation string, rewrite *openfgav1.Userset, depth uint32) (cost, error) {
+	c := cost{depth: depth}
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		// the direct and wildcard users are read with a query, and the usersets with another
+		c.queries = 1
+		refs, err := e.typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+		if err != nil {
+			return cost{}, err
+		}
+		usersets := false
+		for _, ref := range refs {
+			if ref.GetRelation() == "" {
+				continue
+			}
+			if !usersets {
+				usersets = true
+				c.queries++
+			}
+			n, err := e.fanout(objectType, relation, formatUserType(ref))
+			if err != nil {
+				return cost{}, err
+			}
+			if n == 0 {
+				continue
+			}
+			sub, err := e.check(ref.GetType(), ref.GetRelation(), depth+1)
+			if err != nil {
+				return cost{}, err
+			}
+			c = c.add(sub.dispatched(n))
+		}
+	case *openfgav1.Userset_ComputedUserset:
+		sub, err := e.check(objectType, rw.ComputedUserset.GetRelation(), depth+1)
+		if err != nil {
+			return cost{}, err
+		}
+		c = c.add(sub.dispatched(1))
+	case *openfgav1.Userset_TupleToUserset:
+		// the parents are read with a query, and the computed relation is dispatched for each
+		c.queries = 1
+		tupleset := rw.TupleToUserset.GetTupleset().GetRelation()
+		computed := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		refs, err := e.typesys.GetDirectlyRelatedUserTypes(objectType, tupleset)
+		if err != nil {
+			return cost{}, err
+		}
+		for _, ref := range refs {
+			if ref.GetRelation() != "" || ref.GetWildcard() != nil {
+				continue
+			}
+			if _, err := e.typesys.GetRelation(ref.GetType(), computed); err != nil {
+				continue
+			}
+			n, err := e.fanout(objectType, tupleset, ref.GetType())
+			if err != nil {
+				return cost{}, err
+			}
+			if n == 0 {
+				continue
+			}
+			sub, err := e.check(ref.GetType(), computed, depth+1)
+			if err != nil {
+				return cost{}, err
+			}
+			c = c.add(sub.dispatched(n))
+		}
+	case *openfgav1.Userset_Union:
+		return e.checkChildren(objectType, relation, rw.Union.GetChild(), depth)
+	case *openfgav1.Userset_Intersection:
+		return e.checkChildren(objectType, relation, rw.Intersection.GetChild(), depth)
+	case *openfgav1.Userset_Difference:
+		return e.checkChildren(objectType, relation, []*openfgav1.Userset{rw.Difference.GetBase(), rw.Difference.GetSubtract()}, depth)
+	}
+	return c, nil
+}
+
+// checkChildren returns the cost of the resolution of all the children, since a Check only skips
+// the remaining children of a union once one allows it, or of an intersection once one denies it.
+func (e *estimator) checkChildren(objectType, relation string, children []*openfgav1.Userset, depth uint32) (cost, error) {
+	c := cost{depth: depth}
+	for _, child := range children {
+		sub, err := e.checkRewrite(objectType, relation, child, depth)
+		if err != nil {
+			return cost{}, err
+		}
+		c = c.add(sub)
+	}
+	return c, nil
+}
+
+// userRef is the type of the user of a ListObjects.
+type userRef struct {
+	objectType string
+	relation   string
+	wildcard   bool
+}
+
+// matches reports whether the tuples of the users of ref grant a relation to the user.
+func (u userRef) matches(ref *openfgav1.RelationReference) bool {
+	if ref.GetType() != u.objectType {
+		return false
+	}
+	if ref.GetWildcard() != nil {
+		return u.relation == ""
+	}
+	return !u.wildcard && ref.GetRelation() == u.relation
+}
+
+// listObjects returns the cost of the ListObjects of the objects of objectType on which a user of
+// the type of user has the relation, and the estimated number of these objects.
+func (e *estimator) listObjects(objectType, relation string, user userRef, depth uint32) (cost, float64, error) {
+	key := tuple.ToObjectRelationString(objectType, relation)
+	if !e.enter("list objects "+key, depth) {
+		return cost{depth: depth}, 0, nil
+	}
+	defer delete(e.visiting, "list objects "+key)
+
+	rel, err := e.typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return cost{}, 0, err
+	}
+	return e.listObjectsRewrite(objectType, relation, rel.GetRewrite(), user, depth)
+}
+
+func (e *estimator) listObjectsRewrite(objectType, relation string, rewrite *openfgav1.Userset, user userRef, depth uint32) (cost, float64, error) {
+	c := cost{depth: depth}
+	var objects float64
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		refs, err := e.typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+		if err != nil {
+			return cost{}, 0, err
+		}
+		for _, ref := range refs {
+			if user.matches(ref) {
+				// the objects are read with a query starting with the user
+				n, err := e.reverseFanout(objectType, relation, formatUserType(ref))
+				if err != nil {
+					return cost{}, 0, err
+				}
+				c.queries++
+				objects += n
+			}
+			if ref.GetRelation() == "" {
+				continue
+			}
+			// the usersets are listed, and then the objects are read with a query for each
+			sub, usersets, err := e.listObjects(ref.GetType(), ref.GetRelation(), user, depth+1)
+			if err != nil {
+				return cost{}, 0, err
+			}
+			n, err := e.reverseFanout(objectType, relation, formatUserType(ref))
+			if err != nil {
+				return cost{}, 0, err
+			}
+			c = c.add(sub.dispatched(1))
+			c.queries += usersets
+			objects += usersets * n
+		}
+	case *openfgav1.Userset_ComputedUserset:
+		sub, found, err := e.listObjects(objectType, rw.ComputedUserset.GetRelation(), user, depth+1)
+		if err != nil {
+			return cost{}, 0, err
+		}
+		c = c.add(sub.dispatched(1))
+		objects = found
+	case *openfgav1.Userset_TupleToUserset:
+		// the parents are listed, and then their children are read with a query for each
+		tupleset := rw.TupleToUserset.GetTupleset().GetRelation()
+		computed := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		refs, err := e.typesys.GetDirectlyRelatedUserTypes(objectType, tupleset)
+		if err != nil {
+			return cost{}, 0, err
+		}
+		for _, ref := range refs {
+			if ref.GetRelation() != "" || ref.GetWildcard() != nil {
+				continue
+			}
+			if _, err := e.typesys.GetRelation(ref.GetType(), computed); err != nil {
+				continue
+			}
+			sub, parents, err := e.listObjects(ref.GetType(), computed, user, depth+1)
+			if err != nil {
+				return cost{}, 0, err
+			}
+			n, err := e.reverseFanout(objectType, tupleset, ref.GetType())
+			if err != nil {
+				return cost{}, 0, err
+			}
+			c = c.add(sub.dispatched(1))
+			c.queries += parents
+			objects += parents * n
+		}
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			sub, found, err := e.listObjectsRewrite(objectType, relation, child, user, depth)
+			if err != nil {
+				return cost{}, 0, err
+			}
+			c = c.add(sub)
+			objects += found
+		}
+	case *openfgav1.Userset_Intersection:
+		return e.listObjectsCandidates(objectType, relation, rw.Intersection.GetChild()[0], user, depth)
+	case *openfgav1.Userset_Difference:
+		return e.listObjectsCandidates(objectType, relation, rw.Difference.GetBase(), user, depth)
+	}
+	return c, objects, nil
+}
+
+// listObjectsCandidates returns the cost of the ListObjects of the candidates of base, which are
+// then checked for the relation, and the estimated number of candidates, which bounds the number
+// of objects.
+func (e *estimator) listObjectsCandidates(objectType, relation string, base *openfgav1.Userset, user userRef, depth uint32) (cost, float64, error) {
+	c, candidates, err := e.listObjectsRewrite(objectType, relation, base, user, depth)
+	if err != nil {
+		return cost{}, 0, err
+	}
+	check, err := e.check(objectType, relation, depth+1)
+	if err != nil {
+		return cost{}, 0, err
+	}
+	return c.add(check.dispatched(candidates)), candidates, nil
+}
+
+// fanout returns the average number of tuples of the relation with users of userType per object of
+// objectType.
+func (e *estimator) fanout(objectType, relation, userType string) (float64, error) {
+	sample, err := e.sample(objectType)
+	if err != nil {
+		return 0, err
+	}
+	rs, ok := sample.relations[relation+"@"+userType]
+	if !ok || sample.stats.Objects == 0 {
+		return 0, nil
+	}
+	return float64(rs.Tuples) / float64(sample.stats.Objects), nil
+}
+
+// reverseFanout returns the average number of tuples of the relation of objects of objectType per
+// user of userType.
+func (e *estimator) reverseFanout(objectType, relation, userType string) (float64, error) {
+	sample, err := e.sample(objectType)
+	if err != nil {
+		return 0, err
+	}
+	rs, ok := sample.relations[relation+"@"+userType]
+	if !ok || rs.Users == 0 {
+		return 0, nil
+	}
+	return float64(rs.Tuples) / float64(rs.Users), nil
+}
+
+// sample reads the first tuples of the objects of objectType, up to the sample size, and returns
+// their cardinality statistics.
+func (e *estimator) sample(objectType string) (*typeSample, error) {
+	if sample, ok := e.samples[objectType]; ok {
+		return sample, nil
+	}
+
+	sample := &typeSample{
+		stats:     &TypeStats{Type: objectType},
+		relations: map[string]*RelationStats{},
+	}
+	objects := map[string]struct{}{}
+	relationObjects := map[string]map[string]struct{}{}
+	relationUsers := map[string]map[string]struct{}{}
+
+	var token string
+	for sample.stats.Tuples < e.sampleSize {
+		tuples, next, err := e.datastore.ReadPage(e.ctx, e.storeID, storage.ReadFilter{Object: objectType + ":"}, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(int32(min(samplePageSize, e.sampleSize-sample.stats.Tuples)), token),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tuples {
+			tk := t.GetKey()
+			userType := formatUser(tk.GetUser())
+			key := tk.GetRelation() + "@" + userType
+			rs, ok := sample.relations[key]
+			if !ok {
+				rs = &RelationStats{Type: objectType, Relation: tk.GetRelation(), UserType: userType}
+				sample.relations[key] = rs
+				relationObjects[key] = map[string]struct{}{}
+				relationUsers[key] = map[string]struct{}{}
+			}
+			rs.Tuples++
+			relationObjects[key][tk.GetObject()] = struct{}{}
+			relationUsers[key][tk.GetUser()] = struct{}{}
+			objects[tk.GetObject()] = struct{}{}
+			sample.stats.Tuples++
+		}
+		token = next
+		if token == "" {
+			break
+		}
+	}
+	sample.stats.Objects = len(objects)
+	sample.stats.Truncated = token != ""
+	for key, rs := range sample.relations {
+		rs.Objects = len(relationObjects[key])
+		rs.Users = len(relationUsers[key])
+	}
+
+	switch {
+	case sample.stats.Tuples == 0:
+		e.warn("the store has no tuples of type %s: the estimate assumes none", objectType)
+	case sample.stats.Truncated:
+		e.warn("the tuples of type %s are sampled: the estimate is based on the first %d", objectType, sample.stats.Tuples)
+	}
+	e.samples[objectType] = sample
+	return sample, nil
+}
+
+// formatUser returns the type of user, e.g. "user", "user:*" or "group#member".
+func formatUser(user string) string {
+	userType, userID, userRelation := tuple.ToUserParts(user)
+	switch {
+	case userID == tuple.Wildcard:
+		return tuple.TypedPublicWildcard(userType)
+	case userRelation != "":
+		return tuple.ToObjectRelationString(userType, userRelation)
+	}
+	return userType
+}
+
+// formatUserType returns the type of the users of ref, in the format of formatUser.
+func formatUserType(ref *openfgav1.RelationReference) string {
+	switch {
+	case ref.GetWildcard() != nil:
+		return tuple.TypedPublicWildcard(ref.GetType())
+	case ref.GetRelation() != "":
+		return tuple.ToObjectRelationString(ref.GetType(), ref.GetRelation())
+	}
+	return ref.GetType()
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,117 @@
+package costestimate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestEstimate(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define viewer: [user, group#member]
+		type document
+			relations
+				define parent: [folder]
+				define editor: [user]
+				define viewer: [user, user:*] or editor or viewer from parent
+				define can_delete: editor but not viewer`)
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	require.NoError(t, err)
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	storeID := ulid.Make().String()
+
+	err = ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "parent", "folder:a"),
+		tuple.NewTupleKey("document:1", "parent", "folder:b"),
+		tuple.NewTupleKey("document:2", "parent", "folder:a"),
+		tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:*"),
+		tuple.NewTupleKey("folder:a", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("folder:b", "viewer", "user:bob"),
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+		tuple.NewTupleKey("group:eng", "member", "group:ops#member"),
+		tuple.NewTupleKey("group:ops", "member", "user:bob"),
+	})
+	require.NoError(t, err)
+
+	t.Run("check", func(t *testing.T) {
+		estimate, err := ComputeCheck(ctx, ds, typesys, storeID, "document", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), estimate.AuthorizationModelID)
+
+		// [user, user:*]: 1 query
+		// editor: 1 dispatch and 1 query
+		// viewer from parent: 1 query, and for each of the 1.5 parents per document, 1 dispatch
+		// and the 3 queries and 0.75 dispatches of the viewers of a folder
+		require.InDelta(t, 7.5, estimate.DatastoreQueries, 0.01)
+		require.InDelta(t, 3.63, estimate.Dispatches, 0.01)
+		require.Equal(t, uint32(3), estimate.Depth)
+		require.Nil(t, estimate.Objects)
+		require.Equal(t, []string{"check group#member is recursive: the estimate counts a single level of recursion"}, estimate.Warnings)
+
+		require.Len(t, estimate.Types, 3)
+		require.Equal(t, &TypeStats{Type: "document", Tuples: 5, Objects: 2}, estimate.Types[0])
+		require.Equal(t, &RelationStats{Type: "document", Relation: "parent", UserType: "folder", Tuples: 3, Objects: 2, Users: 2}, estimate.Relations[1])
+	})
+
+	t.Run("list_objects", func(t *testing.T) {
+		estimate, err := ComputeListObjects(ctx, ds, typesys, storeID, "document", "viewer", "user:anne")
+		require.NoError(t, err)
+
+		require.InDelta(t, 8, estimate.DatastoreQueries, 0.01)
+		require.InDelta(t, 4, estimate.Dispatches, 0.01)
+		require.NotNil(t, estimate.Objects)
+		require.InDelta(t, 5, *estimate.Objects, 0.01)
+	})
+
+	t.Run("list_objects_checks_the_candidates_of_an_exclusion", func(t *testing.T) {
+		estimate, err := ComputeListObjects(ctx, ds, typesys, storeID, "document", "can_delete", "user:anne")
+		require.NoError(t, err)
+
+		// 1 dispatch and 1 query for the editors, and 1 dispatch for the check of the candidate: 1
+		// dispatch and 1 query for its editors, and 1 dispatch and the cost of its viewers
+		require.InDelta(t, 1+1+7.5, estimate.DatastoreQueries, 0.01)
+		require.InDelta(t, 1+1+1+1+3.63, estimate.Dispatches, 0.01)
+		require.InDelta(t, 1, *estimate.Objects, 0.01)
+	})
+
+	t.Run("sample_size", func(t *testing.T) {
+		estimate, err := ComputeCheck(ctx, ds, typesys, storeID, "document", "viewer", WithSampleSize(2))
+		require.NoError(t, err)
+
+		require.Equal(t, "document", estimate.Types[0].Type)
+		require.Equal(t, 2, estimate.Types[0].Tuples)
+		require.True(t, estimate.Types[0].Truncated)
+		require.Contains(t, estimate.Warnings, "the tuples of type document are sampled: the estimate is based on the first 2")
+	})
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		_, err := ComputeCheck(ctx, ds, typesys, storeID, "document", "owner")
+		require.ErrorIs(t, err, typesystem.ErrRelationUndefined)
+	})
+}
@@ -0,0 +1,140 @@
+package costestimate
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the cost estimate service.
+	ServiceName = "openfga.costestimate.v1.CostEstimateService"
+
+	// EstimateCheckMethod is the full name of the method that estimates the cost of a Check.
+	EstimateCheckMethod = "/" + ServiceName + "/EstimateCheck"
+
+	// EstimateListObjectsMethod is the full name of the method that estimates the cost of a
+	// ListObjects.
+	EstimateListObjectsMethod = "/" + ServiceName + "/EstimateListObjects"
+
+	// codecName is the content-subtype of the cost estimate requests. The cost estimate service is
+	// not part of the OpenFGA API, so its messages are encoded as JSON instead of generated
+	// protobufs.
+	codecName = "openfga-costestimate-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// EstimateCheckRequest requests the cost of a Check of the relation of the object for the user,
+// with a model of a store, or its latest model if AuthorizationModelID is empty.
+type EstimateCheckRequest struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	Object               string `json:"object"`
+	Relation             string `json:"relation"`
+	User                 string `json:"user"`
+}
+
+// EstimateListObjectsRequest requests the cost of a ListObjects of the objects of the type on
+// which the user has the relation, with a model of a store, or its latest model if
+// AuthorizationModelID is empty.
+type EstimateListObjectsRequest struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	Type                 string `json:"type"`
+	Relation             string `json:"relation"`
+	User                 string `json:"user"`
+}
+
+// Server estimates the cost of the requests. It is implemented by the OpenFGA server.
+type Server interface {
+	EstimateCheckCost(ctx context.Context, req *EstimateCheckRequest) (*Estimate, error)
+	EstimateListObjectsCost(ctx context.Context, req *EstimateListObjectsRequest) (*Estimate, error)
+}
+
+// RegisterServer registers the cost estimate service, which estimates the cost of the requests
+// with srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "EstimateCheck",
+				Handler:    estimateCheckHandler,
+			},
+			{
+				MethodName: "EstimateListObjects",
+				Handler:    estimateListObjectsHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/costestimate/service.go",
+	}, srv)
+}
+
+func estimateCheckHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &EstimateCheckRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).EstimateCheckCost(ctx, req.(*EstimateCheckRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: EstimateCheckMethod}, handler)
+}
+
+func estimateListObjectsHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &EstimateListObjectsRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).EstimateListObjectsCost(ctx, req.(*EstimateListObjectsRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: EstimateListObjectsMethod}, handler)
+}
+
+// EstimateCheck estimates the cost of a Check on conn.
+func EstimateCheck(ctx context.Context, conn grpc.ClientConnInterface, req *EstimateCheckRequest) (*Estimate, error) {
+	out := &Estimate{}
+	if err := conn.Invoke(ctx, EstimateCheckMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EstimateListObjects estimates the cost of a ListObjects on conn.
+func EstimateListObjects(ctx context.Context, conn grpc.ClientConnInterface, req *EstimateListObjectsRequest) (*Estimate, error) {
+	out := &Estimate{}
+	if err := conn.Invoke(ctx, EstimateListObjectsMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
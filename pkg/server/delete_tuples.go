@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// ErrDeleteTuplesFilterRequired is returned by DeleteTuples when the filter is empty: deleting all
+// the tuples of a store is done by deleting the store.
+var ErrDeleteTuplesFilterRequired = status.Error(codes.InvalidArgument, "a filter on the object, object type, relation or user is required")
+
+// DeleteTuples deletes all the tuples of a store that match the tuple key of req, and returns the
+// number of deleted tuples. The tuple key filters the tuples like the tuple key of a Read, except
+// that any combination of its fields can be set: e.g. an object type ("document:") alone, a
+// relation alone, or a user alone. The page size and continuation token of req are ignored.
+//
+// The tuples are deleted in batches of the maximum number of tuples per write, each of which is
+// deleted by a Write, so the deletion is not atomic: if a batch fails, the previous batches stay
+// deleted.
+func (s *Server) DeleteTuples(ctx context.Context, req *openfgav1.ReadRequest) (uint64, error) {
+	tk := req.GetTupleKey()
+	ctx, span := tracer.Start(ctx, "DeleteTuples", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
+		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
+		attribute.KeyValue{Key: "user", Value: attribute.StringValue(tk.GetUser())},
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return 0, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if tk.GetObject() == "" && tk.GetRelation() == "" && tk.GetUser() == "" {
+		return 0, ErrDeleteTuplesFilterRequired
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Read.String(),
+	})
+
+	storeID := req.GetStoreId()
+	err := s.checkAuthz(ctx, storeID, apimethod.Read)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := storage.ReadFilter{
+		Object:   tk.GetObject(),
+		Relation: tk.GetRelation(),
+		User:     tk.GetUser(),
+	}
+	// the first page is read again after each batch: the continuation tokens of some datastores
+	// are offsets, which the deleted tuples would shift
+	opts := storage.ReadPageOptions{
+		Pagination:  storage.NewPaginationOptions(int32(s.datastore.MaxTuplesPerWrite()), ""),
+		Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+	}
+
+	var deleted uint64
+	for {
+		tuples, _, err := s.datastore.ReadPage(ctx, storeID, filter, opts)
+		if err != nil {
+			return deleted, serverErrors.HandleError("", err)
+		}
+		if len(tuples) == 0 {
+			span.SetAttributes(attribute.Int64("deleted_count", int64(deleted)))
+			return deleted, nil
+		}
+
+		deletes := make([]*openfgav1.TupleKeyWithoutCondition, 0, len(tuples))
+		for _, t := range tuples {
+			deletes = append(deletes, tuple.TupleKeyToTupleKeyWithoutCondition(t.GetKey()))
+		}
+		_, err = s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Deletes: &openfgav1.WriteRequestDeletes{
+				TupleKeys: deletes,
+				// the tuples deleted concurrently must not fail the batch
+				OnMissing: "ignore",
+			},
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += uint64(len(deletes))
+	}
+}
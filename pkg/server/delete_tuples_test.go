@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestDeleteTuples(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	// deletes in batches of 2 tuples
+	ds := memory.New(memory.WithMaxTuplesPerWrite(2))
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "delete-tuples"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define viewer: [user]
+				define editor: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	for _, tk := range []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:3", "viewer", "user:bob"),
+		tuple.NewTupleKey("document:4", "editor", "user:bob"),
+		tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("folder:2", "viewer", "user:bob"),
+	} {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+		})
+		require.NoError(t, err)
+	}
+
+	remaining := func(t *testing.T) []string {
+		t.Helper()
+		resp, err := s.Read(ctx, &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		var keys []string
+		for _, tpl := range resp.GetTuples() {
+			keys = append(keys, tuple.TupleKeyToString(tpl.GetKey()))
+		}
+		return keys
+	}
+
+	t.Run("requires_a_filter", func(t *testing.T) {
+		_, err := s.DeleteTuples(ctx, &openfgav1.ReadRequest{StoreId: storeID})
+		require.ErrorIs(t, err, ErrDeleteTuplesFilterRequired)
+		require.Len(t, remaining(t), 7)
+	})
+
+	t.Run("by_object", func(t *testing.T) {
+		deleted, err := s.DeleteTuples(ctx, &openfgav1.ReadRequest{
+			StoreId:  storeID,
+			TupleKey: &openfgav1.ReadRequestTupleKey{Object: "document:1"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), deleted)
+		require.Len(t, remaining(t), 5)
+	})
+
+	t.Run("by_relation", func(t *testing.T) {
+		deleted, err := s.DeleteTuples(ctx, &openfgav1.ReadRequest{
+			StoreId:  storeID,
+			TupleKey: &openfgav1.ReadRequestTupleKey{Relation: "editor"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), deleted)
+		require.Len(t, remaining(t), 4)
+	})
+
+	t.Run("by_object_type_in_batches", func(t *testing.T) {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:5", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:6", "viewer", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+
+		deleted, err := s.DeleteTuples(ctx, &openfgav1.ReadRequest{
+			StoreId:  storeID,
+			TupleKey: &openfgav1.ReadRequestTupleKey{Object: "document:"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), deleted)
+		require.ElementsMatch(t, []string{"folder:1#viewer@user:anne", "folder:2#viewer@user:bob"}, remaining(t))
+	})
+
+	t.Run("by_user", func(t *testing.T) {
+		deleted, err := s.DeleteTuples(ctx, &openfgav1.ReadRequest{
+			StoreId:  storeID,
+			TupleKey: &openfgav1.ReadRequestTupleKey{User: "user:bob"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), deleted)
+		require.Equal(t, []string{"folder:1#viewer@user:anne"}, remaining(t))
+	})
+
+	t.Run("no_match", func(t *testing.T) {
+		deleted, err := s.DeleteTuples(ctx, &openfgav1.ReadRequest{
+			StoreId:  storeID,
+			TupleKey: &openfgav1.ReadRequestTupleKey{User: "user:bob"},
+		})
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+}
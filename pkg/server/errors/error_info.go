@@ -0,0 +1,271 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+const (
+	// ErrorInfoDomain is the domain of the google.rpc.ErrorInfo details attached to the errors.
+	ErrorInfoDomain = "openfga.dev"
+
+	// ErrorReasonHeader is the HTTP header set on the error responses to the reason of the error.
+	ErrorReasonHeader = "Openfga-Error-Reason"
+
+	// errorInfoCodeKey is the key of the metadata of the google.rpc.ErrorInfo details that holds
+	// the OpenFGA error code, e.g. "validation_error".
+	errorInfoCodeKey = "code"
+
+	// ErrorInfoMaxChecksKey is the key of the metadata of the google.rpc.ErrorInfo details of the
+	// ReasonReduceBatchSize errors that holds the number of checks of a batch the server accepts.
+	ErrorInfoMaxChecksKey = "max_checks"
+
+	// RetryAfterHeader is the HTTP header set on the error responses with google.rpc.RetryInfo
+	// details to the number of seconds after which the request can be retried.
+	RetryAfterHeader = "Retry-After"
+)
+
+// The reasons of the google.rpc.ErrorInfo details attached to the errors. Unlike the messages of
+// the errors, the reasons are stable, so that clients can branch on them.
+const (
+	ReasonUnauthenticated          = "UNAUTHENTICATED"
+	ReasonForbidden                = "FORBIDDEN"
+	ReasonValidation               = "VALIDATION"
+	ReasonModelValidation          = "MODEL_VALIDATION"
+	ReasonTupleValidation          = "TUPLE_VALIDATION"
+	ReasonModelNotFound            = "MODEL_NOT_FOUND"
+	ReasonStoreNotFound            = "STORE_NOT_FOUND"
+	ReasonDepthExceeded            = "DEPTH_EXCEEDED"
+	ReasonInvalidContinuationToken = "INVALID_CONTINUATION_TOKEN"
+	ReasonThrottledDispatch        = "THROTTLED_DISPATCH"
+	ReasonDatastoreThrottled       = "DATASTORE_THROTTLED"
+	ReasonReduceBatchSize          = "REDUCE_BATCH_SIZE"
+	ReasonWriteVetoed              = "WRITE_VETOED"
+	ReasonWriteThrottled           = "WRITE_THROTTLED"
+	ReasonResourceExhausted        = "RESOURCE_EXHAUSTED"
+	ReasonCancelled                = "CANCELLED"
+	ReasonDeadlineExceeded         = "DEADLINE_EXCEEDED"
+	ReasonConflict                 = "CONFLICT"
+	ReasonUnavailable              = "UNAVAILABLE"
+	ReasonNotFound                 = "NOT_FOUND"
+	ReasonUnimplemented            = "UNIMPLEMENTED"
+	ReasonInternal                 = "INTERNAL"
+)
+
+// errorCodeReasons maps the OpenFGA error codes that have a more specific reason than the range of
+// their code.
+var errorCodeReasons = map[int32]string{
+	int32(openfgav1.AuthErrorCode_forbidden): ReasonForbidden,
+
+	int32(openfgav1.ErrorCode_authorization_model_resolution_too_complex):       ReasonDepthExceeded,
+	int32(openfgav1.ErrorCode_authorization_model_not_found):                    ReasonModelNotFound,
+	int32(openfgav1.ErrorCode_latest_authorization_model_not_found):             ReasonModelNotFound,
+	int32(openfgav1.ErrorCode_invalid_continuation_token):                       ReasonInvalidContinuationToken,
+	int32(openfgav1.ErrorCode_query_string_type_continuation_token_mismatch):    ReasonInvalidContinuationToken,
+	int32(openfgav1.ErrorCode_cancelled):                                        ReasonCancelled,
+	int32(openfgav1.ErrorCode_invalid_authorization_model):                      ReasonModelValidation,
+	int32(openfgav1.ErrorCode_unsupported_schema_version):                       ReasonModelValidation,
+	int32(openfgav1.ErrorCode_cannot_allow_duplicate_types_in_one_request):      ReasonModelValidation,
+	int32(openfgav1.ErrorCode_cannot_allow_multiple_references_to_one_relation): ReasonModelValidation,
+	int32(openfgav1.ErrorCode_empty_relation_definition):                        ReasonModelValidation,
+	int32(openfgav1.ErrorCode_type_definitions_too_few_items):                   ReasonModelValidation,
+	int32(openfgav1.ErrorCode_type_invalid_length):                              ReasonModelValidation,
+	int32(openfgav1.ErrorCode_type_invalid_pattern):                             ReasonModelValidation,
+	int32(openfgav1.ErrorCode_relations_too_few_items):                          ReasonModelValidation,
+	int32(openfgav1.ErrorCode_relations_too_long):                               ReasonModelValidation,
+	int32(openfgav1.ErrorCode_relations_invalid_pattern):                        ReasonModelValidation,
+	int32(openfgav1.ErrorCode_difference_base_missing_value):                    ReasonModelValidation,
+	int32(openfgav1.ErrorCode_subtract_base_missing_value):                      ReasonModelValidation,
+	int32(openfgav1.ErrorCode_invalid_tuple):                                    ReasonTupleValidation,
+	int32(openfgav1.ErrorCode_invalid_contextual_tuple):                         ReasonTupleValidation,
+	int32(openfgav1.ErrorCode_duplicate_contextual_tuple):                       ReasonTupleValidation,
+	int32(openfgav1.ErrorCode_cannot_allow_duplicate_tuples_in_one_request):     ReasonTupleValidation,
+	int32(openfgav1.ErrorCode_write_failed_due_to_invalid_input):                ReasonTupleValidation,
+	int32(openfgav1.ErrorCode_type_not_found):                                   ReasonTupleValidation,
+	int32(openfgav1.ErrorCode_relation_not_found):                               ReasonTupleValidation,
+
+	int32(openfgav1.UnprocessableContentErrorCode_throttled_timeout_error): ReasonThrottledDispatch,
+
+	int32(openfgav1.InternalErrorCode_deadline_exceeded):  ReasonDeadlineExceeded,
+	int32(openfgav1.InternalErrorCode_resource_exhausted): ReasonResourceExhausted,
+	int32(openfgav1.InternalErrorCode_aborted):            ReasonConflict,
+	int32(openfgav1.InternalErrorCode_unavailable):        ReasonUnavailable,
+
+	int32(openfgav1.NotFoundErrorCode_store_id_not_found): ReasonStoreNotFound,
+	int32(openfgav1.NotFoundErrorCode_unimplemented):      ReasonUnimplemented,
+	int32(codes.Aborted): ReasonConflict,
+}
+
+// ErrorReason returns the reason of the error with status st, i.e. the reason of its
+// google.rpc.ErrorInfo details if it has any, or the reason derived from its code otherwise. It
+// returns an empty string if st is OK.
+func ErrorReason(st *status.Status) string {
+	if st.Code() == codes.OK {
+		return ""
+	}
+	if info := errorInfo(st); info != nil {
+		return info.GetReason()
+	}
+
+	code := ConvertToEncodedErrorCode(st)
+	if reason, ok := errorCodeReasons[code]; ok {
+		return reason
+	}
+
+	switch {
+	case code >= cFirstAuthenticationErrorCode && code < cFirstValidationErrorCode:
+		return ReasonUnauthenticated
+	case code >= cFirstValidationErrorCode && code < cFirstThrottlingErrorCode:
+		return ReasonValidation
+	case code >= cFirstThrottlingErrorCode && code < cFirstInternalErrorCode:
+		return ReasonResourceExhausted
+	case code >= cFirstUnknownEndpointErrorCode:
+		return ReasonNotFound
+	default:
+		return ReasonInternal
+	}
+}
+
+func errorInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// WithErrorInfo returns err with google.rpc.ErrorInfo details that hold the reason of err and its
+// OpenFGA error code. It returns err as is if it is nil or if it already has such details.
+func WithErrorInfo(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st := status.Convert(err)
+	if errorInfo(st) != nil {
+		return err
+	}
+
+	reason := ErrorReason(st)
+	if errors.Is(err, ErrTransactionThrottled) {
+		reason = ReasonDatastoreThrottled
+	}
+
+	withInfo, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorInfoDomain,
+		Metadata: map[string]string{
+			errorInfoCodeKey: NewEncodedError(ConvertToEncodedErrorCode(st), st.Message()).Code(),
+		},
+	})
+	if detailsErr != nil {
+		return err
+	}
+	return withInfo.Err()
+}
+
+// ReduceBatchSize returns the error of a BatchCheck of size checks when the server, under load,
+// only accepts batches of up to maxChecks checks. Its google.rpc.ErrorInfo details have the
+// ReasonReduceBatchSize reason and maxChecks in their metadata, so that clients can split their
+// batches and back off.
+func ReduceBatchSize(size int, maxChecks uint32) error {
+	code := codes.Code(openfgav1.UnprocessableContentErrorCode_throttled_timeout_error)
+	message := fmt.Sprintf("batchCheck received %d checks, the maximum allowed while the server is under load is %d: reduce the batch size", size, maxChecks)
+	st, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason: ReasonReduceBatchSize,
+		Domain: ErrorInfoDomain,
+		Metadata: map[string]string{
+			errorInfoCodeKey:      NewEncodedError(int32(code), message).Code(),
+			ErrorInfoMaxChecksKey: strconv.FormatUint(uint64(maxChecks), 10),
+		},
+	})
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+// WriteVetoed returns the error of a Write vetoed by the write validation hook for reason. Its
+// google.rpc.ErrorInfo details have the ReasonWriteVetoed reason, so that clients can tell the
+// vetoes apart from the invalid tuples.
+func WriteVetoed(reason string) error {
+	code := codes.Code(openfgav1.ErrorCode_validation_error)
+	message := "the write was vetoed by the write validation hook"
+	if reason != "" {
+		message += ": " + reason
+	}
+	st, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason: ReasonWriteVetoed,
+		Domain: ErrorInfoDomain,
+		Metadata: map[string]string{
+			errorInfoCodeKey: NewEncodedError(int32(code), message).Code(),
+		},
+	})
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+// WriteThrottled returns the error of a Write rejected because its store exceeded its write
+// throughput limit. Its google.rpc.ErrorInfo details have the ReasonWriteThrottled reason, and its
+// google.rpc.RetryInfo details hold retryAfter, the time after which the write can be retried.
+func WriteThrottled(storeID string, retryAfter time.Duration) error {
+	code := codes.ResourceExhausted
+	message := fmt.Sprintf("the store '%s' exceeded its write throughput limit: retry after %s", storeID, retryAfter.Round(time.Millisecond))
+	st, err := status.New(code, message).WithDetails(
+		&errdetails.ErrorInfo{
+			Reason: ReasonWriteThrottled,
+			Domain: ErrorInfoDomain,
+			Metadata: map[string]string{
+				errorInfoCodeKey: NewEncodedError(int32(openfgav1.InternalErrorCode_resource_exhausted), message).Code(),
+			},
+		},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+// RetryDelay returns the delay of the google.rpc.RetryInfo details of st, if it has any.
+func RetryDelay(st *status.Status) (time.Duration, bool) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// NewErrorInfoUnaryInterceptor returns a grpc.UnaryServerInterceptor that attaches
+// google.rpc.ErrorInfo details to the errors of the requests. It must come before the logging
+// interceptor, which logs the internal errors that the details hide.
+func NewErrorInfoUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, WithErrorInfo(err)
+	}
+}
+
+// NewErrorInfoStreamingInterceptor returns a grpc.StreamServerInterceptor that attaches
+// google.rpc.ErrorInfo details to the errors of the streams. It must come before the logging
+// interceptor, which logs the internal errors that the details hide.
+func NewErrorInfoStreamingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return WithErrorInfo(handler(srv, stream))
+	}
+}
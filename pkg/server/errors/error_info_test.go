@@ -0,0 +1,198 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+func TestWithErrorInfo(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedReason string
+		expectedCode   string
+	}{
+		{
+			name:           "throttled_dispatch",
+			err:            ErrThrottledTimeout,
+			expectedReason: ReasonThrottledDispatch,
+			expectedCode:   openfgav1.UnprocessableContentErrorCode_throttled_timeout_error.String(),
+		},
+		{
+			name:           "depth_exceeded",
+			err:            ErrAuthorizationModelResolutionTooComplex,
+			expectedReason: ReasonDepthExceeded,
+			expectedCode:   openfgav1.ErrorCode_authorization_model_resolution_too_complex.String(),
+		},
+		{
+			name:           "model_validation",
+			err:            InvalidAuthorizationModelInput(errors.New("invalid model")),
+			expectedReason: ReasonModelValidation,
+			expectedCode:   openfgav1.ErrorCode_invalid_authorization_model.String(),
+		},
+		{
+			name:           "tuple_validation",
+			err:            TypeNotFound("document"),
+			expectedReason: ReasonTupleValidation,
+			expectedCode:   openfgav1.ErrorCode_type_not_found.String(),
+		},
+		{
+			name:           "generic_validation",
+			err:            ValidationError(errors.New("invalid")),
+			expectedReason: ReasonValidation,
+			expectedCode:   openfgav1.ErrorCode_validation_error.String(),
+		},
+		{
+			name:           "store_not_found",
+			err:            ErrStoreIDNotFound,
+			expectedReason: ReasonStoreNotFound,
+			expectedCode:   openfgav1.NotFoundErrorCode_store_id_not_found.String(),
+		},
+		{
+			name:           "datastore_throttled",
+			err:            ErrTransactionThrottled,
+			expectedReason: ReasonDatastoreThrottled,
+			expectedCode:   openfgav1.InternalErrorCode_resource_exhausted.String(),
+		},
+		{
+			name:           "internal_error",
+			err:            NewInternalError("", errors.New("internal")),
+			expectedReason: ReasonInternal,
+			expectedCode:   openfgav1.InternalErrorCode_internal_error.String(),
+		},
+		{
+			name:           "unauthenticated",
+			err:            status.Error(codes.Unauthenticated, "unauthenticated"),
+			expectedReason: ReasonUnauthenticated,
+			expectedCode:   openfgav1.AuthErrorCode_unauthenticated.String(),
+		},
+		{
+			name:           "plain_error",
+			err:            errors.New("plain"),
+			expectedReason: ReasonInternal,
+			expectedCode:   openfgav1.InternalErrorCode_internal_error.String(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := WithErrorInfo(test.err)
+
+			st := status.Convert(err)
+			require.Equal(t, status.Convert(test.err).Code(), st.Code())
+			require.Equal(t, status.Convert(test.err).Message(), st.Message())
+
+			info := errorInfo(st)
+			require.NotNil(t, info)
+			require.Equal(t, test.expectedReason, info.GetReason())
+			require.Equal(t, ErrorInfoDomain, info.GetDomain())
+			require.Equal(t, test.expectedCode, info.GetMetadata()[errorInfoCodeKey])
+			require.Equal(t, test.expectedReason, ErrorReason(st))
+		})
+	}
+
+	t.Run("nil_error", func(t *testing.T) {
+		require.NoError(t, WithErrorInfo(nil))
+	})
+
+	t.Run("existing_error_info_is_kept", func(t *testing.T) {
+		st, err := status.New(codes.Internal, "internal").WithDetails(&errdetails.ErrorInfo{Reason: "CUSTOM", Domain: ErrorInfoDomain})
+		require.NoError(t, err)
+
+		withInfo := WithErrorInfo(st.Err())
+		require.Equal(t, st.Err(), withInfo)
+		require.Equal(t, "CUSTOM", ErrorReason(status.Convert(withInfo)))
+	})
+}
+
+func TestErrorReason(t *testing.T) {
+	require.Empty(t, ErrorReason(status.New(codes.OK, "")))
+	require.Equal(t, ReasonCancelled, ErrorReason(status.Convert(ErrRequestCancelled)))
+	require.Equal(t, ReasonDeadlineExceeded, ErrorReason(status.Convert(ErrRequestDeadlineExceeded)))
+	require.Equal(t, ReasonInvalidContinuationToken, ErrorReason(status.Convert(ErrInvalidContinuationToken)))
+	require.Equal(t, ReasonConflict, ErrorReason(status.New(codes.Aborted, "conflict")))
+	require.Equal(t, ReasonUnimplemented, ErrorReason(status.New(codes.Unimplemented, "unimplemented")))
+}
+
+func TestReduceBatchSize(t *testing.T) {
+	err := ReduceBatchSize(50, 10)
+
+	st := status.Convert(err)
+	require.Equal(t, codes.Code(openfgav1.UnprocessableContentErrorCode_throttled_timeout_error), st.Code())
+	require.Contains(t, st.Message(), "reduce the batch size")
+	require.Equal(t, ReasonReduceBatchSize, ErrorReason(st))
+
+	info := errorInfo(st)
+	require.NotNil(t, info)
+	require.Equal(t, "10", info.GetMetadata()[ErrorInfoMaxChecksKey])
+
+	encoded := NewEncodedError(ConvertToEncodedErrorCode(st), st.Message())
+	require.Equal(t, codes.ResourceExhausted, encoded.GRPCStatusCode)
+
+	// the interceptors keep the details of the error
+	require.Equal(t, err, WithErrorInfo(err))
+}
+
+func TestWriteVetoed(t *testing.T) {
+	err := WriteVetoed("documents cannot be shared outside of the organization")
+
+	st := status.Convert(err)
+	require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), st.Code())
+	require.Equal(t, "the write was vetoed by the write validation hook: documents cannot be shared outside of the organization", st.Message())
+	require.Equal(t, ReasonWriteVetoed, ErrorReason(st))
+
+	encoded := NewEncodedError(ConvertToEncodedErrorCode(st), st.Message())
+	require.Equal(t, codes.InvalidArgument, encoded.GRPCStatusCode)
+	require.Equal(t, "validation_error", encoded.Code())
+
+	require.Equal(t, "the write was vetoed by the write validation hook", status.Convert(WriteVetoed("")).Message())
+}
+
+func TestWriteThrottled(t *testing.T) {
+	st := status.Convert(WriteThrottled("store1", 1500*time.Millisecond))
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Equal(t, ReasonWriteThrottled, errorInfo(st).GetReason())
+
+	retryAfter, ok := RetryDelay(st)
+	require.True(t, ok)
+	require.Equal(t, 1500*time.Millisecond, retryAfter)
+
+	_, ok = RetryDelay(status.Convert(ErrThrottledTimeout))
+	require.False(t, ok)
+}
+
+func TestErrorInfoInterceptors(t *testing.T) {
+	t.Run("unary", func(t *testing.T) {
+		interceptor := NewErrorInfoUnaryInterceptor()
+
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, ErrThrottledTimeout
+		})
+		require.Equal(t, ReasonThrottledDispatch, errorInfo(status.Convert(err)).GetReason())
+
+		resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		interceptor := NewErrorInfoStreamingInterceptor()
+
+		err := interceptor(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+			return ErrAuthorizationModelResolutionTooComplex
+		})
+		require.Equal(t, ReasonDepthExceeded, errorInfo(status.Convert(err)).GetReason())
+	})
+}
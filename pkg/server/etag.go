@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	httpmiddleware "github.com/openfga/openfga/pkg/middleware/http"
+)
+
+const (
+	// ETagHeader is the response header holding the entity tag of an authorization model response.
+	ETagHeader = "Etag"
+	// IfNoneMatchHeader is the request header holding the entity tags already known by the client.
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// authorizationModelETag returns the entity tag for a single authorization model.
+// Authorization models are immutable, so their ID uniquely identifies their content.
+func authorizationModelETag(modelID string) string {
+	return strconv.Quote(modelID)
+}
+
+// authorizationModelsETag returns the entity tag for a page of authorization models.
+func authorizationModelsETag(modelIDs []string, continuationToken string) string {
+	h := sha256.New()
+	for _, id := range modelIDs {
+		h.Write([]byte(id))
+		h.Write([]byte{'\n'})
+	}
+	h.Write([]byte(continuationToken))
+	return strconv.Quote(hex.EncodeToString(h.Sum(nil)[:16]))
+}
+
+// setETag sends the entity tag to the client and reports whether it matches one of the entity
+// tags in the request's If-None-Match header. On a match the HTTP status is set to 304 Not
+// Modified, and the caller should return an empty response.
+func (s *Server) setETag(ctx context.Context, etag string) bool {
+	s.transport.SetHeader(ctx, ETagHeader, etag)
+
+	if !ifNoneMatch(ctx, etag) {
+		return false
+	}
+
+	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusNotModified))
+	return true
+}
+
+// ifNoneMatch reports whether the If-None-Match request header matches the given entity tag,
+// using the weak comparison function defined in RFC 9110.
+func ifNoneMatch(ctx context.Context, etag string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, value := range md.Get(strings.ToLower(IfNoneMatchHeader)) {
+		for _, candidate := range strings.Split(value, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
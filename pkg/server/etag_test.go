@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	httpmiddleware "github.com/openfga/openfga/pkg/middleware/http"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+type headerRecordingTransport struct {
+	mu      sync.Mutex
+	headers map[string]string
+}
+
+func (h *headerRecordingTransport) SetHeader(_ context.Context, key, value string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.headers[key] = value
+}
+
+func (h *headerRecordingTransport) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.headers = map[string]string{}
+}
+
+func (h *headerRecordingTransport) get(key string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.headers[key]
+}
+
+func TestAuthorizationModelETags(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithTransport(transport),
+	)
+	t.Cleanup(s.Close)
+
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user`)
+
+	writeResp, err := s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeResp.GetAuthorizationModelId()
+
+	withIfNoneMatch := func(etag string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(strings.ToLower(IfNoneMatchHeader), etag))
+	}
+
+	t.Run("read_authorization_model", func(t *testing.T) {
+		transport.reset()
+		req := &openfgav1.ReadAuthorizationModelRequest{StoreId: storeID, Id: modelID}
+
+		resp, err := s.ReadAuthorizationModel(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, modelID, resp.GetAuthorizationModel().GetId())
+		etag := transport.get(ETagHeader)
+		require.Equal(t, strconv.Quote(modelID), etag)
+		require.Empty(t, transport.get(httpmiddleware.XHttpCode))
+
+		transport.reset()
+		resp, err = s.ReadAuthorizationModel(withIfNoneMatch(etag), req)
+		require.NoError(t, err)
+		require.Nil(t, resp.GetAuthorizationModel())
+		require.Equal(t, strconv.Itoa(http.StatusNotModified), transport.get(httpmiddleware.XHttpCode))
+
+		transport.reset()
+		resp, err = s.ReadAuthorizationModel(withIfNoneMatch(`"other", W/`+etag), req)
+		require.NoError(t, err)
+		require.Nil(t, resp.GetAuthorizationModel())
+		require.Equal(t, strconv.Itoa(http.StatusNotModified), transport.get(httpmiddleware.XHttpCode))
+
+		transport.reset()
+		resp, err = s.ReadAuthorizationModel(withIfNoneMatch(`"other"`), req)
+		require.NoError(t, err)
+		require.Equal(t, modelID, resp.GetAuthorizationModel().GetId())
+		require.Empty(t, transport.get(httpmiddleware.XHttpCode))
+	})
+
+	t.Run("read_authorization_models", func(t *testing.T) {
+		transport.reset()
+		req := &openfgav1.ReadAuthorizationModelsRequest{StoreId: storeID}
+
+		resp, err := s.ReadAuthorizationModels(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, resp.GetAuthorizationModels(), 1)
+		etag := transport.get(ETagHeader)
+		require.NotEmpty(t, etag)
+
+		transport.reset()
+		resp, err = s.ReadAuthorizationModels(withIfNoneMatch(etag), req)
+		require.NoError(t, err)
+		require.Empty(t, resp.GetAuthorizationModels())
+		require.Equal(t, strconv.Itoa(http.StatusNotModified), transport.get(httpmiddleware.XHttpCode))
+
+		// A new model changes the entity tag of the latest page.
+		_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+
+		transport.reset()
+		resp, err = s.ReadAuthorizationModels(withIfNoneMatch(etag), req)
+		require.NoError(t, err)
+		require.Len(t, resp.GetAuthorizationModels(), 2)
+		require.NotEqual(t, etag, transport.get(ETagHeader))
+		require.Empty(t, transport.get(httpmiddleware.XHttpCode))
+	})
+}
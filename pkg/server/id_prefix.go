@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// IDPrefixHeader filters the results of ListObjects and StreamedListObjects by object ID, and the
+// results of ListUsers by user ID, so that clients looking for e.g. the documents under a folder
+// path encoded in their IDs don't pull all of them. When a request sets it, only the results whose
+// ID starts with its value are returned. Wildcards (e.g. user:*) are still returned by ListUsers.
+const IDPrefixHeader = "Openfga-Id-Prefix"
+
+// idPrefixFromHeader returns the prefix requested in the IDPrefixHeader, or the empty string.
+func idPrefixFromHeader(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(IDPrefixHeader))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
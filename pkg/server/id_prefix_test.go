@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestIDPrefixHeader(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user, user:*]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:eng/roadmap", "viewer", "user:eng/anne"),
+		tuple.NewTupleKey("document:eng/budget", "viewer", "user:eng/anne"),
+		tuple.NewTupleKey("document:sales/budget", "viewer", "user:eng/anne"),
+		tuple.NewTupleKey("document:eng/roadmap", "viewer", "user:sales/bob"),
+		tuple.NewTupleKey("document:eng/roadmap", "viewer", "user:*"),
+	}))
+
+	withPrefix := func(prefix string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(IDPrefixHeader, prefix))
+	}
+
+	t.Run("list_objects", func(t *testing.T) {
+		resp, err := s.ListObjects(withPrefix("eng/"), &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:eng/anne",
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"document:eng/roadmap", "document:eng/budget"}, resp.GetObjects())
+
+		resp, err = s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:eng/anne",
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetObjects(), 3)
+	})
+
+	t.Run("list_users", func(t *testing.T) {
+		resp, err := s.ListUsers(withPrefix("eng/"), &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "document", Id: "eng/roadmap"},
+			Relation:    "viewer",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*openfgav1.User{
+			tuple.StringToUserProto("user:eng/anne"),
+			tuple.StringToUserProto("user:*"),
+		}, resp.GetUsers())
+	})
+
+	t.Run("list_users_expanded_wildcard", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(IDPrefixHeader, "sales/", ListUsersWildcardsHeader, "expand"))
+		resp, err := s.ListUsers(ctx, &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "document", Id: "eng/roadmap"},
+			Relation:    "viewer",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*openfgav1.User{tuple.StringToUserProto("user:sales/bob")}, resp.GetUsers())
+	})
+}
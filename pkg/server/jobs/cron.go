@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron is returned for the cron expressions that can't be parsed.
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// maxCronSearch bounds the search of the next time of an expression, e.g. "0 0 30 2 *" never
+// matches.
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// cronDescriptors are the shorthands of the common expressions.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField is the range of the values of a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Cron is a parsed cron expression, evaluated in UTC.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar are set for the day fields that are "*": a day matches when both day
+	// fields match, or when either matches if neither is "*", like the classic cron.
+	domStar, dowStar bool
+}
+
+// ParseCron parses a cron expression of five fields, the minute, hour, day of month, month and day
+// of week, e.g. "30 2 * * 1-5". Each field is "*", a value, a range "a-b", a list "a,b" of values
+// or ranges, optionally followed by a step "/n". Sunday is 0 or 7. The descriptors "@yearly",
+// "@monthly", "@weekly", "@daily" and "@hourly" are also accepted.
+func ParseCron(expr string) (*Cron, error) {
+	if descriptor, ok := cronDescriptors[strings.TrimSpace(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w '%s': expected %d fields", ErrInvalidCron, expr, len(cronFields))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		bits, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': %w", ErrInvalidCron, expr, err)
+		}
+		values[i] = bits
+	}
+
+	c := &Cron{
+		minute:  values[0],
+		hour:    values[1],
+		dom:     values[2],
+		month:   values[3],
+		dow:     values[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	// Sunday is both 0 and 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parseCronField returns the bits of the values of a field.
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step '%s' of the %s", stepPart, bounds.name)
+			}
+		}
+
+		low, high := bounds.min, bounds.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			low, err = parseCronValue(lowPart, bounds)
+			if err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				high, err = parseCronValue(highPart, bounds)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "a/n" is every n from a
+				high = bounds.max
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range '%s' of the %s", rangePart, bounds.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, bounds cronField) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < bounds.min || v > bounds.max {
+		return 0, fmt.Errorf("invalid %s '%s', expected a value between %d and %d", bounds.name, s, bounds.min, bounds.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t that matches the expression, in UTC, or the zero
+// time if no time within the next five years matches.
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *Cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
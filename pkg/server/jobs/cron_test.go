@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2026, 1, 14, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2026, 1, 14, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expected: time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC)},
+		{expr: "5/20 * * * *", expected: time.Date(2026, 1, 14, 10, 25, 0, 0, time.UTC)},
+		{expr: "0 2 * * *", expected: time.Date(2026, 1, 15, 2, 0, 0, 0, time.UTC)},
+		{expr: "@daily", expected: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", expected: time.Date(2026, 1, 14, 11, 0, 0, 0, time.UTC)},
+		{expr: "@weekly", expected: time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", expected: time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", expected: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "@yearly", expected: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "30 9 * * 1-5", expected: time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC)},
+		{expr: "0 12 1,20 * *", expected: time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)},
+		// either day field matches when neither is "*"
+		{expr: "0 0 20 * 5", expected: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", expected: time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			c, err := ParseCron(test.expr)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, c.Next(from))
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseCron(expr)
+			require.ErrorIs(t, err, ErrInvalidCron)
+		})
+	}
+}
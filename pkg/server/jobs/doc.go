@@ -0,0 +1,28 @@
+// Package jobs runs the long operations of the stores, such as the purge of the tuples matching a
+// filter or the collection of the orphaned tuples, as background jobs, so that the clients don't
+// hold a connection open for minutes: they create a job, then poll its status until it finishes.
+//
+// Each kind of job is registered on a Manager with the function that runs it. The jobs that a
+// replica creates are queued and run by its pool of workers, and their state, progress and result
+// are persisted in the datastore, so that any replica reports them. A job is canceled by any
+// replica too: the replica that runs it reads the cancellation with its next heartbeat, which also
+// persists its progress. A job that is not finished and whose heartbeat stopped, e.g. because its
+// replica crashed, is reported as failed.
+//
+// The jobs of a store are created, read, listed and canceled by the jobs service, whose messages
+// are encoded as JSON: /openfga.jobs.v1.JobService/Create, /openfga.jobs.v1.JobService/Get,
+// /openfga.jobs.v1.JobService/List and /openfga.jobs.v1.JobService/Cancel. They are also served
+// over HTTP as POST /stores/{store_id}/jobs, GET /stores/{store_id}/jobs/{job_id},
+// GET /stores/{store_id}/jobs and POST /stores/{store_id}/jobs/{job_id}/cancel.
+//
+// A job can also recur, e.g. a nightly access review export or a weekly report of the orphaned
+// tuples, with a schedule of its kind and params and a cron expression (see ParseCron), persisted
+// in the datastore too. Every replica runs the scheduler, but only the replica that holds its lease
+// creates the jobs of the due schedules, so that each run creates a single job; another replica
+// acquires the lease when its holder stops renewing it. The schedules of a store are managed with
+// /openfga.jobs.v1.JobService/CreateSchedule, /openfga.jobs.v1.JobService/GetSchedule,
+// /openfga.jobs.v1.JobService/ListSchedules and /openfga.jobs.v1.JobService/DeleteSchedule, also
+// served over HTTP as POST /stores/{store_id}/job-schedules, GET /stores/{store_id}/job-schedules,
+// GET /stores/{store_id}/job-schedules/{schedule_id} and
+// DELETE /stores/{store_id}/job-schedules/{schedule_id}.
+package jobs
@@ -0,0 +1,634 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// DefaultWorkers is the default number of jobs that a replica runs concurrently.
+	DefaultWorkers = 4
+
+	// DefaultQueueSize is the default number of jobs that a replica queues before refusing to
+	// create more.
+	DefaultQueueSize = 100
+
+	// DefaultJobRetention is the default duration for which the finished jobs are kept.
+	DefaultJobRetention = 24 * time.Hour
+
+	// DefaultHeartbeatInterval is the default interval at which the progress of the jobs is
+	// persisted and their cancellation is read.
+	DefaultHeartbeatInterval = 10 * time.Second
+
+	// missedHeartbeats is the number of heartbeats after which a job that is not finished is
+	// reported as failed.
+	missedHeartbeats = 3
+
+	// finalWriteTimeout bounds the persistence of the final state of a job, which is written even
+	// when the Manager stops.
+	finalWriteTimeout = 5 * time.Second
+)
+
+var (
+	// ErrJobNotFound is returned for the jobs that don't exist, or whose retention elapsed.
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrUnknownKind is returned when creating a job of a kind that is not registered.
+	ErrUnknownKind = errors.New("unknown job kind")
+
+	// ErrQueueFull is returned when creating a job while the queue of the replica is full.
+	ErrQueueFull = errors.New("the job queue is full")
+
+	// errStopped is the error of the jobs that were running or queued when the Manager stopped.
+	errStopped = errors.New("the server stopped before the job finished")
+
+	// errAbandoned is the error of the jobs whose heartbeat stopped.
+	errAbandoned = errors.New("the replica that ran the job stopped before the job finished")
+)
+
+// State is the state of a job.
+type State string
+
+const (
+	// StatePending is the state of the queued jobs.
+	StatePending State = "pending"
+
+	// StateRunning is the state of the jobs that a worker runs.
+	StateRunning State = "running"
+
+	// StateSucceeded is the state of the jobs that finished without error.
+	StateSucceeded State = "succeeded"
+
+	// StateFailed is the state of the jobs that stopped on an error.
+	StateFailed State = "failed"
+
+	// StateCanceled is the state of the jobs that stopped because their cancellation was
+	// requested. The changes they made are not rolled back.
+	StateCanceled State = "canceled"
+)
+
+// Finished returns whether the jobs in the state are no longer queued or running.
+func (s State) Finished() bool {
+	return s != StatePending && s != StateRunning
+}
+
+// Job reports the state of a job of a store.
+type Job struct {
+	ID      string          `json:"id"`
+	StoreID string          `json:"store_id"`
+	Kind    string          `json:"kind"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	State   State           `json:"state"`
+
+	// Completed and Total are the units of work of the job, e.g. deleted tuples, that are done and
+	// that are expected, as reported by the job.
+	Completed uint64 `json:"completed"`
+	Total     uint64 `json:"total"`
+
+	// Progress is the ratio of completed units of work, between 0 and 1.
+	Progress float64 `json:"progress"`
+
+	// Result is the result of the succeeded jobs, whose format depends on their kind.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error is the error of the failed jobs.
+	Error string `json:"error,omitempty"`
+
+	// CancelRequested is set once the cancellation of the job is requested, until it stops.
+	CancelRequested bool `json:"cancel_requested,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// EstimatedCompletion is the time at which the running jobs should finish at the rate they
+	// have completed their work so far. It is unset until they report progress.
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
+}
+
+// ProgressFunc reports the units of work of a running job that are completed and expected.
+type ProgressFunc func(completed, total uint64)
+
+// RunFunc runs a job of a store with its params until it finishes or ctx is canceled, reporting
+// its progress, and returns its result, which is encoded as JSON. The ID of the job is returned by
+// JobIDFromContext(ctx).
+type RunFunc func(ctx context.Context, storeID string, params json.RawMessage, progress ProgressFunc) (any, error)
+
+type jobIDContextKey struct{}
+
+// JobIDFromContext returns the ID of the job that a RunFunc runs with ctx, or the empty string.
+func JobIDFromContext(ctx context.Context) string {
+	jobID, _ := ctx.Value(jobIDContextKey{}).(string)
+	return jobID
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithWorkers sets the number of jobs that the Manager runs concurrently. It defaults to
+// DefaultWorkers.
+func WithWorkers(n int) Option {
+	return func(m *Manager) {
+		m.workers = n
+	}
+}
+
+// WithQueueSize sets the number of jobs that the Manager queues before refusing to create more.
+// It defaults to DefaultQueueSize.
+func WithQueueSize(n int) Option {
+	return func(m *Manager) {
+		m.queueSize = n
+	}
+}
+
+// WithJobRetention sets the duration for which the finished jobs are kept. It defaults to
+// DefaultJobRetention.
+func WithJobRetention(retention time.Duration) Option {
+	return func(m *Manager) {
+		m.retention = retention
+	}
+}
+
+// WithHeartbeatInterval sets the interval at which the progress of the jobs is persisted and
+// their cancellation is read. It defaults to DefaultHeartbeatInterval.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.heartbeat = interval
+	}
+}
+
+// WithLogger sets the logger of the failed jobs.
+func WithLogger(l logger.Logger) Option {
+	return func(m *Manager) {
+		m.logger = l
+	}
+}
+
+// WithSchedules enables the recurring jobs, whose schedules are persisted with backend. The
+// replica that holds the lease of the scheduler creates the jobs of the due schedules.
+func WithSchedules(backend storage.ScheduleBackend) Option {
+	return func(m *Manager) {
+		m.schedules = backend
+	}
+}
+
+// WithScheduleInterval sets the interval at which the replicas acquire the lease of the
+// scheduler, and its holder creates the jobs of the due schedules. It defaults to
+// DefaultScheduleInterval.
+func WithScheduleInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.scheduleInterval = interval
+	}
+}
+
+// localJob is a job created by this replica that is not finished.
+type localJob struct {
+	job      *Job               // GUARDED_BY(Manager.mu)
+	cancel   context.CancelFunc // GUARDED_BY(Manager.mu), set while the job runs
+	canceled bool               // GUARDED_BY(Manager.mu)
+
+	// writeMu serializes the writes of the job, each of its latest state, so that a heartbeat
+	// never overwrites its final state.
+	writeMu sync.Mutex
+}
+
+// Manager queues the jobs created on this replica and runs them with a pool of workers, with the
+// function registered for their kind. It persists the jobs with a storage.JobBackend, so that
+// they are reported and canceled by any replica.
+type Manager struct {
+	backend   storage.JobBackend
+	workers   int
+	queueSize int
+	retention time.Duration
+	heartbeat time.Duration
+	logger    logger.Logger
+
+	schedules        storage.ScheduleBackend
+	scheduleInterval time.Duration
+	// holder identifies this replica as the holder of the lease of the scheduler.
+	holder string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	queue  chan *localJob
+
+	mu    sync.Mutex
+	kinds map[string]RunFunc
+	local map[string]*localJob
+}
+
+// NewManager returns a Manager that persists the jobs with backend, and starts its workers. Stop
+// must be called to stop them.
+func NewManager(backend storage.JobBackend, opts ...Option) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		backend:   backend,
+		workers:   DefaultWorkers,
+		queueSize: DefaultQueueSize,
+		retention: DefaultJobRetention,
+		heartbeat: DefaultHeartbeatInterval,
+		logger:    logger.NewNoopLogger(),
+
+		scheduleInterval: DefaultScheduleInterval,
+		holder:           ulid.Make().String(),
+
+		ctx:    ctx,
+		cancel: cancel,
+		kinds:  map[string]RunFunc{},
+		local:  map[string]*localJob{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.queue = make(chan *localJob, m.queueSize)
+
+	m.wg.Add(m.workers + 1)
+	for range m.workers {
+		go m.work()
+	}
+	go m.heartbeats()
+	if m.schedules != nil {
+		m.wg.Add(1)
+		go m.runSchedules()
+	}
+	return m
+}
+
+// Register registers the function that runs the jobs of kind.
+func (m *Manager) Register(kind string, run RunFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kinds[kind] = run
+}
+
+// Create queues a job of kind for storeID with params and returns it.
+func (m *Manager) Create(ctx context.Context, storeID, kind string, params json.RawMessage) (*Job, error) {
+	m.mu.Lock()
+	_, ok := m.kinds[kind]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownKind, kind)
+	}
+	if m.ctx.Err() != nil {
+		return nil, errStopped
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:        ulid.Make().String(),
+		StoreID:   storeID,
+		Kind:      kind,
+		Params:    params,
+		State:     StatePending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.write(ctx, job); err != nil {
+		return nil, err
+	}
+	snapshot := *job
+
+	local := &localJob{job: job}
+	m.mu.Lock()
+	m.local[job.ID] = local
+	m.mu.Unlock()
+
+	select {
+	case m.queue <- local:
+		return &snapshot, nil
+	default:
+		m.finish(local, StateFailed, nil, ErrQueueFull)
+		return nil, ErrQueueFull
+	}
+}
+
+// Get returns the job jobID of storeID.
+func (m *Manager) Get(ctx context.Context, storeID, jobID string) (*Job, error) {
+	if job, ok := m.localSnapshot(storeID, jobID); ok {
+		return job, nil
+	}
+
+	record, err := m.backend.ReadJob(ctx, storeID, jobID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return m.decode(record)
+}
+
+// List returns the jobs of storeID, from the oldest to the most recent.
+func (m *Manager) List(ctx context.Context, storeID string) ([]*Job, error) {
+	records, err := m.backend.ListJobs(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(records))
+	for _, record := range records {
+		if job, ok := m.localSnapshot(storeID, record.ID); ok {
+			jobs = append(jobs, job)
+			continue
+		}
+		job, err := m.decode(record)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Cancel requests the cancellation of the job jobID of storeID, and returns it. The jobs queued
+// on this replica are canceled immediately, and the running jobs stop as soon as the replica that
+// runs them reads the cancellation. The finished jobs are not changed.
+func (m *Manager) Cancel(ctx context.Context, storeID, jobID string) (*Job, error) {
+	job, err := m.Get(ctx, storeID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.State.Finished() {
+		return job, nil
+	}
+
+	if err := m.backend.CancelJob(ctx, storeID, jobID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	m.cancelLocal(jobID)
+	return m.Get(ctx, storeID, jobID)
+}
+
+// Stop stops the running jobs and waits for them to return. They fail, like the queued jobs.
+func (m *Manager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	queued := make([]*localJob, 0, len(m.local))
+	for _, local := range m.local {
+		queued = append(queued, local)
+	}
+	m.mu.Unlock()
+
+	for _, local := range queued {
+		m.finish(local, StateFailed, nil, errStopped)
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case local := <-m.queue:
+			m.run(local)
+		}
+	}
+}
+
+func (m *Manager) run(local *localJob) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	if local.job.State != StatePending {
+		// canceled while it was queued
+		m.mu.Unlock()
+		return
+	}
+	run := m.kinds[local.job.Kind]
+	now := time.Now()
+	local.job.State = StateRunning
+	local.job.StartedAt = &now
+	local.job.UpdatedAt = now
+	local.cancel = cancel
+	storeID, params := local.job.StoreID, local.job.Params
+	ctx = context.WithValue(ctx, jobIDContextKey{}, local.job.ID)
+	m.mu.Unlock()
+	m.persist(m.ctx, local)
+
+	result, err := run(ctx, storeID, params, func(completed, total uint64) {
+		m.progress(local, completed, total)
+	})
+
+	m.mu.Lock()
+	canceled := local.canceled
+	m.mu.Unlock()
+
+	switch {
+	case err == nil:
+		m.finish(local, StateSucceeded, result, nil)
+	case canceled:
+		m.finish(local, StateCanceled, nil, nil)
+	case m.ctx.Err() != nil:
+		m.finish(local, StateFailed, nil, errStopped)
+	default:
+		m.finish(local, StateFailed, nil, err)
+	}
+}
+
+// progress records the progress reported by a running job, and estimates its completion.
+func (m *Manager) progress(local *localJob, completed, total uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := local.job
+	now := time.Now()
+	job.Completed = completed
+	job.Total = max(total, completed)
+	job.UpdatedAt = now
+	if job.Total == 0 || job.Completed == 0 {
+		return
+	}
+	job.Progress = float64(job.Completed) / float64(job.Total)
+
+	elapsed := now.Sub(*job.StartedAt)
+	remaining := time.Duration(float64(elapsed) * float64(job.Total-job.Completed) / float64(job.Completed))
+	completion := now.Add(remaining)
+	job.EstimatedCompletion = &completion
+}
+
+// finish records the final state of a job, persists it and forgets it, unless it is already
+// finished.
+func (m *Manager) finish(local *localJob, state State, result any, err error) {
+	var data json.RawMessage
+	if state == StateSucceeded && result != nil {
+		var marshalErr error
+		data, marshalErr = json.Marshal(result)
+		if marshalErr != nil {
+			state, err = StateFailed, fmt.Errorf("failed to encode the result of the job: %w", marshalErr)
+		}
+	}
+
+	m.mu.Lock()
+	job := local.job
+	if job.State.Finished() {
+		m.mu.Unlock()
+		return
+	}
+	job.State = state
+	job.UpdatedAt = time.Now()
+	job.EstimatedCompletion = nil
+	job.Result = data
+	if state == StateSucceeded {
+		job.Progress = 1
+	}
+	if err != nil {
+		job.Error = err.Error()
+	}
+	m.mu.Unlock()
+
+	if state == StateFailed {
+		m.logger.Error("job failed",
+			zap.String("job_id", job.ID),
+			zap.String("store_id", job.StoreID),
+			zap.String("kind", job.Kind),
+			zap.Error(err),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), finalWriteTimeout)
+	defer cancel()
+	m.persist(ctx, local)
+
+	m.mu.Lock()
+	delete(m.local, job.ID)
+	m.mu.Unlock()
+}
+
+// cancelLocal cancels the job jobID if this replica queued or runs it.
+func (m *Manager) cancelLocal(jobID string) {
+	m.mu.Lock()
+	local, ok := m.local[jobID]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	local.canceled = true
+	local.job.CancelRequested = true
+	pending := local.job.State == StatePending
+	cancel := local.cancel
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if pending {
+		m.finish(local, StateCanceled, nil, nil)
+	}
+}
+
+func (m *Manager) heartbeats() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.beat()
+		}
+	}
+}
+
+// beat persists the jobs of this replica, cancels the ones whose cancellation was requested on
+// another replica, and deletes the jobs whose retention elapsed.
+func (m *Manager) beat() {
+	m.mu.Lock()
+	locals := make([]*localJob, 0, len(m.local))
+	for _, local := range m.local {
+		locals = append(locals, local)
+	}
+	m.mu.Unlock()
+
+	for _, local := range locals {
+		m.persist(m.ctx, local)
+
+		m.mu.Lock()
+		storeID, jobID := local.job.StoreID, local.job.ID
+		m.mu.Unlock()
+		record, err := m.backend.ReadJob(m.ctx, storeID, jobID)
+		if err == nil && record.CancelRequested {
+			m.cancelLocal(jobID)
+		}
+	}
+
+	if _, err := m.backend.DeleteJobs(m.ctx, time.Now().Add(-m.retention)); err != nil && m.ctx.Err() == nil {
+		m.logger.Error("failed to delete the expired jobs", zap.Error(err))
+	}
+}
+
+// localSnapshot returns a copy of the job jobID of storeID if this replica queued or runs it.
+func (m *Manager) localSnapshot(storeID, jobID string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	local, ok := m.local[jobID]
+	if !ok || local.job.StoreID != storeID {
+		return nil, false
+	}
+	snapshot := *local.job
+	return &snapshot, true
+}
+
+// persist writes the latest state of a job of this replica.
+func (m *Manager) persist(ctx context.Context, local *localJob) {
+	local.writeMu.Lock()
+	defer local.writeMu.Unlock()
+
+	m.mu.Lock()
+	snapshot := *local.job
+	m.mu.Unlock()
+
+	if err := m.write(ctx, &snapshot); err != nil && ctx.Err() == nil {
+		m.logger.Error("failed to persist a job", zap.String("job_id", snapshot.ID), zap.Error(err))
+	}
+}
+
+func (m *Manager) write(ctx context.Context, job *Job) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return m.backend.WriteJob(ctx, &storage.Job{
+		Store:   job.StoreID,
+		ID:      job.ID,
+		Kind:    job.Kind,
+		Done:    job.State.Finished(),
+		Payload: payload,
+	})
+}
+
+// decode returns the job persisted in record. The jobs that are not finished and whose
+// heartbeat stopped are reported as failed.
+func (m *Manager) decode(record *storage.Job) (*Job, error) {
+	job := &Job{}
+	if err := json.Unmarshal(record.Payload, job); err != nil {
+		return nil, fmt.Errorf("failed to decode the job '%s': %w", record.ID, err)
+	}
+	job.CancelRequested = job.CancelRequested || record.CancelRequested
+	if !job.State.Finished() && time.Since(record.UpdatedAt) > missedHeartbeats*m.heartbeat {
+		job.State = StateFailed
+		job.Error = errAbandoned.Error()
+		job.EstimatedCompletion = nil
+	}
+	return job, nil
+}
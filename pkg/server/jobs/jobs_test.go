@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+// waitForJob waits for the job to finish and returns it.
+func waitForJob(t *testing.T, m *Manager, storeID, jobID string) *Job {
+	t.Helper()
+
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(context.Background(), storeID, jobID)
+		require.NoError(t, err)
+		return job.State.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestManager(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New().(*memory.MemoryBackend)
+	t.Cleanup(ds.Close)
+
+	m := NewManager(ds, WithHeartbeatInterval(10*time.Millisecond))
+	t.Cleanup(m.Stop)
+
+	m.Register("count", func(_ context.Context, _ string, params json.RawMessage, progress ProgressFunc) (any, error) {
+		var n uint64
+		if err := json.Unmarshal(params, &n); err != nil {
+			return nil, err
+		}
+		for i := range n {
+			progress(i+1, n)
+		}
+		return map[string]uint64{"counted": n}, nil
+	})
+	m.Register("fail", func(context.Context, string, json.RawMessage, ProgressFunc) (any, error) {
+		return nil, errors.New("boom")
+	})
+	m.Register("block", func(ctx context.Context, _ string, _ json.RawMessage, _ ProgressFunc) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	m.Register("id", func(ctx context.Context, _ string, _ json.RawMessage, _ ProgressFunc) (any, error) {
+		return JobIDFromContext(ctx), nil
+	})
+
+	storeID := ulid.Make().String()
+
+	t.Run("succeeded", func(t *testing.T) {
+		created, err := m.Create(ctx, storeID, "count", json.RawMessage(`3`))
+		require.NoError(t, err)
+		require.Equal(t, "count", created.Kind)
+
+		job := waitForJob(t, m, storeID, created.ID)
+		require.Equal(t, StateSucceeded, job.State)
+		require.Equal(t, uint64(3), job.Completed)
+		require.Equal(t, uint64(3), job.Total)
+		require.InDelta(t, 1, job.Progress, 0)
+		require.JSONEq(t, `{"counted":3}`, string(job.Result))
+		require.NotNil(t, job.StartedAt)
+		require.Nil(t, job.EstimatedCompletion)
+
+		// the finished job is read from the datastore
+		record, err := ds.ReadJob(ctx, storeID, created.ID)
+		require.NoError(t, err)
+		require.True(t, record.Done)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		created, err := m.Create(ctx, storeID, "fail", nil)
+		require.NoError(t, err)
+
+		job := waitForJob(t, m, storeID, created.ID)
+		require.Equal(t, StateFailed, job.State)
+		require.Equal(t, "boom", job.Error)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		created, err := m.Create(ctx, storeID, "block", nil)
+		require.NoError(t, err)
+
+		_, err = m.Cancel(ctx, storeID, created.ID)
+		require.NoError(t, err)
+
+		job := waitForJob(t, m, storeID, created.ID)
+		require.Equal(t, StateCanceled, job.State)
+		require.True(t, job.CancelRequested)
+	})
+
+	t.Run("canceled_by_another_replica", func(t *testing.T) {
+		created, err := m.Create(ctx, storeID, "block", nil)
+		require.NoError(t, err)
+
+		other := NewManager(ds)
+		t.Cleanup(other.Stop)
+		_, err = other.Cancel(ctx, storeID, created.ID)
+		require.NoError(t, err)
+
+		job := waitForJob(t, other, storeID, created.ID)
+		require.Equal(t, StateCanceled, job.State)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		jobs, err := m.List(ctx, storeID)
+		require.NoError(t, err)
+		require.Len(t, jobs, 4)
+		require.Equal(t, "count", jobs[0].Kind)
+
+		jobs, err = m.List(ctx, ulid.Make().String())
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+
+	t.Run("job_id_in_context", func(t *testing.T) {
+		created, err := m.Create(ctx, ulid.Make().String(), "id", nil)
+		require.NoError(t, err)
+
+		job := waitForJob(t, m, created.StoreID, created.ID)
+		require.Equal(t, StateSucceeded, job.State)
+		require.JSONEq(t, `"`+created.ID+`"`, string(job.Result))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := m.Create(ctx, storeID, "unknown", nil)
+		require.ErrorIs(t, err, ErrUnknownKind)
+
+		_, err = m.Get(ctx, storeID, ulid.Make().String())
+		require.ErrorIs(t, err, ErrJobNotFound)
+
+		_, err = m.Cancel(ctx, storeID, ulid.Make().String())
+		require.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
+
+func TestManagerStop(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New().(*memory.MemoryBackend)
+	t.Cleanup(ds.Close)
+
+	m := NewManager(ds, WithWorkers(1))
+	m.Register("block", func(ctx context.Context, _ string, _ json.RawMessage, _ ProgressFunc) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	storeID := ulid.Make().String()
+	running, err := m.Create(ctx, storeID, "block", nil)
+	require.NoError(t, err)
+	queued, err := m.Create(ctx, storeID, "block", nil)
+	require.NoError(t, err)
+	m.Stop()
+
+	for _, id := range []string{running.ID, queued.ID} {
+		job, err := m.Get(ctx, storeID, id)
+		require.NoError(t, err)
+		require.Equal(t, StateFailed, job.State)
+		require.Equal(t, errStopped.Error(), job.Error)
+	}
+}
+
+func TestManagerAbandonedJob(t *testing.T) {
+	ctx := context.Background()
+
+	ds := memory.New().(*memory.MemoryBackend)
+	t.Cleanup(ds.Close)
+
+	// a running job whose replica stopped without recording it
+	storeID := ulid.Make().String()
+	payload, err := json.Marshal(&Job{ID: "01ARZ3NDEKTSV4RRFFQ69G5FAV", StoreID: storeID, Kind: "purge", State: StateRunning})
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteJob(ctx, &storage.Job{Store: storeID, ID: "01ARZ3NDEKTSV4RRFFQ69G5FAV", Kind: "purge", Payload: payload}))
+
+	m := NewManager(ds, WithHeartbeatInterval(time.Millisecond))
+	t.Cleanup(m.Stop)
+
+	require.Eventually(t, func() bool {
+		job, err := m.Get(ctx, storeID, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+		require.NoError(t, err)
+		return job.State == StateFailed
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestManagerQueueFull(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New().(*memory.MemoryBackend)
+	t.Cleanup(ds.Close)
+
+	m := NewManager(ds, WithWorkers(1), WithQueueSize(1))
+	t.Cleanup(m.Stop)
+
+	started := make(chan struct{}, 1)
+	m.Register("block", func(ctx context.Context, _ string, _ json.RawMessage, _ ProgressFunc) (any, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	storeID := ulid.Make().String()
+	_, err := m.Create(ctx, storeID, "block", nil)
+	require.NoError(t, err)
+	<-started
+	_, err = m.Create(ctx, storeID, "block", nil)
+	require.NoError(t, err)
+
+	_, err = m.Create(ctx, storeID, "block", nil)
+	require.ErrorIs(t, err, ErrQueueFull)
+}
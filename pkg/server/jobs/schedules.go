@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// DefaultScheduleInterval is the default interval at which the due schedules are run.
+	DefaultScheduleInterval = 30 * time.Second
+
+	// schedulerLease is the name of the lease held by the replica that runs the schedules.
+	schedulerLease = "jobs-scheduler"
+)
+
+var (
+	// ErrScheduleNotFound is returned for the schedules that don't exist.
+	ErrScheduleNotFound = errors.New("schedule not found")
+
+	// ErrSchedulesDisabled is returned by the schedule methods of the managers created without
+	// WithSchedules.
+	ErrSchedulesDisabled = errors.New("the job schedules are not enabled")
+)
+
+// Schedule reports a recurring job of a store, created with its params each time its cron
+// expression matches.
+type Schedule struct {
+	ID      string          `json:"id"`
+	StoreID string          `json:"store_id"`
+	Kind    string          `json:"kind"`
+	Cron    string          `json:"cron"`
+	Params  json.RawMessage `json:"params,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// LastRunAt and LastJobID are the time of the last run of the schedule and the job it created.
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastJobID string     `json:"last_job_id,omitempty"`
+
+	// NextRunAt is the next time at which the cron expression matches. The schedule runs within
+	// the schedule interval of it.
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+}
+
+// schedulePayload is the part of a Schedule persisted in the payload of its storage.Schedule.
+type schedulePayload struct {
+	Kind      string          `json:"kind"`
+	Cron      string          `json:"cron"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// CreateSchedule creates a schedule of the jobs of kind for storeID with params, run each time
+// expr matches, in UTC. See ParseCron for the format of expr.
+func (m *Manager) CreateSchedule(ctx context.Context, storeID, kind, expr string, params json.RawMessage) (*Schedule, error) {
+	if m.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+
+	m.mu.Lock()
+	_, ok := m.kinds[kind]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownKind, kind)
+	}
+	if _, err := ParseCron(expr); err != nil {
+		return nil, err
+	}
+
+	record := &storage.Schedule{Store: storeID, ID: ulid.Make().String()}
+	payload, err := json.Marshal(&schedulePayload{
+		Kind:      kind,
+		Cron:      expr,
+		Params:    params,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	record.Payload = payload
+	if err := m.schedules.WriteSchedule(ctx, record); err != nil {
+		return nil, err
+	}
+	return decodeSchedule(record)
+}
+
+// GetSchedule returns the schedule scheduleID of storeID.
+func (m *Manager) GetSchedule(ctx context.Context, storeID, scheduleID string) (*Schedule, error) {
+	if m.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+
+	record, err := m.schedules.ReadSchedule(ctx, storeID, scheduleID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, err
+	}
+	return decodeSchedule(record)
+}
+
+// ListSchedules returns the schedules of storeID, from the oldest to the most recent.
+func (m *Manager) ListSchedules(ctx context.Context, storeID string) ([]*Schedule, error) {
+	if m.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+
+	records, err := m.schedules.ListSchedules(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]*Schedule, 0, len(records))
+	for _, record := range records {
+		schedule, err := decodeSchedule(record)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule deletes the schedule scheduleID of storeID. The jobs it created are not changed.
+func (m *Manager) DeleteSchedule(ctx context.Context, storeID, scheduleID string) error {
+	if m.schedules == nil {
+		return ErrSchedulesDisabled
+	}
+
+	err := m.schedules.DeleteSchedule(ctx, storeID, scheduleID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrScheduleNotFound
+	}
+	return err
+}
+
+func (m *Manager) runSchedules() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.scheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.runDueSchedules()
+		}
+	}
+}
+
+// runDueSchedules creates the jobs of the schedules whose next run is due, if this replica holds
+// the lease of the scheduler. The runs missed while no replica held it are run once.
+func (m *Manager) runDueSchedules() {
+	leader, err := m.schedules.AcquireLease(m.ctx, schedulerLease, m.holder, missedHeartbeats*m.scheduleInterval)
+	if err != nil {
+		if m.ctx.Err() == nil {
+			m.logger.Error("failed to acquire the lease of the job scheduler", zap.Error(err))
+		}
+		return
+	}
+	if !leader {
+		return
+	}
+
+	records, err := m.schedules.ListSchedules(m.ctx, "")
+	if err != nil {
+		if m.ctx.Err() == nil {
+			m.logger.Error("failed to list the job schedules", zap.Error(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		schedule, err := decodeSchedule(record)
+		if err != nil {
+			m.logger.Error("failed to decode a job schedule", zap.String("schedule_id", record.ID), zap.Error(err))
+			continue
+		}
+		if schedule.NextRunAt == nil || schedule.NextRunAt.After(now) {
+			continue
+		}
+
+		// the schedules whose job can't be created, e.g. because the queue is full, run again
+		// with the next tick
+		job, err := m.Create(m.ctx, schedule.StoreID, schedule.Kind, schedule.Params)
+		if err != nil {
+			m.logger.Error("failed to create the job of a schedule",
+				zap.String("schedule_id", schedule.ID),
+				zap.String("store_id", schedule.StoreID),
+				zap.String("kind", schedule.Kind),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		err = m.schedules.RecordScheduleRun(m.ctx, schedule.StoreID, schedule.ID, now, job.ID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			m.logger.Error("failed to record the run of a schedule", zap.String("schedule_id", schedule.ID), zap.Error(err))
+		}
+	}
+}
+
+// decodeSchedule returns the schedule persisted in record, and computes its next run.
+func decodeSchedule(record *storage.Schedule) (*Schedule, error) {
+	var payload schedulePayload
+	if err := json.Unmarshal(record.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode the schedule '%s': %w", record.ID, err)
+	}
+
+	schedule := &Schedule{
+		ID:        record.ID,
+		StoreID:   record.Store,
+		Kind:      payload.Kind,
+		Cron:      payload.Cron,
+		Params:    payload.Params,
+		CreatedAt: payload.CreatedAt,
+		LastJobID: record.LastJobID,
+	}
+	from := payload.CreatedAt
+	if !record.LastRunAt.IsZero() {
+		lastRunAt := record.LastRunAt
+		schedule.LastRunAt = &lastRunAt
+		from = lastRunAt
+	}
+
+	c, err := ParseCron(payload.Cron)
+	if err != nil {
+		return nil, err
+	}
+	if next := c.Next(from); !next.IsZero() {
+		schedule.NextRunAt = &next
+	}
+	return schedule, nil
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+func TestSchedules(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New().(*memory.MemoryBackend)
+	t.Cleanup(ds.Close)
+
+	noop := func(context.Context, string, json.RawMessage, ProgressFunc) (any, error) {
+		return nil, nil
+	}
+
+	// two replicas, of which only the holder of the lease runs the schedules
+	replicas := make([]*Manager, 2)
+	for i := range replicas {
+		replicas[i] = NewManager(ds, WithSchedules(ds), WithScheduleInterval(10*time.Millisecond))
+		replicas[i].Register("report", noop)
+		t.Cleanup(replicas[i].Stop)
+	}
+	m := replicas[0]
+
+	storeID := ulid.Make().String()
+
+	t.Run("create", func(t *testing.T) {
+		schedule, err := m.CreateSchedule(ctx, storeID, "report", "0 3 * * 1", json.RawMessage(`{"dry_run":true}`))
+		require.NoError(t, err)
+		require.Equal(t, "report", schedule.Kind)
+		require.Nil(t, schedule.LastRunAt)
+		require.NotNil(t, schedule.NextRunAt)
+		require.Equal(t, time.Monday, schedule.NextRunAt.Weekday())
+
+		read, err := m.GetSchedule(ctx, storeID, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, schedule.NextRunAt, read.NextRunAt)
+		require.JSONEq(t, `{"dry_run":true}`, string(read.Params))
+
+		schedules, err := m.ListSchedules(ctx, storeID)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+
+		require.NoError(t, m.DeleteSchedule(ctx, storeID, schedule.ID))
+		_, err = m.GetSchedule(ctx, storeID, schedule.ID)
+		require.ErrorIs(t, err, ErrScheduleNotFound)
+		require.ErrorIs(t, m.DeleteSchedule(ctx, storeID, schedule.ID), ErrScheduleNotFound)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := m.CreateSchedule(ctx, storeID, "unknown", "@daily", nil)
+		require.ErrorIs(t, err, ErrUnknownKind)
+
+		_, err = m.CreateSchedule(ctx, storeID, "report", "@every 5m", nil)
+		require.ErrorIs(t, err, ErrInvalidCron)
+	})
+
+	t.Run("run_once_by_the_leader", func(t *testing.T) {
+		// an hourly schedule created more than an hour ago is due
+		payload, err := json.Marshal(&schedulePayload{Kind: "report", Cron: "@hourly", CreatedAt: time.Now().Add(-2 * time.Hour)})
+		require.NoError(t, err)
+		id := ulid.Make().String()
+		require.NoError(t, ds.WriteSchedule(ctx, &storage.Schedule{Store: storeID, ID: id, Payload: payload}))
+
+		var schedule *Schedule
+		require.Eventually(t, func() bool {
+			schedule, err = m.GetSchedule(ctx, storeID, id)
+			require.NoError(t, err)
+			return schedule.LastJobID != ""
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NotNil(t, schedule.LastRunAt)
+		require.True(t, schedule.NextRunAt.After(time.Now()))
+
+		job := waitForJob(t, m, storeID, schedule.LastJobID)
+		require.Equal(t, StateSucceeded, job.State)
+
+		// the missed runs are run once, by a single replica
+		time.Sleep(50 * time.Millisecond)
+		jobs, err := ds.ListJobs(ctx, storeID)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+	})
+}
+
+func TestSchedulesDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New().(*memory.MemoryBackend)
+	t.Cleanup(ds.Close)
+
+	m := NewManager(ds)
+	t.Cleanup(m.Stop)
+
+	_, err := m.CreateSchedule(context.Background(), ulid.Make().String(), "report", "@daily", nil)
+	require.ErrorIs(t, err, ErrSchedulesDisabled)
+}
@@ -0,0 +1,369 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the jobs service.
+	ServiceName = "openfga.jobs.v1.JobService"
+
+	// CreateMethod is the full name of the method that creates a job.
+	CreateMethod = "/" + ServiceName + "/Create"
+
+	// GetMethod is the full name of the method that reads a job.
+	GetMethod = "/" + ServiceName + "/Get"
+
+	// ListMethod is the full name of the method that lists the jobs of a store.
+	ListMethod = "/" + ServiceName + "/List"
+
+	// CancelMethod is the full name of the method that cancels a job.
+	CancelMethod = "/" + ServiceName + "/Cancel"
+
+	// CreateScheduleMethod is the full name of the method that creates a schedule of recurring jobs.
+	CreateScheduleMethod = "/" + ServiceName + "/CreateSchedule"
+
+	// GetScheduleMethod is the full name of the method that reads a schedule.
+	GetScheduleMethod = "/" + ServiceName + "/GetSchedule"
+
+	// ListSchedulesMethod is the full name of the method that lists the schedules of a store.
+	ListSchedulesMethod = "/" + ServiceName + "/ListSchedules"
+
+	// DeleteScheduleMethod is the full name of the method that deletes a schedule.
+	DeleteScheduleMethod = "/" + ServiceName + "/DeleteSchedule"
+
+	// codecName is the content-subtype of the jobs requests. The jobs service is not part of the
+	// OpenFGA API, so its messages are encoded as JSON instead of generated protobufs.
+	codecName = "openfga-jobs-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// CreateRequest creates a job of a kind, such as "purge", for a store with the params of the kind.
+type CreateRequest struct {
+	StoreID string          `json:"store_id"`
+	Kind    string          `json:"kind"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// GetRequest reads a job of a store.
+type GetRequest struct {
+	StoreID string `json:"store_id"`
+	JobID   string `json:"job_id"`
+}
+
+// ListRequest lists the jobs of a store.
+type ListRequest struct {
+	StoreID string `json:"store_id"`
+}
+
+// ListResponse is the list of the jobs of a store.
+type ListResponse struct {
+	Jobs []*Job `json:"jobs"`
+}
+
+// CancelRequest requests the cancellation of a job of a store.
+type CancelRequest struct {
+	StoreID string `json:"store_id"`
+	JobID   string `json:"job_id"`
+}
+
+// CreateScheduleRequest creates a schedule of the jobs of a kind for a store with the params of the
+// kind, created each time the cron expression matches, in UTC.
+type CreateScheduleRequest struct {
+	StoreID string          `json:"store_id"`
+	Kind    string          `json:"kind"`
+	Cron    string          `json:"cron"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// GetScheduleRequest reads a schedule of a store.
+type GetScheduleRequest struct {
+	StoreID    string `json:"store_id"`
+	ScheduleID string `json:"schedule_id"`
+}
+
+// ListSchedulesRequest lists the schedules of a store.
+type ListSchedulesRequest struct {
+	StoreID string `json:"store_id"`
+}
+
+// ListSchedulesResponse is the list of the schedules of a store.
+type ListSchedulesResponse struct {
+	Schedules []*Schedule `json:"schedules"`
+}
+
+// DeleteScheduleRequest deletes a schedule of a store.
+type DeleteScheduleRequest struct {
+	StoreID    string `json:"store_id"`
+	ScheduleID string `json:"schedule_id"`
+}
+
+// DeleteScheduleResponse is the empty response of DeleteSchedule.
+type DeleteScheduleResponse struct{}
+
+// Server creates, reads, lists and cancels the jobs, and manages their schedules. It is
+// implemented by the OpenFGA server.
+type Server interface {
+	CreateJob(ctx context.Context, req *CreateRequest) (*Job, error)
+	GetJob(ctx context.Context, req *GetRequest) (*Job, error)
+	ListJobs(ctx context.Context, req *ListRequest) (*ListResponse, error)
+	CancelJob(ctx context.Context, req *CancelRequest) (*Job, error)
+	CreateJobSchedule(ctx context.Context, req *CreateScheduleRequest) (*Schedule, error)
+	GetJobSchedule(ctx context.Context, req *GetScheduleRequest) (*Schedule, error)
+	ListJobSchedules(ctx context.Context, req *ListSchedulesRequest) (*ListSchedulesResponse, error)
+	DeleteJobSchedule(ctx context.Context, req *DeleteScheduleRequest) (*DeleteScheduleResponse, error)
+}
+
+// RegisterServer registers the jobs service, which creates, reads, lists and cancels the jobs and
+// manages their schedules with srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Create",
+				Handler:    createHandler,
+			},
+			{
+				MethodName: "Get",
+				Handler:    getHandler,
+			},
+			{
+				MethodName: "List",
+				Handler:    listHandler,
+			},
+			{
+				MethodName: "Cancel",
+				Handler:    cancelHandler,
+			},
+			{
+				MethodName: "CreateSchedule",
+				Handler:    createScheduleHandler,
+			},
+			{
+				MethodName: "GetSchedule",
+				Handler:    getScheduleHandler,
+			},
+			{
+				MethodName: "ListSchedules",
+				Handler:    listSchedulesHandler,
+			},
+			{
+				MethodName: "DeleteSchedule",
+				Handler:    deleteScheduleHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/jobs/service.go",
+	}, srv)
+}
+
+func createHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &CreateRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).CreateJob(ctx, req.(*CreateRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: CreateMethod}, handler)
+}
+
+func getHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &GetRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).GetJob(ctx, req.(*GetRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: GetMethod}, handler)
+}
+
+func listHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &ListRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).ListJobs(ctx, req.(*ListRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: ListMethod}, handler)
+}
+
+func cancelHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &CancelRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).CancelJob(ctx, req.(*CancelRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: CancelMethod}, handler)
+}
+
+func createScheduleHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &CreateScheduleRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).CreateJobSchedule(ctx, req.(*CreateScheduleRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: CreateScheduleMethod}, handler)
+}
+
+func getScheduleHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &GetScheduleRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).GetJobSchedule(ctx, req.(*GetScheduleRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: GetScheduleMethod}, handler)
+}
+
+func listSchedulesHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &ListSchedulesRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).ListJobSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: ListSchedulesMethod}, handler)
+}
+
+func deleteScheduleHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &DeleteScheduleRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).DeleteJobSchedule(ctx, req.(*DeleteScheduleRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: DeleteScheduleMethod}, handler)
+}
+
+// Create creates a job on conn and returns it.
+func Create(ctx context.Context, conn grpc.ClientConnInterface, req *CreateRequest) (*Job, error) {
+	out := &Job{}
+	if err := conn.Invoke(ctx, CreateMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Get reads a job on conn.
+func Get(ctx context.Context, conn grpc.ClientConnInterface, req *GetRequest) (*Job, error) {
+	out := &Job{}
+	if err := conn.Invoke(ctx, GetMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List lists the jobs of a store on conn.
+func List(ctx context.Context, conn grpc.ClientConnInterface, req *ListRequest) (*ListResponse, error) {
+	out := &ListResponse{}
+	if err := conn.Invoke(ctx, ListMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Cancel requests the cancellation of a job on conn and returns it.
+func Cancel(ctx context.Context, conn grpc.ClientConnInterface, req *CancelRequest) (*Job, error) {
+	out := &Job{}
+	if err := conn.Invoke(ctx, CancelMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateSchedule creates a schedule of recurring jobs on conn and returns it.
+func CreateSchedule(ctx context.Context, conn grpc.ClientConnInterface, req *CreateScheduleRequest) (*Schedule, error) {
+	out := &Schedule{}
+	if err := conn.Invoke(ctx, CreateScheduleMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetSchedule reads a schedule on conn.
+func GetSchedule(ctx context.Context, conn grpc.ClientConnInterface, req *GetScheduleRequest) (*Schedule, error) {
+	out := &Schedule{}
+	if err := conn.Invoke(ctx, GetScheduleMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListSchedules lists the schedules of a store on conn.
+func ListSchedules(ctx context.Context, conn grpc.ClientConnInterface, req *ListSchedulesRequest) (*ListSchedulesResponse, error) {
+	out := &ListSchedulesResponse{}
+	if err := conn.Invoke(ctx, ListSchedulesMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteSchedule deletes a schedule on conn.
+func DeleteSchedule(ctx context.Context, conn grpc.ClientConnInterface, req *DeleteScheduleRequest) error {
+	return conn.Invoke(ctx, DeleteScheduleMethod, req, &DeleteScheduleResponse{}, grpc.CallContentSubtype(codecName))
+}
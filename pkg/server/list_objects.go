@@ -59,12 +59,46 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 	if err != nil {
 		return nil, err
 	}
+	req.Consistency = s.sessionConsistency(ctx, storeID, req.GetConsistency())
+
+	ctx, resolveNodeLimit, err := s.contextWithMaxResolutionDepth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Context = s.injectConditionContext(ctx, req.GetContext())
+	req.Context, err = s.enrichContext(ctx, methodName, storeID, req.GetUser(), "", req.GetContext())
+	if err != nil {
+		return nil, err
+	}
 
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	s.dropUndefinedContextualTupleKeys(ctx, typesys, req.GetContextualTuples())
+
+	cacheKey := s.listObjectsCacheKey(ctx, req)
+	if objects, ok := s.getCachedListObjects(ctx, req, cacheKey); ok {
+		grpc_ctxtags.Extract(ctx).Set("request.cached", true)
+		if listObjectsExplainRequested(ctx) {
+			s.setListObjectsExplainHeader(ctx, typesys, req, objects)
+		}
+		return &openfgav1.ListObjectsResponse{
+			Objects: objects,
+		}, nil
+	}
+
+	if objects, ok := s.getMaterializedObjectList(ctx, req); ok {
+		grpc_ctxtags.Extract(ctx).Set("request.materialized", true)
+		if listObjectsExplainRequested(ctx) {
+			s.setListObjectsExplainHeader(ctx, typesys, req, objects)
+		}
+		return &openfgav1.ListObjectsResponse{
+			Objects: objects,
+		}, nil
+	}
 
 	builder := s.getListObjectsCheckResolverBuilder(storeID)
 	checkResolver, checkResolverCloser, err := builder.Build()
@@ -86,7 +120,7 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 			Threshold:    s.listObjectsDispatchDefaultThreshold,
 			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
 		}),
-		commands.WithResolveNodeLimit(s.resolveNodeLimit),
+		commands.WithResolveNodeLimit(resolveNodeLimit),
 		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
 		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
 		commands.WithListObjectsCache(s.sharedDatastoreResources, s.cacheSettings),
@@ -100,6 +134,7 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 		commands.WithListObjectsBufferCapacity(s.listObjectsPipelineConfig.BufferCapacity),
 		commands.WithListObjectsNumProcs(s.listObjectsPipelineConfig.NumProcs),
 		commands.WithFeatureFlagClient(s.featureFlagClient),
+		commands.WithListObjectsObjectIDPrefix(idPrefixFromHeader(ctx)),
 	)
 	if err != nil {
 		return nil, serverErrors.NewInternalError("", err)
@@ -186,6 +221,12 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 	checkCounter := float64(result.ResolutionMetadata.CheckCounter.Load())
 	grpc_ctxtags.Extract(ctx).Set(listObjectsCheckCountName, checkCounter)
 
+	if listObjectsExplainRequested(ctx) {
+		s.setListObjectsExplainHeader(ctx, typesys, req, result.Objects)
+	}
+
+	s.setCachedListObjects(cacheKey, result.Objects, start)
+
 	return &openfgav1.ListObjectsResponse{
 		Objects: result.Objects,
 	}, nil
@@ -224,12 +265,25 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 	if err != nil {
 		return err
 	}
+	req.Consistency = s.sessionConsistency(ctx, storeID, req.GetConsistency())
+
+	ctx, resolveNodeLimit, err := s.contextWithMaxResolutionDepth(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.Context = s.injectConditionContext(ctx, req.GetContext())
+	req.Context, err = s.enrichContext(ctx, methodName, storeID, req.GetUser(), "", req.GetContext())
+	if err != nil {
+		return err
+	}
 
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return err
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	s.dropUndefinedContextualTupleKeys(ctx, typesys, req.GetContextualTuples())
 
 	builder := s.getListObjectsCheckResolverBuilder(storeID)
 	checkResolver, checkResolverCloser, err := builder.Build()
@@ -251,7 +305,7 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
 		}),
 		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
-		commands.WithResolveNodeLimit(s.resolveNodeLimit),
+		commands.WithResolveNodeLimit(resolveNodeLimit),
 		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
 		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
 		commands.WithListObjectsPipelineEnabled(s.listObjectsPipelineEnabled),
@@ -259,6 +313,9 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 		commands.WithListObjectsBufferCapacity(s.listObjectsPipelineConfig.BufferCapacity),
 		commands.WithListObjectsNumProcs(s.listObjectsPipelineConfig.NumProcs),
 		commands.WithFeatureFlagClient(s.featureFlagClient),
+		commands.WithStreamedListObjectsMaxInFlight(s.streamedListObjectsMaxInFlight),
+		commands.WithStreamedListObjectsMessagesPerSecond(s.streamedListObjectsRateLimit),
+		commands.WithListObjectsObjectIDPrefix(idPrefixFromHeader(ctx)),
 	)
 	if err != nil {
 		return serverErrors.NewInternalError("", err)
@@ -338,5 +395,6 @@ func (s *Server) getListObjectsCheckResolverBuilder(storeID string) *graph.Check
 		}...),
 		graph.WithCachedCheckResolverOpts(s.cacheSettings.ShouldCacheCheckQueries(), checkCacheOptions...),
 		graph.WithDispatchThrottlingCheckResolverOpts(s.checkDispatchThrottlingEnabled, checkDispatchThrottlingOptions...),
+		graph.WithCustomCheckResolvers(s.customCheckResolvers...),
 	}...)
 }
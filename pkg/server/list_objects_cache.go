@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var (
+	listObjectsCacheHitCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "list_objects_cache_hit_count",
+		Help:      "The total number of valid ListObjects query cache hits.",
+	})
+
+	listObjectsCacheInvalidHitCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "list_objects_cache_invalid_hit_count",
+		Help:      "The total number of ListObjects query cache hits that were discarded because a write to their store was found after they were cached.",
+	})
+)
+
+// listObjectsCacheKey returns the key of the cached response of req, whose model is resolved, or
+// an empty key if the ListObjects responses are not cached.
+func (s *Server) listObjectsCacheKey(ctx context.Context, req *openfgav1.ListObjectsRequest) string {
+	if !s.cacheSettings.ShouldCacheListObjectsQueries() {
+		return ""
+	}
+
+	key, err := storage.GetListObjectsCacheKey(&storage.ListObjectsCacheKeyParams{
+		StoreID:              req.GetStoreId(),
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		ObjectType:           req.GetType(),
+		Relation:             req.GetRelation(),
+		User:                 req.GetUser(),
+		ContextualTuples:     req.GetContextualTuples().GetTupleKeys(),
+		Context:              req.GetContext(),
+		ObjectIDPrefix:       idPrefixFromHeader(ctx),
+	})
+	if err != nil {
+		// the request is not cached rather than failed
+		s.logger.Debug("failed to build the ListObjects cache key", zap.Error(err))
+		return ""
+	}
+	return key
+}
+
+// getCachedListObjects returns the cached objects of key, if they were cached after the last
+// write to the store found by the cache controller, the watermark of the store. With
+// HIGHER_CONSISTENCY, the cache is not read.
+func (s *Server) getCachedListObjects(ctx context.Context, req *openfgav1.ListObjectsRequest, key string) ([]string, bool) {
+	if key == "" || req.GetConsistency() == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return nil, false
+	}
+
+	entry, _ := s.sharedDatastoreResources.CheckCache.Get(key).(*storage.ListObjectsCacheEntry)
+	if entry == nil {
+		return nil, false
+	}
+
+	watermark := s.sharedDatastoreResources.CacheController.DetermineInvalidationTime(ctx, req.GetStoreId())
+	isValid := entry.LastModified.After(watermark)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cached", isValid))
+	if !isValid {
+		listObjectsCacheInvalidHitCounter.Inc()
+		return nil, false
+	}
+
+	listObjectsCacheHitCounter.Inc()
+	// return a copy, as the response may be modified
+	return slices.Clone(entry.Objects), true
+}
+
+// setCachedListObjects caches the objects of key, unless there are more than the maximum number
+// of objects of a cached response, or they may be partial because the ListObjects deadline was
+// reached since start.
+func (s *Server) setCachedListObjects(key string, objects []string, start time.Time) {
+	if key == "" || len(objects) > int(s.cacheSettings.ListObjectsQueryCacheMaxResults) {
+		return
+	}
+	if s.listObjectsDeadline > 0 && time.Since(start) >= s.listObjectsDeadline {
+		return
+	}
+
+	s.sharedDatastoreResources.CheckCache.Set(key, &storage.ListObjectsCacheEntry{
+		Objects:      slices.Clone(objects),
+		LastModified: time.Now(),
+	}, storage.JitteredTTL(s.cacheSettings.ListObjectsQueryCacheTTL, s.cacheSettings.CacheTTLJitterPercentage))
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestListObjectsQueryCache(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]
+	`)
+
+	setup := func(t *testing.T, opts ...OpenFGAServiceV1Option) (*Server, string) {
+		_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+		s := MustNewServerWithOpts(append([]OpenFGAServiceV1Option{
+			WithDatastore(ds),
+			WithListObjectsQueryCacheEnabled(true),
+		}, opts...)...)
+		t.Cleanup(s.Close)
+
+		createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+		require.NoError(t, err)
+		storeID := createStoreResp.GetId()
+
+		_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return s, storeID
+	}
+
+	write := func(t *testing.T, s *Server, storeID, object string) {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey(object, "viewer", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	listObjects := func(t *testing.T, s *Server, storeID string, consistency openfgav1.ConsistencyPreference) []string {
+		resp, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:     storeID,
+			Type:        "document",
+			Relation:    "viewer",
+			User:        "user:anne",
+			Consistency: consistency,
+		})
+		require.NoError(t, err)
+		return resp.GetObjects()
+	}
+
+	t.Run("cached_until_ttl", func(t *testing.T) {
+		s, storeID := setup(t)
+		write(t, s, storeID, "document:1")
+		require.Equal(t, []string{"document:1"}, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED))
+
+		// without a cache controller, the write isn't found before the cached response expires
+		write(t, s, storeID, "document:2")
+		require.Equal(t, []string{"document:1"}, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED))
+
+		// with HIGHER_CONSISTENCY, the cache isn't read, but the response is cached
+		require.ElementsMatch(t, []string{"document:1", "document:2"}, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+		require.ElementsMatch(t, []string{"document:1", "document:2"}, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED))
+	})
+
+	t.Run("invalidated_by_writes", func(t *testing.T) {
+		s, storeID := setup(t,
+			WithCacheControllerEnabled(true),
+			WithCacheControllerTTL(time.Millisecond),
+		)
+		write(t, s, storeID, "document:1")
+		require.Equal(t, []string{"document:1"}, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED))
+
+		// the cache controller finds the write, which advances the watermark of the store
+		write(t, s, storeID, "document:2")
+		require.Eventually(t, func() bool {
+			return len(listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED)) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("responses_over_the_limit_not_cached", func(t *testing.T) {
+		s, storeID := setup(t, WithListObjectsQueryCacheMaxResults(1))
+		write(t, s, storeID, "document:1")
+		write(t, s, storeID, "document:2")
+		require.Len(t, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED), 2)
+
+		write(t, s, storeID, "document:3")
+		require.Len(t, listObjects(t, s, storeID, openfgav1.ConsistencyPreference_UNSPECIFIED), 3)
+	})
+}
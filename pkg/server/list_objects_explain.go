@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ListObjectsExplainHeader requests the explanation of the objects returned by ListObjects, to
+// debug surprising entries. When a request sets it to "true", the response carries a header of
+// the same name with a JSON object that maps the returned objects to the path of tuples that
+// grants them the relation, in the format of the CheckReasonHeader. The last tuple of a path is
+// the tuple of the object that contributed it, e.g. a parent folder or a group of editors.
+//
+// Only the first maxExplainedListObjects objects are explained, to bound the cost of the
+// explanations and the size of the header.
+const ListObjectsExplainHeader = "Openfga-List-Objects-Explain"
+
+// maxExplainedListObjects is the maximum number of objects in the ListObjectsExplainHeader.
+const maxExplainedListObjects = 100
+
+// listObjectsExplainRequested reports whether the request sets the ListObjectsExplainHeader.
+func listObjectsExplainRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(ListObjectsExplainHeader))
+	if len(values) == 0 {
+		return false
+	}
+	requested, _ := strconv.ParseBool(strings.TrimSpace(values[0]))
+	return requested
+}
+
+// setListObjectsExplainHeader returns the paths of tuples that grant the relation of req on
+// objects in the ListObjectsExplainHeader. The objects whose path is not found are left out of the
+// header, and the failures to find the paths are logged.
+func (s *Server) setListObjectsExplainHeader(ctx context.Context, typesys *typesystem.TypeSystem, req *openfgav1.ListObjectsRequest, objects []string) {
+	ctx, span := tracer.Start(ctx, "listObjectsExplain")
+	defer span.End()
+
+	if len(objects) > maxExplainedListObjects {
+		objects = objects[:maxExplainedListObjects]
+	}
+	span.SetAttributes(attribute.Int("explained_objects", len(objects)))
+
+	q := commands.NewCheckReasonQuery(s.datastore, typesys,
+		commands.WithCheckReasonResolveNodeLimit(s.resolveNodeLimit),
+	)
+	explanations := make(map[string][]checkReasonTuple, len(objects))
+	for _, object := range objects {
+		path, ok, err := q.Execute(ctx, &commands.CheckReasonParams{
+			StoreID:          req.GetStoreId(),
+			TupleKey:         tuple.NewCheckRequestTupleKey(object, req.GetRelation(), req.GetUser()),
+			ContextualTuples: req.GetContextualTuples(),
+			Context:          req.GetContext(),
+			Consistency:      req.GetConsistency(),
+		})
+		if err != nil {
+			telemetry.TraceError(span, err)
+			s.logger.WarnWithContext(ctx, "failed to explain a listed object", zap.String("object", object), zap.Error(err))
+			continue
+		}
+		if ok {
+			explanations[object] = newCheckReason(path)
+		}
+	}
+
+	value, err := json.Marshal(explanations)
+	if err != nil {
+		return
+	}
+	s.transport.SetHeader(ctx, ListObjectsExplainHeader, string(value))
+}
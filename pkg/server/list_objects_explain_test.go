@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestListObjectsExplainHeader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define viewer: [user]
+
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent
+	`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("folder:x", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:2", "parent", "folder:x"),
+		}},
+	})
+	require.NoError(t, err)
+
+	listObjects := func(ctx context.Context, user string) []string {
+		transport.reset()
+		resp, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     user,
+		})
+		require.NoError(t, err)
+		return resp.GetObjects()
+	}
+
+	t.Run("not_returned_unless_requested", func(t *testing.T) {
+		require.Len(t, listObjects(ctx, "user:anne"), 2)
+		require.Empty(t, transport.get(ListObjectsExplainHeader))
+	})
+
+	t.Run("returned_for_each_object", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(ListObjectsExplainHeader, "true"))
+		require.Len(t, listObjects(ctx, "user:anne"), 2)
+
+		var explanations map[string][]checkReasonTuple
+		require.NoError(t, json.Unmarshal([]byte(transport.get(ListObjectsExplainHeader)), &explanations))
+		require.Equal(t, map[string][]checkReasonTuple{
+			"document:1": {
+				{Object: "document:1", Relation: "viewer", User: "user:anne"},
+			},
+			"document:2": {
+				{Object: "folder:x", Relation: "viewer", User: "user:anne"},
+				{Object: "document:2", Relation: "parent", User: "folder:x"},
+			},
+		}, explanations)
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		for batch := range 2 {
+			var writes []*openfgav1.TupleKey
+			for i := range 60 {
+				writes = append(writes, tuple.NewTupleKey("document:many-"+strconv.Itoa(batch*60+i), "viewer", "user:bob"))
+			}
+			_, err := s.Write(ctx, &openfgav1.WriteRequest{
+				StoreId: storeID,
+				Writes:  &openfgav1.WriteRequestWrites{TupleKeys: writes},
+			})
+			require.NoError(t, err)
+		}
+
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(ListObjectsExplainHeader, "true"))
+		require.Len(t, listObjects(ctx, "user:bob"), 120)
+
+		var explanations map[string][]checkReasonTuple
+		require.NoError(t, json.Unmarshal([]byte(transport.get(ListObjectsExplainHeader)), &explanations))
+		require.Len(t, explanations, maxExplainedListObjects)
+	})
+}
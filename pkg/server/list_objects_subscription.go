@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/hotpath"
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/listobjectssubscription"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var listObjectsSubscriptionsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: build.ProjectName,
+	Name:      "list_objects_subscriptions",
+	Help:      "The number of active ListObjects subscriptions.",
+})
+
+// errIncompleteSubscription is returned when the initial objects of a subscription can't all be
+// listed, so that its deltas would be wrong.
+var errIncompleteSubscription = status.Error(codes.FailedPrecondition,
+	"the objects of the subscription can't all be listed within the ListObjects deadline and maximum number of results")
+
+// SubscribeListObjects sends the objects of a ListObjects with send, and then the objects added to
+// and removed from them as the writes to the store change them, until ctx is done or send fails.
+// The changelog of the store is read every poll interval, and the objects are listed again when
+// the changed tuples can affect them. The objects are listed with the authorization model resolved
+// when the subscription starts, and with HIGHER_CONSISTENCY.
+func (s *Server) SubscribeListObjects(ctx context.Context, req *openfgav1.ListObjectsRequest, send func(*listobjectssubscription.Delta) error) error {
+	ctx, span := tracer.Start(ctx, "SubscribeListObjects", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.String("object_type", req.GetType()),
+		attribute.String("relation", req.GetRelation()),
+		attribute.String("user", req.GetUser()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	storeID := req.GetStoreId()
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.ListObjects.String(),
+	})
+	if err := s.checkAuthz(ctx, storeID, apimethod.ListObjects); err != nil {
+		return err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+	if err != nil {
+		return err
+	}
+	req = proto.Clone(req).(*openfgav1.ListObjectsRequest)
+	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	req.Consistency = openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY
+
+	// the changes of the tuples of the other types can't change the objects, unless the relation
+	// involves intersections, exclusions or conditions
+	var objectTypes map[string]struct{}
+	if deps, err := hotpath.Analyze(typesys, req.GetType(), req.GetRelation()); err == nil {
+		objectTypes = deps.ObjectTypes
+	}
+
+	listObjectsSubscriptionsGauge.Inc()
+	defer listObjectsSubscriptionsGauge.Dec()
+
+	// the changelog is followed from before the objects are listed, so that no change is missed
+	token, err := s.latestTupleChange(ctx, storeID)
+	if err != nil {
+		return serverErrors.HandleError("", err)
+	}
+	objects, complete, err := s.listSubscribedObjects(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !complete {
+		return errIncompleteSubscription
+	}
+	if err := send(&listobjectssubscription.Delta{Initial: true, Added: sortedObjects(objects), Removed: []string{}}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.subscriptionPollInterval)
+	defer ticker.Stop()
+
+	// stale is set when the objects couldn't all be listed, so that they are listed again on the
+	// next poll even if nothing changed
+	stale := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		var changed map[string]struct{}
+		changed, token, err = s.readChangedObjectTypes(ctx, storeID, token)
+		if err != nil {
+			return serverErrors.HandleError("", err)
+		}
+		if !affects(changed, objectTypes) && !stale {
+			continue
+		}
+
+		latest, complete, err := s.listSubscribedObjects(ctx, req)
+		if err != nil {
+			return err
+		}
+		if stale = !complete; stale {
+			continue
+		}
+
+		delta := &listobjectssubscription.Delta{Added: []string{}, Removed: []string{}}
+		for object := range latest {
+			if _, ok := objects[object]; !ok {
+				delta.Added = append(delta.Added, object)
+			}
+		}
+		for object := range objects {
+			if _, ok := latest[object]; !ok {
+				delta.Removed = append(delta.Removed, object)
+			}
+		}
+		objects = latest
+		if len(delta.Added) == 0 && len(delta.Removed) == 0 {
+			continue
+		}
+		slices.Sort(delta.Added)
+		slices.Sort(delta.Removed)
+		if err := send(delta); err != nil {
+			return err
+		}
+	}
+}
+
+// listSubscribedObjects lists the objects of a subscription, and whether they are complete, i.e.
+// neither the deadline nor the maximum number of results of ListObjects was reached.
+func (s *Server) listSubscribedObjects(ctx context.Context, req *openfgav1.ListObjectsRequest) (map[string]struct{}, bool, error) {
+	start := time.Now()
+	resp, err := s.ListObjects(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := (s.listObjectsMaxResults == 0 || len(resp.GetObjects()) < int(s.listObjectsMaxResults)) &&
+		(s.listObjectsDeadline == 0 || time.Since(start) < s.listObjectsDeadline)
+
+	objects := make(map[string]struct{}, len(resp.GetObjects()))
+	for _, object := range resp.GetObjects() {
+		objects[object] = struct{}{}
+	}
+	return objects, complete, nil
+}
+
+func sortedObjects(objects map[string]struct{}) []string {
+	sorted := make([]string, 0, len(objects))
+	for object := range objects {
+		sorted = append(sorted, object)
+	}
+	slices.Sort(sorted)
+	return sorted
+}
+
+// latestTupleChange returns the continuation token of the latest change of the changelog of a
+// store, or an empty token if it has none.
+func (s *Server) latestTupleChange(ctx context.Context, storeID string) (string, error) {
+	_, token, err := s.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{
+		HorizonOffset: time.Duration(s.changelogHorizonOffset) * time.Minute,
+	}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(1, ""),
+		SortDesc:   true,
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	return token, err
+}
+
+// affects reports whether the changes of the tuples of the changed object types can affect the
+// objects that depend on the tuples of objectTypes, or on any tuple if objectTypes is nil.
+func affects(changed, objectTypes map[string]struct{}) bool {
+	if objectTypes == nil {
+		return len(changed) > 0
+	}
+	for objectType := range changed {
+		if _, ok := objectTypes[objectType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readChangedObjectTypes returns the types of the objects whose tuples were changed after the
+// continuation token in the changelog of a store, and the continuation token of the last change.
+func (s *Server) readChangedObjectTypes(ctx context.Context, storeID, continuationToken string) (map[string]struct{}, string, error) {
+	changed := make(map[string]struct{})
+	for {
+		changes, token, err := s.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{
+			HorizonOffset: time.Duration(s.changelogHorizonOffset) * time.Minute,
+		}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, change := range changes {
+			changed[tuple.GetType(change.GetTupleKey().GetObject())] = struct{}{}
+		}
+
+		if token != "" {
+			continuationToken = token
+		}
+		if token == "" || len(changes) < storage.DefaultPageSize {
+			break
+		}
+	}
+	return changed, continuationToken, nil
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/server/listobjectssubscription"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestSubscribeListObjects(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "list-objects-subscription"})
+	require.NoError(t, err)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithListObjectsSubscriptionPollInterval(10*time.Millisecond),
+		WithListObjectsMaxResults(3),
+	)
+	t.Cleanup(s.Close)
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	write := func(writes []*openfgav1.TupleKey, deletes []*openfgav1.TupleKeyWithoutCondition) {
+		req := &openfgav1.WriteRequest{StoreId: storeID}
+		if len(writes) > 0 {
+			req.Writes = &openfgav1.WriteRequestWrites{TupleKeys: writes}
+		}
+		if len(deletes) > 0 {
+			req.Deletes = &openfgav1.WriteRequestDeletes{TupleKeys: deletes}
+		}
+		_, err := s.Write(ctx, req)
+		require.NoError(t, err)
+	}
+	write([]*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")}, nil)
+
+	req := &openfgav1.ListObjectsRequest{StoreId: storeID, Type: "document", Relation: "viewer", User: "user:anne"}
+
+	t.Run("streams_the_changes_of_the_objects", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		deltas := make(chan *listobjectssubscription.Delta)
+		done := make(chan error, 1)
+		go func() {
+			done <- s.SubscribeListObjects(ctx, req, func(delta *listobjectssubscription.Delta) error {
+				deltas <- delta
+				return nil
+			})
+		}()
+
+		require.Equal(t, &listobjectssubscription.Delta{Initial: true, Added: []string{"document:1"}, Removed: []string{}}, <-deltas)
+
+		// the changes of the folders can't change the objects, so they aren't listed again
+		write([]*openfgav1.TupleKey{tuple.NewTupleKey("folder:1", "viewer", "user:anne")}, nil)
+		write([]*openfgav1.TupleKey{tuple.NewTupleKey("document:2", "viewer", "user:anne")},
+			[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "viewer", "user:anne"))})
+		require.Equal(t, &listobjectssubscription.Delta{Added: []string{"document:2"}, Removed: []string{"document:1"}}, <-deltas)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("stops_when_send_fails", func(t *testing.T) {
+		err := s.SubscribeListObjects(ctx, req, func(*listobjectssubscription.Delta) error {
+			return context.Canceled
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fails_when_the_objects_are_incomplete", func(t *testing.T) {
+		write([]*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:3", "viewer", "user:bob"),
+			tuple.NewTupleKey("document:4", "viewer", "user:bob"),
+			tuple.NewTupleKey("document:5", "viewer", "user:bob"),
+		}, nil)
+		err := s.SubscribeListObjects(ctx, &openfgav1.ListObjectsRequest{StoreId: storeID, Type: "document", Relation: "viewer", User: "user:bob"},
+			func(*listobjectssubscription.Delta) error {
+				return nil
+			})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -28,6 +29,11 @@ import (
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
+// ListUsersWildcardsHeader selects how ListUsers returns type-bound public access (e.g. user:*):
+// "include" (the default) returns it as a wildcard entry, "expand" replaces it with the concrete
+// users of that type found in the store, and "exclude" leaves it out of the results.
+const ListUsersWildcardsHeader = "Openfga-List-Users-Wildcards"
+
 // ListUsers returns all users (e.g. subjects) matching a specific user filter criteria
 // that have a specific relation with some object.
 func (s *Server) ListUsers(
@@ -64,11 +70,23 @@ func (s *Server) ListUsers(
 		return nil, err
 	}
 
+	wildcardHandling, err := wildcardHandlingFromHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveNodeLimit, err := s.resolveNodeLimitFromHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	req.ContextualTuples = s.dropUndefinedContextualTuples(ctx, typesys, req.GetContextualTuples())
+	req.Context = s.injectConditionContext(ctx, req.GetContext())
 
 	err = listusers.ValidateListUsersRequest(ctx, req, typesys)
 	if err != nil {
@@ -79,12 +97,14 @@ func (s *Server) ListUsers(
 
 	listUsersQuery := listusers.NewListUsersQuery(s.datastore,
 		req.GetContextualTuples(),
-		listusers.WithResolveNodeLimit(s.resolveNodeLimit),
+		listusers.WithResolveNodeLimit(resolveNodeLimit),
 		listusers.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
 		listusers.WithListUsersQueryLogger(s.logger),
 		listusers.WithListUsersMaxResults(s.listUsersMaxResults),
 		listusers.WithListUsersDeadline(s.listUsersDeadline),
 		listusers.WithListUsersMaxConcurrentReads(s.maxConcurrentReadsForListUsers),
+		listusers.WithWildcardHandling(wildcardHandling),
+		listusers.WithListUsersUserIDPrefix(idPrefixFromHeader(ctx)),
 		listusers.WithDispatchThrottlerConfig(threshold.Config{
 			Throttler:    s.listUsersDispatchThrottler,
 			Enabled:      s.listUsersDispatchThrottlingEnabled,
@@ -161,6 +181,23 @@ func (s *Server) ListUsers(
 	}, nil
 }
 
+// wildcardHandlingFromHeader returns the wildcard handling requested in the ListUsersWildcardsHeader.
+func wildcardHandlingFromHeader(ctx context.Context) (listusers.WildcardHandling, error) {
+	var value string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// grpc-gateway converts header names to lowercase
+		if values := md.Get(strings.ToLower(ListUsersWildcardsHeader)); len(values) > 0 {
+			value = strings.ToLower(strings.TrimSpace(values[0]))
+		}
+	}
+
+	h, err := listusers.ParseWildcardHandling(value)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return h, nil
+}
+
 func userFiltersToString(filter []*openfgav1.UserTypeFilter) string {
 	var s strings.Builder
 	for _, f := range filter {
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/goleak"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -216,6 +217,53 @@ func TestModelIdNotFound(t *testing.T) {
 	require.Equal(t, codes.Code(2020), e.Code())
 }
 
+func TestListUsers_WildcardsHeader(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user, user:*]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:*"),
+		tuple.NewTupleKey("document:2", "viewer", "user:jon"),
+	}))
+
+	listUsers := func(wildcards string) (*openfgav1.ListUsersResponse, error) {
+		return s.ListUsers(metadata.NewIncomingContext(ctx, metadata.Pairs(ListUsersWildcardsHeader, wildcards)), &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "document", Id: "1"},
+			Relation:    "viewer",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		})
+	}
+
+	resp, err := listUsers("include")
+	require.NoError(t, err)
+	require.Equal(t, []*openfgav1.User{tuple.StringToUserProto("user:*")}, resp.GetUsers())
+
+	resp, err = listUsers("Expand")
+	require.NoError(t, err)
+	require.Equal(t, []*openfgav1.User{tuple.StringToUserProto("user:jon")}, resp.GetUsers())
+
+	resp, err = listUsers("exclude")
+	require.NoError(t, err)
+	require.Empty(t, resp.GetUsers())
+
+	_, err = listUsers("all")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestExperimentalListUsers(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,16 @@
+// Package listobjectssubscription serves the subscriptions to the objects of a ListObjects, i.e.
+// to the objects a user has a relation with, as a stream of the objects added to and removed from
+// them, so that the clients can keep them up to date without polling ListObjects.
+//
+// The first delta of a subscription has all the objects, and is marked as initial. The following
+// deltas are sent as the writes to the store change the objects: the changelog of the store is
+// followed, and the objects are listed again when the changed tuples can affect them. The
+// subscriptions use the authorization model resolved when they start, and their objects are
+// listed with HIGHER_CONSISTENCY, so that they reflect the changes that triggered them.
+//
+// The subscriptions are served by the ListObjects subscription service, a server streaming method
+// whose request is a ListObjectsRequest of the OpenFGA API encoded as protobuf JSON, and whose
+// deltas are encoded as JSON: /openfga.listobjectssubscription.v1.ListObjectsSubscriptionService/Subscribe.
+// It is also served over HTTP as POST /stores/{store_id}/list-objects/subscribe, whose response has
+// one delta per line.
+package listobjectssubscription
@@ -0,0 +1,130 @@
+package listobjectssubscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+const (
+	// ServiceName is the name of the ListObjects subscription service.
+	ServiceName = "openfga.listobjectssubscription.v1.ListObjectsSubscriptionService"
+
+	// SubscribeMethod is the full name of the server streaming method that streams the changes of
+	// the objects of a ListObjects.
+	SubscribeMethod = "/" + ServiceName + "/Subscribe"
+
+	// codecName is the content-subtype of the ListObjects subscription requests. The ListObjects
+	// subscription service is not part of the OpenFGA API, so its deltas are encoded as JSON instead
+	// of generated protobufs. Its request, a ListObjectsRequest, is encoded as protobuf JSON.
+	codecName = "openfga-listobjectssubscription-json"
+)
+
+var subscribeStreamDesc = grpc.StreamDesc{
+	StreamName:    "Subscribe",
+	ServerStreams: true,
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Unmarshal(data, m)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// Delta is a change of the objects of a subscription, sent as soon as it is found.
+type Delta struct {
+	// Initial marks the first delta of a subscription, whose Added are all its objects.
+	Initial bool `json:"initial,omitempty"`
+
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Server streams the changes of the objects of a ListObjects. It is implemented by the OpenFGA
+// server.
+type Server interface {
+	SubscribeListObjects(ctx context.Context, req *openfgav1.ListObjectsRequest, send func(*Delta) error) error
+}
+
+// RegisterServer registers the ListObjects subscription service, which streams the changes of the
+// objects of a ListObjects with srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	desc := subscribeStreamDesc
+	desc.Handler = subscribeHandler
+
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods:     []grpc.MethodDesc{},
+		Streams:     []grpc.StreamDesc{desc},
+		Metadata:    "pkg/server/listobjectssubscription/service.go",
+	}, srv)
+}
+
+func subscribeHandler(srv any, stream grpc.ServerStream) error {
+	in := &openfgav1.ListObjectsRequest{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(Server).SubscribeListObjects(stream.Context(), in, func(delta *Delta) error {
+		return stream.SendMsg(delta)
+	})
+}
+
+// Subscribe calls recv with each delta of the objects of req streamed on conn, until ctx is done
+// or the stream fails. An error returned by recv ends the subscription and is returned.
+func Subscribe(ctx context.Context, conn grpc.ClientConnInterface, req *openfgav1.ListObjectsRequest, recv func(*Delta) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &subscribeStreamDesc, SubscribeMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		delta := &Delta{}
+		err := stream.RecvMsg(delta)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := recv(delta); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/objectlists"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var materializedObjectListsLookupCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "materialized_object_lists_lookup_count",
+	Help:      "The total number of ListObjects requests looked up in the materialized object lists, by outcome.",
+}, []string{"outcome"})
+
+// materializedObjectListKey returns the triple of a ListObjects request, if its objects can be
+// materialized, i.e. it has no contextual tuples, no context and no object ID prefix.
+func materializedObjectListKey(ctx context.Context, req *openfgav1.ListObjectsRequest) (objectlists.Key, bool) {
+	if len(req.GetContextualTuples().GetTupleKeys()) > 0 || len(req.GetContext().GetFields()) > 0 || idPrefixFromHeader(ctx) != "" {
+		return objectlists.Key{}, false
+	}
+	return objectlists.Key{
+		StoreID:    req.GetStoreId(),
+		ModelID:    req.GetAuthorizationModelId(),
+		ObjectType: req.GetType(),
+		Relation:   req.GetRelation(),
+		User:       req.GetUser(),
+	}, true
+}
+
+// getMaterializedObjectList counts the ListObjects request of its triple, and returns the
+// materialized objects of the triple, if they reflect the last write to the store found by the
+// cache controller. With HIGHER_CONSISTENCY, the materialized object lists are not read.
+func (s *Server) getMaterializedObjectList(ctx context.Context, req *openfgav1.ListObjectsRequest) ([]string, bool) {
+	if s.objectListsCache == nil || req.GetConsistency() == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return nil, false
+	}
+	key, ok := materializedObjectListKey(ctx, req)
+	if !ok {
+		return nil, false
+	}
+	s.objectListsCache.Observe(key)
+
+	watermark := s.sharedDatastoreResources.CacheController.DetermineInvalidationTime(ctx, req.GetStoreId())
+	objects, ok := s.objectListsCache.Lookup(key, watermark)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("materialized", ok))
+	if !ok {
+		materializedObjectListsLookupCounter.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	materializedObjectListsLookupCounter.WithLabelValues("hit").Inc()
+	return objects, true
+}
+
+// materializeObjectList lists the objects of a triple with HIGHER_CONSISTENCY, and the deadline and
+// the maximum number of results of ListObjects. The objects are not materialized if the deadline
+// is reached, as they may be partial.
+func (s *Server) materializeObjectList(ctx context.Context, key objectlists.Key) ([]string, error) {
+	typesys, err := s.typesystemResolver(ctx, key.StoreID, key.ModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := s.getListObjectsCheckResolverBuilder(key.StoreID)
+	checkResolver, checkResolverCloser, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	q, err := commands.NewListObjectsQuery(
+		s.datastore,
+		checkResolver,
+		key.StoreID,
+		commands.WithLogger(s.logger),
+		commands.WithListObjectsDeadline(s.listObjectsDeadline),
+		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
+		commands.WithResolveNodeLimit(s.resolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithFeatureFlagClient(s.featureFlagClient),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := q.Execute(typesystem.ContextWithTypesystem(ctx, typesys), &openfgav1.ListObjectsRequest{
+		StoreId:              key.StoreID,
+		AuthorizationModelId: key.ModelID,
+		Type:                 key.ObjectType,
+		Relation:             key.Relation,
+		User:                 key.User,
+		Consistency:          openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.listObjectsDeadline > 0 && time.Since(start) >= s.listObjectsDeadline {
+		return nil, fmt.Errorf("%w: the ListObjects deadline was reached", objectlists.ErrNotEligible)
+	}
+	return result.Objects, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestMaterializedObjectLists(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "materialized-object-lists"})
+	require.NoError(t, err)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithMaterializedObjectListsEnabled(true),
+		WithMaterializedObjectListsInterval(time.Hour),
+		WithMaterializedObjectListsMinRequests(1),
+	)
+	t.Cleanup(s.Close)
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+	})
+	require.NoError(t, err)
+
+	listObjects := func(consistency openfgav1.ConsistencyPreference) []string {
+		resp, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:     storeID,
+			Type:        "document",
+			Relation:    "viewer",
+			User:        "user:anne",
+			Consistency: consistency,
+		})
+		require.NoError(t, err)
+		return resp.GetObjects()
+	}
+	require.Equal(t, []string{"document:1"}, listObjects(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+	require.NoError(t, s.objectListsCache.Run(ctx))
+
+	// the tuple is deleted behind the back of the server, so that only the requests resolved from
+	// the tuples see it
+	require.NoError(t, ds.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk)}, nil))
+
+	require.Equal(t, []string{"document:1"}, listObjects(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+	require.Empty(t, listObjects(openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+
+	require.NoError(t, s.objectListsCache.Run(ctx))
+	require.Empty(t, listObjects(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"document:1"}, listObjects(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+}
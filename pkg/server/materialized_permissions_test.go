@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/internal/materialize"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestMaterializedPermissions(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "materialized-permissions"})
+	require.NoError(t, err)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithMaterializedPermissions([]materialize.Relation{{StoreID: storeID, ObjectType: "document", Relation: "viewer"}}, time.Hour),
+	)
+	t.Cleanup(s.Close)
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.materializer.Run(ctx))
+
+	// the tuple is deleted behind the back of the materializer, so that only the Checks resolved
+	// from the tuples see it
+	require.NoError(t, ds.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk)}, nil))
+
+	check := func(consistency openfgav1.ConsistencyPreference) bool {
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:     storeID,
+			TupleKey:    tuple.NewCheckRequestTupleKey(tk.GetObject(), tk.GetRelation(), tk.GetUser()),
+			Consistency: consistency,
+		})
+		require.NoError(t, err)
+		return resp.GetAllowed()
+	}
+	require.True(t, check(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+	require.False(t, check(openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+
+	require.NoError(t, s.materializer.Run(ctx))
+	require.False(t, check(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/modelvalidation"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/modelsimulation"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ErrModelSimulationDisabled is returned by SimulateModelChange when the model simulations are
+// not enabled with WithModelSimulationEnabled.
+var ErrModelSimulationDisabled = status.Error(codes.Unimplemented, "model simulation is not enabled")
+
+// SimulateModelChange evaluates the checks of req with the current model of the store and with
+// the proposed model of req, which is not written, and reports the checks whose outcome differs.
+// It is authorized like BatchCheck.
+func (s *Server) SimulateModelChange(ctx context.Context, req *modelsimulation.SimulateRequest) (*modelsimulation.SimulateResponse, error) {
+	ctx, span := tracer.Start(ctx, "SimulateModelChange", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.Int("checks", len(req.Checks)),
+	))
+	defer span.End()
+
+	if !s.modelSimulationEnabled {
+		return nil, ErrModelSimulationDisabled
+	}
+
+	if err := (&openfgav1.ReadAuthorizationModelsRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.AuthorizationModelID != "" {
+		if err := (&openfgav1.ReadAuthorizationModelRequest{StoreId: req.StoreID, Id: req.AuthorizationModelID}).Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if len(req.ProposedModel) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "proposed_model is required")
+	}
+	if len(req.Checks) > int(s.maxChecksPerBatchCheck) {
+		return nil, serverErrors.ValidationError(fmt.Errorf("simulation received %d checks, the maximum allowed is %d", len(req.Checks), s.maxChecksPerBatchCheck))
+	}
+
+	checks := make([]*openfgav1.CheckRequest, 0, len(req.Checks))
+	for i, check := range req.Checks {
+		checkReq := &openfgav1.CheckRequest{}
+		if err := protojson.Unmarshal(check, checkReq); err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid check %d: %s", i, err))
+		}
+		checkReq.StoreId = req.StoreID
+		checkReq.AuthorizationModelId = ""
+		if err := checkReq.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid check %d: %s", i, err))
+		}
+		checks = append(checks, checkReq)
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.BatchCheck.String(),
+	})
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.BatchCheck); err != nil {
+		return nil, err
+	}
+
+	current, err := s.resolveTypesystem(ctx, req.StoreID, req.AuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := s.proposedTypesystem(ctx, req.ProposedModel)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &modelsimulation.SimulateResponse{
+		AuthorizationModelID: current.GetAuthorizationModelID(),
+		Checks:               len(checks),
+		Changes:              []*modelsimulation.Change{},
+	}
+	for i, check := range checks {
+		params := &commands.CheckCommandParams{
+			StoreID:          check.GetStoreId(),
+			TupleKey:         check.GetTupleKey(),
+			ContextualTuples: check.GetContextualTuples(),
+			Context:          check.GetContext(),
+			Consistency:      check.GetConsistency(),
+		}
+		currentOutcome := s.simulateCheck(ctx, current, params)
+		proposedOutcome := s.simulateCheck(ctx, proposed, params)
+		if ctx.Err() != nil {
+			return nil, serverErrors.HandleError("", ctx.Err())
+		}
+		if currentOutcome != proposedOutcome {
+			res.Changes = append(res.Changes, &modelsimulation.Change{
+				Index:    i,
+				Request:  req.Checks[i],
+				Current:  currentOutcome,
+				Proposed: proposedOutcome,
+			})
+		}
+	}
+	return res, nil
+}
+
+// proposedTypesystem validates the proposed model of a simulation as WriteAuthorizationModel
+// does, and returns its typesystem.
+func (s *Server) proposedTypesystem(ctx context.Context, data []byte) (*typesystem.TypeSystem, error) {
+	model := &openfgav1.AuthorizationModel{}
+	if err := protojson.Unmarshal(data, model); err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid proposed_model: %s", err))
+	}
+	err := modelvalidation.Validate(ctx, model, modelvalidation.WithMaxAuthorizationModelSizeInBytes(s.maxAuthorizationModelSizeInBytes))
+	if err != nil {
+		return nil, serverErrors.ValidationError(err)
+	}
+	// the resolution requires a model ID, and a new one can't collide with the cached models
+	model.Id = ulid.Make().String()
+	return typesystem.NewAndValidate(ctx, model)
+}
+
+// simulateCheck returns the outcome of the Check of params with the model of typesys.
+func (s *Server) simulateCheck(ctx context.Context, typesys *typesystem.TypeSystem, params *commands.CheckCommandParams) modelsimulation.Outcome {
+	allowed, err := s.resolveUncachedCheck(ctx, s.datastore, typesys, params)
+	if err != nil {
+		return modelsimulation.Outcome{Error: status.Convert(err).Message()}
+	}
+	return modelsimulation.Outcome{Allowed: allowed}
+}
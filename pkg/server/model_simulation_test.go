@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/modelsimulation"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestSimulateModelChange(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithModelSimulationEnabled(true))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "simulation"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define owner: [user]
+				define editor: [user]
+				define viewer: [user] or editor`)
+	writeModel, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "owner", "user:anne"),
+				tuple.NewTupleKey("document:1", "editor", "user:bob"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// the proposed model grants viewer to the owners instead of the editors
+	proposed := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define owner: [user]
+				define viewer: [user] or owner`)
+	proposedModel, err := protojson.Marshal(proposed)
+	require.NoError(t, err)
+
+	checks := []json.RawMessage{
+		json.RawMessage(`{"tuple_key": {"object": "document:1", "relation": "viewer", "user": "user:anne"}}`),
+		json.RawMessage(`{"tuple_key": {"object": "document:1", "relation": "viewer", "user": "user:bob"}}`),
+		json.RawMessage(`{"tuple_key": {"object": "document:1", "relation": "owner", "user": "user:anne"}}`),
+		json.RawMessage(`{"tuple_key": {"object": "document:1", "relation": "editor", "user": "user:bob"}}`),
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		res, err := s.SimulateModelChange(ctx, &modelsimulation.SimulateRequest{
+			StoreID:       storeID,
+			ProposedModel: proposedModel,
+			Checks:        checks,
+		})
+		require.NoError(t, err)
+		require.Equal(t, writeModel.GetAuthorizationModelId(), res.AuthorizationModelID)
+		require.Equal(t, 4, res.Checks)
+		require.Len(t, res.Changes, 3)
+
+		require.Equal(t, 0, res.Changes[0].Index)
+		require.Equal(t, modelsimulation.Outcome{Allowed: false}, res.Changes[0].Current)
+		require.Equal(t, modelsimulation.Outcome{Allowed: true}, res.Changes[0].Proposed)
+
+		require.Equal(t, 1, res.Changes[1].Index)
+		require.Equal(t, modelsimulation.Outcome{Allowed: true}, res.Changes[1].Current)
+		require.Equal(t, modelsimulation.Outcome{Allowed: false}, res.Changes[1].Proposed)
+
+		// the proposed model removes the editor relation
+		require.Equal(t, 3, res.Changes[2].Index)
+		require.Equal(t, modelsimulation.Outcome{Allowed: true}, res.Changes[2].Current)
+		require.NotEmpty(t, res.Changes[2].Proposed.Error)
+	})
+
+	t.Run("invalid_proposed_model", func(t *testing.T) {
+		_, err := s.SimulateModelChange(ctx, &modelsimulation.SimulateRequest{
+			StoreID:       storeID,
+			ProposedModel: json.RawMessage(`{"schema_version": "1.1", "type_definitions": [{"type": "document", "relations": {"viewer": {"computedUserset": {"relation": "owner"}}}}]}`),
+			Checks:        checks,
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	t.Run("invalid_check", func(t *testing.T) {
+		_, err := s.SimulateModelChange(ctx, &modelsimulation.SimulateRequest{
+			StoreID:       storeID,
+			ProposedModel: proposedModel,
+			Checks:        []json.RawMessage{json.RawMessage(`{"tuple_key": {"object": "document:1"}}`)},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestSimulateModelChangeDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	_, err := s.SimulateModelChange(context.Background(), &modelsimulation.SimulateRequest{
+		StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	})
+	require.ErrorIs(t, err, ErrModelSimulationDisabled)
+}
@@ -0,0 +1,12 @@
+// Package modelsimulation evaluates a set of Check requests against both the current
+// authorization model of a store and a proposed model that is not written, with the tuples of the
+// store, and reports the checks whose outcome would change, to de-risk the rollout of a model.
+//
+// The checks are the ones of the Check API, e.g. the Check requests of a recording made with the
+// `--request-recording-enabled` flag of `openfga run`, which the `openfga simulate-model` command
+// submits in batches.
+//
+// The simulations are served by the model simulation service, whose messages are encoded as JSON:
+// /openfga.modelsimulation.v1.ModelSimulationService/Simulate. It is also served over HTTP as
+// POST /stores/{store_id}/model-simulation.
+package modelsimulation
@@ -0,0 +1,47 @@
+package modelsimulation
+
+import (
+	"encoding/json"
+)
+
+// SimulateRequest requests the outcomes of Checks with a proposed model and with a model of a
+// store, or its latest model if AuthorizationModelID is empty.
+type SimulateRequest struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+
+	// ProposedModel is the proposed authorization model, in the JSON of the WriteAuthorizationModel
+	// API: its schema_version, type_definitions and conditions.
+	ProposedModel json.RawMessage `json:"proposed_model"`
+
+	// Checks are the Check requests, in the JSON of the Check API. Their store and authorization
+	// model are ignored.
+	Checks []json.RawMessage `json:"checks"`
+}
+
+// Outcome is the outcome of a Check with a model.
+type Outcome struct {
+	Allowed bool `json:"allowed"`
+
+	// Error is the error of the Check, e.g. because the proposed model removed the relation of
+	// the check.
+	Error string `json:"error,omitempty"`
+}
+
+// Change is a Check whose outcome with the proposed model differs from its outcome with the
+// current model.
+type Change struct {
+	// Index is the index of the check in the checks of the request.
+	Index    int             `json:"index"`
+	Request  json.RawMessage `json:"request"`
+	Current  Outcome         `json:"current"`
+	Proposed Outcome         `json:"proposed"`
+}
+
+// SimulateResponse reports the checks whose outcome would change with the proposed model.
+type SimulateResponse struct {
+	// AuthorizationModelID is the ID of the current model that the checks were evaluated with.
+	AuthorizationModelID string    `json:"authorization_model_id"`
+	Checks               int       `json:"checks"`
+	Changes              []*Change `json:"changes"`
+}
@@ -0,0 +1,86 @@
+package modelsimulation
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the model simulation service.
+	ServiceName = "openfga.modelsimulation.v1.ModelSimulationService"
+
+	// SimulateMethod is the full name of the method that simulates a model change.
+	SimulateMethod = "/" + ServiceName + "/Simulate"
+
+	// codecName is the content-subtype of the model simulation requests. The model simulation
+	// service is not part of the OpenFGA API, so its messages are encoded as JSON instead of
+	// generated protobufs.
+	codecName = "openfga-modelsimulation-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// Server simulates model changes. It is implemented by the OpenFGA server.
+type Server interface {
+	SimulateModelChange(ctx context.Context, req *SimulateRequest) (*SimulateResponse, error)
+}
+
+// RegisterServer registers the model simulation service, which simulates model changes with srv,
+// on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Simulate",
+				Handler:    simulateHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/modelsimulation/service.go",
+	}, srv)
+}
+
+func simulateHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &SimulateRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).SimulateModelChange(ctx, req.(*SimulateRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: SimulateMethod}, handler)
+}
+
+// Simulate simulates a model change on conn.
+func Simulate(ctx context.Context, conn grpc.ClientConnInterface, req *SimulateRequest) (*SimulateResponse, error) {
+	out := &SimulateResponse{}
+	if err := conn.Invoke(ctx, SimulateMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,10 @@
+// Package purge deletes the tuples of a store, or the tuples of a store matching a filter, in
+// batches of the maximum number of tuples per write, so that no transaction is long-running.
+//
+// Purge runs the purge jobs of the jobs package: the jobs that delete the tuples matching a filter,
+// which is preferable to a DeleteTuples of the bulk write service when the filter matches too many
+// tuples for one request, and, when the purge of the deleted stores is enabled, the jobs that
+// deleting a store creates to delete its tuples, which the datastores otherwise keep. Like the other
+// jobs, they are persisted in the datastore and their state, progress and estimated completion are
+// reported by the jobs service of any replica.
+package purge
@@ -0,0 +1,71 @@
+package purge
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// Purge deletes the tuples of storeID matching filter, or all the tuples of storeID if it is
+// empty, in batches of the maximum number of tuples per write of datastore. It calls progress
+// with the number of matching tuples once they are counted, and with the number of tuples of each
+// deleted batch.
+func Purge(ctx context.Context, datastore storage.OpenFGADatastore, storeID string, filter storage.ReadFilter, progress func(deleted, total uint64)) error {
+	batchSize := datastore.MaxTuplesPerWrite()
+
+	total, err := count(ctx, datastore, storeID, filter, batchSize)
+	if err != nil {
+		return err
+	}
+	progress(0, total)
+
+	// the first page is read again after each batch: the continuation tokens of some datastores
+	// are offsets, which the deleted tuples would shift
+	opts := storage.ReadPageOptions{
+		Pagination:  storage.NewPaginationOptions(int32(batchSize), ""),
+		Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+	}
+	for {
+		tuples, _, err := datastore.ReadPage(ctx, storeID, filter, opts)
+		if err != nil {
+			return err
+		}
+		if len(tuples) == 0 {
+			return nil
+		}
+
+		deletes := make(storage.Deletes, 0, len(tuples))
+		for _, t := range tuples {
+			deletes = append(deletes, tuple.TupleKeyToTupleKeyWithoutCondition(t.GetKey()))
+		}
+		err = datastore.Write(ctx, storeID, deletes, nil, storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore))
+		if err != nil {
+			return err
+		}
+
+		progress(uint64(len(deletes)), total)
+	}
+}
+
+// count returns the number of tuples of storeID matching filter.
+func count(ctx context.Context, datastore storage.OpenFGADatastore, storeID string, filter storage.ReadFilter, pageSize int) (uint64, error) {
+	var count uint64
+	token := ""
+	for {
+		tuples, next, err := datastore.ReadPage(ctx, storeID, filter, storage.ReadPageOptions{
+			Pagination:  storage.NewPaginationOptions(int32(pageSize), token),
+			Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+		})
+		if err != nil {
+			return 0, err
+		}
+		count += uint64(len(tuples))
+		if next == "" || len(tuples) == 0 {
+			return count, nil
+		}
+		token = next
+	}
+}
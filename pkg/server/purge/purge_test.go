@@ -0,0 +1,82 @@
+package purge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func writeTuples(t *testing.T, ds storage.OpenFGADatastore, storeID string, objects ...string) {
+	t.Helper()
+
+	writes := make(storage.Writes, 0, len(objects))
+	for _, object := range objects {
+		writes = append(writes, tuple.NewTupleKey(object, "viewer", "user:anne"))
+	}
+	require.NoError(t, ds.Write(context.Background(), storeID, nil, writes))
+}
+
+func readTuples(t *testing.T, ds storage.OpenFGADatastore, storeID string) []*openfgav1.Tuple {
+	t.Helper()
+
+	tuples, _, err := ds.ReadPage(context.Background(), storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+		Pagination: storage.NewPaginationOptions(100, ""),
+	})
+	require.NoError(t, err)
+	return tuples
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+
+	// deletes in batches of 2 tuples
+	ds := memory.New(memory.WithMaxTuplesPerWrite(2))
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	otherStoreID := ulid.Make().String()
+	writeTuples(t, ds, storeID, "document:1", "document:2")
+	writeTuples(t, ds, storeID, "document:3", "folder:1", "folder:2")
+	writeTuples(t, ds, otherStoreID, "document:1")
+
+	t.Run("filter", func(t *testing.T) {
+		var progress [][2]uint64
+		err := Purge(ctx, ds, storeID, storage.ReadFilter{Object: "document:"}, func(deleted, total uint64) {
+			progress = append(progress, [2]uint64{deleted, total})
+		})
+		require.NoError(t, err)
+		// the count, then the batches
+		require.Equal(t, [][2]uint64{{0, 3}, {2, 3}, {1, 3}}, progress)
+
+		require.Len(t, readTuples(t, ds, storeID), 2)
+	})
+
+	t.Run("store", func(t *testing.T) {
+		var deleted uint64
+		err := Purge(ctx, ds, storeID, storage.ReadFilter{}, func(batch, _ uint64) {
+			deleted += batch
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), deleted)
+
+		require.Empty(t, readTuples(t, ds, storeID))
+		require.Len(t, readTuples(t, ds, otherStoreID), 1)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		err := Purge(canceled, ds, otherStoreID, storage.ReadFilter{}, func(uint64, uint64) {})
+		require.ErrorIs(t, err, context.Canceled)
+
+		require.Len(t, readTuples(t, ds, otherStoreID), 1)
+	})
+}
@@ -2,20 +2,35 @@ package server
 
 import (
 	"context"
+	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/internal/fieldmask"
 	"github.com/openfga/openfga/internal/telemetry"
 	"github.com/openfga/openfga/internal/utils/apimethod"
 	"github.com/openfga/openfga/pkg/middleware/validator"
 	"github.com/openfga/openfga/pkg/server/commands"
 )
 
+// ReadConditionsHeader filters the tuples returned by Read by condition name. When a request
+// sets it to a comma-separated list of condition names, e.g. "expired_grant", only the tuples
+// with one of those conditions are returned.
+const ReadConditionsHeader = "Openfga-Read-Conditions"
+
+// ReadUserTypeHeader filters the tuples returned by Read by the type of their user. When a request
+// sets it to a type, e.g. "group", only the tuples whose user is of that type are returned,
+// including usersets (group:eng#member) and wildcards (group:*). The request must not set the
+// user of the tuple key.
+const ReadUserTypeHeader = "Openfga-Read-User-Type"
+
 func (s *Server) Read(ctx context.Context, req *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error) {
 	tk := req.GetTupleKey()
 	ctx, span := tracer.Start(ctx, apimethod.Read.String(), trace.WithAttributes(
@@ -42,17 +57,83 @@ func (s *Server) Read(ctx context.Context, req *openfgav1.ReadRequest) (*openfga
 	if err != nil {
 		return nil, err
 	}
+	req.Consistency = s.sessionConsistency(ctx, req.GetStoreId(), req.GetConsistency())
+
+	mask, err := fieldMaskFromHeader(ctx, &openfgav1.Tuple{})
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, userType := readFiltersFromHeaders(ctx)
 
 	q := commands.NewReadQuery(s.datastore,
 		commands.WithReadQueryLogger(s.logger),
 		commands.WithReadQueryEncoder(s.encoder),
 		commands.WithReadQueryTokenSerializer(s.tokenSerializer),
+		commands.WithReadQueryConditions(conditions),
+		commands.WithReadQueryUserType(userType),
 	)
-	return q.Execute(ctx, &openfgav1.ReadRequest{
+	resp, err := q.Execute(ctx, &openfgav1.ReadRequest{
 		StoreId:           req.GetStoreId(),
 		TupleKey:          tk,
 		PageSize:          req.GetPageSize(),
 		ContinuationToken: req.GetContinuationToken(),
 		Consistency:       req.GetConsistency(),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tuple := range resp.GetTuples() {
+		mask.Apply(tuple)
+	}
+
+	return resp, nil
+}
+
+// readFiltersFromHeaders returns the condition names of the ReadConditionsHeader and the user type
+// of the ReadUserTypeHeader, if any.
+func readFiltersFromHeaders(ctx context.Context) ([]string, string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ""
+	}
+
+	var conditions []string
+	// grpc-gateway converts header names to lowercase
+	for _, value := range md.Get(strings.ToLower(ReadConditionsHeader)) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				conditions = append(conditions, name)
+			}
+		}
+	}
+
+	var userType string
+	if values := md.Get(strings.ToLower(ReadUserTypeHeader)); len(values) > 0 {
+		userType = strings.TrimSpace(values[0])
+	}
+
+	return conditions, userType
+}
+
+// fieldMaskFromHeader parses the field mask sent in the FieldsHeader, if any, against the
+// type of the items returned by the API. A nil mask is returned if the header is not present.
+func fieldMaskFromHeader(ctx context.Context, item proto.Message) (*fieldmask.Mask, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(FieldsHeader))
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	mask, err := fieldmask.Parse(strings.Join(values, ","), item)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return mask, nil
 }
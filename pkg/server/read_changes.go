@@ -50,11 +50,26 @@ func (s *Server) ReadChanges(ctx context.Context, req *openfgav1.ReadChangesRequ
 		return nil, err
 	}
 
+	mask, err := fieldMaskFromHeader(ctx, &openfgav1.TupleChange{})
+	if err != nil {
+		return nil, err
+	}
+
 	q := commands.NewReadChangesQuery(s.datastore,
 		commands.WithReadChangesQueryLogger(s.logger),
 		commands.WithReadChangesQueryEncoder(s.encoder),
 		commands.WithContinuationTokenSerializer(s.tokenSerializer),
 		commands.WithReadChangeQueryHorizonOffset(s.changelogHorizonOffset),
+		commands.WithReadChangesCursorTracker(s.changelogCursors),
 	)
-	return q.Execute(ctx, req)
+	resp, err := q.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range resp.GetChanges() {
+		mask.Apply(change)
+	}
+
+	return resp, nil
 }
@@ -2,16 +2,21 @@ package server
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
 	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
 )
 
 func TestReadChangesPageSizeValidation(t *testing.T) {
@@ -283,3 +288,104 @@ func TestReadChangesPageSizeValidation(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid ReadChangesRequest.PageSize: value must be inside range [1, 100]")
 	})
 }
+
+func TestReadAndReadChangesFieldMask(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	openfga := MustNewServerWithOpts(
+		WithDatastore(ds),
+	)
+	t.Cleanup(openfga.Close)
+
+	storeID := ulid.Make().String()
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	withFields := func(fields string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(strings.ToLower(FieldsHeader), fields))
+	}
+
+	t.Run("read", func(t *testing.T) {
+		resp, err := openfga.Read(withFields("key.object,key.relation"), &openfgav1.ReadRequest{
+			StoreId: storeID,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetTuples(), 1)
+		require.Equal(t, "document:1", resp.GetTuples()[0].GetKey().GetObject())
+		require.Equal(t, "viewer", resp.GetTuples()[0].GetKey().GetRelation())
+		require.Empty(t, resp.GetTuples()[0].GetKey().GetUser())
+		require.Nil(t, resp.GetTuples()[0].GetTimestamp())
+	})
+
+	t.Run("read_changes", func(t *testing.T) {
+		resp, err := openfga.ReadChanges(withFields("tuple_key.user"), &openfgav1.ReadChangesRequest{
+			StoreId: storeID,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetChanges(), 1)
+		require.Equal(t, "user:anne", resp.GetChanges()[0].GetTupleKey().GetUser())
+		require.Empty(t, resp.GetChanges()[0].GetTupleKey().GetObject())
+		require.Nil(t, resp.GetChanges()[0].GetTimestamp())
+		require.NotEmpty(t, resp.GetContinuationToken())
+	})
+
+	t.Run("invalid_field_mask", func(t *testing.T) {
+		_, err := openfga.Read(withFields("key.unknown"), &openfgav1.ReadRequest{
+			StoreId: storeID,
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestReadChangesCursorsAreRetained(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithChangelogRetentionEnabled(true),
+		WithChangelogRetentionPolicy(0, 1),
+	)
+	t.Cleanup(s.Close)
+
+	store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "retention"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	for _, object := range []string{"document:1", "document:2", "document:3", "document:4", "document:5"} {
+		err := ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tuple.NewTupleKey(object, "viewer", "user:jon")})
+		require.NoError(t, err)
+	}
+
+	resp, err := s.ReadChanges(ctx, &openfgav1.ReadChangesRequest{
+		StoreId:  storeID,
+		PageSize: wrapperspb.Int32(2),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetChanges(), 2)
+
+	require.NoError(t, s.changelogRetainer.Run(ctx))
+
+	// Only the change read before the cursor was deleted.
+	resp, err = s.ReadChanges(ctx, &openfgav1.ReadChangesRequest{
+		StoreId:           storeID,
+		ContinuationToken: resp.GetContinuationToken(),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetChanges(), 3)
+
+	resp, err = s.ReadChanges(ctx, &openfgav1.ReadChangesRequest{StoreId: storeID})
+	require.NoError(t, err)
+	require.Len(t, resp.GetChanges(), 4)
+}
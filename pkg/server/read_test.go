@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestReadFilterHeaders(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "expired_grant", nil),
+		tuple.NewTupleKeyWithCondition("folder:1", "viewer", "user:bob", "expired_grant", nil),
+		tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("document:2", "viewer", "group:*"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+	}))
+
+	read := func(md metadata.MD, tk *openfgav1.ReadRequestTupleKey) ([]*openfgav1.TupleKey, error) {
+		resp, err := s.Read(metadata.NewIncomingContext(ctx, md), &openfgav1.ReadRequest{
+			StoreId:  storeID,
+			TupleKey: tk,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return testutils.ConvertTuplesToTupleKeys(resp.GetTuples()), nil
+	}
+
+	t.Run("conditions", func(t *testing.T) {
+		tuples, err := read(metadata.Pairs(ReadConditionsHeader, "expired_grant, other"), nil)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "expired_grant", nil),
+			tuple.NewTupleKeyWithCondition("folder:1", "viewer", "user:bob", "expired_grant", nil),
+		}, tuples)
+	})
+
+	t.Run("user_type", func(t *testing.T) {
+		tuples, err := read(metadata.Pairs(ReadUserTypeHeader, "group"), nil)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+			tuple.NewTupleKey("document:2", "viewer", "group:*"),
+		}, tuples)
+
+		tuples, err = read(metadata.Pairs(ReadUserTypeHeader, "user"), &openfgav1.ReadRequestTupleKey{Object: "document:"})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "expired_grant", nil),
+			tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		}, tuples)
+	})
+
+	t.Run("user_type_and_conditions", func(t *testing.T) {
+		tuples, err := read(metadata.Pairs(ReadUserTypeHeader, "user", ReadConditionsHeader, "expired_grant"), &openfgav1.ReadRequestTupleKey{Object: "document:"})
+		require.NoError(t, err)
+		require.Equal(t, []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "expired_grant", nil),
+		}, tuples)
+	})
+
+	t.Run("user_type_with_user", func(t *testing.T) {
+		_, err := read(metadata.Pairs(ReadUserTypeHeader, "user"), &openfgav1.ReadRequestTupleKey{User: "user:anne"})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	t.Run("invalid_user_type", func(t *testing.T) {
+		_, err := read(metadata.Pairs(ReadUserTypeHeader, "group#member"), nil)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+}
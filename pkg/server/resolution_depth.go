@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/graph"
+)
+
+// MaxResolutionDepthHeader lowers, for a Check, BatchCheck, ListObjects, StreamedListObjects or
+// ListUsers request, the maximum resolution depth of the server (see WithResolveNodeLimit), so
+// that latency-critical requests on recursive models fail fast instead of exploring deep paths.
+// Its value is a positive integer; values greater than the limit of the server are capped to it.
+// The requests that exceed the depth fail with a resolution depth exceeded error.
+const MaxResolutionDepthHeader = "Openfga-Max-Resolution-Depth"
+
+// resolveNodeLimitFromHeader returns the maximum resolution depth of the request: the one of the
+// MaxResolutionDepthHeader if it is lower than the limit of the server, else the limit of the
+// server.
+func (s *Server) resolveNodeLimitFromHeader(ctx context.Context) (uint32, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return s.resolveNodeLimit, nil
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(MaxResolutionDepthHeader))
+	if len(values) == 0 {
+		return s.resolveNodeLimit, nil
+	}
+
+	depth, err := strconv.ParseUint(strings.TrimSpace(values[0]), 10, 32)
+	if err != nil || depth == 0 {
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s header %q: it must be a positive integer", MaxResolutionDepthHeader, values[0]))
+	}
+	return min(uint32(depth), s.resolveNodeLimit), nil
+}
+
+// contextWithMaxResolutionDepth returns ctx with the maximum resolution depth of the request
+// requested in the MaxResolutionDepthHeader, for the check resolvers of the server.
+func (s *Server) contextWithMaxResolutionDepth(ctx context.Context) (context.Context, uint32, error) {
+	limit, err := s.resolveNodeLimitFromHeader(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if limit < s.resolveNodeLimit {
+		ctx = graph.ContextWithMaxResolutionDepth(ctx, limit)
+	}
+	return ctx, limit, nil
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestMaxResolutionDepthHeader(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds), WithResolveNodeLimit(10))
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:1", "member", "user:anne"),
+		tuple.NewTupleKey("group:2", "member", "group:1#member"),
+		tuple.NewTupleKey("group:3", "member", "group:2#member"),
+		tuple.NewTupleKey("group:4", "member", "group:3#member"),
+		tuple.NewTupleKey("group:5", "member", "group:4#member"),
+	}))
+
+	withDepth := func(depth string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(MaxResolutionDepthHeader, depth))
+	}
+	check := &openfgav1.CheckRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewCheckRequestTupleKey("group:5", "member", "user:anne"),
+	}
+
+	t.Run("check", func(t *testing.T) {
+		resp, err := s.Check(ctx, check)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+
+		_, err = s.Check(withDepth("2"), check)
+		require.ErrorIs(t, err, serverErrors.ErrAuthorizationModelResolutionTooComplex)
+	})
+
+	t.Run("check_capped_to_the_server_limit", func(t *testing.T) {
+		resp, err := s.Check(withDepth("100"), check)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := s.Check(withDepth("0"), check)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = s.Check(withDepth("deep"), check)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("list_objects", func(t *testing.T) {
+		req := &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "group",
+			Relation: "member",
+			User:     "user:anne",
+		}
+		resp, err := s.ListObjects(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, resp.GetObjects(), 5)
+
+		_, err = s.ListObjects(withDepth("2"), req)
+		require.ErrorIs(t, err, serverErrors.ErrAuthorizationModelResolutionTooComplex)
+	})
+
+	t.Run("list_users", func(t *testing.T) {
+		req := &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "group", Id: "5"},
+			Relation:    "member",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		}
+		resp, err := s.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, resp.GetUsers(), 1)
+
+		_, err = s.ListUsers(withDepth("2"), req)
+		require.ErrorIs(t, err, serverErrors.ErrAuthorizationModelResolutionTooComplex)
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"time"
 
@@ -24,22 +27,39 @@ import (
 
 	"github.com/openfga/openfga/internal/authz"
 	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/cachecontroller"
+	"github.com/openfga/openfga/internal/cachehints"
+	"github.com/openfga/openfga/internal/changelog"
+	"github.com/openfga/openfga/internal/cluster"
 	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/hotpath"
 	"github.com/openfga/openfga/internal/listobjects/pipeline"
+	"github.com/openfga/openfga/internal/materialize"
 	"github.com/openfga/openfga/internal/modelgraph"
+	"github.com/openfga/openfga/internal/objectlists"
+	"github.com/openfga/openfga/internal/orphans"
 	"github.com/openfga/openfga/internal/planner"
 	"github.com/openfga/openfga/internal/shared"
 	"github.com/openfga/openfga/internal/telemetry"
 	"github.com/openfga/openfga/internal/throttler"
 	"github.com/openfga/openfga/internal/utils"
 	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/internal/verifier"
 	"github.com/openfga/openfga/pkg/authclaims"
+	"github.com/openfga/openfga/pkg/checkresolver"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/featureflags"
 	"github.com/openfga/openfga/pkg/gateway"
 	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/middleware/priority"
+	"github.com/openfga/openfga/pkg/server/accessreview"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/server/contexthook"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/server/storeevents"
+	"github.com/openfga/openfga/pkg/server/writehook"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers"
 	"github.com/openfga/openfga/pkg/typesystem"
@@ -47,12 +67,16 @@ import (
 
 const (
 	AuthorizationModelIDHeader = "Openfga-Authorization-Model-Id"
-	authorizationModelIDKey    = "authorization_model_id"
+	// FieldsHeader carries a comma-separated field mask that limits the fields returned for
+	// each tuple in Read and ReadChanges responses (e.g. "key.object,key.relation").
+	FieldsHeader            = "Openfga-Fields"
+	authorizationModelIDKey = "authorization_model_id"
 
 	allowedLabel = "allowed"
 
 	throttleTypeDatastore = "datastore"
 	throttleTypeDispatch  = "dispatch"
+	throttleTypeLoad      = "load"
 )
 
 var tracer = otel.Tracer("openfga/pkg/server")
@@ -114,6 +138,18 @@ var (
 
 	listObjectsCheckCountName = "check_count"
 
+	writeValidationHookCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "write_validation_hook_count",
+		Help:      "The total number of Writes submitted to the write validation hook, labeled by the result of the validation.",
+	}, []string{"result"})
+
+	contextEnrichmentHookCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "context_enrichment_hook_count",
+		Help:      "The total number of calls to the context enrichment hook, labeled by the method of the request and the result of the call.",
+	}, []string{"method", "result"})
+
 	throttledRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: build.ProjectName,
 		Name:      "throttled_requests_count",
@@ -150,6 +186,22 @@ var (
 		NativeHistogramMinResetDuration: time.Hour,
 	}, []string{"require_authorize_check", "on_duplicate_write", "on_missing_delete"})
 
+	writeSchedulerQueuedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "write_scheduler_queued_requests",
+		Help:      "The number of Write requests waiting for the write scheduler.",
+	})
+
+	writeSchedulerQueueDurationHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "write_scheduler_queue_duration_ms",
+		Help:                            "The time in milliseconds that the Write requests waited for the write scheduler.",
+		Buckets:                         []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	})
+
 	checkDurationHistogramName = "check_duration_ms"
 	checkDurationHistogram     = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace:                       build.ProjectName,
@@ -173,23 +225,116 @@ type Server struct {
 	tokenSerializer                  encoder.ContinuationTokenSerializer
 	encoder                          encoder.Encoder
 	transport                        gateway.Transport
+	serverTimingEnabled              bool
 	resolveNodeLimit                 uint32
 	resolveNodeBreadthLimit          uint32
 	changelogHorizonOffset           int
+	changelogRetentionEnabled        bool
+	changelogRetentionInterval       time.Duration
+	changelogRetentionMaxAge         time.Duration
+	changelogRetentionMaxEntries     uint32
+	changelogRetentionStorePolicies  map[string]changelog.Policy
+	changelogRetentionCursorLease    time.Duration
+	changelogCursors                 *changelog.CursorTracker
+	changelogRetainer                *changelog.Retainer
+	orphanedTuplesCollectorEnabled   bool
+	orphanedTuplesCollectorInterval  time.Duration
+	orphanedTuplesCollectorDryRun    bool
+	orphanedTuplesCollector          *orphans.Collector
+	storePurgeEnabled                bool
+	storeEventsEnabled               bool
+	storeEventsRecorder              *storeevents.Recorder
+	assertionCoverageEnabled         bool
+	costEstimateEnabled              bool
+	costEstimateSampleSize           int
+	whatIfEnabled                    bool
+	modelSimulationEnabled           bool
+	accessReviewEnabled              bool
+	accessReviewArtifactDir          string
+	accessReviewExporter             *accessreview.Exporter
+	jobsEnabled                      bool
+	jobsWorkers                      int
+	jobsQueueSize                    int
+	jobsRetention                    time.Duration
+	jobsScheduleInterval             time.Duration
+	jobsManager                      *jobs.Manager
+	multiRegionID                    uint8
+	changeListener                   storage.ChangeListener
+	changeSubscriber                 *cachecontroller.Subscriber
+	writeValidationHook              writehook.Hook
+	writeValidationHookTimeout       time.Duration
+	writeValidationHookFailOpen      bool
+	contextEnrichmentHook            contexthook.Hook
+	contextEnrichmentHookTimeout     time.Duration
+	conditionContextHeaders          map[string]string
+	conditionContextClaims           map[string]string
+	consistencyVerifierEnabled       bool
+	consistencyVerifierInterval      time.Duration
+	consistencyVerifierSampleSize    int
+	consistencyVerifier              *verifier.Verifier
+	hotPathsEnabled                  bool
+	hotPathsInterval                 time.Duration
+	hotPathsTopN                     int
+	hotPathsMinChecks                int
+	hotPathsMaxObjects               int
+	hotPathIndex                     *hotpath.Index
+	hotPathPrecomputer               *hotpath.Precomputer
+	clusterSelfAddress               string
+	clusterPeers                     []string
+	clusterSingleflightEnabled       bool
+	clusterForwardTimeout            time.Duration
+	clusterTLSEnabled                bool
+	clusterDispatchEnabled           bool
+	clusterGossipBindAddress         string
+	clusterGossipJoin                []string
+	clusterDispatchTier              []string
+	cluster                          *cluster.Cluster
+	dispatchTier                     *cluster.Cluster
+	checkStoreBulkheadLimit          int
+	checkStoreBulkheadStoreLimits    map[string]int
+	checkStoreBulkheads              *graph.StoreBulkheads
+	checkCoalescingWindow            time.Duration
+	checkCoalescer                   *graph.CheckCoalescer
+	writeLimiter                     *throttler.StoreWriteLimiter
+	writeScheduler                   *priority.Scheduler
+	materializedRelations            []materialize.Relation
+	materializedInterval             time.Duration
+	materializer                     *materialize.Materializer
+	objectListsEnabled               bool
+	objectListsInterval              time.Duration
+	objectListsTopN                  int
+	objectListsMinRequests           int
+	objectListsCache                 *objectlists.Cache
 	readChangesMaxPageSize           int32
 	listObjectsDeadline              time.Duration
 	listObjectsMaxResults            uint32
+	subscriptionPollInterval         time.Duration
+	cacheHintsEnabled                bool
+	cacheHintsMaxAge                 time.Duration
+	cacheInvalidationPollInterval    time.Duration
+	cacheHints                       *cachehints.Tracker
+	sessionTokensEnabled             bool
+	sessionTokensCommitMargin        time.Duration
 	listObjectsPipelineEnabled       bool
 	listObjectsPipelineConfig        pipeline.Config
+	streamedListObjectsMaxInFlight   uint32
+	streamedListObjectsRateLimit     uint32
 	listUsersDeadline                time.Duration
 	listUsersMaxResults              uint32
 	maxChecksPerBatchCheck           uint32
 	maxConcurrentChecksPerBatch      uint32
+	batchCheckLoadSignal             BatchCheckLoadSignal
+	batchCheckLoadThreshold          float64
+	batchCheckMinLimitRatio          float64
+	contextualTuplesValidationMode   string
 	maxConcurrentReadsForListObjects uint32
 	maxConcurrentReadsForCheck       uint32
 	maxConcurrentReadsForListUsers   uint32
 	maxAuthorizationModelCacheSize   int
 	maxTypesystemCacheSize           int
+	latestModelCacheTTL              time.Duration
+	latestModelCache                 storage.InMemoryCache[any]
+	typesystemCompilationCache       storage.InMemoryCache[any]
 	maxAuthorizationModelSizeInBytes int
 	authzenBaseURL                   string
 	experimentals                    []string
@@ -210,7 +355,8 @@ type Server struct {
 	// sharedDatastoreResources are created by the server
 	sharedDatastoreResources *shared.SharedDatastoreResources
 
-	shadowCheckResolverTimeout time.Duration
+	shadowCheckResolverTimeout       time.Duration
+	shadowCheckResolverSamplePercent int
 
 	shadowListObjectsQueryTimeout       time.Duration
 	shadowListObjectsQueryMaxDeltaItems int
@@ -256,10 +402,17 @@ type Server struct {
 	requestTimeout time.Duration
 
 	sharedResourceOptions []shared.SharedDatastoreResourcesOpt
+
+	datastoreWrappers []DatastoreWrapper
+
+	customCheckResolvers []graph.CustomCheckResolver
 }
 
 type OpenFGAServiceV1Option func(s *Server)
 
+// DatastoreWrapper wraps the datastore of the Server, e.g. to add custom metrics to its queries.
+type DatastoreWrapper func(storage.OpenFGADatastore) storage.OpenFGADatastore
+
 // WithDatastore passes a datastore to the Server.
 // You must call [storage.OpenFGADatastore.Close] on it after you have stopped using it.
 func WithDatastore(ds storage.OpenFGADatastore) OpenFGAServiceV1Option {
@@ -268,6 +421,22 @@ func WithDatastore(ds storage.OpenFGADatastore) OpenFGAServiceV1Option {
 	}
 }
 
+// WithDatastoreWrappers wraps the datastore of the Server with wrappers, in order, so that the last
+// wrapper is the outermost. The wrappers only see the queries that miss the caches of the Server.
+func WithDatastoreWrappers(wrappers ...DatastoreWrapper) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.datastoreWrappers = append(s.datastoreWrappers, wrappers...)
+	}
+}
+
+// WithCheckResolvers inserts custom resolvers of the Check sub-problems in the chain of resolvers
+// of the Check, BatchCheck and ListObjects queries, see the checkresolver package.
+func WithCheckResolvers(resolvers ...checkresolver.Custom) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.customCheckResolvers = append(s.customCheckResolvers, resolvers...)
+	}
+}
+
 func WithContinuationTokenSerializer(ds encoder.ContinuationTokenSerializer) OpenFGAServiceV1Option {
 	return func(s *Server) {
 		s.tokenSerializer = ds
@@ -288,6 +457,36 @@ func WithAuthorizationModelCacheSize(maxAuthorizationModelCacheSize int) OpenFGA
 	}
 }
 
+// WithLatestAuthorizationModelCacheTTL caches the ID of the latest authorization model of each
+// store for ttl, so that the requests that don't pin a model ID don't look it up in the datastore.
+// Writing a model invalidates the ID of its store. If 0, the latest model is looked up on every
+// request.
+func WithLatestAuthorizationModelCacheTTL(ttl time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.latestModelCacheTTL = ttl
+	}
+}
+
+// WithLatestAuthorizationModelCache holds the IDs cached by WithLatestAuthorizationModelCacheTTL
+// in cache instead of in memory, e.g. in a cache shared by the instances of OpenFGA so that a model
+// written through one of them is served by all of them at once. The server stops it when it shuts
+// down.
+func WithLatestAuthorizationModelCache(cache storage.InMemoryCache[any]) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.latestModelCache = cache
+	}
+}
+
+// WithTypesystemCompilationCache stores the artifacts of the validation and compilation of the
+// authorization models, such as their compiled conditions, in cache, e.g. a cache shared by the
+// instances of OpenFGA, so that the instances that start don't compile the large models again. The
+// server stops it when it shuts down.
+func WithTypesystemCompilationCache(cache storage.InMemoryCache[any]) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.typesystemCompilationCache = cache
+	}
+}
+
 // WithTypesystemCacheSize sets the maximum number of type system models that will be cached in memory.
 func WithTypesystemCacheSize(maxTypesystemCacheSize int) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -345,6 +544,536 @@ func WithChangelogHorizonOffset(offset int) OpenFGAServiceV1Option {
 	}
 }
 
+// WithChangelogRetentionEnabled enables the background job that periodically deletes the changes
+// of each store that fall outside of its retention policy.
+func WithChangelogRetentionEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changelogRetentionEnabled = enabled
+	}
+}
+
+// WithChangelogRetentionInterval sets the time between two runs of the changelog retention job.
+func WithChangelogRetentionInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changelogRetentionInterval = interval
+	}
+}
+
+// WithChangelogRetentionPolicy sets the retention policy of the stores without a policy of their own.
+// Changes older than maxAge, or beyond the maxEntries most recent changes of a store, are deleted.
+// A zero value disables a bound.
+func WithChangelogRetentionPolicy(maxAge time.Duration, maxEntries uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changelogRetentionMaxAge = maxAge
+		s.changelogRetentionMaxEntries = maxEntries
+	}
+}
+
+// WithChangelogRetentionStorePolicies overrides the changelog retention policy of specific stores.
+func WithChangelogRetentionStorePolicies(policies map[string]changelog.Policy) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changelogRetentionStorePolicies = policies
+	}
+}
+
+// WithChangelogRetentionCursorLease sets how long after it was handed out a ReadChanges continuation
+// token keeps the changes it has yet to read from being deleted by the changelog retention job.
+func WithChangelogRetentionCursorLease(lease time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changelogRetentionCursorLease = lease
+	}
+}
+
+// WithOrphanedTuplesCollectorEnabled enables the background job that periodically collects the
+// tuples of every store that reference types, relations or conditions that are not defined in the
+// latest model of the store.
+func WithOrphanedTuplesCollectorEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.orphanedTuplesCollectorEnabled = enabled
+	}
+}
+
+// WithOrphanedTuplesCollectorInterval sets the time between two runs of the orphaned tuples collector.
+func WithOrphanedTuplesCollectorInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.orphanedTuplesCollectorInterval = interval
+	}
+}
+
+// WithOrphanedTuplesCollectorDryRun makes the orphaned tuples collector only log the orphaned
+// tuples it finds, without deleting them.
+func WithOrphanedTuplesCollectorDryRun(dryRun bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.orphanedTuplesCollectorDryRun = dryRun
+	}
+}
+
+// WithStorePurgeEnabled makes DeleteStore create a background purge job that deletes the tuples of
+// the store in batches, whose progress is reported by GetJob. It requires the background jobs to
+// be enabled with WithJobsEnabled.
+func WithStorePurgeEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.storePurgeEnabled = enabled
+	}
+}
+
+// WithStoreEventsEnabled enables ReadStoreEvents, which reads the changelog of a store including
+// its authorization model writes, assertions writes, creation and deletion along with its tuple
+// changes. The assertions writes and the deletions of the stores are recorded by the server.
+func WithStoreEventsEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.storeEventsEnabled = enabled
+	}
+}
+
+// WithListObjectsSubscriptionPollInterval sets the time between two reads of the changelog of the
+// store of a ListObjects subscription.
+func WithListObjectsSubscriptionPollInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.subscriptionPollInterval = interval
+	}
+}
+
+// WithCacheHintsEnabled enables the Cache-Control and the invalidation keys headers of the Check
+// responses, with which the clients can cache them, and WatchCacheInvalidations, which streams
+// the invalidation keys of the writes to a store.
+func WithCacheHintsEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheHintsEnabled = enabled
+	}
+}
+
+// WithCacheHintsMaxAge sets the maximum max-age of the Check responses.
+func WithCacheHintsMaxAge(maxAge time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheHintsMaxAge = maxAge
+	}
+}
+
+// WithCacheInvalidationPollInterval sets the time between two reads of the changelog of the store
+// of a cache invalidation stream.
+func WithCacheInvalidationPollInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheInvalidationPollInterval = interval
+	}
+}
+
+// WithSessionTokensEnabled enables the read-your-writes session tokens returned by Write, with
+// which the reads of the same store observe the write. See SessionTokenHeader.
+func WithSessionTokensEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.sessionTokensEnabled = enabled
+	}
+}
+
+// WithSessionTokensCommitMargin sets how much the time of the most recent change observed by the
+// cache controller must exceed that of the change of a write for the write to be known to be
+// observed.
+func WithSessionTokensCommitMargin(margin time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.sessionTokensCommitMargin = margin
+	}
+}
+
+// WithMultiRegionID sets the region of an active-active multi-region deployment, between 1 and 255,
+// which must be unique across the regions writing to the same datastore. The tuples are then
+// written with last-writer-wins semantics, and the ULIDs of their changes encode the region. 0, the
+// default, means that the deployment is not multi-region.
+func WithMultiRegionID(regionID uint8) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.multiRegionID = regionID
+	}
+}
+
+// WithChangeListener sets the listener of the changes of the tuples committed to the datastore,
+// which invalidates the caches of the cache controllers as soon as they are committed, without
+// waiting for the cache controllers to find them in the changelog. It requires the cache controller
+// to be enabled, which remains the fallback when the listener fails.
+func WithChangeListener(listener storage.ChangeListener) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changeListener = listener
+	}
+}
+
+// WithAssertionCoverageEnabled enables GetAssertionCoverage, which reports which relations of a
+// model, and which branches of their rewrites, are exercised by the assertions of the model.
+func WithAssertionCoverageEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.assertionCoverageEnabled = enabled
+	}
+}
+
+// WithCostEstimateEnabled enables EstimateCheckCost and EstimateListObjectsCost, which estimate
+// the cost of a Check or a ListObjects without executing it.
+func WithCostEstimateEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.costEstimateEnabled = enabled
+	}
+}
+
+// WithWhatIfEnabled enables WhatIfCheck and WhatIfBatchCheck, which evaluate Checks as if a set of
+// tuple writes and deletes had been applied to the store.
+func WithWhatIfEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.whatIfEnabled = enabled
+	}
+}
+
+// WithModelSimulationEnabled enables SimulateModelChange, which reports the checks whose outcome
+// would change with a proposed authorization model.
+func WithModelSimulationEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.modelSimulationEnabled = enabled
+	}
+}
+
+// WithAccessReviewEnabled enables the background access review jobs, which export all the users that
+// have a relation on all the objects of a type to artifacts downloaded with DownloadAccessReview.
+// It requires the background jobs to be enabled with WithJobsEnabled.
+func WithAccessReviewEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.accessReviewEnabled = enabled
+	}
+}
+
+// WithAccessReviewArtifactDir sets the directory the artifacts of the access review jobs are written
+// to. It defaults to a directory in the temporary directory of the OS.
+func WithAccessReviewArtifactDir(dir string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.accessReviewArtifactDir = dir
+	}
+}
+
+// WithAccessReviewJobRetention sets the duration for which the finished access review jobs, and
+// their artifacts, are kept.
+//
+// Deprecated: The access review jobs, and their artifacts, are kept for the retention of the
+// background jobs, see WithJobsRetention. WithAccessReviewJobRetention has no effect.
+func WithAccessReviewJobRetention(_ time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {}
+}
+
+// WithJobsEnabled enables the background jobs, which run the long operations of the stores, such
+// as purges, and persist their status in the datastore.
+func WithJobsEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.jobsEnabled = enabled
+	}
+}
+
+// WithJobsWorkers sets the number of background jobs that the server runs concurrently.
+func WithJobsWorkers(workers int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.jobsWorkers = workers
+	}
+}
+
+// WithJobsQueueSize sets the number of background jobs that the server queues before refusing to
+// create more.
+func WithJobsQueueSize(size int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.jobsQueueSize = size
+	}
+}
+
+// WithJobsRetention sets the duration for which the finished background jobs, and the artifacts of
+// the access review jobs, are kept.
+func WithJobsRetention(retention time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.jobsRetention = retention
+	}
+}
+
+// WithJobsScheduleInterval sets the interval at which the replica that holds the lease of the
+// scheduler creates the jobs of the due schedules.
+func WithJobsScheduleInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.jobsScheduleInterval = interval
+	}
+}
+
+// WithCostEstimateSampleSize sets the maximum number of tuples of each type sampled by the cost
+// estimates for the cardinality of its relations.
+func WithCostEstimateSampleSize(size int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.costEstimateSampleSize = size
+	}
+}
+
+// WithWriteValidationHook submits every authorized Write to hook, which can veto it or annotate
+// it, before it is applied. The calls to hook are bounded by timeout. When a call fails, the Write
+// is applied if failOpen is true, or fails otherwise. The server closes hook when it closes. A nil
+// hook leaves the Writes unvalidated.
+func WithWriteValidationHook(hook writehook.Hook, timeout time.Duration, failOpen bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.writeValidationHook = hook
+		s.writeValidationHookTimeout = timeout
+		s.writeValidationHookFailOpen = failOpen
+	}
+}
+
+// WithContextEnrichmentHook fetches the condition context attributes of the user and the object
+// of the Check and ListObjects requests from hook, and merges them into the context of the
+// requests. The calls to hook are bounded by timeout, and the requests fail when a call fails.
+// The server closes hook when it closes. A nil hook leaves the contexts of the requests as is.
+func WithContextEnrichmentHook(hook contexthook.Hook, timeout time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.contextEnrichmentHook = hook
+		s.contextEnrichmentHookTimeout = timeout
+	}
+}
+
+// WithConditionContextHeaders injects the values of the HTTP headers, or gRPC metadata, of the
+// Check, BatchCheck, ListObjects, StreamedListObjects and ListUsers requests into their condition
+// context, so that the attributes set by e.g. an edge proxy don't need to be sent by the clients.
+// headers maps the lowercase names of the headers to the names of their parameters in the context,
+// see ParseConditionContextHeaders. The mapped parameters are removed from the context of the
+// requests, so that the clients can't set them by leaving out the headers.
+func WithConditionContextHeaders(headers map[string]string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.conditionContextHeaders = headers
+	}
+}
+
+// WithConditionContextClaims injects the validated claims of the principal authenticated with a
+// token, e.g. a JWT, into the condition context of the same requests as
+// WithConditionContextHeaders, so that conditions can e.g. compare the department of the caller
+// to the one of the object without the clients copying the claims into the requests. claims maps
+// the names of the allowed claims to the names of their parameters in the context, see
+// ParseConditionContextClaims. The values of the claims replace the ones of the headers, and the
+// mapped parameters are removed from the context of the requests, so that the clients can't set
+// the claims their token doesn't carry.
+func WithConditionContextClaims(claims map[string]string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.conditionContextClaims = claims
+	}
+}
+
+// WithStorePurgeJobRetention sets the duration for which the finished purge jobs are reported.
+//
+// Deprecated: The purge jobs are kept for the retention of the background jobs, see
+// WithJobsRetention. WithStorePurgeJobRetention has no effect.
+func WithStorePurgeJobRetention(_ time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {}
+}
+
+// WithConsistencyVerifierEnabled enables the background job that samples the tuples of every store
+// and reports the violations of the storage invariants.
+func WithConsistencyVerifierEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.consistencyVerifierEnabled = enabled
+	}
+}
+
+// WithConsistencyVerifierInterval sets the time between two runs of the consistency verifier.
+func WithConsistencyVerifierInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.consistencyVerifierInterval = interval
+	}
+}
+
+// WithConsistencyVerifierSampleSize sets the number of tuples, and the number of changes, verified
+// per store on each run of the consistency verifier.
+func WithConsistencyVerifierSampleSize(size int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.consistencyVerifierSampleSize = size
+	}
+}
+
+// WithHotPathsEnabled enables the tracking of the (object type, relation) pairs checked the most in
+// each store, and the background precomputation of their membership.
+func WithHotPathsEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.hotPathsEnabled = enabled
+	}
+}
+
+// WithHotPathsInterval sets the time between two precomputations of the hot paths.
+func WithHotPathsInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.hotPathsInterval = interval
+	}
+}
+
+// WithHotPathsTopN sets the number of pairs per store precomputed on each run.
+func WithHotPathsTopN(n int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.hotPathsTopN = n
+	}
+}
+
+// WithHotPathsMinChecks sets the number of Checks a pair must receive between two runs to be precomputed.
+func WithHotPathsMinChecks(minChecks int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.hotPathsMinChecks = minChecks
+	}
+}
+
+// WithHotPathsMaxObjects sets the maximum number of objects of a type whose membership is precomputed.
+func WithHotPathsMaxObjects(maxObjects int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.hotPathsMaxObjects = maxObjects
+	}
+}
+
+// WithClusterPeers sets the gRPC addresses of the replicas of the server, and the address of this
+// replica among them.
+func WithClusterPeers(selfAddress string, peers []string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterSelfAddress = selfAddress
+		s.clusterPeers = peers
+	}
+}
+
+// WithClusterSingleflightEnabled enables the forwarding of the Checks to the replica that owns
+// them, so that identical Checks received by different replicas are resolved once.
+func WithClusterSingleflightEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterSingleflightEnabled = enabled
+	}
+}
+
+// WithClusterForwardTimeout sets the maximum duration of a Check forwarded to another replica,
+// after which the Check is resolved locally.
+func WithClusterForwardTimeout(timeout time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterForwardTimeout = timeout
+	}
+}
+
+// WithClusterTLSEnabled makes the connections to the other replicas use TLS.
+func WithClusterTLSEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterTLSEnabled = enabled
+	}
+}
+
+// WithClusterDispatchEnabled enables the dispatch of the Check sub-problems to the replica that
+// owns them, so that the cache of every replica specializes in the sub-problems it owns.
+func WithClusterDispatchEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterDispatchEnabled = enabled
+	}
+}
+
+// WithClusterGossip makes the replicas discover each other by gossiping on bindAddress, in addition
+// to the peers. This replica joins the cluster through the replicas listening for gossip at the join
+// addresses.
+func WithClusterGossip(bindAddress string, join []string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterGossipBindAddress = bindAddress
+		s.clusterGossipJoin = join
+	}
+}
+
+// WithClusterDispatchTier sets the gRPC addresses of the replicas of a dedicated tier that resolves
+// the Checks of this replica through its dispatch service, so that the replicas serving the API do
+// not resolve the sub-problems of the Checks themselves.
+func WithClusterDispatchTier(addresses []string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.clusterDispatchTier = addresses
+	}
+}
+
+// WithCheckStoreBulkheads bounds the number of Checks of every store resolved at once to limit, or
+// to the limit in storeLimits for the stores it lists, so that a burst of Checks on a store cannot
+// take all the resources of the server. The other Checks of the store wait for their turn. A limit
+// of 0 lifts the bound.
+func WithCheckStoreBulkheads(limit int, storeLimits map[string]int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkStoreBulkheadLimit = limit
+		s.checkStoreBulkheadStoreLimits = storeLimits
+	}
+}
+
+// WithCheckCoalescingWindow merges the identical Checks received within window of each other into
+// one resolution, so that a burst of Checks on a hot object is resolved once. The first of them
+// waits window before it is resolved. A window of 0 disables it.
+func WithCheckCoalescingWindow(window time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCoalescingWindow = window
+	}
+}
+
+// WithWriteThrottling limits the write throughput of every store to rates, or to the rates in
+// storeTuplesPerSecond and storeChangesPerSecond for the stores they list, so that a bulk load on a
+// store cannot overwhelm the changelog and the replicas. The writes over the limits are rejected
+// with a RESOURCE_EXHAUSTED error that holds the time after which they can be retried. A rate of 0
+// means no limit.
+func WithWriteThrottling(rates throttler.WriteRates, storeTuplesPerSecond, storeChangesPerSecond map[string]float64) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		if limiter := throttler.NewStoreWriteLimiter(rates, storeTuplesPerSecond, storeChangesPerSecond); limiter.Enabled() {
+			s.writeLimiter = limiter
+		}
+	}
+}
+
+// WithWriteScheduling bounds the number of Writes sent to the datastore at once to maxInFlight.
+// When the bound is reached, i.e. when the datastore is write-saturated, the Writes are queued and
+// scheduled by weighted fair queueing between their stores, in proportion to storeWeights and to
+// the number of tuples they change, so that a bulk load on a store doesn't delay the small Writes
+// of the others. The stores without a weight have a weight of 1. A maxInFlight of 0 disables it.
+func WithWriteScheduling(maxInFlight int, storeWeights map[string]float64) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		if maxInFlight > 0 {
+			s.writeScheduler = priority.NewScheduler(maxInFlight, storeWeights, priority.WithQueuedGauge(writeSchedulerQueuedGauge))
+		}
+	}
+}
+
+// WithMaterializedPermissions maintains the (user, relation, object) permissions of relations in
+// the datastore, updated from the changelog of their store every interval, so that their Checks
+// are answered with a single row lookup. Only one replica updates the permissions at a time. The
+// Checks with HIGHER_CONSISTENCY or contextual tuples, and those made before the permissions
+// reflect the latest writes, are resolved from the tuples. No relations disables it.
+func WithMaterializedPermissions(relations []materialize.Relation, interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.materializedRelations = relations
+		s.materializedInterval = interval
+	}
+}
+
+// WithMaterializedObjectListsEnabled enables the materialization of the ListObjects results of the
+// (user, relation, object type) triples requested the most in each store, kept up to date from the
+// changelog of their store, so that their ListObjects requests are answered with a single lookup.
+// The requests with HIGHER_CONSISTENCY, contextual tuples, a context or an object ID prefix, and
+// those made before the objects reflect the latest writes, are resolved from the tuples.
+func WithMaterializedObjectListsEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.objectListsEnabled = enabled
+	}
+}
+
+// WithMaterializedObjectListsInterval sets the time between two updates of the materialized object lists.
+func WithMaterializedObjectListsInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.objectListsInterval = interval
+	}
+}
+
+// WithMaterializedObjectListsTopN sets the number of triples per store materialized on each update.
+func WithMaterializedObjectListsTopN(n int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.objectListsTopN = n
+	}
+}
+
+// WithMaterializedObjectListsMinRequests sets the number of ListObjects requests a triple must
+// receive between two updates to be materialized.
+func WithMaterializedObjectListsMinRequests(minRequests int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.objectListsMinRequests = minRequests
+	}
+}
+
+// WithServerTimingEnabled enables the Server-Timing header on the Check and BatchCheck responses,
+// so that the clients can attribute the latency of their requests without access to the traces.
+func WithServerTimingEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.serverTimingEnabled = enabled
+	}
+}
+
 // WithReadChangesMaxPageSize sets the maximum page size for ReadChanges API requests.
 func WithReadChangesMaxPageSize(max uint32) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -372,6 +1101,23 @@ func WithListObjectsMaxResults(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithStreamedListObjectsMaxInFlightMessages affects the Streamed ListObjects API only.
+// It sets the maximum number of resolved objects that can be buffered before they are sent to the
+// client. Once the buffer is full, resolution waits for the client to receive more objects.
+func WithStreamedListObjectsMaxInFlightMessages(limit uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.streamedListObjectsMaxInFlight = limit
+	}
+}
+
+// WithStreamedListObjectsMessagesPerSecond affects the Streamed ListObjects API only.
+// It sets the maximum rate at which objects are sent on each stream. If it's zero, the rate is not limited.
+func WithStreamedListObjectsMessagesPerSecond(limit uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.streamedListObjectsRateLimit = limit
+	}
+}
+
 // WithListObjectsPipelineEnabled affects the ListObjects API and Streamed ListObjects API only.
 // It sets whether the ListObjects pipeline optimization algorithm is enabled.
 func WithListObjectsPipelineEnabled(enabled bool) OpenFGAServiceV1Option {
@@ -487,6 +1233,31 @@ func WithCheckCacheLimit(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithCheckCacheMaxBytes limits the check cache by the memory held by its entries instead of by
+// their number. If 0, the check cache is limited by WithCheckCacheLimit.
+func WithCheckCacheMaxBytes(maxBytes uint64) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheMaxBytes = maxBytes
+	}
+}
+
+// WithCheckCacheShards sets the number of shards of the check cache, each with its own lock and
+// an equal part of its limit, so that concurrent Checks don't contend on a single lock. If 0, the
+// check cache has one shard per CPU.
+func WithCheckCacheShards(shards uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheShards = shards
+	}
+}
+
+// WithCheckCacheDiskPath sets the file the cached Check results are persisted to, so that they
+// survive a restart. If empty, the check cache is only kept in memory.
+func WithCheckCacheDiskPath(path string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheDiskPath = path
+	}
+}
+
 // WithCacheControllerEnabled enables cache invalidation of different cache entities.
 func WithCacheControllerEnabled(enabled bool) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -555,6 +1326,31 @@ func WithListObjectsIteratorCacheTTL(ttl time.Duration) OpenFGAServiceV1Option {
 	}
 }
 
+// WithListObjectsQueryCacheEnabled enables caching of the ListObjects responses. A cached response
+// is returned until the cache controller finds a write to its store, if it is enabled, or until
+// its TTL expires.
+func WithListObjectsQueryCacheEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.ListObjectsQueryCacheEnabled = enabled
+	}
+}
+
+// WithListObjectsQueryCacheMaxResults sets the maximum number of objects of a cached ListObjects
+// response. Needs WithListObjectsQueryCacheEnabled set to true.
+func WithListObjectsQueryCacheMaxResults(limit uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.ListObjectsQueryCacheMaxResults = limit
+	}
+}
+
+// WithListObjectsQueryCacheTTL sets the TTL of the cached ListObjects responses.
+// Needs WithListObjectsQueryCacheEnabled set to true.
+func WithListObjectsQueryCacheTTL(ttl time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.ListObjectsQueryCacheTTL = ttl
+	}
+}
+
 // WithCacheTTLJitterPercentage sets the jitter percentage applied to cache TTLs.
 // A value of 10 means up to 10% of the base TTL is added as random jitter to each
 // cache entry, spreading out expirations and preventing thundering herd effects.
@@ -761,6 +1557,19 @@ func WithMaxChecksPerBatchCheck(maxChecks uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithBatchCheckAdaptiveLimits reduces the maximum number of checks of a BatchCheck and the number
+// of checks it evaluates concurrently when the load reported by signal exceeds loadThreshold, down
+// to minRatio of the limits when the load reaches 1. The BatchChecks with more checks than the
+// reduced limit fail with a [serverErrors.ReduceBatchSize] error, so that clients can split their
+// batches. A nil signal leaves the limits fixed.
+func WithBatchCheckAdaptiveLimits(signal BatchCheckLoadSignal, loadThreshold, minRatio float64) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.batchCheckLoadSignal = signal
+		s.batchCheckLoadThreshold = loadThreshold
+		s.batchCheckMinLimitRatio = minRatio
+	}
+}
+
 func WithCheckDatabaseThrottle(threshold int, duration time.Duration) OpenFGAServiceV1Option {
 	return func(s *Server) {
 		s.checkDatastoreThrottleThreshold = threshold
@@ -789,6 +1598,15 @@ func WithShadowCheckResolverTimeout(threshold time.Duration) OpenFGAServiceV1Opt
 	}
 }
 
+// WithShadowCheckResolverSamplePercentage sets the percentage of the Checks that are also evaluated
+// by the shadow Check resolvers enabled through the `shadow_check` and `shadow_weighted_graph_check`
+// experimental flags.
+func WithShadowCheckResolverSamplePercentage(percentage int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.shadowCheckResolverSamplePercent = percentage
+	}
+}
+
 // WithShadowListObjectsQueryTimeout is the amount of time to wait for the shadow ListObjects evaluation response.
 func WithShadowListObjectsQueryTimeout(threshold time.Duration) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -868,6 +1686,16 @@ func WithCheckCache(c storage.InMemoryCache[any]) OpenFGAServiceV1Option {
 	}
 }
 
+// WithContextualTuplesValidationMode affects the Check, BatchCheck, Expand, ListObjects and ListUsers APIs.
+// It sets how contextual tuples that reference types not defined in the model are handled: in
+// serverconfig.ContextualTuplesValidationModeStrict mode (the default) the request is rejected, and in
+// serverconfig.ContextualTuplesValidationModeLenient mode those contextual tuples are ignored.
+func WithContextualTuplesValidationMode(mode string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.contextualTuplesValidationMode = mode
+	}
+}
+
 // NewServerWithOpts returns a new server.
 // You must call Close on it after you are done using it.
 func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
@@ -877,17 +1705,59 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		encoder:                          encoder.NewBase64Encoder(),
 		transport:                        gateway.NewNoopTransport(),
 		changelogHorizonOffset:           serverconfig.DefaultChangelogHorizonOffset,
+		changelogRetentionEnabled:        serverconfig.DefaultChangelogRetentionEnabled,
+		changelogRetentionInterval:       serverconfig.DefaultChangelogRetentionInterval,
+		changelogRetentionMaxAge:         serverconfig.DefaultChangelogRetentionMaxAge,
+		changelogRetentionMaxEntries:     serverconfig.DefaultChangelogRetentionMaxEntries,
+		changelogRetentionCursorLease:    serverconfig.DefaultChangelogRetentionCursorLease,
+		orphanedTuplesCollectorEnabled:   serverconfig.DefaultOrphanedTuplesCollectorEnabled,
+		orphanedTuplesCollectorInterval:  serverconfig.DefaultOrphanedTuplesCollectorInterval,
+		orphanedTuplesCollectorDryRun:    serverconfig.DefaultOrphanedTuplesCollectorDryRun,
+		storePurgeEnabled:                serverconfig.DefaultStorePurgeEnabled,
+		storeEventsEnabled:               serverconfig.DefaultStoreEventsEnabled,
+		assertionCoverageEnabled:         serverconfig.DefaultAssertionCoverageEnabled,
+		costEstimateEnabled:              serverconfig.DefaultCostEstimateEnabled,
+		costEstimateSampleSize:           serverconfig.DefaultCostEstimateSampleSize,
+		whatIfEnabled:                    serverconfig.DefaultWhatIfEnabled,
+		modelSimulationEnabled:           serverconfig.DefaultModelSimulationEnabled,
+		accessReviewEnabled:              serverconfig.DefaultAccessReviewEnabled,
+		jobsEnabled:                      serverconfig.DefaultJobsEnabled,
+		jobsWorkers:                      serverconfig.DefaultJobsWorkers,
+		jobsQueueSize:                    serverconfig.DefaultJobsQueueSize,
+		jobsRetention:                    serverconfig.DefaultJobsRetention,
+		jobsScheduleInterval:             serverconfig.DefaultJobsScheduleInterval,
+		consistencyVerifierEnabled:       serverconfig.DefaultConsistencyVerifierEnabled,
+		consistencyVerifierInterval:      serverconfig.DefaultConsistencyVerifierInterval,
+		consistencyVerifierSampleSize:    serverconfig.DefaultConsistencyVerifierSampleSize,
+		hotPathsEnabled:                  serverconfig.DefaultHotPathsEnabled,
+		hotPathsInterval:                 serverconfig.DefaultHotPathsInterval,
+		hotPathsTopN:                     serverconfig.DefaultHotPathsTopN,
+		hotPathsMinChecks:                serverconfig.DefaultHotPathsMinChecks,
+		hotPathsMaxObjects:               serverconfig.DefaultHotPathsMaxObjects,
+		objectListsEnabled:               serverconfig.DefaultMaterializedObjectListsEnabled,
+		objectListsInterval:              serverconfig.DefaultMaterializedObjectListsInterval,
+		objectListsTopN:                  serverconfig.DefaultMaterializedObjectListsTopN,
+		objectListsMinRequests:           serverconfig.DefaultMaterializedObjectListsMinRequests,
+		clusterForwardTimeout:            serverconfig.DefaultClusterForwardTimeout,
 		readChangesMaxPageSize:           serverconfig.DefaultReadChangesMaxPageSize,
 		resolveNodeLimit:                 serverconfig.DefaultResolveNodeLimit,
 		resolveNodeBreadthLimit:          serverconfig.DefaultResolveNodeBreadthLimit,
 		listObjectsDeadline:              serverconfig.DefaultListObjectsDeadline,
 		listObjectsMaxResults:            serverconfig.DefaultListObjectsMaxResults,
+		subscriptionPollInterval:         serverconfig.DefaultListObjectsSubscriptionPollInterval,
+		cacheHintsEnabled:                serverconfig.DefaultCacheHintsEnabled,
+		cacheHintsMaxAge:                 serverconfig.DefaultCacheHintsMaxAge,
+		cacheInvalidationPollInterval:    serverconfig.DefaultCacheHintsInvalidationPollInterval,
+		sessionTokensEnabled:             serverconfig.DefaultSessionTokensEnabled,
+		sessionTokensCommitMargin:        serverconfig.DefaultSessionTokensCommitMargin,
 		listObjectsPipelineEnabled:       serverconfig.DefaultListObjectsPipelineEnabled,
 		listObjectsPipelineConfig:        pipeline.DefaultConfig(),
+		streamedListObjectsMaxInFlight:   serverconfig.DefaultStreamedListObjectsMaxInFlightMessages,
 		listUsersDeadline:                serverconfig.DefaultListUsersDeadline,
 		listUsersMaxResults:              serverconfig.DefaultListUsersMaxResults,
 		maxChecksPerBatchCheck:           serverconfig.DefaultMaxChecksPerBatchCheck,
 		maxConcurrentChecksPerBatch:      serverconfig.DefaultMaxConcurrentChecksPerBatchCheck,
+		contextualTuplesValidationMode:   serverconfig.DefaultContextualTuplesValidationMode,
 		maxConcurrentReadsForCheck:       serverconfig.DefaultMaxConcurrentReadsForCheck,
 		maxConcurrentReadsForListObjects: serverconfig.DefaultMaxConcurrentReadsForListObjects,
 		maxConcurrentReadsForListUsers:   serverconfig.DefaultMaxConcurrentReadsForListUsers,
@@ -899,7 +1769,8 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 
 		cacheSettings: serverconfig.NewDefaultCacheSettings(),
 
-		shadowCheckResolverTimeout: serverconfig.DefaultShadowCheckResolverTimeout,
+		shadowCheckResolverTimeout:       serverconfig.DefaultShadowCheckResolverTimeout,
+		shadowCheckResolverSamplePercent: serverconfig.DefaultShadowCheckResolverSamplePercentage,
 
 		shadowListObjectsQueryTimeout:       serverconfig.DefaultShadowListObjectsQueryTimeout,
 		shadowListObjectsQueryMaxDeltaItems: serverconfig.DefaultShadowListObjectsQueryMaxDeltaItems,
@@ -974,6 +1845,125 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		return nil, fmt.Errorf("ListUsers default dispatch throttling threshold must be equal or smaller than max dispatch threshold for ListUsers")
 	}
 
+	if s.contextualTuplesValidationMode != serverconfig.ContextualTuplesValidationModeStrict &&
+		s.contextualTuplesValidationMode != serverconfig.ContextualTuplesValidationModeLenient {
+		return nil, fmt.Errorf("invalid contextual tuples validation mode '%s'", s.contextualTuplesValidationMode)
+	}
+
+	if s.changelogRetentionEnabled && s.changelogRetentionInterval <= 0 {
+		return nil, fmt.Errorf("changelog retention interval must be greater than 0")
+	}
+
+	if s.orphanedTuplesCollectorEnabled && s.orphanedTuplesCollectorInterval <= 0 {
+		return nil, fmt.Errorf("orphaned tuples collector interval must be greater than 0")
+	}
+
+	if s.storePurgeEnabled && !s.jobsEnabled {
+		return nil, fmt.Errorf("store purge requires the background jobs to be enabled")
+	}
+
+	if s.accessReviewEnabled && !s.jobsEnabled {
+		return nil, fmt.Errorf("access review requires the background jobs to be enabled")
+	}
+
+	if s.writeValidationHook != nil && s.writeValidationHookTimeout <= 0 {
+		return nil, fmt.Errorf("write validation hook timeout must be greater than 0")
+	}
+
+	if s.contextEnrichmentHook != nil && s.contextEnrichmentHookTimeout <= 0 {
+		return nil, fmt.Errorf("context enrichment hook timeout must be greater than 0")
+	}
+
+	if s.shadowCheckResolverSamplePercent < 0 || s.shadowCheckResolverSamplePercent > 100 {
+		return nil, fmt.Errorf("shadow check resolver sample percentage must be between 0 and 100")
+	}
+
+	if s.consistencyVerifierEnabled && (s.consistencyVerifierInterval <= 0 || s.consistencyVerifierSampleSize <= 0) {
+		return nil, fmt.Errorf("consistency verifier interval and sample size must be greater than 0")
+	}
+
+	if s.hotPathsEnabled && (s.hotPathsInterval <= 0 || s.hotPathsTopN <= 0 || s.hotPathsMaxObjects <= 0) {
+		return nil, fmt.Errorf("hot paths interval, top n and max objects must be greater than 0")
+	}
+
+	if s.subscriptionPollInterval <= 0 {
+		return nil, fmt.Errorf("ListObjects subscription poll interval must be greater than 0")
+	}
+
+	if s.cacheHintsEnabled {
+		if s.cacheHintsMaxAge < 0 || s.cacheInvalidationPollInterval <= 0 {
+			return nil, fmt.Errorf("cache hints max age must be non-negative and invalidation poll interval must be greater than 0")
+		}
+		s.cacheHints = cachehints.NewTracker(s.cacheHintsMaxAge)
+	}
+
+	if s.sessionTokensEnabled && s.sessionTokensCommitMargin < 0 {
+		return nil, fmt.Errorf("session tokens commit margin must be non-negative")
+	}
+
+	if s.objectListsEnabled && (s.objectListsInterval <= 0 || s.objectListsTopN <= 0) {
+		return nil, fmt.Errorf("materialized object lists interval and top n must be greater than 0")
+	}
+
+	if s.changeListener != nil && !s.cacheSettings.ShouldCreateCacheController() {
+		return nil, fmt.Errorf("a change listener requires the cache controller to be enabled")
+	}
+
+	if s.checkStoreBulkheadLimit < 0 {
+		return nil, fmt.Errorf("check store bulkhead limit must be non-negative")
+	}
+	if s.checkStoreBulkheadLimit > 0 || len(s.checkStoreBulkheadStoreLimits) > 0 {
+		s.checkStoreBulkheads = graph.NewStoreBulkheads(s.checkStoreBulkheadLimit, s.checkStoreBulkheadStoreLimits)
+	}
+
+	if s.checkCoalescingWindow < 0 {
+		return nil, fmt.Errorf("check coalescing window must be non-negative")
+	}
+	if s.checkCoalescingWindow > 0 {
+		s.checkCoalescer = graph.NewCheckCoalescer(s.checkCoalescingWindow)
+	}
+
+	if len(s.materializedRelations) > 0 && s.materializedInterval <= 0 {
+		return nil, fmt.Errorf("materialized permissions interval must be greater than 0")
+	}
+
+	if s.clusterSingleflightEnabled || s.clusterDispatchEnabled {
+		if s.clusterSelfAddress == "" || s.clusterForwardTimeout <= 0 {
+			return nil, fmt.Errorf("cluster singleflight and dispatch require the address of this replica and a forward timeout greater than 0")
+		}
+
+		clusterOpts := []cluster.Option{
+			cluster.WithForwardTimeout(s.clusterForwardTimeout),
+			cluster.WithTLS(s.clusterTLSEnabled),
+			cluster.WithLogger(s.logger),
+		}
+		if s.clusterGossipBindAddress != "" {
+			clusterOpts = append(clusterOpts, cluster.WithGossip(s.clusterGossipBindAddress, s.clusterGossipJoin))
+		}
+
+		var err error
+		s.cluster, err = cluster.New(s.clusterSelfAddress, s.clusterPeers, clusterOpts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.clusterDispatchTier) > 0 {
+		if s.clusterForwardTimeout <= 0 {
+			return nil, fmt.Errorf("cluster dispatch tier requires a forward timeout greater than 0")
+		}
+
+		var err error
+		s.dispatchTier, err = cluster.NewTier(s.clusterDispatchTier,
+			cluster.WithForwardTimeout(s.clusterForwardTimeout),
+			cluster.WithTLS(s.clusterTLSEnabled),
+			cluster.WithLogger(s.logger),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if s.featureFlagClient == nil {
 		s.featureFlagClient = featureflags.NewDefaultClient(s.experimentals)
 	}
@@ -985,24 +1975,50 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 
 	// below this point, don't throw errors or we may leak resources in tests
 
+	for _, wrap := range s.datastoreWrappers {
+		s.datastore = wrap(s.datastore)
+	}
+
 	if !s.contextPropagationToDatastore {
 		// Creates a new [storagewrappers.ContextTracerWrapper] that will execute datastore queries using
 		// a new background context with the current trace context.
 		s.datastore = storagewrappers.NewContextWrapper(s.datastore)
 	}
 
-	s.datastore, err = storagewrappers.NewCachedOpenFGADatastore(s.datastore, s.maxAuthorizationModelCacheSize)
+	s.datastore, err = storagewrappers.NewCachedOpenFGADatastore(s.datastore, s.maxAuthorizationModelCacheSize,
+		storagewrappers.WithLatestModelCache(s.latestModelCacheTTL, s.latestModelCache))
 	if err != nil {
 		return nil, err
 	}
 
-	s.sharedResourceOptions = append(s.sharedResourceOptions, shared.WithLogger(s.logger))
+	s.sharedResourceOptions = append(s.sharedResourceOptions,
+		shared.WithLogger(s.logger),
+		shared.WithDiskCacheOpts(storage.WithCacheItemDecoder(graph.CheckResponseCacheEntityType, graph.DecodeCheckResponseCacheEntry)),
+	)
+	if s.sessionTokensEnabled {
+		s.sharedResourceOptions = append(s.sharedResourceOptions, shared.WithCacheControllerOpts(cachecontroller.WithChangeObservation()))
+	}
 
 	s.sharedDatastoreResources, err = shared.NewSharedDatastoreResources(s.ctx, s.singleflightGroup, s.datastore, s.cacheSettings, s.sharedResourceOptions...)
 	if err != nil {
 		return nil, err
 	}
 
+	if s.changeListener != nil {
+		var invalidators []cachecontroller.ChangeInvalidator
+		for _, controller := range []cachecontroller.CacheController{
+			s.sharedDatastoreResources.CacheController,
+			s.sharedDatastoreResources.ShadowCacheController,
+		} {
+			if invalidator, ok := controller.(cachecontroller.ChangeInvalidator); ok && !slices.Contains(invalidators, invalidator) {
+				invalidators = append(invalidators, invalidator)
+			}
+		}
+		s.changeSubscriber = cachecontroller.NewSubscriber(s.changeListener, invalidators,
+			cachecontroller.WithSubscriberLogger(s.logger))
+		s.changeSubscriber.Start()
+	}
+
 	if s.listObjectsDispatchThrottlingEnabled {
 		s.listObjectsDispatchThrottler = throttler.NewConstantRateThrottler(s.listObjectsDispatchThrottlingFrequency, "list_objects_dispatch_throttle")
 	}
@@ -1011,7 +2027,11 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		s.listUsersDispatchThrottler = throttler.NewConstantRateThrottler(s.listUsersDispatchThrottlingFrequency, "list_users_dispatch_throttle")
 	}
 
-	s.typesystemResolver, s.typesystemResolverStop, err = typesystem.MemoizedTypesystemResolverFunc(s.datastore, s.maxTypesystemCacheSize)
+	var typesystemResolverOpts []typesystem.MemoizedTypesystemResolverOpt
+	if s.typesystemCompilationCache != nil {
+		typesystemResolverOpts = append(typesystemResolverOpts, typesystem.WithCompilationCache(s.typesystemCompilationCache))
+	}
+	s.typesystemResolver, s.typesystemResolverStop, err = typesystem.MemoizedTypesystemResolverFunc(s.datastore, s.maxTypesystemCacheSize, typesystemResolverOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1020,6 +2040,114 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 	s.authzModelGraphResolver = modelgraph.NewResolver(s.datastore, s.sharedDatastoreResources.CheckCache, 24*7*time.Hour)
 	s.shadowAuthzModelGraphResolver = modelgraph.NewResolver(s.datastore, s.sharedDatastoreResources.ShadowCheckCache, 24*7*time.Hour)
 
+	if ds, ok := s.datastore.(changelog.Datastore); ok && s.changelogRetentionEnabled {
+		s.changelogCursors = changelog.NewCursorTracker(s.changelogRetentionCursorLease)
+		s.changelogRetainer = changelog.NewRetainer(ds,
+			changelog.WithDefaultPolicy(changelog.Policy{
+				MaxAge:     s.changelogRetentionMaxAge,
+				MaxEntries: s.changelogRetentionMaxEntries,
+			}),
+			changelog.WithStorePolicies(s.changelogRetentionStorePolicies),
+			changelog.WithCursorTracker(s.changelogCursors),
+			changelog.WithInterval(s.changelogRetentionInterval),
+			changelog.WithLogger(s.logger),
+		)
+		s.changelogRetainer.Start()
+	}
+
+	if s.orphanedTuplesCollectorEnabled {
+		s.orphanedTuplesCollector = orphans.NewCollector(orphans.NewDatastoreStore(s.datastore), s.datastore,
+			orphans.WithInterval(s.orphanedTuplesCollectorInterval),
+			orphans.WithDryRun(s.orphanedTuplesCollectorDryRun),
+			orphans.WithLogger(s.logger),
+		)
+		s.orphanedTuplesCollector.Start()
+	}
+
+	if s.accessReviewEnabled {
+		artifactDir := s.accessReviewArtifactDir
+		if artifactDir == "" {
+			artifactDir = filepath.Join(os.TempDir(), "openfga-access-reviews")
+		}
+		exporter, err := accessreview.NewExporter(s.datastore, artifactDir,
+			accessreview.WithArtifactRetention(s.jobsRetention),
+			accessreview.WithLogger(s.logger),
+		)
+		if err != nil {
+			return nil, err
+		}
+		s.accessReviewExporter = exporter
+	}
+
+	if s.jobsEnabled {
+		backend, ok := s.datastore.(storage.JobBackend)
+		if !ok {
+			return nil, errors.New("the datastore does not support background jobs")
+		}
+		opts := []jobs.Option{
+			jobs.WithWorkers(s.jobsWorkers),
+			jobs.WithQueueSize(s.jobsQueueSize),
+			jobs.WithJobRetention(s.jobsRetention),
+			jobs.WithLogger(s.logger),
+		}
+		// the schedules are optional, for the datastores that don't support them
+		if schedules, ok := s.datastore.(storage.ScheduleBackend); ok {
+			opts = append(opts, jobs.WithSchedules(schedules), jobs.WithScheduleInterval(s.jobsScheduleInterval))
+		}
+		s.jobsManager = jobs.NewManager(backend, opts...)
+		s.registerJobKinds()
+	}
+
+	if s.storeEventsEnabled {
+		s.storeEventsRecorder = storeevents.NewRecorder()
+	}
+
+	if s.consistencyVerifierEnabled {
+		s.consistencyVerifier = verifier.NewVerifier(s.datastore,
+			verifier.WithInterval(s.consistencyVerifierInterval),
+			verifier.WithSampleSize(s.consistencyVerifierSampleSize),
+			verifier.WithCheckCache(s.sharedDatastoreResources.CheckCache),
+			verifier.WithLogger(s.logger),
+		)
+		s.consistencyVerifier.Start()
+	}
+
+	if s.hotPathsEnabled {
+		s.hotPathIndex = hotpath.NewIndex(hotpath.WithTTL(2 * s.hotPathsInterval))
+		s.hotPathPrecomputer = hotpath.NewPrecomputer(s.hotPathIndex, s.datastore, s.typesystemResolver,
+			hotpath.WithInterval(s.hotPathsInterval),
+			hotpath.WithTopN(s.hotPathsTopN),
+			hotpath.WithMinChecks(uint64(max(s.hotPathsMinChecks, 0))),
+			hotpath.WithMaxObjects(s.hotPathsMaxObjects),
+			hotpath.WithLogger(s.logger),
+		)
+		s.hotPathPrecomputer.Start()
+	}
+
+	if len(s.materializedRelations) > 0 {
+		backend, ok := s.datastore.(storage.MaterializedPermissionBackend)
+		if !ok {
+			return nil, fmt.Errorf("the datastore does not support materialized permissions")
+		}
+		s.materializer = materialize.NewMaterializer(s.datastore, backend, s.typesystemResolver, s.materializedRelations,
+			materialize.WithInterval(s.materializedInterval),
+			materialize.WithHorizonOffset(time.Duration(s.changelogHorizonOffset)*time.Minute),
+			materialize.WithLogger(s.logger),
+		)
+		s.materializer.Start()
+	}
+
+	if s.objectListsEnabled {
+		s.objectListsCache = objectlists.NewCache(s.datastore, s.typesystemResolver, s.materializeObjectList,
+			objectlists.WithInterval(s.objectListsInterval),
+			objectlists.WithTopN(s.objectListsTopN),
+			objectlists.WithMinRequests(uint64(max(s.objectListsMinRequests, 0))),
+			objectlists.WithHorizonOffset(time.Duration(s.changelogHorizonOffset)*time.Minute),
+			objectlists.WithLogger(s.logger),
+		)
+		s.objectListsCache.Start()
+	}
+
 	if s.IsAccessControlEnabled() {
 		s.authorizer = authz.NewAuthorizer(&authz.Config{StoreID: s.AccessControl.StoreID, ModelID: s.AccessControl.ModelID}, s, s.logger)
 	}
@@ -1032,7 +2160,46 @@ func (s *Server) Close() {
 	if s.planner != nil {
 		s.planner.Stop()
 	}
+	if s.changeSubscriber != nil {
+		s.changeSubscriber.Stop()
+	}
+	if s.changelogRetainer != nil {
+		s.changelogRetainer.Stop()
+	}
+	if s.orphanedTuplesCollector != nil {
+		s.orphanedTuplesCollector.Stop()
+	}
+	if s.jobsManager != nil {
+		s.jobsManager.Stop()
+	}
+	if s.writeValidationHook != nil {
+		_ = s.writeValidationHook.Close()
+	}
+	if s.contextEnrichmentHook != nil {
+		_ = s.contextEnrichmentHook.Close()
+	}
+	if s.consistencyVerifier != nil {
+		s.consistencyVerifier.Stop()
+	}
+	if s.hotPathPrecomputer != nil {
+		s.hotPathPrecomputer.Stop()
+	}
+	if s.materializer != nil {
+		s.materializer.Stop()
+	}
+	if s.objectListsCache != nil {
+		s.objectListsCache.Stop()
+	}
+	if s.cluster != nil {
+		s.cluster.Close()
+	}
+	if s.dispatchTier != nil {
+		s.dispatchTier.Close()
+	}
 	s.typesystemResolverStop()
+	if s.typesystemCompilationCache != nil {
+		s.typesystemCompilationCache.Stop()
+	}
 
 	if s.listObjectsDispatchThrottler != nil {
 		s.listObjectsDispatchThrottler.Close()
@@ -1065,6 +2232,30 @@ func (s *Server) IsReady(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
+// dropUndefinedContextualTuples removes, in lenient contextual tuples validation mode, the
+// contextual tuples that reference types not defined in the model. In strict mode the tuples
+// are left untouched and the commands reject the request if they are not valid.
+func (s *Server) dropUndefinedContextualTuples(ctx context.Context, typesys *typesystem.TypeSystem, tks []*openfgav1.TupleKey) []*openfgav1.TupleKey {
+	if s.contextualTuplesValidationMode != serverconfig.ContextualTuplesValidationModeLenient || len(tks) == 0 {
+		return tks
+	}
+
+	kept, dropped := validation.DropTuplesWithUndefinedTypes(typesys, tks)
+	if dropped > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("contextual_tuples_dropped", dropped))
+	}
+	return kept
+}
+
+// dropUndefinedContextualTupleKeys is like dropUndefinedContextualTuples, but updates the
+// contextual tuples of a request in place.
+func (s *Server) dropUndefinedContextualTupleKeys(ctx context.Context, typesys *typesystem.TypeSystem, contextualTuples *openfgav1.ContextualTupleKeys) {
+	if contextualTuples == nil {
+		return
+	}
+	contextualTuples.TupleKeys = s.dropUndefinedContextualTuples(ctx, typesys, contextualTuples.GetTupleKeys())
+}
+
 // resolveTypesystem resolves the underlying TypeSystem given the storeID and modelID and
 // it sets some response metadata based on the model resolution.
 func (s *Server) resolveTypesystem(ctx context.Context, storeID, modelID string) (*typesystem.TypeSystem, error) {
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/openfga/openfga/internal/cachecontroller"
 	"github.com/openfga/openfga/internal/graph"
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/checkresolver"
 	"github.com/openfga/openfga/pkg/featureflags"
 	"github.com/openfga/openfga/pkg/logger"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
@@ -669,6 +671,129 @@ func TestRequestContextPropagation(t *testing.T) {
 	}
 }
 
+type countingDatastore struct {
+	storage.OpenFGADatastore
+	name               string
+	readModelCount     *atomic.Int32
+	readModelWrapperOf *[]string
+}
+
+func (c *countingDatastore) ReadAuthorizationModel(ctx context.Context, storeID string, modelID string) (*openfgav1.AuthorizationModel, error) {
+	c.readModelCount.Add(1)
+	*c.readModelWrapperOf = append(*c.readModelWrapperOf, c.name)
+	return c.OpenFGADatastore.ReadAuthorizationModel(ctx, storeID, modelID)
+}
+
+func TestServerWithDatastoreWrappers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	var readModelCount atomic.Int32
+	var calls []string
+	wrapper := func(name string) DatastoreWrapper {
+		return func(ds storage.OpenFGADatastore) storage.OpenFGADatastore {
+			return &countingDatastore{OpenFGADatastore: ds, name: name, readModelCount: &readModelCount, readModelWrapperOf: &calls}
+		}
+	}
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithDatastoreWrappers(wrapper("inner"), wrapper("outer")),
+	)
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "wrappers"})
+	require.NoError(t, err)
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user`)
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = s.ReadAuthorizationModel(ctx, &openfgav1.ReadAuthorizationModelRequest{
+			StoreId: store.GetId(),
+			Id:      writeModelResp.GetAuthorizationModelId(),
+		})
+		require.NoError(t, err)
+	}
+
+	// the second read is served by the authorization model cache, which wraps the wrappers
+	require.Equal(t, int32(2), readModelCount.Load())
+	require.Equal(t, []string{"outer", "inner"}, calls)
+}
+
+func TestServerWithCheckResolvers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	var resolved atomic.Int32
+	allowlist := checkresolver.Middleware(func(ctx context.Context, req *checkresolver.Request, next checkresolver.CheckResolver) (*checkresolver.Response, error) {
+		if req.GetTupleKey().GetUser() == "user:admin" {
+			return &checkresolver.Response{Allowed: true}, nil
+		}
+		return next.ResolveCheck(ctx, req)
+	})
+	counter := checkresolver.Middleware(func(ctx context.Context, req *checkresolver.Request, next checkresolver.CheckResolver) (*checkresolver.Response, error) {
+		resolved.Add(1)
+		return next.ResolveCheck(ctx, req)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithCheckResolvers(
+			checkresolver.Custom{Position: checkresolver.PositionFirst, New: allowlist},
+			checkresolver.Custom{Position: checkresolver.PositionLast, New: counter},
+		),
+	)
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "resolvers"})
+	require.NoError(t, err)
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	check := func(user string) bool {
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:              store.GetId(),
+			AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+			TupleKey:             tuple.NewCheckRequestTupleKey("document:1", "viewer", user),
+		})
+		require.NoError(t, err)
+		return resp.GetAllowed()
+	}
+
+	require.True(t, check("user:admin"))
+	require.Zero(t, resolved.Load())
+
+	require.False(t, check("user:anne"))
+	require.Equal(t, int32(1), resolved.Load())
+}
+
 func TestThreeProngThroughVariousLayers(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -1858,6 +1983,29 @@ func TestDelegateCheckResolver(t *testing.T) {
 		_, ok = localChecker.GetDelegate().(*graph.CachedCheckResolver)
 		require.True(t, ok)
 	})
+
+	t.Run("check_coalescing_enabled", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		s := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithCheckQueryCacheEnabled(true),
+			WithCheckCoalescingWindow(2*time.Millisecond),
+		)
+		t.Cleanup(s.Close)
+
+		checkResolver, closer, _ := s.getCheckResolverBuilder("store_id_123").Build()
+		defer closer()
+
+		cachedCheckResolver, ok := checkResolver.(*graph.CachedCheckResolver)
+		require.True(t, ok)
+
+		coalescingResolver, ok := cachedCheckResolver.GetDelegate().(*graph.CoalescingCheckResolver)
+		require.True(t, ok)
+
+		_, ok = coalescingResolver.GetDelegate().(*graph.LocalChecker)
+		require.True(t, ok)
+	})
 }
 
 func TestWithFeatureFlagClient(t *testing.T) {
@@ -2252,6 +2400,68 @@ func TestCheckWithCachedControllerEnabled(t *testing.T) {
 	})
 }
 
+// listenUntilDone is a storage.ChangeListener that pushes its changes once and then waits for
+// the subscription to be canceled.
+type listenUntilDone struct {
+	storeID string
+	changes []*openfgav1.TupleChange
+}
+
+func (l listenUntilDone) Listen(ctx context.Context, handle func(string, []*openfgav1.TupleChange)) error {
+	handle(l.storeID, l.changes)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestServerWithChangeListener(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	listener := listenUntilDone{
+		storeID: ulid.Make().String(),
+		changes: []*openfgav1.TupleChange{{
+			TupleKey:  tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
+		}},
+	}
+
+	t.Run("requires_the_cache_controller", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		_, err := NewServerWithOpts(
+			WithDatastore(mockDatastore),
+			WithCheckQueryCacheEnabled(true),
+			WithChangeListener(listener),
+		)
+		require.ErrorContains(t, err, "a change listener requires the cache controller to be enabled")
+	})
+
+	t.Run("invalidates_the_cache_controller", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		s := MustNewServerWithOpts(
+			WithDatastore(mockDatastore),
+			WithCacheControllerEnabled(true),
+			WithCheckQueryCacheEnabled(true),
+			WithChangeListener(listener),
+		)
+
+		t.Cleanup(func() {
+			mockDatastore.EXPECT().Close().Times(1)
+			s.Close()
+		})
+
+		require.Eventually(t, func() bool {
+			return s.sharedDatastoreResources.CheckCache.Get(storage.GetChangelogCacheKey(listener.storeID)) != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
 func TestCheckWithCachedIterator(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
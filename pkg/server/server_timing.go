@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/server/commands"
+)
+
+// ServerTimingHeader is the response header reporting the resolution time, the datastore time and
+// the cache status of the Check and BatchCheck requests, as defined by the W3C Server Timing
+// specification.
+const ServerTimingHeader = "Server-Timing"
+
+// The cache status reported in the Server-Timing header.
+const (
+	serverTimingCacheHit     = "hit"
+	serverTimingCacheMiss    = "miss"
+	serverTimingCachePartial = "partial"
+	serverTimingCacheBypass  = "bypass"
+)
+
+// setServerTiming sets the Server-Timing header, if enabled.
+func (s *Server) setServerTiming(ctx context.Context, resolution, datastore time.Duration, cacheStatus string) {
+	if !s.serverTimingEnabled {
+		return
+	}
+
+	s.transport.SetHeader(ctx, ServerTimingHeader, fmt.Sprintf("resolution;dur=%s, datastore;dur=%s, cache;desc=%s",
+		serverTimingDuration(resolution),
+		serverTimingDuration(datastore),
+		cacheStatus,
+	))
+}
+
+// serverTimingDuration formats d in milliseconds, with a microsecond precision.
+func serverTimingDuration(d time.Duration) string {
+	return strconv.FormatFloat(float64(d.Microseconds())/1000, 'f', -1, 64)
+}
+
+// checkCacheBypassed reports whether the Check query cache cannot serve a request with the given
+// consistency.
+func (s *Server) checkCacheBypassed(consistency openfgav1.ConsistencyPreference) bool {
+	return !s.cacheSettings.ShouldCacheCheckQueries() || consistency == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY
+}
+
+// checkCacheStatus returns the cache status of a Check response.
+func (s *Server) checkCacheStatus(consistency openfgav1.ConsistencyPreference, metadata graph.ResolveCheckResponseMetadata) string {
+	switch {
+	case s.checkCacheBypassed(consistency):
+		return serverTimingCacheBypass
+	case metadata.CacheHit:
+		return serverTimingCacheHit
+	default:
+		return serverTimingCacheMiss
+	}
+}
+
+// batchCheckCacheStatus returns the cache status of a BatchCheck response: a hit if every check
+// was served from the cache, a miss if none was, and partial otherwise.
+func (s *Server) batchCheckCacheStatus(consistency openfgav1.ConsistencyPreference, result map[commands.CorrelationID]*commands.BatchCheckOutcome) string {
+	if s.checkCacheBypassed(consistency) {
+		return serverTimingCacheBypass
+	}
+
+	hits := 0
+	for _, outcome := range result {
+		if outcome.CheckResponse.GetResolutionMetadata().CacheHit {
+			hits++
+		}
+	}
+
+	switch hits {
+	case 0:
+		return serverTimingCacheMiss
+	case len(result):
+		return serverTimingCacheHit
+	default:
+		return serverTimingCachePartial
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestServerTimingHeader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithTransport(transport),
+		WithServerTimingEnabled(true),
+		WithCheckQueryCacheEnabled(true),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-test",
+	})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]
+	`)
+	_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	serverTiming := regexp.MustCompile(`^resolution;dur=[0-9.]+, datastore;dur=[0-9.]+, cache;desc=([a-z]+)$`)
+	cacheStatus := func(t *testing.T) string {
+		matches := serverTiming.FindStringSubmatch(transport.get(ServerTimingHeader))
+		require.Len(t, matches, 2, transport.get(ServerTimingHeader))
+		return matches[1]
+	}
+
+	checkRequest := func(consistency openfgav1.ConsistencyPreference) *openfgav1.CheckRequest {
+		return &openfgav1.CheckRequest{
+			StoreId:     storeID,
+			TupleKey:    &openfgav1.CheckRequestTupleKey{User: "user:anne", Relation: "viewer", Object: "document:1"},
+			Consistency: consistency,
+		}
+	}
+
+	t.Run("check", func(t *testing.T) {
+		transport.reset()
+		_, err := s.Check(context.Background(), checkRequest(openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.Equal(t, serverTimingCacheMiss, cacheStatus(t))
+
+		transport.reset()
+		_, err = s.Check(context.Background(), checkRequest(openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.Equal(t, serverTimingCacheHit, cacheStatus(t))
+
+		transport.reset()
+		_, err = s.Check(context.Background(), checkRequest(openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+		require.NoError(t, err)
+		require.Equal(t, serverTimingCacheBypass, cacheStatus(t))
+	})
+
+	t.Run("batch_check", func(t *testing.T) {
+		transport.reset()
+		_, err := s.BatchCheck(context.Background(), &openfgav1.BatchCheckRequest{
+			StoreId: storeID,
+			Checks: []*openfgav1.BatchCheckItem{
+				{
+					TupleKey:      &openfgav1.CheckRequestTupleKey{User: "user:anne", Relation: "viewer", Object: "document:1"},
+					CorrelationId: "id1",
+				},
+				{
+					TupleKey:      &openfgav1.CheckRequestTupleKey{User: "user:bob", Relation: "viewer", Object: "document:1"},
+					CorrelationId: "id2",
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, serverTimingCachePartial, cacheStatus(t))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		transport := &headerRecordingTransport{headers: map[string]string{}}
+		s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+		t.Cleanup(s.Close)
+
+		_, err := s.Check(context.Background(), checkRequest(openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.Empty(t, transport.get(ServerTimingHeader))
+	})
+}
+
+func TestServerTimingDuration(t *testing.T) {
+	require.Equal(t, "0", serverTimingDuration(0))
+	require.Equal(t, "1.5", serverTimingDuration(1500*time.Microsecond))
+	require.Equal(t, "250", serverTimingDuration(250*time.Millisecond))
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/cachecontroller"
+)
+
+// SessionTokenHeader is set by Write, when the session tokens are enabled, to a token encoding the
+// store and the ULID of the last change of the write in the changelog. When Read, Check,
+// ListObjects and StreamedListObjects requests of the same store set it, they observe the write:
+// they are served with HIGHER_CONSISTENCY until the cache controller of the replica serving them
+// has read the change of the write, or a change committed after it, from the changelog, and
+// invalidated the caches older than it. The cache controller reads the changelog like the reads
+// with MINIMIZE_LATENCY, e.g. from the secondary database.
+//
+// The ULIDs are generated by the instances before the changes are committed, so a change with a
+// later ULID can be committed, and observed, before the change of the write. A later change is
+// only known to be committed after the write once its time exceeds that of the write by the
+// commit margin.
+const SessionTokenHeader = "Openfga-Session-Token"
+
+// newSessionToken returns the session token of a write to the store whose last change in the
+// changelog has the given ULID.
+func newSessionToken(storeID, changeID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(storeID + ":" + changeID))
+}
+
+// parseSessionToken returns the store and the ULID of the last change of the write of a session
+// token.
+func parseSessionToken(token string) (string, ulid.ULID, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", ulid.ULID{}, false
+	}
+	storeID, changeID, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", ulid.ULID{}, false
+	}
+	id, err := ulid.ParseStrict(changeID)
+	if err != nil {
+		return "", ulid.ULID{}, false
+	}
+	return storeID, id, true
+}
+
+// sessionTokenFromHeader returns the session token requested in the SessionTokenHeader, or the
+// empty string.
+func sessionTokenFromHeader(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	// grpc-gateway converts header names to lowercase
+	values := md.Get(strings.ToLower(SessionTokenHeader))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// sessionConsistency returns the consistency with which a request of the store must be served to
+// observe the write of its session token, if any. The invalid session tokens and the session tokens
+// of the other stores are ignored.
+func (s *Server) sessionConsistency(ctx context.Context, storeID string, consistency openfgav1.ConsistencyPreference) openfgav1.ConsistencyPreference {
+	if !s.sessionTokensEnabled || consistency == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return consistency
+	}
+	token := sessionTokenFromHeader(ctx)
+	if token == "" {
+		return consistency
+	}
+	tokenStoreID, changeID, ok := parseSessionToken(token)
+	if !ok || tokenStoreID != storeID {
+		return consistency
+	}
+
+	// the write is never known to be observed when the cache controller is disabled, or doesn't
+	// record the changes it observes
+	observed := false
+	if observer, ok := s.sharedDatastoreResources.CacheController.(cachecontroller.ChangeObserver); ok {
+		lastChangeID, err := ulid.ParseStrict(observer.LastObservedChangeID(ctx, storeID))
+		observed = err == nil && (lastChangeID == changeID ||
+			ulid.Time(lastChangeID.Time()).Sub(ulid.Time(changeID.Time())) >= s.sessionTokensCommitMargin)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("session_token_observed", observed))
+	if observed {
+		return consistency
+	}
+	return openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/cachecontroller"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestSessionTokens(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	_, ds, _ := util.MustBootstrapDatastore(t, "memory")
+
+	transport := &headerRecordingTransport{headers: map[string]string{}}
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithTransport(transport),
+		WithSessionTokensEnabled(true),
+		WithSessionTokensCommitMargin(time.Second),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: "1.1",
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{Type: "user"},
+			{Type: "document", Relations: map[string]*openfgav1.Userset{
+				"viewer": {Userset: &openfgav1.Userset_This{}},
+			}, Metadata: &openfgav1.Metadata{Relations: map[string]*openfgav1.RelationMetadata{
+				"viewer": {DirectlyRelatedUserTypes: []*openfgav1.RelationReference{{Type: "user"}}},
+			}}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+	token := transport.get(SessionTokenHeader)
+	require.NotEmpty(t, token)
+
+	tokenStoreID, changeID, ok := parseSessionToken(token)
+	require.True(t, ok)
+	require.Equal(t, storeID, tokenStoreID)
+
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(SessionTokenHeader, token))
+	}
+	consistency := func(ctx context.Context, storeID string) openfgav1.ConsistencyPreference {
+		return s.sessionConsistency(ctx, storeID, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY)
+	}
+
+	t.Run("without_cache_controller", func(t *testing.T) {
+		require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, consistency(ctx, storeID))
+		require.Equal(t, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY, consistency(withToken(token), storeID))
+		require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, consistency(withToken(token), "other"))
+		require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, consistency(withToken("invalid"), storeID))
+	})
+
+	t.Run("with_cache_controller", func(t *testing.T) {
+		observer := &fakeChangeObserver{CacheController: cachecontroller.NewNoopCacheController()}
+		original := s.sharedDatastoreResources.CacheController
+		s.sharedDatastoreResources.CacheController = observer
+		defer func() {
+			s.sharedDatastoreResources.CacheController = original
+		}()
+
+		require.Equal(t, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY, consistency(withToken(token), storeID))
+
+		observer.lastChangeID = ulid.MustNew(changeID.Time()-1, nil).String()
+		require.Equal(t, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY, consistency(withToken(token), storeID))
+
+		observer.lastChangeID = changeID.String()
+		require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, consistency(withToken(token), storeID))
+
+		// a later change may have been committed before the write within the commit margin
+		observer.lastChangeID = ulid.MustNew(changeID.Time()+1, nil).String()
+		require.Equal(t, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY, consistency(withToken(token), storeID))
+
+		observer.lastChangeID = ulid.MustNew(changeID.Time()+uint64(time.Second.Milliseconds()), nil).String()
+		require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, consistency(withToken(token), storeID))
+	})
+
+	t.Run("with_in_memory_cache_controller", func(t *testing.T) {
+		cache, err := storage.NewInMemoryLRUCache[any]()
+		require.NoError(t, err)
+		defer cache.Stop()
+		cacheController := cachecontroller.NewCacheController(ds, cache, time.Minute, time.Minute, time.Minute, cachecontroller.WithChangeObservation())
+		original := s.sharedDatastoreResources.CacheController
+		s.sharedDatastoreResources.CacheController = cacheController
+		defer func() {
+			s.sharedDatastoreResources.CacheController = original
+		}()
+
+		// the first request triggers the read of the changelog
+		require.Equal(t, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY, consistency(withToken(token), storeID))
+		require.Eventually(t, func() bool {
+			return consistency(withToken(token), storeID) == openfgav1.ConsistencyPreference_MINIMIZE_LATENCY
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("read_observes_the_write", func(t *testing.T) {
+		resp, err := s.Read(withToken(token), &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		require.Len(t, resp.GetTuples(), 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s.sessionTokensEnabled = false
+		defer func() {
+			s.sessionTokensEnabled = true
+		}()
+
+		transport.reset()
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+		require.Empty(t, transport.get(SessionTokenHeader))
+
+		require.Equal(t, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY, consistency(withToken(token), storeID))
+	})
+}
+
+// fakeChangeObserver is a cache controller that has observed the change with the given ULID.
+type fakeChangeObserver struct {
+	cachecontroller.CacheController
+	lastChangeID string
+}
+
+func (f *fakeChangeObserver) LastObservedChangeID(context.Context, string) string {
+	return f.lastChangeID
+}
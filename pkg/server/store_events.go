@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/storeevents"
+)
+
+// ErrStoreEventsDisabled is returned by ReadStoreEvents when the store events are not enabled
+// with WithStoreEventsEnabled.
+var ErrStoreEventsDisabled = status.Error(codes.Unimplemented, "store events are not enabled")
+
+// ReadStoreEvents reads the events of a store after the continuation token of req, in the order in
+// which they happened: the changes of its tuples as returned by ReadChanges, the writes of its
+// authorization models and of their assertions, and its creation and deletion. It is authorized
+// like ReadChanges.
+func (s *Server) ReadStoreEvents(ctx context.Context, req *storeevents.ReadRequest) (*storeevents.ReadResponse, error) {
+	ctx, span := tracer.Start(ctx, "ReadStoreEvents", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.StringSlice("types", req.Types),
+	))
+	defer span.End()
+
+	if s.storeEventsRecorder == nil {
+		return nil, ErrStoreEventsDisabled
+	}
+
+	if err := (&openfgav1.ReadChangesRequest{StoreId: req.StoreID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.PageSize < 0 || req.PageSize > s.readChangesMaxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid ReadRequest.PageSize: value must be inside range [1, %d]", s.readChangesMaxPageSize)
+	}
+	types, err := storeevents.ParseEventTypes(req.Types)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.ReadChanges.String(),
+	})
+
+	err = s.checkAuthz(ctx, req.StoreID, apimethod.ReadChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := storeevents.NewReader(s.datastore, s.storeEventsRecorder,
+		storeevents.WithHorizonOffset(time.Duration(s.changelogHorizonOffset)*time.Minute),
+	)
+	events, token, err := reader.Read(ctx, req.StoreID, types, int(req.PageSize), req.ContinuationToken)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	return &storeevents.ReadResponse{
+		Events:            events,
+		ContinuationToken: token,
+	}, nil
+}
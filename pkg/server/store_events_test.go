@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/storeevents"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestReadStoreEvents(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithStoreEventsEnabled(true))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "events"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "user"}},
+	})
+	require.NoError(t, err)
+
+	_, err = s.WriteAssertions(ctx, &openfgav1.WriteAssertionsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetAuthorizationModelId(),
+	})
+	require.NoError(t, err)
+
+	t.Run("assertions_writes_are_recorded", func(t *testing.T) {
+		res, err := s.ReadStoreEvents(ctx, &storeevents.ReadRequest{
+			StoreID: storeID,
+			Types:   []string{string(storeevents.EventTypeAssertionsWrite)},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Events, 1)
+		require.Equal(t, model.GetAuthorizationModelId(), res.Events[0].AuthorizationModelID)
+		require.NotEmpty(t, res.ContinuationToken)
+	})
+
+	t.Run("invalid_type", func(t *testing.T) {
+		_, err := s.ReadStoreEvents(ctx, &storeevents.ReadRequest{StoreID: storeID, Types: []string{"unknown"}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid_page_size", func(t *testing.T) {
+		_, err := s.ReadStoreEvents(ctx, &storeevents.ReadRequest{StoreID: storeID, PageSize: 1000000})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid_continuation_token", func(t *testing.T) {
+		_, err := s.ReadStoreEvents(ctx, &storeevents.ReadRequest{StoreID: storeID, ContinuationToken: "invalid"})
+		require.ErrorIs(t, err, serverErrors.ErrInvalidContinuationToken)
+	})
+
+	t.Run("store_deletes_are_recorded", func(t *testing.T) {
+		_, err := s.DeleteStore(ctx, &openfgav1.DeleteStoreRequest{StoreId: storeID})
+		require.NoError(t, err)
+
+		res, err := s.ReadStoreEvents(ctx, &storeevents.ReadRequest{
+			StoreID: storeID,
+			Types:   []string{string(storeevents.EventTypeStoreDelete)},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Events, 1)
+	})
+}
+
+func TestReadStoreEventsDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	_, err := s.ReadStoreEvents(context.Background(), &storeevents.ReadRequest{StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"})
+	require.ErrorIs(t, err, ErrStoreEventsDisabled)
+}
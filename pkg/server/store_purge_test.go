@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/jobs"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestStorePurge(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithJobsEnabled(true), WithStorePurgeEnabled(true))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "purge"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+	require.NoError(t, ds.Write(ctx, storeID, nil, storage.Writes{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+	}))
+
+	_, err = s.DeleteStore(ctx, &openfgav1.DeleteStoreRequest{StoreId: storeID})
+	require.NoError(t, err)
+
+	var job *jobs.Job
+	require.Eventually(t, func() bool {
+		list, err := s.ListJobs(ctx, &jobs.ListRequest{StoreID: storeID})
+		require.NoError(t, err)
+		if len(list.Jobs) != 1 {
+			return false
+		}
+		job = list.Jobs[0]
+		return job.State.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, JobKindPurge, job.Kind)
+	require.Equal(t, jobs.StateSucceeded, job.State, job.Error)
+	require.JSONEq(t, `{"deleted":3}`, string(job.Result))
+
+	tuples, _, err := ds.ReadPage(ctx, storeID, storage.ReadFilter{}, storage.ReadPageOptions{
+		Pagination: storage.NewPaginationOptions(100, ""),
+	})
+	require.NoError(t, err)
+	require.Empty(t, tuples)
+}
+
+func TestStorePurgeRequiresJobs(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	_, err := NewServerWithOpts(WithDatastore(ds), WithStorePurgeEnabled(true))
+	require.EqualError(t, err, "store purge requires the background jobs to be enabled")
+}
@@ -0,0 +1,13 @@
+// Package storeevents serves the changelog of a store that includes the changes of its
+// authorization models, its assertions and its lifecycle alongside the changes of its tuples.
+//
+// The events of a store are read in the order in which they happened with a single cursor, by
+// the store events service, whose messages are encoded as JSON:
+// /openfga.events.v1.StoreEventsService/ReadEvents. It is also served over HTTP as
+// GET /stores/{store_id}/events.
+//
+// The tuple changes, the authorization model writes and the store creation are read from the
+// datastore. The datastores don't record when assertions are written or when a deleted store
+// was deleted, so these events are recorded in memory by the replica that served the request:
+// they are only reported by that replica, until it restarts.
+package storeevents
@@ -0,0 +1,359 @@
+package storeevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// EventType is the type of a store event.
+type EventType string
+
+const (
+	// EventTypeTupleWrite is the type of the events of the tuples written to the store.
+	EventTypeTupleWrite EventType = "tuple_write"
+
+	// EventTypeTupleDelete is the type of the events of the tuples deleted from the store.
+	EventTypeTupleDelete EventType = "tuple_delete"
+
+	// EventTypeAuthorizationModelWrite is the type of the events of the authorization models
+	// written to the store.
+	EventTypeAuthorizationModelWrite EventType = "authorization_model_write"
+
+	// EventTypeAssertionsWrite is the type of the events of the assertions written for an
+	// authorization model of the store.
+	EventTypeAssertionsWrite EventType = "assertions_write"
+
+	// EventTypeStoreCreate is the type of the event of the creation of the store.
+	EventTypeStoreCreate EventType = "store_create"
+
+	// EventTypeStoreDelete is the type of the event of the deletion of the store.
+	EventTypeStoreDelete EventType = "store_delete"
+)
+
+// maxRecordedEvents is the number of events recorded in memory for each store. The oldest events
+// are dropped first.
+const maxRecordedEvents = 1000
+
+// ErrInvalidEventType is returned when parsing an unknown event type.
+var ErrInvalidEventType = errors.New("invalid store event type")
+
+var eventTypes = []EventType{
+	EventTypeTupleWrite,
+	EventTypeTupleDelete,
+	EventTypeAuthorizationModelWrite,
+	EventTypeAssertionsWrite,
+	EventTypeStoreCreate,
+	EventTypeStoreDelete,
+}
+
+// ParseEventTypes parses a list of event types. An empty list selects all the event types.
+func ParseEventTypes(values []string) ([]EventType, error) {
+	types := make([]EventType, 0, len(values))
+	for _, v := range values {
+		t := EventType(v)
+		found := false
+		for _, known := range eventTypes {
+			if t == known {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w '%s'", ErrInvalidEventType, v)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Event is an event of a store. The tuple fields are only set for the tuple events, and
+// AuthorizationModelID only for the authorization model and assertions events.
+type Event struct {
+	Type                 EventType `json:"type"`
+	Timestamp            time.Time `json:"timestamp"`
+	Object               string    `json:"object,omitempty"`
+	Relation             string    `json:"relation,omitempty"`
+	User                 string    `json:"user,omitempty"`
+	Condition            string    `json:"condition,omitempty"`
+	AuthorizationModelID string    `json:"authorization_model_id,omitempty"`
+}
+
+// positionedEvent is an event with its position in the changelog of the store, a ULID that sorts
+// it among the changes of the tuples.
+type positionedEvent struct {
+	position string
+	event    *Event
+}
+
+// Recorder records in memory the events of the stores that the datastores don't keep.
+type Recorder struct {
+	mu     sync.Mutex
+	events map[string][]positionedEvent
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{events: map[string][]positionedEvent{}}
+}
+
+// RecordAssertionsWrite records that the assertions of an authorization model of the store were
+// written.
+func (r *Recorder) RecordAssertionsWrite(storeID, modelID string) {
+	r.record(storeID, &Event{Type: EventTypeAssertionsWrite, AuthorizationModelID: modelID})
+}
+
+// RecordStoreDelete records that the store was deleted.
+func (r *Recorder) RecordStoreDelete(storeID string) {
+	r.record(storeID, &Event{Type: EventTypeStoreDelete})
+}
+
+func (r *Recorder) record(storeID string, event *Event) {
+	id := ulid.Make()
+	event.Timestamp = ulid.Time(id.Time()).UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := append(r.events[storeID], positionedEvent{position: id.String(), event: event})
+	if len(events) > maxRecordedEvents {
+		events = events[len(events)-maxRecordedEvents:]
+	}
+	r.events[storeID] = events
+}
+
+// read returns the recorded events of the store after from and up to to, or without upper bound
+// if to is empty.
+func (r *Recorder) read(storeID, from, to string) []positionedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []positionedEvent
+	for _, e := range r.events[storeID] {
+		if inRange(e.position, from, to) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// ReaderOption is an option of a Reader.
+type ReaderOption func(*Reader)
+
+// WithHorizonOffset sets the duration for which the most recent tuple changes are not returned,
+// as for ReadChanges.
+func WithHorizonOffset(offset time.Duration) ReaderOption {
+	return func(r *Reader) {
+		r.horizonOffset = offset
+	}
+}
+
+// Reader reads the events of the stores from the datastore and from a Recorder.
+type Reader struct {
+	datastore     storage.OpenFGADatastore
+	recorder      *Recorder
+	horizonOffset time.Duration
+}
+
+// NewReader creates a new Reader of the events of the datastore and of the recorder.
+func NewReader(datastore storage.OpenFGADatastore, recorder *Recorder, opts ...ReaderOption) *Reader {
+	r := &Reader{
+		datastore: datastore,
+		recorder:  recorder,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Read returns the events of the store of the given types (or of all types if none are given)
+// that happened after the continuation token, in the order in which they happened, and the
+// continuation token from which to read the next events. At most pageSize tuple events are
+// returned, along with the other events that happened until the last of them. If there are no
+// new events, the continuation token is returned unchanged so that it can be polled.
+func (r *Reader) Read(ctx context.Context, storeID string, types []EventType, pageSize int, continuationToken string) ([]*Event, string, error) {
+	if continuationToken != "" {
+		if _, err := ulid.Parse(continuationToken); err != nil {
+			return nil, "", storage.ErrInvalidContinuationToken
+		}
+	}
+	if pageSize <= 0 {
+		pageSize = storage.DefaultPageSize
+	}
+
+	wanted := map[EventType]bool{}
+	for _, t := range types {
+		wanted[t] = true
+	}
+	want := func(t EventType) bool {
+		return len(wanted) == 0 || wanted[t]
+	}
+
+	var tupleEvents []*Event
+	var lastTuplePosition, bound string
+	if want(EventTypeTupleWrite) || want(EventTypeTupleDelete) {
+		changes, token, err := r.datastore.ReadChanges(ctx, storeID,
+			storage.ReadChangesFilter{HorizonOffset: r.horizonOffset},
+			storage.ReadChangesOptions{Pagination: storage.NewPaginationOptions(int32(pageSize), continuationToken)},
+		)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, "", err
+		}
+		if len(changes) > 0 {
+			lastTuplePosition = token
+			// The other events after the last change of a full page are returned with the next page.
+			if len(changes) == pageSize {
+				bound = token
+			}
+		}
+		for _, change := range changes {
+			if event := tupleEvent(change); want(event.Type) {
+				tupleEvents = append(tupleEvents, event)
+			}
+		}
+	}
+
+	others, err := r.readOtherEvents(ctx, storeID, want, continuationToken, bound)
+	if err != nil {
+		return nil, "", err
+	}
+	if !want(EventTypeTupleWrite) && !want(EventTypeTupleDelete) && len(others) > pageSize {
+		others = others[:pageSize]
+	}
+
+	events := make([]*Event, 0, len(tupleEvents)+len(others))
+	i := 0
+	for _, other := range others {
+		for i < len(tupleEvents) && tupleEvents[i].Timestamp.Before(other.event.Timestamp) {
+			events = append(events, tupleEvents[i])
+			i++
+		}
+		events = append(events, other.event)
+	}
+	events = append(events, tupleEvents[i:]...)
+
+	next := continuationToken
+	if lastTuplePosition > next {
+		next = lastTuplePosition
+	}
+	if len(others) > 0 && others[len(others)-1].position > next {
+		next = others[len(others)-1].position
+	}
+
+	return events, next, nil
+}
+
+// readOtherEvents returns the events that aren't tuple changes after from and up to to, or
+// without upper bound if to is empty, sorted by position.
+func (r *Reader) readOtherEvents(ctx context.Context, storeID string, want func(EventType) bool, from, to string) ([]positionedEvent, error) {
+	var events []positionedEvent
+
+	if want(EventTypeStoreCreate) {
+		store, err := r.datastore.GetStore(ctx, storeID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		if store != nil {
+			createdAt := store.GetCreatedAt().AsTime()
+			position := ulid.MustNew(ulid.Timestamp(createdAt), nil).String()
+			if inRange(position, from, to) {
+				events = append(events, positionedEvent{
+					position: position,
+					event:    &Event{Type: EventTypeStoreCreate, Timestamp: createdAt.UTC()},
+				})
+			}
+		}
+	}
+
+	if want(EventTypeAuthorizationModelWrite) {
+		models, err := r.readModelEvents(ctx, storeID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, models...)
+	}
+
+	if r.recorder != nil {
+		for _, e := range r.recorder.read(storeID, from, to) {
+			if want(e.event.Type) {
+				events = append(events, e)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].position < events[j].position
+	})
+	return events, nil
+}
+
+// readModelEvents returns the events of the authorization models written after from and up to to.
+// The models are read from the newest, so the pages stop when reaching from.
+func (r *Reader) readModelEvents(ctx context.Context, storeID, from, to string) ([]positionedEvent, error) {
+	var events []positionedEvent
+	token := ""
+	for {
+		models, next, err := r.datastore.ReadAuthorizationModels(ctx, storeID, storage.ReadAuthorizationModelsOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, token),
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return events, nil
+			}
+			return nil, err
+		}
+
+		for _, model := range models {
+			if model.GetId() <= from {
+				return events, nil
+			}
+			id, err := ulid.Parse(model.GetId())
+			if err != nil || !inRange(model.GetId(), from, to) {
+				continue
+			}
+			events = append(events, positionedEvent{
+				position: model.GetId(),
+				event: &Event{
+					Type:                 EventTypeAuthorizationModelWrite,
+					Timestamp:            ulid.Time(id.Time()).UTC(),
+					AuthorizationModelID: model.GetId(),
+				},
+			})
+		}
+
+		if next == "" || len(models) == 0 {
+			return events, nil
+		}
+		token = next
+	}
+}
+
+func tupleEvent(change *openfgav1.TupleChange) *Event {
+	event := &Event{
+		Type:      EventTypeTupleWrite,
+		Timestamp: change.GetTimestamp().AsTime().UTC(),
+		Object:    change.GetTupleKey().GetObject(),
+		Relation:  change.GetTupleKey().GetRelation(),
+		User:      change.GetTupleKey().GetUser(),
+		Condition: change.GetTupleKey().GetCondition().GetName(),
+	}
+	if change.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_DELETE {
+		event.Type = EventTypeTupleDelete
+	}
+	return event
+}
+
+// inRange returns whether position is after from and up to to, or without upper bound if to is
+// empty.
+func inRange(position, from, to string) bool {
+	return position > from && (to == "" || position <= to)
+}
@@ -0,0 +1,153 @@
+package storeevents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func eventTypesOf(events []*Event) []EventType {
+	types := make([]EventType, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestReader(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	recorder := NewRecorder()
+
+	// each event happens in a later millisecond than the previous one, so that their order is
+	// deterministic
+	step := func() { time.Sleep(2 * time.Millisecond) }
+
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "events"})
+	require.NoError(t, err)
+	step()
+
+	modelID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, &openfgav1.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: "1.1",
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{Type: "user"},
+		},
+	}))
+	step()
+
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	require.NoError(t, ds.Write(ctx, storeID, nil, storage.Writes{tk}))
+	step()
+
+	recorder.RecordAssertionsWrite(storeID, modelID)
+	step()
+
+	require.NoError(t, ds.Write(ctx, storeID, storage.Deletes{tuple.TupleKeyToTupleKeyWithoutCondition(tk)}, nil))
+	step()
+
+	recorder.RecordStoreDelete(storeID)
+
+	reader := NewReader(ds, recorder)
+
+	t.Run("all_events_in_order", func(t *testing.T) {
+		events, token, err := reader.Read(ctx, storeID, nil, 50, "")
+		require.NoError(t, err)
+		require.Equal(t, []EventType{
+			EventTypeStoreCreate,
+			EventTypeAuthorizationModelWrite,
+			EventTypeTupleWrite,
+			EventTypeAssertionsWrite,
+			EventTypeTupleDelete,
+			EventTypeStoreDelete,
+		}, eventTypesOf(events))
+		require.Equal(t, modelID, events[1].AuthorizationModelID)
+		require.Equal(t, "document:1", events[2].Object)
+		require.Equal(t, "viewer", events[2].Relation)
+		require.Equal(t, "user:anne", events[2].User)
+		require.Equal(t, modelID, events[3].AuthorizationModelID)
+
+		events, next, err := reader.Read(ctx, storeID, nil, 50, token)
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.Equal(t, token, next)
+	})
+
+	t.Run("pages_follow_the_tuple_changes", func(t *testing.T) {
+		events, token, err := reader.Read(ctx, storeID, nil, 1, "")
+		require.NoError(t, err)
+		require.Equal(t, []EventType{
+			EventTypeStoreCreate,
+			EventTypeAuthorizationModelWrite,
+			EventTypeTupleWrite,
+		}, eventTypesOf(events))
+
+		events, token, err = reader.Read(ctx, storeID, nil, 1, token)
+		require.NoError(t, err)
+		require.Equal(t, []EventType{EventTypeAssertionsWrite, EventTypeTupleDelete}, eventTypesOf(events))
+
+		events, _, err = reader.Read(ctx, storeID, nil, 1, token)
+		require.NoError(t, err)
+		require.Equal(t, []EventType{EventTypeStoreDelete}, eventTypesOf(events))
+	})
+
+	t.Run("filter_by_type", func(t *testing.T) {
+		events, _, err := reader.Read(ctx, storeID, []EventType{EventTypeTupleDelete, EventTypeStoreDelete}, 50, "")
+		require.NoError(t, err)
+		require.Equal(t, []EventType{EventTypeTupleDelete, EventTypeStoreDelete}, eventTypesOf(events))
+
+		events, token, err := reader.Read(ctx, storeID, []EventType{EventTypeAuthorizationModelWrite, EventTypeAssertionsWrite}, 1, "")
+		require.NoError(t, err)
+		require.Equal(t, []EventType{EventTypeAuthorizationModelWrite}, eventTypesOf(events))
+
+		events, _, err = reader.Read(ctx, storeID, []EventType{EventTypeAuthorizationModelWrite, EventTypeAssertionsWrite}, 1, token)
+		require.NoError(t, err)
+		require.Equal(t, []EventType{EventTypeAssertionsWrite}, eventTypesOf(events))
+	})
+
+	t.Run("unknown_store", func(t *testing.T) {
+		events, token, err := reader.Read(ctx, ulid.Make().String(), nil, 50, "")
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.Empty(t, token)
+	})
+
+	t.Run("invalid_continuation_token", func(t *testing.T) {
+		_, _, err := reader.Read(ctx, storeID, nil, 50, "invalid")
+		require.ErrorIs(t, err, storage.ErrInvalidContinuationToken)
+	})
+}
+
+func TestRecorderIsBounded(t *testing.T) {
+	recorder := NewRecorder()
+	for i := 0; i < maxRecordedEvents+10; i++ {
+		recorder.RecordAssertionsWrite("store", "model")
+	}
+	require.Len(t, recorder.read("store", "", ""), maxRecordedEvents)
+	require.Empty(t, recorder.read("other", "", ""))
+}
+
+func TestParseEventTypes(t *testing.T) {
+	types, err := ParseEventTypes(nil)
+	require.NoError(t, err)
+	require.Empty(t, types)
+
+	types, err = ParseEventTypes([]string{"tuple_write", "store_delete"})
+	require.NoError(t, err)
+	require.Equal(t, []EventType{EventTypeTupleWrite, EventTypeStoreDelete}, types)
+
+	_, err = ParseEventTypes([]string{"tuple_update"})
+	require.ErrorIs(t, err, ErrInvalidEventType)
+}
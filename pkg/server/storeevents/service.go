@@ -0,0 +1,101 @@
+package storeevents
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the store events service.
+	ServiceName = "openfga.events.v1.StoreEventsService"
+
+	// ReadEventsMethod is the full name of the method that reads the events of a store.
+	ReadEventsMethod = "/" + ServiceName + "/ReadEvents"
+
+	// codecName is the content-subtype of the store events requests. The store events service is
+	// not part of the OpenFGA API, so its messages are encoded as JSON instead of generated protobufs.
+	codecName = "openfga-events-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// ReadRequest reads the events of a store of the given types, or of all types if none are given,
+// after the continuation token.
+type ReadRequest struct {
+	StoreID           string   `json:"store_id"`
+	Types             []string `json:"types,omitempty"`
+	PageSize          int32    `json:"page_size,omitempty"`
+	ContinuationToken string   `json:"continuation_token,omitempty"`
+}
+
+// ReadResponse is a page of the events of a store, and the continuation token from which to read
+// the next events.
+type ReadResponse struct {
+	Events            []*Event `json:"events"`
+	ContinuationToken string   `json:"continuation_token"`
+}
+
+// Server reads the events of the stores. It is implemented by the OpenFGA server.
+type Server interface {
+	ReadStoreEvents(ctx context.Context, req *ReadRequest) (*ReadResponse, error)
+}
+
+// RegisterServer registers the store events service, which reads the events with srv, on
+// registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "ReadEvents",
+				Handler:    readEventsHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/storeevents/service.go",
+	}, srv)
+}
+
+func readEventsHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &ReadRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).ReadStoreEvents(ctx, req.(*ReadRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: ReadEventsMethod}, handler)
+}
+
+// ReadEvents reads the events of a store on conn.
+func ReadEvents(ctx context.Context, conn grpc.ClientConnInterface, req *ReadRequest) (*ReadResponse, error) {
+	out := &ReadResponse{}
+	if err := conn.Invoke(ctx, ReadEventsMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
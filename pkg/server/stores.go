@@ -78,6 +78,16 @@ func (s *Server) DeleteStore(ctx context.Context, req *openfgav1.DeleteStoreRequ
 		return nil, err
 	}
 
+	if s.storePurgeEnabled {
+		// the datastores keep the tuples of the deleted stores
+		s.createStorePurgeJob(ctx, req.GetStoreId())
+	}
+
+	if s.storeEventsRecorder != nil {
+		// the datastores don't keep when the stores were deleted
+		s.storeEventsRecorder.RecordStoreDelete(req.GetStoreId())
+	}
+
 	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusNoContent))
 
 	return res, nil
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/server/streamedbatchcheck"
+)
+
+// StreamedBatchCheck evaluates the checks of a BatchCheck like BatchCheck, and sends the outcome of
+// each check with send as soon as it is evaluated, in the order in which they complete. When send
+// fails, e.g. because the client went away, the checks that are not evaluated yet are canceled.
+func (s *Server) StreamedBatchCheck(ctx context.Context, req *openfgav1.BatchCheckRequest, send func(*streamedbatchcheck.Outcome) error) error {
+	ctx, span := tracer.Start(ctx, "StreamedBatchCheck", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.Int("batch_size", len(req.GetChecks())),
+		attribute.String("consistency", req.GetConsistency().String()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.BatchCheck.String(),
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	startTime := time.Now()
+
+	// the checks complete concurrently, and their outcomes are sent one at a time
+	var mu sync.Mutex
+	var sendErr error
+	onCompletion := func(ids []commands.CorrelationID, outcome *commands.BatchCheckOutcome, metadata commands.BatchCheckItemMetadata) {
+		elapsed := time.Since(startTime)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if sendErr != nil {
+			return
+		}
+		for _, id := range ids {
+			if sendErr = send(streamedBatchCheckOutcome(id, outcome, metadata, elapsed)); sendErr != nil {
+				cancel()
+				return
+			}
+		}
+	}
+
+	ctx, cmd, closer, err := s.newBatchCheckCommand(ctx, req, span, commands.WithBatchCheckCompletionFunc(onCompletion))
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	result, metadata, err := cmd.Execute(ctx, &commands.BatchCheckCommandParams{
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		Checks:               req.GetChecks(),
+		Consistency:          req.GetConsistency(),
+		StoreID:              req.GetStoreId(),
+	})
+	if err != nil {
+		return batchCheckError(span, err)
+	}
+
+	methodName := "streamedbatchcheck"
+	s.observeBatchCheckMetadata(ctx, span, methodName, metadata)
+	for _, outcome := range result {
+		s.emitCheckDurationMetric(outcome.CheckResponse.GetResolutionMetadata(), methodName)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sendErr != nil {
+		telemetry.TraceError(span, sendErr)
+	}
+	return sendErr
+}
+
+// streamedBatchCheckOutcome returns the outcome of the check of id, which completed after elapsed.
+func streamedBatchCheckOutcome(id commands.CorrelationID, outcome *commands.BatchCheckOutcome, metadata commands.BatchCheckItemMetadata, elapsed time.Duration) *streamedbatchcheck.Outcome {
+	streamed := &streamedbatchcheck.Outcome{
+		CorrelationID: string(id),
+		DurationMs:    float64(metadata.Duration.Microseconds()) / 1000,
+		ElapsedMs:     float64(elapsed.Microseconds()) / 1000,
+	}
+	if outcome.Err == nil {
+		streamed.Allowed = outcome.CheckResponse.GetAllowed()
+		return streamed
+	}
+
+	checkErr := transformCheckCommandErrorToBatchCheckError(outcome.Err)
+	streamed.Error = &streamedbatchcheck.Error{Message: checkErr.GetMessage()}
+	switch code := checkErr.GetCode().(type) {
+	case *openfgav1.CheckError_InputError:
+		streamed.Error.Code = code.InputError.String()
+	case *openfgav1.CheckError_InternalError:
+		streamed.Error.Code = code.InternalError.String()
+	}
+	return streamed
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/streamedbatchcheck"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestStreamedBatchCheck(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "streamed-batch-check"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")},
+		},
+	})
+	require.NoError(t, err)
+
+	req := func() *openfgav1.BatchCheckRequest {
+		return &openfgav1.BatchCheckRequest{
+			StoreId: storeID,
+			Checks: []*openfgav1.BatchCheckItem{
+				{TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"), CorrelationId: "anne"},
+				{TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"), CorrelationId: "anne_duplicate"},
+				{TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:bob"), CorrelationId: "bob"},
+				{TupleKey: tuple.NewCheckRequestTupleKey("document:1", "owner", "user:anne"), CorrelationId: "invalid"},
+			},
+		}
+	}
+
+	t.Run("outcomes", func(t *testing.T) {
+		outcomes := map[string]*streamedbatchcheck.Outcome{}
+		err := s.StreamedBatchCheck(ctx, req(), func(outcome *streamedbatchcheck.Outcome) error {
+			outcomes[outcome.CorrelationID] = outcome
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, outcomes, 4)
+
+		require.True(t, outcomes["anne"].Allowed)
+		require.True(t, outcomes["anne_duplicate"].Allowed)
+		require.False(t, outcomes["bob"].Allowed)
+		require.Nil(t, outcomes["bob"].Error)
+		require.GreaterOrEqual(t, outcomes["bob"].ElapsedMs, outcomes["bob"].DurationMs)
+
+		require.NotNil(t, outcomes["invalid"].Error)
+		require.Equal(t, openfgav1.ErrorCode_validation_error.String(), outcomes["invalid"].Error.Code)
+	})
+
+	t.Run("send_error_stops_the_batch", func(t *testing.T) {
+		errClosed := errors.New("closed")
+		sent := 0
+		err := s.StreamedBatchCheck(ctx, req(), func(*streamedbatchcheck.Outcome) error {
+			sent++
+			return errClosed
+		})
+		require.ErrorIs(t, err, errClosed)
+		require.Equal(t, 1, sent)
+	})
+
+	t.Run("invalid_batch", func(t *testing.T) {
+		err := s.StreamedBatchCheck(ctx, &openfgav1.BatchCheckRequest{StoreId: storeID}, func(*streamedbatchcheck.Outcome) error {
+			return nil
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
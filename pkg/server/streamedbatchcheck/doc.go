@@ -0,0 +1,15 @@
+// Package streamedbatchcheck serves the checks of a BatchCheck as a stream of their outcomes, in
+// the order in which they complete rather than once all of them are evaluated, so that a slow check
+// doesn't delay the others.
+//
+// Each outcome reports the duration of its check and the time elapsed since the batch started when
+// it completed, so that the clients can apply their own deadline to each check and discard, or stop
+// waiting for, the late ones. Duplicate checks are evaluated once, and their outcomes are sent
+// together.
+//
+// The batches are served by the streamed BatchCheck service, a server streaming method whose
+// request is a BatchCheckRequest of the OpenFGA API encoded as protobuf JSON, and whose outcomes are
+// encoded as JSON: /openfga.streamedbatchcheck.v1.StreamedBatchCheckService/BatchCheck. It is also
+// served over HTTP as POST /stores/{store_id}/streamed-batch-check, whose response has one outcome
+// per line.
+package streamedbatchcheck
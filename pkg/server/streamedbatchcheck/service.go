@@ -0,0 +1,145 @@
+package streamedbatchcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+const (
+	// ServiceName is the name of the streamed BatchCheck service.
+	ServiceName = "openfga.streamedbatchcheck.v1.StreamedBatchCheckService"
+
+	// BatchCheckMethod is the full name of the server streaming method that streams the outcomes of
+	// the checks of a BatchCheck as they complete.
+	BatchCheckMethod = "/" + ServiceName + "/BatchCheck"
+
+	// codecName is the content-subtype of the streamed BatchCheck requests. The streamed BatchCheck
+	// service is not part of the OpenFGA API, so its outcomes are encoded as JSON instead of
+	// generated protobufs. Its request, a BatchCheckRequest, is encoded as protobuf JSON.
+	codecName = "openfga-streamedbatchcheck-json"
+)
+
+var batchCheckStreamDesc = grpc.StreamDesc{
+	StreamName:    "BatchCheck",
+	ServerStreams: true,
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Unmarshal(data, m)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// Outcome is the outcome of a check of a streamed BatchCheck, sent as soon as it is evaluated.
+type Outcome struct {
+	CorrelationID string `json:"correlation_id"`
+
+	// Allowed is the result of the check, if it didn't fail.
+	Allowed bool   `json:"allowed"`
+	Error   *Error `json:"error,omitempty"`
+
+	// DurationMs is the duration of the evaluation of the check, in milliseconds.
+	DurationMs float64 `json:"duration_ms"`
+
+	// ElapsedMs is the time elapsed since the batch started when the check completed, in
+	// milliseconds. It includes the time the check waited for the checks evaluated before it.
+	ElapsedMs float64 `json:"elapsed_ms"`
+}
+
+// Error is the error of a failed check of a streamed BatchCheck.
+type Error struct {
+	// Code is the name of the openfgav1.ErrorCode or openfgav1.InternalErrorCode of the error, e.g.
+	// "validation_error" or "deadline_exceeded".
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server streams the outcomes of the checks of a BatchCheck as they complete. It is implemented by
+// the OpenFGA server.
+type Server interface {
+	StreamedBatchCheck(ctx context.Context, req *openfgav1.BatchCheckRequest, send func(*Outcome) error) error
+}
+
+// RegisterServer registers the streamed BatchCheck service, which streams the outcomes of the
+// checks of a BatchCheck with srv, on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	desc := batchCheckStreamDesc
+	desc.Handler = batchCheckHandler
+
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods:     []grpc.MethodDesc{},
+		Streams:     []grpc.StreamDesc{desc},
+		Metadata:    "pkg/server/streamedbatchcheck/service.go",
+	}, srv)
+}
+
+func batchCheckHandler(srv any, stream grpc.ServerStream) error {
+	in := &openfgav1.BatchCheckRequest{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(Server).StreamedBatchCheck(stream.Context(), in, func(outcome *Outcome) error {
+		return stream.SendMsg(outcome)
+	})
+}
+
+// BatchCheck calls recv with the outcome of each check of req streamed on conn, in the order in
+// which they complete. An error returned by recv cancels the batch and is returned.
+func BatchCheck(ctx context.Context, conn grpc.ClientConnInterface, req *openfgav1.BatchCheckRequest, recv func(*Outcome) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &batchCheckStreamDesc, BatchCheckMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		outcome := &Outcome{}
+		err := stream.RecvMsg(outcome)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := recv(outcome); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/telemetry"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/whatif"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ErrWhatIfDisabled is returned by WhatIfCheck and WhatIfBatchCheck when the what-if checks are
+// not enabled with WithWhatIfEnabled.
+var ErrWhatIfDisabled = status.Error(codes.Unimplemented, "what-if checks are not enabled")
+
+// WhatIfCheck evaluates a Check as if the changes of req had been applied to the store, and
+// without them. It is authorized like Check.
+func (s *Server) WhatIfCheck(ctx context.Context, req *whatif.CheckRequest) (*whatif.CheckResponse, error) {
+	ctx, span := tracer.Start(ctx, "WhatIfCheck", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("object", req.Object),
+		attribute.String("relation", req.Relation),
+		attribute.String("user", req.User),
+	))
+	defer span.End()
+
+	if !s.whatIfEnabled {
+		return nil, ErrWhatIfDisabled
+	}
+
+	evaluator, err := s.newWhatIfEvaluator(ctx, req.StoreID, req.AuthorizationModelID, &req.Changes, apimethod.Check)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := evaluator.evaluate(ctx, &req.Check)
+	if err != nil {
+		return nil, err
+	}
+	return &whatif.CheckResponse{
+		AuthorizationModelID: evaluator.typesys.GetAuthorizationModelID(),
+		CheckResult:          *result,
+	}, nil
+}
+
+// WhatIfBatchCheck evaluates the Checks of a BatchCheck as if the changes of req had been applied
+// to the store, and without them. It is authorized like BatchCheck. The errors of the checks are
+// returned in their results.
+func (s *Server) WhatIfBatchCheck(ctx context.Context, req *whatif.BatchCheckRequest) (*whatif.BatchCheckResponse, error) {
+	ctx, span := tracer.Start(ctx, "WhatIfBatchCheck", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.Int("checks", len(req.Checks)),
+	))
+	defer span.End()
+
+	if !s.whatIfEnabled {
+		return nil, ErrWhatIfDisabled
+	}
+
+	if len(req.Checks) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "the batch must contain at least one check")
+	}
+	if len(req.Checks) > int(s.maxChecksPerBatchCheck) {
+		return nil, serverErrors.ValidationError(fmt.Errorf("batchCheck received %d checks, the maximum allowed is %d", len(req.Checks), s.maxChecksPerBatchCheck))
+	}
+	correlationIDs := make(map[string]struct{}, len(req.Checks))
+	for _, item := range req.Checks {
+		if item.CorrelationID == "" {
+			return nil, status.Error(codes.InvalidArgument, "the checks must have a correlation_id")
+		}
+		if _, ok := correlationIDs[item.CorrelationID]; ok {
+			return nil, serverErrors.ValidationError(fmt.Errorf("duplicate correlation_id '%s'", item.CorrelationID))
+		}
+		correlationIDs[item.CorrelationID] = struct{}{}
+	}
+
+	evaluator, err := s.newWhatIfEvaluator(ctx, req.StoreID, req.AuthorizationModelID, &req.Changes, apimethod.BatchCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &whatif.BatchCheckResponse{
+		AuthorizationModelID: evaluator.typesys.GetAuthorizationModelID(),
+		Results:              make([]*whatif.BatchCheckResult, 0, len(req.Checks)),
+	}
+	for _, item := range req.Checks {
+		itemResult := &whatif.BatchCheckResult{CorrelationID: item.CorrelationID}
+		result, err := evaluator.evaluate(ctx, &item.Check)
+		if err != nil {
+			itemResult.Error = err.Error()
+		} else {
+			itemResult.CheckResult = *result
+		}
+		res.Results = append(res.Results, itemResult)
+	}
+	return res, nil
+}
+
+// whatIfEvaluator evaluates the Checks of a model of a store with and without changes.
+type whatIfEvaluator struct {
+	*Server
+	storeID string
+	typesys *typesystem.TypeSystem
+
+	// changed reads the tuples of the store with the changes.
+	changed storage.RelationshipTupleReader
+}
+
+// newWhatIfEvaluator authorizes method on the store, and validates the changes with the model of
+// the store.
+func (s *Server) newWhatIfEvaluator(ctx context.Context, storeID, modelID string, changes *whatif.Changes, method apimethod.APIMethod) (*whatIfEvaluator, error) {
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  method.String(),
+	})
+
+	if err := (&openfgav1.ReadAuthorizationModelsRequest{StoreId: storeID}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if modelID != "" {
+		if err := (&openfgav1.ReadAuthorizationModelRequest{StoreId: storeID, Id: modelID}).Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if len(changes.Writes)+len(changes.Deletes) > s.datastore.MaxTuplesPerWrite() {
+		return nil, serverErrors.ExceededEntityLimit("write operations", s.datastore.MaxTuplesPerWrite())
+	}
+
+	if err := s.checkAuthz(ctx, storeID, method); err != nil {
+		return nil, err
+	}
+	typesys, err := s.resolveTypesystem(ctx, storeID, modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	writes, err := whatif.TupleKeys(changes.Writes)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	for _, tk := range writes {
+		if err := validation.ValidateTupleForWrite(typesys, tk); err != nil {
+			return nil, serverErrors.ValidationError(err)
+		}
+	}
+	deletes := make([]*openfgav1.TupleKeyWithoutCondition, 0, len(changes.Deletes))
+	for _, t := range changes.Deletes {
+		tk := tuple.NewTupleKey(t.Object, t.Relation, t.User)
+		if err := validation.ValidateUserObjectRelation(typesys, tk); err != nil {
+			return nil, serverErrors.ValidationError(&tuple.InvalidTupleError{Cause: err, TupleKey: tk})
+		}
+		deletes = append(deletes, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+	}
+
+	return &whatIfEvaluator{
+		Server:  s,
+		storeID: storeID,
+		typesys: typesys,
+		changed: storagewrappers.NewHypotheticalTupleReader(s.datastore, writes, deletes),
+	}, nil
+}
+
+// evaluate evaluates check with and without the changes.
+func (e *whatIfEvaluator) evaluate(ctx context.Context, check *whatif.Check) (*whatif.CheckResult, error) {
+	checkContext, err := whatif.Context(check.Context)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid context: %s", err))
+	}
+	contextualTuples, err := whatif.TupleKeys(check.ContextualTuples)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	tk := tuple.NewCheckRequestTupleKey(check.Object, check.Relation, check.User)
+	if err := (&openfgav1.CheckRequest{StoreId: e.storeID, TupleKey: tk}).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	params := &commands.CheckCommandParams{
+		StoreID:          e.storeID,
+		TupleKey:         tk,
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: contextualTuples},
+		Context:          checkContext,
+		// the changes are previewed against the latest tuples of the store
+		Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+	}
+
+	currentlyAllowed, err := e.resolveUncachedCheck(ctx, e.datastore, e.typesys, params)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := e.resolveUncachedCheck(ctx, e.changed, e.typesys, params)
+	if err != nil {
+		return nil, err
+	}
+	return &whatif.CheckResult{
+		Allowed:          allowed,
+		CurrentlyAllowed: currentlyAllowed,
+		Changed:          allowed != currentlyAllowed,
+	}, nil
+}
+
+// resolveUncachedCheck resolves the Check of params with the tuples of ds and the model of
+// typesys. It doesn't use the caches of the server, which know neither hypothetical tuples nor
+// unwritten models.
+func (s *Server) resolveUncachedCheck(ctx context.Context, ds storage.RelationshipTupleReader, typesys *typesystem.TypeSystem, params *commands.CheckCommandParams) (bool, error) {
+	localChecker := graph.NewLocalChecker(
+		graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		graph.WithMaxResolutionDepth(s.resolveNodeLimit),
+		graph.WithUpstreamTimeout(s.requestTimeout),
+		graph.WithLocalCheckerLogger(s.logger),
+	)
+	defer localChecker.Close()
+
+	checkQuery := commands.NewCheckCommand(
+		ds,
+		localChecker,
+		typesys,
+		commands.WithCheckCommandLogger(s.logger),
+		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+	)
+	resp, _, err := checkQuery.Execute(ctx, params)
+	if err != nil {
+		return false, commands.CheckCommandErrorToServerError(err)
+	}
+	return resp.GetAllowed(), nil
+}
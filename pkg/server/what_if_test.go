@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/server/whatif"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestWhatIf(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds), WithWhatIfEnabled(true))
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "what-if"})
+	require.NoError(t, err)
+	storeID := store.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type document
+			relations
+				define viewer: [user, group#member]`)
+	writeModel, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+				tuple.NewTupleKey("group:eng", "member", "user:anne"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("revoke_group_membership", func(t *testing.T) {
+		res, err := s.WhatIfCheck(ctx, &whatif.CheckRequest{
+			StoreID: storeID,
+			Changes: whatif.Changes{
+				Deletes: []*whatif.Tuple{{Object: "group:eng", Relation: "member", User: "user:anne"}},
+			},
+			Check: whatif.Check{Object: "document:1", Relation: "viewer", User: "user:anne"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, writeModel.GetAuthorizationModelId(), res.AuthorizationModelID)
+		require.False(t, res.Allowed)
+		require.True(t, res.CurrentlyAllowed)
+		require.True(t, res.Changed)
+
+		// the changes are not applied
+		check, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, check.GetAllowed())
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		res, err := s.WhatIfBatchCheck(ctx, &whatif.BatchCheckRequest{
+			StoreID: storeID,
+			Changes: whatif.Changes{
+				Writes:  []*whatif.Tuple{{Object: "group:eng", Relation: "member", User: "user:bob"}},
+				Deletes: []*whatif.Tuple{{Object: "group:eng", Relation: "member", User: "user:anne"}},
+			},
+			Checks: []*whatif.BatchCheckItem{
+				{CorrelationID: "anne", Check: whatif.Check{Object: "document:1", Relation: "viewer", User: "user:anne"}},
+				{CorrelationID: "bob", Check: whatif.Check{Object: "document:1", Relation: "viewer", User: "user:bob"}},
+				{CorrelationID: "carl", Check: whatif.Check{Object: "document:1", Relation: "viewer", User: "user:carl"}},
+				{CorrelationID: "invalid", Check: whatif.Check{Object: "document:1", Relation: "owner", User: "user:carl"}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Results, 4)
+
+		require.Equal(t, "anne", res.Results[0].CorrelationID)
+		require.Equal(t, whatif.CheckResult{Allowed: false, CurrentlyAllowed: true, Changed: true}, res.Results[0].CheckResult)
+		require.Equal(t, whatif.CheckResult{Allowed: true, CurrentlyAllowed: false, Changed: true}, res.Results[1].CheckResult)
+		require.Equal(t, whatif.CheckResult{}, res.Results[2].CheckResult)
+		require.NotEmpty(t, res.Results[3].Error)
+	})
+
+	t.Run("invalid_change", func(t *testing.T) {
+		_, err := s.WhatIfCheck(ctx, &whatif.CheckRequest{
+			StoreID: storeID,
+			Changes: whatif.Changes{
+				Writes: []*whatif.Tuple{{Object: "group:eng", Relation: "owner", User: "user:bob"}},
+			},
+			Check: whatif.Check{Object: "document:1", Relation: "viewer", User: "user:anne"},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	t.Run("invalid_store_id", func(t *testing.T) {
+		_, err := s.WhatIfCheck(ctx, &whatif.CheckRequest{
+			StoreID: "invalid",
+			Check:   whatif.Check{Object: "document:1", Relation: "viewer", User: "user:anne"},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestWhatIfDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	_, err := s.WhatIfCheck(context.Background(), &whatif.CheckRequest{
+		StoreID: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		Check:   whatif.Check{Object: "document:1", Relation: "viewer", User: "user:anne"},
+	})
+	require.ErrorIs(t, err, ErrWhatIfDisabled)
+}
@@ -0,0 +1,14 @@
+// Package whatif evaluates Check and BatchCheck requests as if a set of tuple writes and deletes
+// had been applied to the store, to preview the effect of a change before making it, e.g. of the
+// revocation of a group membership.
+//
+// Unlike the contextual tuples, which can only add tuples, the changes can delete tuples of the
+// store and replace them, e.g. with another condition. The store is not changed. Each check is
+// evaluated both with and without the changes, so that the results report whether the change
+// would grant or revoke the relation.
+//
+// The checks are served by the what-if service, whose messages are encoded as JSON:
+// /openfga.whatif.v1.WhatIfService/Check and /openfga.whatif.v1.WhatIfService/BatchCheck. They
+// are also served over HTTP as POST /stores/{store_id}/what-if/check and POST
+// /stores/{store_id}/what-if/batch-check.
+package whatif
@@ -0,0 +1,118 @@
+package whatif
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the what-if service.
+	ServiceName = "openfga.whatif.v1.WhatIfService"
+
+	// CheckMethod is the full name of the method that evaluates a Check with changes.
+	CheckMethod = "/" + ServiceName + "/Check"
+
+	// BatchCheckMethod is the full name of the method that evaluates the Checks of a BatchCheck
+	// with changes.
+	BatchCheckMethod = "/" + ServiceName + "/BatchCheck"
+
+	// codecName is the content-subtype of the what-if requests. The what-if service is not part of
+	// the OpenFGA API, so its messages are encoded as JSON instead of generated protobufs.
+	codecName = "openfga-whatif-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// Server evaluates the checks with changes. It is implemented by the OpenFGA server.
+type Server interface {
+	WhatIfCheck(ctx context.Context, req *CheckRequest) (*CheckResponse, error)
+	WhatIfBatchCheck(ctx context.Context, req *BatchCheckRequest) (*BatchCheckResponse, error)
+}
+
+// RegisterServer registers the what-if service, which evaluates the checks with changes with srv,
+// on registrar.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Server) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Server)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Check",
+				Handler:    checkHandler,
+			},
+			{
+				MethodName: "BatchCheck",
+				Handler:    batchCheckHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/whatif/service.go",
+	}, srv)
+}
+
+func checkHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &CheckRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).WhatIfCheck(ctx, req.(*CheckRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: CheckMethod}, handler)
+}
+
+func batchCheckHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &BatchCheckRequest{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).WhatIfBatchCheck(ctx, req.(*BatchCheckRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: BatchCheckMethod}, handler)
+}
+
+// WhatIfCheck evaluates a Check with changes on conn.
+func WhatIfCheck(ctx context.Context, conn grpc.ClientConnInterface, req *CheckRequest) (*CheckResponse, error) {
+	out := &CheckResponse{}
+	if err := conn.Invoke(ctx, CheckMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WhatIfBatchCheck evaluates the Checks of a BatchCheck with changes on conn.
+func WhatIfBatchCheck(ctx context.Context, conn grpc.ClientConnInterface, req *BatchCheckRequest) (*BatchCheckResponse, error) {
+	out := &BatchCheckResponse{}
+	if err := conn.Invoke(ctx, BatchCheckMethod, req, out, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,122 @@
+package whatif
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// Tuple is a tuple of the changes or of the contextual tuples of a check.
+type Tuple struct {
+	Object    string     `json:"object"`
+	Relation  string     `json:"relation"`
+	User      string     `json:"user"`
+	Condition *Condition `json:"condition,omitempty"`
+}
+
+// Condition is the condition of a Tuple, with its context.
+type Condition struct {
+	Name    string         `json:"name"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+// Changes are the tuple writes and deletes that the checks are evaluated with.
+type Changes struct {
+	Writes  []*Tuple `json:"writes,omitempty"`
+	Deletes []*Tuple `json:"deletes,omitempty"`
+}
+
+// CheckRequest requests the Check of the relation of the object for the user, with a model of a
+// store or its latest model if AuthorizationModelID is empty, as if the changes had been applied.
+type CheckRequest struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	Changes
+	Check
+}
+
+// BatchCheckRequest requests the Checks of a BatchCheck with a model of a store, or its latest
+// model if AuthorizationModelID is empty, as if the changes had been applied.
+type BatchCheckRequest struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	Changes
+	Checks []*BatchCheckItem `json:"checks"`
+}
+
+// Check is the Check of the relation of the object for the user, with its context and contextual
+// tuples.
+type Check struct {
+	Object           string         `json:"object"`
+	Relation         string         `json:"relation"`
+	User             string         `json:"user"`
+	Context          map[string]any `json:"context,omitempty"`
+	ContextualTuples []*Tuple       `json:"contextual_tuples,omitempty"`
+}
+
+// BatchCheckItem is a Check of a BatchCheckRequest, identified by its CorrelationID.
+type BatchCheckItem struct {
+	CorrelationID string `json:"correlation_id"`
+	Check
+}
+
+// CheckResult is the result of a Check with and without the changes.
+type CheckResult struct {
+	// Allowed is the result of the Check with the changes.
+	Allowed bool `json:"allowed"`
+
+	// CurrentlyAllowed is the result of the Check without the changes.
+	CurrentlyAllowed bool `json:"currently_allowed"`
+
+	// Changed reports whether the changes would grant or revoke the relation, i.e. whether
+	// Allowed and CurrentlyAllowed differ.
+	Changed bool `json:"changed"`
+}
+
+// CheckResponse is the result of a CheckRequest.
+type CheckResponse struct {
+	AuthorizationModelID string `json:"authorization_model_id"`
+	CheckResult
+}
+
+// BatchCheckResponse is the result of a BatchCheckRequest, in the order of its checks.
+type BatchCheckResponse struct {
+	AuthorizationModelID string              `json:"authorization_model_id"`
+	Results              []*BatchCheckResult `json:"results"`
+}
+
+// BatchCheckResult is the result of a BatchCheckItem, or its error.
+type BatchCheckResult struct {
+	CorrelationID string `json:"correlation_id"`
+	CheckResult
+	Error string `json:"error,omitempty"`
+}
+
+// TupleKeys returns the tuple keys of tuples.
+func TupleKeys(tuples []*Tuple) ([]*openfgav1.TupleKey, error) {
+	tks := make([]*openfgav1.TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		if t.Condition == nil {
+			tks = append(tks, tuple.NewTupleKey(t.Object, t.Relation, t.User))
+			continue
+		}
+		conditionContext, err := Context(t.Condition.Context)
+		if err != nil {
+			return nil, fmt.Errorf("invalid context of the condition of tuple '%s': %w", tuple.TupleKeyToString(tuple.NewTupleKey(t.Object, t.Relation, t.User)), err)
+		}
+		tks = append(tks, tuple.NewTupleKeyWithCondition(t.Object, t.Relation, t.User, t.Condition.Name, conditionContext))
+	}
+	return tks, nil
+}
+
+// Context returns the context of a Check or of a condition, or nil if it is empty.
+func Context(values map[string]any) (*structpb.Struct, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return structpb.NewStruct(values)
+}
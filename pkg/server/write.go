@@ -15,8 +15,11 @@ import (
 	"github.com/openfga/openfga/internal/telemetry"
 	"github.com/openfga/openfga/internal/utils/apimethod"
 	"github.com/openfga/openfga/pkg/authclaims"
+	"github.com/openfga/openfga/pkg/middleware/priority"
 	"github.com/openfga/openfga/pkg/middleware/validator"
 	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/tuple"
 )
 
 func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openfgav1.WriteResponse, error) {
@@ -40,6 +43,14 @@ func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openf
 
 	storeID := req.GetStoreId()
 
+	if s.writeLimiter != nil {
+		tuples := len(req.GetWrites().GetTupleKeys())
+		if retryAfter, ok := s.writeLimiter.Allow(storeID, tuples, tuples+len(req.GetDeletes().GetTupleKeys())); !ok {
+			span.SetAttributes(attribute.Bool("throttled", true))
+			return nil, serverErrors.WriteThrottled(storeID, retryAfter)
+		}
+	}
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
@@ -51,9 +62,29 @@ func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openf
 		return nil, err
 	}
 
+	if s.writeValidationHook != nil {
+		err = s.validateWriteWithHook(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.writeScheduler != nil {
+		release, err := s.acquireWrite(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	var lastChangeID string
 	cmd := commands.NewWriteCommand(
 		s.datastore,
 		commands.WithWriteCmdLogger(s.logger),
+		commands.WithWriteCmdRegion(s.multiRegionID),
+		commands.WithWriteCmdOnLastChangeID(func(id string) {
+			lastChangeID = id
+		}),
 	)
 	resp, err := cmd.Execute(ctx, &openfgav1.WriteRequest{
 		StoreId:              storeID,
@@ -61,6 +92,18 @@ func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openf
 		Writes:               req.GetWrites(),
 		Deletes:              req.GetDeletes(),
 	})
+	if err == nil && s.hotPathIndex != nil {
+		s.hotPathIndex.Invalidate(storeID, writtenObjectTypes(req)...)
+	}
+	if err == nil && s.objectListsCache != nil {
+		s.objectListsCache.Invalidate(storeID, writtenObjectTypes(req)...)
+	}
+	if err == nil && s.cacheHints != nil {
+		s.cacheHints.ObserveTupleWrite(storeID, time.Now())
+	}
+	if err == nil && s.sessionTokensEnabled && lastChangeID != "" {
+		s.transport.SetHeader(ctx, SessionTokenHeader, newSessionToken(storeID, lastChangeID))
+	}
 
 	// For now, we only measure the duration if it passes the authz step to make the comparison
 	// apple to apple.
@@ -72,3 +115,31 @@ func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openf
 
 	return resp, err
 }
+
+// acquireWrite waits until the write scheduler lets req be sent to the datastore. Its cost is the
+// number of tuples it writes and deletes.
+func (s *Server) acquireWrite(ctx context.Context, req *openfgav1.WriteRequest) (func(), error) {
+	ctx, span := tracer.Start(ctx, "acquireWrite")
+	defer span.End()
+
+	changes := len(req.GetWrites().GetTupleKeys()) + len(req.GetDeletes().GetTupleKeys())
+	start := time.Now()
+	release, err := s.writeScheduler.AcquireN(ctx, priority.Interactive, req.GetStoreId(), float64(changes))
+	writeSchedulerQueueDurationHistogram.Observe(float64(time.Since(start).Milliseconds()))
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return release, nil
+}
+
+// writtenObjectTypes returns the types of the objects of the tuples written or deleted by req.
+func writtenObjectTypes(req *openfgav1.WriteRequest) []string {
+	var objectTypes []string
+	for _, tk := range req.GetWrites().GetTupleKeys() {
+		objectTypes = append(objectTypes, tuple.GetType(tk.GetObject()))
+	}
+	for _, tk := range req.GetDeletes().GetTupleKeys() {
+		objectTypes = append(objectTypes, tuple.GetType(tk.GetObject()))
+	}
+	return objectTypes
+}
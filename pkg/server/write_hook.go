@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/telemetry"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/writehook"
+)
+
+// The results of the write validation hook, as labeled by writeValidationHookCounter.
+const (
+	writeValidationAllowed      = "allowed"
+	writeValidationVetoed       = "vetoed"
+	writeValidationFailedOpen   = "failed_open"
+	writeValidationFailedClosed = "failed_closed"
+)
+
+// ErrWriteValidationHookFailed is returned by Write when the write validation hook fails and the
+// server does not fail open.
+var ErrWriteValidationHookFailed = status.Error(codes.Unavailable, "the write validation hook failed")
+
+// validateWriteWithHook submits req to the write validation hook, and returns an error if the
+// hook vetoes it, or if the hook fails and the server does not fail open. The annotations of the
+// allowed Writes are set on the response headers.
+func (s *Server) validateWriteWithHook(ctx context.Context, req *openfgav1.WriteRequest) error {
+	ctx, span := tracer.Start(ctx, "validateWriteWithHook")
+	defer span.End()
+
+	hookCtx, cancel := context.WithTimeout(ctx, s.writeValidationHookTimeout)
+	defer cancel()
+
+	res, err := s.writeValidationHook.Validate(hookCtx, writehook.NewRequest(req))
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if s.writeValidationHookFailOpen {
+			writeValidationHookCounter.WithLabelValues(writeValidationFailedOpen).Inc()
+			s.logger.WarnWithContext(ctx, "write validation hook failed, the write is applied", zap.Error(err))
+			return nil
+		}
+
+		writeValidationHookCounter.WithLabelValues(writeValidationFailedClosed).Inc()
+		s.logger.ErrorWithContext(ctx, "write validation hook failed, the write is rejected", zap.Error(err))
+		return ErrWriteValidationHookFailed
+	}
+
+	if !res.Allowed {
+		writeValidationHookCounter.WithLabelValues(writeValidationVetoed).Inc()
+		span.SetAttributes(attribute.String("write_validation.veto_reason", res.Reason))
+		return serverErrors.WriteVetoed(res.Reason)
+	}
+
+	writeValidationHookCounter.WithLabelValues(writeValidationAllowed).Inc()
+	if len(res.Annotations) > 0 {
+		annotations := writehook.FormatAnnotations(res.Annotations)
+		span.SetAttributes(attribute.String("write_validation.annotations", annotations))
+		s.transport.SetHeader(ctx, writehook.AnnotationsHeader, annotations)
+	}
+	return nil
+}
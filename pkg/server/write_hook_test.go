@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/server/writehook"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// fakeWriteHook vetoes the Writes of tuples of user:mallory, fails on the Writes of tuples of
+// user:broken, times out on the Writes of tuples of user:slow, and allows the others.
+type fakeWriteHook struct {
+	mu       sync.Mutex
+	requests []*writehook.Request
+	closed   bool
+}
+
+func (h *fakeWriteHook) Validate(ctx context.Context, req *writehook.Request) (*writehook.Response, error) {
+	h.mu.Lock()
+	h.requests = append(h.requests, req)
+	h.mu.Unlock()
+
+	for _, t := range req.Writes {
+		switch t.User {
+		case "user:mallory":
+			return &writehook.Response{Reason: "mallory cannot be granted access"}, nil
+		case "user:broken":
+			return nil, errors.New("unreachable")
+		case "user:slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}
+	return &writehook.Response{Allowed: true, Annotations: map[string]string{"ticket": "SEC-1"}}, nil
+}
+
+func (h *fakeWriteHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	return nil
+}
+
+func TestWriteValidationHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	newServer := func(t *testing.T, hook writehook.Hook, failOpen bool) (*Server, string) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		s := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithWriteValidationHook(hook, 50*time.Millisecond, failOpen),
+		)
+		t.Cleanup(s.Close)
+
+		store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "write-hook"})
+		require.NoError(t, err)
+
+		model := parser.MustTransformDSLToProto(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user]`)
+		_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         store.GetId(),
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return s, store.GetId()
+	}
+
+	write := func(s *Server, storeID, user string) error {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", user),
+			}},
+		})
+		return err
+	}
+
+	t.Run("fail_closed", func(t *testing.T) {
+		hook := &fakeWriteHook{}
+		s, storeID := newServer(t, hook, false)
+
+		require.NoError(t, write(s, storeID, "user:anne"))
+
+		err := write(s, storeID, "user:mallory")
+		require.Equal(t, serverErrors.ReasonWriteVetoed, serverErrors.ErrorReason(status.Convert(err)))
+		require.ErrorContains(t, err, "mallory cannot be granted access")
+
+		require.ErrorIs(t, write(s, storeID, "user:broken"), ErrWriteValidationHookFailed)
+		require.ErrorIs(t, write(s, storeID, "user:slow"), ErrWriteValidationHookFailed)
+
+		resp, err := s.Read(ctx, &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		require.Len(t, resp.GetTuples(), 1)
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		require.Len(t, hook.requests, 4)
+		require.Equal(t, storeID, hook.requests[0].StoreID)
+		require.NotEmpty(t, hook.requests[0].AuthorizationModelID)
+	})
+
+	t.Run("fail_open", func(t *testing.T) {
+		s, storeID := newServer(t, &fakeWriteHook{}, true)
+
+		err := write(s, storeID, "user:mallory")
+		require.Equal(t, serverErrors.ReasonWriteVetoed, serverErrors.ErrorReason(status.Convert(err)))
+
+		require.NoError(t, write(s, storeID, "user:broken"))
+		require.NoError(t, write(s, storeID, "user:slow"))
+
+		resp, err := s.Read(ctx, &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		require.Len(t, resp.GetTuples(), 2)
+	})
+
+	t.Run("closed_with_the_server", func(t *testing.T) {
+		hook := &fakeWriteHook{}
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		s := MustNewServerWithOpts(WithDatastore(ds), WithWriteValidationHook(hook, time.Second, false))
+		s.Close()
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		require.True(t, hook.closed)
+	})
+}
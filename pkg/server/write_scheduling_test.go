@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/middleware/priority"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestWriteScheduling(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithWriteScheduling(1, nil),
+	)
+	t.Cleanup(s.Close)
+
+	store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "write-scheduling"})
+	require.NoError(t, err)
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         store.GetId(),
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	write := func(ctx context.Context, user string) error {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: store.GetId(),
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", user),
+			}},
+		})
+		return err
+	}
+
+	t.Run("queued_writes_complete", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- write(ctx, fmt.Sprintf("user:%d", i))
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		resp, err := s.Read(ctx, &openfgav1.ReadRequest{StoreId: store.GetId()})
+		require.NoError(t, err)
+		require.Len(t, resp.GetTuples(), 10)
+	})
+
+	t.Run("queued_writes_stop_waiting_when_the_context_is_done", func(t *testing.T) {
+		release, err := s.writeScheduler.Acquire(ctx, priority.Interactive, store.GetId())
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		err = write(ctx, "user:anne")
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/internal/throttler"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestWriteThrottling(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithWriteThrottling(throttler.WriteRates{TuplesPerSecond: 2}, nil, nil),
+	)
+	t.Cleanup(s.Close)
+
+	newStore := func(t *testing.T) string {
+		store, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "write-throttling"})
+		require.NoError(t, err)
+
+		model := parser.MustTransformDSLToProto(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user]`)
+		_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         store.GetId(),
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return store.GetId()
+	}
+
+	write := func(storeID string, users ...string) error {
+		tupleKeys := make([]*openfgav1.TupleKey, 0, len(users))
+		for _, user := range users {
+			tupleKeys = append(tupleKeys, tuple.NewTupleKey("document:1", "viewer", user))
+		}
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: tupleKeys},
+		})
+		return err
+	}
+
+	storeID := newStore(t)
+	require.NoError(t, write(storeID, "user:anne", "user:bob"))
+
+	err := write(storeID, "user:charlie")
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Equal(t, serverErrors.ReasonWriteThrottled, serverErrors.ErrorReason(st))
+	retryAfter, ok := serverErrors.RetryDelay(st)
+	require.True(t, ok)
+	require.Positive(t, retryAfter)
+
+	// the other stores are not throttled
+	require.NoError(t, write(newStore(t), "user:anne", "user:bob"))
+}
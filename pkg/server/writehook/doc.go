@@ -0,0 +1,15 @@
+// Package writehook calls out to an external service that validates the Writes against business
+// rules that the type restrictions of the model cannot express.
+//
+// The server submits every authorized Write to the hook before it is applied. The hook responds
+// with a verdict that allows the Write, optionally annotated with key-value pairs returned to the
+// client, or vetoes it with a reason returned to the client in a WRITE_VETOED error.
+//
+// The hooks are called over HTTP, with a JSON POST of the Request to which the hook responds with
+// the Response as JSON, or over gRPC, with the Validate method of the
+// openfga.writehook.v1.WriteValidationService, whose messages are encoded as JSON too. The
+// servers of the gRPC hooks written in Go can register the service with RegisterServer.
+//
+// The server bounds the calls with a timeout. When a call fails, the Write fails too unless the
+// server is configured to fail open, in which case the Write is applied.
+package writehook
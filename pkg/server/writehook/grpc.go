@@ -0,0 +1,108 @@
+package writehook
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the name of the service that the gRPC hooks implement.
+	ServiceName = "openfga.writehook.v1.WriteValidationService"
+
+	// ValidateMethod is the full name of the method that the gRPC hooks implement.
+	ValidateMethod = "/" + ServiceName + "/Validate"
+
+	// codecName is the content-subtype of the requests to the gRPC hooks. The Request and the
+	// Response are encoded as JSON, like for the HTTP hooks, instead of generated protobufs.
+	codecName = "openfga-writehook-json"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// GRPCHook calls the Validate method of the WriteValidationService of a gRPC server.
+type GRPCHook struct {
+	conn *grpc.ClientConn
+}
+
+var _ Hook = (*GRPCHook)(nil)
+
+// NewGRPCHook returns the Hook that calls the gRPC server at addr, without TLS.
+func NewGRPCHook(addr string, opts ...grpc.DialOption) (*GRPCHook, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCHook{conn: conn}, nil
+}
+
+// Validate see [Hook].Validate.
+func (h *GRPCHook) Validate(ctx context.Context, req *Request) (*Response, error) {
+	res := &Response{}
+	if err := h.conn.Invoke(ctx, ValidateMethod, req, res, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Close see [Hook].Close.
+func (h *GRPCHook) Close() error {
+	return h.conn.Close()
+}
+
+// Validator is the server side of a gRPC hook.
+type Validator interface {
+	Validate(ctx context.Context, req *Request) (*Response, error)
+}
+
+// RegisterServer registers the WriteValidationService, which validates the Writes with srv, on
+// registrar. It is meant for the servers of the gRPC hooks written in Go.
+func RegisterServer(registrar grpc.ServiceRegistrar, srv Validator) {
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Validator)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Validate",
+				Handler:    validateHandler,
+			},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "pkg/server/writehook/grpc.go",
+	}, srv)
+}
+
+func validateHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := &Request{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Validator).Validate(ctx, req.(*Request))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: ValidateMethod}, handler)
+}
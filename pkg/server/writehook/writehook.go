@@ -0,0 +1,121 @@
+package writehook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// ErrInvalidURL is returned by New when the URL of the hook is neither an HTTP nor a gRPC URL.
+var ErrInvalidURL = errors.New("the URL of the write validation hook must have the http, https or grpc scheme")
+
+// Tuple is a tuple written or deleted by a Write.
+type Tuple struct {
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+	User      string `json:"user"`
+	Condition string `json:"condition,omitempty"`
+
+	// ConditionContext is the context of the condition of the written tuples, if any.
+	ConditionContext map[string]any `json:"condition_context,omitempty"`
+}
+
+// Request is the Write submitted to the hook, once it is authorized and its model is resolved.
+type Request struct {
+	StoreID              string  `json:"store_id"`
+	AuthorizationModelID string  `json:"authorization_model_id"`
+	Writes               []Tuple `json:"writes,omitempty"`
+	Deletes              []Tuple `json:"deletes,omitempty"`
+}
+
+// NewRequest returns the Request of req.
+func NewRequest(req *openfgav1.WriteRequest) *Request {
+	r := &Request{
+		StoreID:              req.GetStoreId(),
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+	}
+	for _, tk := range req.GetWrites().GetTupleKeys() {
+		t := Tuple{
+			Object:    tk.GetObject(),
+			Relation:  tk.GetRelation(),
+			User:      tk.GetUser(),
+			Condition: tk.GetCondition().GetName(),
+		}
+		if conditionContext := tk.GetCondition().GetContext(); conditionContext != nil {
+			t.ConditionContext = conditionContext.AsMap()
+		}
+		r.Writes = append(r.Writes, t)
+	}
+	for _, tk := range req.GetDeletes().GetTupleKeys() {
+		r.Deletes = append(r.Deletes, Tuple{
+			Object:   tk.GetObject(),
+			Relation: tk.GetRelation(),
+			User:     tk.GetUser(),
+		})
+	}
+	return r
+}
+
+// Response is the verdict of the hook on a Write.
+type Response struct {
+	// Allowed is false to veto the Write.
+	Allowed bool `json:"allowed"`
+
+	// Reason explains the veto. It is returned to the client in the error of the Write.
+	Reason string `json:"reason,omitempty"`
+
+	// Annotations are returned to the client in the AnnotationsHeader of the response of the Write
+	// if it is allowed, and recorded on its span.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AnnotationsHeader is the response header of the Writes annotated by the hook. Its value is the
+// comma-separated list of the key=value annotations, sorted by key.
+const AnnotationsHeader = "Openfga-Write-Annotations"
+
+// FormatAnnotations returns the value of the AnnotationsHeader of annotations.
+func FormatAnnotations(annotations map[string]string) string {
+	pairs := make([]string, 0, len(annotations))
+	for key, value := range annotations {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Hook validates the Writes against external business rules.
+type Hook interface {
+	// Validate returns the verdict of the hook on req, or an error if the hook could not decide,
+	// e.g. because it is unreachable. The errors are handled according to the fail mode of the
+	// server.
+	Validate(ctx context.Context, req *Request) (*Response, error)
+
+	// Close releases the resources of the hook.
+	Close() error
+}
+
+// New returns the Hook that calls out to rawURL: an HTTP hook for the http and https schemes, or
+// a gRPC hook for the grpc scheme, e.g. "grpc://validator:50051".
+func New(rawURL string) (Hook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return NewHTTPHook(rawURL), nil
+	case "grpc":
+		if u.Host == "" {
+			return nil, ErrInvalidURL
+		}
+		return NewGRPCHook(u.Host)
+	default:
+		return nil, ErrInvalidURL
+	}
+}
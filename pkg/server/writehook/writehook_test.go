@@ -0,0 +1,162 @@
+package writehook
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// vetoingValidator vetoes the Writes of tuples of user:mallory, and annotates the others.
+type vetoingValidator struct{}
+
+func (vetoingValidator) Validate(_ context.Context, req *Request) (*Response, error) {
+	for _, t := range req.Writes {
+		if t.User == "user:mallory" {
+			return &Response{Reason: "mallory cannot be granted access"}, nil
+		}
+	}
+	return &Response{Allowed: true, Annotations: map[string]string{"ticket": "SEC-1", "approver": "bob"}}, nil
+}
+
+func TestNewRequest(t *testing.T) {
+	conditionContext, err := structpb.NewStruct(map[string]any{"ip": "10.0.0.1"})
+	require.NoError(t, err)
+
+	req := NewRequest(&openfgav1.WriteRequest{
+		StoreId:              "store",
+		AuthorizationModelId: "model",
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "in_network", conditionContext),
+		}},
+		Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+			{Object: "document:2", Relation: "viewer", User: "user:anne"},
+		}},
+	})
+
+	require.Equal(t, &Request{
+		StoreID:              "store",
+		AuthorizationModelID: "model",
+		Writes: []Tuple{{
+			Object:           "document:1",
+			Relation:         "viewer",
+			User:             "user:anne",
+			Condition:        "in_network",
+			ConditionContext: map[string]any{"ip": "10.0.0.1"},
+		}},
+		Deletes: []Tuple{{Object: "document:2", Relation: "viewer", User: "user:anne"}},
+	}, req)
+}
+
+func TestFormatAnnotations(t *testing.T) {
+	require.Empty(t, FormatAnnotations(nil))
+	require.Equal(t, "approver=bob,ticket=SEC-1", FormatAnnotations(map[string]string{"ticket": "SEC-1", "approver": "bob"}))
+}
+
+func TestNew(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	hook, err := New("https://validator.example.com/validate")
+	require.NoError(t, err)
+	require.IsType(t, &HTTPHook{}, hook)
+	require.NoError(t, hook.Close())
+
+	hook, err = New("grpc://validator:50051")
+	require.NoError(t, err)
+	require.IsType(t, &GRPCHook{}, hook)
+	require.NoError(t, hook.Close())
+
+	for _, rawURL := range []string{"validator:50051", "ftp://validator", "grpc:///path", "://"} {
+		_, err = New(rawURL)
+		require.ErrorIs(t, err, ErrInvalidURL, rawURL)
+	}
+}
+
+func TestHTTPHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		res, _ := vetoingValidator{}.Validate(r.Context(), req)
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+
+	hook := NewHTTPHook(srv.URL)
+	t.Cleanup(func() {
+		_ = hook.Close()
+	})
+
+	res, err := hook.Validate(context.Background(), &Request{Writes: []Tuple{{Object: "document:1", Relation: "viewer", User: "user:anne"}}})
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, "SEC-1", res.Annotations["ticket"])
+
+	res, err = hook.Validate(context.Background(), &Request{Writes: []Tuple{{Object: "document:1", Relation: "viewer", User: "user:mallory"}}})
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, "mallory cannot be granted access", res.Reason)
+
+	broken := NewHTTPHook(srv.URL + "/broken")
+	t.Cleanup(func() {
+		_ = broken.Close()
+	})
+	_, err = broken.Validate(context.Background(), &Request{})
+	require.ErrorContains(t, err, "status 500")
+}
+
+func TestGRPCHook(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	RegisterServer(srv, vetoingValidator{})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	hook, err := NewGRPCHook("passthrough:///bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = hook.Close()
+	})
+
+	res, err := hook.Validate(context.Background(), &Request{Writes: []Tuple{{Object: "document:1", Relation: "viewer", User: "user:anne"}}})
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, map[string]string{"ticket": "SEC-1", "approver": "bob"}, res.Annotations)
+
+	res, err = hook.Validate(context.Background(), &Request{Writes: []Tuple{{Object: "document:1", Relation: "viewer", User: "user:mallory"}}})
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+}
@@ -5,17 +5,23 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"hash/maphash"
 	"io"
 	"math"
 	"math/rand"
+	"runtime"
 	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/Yiling-J/theine-go"
+	"github.com/cespare/xxhash/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -44,9 +50,15 @@ const (
 	iteratorCachePrefix        = "ic."
 	changelogCachePrefix       = "cc."
 	invalidIteratorCachePrefix = "iq."
+	listObjectsCachePrefix     = "lo."
 	defaultMaxCacheSize        = 10000
 	oneYear                    = time.Hour * 24 * 365
 
+	// defaultCacheItemSize is the size accounted for the items that do not implement SizedCacheItem.
+	defaultCacheItemSize = 64
+	// cacheEntryOverhead is the memory used by the cache for each entry, besides its key and value.
+	cacheEntryOverhead = 96
+
 	removedLabel     = "removed"
 	evictedLabel     = "evicted"
 	expiredLabel     = "expired"
@@ -57,12 +69,25 @@ type CacheItem interface {
 	CacheEntityType() string
 }
 
+// SizedCacheItem is a CacheItem that reports the memory it holds, so that caches limited by
+// WithMaxCacheBytes can account for it.
+type SizedCacheItem interface {
+	CacheItemSize() int64
+}
+
 // InMemoryCache is a general purpose cache to store things in memory.
+//
+// It is also the extension point of the check cache: a custom main can register an implementation
+// backed by an external cache with the caches package, and select it with the check cache engine.
+// Implementations must be safe for concurrent use.
 type InMemoryCache[T any] interface {
 	// Get If the key exists, returns the value. If the key didn't exist, returns nil.
 	Get(key string) T
+
+	// Set stores the value for the key until the ttl elapses. The cache may evict it earlier.
 	Set(key string, value T, ttl time.Duration)
 
+	// Delete removes the key, e.g. when the cache controller invalidates it.
 	Delete(key string)
 
 	// Stop cleans resources.
@@ -87,9 +112,16 @@ func NewNoopCache() *NoopCache {
 
 // Specific implementation
 
+// InMemoryLRUCache is an InMemoryCache that evicts the least recently and frequently used entries
+// once full. It is split in shards, selected by the hash of the keys, each with its own eviction
+// policy and lock, so that concurrent writers don't contend on a single lock.
 type InMemoryLRUCache[T any] struct {
-	client      *theine.Cache[string, T]
+	shards      []*theine.Cache[string, T]
+	shardSizes  []atomic.Int64
+	seed        maphash.Seed
 	maxElements int64
+	maxBytes    int64
+	shardCount  int
 	stopOnce    *sync.Once
 }
 
@@ -101,11 +133,30 @@ func WithMaxCacheSize[T any](maxElements int64) InMemoryLRUCacheOpt[T] {
 	}
 }
 
+// WithMaxCacheBytes limits the cache by the memory held by its entries instead of by their number.
+// The size of an entry is its key, plus the size reported by its value if it implements
+// SizedCacheItem, plus a fixed overhead. A value of 0 keeps the limit on the number of entries.
+func WithMaxCacheBytes[T any](maxBytes int64) InMemoryLRUCacheOpt[T] {
+	return func(i *InMemoryLRUCache[T]) {
+		i.maxBytes = maxBytes
+	}
+}
+
+// WithCacheShards splits the cache in shards, each holding an equal part of its limit. A value of
+// 0 creates one shard per CPU (see runtime.GOMAXPROCS). The default is a single shard.
+func WithCacheShards[T any](shards int) InMemoryLRUCacheOpt[T] {
+	return func(i *InMemoryLRUCache[T]) {
+		i.shardCount = shards
+	}
+}
+
 var _ InMemoryCache[any] = (*InMemoryLRUCache[any])(nil)
 
 func NewInMemoryLRUCache[T any](opts ...InMemoryLRUCacheOpt[T]) (*InMemoryLRUCache[T], error) {
 	t := &InMemoryLRUCache[T]{
+		seed:        maphash.MakeSeed(),
 		maxElements: defaultMaxCacheSize,
+		shardCount:  1,
 		stopOnce:    &sync.Once{},
 	}
 
@@ -113,7 +164,38 @@ func NewInMemoryLRUCache[T any](opts ...InMemoryLRUCacheOpt[T]) (*InMemoryLRUCac
 		opt(t)
 	}
 
-	cacheBuilder := theine.NewBuilder[string, T](t.maxElements)
+	if t.shardCount <= 0 {
+		t.shardCount = runtime.GOMAXPROCS(0)
+	}
+
+	maxCost := t.maxElements
+	if t.maxBytes > 0 {
+		maxCost = t.maxBytes
+	}
+	// every shard holds at least one entry, and the shards of a limit that doesn't divide evenly
+	// hold the remainder, up to one entry per shard
+	shardCount := int(max(min(int64(t.shardCount), maxCost), 1))
+	shardMaxCost := (maxCost + int64(shardCount) - 1) / int64(shardCount)
+
+	t.shards = make([]*theine.Cache[string, T], 0, shardCount)
+	t.shardSizes = make([]atomic.Int64, shardCount)
+	t.shardCount = shardCount
+	for range shardCount {
+		shard, err := newInMemoryLRUCacheShard[T](shardMaxCost)
+		if err != nil {
+			for _, shard := range t.shards {
+				shard.Close()
+			}
+			return nil, err
+		}
+		t.shards = append(t.shards, shard)
+	}
+
+	return t, nil
+}
+
+func newInMemoryLRUCacheShard[T any](maxCost int64) (*theine.Cache[string, T], error) {
+	cacheBuilder := theine.NewBuilder[string, T](maxCost)
 	cacheBuilder.RemovalListener(func(key string, value T, reason theine.RemoveReason) {
 		var (
 			reasonLabel string
@@ -139,18 +221,20 @@ func NewInMemoryLRUCache[T any](opts ...InMemoryLRUCacheOpt[T]) (*InMemoryLRUCac
 		cacheItemRemovedCount.WithLabelValues(entityLabel, reasonLabel).Inc()
 	})
 
-	var err error
-	t.client, err = cacheBuilder.Build()
-	if err != nil {
-		return nil, err
-	}
+	return cacheBuilder.Build()
+}
 
-	return t, nil
+// shard returns the index of the shard of key.
+func (i InMemoryLRUCache[T]) shard(key string) int {
+	if i.shardCount == 1 {
+		return 0
+	}
+	return int(maphash.String(i.seed, key) % uint64(i.shardCount))
 }
 
 func (i InMemoryLRUCache[T]) Get(key string) T {
 	var zero T
-	item, ok := i.client.Get(key)
+	item, ok := i.shards[i.shard(key)].Get(key)
 	if !ok {
 		return zero
 	}
@@ -170,12 +254,25 @@ func (i InMemoryLRUCache[T]) Set(key string, value T, ttl time.Duration) {
 		return
 	}
 
-	// Ignore the boolean return here as we always pass cost=1 and items are always admitted
-	i.client.SetWithTTL(key, value, 1, ttl)
+	n := i.shard(key)
+	shard := i.shards[n]
+	if i.maxBytes > 0 {
+		// the items larger than a whole shard are not admitted
+		shard.SetWithTTL(key, value, cacheEntrySize(key, value), ttl)
+	} else {
+		// Ignore the boolean return here as we always pass cost=1 and items are always admitted
+		shard.SetWithTTL(key, value, 1, ttl)
+	}
 
 	// Note: EstimatedSize is eventually consistent due to a shared lock in theine's maintenance routine.
-	// It shouldn't matter in practice, but it may lag behind a few entries.
-	cacheSizeFloat := float64(i.client.EstimatedSize())
+	// It shouldn't matter in practice, but it may lag behind a few entries. It locks the policy of
+	// the shard, so the sizes of the other shards are the ones recorded on their last Set.
+	i.shardSizes[n].Store(int64(shard.EstimatedSize()))
+	var size int64
+	for j := range i.shardSizes {
+		size += i.shardSizes[j].Load()
+	}
+	cacheSizeFloat := float64(size)
 	if item, ok := any(value).(CacheItem); ok {
 		cacheItemCount.WithLabelValues(item.CacheEntityType()).Set(cacheSizeFloat)
 	} else {
@@ -184,30 +281,51 @@ func (i InMemoryLRUCache[T]) Set(key string, value T, ttl time.Duration) {
 }
 
 func (i InMemoryLRUCache[T]) Delete(key string) {
-	i.client.Delete(key)
+	i.shards[i.shard(key)].Delete(key)
 }
 
 func (i InMemoryLRUCache[T]) Stop() {
 	i.stopOnce.Do(func() {
-		i.client.Close()
+		for _, shard := range i.shards {
+			shard.Close()
+		}
 	})
 }
 
+// cacheEntrySize returns the memory held by a cache entry, in bytes.
+func cacheEntrySize(key string, value any) int64 {
+	size := int64(cacheEntryOverhead + len(key))
+	if item, ok := value.(SizedCacheItem); ok {
+		return size + item.CacheItemSize()
+	}
+	return size + defaultCacheItemSize
+}
+
 var (
-	_ CacheItem = (*ChangelogCacheEntry)(nil)
-	_ CacheItem = (*InvalidEntityCacheEntry)(nil)
-	_ CacheItem = (*TupleIteratorCacheEntry)(nil)
+	_ CacheItem      = (*ChangelogCacheEntry)(nil)
+	_ CacheItem      = (*InvalidEntityCacheEntry)(nil)
+	_ CacheItem      = (*TupleIteratorCacheEntry)(nil)
+	_ CacheItem      = (*ListObjectsCacheEntry)(nil)
+	_ SizedCacheItem = (*ChangelogCacheEntry)(nil)
+	_ SizedCacheItem = (*InvalidEntityCacheEntry)(nil)
+	_ SizedCacheItem = (*TupleIteratorCacheEntry)(nil)
+	_ SizedCacheItem = (*ListObjectsCacheEntry)(nil)
 )
 
 type ChangelogCacheEntry struct {
 	LastModified time.Time // Last time the store was modified
 	LastChecked  time.Time // Last time the changelog was checked
+	LastChangeID string    // ULID of the most recent change read from the changelog, if any
 }
 
 func (c *ChangelogCacheEntry) CacheEntityType() string {
 	return "changelog"
 }
 
+func (c *ChangelogCacheEntry) CacheItemSize() int64 {
+	return int64(unsafe.Sizeof(*c)) + int64(len(c.LastChangeID))
+}
+
 func GetChangelogCacheKey(storeID string) string {
 	return changelogCachePrefix + storeID
 }
@@ -220,6 +338,10 @@ func (i *InvalidEntityCacheEntry) CacheEntityType() string {
 	return "invalid_entity"
 }
 
+func (i *InvalidEntityCacheEntry) CacheItemSize() int64 {
+	return int64(unsafe.Sizeof(*i))
+}
+
 func GetInvalidIteratorCacheKey(storeID string) string {
 	return invalidIteratorCachePrefix + storeID
 }
@@ -247,6 +369,24 @@ func (t *TupleIteratorCacheEntry) CacheEntityType() string {
 	return "tuple_iterator"
 }
 
+func (t *TupleIteratorCacheEntry) CacheItemSize() int64 {
+	size := int64(unsafe.Sizeof(*t))
+	for _, record := range t.Tuples {
+		size += int64(unsafe.Sizeof(*record)) + int64(len(record.Store)+len(record.ObjectType)+len(record.ObjectID)+
+			len(record.Relation)+len(record.User)+len(record.UserObjectType)+len(record.UserObjectID)+
+			len(record.UserRelation)+len(record.ConditionName)+len(record.Ulid)) + ConditionContextSize(record.ConditionContext)
+	}
+	return size
+}
+
+// ConditionContextSize approximates the memory held by a condition context with its encoded size.
+func ConditionContextSize(context *structpb.Struct) int64 {
+	if context == nil {
+		return 0
+	}
+	return int64(proto.Size(context))
+}
+
 func GetReadUsersetTuplesCacheKeyPrefix(store, object, relation string) string {
 	return iteratorCachePrefix + "rut/" + store + "/" + object + "#" + relation
 }
@@ -259,6 +399,62 @@ func GetReadCacheKey(store, tuple string) string {
 	return iteratorCachePrefix + "r/" + store + "/" + tuple
 }
 
+// ListObjectsCacheEntry is a cached ListObjects response. It is valid while LastModified is after
+// the last write to its store.
+type ListObjectsCacheEntry struct {
+	Objects      []string
+	LastModified time.Time
+}
+
+func (l *ListObjectsCacheEntry) CacheEntityType() string {
+	return "list_objects"
+}
+
+func (l *ListObjectsCacheEntry) CacheItemSize() int64 {
+	size := int64(unsafe.Sizeof(*l))
+	for _, object := range l.Objects {
+		size += int64(unsafe.Sizeof(object)) + int64(len(object))
+	}
+	return size
+}
+
+// ListObjectsCacheKeyParams is all the necessary pieces to create a unique-per-ListObjects cache
+// key.
+type ListObjectsCacheKeyParams struct {
+	StoreID              string
+	AuthorizationModelID string
+	ObjectType           string
+	Relation             string
+	User                 string
+	ContextualTuples     []*openfgav1.TupleKey
+	Context              *structpb.Struct
+
+	// ObjectIDPrefix is the prefix the IDs of the listed objects are filtered by, if any.
+	ObjectIDPrefix string
+}
+
+// GetListObjectsCacheKey returns the cache key of a ListObjects response. Like the Check cache
+// keys, the order of the contextual tuples and of the context parameters is ignored.
+func GetListObjectsCacheKey(params *ListObjectsCacheKeyParams) (string, error) {
+	d := getCacheKeyDigest()
+	defer cacheKeyDigests.Put(d)
+
+	err := WriteInvariantCheckCacheKey(d, &CheckCacheKeyParams{
+		StoreID:              params.StoreID,
+		AuthorizationModelID: params.AuthorizationModelID,
+		ContextualTuples:     params.ContextualTuples,
+		Context:              params.Context,
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, part := range []string{"/", params.ObjectType, "#", params.Relation, "@", params.User, "/", params.ObjectIDPrefix} {
+		// Digest.WriteString returns int and a nil error, ignoring
+		_, _ = d.WriteString(part)
+	}
+	return listObjectsCachePrefix + params.StoreID + "/" + strconv.FormatUint(d.Sum64(), 10), nil
+}
+
 // ErrUnexpectedStructValue is an error used to indicate that
 // an unexpected structpb.Value kind was encountered.
 var ErrUnexpectedStructValue = errors.New("unexpected structpb value encountered")
@@ -288,7 +484,11 @@ func writeValue(w io.StringWriter, v *structpb.Value) (err error) {
 		}
 
 		numBytes := len(val.StringValue)
-		_, err = w.WriteString(strconv.Itoa(numBytes) + ":")
+		_, err = w.WriteString(strconv.Itoa(numBytes))
+		if err != nil {
+			return
+		}
+		_, err = w.WriteString(":")
 		if err != nil {
 			return
 		}
@@ -385,13 +585,15 @@ func writeStruct(w io.StringWriter, s *structpb.Struct) (err error) {
 // in the tuple string representation. Returns an error only when
 // the underlying writer returns an error.
 func writeTuples(w io.StringWriter, tuples ...*openfgav1.TupleKey) (err error) {
-	sortedTuples := make(tuple.TupleKeys, len(tuples))
-
-	// copy tuples slice to avoid mutating the original slice during sorting.
-	copy(sortedTuples, tuples)
-
-	// sort tuples for a deterministic write
-	sort.Sort(sortedTuples)
+	sortedTuples := tuple.TupleKeys(tuples)
+	if !isSorted(sortedTuples) {
+		// copy tuples slice to avoid mutating the original slice during sorting.
+		sortedTuples = make(tuple.TupleKeys, len(tuples))
+		copy(sortedTuples, tuples)
+
+		// sort tuples for a deterministic write
+		sort.Sort(sortedTuples)
+	}
 
 	// prefix to avoid overlap with previous strings written
 	_, err = w.WriteString("/")
@@ -400,12 +602,7 @@ func writeTuples(w io.StringWriter, tuples ...*openfgav1.TupleKey) (err error) {
 	}
 
 	for n, tupleKey := range sortedTuples {
-		objectRelation := tupleKey.GetObject() + "#" + tupleKey.GetRelation()
-		if err = validateNoForbiddenChars(objectRelation); err != nil {
-			return
-		}
-		_, err = w.WriteString(objectRelation)
-		if err != nil {
+		if err = writeObjectRelation(w, tupleKey.GetObject(), tupleKey.GetRelation()); err != nil {
 			return
 		}
 
@@ -418,7 +615,11 @@ func writeTuples(w io.StringWriter, tuples ...*openfgav1.TupleKey) (err error) {
 			if err = validateNoForbiddenChars(condName); err != nil {
 				return
 			}
-			_, err = w.WriteString(" with " + condName)
+			_, err = w.WriteString(" with ")
+			if err != nil {
+				return
+			}
+			_, err = w.WriteString(condName)
 			if err != nil {
 				return
 			}
@@ -438,11 +639,7 @@ func writeTuples(w io.StringWriter, tuples ...*openfgav1.TupleKey) (err error) {
 			}
 		}
 
-		user := tupleKey.GetUser()
-		if err = validateNoForbiddenChars(user); err != nil {
-			return
-		}
-		if _, err = w.WriteString("@" + user); err != nil {
+		if err = writeUser(w, tupleKey.GetUser()); err != nil {
 			return
 		}
 
@@ -455,6 +652,47 @@ func writeTuples(w io.StringWriter, tuples ...*openfgav1.TupleKey) (err error) {
 	return
 }
 
+// isSorted reports whether tuples is sorted. Unlike sort.IsSorted, it doesn't allocate the
+// sort.Interface of tuples.
+func isSorted(tuples tuple.TupleKeys) bool {
+	for i := len(tuples) - 1; i > 0; i-- {
+		if tuples.Less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeObjectRelation writes object#relation to writer w.
+func writeObjectRelation(w io.StringWriter, object, relation string) error {
+	if err := validateNoForbiddenChars(object); err != nil {
+		return err
+	}
+	if err := validateNoForbiddenChars(relation); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(object); err != nil {
+		return err
+	}
+	if _, err := w.WriteString("#"); err != nil {
+		return err
+	}
+	_, err := w.WriteString(relation)
+	return err
+}
+
+// writeUser writes @user to writer w.
+func writeUser(w io.StringWriter, user string) error {
+	if err := validateNoForbiddenChars(user); err != nil {
+		return err
+	}
+	if _, err := w.WriteString("@"); err != nil {
+		return err
+	}
+	_, err := w.WriteString(user)
+	return err
+}
+
 // JitteredTTL returns a TTL with random jitter added. The jitter is a random duration
 // in the range [0, baseTTL * jitterPercentage / 100]. Values above 100 are treated as
 // 100. If jitterPercentage is 0 the base TTL is returned unchanged.
@@ -501,14 +739,12 @@ type CheckCacheKeyParams struct {
 // should produce the same cache key. Contextual tuple order and context parameter order is ignored,
 // only the contents are compared.
 func WriteCheckCacheKey(w io.StringWriter, params *CheckCacheKeyParams) error {
-	t := tuple.From(params.TupleKey)
-
-	tupleStr := t.String()
-	if err := validateNoForbiddenChars(tupleStr); err != nil {
+	// the tuple is written in parts rather than as tuple.String(), which would allocate it
+	err := writeObjectRelation(w, params.TupleKey.GetObject(), params.TupleKey.GetRelation())
+	if err != nil {
 		return err
 	}
-	_, err := w.WriteString(tupleStr)
-	if err != nil {
+	if err = writeUser(w, params.TupleKey.GetUser()); err != nil {
 		return err
 	}
 
@@ -541,3 +777,55 @@ func WriteInvariantCheckCacheKey(w io.StringWriter, params *CheckCacheKeyParams)
 
 	return nil
 }
+
+// cacheKeyDigests pools the digests that hash the cache keys, so that hashing a key doesn't
+// allocate.
+var cacheKeyDigests = sync.Pool{
+	New: func() any {
+		return xxhash.New()
+	},
+}
+
+// getCacheKeyDigest returns a reset digest of the pool. It is put back in the pool once the hash
+// is computed.
+func getCacheKeyDigest() *xxhash.Digest {
+	d := cacheKeyDigests.Get().(*xxhash.Digest)
+	d.Reset()
+	return d
+}
+
+// CheckCacheKeyHash returns the xxhash of the cache key written by WriteCheckCacheKey for params,
+// without allocating the key.
+func CheckCacheKeyHash(params *CheckCacheKeyParams) (uint64, error) {
+	d := getCacheKeyDigest()
+	defer cacheKeyDigests.Put(d)
+
+	if err := WriteCheckCacheKey(d, params); err != nil {
+		return 0, err
+	}
+	return d.Sum64(), nil
+}
+
+// InvariantCheckCacheKeyHash returns the xxhash of the cache key written by
+// WriteInvariantCheckCacheKey for params, without allocating the key.
+func InvariantCheckCacheKeyHash(params *CheckCacheKeyParams) (uint64, error) {
+	d := getCacheKeyDigest()
+	defer cacheKeyDigests.Put(d)
+
+	if err := WriteInvariantCheckCacheKey(d, params); err != nil {
+		return 0, err
+	}
+	return d.Sum64(), nil
+}
+
+// HashCacheKeyParts returns the xxhash of the concatenation of parts, without allocating it.
+func HashCacheKeyParts(parts ...string) uint64 {
+	d := getCacheKeyDigest()
+	defer cacheKeyDigests.Put(d)
+
+	for _, part := range parts {
+		// Digest.WriteString returns int and a nil error, ignoring
+		_, _ = d.WriteString(part)
+	}
+	return d.Sum64()
+}
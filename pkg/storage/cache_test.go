@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/oklog/ulid/v2"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
@@ -533,11 +535,11 @@ func TestInMemoryCache(t *testing.T) {
 
 		// This .Wait() is needed as cache client.EstimatedSize() is eventually consistent
 		// due it its use of a shared mutex with theine's maintenance routine
-		cache.client.Wait()
+		cache.shards[0].Wait()
 
 		cache.Set(k, "value2", time.Second)
 		before := testutil.ToFloat64(cacheItemCount.WithLabelValues(unspecifiedLabel))
-		cache.client.Wait()
+		cache.shards[0].Wait()
 
 		cache.Set(k, "value3", time.Second)
 		after := testutil.ToFloat64(cacheItemCount.WithLabelValues(unspecifiedLabel))
@@ -562,7 +564,7 @@ func TestInMemoryCache(t *testing.T) {
 			cache.Set(strconv.Itoa(i), "value"+strconv.Itoa(i), time.Second)
 		}
 		// Allow maintenance routine to finish
-		cache.client.Wait()
+		cache.shards[0].Wait()
 
 		// Set once more to ensure metric has caught up
 		cache.Set("10", "value10", time.Second)
@@ -575,7 +577,7 @@ func TestInMemoryCache(t *testing.T) {
 			cache.Delete(strconv.Itoa(i))
 		}
 		// Allow maintenance routine to finish
-		cache.client.Wait()
+		cache.shards[0].Wait()
 
 		// The cache item count is only updated on Set()
 		cache.Set("10", "value10", time.Second)
@@ -587,6 +589,71 @@ func TestInMemoryCache(t *testing.T) {
 		require.InDelta(t, 9, before-after, 2)
 	})
 
+	t.Run("max_bytes_evicts_by_size", func(t *testing.T) {
+		cache, err := NewInMemoryLRUCache(WithMaxCacheBytes[any](10 * 1024))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			goleak.VerifyNone(t)
+		})
+		defer cache.Stop()
+
+		small := &ChangelogCacheEntry{LastModified: time.Now()}
+		large := &TupleIteratorCacheEntry{}
+		for i := range 100 {
+			large.Tuples = append(large.Tuples, &TupleRecord{ObjectType: "document", ObjectID: strconv.Itoa(i), Relation: "viewer", User: "user:" + strconv.Itoa(i)})
+		}
+		require.Greater(t, large.CacheItemSize(), int64(10*1024))
+		require.Less(t, small.CacheItemSize(), large.CacheItemSize())
+
+		cache.Set("small", small, time.Minute)
+		cache.Set("large", large, time.Minute)
+		cache.shards[0].Wait()
+
+		require.Equal(t, small, cache.Get("small"))
+		require.Nil(t, cache.Get("large"), "items larger than the cache are not admitted")
+	})
+
+	t.Run("shards", func(t *testing.T) {
+		cache, err := NewInMemoryLRUCache(WithMaxCacheSize[string](1000), WithCacheShards[string](4))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			goleak.VerifyNone(t)
+		})
+		defer cache.Stop()
+		require.Len(t, cache.shards, 4)
+
+		for i := range 100 {
+			cache.Set(strconv.Itoa(i), "value"+strconv.Itoa(i), time.Minute)
+		}
+		for i := range 100 {
+			require.Equal(t, "value"+strconv.Itoa(i), cache.Get(strconv.Itoa(i)))
+		}
+
+		cache.Delete("1")
+		require.Empty(t, cache.Get("1"))
+		require.Equal(t, "value2", cache.Get("2"))
+	})
+
+	t.Run("shards_per_cpu", func(t *testing.T) {
+		cache, err := NewInMemoryLRUCache(WithCacheShards[string](0))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			goleak.VerifyNone(t)
+		})
+		defer cache.Stop()
+		require.Len(t, cache.shards, runtime.GOMAXPROCS(0))
+	})
+
+	t.Run("shards_bounded_by_limit", func(t *testing.T) {
+		cache, err := NewInMemoryLRUCache(WithMaxCacheSize[string](2), WithCacheShards[string](8))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			goleak.VerifyNone(t)
+		})
+		defer cache.Stop()
+		require.Len(t, cache.shards, 2)
+	})
+
 	t.Run("stop_multiple_times", func(t *testing.T) {
 		cache, err := NewInMemoryLRUCache[string]()
 		require.NoError(t, err)
@@ -1173,6 +1240,59 @@ func BenchmarkInvariantCacheKeyWithContext(b *testing.B) {
 	}
 }
 
+func TestCheckCacheKeyHash(t *testing.T) {
+	contextStruct, err := structpb.NewStruct(map[string]interface{}{
+		"stringKey": "hello",
+		"listKey":   []interface{}{"item1", 2},
+	})
+	require.NoError(t, err)
+
+	params := &CheckCacheKeyParams{
+		StoreID:              ulid.Make().String(),
+		AuthorizationModelID: ulid.Make().String(),
+		TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		ContextualTuples: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:y", "viewer", "user:y"),
+			tuple.NewTupleKeyWithCondition("document:x", "viewer", "user:x", "cond", contextStruct),
+		},
+		Context: contextStruct,
+	}
+
+	hash, err := CheckCacheKeyHash(params)
+	require.NoError(t, err)
+	require.Equal(t, xxhash.Sum64String(MustGetCheckCacheKey(params)), hash)
+
+	invariantKey := &strings.Builder{}
+	require.NoError(t, WriteInvariantCheckCacheKey(invariantKey, params))
+	hash, err = InvariantCheckCacheKeyHash(params)
+	require.NoError(t, err)
+	require.Equal(t, xxhash.Sum64String(invariantKey.String()), hash)
+
+	require.Equal(t, xxhash.Sum64String("document:1#viewer@user:anne"), HashCacheKeyParts("document:1", "#", "viewer", "@", "user:anne"))
+
+	params.TupleKey = tuple.NewTupleKey("document:1", "viewer", "user:\x00")
+	_, err = CheckCacheKeyHash(params)
+	require.Error(t, err)
+}
+
+func BenchmarkCheckCacheKeyHash(b *testing.B) {
+	params := &CheckCacheKeyParams{
+		AuthorizationModelID: ulid.Make().String(),
+		StoreID:              ulid.Make().String(),
+		TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+		ContextualTuples: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:x", "viewer", "user:x"),
+			tuple.NewTupleKey("document:y", "viewer", "user:y"),
+		},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err := CheckCacheKeyHash(params)
+		require.NoError(b, err)
+	}
+}
+
 func BenchmarkGetInvalidIteratorByUserObjectTypeCacheKeys(b *testing.B) {
 	storeID := "abc123"
 	objectType := "document"
@@ -1210,6 +1330,51 @@ func BenchmarkGetInvalidIteratorByUserObjectTypeCacheKeys(b *testing.B) {
 	}
 }
 
+func TestGetListObjectsCacheKey(t *testing.T) {
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	params := func() *ListObjectsCacheKeyParams {
+		return &ListObjectsCacheKeyParams{
+			StoreID:              storeID,
+			AuthorizationModelID: modelID,
+			ObjectType:           "document",
+			Relation:             "viewer",
+			User:                 "user:jon",
+		}
+	}
+	key, err := GetListObjectsCacheKey(params())
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, listObjectsCachePrefix+storeID+"/"))
+
+	same, err := GetListObjectsCacheKey(params())
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	contextStruct, err := structpb.NewStruct(map[string]interface{}{"key1": true})
+	require.NoError(t, err)
+
+	for name, modify := range map[string]func(p *ListObjectsCacheKeyParams){
+		"model":    func(p *ListObjectsCacheKeyParams) { p.AuthorizationModelID = ulid.Make().String() },
+		"type":     func(p *ListObjectsCacheKeyParams) { p.ObjectType = "folder" },
+		"relation": func(p *ListObjectsCacheKeyParams) { p.Relation = "editor" },
+		"user":     func(p *ListObjectsCacheKeyParams) { p.User = "user:anne" },
+		"prefix":   func(p *ListObjectsCacheKeyParams) { p.ObjectIDPrefix = "1" },
+		"context":  func(p *ListObjectsCacheKeyParams) { p.Context = contextStruct },
+		"contextual_tuples": func(p *ListObjectsCacheKeyParams) {
+			p.ContextualTuples = []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:jon")}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := params()
+			modify(p)
+			other, err := GetListObjectsCacheKey(p)
+			require.NoError(t, err)
+			require.NotEqual(t, key, other)
+		})
+	}
+}
+
 func TestJitteredTTL(t *testing.T) {
 	tests := []struct {
 		name             string
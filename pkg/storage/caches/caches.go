@@ -0,0 +1,81 @@
+package caches
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// builtins are the cache engines served by OpenFGA itself, which cannot be registered.
+var builtins = []string{"memory"}
+
+// NewFunc constructs the check cache with the check cache settings of the configuration of the
+// server in cfg, e.g. its size limit. The cache must be safe for concurrent use, and its Get must
+// return nil for the keys it does not hold. The server stops it when it shuts down.
+type NewFunc func(cfg *config.CheckCacheConfig) (storage.InMemoryCache[any], error)
+
+// Cache is a third-party check cache.
+type Cache struct {
+	// New constructs the cache. It is required.
+	New NewFunc
+}
+
+var (
+	mu     sync.RWMutex
+	caches = make(map[string]Cache)
+)
+
+// Register makes the cache available as the check cache engine name. It is meant to be called
+// from the init function of the cache package, or from a custom main before the run command is
+// executed, e.g.
+//
+//	func main() {
+//		caches.Register("groupcache", caches.Cache{New: groupcache.New})
+//
+//		rootCmd := cmd.NewRootCommand()
+//		rootCmd.AddCommand(run.NewRunCommand())
+//		...
+//	}
+//
+// It panics if the name is the one of a built-in engine or of a cache already registered, or if
+// the cache has no New function.
+func Register(name string, cache Cache) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cache.New == nil {
+		panic(fmt.Sprintf("caches: Register of '%s' without a New function", name))
+	}
+	if slices.Contains(builtins, name) {
+		panic(fmt.Sprintf("caches: Register of the built-in engine '%s'", name))
+	}
+	if _, ok := caches[name]; ok {
+		panic(fmt.Sprintf("caches: Register called twice for cache '%s'", name))
+	}
+	caches[name] = cache
+}
+
+// Lookup returns the cache registered as name, if any.
+func Lookup(name string) (Cache, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	cache, ok := caches[name]
+	return cache, ok
+}
+
+// Names returns the sorted names of the registered caches.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(caches))
+	for name := range caches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
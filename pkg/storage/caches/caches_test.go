@@ -0,0 +1,67 @@
+package caches
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func newLRU(cfg *config.CheckCacheConfig) (storage.InMemoryCache[any], error) {
+	return storage.NewInMemoryLRUCache(storage.WithMaxCacheSize[any](int64(cfg.Limit)))
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("lookup_registered_cache", func(t *testing.T) {
+		Register("test-registered", Cache{New: newLRU})
+
+		cache, ok := Lookup("test-registered")
+		require.True(t, ok)
+
+		c, err := cache.New(&config.CheckCacheConfig{Limit: 10})
+		require.NoError(t, err)
+		t.Cleanup(c.Stop)
+
+		c.Set("key", "value", time.Minute)
+		require.Equal(t, "value", c.Get("key"))
+		require.Contains(t, Names(), "test-registered")
+	})
+
+	t.Run("lookup_unknown_cache", func(t *testing.T) {
+		_, ok := Lookup("test-unknown")
+		require.False(t, ok)
+	})
+
+	t.Run("register_twice_panics", func(t *testing.T) {
+		Register("test-twice", Cache{New: newLRU})
+		require.Panics(t, func() {
+			Register("test-twice", Cache{New: newLRU})
+		})
+	})
+
+	t.Run("register_builtin_panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			Register("memory", Cache{New: newLRU})
+		})
+	})
+
+	t.Run("register_without_new_panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			Register("test-without-new", Cache{})
+		})
+		_, ok := Lookup("test-without-new")
+		require.False(t, ok)
+	})
+}
+
+func TestNames(t *testing.T) {
+	Register("test-names-b", Cache{New: newLRU})
+	Register("test-names-a", Cache{New: newLRU})
+
+	names := Names()
+	require.IsNonDecreasing(t, names)
+	require.Subset(t, names, []string{"test-names-a", "test-names-b"})
+}
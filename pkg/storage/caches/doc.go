@@ -0,0 +1,4 @@
+// Package caches contains the registry of the third-party check caches, which a custom main
+// registers so that the run command caches the Check subproblems and iterators in them, e.g. in a
+// cache shared by the servers of a deployment, without forking OpenFGA.
+package caches
@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+const (
+	defaultDiskCacheQueueSize     = 4096
+	defaultDiskCacheSweepInterval = time.Minute
+	diskCacheMaxBatchSize         = 256
+)
+
+var (
+	diskCacheBucket = []byte("cache")
+
+	diskCacheHitCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "disk_cache_hit_count",
+		Help:      "The total number of cache misses in memory that were served from the disk-backed cache.",
+	}, []string{"entity"})
+
+	diskCacheDroppedWriteCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "disk_cache_dropped_write_count",
+		Help:      "The total number of writes to the disk-backed cache dropped because the write queue was full.",
+	})
+)
+
+// PersistentCacheItem is a CacheItem that can be stored by a DiskBackedCache.
+type PersistentCacheItem interface {
+	CacheItem
+	MarshalBinary() ([]byte, error)
+}
+
+// CacheItemDecoder decodes a PersistentCacheItem from the bytes returned by its MarshalBinary.
+type CacheItemDecoder func(data []byte) (any, error)
+
+// DiskBackedCacheOpt defines an option that can be used to change the behavior of a DiskBackedCache.
+type DiskBackedCacheOpt func(*DiskBackedCache)
+
+// WithCacheItemDecoder makes the DiskBackedCache persist the items of the entity type, and decode
+// them with decode. The items of the other entity types are only kept in memory.
+func WithCacheItemDecoder(entityType string, decode CacheItemDecoder) DiskBackedCacheOpt {
+	return func(d *DiskBackedCache) {
+		d.decoders[entityType] = decode
+	}
+}
+
+// WithDiskCacheSweepInterval sets the time between two deletions of the expired items from the disk.
+func WithDiskCacheSweepInterval(interval time.Duration) DiskBackedCacheOpt {
+	return func(d *DiskBackedCache) {
+		d.sweepInterval = interval
+	}
+}
+
+// WithDiskCacheLogger sets the logger of the DiskBackedCache.
+func WithDiskCacheLogger(l logger.Logger) DiskBackedCacheOpt {
+	return func(d *DiskBackedCache) {
+		d.logger = l
+	}
+}
+
+type diskCacheOp struct {
+	key   string
+	value []byte // nil deletes the key
+}
+
+// DiskBackedCache is an InMemoryCache layered over a file, so that its items survive a restart of
+// the process. Items are written to the file in the background, and read from it when they are
+// missing in memory, e.g. after a restart.
+type DiskBackedCache struct {
+	memory        InMemoryCache[any]
+	db            *bolt.DB
+	decoders      map[string]CacheItemDecoder
+	sweepInterval time.Duration
+	logger        logger.Logger
+
+	ops      chan diskCacheOp
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+var _ InMemoryCache[any] = (*DiskBackedCache)(nil)
+
+// NewDiskBackedCache returns a cache that keeps its items in memory, and persists the items of the
+// entity types with a decoder to the file at path. The file is created if it does not exist.
+func NewDiskBackedCache(memory InMemoryCache[any], path string, opts ...DiskBackedCacheOpt) (*DiskBackedCache, error) {
+	d := &DiskBackedCache{
+		memory:        memory,
+		decoders:      make(map[string]CacheItemDecoder),
+		sweepInterval: defaultDiskCacheSweepInterval,
+		logger:        logger.NewNoopLogger(),
+		ops:           make(chan diskCacheOp, defaultDiskCacheQueueSize),
+		stop:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	var err error
+	d.db, err = bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the disk cache '%s': %w", path, err)
+	}
+
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(diskCacheBucket)
+		return err
+	})
+	if err == nil {
+		err = d.sweep()
+	}
+	if err != nil {
+		d.db.Close()
+		return nil, fmt.Errorf("failed to initialize the disk cache '%s': %w", path, err)
+	}
+
+	d.wg.Add(1)
+	go d.run()
+
+	return d, nil
+}
+
+// Get returns the item from memory or, if it is missing in memory, from the disk.
+func (d *DiskBackedCache) Get(key string) any {
+	if value := d.memory.Get(key); value != nil {
+		return value
+	}
+
+	var (
+		value     any
+		expiresAt time.Time
+	)
+	_ = d.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(diskCacheBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		entityType, payload, exp, err := decodeDiskCacheRecord(data)
+		if err != nil || !time.Now().Before(exp) {
+			return nil
+		}
+		decode, ok := d.decoders[entityType]
+		if !ok {
+			return nil
+		}
+		// the payload is only valid during the transaction, so it is decoded here
+		value, err = decode(payload)
+		if err != nil {
+			value = nil
+			return nil
+		}
+		expiresAt = exp
+		diskCacheHitCount.WithLabelValues(entityType).Inc()
+		return nil
+	})
+
+	if value != nil {
+		d.memory.Set(key, value, time.Until(expiresAt))
+	}
+	return value
+}
+
+// Set stores the item in memory, and queues it to be written to the disk if its entity type has a decoder.
+func (d *DiskBackedCache) Set(key string, value any, ttl time.Duration) {
+	d.memory.Set(key, value, ttl)
+
+	item, ok := value.(PersistentCacheItem)
+	if !ok || ttl <= 0 {
+		return
+	}
+	if _, ok := d.decoders[item.CacheEntityType()]; !ok {
+		return
+	}
+	payload, err := item.MarshalBinary()
+	if err != nil {
+		return
+	}
+	d.enqueue(diskCacheOp{key: key, value: encodeDiskCacheRecord(item.CacheEntityType(), payload, time.Now().Add(min(ttl, oneYear)))})
+}
+
+// Delete removes the item from memory and from the disk.
+func (d *DiskBackedCache) Delete(key string) {
+	d.memory.Delete(key)
+	d.enqueue(diskCacheOp{key: key})
+}
+
+// Stop writes the queued items to the disk, closes the file and stops the in-memory cache.
+func (d *DiskBackedCache) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		d.wg.Wait()
+		if err := d.db.Close(); err != nil {
+			d.logger.Error("failed to close the disk cache", zap.Error(err))
+		}
+		d.memory.Stop()
+	})
+}
+
+func (d *DiskBackedCache) enqueue(op diskCacheOp) {
+	select {
+	case <-d.stop:
+	case d.ops <- op:
+	default:
+		diskCacheDroppedWriteCount.Inc()
+	}
+}
+
+func (d *DiskBackedCache) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.sweepInterval)
+	defer ticker.Stop()
+
+	batch := make([]diskCacheOp, 0, diskCacheMaxBatchSize)
+	for {
+		select {
+		case op := <-d.ops:
+			batch = append(batch[:0], op)
+			// write the operations queued meanwhile in the same transaction
+		collect:
+			for len(batch) < diskCacheMaxBatchSize {
+				select {
+				case op := <-d.ops:
+					batch = append(batch, op)
+				default:
+					break collect
+				}
+			}
+			d.write(batch)
+		case <-ticker.C:
+			if err := d.sweep(); err != nil {
+				d.logger.Error("failed to delete the expired items of the disk cache", zap.Error(err))
+			}
+		case <-d.stop:
+			batch = batch[:0]
+		drain:
+			for {
+				select {
+				case op := <-d.ops:
+					batch = append(batch, op)
+				default:
+					break drain
+				}
+			}
+			d.write(batch)
+			return
+		}
+	}
+}
+
+func (d *DiskBackedCache) write(batch []diskCacheOp) {
+	if len(batch) == 0 {
+		return
+	}
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(diskCacheBucket)
+		for _, op := range batch {
+			var err error
+			if op.value == nil {
+				err = bucket.Delete([]byte(op.key))
+			} else {
+				err = bucket.Put([]byte(op.key), op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		d.logger.Error("failed to write to the disk cache", zap.Error(err))
+	}
+}
+
+// sweep deletes the expired and unreadable items from the disk.
+func (d *DiskBackedCache) sweep() error {
+	now := time.Now()
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(diskCacheBucket)
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			if _, _, expiresAt, err := decodeDiskCacheRecord(v); err != nil || !now.Before(expiresAt) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// the keys cannot be deleted while iterating over the bucket
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var errInvalidDiskCacheRecord = errors.New("invalid disk cache record")
+
+// encodeDiskCacheRecord encodes an item as its expiration time, the length of its entity type,
+// its entity type and its payload.
+func encodeDiskCacheRecord(entityType string, payload []byte, expiresAt time.Time) []byte {
+	record := make([]byte, 0, 9+len(entityType)+len(payload))
+	record = binary.BigEndian.AppendUint64(record, uint64(expiresAt.UnixNano()))
+	record = append(record, byte(len(entityType)))
+	record = append(record, entityType...)
+	return append(record, payload...)
+}
+
+func decodeDiskCacheRecord(record []byte) (string, []byte, time.Time, error) {
+	if len(record) < 9 || len(record) < 9+int(record[8]) {
+		return "", nil, time.Time{}, errInvalidDiskCacheRecord
+	}
+	expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(record[:8])))
+	entityTypeEnd := 9 + int(record[8])
+	return string(record[9:entityTypeEnd]), record[entityTypeEnd:], expiresAt, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type persistentTestItem struct {
+	Value string
+}
+
+func (p *persistentTestItem) CacheEntityType() string {
+	return "persistent_test_item"
+}
+
+func (p *persistentTestItem) MarshalBinary() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func decodePersistentTestItem(data []byte) (any, error) {
+	item := &persistentTestItem{}
+	return item, json.Unmarshal(data, item)
+}
+
+func TestDiskBackedCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+
+	open := func(t *testing.T) *DiskBackedCache {
+		t.Helper()
+		memory, err := NewInMemoryLRUCache[any]()
+		require.NoError(t, err)
+		cache, err := NewDiskBackedCache(memory, path, WithCacheItemDecoder("persistent_test_item", decodePersistentTestItem))
+		require.NoError(t, err)
+		return cache
+	}
+
+	cache := open(t)
+	cache.Set("kept", &persistentTestItem{Value: "kept"}, time.Hour)
+	cache.Set("deleted", &persistentTestItem{Value: "deleted"}, time.Hour)
+	cache.Set("expired", &persistentTestItem{Value: "expired"}, time.Millisecond)
+	cache.Set("memory_only", &ChangelogCacheEntry{LastModified: time.Now()}, time.Hour)
+	cache.Delete("deleted")
+	require.NotNil(t, cache.Get("memory_only"))
+	cache.Stop()
+
+	time.Sleep(2 * time.Millisecond)
+
+	cache = open(t)
+	t.Cleanup(cache.Stop)
+	require.Equal(t, &persistentTestItem{Value: "kept"}, cache.Get("kept"))
+	require.Nil(t, cache.Get("deleted"))
+	require.Nil(t, cache.Get("expired"))
+	require.Nil(t, cache.Get("memory_only"))
+}
+
+func TestDiskCacheRecord(t *testing.T) {
+	expiresAt := time.Unix(0, time.Now().UnixNano())
+	entityType, payload, gotExpiresAt, err := decodeDiskCacheRecord(encodeDiskCacheRecord("check_response", []byte("payload"), expiresAt))
+	require.NoError(t, err)
+	require.Equal(t, "check_response", entityType)
+	require.Equal(t, []byte("payload"), payload)
+	require.True(t, expiresAt.Equal(gotExpiresAt))
+
+	_, _, _, err = decodeDiskCacheRecord([]byte{0, 0, 0, 0, 0, 0, 0, 0, 10, 'a'})
+	require.ErrorIs(t, err, errInvalidDiskCacheRecord)
+}
@@ -0,0 +1,3 @@
+// Package drivers contains the registry of the third-party storage drivers, which a custom main
+// registers so that the run command serves them without forking OpenFGA.
+package drivers
@@ -0,0 +1,85 @@
+package drivers
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+// builtins are the engines served by OpenFGA itself, which cannot be registered.
+var builtins = []string{"memory", "mysql", "postgres", "sqlite"}
+
+// OpenFunc opens the datastore at uri, with the datastore settings of the configuration of the
+// server in cfg, e.g. its credentials, its connection pool and its logger.
+type OpenFunc func(uri string, cfg *sqlcommon.Config) (storage.OpenFGADatastore, error)
+
+// Driver is a third-party storage driver.
+type Driver struct {
+	// Open opens the datastore. It is required.
+	Open OpenFunc
+
+	// ContinuationTokenSerializer serializes the continuation tokens of the datastore. If it is
+	// nil, the continuation tokens are serialized like the ones of the SQL datastores.
+	ContinuationTokenSerializer encoder.ContinuationTokenSerializer
+}
+
+var (
+	mu      sync.RWMutex
+	drivers = make(map[string]Driver)
+)
+
+// Register makes the driver available as the datastore engine name. It is meant to be called from
+// the init function of the driver package, or from a custom main before the run command is
+// executed, e.g.
+//
+//	func main() {
+//		drivers.Register("spanner", drivers.Driver{Open: spanner.New})
+//
+//		rootCmd := cmd.NewRootCommand()
+//		rootCmd.AddCommand(run.NewRunCommand())
+//		...
+//	}
+//
+// It panics if the name is the one of a built-in engine or of a driver already registered, or if
+// the driver has no Open function.
+func Register(name string, driver Driver) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if driver.Open == nil {
+		panic(fmt.Sprintf("drivers: Register of '%s' without an Open function", name))
+	}
+	if slices.Contains(builtins, name) {
+		panic(fmt.Sprintf("drivers: Register of the built-in engine '%s'", name))
+	}
+	if _, ok := drivers[name]; ok {
+		panic(fmt.Sprintf("drivers: Register called twice for driver '%s'", name))
+	}
+	drivers[name] = driver
+}
+
+// Lookup returns the driver registered as name, if any.
+func Lookup(name string) (Driver, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	driver, ok := drivers[name]
+	return driver, ok
+}
+
+// Names returns the sorted names of the registered drivers.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
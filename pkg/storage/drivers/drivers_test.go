@@ -0,0 +1,65 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+func openMemory(string, *sqlcommon.Config) (storage.OpenFGADatastore, error) {
+	return memory.New(), nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("lookup_registered_driver", func(t *testing.T) {
+		Register("test-registered", Driver{Open: openMemory})
+
+		driver, ok := Lookup("test-registered")
+		require.True(t, ok)
+
+		ds, err := driver.Open("uri", sqlcommon.NewConfig())
+		require.NoError(t, err)
+		t.Cleanup(ds.Close)
+		require.Nil(t, driver.ContinuationTokenSerializer)
+		require.Contains(t, Names(), "test-registered")
+	})
+
+	t.Run("lookup_unknown_driver", func(t *testing.T) {
+		_, ok := Lookup("test-unknown")
+		require.False(t, ok)
+	})
+
+	t.Run("register_twice_panics", func(t *testing.T) {
+		Register("test-twice", Driver{Open: openMemory})
+		require.Panics(t, func() {
+			Register("test-twice", Driver{Open: openMemory})
+		})
+	})
+
+	t.Run("register_builtin_panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			Register("postgres", Driver{Open: openMemory})
+		})
+	})
+
+	t.Run("register_without_open_panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			Register("test-without-open", Driver{})
+		})
+		_, ok := Lookup("test-without-open")
+		require.False(t, ok)
+	})
+}
+
+func TestNames(t *testing.T) {
+	Register("test-names-b", Driver{Open: openMemory})
+	Register("test-names-a", Driver{Open: openMemory})
+
+	names := Names()
+	require.IsNonDecreasing(t, names)
+	require.Subset(t, names, []string{"test-names-a", "test-names-b"})
+}
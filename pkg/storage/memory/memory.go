@@ -132,6 +132,10 @@ type MemoryBackend struct {
 	tuples      map[string][]*storage.TupleRecord // GUARDED_BY(mutexTuples).
 	mutexTuples sync.RWMutex
 
+	// reverse user index, nil if disabled
+	// map: store => user => set of tuples
+	userTuples map[string]map[string][]*storage.TupleRecord // GUARDED_BY(mutexTuples).
+
 	// ChangelogBackend
 	// map: store => set of changes
 	changes map[string][]*tupleChangeRec // GUARDED_BY(mutexTuples).
@@ -148,11 +152,55 @@ type MemoryBackend struct {
 	// map: store id | authz model id => assertions
 	assertions      map[string][]*openfgav1.Assertion // GUARDED_BY(mutexAssertions).
 	mutexAssertions sync.RWMutex
+
+	// JobBackend
+	// map: store id => job id => job
+	jobs      map[string]map[string]*storage.Job // GUARDED_BY(mutexJobs).
+	mutexJobs sync.RWMutex
+
+	// ScheduleBackend
+	// map: store id => schedule id => schedule
+	schedules map[string]map[string]*storage.Schedule // GUARDED_BY(mutexSchedules).
+	// map: lease name => lease
+	leases         map[string]lease // GUARDED_BY(mutexSchedules).
+	mutexSchedules sync.RWMutex
+
+	// MaterializedPermissionBackend
+	// map: store id | object type | relation => materialized relation
+	materializedRelations map[string]*materializedRelation // GUARDED_BY(mutexMaterialized).
+	mutexMaterialized     sync.RWMutex
+}
+
+type materializedRelation struct {
+	state storage.MaterializedRelation
+	// map: object id => user => struct{}
+	permissions map[string]map[string]struct{}
+}
+
+func materializedRelationKey(store, objectType, relation string) string {
+	return store + "|" + objectType + "|" + relation
+}
+
+type lease struct {
+	holder    string
+	expiresAt time.Time
 }
 
 // Ensures that [MemoryBackend] implements the [storage.OpenFGADatastore] interface.
 var _ storage.OpenFGADatastore = (*MemoryBackend)(nil)
 
+// Ensures that [MemoryBackend] implements the [storage.ChangelogPruner] interface.
+var _ storage.ChangelogPruner = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.JobBackend] interface.
+var _ storage.JobBackend = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.ScheduleBackend] interface.
+var _ storage.ScheduleBackend = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.MaterializedPermissionBackend] interface.
+var _ storage.MaterializedPermissionBackend = (*MemoryBackend)(nil)
+
 // AuthorizationModelEntry represents an entry in a storage system
 // that holds information about an authorization model.
 type AuthorizationModelEntry struct {
@@ -170,6 +218,10 @@ func New(opts ...StorageOption) storage.OpenFGADatastore {
 		authorizationModels:           make(map[string]map[string]*AuthorizationModelEntry),
 		stores:                        make(map[string]*openfgav1.Store, 0),
 		assertions:                    make(map[string][]*openfgav1.Assertion, 0),
+		jobs:                          make(map[string]map[string]*storage.Job),
+		schedules:                     make(map[string]map[string]*storage.Schedule),
+		materializedRelations:         make(map[string]*materializedRelation),
+		leases:                        make(map[string]lease),
 	}
 
 	for _, opt := range opts {
@@ -193,6 +245,39 @@ func WithMaxTypesPerAuthorizationModel(n int) StorageOption {
 	return func(ds *MemoryBackend) { ds.maxTypesPerAuthorizationModel = n }
 }
 
+// WithReverseUserIndex returns a [StorageOption] that maintains a reverse index of the tuples by
+// user on write, so that ReadStartingWithUser looks up the tuples of the users instead of scanning
+// the tuples of the store.
+func WithReverseUserIndex() StorageOption {
+	return func(ds *MemoryBackend) { ds.userTuples = make(map[string]map[string][]*storage.TupleRecord) }
+}
+
+// indexUser adds a tuple to the reverse user index, if enabled.
+func (s *MemoryBackend) indexUser(tr *storage.TupleRecord) {
+	if s.userTuples == nil {
+		return
+	}
+	if s.userTuples[tr.Store] == nil {
+		s.userTuples[tr.Store] = make(map[string][]*storage.TupleRecord)
+	}
+	s.userTuples[tr.Store][tr.User] = append(s.userTuples[tr.Store][tr.User], tr)
+}
+
+// unindexUser removes a tuple from the reverse user index, if enabled.
+func (s *MemoryBackend) unindexUser(tr *storage.TupleRecord) {
+	if s.userTuples == nil {
+		return
+	}
+	records := slices.DeleteFunc(s.userTuples[tr.Store][tr.User], func(r *storage.TupleRecord) bool {
+		return r == tr
+	})
+	if len(records) == 0 {
+		delete(s.userTuples[tr.Store], tr.User)
+		return
+	}
+	s.userTuples[tr.Store][tr.User] = records
+}
+
 // Close does not do anything for [MemoryBackend].
 func (s *MemoryBackend) Close() {}
 
@@ -285,6 +370,33 @@ func (s *MemoryBackend) ReadChanges(ctx context.Context, store string, filter st
 	return res, last.String(), nil
 }
 
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *MemoryBackend) PruneChanges(ctx context.Context, store string, options storage.PruneChangesOptions) (int64, error) {
+	_, span := tracer.Start(ctx, "memory.PruneChanges")
+	defer span.End()
+
+	s.mutexTuples.Lock()
+	defer s.mutexTuples.Unlock()
+
+	changes := s.changes[store]
+
+	var nthLatest string
+	if options.KeepLatest > 0 && len(changes) >= int(options.KeepLatest) {
+		nthLatest = changes[len(changes)-int(options.KeepLatest)].Ulid.String()
+	}
+
+	cutoff := storage.PruneChangesCutoff(options, nthLatest)
+	if cutoff == "" {
+		return 0, nil
+	}
+
+	i := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Ulid.String() >= cutoff
+	})
+	s.changes[store] = slices.Clone(changes[i:])
+	return int64(i), nil
+}
+
 // read returns an iterator of a store's tuples with a given tuple as filter.
 // A nil paginationOptions input means the returned iterator will iterate through all values.
 func (s *MemoryBackend) read(ctx context.Context, store string, filter storage.ReadFilter, options *storage.ReadPageOptions) (*staticIterator, error) {
@@ -295,7 +407,7 @@ func (s *MemoryBackend) read(ctx context.Context, store string, filter storage.R
 	defer s.mutexTuples.RUnlock()
 
 	var matches []*storage.TupleRecord
-	if filter.Object == "" && filter.Relation == "" && filter.User == "" {
+	if filter.Object == "" && filter.Relation == "" && filter.User == "" && len(filter.Conditions) == 0 {
 		matches = make([]*storage.TupleRecord, len(s.tuples[store]))
 		copy(matches, s.tuples[store])
 	} else {
@@ -310,26 +422,31 @@ func (s *MemoryBackend) read(ctx context.Context, store string, filter storage.R
 		}
 	}
 
-	var err error
-	var from int
-	if options != nil && options.Pagination.From != "" {
-		from, err = strconv.Atoi(options.Pagination.From)
-		if err != nil {
-			telemetry.TraceError(span, err)
-			return nil, err
-		}
+	if options == nil {
+		return &staticIterator{records: matches}, nil
 	}
 
-	if from <= len(matches) {
+	// Pages are returned in the order of the ulids of the tuples, across all types, and the
+	// continuation token is the ulid of the first tuple of the next page, like in the SQL
+	// datastores, so that the tuples that are deleted between pages don't shift the next pages.
+	slices.SortStableFunc(matches, func(a, b *storage.TupleRecord) int {
+		return strings.Compare(a.Ulid, b.Ulid)
+	})
+
+	if options.Pagination.From != "" {
+		if _, err := ulid.Parse(options.Pagination.From); err != nil {
+			telemetry.TraceError(span, err)
+			return nil, storage.ErrInvalidContinuationToken
+		}
+		from, _ := slices.BinarySearchFunc(matches, options.Pagination.From, func(t *storage.TupleRecord, from string) int {
+			return strings.Compare(t.Ulid, from)
+		})
 		matches = matches[from:]
 	}
 
-	to := 0 // fetch everything
-	if options != nil {
-		to = options.Pagination.PageSize
-	}
+	to := options.Pagination.PageSize
 	if to != 0 && to < len(matches) {
-		return &staticIterator{records: matches[:to], continuationToken: strconv.Itoa(from + to)}, nil
+		return &staticIterator{records: matches[:to], continuationToken: matches[to].Ulid}, nil
 	}
 
 	return &staticIterator{records: matches}, nil
@@ -350,13 +467,15 @@ func (s *MemoryBackend) Write(ctx context.Context, store string, deletes storage
 
 	now := timestamppb.Now()
 
-	duplicateDeletes, _, err := sanitizeTuplesWriteDelete(s.tuples[store], deletes, writes, storage.NewTupleWriteOptions(opts...))
+	writeOpts := storage.NewTupleWriteOptions(opts...)
+	duplicateDeletes, _, err := sanitizeTuplesWriteDelete(s.tuples[store], deletes, writes, writeOpts)
 	if err != nil {
 		return err
 	}
 
 	var records []*storage.TupleRecord
-	entropy := ulid.DefaultEntropy()
+	entropy := storage.NewChangeEntropy(writeOpts.Region)
+	changes := len(s.changes[store])
 Delete:
 	for _, tr := range s.tuples[store] {
 		t := tr.AsTuple()
@@ -378,6 +497,7 @@ Delete:
 						Ulid: ulid.MustNew(ulid.Timestamp(now.AsTime()), entropy),
 					},
 				)
+				s.unindexUser(tr)
 				continue Delete
 			}
 		}
@@ -386,8 +506,22 @@ Delete:
 
 Write:
 	for _, t := range writes {
-		for _, et := range records {
+		for i, et := range records {
 			if match(et, t) {
+				if writeOpts.OnDuplicateInsert == storage.OnDuplicateInsertOverwrite && !sameCondition(et, t) {
+					// the last writer wins
+					records = slices.Delete(records, i, i+1)
+					s.unindexUser(et)
+					s.changes[store] = append(s.changes[store], &tupleChangeRec{
+						Change: &openfgav1.TupleChange{
+							TupleKey:  tupleUtils.NewTupleKey(t.GetObject(), t.GetRelation(), t.GetUser()), // Redact the condition info.
+							Operation: openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+							Timestamp: now,
+						},
+						Ulid: ulid.MustNew(ulid.Timestamp(now.AsTime()), entropy),
+					})
+					break
+				}
 				// notice we don't need to assert for duplicateWrites because the fact that we match,
 				// and it satisfies sanitizeTuplesWriteDelete means that it is a valid duplicate write.
 				continue Write
@@ -403,7 +537,7 @@ Write:
 
 		objectType, objectID := tupleUtils.SplitObject(t.GetObject())
 
-		records = append(records, &storage.TupleRecord{
+		record := &storage.TupleRecord{
 			Store:            store,
 			ObjectType:       objectType,
 			ObjectID:         objectID,
@@ -411,9 +545,11 @@ Write:
 			User:             t.GetUser(),
 			ConditionName:    conditionName,
 			ConditionContext: conditionContext,
-			Ulid:             ulid.MustNew(ulid.Timestamp(now.AsTime()), ulid.DefaultEntropy()).String(),
+			Ulid:             ulid.MustNew(ulid.Timestamp(now.AsTime()), entropy).String(),
 			InsertedAt:       now.AsTime(),
-		})
+		}
+		records = append(records, record)
+		s.indexUser(record)
 
 		tk := tupleUtils.NewTupleKeyWithCondition(
 			tupleUtils.BuildObject(objectType, objectID),
@@ -433,6 +569,9 @@ Write:
 		})
 	}
 	s.tuples[store] = records
+	if writeOpts.OnLastChangeID != nil && len(s.changes[store]) > changes {
+		writeOpts.OnLastChangeID(s.changes[store][len(s.changes[store])-1].Ulid.String())
+	}
 	return nil
 }
 
@@ -456,13 +595,19 @@ func sanitizeTuplesWriteDelete(
 	for i, tk := range writes {
 		record := find(records, tk)
 		if record != nil {
-			if opts.OnDuplicateInsert == storage.OnDuplicateInsertIgnore {
+			switch opts.OnDuplicateInsert {
+			case storage.OnDuplicateInsertIgnore:
 				// need to validate against condition and context
-				if record.ConditionName == tk.GetCondition().GetName() && record.ConditionContext.String() == tk.GetCondition().GetContext().String() {
+				if sameCondition(record, tk) {
 					duplicateWrites = append(duplicateWrites, i)
 					continue
 				}
 				return nil, nil, storage.TupleConditionConflictError(tk)
+			case storage.OnDuplicateInsertOverwrite:
+				if sameCondition(record, tk) {
+					duplicateWrites = append(duplicateWrites, i)
+				}
+				continue
 			}
 			return nil, nil, storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
 		}
@@ -470,6 +615,11 @@ func sanitizeTuplesWriteDelete(
 	return duplicateDeletes, duplicateWrites, nil
 }
 
+// sameCondition returns true if the record has the same condition and context as the tuple key.
+func sameCondition(record *storage.TupleRecord, tk *openfgav1.TupleKey) bool {
+	return record.ConditionName == tk.GetCondition().GetName() && record.ConditionContext.String() == tk.GetCondition().GetContext().String()
+}
+
 // find returns tuple if *storage.TupleRecord [*storage.TupleRecord] returns true.
 // Return nil otherwise.
 func find(records []*storage.TupleRecord, tupleKey *openfgav1.TupleKey) *storage.TupleRecord {
@@ -559,30 +709,35 @@ func (s *MemoryBackend) ReadStartingWithUser(
 	defer s.mutexTuples.RUnlock()
 
 	var matches []*storage.TupleRecord
-	for _, t := range s.tuples[store] {
-		if t.ObjectType != filter.ObjectType {
-			continue
+	for _, userFilter := range filter.UserFilter {
+		targetUser := userFilter.GetObject()
+		if userFilter.GetRelation() != "" {
+			targetUser = tupleUtils.GetObjectRelationAsString(userFilter)
 		}
 
-		if t.Relation != filter.Relation {
-			continue
+		candidates := s.tuples[store]
+		if s.userTuples != nil {
+			candidates = s.userTuples[store][targetUser]
 		}
 
-		if filter.ObjectIDs != nil && !filter.ObjectIDs.Exists(t.ObjectID) {
-			continue
-		}
+		for _, t := range candidates {
+			if targetUser != t.User {
+				continue
+			}
 
-		if len(filter.Conditions) > 0 && !slices.Contains(filter.Conditions, t.ConditionName) {
-			continue
-		}
+			if t.ObjectType != filter.ObjectType {
+				continue
+			}
 
-		for _, userFilter := range filter.UserFilter {
-			targetUser := userFilter.GetObject()
-			if userFilter.GetRelation() != "" {
-				targetUser = tupleUtils.GetObjectRelationAsString(userFilter)
+			if t.Relation != filter.Relation {
+				continue
 			}
 
-			if targetUser != t.User {
+			if filter.ObjectIDs != nil && !filter.ObjectIDs.Exists(t.ObjectID) {
+				continue
+			}
+
+			if len(filter.Conditions) > 0 && !slices.Contains(filter.Conditions, t.ConditionName) {
 				continue
 			}
 
@@ -803,6 +958,307 @@ func (s *MemoryBackend) ReadAssertions(ctx context.Context, store, modelID strin
 	return assertions, nil
 }
 
+// WriteJob see [storage.JobBackend].WriteJob.
+func (s *MemoryBackend) WriteJob(ctx context.Context, job *storage.Job) error {
+	_, span := tracer.Start(ctx, "memory.WriteJob")
+	defer span.End()
+
+	s.mutexJobs.Lock()
+	defer s.mutexJobs.Unlock()
+
+	jobs, ok := s.jobs[job.Store]
+	if !ok {
+		jobs = map[string]*storage.Job{}
+		s.jobs[job.Store] = jobs
+	}
+
+	written := *job
+	written.Payload = slices.Clone(job.Payload)
+	written.CancelRequested = false
+	if existing, ok := jobs[job.ID]; ok {
+		written.CancelRequested = existing.CancelRequested
+	}
+	written.UpdatedAt = time.Now().UTC()
+	jobs[job.ID] = &written
+	return nil
+}
+
+// ReadJob see [storage.JobBackend].ReadJob.
+func (s *MemoryBackend) ReadJob(ctx context.Context, store, id string) (*storage.Job, error) {
+	_, span := tracer.Start(ctx, "memory.ReadJob")
+	defer span.End()
+
+	s.mutexJobs.RLock()
+	defer s.mutexJobs.RUnlock()
+
+	job, ok := s.jobs[store][id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	read := *job
+	read.Payload = slices.Clone(job.Payload)
+	return &read, nil
+}
+
+// ListJobs see [storage.JobBackend].ListJobs.
+func (s *MemoryBackend) ListJobs(ctx context.Context, store string) ([]*storage.Job, error) {
+	_, span := tracer.Start(ctx, "memory.ListJobs")
+	defer span.End()
+
+	s.mutexJobs.RLock()
+	defer s.mutexJobs.RUnlock()
+
+	jobs := make([]*storage.Job, 0, len(s.jobs[store]))
+	for _, job := range s.jobs[store] {
+		read := *job
+		read.Payload = slices.Clone(job.Payload)
+		jobs = append(jobs, &read)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// CancelJob see [storage.JobBackend].CancelJob.
+func (s *MemoryBackend) CancelJob(ctx context.Context, store, id string) error {
+	_, span := tracer.Start(ctx, "memory.CancelJob")
+	defer span.End()
+
+	s.mutexJobs.Lock()
+	defer s.mutexJobs.Unlock()
+
+	job, ok := s.jobs[store][id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	job.CancelRequested = true
+	job.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// DeleteJobs see [storage.JobBackend].DeleteJobs.
+func (s *MemoryBackend) DeleteJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	_, span := tracer.Start(ctx, "memory.DeleteJobs")
+	defer span.End()
+
+	s.mutexJobs.Lock()
+	defer s.mutexJobs.Unlock()
+
+	var deleted int64
+	for store, jobs := range s.jobs {
+		for id, job := range jobs {
+			if job.Done && job.UpdatedAt.Before(updatedBefore) {
+				delete(jobs, id)
+				deleted++
+			}
+		}
+		if len(jobs) == 0 {
+			delete(s.jobs, store)
+		}
+	}
+	return deleted, nil
+}
+
+// WriteSchedule see [storage.ScheduleBackend].WriteSchedule.
+func (s *MemoryBackend) WriteSchedule(ctx context.Context, schedule *storage.Schedule) error {
+	_, span := tracer.Start(ctx, "memory.WriteSchedule")
+	defer span.End()
+
+	s.mutexSchedules.Lock()
+	defer s.mutexSchedules.Unlock()
+
+	schedules, ok := s.schedules[schedule.Store]
+	if !ok {
+		schedules = map[string]*storage.Schedule{}
+		s.schedules[schedule.Store] = schedules
+	}
+
+	written := *schedule
+	written.Payload = slices.Clone(schedule.Payload)
+	written.LastRunAt, written.LastJobID = time.Time{}, ""
+	if existing, ok := schedules[schedule.ID]; ok {
+		written.LastRunAt, written.LastJobID = existing.LastRunAt, existing.LastJobID
+	}
+	schedules[schedule.ID] = &written
+	return nil
+}
+
+// ReadSchedule see [storage.ScheduleBackend].ReadSchedule.
+func (s *MemoryBackend) ReadSchedule(ctx context.Context, store, id string) (*storage.Schedule, error) {
+	_, span := tracer.Start(ctx, "memory.ReadSchedule")
+	defer span.End()
+
+	s.mutexSchedules.RLock()
+	defer s.mutexSchedules.RUnlock()
+
+	schedule, ok := s.schedules[store][id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	read := *schedule
+	read.Payload = slices.Clone(schedule.Payload)
+	return &read, nil
+}
+
+// ListSchedules see [storage.ScheduleBackend].ListSchedules.
+func (s *MemoryBackend) ListSchedules(ctx context.Context, store string) ([]*storage.Schedule, error) {
+	_, span := tracer.Start(ctx, "memory.ListSchedules")
+	defer span.End()
+
+	s.mutexSchedules.RLock()
+	defer s.mutexSchedules.RUnlock()
+
+	var schedules []*storage.Schedule
+	for scheduleStore, storeSchedules := range s.schedules {
+		if store != "" && scheduleStore != store {
+			continue
+		}
+		for _, schedule := range storeSchedules {
+			read := *schedule
+			read.Payload = slices.Clone(schedule.Payload)
+			schedules = append(schedules, &read)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Store != schedules[j].Store {
+			return schedules[i].Store < schedules[j].Store
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+	return schedules, nil
+}
+
+// DeleteSchedule see [storage.ScheduleBackend].DeleteSchedule.
+func (s *MemoryBackend) DeleteSchedule(ctx context.Context, store, id string) error {
+	_, span := tracer.Start(ctx, "memory.DeleteSchedule")
+	defer span.End()
+
+	s.mutexSchedules.Lock()
+	defer s.mutexSchedules.Unlock()
+
+	if _, ok := s.schedules[store][id]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.schedules[store], id)
+	if len(s.schedules[store]) == 0 {
+		delete(s.schedules, store)
+	}
+	return nil
+}
+
+// RecordScheduleRun see [storage.ScheduleBackend].RecordScheduleRun.
+func (s *MemoryBackend) RecordScheduleRun(ctx context.Context, store, id string, runAt time.Time, jobID string) error {
+	_, span := tracer.Start(ctx, "memory.RecordScheduleRun")
+	defer span.End()
+
+	s.mutexSchedules.Lock()
+	defer s.mutexSchedules.Unlock()
+
+	schedule, ok := s.schedules[store][id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	schedule.LastRunAt = runAt.UTC()
+	schedule.LastJobID = jobID
+	return nil
+}
+
+// AcquireLease see [storage.ScheduleBackend].AcquireLease.
+func (s *MemoryBackend) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	_, span := tracer.Start(ctx, "memory.AcquireLease")
+	defer span.End()
+
+	s.mutexSchedules.Lock()
+	defer s.mutexSchedules.Unlock()
+
+	now := time.Now()
+	if existing, ok := s.leases[name]; ok && existing.holder != holder && existing.expiresAt.After(now) {
+		return false, nil
+	}
+	s.leases[name] = lease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// ReadMaterializedRelation see [storage.MaterializedPermissionBackend].ReadMaterializedRelation.
+func (s *MemoryBackend) ReadMaterializedRelation(ctx context.Context, store, objectType, relation string) (*storage.MaterializedRelation, error) {
+	_, span := tracer.Start(ctx, "memory.ReadMaterializedRelation")
+	defer span.End()
+
+	s.mutexMaterialized.RLock()
+	defer s.mutexMaterialized.RUnlock()
+
+	materialized, ok := s.materializedRelations[materializedRelationKey(store, objectType, relation)]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	state := materialized.state
+	return &state, nil
+}
+
+// WriteMaterializedPermissions see [storage.MaterializedPermissionBackend].WriteMaterializedPermissions.
+func (s *MemoryBackend) WriteMaterializedPermissions(ctx context.Context, relation *storage.MaterializedRelation, update storage.MaterializedPermissionsUpdate) error {
+	_, span := tracer.Start(ctx, "memory.WriteMaterializedPermissions")
+	defer span.End()
+
+	s.mutexMaterialized.Lock()
+	defer s.mutexMaterialized.Unlock()
+
+	key := materializedRelationKey(relation.Store, relation.ObjectType, relation.Relation)
+	materialized, ok := s.materializedRelations[key]
+	if !ok || update.Reset {
+		materialized = &materializedRelation{permissions: make(map[string]map[string]struct{})}
+		s.materializedRelations[key] = materialized
+	}
+
+	materialized.state = *relation
+	materialized.state.UpdatedAt = relation.UpdatedAt.UTC()
+	for _, objectID := range update.ObjectIDs {
+		delete(materialized.permissions, objectID)
+	}
+	for _, permission := range update.Permissions {
+		users, ok := materialized.permissions[permission.ObjectID]
+		if !ok {
+			users = make(map[string]struct{})
+			materialized.permissions[permission.ObjectID] = users
+		}
+		users[permission.User] = struct{}{}
+	}
+	return nil
+}
+
+// ReadMaterializedPermission see [storage.MaterializedPermissionBackend].ReadMaterializedPermission.
+func (s *MemoryBackend) ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error) {
+	_, span := tracer.Start(ctx, "memory.ReadMaterializedPermission")
+	defer span.End()
+
+	s.mutexMaterialized.RLock()
+	defer s.mutexMaterialized.RUnlock()
+
+	materialized, ok := s.materializedRelations[materializedRelationKey(store, objectType, relation)]
+	if !ok {
+		return false, nil
+	}
+	for _, user := range users {
+		if _, ok := materialized.permissions[objectID][user]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteMaterializedRelation see [storage.MaterializedPermissionBackend].DeleteMaterializedRelation.
+func (s *MemoryBackend) DeleteMaterializedRelation(ctx context.Context, store, objectType, relation string) error {
+	_, span := tracer.Start(ctx, "memory.DeleteMaterializedRelation")
+	defer span.End()
+
+	s.mutexMaterialized.Lock()
+	defer s.mutexMaterialized.Unlock()
+
+	delete(s.materializedRelations, materializedRelationKey(store, objectType, relation))
+	return nil
+}
+
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
 func (s *MemoryBackend) MaxTuplesPerWrite() int {
 	return s.maxTuplesPerWrite
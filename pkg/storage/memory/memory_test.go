@@ -24,6 +24,11 @@ func TestMemdbStorage(t *testing.T) {
 	test.RunAllTests(t, ds)
 }
 
+func TestMemdbStorageWithReverseUserIndex(t *testing.T) {
+	ds := New(WithReverseUserIndex())
+	test.RunAllTests(t, ds)
+}
+
 func TestStaticTupleIterator(t *testing.T) {
 	t.Run("empty_iterator", func(t *testing.T) {
 		tests := []struct {
@@ -13,6 +13,8 @@ import (
 
 	"github.com/openfga/openfga/assets"
 	"github.com/openfga/openfga/pkg/logger"
+	mysqlstorage "github.com/openfga/openfga/pkg/storage/mysql"
+	"github.com/openfga/openfga/pkg/storage/postgres"
 	"github.com/openfga/openfga/pkg/storage/sqlite"
 )
 
@@ -25,6 +27,25 @@ type MigrationConfig struct {
 	Username      string
 	Password      string
 	Logger        logger.Logger
+
+	// PostgresStorePartitions is the number of hash partitions by store of the tuple and changelog
+	// tables of a postgres datastore, applied once the schema is migrated to the latest version.
+	// If 0, the partitioning of the tables is left as it is.
+	PostgresStorePartitions int
+
+	// PartitionChangelogByTime partitions the changelog of a postgres or mysql datastore by the
+	// time of its changes, once the schema is migrated to the latest version, so that its retention
+	// drops whole partitions.
+	PartitionChangelogByTime bool
+
+	// TuneMaintenance applies the recommended maintenance settings to the tables of a postgres or
+	// mysql datastore, once the schema is migrated to the latest version and its tables partitioned.
+	TuneMaintenance bool
+
+	// ReverseUserIndex enables or disables the reverse user index of a postgres or mysql
+	// datastore, once the schema is migrated to the latest version; it is enabled once the tuples
+	// are indexed. If nil, the index is left as it is.
+	ReverseUserIndex *bool
 }
 
 // RunMigrations runs the migrations for the given config. This function is exposed to allow embedding openFGA
@@ -35,7 +56,11 @@ type MigrationConfig struct {
 // 2. Integrate OpenFGA's schema updates into their own migration workflows
 // 3. Perform versioned upgrades of the schema as needed
 // The function handles migrations for multiple database engines (postgres, mysql, sqlite) and supports
-// both upgrading and downgrading to specific versions.
+// both upgrading and downgrading to specific versions. When migrating a postgres datastore to the latest
+// version, it also partitions its tables by store if PostgresStorePartitions is set, and the changelog
+// of a postgres or mysql datastore by time if PartitionChangelogByTime is set, tunes the
+// maintenance of the tables if TuneMaintenance is set, and enables or disables the reverse user
+// index if ReverseUserIndex is set.
 func RunMigrations(cfg MigrationConfig) error {
 	goose.SetLogger(goose.NopLogger())
 	goose.SetVerbose(cfg.Verbose)
@@ -139,6 +164,67 @@ func RunMigrations(cfg MigrationConfig) error {
 			return fmt.Errorf("failed to run migrations: %w", err)
 		}
 		log.Info("migration done")
+
+		// the changelog partitioned by time is not partitioned by store
+		if cfg.PartitionChangelogByTime {
+			log.Info("partitioning changelog by time")
+			var err error
+			switch cfg.Engine {
+			case "postgres":
+				err = postgres.PartitionChangelogByTime(context.Background(), db)
+			case "mysql":
+				err = mysqlstorage.PartitionChangelogByTime(context.Background(), db)
+			default:
+				err = fmt.Errorf("the changelog of the %s datastore can't be partitioned", cfg.Engine)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to partition changelog by time: %w", err)
+			}
+			log.Info("partitioning done")
+		}
+
+		if cfg.Engine == "postgres" && cfg.PostgresStorePartitions > 0 {
+			log.Info("partitioning tables by store", zap.Int("partitions", cfg.PostgresStorePartitions))
+			if err := postgres.PartitionByStore(context.Background(), db, cfg.PostgresStorePartitions); err != nil {
+				return fmt.Errorf("failed to partition tables by store: %w", err)
+			}
+			log.Info("partitioning done")
+		}
+
+		// the partitions are tuned, so they are created first
+		if cfg.TuneMaintenance {
+			log.Info("tuning maintenance of tables")
+			var err error
+			switch cfg.Engine {
+			case "postgres":
+				err = postgres.TuneMaintenance(context.Background(), db)
+			case "mysql":
+				err = mysqlstorage.TuneMaintenance(context.Background(), db)
+			default:
+				err = fmt.Errorf("the maintenance of the %s datastore can't be tuned", cfg.Engine)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to tune maintenance of tables: %w", err)
+			}
+			log.Info("tuning done")
+		}
+
+		if cfg.ReverseUserIndex != nil {
+			log.Info("reconciling reverse user index", zap.Bool("enabled", *cfg.ReverseUserIndex))
+			var err error
+			switch cfg.Engine {
+			case "postgres":
+				err = postgres.ReconcileReverseUserIndex(context.Background(), db, *cfg.ReverseUserIndex)
+			case "mysql":
+				err = mysqlstorage.ReconcileReverseUserIndex(context.Background(), db, *cfg.ReverseUserIndex)
+			default:
+				err = fmt.Errorf("the %s datastore has no reverse user index", cfg.Engine)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to reconcile reverse user index: %w", err)
+			}
+			log.Info("reconciliation done")
+		}
 		return nil
 	}
 
@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+// maintainedTables are the tables whose rows are frequently deleted or updated.
+var maintainedTables = []string{"tuple", "changelog", sqlcommon.ReverseUserIndexTable}
+
+// maintenanceSettings are the recommended table options of the maintainedTables: their statistics
+// are persisted and recalculated after their rows changed, from more pages than the default 20 so
+// that the plans of the large tables don't regress with their growth. InnoDB purges the deleted
+// rows in the background, so there is no equivalent of the autovacuum settings of PostgreSQL.
+var maintenanceSettings = []string{"STATS_PERSISTENT=1", "STATS_AUTO_RECALC=1", "STATS_SAMPLE_PAGES=64"}
+
+// TuneMaintenance applies the recommended statistics settings to the tuple, changelog and reverse
+// user index tables. The tables that don't exist are skipped.
+func TuneMaintenance(ctx context.Context, db *sql.DB) error {
+	for _, table := range maintainedTables {
+		var exists bool
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`, table).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s %s`, table, strings.Join(maintenanceSettings, " "))); err != nil {
+			return fmt.Errorf("failed to tune the maintenance of table %q: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func maintenanceReport(ctx context.Context, db *sql.DB) ([]sqlcommon.TableMaintenance, error) {
+	var report []sqlcommon.TableMaintenance
+	for _, table := range maintainedTables {
+		var rows, free sql.NullInt64
+		var createOptions sql.NullString
+		err := db.QueryRowContext(ctx, `SELECT TABLE_ROWS, DATA_FREE, CREATE_OPTIONS FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`, table).Scan(&rows, &free, &createOptions)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tuned := true
+		for _, setting := range maintenanceSettings {
+			if !strings.Contains(strings.ToLower(createOptions.String), strings.ToLower(setting)) {
+				tuned = false
+			}
+		}
+		report = append(report, sqlcommon.TableMaintenance{
+			Table:      table,
+			LiveRows:   rows.Int64,
+			BloatBytes: free.Int64,
+			Tuned:      tuned,
+		})
+	}
+	return report, nil
+}
+
+// MaintenanceReport see [sqlcommon.MaintenanceReporter].MaintenanceReport.
+func (s *Datastore) MaintenanceReport(ctx context.Context) ([]sqlcommon.TableMaintenance, error) {
+	ctx, span := startTrace(ctx, "MaintenanceReport")
+	defer span.End()
+
+	report, err := maintenanceReport(ctx, s.db)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return report, nil
+}
+
+var _ sqlcommon.MaintenanceReporter = (*Datastore)(nil)
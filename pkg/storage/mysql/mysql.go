@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,17 +41,38 @@ func startTrace(ctx context.Context, name string) (context.Context, trace.Span)
 type Datastore struct {
 	stbl                   sq.StatementBuilderType
 	db                     *sql.DB
+	stmts                  *sqlcommon.StmtCache
 	dbInfo                 *sqlcommon.DBInfo
 	logger                 logger.Logger
 	dbStatsCollector       prometheus.Collector
 	maxTuplesPerWriteField int
 	maxTypesPerModelField  int
 	versionReady           bool
+	indexAdvisor           *sqlcommon.IndexAdvisor
+	reverseUserIndex       bool
 }
 
 // Ensures that Datastore implements the OpenFGADatastore interface.
 var _ storage.OpenFGADatastore = (*Datastore)(nil)
 
+// Ensures that Datastore implements the SetOperationReader interface.
+var _ storage.SetOperationReader = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ChangelogPruner interface.
+var _ storage.ChangelogPruner = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ChangelogPartitioner interface.
+var _ storage.ChangelogPartitioner = (*Datastore)(nil)
+
+// Ensures that Datastore implements the JobBackend interface.
+var _ storage.JobBackend = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ScheduleBackend interface.
+var _ storage.ScheduleBackend = (*Datastore)(nil)
+
+// Ensures that Datastore implements the MaterializedPermissionBackend interface.
+var _ storage.MaterializedPermissionBackend = (*Datastore)(nil)
+
 // New creates a new [Datastore] storage.
 func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
 	if cfg.Username != "" || cfg.Password != "" {
@@ -67,6 +91,14 @@ func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
 		uri = dsnCfg.FormatDSN()
 	}
 
+	if cfg.IAMAuthMethod != "" || cfg.PasswordProvider != nil {
+		db, err := openWithAuthTokens(uri, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("initialize mysql connection: %w", err)
+		}
+		return NewWithDB(db, cfg)
+	}
+
 	db, err := sql.Open("mysql", uri)
 	if err != nil {
 		return nil, fmt.Errorf("initialize mysql connection: %w", err)
@@ -74,6 +106,51 @@ func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
 	return NewWithDB(db, cfg)
 }
 
+// openWithAuthTokens opens the database with connections that authenticate with a token of the
+// IAM authentication method of the config, or of its PasswordProvider, instead of the password.
+// The IAM tokens are sent in cleartext as the IAM authentication requires, so the connections
+// must use TLS.
+func openWithAuthTokens(uri string, cfg *sqlcommon.Config) (*sql.DB, error) {
+	dsnCfg, err := mysql.ParseDSN(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parse mysql connection dsn: %w", err)
+	}
+	provider := cfg.PasswordProvider
+	if cfg.IAMAuthMethod != "" {
+		provider, err = sqlcommon.NewAuthTokenProvider(cfg.IAMAuthMethod, cfg.IAMAuthRegion)
+		if err != nil {
+			return nil, err
+		}
+		dsnCfg.AllowCleartextPasswords = true
+	}
+
+	err = dsnCfg.Apply(mysql.BeforeConnect(func(ctx context.Context, connCfg *mysql.Config) error {
+		host, port, err := net.SplitHostPort(connCfg.Addr)
+		if err != nil {
+			return fmt.Errorf("parse mysql address %q: %w", connCfg.Addr, err)
+		}
+		portNumber, err := strconv.Atoi(port)
+		if err != nil {
+			return fmt.Errorf("parse mysql address %q: %w", connCfg.Addr, err)
+		}
+		token, err := provider.AuthToken(ctx, host, portNumber, connCfg.User)
+		if err != nil {
+			return fmt.Errorf("failed to get the authentication token: %w", err)
+		}
+		connCfg.Passwd = token
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := mysql.NewConnector(dsnCfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
 // NewWithDB creates a new [Datastore] storage with the provided database connection.
 func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 	if cfg.MaxIdleConns != 0 {
@@ -100,6 +177,10 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 		return nil, fmt.Errorf("ping db: %w", err)
 	}
 
+	if err := verifyReverseUserIndex(context.Background(), db, cfg.ReverseUserIndex); err != nil {
+		return nil, err
+	}
+
 	var collector prometheus.Collector
 	if cfg.ExportMetrics {
 		collector = collectors.NewDBStatsCollector(db, "openfga")
@@ -110,17 +191,73 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 
 	stbl := sq.StatementBuilder.RunWith(db)
 	dbInfo := sqlcommon.NewDBInfo(stbl, HandleSQLError, "mysql")
+	dbInfo.ReverseUserIndex = cfg.ReverseUserIndex
+
+	var indexAdvisor *sqlcommon.IndexAdvisor
+	if cfg.IndexAdvisorSlowQueryThreshold > 0 {
+		indexAdvisor = sqlcommon.NewIndexAdvisor(cfg.IndexAdvisorSlowQueryThreshold, explainFullScans(db), cfg.Logger)
+	}
 
-	return &Datastore{
+	ds := &Datastore{
 		stbl:                   stbl,
 		db:                     db,
+		stmts:                  sqlcommon.NewStmtCache(db, sqlcommon.DefaultMaxPreparedStatements),
 		dbInfo:                 dbInfo,
 		logger:                 cfg.Logger,
 		dbStatsCollector:       collector,
 		maxTuplesPerWriteField: cfg.MaxTuplesPerWriteField,
 		maxTypesPerModelField:  cfg.MaxTypesPerModelField,
 		versionReady:           false,
-	}, nil
+		indexAdvisor:           indexAdvisor,
+		reverseUserIndex:       cfg.ReverseUserIndex,
+	}
+
+	if cfg.MaintenanceTuning {
+		if err := TuneMaintenance(context.Background(), db); err != nil {
+			cfg.Logger.Warn("failed to tune the maintenance of the datastore tables", zap.Error(err))
+		}
+	}
+	sqlcommon.CheckMaintenance(context.Background(), ds, cfg.Logger)
+
+	return ds, nil
+}
+
+// explainFullScans returns the sqlcommon.ExplainFunc of db, which reports the full table scans of
+// the plans: the rows of the EXPLAIN output of which the access type is ALL.
+func explainFullScans(db *sql.DB) sqlcommon.ExplainFunc {
+	return func(ctx context.Context, query string, args []interface{}) ([]string, error) {
+		rows, err := db.QueryContext(ctx, "EXPLAIN "+query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		tableColumn, typeColumn := slices.Index(columns, "table"), slices.Index(columns, "type")
+		if tableColumn < 0 || typeColumn < 0 {
+			return nil, fmt.Errorf("unexpected explain columns %v", columns)
+		}
+
+		var tables []string
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				return nil, err
+			}
+			table := values[tableColumn].String
+			if values[typeColumn].String == "ALL" && !slices.Contains(tables, table) {
+				tables = append(tables, table)
+			}
+		}
+		return tables, rows.Err()
+	}
 }
 
 // Close see [storage.OpenFGADatastore].Close.
@@ -128,6 +265,8 @@ func (s *Datastore) Close() {
 	if s.dbStatsCollector != nil {
 		prometheus.Unregister(s.dbStatsCollector)
 	}
+	s.indexAdvisor.Close()
+	s.stmts.Close()
 	s.db.Close()
 }
 
@@ -208,7 +347,7 @@ func (s *Datastore) read(ctx context.Context, store string, filter storage.ReadF
 		sb = sb.Limit(uint64(options.Pagination.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
 	}
 
-	return sqlcommon.NewSQLTupleIterator(sqlcommon.NewSBIteratorQuery(sb), HandleSQLError), nil
+	return sqlcommon.NewSQLTupleIterator(s.indexAdvisor.Advise(sqlcommon.NewStmtCacheIteratorQuery(sb, s.stmts)), HandleSQLError), nil
 }
 
 // Write see [storage.RelationshipTupleWriter].Write.
@@ -341,7 +480,7 @@ func (s *Datastore) ReadUsersetTuples(
 		sb = sb.Where(sq.Eq{"COALESCE(condition_name, '')": filter.Conditions})
 	}
 
-	return sqlcommon.NewSQLTupleIterator(sqlcommon.NewSBIteratorQuery(sb), HandleSQLError), nil
+	return sqlcommon.NewSQLTupleIterator(s.indexAdvisor.Advise(sqlcommon.NewStmtCacheIteratorQuery(sb, s.stmts)), HandleSQLError), nil
 }
 
 // ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
@@ -363,13 +502,18 @@ func (s *Datastore) ReadStartingWithUser(
 		targetUsersArg = append(targetUsersArg, targetUser)
 	}
 
+	table := "tuple"
+	if s.reverseUserIndex {
+		table = sqlcommon.ReverseUserIndexTable
+	}
+
 	builder := s.stbl.
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
 			"condition_name", "condition_context", "ulid", "inserted_at",
 		).
-		From("tuple").
+		From(table).
 		Where(sq.Eq{
 			"store":       store,
 			"object_type": filter.ObjectType,
@@ -383,7 +527,511 @@ func (s *Datastore) ReadStartingWithUser(
 	if len(filter.Conditions) > 0 {
 		builder = builder.Where(sq.Eq{"COALESCE(condition_name, '')": filter.Conditions})
 	}
-	return sqlcommon.NewSQLTupleIterator(sqlcommon.NewSBIteratorQuery(builder), HandleSQLError), nil
+	return sqlcommon.NewSQLTupleIterator(s.indexAdvisor.Advise(sqlcommon.NewStmtCacheIteratorQuery(builder, s.stmts)), HandleSQLError), nil
+}
+
+// WriteJob see [storage.JobBackend].WriteJob.
+func (s *Datastore) WriteJob(ctx context.Context, job *storage.Job) error {
+	ctx, span := startTrace(ctx, "WriteJob")
+	defer span.End()
+
+	_, err := s.stbl.
+		Insert("job").
+		Columns("store", "ulid", "kind", "done", "payload", "updated_at").
+		Values(job.Store, job.ID, job.Kind, job.Done, job.Payload, sq.Expr("NOW(6)")).
+		Suffix("ON DUPLICATE KEY UPDATE kind = VALUES(kind), done = VALUES(done), payload = VALUES(payload), updated_at = VALUES(updated_at)").
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadJob see [storage.JobBackend].ReadJob.
+func (s *Datastore) ReadJob(ctx context.Context, store, id string) (*storage.Job, error) {
+	ctx, span := startTrace(ctx, "ReadJob")
+	defer span.End()
+
+	var job storage.Job
+	err := s.stbl.
+		Select("store", "ulid", "kind", "done", "cancel_requested", "payload", "updated_at").
+		From("job").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		QueryRowContext(ctx).
+		Scan(&job.Store, &job.ID, &job.Kind, &job.Done, &job.CancelRequested, &job.Payload, &job.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+	return &job, nil
+}
+
+// ListJobs see [storage.JobBackend].ListJobs.
+func (s *Datastore) ListJobs(ctx context.Context, store string) ([]*storage.Job, error) {
+	ctx, span := startTrace(ctx, "ListJobs")
+	defer span.End()
+
+	rows, err := s.stbl.
+		Select("store", "ulid", "kind", "done", "cancel_requested", "payload", "updated_at").
+		From("job").
+		Where(sq.Eq{"store": store}).
+		OrderBy("ulid").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var jobs []*storage.Job
+	for rows.Next() {
+		var job storage.Job
+		err := rows.Scan(&job.Store, &job.ID, &job.Kind, &job.Done, &job.CancelRequested, &job.Payload, &job.UpdatedAt)
+		if err != nil {
+			return nil, HandleSQLError(err)
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return jobs, nil
+}
+
+// CancelJob see [storage.JobBackend].CancelJob.
+func (s *Datastore) CancelJob(ctx context.Context, store, id string) error {
+	ctx, span := startTrace(ctx, "CancelJob")
+	defer span.End()
+
+	res, err := s.stbl.
+		Update("job").
+		Set("cancel_requested", true).
+		Set("updated_at", sq.Expr("NOW(6)")).
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteJobs see [storage.JobBackend].DeleteJobs.
+func (s *Datastore) DeleteJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	ctx, span := startTrace(ctx, "DeleteJobs")
+	defer span.End()
+
+	res, err := s.stbl.
+		Delete("job").
+		Where(sq.Eq{"done": true}).
+		Where(fmt.Sprintf("updated_at < NOW(6) - INTERVAL %d MICROSECOND", time.Since(updatedBefore).Microseconds())).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return rowsAffected, nil
+}
+
+// WriteSchedule see [storage.ScheduleBackend].WriteSchedule.
+func (s *Datastore) WriteSchedule(ctx context.Context, schedule *storage.Schedule) error {
+	ctx, span := startTrace(ctx, "WriteSchedule")
+	defer span.End()
+
+	_, err := s.stbl.
+		Insert("job_schedule").
+		Columns("store", "ulid", "payload").
+		Values(schedule.Store, schedule.ID, schedule.Payload).
+		Suffix("ON DUPLICATE KEY UPDATE payload = VALUES(payload)").
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadSchedule see [storage.ScheduleBackend].ReadSchedule.
+func (s *Datastore) ReadSchedule(ctx context.Context, store, id string) (*storage.Schedule, error) {
+	ctx, span := startTrace(ctx, "ReadSchedule")
+	defer span.End()
+
+	schedule, err := scanSchedule(s.stbl.
+		Select("store", "ulid", "payload", "last_run_at", "last_job_id").
+		From("job_schedule").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		QueryRowContext(ctx))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+	return schedule, nil
+}
+
+// ListSchedules see [storage.ScheduleBackend].ListSchedules.
+func (s *Datastore) ListSchedules(ctx context.Context, store string) ([]*storage.Schedule, error) {
+	ctx, span := startTrace(ctx, "ListSchedules")
+	defer span.End()
+
+	sb := s.stbl.
+		Select("store", "ulid", "payload", "last_run_at", "last_job_id").
+		From("job_schedule").
+		OrderBy("store", "ulid")
+	if store != "" {
+		sb = sb.Where(sq.Eq{"store": store})
+	}
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var schedules []*storage.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, HandleSQLError(err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule see [storage.ScheduleBackend].DeleteSchedule.
+func (s *Datastore) DeleteSchedule(ctx context.Context, store, id string) error {
+	ctx, span := startTrace(ctx, "DeleteSchedule")
+	defer span.End()
+
+	res, err := s.stbl.
+		Delete("job_schedule").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// RecordScheduleRun see [storage.ScheduleBackend].RecordScheduleRun.
+func (s *Datastore) RecordScheduleRun(ctx context.Context, store, id string, runAt time.Time, jobID string) error {
+	ctx, span := startTrace(ctx, "RecordScheduleRun")
+	defer span.End()
+
+	res, err := s.stbl.
+		Update("job_schedule").
+		Set("last_run_at", runAt.UTC()).
+		Set("last_job_id", jobID).
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if rowsAffected == 0 {
+		// the rows whose values are unchanged are not affected
+		if _, err := s.ReadSchedule(ctx, store, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AcquireLease see [storage.ScheduleBackend].AcquireLease.
+func (s *Datastore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	ctx, span := startTrace(ctx, "AcquireLease")
+	defer span.End()
+
+	expiresAt := sq.Expr(fmt.Sprintf("NOW(6) + INTERVAL %d MICROSECOND", ttl.Microseconds()))
+
+	// extend the lease of holder, or take over an expired lease
+	res, err := s.stbl.
+		Update("lease").
+		Set("holder", holder).
+		Set("expires_at", expiresAt).
+		Where(sq.Eq{"name": name}).
+		Where(sq.Or{sq.Eq{"holder": holder}, sq.Expr("expires_at < NOW(6)")}).
+		ExecContext(ctx)
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	if rowsAffected == 1 {
+		return true, nil
+	}
+
+	// the lease doesn't exist, or is held by another holder
+	res, err = s.stbl.
+		Insert("lease").
+		Options("IGNORE").
+		Columns("name", "holder", "expires_at").
+		Values(name, holder, expiresAt).
+		ExecContext(ctx)
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// scanSchedule scans a row of the job_schedule table, whose runs are NULL until recorded.
+func scanSchedule(row sq.RowScanner) (*storage.Schedule, error) {
+	var schedule storage.Schedule
+	var lastRunAt sql.NullTime
+	var lastJobID sql.NullString
+	if err := row.Scan(&schedule.Store, &schedule.ID, &schedule.Payload, &lastRunAt, &lastJobID); err != nil {
+		return nil, err
+	}
+	if lastRunAt.Valid {
+		schedule.LastRunAt = lastRunAt.Time.UTC()
+	}
+	schedule.LastJobID = lastJobID.String
+	return &schedule, nil
+}
+
+// ReadMaterializedRelation see [storage.MaterializedPermissionBackend].ReadMaterializedRelation.
+func (s *Datastore) ReadMaterializedRelation(ctx context.Context, store, objectType, relation string) (*storage.MaterializedRelation, error) {
+	ctx, span := startTrace(ctx, "ReadMaterializedRelation")
+	defer span.End()
+
+	var state storage.MaterializedRelation
+	err := s.stbl.
+		Select("store", "object_type", "relation", "authorization_model_id", "continuation_token", "updated_at").
+		From("materialized_relation").
+		Where(sq.Eq{"store": store, "object_type": objectType, "relation": relation}).
+		QueryRowContext(ctx).
+		Scan(&state.Store, &state.ObjectType, &state.Relation, &state.ModelID, &state.ContinuationToken, &state.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+	state.UpdatedAt = state.UpdatedAt.UTC()
+	return &state, nil
+}
+
+// WriteMaterializedPermissions see [storage.MaterializedPermissionBackend].WriteMaterializedPermissions.
+func (s *Datastore) WriteMaterializedPermissions(ctx context.Context, relation *storage.MaterializedRelation, update storage.MaterializedPermissionsUpdate) error {
+	ctx, span := startTrace(ctx, "WriteMaterializedPermissions")
+	defer span.End()
+
+	txn, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	key := sq.Eq{"store": relation.Store, "object_type": relation.ObjectType, "relation": relation.Relation}
+
+	if update.Reset || len(update.ObjectIDs) > 0 {
+		deleteBuilder := s.stbl.Delete("materialized_permission").Where(key)
+		if !update.Reset {
+			deleteBuilder = deleteBuilder.Where(sq.Eq{"object_id": update.ObjectIDs})
+		}
+		if _, err := deleteBuilder.RunWith(txn).ExecContext(ctx); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	for start := 0; start < len(update.Permissions); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(update.Permissions))
+		insertBuilder := s.stbl.
+			Insert("materialized_permission").
+			Options("IGNORE").
+			Columns("store", "object_type", "relation", "object_id", "_user")
+		for _, permission := range update.Permissions[start:end] {
+			insertBuilder = insertBuilder.Values(relation.Store, relation.ObjectType, relation.Relation, permission.ObjectID, permission.User)
+		}
+		if _, err := insertBuilder.RunWith(txn).ExecContext(ctx); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	_, err = s.stbl.
+		Insert("materialized_relation").
+		Columns("store", "object_type", "relation", "authorization_model_id", "continuation_token", "updated_at").
+		Values(relation.Store, relation.ObjectType, relation.Relation, relation.ModelID, relation.ContinuationToken, relation.UpdatedAt.UTC()).
+		Suffix("ON DUPLICATE KEY UPDATE authorization_model_id = VALUES(authorization_model_id), continuation_token = VALUES(continuation_token), updated_at = VALUES(updated_at)").
+		RunWith(txn).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadMaterializedPermission see [storage.MaterializedPermissionBackend].ReadMaterializedPermission.
+func (s *Datastore) ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error) {
+	ctx, span := startTrace(ctx, "ReadMaterializedPermission")
+	defer span.End()
+
+	if len(users) == 0 {
+		return false, nil
+	}
+
+	var found int
+	err := s.stbl.
+		Select("1").
+		From("materialized_permission").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"relation":    relation,
+			"object_id":   objectID,
+			"_user":       users,
+		}).
+		Limit(1).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	return true, nil
+}
+
+// DeleteMaterializedRelation see [storage.MaterializedPermissionBackend].DeleteMaterializedRelation.
+func (s *Datastore) DeleteMaterializedRelation(ctx context.Context, store, objectType, relation string) error {
+	ctx, span := startTrace(ctx, "DeleteMaterializedRelation")
+	defer span.End()
+
+	txn, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	key := sq.Eq{"store": store, "object_type": objectType, "relation": relation}
+	for _, table := range []string{"materialized_permission", "materialized_relation"} {
+		if _, err := s.stbl.Delete(table).Where(key).RunWith(txn).ExecContext(ctx); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Datastore) PruneChanges(ctx context.Context, store string, options storage.PruneChangesOptions) (int64, error) {
+	ctx, span := startTrace(ctx, "PruneChanges")
+	defer span.End()
+
+	var nthLatest string
+	if options.KeepLatest > 0 {
+		err := s.stbl.
+			Select("ulid").
+			From("changelog").
+			Where(sq.Eq{"store": store}).
+			OrderBy("ulid desc").
+			Limit(1).
+			Offset(uint64(options.KeepLatest - 1)).
+			QueryRowContext(ctx).
+			Scan(&nthLatest)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, HandleSQLError(err)
+		}
+	}
+
+	cutoff := storage.PruneChangesCutoff(options, nthLatest)
+	if cutoff == "" {
+		return 0, nil
+	}
+
+	res, err := s.stbl.
+		Delete("changelog").
+		Where(sq.Eq{"store": store}).
+		Where(sq.Lt{"ulid": cutoff}).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return rowsAffected, nil
+}
+
+// NewChangelogListener returns a [sqlcommon.ChangelogListener] that polls the changelog of all the
+// stores of the datastore, since MySQL cannot push its changes without a binlog client.
+func (s *Datastore) NewChangelogListener(opts ...sqlcommon.ChangelogListenerOption) *sqlcommon.ChangelogListener {
+	return sqlcommon.NewChangelogListener(s.dbInfo, opts...)
+}
+
+// ReadObjectIDsWithSetOperation see [storage.SetOperationReader].ReadObjectIDsWithSetOperation.
+func (s *Datastore) ReadObjectIDsWithSetOperation(
+	ctx context.Context,
+	store string,
+	filter storage.SetOperationFilter,
+	options storage.ReadSetOperationOptions,
+) ([]string, error) {
+	ctx, span := startTrace(ctx, "ReadObjectIDsWithSetOperation")
+	defer span.End()
+
+	stmt, args, err := sqlcommon.SetOperationQuery{
+		UserCondition: func(users []string) sq.Sqlizer {
+			return sq.Eq{"_user": users}
+		},
+		// EXCEPT is only available from MySQL 8.0.31.
+		WithoutExcept: true,
+	}.Build(store, filter, options.Limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	ids, err := sqlcommon.ScanObjectIDs(rows)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return ids, nil
 }
 
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -44,6 +45,144 @@ func TestMySQLDatastore(t *testing.T) {
 	defer dsCustom.Close()
 
 	t.Run("WriteTuplesWithMaxTuplesPerWrite", test.WriteTuplesWithMaxTuplesPerWrite(dsCustom, context.Background()))
+
+	// Run the tuple tests with the reverse user index, once it is enabled in the datastore.
+	require.NoError(t, ReconcileReverseUserIndex(context.Background(), ds.db, true))
+
+	dsIndexed, err := New(uri, sqlcommon.NewConfig(
+		sqlcommon.WithReverseUserIndex(),
+	))
+	require.NoError(t, err)
+	defer dsIndexed.Close()
+
+	t.Run("TestTupleWriteAndReadWithReverseUserIndex", func(t *testing.T) { test.TupleWritingAndReadingTest(t, dsIndexed) })
+	t.Run("TestReadStartingWithUserWithReverseUserIndex", func(t *testing.T) { test.ReadStartingWithUserTest(t, dsIndexed) })
+}
+
+func TestReverseUserIndexReconciliation(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "mysql")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig())
+	require.NoError(t, err)
+	defer ds.Close()
+
+	ctx := context.Background()
+	store := ulid.Make().String()
+	filter := storage.ReadStartingWithUserFilter{
+		ObjectType: "document",
+		Relation:   "viewer",
+		UserFilter: []*openfgav1.ObjectRelation{{Object: "user:jon"}},
+	}
+	readObjects := func(ds storage.OpenFGADatastore) []string {
+		iter, err := ds.ReadStartingWithUser(ctx, store, filter, storage.ReadStartingWithUserOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+
+		var objects []string
+		for {
+			tp, err := iter.Next(ctx)
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return objects
+			}
+			require.NoError(t, err)
+			objects = append(objects, tp.GetKey().GetObject())
+		}
+	}
+
+	err = ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tupleUtils.NewTupleKey("document:1", "viewer", "user:jon"),
+		tupleUtils.NewTupleKey("document:2", "viewer", "user:jon"),
+	})
+	require.NoError(t, err)
+
+	// the instances with the index refuse to start until it is enabled in the datastore
+	_, err = New(uri, sqlcommon.NewConfig(sqlcommon.WithReverseUserIndex()))
+	require.ErrorContains(t, err, "the reverse user index is not enabled in the datastore")
+
+	// the tuples written before the index is enabled are indexed when it is enabled
+	require.NoError(t, ReconcileReverseUserIndex(ctx, ds.db, true))
+	dsIndexed, err := New(uri, sqlcommon.NewConfig(sqlcommon.WithReverseUserIndex()))
+	require.NoError(t, err)
+	defer dsIndexed.Close()
+	require.Equal(t, []string{"document:1", "document:2"}, readObjects(dsIndexed))
+
+	// the instances without the index refuse to start once it is enabled in the datastore
+	_, err = New(uri, sqlcommon.NewConfig())
+	require.ErrorContains(t, err, "the reverse user index is enabled in the datastore")
+
+	// the tuples deleted by an instance that was still running without the index are removed
+	// from it when it is reconciled again
+	err = ds.Write(ctx, store, []*openfgav1.TupleKeyWithoutCondition{
+		tupleUtils.TupleKeyToTupleKeyWithoutCondition(tupleUtils.NewTupleKey("document:1", "viewer", "user:jon")),
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"document:1", "document:2"}, readObjects(dsIndexed))
+
+	require.NoError(t, ReconcileReverseUserIndex(ctx, ds.db, true))
+	require.Equal(t, []string{"document:2"}, readObjects(dsIndexed))
+
+	// the instances without the index start once it is disabled in the datastore
+	require.NoError(t, ReconcileReverseUserIndex(ctx, ds.db, false))
+	dsUnindexed, err := New(uri, sqlcommon.NewConfig())
+	require.NoError(t, err)
+	dsUnindexed.Close()
+}
+
+func TestChangelogPartitionedByTime(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "mysql")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig())
+	require.NoError(t, err)
+	defer ds.Close()
+
+	ctx := context.Background()
+	db := ds.db
+
+	store := ulid.Make().String()
+	countChanges := func() int {
+		changes, _, err := ds.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(10, ""),
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0
+		}
+		require.NoError(t, err)
+		return len(changes)
+	}
+
+	err = ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tupleUtils.NewTupleKey("document:1", "viewer", "user:jon"),
+		tupleUtils.NewTupleKey("document:2", "viewer", "user:jon"),
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.ErrorIs(t, ds.CreateChangelogPartitions(ctx, now), errors.ErrUnsupported)
+	_, err = ds.DropChangelogPartitions(ctx, now)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	require.NoError(t, PartitionChangelogByTime(ctx, db))
+	require.NoError(t, PartitionChangelogByTime(ctx, db))
+	require.Equal(t, 2, countChanges())
+
+	require.NoError(t, ds.CreateChangelogPartitions(ctx, now.Add(5*storage.ChangelogPartitionInterval)))
+	err = ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tupleUtils.NewTupleKey("document:3", "viewer", "user:jon"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, countChanges())
+
+	// the partitions of the changes of today are dropped once the day is over
+	dropped, err := ds.DropChangelogPartitions(ctx, now)
+	require.NoError(t, err)
+	require.Zero(t, dropped)
+
+	dropped, err = ds.DropChangelogPartitions(ctx, storage.ChangelogPartitionStart(now).Add(storage.ChangelogPartitionInterval))
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+	require.Zero(t, countChanges())
 }
 
 func TestMySQLDatastoreAfterCloseIsNotReady(t *testing.T) {
@@ -1147,3 +1286,32 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestMaintenance(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "mysql")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig())
+	require.NoError(t, err)
+	defer ds.Close()
+
+	ctx := context.Background()
+	tuned := func() map[string]bool {
+		report, err := ds.MaintenanceReport(ctx)
+		require.NoError(t, err)
+		tables := make(map[string]bool, len(report))
+		for _, table := range report {
+			require.Zero(t, table.DeadRows)
+			tables[table.Table] = table.Tuned
+		}
+		return tables
+	}
+	require.Equal(t, map[string]bool{"tuple": false, "changelog": false, sqlcommon.ReverseUserIndexTable: false}, tuned())
+
+	require.NoError(t, TuneMaintenance(ctx, ds.db))
+	require.Equal(t, map[string]bool{"tuple": true, "changelog": true, sqlcommon.ReverseUserIndexTable: true}, tuned())
+
+	// the settings are kept by the partitioning
+	require.NoError(t, PartitionChangelogByTime(ctx, ds.db))
+	require.True(t, tuned()["changelog"])
+}
@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+// maxChangelogPartition is the partition of a changelog partitioned by time that receives the
+// changes after its other partitions.
+const maxChangelogPartition = "pmax"
+
+// changelogPartitionsAhead is the time for which the partitions of the changes to come are
+// created in advance, so that the changes are not inserted into the max partition.
+const changelogPartitionsAhead = 2 * storage.ChangelogPartitionInterval
+
+// changelogPartitions returns the names of the partitions of the changelog and whether it is
+// partitioned by time.
+func changelogPartitions(ctx context.Context, db *sql.DB) ([]string, bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT PARTITION_NAME, PARTITION_METHOD FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'changelog' AND PARTITION_NAME IS NOT NULL
+		ORDER BY PARTITION_ORDINAL_POSITION`)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var names []string
+	partitioned := false
+	for rows.Next() {
+		var name, method string
+		if err := rows.Scan(&name, &method); err != nil {
+			return nil, false, err
+		}
+		names = append(names, name)
+		partitioned = method == "RANGE COLUMNS"
+	}
+	return names, partitioned, rows.Err()
+}
+
+func changelogPartitionDefinition(start time.Time) string {
+	return fmt.Sprintf(`PARTITION %s VALUES LESS THAN ('%s')`,
+		sqlcommon.ChangelogPartitionName(start), storage.ChangelogPartitionBound(start.Add(storage.ChangelogPartitionInterval)))
+}
+
+// PartitionChangelogByTime partitions the changelog table by the ULID, i.e. the time, of its
+// changes into partitions of storage.ChangelogPartitionInterval, so that the retention of the
+// changelog drops its old partitions instead of deleting its changes one by one. The first
+// partition receives the changes before it, and a max partition the changes after the last one.
+// MySQL copies the changelog into its partitions, so the writes wait for the copy.
+func PartitionChangelogByTime(ctx context.Context, db *sql.DB) error {
+	_, partitioned, err := changelogPartitions(ctx, db)
+	if err != nil {
+		return err
+	}
+	if partitioned {
+		return nil
+	}
+
+	now := time.Now()
+	oldest := now
+	var oldestULID sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT MIN(ulid) FROM changelog`).Scan(&oldestULID); err != nil {
+		return err
+	}
+	if id, err := ulid.Parse(oldestULID.String); oldestULID.Valid && err == nil {
+		oldest = ulid.Time(id.Time())
+	}
+
+	var definitions []string
+	for _, start := range sqlcommon.ChangelogPartitionsUntil(oldest, now.Add(changelogPartitionsAhead)) {
+		definitions = append(definitions, changelogPartitionDefinition(start))
+	}
+	definitions = append(definitions, fmt.Sprintf(`PARTITION %s VALUES LESS THAN (MAXVALUE)`, maxChangelogPartition))
+
+	_, err = db.ExecContext(ctx, `ALTER TABLE changelog PARTITION BY RANGE COLUMNS(ulid) (`+strings.Join(definitions, ", ")+`)`)
+	if err != nil {
+		return fmt.Errorf("failed to partition table %q: %w", "changelog", err)
+	}
+	return nil
+}
+
+// changelogPartitioned returns the partitions of the changelog, or errors.ErrUnsupported if it is
+// not partitioned by time.
+func (s *Datastore) changelogPartitioned(ctx context.Context) ([]string, error) {
+	names, partitioned, err := changelogPartitions(ctx, s.db)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	if !partitioned {
+		return nil, errors.ErrUnsupported
+	}
+	return names, nil
+}
+
+// CreateChangelogPartitions see [storage.ChangelogPartitioner].CreateChangelogPartitions.
+func (s *Datastore) CreateChangelogPartitions(ctx context.Context, until time.Time) error {
+	ctx, span := startTrace(ctx, "CreateChangelogPartitions")
+	defer span.End()
+
+	names, err := s.changelogPartitioned(ctx)
+	if err != nil {
+		return err
+	}
+	var latest time.Time
+	for _, name := range names {
+		if start, ok := sqlcommon.ParseChangelogPartitionName(name); ok && start.After(latest) {
+			latest = start
+		}
+	}
+
+	// the partitions are split from the max partition, after the last one
+	var definitions []string
+	for _, start := range sqlcommon.ChangelogPartitionsUntil(time.Now(), until) {
+		if start.After(latest) {
+			definitions = append(definitions, changelogPartitionDefinition(start))
+		}
+	}
+	if len(definitions) == 0 {
+		return nil
+	}
+	definitions = append(definitions, fmt.Sprintf(`PARTITION %s VALUES LESS THAN (MAXVALUE)`, maxChangelogPartition))
+
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE changelog REORGANIZE PARTITION %s INTO (%s)`,
+		maxChangelogPartition, strings.Join(definitions, ", ")))
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// DropChangelogPartitions see [storage.ChangelogPartitioner].DropChangelogPartitions.
+func (s *Datastore) DropChangelogPartitions(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := startTrace(ctx, "DropChangelogPartitions")
+	defer span.End()
+
+	names, err := s.changelogPartitioned(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var expired []string
+	for _, name := range names {
+		if start, ok := sqlcommon.ParseChangelogPartitionName(name); ok && !start.Add(storage.ChangelogPartitionInterval).After(before) {
+			expired = append(expired, name)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, `ALTER TABLE changelog DROP PARTITION `+strings.Join(expired, ", ")); err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+// maintainedTables are the tables whose rows are frequently deleted or updated, which the default
+// autovacuum settings leave bloated when they are large.
+var maintainedTables = []string{"tuple", changelogTable.name, sqlcommon.ReverseUserIndexTable}
+
+// maintenanceSettings are the recommended storage parameters of the maintainedTables: they are
+// vacuumed and analyzed after a fixed share of their rows changed, instead of the default 20% and
+// 10% that let the large tables accumulate millions of dead rows.
+var maintenanceSettings = map[string]string{
+	"autovacuum_vacuum_scale_factor":  "0.02",
+	"autovacuum_analyze_scale_factor": "0.01",
+}
+
+// maintenanceOptions returns the maintenanceSettings as storage parameters, e.g. for a WITH clause
+// or the reloptions of pg_class.
+func maintenanceOptions() []string {
+	options := make([]string, 0, len(maintenanceSettings))
+	for name, value := range maintenanceSettings {
+		options = append(options, name+"="+value)
+	}
+	sort.Strings(options)
+	return options
+}
+
+// TuneMaintenance applies the recommended autovacuum and analyze settings to the tuple, changelog
+// and reverse user index tables. The settings of a partitioned table are applied to its
+// partitions, which are the ones vacuumed. The tables that don't exist are skipped.
+func TuneMaintenance(ctx context.Context, db *sql.DB) error {
+	options := strings.Join(maintenanceOptions(), ", ")
+	for _, table := range maintainedTables {
+		leaves, err := tableLeaves(ctx, db, table)
+		if err != nil {
+			return err
+		}
+		for _, leaf := range leaves {
+			if _, err := db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s SET (%s)`, leaf, options)); err != nil {
+				return fmt.Errorf("failed to tune the maintenance of table %q: %w", leaf, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tableLeaves returns the table itself, or its partitions if it is partitioned, and nothing if it
+// doesn't exist.
+func tableLeaves(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT relid::text FROM pg_partition_tree(to_regclass($1)) WHERE isleaf`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var leaves []string
+	for rows.Next() {
+		var leaf string
+		if err := rows.Scan(&leaf); err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+	return leaves, rows.Err()
+}
+
+func maintenanceReport(ctx context.Context, db *sql.DB) ([]sqlcommon.TableMaintenance, error) {
+	var report []sqlcommon.TableMaintenance
+	for _, table := range maintainedTables {
+		var leaves int
+		var live, dead, bloat int64
+		var lastVacuum sql.NullTime
+		var tuned sql.NullBool
+		err := db.QueryRowContext(ctx, `SELECT count(*),
+				COALESCE(sum(s.n_live_tup), 0)::bigint,
+				COALESCE(sum(s.n_dead_tup), 0)::bigint,
+				COALESCE(sum(CASE WHEN s.n_live_tup + s.n_dead_tup > 0
+					THEN pg_table_size(t.relid) * s.n_dead_tup / (s.n_live_tup + s.n_dead_tup) ELSE 0 END), 0)::bigint,
+				max(GREATEST(s.last_vacuum, s.last_autovacuum)),
+				bool_and(COALESCE(c.reloptions, '{}') @> $2::text[])
+			FROM pg_partition_tree(to_regclass($1)) t
+			JOIN pg_class c ON c.oid = t.relid
+			LEFT JOIN pg_stat_user_tables s ON s.relid = t.relid
+			WHERE t.isleaf`, table, maintenanceOptions()).Scan(&leaves, &live, &dead, &bloat, &lastVacuum, &tuned)
+		if err != nil {
+			return nil, err
+		}
+		if leaves == 0 {
+			continue
+		}
+
+		maintenance := sqlcommon.TableMaintenance{
+			Table:      table,
+			LiveRows:   live,
+			DeadRows:   dead,
+			BloatBytes: bloat,
+			Tuned:      tuned.Bool,
+		}
+		if lastVacuum.Valid {
+			maintenance.LastVacuum = &lastVacuum.Time
+		}
+		report = append(report, maintenance)
+	}
+	return report, nil
+}
+
+// MaintenanceReport see [sqlcommon.MaintenanceReporter].MaintenanceReport.
+func (s *Datastore) MaintenanceReport(ctx context.Context) ([]sqlcommon.TableMaintenance, error) {
+	ctx, span := startTrace(ctx, "MaintenanceReport")
+	defer span.End()
+
+	db := stdlib.OpenDBFromPool(s.primaryDB)
+	defer db.Close()
+
+	report, err := maintenanceReport(ctx, db)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return report, nil
+}
+
+var _ sqlcommon.MaintenanceReporter = (*Datastore)(nil)
+
+// tuneMaintenance applies TuneMaintenance to the tables of the datastore when it is opened.
+func (s *Datastore) tuneMaintenance(ctx context.Context) error {
+	db := stdlib.OpenDBFromPool(s.primaryDB)
+	defer db.Close()
+
+	return TuneMaintenance(ctx, db)
+}
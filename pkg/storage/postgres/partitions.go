@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+// partitionedTable is a table that can be partitioned, by the hash of its store column by
+// PartitionByStore, or by the time of its changes by PartitionChangelogByTime for the changelog.
+type partitionedTable struct {
+	name       string
+	primaryKey []string
+	// indexes are the statements creating the indexes of the table, other than its primary key.
+	// The unique indexes include the store, as the partitioned tables require.
+	indexes []string
+}
+
+var changelogTable = partitionedTable{
+	name:       "changelog",
+	primaryKey: []string{"store", "ulid", "object_type"},
+}
+
+var partitionedTables = []partitionedTable{
+	{
+		name:       "tuple",
+		primaryKey: []string{"store", "object_type", "object_id", "relation", "_user"},
+		indexes: []string{
+			`CREATE INDEX idx_tuple_partial_user ON tuple (store, object_type, object_id, relation, _user) WHERE user_type = 'user'`,
+			`CREATE INDEX idx_tuple_partial_userset ON tuple (store, object_type, object_id, relation, _user) WHERE user_type = 'userset'`,
+			`CREATE UNIQUE INDEX idx_tuple_ulid ON tuple (store, ulid)`,
+			`CREATE INDEX idx_user_lookup ON tuple (store, _user, relation, object_type, object_id COLLATE "C")`,
+		},
+	},
+	changelogTable,
+}
+
+// The partitioning strategies of the tables, as in pg_partitioned_table.
+const (
+	hashPartitioning  = "h"
+	rangePartitioning = "r"
+)
+
+// StorePartitions returns the number of hash partitions of the tuple and changelog tables by
+// store, or 0 if they are not partitioned by store.
+func StorePartitions(ctx context.Context, db *sql.DB) (map[string]int, error) {
+	partitions := make(map[string]int, len(partitionedTables))
+	for _, table := range partitionedTables {
+		strategy, n, err := tablePartitioning(ctx, db, table.name)
+		if err != nil {
+			return nil, err
+		}
+		if strategy != hashPartitioning {
+			n = 0
+		}
+		partitions[table.name] = n
+	}
+	return partitions, nil
+}
+
+// tablePartitioning returns the partitioning strategy of a table, or an empty strategy if it is
+// not partitioned, and its number of partitions.
+func tablePartitioning(ctx context.Context, db *sql.DB, table string) (string, int, error) {
+	var strategy string
+	var n int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(p.partstrat::text, ''),
+			(SELECT count(*) FROM pg_inherits WHERE inhparent = c.oid)
+		FROM pg_class c LEFT JOIN pg_partitioned_table p ON p.partrelid = c.oid
+		WHERE c.oid = to_regclass($1)`, table).Scan(&strategy, &n)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, fmt.Errorf("table %q not found, run the migrations first", table)
+	}
+	return strategy, n, err
+}
+
+// PartitionByStore partitions the tuple and changelog tables by the hash of their store into the
+// given number of partitions, so that the vacuums and the deletes of very large multi-tenant
+// deployments work on the smaller tables of the partitions. The tables already partitioned into a
+// different number of partitions are repartitioned, and a changelog partitioned by time by
+// PartitionChangelogByTime is left as it is. Each table is copied into its partitions in a
+// transaction that locks it, so the writes to the table wait for the copy.
+func PartitionByStore(ctx context.Context, db *sql.DB, partitions int) error {
+	if partitions < 1 {
+		return fmt.Errorf("the number of partitions must be greater than 0, got %d", partitions)
+	}
+	for _, table := range partitionedTables {
+		strategy, n, err := tablePartitioning(ctx, db, table.name)
+		if err != nil {
+			return err
+		}
+		if strategy == rangePartitioning || (strategy == hashPartitioning && n == partitions) {
+			continue
+		}
+		if err := partitionTable(ctx, db, table, partitions); err != nil {
+			return fmt.Errorf("failed to partition table %q: %w", table.name, err)
+		}
+	}
+	return nil
+}
+
+func partitionTable(ctx context.Context, db *sql.DB, table partitionedTable, partitions int) error {
+	// the partitions of the previous partitioning, if any, are dropped with its table, so the
+	// names of the new ones include their number
+	var statements []string
+	for i := range partitions {
+		statements = append(statements, fmt.Sprintf(`CREATE TABLE %s_p%d_%d PARTITION OF %s_partitioned FOR VALUES WITH (MODULUS %d, REMAINDER %d)`,
+			table.name, partitions, i, table.name, partitions, i))
+	}
+	return replaceWithPartitionedTable(ctx, db, table, "HASH (store)", statements)
+}
+
+// replaceWithPartitionedTable replaces a table with a table partitioned with the given partition
+// clause, created with the statements creating its partitions, in a transaction that locks it.
+func replaceWithPartitionedTable(ctx context.Context, db *sql.DB, table partitionedTable, partitionBy string, partitionStatements []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	partitioned := table.name + "_partitioned"
+	statements := []string{
+		fmt.Sprintf(`LOCK TABLE %s IN EXCLUSIVE MODE`, table.name),
+		fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY %s`, partitioned, table.name, partitionBy),
+		fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT %s_pkey PRIMARY KEY (%s)`, partitioned, partitioned, strings.Join(table.primaryKey, ", ")),
+	}
+	statements = append(statements, partitionStatements...)
+	statements = append(statements,
+		fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s`, partitioned, table.name),
+		fmt.Sprintf(`DROP TABLE %s`, table.name),
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, partitioned, table.name),
+		fmt.Sprintf(`ALTER TABLE %s RENAME CONSTRAINT %s_pkey TO %s_pkey`, table.name, partitioned, table.name),
+	)
+	statements = append(statements, table.indexes...)
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// changelogPartitionsAhead is the time for which the partitions of the changes to come are
+// created in advance, so that the changes are not inserted into the default partition.
+const changelogPartitionsAhead = 2 * storage.ChangelogPartitionInterval
+
+// PartitionChangelogByTime partitions the changelog table by the ULID, i.e. the time, of its
+// changes into partitions of storage.ChangelogPartitionInterval, so that the retention of the
+// changelog drops its old partitions instead of deleting its changes one by one. It replaces the
+// partitioning of the changelog by store, if any. The partitions of the existing changes are
+// created, and a default partition receives the changes outside of the created partitions. The
+// changelog is copied into its partitions in a transaction that locks it, so the writes wait for
+// the copy.
+func PartitionChangelogByTime(ctx context.Context, db *sql.DB) error {
+	strategy, _, err := tablePartitioning(ctx, db, changelogTable.name)
+	if err != nil {
+		return err
+	}
+	if strategy == rangePartitioning {
+		return nil
+	}
+
+	now := time.Now()
+	oldest := now
+	var oldestULID sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT min(ulid) FROM changelog`).Scan(&oldestULID); err != nil {
+		return err
+	}
+	if id, err := ulid.Parse(oldestULID.String); oldestULID.Valid && err == nil {
+		oldest = ulid.Time(id.Time())
+	}
+
+	statements := []string{`CREATE TABLE changelog_default PARTITION OF changelog_partitioned DEFAULT WITH (` + strings.Join(maintenanceOptions(), ", ") + `)`}
+	for _, start := range sqlcommon.ChangelogPartitionsUntil(oldest, now.Add(changelogPartitionsAhead)) {
+		statements = append(statements, changelogPartitionStatement("changelog_partitioned", start))
+	}
+	if err := replaceWithPartitionedTable(ctx, db, changelogTable, "RANGE (ulid)", statements); err != nil {
+		return fmt.Errorf("failed to partition table %q: %w", changelogTable.name, err)
+	}
+	return nil
+}
+
+// changelogPartitionStatement returns the statement creating the changelog partition of the changes
+// from the given start, with the recommended maintenance settings so that the partitions created
+// by the retention are tuned as the existing ones.
+func changelogPartitionStatement(parent string, start time.Time) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS changelog_%s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s') WITH (%s)`,
+		sqlcommon.ChangelogPartitionName(start), parent,
+		storage.ChangelogPartitionBound(start), storage.ChangelogPartitionBound(start.Add(storage.ChangelogPartitionInterval)),
+		strings.Join(maintenanceOptions(), ", "))
+}
+
+// changelogPartitioned returns the changelog as a *sql.DB, or errors.ErrUnsupported if it is not
+// partitioned by time.
+func (s *Datastore) changelogPartitioned(ctx context.Context) (*sql.DB, error) {
+	db := stdlib.OpenDBFromPool(s.primaryDB)
+	strategy, _, err := tablePartitioning(ctx, db, changelogTable.name)
+	if err == nil && strategy != rangePartitioning {
+		err = errors.ErrUnsupported
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// CreateChangelogPartitions see [storage.ChangelogPartitioner].CreateChangelogPartitions.
+func (s *Datastore) CreateChangelogPartitions(ctx context.Context, until time.Time) error {
+	ctx, span := startTrace(ctx, "CreateChangelogPartitions")
+	defer span.End()
+
+	db, err := s.changelogPartitioned(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, start := range sqlcommon.ChangelogPartitionsUntil(time.Now(), until) {
+		if _, err := db.ExecContext(ctx, changelogPartitionStatement(changelogTable.name, start)); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+	return nil
+}
+
+// DropChangelogPartitions see [storage.ChangelogPartitioner].DropChangelogPartitions.
+func (s *Datastore) DropChangelogPartitions(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := startTrace(ctx, "DropChangelogPartitions")
+	defer span.End()
+
+	db, err := s.changelogPartitioned(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass('changelog')`)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	var expired []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return 0, HandleSQLError(err)
+		}
+		partition, _ := strings.CutPrefix(name, changelogTable.name+"_")
+		if start, ok := sqlcommon.ParseChangelogPartitionName(partition); ok && !start.Add(storage.ChangelogPartitionInterval).After(before) {
+			expired = append(expired, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, HandleSQLError(err)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	for i, name := range expired {
+		if _, err := db.ExecContext(ctx, `DROP TABLE `+name); err != nil {
+			return i, HandleSQLError(err)
+		}
+	}
+	return len(expired), nil
+}
@@ -60,6 +60,11 @@ func (p *PgxTxnIterQuery) GetRows(ctx context.Context) (sqlcommon.Rows, error) {
 	return &pgxRowsWrapper{rows: rows}, nil
 }
 
+// ToSql returns the query and the arguments of the txn query.
+func (p *PgxTxnIterQuery) ToSql() (string, []interface{}, error) {
+	return p.query, p.args, nil
+}
+
 // pgxRowsWrapper wraps pgx.Rows to implement sqlcommon.Rows interface.
 type pgxRowsWrapper struct {
 	rows pgx.Rows
@@ -9,7 +9,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -54,11 +56,31 @@ type Datastore struct {
 	maxTuplesPerWriteField    int
 	maxTypesPerModelField     int
 	versionReady              bool
+	indexAdvisor              *sqlcommon.IndexAdvisor
+	reverseUserIndex          bool
 }
 
 // Ensures that Datastore implements the OpenFGADatastore interface.
 var _ storage.OpenFGADatastore = (*Datastore)(nil)
 
+// Ensures that Datastore implements the SetOperationReader interface.
+var _ storage.SetOperationReader = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ChangelogPruner interface.
+var _ storage.ChangelogPruner = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ChangelogPartitioner interface.
+var _ storage.ChangelogPartitioner = (*Datastore)(nil)
+
+// Ensures that Datastore implements the JobBackend interface.
+var _ storage.JobBackend = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ScheduleBackend interface.
+var _ storage.ScheduleBackend = (*Datastore)(nil)
+
+// Ensures that Datastore implements the MaterializedPermissionBackend interface.
+var _ storage.MaterializedPermissionBackend = (*Datastore)(nil)
+
 func parseConfig(uri string, override bool, cfg *sqlcommon.Config) (*pgxpool.Config, error) {
 	c, err := pgxpool.ParseConfig(uri)
 	if err != nil {
@@ -107,10 +129,42 @@ func parseConfig(uri string, override bool, cfg *sqlcommon.Config) (*pgxpool.Con
 		c.MaxConnIdleTime = cfg.ConnMaxIdleTime
 	}
 
+	if cfg.TransactionPooling {
+		// the statements prepared by the default mode belong to the session of the connection,
+		// which is assigned to the transactions of other clients by PgBouncer
+		c.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+		c.ConnConfig.StatementCacheCapacity = 0
+		c.ConnConfig.DescriptionCacheCapacity = 0
+	}
+
 	c.BeforeConnect = createBeforeConnect(cfg.Logger, &FSPassfileProvider{cfg.Logger, os.UserHomeDir, FileOpener})
+	provider := cfg.PasswordProvider
+	if cfg.IAMAuthMethod != "" {
+		provider, err = sqlcommon.NewAuthTokenProvider(cfg.IAMAuthMethod, cfg.IAMAuthRegion)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if provider != nil {
+		c.BeforeConnect = createAuthTokenBeforeConnect(provider)
+	}
 	return c, nil
 }
 
+// createAuthTokenBeforeConnect authenticates every new connection with a token of the provider
+// instead of the password, so that the connections opened after the expiry of an IAM token, or
+// the rotation of a password, authenticate with the refreshed one.
+func createAuthTokenBeforeConnect(provider sqlcommon.AuthTokenProvider) func(ctx context.Context, conn *pgx.ConnConfig) error {
+	return func(ctx context.Context, config *pgx.ConnConfig) error {
+		token, err := provider.AuthToken(ctx, config.Host, int(config.Port), config.User)
+		if err != nil {
+			return fmt.Errorf("pgxpool BeforeConnect hook - failed to get the authentication token: %w", err)
+		}
+		config.Password = token
+		return nil
+	}
+}
+
 var ErrNoPassfile = errors.New("passfile does not exist")
 var ErrInsecurePassfilePermissions = errors.New("passfile permissions are too permissive")
 
@@ -282,7 +336,16 @@ func NewWithDB(primaryDB, secondaryDB *pgxpool.Pool, cfg *sqlcommon.Config) (*Da
 		}
 	}
 
-	return &Datastore{
+	var indexAdvisor *sqlcommon.IndexAdvisor
+	if cfg.IndexAdvisorSlowQueryThreshold > 0 {
+		indexAdvisor = sqlcommon.NewIndexAdvisor(cfg.IndexAdvisorSlowQueryThreshold, explainFullScans(primaryDB), cfg.Logger)
+	}
+
+	if err := verifyReverseUserIndex(context.Background(), primaryDB, cfg.ReverseUserIndex); err != nil {
+		return nil, err
+	}
+
+	ds := &Datastore{
 		primaryDB:                 primaryDB,
 		secondaryDB:               secondaryDB,
 		logger:                    cfg.Logger,
@@ -291,7 +354,56 @@ func NewWithDB(primaryDB, secondaryDB *pgxpool.Pool, cfg *sqlcommon.Config) (*Da
 		maxTuplesPerWriteField:    cfg.MaxTuplesPerWriteField,
 		maxTypesPerModelField:     cfg.MaxTypesPerModelField,
 		versionReady:              false,
-	}, nil
+		indexAdvisor:              indexAdvisor,
+		reverseUserIndex:          cfg.ReverseUserIndex,
+	}
+
+	if cfg.MaintenanceTuning {
+		if err := ds.tuneMaintenance(context.Background()); err != nil {
+			cfg.Logger.Warn("failed to tune the maintenance of the datastore tables", zap.Error(err))
+		}
+	}
+	sqlcommon.CheckMaintenance(context.Background(), ds, cfg.Logger)
+
+	return ds, nil
+}
+
+var seqScanRegex = regexp.MustCompile(`Seq Scan on (\w+)`)
+
+// explainFullScans returns the sqlcommon.ExplainFunc of db, which reports the sequential scans of
+// the plans.
+func explainFullScans(db PgxQuery) sqlcommon.ExplainFunc {
+	return func(ctx context.Context, query string, args []interface{}) ([]string, error) {
+		rows, err := db.Query(ctx, "EXPLAIN "+query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var plan []string
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				return nil, err
+			}
+			plan = append(plan, line)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return seqScanTables(plan), nil
+	}
+}
+
+// seqScanTables returns the tables that the lines of a plan scan sequentially.
+func seqScanTables(plan []string) []string {
+	var tables []string
+	for _, line := range plan {
+		if match := seqScanRegex.FindStringSubmatch(line); match != nil && !slices.Contains(tables, match[1]) {
+			tables = append(tables, match[1])
+		}
+	}
+	return tables
 }
 
 func (s *Datastore) isSecondaryConfigured() bool {
@@ -300,6 +412,7 @@ func (s *Datastore) isSecondaryConfigured() bool {
 
 // Close see [storage.OpenFGADatastore].Close.
 func (s *Datastore) Close() {
+	s.indexAdvisor.Close()
 	if s.primaryDBStatsCollector != nil {
 		prometheus.Unregister(s.primaryDBStatsCollector)
 	}
@@ -409,7 +522,7 @@ func (s *Datastore) read(ctx context.Context, store string, filter storage.ReadF
 		return nil, HandleSQLError(err)
 	}
 
-	return sqlcommon.NewSQLTupleIterator(poolGetRows, HandleSQLError), nil
+	return sqlcommon.NewSQLTupleIterator(s.indexAdvisor.Advise(poolGetRows), HandleSQLError), nil
 }
 
 // Write see [storage.RelationshipTupleWriter].Write.
@@ -482,8 +595,85 @@ func executeDeleteTuples(ctx context.Context, txn PgxExec, store string, deleteC
 	return nil
 }
 
+// minCopyFromRows is the number of rows from which the tuples and the changelog of a write are
+// inserted with COPY FROM instead of multi-row INSERT statements: COPY streams the rows without
+// parsing them as SQL, which is much faster for large writes, but it costs an additional round
+// trip to resolve the insertion time of the rows.
+const minCopyFromRows = 500
+
+var tupleColumns = []string{
+	"store",
+	"object_type",
+	"object_id",
+	"relation",
+	"_user",
+	"user_type",
+	"condition_name",
+	"condition_context",
+	"ulid",
+	"inserted_at",
+}
+
+var changelogColumns = []string{
+	"store",
+	"object_type",
+	"object_id",
+	"relation",
+	"_user",
+	"condition_name",
+	"condition_context",
+	"operation",
+	"ulid",
+	"inserted_at",
+}
+
+// PgxCopier interface allows the pgx CopyFrom functionality, along with the QueryRow that resolves
+// the SQL expressions of the copied rows.
+type PgxCopier interface {
+	PgxExec
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// copyFromItems inserts items in table with COPY FROM. The items are the values of the columns of
+// an INSERT statement, in which the NOW() expressions are replaced with the time of the
+// transaction, as COPY only accepts values.
+func copyFromItems(ctx context.Context, txn PgxCopier, table string, columns []string, items [][]interface{}) error {
+	var now time.Time
+	if err := txn.QueryRow(ctx, "SELECT NOW()").Scan(&now); err != nil {
+		return HandleSQLError(err)
+	}
+
+	rows := make([][]interface{}, len(items))
+	for i, item := range items {
+		row := make([]interface{}, len(item))
+		for j, value := range item {
+			if _, ok := value.(sq.Sqlizer); ok {
+				value = now
+			}
+			row[j] = value
+		}
+		rows[i] = row
+	}
+
+	_, err := txn.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
 // For the prepared writeItems, execute insert writeItems.
 func executeWriteTuples(ctx context.Context, txn PgxExec, writeItems [][]interface{}) error {
+	if copier, ok := txn.(PgxCopier); ok && len(writeItems) >= minCopyFromRows {
+		err := copyFromItems(ctx, copier, "tuple", tupleColumns, writeItems)
+		if errors.Is(err, storage.ErrCollision) {
+			// ErrCollision is returned on duplicate write (constraint violation), meaning we hit a race condition - someone else inserted the same row(s).
+			return storage.ErrWriteConflictOnInsert
+		}
+		return err
+	}
+
 	stbl := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	for start, totalWrites := 0, len(writeItems); start < totalWrites; start += storage.DefaultMaxTuplesPerWrite {
@@ -496,18 +686,7 @@ func executeWriteTuples(ctx context.Context, txn PgxExec, writeItems [][]interfa
 
 		insertBuilder := stbl.
 			Insert("tuple").
-			Columns(
-				"store",
-				"object_type",
-				"object_id",
-				"relation",
-				"_user",
-				"user_type",
-				"condition_name",
-				"condition_context",
-				"ulid",
-				"inserted_at",
-			)
+			Columns(tupleColumns...)
 
 		for _, item := range writesBatch {
 			insertBuilder = insertBuilder.Values(item...)
@@ -532,7 +711,55 @@ func executeWriteTuples(ctx context.Context, txn PgxExec, writeItems [][]interfa
 	return nil
 }
 
+// executeWriteReverseUserIndex replaces the rows of the sqlcommon.ReverseUserIndexTable of the
+// tuples of a write.
+func executeWriteReverseUserIndex(ctx context.Context, txn PgxExec, store string, deleteConditions sq.Or, writeItems [][]interface{}) error {
+	stbl := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	conditions := sqlcommon.ReverseUserIndexDeleteConditions(deleteConditions, writeItems)
+	for start := 0; start < len(conditions); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(conditions))
+
+		stmt, args, err := stbl.Delete(sqlcommon.ReverseUserIndexTable).Where(sq.Eq{"store": store}).
+			Where(conditions[start:end]).ToSql()
+		if err != nil {
+			return HandleSQLError(err)
+		}
+		if _, err := txn.Exec(ctx, stmt, args...); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	if copier, ok := txn.(PgxCopier); ok && len(writeItems) >= minCopyFromRows {
+		return copyFromItems(ctx, copier, sqlcommon.ReverseUserIndexTable, tupleColumns, writeItems)
+	}
+
+	for start := 0; start < len(writeItems); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(writeItems))
+
+		insertBuilder := stbl.
+			Insert(sqlcommon.ReverseUserIndexTable).
+			Columns(tupleColumns...)
+		for _, item := range writeItems[start:end] {
+			insertBuilder = insertBuilder.Values(item...)
+		}
+
+		stmt, args, err := insertBuilder.ToSql()
+		if err != nil {
+			return HandleSQLError(err)
+		}
+		if _, err := txn.Exec(ctx, stmt, args...); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+	return nil
+}
+
 func executeInsertChanges(ctx context.Context, txn PgxExec, changeLogItems [][]interface{}) error {
+	if copier, ok := txn.(PgxCopier); ok && len(changeLogItems) >= minCopyFromRows {
+		return copyFromItems(ctx, copier, "changelog", changelogColumns, changeLogItems)
+	}
+
 	stbl := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	for start, totalItems := 0, len(changeLogItems); start < totalItems; start += storage.DefaultMaxTuplesPerWrite {
 		end := start + storage.DefaultMaxTuplesPerWrite
@@ -544,18 +771,7 @@ func executeInsertChanges(ctx context.Context, txn PgxExec, changeLogItems [][]i
 
 		changelogBuilder := stbl.
 			Insert("changelog").
-			Columns(
-				"store",
-				"object_type",
-				"object_id",
-				"relation",
-				"_user",
-				"condition_name",
-				"condition_context",
-				"operation",
-				"ulid",
-				"inserted_at",
-			)
+			Columns(changelogColumns...)
 
 		for _, item := range changeLogBatch {
 			changelogBuilder = changelogBuilder.Values(item...)
@@ -629,6 +845,13 @@ func (s *Datastore) write(
 		return err
 	}
 
+	if s.reverseUserIndex {
+		err = executeWriteReverseUserIndex(ctx, txn, store, deleteConditions, writeItems)
+		if err != nil {
+			return err
+		}
+	}
+
 	// 5. Execute INSERT changelog statements
 	err = executeInsertChanges(ctx, txn, changeLogItems)
 	if err != nil {
@@ -766,7 +989,7 @@ func (s *Datastore) ReadUsersetTuples(
 		return nil, HandleSQLError(err)
 	}
 
-	return sqlcommon.NewSQLTupleIterator(poolGetRows, HandleSQLError), nil
+	return sqlcommon.NewSQLTupleIterator(s.indexAdvisor.Advise(poolGetRows), HandleSQLError), nil
 }
 
 // ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
@@ -790,13 +1013,18 @@ func (s *Datastore) ReadStartingWithUser(
 		targetUsersArg = append(targetUsersArg, targetUser)
 	}
 
+	table := "tuple"
+	if s.reverseUserIndex {
+		table = sqlcommon.ReverseUserIndexTable
+	}
+
 	builder := readStbl.
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
 			"condition_name", "condition_context", "ulid", "inserted_at",
 		).
-		From("tuple").
+		From(table).
 		Where(sq.Eq{
 			"store":       store,
 			"object_type": filter.ObjectType,
@@ -814,7 +1042,536 @@ func (s *Datastore) ReadStartingWithUser(
 	if err != nil {
 		return nil, HandleSQLError(err)
 	}
-	return sqlcommon.NewSQLTupleIterator(poolGetRows, HandleSQLError), nil
+	return sqlcommon.NewSQLTupleIterator(s.indexAdvisor.Advise(poolGetRows), HandleSQLError), nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Datastore) PruneChanges(ctx context.Context, store string, options storage.PruneChangesOptions) (int64, error) {
+	ctx, span := startTrace(ctx, "PruneChanges")
+	defer span.End()
+
+	db := s.primaryDB
+
+	var nthLatest string
+	if options.KeepLatest > 0 {
+		stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Select("ulid").
+			From("changelog").
+			Where(sq.Eq{"store": store}).
+			OrderBy("ulid desc").
+			Limit(1).
+			Offset(uint64(options.KeepLatest - 1)).
+			ToSql()
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
+		err = db.QueryRow(ctx, stmt, args...).Scan(&nthLatest)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return 0, HandleSQLError(err)
+		}
+	}
+
+	cutoff := storage.PruneChangesCutoff(options, nthLatest)
+	if cutoff == "" {
+		return 0, nil
+	}
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("changelog").
+		Where(sq.Eq{"store": store}).
+		Where(sq.Lt{"ulid": cutoff}).
+		ToSql()
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	res, err := db.Exec(ctx, stmt, args...)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// WriteJob see [storage.JobBackend].WriteJob.
+func (s *Datastore) WriteJob(ctx context.Context, job *storage.Job) error {
+	ctx, span := startTrace(ctx, "WriteJob")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("job").
+		Columns("store", "ulid", "kind", "done", "payload", "updated_at").
+		Values(job.Store, job.ID, job.Kind, job.Done, job.Payload, sq.Expr("NOW()")).
+		Suffix("ON CONFLICT (store, ulid) DO UPDATE SET kind = EXCLUDED.kind, done = EXCLUDED.done, payload = EXCLUDED.payload, updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadJob see [storage.JobBackend].ReadJob.
+func (s *Datastore) ReadJob(ctx context.Context, store, id string) (*storage.Job, error) {
+	ctx, span := startTrace(ctx, "ReadJob")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("store", "ulid", "kind", "done", "cancel_requested", "payload", "updated_at").
+		From("job").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	var job storage.Job
+	err = s.primaryDB.QueryRow(ctx, stmt, args...).
+		Scan(&job.Store, &job.ID, &job.Kind, &job.Done, &job.CancelRequested, &job.Payload, &job.UpdatedAt)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return &job, nil
+}
+
+// ListJobs see [storage.JobBackend].ListJobs.
+func (s *Datastore) ListJobs(ctx context.Context, store string) ([]*storage.Job, error) {
+	ctx, span := startTrace(ctx, "ListJobs")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("store", "ulid", "kind", "done", "cancel_requested", "payload", "updated_at").
+		From("job").
+		Where(sq.Eq{"store": store}).
+		OrderBy("ulid").
+		ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	rows, err := s.primaryDB.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var jobs []*storage.Job
+	for rows.Next() {
+		var job storage.Job
+		err := rows.Scan(&job.Store, &job.ID, &job.Kind, &job.Done, &job.CancelRequested, &job.Payload, &job.UpdatedAt)
+		if err != nil {
+			return nil, HandleSQLError(err)
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return jobs, nil
+}
+
+// CancelJob see [storage.JobBackend].CancelJob.
+func (s *Datastore) CancelJob(ctx context.Context, store, id string) error {
+	ctx, span := startTrace(ctx, "CancelJob")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("job").
+		Set("cancel_requested", true).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	res, err := s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if res.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteJobs see [storage.JobBackend].DeleteJobs.
+func (s *Datastore) DeleteJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	ctx, span := startTrace(ctx, "DeleteJobs")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("job").
+		Where(sq.Eq{"done": true}).
+		Where(fmt.Sprintf("updated_at < NOW() - interval '%dms'", time.Since(updatedBefore).Milliseconds())).
+		ToSql()
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	res, err := s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// WriteSchedule see [storage.ScheduleBackend].WriteSchedule.
+func (s *Datastore) WriteSchedule(ctx context.Context, schedule *storage.Schedule) error {
+	ctx, span := startTrace(ctx, "WriteSchedule")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("job_schedule").
+		Columns("store", "ulid", "payload").
+		Values(schedule.Store, schedule.ID, schedule.Payload).
+		Suffix("ON CONFLICT (store, ulid) DO UPDATE SET payload = EXCLUDED.payload").
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadSchedule see [storage.ScheduleBackend].ReadSchedule.
+func (s *Datastore) ReadSchedule(ctx context.Context, store, id string) (*storage.Schedule, error) {
+	ctx, span := startTrace(ctx, "ReadSchedule")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("store", "ulid", "payload", "last_run_at", "last_job_id").
+		From("job_schedule").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	schedule, err := scanSchedule(s.primaryDB.QueryRow(ctx, stmt, args...))
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return schedule, nil
+}
+
+// ListSchedules see [storage.ScheduleBackend].ListSchedules.
+func (s *Datastore) ListSchedules(ctx context.Context, store string) ([]*storage.Schedule, error) {
+	ctx, span := startTrace(ctx, "ListSchedules")
+	defer span.End()
+
+	sb := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("store", "ulid", "payload", "last_run_at", "last_job_id").
+		From("job_schedule").
+		OrderBy("store", "ulid")
+	if store != "" {
+		sb = sb.Where(sq.Eq{"store": store})
+	}
+	stmt, args, err := sb.ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	rows, err := s.primaryDB.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var schedules []*storage.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, HandleSQLError(err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule see [storage.ScheduleBackend].DeleteSchedule.
+func (s *Datastore) DeleteSchedule(ctx context.Context, store, id string) error {
+	ctx, span := startTrace(ctx, "DeleteSchedule")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("job_schedule").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	res, err := s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if res.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// RecordScheduleRun see [storage.ScheduleBackend].RecordScheduleRun.
+func (s *Datastore) RecordScheduleRun(ctx context.Context, store, id string, runAt time.Time, jobID string) error {
+	ctx, span := startTrace(ctx, "RecordScheduleRun")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("job_schedule").
+		Set("last_run_at", runAt.UTC()).
+		Set("last_job_id", jobID).
+		Where(sq.Eq{"store": store, "ulid": id}).
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	res, err := s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if res.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// AcquireLease see [storage.ScheduleBackend].AcquireLease.
+func (s *Datastore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	ctx, span := startTrace(ctx, "AcquireLease")
+	defer span.End()
+
+	expiresAt := fmt.Sprintf("NOW() + interval '%dms'", ttl.Milliseconds())
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("lease").
+		Columns("name", "holder", "expires_at").
+		Values(name, holder, sq.Expr(expiresAt)).
+		Suffix("ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at WHERE lease.holder = EXCLUDED.holder OR lease.expires_at < NOW()").
+		ToSql()
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+
+	res, err := s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+// scanSchedule scans a row of the job_schedule table, whose runs are NULL until recorded.
+func scanSchedule(row pgx.Row) (*storage.Schedule, error) {
+	var schedule storage.Schedule
+	var lastRunAt *time.Time
+	var lastJobID *string
+	if err := row.Scan(&schedule.Store, &schedule.ID, &schedule.Payload, &lastRunAt, &lastJobID); err != nil {
+		return nil, err
+	}
+	if lastRunAt != nil {
+		schedule.LastRunAt = lastRunAt.UTC()
+	}
+	if lastJobID != nil {
+		schedule.LastJobID = *lastJobID
+	}
+	return &schedule, nil
+}
+
+// ReadMaterializedRelation see [storage.MaterializedPermissionBackend].ReadMaterializedRelation.
+func (s *Datastore) ReadMaterializedRelation(ctx context.Context, store, objectType, relation string) (*storage.MaterializedRelation, error) {
+	ctx, span := startTrace(ctx, "ReadMaterializedRelation")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("store", "object_type", "relation", "authorization_model_id", "continuation_token", "updated_at").
+		From("materialized_relation").
+		Where(sq.Eq{"store": store, "object_type": objectType, "relation": relation}).
+		ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	var state storage.MaterializedRelation
+	err = s.primaryDB.QueryRow(ctx, stmt, args...).
+		Scan(&state.Store, &state.ObjectType, &state.Relation, &state.ModelID, &state.ContinuationToken, &state.UpdatedAt)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	state.UpdatedAt = state.UpdatedAt.UTC()
+	return &state, nil
+}
+
+// WriteMaterializedPermissions see [storage.MaterializedPermissionBackend].WriteMaterializedPermissions.
+func (s *Datastore) WriteMaterializedPermissions(ctx context.Context, relation *storage.MaterializedRelation, update storage.MaterializedPermissionsUpdate) error {
+	ctx, span := startTrace(ctx, "WriteMaterializedPermissions")
+	defer span.End()
+
+	txn, err := s.primaryDB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() { _ = txn.Rollback(ctx) }()
+
+	stbl := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	key := sq.Eq{"store": relation.Store, "object_type": relation.ObjectType, "relation": relation.Relation}
+
+	if update.Reset || len(update.ObjectIDs) > 0 {
+		deleteBuilder := stbl.Delete("materialized_permission").Where(key)
+		if !update.Reset {
+			deleteBuilder = deleteBuilder.Where(sq.Eq{"object_id": update.ObjectIDs})
+		}
+		stmt, args, err := deleteBuilder.ToSql()
+		if err != nil {
+			return HandleSQLError(err)
+		}
+		if _, err := txn.Exec(ctx, stmt, args...); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	for start := 0; start < len(update.Permissions); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(update.Permissions))
+		insertBuilder := stbl.
+			Insert("materialized_permission").
+			Columns("store", "object_type", "relation", "object_id", "_user").
+			Suffix("ON CONFLICT DO NOTHING")
+		for _, permission := range update.Permissions[start:end] {
+			insertBuilder = insertBuilder.Values(relation.Store, relation.ObjectType, relation.Relation, permission.ObjectID, permission.User)
+		}
+		stmt, args, err := insertBuilder.ToSql()
+		if err != nil {
+			return HandleSQLError(err)
+		}
+		if _, err := txn.Exec(ctx, stmt, args...); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	stmt, args, err := stbl.
+		Insert("materialized_relation").
+		Columns("store", "object_type", "relation", "authorization_model_id", "continuation_token", "updated_at").
+		Values(relation.Store, relation.ObjectType, relation.Relation, relation.ModelID, relation.ContinuationToken, relation.UpdatedAt.UTC()).
+		Suffix("ON CONFLICT (store, object_type, relation) DO UPDATE SET authorization_model_id = EXCLUDED.authorization_model_id, continuation_token = EXCLUDED.continuation_token, updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if _, err := txn.Exec(ctx, stmt, args...); err != nil {
+		return HandleSQLError(err)
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadMaterializedPermission see [storage.MaterializedPermissionBackend].ReadMaterializedPermission.
+func (s *Datastore) ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error) {
+	ctx, span := startTrace(ctx, "ReadMaterializedPermission")
+	defer span.End()
+
+	if len(users) == 0 {
+		return false, nil
+	}
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("1").
+		From("materialized_permission").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"relation":    relation,
+			"object_id":   objectID,
+			"_user":       users,
+		}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+
+	var found int
+	err = s.primaryDB.QueryRow(ctx, stmt, args...).Scan(&found)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	return true, nil
+}
+
+// DeleteMaterializedRelation see [storage.MaterializedPermissionBackend].DeleteMaterializedRelation.
+func (s *Datastore) DeleteMaterializedRelation(ctx context.Context, store, objectType, relation string) error {
+	ctx, span := startTrace(ctx, "DeleteMaterializedRelation")
+	defer span.End()
+
+	txn, err := s.primaryDB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() { _ = txn.Rollback(ctx) }()
+
+	key := sq.Eq{"store": store, "object_type": objectType, "relation": relation}
+	for _, table := range []string{"materialized_permission", "materialized_relation"} {
+		stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Delete(table).
+			Where(key).
+			ToSql()
+		if err != nil {
+			return HandleSQLError(err)
+		}
+		if _, err := txn.Exec(ctx, stmt, args...); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadObjectIDsWithSetOperation see [storage.SetOperationReader].ReadObjectIDsWithSetOperation.
+func (s *Datastore) ReadObjectIDsWithSetOperation(
+	ctx context.Context,
+	store string,
+	filter storage.SetOperationFilter,
+	options storage.ReadSetOperationOptions,
+) ([]string, error) {
+	ctx, span := startTrace(ctx, "ReadObjectIDsWithSetOperation")
+	defer span.End()
+
+	stmt, args, err := sqlcommon.SetOperationQuery{
+		Placeholder: sq.Dollar,
+		UserCondition: func(users []string) sq.Sqlizer {
+			return sq.Eq{"_user": users}
+		},
+	}.Build(store, filter, options.Limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	db := s.getPgxPool(options.Consistency.Preference)
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	ids, err := sqlcommon.ScanObjectIDs(&pgxRowsWrapper{rows})
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return ids, nil
 }
 
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
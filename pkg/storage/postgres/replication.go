@@ -0,0 +1,409 @@
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// DefaultReplicationPublication is the default name of the publication of the tuple table that a
+// ReplicationListener subscribes to.
+const DefaultReplicationPublication = "openfga_tuple_changes"
+
+// replicationStatusInterval is the interval at which a ReplicationListener reports the position
+// of the changes it received, which Postgres expects within wal_sender_timeout.
+const replicationStatusInterval = 10 * time.Second
+
+// pgEpoch is the epoch of the timestamps of the logical replication protocol.
+var pgEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Ensures that ReplicationListener implements the ChangeListener interface.
+var _ storage.ChangeListener = (*ReplicationListener)(nil)
+
+// ReplicationListenerOption defines an option that can be used to change the behavior of a
+// ReplicationListener.
+type ReplicationListenerOption func(*ReplicationListener)
+
+// WithReplicationPublication sets the name of the publication of the tuple table to subscribe to.
+// Defaults to DefaultReplicationPublication.
+func WithReplicationPublication(publication string) ReplicationListenerOption {
+	return func(l *ReplicationListener) {
+		l.publication = publication
+	}
+}
+
+// ReplicationListener is a [storage.ChangeListener] that subscribes to the logical replication of
+// the tuple table, decoded by the built-in pgoutput plugin.
+//
+// The database must run with wal_level=logical, the user must have the REPLICATION attribute, and
+// the publication must exist, e.g.:
+//
+//	CREATE PUBLICATION openfga_tuple_changes FOR TABLE tuple;
+//
+// Every call to Listen creates a temporary replication slot, which Postgres drops when the
+// connection closes: no WAL is retained for the changes committed between two calls.
+type ReplicationListener struct {
+	uri         string
+	cfg         *sqlcommon.Config
+	publication string
+	logger      logger.Logger
+}
+
+// NewReplicationListener returns a ReplicationListener connecting to uri, with the username,
+// password and logger of cfg.
+func NewReplicationListener(uri string, cfg *sqlcommon.Config, opts ...ReplicationListenerOption) *ReplicationListener {
+	l := &ReplicationListener{
+		uri:         uri,
+		cfg:         cfg,
+		publication: DefaultReplicationPublication,
+		logger:      cfg.Logger,
+	}
+	if l.logger == nil {
+		l.logger = logger.NewNoopLogger()
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Listen see [storage.ChangeListener].Listen.
+func (l *ReplicationListener) Listen(ctx context.Context, handle func(store string, changes []*openfgav1.TupleChange)) error {
+	c, err := parseConfig(l.uri, l.cfg.Username != "" || l.cfg.Password != "", l.cfg)
+	if err != nil {
+		return err
+	}
+	// the replication connection isn't opened by the pool, which authenticates its connections
+	if err := c.BeforeConnect(ctx, c.ConnConfig); err != nil {
+		return err
+	}
+	connConfig := c.ConnConfig.Config.Copy()
+	connConfig.RuntimeParams["replication"] = "database"
+
+	conn, err := pgconn.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return fmt.Errorf("connect for replication: %w", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	slot := "openfga_" + strings.ToLower(ulid.Make().String())
+	_, err = conn.Exec(ctx, fmt.Sprintf("CREATE_REPLICATION_SLOT %s TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT", slot)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("create replication slot: %w", err)
+	}
+
+	conn.Frontend().SendQuery(&pgproto3.Query{String: fmt.Sprintf(
+		"START_REPLICATION SLOT %s LOGICAL 0/0 (proto_version '1', publication_names '%s')",
+		slot, strings.ReplaceAll(l.publication, "'", "''"),
+	)})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("start replication: %w", err)
+	}
+	if err := waitForCopyBoth(ctx, conn); err != nil {
+		return fmt.Errorf("start replication: %w", err)
+	}
+	l.logger.Info("subscribed to the logical replication of the tuples",
+		zap.String("slot", slot), zap.String("publication", l.publication))
+
+	decoder := newPgoutputDecoder()
+	var received uint64
+	nextStatus := time.Now().Add(replicationStatusInterval)
+	for {
+		if !time.Now().Before(nextStatus) {
+			if err := sendStandbyStatus(conn, received); err != nil {
+				return fmt.Errorf("send replication status: %w", err)
+			}
+			nextStatus = time.Now().Add(replicationStatusInterval)
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if pgconn.Timeout(err) {
+				continue
+			}
+			return fmt.Errorf("receive replication message: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			if len(msg.Data) == 0 {
+				continue
+			}
+			switch msg.Data[0] {
+			case 'k': // primary keepalive: walEnd, sendTime, replyRequested
+				if len(msg.Data) >= 18 && msg.Data[17] != 0 {
+					nextStatus = time.Time{}
+				}
+			case 'w': // XLogData: walStart, walEnd, sendTime, data
+				if len(msg.Data) < 25 {
+					return errors.New("invalid replication message")
+				}
+				committed, err := decoder.decode(msg.Data[25:])
+				if err != nil {
+					return err
+				}
+				received = max(received, binary.BigEndian.Uint64(msg.Data[1:9])+uint64(len(msg.Data)-25))
+				for _, c := range committed {
+					handle(c.store, c.changes)
+				}
+			}
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		case *pgproto3.CopyDone:
+			return errors.New("replication stream ended")
+		}
+	}
+}
+
+// waitForCopyBoth waits for the server to start streaming the replication.
+func waitForCopyBoth(ctx context.Context, conn *pgconn.PgConn) error {
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return nil
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		}
+	}
+}
+
+// sendStandbyStatus reports that the changes up to lsn were received, flushed and applied.
+func sendStandbyStatus(conn *pgconn.PgConn, lsn uint64) error {
+	data := make([]byte, 0, 34)
+	data = append(data, 'r')
+	data = binary.BigEndian.AppendUint64(data, lsn)
+	data = binary.BigEndian.AppendUint64(data, lsn)
+	data = binary.BigEndian.AppendUint64(data, lsn)
+	data = binary.BigEndian.AppendUint64(data, uint64(time.Since(pgEpoch).Microseconds()))
+	data = append(data, 0)
+
+	conn.Frontend().Send(&pgproto3.CopyData{Data: data})
+	return conn.Frontend().Flush()
+}
+
+// storeChanges are the changes of the tuples of a store in a transaction.
+type storeChanges struct {
+	store   string
+	changes []*openfgav1.TupleChange
+}
+
+// pgoutputRelation is a table described by a relation message.
+type pgoutputRelation struct {
+	name    string
+	columns []string
+}
+
+// pgoutputDecoder decodes the messages of the version 1 of the pgoutput protocol into the changes
+// of the tuples of the stores, which it returns when their transaction is committed.
+type pgoutputDecoder struct {
+	relations map[uint32]*pgoutputRelation
+
+	commitTime time.Time
+	stores     map[string]int // store => index in pending
+	pending    []storeChanges
+}
+
+func newPgoutputDecoder() *pgoutputDecoder {
+	return &pgoutputDecoder{
+		relations: map[uint32]*pgoutputRelation{},
+		stores:    map[string]int{},
+	}
+}
+
+// decode decodes a message, and returns the changes of its transaction if it is a commit message.
+func (d *pgoutputDecoder) decode(data []byte) ([]storeChanges, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty pgoutput message")
+	}
+	r := &pgoutputReader{data: data[1:]}
+
+	switch data[0] {
+	case 'B': // begin: final LSN, commit timestamp, xid
+		r.uint64()
+		d.commitTime = pgEpoch.Add(time.Duration(int64(r.uint64())) * time.Microsecond)
+		d.stores = map[string]int{}
+		d.pending = nil
+	case 'C': // commit
+		committed := d.pending
+		d.stores = map[string]int{}
+		d.pending = nil
+		return committed, nil
+	case 'R': // relation: id, namespace, name, replica identity, columns
+		id := r.uint32()
+		r.string()
+		relation := &pgoutputRelation{name: r.string()}
+		r.byte()
+		n := int(r.uint16())
+		for i := 0; i < n && r.err == nil; i++ {
+			r.byte()
+			relation.columns = append(relation.columns, r.string())
+			r.uint32()
+			r.uint32()
+		}
+		if r.err == nil {
+			d.relations[id] = relation
+		}
+	case 'I': // insert: relation id, 'N', new tuple
+		relation := d.relations[r.uint32()]
+		r.byte()
+		d.addChange(relation, r.tuple(), openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
+	case 'U': // update: relation id, optional 'K' or 'O' and old tuple, 'N', new tuple
+		relation := d.relations[r.uint32()]
+		if kind := r.byte(); kind == 'K' || kind == 'O' {
+			r.tuple()
+			r.byte()
+		}
+		d.addChange(relation, r.tuple(), openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
+	case 'D': // delete: relation id, 'K' or 'O', key or old tuple
+		relation := d.relations[r.uint32()]
+		r.byte()
+		d.addChange(relation, r.tuple(), openfgav1.TupleOperation_TUPLE_OPERATION_DELETE)
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid pgoutput message '%c': %w", data[0], r.err)
+	}
+	return nil, nil
+}
+
+// addChange adds the change of a row of relation if it is the tuple table.
+func (d *pgoutputDecoder) addChange(relation *pgoutputRelation, values []*string, operation openfgav1.TupleOperation) {
+	if relation == nil || relation.name != "tuple" {
+		return
+	}
+
+	columns := map[string]string{}
+	for i, value := range values {
+		if i < len(relation.columns) && value != nil {
+			columns[relation.columns[i]] = *value
+		}
+	}
+	store := columns["store"]
+	if store == "" {
+		return
+	}
+
+	i, ok := d.stores[store]
+	if !ok {
+		i = len(d.pending)
+		d.stores[store] = i
+		d.pending = append(d.pending, storeChanges{store: store})
+	}
+	d.pending[i].changes = append(d.pending[i].changes, &openfgav1.TupleChange{
+		TupleKey: tupleUtils.NewTupleKey(
+			tupleUtils.BuildObject(columns["object_type"], columns["object_id"]),
+			columns["relation"],
+			columns["_user"],
+		),
+		Operation: operation,
+		Timestamp: timestamppb.New(d.commitTime),
+	})
+}
+
+// pgoutputReader reads the fields of a pgoutput message, until the first error.
+type pgoutputReader struct {
+	data []byte
+	err  error
+}
+
+func (r *pgoutputReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errors.New("unexpected end of message")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *pgoutputReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *pgoutputReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *pgoutputReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *pgoutputReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// string reads a null-terminated string.
+func (r *pgoutputReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	for i, b := range r.data {
+		if b == 0 {
+			s := string(r.data[:i])
+			r.data = r.data[i+1:]
+			return s
+		}
+	}
+	r.err = errors.New("unterminated string")
+	return ""
+}
+
+// tuple reads the values of the columns of a row, which are nil if null or unchanged.
+func (r *pgoutputReader) tuple() []*string {
+	n := int(r.uint16())
+	values := make([]*string, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		switch r.byte() {
+		case 't', 'b':
+			length := r.uint32()
+			if b := r.next(int(length)); b != nil {
+				value := string(b)
+				values = append(values, &value)
+			}
+		default: // 'n' null, 'u' unchanged toasted value
+			values = append(values, nil)
+		}
+	}
+	return values
+}
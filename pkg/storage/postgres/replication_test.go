@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// pgoutputMessage builds a pgoutput message from its type and fields, which are bytes, uint16,
+// uint32, uint64, strings (null-terminated) or rows of nullable values.
+func pgoutputMessage(kind byte, fields ...any) []byte {
+	data := []byte{kind}
+	for _, field := range fields {
+		switch f := field.(type) {
+		case byte:
+			data = append(data, f)
+		case uint16:
+			data = binary.BigEndian.AppendUint16(data, f)
+		case uint32:
+			data = binary.BigEndian.AppendUint32(data, f)
+		case uint64:
+			data = binary.BigEndian.AppendUint64(data, f)
+		case string:
+			data = append(append(data, f...), 0)
+		case []*string:
+			data = binary.BigEndian.AppendUint16(data, uint16(len(f)))
+			for _, value := range f {
+				if value == nil {
+					data = append(data, 'n')
+					continue
+				}
+				data = append(data, 't')
+				data = binary.BigEndian.AppendUint32(data, uint32(len(*value)))
+				data = append(data, *value...)
+			}
+		}
+	}
+	return data
+}
+
+func row(values ...string) []*string {
+	res := make([]*string, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			res = append(res, nil)
+			continue
+		}
+		res = append(res, &value)
+	}
+	return res
+}
+
+func relationMessage(id uint32, name string, columns ...string) []byte {
+	fields := []any{id, "public", name, byte('d'), uint16(len(columns))}
+	for _, column := range columns {
+		fields = append(fields, byte(0), column, uint32(25), uint32(0xffffffff))
+	}
+	return pgoutputMessage('R', fields...)
+}
+
+func TestPgoutputDecoder(t *testing.T) {
+	decoder := newPgoutputDecoder()
+	commitTime := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	messages := [][]byte{
+		relationMessage(1, "tuple", "store", "object_type", "object_id", "relation", "_user", "user_type", "condition_name"),
+		relationMessage(2, "changelog", "store", "object_type"),
+		pgoutputMessage('B', uint64(100), uint64(commitTime.Sub(pgEpoch).Microseconds()), uint32(7)),
+		pgoutputMessage('I', uint32(1), byte('N'), row("store1", "document", "1", "viewer", "user:anne", "user", "")),
+		pgoutputMessage('I', uint32(2), byte('N'), row("store1", "document")),
+		pgoutputMessage('D', uint32(1), byte('K'), row("store2", "folder", "x", "owner", "group:eng#member", "", "")),
+		pgoutputMessage('U', uint32(1), byte('N'), row("store1", "document", "2", "editor", "user:bob", "user", "cond")),
+	}
+	for _, msg := range messages {
+		committed, err := decoder.decode(msg)
+		require.NoError(t, err)
+		require.Empty(t, committed)
+	}
+
+	committed, err := decoder.decode(pgoutputMessage('C', byte(0), uint64(100), uint64(120), uint64(0)))
+	require.NoError(t, err)
+	require.Len(t, committed, 2)
+
+	require.Equal(t, "store1", committed[0].store)
+	require.Len(t, committed[0].changes, 2)
+	require.Equal(t, "document:1#viewer@user:anne", tupleUtils.TupleKeyToString(committed[0].changes[0].GetTupleKey()))
+	require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, committed[0].changes[0].GetOperation())
+	require.Equal(t, commitTime, committed[0].changes[0].GetTimestamp().AsTime())
+	require.Equal(t, "document:2#editor@user:bob", tupleUtils.TupleKeyToString(committed[0].changes[1].GetTupleKey()))
+
+	require.Equal(t, "store2", committed[1].store)
+	require.Len(t, committed[1].changes, 1)
+	require.Equal(t, "folder:x#owner@group:eng#member", tupleUtils.TupleKeyToString(committed[1].changes[0].GetTupleKey()))
+	require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, committed[1].changes[0].GetOperation())
+
+	t.Run("the_next_transaction_starts_empty", func(t *testing.T) {
+		_, err := decoder.decode(pgoutputMessage('B', uint64(200), uint64(0), uint32(8)))
+		require.NoError(t, err)
+		committed, err := decoder.decode(pgoutputMessage('C', byte(0), uint64(200), uint64(220), uint64(0)))
+		require.NoError(t, err)
+		require.Empty(t, committed)
+	})
+
+	t.Run("truncated_message", func(t *testing.T) {
+		_, err := decoder.decode(pgoutputMessage('I', uint32(1), byte('N'), uint16(3)))
+		require.ErrorContains(t, err, "unexpected end of message")
+	})
+}
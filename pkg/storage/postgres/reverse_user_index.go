@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+)
+
+// reverseUserIndexStateTimeout bounds the read of the sqlcommon.ReverseUserIndexStateTable when
+// the datastore is opened.
+const reverseUserIndexStateTimeout = 10 * time.Second
+
+// ReconcileReverseUserIndex enables or disables the reverse user index of the datastore, see
+// [sqlcommon.ReconcileReverseUserIndex].
+func ReconcileReverseUserIndex(ctx context.Context, db *sql.DB, enabled bool) error {
+	return sqlcommon.ReconcileReverseUserIndex(ctx, db, sq.StatementBuilder.PlaceholderFormat(sq.Dollar), func(columns []string) string {
+		updates := make([]string, 0, len(columns))
+		for _, column := range columns {
+			updates = append(updates, column+" = EXCLUDED."+column)
+		}
+		return "ON CONFLICT (store, _user, object_type, relation, object_id) DO UPDATE SET " + strings.Join(updates, ", ")
+	}, enabled)
+}
+
+// verifyReverseUserIndex returns an error if the reverse user index is enabled in the datastore
+// and not in its configuration, or the reverse. It isn't enabled in the datastores that aren't
+// migrated to it.
+func verifyReverseUserIndex(ctx context.Context, db *pgxpool.Pool, enabled bool) error {
+	ctx, cancel := context.WithTimeout(ctx, reverseUserIndexStateTimeout)
+	defer cancel()
+
+	var enabledInDatastore bool
+	err := db.QueryRow(ctx, `SELECT enabled FROM `+sqlcommon.ReverseUserIndexStateTable).Scan(&enabledInDatastore)
+	var pgErr *pgconn.PgError
+	if err != nil && !(errors.As(err, &pgErr) && pgErr.Code == "42P01") { // undefined_table
+		return HandleSQLError(err)
+	}
+	return sqlcommon.VerifyReverseUserIndex(enabledInDatastore, enabled)
+}
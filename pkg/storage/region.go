@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// NewChangeEntropy returns the entropy of the ULIDs of the changes of a write that originates from
+// region, which ensures increasingly unique values within a single thread. It is not safe for
+// concurrent use.
+//
+// In a multi-region deployment, i.e. if region is not 0, the first byte of the entropy of the
+// ULIDs is the region, see ChangeRegion, so that the changes written at the same millisecond by
+// different regions never collide once replicated, and are ordered by region.
+func NewChangeEntropy(region uint8) io.Reader {
+	if region == 0 {
+		return ulid.DefaultEntropy()
+	}
+	return &regionEntropy{region: region}
+}
+
+// ChangeRegion returns the region encoded in the ULID of a change by a multi-region deployment.
+// It is meaningless for the changes of the other deployments.
+func ChangeRegion(id ulid.ULID) uint8 {
+	return id.Entropy()[0]
+}
+
+// regionEntropy is an ulid.MonotonicReader that prefixes its entropy with a region.
+type regionEntropy struct {
+	region uint8
+	ms     uint64
+	last   [9]byte
+	init   bool
+}
+
+// Read fills p with the region followed by random bytes.
+func (e *regionEntropy) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	p[0] = e.region
+	if _, err := io.ReadFull(rand.Reader, p[1:]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// MonotonicRead fills p with the region followed by bytes that are random for a new millisecond,
+// and that are incremented within the same millisecond.
+func (e *regionEntropy) MonotonicRead(ms uint64, p []byte) error {
+	if e.init && e.ms == ms {
+		i := len(e.last) - 1
+		for ; i >= 0; i-- {
+			e.last[i]++
+			if e.last[i] != 0 {
+				break
+			}
+		}
+		if i < 0 {
+			return ulid.ErrMonotonicOverflow
+		}
+	} else {
+		if _, err := io.ReadFull(rand.Reader, e.last[:]); err != nil {
+			return err
+		}
+		// leave room to increment within the millisecond
+		e.last[0] &= 0x7f
+		e.ms = ms
+		e.init = true
+	}
+
+	p[0] = e.region
+	copy(p[1:], e.last[:])
+	return nil
+}
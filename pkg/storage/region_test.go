@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeEntropy(t *testing.T) {
+	t.Run("encodes_the_region", func(t *testing.T) {
+		entropy := NewChangeEntropy(7)
+		now := ulid.Timestamp(time.Now())
+
+		var previous ulid.ULID
+		for i := 0; i < 100; i++ {
+			id := ulid.MustNew(now, entropy)
+			require.Equal(t, uint8(7), ChangeRegion(id))
+			require.Positive(t, id.Compare(previous))
+			previous = id
+		}
+	})
+
+	t.Run("orders_the_regions_within_a_millisecond", func(t *testing.T) {
+		now := ulid.Timestamp(time.Now())
+		id1 := ulid.MustNew(now, NewChangeEntropy(1))
+		id2 := ulid.MustNew(now, NewChangeEntropy(2))
+		require.Negative(t, id1.Compare(id2))
+	})
+
+	t.Run("no_region", func(t *testing.T) {
+		require.Equal(t, ulid.DefaultEntropy(), NewChangeEntropy(0))
+	})
+}
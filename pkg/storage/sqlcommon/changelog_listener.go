@@ -0,0 +1,177 @@
+package sqlcommon
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// DefaultChangelogListenerInterval is the default interval at which a ChangelogListener polls
+	// the changelog.
+	DefaultChangelogListenerInterval = time.Second
+
+	// DefaultChangelogListenerLookback is the default duration for which a ChangelogListener reads
+	// again the changes before its watermark.
+	DefaultChangelogListenerLookback = 5 * time.Second
+
+	changelogListenerPageSize = 1000
+)
+
+// Ensures that ChangelogListener implements the ChangeListener interface.
+var _ storage.ChangeListener = (*ChangelogListener)(nil)
+
+// ChangelogListenerOption defines an option that can be used to change the behavior of a
+// ChangelogListener.
+type ChangelogListenerOption func(*ChangelogListener)
+
+// WithChangelogListenerInterval sets the interval at which the changelog is polled. Defaults to
+// DefaultChangelogListenerInterval.
+func WithChangelogListenerInterval(interval time.Duration) ChangelogListenerOption {
+	return func(l *ChangelogListener) {
+		l.interval = interval
+	}
+}
+
+// WithChangelogListenerLookback sets the duration for which the changes before the watermark are
+// read again. Defaults to DefaultChangelogListenerLookback.
+func WithChangelogListenerLookback(lookback time.Duration) ChangelogListenerOption {
+	return func(l *ChangelogListener) {
+		l.lookback = lookback
+	}
+}
+
+// ChangelogListener is a [storage.ChangeListener] that polls the changelog table of all the stores
+// for the changes after a watermark, for the datastores that cannot push their changes.
+//
+// The ULIDs of the changes are generated before their transaction commits, and by the clocks of
+// the replicas that wrote them, so a change can be committed after a change with a greater ULID.
+// Every poll therefore reads again the changes of the lookback before the watermark, i.e. the start
+// of the previous poll, and only pushes the ones it has not pushed yet. A change committed more than
+// the lookback after its ULID was generated is missed.
+type ChangelogListener struct {
+	dbInfo   *DBInfo
+	interval time.Duration
+	lookback time.Duration
+}
+
+// NewChangelogListener returns a ChangelogListener reading the changelog table of dbInfo.
+func NewChangelogListener(dbInfo *DBInfo, opts ...ChangelogListenerOption) *ChangelogListener {
+	l := &ChangelogListener{
+		dbInfo:   dbInfo,
+		interval: DefaultChangelogListenerInterval,
+		lookback: DefaultChangelogListenerLookback,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Listen see [storage.ChangeListener].Listen. It pushes the changes committed after it is called,
+// and may push the ones committed during the lookback before.
+func (l *ChangelogListener) Listen(ctx context.Context, handle func(store string, changes []*openfgav1.TupleChange)) error {
+	watermark := time.Now()
+	pushed := map[string]time.Time{} // the ULIDs of the changes pushed after the watermark - lookback
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		started := time.Now()
+		from := watermark.Add(-l.lookback)
+		if err := l.poll(ctx, from, pushed, handle); err != nil {
+			return err
+		}
+
+		for id, ts := range pushed {
+			if ts.Before(from) {
+				delete(pushed, id)
+			}
+		}
+		watermark = started
+	}
+}
+
+// poll pushes the changes with a ULID after from that are not in pushed, and adds them to it.
+func (l *ChangelogListener) poll(ctx context.Context, from time.Time, pushed map[string]time.Time, handle func(string, []*openfgav1.TupleChange)) error {
+	var after ulid.ULID
+	if err := after.SetTime(ulid.Timestamp(from)); err != nil {
+		return err
+	}
+	fromUlid := after.String()
+
+	for {
+		rows, err := l.dbInfo.stbl.
+			Select("store", "ulid", "object_type", "object_id", "relation", "_user", "operation", "inserted_at").
+			From("changelog").
+			Where(sq.Gt{"ulid": fromUlid}).
+			OrderBy("ulid").
+			Limit(changelogListenerPageSize).
+			QueryContext(ctx)
+		if err != nil {
+			return l.dbInfo.HandleSQLError(err)
+		}
+
+		var stores []string
+		changes := map[string][]*openfgav1.TupleChange{}
+		read := 0
+		for rows.Next() {
+			var store, id, objectType, objectID, relation, user string
+			var operation int
+			var insertedAt time.Time
+			if err := rows.Scan(&store, &id, &objectType, &objectID, &relation, &user, &operation, &insertedAt); err != nil {
+				_ = rows.Close()
+				return l.dbInfo.HandleSQLError(err)
+			}
+			read++
+			fromUlid = id
+
+			if _, ok := pushed[id]; ok {
+				continue
+			}
+			parsed, err := ulid.Parse(id)
+			if err != nil {
+				_ = rows.Close()
+				return err
+			}
+			pushed[id] = ulid.Time(parsed.Time())
+
+			if _, ok := changes[store]; !ok {
+				stores = append(stores, store)
+			}
+			changes[store] = append(changes[store], &openfgav1.TupleChange{
+				TupleKey:  tupleUtils.NewTupleKey(tupleUtils.BuildObject(objectType, objectID), relation, user),
+				Operation: openfgav1.TupleOperation(operation),
+				Timestamp: timestamppb.New(insertedAt.UTC()),
+			})
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return l.dbInfo.HandleSQLError(err)
+		}
+
+		for _, store := range stores {
+			handle(store, changes[store])
+		}
+		if read < changelogListenerPageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestChangelogListener(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1) // every connection to :memory: opens a new database
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE changelog (
+		store CHAR(26) NOT NULL,
+		object_type VARCHAR(256) NOT NULL,
+		object_id VARCHAR(256) NOT NULL,
+		relation VARCHAR(50) NOT NULL,
+		_user VARCHAR(512) NOT NULL,
+		operation INTEGER NOT NULL,
+		ulid CHAR(26) NOT NULL,
+		inserted_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (store, ulid, object_type)
+	)`)
+	require.NoError(t, err)
+
+	stbl := sq.StatementBuilder.RunWith(db)
+	insert := func(store, object, relation, user string, operation openfgav1.TupleOperation, at time.Time) {
+		objectType, objectID := tupleUtils.SplitObject(object)
+		_, err := stbl.Insert("changelog").
+			Columns("store", "object_type", "object_id", "relation", "_user", "operation", "ulid", "inserted_at").
+			Values(store, objectType, objectID, relation, user, operation, ulid.MustNew(ulid.Timestamp(at), ulid.DefaultEntropy()).String(), at).
+			Exec()
+		require.NoError(t, err)
+	}
+
+	var mu sync.Mutex
+	pushed := map[string][]string{}
+	handle := func(store string, changes []*openfgav1.TupleChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, change := range changes {
+			pushed[store] = append(pushed[store], tupleUtils.TupleKeyToString(change.GetTupleKey()))
+		}
+	}
+	pushedTo := func(store string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), pushed[store]...)
+	}
+
+	// written before the lookback of the first poll
+	insert("store1", "document:0", "viewer", "user:zed", openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, time.Now().Add(-time.Minute))
+
+	listener := NewChangelogListener(&DBInfo{stbl: stbl, HandleSQLError: func(err error, _ ...interface{}) error { return err }},
+		WithChangelogListenerInterval(10*time.Millisecond),
+		WithChangelogListenerLookback(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- listener.Listen(ctx, handle)
+	}()
+
+	insert("store1", "document:1", "viewer", "user:anne", openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, time.Now())
+	insert("store2", "folder:x", "owner", "group:eng#member", openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, time.Now())
+	require.Eventually(t, func() bool {
+		return len(pushedTo("store1")) == 1 && len(pushedTo("store2")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// committed after a change with a greater ULID, within the lookback
+	insert("store1", "document:2", "editor", "user:bob", openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, time.Now().Add(-500*time.Millisecond))
+	require.Eventually(t, func() bool {
+		return len(pushedTo("store1")) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the changes are pushed only once, whatever the number of polls that read them
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, []string{"document:1#viewer@user:anne", "document:2#editor@user:bob"}, pushedTo("store1"))
+	require.Equal(t, []string{"folder:x#owner@group:eng#member"}, pushedTo("store2"))
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,44 @@
+package sqlcommon
+
+import (
+	"strings"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// changelogPartitionPrefix prefixes the names of the partitions of a changelog partitioned by time,
+// which are followed by the date of their changes.
+const changelogPartitionPrefix = "p"
+
+const changelogPartitionDateFormat = "20060102"
+
+// ChangelogPartitionName returns the name of the changelog partition of the changes inserted from
+// the given start, e.g. p20240131.
+func ChangelogPartitionName(start time.Time) string {
+	return changelogPartitionPrefix + start.UTC().Format(changelogPartitionDateFormat)
+}
+
+// ParseChangelogPartitionName returns the start of the changes of the changelog partition with the
+// given name, and false if it isn't the name of a partition returned by ChangelogPartitionName.
+func ParseChangelogPartitionName(name string) (time.Time, bool) {
+	date, ok := strings.CutPrefix(name, changelogPartitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(changelogPartitionDateFormat, date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// ChangelogPartitionsUntil returns the starts of the changelog partitions of the changes inserted
+// from the partition of from until the given time.
+func ChangelogPartitionsUntil(from, until time.Time) []time.Time {
+	var starts []time.Time
+	for start := storage.ChangelogPartitionStart(from); start.Before(until); start = start.Add(storage.ChangelogPartitionInterval) {
+		starts = append(starts, start)
+	}
+	return starts
+}
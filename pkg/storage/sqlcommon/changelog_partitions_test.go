@@ -0,0 +1,25 @@
+package sqlcommon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogPartitions(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, "p20240131", ChangelogPartitionName(start))
+
+	parsed, ok := ParseChangelogPartitionName("p20240131")
+	require.True(t, ok)
+	require.Equal(t, start, parsed)
+
+	for _, name := range []string{"pmax", "changelog_default", "p4_0", "20240131"} {
+		_, ok := ParseChangelogPartitionName(name)
+		require.False(t, ok, name)
+	}
+
+	require.Equal(t, []time.Time{start, start.AddDate(0, 0, 1)}, ChangelogPartitionsUntil(start.Add(time.Hour), start.AddDate(0, 0, 1).Add(time.Minute)))
+	require.Empty(t, ChangelogPartitionsUntil(start, start))
+}
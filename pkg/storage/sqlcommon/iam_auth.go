@@ -0,0 +1,192 @@
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awscredentials "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+)
+
+// The IAM authentication methods of the SQL datastores, whose connections authenticate with
+// short-lived tokens of the identity of the server instead of a long-lived password.
+const (
+	// IAMAuthAWSRDS authenticates with the AWS RDS IAM authentication tokens, signed with the
+	// credentials of the default AWS credential chain, e.g. the web identity of an EKS service
+	// account, the role of an ECS task or the instance profile of an EC2 instance.
+	IAMAuthAWSRDS = "aws_rds"
+
+	// IAMAuthGCPCloudSQL authenticates with the OAuth2 access tokens of the service account of the
+	// instance, from the GCP metadata server, for the Cloud SQL IAM database authentication.
+	IAMAuthGCPCloudSQL = "gcp_cloudsql"
+)
+
+// IAMAuthMethods are the supported IAM authentication methods.
+var IAMAuthMethods = []string{IAMAuthAWSRDS, IAMAuthGCPCloudSQL}
+
+// authTokenRefreshMargin is the time before their expiry from which the tokens are refreshed, so
+// that a connection never authenticates with a token that expires meanwhile.
+const authTokenRefreshMargin = 5 * time.Minute
+
+// AuthTokenProvider provides the tokens that authenticate the connections of a user to a
+// database server in place of its password.
+type AuthTokenProvider interface {
+	AuthToken(ctx context.Context, host string, port int, user string) (string, error)
+}
+
+// AuthTokenProviderFunc is an AuthTokenProvider of a function.
+type AuthTokenProviderFunc func(ctx context.Context, host string, port int, user string) (string, error)
+
+// AuthToken see [AuthTokenProvider].AuthToken.
+func (f AuthTokenProviderFunc) AuthToken(ctx context.Context, host string, port int, user string) (string, error) {
+	return f(ctx, host, port, user)
+}
+
+// authTokenFetcher fetches a new token and returns its expiry.
+type authTokenFetcher func(ctx context.Context, host string, port int, user string) (string, time.Time, error)
+
+type cachedAuthToken struct {
+	token   string
+	expires time.Time
+}
+
+// cachingAuthTokenProvider reuses the tokens of a fetcher until they are about to expire, so
+// that the connections opened meanwhile don't fetch or sign a token each.
+type cachingAuthTokenProvider struct {
+	fetch authTokenFetcher
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]cachedAuthToken // GUARDED_BY(mu)
+}
+
+func newCachingAuthTokenProvider(fetch authTokenFetcher) *cachingAuthTokenProvider {
+	return &cachingAuthTokenProvider{
+		fetch:  fetch,
+		now:    time.Now,
+		tokens: make(map[string]cachedAuthToken),
+	}
+}
+
+// AuthToken see [AuthTokenProvider].AuthToken.
+func (p *cachingAuthTokenProvider) AuthToken(ctx context.Context, host string, port int, user string) (string, error) {
+	key := user + "@" + net.JoinHostPort(host, strconv.Itoa(port))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached, ok := p.tokens[key]; ok && p.now().Add(authTokenRefreshMargin).Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	token, expires, err := p.fetch(ctx, host, port, user)
+	if err != nil {
+		return "", err
+	}
+	p.tokens[key] = cachedAuthToken{token: token, expires: expires}
+	return token, nil
+}
+
+// NewAuthTokenProvider returns the AuthTokenProvider of an IAM authentication method, which
+// refreshes its tokens before they expire. The region is the AWS region of the database for
+// IAMAuthAWSRDS, and defaults to the region of the AWS configuration, e.g. AWS_REGION.
+func NewAuthTokenProvider(method, region string) (AuthTokenProvider, error) {
+	switch method {
+	case IAMAuthAWSRDS:
+		var opts []func(*awsconfig.LoadOptions) error
+		if region != "" {
+			opts = append(opts, awsconfig.WithRegion(region))
+		}
+		awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the AWS configuration: %w", err)
+		}
+		if awsCfg.Region == "" {
+			return nil, fmt.Errorf("the AWS region of the %s IAM authentication is not set", method)
+		}
+		return newCachingAuthTokenProvider(func(ctx context.Context, host string, port int, user string) (string, time.Time, error) {
+			return rdsAuthToken(ctx, host, port, user, awsCfg.Region, awsCfg.Credentials, time.Now())
+		}), nil
+	case IAMAuthGCPCloudSQL:
+		return newCachingAuthTokenProvider(func(ctx context.Context, _ string, _ int, _ string) (string, time.Time, error) {
+			return gcpAccessToken(ctx, http.DefaultClient)
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown IAM authentication method %q, must be one of %v", method, IAMAuthMethods)
+	}
+}
+
+// rdsAuthTokenLifetime is the lifetime of the RDS IAM authentication tokens, set by AWS.
+const rdsAuthTokenLifetime = 15 * time.Minute
+
+// rdsAuthToken returns the RDS IAM authentication token of a user, signed with the credentials of
+// the provider, and its expiry. The token expires with the temporary credentials it is signed
+// with, if they expire first.
+func rdsAuthToken(ctx context.Context, host string, port int, user, region string, provider aws.CredentialsProvider, now time.Time) (string, time.Time, error) {
+	credentials, err := provider.Retrieve(ctx)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get the AWS credentials: %w", err)
+	}
+
+	endpoint := net.JoinHostPort(host, strconv.Itoa(port))
+	token, err := auth.BuildAuthToken(ctx, endpoint, region, user, awscredentials.StaticCredentialsProvider{Value: credentials})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build the RDS authentication token: %w", err)
+	}
+
+	expires := now.Add(rdsAuthTokenLifetime)
+	if credentials.CanExpire && credentials.Expires.Before(expires) {
+		expires = credentials.Expires
+	}
+	return token, expires, nil
+}
+
+// gcpMetadataHost is the host of the GCP metadata server, which GCE_METADATA_HOST overrides as in
+// the Google Cloud client libraries.
+const gcpMetadataHost = "metadata.google.internal"
+
+// gcpSQLLoginScope is the OAuth2 scope of the Cloud SQL IAM database authentication.
+const gcpSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+// gcpAccessToken returns an OAuth2 access token of the default service account of the instance,
+// from the GCP metadata server, and its expiry.
+func gcpAccessToken(ctx context.Context, client *http.Client) (string, time.Time, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = gcpMetadataHost
+	}
+	endpoint := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token?scopes=" + url.QueryEscape(gcpSQLLoginScope)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get the GCP access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("failed to get the GCP access token: %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode the GCP access token: %w", err)
+	}
+	return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn) * time.Second), nil
+}
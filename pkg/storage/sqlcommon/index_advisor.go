@@ -0,0 +1,178 @@
+package sqlcommon
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+const (
+	// maxAdvisedQueryShapes is the maximum number of query shapes that an IndexAdvisor explains.
+	maxAdvisedQueryShapes = 1000
+
+	// explainTimeout is the timeout of the EXPLAIN of a query.
+	explainTimeout = 10 * time.Second
+)
+
+var indexAdviceCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "datastore_index_advice_count",
+	Help:      "The number of slow datastore query shapes of which the plan scans a table fully, labeled by table and by the columns of their filter, which are candidates for a composite index.",
+}, []string{"table", "columns"})
+
+// ExplainFunc explains query with args with the database, and returns the tables that the plan of
+// the query scans fully.
+type ExplainFunc func(ctx context.Context, query string, args []interface{}) ([]string, error)
+
+// SQLIteratorQuery is a SQLIteratorRowGetter that exposes its query, for the IndexAdvisor.
+type SQLIteratorQuery interface {
+	SQLIteratorRowGetter
+	ToSql() (string, []interface{}, error)
+}
+
+// IndexAdvisor is a diagnostics mode of the SQL datastores for operators that tune their
+// databases. It EXPLAINs the first query of each shape (the SQL of the query, without its
+// arguments) whose rows take longer than a threshold to be returned, and when the plan of the
+// query scans a table fully, logs a warning and counts it in the
+// openfga_datastore_index_advice_count metric, with the columns of the filter of the query as the
+// suggested composite index.
+//
+// It explains one query at a time in the background and skips the slow queries meanwhile, so that
+// it doesn't add load to a database that is already slow.
+type IndexAdvisor struct {
+	threshold time.Duration
+	explain   ExplainFunc
+	logger    logger.Logger
+
+	mu        sync.Mutex
+	explained map[string]struct{} // GUARDED_BY(mu)
+
+	running atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewIndexAdvisor returns an IndexAdvisor of the queries slower than threshold, which it explains
+// with explain.
+func NewIndexAdvisor(threshold time.Duration, explain ExplainFunc, logger logger.Logger) *IndexAdvisor {
+	return &IndexAdvisor{
+		threshold: threshold,
+		explain:   explain,
+		logger:    logger,
+		explained: make(map[string]struct{}),
+	}
+}
+
+// Advise returns a SQLIteratorRowGetter that runs q and submits its query to the advisor when it is
+// slow. It returns q if a is nil.
+func (a *IndexAdvisor) Advise(q SQLIteratorQuery) SQLIteratorRowGetter {
+	if a == nil {
+		return q
+	}
+	return &advisedQuery{advisor: a, query: q}
+}
+
+// Close waits for the EXPLAIN in progress.
+func (a *IndexAdvisor) Close() {
+	if a == nil {
+		return
+	}
+	a.wg.Wait()
+}
+
+type advisedQuery struct {
+	advisor *IndexAdvisor
+	query   SQLIteratorQuery
+}
+
+func (q *advisedQuery) GetRows(ctx context.Context) (Rows, error) {
+	start := time.Now()
+	rows, err := q.query.GetRows(ctx)
+	if err == nil && time.Since(start) >= q.advisor.threshold {
+		q.advisor.submit(q.query)
+	}
+	return rows, err
+}
+
+// submit explains the query of q in the background, unless its shape was explained already or
+// another query is being explained.
+func (a *IndexAdvisor) submit(q SQLIteratorQuery) {
+	query, args, err := q.ToSql()
+	if err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	_, explained := a.explained[query]
+	full := len(a.explained) >= maxAdvisedQueryShapes
+	a.mu.Unlock()
+	if explained || full || !a.running.CompareAndSwap(false, true) {
+		return
+	}
+
+	a.mu.Lock()
+	a.explained[query] = struct{}{}
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer a.running.Store(false)
+
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+		a.advise(ctx, query, args)
+	}()
+}
+
+func (a *IndexAdvisor) advise(ctx context.Context, query string, args []interface{}) {
+	tables, err := a.explain(ctx, query, args)
+	if err != nil {
+		a.logger.Warn("index advisor failed to explain a slow query", zap.String("query", query), zap.Error(err))
+		return
+	}
+
+	columns := FilterColumns(query)
+	for _, table := range tables {
+		indexAdviceCounter.WithLabelValues(table, strings.Join(columns, ",")).Inc()
+		a.logger.Warn("index advisor: a slow query scans a table fully",
+			zap.String("table", table),
+			zap.String("query", query),
+			zap.Strings("filter_columns", columns),
+			zap.String("suggestion", "consider a composite index on "+table+" ("+strings.Join(columns, ", ")+")"),
+		)
+	}
+}
+
+var (
+	whereClauseRegex  = regexp.MustCompile(`(?is)\bWHERE\b(.*?)(?:\bGROUP BY\b|\bORDER BY\b|\bLIMIT\b|$)`)
+	filterColumnRegex = regexp.MustCompile(`(?i)\b([a-z_][a-z0-9_]*)\s*(?:=|<>|!=|>=|<=|>|<|\bIN\b|\bLIKE\b)`)
+)
+
+// FilterColumns returns the columns compared in the WHERE clause of query, in their order of
+// appearance, which is the order of the suggested composite index.
+func FilterColumns(query string) []string {
+	where := whereClauseRegex.FindStringSubmatch(query)
+	if where == nil {
+		return nil
+	}
+
+	var columns []string
+	for _, match := range filterColumnRegex.FindAllStringSubmatch(where[1], -1) {
+		column := strings.ToLower(match[1])
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,76 @@
+package sqlcommon
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+func TestFilterColumns(t *testing.T) {
+	tests := map[string]struct {
+		query   string
+		columns []string
+	}{
+		"no_where": {
+			query: "SELECT * FROM tuple",
+		},
+		"equalities_in_order": {
+			query:   "SELECT store, _user FROM tuple WHERE store = $1 AND object_type = $2 AND relation = $3 ORDER BY ulid LIMIT 10",
+			columns: []string{"store", "object_type", "relation"},
+		},
+		"in_and_like": {
+			query:   "SELECT * FROM tuple WHERE store = ? AND user_type = ? AND (_user LIKE ? OR _user = ?) AND object_id IN (?,?)",
+			columns: []string{"store", "user_type", "_user", "object_id"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.columns, FilterColumns(test.query))
+		})
+	}
+}
+
+func TestIndexAdvisor(t *testing.T) {
+	var mu sync.Mutex
+	var explained []string
+	advisor := NewIndexAdvisor(0, func(_ context.Context, query string, _ []interface{}) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		explained = append(explained, query)
+		return []string{"advised_table"}, nil
+	}, logger.NewNoopLogger())
+
+	run := func(sb sq.SelectBuilder) {
+		q := advisor.Advise(&stubIteratorQuery{SBIteratorQuery: NewSBIteratorQuery(sb)})
+		_, err := q.GetRows(context.Background())
+		require.NoError(t, err)
+		advisor.Close()
+	}
+
+	before := testutil.ToFloat64(indexAdviceCounter.WithLabelValues("advised_table", "relation,store"))
+	run(sq.Select("*").From("tuple").Where(sq.Eq{"store": "a", "relation": "viewer"}))
+	run(sq.Select("*").From("tuple").Where(sq.Eq{"store": "b", "relation": "editor"}))
+	require.Equal(t, []string{"SELECT * FROM tuple WHERE relation = ? AND store = ?"}, explained)
+	require.InDelta(t, before+1, testutil.ToFloat64(indexAdviceCounter.WithLabelValues("advised_table", "relation,store")), 0)
+
+	// a nil advisor doesn't wrap the query
+	var nilAdvisor *IndexAdvisor
+	q := &stubIteratorQuery{}
+	require.Same(t, q, nilAdvisor.Advise(q))
+	nilAdvisor.Close()
+}
+
+// stubIteratorQuery is a SQLIteratorQuery that doesn't run its query.
+type stubIteratorQuery struct {
+	*SBIteratorQuery
+}
+
+func (q *stubIteratorQuery) GetRows(context.Context) (Rows, error) {
+	return &stubRows{}, nil
+}
@@ -0,0 +1,90 @@
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+// bloatedDeadRowsRatio is the ratio of the dead rows of a table to its live rows from which it is
+// reported as bloated.
+const bloatedDeadRowsRatio = 0.2
+
+// TableMaintenance is the maintenance state of a table of a SQL datastore, as estimated by the
+// statistics of the database. The partitions of a partitioned table are summed.
+type TableMaintenance struct {
+	Table string `json:"table"`
+
+	// LiveRows is the estimated number of rows of the table.
+	LiveRows int64 `json:"live_rows"`
+
+	// DeadRows is the estimated number of the rows deleted or updated that were not vacuumed yet.
+	// It is always 0 in MySQL, which purges them in the background.
+	DeadRows int64 `json:"dead_rows"`
+
+	// BloatBytes is the estimated size of the table taken by dead rows or free space.
+	BloatBytes int64 `json:"bloat_bytes"`
+
+	// LastVacuum is the time at which the table was last vacuumed, if known.
+	LastVacuum *time.Time `json:"last_vacuum,omitempty"`
+
+	// Tuned reports whether the table has the recommended maintenance settings.
+	Tuned bool `json:"tuned"`
+}
+
+// Bloated reports whether the table has many more dead rows than expected from its maintenance.
+func (t TableMaintenance) Bloated() bool {
+	return t.DeadRows > 0 && float64(t.DeadRows) > bloatedDeadRowsRatio*float64(t.LiveRows)
+}
+
+// MaintenanceReporter is implemented by the SQL datastores that report the maintenance state of
+// their tables.
+type MaintenanceReporter interface {
+	MaintenanceReport(ctx context.Context) ([]TableMaintenance, error)
+}
+
+// CheckMaintenance logs the tables of the datastore that don't have the recommended maintenance
+// settings, and warns about the bloated ones.
+func CheckMaintenance(ctx context.Context, reporter MaintenanceReporter, l logger.Logger) {
+	tables, err := reporter.MaintenanceReport(ctx)
+	if err != nil {
+		l.Warn("failed to check the maintenance of the datastore tables", zap.Error(err))
+		return
+	}
+	for _, table := range tables {
+		if !table.Tuned {
+			l.Info("datastore table doesn't have the recommended maintenance settings, apply them with 'openfga migrate --tune-maintenance' or '--datastore-maintenance-tuning-enabled'",
+				zap.String("table", table.Table))
+		}
+		if table.Bloated() {
+			l.Warn("datastore table is bloated with dead rows",
+				zap.String("table", table.Table),
+				zap.Int64("live_rows", table.LiveRows),
+				zap.Int64("dead_rows", table.DeadRows),
+				zap.Int64("bloat_bytes", table.BloatBytes))
+		}
+	}
+}
+
+// NewMaintenanceHandler returns an http.Handler that serves the maintenance report of the tables of
+// the datastore as JSON.
+func NewMaintenanceHandler(reporter MaintenanceReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tables, err := reporter.MaintenanceReport(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Tables []TableMaintenance `json:"tables"`
+		}{
+			Tables: tables,
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubMaintenanceReporter struct {
+	tables []TableMaintenance
+	err    error
+}
+
+func (r *stubMaintenanceReporter) MaintenanceReport(context.Context) ([]TableMaintenance, error) {
+	return r.tables, r.err
+}
+
+func TestTableMaintenanceBloated(t *testing.T) {
+	require.False(t, TableMaintenance{}.Bloated())
+	require.False(t, TableMaintenance{LiveRows: 100, DeadRows: 20}.Bloated())
+	require.True(t, TableMaintenance{LiveRows: 100, DeadRows: 21}.Bloated())
+	require.True(t, TableMaintenance{DeadRows: 1}.Bloated())
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	reporter := &stubMaintenanceReporter{tables: []TableMaintenance{
+		{Table: "tuple", LiveRows: 10, DeadRows: 5, BloatBytes: 100, Tuned: true},
+	}}
+	handler := NewMaintenanceHandler(reporter)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/datastore/maintenance", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"tables":[{"table":"tuple","live_rows":10,"dead_rows":5,"bloat_bytes":100,"tuned":true}]}`, rec.Body.String())
+
+	var report struct {
+		Tables []TableMaintenance `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Equal(t, reporter.tables, report.Tables)
+
+	reporter.err = errors.New("unavailable")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/datastore/maintenance", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,131 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ReverseUserIndexStateTable records whether the ReverseUserIndexTable is enabled in the datastore,
+// so that the instances whose configuration doesn't match it refuse to open the datastore: an
+// instance that doesn't maintain the index on write hides its tuples from the ones that read it.
+const ReverseUserIndexStateTable = "tuple_user_index_state"
+
+// reverseUserIndexBatchSize is the number of rows of a table reconciled by each statement of
+// ReconcileReverseUserIndex.
+var reverseUserIndexBatchSize = 1000
+
+var (
+	tupleKeyColumns            = []string{"store", "object_type", "object_id", "relation", "_user"}
+	reverseUserIndexKeyColumns = []string{"store", "_user", "object_type", "relation", "object_id"}
+	reverseUserIndexColumns    = []string{
+		"store", "object_type", "object_id", "relation", "_user",
+		"user_type", "condition_name", "condition_context", "ulid", "inserted_at",
+	}
+)
+
+// VerifyReverseUserIndex returns an error if the reverse user index is enabled in the datastore,
+// as recorded in the ReverseUserIndexStateTable, and not in the configuration of the instance,
+// or the reverse.
+func VerifyReverseUserIndex(enabledInDatastore, enabled bool) error {
+	switch {
+	case enabledInDatastore && !enabled:
+		return errors.New("the reverse user index is enabled in the datastore, so it must be enabled on all the instances: enable it, or disable it in the datastore with 'openfga migrate --reverse-user-index=disabled'")
+	case !enabledInDatastore && enabled:
+		return errors.New("the reverse user index is not enabled in the datastore: build it with 'openfga migrate --reverse-user-index=enabled' before enabling it on the instances")
+	}
+	return nil
+}
+
+// ReconcileReverseUserIndex records in the ReverseUserIndexStateTable whether the
+// ReverseUserIndexTable is enabled and, before it enables it, reconciles it with the tuples, which
+// are written without maintaining it while it is disabled: the rows of the missing or overwritten
+// tuples are upserted, and the rows of the deleted tuples are deleted. Each statement reconciles a
+// batch of rows in the order of the key of its table, so that the tables aren't locked for the
+// whole reconciliation, and the batches that were reconciled are kept if it is interrupted. It
+// can be run again once it is enabled, e.g. to index the tuples written by the instances that
+// were still running without the index.
+//
+// stbl is the statement builder of the engine, and upsert returns its clause that replaces the
+// given columns of the conflicting rows of the ReverseUserIndexTable.
+func ReconcileReverseUserIndex(ctx context.Context, db *sql.DB, stbl sq.StatementBuilderType, upsert func(columns []string) string, enabled bool) error {
+	if enabled {
+		columns := strings.Join(reverseUserIndexColumns, ", ")
+		err := reconcileBatches(ctx, db, stbl, "tuple", tupleKeyColumns, func(batch sq.Sqlizer) error {
+			stmt, args, err := stbl.Select(reverseUserIndexColumns...).From("tuple").Where(batch).ToSql()
+			if err != nil {
+				return err
+			}
+			_, err = db.ExecContext(ctx, `INSERT INTO `+ReverseUserIndexTable+` (`+columns+`) `+stmt+` `+upsert(reverseUserIndexColumns[5:]), args...)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to index the tuples: %w", err)
+		}
+
+		err = reconcileBatches(ctx, db, stbl, ReverseUserIndexTable, reverseUserIndexKeyColumns, func(batch sq.Sqlizer) error {
+			_, err := stbl.Delete(ReverseUserIndexTable).Where(batch).
+				Where(`NOT EXISTS (SELECT 1 FROM tuple t WHERE t.store = ` + ReverseUserIndexTable + `.store
+					AND t.object_type = ` + ReverseUserIndexTable + `.object_type AND t.object_id = ` + ReverseUserIndexTable + `.object_id
+					AND t.relation = ` + ReverseUserIndexTable + `.relation AND t._user = ` + ReverseUserIndexTable + `._user
+					AND t.ulid = ` + ReverseUserIndexTable + `.ulid)`).
+				RunWith(db).
+				ExecContext(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete the stale rows of the index: %w", err)
+		}
+	}
+
+	_, err := stbl.Update(ReverseUserIndexStateTable).Set("enabled", enabled).RunWith(db).ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record the state of the index: %w", err)
+	}
+	return nil
+}
+
+// reconcileBatches calls reconcile with the conditions of the consecutive batches of
+// reverseUserIndexBatchSize rows of table, given the columns of its primary key.
+func reconcileBatches(ctx context.Context, db *sql.DB, stbl sq.StatementBuilderType, table string, key []string, reconcile func(batch sq.Sqlizer) error) error {
+	keyExpr := "(" + strings.Join(key, ", ") + ")"
+	rowExpr := "(" + sq.Placeholders(len(key)) + ")"
+
+	var lower []interface{}
+	for {
+		batch := sq.And{}
+		if lower != nil {
+			batch = append(batch, sq.Expr(keyExpr+" > "+rowExpr, lower...))
+		}
+
+		upper := make([]interface{}, len(key))
+		values := make([]string, len(key))
+		for i := range values {
+			upper[i] = &values[i]
+		}
+		err := stbl.Select(key...).From(table).Where(batch).OrderBy(key...).
+			Limit(1).Offset(uint64(reverseUserIndexBatchSize - 1)).
+			RunWith(db).
+			QueryRowContext(ctx).
+			Scan(upper...)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the last batch
+			return reconcile(batch)
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := range values {
+			upper[i] = values[i]
+		}
+		if err := reconcile(append(batch, sq.Expr(keyExpr+" <= "+rowExpr, upper...))); err != nil {
+			return err
+		}
+		lower = upper
+	}
+}
@@ -0,0 +1,101 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestVerifyReverseUserIndex(t *testing.T) {
+	require.NoError(t, VerifyReverseUserIndex(false, false))
+	require.NoError(t, VerifyReverseUserIndex(true, true))
+	require.ErrorContains(t, VerifyReverseUserIndex(true, false), "the reverse user index is enabled in the datastore")
+	require.ErrorContains(t, VerifyReverseUserIndex(false, true), "the reverse user index is not enabled in the datastore")
+}
+
+func TestReconcileReverseUserIndex(t *testing.T) {
+	batchSize := reverseUserIndexBatchSize
+	reverseUserIndexBatchSize = 2
+	t.Cleanup(func() { reverseUserIndexBatchSize = batchSize })
+
+	// the statements of the engines run against sqlite, which supports their row values and upserts
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`CREATE TABLE tuple (store TEXT, object_type TEXT, object_id TEXT, relation TEXT, _user TEXT, user_type TEXT,
+			condition_name TEXT, condition_context BLOB, ulid TEXT, inserted_at TIMESTAMP,
+			PRIMARY KEY (store, object_type, object_id, relation, _user))`,
+		`CREATE TABLE tuple_user_index (store TEXT, object_type TEXT, object_id TEXT, relation TEXT, _user TEXT, user_type TEXT,
+			condition_name TEXT, condition_context BLOB, ulid TEXT, inserted_at TIMESTAMP,
+			PRIMARY KEY (store, _user, object_type, relation, object_id))`,
+		`CREATE TABLE tuple_user_index_state (id SMALLINT PRIMARY KEY, enabled BOOLEAN NOT NULL)`,
+		`INSERT INTO tuple_user_index_state (id, enabled) VALUES (1, FALSE)`,
+		`INSERT INTO tuple VALUES
+			('store1', 'document', '1', 'viewer', 'user:jon', 'user', NULL, NULL, 'ulid1', CURRENT_TIMESTAMP),
+			('store1', 'document', '2', 'viewer', 'user:jon', 'user', NULL, NULL, 'ulid2', CURRENT_TIMESTAMP),
+			('store1', 'document', '3', 'viewer', 'user:maria', 'user', NULL, NULL, 'ulid3', CURRENT_TIMESTAMP),
+			('store1', 'folder', '1', 'viewer', 'user:jon', 'user', NULL, NULL, 'ulid4', CURRENT_TIMESTAMP),
+			('store2', 'document', '1', 'viewer', 'user:jon', 'user', 'condition', NULL, 'ulid5', CURRENT_TIMESTAMP)`,
+		// the tuples deleted or overwritten while the index was disabled
+		`INSERT INTO tuple_user_index VALUES
+			('store1', 'document', '4', 'viewer', 'user:jon', 'user', NULL, NULL, 'ulid0', CURRENT_TIMESTAMP),
+			('store2', 'document', '1', 'viewer', 'user:jon', 'user', NULL, NULL, 'ulid0', CURRENT_TIMESTAMP)`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	upsert := func(columns []string) string {
+		updates := make([]string, 0, len(columns))
+		for _, column := range columns {
+			updates = append(updates, column+" = excluded."+column)
+		}
+		return "ON CONFLICT (store, _user, object_type, relation, object_id) DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+	rows := func(table string) []string {
+		r, err := db.QueryContext(ctx, `SELECT store, object_type, object_id, relation, _user, COALESCE(condition_name, ''), ulid FROM `+table+
+			` ORDER BY store, object_type, object_id, relation, _user`)
+		require.NoError(t, err)
+		defer r.Close()
+
+		var rows []string
+		for r.Next() {
+			row := make([]string, 7)
+			require.NoError(t, r.Scan(&row[0], &row[1], &row[2], &row[3], &row[4], &row[5], &row[6]))
+			rows = append(rows, strings.Join(row, " "))
+		}
+		require.NoError(t, r.Err())
+		return rows
+	}
+	enabled := func() bool {
+		var enabled bool
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT enabled FROM tuple_user_index_state`).Scan(&enabled))
+		return enabled
+	}
+
+	require.NoError(t, ReconcileReverseUserIndex(ctx, db, sq.StatementBuilder, upsert, true))
+	require.Equal(t, rows("tuple"), rows("tuple_user_index"))
+	require.Len(t, rows("tuple_user_index"), 5)
+	require.True(t, enabled())
+
+	// it can be run again once it is enabled
+	_, err = db.ExecContext(ctx, `DELETE FROM tuple WHERE object_type = 'folder'`)
+	require.NoError(t, err)
+	require.NoError(t, ReconcileReverseUserIndex(ctx, db, sq.StatementBuilder, upsert, true))
+	require.Equal(t, rows("tuple"), rows("tuple_user_index"))
+	require.True(t, enabled())
+
+	// the rows of the index are kept when it is disabled
+	require.NoError(t, ReconcileReverseUserIndex(ctx, db, sq.StatementBuilder, upsert, false))
+	require.Len(t, rows("tuple_user_index"), 4)
+	require.False(t, enabled())
+}
@@ -0,0 +1,120 @@
+package sqlcommon
+
+import (
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// SetOperationQuery builds the query evaluating a [storage.SetOperationFilter]. The union terms
+// are combined with UNION and the excluded terms are removed with EXCEPT, or with a NOT IN
+// anti-join for dialects without EXCEPT support.
+type SetOperationQuery struct {
+	// Placeholder is the placeholder format of the SQL dialect.
+	Placeholder sq.PlaceholderFormat
+
+	// UserCondition returns the condition matching the tuples whose user is one of users.
+	UserCondition func(users []string) sq.Sqlizer
+
+	// WithoutExcept must be set for dialects that do not support EXCEPT (e.g. MySQL before 8.0.31).
+	WithoutExcept bool
+}
+
+// Build returns the SQL statement and arguments evaluating filter in the given store. The
+// statement returns a single object_id column.
+func (q SetOperationQuery) Build(store string, filter storage.SetOperationFilter, limit uint32) (string, []interface{}, error) {
+	if len(filter.Union) == 0 {
+		return "", nil, fmt.Errorf("set operation filter for type '%s' has no union terms", filter.ObjectType)
+	}
+
+	union, args, err := q.compound(store, filter.ObjectType, filter.Union)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var stmt strings.Builder
+	switch {
+	case len(filter.Except) == 0:
+		stmt.WriteString(union)
+	case q.WithoutExcept:
+		except, exceptArgs, err := q.compound(store, filter.ObjectType, filter.Except)
+		if err != nil {
+			return "", nil, err
+		}
+		stmt.WriteString("SELECT object_id FROM (" + union + ") AS included WHERE object_id NOT IN (" + except + ")")
+		args = append(args, exceptArgs...)
+	default:
+		stmt.WriteString(union)
+		for _, term := range filter.Except {
+			sql, termArgs, err := q.term(store, filter.ObjectType, term).ToSql()
+			if err != nil {
+				return "", nil, err
+			}
+			stmt.WriteString(" EXCEPT " + sql)
+			args = append(args, termArgs...)
+		}
+	}
+
+	if limit > 0 {
+		stmt.WriteString(fmt.Sprintf(" LIMIT %d", limit))
+	}
+
+	placeholder := q.Placeholder
+	if placeholder == nil {
+		placeholder = sq.Question
+	}
+	sql, err := placeholder.ReplacePlaceholders(stmt.String())
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, args, nil
+}
+
+// compound returns the UNION of the queries of the given terms.
+func (q SetOperationQuery) compound(store, objectType string, terms []storage.SetOperationTerm) (string, []interface{}, error) {
+	parts := make([]string, 0, len(terms))
+	var args []interface{}
+	for _, term := range terms {
+		sql, termArgs, err := q.term(store, objectType, term).ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, sql)
+		args = append(args, termArgs...)
+	}
+	return strings.Join(parts, " UNION "), args, nil
+}
+
+func (q SetOperationQuery) term(store, objectType string, term storage.SetOperationTerm) sq.SelectBuilder {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Select("object_id").
+		From("tuple").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"relation":    term.Relation,
+		}).
+		Where(q.UserCondition(term.Users)).
+		Where(sq.Eq{"COALESCE(condition_name, '')": ""})
+}
+
+// ScanObjectIDs reads the object IDs returned by a query built with [SetOperationQuery] and closes rows.
+func ScanObjectIDs(rows Rows) ([]string, error) {
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -50,6 +50,37 @@ type Config struct {
 	ConnMaxLifetime time.Duration
 
 	ExportMetrics bool
+
+	// IndexAdvisorSlowQueryThreshold enables the IndexAdvisor of the queries slower than it when
+	// it is not zero.
+	IndexAdvisorSlowQueryThreshold time.Duration
+
+	// ReverseUserIndex maintains the ReverseUserIndexTable of the tuples on write, and reads
+	// the tuples of the users from it.
+	ReverseUserIndex bool
+
+	// MaintenanceTuning applies the recommended maintenance settings to the tables when the
+	// datastore is opened, e.g. the autovacuum settings in PostgreSQL.
+	MaintenanceTuning bool
+
+	// TransactionPooling avoids the session-level state of the connections, so that they can be
+	// pooled by transaction, e.g. by PgBouncer in transaction pooling mode: the queries aren't
+	// prepared as named statements, which only exist on the server connection that prepared them.
+	// It is only available in PostgreSQL.
+	TransactionPooling bool
+
+	// IAMAuthMethod authenticates the connections with the short-lived tokens of an IAM
+	// authentication method, e.g. IAMAuthAWSRDS, instead of the password, when it is not empty.
+	// The tokens are refreshed before they expire.
+	IAMAuthMethod string
+
+	// IAMAuthRegion is the AWS region of the database of IAMAuthAWSRDS.
+	IAMAuthRegion string
+
+	// PasswordProvider provides the password of every new connection, instead of Password, e.g.
+	// so that the connections opened after the rotation of the password use the rotated one. It
+	// is ignored with IAMAuthMethod.
+	PasswordProvider AuthTokenProvider
 }
 
 // DatastoreOption defines a function type
@@ -174,6 +205,54 @@ func WithMetrics() DatastoreOption {
 	}
 }
 
+// WithIndexAdvisor returns a DatastoreOption that enables the IndexAdvisor
+// of the queries slower than slowQueryThreshold in the Config.
+func WithIndexAdvisor(slowQueryThreshold time.Duration) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.IndexAdvisorSlowQueryThreshold = slowQueryThreshold
+	}
+}
+
+// WithReverseUserIndex returns a DatastoreOption that enables
+// the reverse user index of the tuples in the Config.
+func WithReverseUserIndex() DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ReverseUserIndex = true
+	}
+}
+
+// WithMaintenanceTuning returns a DatastoreOption that applies
+// the recommended maintenance settings to the tables in the Config.
+func WithMaintenanceTuning() DatastoreOption {
+	return func(cfg *Config) {
+		cfg.MaintenanceTuning = true
+	}
+}
+
+// WithTransactionPooling returns a DatastoreOption that enables
+// the compatibility with connections pooled by transaction in the Config.
+func WithTransactionPooling() DatastoreOption {
+	return func(cfg *Config) {
+		cfg.TransactionPooling = true
+	}
+}
+
+// WithIAMAuth returns a DatastoreOption that sets
+// the IAM authentication method and its AWS region in the Config.
+func WithIAMAuth(method, region string) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.IAMAuthMethod = method
+		cfg.IAMAuthRegion = region
+	}
+}
+
+// WithPasswordProvider returns a DatastoreOption that sets the PasswordProvider in the Config.
+func WithPasswordProvider(provider AuthTokenProvider) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.PasswordProvider = provider
+	}
+}
+
 // NewConfig creates a new Config instance with default values
 // and applies any provided DatastoreOption modifications.
 func NewConfig(opts ...DatastoreOption) *Config {
@@ -257,6 +336,11 @@ func (q *SBIteratorQuery) GetRows(ctx context.Context) (Rows, error) {
 	return q.sb.QueryContext(ctx)
 }
 
+// ToSql returns the query and the arguments of the select builder.
+func (q *SBIteratorQuery) ToSql() (string, []interface{}, error) {
+	return q.sb.ToSql()
+}
+
 // Rows is an interface that abstracts the iteration over SQL query results.
 // It provides methods to close the result set, check for errors, advance to the next row,
 // and scan the current row's columns into provided destinations.
@@ -287,6 +371,10 @@ type SQLTupleIterator struct {
 	firstRow *storage.TupleRecord // GUARDED_BY(mu)
 	mu       sync.Mutex
 
+	// scanner holds the scan destinations of the rows. It is taken from tupleRowScanners on the
+	// first row and put back by Stop.
+	scanner *tupleRowScanner // GUARDED_BY(mu)
+
 	rowGetter SQLIteratorRowGetter
 }
 
@@ -311,6 +399,54 @@ func SQLIteratorColumns() []string {
 	return sqlIteratorColumns
 }
 
+// tupleRowScanner holds the destinations of the sqlIteratorColumns of a row. The destinations
+// escape to the heap when they are passed to Rows.Scan, so a scanner is reused across the rows of
+// an iterator, and across iterators through tupleRowScanners, instead of allocating them for every
+// row.
+type tupleRowScanner struct {
+	record           storage.TupleRecord
+	conditionName    sql.NullString
+	conditionContext []byte
+	dest             []any
+}
+
+var tupleRowScanners = sync.Pool{
+	New: func() any {
+		s := &tupleRowScanner{}
+		s.dest = []any{
+			&s.record.Store,
+			&s.record.ObjectType,
+			&s.record.ObjectID,
+			&s.record.Relation,
+			&s.record.User,
+			&s.conditionName,
+			&s.conditionContext,
+			&s.record.Ulid,
+			&s.record.InsertedAt,
+		}
+		return s
+	},
+}
+
+// scan scans the current row of rows and returns a copy of its record, without its condition
+// context, and the encoded condition context, which the drivers copy out of their buffers.
+func (s *tupleRowScanner) scan(rows Rows) (*storage.TupleRecord, []byte, error) {
+	s.conditionName = sql.NullString{}
+	s.conditionContext = nil
+	if err := rows.Scan(s.dest...); err != nil {
+		return nil, nil, err
+	}
+
+	record := s.record
+	record.ConditionName = s.conditionName.String
+	conditionContext := s.conditionContext
+
+	// don't keep references to the values of the row in the pool
+	s.record = storage.TupleRecord{}
+	s.conditionContext = nil
+	return &record, conditionContext, nil
+}
+
 // NewSQLTupleIterator returns a SQL tuple iterator.
 func NewSQLTupleIterator(rowGetter SQLIteratorRowGetter, errHandler errorHandlerFn) *SQLTupleIterator {
 	return &SQLTupleIterator{
@@ -374,37 +510,18 @@ func (t *SQLTupleIterator) next(ctx context.Context) (*storage.TupleRecord, erro
 		return nil, storage.ErrIteratorDone
 	}
 
-	var conditionName sql.NullString
-	var conditionContext []byte
-	var record storage.TupleRecord
-	err := t.rows.Scan(
-		&record.Store,
-		&record.ObjectType,
-		&record.ObjectID,
-		&record.Relation,
-		&record.User,
-		&conditionName,
-		&conditionContext,
-		&record.Ulid,
-		&record.InsertedAt,
-	)
+	record, conditionContext, err := t.scanRow()
 	t.mu.Unlock()
 
 	if err != nil {
 		return nil, t.handleSQLError(err)
 	}
 
-	record.ConditionName = conditionName.String
-
-	if conditionContext != nil {
-		var conditionContextStruct structpb.Struct
-		if err := proto.Unmarshal(conditionContext, &conditionContextStruct); err != nil {
-			return nil, err
-		}
-		record.ConditionContext = &conditionContextStruct
+	if err := unmarshalConditionContext(record, conditionContext); err != nil {
+		return nil, err
 	}
 
-	return &record, nil
+	return record, nil
 }
 
 func (t *SQLTupleIterator) head(ctx context.Context) (*storage.TupleRecord, error) {
@@ -438,36 +555,39 @@ func (t *SQLTupleIterator) head(ctx context.Context) (*storage.TupleRecord, erro
 		return nil, storage.ErrIteratorDone
 	}
 
-	var conditionName sql.NullString
-	var conditionContext []byte
-	var record storage.TupleRecord
-	err := t.rows.Scan(
-		&record.Store,
-		&record.ObjectType,
-		&record.ObjectID,
-		&record.Relation,
-		&record.User,
-		&conditionName,
-		&conditionContext,
-		&record.Ulid,
-		&record.InsertedAt,
-	)
+	record, conditionContext, err := t.scanRow()
 	if err != nil {
 		return nil, t.handleSQLError(err)
 	}
 
-	record.ConditionName = conditionName.String
+	if err := unmarshalConditionContext(record, conditionContext); err != nil {
+		return nil, err
+	}
+	t.firstRow = record
 
-	if conditionContext != nil {
-		var conditionContextStruct structpb.Struct
-		if err := proto.Unmarshal(conditionContext, &conditionContextStruct); err != nil {
-			return nil, err
-		}
-		record.ConditionContext = &conditionContextStruct
+	return record, nil
+}
+
+// scanRow scans the current row of t.rows with the scanner of the iterator, which it takes from
+// the pool on the first row.
+func (t *SQLTupleIterator) scanRow() (*storage.TupleRecord, []byte, error) {
+	if t.scanner == nil {
+		t.scanner = tupleRowScanners.Get().(*tupleRowScanner)
 	}
-	t.firstRow = &record
+	return t.scanner.scan(t.rows)
+}
 
-	return &record, nil
+// unmarshalConditionContext sets the condition context of record from its encoded value.
+func unmarshalConditionContext(record *storage.TupleRecord, conditionContext []byte) error {
+	if conditionContext == nil {
+		return nil
+	}
+	var conditionContextStruct structpb.Struct
+	if err := proto.Unmarshal(conditionContext, &conditionContextStruct); err != nil {
+		return err
+	}
+	record.ConditionContext = &conditionContextStruct
+	return nil
 }
 
 // ToArray converts the tupleIterator to an []*openfgav1.Tuple and a possibly empty continuation token.
@@ -536,12 +656,19 @@ func (t *SQLTupleIterator) Stop() {
 	if t.rows != nil {
 		_ = t.rows.Close()
 	}
+	if t.scanner != nil {
+		tupleRowScanners.Put(t.scanner)
+		t.scanner = nil
+	}
 }
 
 // DBInfo encapsulates DB information for use in common method.
 type DBInfo struct {
 	stbl           sq.StatementBuilderType
 	HandleSQLError errorHandlerFn
+
+	// ReverseUserIndex maintains the ReverseUserIndexTable in Write.
+	ReverseUserIndex bool
 }
 
 type errorHandlerFn func(error, ...interface{}) error
@@ -678,9 +805,37 @@ func GetDeleteWriteChangelogItems(
 	changeLogItems := make([][]interface{}, 0, len(writeData.Deletes)+len(writeData.Writes))
 
 	// ensures increasingly unique values within a single thread
-	entropy := ulid.DefaultEntropy()
+	entropy := storage.NewChangeEntropy(writeData.Opts.Region)
 
 	deleteConditions := sq.Or{}
+	var lastChangeID string
+
+	appendDelete := func(object, relation, user string) {
+		id := ulid.MustNew(ulid.Timestamp(writeData.Now), entropy).String()
+		lastChangeID = id
+		objectType, objectID := tupleUtils.SplitObject(object)
+
+		deleteConditions = append(deleteConditions, sq.Eq{
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    relation,
+			"_user":       user,
+			"user_type":   tupleUtils.GetUserTypeFromUser(user),
+		})
+
+		changeLogItems = append(changeLogItems, []interface{}{
+			store,
+			objectType,
+			objectID,
+			relation,
+			user,
+			"",
+			nil, // Redact condition info for Deletes since we only need the base triplet (object, relation, user).
+			int32(openfgav1.TupleOperation_TUPLE_OPERATION_DELETE),
+			id,
+			sq.Expr("NOW()"),
+		})
+	}
 
 	// 1. For Deletes
 	// a. If on_missing: error ( default behavior ):
@@ -708,29 +863,7 @@ func GetDeleteWriteChangelogItems(
 			}
 		}
 
-		id := ulid.MustNew(ulid.Timestamp(writeData.Now), entropy).String()
-		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
-
-		deleteConditions = append(deleteConditions, sq.Eq{
-			"object_type": objectType,
-			"object_id":   objectID,
-			"relation":    tk.GetRelation(),
-			"_user":       tk.GetUser(),
-			"user_type":   tupleUtils.GetUserTypeFromUser(tk.GetUser()),
-		})
-
-		changeLogItems = append(changeLogItems, []interface{}{
-			store,
-			objectType,
-			objectID,
-			tk.GetRelation(),
-			tk.GetUser(),
-			"",
-			nil, // Redact condition info for Deletes since we only need the base triplet (object, relation, user).
-			int32(openfgav1.TupleOperation_TUPLE_OPERATION_DELETE),
-			id,
-			sq.Expr("NOW()"),
-		})
+		appendDelete(tk.GetObject(), tk.GetRelation(), tk.GetUser())
 	}
 
 	writeItems := make([][]interface{}, 0, len(writeData.Writes))
@@ -743,12 +876,20 @@ func GetDeleteWriteChangelogItems(
 	// - Based on the results from step 3.a, which identified and locked existing rows, the system will compare values to the ones we’re trying to insert
 	// - On conflict ( values not identical ) - return an error 409 Conflict
 	// - For rows that DO NOT exist in DB - create both INSERT tuple & INSERT changelog statements
-	// c. Execute INSERTs as a single statement
+	// c. If on_duplicate: overwrite ( multi-region deployments )
+	// - Same as b., except that on conflict the last writer wins: create DELETE tuple & INSERT changelog statements
+	//   for the existing row, followed by both INSERT tuple & INSERT changelog statements
+	// d. Execute INSERTs as a single statement
 	//   On error, return 409 Conflict
 	for _, tk := range writeData.Writes {
 		if existingTuple, ok := existing[tupleUtils.TupleKeyToString(tk)]; ok {
 			// If the tuple exists, we can not write it.
 			switch writeData.Opts.OnDuplicateInsert {
+			case storage.OnDuplicateInsertOverwrite:
+				if proto.Equal(existingTuple.GetKey().GetCondition(), tk.GetCondition()) {
+					continue
+				}
+				appendDelete(tk.GetObject(), tk.GetRelation(), tk.GetUser())
 			case storage.OnDuplicateInsertIgnore:
 				// If the tuple exists and the condition is the same, we can ignore it.
 				// We need to use its serialized text instead of reflect.DeepEqual to avoid comparing internal values.
@@ -768,6 +909,7 @@ func GetDeleteWriteChangelogItems(
 		}
 
 		id := ulid.MustNew(ulid.Timestamp(writeData.Now), entropy).String()
+		lastChangeID = id
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
 
 		conditionName, conditionContext, err := MarshalRelationshipCondition(tk.GetCondition())
@@ -801,6 +943,9 @@ func GetDeleteWriteChangelogItems(
 			sq.Expr("NOW()"),
 		})
 	}
+	if writeData.Opts.OnLastChangeID != nil && lastChangeID != "" {
+		writeData.Opts.OnLastChangeID(lastChangeID)
+	}
 	return deleteConditions, writeItems, changeLogItems, nil
 }
 
@@ -811,6 +956,28 @@ type WriteData struct {
 	Now     time.Time
 }
 
+// writeBatchSize is the maximum number of rows of the DELETE and INSERT statements of Write. The
+// rows of a write are sent in as few multi-row statements as possible, and the bound keeps the
+// statements of the largest writes under the 65535 placeholders of a MySQL statement.
+const writeBatchSize = 1000
+
+// deleteConditionKeys returns the keys of the tuples of the delete conditions of
+// GetDeleteWriteChangelogItems.
+func deleteConditionKeys(deleteConditions sq.Or) []TupleLockKey {
+	keys := make([]TupleLockKey, 0, len(deleteConditions))
+	for _, condition := range deleteConditions {
+		eq := condition.(sq.Eq)
+		keys = append(keys, TupleLockKey{
+			objectType: eq["object_type"].(string),
+			objectID:   eq["object_id"].(string),
+			relation:   eq["relation"].(string),
+			user:       eq["_user"].(string),
+			userType:   string(eq["user_type"].(tupleUtils.UserType)),
+		})
+	}
+	return keys
+}
+
 // Write provides the common method for writing to database across sql storage.
 func Write(
 	ctx context.Context,
@@ -839,8 +1006,8 @@ func Write(
 
 	// 3. If list compiled in step 2 is not empty, execute SELECT … FOR UPDATE statement
 
-	for start := 0; start < total; start += storage.DefaultMaxTuplesPerWrite {
-		end := start + storage.DefaultMaxTuplesPerWrite
+	for start := 0; start < total; start += writeBatchSize {
+		end := start + writeBatchSize
 		if end > total {
 			end = total
 		}
@@ -857,16 +1024,20 @@ func Write(
 		return err
 	}
 
-	for start, totalDeletes := 0, len(deleteConditions); start < totalDeletes; start += storage.DefaultMaxTuplesPerWrite {
-		end := start + storage.DefaultMaxTuplesPerWrite
+	deleteKeys := deleteConditionKeys(deleteConditions)
+	for start, totalDeletes := 0, len(deleteKeys); start < totalDeletes; start += writeBatchSize {
+		end := start + writeBatchSize
 		if end > totalDeletes {
 			end = totalDeletes
 		}
 
-		deleteConditionsBatch := deleteConditions[start:end]
+		deleteKeysBatch := deleteKeys[start:end]
+		inExpr, args := BuildRowConstructorIN(deleteKeysBatch)
 
 		res, err := dbInfo.stbl.Delete("tuple").Where(sq.Eq{"store": store}).
-			Where(deleteConditionsBatch).
+			// Row-constructor IN on full composite key, like the SELECT … FOR UPDATE, instead of
+			// an OR of the keys.
+			Where(sq.Expr("(object_type, object_id, relation, _user, user_type) IN "+inExpr, args...)).
 			RunWith(txn). // Part of a txn.
 			ExecContext(ctx)
 		if err != nil {
@@ -878,14 +1049,14 @@ func Write(
 			return dbInfo.HandleSQLError(err)
 		}
 
-		if rowsAffected != int64(len(deleteConditionsBatch)) {
+		if rowsAffected != int64(len(deleteKeysBatch)) {
 			// If we deleted fewer rows than planned (after read before write), means we hit a race condition - someone else deleted the same row(s).
 			return storage.ErrWriteConflictOnDelete
 		}
 	}
 
-	for start, totalWrites := 0, len(writeItems); start < totalWrites; start += storage.DefaultMaxTuplesPerWrite {
-		end := start + storage.DefaultMaxTuplesPerWrite
+	for start, totalWrites := 0, len(writeItems); start < totalWrites; start += writeBatchSize {
+		end := start + writeBatchSize
 		if end > totalWrites {
 			end = totalWrites
 		}
@@ -924,9 +1095,15 @@ func Write(
 		}
 	}
 
+	if dbInfo.ReverseUserIndex {
+		if err := writeReverseUserIndex(ctx, dbInfo, txn, store, deleteConditions, writeItems); err != nil {
+			return err
+		}
+	}
+
 	// 5. Execute INSERT changelog statements
-	for start, totalItems := 0, len(changeLogItems); start < totalItems; start += storage.DefaultMaxTuplesPerWrite {
-		end := start + storage.DefaultMaxTuplesPerWrite
+	for start, totalItems := 0, len(changeLogItems); start < totalItems; start += writeBatchSize {
+		end := start + writeBatchSize
 		if end > totalItems {
 			end = totalItems
 		}
@@ -966,6 +1143,83 @@ func Write(
 	return nil
 }
 
+// ReverseUserIndexTable is the reverse index of the tuples by user: a copy of the tuple table whose
+// primary key starts with the user, so that the tuples of the users that ReadStartingWithUser
+// reads are index lookups on the large stores. It is maintained on write if enabled with
+// [WithReverseUserIndex], and reconciled with the tuples by [ReconcileReverseUserIndex] before
+// it is enabled in the datastore.
+const ReverseUserIndexTable = "tuple_user_index"
+
+// ReverseUserIndexDeleteConditions returns the conditions of the rows of the ReverseUserIndexTable
+// that a write replaces, given the results of GetDeleteWriteChangelogItems: the rows of the deleted
+// tuples and the ones of the written tuples, which are stale if the tuples were deleted while the
+// index was disabled.
+func ReverseUserIndexDeleteConditions(deleteConditions sq.Or, writeItems [][]interface{}) sq.Or {
+	conditions := make(sq.Or, 0, len(deleteConditions)+len(writeItems))
+	conditions = append(conditions, deleteConditions...)
+	for _, item := range writeItems {
+		conditions = append(conditions, sq.Eq{
+			"object_type": item[1],
+			"object_id":   item[2],
+			"relation":    item[3],
+			"_user":       item[4],
+			"user_type":   item[5],
+		})
+	}
+	return conditions
+}
+
+// writeReverseUserIndex replaces the rows of the ReverseUserIndexTable of the tuples of a write.
+func writeReverseUserIndex(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	txn *sql.Tx,
+	store string,
+	deleteConditions sq.Or,
+	writeItems [][]interface{},
+) error {
+	keys := deleteConditionKeys(ReverseUserIndexDeleteConditions(deleteConditions, writeItems))
+	for start := 0; start < len(keys); start += writeBatchSize {
+		end := min(start+writeBatchSize, len(keys))
+		inExpr, args := BuildRowConstructorIN(keys[start:end])
+
+		_, err := dbInfo.stbl.Delete(ReverseUserIndexTable).Where(sq.Eq{"store": store}).
+			Where(sq.Expr("(object_type, object_id, relation, _user, user_type) IN "+inExpr, args...)).
+			RunWith(txn).
+			ExecContext(ctx)
+		if err != nil {
+			return dbInfo.HandleSQLError(err)
+		}
+	}
+
+	for start := 0; start < len(writeItems); start += writeBatchSize {
+		end := min(start+writeBatchSize, len(writeItems))
+
+		insertBuilder := dbInfo.stbl.
+			Insert(ReverseUserIndexTable).
+			Columns(
+				"store",
+				"object_type",
+				"object_id",
+				"relation",
+				"_user",
+				"user_type",
+				"condition_name",
+				"condition_context",
+				"ulid",
+				"inserted_at",
+			)
+		for _, item := range writeItems[start:end] {
+			insertBuilder = insertBuilder.Values(item...)
+		}
+
+		if _, err := insertBuilder.RunWith(txn).ExecContext(ctx); err != nil {
+			return dbInfo.HandleSQLError(err)
+		}
+	}
+	return nil
+}
+
 // WriteAuthorizationModel writes an authorization model for the given store in one row.
 func WriteAuthorizationModel(
 	ctx context.Context,
@@ -3,10 +3,12 @@ package sqlcommon
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -207,3 +209,83 @@ func TestFetchBufferMetric(t *testing.T) {
 		require.GreaterOrEqual(t, sqlIterQuerySampleCount(t, "true"), before+1)
 	})
 }
+
+func TestSetOperationQuery(t *testing.T) {
+	filter := storage.SetOperationFilter{
+		ObjectType: "document",
+		Union: []storage.SetOperationTerm{
+			{Relation: "owner", Users: []string{"user:anne"}},
+			{Relation: "editor", Users: []string{"user:anne", "user:*"}},
+		},
+		Except: []storage.SetOperationTerm{
+			{Relation: "blocked", Users: []string{"user:anne"}},
+		},
+	}
+	userCondition := func(users []string) sq.Sqlizer {
+		return sq.Eq{"_user": users}
+	}
+	term := "SELECT object_id FROM tuple WHERE object_type = %[1]s AND relation = %[2]s AND store = %[3]s AND %[4]s AND COALESCE(condition_name, '') = %[5]s"
+
+	t.Run("except", func(t *testing.T) {
+		stmt, args, err := SetOperationQuery{
+			Placeholder:   sq.Dollar,
+			UserCondition: userCondition,
+		}.Build("store", filter, 10)
+		require.NoError(t, err)
+		require.Equal(t,
+			fmt.Sprintf(term, "$1", "$2", "$3", "_user IN ($4)", "$5")+" UNION "+
+				fmt.Sprintf(term, "$6", "$7", "$8", "_user IN ($9,$10)", "$11")+" EXCEPT "+
+				fmt.Sprintf(term, "$12", "$13", "$14", "_user IN ($15)", "$16")+" LIMIT 10",
+			stmt)
+		require.Equal(t, []interface{}{
+			"document", "owner", "store", "user:anne", "",
+			"document", "editor", "store", "user:anne", "user:*", "",
+			"document", "blocked", "store", "user:anne", "",
+		}, args)
+	})
+
+	t.Run("without_except", func(t *testing.T) {
+		stmt, _, err := SetOperationQuery{
+			UserCondition: userCondition,
+			WithoutExcept: true,
+		}.Build("store", filter, 0)
+		require.NoError(t, err)
+		q := func(user string) string { return fmt.Sprintf(term, "?", "?", "?", user, "?") }
+		require.Equal(t,
+			"SELECT object_id FROM ("+q("_user IN (?)")+" UNION "+q("_user IN (?,?)")+") AS included WHERE object_id NOT IN ("+q("_user IN (?)")+")",
+			stmt)
+	})
+
+	t.Run("no_union_terms", func(t *testing.T) {
+		_, _, err := SetOperationQuery{UserCondition: userCondition}.Build("store", storage.SetOperationFilter{ObjectType: "document"}, 0)
+		require.Error(t, err)
+	})
+}
+
+func TestDeleteConditionKeys(t *testing.T) {
+	existing := map[string]*openfgav1.Tuple{}
+	deletes := storage.Deletes{
+		tupleUtils.TupleKeyToTupleKeyWithoutCondition(tupleUtils.NewTupleKey("document:1", "viewer", "user:anne")),
+		tupleUtils.TupleKeyToTupleKeyWithoutCondition(tupleUtils.NewTupleKey("document:2", "viewer", "group:eng#member")),
+	}
+	for _, tk := range deletes {
+		existing[tupleUtils.TupleKeyToString(tupleUtils.TupleKeyWithoutConditionToTupleKey(tk))] = &openfgav1.Tuple{}
+	}
+
+	deleteConditions, _, _, err := GetDeleteWriteChangelogItems("store", existing, WriteData{
+		Deletes: deletes,
+		Opts:    storage.NewTupleWriteOptions(),
+		Now:     time.Now(),
+	})
+	require.NoError(t, err)
+
+	keys := deleteConditionKeys(deleteConditions)
+	require.Equal(t, []TupleLockKey{
+		{objectType: "document", objectID: "1", relation: "viewer", user: "user:anne", userType: "user"},
+		{objectType: "document", objectID: "2", relation: "viewer", user: "group:eng#member", userType: "userset"},
+	}, keys)
+
+	inExpr, args := BuildRowConstructorIN(keys)
+	require.Equal(t, "((?,?,?,?,?),(?,?,?,?,?))", inExpr)
+	require.Len(t, args, 10)
+}
@@ -0,0 +1,156 @@
+package sqlcommon
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// DefaultMaxPreparedStatements is the default number of prepared statements of a StmtCache.
+const DefaultMaxPreparedStatements = 256
+
+// StmtCache caches the prepared statements of queries by their SQL, so that the queries of a same
+// shape, like the ones of the tuple iterators that only differ by their arguments, are prepared
+// once instead of on every call. It keeps the most recently used statements up to its maximum
+// size, and closes the statements it evicts once the queries that use them return.
+//
+// Drivers that cache prepared statements on their own, like pgx, don't need it.
+type StmtCache struct {
+	db      *sql.DB
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // GUARDED_BY(mu)
+	lru     *list.List               // GUARDED_BY(mu)
+}
+
+type stmtCacheEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	users   int  // the number of in-flight queries of the statement
+	evicted bool // the statement is closed when its last query returns
+}
+
+// NewStmtCache returns a StmtCache of the statements of db, of at most maxSize statements.
+func NewStmtCache(db *sql.DB, maxSize int) *StmtCache {
+	return &StmtCache{
+		db:      db,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element, maxSize),
+		lru:     list.New(),
+	}
+}
+
+// QueryContext executes the prepared statement of query with args, preparing it on its first use.
+func (c *StmtCache) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	entry, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(entry)
+
+	// the rows keep the statement open until they are closed, even if it is evicted meanwhile
+	return entry.stmt.QueryContext(ctx, args...)
+}
+
+func (c *StmtCache) acquire(ctx context.Context, query string) (*stmtCacheEntry, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[query]; ok {
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*stmtCacheEntry)
+		entry.users++
+		c.mu.Unlock()
+		return entry, nil
+	}
+	c.mu.Unlock()
+
+	// prepare outside of the lock: it is a round trip to the database
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[query]; ok {
+		// another query prepared it concurrently
+		_ = stmt.Close()
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*stmtCacheEntry)
+		entry.users++
+		return entry, nil
+	}
+
+	entry := &stmtCacheEntry{query: query, stmt: stmt, users: 1}
+	c.entries[query] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxSize {
+		c.evict(c.lru.Back())
+	}
+	return entry, nil
+}
+
+func (c *StmtCache) release(entry *stmtCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.users--
+	if entry.evicted && entry.users == 0 {
+		_ = entry.stmt.Close()
+	}
+}
+
+// evict removes elem from the cache, closing its statement unless a query is using it.
+func (c *StmtCache) evict(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*stmtCacheEntry)
+	delete(c.entries, entry.query)
+	entry.evicted = true
+	if entry.users == 0 {
+		_ = entry.stmt.Close()
+	}
+}
+
+// Len returns the number of cached statements.
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close closes the cached statements.
+func (c *StmtCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back())
+	}
+}
+
+// StmtCacheIteratorQuery is a SQLIteratorRowGetter that runs the query of a select builder with the
+// prepared statements of a StmtCache.
+type StmtCacheIteratorQuery struct {
+	sb    sq.SelectBuilder
+	stmts *StmtCache
+}
+
+var _ SQLIteratorRowGetter = (*StmtCacheIteratorQuery)(nil)
+
+// NewStmtCacheIteratorQuery returns a StmtCacheIteratorQuery of sb with the statements of stmts.
+func NewStmtCacheIteratorQuery(sb sq.SelectBuilder, stmts *StmtCache) *StmtCacheIteratorQuery {
+	return &StmtCacheIteratorQuery{sb: sb, stmts: stmts}
+}
+
+// GetRows executes the query with its prepared statement.
+func (q *StmtCacheIteratorQuery) GetRows(ctx context.Context) (Rows, error) {
+	query, args, err := q.sb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return q.stmts.QueryContext(ctx, query, args...)
+}
+
+// ToSql returns the query and the arguments of the select builder.
+func (q *StmtCacheIteratorQuery) ToSql() (string, []interface{}, error) {
+	return q.sb.ToSql()
+}
@@ -0,0 +1,111 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	_ "modernc.org/sqlite"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func TestStmtCacheIteratorQuery(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1) // every connection to :memory: opens a new database
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE tuple (
+		store CHAR(26) NOT NULL,
+		object_type VARCHAR(256) NOT NULL,
+		object_id VARCHAR(256) NOT NULL,
+		relation VARCHAR(50) NOT NULL,
+		_user VARCHAR(512) NOT NULL,
+		condition_name VARCHAR(256),
+		condition_context BLOB,
+		ulid CHAR(26) NOT NULL,
+		inserted_at TIMESTAMP NOT NULL
+	)`)
+	require.NoError(t, err)
+
+	conditionContext, err := structpb.NewStruct(map[string]interface{}{"x": "1"})
+	require.NoError(t, err)
+	encodedConditionContext, err := proto.Marshal(conditionContext)
+	require.NoError(t, err)
+
+	stbl := sq.StatementBuilder.RunWith(db)
+	_, err = stbl.Insert("tuple").
+		Columns(sqlIteratorColumns...).
+		Values("store", "document", "1", "viewer", "group:eng#member", "cond", encodedConditionContext, ulid.Make().String(), time.Now()).
+		Values("store", "document", "2", "viewer", "group:ops#member", nil, nil, ulid.Make().String(), time.Now()).
+		Values("store", "document", "3", "viewer", "user:anne", nil, nil, ulid.Make().String(), time.Now()).
+		Exec()
+	require.NoError(t, err)
+
+	stmts := NewStmtCache(db, 1)
+	t.Cleanup(stmts.Close)
+
+	read := func(objectID string) []*storage.TupleRecord {
+		sb := stbl.Select(sqlIteratorColumns...).
+			From("tuple").
+			Where(sq.Eq{"store": "store"}).
+			Where(sq.Like{"_user": "%#%"}).
+			OrderBy("object_id")
+		if objectID != "" {
+			sb = sb.Where(sq.Eq{"object_id": objectID})
+		}
+
+		iter := NewSQLTupleIterator(NewStmtCacheIteratorQuery(sb, stmts), identityErrHandler)
+		defer iter.Stop()
+
+		var records []*storage.TupleRecord
+		head, err := iter.head(ctx)
+		require.NoError(t, err)
+		for {
+			record, err := iter.next(ctx)
+			if err != nil {
+				require.ErrorIs(t, err, storage.ErrIteratorDone)
+				break
+			}
+			records = append(records, record)
+		}
+		if len(records) > 0 {
+			require.Same(t, head, records[0])
+		}
+		return records
+	}
+
+	records := read("")
+	require.Len(t, records, 2)
+	require.Equal(t, "1", records[0].ObjectID)
+	require.Equal(t, "group:eng#member", records[0].User)
+	require.Equal(t, "cond", records[0].ConditionName)
+	require.True(t, proto.Equal(conditionContext, records[0].ConditionContext))
+	require.Equal(t, "2", records[1].ObjectID)
+	require.Equal(t, "group:ops#member", records[1].User)
+	require.Empty(t, records[1].ConditionName)
+	require.Nil(t, records[1].ConditionContext)
+	require.Equal(t, 1, stmts.Len())
+
+	// the statement of the same shape is reused
+	records = read("")
+	require.Len(t, records, 2)
+	require.Equal(t, 1, stmts.Len())
+
+	// the statement of another shape evicts it
+	records = read("2")
+	require.Len(t, records, 1)
+	require.Equal(t, "2", records[0].ObjectID)
+	require.Equal(t, 1, stmts.Len())
+
+	records = read("")
+	require.Len(t, records, 2)
+}
@@ -57,6 +57,21 @@ type Datastore struct {
 // Ensures that SQLite implements the OpenFGADatastore interface.
 var _ storage.OpenFGADatastore = (*Datastore)(nil)
 
+// Ensures that SQLite implements the SetOperationReader interface.
+var _ storage.SetOperationReader = (*Datastore)(nil)
+
+// Ensures that SQLite implements the ChangelogPruner interface.
+var _ storage.ChangelogPruner = (*Datastore)(nil)
+
+// Ensures that SQLite implements the JobBackend interface.
+var _ storage.JobBackend = (*Datastore)(nil)
+
+// Ensures that SQLite implements the ScheduleBackend interface.
+var _ storage.ScheduleBackend = (*Datastore)(nil)
+
+// Ensures that SQLite implements the MaterializedPermissionBackend interface.
+var _ storage.MaterializedPermissionBackend = (*Datastore)(nil)
+
 // PrepareDSN Prepare a raw DSN from config for use with SQLite, specifying defaults for journal mode and busy timeout.
 func PrepareDSN(uri string) (string, error) {
 	// Set journal mode and busy timeout pragmas if not specified.
@@ -434,9 +449,42 @@ func (s *Datastore) write(
 	changeLogItems := make([][]interface{}, 0, len(deletes)+len(writes))
 
 	// ensures increasingly unique values within a single thread
-	entropy := ulid.DefaultEntropy()
+	entropy := storage.NewChangeEntropy(opts.Region)
 
 	deleteConditions := sq.Or{}
+	var lastChangeID string
+
+	appendDelete := func(object, relation, user string) {
+		id := ulid.MustNew(ulid.Timestamp(now), entropy).String()
+		lastChangeID = id
+		objectType, objectID := tupleUtils.SplitObject(object)
+		userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(user)
+
+		deleteConditions = append(deleteConditions, sq.Eq{
+			"object_type":      objectType,
+			"object_id":        objectID,
+			"relation":         relation,
+			"user_object_type": userObjectType,
+			"user_object_id":   userObjectID,
+			"user_relation":    userRelation,
+			"user_type":        tupleUtils.GetUserTypeFromUser(user),
+		})
+
+		changeLogItems = append(changeLogItems, []interface{}{
+			store,
+			objectType,
+			objectID,
+			relation,
+			userObjectType,
+			userObjectID,
+			userRelation,
+			"",
+			nil, // Redact condition info for deletes since we only need the base triplet (object, relation, user).
+			openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+			id,
+			sq.Expr("datetime('subsec')"),
+		})
+	}
 
 	// 4. For deletes
 	// a. If on_missing: error ( default behavior ):
@@ -464,34 +512,7 @@ func (s *Datastore) write(
 			}
 		}
 
-		id := ulid.MustNew(ulid.Timestamp(now), entropy).String()
-		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
-		userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
-
-		deleteConditions = append(deleteConditions, sq.Eq{
-			"object_type":      objectType,
-			"object_id":        objectID,
-			"relation":         tk.GetRelation(),
-			"user_object_type": userObjectType,
-			"user_object_id":   userObjectID,
-			"user_relation":    userRelation,
-			"user_type":        tupleUtils.GetUserTypeFromUser(tk.GetUser()),
-		})
-
-		changeLogItems = append(changeLogItems, []interface{}{
-			store,
-			objectType,
-			objectID,
-			tk.GetRelation(),
-			userObjectType,
-			userObjectID,
-			userRelation,
-			"",
-			nil, // Redact condition info for deletes since we only need the base triplet (object, relation, user).
-			openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
-			id,
-			sq.Expr("datetime('subsec')"),
-		})
+		appendDelete(tk.GetObject(), tk.GetRelation(), tk.GetUser())
 	}
 
 	writeItems := make([][]interface{}, 0, len(writes))
@@ -504,12 +525,20 @@ func (s *Datastore) write(
 	// - Based on the results from step 3.a, which identified and locked existing rows, the system will compare values to the ones we’re trying to insert
 	// - On conflict ( values not identical ) - return an error 409 Conflict
 	// - For rows that DO NOT exist in DB - create both INSERT tuple & INSERT changelog statements
-	// c. Execute INSERTs as a single statement
+	// c. If on_duplicate: overwrite ( multi-region deployments )
+	// - Same as b., except that on conflict the last writer wins: create DELETE tuple & INSERT changelog statements
+	//   for the existing row, followed by both INSERT tuple & INSERT changelog statements
+	// d. Execute INSERTs as a single statement
 	//   On error, return 409 Conflict
 	for _, tk := range writes {
 		if existingTuple, ok := existing[tupleUtils.TupleKeyToString(tk)]; ok {
 			// If the tuple exists, we can not write it.
 			switch opts.OnDuplicateInsert {
+			case storage.OnDuplicateInsertOverwrite:
+				if proto.Equal(existingTuple.GetKey().GetCondition(), tk.GetCondition()) {
+					continue
+				}
+				appendDelete(tk.GetObject(), tk.GetRelation(), tk.GetUser())
 			case storage.OnDuplicateInsertIgnore:
 				// If the tuple exists and the condition is the same, we can ignore it.
 				// We need to use its serialized text instead of reflect.DeepEqual to avoid comparing internal values.
@@ -529,6 +558,7 @@ func (s *Datastore) write(
 		}
 
 		id := ulid.MustNew(ulid.Timestamp(now), entropy).String()
+		lastChangeID = id
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
 		userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
 
@@ -567,6 +597,9 @@ func (s *Datastore) write(
 			sq.Expr("datetime('subsec')"),
 		})
 	}
+	if opts.OnLastChangeID != nil && lastChangeID != "" {
+		opts.OnLastChangeID(lastChangeID)
+	}
 
 	for start, totalDeletes := 0, len(deleteConditions); start < totalDeletes; start += storage.DefaultMaxTuplesPerWrite {
 		end := start + storage.DefaultMaxTuplesPerWrite
@@ -852,6 +885,532 @@ func (s *Datastore) ReadStartingWithUser(
 	return NewSQLTupleIterator(builder, HandleSQLError), nil
 }
 
+// WriteJob see [storage.JobBackend].WriteJob.
+func (s *Datastore) WriteJob(ctx context.Context, job *storage.Job) error {
+	ctx, span := startTrace(ctx, "WriteJob")
+	defer span.End()
+
+	err := busyRetry(func() error {
+		_, err := s.stbl.
+			Insert("job").
+			Columns("store", "ulid", "kind", "done", "payload", "updated_at").
+			Values(job.Store, job.ID, job.Kind, job.Done, job.Payload, sq.Expr("datetime('subsec')")).
+			Suffix("ON CONFLICT (store, ulid) DO UPDATE SET kind = excluded.kind, done = excluded.done, payload = excluded.payload, updated_at = excluded.updated_at").
+			ExecContext(ctx)
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadJob see [storage.JobBackend].ReadJob.
+func (s *Datastore) ReadJob(ctx context.Context, store, id string) (*storage.Job, error) {
+	ctx, span := startTrace(ctx, "ReadJob")
+	defer span.End()
+
+	var job storage.Job
+	err := s.stbl.
+		Select("store", "ulid", "kind", "done", "cancel_requested", "payload", "updated_at").
+		From("job").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		QueryRowContext(ctx).
+		Scan(&job.Store, &job.ID, &job.Kind, &job.Done, &job.CancelRequested, &job.Payload, &job.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+	return &job, nil
+}
+
+// ListJobs see [storage.JobBackend].ListJobs.
+func (s *Datastore) ListJobs(ctx context.Context, store string) ([]*storage.Job, error) {
+	ctx, span := startTrace(ctx, "ListJobs")
+	defer span.End()
+
+	rows, err := s.stbl.
+		Select("store", "ulid", "kind", "done", "cancel_requested", "payload", "updated_at").
+		From("job").
+		Where(sq.Eq{"store": store}).
+		OrderBy("ulid").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var jobs []*storage.Job
+	for rows.Next() {
+		var job storage.Job
+		err := rows.Scan(&job.Store, &job.ID, &job.Kind, &job.Done, &job.CancelRequested, &job.Payload, &job.UpdatedAt)
+		if err != nil {
+			return nil, HandleSQLError(err)
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return jobs, nil
+}
+
+// CancelJob see [storage.JobBackend].CancelJob.
+func (s *Datastore) CancelJob(ctx context.Context, store, id string) error {
+	ctx, span := startTrace(ctx, "CancelJob")
+	defer span.End()
+
+	var rowsAffected int64
+	err := busyRetry(func() error {
+		res, err := s.stbl.
+			Update("job").
+			Set("cancel_requested", true).
+			Set("updated_at", sq.Expr("datetime('subsec')")).
+			Where(sq.Eq{"store": store, "ulid": id}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteJobs see [storage.JobBackend].DeleteJobs.
+func (s *Datastore) DeleteJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	ctx, span := startTrace(ctx, "DeleteJobs")
+	defer span.End()
+
+	var rowsAffected int64
+	err := busyRetry(func() error {
+		res, err := s.stbl.
+			Delete("job").
+			Where(sq.Eq{"done": true}).
+			Where("updated_at < datetime('subsec', ?)", fmt.Sprintf("%+f seconds", -time.Since(updatedBefore).Seconds())).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return rowsAffected, nil
+}
+
+// WriteSchedule see [storage.ScheduleBackend].WriteSchedule.
+func (s *Datastore) WriteSchedule(ctx context.Context, schedule *storage.Schedule) error {
+	ctx, span := startTrace(ctx, "WriteSchedule")
+	defer span.End()
+
+	err := busyRetry(func() error {
+		_, err := s.stbl.
+			Insert("job_schedule").
+			Columns("store", "ulid", "payload").
+			Values(schedule.Store, schedule.ID, schedule.Payload).
+			Suffix("ON CONFLICT (store, ulid) DO UPDATE SET payload = excluded.payload").
+			ExecContext(ctx)
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadSchedule see [storage.ScheduleBackend].ReadSchedule.
+func (s *Datastore) ReadSchedule(ctx context.Context, store, id string) (*storage.Schedule, error) {
+	ctx, span := startTrace(ctx, "ReadSchedule")
+	defer span.End()
+
+	schedule, err := scanSchedule(s.stbl.
+		Select("store", "ulid", "payload", "last_run_at", "last_job_id").
+		From("job_schedule").
+		Where(sq.Eq{"store": store, "ulid": id}).
+		QueryRowContext(ctx))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+	return schedule, nil
+}
+
+// ListSchedules see [storage.ScheduleBackend].ListSchedules.
+func (s *Datastore) ListSchedules(ctx context.Context, store string) ([]*storage.Schedule, error) {
+	ctx, span := startTrace(ctx, "ListSchedules")
+	defer span.End()
+
+	sb := s.stbl.
+		Select("store", "ulid", "payload", "last_run_at", "last_job_id").
+		From("job_schedule").
+		OrderBy("store", "ulid")
+	if store != "" {
+		sb = sb.Where(sq.Eq{"store": store})
+	}
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var schedules []*storage.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, HandleSQLError(err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule see [storage.ScheduleBackend].DeleteSchedule.
+func (s *Datastore) DeleteSchedule(ctx context.Context, store, id string) error {
+	ctx, span := startTrace(ctx, "DeleteSchedule")
+	defer span.End()
+
+	var rowsAffected int64
+	err := busyRetry(func() error {
+		res, err := s.stbl.
+			Delete("job_schedule").
+			Where(sq.Eq{"store": store, "ulid": id}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// RecordScheduleRun see [storage.ScheduleBackend].RecordScheduleRun.
+func (s *Datastore) RecordScheduleRun(ctx context.Context, store, id string, runAt time.Time, jobID string) error {
+	ctx, span := startTrace(ctx, "RecordScheduleRun")
+	defer span.End()
+
+	var rowsAffected int64
+	err := busyRetry(func() error {
+		res, err := s.stbl.
+			Update("job_schedule").
+			Set("last_run_at", runAt.UTC()).
+			Set("last_job_id", jobID).
+			Where(sq.Eq{"store": store, "ulid": id}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// AcquireLease see [storage.ScheduleBackend].AcquireLease.
+func (s *Datastore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	ctx, span := startTrace(ctx, "AcquireLease")
+	defer span.End()
+
+	var rowsAffected int64
+	err := busyRetry(func() error {
+		res, err := s.stbl.
+			Insert("lease").
+			Columns("name", "holder", "expires_at").
+			Values(name, holder, sq.Expr("datetime('subsec', ?)", fmt.Sprintf("%+f seconds", ttl.Seconds()))).
+			Suffix("ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at WHERE lease.holder = excluded.holder OR lease.expires_at < datetime('subsec')").
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// scanSchedule scans a row of the job_schedule table, whose runs are NULL until recorded.
+func scanSchedule(row sq.RowScanner) (*storage.Schedule, error) {
+	var schedule storage.Schedule
+	var lastRunAt sql.NullTime
+	var lastJobID sql.NullString
+	if err := row.Scan(&schedule.Store, &schedule.ID, &schedule.Payload, &lastRunAt, &lastJobID); err != nil {
+		return nil, err
+	}
+	if lastRunAt.Valid {
+		schedule.LastRunAt = lastRunAt.Time.UTC()
+	}
+	schedule.LastJobID = lastJobID.String
+	return &schedule, nil
+}
+
+// ReadMaterializedRelation see [storage.MaterializedPermissionBackend].ReadMaterializedRelation.
+func (s *Datastore) ReadMaterializedRelation(ctx context.Context, store, objectType, relation string) (*storage.MaterializedRelation, error) {
+	ctx, span := startTrace(ctx, "ReadMaterializedRelation")
+	defer span.End()
+
+	var state storage.MaterializedRelation
+	err := s.stbl.
+		Select("store", "object_type", "relation", "authorization_model_id", "continuation_token", "updated_at").
+		From("materialized_relation").
+		Where(sq.Eq{"store": store, "object_type": objectType, "relation": relation}).
+		QueryRowContext(ctx).
+		Scan(&state.Store, &state.ObjectType, &state.Relation, &state.ModelID, &state.ContinuationToken, &state.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+	state.UpdatedAt = state.UpdatedAt.UTC()
+	return &state, nil
+}
+
+// WriteMaterializedPermissions see [storage.MaterializedPermissionBackend].WriteMaterializedPermissions.
+func (s *Datastore) WriteMaterializedPermissions(ctx context.Context, relation *storage.MaterializedRelation, update storage.MaterializedPermissionsUpdate) error {
+	ctx, span := startTrace(ctx, "WriteMaterializedPermissions")
+	defer span.End()
+
+	var txn *sql.Tx
+	err := busyRetry(func() error {
+		var err error
+		txn, err = s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	key := sq.Eq{"store": relation.Store, "object_type": relation.ObjectType, "relation": relation.Relation}
+
+	if update.Reset || len(update.ObjectIDs) > 0 {
+		deleteBuilder := s.stbl.Delete("materialized_permission").Where(key)
+		if !update.Reset {
+			deleteBuilder = deleteBuilder.Where(sq.Eq{"object_id": update.ObjectIDs})
+		}
+		if _, err := deleteBuilder.RunWith(txn).ExecContext(ctx); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	for start := 0; start < len(update.Permissions); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(update.Permissions))
+		insertBuilder := s.stbl.
+			Insert("materialized_permission").
+			Columns("store", "object_type", "relation", "object_id", "_user").
+			Suffix("ON CONFLICT DO NOTHING")
+		for _, permission := range update.Permissions[start:end] {
+			insertBuilder = insertBuilder.Values(relation.Store, relation.ObjectType, relation.Relation, permission.ObjectID, permission.User)
+		}
+		if _, err := insertBuilder.RunWith(txn).ExecContext(ctx); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	_, err = s.stbl.
+		Insert("materialized_relation").
+		Columns("store", "object_type", "relation", "authorization_model_id", "continuation_token", "updated_at").
+		Values(relation.Store, relation.ObjectType, relation.Relation, relation.ModelID, relation.ContinuationToken, relation.UpdatedAt.UTC()).
+		Suffix("ON CONFLICT (store, object_type, relation) DO UPDATE SET authorization_model_id = excluded.authorization_model_id, continuation_token = excluded.continuation_token, updated_at = excluded.updated_at").
+		RunWith(txn).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	err = busyRetry(func() error {
+		return txn.Commit()
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadMaterializedPermission see [storage.MaterializedPermissionBackend].ReadMaterializedPermission.
+func (s *Datastore) ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error) {
+	ctx, span := startTrace(ctx, "ReadMaterializedPermission")
+	defer span.End()
+
+	if len(users) == 0 {
+		return false, nil
+	}
+
+	var found int
+	err := s.stbl.
+		Select("1").
+		From("materialized_permission").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"relation":    relation,
+			"object_id":   objectID,
+			"_user":       users,
+		}).
+		Limit(1).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	return true, nil
+}
+
+// DeleteMaterializedRelation see [storage.MaterializedPermissionBackend].DeleteMaterializedRelation.
+func (s *Datastore) DeleteMaterializedRelation(ctx context.Context, store, objectType, relation string) error {
+	ctx, span := startTrace(ctx, "DeleteMaterializedRelation")
+	defer span.End()
+
+	var txn *sql.Tx
+	err := busyRetry(func() error {
+		var err error
+		txn, err = s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	key := sq.Eq{"store": store, "object_type": objectType, "relation": relation}
+	for _, table := range []string{"materialized_permission", "materialized_relation"} {
+		if _, err := s.stbl.Delete(table).Where(key).RunWith(txn).ExecContext(ctx); err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	err = busyRetry(func() error {
+		return txn.Commit()
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Datastore) PruneChanges(ctx context.Context, store string, options storage.PruneChangesOptions) (int64, error) {
+	ctx, span := startTrace(ctx, "PruneChanges")
+	defer span.End()
+
+	var nthLatest string
+	if options.KeepLatest > 0 {
+		err := s.stbl.
+			Select("ulid").
+			From("changelog").
+			Where(sq.Eq{"store": store}).
+			OrderBy("ulid desc").
+			Limit(1).
+			Offset(uint64(options.KeepLatest - 1)).
+			QueryRowContext(ctx).
+			Scan(&nthLatest)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, HandleSQLError(err)
+		}
+	}
+
+	cutoff := storage.PruneChangesCutoff(options, nthLatest)
+	if cutoff == "" {
+		return 0, nil
+	}
+
+	var rowsAffected int64
+	err := busyRetry(func() error {
+		res, err := s.stbl.
+			Delete("changelog").
+			Where(sq.Eq{"store": store}).
+			Where(sq.Lt{"ulid": cutoff}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return rowsAffected, nil
+}
+
+// ReadObjectIDsWithSetOperation see [storage.SetOperationReader].ReadObjectIDsWithSetOperation.
+func (s *Datastore) ReadObjectIDsWithSetOperation(
+	ctx context.Context,
+	store string,
+	filter storage.SetOperationFilter,
+	options storage.ReadSetOperationOptions,
+) ([]string, error) {
+	ctx, span := startTrace(ctx, "ReadObjectIDsWithSetOperation")
+	defer span.End()
+
+	stmt, args, err := sqlcommon.SetOperationQuery{
+		UserCondition: func(users []string) sq.Sqlizer {
+			var targetUsers sq.Or
+			for _, u := range users {
+				userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(u)
+				targetUsers = append(targetUsers, sq.Eq{
+					"user_object_type": userObjectType,
+					"user_object_id":   userObjectID,
+					"user_relation":    userRelation,
+				})
+			}
+			return targetUsers
+		},
+	}.Build(store, filter, options.Limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	ids, err := sqlcommon.ScanObjectIDs(rows)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return ids, nil
+}
+
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
 func (s *Datastore) MaxTuplesPerWrite() int {
 	return s.maxTuplesPerWriteField
@@ -1001,3 +1001,59 @@ func TestReadUsersetTuplesFilterWithConditions(t *testing.T) {
 	_, err = iter.Next(ctx)
 	require.Error(t, err)
 }
+
+func TestSQLiteDatastore_ReadObjectIDsWithSetOperation(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "sqlite")
+	uri := testDatastore.GetConnectionURI(true)
+	cfg := sqlcommon.NewConfig()
+	ds, err := New(uri, cfg)
+	require.NoError(t, err)
+	defer ds.Close()
+	ctx := context.Background()
+
+	store := ulid.Make().String()
+
+	err = ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tupleUtils.NewTupleKey("document:1", "owner", "user:alice"),
+		tupleUtils.NewTupleKey("document:2", "editor", "user:alice"),
+		tupleUtils.NewTupleKey("document:3", "editor", "user:*"),
+		tupleUtils.NewTupleKey("document:4", "editor", "user:bob"),
+		tupleUtils.NewTupleKey("document:2", "blocked", "user:alice"),
+		tupleUtils.NewTupleKey("document:5", "owner", "group:eng#member"),
+		tupleUtils.NewTupleKeyWithCondition("document:6", "owner", "user:alice", "cond", nil),
+	})
+	require.NoError(t, err)
+
+	union := []storage.SetOperationTerm{
+		{Relation: "owner", Users: []string{"user:alice"}},
+		{Relation: "editor", Users: []string{"user:alice", "user:*"}},
+	}
+
+	t.Run("union", func(t *testing.T) {
+		ids, err := ds.ReadObjectIDsWithSetOperation(ctx, store, storage.SetOperationFilter{
+			ObjectType: "document",
+			Union:      union,
+		}, storage.ReadSetOperationOptions{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"1", "2", "3"}, ids)
+	})
+
+	t.Run("union_except", func(t *testing.T) {
+		ids, err := ds.ReadObjectIDsWithSetOperation(ctx, store, storage.SetOperationFilter{
+			ObjectType: "document",
+			Union:      union,
+			Except:     []storage.SetOperationTerm{{Relation: "blocked", Users: []string{"user:alice"}}},
+		}, storage.ReadSetOperationOptions{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"1", "3"}, ids)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		ids, err := ds.ReadObjectIDsWithSetOperation(ctx, store, storage.SetOperationFilter{
+			ObjectType: "document",
+			Union:      union,
+		}, storage.ReadSetOperationOptions{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/oklog/ulid/v2"
+
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 )
 
@@ -233,6 +235,11 @@ const (
 	// OnDuplicateInsertIgnore indicates that if an insert operation is attempted on a tuple that already exists,
 	// it should be ignored as a no-op and no error should be returned.
 	OnDuplicateInsertIgnore OnDuplicateInsert = 1
+
+	// OnDuplicateInsertOverwrite indicates that if an insert operation is attempted on a tuple that already exists,
+	// the last writer wins: the existing tuple is replaced if its condition differs, which is recorded in the changelog
+	// as its delete followed by its write, and the operation is ignored otherwise.
+	OnDuplicateInsertOverwrite OnDuplicateInsert = 2
 )
 
 // TupleWriteOptions defines the options that can be used when writing tuples.
@@ -241,6 +248,15 @@ const (
 type TupleWriteOptions struct {
 	OnMissingDelete   OnMissingDelete
 	OnDuplicateInsert OnDuplicateInsert
+
+	// Region is the ID of the region the write originates from, which is encoded in the ULIDs of its
+	// changes, see NewChangeEntropy. 0 means that the deployment is not multi-region.
+	Region uint8
+
+	// OnLastChangeID, if set, is called with the ULID of the last change of the write in the
+	// changelog, before the write is committed. It is the ULID of a committed change only if Write
+	// returns no error.
+	OnLastChangeID func(id string)
 }
 
 type TupleWriteOption func(*TupleWriteOptions)
@@ -257,6 +273,20 @@ func WithOnDuplicateInsert(onDuplicateInsert OnDuplicateInsert) TupleWriteOption
 	}
 }
 
+// WithRegion sets the ID of the region the write originates from.
+func WithRegion(region uint8) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.Region = region
+	}
+}
+
+// WithOnLastChangeID sets the function called with the ULID of the last change of the write.
+func WithOnLastChangeID(onLastChangeID func(id string)) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.OnLastChangeID = onLastChangeID
+	}
+}
+
 func NewTupleWriteOptions(opts ...TupleWriteOption) TupleWriteOptions {
 	res := TupleWriteOptions{
 		OnMissingDelete:   OnMissingDeleteError,
@@ -404,6 +434,221 @@ type ChangelogBackend interface {
 	ReadChanges(ctx context.Context, store string, filter ReadChangesFilter, options ReadChangesOptions) ([]*openfgav1.TupleChange, string, error)
 }
 
+// PruneChangesOptions selects the changes of a store deleted by [ChangelogPruner].PruneChanges.
+// A change is deleted if it was inserted before OlderThan or if it is not one of the KeepLatest
+// most recent changes, unless it is at or after the Horizon.
+type PruneChangesOptions struct {
+	// OlderThan is the time before which changes are deleted. The zero value disables this bound.
+	OlderThan time.Time
+
+	// KeepLatest is the number of most recent changes that are kept. 0 disables this bound.
+	KeepLatest uint32
+
+	// Horizon is the ULID of the oldest change that must be kept, e.g. the position of the oldest
+	// active ReadChanges cursor. Empty means that no change is protected.
+	Horizon string
+}
+
+// ChangelogPruner is implemented by datastores that can delete old changes from the changelog.
+type ChangelogPruner interface {
+	// PruneChanges deletes the changes of a store selected by options and returns how many
+	// changes were deleted.
+	PruneChanges(ctx context.Context, store string, options PruneChangesOptions) (int64, error)
+}
+
+// PruneChangesCutoff returns the ULID below which changes are deleted according to options, given
+// the ULID of the KeepLatest-th most recent change of the store (empty if the store has fewer
+// changes). It returns an empty string if no change must be deleted.
+func PruneChangesCutoff(options PruneChangesOptions, nthLatest string) string {
+	var cutoff string
+	if !options.OlderThan.IsZero() {
+		var id ulid.ULID
+		if err := id.SetTime(ulid.Timestamp(options.OlderThan)); err == nil {
+			cutoff = id.String()
+		}
+	}
+	if options.KeepLatest > 0 && nthLatest > cutoff {
+		cutoff = nthLatest
+	}
+	if options.Horizon != "" && options.Horizon < cutoff {
+		cutoff = options.Horizon
+	}
+	return cutoff
+}
+
+// ChangelogPartitionInterval is the time range of the changes of each partition of a changelog
+// partitioned by time.
+const ChangelogPartitionInterval = 24 * time.Hour
+
+// ChangelogPartitioner is implemented by datastores whose changelog can be partitioned by the time
+// of its changes, so that the old changes are dropped with their partitions rather than deleted one
+// by one. The partitions span ChangelogPartitionInterval, and their methods return
+// errors.ErrUnsupported if the changelog is not partitioned by time.
+type ChangelogPartitioner interface {
+	// CreateChangelogPartitions creates the missing partitions of the changes inserted from now
+	// until the given time.
+	CreateChangelogPartitions(ctx context.Context, until time.Time) error
+
+	// DropChangelogPartitions drops the partitions whose changes were all inserted before the
+	// given time, and returns how many partitions were dropped.
+	DropChangelogPartitions(ctx context.Context, before time.Time) (int, error)
+}
+
+// ChangelogPartitionStart returns the start of the changelog partition of the changes inserted at
+// the given time.
+func ChangelogPartitionStart(at time.Time) time.Time {
+	return at.UTC().Truncate(ChangelogPartitionInterval)
+}
+
+// ChangelogPartitionBound returns the smallest ULID of the changes inserted at or after the given
+// time, i.e. the bound between the changelog partitions of the changes inserted before and after it.
+func ChangelogPartitionBound(at time.Time) string {
+	var id ulid.ULID
+	_ = id.SetTime(ulid.Timestamp(at))
+	return id.String()
+}
+
+// ChangeListener is implemented by the components that push the changes of the tuples of every
+// store as soon as they are committed to a datastore, e.g. by subscribing to its replication stream.
+type ChangeListener interface {
+	// Listen calls handle with the changes of the tuples of each store in each committed
+	// transaction, in commit order, until ctx is done or the subscription fails. It returns the
+	// error that ended the subscription. The changes committed while no Listen call is in progress
+	// are not pushed.
+	Listen(ctx context.Context, handle func(store string, changes []*openfgav1.TupleChange)) error
+}
+
+// Job is a background job of a store persisted by a [JobBackend]. The datastores don't interpret
+// its Kind or its Payload, which are encoded by the component that runs it.
+type Job struct {
+	Store string
+	// ID is a ULID.
+	ID   string
+	Kind string
+	// Done is set once the job finished. Only the done jobs are deleted by DeleteJobs.
+	Done bool
+	// CancelRequested is set by CancelJob, so that the replica that runs the job stops it.
+	CancelRequested bool
+	Payload         []byte
+	// UpdatedAt is the time of the last WriteJob or CancelJob of the job.
+	UpdatedAt time.Time
+}
+
+// JobBackend is implemented by datastores that persist the background jobs of the stores, so that
+// any replica can report them and request their cancellation.
+type JobBackend interface {
+	// WriteJob creates a job, or replaces its Kind, Done and Payload. CancelRequested is ignored,
+	// so that a cancellation is never overwritten, and UpdatedAt is set to the current time.
+	WriteJob(ctx context.Context, job *Job) error
+
+	// ReadJob returns a job of a store, or ErrNotFound.
+	ReadJob(ctx context.Context, store, id string) (*Job, error)
+
+	// ListJobs returns the jobs of a store, ordered by ID.
+	ListJobs(ctx context.Context, store string) ([]*Job, error)
+
+	// CancelJob sets CancelRequested on a job of a store, or returns ErrNotFound.
+	CancelJob(ctx context.Context, store, id string) error
+
+	// DeleteJobs deletes the done jobs of every store last updated before updatedBefore and returns
+	// how many jobs were deleted.
+	DeleteJobs(ctx context.Context, updatedBefore time.Time) (int64, error)
+}
+
+// Schedule is a recurring background job of a store persisted by a [ScheduleBackend]. The
+// datastores don't interpret its Payload, which is encoded by the component that runs it.
+type Schedule struct {
+	Store string
+	// ID is a ULID.
+	ID      string
+	Payload []byte
+	// LastRunAt is the time of the last run recorded by RecordScheduleRun, or the zero time.
+	LastRunAt time.Time
+	// LastJobID is the ID of the job created by the last run.
+	LastJobID string
+}
+
+// ScheduleBackend is implemented by datastores that persist the recurring background jobs of the
+// stores, and the leases that elect the replica that runs them.
+type ScheduleBackend interface {
+	// WriteSchedule creates a schedule, or replaces its Payload. LastRunAt and LastJobID are
+	// ignored, so that the runs are only recorded by RecordScheduleRun.
+	WriteSchedule(ctx context.Context, schedule *Schedule) error
+
+	// ReadSchedule returns a schedule of a store, or ErrNotFound.
+	ReadSchedule(ctx context.Context, store, id string) (*Schedule, error)
+
+	// ListSchedules returns the schedules of a store, or of every store if store is empty, ordered
+	// by store and ID.
+	ListSchedules(ctx context.Context, store string) ([]*Schedule, error)
+
+	// DeleteSchedule deletes a schedule of a store, or returns ErrNotFound.
+	DeleteSchedule(ctx context.Context, store, id string) error
+
+	// RecordScheduleRun sets LastRunAt and LastJobID on a schedule of a store, or returns
+	// ErrNotFound, e.g. if it was deleted while it ran.
+	RecordScheduleRun(ctx context.Context, store, id string, runAt time.Time, jobID string) error
+
+	// AcquireLease acquires the lease name for holder until ttl elapses, or extends it if holder
+	// already holds it, and returns whether holder holds it. A lease is acquired by another holder
+	// only once it expired. The expiration is computed with the clock of the datastore.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+}
+
+// MaterializedRelation is the state of the materialized permissions of a relation of a store,
+// persisted by a [MaterializedPermissionBackend].
+type MaterializedRelation struct {
+	Store      string
+	ObjectType string
+	Relation   string
+	// ModelID is the ID of the authorization model the permissions were computed with.
+	ModelID string
+	// ContinuationToken is the position in the changelog of the store up to which the permissions
+	// are up to date, as returned by ReadChanges.
+	ContinuationToken string
+	// UpdatedAt is the time up to which the changes of the changelog are reflected in the
+	// permissions, i.e. the time the changelog was last read at.
+	UpdatedAt time.Time
+}
+
+// MaterializedPermission is a row of a materialized relation: User, e.g. "user:anne" or the typed
+// wildcard "user:*", is related to the object of ObjectID.
+type MaterializedPermission struct {
+	ObjectID string
+	User     string
+}
+
+// MaterializedPermissionsUpdate is a change of the permissions of a materialized relation.
+type MaterializedPermissionsUpdate struct {
+	// Reset deletes the permissions of every object of the relation, e.g. when they are
+	// recomputed with a new authorization model.
+	Reset bool
+	// ObjectIDs are the objects whose permissions are deleted before Permissions are written.
+	ObjectIDs   []string
+	Permissions []MaterializedPermission
+}
+
+// MaterializedPermissionBackend is implemented by datastores that persist the denormalized
+// (user, relation, object) permissions of the relations designated for materialization, so that
+// their Checks are answered with a single row lookup.
+type MaterializedPermissionBackend interface {
+	// ReadMaterializedRelation returns the state of a materialized relation of a store, or
+	// ErrNotFound.
+	ReadMaterializedRelation(ctx context.Context, store, objectType, relation string) (*MaterializedRelation, error)
+
+	// WriteMaterializedPermissions applies update to the permissions of a materialized relation
+	// and replaces its state, in a single transaction.
+	WriteMaterializedPermissions(ctx context.Context, relation *MaterializedRelation, update MaterializedPermissionsUpdate) error
+
+	// ReadMaterializedPermission reports whether one of users is related to the object of
+	// objectType and objectID by a materialized relation of a store.
+	ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error)
+
+	// DeleteMaterializedRelation deletes the state and the permissions of a materialized relation
+	// of a store.
+	DeleteMaterializedRelation(ctx context.Context, store, objectType, relation string) error
+}
+
 // OpenFGADatastore is an interface that defines a set of methods for interacting
 // with and managing data in an OpenFGA (Fine-Grained Authorization) system.
 type OpenFGADatastore interface {
@@ -420,6 +665,42 @@ type OpenFGADatastore interface {
 	Close()
 }
 
+// SetOperationTerm selects the unconditioned tuples of one relation whose user is one of Users
+// (e.g. "user:anne" or the typed wildcard "user:*").
+type SetOperationTerm struct {
+	Relation string
+	Users    []string
+}
+
+// SetOperationFilter describes a set-algebra query over the direct relationships of one object type:
+// the objects that match at least one of the Union terms and none of the Except terms.
+type SetOperationFilter struct {
+	// Mandatory.
+	ObjectType string
+	// Mandatory. The objects matching any of these terms are included in the result.
+	Union []SetOperationTerm
+	// Optional. The objects matching any of these terms are removed from the result.
+	Except []SetOperationTerm
+}
+
+// ReadSetOperationOptions represents the options that can
+// be used with the ReadObjectIDsWithSetOperation method.
+type ReadSetOperationOptions struct {
+	Consistency ConsistencyOptions
+	// Limit is the maximum number of object IDs to return. If 0, all the object IDs are returned.
+	Limit uint32
+}
+
+// SetOperationReader is an optional interface implemented by datastores that can evaluate
+// unions and exclusions of direct relationships as a single query, rather than having each
+// branch read and merged by the caller.
+type SetOperationReader interface {
+	// ReadObjectIDsWithSetOperation returns the distinct IDs of the objects matching the filter.
+	// Tuples with a condition never match a term. There is NO guarantee on the order of the IDs.
+	// If the datastore cannot evaluate the filter, it must return [errors.ErrUnsupported].
+	ReadObjectIDsWithSetOperation(ctx context.Context, store string, filter SetOperationFilter, options ReadSetOperationOptions) ([]string, error)
+}
+
 // ReadinessStatus represents the readiness status of the datastore.
 type ReadinessStatus struct {
 	// Message is a human-friendly status message for the current datastore status.
@@ -28,15 +28,22 @@ type Metadata struct {
 	DatastoreQueryCount uint32
 	DatastoreItemCount  uint64
 	WasThrottled        bool
+
+	// DatastoreDuration is the cumulative time spent in the datastore. Concurrent reads are added
+	// up, so it can exceed the duration of the request.
+	DatastoreDuration time.Duration
 }
 
 type countingTupleIterator struct {
 	storage.TupleIterator
-	counter *atomic.Uint64
+	counter  *atomic.Uint64
+	duration *atomic.Int64
 }
 
 func (itr *countingTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	start := time.Now()
 	i, err := itr.TupleIterator.Next(ctx)
+	itr.duration.Add(int64(time.Since(start)))
 	if err != nil {
 		return i, err
 	}
@@ -75,6 +82,7 @@ type BoundedTupleReader struct {
 	limiter    chan struct{} // bound concurrency
 	countReads atomic.Uint32
 	countItems atomic.Uint64
+	duration   atomic.Int64 // nanoseconds
 	method     string
 
 	throttlingEnabled bool
@@ -104,6 +112,7 @@ func (b *BoundedTupleReader) GetMetadata() Metadata {
 		DatastoreQueryCount: b.countReads.Load(),
 		DatastoreItemCount:  b.countItems.Load(),
 		WasThrottled:        b.throttled.Load(),
+		DatastoreDuration:   time.Duration(b.duration.Load()),
 	}
 }
 
@@ -120,7 +129,9 @@ func (b *BoundedTupleReader) ReadUserTuple(
 	}
 
 	defer b.done()
+	start := time.Now()
 	t, err := b.RelationshipTupleReader.ReadUserTuple(ctx, store, filter, options)
+	b.duration.Add(int64(time.Since(start)))
 	if t == nil || err != nil {
 		return t, err
 	}
@@ -136,11 +147,13 @@ func (b *BoundedTupleReader) Read(ctx context.Context, store string, filter stor
 	}
 
 	defer b.done()
+	start := time.Now()
 	itr, err := b.RelationshipTupleReader.Read(ctx, store, filter, options)
+	b.duration.Add(int64(time.Since(start)))
 	if itr == nil || err != nil {
 		return itr, err
 	}
-	return &countingTupleIterator{itr, &b.countItems}, nil
+	return &countingTupleIterator{itr, &b.countItems, &b.duration}, nil
 }
 
 // ReadUsersetTuples returns all userset tuples for a specified object and relation.
@@ -156,11 +169,13 @@ func (b *BoundedTupleReader) ReadUsersetTuples(
 	}
 
 	defer b.done()
+	start := time.Now()
 	itr, err := b.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter, options)
+	b.duration.Add(int64(time.Since(start)))
 	if itr == nil || err != nil {
 		return itr, err
 	}
-	return &countingTupleIterator{itr, &b.countItems}, nil
+	return &countingTupleIterator{itr, &b.countItems, &b.duration}, nil
 }
 
 // ReadStartingWithUser performs a reverse read of relationship tuples starting at one or
@@ -178,11 +193,13 @@ func (b *BoundedTupleReader) ReadStartingWithUser(
 
 	defer b.done()
 
+	start := time.Now()
 	itr, err := b.RelationshipTupleReader.ReadStartingWithUser(ctx, store, filter, options)
+	b.duration.Add(int64(time.Since(start)))
 	if itr == nil || err != nil {
 		return itr, err
 	}
-	return &countingTupleIterator{itr, &b.countItems}, nil
+	return &countingTupleIterator{itr, &b.countItems, &b.duration}, nil
 }
 
 func (b *BoundedTupleReader) instrument(ctx context.Context, op string, d time.Duration, vec *prometheus.HistogramVec) {
@@ -33,6 +33,21 @@ type CachedTupleReader struct {
 	drainTimeout time.Duration // Timeout for background drain operations
 	sf           *singleflight.Group
 	wg           *sync.WaitGroup
+
+	// jitterPercentage is the percentage of the ttl added as random jitter to the TTL of each entry.
+	jitterPercentage uint32
+}
+
+// CachedTupleReaderOpt defines an option that can be used to change the behavior of a CachedTupleReader.
+type CachedTupleReaderOpt func(*CachedTupleReader)
+
+// WithCachedTupleReaderJitterPercentage sets the percentage (0-100) of the ttl added as random
+// jitter to the TTL of each cached iterator, so that the iterators cached by a burst of requests
+// don't all expire at once.
+func WithCachedTupleReaderJitterPercentage(pct uint32) CachedTupleReaderOpt {
+	return func(c *CachedTupleReader) {
+		c.jitterPercentage = pct
+	}
 }
 
 // Ensure CachedTupleReader implements RelationshipTupleReader.
@@ -50,6 +65,7 @@ func NewCachedTupleReader(
 	sf *singleflight.Group,
 	wg *sync.WaitGroup,
 	drainTimeout time.Duration,
+	opts ...CachedTupleReaderOpt,
 ) *CachedTupleReader {
 	if maxSize <= 0 {
 		maxSize = maxCachedElements // Default to 1000
@@ -65,7 +81,7 @@ func NewCachedTupleReader(
 	if sf == nil {
 		sf = &singleflight.Group{}
 	}
-	return &CachedTupleReader{
+	c := &CachedTupleReader{
 		delegate:     delegate,
 		cache:        cache,
 		maxSize:      maxSize,
@@ -74,6 +90,12 @@ func NewCachedTupleReader(
 		sf:           sf,
 		wg:           wg,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // ReadUsersetTuples reads userset tuples with caching.
@@ -118,7 +140,7 @@ func (c *CachedTupleReader) ReadUsersetTuples(
 
 	// Return caching iterator
 	return newCachingIterator(
-		dbIter, c.cache, cacheKey, c.maxSize, c.ttl, c.drainTimeout,
+		dbIter, c.cache, cacheKey, c.maxSize, storage.JitteredTTL(c.ttl, c.jitterPercentage), c.drainTimeout,
 		c.sf, c.wg, objectType, filter.Relation, "ReadUsersetTuples",
 	), nil
 }
@@ -159,7 +181,7 @@ func (c *CachedTupleReader) Read(
 	}
 
 	return newCachingIterator(
-		dbIter, c.cache, cacheKey, c.maxSize, c.ttl, c.drainTimeout,
+		dbIter, c.cache, cacheKey, c.maxSize, storage.JitteredTTL(c.ttl, c.jitterPercentage), c.drainTimeout,
 		c.sf, c.wg, objectType, filter.Relation, "Read",
 	), nil
 }
@@ -199,7 +221,7 @@ func (c *CachedTupleReader) ReadStartingWithUser(
 	}
 
 	return newCachingIterator(
-		dbIter, c.cache, cacheKey, c.maxSize, c.ttl, c.drainTimeout,
+		dbIter, c.cache, cacheKey, c.maxSize, storage.JitteredTTL(c.ttl, c.jitterPercentage), c.drainTimeout,
 		c.sf, c.wg, filter.ObjectType, filter.Relation, "ReadStartingWithUser",
 	), nil
 }
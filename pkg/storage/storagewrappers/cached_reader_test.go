@@ -71,6 +71,40 @@ func TestCachedTupleReader_ReadUsersetTuples_CacheMiss(t *testing.T) {
 	require.True(t, ok, "Expected CachingIterator on cache miss")
 }
 
+func TestCachedTupleReader_JitteredTTL(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockCache := mocks.NewMockInMemoryCache[any](mockController)
+	mockDatastore := mocks.NewMockRelationshipTupleReader(mockController)
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	reader := NewCachedTupleReader(ctx, mockDatastore, mockCache, 1000, time.Hour, &singleflight.Group{}, &sync.WaitGroup{}, 30*time.Second,
+		WithCachedTupleReaderJitterPercentage(50))
+
+	filter := storage.ReadFilter{Object: "document:1", Relation: "parent", User: "folder:"}
+	opts := storage.ReadOptions{}
+
+	mockCache.EXPECT().Get(gomock.Any()).Return(nil).AnyTimes()
+	mockDatastore.EXPECT().Read(gomock.Any(), storeID, filter, opts).Return(storage.NewStaticTupleIterator(nil), nil).AnyTimes()
+
+	for range 10 {
+		iter, err := reader.Read(ctx, storeID, filter, opts)
+		require.NoError(t, err)
+
+		cachingIter, ok := iter.(*CachingIterator)
+		require.True(t, ok)
+		require.GreaterOrEqual(t, cachingIter.ttl, time.Hour)
+		require.LessOrEqual(t, cachingIter.ttl, 90*time.Minute)
+	}
+}
+
 func TestCachedTupleReader_ReadUsersetTuples_CacheHit(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
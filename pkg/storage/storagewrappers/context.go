@@ -14,6 +14,7 @@ import (
 // original context is cancelled, helping to avoid unnecessary database connection churn.
 type ContextTracerWrapper struct {
 	storage.OpenFGADatastore
+	optionalBackends
 }
 
 var _ storage.OpenFGADatastore = (*ContextTracerWrapper)(nil)
@@ -21,7 +22,10 @@ var _ storage.OpenFGADatastore = (*ContextTracerWrapper)(nil)
 // NewContextWrapper creates a new instance of [ContextTracerWrapper], wrapping the specified datastore. It is crucial
 // for [ContextTracerWrapper] to be the first wrapper around the datastore for traces to function correctly.
 func NewContextWrapper(inner storage.OpenFGADatastore) *ContextTracerWrapper {
-	return &ContextTracerWrapper{inner}
+	return &ContextTracerWrapper{
+		OpenFGADatastore: inner,
+		optionalBackends: optionalBackends{ds: inner, withContext: queryContext},
+	}
 }
 
 // queryContext generates a new context that is independent of the provided
@@ -0,0 +1,416 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// EncryptedDatastore is a wrapper of a datastore that encodes the IDs of the users of the tuples
+// at rest with a [UserIDCodec], so that the identifiers of e.g. the people in the users of the
+// tuples are protected even with a direct access to the database. The types and the relations of
+// the users, and the wildcards, are not encoded, so that the queries filtered by user type still
+// work. The users in the filters of the queries are encoded, and the users of the tuples read are
+// decoded.
+//
+// The tuples written before the wrapper was added are read as is, but are not found by the
+// queries filtered by their user until they are written again. Their IDs must not look like the
+// encoded IDs of the codec, e.g. start with the "enc." prefix of the AES codec, since they would
+// be decoded.
+type EncryptedDatastore struct {
+	storage.OpenFGADatastore
+	optionalBackends
+	codec           UserIDCodec
+	types           map[string]struct{}
+	maxEncodedBytes int
+}
+
+var _ storage.OpenFGADatastore = (*EncryptedDatastore)(nil)
+
+// DefaultMaxEncodedUserBytes is the default maximum length of the encoded users, the maximum length
+// of the users of the API.
+const DefaultMaxEncodedUserBytes = 512
+
+var (
+	// ErrEncodedUserTooLong is returned by Write when the encoded user of a tuple is longer than
+	// the datastore allows, see WithMaxEncodedUserBytes.
+	ErrEncodedUserTooLong = errors.New("the encoded user is too long")
+
+	// ErrUserIDLooksEncoded is returned by Write when the ID of a user that is not encoded would be
+	// decoded by the codec, e.g. when it starts with the prefix of the encoded IDs.
+	ErrUserIDLooksEncoded = errors.New("the ID of the user looks like an encoded ID")
+)
+
+// EncryptedDatastoreOpt configures an [EncryptedDatastore].
+type EncryptedDatastoreOpt func(*EncryptedDatastore)
+
+// WithMaxEncodedUserBytes sets the maximum length of the encoded users, e.g. the length of the
+// user column of the datastore. It defaults to DefaultMaxEncodedUserBytes.
+func WithMaxEncodedUserBytes(maxBytes int) EncryptedDatastoreOpt {
+	return func(d *EncryptedDatastore) {
+		d.maxEncodedBytes = maxBytes
+	}
+}
+
+// NewEncryptedDatastore returns an [EncryptedDatastore] of inner that encodes the IDs of the users
+// of types with codec, or of the users of all the types if types is empty.
+func NewEncryptedDatastore(inner storage.OpenFGADatastore, codec UserIDCodec, types []string, opts ...EncryptedDatastoreOpt) *EncryptedDatastore {
+	d := &EncryptedDatastore{
+		OpenFGADatastore: inner,
+		optionalBackends: optionalBackends{ds: inner},
+		codec:            codec,
+		types:            make(map[string]struct{}, len(types)),
+		maxEncodedBytes:  DefaultMaxEncodedUserBytes,
+	}
+	for _, typ := range types {
+		d.types[typ] = struct{}{}
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// encodedType reports whether the IDs of the users of userType are encoded.
+func (d *EncryptedDatastore) encodedType(userType string) bool {
+	if len(d.types) == 0 {
+		return true
+	}
+	_, ok := d.types[userType]
+	return ok
+}
+
+// encodedUser returns user with its ID encoded, if it is of one of the encoded types.
+func (d *EncryptedDatastore) encodedUser(ctx context.Context, user string) (string, error) {
+	userType, userID, userRelation := tuple.ToUserParts(user)
+	if userType == "" || userID == "" || userID == tuple.Wildcard || !d.encodedType(userType) {
+		return user, nil
+	}
+
+	encoded, err := d.codec.EncodeUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return tuple.FromUserParts(userType, encoded, userRelation), nil
+}
+
+// decodedUser returns user with its ID decoded, if it is of one of the encoded types.
+func (d *EncryptedDatastore) decodedUser(ctx context.Context, user string) (string, error) {
+	userType, userID, userRelation := tuple.ToUserParts(user)
+	if userType == "" || userID == "" || userID == tuple.Wildcard || !d.encodedType(userType) {
+		return user, nil
+	}
+
+	decoded, err := d.codec.DecodeUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return tuple.FromUserParts(userType, decoded, userRelation), nil
+}
+
+// decodedTuple returns a copy of t with its user decoded, since the tuples returned by the
+// datastore may be shared with its own state.
+func (d *EncryptedDatastore) decodedTuple(ctx context.Context, t *openfgav1.Tuple) (*openfgav1.Tuple, error) {
+	key, err := d.decodedTupleKey(ctx, t.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	return &openfgav1.Tuple{Key: key, Timestamp: t.GetTimestamp()}, nil
+}
+
+func (d *EncryptedDatastore) decodedTupleKey(ctx context.Context, tk *openfgav1.TupleKey) (*openfgav1.TupleKey, error) {
+	user, err := d.decodedUser(ctx, tk.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	return &openfgav1.TupleKey{
+		Object:    tk.GetObject(),
+		Relation:  tk.GetRelation(),
+		User:      user,
+		Condition: tk.GetCondition(),
+	}, nil
+}
+
+func (d *EncryptedDatastore) decodedIterator(iter storage.TupleIterator, err error) (storage.TupleIterator, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &decodedTupleIterator{TupleIterator: iter, datastore: d}, nil
+}
+
+// Read see [storage.RelationshipTupleReader].Read.
+func (d *EncryptedDatastore) Read(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadOptions) (storage.TupleIterator, error) {
+	var err error
+	filter.User, err = d.encodedUser(ctx, filter.User)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodedIterator(d.OpenFGADatastore.Read(ctx, store, filter, options))
+}
+
+// ReadPage see [storage.RelationshipTupleReader].ReadPage.
+func (d *EncryptedDatastore) ReadPage(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+	var err error
+	filter.User, err = d.encodedUser(ctx, filter.User)
+	if err != nil {
+		return nil, "", err
+	}
+	tuples, token, err := d.OpenFGADatastore.ReadPage(ctx, store, filter, options)
+	if err != nil {
+		return nil, "", err
+	}
+
+	decoded := make([]*openfgav1.Tuple, 0, len(tuples))
+	for _, t := range tuples {
+		t, err := d.decodedTuple(ctx, t)
+		if err != nil {
+			return nil, "", err
+		}
+		decoded = append(decoded, t)
+	}
+	return decoded, token, nil
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (d *EncryptedDatastore) ReadUserTuple(ctx context.Context, store string, filter storage.ReadUserTupleFilter, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	var err error
+	filter.User, err = d.encodedUser(ctx, filter.User)
+	if err != nil {
+		return nil, err
+	}
+	t, err := d.OpenFGADatastore.ReadUserTuple(ctx, store, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodedTuple(ctx, t)
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
+func (d *EncryptedDatastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	return d.decodedIterator(d.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options))
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
+func (d *EncryptedDatastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	userFilter := make([]*openfgav1.ObjectRelation, 0, len(filter.UserFilter))
+	for _, u := range filter.UserFilter {
+		object, err := d.encodedUser(ctx, u.GetObject())
+		if err != nil {
+			return nil, err
+		}
+		userFilter = append(userFilter, &openfgav1.ObjectRelation{Object: object, Relation: u.GetRelation()})
+	}
+	filter.UserFilter = userFilter
+	return d.decodedIterator(d.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options))
+}
+
+// Write see [storage.RelationshipTupleWriter].Write.
+func (d *EncryptedDatastore) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes, opts ...storage.TupleWriteOption) error {
+	// the errors of the datastore, e.g. of the tuples that already exist, report the encoded users
+	var replacements []string
+	encodedUser := func(user string) (string, error) {
+		encoded, err := d.encodedUser(ctx, user)
+		if err == nil && encoded != user {
+			replacements = append(replacements, encoded, user)
+		}
+		return encoded, err
+	}
+
+	encodedDeletes := make(storage.Deletes, 0, len(deletes))
+	for _, tk := range deletes {
+		user, err := encodedUser(tk.GetUser())
+		if err != nil {
+			return err
+		}
+		encodedDeletes = append(encodedDeletes, &openfgav1.TupleKeyWithoutCondition{
+			Object:   tk.GetObject(),
+			Relation: tk.GetRelation(),
+			User:     user,
+		})
+	}
+
+	encodedWrites := make(storage.Writes, 0, len(writes))
+	for _, tk := range writes {
+		if err := d.validateUnencodedUser(ctx, tk.GetUser()); err != nil {
+			return err
+		}
+		user, err := encodedUser(tk.GetUser())
+		if err != nil {
+			return err
+		}
+		if len(user) > d.maxEncodedBytes {
+			return &invalidUserError{err: fmt.Errorf("%w: %d bytes, the maximum is %d", ErrEncodedUserTooLong, len(user), d.maxEncodedBytes), user: tk.GetUser()}
+		}
+		encodedWrites = append(encodedWrites, &openfgav1.TupleKey{
+			Object:    tk.GetObject(),
+			Relation:  tk.GetRelation(),
+			User:      user,
+			Condition: tk.GetCondition(),
+		})
+	}
+	err := d.OpenFGADatastore.Write(ctx, store, encodedDeletes, encodedWrites, opts...)
+	if err == nil || len(replacements) == 0 {
+		return err
+	}
+	return &decodedError{msg: strings.NewReplacer(replacements...).Replace(err.Error()), err: err}
+}
+
+// validateUnencodedUser returns an error if user is not of an encoded type and its ID would be
+// decoded by the codec, so that the users written in plain text are still read as is once their
+// type is encoded.
+func (d *EncryptedDatastore) validateUnencodedUser(ctx context.Context, user string) error {
+	userType, userID, _ := tuple.ToUserParts(user)
+	if userType == "" || userID == "" || userID == tuple.Wildcard || d.encodedType(userType) {
+		return nil
+	}
+	decoded, err := d.codec.DecodeUserID(ctx, userID)
+	if err != nil || decoded != userID {
+		return &invalidUserError{err: ErrUserIDLooksEncoded, user: user}
+	}
+	return nil
+}
+
+// invalidUserError is an error of a user that can't be written, reported like the invalid writes
+// of the datastores.
+type invalidUserError struct {
+	err  error
+	user string
+}
+
+func (e *invalidUserError) Error() string {
+	return fmt.Sprintf("invalid user '%s': %s", e.user, e.err)
+}
+
+func (e *invalidUserError) Unwrap() error {
+	return e.err
+}
+
+// Is reports the error as a [storage.ErrInvalidWriteInput].
+func (e *invalidUserError) Is(target error) bool {
+	return target == storage.ErrInvalidWriteInput
+}
+
+// decodedError is an error of the datastore whose message reports the decoded users.
+type decodedError struct {
+	msg string
+	err error
+}
+
+func (e *decodedError) Error() string {
+	return e.msg
+}
+
+func (e *decodedError) Unwrap() error {
+	return e.err
+}
+
+// ReadChanges see [storage.ChangelogBackend].ReadChanges.
+func (d *EncryptedDatastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, string, error) {
+	changes, token, err := d.OpenFGADatastore.ReadChanges(ctx, store, filter, options)
+	if err != nil {
+		return nil, token, err
+	}
+
+	decoded := make([]*openfgav1.TupleChange, 0, len(changes))
+	for _, change := range changes {
+		key, err := d.decodedTupleKey(ctx, change.GetTupleKey())
+		if err != nil {
+			return nil, "", err
+		}
+		decoded = append(decoded, &openfgav1.TupleChange{
+			TupleKey:  key,
+			Operation: change.GetOperation(),
+			Timestamp: change.GetTimestamp(),
+		})
+	}
+	return decoded, token, nil
+}
+
+// ReadObjectIDsWithSetOperation see [storage.SetOperationReader].ReadObjectIDsWithSetOperation.
+func (d *EncryptedDatastore) ReadObjectIDsWithSetOperation(ctx context.Context, store string, filter storage.SetOperationFilter, options storage.ReadSetOperationOptions) ([]string, error) {
+	reader, ok := d.OpenFGADatastore.(storage.SetOperationReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	encodedTerms := func(terms []storage.SetOperationTerm) ([]storage.SetOperationTerm, error) {
+		encoded := make([]storage.SetOperationTerm, 0, len(terms))
+		for _, term := range terms {
+			users := make([]string, 0, len(term.Users))
+			for _, u := range term.Users {
+				user, err := d.encodedUser(ctx, u)
+				if err != nil {
+					return nil, err
+				}
+				users = append(users, user)
+			}
+			encoded = append(encoded, storage.SetOperationTerm{Relation: term.Relation, Users: users})
+		}
+		return encoded, nil
+	}
+
+	var err error
+	if filter.Union, err = encodedTerms(filter.Union); err != nil {
+		return nil, err
+	}
+	if filter.Except, err = encodedTerms(filter.Except); err != nil {
+		return nil, err
+	}
+	return reader.ReadObjectIDsWithSetOperation(ctx, store, filter, options)
+}
+
+// WriteMaterializedPermissions encodes the users of the permissions before they are written.
+func (d *EncryptedDatastore) WriteMaterializedPermissions(ctx context.Context, relation *storage.MaterializedRelation, update storage.MaterializedPermissionsUpdate) error {
+	permissions := make([]storage.MaterializedPermission, 0, len(update.Permissions))
+	for _, permission := range update.Permissions {
+		user, err := d.encodedUser(ctx, permission.User)
+		if err != nil {
+			return err
+		}
+		permissions = append(permissions, storage.MaterializedPermission{ObjectID: permission.ObjectID, User: user})
+	}
+	update.Permissions = permissions
+	return d.optionalBackends.WriteMaterializedPermissions(ctx, relation, update)
+}
+
+// ReadMaterializedPermission encodes users before they are looked up.
+func (d *EncryptedDatastore) ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error) {
+	encodedUsers := make([]string, 0, len(users))
+	for _, user := range users {
+		encoded, err := d.encodedUser(ctx, user)
+		if err != nil {
+			return false, err
+		}
+		encodedUsers = append(encodedUsers, encoded)
+	}
+	return d.optionalBackends.ReadMaterializedPermission(ctx, store, objectType, objectID, relation, encodedUsers)
+}
+
+// decodedTupleIterator is a storage.TupleIterator that decodes the users of the tuples of its
+// datastore.
+type decodedTupleIterator struct {
+	storage.TupleIterator
+	datastore *EncryptedDatastore
+}
+
+func (i *decodedTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	t, err := i.TupleIterator.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.datastore.decodedTuple(ctx, t)
+}
+
+func (i *decodedTupleIterator) Head(ctx context.Context) (*openfgav1.Tuple, error) {
+	t, err := i.TupleIterator.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.datastore.decodedTuple(ctx, t)
+}
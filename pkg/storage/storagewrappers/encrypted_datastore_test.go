@@ -0,0 +1,168 @@
+package storagewrappers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestEncryptedDatastore(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	codec, err := NewAESUserIDCodec([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	encrypted := NewEncryptedDatastore(ds, codec, []string{"user"})
+
+	require.NoError(t, encrypted.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:1", "viewer", "user:*"),
+		tuple.NewTupleKey("document:2", "viewer", "group:eng#member"),
+	}))
+
+	t.Run("users_encoded_at_rest", func(t *testing.T) {
+		tuples, _, err := ds.ReadPage(ctx, store, storage.ReadFilter{}, storage.ReadPageOptions{})
+		require.NoError(t, err)
+
+		users := make([]string, 0, len(tuples))
+		for _, tuple := range tuples {
+			users = append(users, tuple.GetKey().GetUser())
+		}
+		require.Contains(t, users, "user:*")
+		require.Contains(t, users, "group:eng#member")
+		require.NotContains(t, users, "user:anne")
+		require.True(t, strings.HasPrefix(users[0], "user:"+encodedUserIDPrefix) ||
+			strings.HasPrefix(users[1], "user:"+encodedUserIDPrefix) ||
+			strings.HasPrefix(users[2], "user:"+encodedUserIDPrefix))
+	})
+
+	t.Run("users_decoded", func(t *testing.T) {
+		iter, err := encrypted.Read(ctx, store, storage.ReadFilter{Object: "document:1", Relation: "viewer", User: "user:"}, storage.ReadOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+
+		var users []string
+		for {
+			tuple, err := iter.Next(ctx)
+			if err != nil {
+				require.ErrorIs(t, err, storage.ErrIteratorDone)
+				break
+			}
+			users = append(users, tuple.GetKey().GetUser())
+		}
+		require.ElementsMatch(t, []string{"user:anne", "user:*"}, users)
+
+		changes, _, err := encrypted.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "user:anne", changes[0].GetTupleKey().GetUser())
+	})
+
+	t.Run("filters_encoded", func(t *testing.T) {
+		tuple, err := encrypted.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{
+			Object:   "document:1",
+			Relation: "viewer",
+			User:     "user:anne",
+		}, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "user:anne", tuple.GetKey().GetUser())
+
+		iter, err := encrypted.ReadStartingWithUser(ctx, store, storage.ReadStartingWithUserFilter{
+			ObjectType: "document",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:anne"}},
+		}, storage.ReadStartingWithUserOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		tuple, err = iter.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "document:1", tuple.GetKey().GetObject())
+	})
+
+	t.Run("deletes_encoded", func(t *testing.T) {
+		require.NoError(t, encrypted.Write(ctx, store, []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "viewer", "user:anne")),
+		}, nil))
+
+		_, err := encrypted.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{
+			Object:   "document:1",
+			Relation: "viewer",
+			User:     "user:anne",
+		}, storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func TestEncryptedDatastoreTypes(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	codec, err := NewAESUserIDCodec([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	encoded, err := codec.EncodeUserID(ctx, "anne")
+	require.NoError(t, err)
+
+	// a plain text ID of a type that is not encoded, with the prefix of the encoded IDs
+	require.NoError(t, ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "group:"+encodedUserIDPrefix+"eng#member"),
+	}))
+	encrypted := NewEncryptedDatastore(ds, codec, []string{"user"}, WithMaxEncodedUserBytes(64))
+
+	t.Run("users_of_other_types_are_not_decoded", func(t *testing.T) {
+		tuples, _, err := encrypted.ReadPage(ctx, store, storage.ReadFilter{Object: "document:1"}, storage.ReadPageOptions{})
+		require.NoError(t, err)
+		require.Len(t, tuples, 1)
+		require.Equal(t, "group:"+encodedUserIDPrefix+"eng#member", tuples[0].GetKey().GetUser())
+	})
+
+	t.Run("unencoded_ids_that_look_encoded_are_refused", func(t *testing.T) {
+		err := encrypted.Write(ctx, store, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:2", "viewer", "group:"+encoded+"#member"),
+		})
+		require.ErrorIs(t, err, ErrUserIDLooksEncoded)
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+
+		// the IDs of the encoded types are encoded, whatever they look like
+		require.NoError(t, encrypted.Write(ctx, store, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:2", "viewer", "user:"+encodedUserIDPrefix+"a"),
+		}))
+		tk, err := encrypted.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{
+			Object:   "document:2",
+			Relation: "viewer",
+			User:     "user:" + encodedUserIDPrefix + "a",
+		}, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "user:"+encodedUserIDPrefix+"a", tk.GetKey().GetUser())
+	})
+
+	t.Run("encoded_users_too_long_are_refused", func(t *testing.T) {
+		err := encrypted.Write(ctx, store, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:3", "viewer", "user:"+strings.Repeat("a", 32)),
+		})
+		require.ErrorIs(t, err, ErrEncodedUserTooLong)
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+		require.ErrorContains(t, err, "user:"+strings.Repeat("a", 32))
+	})
+}
+
+func TestEncryptedDatastoreConformance(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	codec, err := NewAESUserIDCodec([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	test.RunAllTests(t, NewEncryptedDatastore(ds, codec, nil))
+}
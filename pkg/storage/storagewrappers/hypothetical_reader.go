@@ -0,0 +1,136 @@
+package storagewrappers
+
+import (
+	"context"
+	"sync"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// NewHypotheticalTupleReader returns a [storage.RelationshipTupleReader] that reads the tuples of
+// ds as if writes had been written and deletes deleted, without changing ds. Unlike the contextual
+// tuples, the tuples of ds can be deleted, and the writes replace the tuples of ds with the same
+// key, e.g. to change their condition.
+func NewHypotheticalTupleReader(
+	ds storage.RelationshipTupleReader,
+	writes []*openfgav1.TupleKey,
+	deletes []*openfgav1.TupleKeyWithoutCondition,
+) storage.RelationshipTupleReader {
+	hidden := make(map[string]struct{}, len(writes)+len(deletes))
+	for _, tk := range writes {
+		hidden[tuple.TupleKeyToString(tk)] = struct{}{}
+	}
+	for _, tk := range deletes {
+		hidden[tuple.TupleKeyToString(tk)] = struct{}{}
+	}
+	return NewCombinedTupleReader(&hidingTupleReader{RelationshipTupleReader: ds, hidden: hidden}, writes)
+}
+
+// hidingTupleReader reads the tuples of a datastore except the ones with the hidden keys.
+type hidingTupleReader struct {
+	storage.RelationshipTupleReader
+	hidden map[string]struct{}
+}
+
+var _ storage.RelationshipTupleReader = (*hidingTupleReader)(nil)
+
+func (h *hidingTupleReader) isHidden(t *openfgav1.Tuple) bool {
+	_, ok := h.hidden[tuple.TupleKeyToString(t.GetKey())]
+	return ok
+}
+
+func (h *hidingTupleReader) filter(iter storage.TupleIterator, err error) (storage.TupleIterator, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &hidingTupleIterator{iter: iter, isHidden: h.isHidden}, nil
+}
+
+// Read see [storage.RelationshipTupleReader.Read].
+func (h *hidingTupleReader) Read(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadOptions) (storage.TupleIterator, error) {
+	return h.filter(h.RelationshipTupleReader.Read(ctx, store, filter, options))
+}
+
+// ReadPage see [storage.RelationshipTupleReader.ReadPage]. The pages can be shorter than the
+// page size, if some of their tuples are hidden.
+func (h *hidingTupleReader) ReadPage(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+	tuples, token, err := h.RelationshipTupleReader.ReadPage(ctx, store, filter, options)
+	if err != nil {
+		return nil, "", err
+	}
+	visible := tuples[:0]
+	for _, t := range tuples {
+		if !h.isHidden(t) {
+			visible = append(visible, t)
+		}
+	}
+	return visible, token, nil
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader.ReadUserTuple].
+func (h *hidingTupleReader) ReadUserTuple(ctx context.Context, store string, filter storage.ReadUserTupleFilter, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	t, err := h.RelationshipTupleReader.ReadUserTuple(ctx, store, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	if h.isHidden(t) {
+		return nil, storage.ErrNotFound
+	}
+	return t, nil
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader.ReadUsersetTuples].
+func (h *hidingTupleReader) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	return h.filter(h.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter, options))
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader.ReadStartingWithUser].
+func (h *hidingTupleReader) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	return h.filter(h.RelationshipTupleReader.ReadStartingWithUser(ctx, store, filter, options))
+}
+
+// hidingTupleIterator skips the hidden tuples of iter, preserving its order.
+type hidingTupleIterator struct {
+	iter     storage.TupleIterator
+	isHidden func(*openfgav1.Tuple) bool
+	once     sync.Once
+}
+
+var _ storage.TupleIterator = (*hidingTupleIterator)(nil)
+
+// Next see [storage.Iterator.Next].
+func (i *hidingTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	for {
+		t, err := i.iter.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !i.isHidden(t) {
+			return t, nil
+		}
+	}
+}
+
+// Head see [storage.Iterator.Head].
+func (i *hidingTupleIterator) Head(ctx context.Context) (*openfgav1.Tuple, error) {
+	for {
+		t, err := i.iter.Head(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !i.isHidden(t) {
+			return t, nil
+		}
+		if _, err := i.iter.Next(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Stop see [storage.Iterator.Stop].
+func (i *hidingTupleIterator) Stop() {
+	i.once.Do(i.iter.Stop)
+}
@@ -0,0 +1,95 @@
+package storagewrappers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestHypotheticalTupleReader(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	require.NoError(t, ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:1", "viewer", "user:bob"),
+		tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("document:2", "viewer", "user:bob"),
+	}))
+
+	reader := NewHypotheticalTupleReader(ds,
+		[]*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:carl"),
+			tuple.NewTupleKeyWithCondition("document:2", "viewer", "user:bob", "in_region", nil),
+		},
+		[]*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "viewer", "user:anne")),
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "viewer", "group:eng#member")),
+		},
+	)
+
+	keys := func(iter storage.TupleIterator, err error) []string {
+		require.NoError(t, err)
+		defer iter.Stop()
+		var keys []string
+		for {
+			tk, err := iter.Next(ctx)
+			if err != nil {
+				require.ErrorIs(t, err, storage.ErrIteratorDone)
+				return keys
+			}
+			keys = append(keys, tuple.TupleKeyWithConditionToString(tk.GetKey()))
+		}
+	}
+
+	t.Run("read", func(t *testing.T) {
+		require.ElementsMatch(t, []string{
+			"document:1#viewer@user:bob",
+			"document:1#viewer@user:carl",
+		}, keys(reader.Read(ctx, store, storage.ReadFilter{Object: "document:1"}, storage.ReadOptions{})))
+
+		require.ElementsMatch(t, []string{
+			"document:2#viewer@user:bob (condition in_region)",
+		}, keys(reader.Read(ctx, store, storage.ReadFilter{Object: "document:2"}, storage.ReadOptions{})))
+	})
+
+	t.Run("read_user_tuple", func(t *testing.T) {
+		_, err := reader.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{
+			Object: "document:1", Relation: "viewer", User: "user:anne",
+		}, storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		tk, err := reader.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{
+			Object: "document:1", Relation: "viewer", User: "user:carl",
+		}, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "user:carl", tk.GetKey().GetUser())
+	})
+
+	t.Run("read_userset_tuples", func(t *testing.T) {
+		require.Empty(t, keys(reader.ReadUsersetTuples(ctx, store, storage.ReadUsersetTuplesFilter{
+			Object: "document:1", Relation: "viewer",
+		}, storage.ReadUsersetTuplesOptions{})))
+	})
+
+	t.Run("read_starting_with_user", func(t *testing.T) {
+		require.Equal(t, []string{
+			"document:2#viewer@user:bob (condition in_region)",
+		}, keys(reader.ReadStartingWithUser(ctx, store, storage.ReadStartingWithUserFilter{
+			ObjectType: "document",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:bob"}},
+			ObjectIDs:  storage.NewSortedSet("2"),
+		}, storage.ReadStartingWithUserOptions{})))
+	})
+}
@@ -0,0 +1,151 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var datastoreOperationDurationHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace:                       build.ProjectName,
+	Name:                            "datastore_operation_duration_ms",
+	Help:                            "The duration (in ms) of a datastore operation labeled by backend, operation and success. The duration of the operations that return an iterator lasts until the first item of the iterator.",
+	Buckets:                         []float64{1, 5, 10, 25, 50, 100, 200, 300, 1000},
+	NativeHistogramBucketFactor:     1.1,
+	NativeHistogramMaxBucketNumber:  100,
+	NativeHistogramMinResetDuration: time.Hour,
+}, []string{"backend", "operation", "success"})
+
+// InstrumentedDatastore is a wrapper of a datastore that records the duration of its tuple
+// operations in the openfga_datastore_operation_duration_ms histogram, labeled by backend and by
+// operation, so that the regressions of an access pattern stand out of the aggregate. The
+// duration of the operations that return an iterator includes the query of their first item,
+// since the SQL datastores only query the database when the iterator is first read.
+type InstrumentedDatastore struct {
+	storage.OpenFGADatastore
+	optionalBackends
+	backend string
+}
+
+var _ storage.OpenFGADatastore = (*InstrumentedDatastore)(nil)
+
+// NewInstrumentedDatastore returns an [InstrumentedDatastore] of inner, whose metrics are labeled
+// with the name of its backend (e.g. 'postgres').
+func NewInstrumentedDatastore(inner storage.OpenFGADatastore, backend string) *InstrumentedDatastore {
+	return &InstrumentedDatastore{
+		OpenFGADatastore: inner,
+		optionalBackends: optionalBackends{ds: inner},
+		backend:          backend,
+	}
+}
+
+func (d *InstrumentedDatastore) observe(operation string, start time.Time, err error) {
+	success := storage.SuccessLabel(err) || errors.Is(err, storage.ErrIteratorDone)
+	datastoreOperationDurationHistogram.
+		WithLabelValues(d.backend, operation, strconv.FormatBool(success)).
+		Observe(float64(time.Since(start).Milliseconds()))
+}
+
+// instrumentIterator returns an iterator that observes the duration of operation when its first
+// item is read.
+func (d *InstrumentedDatastore) instrumentIterator(operation string, start time.Time, iter storage.TupleIterator, err error) (storage.TupleIterator, error) {
+	if err != nil {
+		d.observe(operation, start, err)
+		return nil, err
+	}
+	return &instrumentedIterator{TupleIterator: iter, observe: func(err error) { d.observe(operation, start, err) }}, nil
+}
+
+// Read see [storage.RelationshipTupleReader].Read.
+func (d *InstrumentedDatastore) Read(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadOptions) (storage.TupleIterator, error) {
+	start := time.Now()
+	iter, err := d.OpenFGADatastore.Read(ctx, store, filter, options)
+	return d.instrumentIterator("Read", start, iter, err)
+}
+
+// ReadPage see [storage.RelationshipTupleReader].ReadPage.
+func (d *InstrumentedDatastore) ReadPage(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+	start := time.Now()
+	tuples, token, err := d.OpenFGADatastore.ReadPage(ctx, store, filter, options)
+	d.observe("ReadPage", start, err)
+	return tuples, token, err
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (d *InstrumentedDatastore) ReadUserTuple(ctx context.Context, store string, filter storage.ReadUserTupleFilter, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	start := time.Now()
+	tuple, err := d.OpenFGADatastore.ReadUserTuple(ctx, store, filter, options)
+	d.observe("ReadUserTuple", start, err)
+	return tuple, err
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
+func (d *InstrumentedDatastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	start := time.Now()
+	iter, err := d.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options)
+	return d.instrumentIterator("ReadUsersetTuples", start, iter, err)
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
+func (d *InstrumentedDatastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	start := time.Now()
+	iter, err := d.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options)
+	return d.instrumentIterator("ReadStartingWithUser", start, iter, err)
+}
+
+// Write see [storage.RelationshipTupleWriter].Write.
+func (d *InstrumentedDatastore) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes, opts ...storage.TupleWriteOption) error {
+	start := time.Now()
+	err := d.OpenFGADatastore.Write(ctx, store, deletes, writes, opts...)
+	d.observe("Write", start, err)
+	return err
+}
+
+// ReadChanges see [storage.ChangelogBackend].ReadChanges.
+func (d *InstrumentedDatastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, string, error) {
+	start := time.Now()
+	changes, token, err := d.OpenFGADatastore.ReadChanges(ctx, store, filter, options)
+	d.observe("ReadChanges", start, err)
+	return changes, token, err
+}
+
+// ReadObjectIDsWithSetOperation see [storage.SetOperationReader].ReadObjectIDsWithSetOperation.
+func (d *InstrumentedDatastore) ReadObjectIDsWithSetOperation(ctx context.Context, store string, filter storage.SetOperationFilter, options storage.ReadSetOperationOptions) ([]string, error) {
+	reader, ok := d.OpenFGADatastore.(storage.SetOperationReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	objectIDs, err := reader.ReadObjectIDsWithSetOperation(ctx, store, filter, options)
+	d.observe("ReadObjectIDsWithSetOperation", start, err)
+	return objectIDs, err
+}
+
+// instrumentedIterator is a storage.TupleIterator that calls observe when its first item is read.
+type instrumentedIterator struct {
+	storage.TupleIterator
+	once    sync.Once
+	observe func(error)
+}
+
+func (i *instrumentedIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	tuple, err := i.TupleIterator.Next(ctx)
+	i.once.Do(func() { i.observe(err) })
+	return tuple, err
+}
+
+func (i *instrumentedIterator) Head(ctx context.Context) (*openfgav1.Tuple, error) {
+	tuple, err := i.TupleIterator.Head(ctx)
+	i.once.Do(func() { i.observe(err) })
+	return tuple, err
+}
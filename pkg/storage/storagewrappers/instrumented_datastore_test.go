@@ -0,0 +1,73 @@
+package storagewrappers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestInstrumentedDatastore(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	// a backend of its own, so that each operation adds a series to the histogram
+	instrumented := NewInstrumentedDatastore(ds, "test-"+ulid.Make().String())
+	series := func() int {
+		return testutil.CollectAndCount(datastoreOperationDurationHistogram)
+	}
+
+	before := series()
+	require.NoError(t, instrumented.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+	}))
+	require.Equal(t, before+1, series())
+
+	_, err := instrumented.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{
+		Object:   "document:1",
+		Relation: "viewer",
+		User:     "user:anne",
+	}, storage.ReadUserTupleOptions{})
+	require.NoError(t, err)
+	require.Equal(t, before+2, series())
+
+	t.Run("iterator_observed_on_first_read", func(t *testing.T) {
+		before := series()
+		iter, err := instrumented.Read(ctx, store, storage.ReadFilter{Object: "document:1"}, storage.ReadOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		require.Equal(t, before, series())
+
+		_, err = iter.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, before+1, series())
+
+		_, err = iter.Next(ctx)
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+		require.Equal(t, before+1, series())
+	})
+
+	t.Run("iterator_done_is_a_success", func(t *testing.T) {
+		iter, err := instrumented.ReadStartingWithUser(ctx, store, storage.ReadStartingWithUserFilter{
+			ObjectType: "document",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:bob"}},
+		}, storage.ReadStartingWithUserOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+
+		_, err = iter.Head(ctx)
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+		require.True(t, datastoreOperationDurationHistogram.DeleteLabelValues(instrumented.backend, "ReadStartingWithUser", "true"))
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/cespare/xxhash/v2"
 	"github.com/prometheus/client_golang/prometheus"
@@ -96,6 +97,16 @@ func (e *V2IteratorCacheEntry) CacheEntityType() string {
 	return "v2_iterator"
 }
 
+// CacheItemSize implements storage.SizedCacheItem.
+func (e *V2IteratorCacheEntry) CacheItemSize() int64 {
+	size := int64(unsafe.Sizeof(*e))
+	for _, entry := range e.Entries {
+		size += int64(unsafe.Sizeof(entry)) + int64(len(entry.ObjectID)+len(entry.User)+len(entry.ConditionName)) +
+			storage.ConditionContextSize(entry.ConditionContext)
+	}
+	return size
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // CachingIterator - Mutex-based caching iterator for cache miss
 // ─────────────────────────────────────────────────────────────────────────────
@@ -0,0 +1,40 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// jobBackend returns the [storage.JobBackend] of a wrapped datastore, or one that returns
+// [errors.ErrUnsupported] if the datastore doesn't persist jobs.
+func jobBackend(ds storage.OpenFGADatastore) storage.JobBackend {
+	if backend, ok := ds.(storage.JobBackend); ok {
+		return backend
+	}
+	return unsupportedJobBackend{}
+}
+
+type unsupportedJobBackend struct{}
+
+func (unsupportedJobBackend) WriteJob(context.Context, *storage.Job) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedJobBackend) ReadJob(context.Context, string, string) (*storage.Job, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedJobBackend) ListJobs(context.Context, string) ([]*storage.Job, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedJobBackend) CancelJob(context.Context, string, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedJobBackend) DeleteJobs(context.Context, time.Time) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,36 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// materializedPermissionBackend returns the [storage.MaterializedPermissionBackend] of a wrapped
+// datastore, or one that returns [errors.ErrUnsupported] if the datastore doesn't persist
+// materialized permissions.
+func materializedPermissionBackend(ds storage.OpenFGADatastore) storage.MaterializedPermissionBackend {
+	if backend, ok := ds.(storage.MaterializedPermissionBackend); ok {
+		return backend
+	}
+	return unsupportedMaterializedPermissionBackend{}
+}
+
+type unsupportedMaterializedPermissionBackend struct{}
+
+func (unsupportedMaterializedPermissionBackend) ReadMaterializedRelation(context.Context, string, string, string) (*storage.MaterializedRelation, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedMaterializedPermissionBackend) WriteMaterializedPermissions(context.Context, *storage.MaterializedRelation, storage.MaterializedPermissionsUpdate) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedMaterializedPermissionBackend) ReadMaterializedPermission(context.Context, string, string, string, string, []string) (bool, error) {
+	return false, errors.ErrUnsupported
+}
+
+func (unsupportedMaterializedPermissionBackend) DeleteMaterializedRelation(context.Context, string, string, string) error {
+	return errors.ErrUnsupported
+}
@@ -29,22 +29,63 @@ func (c *cachedAuthorizationModel) CacheEntityType() string {
 
 type cachedOpenFGADatastore struct {
 	storage.OpenFGADatastore
+	optionalBackends
 	lookupGroup singleflight.Group
 	cache       storage.InMemoryCache[*cachedAuthorizationModel]
+
+	// latestModelCache holds the IDs of the latest models of the stores for latestModelTTL.
+	latestModelCache storage.InMemoryCache[any]
+	latestModelTTL   time.Duration
+}
+
+// CachedOpenFGADatastoreOpt defines an option that can be used to change the behavior of the
+// wrapper returned by NewCachedOpenFGADatastore.
+type CachedOpenFGADatastoreOpt func(*cachedOpenFGADatastore)
+
+// WithLatestModelCache caches the ID of the latest model of each store for ttl, so that the
+// requests that don't pin a model ID don't look the latest model up in the datastore. The wrapper
+// invalidates the ID of a store when a model is written through it.
+//
+// The IDs are held in cache, e.g. a cache shared by the instances of OpenFGA, so that a model
+// written through one instance invalidates the ID in all of them. If cache is nil, they are held
+// in memory, and the other instances serve the previous latest model until the ttl elapses. The
+// wrapper stops the cache when it is closed.
+func WithLatestModelCache(ttl time.Duration, cache storage.InMemoryCache[any]) CachedOpenFGADatastoreOpt {
+	return func(c *cachedOpenFGADatastore) {
+		c.latestModelTTL = ttl
+		c.latestModelCache = cache
+	}
 }
 
 // NewCachedOpenFGADatastore returns a wrapper over a datastore that caches up to maxSize
 // [*openfgav1.AuthorizationModel] on every call to storage.ReadAuthorizationModel.
 // It caches with unlimited TTL because models are immutable. It uses LRU for eviction.
-func NewCachedOpenFGADatastore(inner storage.OpenFGADatastore, maxSize int) (*cachedOpenFGADatastore, error) {
+func NewCachedOpenFGADatastore(inner storage.OpenFGADatastore, maxSize int, opts ...CachedOpenFGADatastoreOpt) (*cachedOpenFGADatastore, error) {
 	cache, err := storage.NewInMemoryLRUCache[*cachedAuthorizationModel](storage.WithMaxCacheSize[*cachedAuthorizationModel](int64(maxSize)))
 	if err != nil {
 		return nil, err
 	}
-	return &cachedOpenFGADatastore{
+	c := &cachedOpenFGADatastore{
 		OpenFGADatastore: inner,
+		optionalBackends: optionalBackends{ds: inner},
 		cache:            *cache,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.latestModelTTL > 0 && c.latestModelCache == nil {
+		c.latestModelCache, err = storage.NewInMemoryLRUCache[any](storage.WithMaxCacheSize[any](int64(maxSize)))
+		if err != nil {
+			c.cache.Stop()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func latestModelCacheKey(storeID string) string {
+	return "latest_authz_model_id:" + storeID
 }
 
 // ReadAuthorizationModel reads the model corresponding to store and model ID.
@@ -68,17 +109,42 @@ func (c *cachedOpenFGADatastore) ReadAuthorizationModel(ctx context.Context, sto
 
 // FindLatestAuthorizationModel see [storage.AuthorizationModelReadBackend].FindLatestAuthorizationModel.
 func (c *cachedOpenFGADatastore) FindLatestAuthorizationModel(ctx context.Context, storeID string) (*openfgav1.AuthorizationModel, error) {
+	if c.latestModelTTL > 0 {
+		if modelID, ok := c.latestModelCache.Get(latestModelCacheKey(storeID)).(string); ok && modelID != "" {
+			if model, err := c.ReadAuthorizationModel(ctx, storeID, modelID); err == nil {
+				return model, nil
+			}
+		}
+	}
+
 	v, err, _ := c.lookupGroup.Do("FindLatestAuthorizationModel:"+storeID, func() (interface{}, error) {
 		return c.OpenFGADatastore.FindLatestAuthorizationModel(ctx, storeID)
 	})
 	if err != nil {
 		return nil, err
 	}
-	return v.(*openfgav1.AuthorizationModel), nil
+	model := v.(*openfgav1.AuthorizationModel)
+	if c.latestModelTTL > 0 {
+		c.cache.Set(fmt.Sprintf("%s:%s", storeID, model.GetId()), &cachedAuthorizationModel{model}, ttl)
+		c.latestModelCache.Set(latestModelCacheKey(storeID), model.GetId(), c.latestModelTTL)
+	}
+	return model, nil
+}
+
+// WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModel.
+func (c *cachedOpenFGADatastore) WriteAuthorizationModel(ctx context.Context, storeID string, model *openfgav1.AuthorizationModel) error {
+	err := c.OpenFGADatastore.WriteAuthorizationModel(ctx, storeID, model)
+	if err == nil && c.latestModelTTL > 0 {
+		c.latestModelCache.Delete(latestModelCacheKey(storeID))
+	}
+	return err
 }
 
 // Close closes the datastore and cleans up any residual resources.
 func (c *cachedOpenFGADatastore) Close() {
 	c.cache.Stop()
+	if c.latestModelCache != nil {
+		c.latestModelCache.Stop()
+	}
 	c.OpenFGADatastore.Close()
 }
@@ -126,3 +126,41 @@ func TestSingleFlightFindLatestAuthorizationModel(t *testing.T) {
 	err = wg.Wait()
 	require.NoError(t, err)
 }
+
+func TestLatestModelCache(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockDatastore := mocks.NewMockOpenFGADatastore(mockController)
+	cachingBackend, err := NewCachedOpenFGADatastore(mockDatastore, 5, WithLatestModelCache(time.Hour, nil))
+	require.NoError(t, err)
+
+	storeID := ulid.Make().String()
+	model := &openfgav1.AuthorizationModel{Id: ulid.Make().String(), SchemaVersion: typesystem.SchemaVersion1_1}
+	newModel := &openfgav1.AuthorizationModel{Id: ulid.Make().String(), SchemaVersion: typesystem.SchemaVersion1_1}
+	gomock.InOrder(
+		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), storeID).Times(1).Return(model, nil),
+		mockDatastore.EXPECT().WriteAuthorizationModel(gomock.Any(), storeID, newModel).Times(1).Return(nil),
+		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), storeID).Times(1).Return(newModel, nil),
+		mockDatastore.EXPECT().Close().Times(1),
+	)
+
+	// the first lookup misses, and the next ones hit the cache
+	for range 3 {
+		latestModel, err := cachingBackend.FindLatestAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+		require.Equal(t, model, latestModel)
+	}
+
+	// writing a model invalidates the latest model of the store
+	require.NoError(t, cachingBackend.WriteAuthorizationModel(ctx, storeID, newModel))
+	latestModel, err := cachingBackend.FindLatestAuthorizationModel(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, newModel, latestModel)
+
+	cachingBackend.Close()
+}
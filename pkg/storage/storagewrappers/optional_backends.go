@@ -0,0 +1,147 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// optionalBackends forwards the optional interfaces of the storage package, such as
+// [storage.JobBackend], to a wrapped datastore, and returns [errors.ErrUnsupported] from those
+// that the datastore doesn't implement. The wrappers embed it next to the datastore they wrap so
+// that a new optional interface is forwarded by all of them once it is added here; a wrapper only
+// declares the methods whose calls it changes.
+type optionalBackends struct {
+	ds storage.OpenFGADatastore
+	// withContext, if set, returns the context passed to ds from that of a call.
+	withContext func(context.Context) context.Context
+}
+
+var (
+	_ storage.SetOperationReader            = optionalBackends{}
+	_ storage.ChangelogPruner               = optionalBackends{}
+	_ storage.ChangelogPartitioner          = optionalBackends{}
+	_ storage.JobBackend                    = optionalBackends{}
+	_ storage.ScheduleBackend               = optionalBackends{}
+	_ storage.MaterializedPermissionBackend = optionalBackends{}
+)
+
+func (b optionalBackends) callContext(ctx context.Context) context.Context {
+	if b.withContext == nil {
+		return ctx
+	}
+	return b.withContext(ctx)
+}
+
+// ReadObjectIDsWithSetOperation see [storage.SetOperationReader].ReadObjectIDsWithSetOperation.
+func (b optionalBackends) ReadObjectIDsWithSetOperation(ctx context.Context, store string, filter storage.SetOperationFilter, options storage.ReadSetOperationOptions) ([]string, error) {
+	reader, ok := b.ds.(storage.SetOperationReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return reader.ReadObjectIDsWithSetOperation(b.callContext(ctx), store, filter, options)
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (b optionalBackends) PruneChanges(ctx context.Context, store string, options storage.PruneChangesOptions) (int64, error) {
+	pruner, ok := b.ds.(storage.ChangelogPruner)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+	return pruner.PruneChanges(b.callContext(ctx), store, options)
+}
+
+// CreateChangelogPartitions see [storage.ChangelogPartitioner].CreateChangelogPartitions.
+func (b optionalBackends) CreateChangelogPartitions(ctx context.Context, until time.Time) error {
+	partitioner, ok := b.ds.(storage.ChangelogPartitioner)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return partitioner.CreateChangelogPartitions(b.callContext(ctx), until)
+}
+
+// DropChangelogPartitions see [storage.ChangelogPartitioner].DropChangelogPartitions.
+func (b optionalBackends) DropChangelogPartitions(ctx context.Context, before time.Time) (int, error) {
+	partitioner, ok := b.ds.(storage.ChangelogPartitioner)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+	return partitioner.DropChangelogPartitions(b.callContext(ctx), before)
+}
+
+// WriteJob see [storage.JobBackend].WriteJob.
+func (b optionalBackends) WriteJob(ctx context.Context, job *storage.Job) error {
+	return jobBackend(b.ds).WriteJob(b.callContext(ctx), job)
+}
+
+// ReadJob see [storage.JobBackend].ReadJob.
+func (b optionalBackends) ReadJob(ctx context.Context, store, id string) (*storage.Job, error) {
+	return jobBackend(b.ds).ReadJob(b.callContext(ctx), store, id)
+}
+
+// ListJobs see [storage.JobBackend].ListJobs.
+func (b optionalBackends) ListJobs(ctx context.Context, store string) ([]*storage.Job, error) {
+	return jobBackend(b.ds).ListJobs(b.callContext(ctx), store)
+}
+
+// CancelJob see [storage.JobBackend].CancelJob.
+func (b optionalBackends) CancelJob(ctx context.Context, store, id string) error {
+	return jobBackend(b.ds).CancelJob(b.callContext(ctx), store, id)
+}
+
+// DeleteJobs see [storage.JobBackend].DeleteJobs.
+func (b optionalBackends) DeleteJobs(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	return jobBackend(b.ds).DeleteJobs(b.callContext(ctx), updatedBefore)
+}
+
+// WriteSchedule see [storage.ScheduleBackend].WriteSchedule.
+func (b optionalBackends) WriteSchedule(ctx context.Context, schedule *storage.Schedule) error {
+	return scheduleBackend(b.ds).WriteSchedule(b.callContext(ctx), schedule)
+}
+
+// ReadSchedule see [storage.ScheduleBackend].ReadSchedule.
+func (b optionalBackends) ReadSchedule(ctx context.Context, store, id string) (*storage.Schedule, error) {
+	return scheduleBackend(b.ds).ReadSchedule(b.callContext(ctx), store, id)
+}
+
+// ListSchedules see [storage.ScheduleBackend].ListSchedules.
+func (b optionalBackends) ListSchedules(ctx context.Context, store string) ([]*storage.Schedule, error) {
+	return scheduleBackend(b.ds).ListSchedules(b.callContext(ctx), store)
+}
+
+// DeleteSchedule see [storage.ScheduleBackend].DeleteSchedule.
+func (b optionalBackends) DeleteSchedule(ctx context.Context, store, id string) error {
+	return scheduleBackend(b.ds).DeleteSchedule(b.callContext(ctx), store, id)
+}
+
+// RecordScheduleRun see [storage.ScheduleBackend].RecordScheduleRun.
+func (b optionalBackends) RecordScheduleRun(ctx context.Context, store, id string, runAt time.Time, jobID string) error {
+	return scheduleBackend(b.ds).RecordScheduleRun(b.callContext(ctx), store, id, runAt, jobID)
+}
+
+// AcquireLease see [storage.ScheduleBackend].AcquireLease.
+func (b optionalBackends) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	return scheduleBackend(b.ds).AcquireLease(b.callContext(ctx), name, holder, ttl)
+}
+
+// ReadMaterializedRelation see [storage.MaterializedPermissionBackend].ReadMaterializedRelation.
+func (b optionalBackends) ReadMaterializedRelation(ctx context.Context, store, objectType, relation string) (*storage.MaterializedRelation, error) {
+	return materializedPermissionBackend(b.ds).ReadMaterializedRelation(b.callContext(ctx), store, objectType, relation)
+}
+
+// WriteMaterializedPermissions see [storage.MaterializedPermissionBackend].WriteMaterializedPermissions.
+func (b optionalBackends) WriteMaterializedPermissions(ctx context.Context, relation *storage.MaterializedRelation, update storage.MaterializedPermissionsUpdate) error {
+	return materializedPermissionBackend(b.ds).WriteMaterializedPermissions(b.callContext(ctx), relation, update)
+}
+
+// ReadMaterializedPermission see [storage.MaterializedPermissionBackend].ReadMaterializedPermission.
+func (b optionalBackends) ReadMaterializedPermission(ctx context.Context, store, objectType, objectID, relation string, users []string) (bool, error) {
+	return materializedPermissionBackend(b.ds).ReadMaterializedPermission(b.callContext(ctx), store, objectType, objectID, relation, users)
+}
+
+// DeleteMaterializedRelation see [storage.MaterializedPermissionBackend].DeleteMaterializedRelation.
+func (b optionalBackends) DeleteMaterializedRelation(ctx context.Context, store, objectType, relation string) error {
+	return materializedPermissionBackend(b.ds).DeleteMaterializedRelation(b.callContext(ctx), store, objectType, relation)
+}
@@ -0,0 +1,44 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// scheduleBackend returns the [storage.ScheduleBackend] of a wrapped datastore, or one that
+// returns [errors.ErrUnsupported] if the datastore doesn't persist schedules.
+func scheduleBackend(ds storage.OpenFGADatastore) storage.ScheduleBackend {
+	if backend, ok := ds.(storage.ScheduleBackend); ok {
+		return backend
+	}
+	return unsupportedScheduleBackend{}
+}
+
+type unsupportedScheduleBackend struct{}
+
+func (unsupportedScheduleBackend) WriteSchedule(context.Context, *storage.Schedule) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedScheduleBackend) ReadSchedule(context.Context, string, string) (*storage.Schedule, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedScheduleBackend) ListSchedules(context.Context, string) ([]*storage.Schedule, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupportedScheduleBackend) DeleteSchedule(context.Context, string, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedScheduleBackend) RecordScheduleRun(context.Context, string, string, time.Time, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupportedScheduleBackend) AcquireLease(context.Context, string, string, time.Duration) (bool, error) {
+	return false, errors.ErrUnsupported
+}
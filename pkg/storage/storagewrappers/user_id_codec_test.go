@@ -0,0 +1,56 @@
+package storagewrappers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESUserIDCodec(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewAESUserIDCodec([]byte("short"))
+	require.Error(t, err)
+
+	codec, err := NewAESUserIDCodec([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	encoded, err := codec.EncodeUserID(ctx, "anne@example.com")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encoded, encodedUserIDPrefix))
+	require.NotContains(t, encoded, "anne")
+	require.NotContainsf(t, encoded, ":", "encoded IDs must be valid in users")
+	require.NotContains(t, encoded, "#")
+
+	// deterministic, so that the encoded users can be compared
+	again, err := codec.EncodeUserID(ctx, "anne@example.com")
+	require.NoError(t, err)
+	require.Equal(t, encoded, again)
+
+	other, err := codec.EncodeUserID(ctx, "bob@example.com")
+	require.NoError(t, err)
+	require.NotEqual(t, encoded, other)
+
+	decoded, err := codec.DecodeUserID(ctx, encoded)
+	require.NoError(t, err)
+	require.Equal(t, "anne@example.com", decoded)
+
+	t.Run("ids_not_encoded_are_decoded_as_is", func(t *testing.T) {
+		decoded, err := codec.DecodeUserID(ctx, "anne")
+		require.NoError(t, err)
+		require.Equal(t, "anne", decoded)
+	})
+
+	t.Run("ids_encoded_with_another_key_are_invalid", func(t *testing.T) {
+		otherCodec, err := NewAESUserIDCodec([]byte("fedcba9876543210"))
+		require.NoError(t, err)
+
+		_, err = otherCodec.DecodeUserID(ctx, encoded)
+		require.ErrorIs(t, err, ErrInvalidEncodedUserID)
+
+		_, err = codec.DecodeUserID(ctx, encodedUserIDPrefix+"not-base64!")
+		require.ErrorIs(t, err, ErrInvalidEncodedUserID)
+	})
+}
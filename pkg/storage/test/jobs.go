@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func JobsTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	backend, ok := datastore.(storage.JobBackend)
+	if !ok {
+		t.Skip("datastore does not implement storage.JobBackend")
+	}
+
+	ctx := context.Background()
+
+	t.Run("write_and_read", func(t *testing.T) {
+		store := ulid.Make().String()
+		job := &storage.Job{Store: store, ID: ulid.Make().String(), Kind: "purge", Payload: []byte(`{"state":"pending"}`)}
+		require.NoError(t, backend.WriteJob(ctx, job))
+
+		read, err := backend.ReadJob(ctx, store, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job.ID, read.ID)
+		require.Equal(t, "purge", read.Kind)
+		require.False(t, read.Done)
+		require.False(t, read.CancelRequested)
+		require.JSONEq(t, `{"state":"pending"}`, string(read.Payload))
+		require.False(t, read.UpdatedAt.IsZero())
+
+		job.Done = true
+		job.Payload = []byte(`{"state":"succeeded"}`)
+		require.NoError(t, backend.WriteJob(ctx, job))
+
+		read, err = backend.ReadJob(ctx, store, job.ID)
+		require.NoError(t, err)
+		require.True(t, read.Done)
+		require.JSONEq(t, `{"state":"succeeded"}`, string(read.Payload))
+
+		_, err = backend.ReadJob(ctx, ulid.Make().String(), job.ID)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		store := ulid.Make().String()
+		first := ulid.Make().String()
+		second := ulid.Make().String()
+		require.NoError(t, backend.WriteJob(ctx, &storage.Job{Store: store, ID: second, Kind: "purge", Payload: []byte(`{}`)}))
+		require.NoError(t, backend.WriteJob(ctx, &storage.Job{Store: store, ID: first, Kind: "purge", Payload: []byte(`{}`)}))
+		require.NoError(t, backend.WriteJob(ctx, &storage.Job{Store: ulid.Make().String(), ID: ulid.Make().String(), Kind: "purge", Payload: []byte(`{}`)}))
+
+		jobs, err := backend.ListJobs(ctx, store)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		require.Equal(t, first, jobs[0].ID)
+		require.Equal(t, second, jobs[1].ID)
+
+		jobs, err = backend.ListJobs(ctx, ulid.Make().String())
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		store := ulid.Make().String()
+		job := &storage.Job{Store: store, ID: ulid.Make().String(), Kind: "purge", Payload: []byte(`{}`)}
+		require.NoError(t, backend.WriteJob(ctx, job))
+		require.NoError(t, backend.CancelJob(ctx, store, job.ID))
+
+		// the cancellation is not overwritten by the progress of the job
+		require.NoError(t, backend.WriteJob(ctx, job))
+
+		read, err := backend.ReadJob(ctx, store, job.ID)
+		require.NoError(t, err)
+		require.True(t, read.CancelRequested)
+
+		err = backend.CancelJob(ctx, store, ulid.Make().String())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := ulid.Make().String()
+		done := &storage.Job{Store: store, ID: ulid.Make().String(), Kind: "purge", Done: true, Payload: []byte(`{}`)}
+		running := &storage.Job{Store: store, ID: ulid.Make().String(), Kind: "purge", Payload: []byte(`{}`)}
+		require.NoError(t, backend.WriteJob(ctx, done))
+		require.NoError(t, backend.WriteJob(ctx, running))
+
+		deleted, err := backend.DeleteJobs(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		// other tests may have written done jobs
+		deleted, err = backend.DeleteJobs(ctx, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, deleted, int64(1))
+
+		jobs, err := backend.ListJobs(ctx, store)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		require.Equal(t, running.ID, jobs[0].ID)
+	})
+}
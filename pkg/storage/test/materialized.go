@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func MaterializedPermissionsTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	backend, ok := datastore.(storage.MaterializedPermissionBackend)
+	if !ok {
+		t.Skip("datastore does not implement storage.MaterializedPermissionBackend")
+	}
+
+	ctx := context.Background()
+
+	t.Run("write_read_and_delete", func(t *testing.T) {
+		store := ulid.Make().String()
+		relation := &storage.MaterializedRelation{
+			Store:             store,
+			ObjectType:        "document",
+			Relation:          "viewer",
+			ModelID:           ulid.Make().String(),
+			ContinuationToken: "token1",
+			UpdatedAt:         time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+
+		_, err := backend.ReadMaterializedRelation(ctx, store, "document", "viewer")
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		err = backend.WriteMaterializedPermissions(ctx, relation, storage.MaterializedPermissionsUpdate{
+			Reset: true,
+			Permissions: []storage.MaterializedPermission{
+				{ObjectID: "1", User: "user:anne"},
+				{ObjectID: "1", User: "user:bob"},
+				{ObjectID: "2", User: "user:*"},
+			},
+		})
+		require.NoError(t, err)
+
+		read, err := backend.ReadMaterializedRelation(ctx, store, "document", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, relation.ModelID, read.ModelID)
+		require.Equal(t, "token1", read.ContinuationToken)
+		require.True(t, relation.UpdatedAt.Equal(read.UpdatedAt), read.UpdatedAt)
+
+		found, err := backend.ReadMaterializedPermission(ctx, store, "document", "1", "viewer", []string{"user:anne"})
+		require.NoError(t, err)
+		require.True(t, found)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "2", "viewer", []string{"user:anne", "user:*"})
+		require.NoError(t, err)
+		require.True(t, found)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "2", "viewer", []string{"user:anne"})
+		require.NoError(t, err)
+		require.False(t, found)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "1", "editor", []string{"user:anne"})
+		require.NoError(t, err)
+		require.False(t, found)
+
+		// the permissions of object 1 are replaced, and those of object 2 are kept
+		relation.ContinuationToken = "token2"
+		err = backend.WriteMaterializedPermissions(ctx, relation, storage.MaterializedPermissionsUpdate{
+			ObjectIDs:   []string{"1", "3"},
+			Permissions: []storage.MaterializedPermission{{ObjectID: "1", User: "user:carl"}},
+		})
+		require.NoError(t, err)
+
+		read, err = backend.ReadMaterializedRelation(ctx, store, "document", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, "token2", read.ContinuationToken)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "1", "viewer", []string{"user:anne", "user:bob"})
+		require.NoError(t, err)
+		require.False(t, found)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "1", "viewer", []string{"user:carl"})
+		require.NoError(t, err)
+		require.True(t, found)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "2", "viewer", []string{"user:*"})
+		require.NoError(t, err)
+		require.True(t, found)
+
+		require.NoError(t, backend.DeleteMaterializedRelation(ctx, store, "document", "viewer"))
+
+		_, err = backend.ReadMaterializedRelation(ctx, store, "document", "viewer")
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		found, err = backend.ReadMaterializedPermission(ctx, store, "document", "1", "viewer", []string{"user:carl"})
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("reset_deletes_the_permissions_of_every_object", func(t *testing.T) {
+		store := ulid.Make().String()
+		relation := &storage.MaterializedRelation{Store: store, ObjectType: "folder", Relation: "owner", ModelID: ulid.Make().String()}
+
+		err := backend.WriteMaterializedPermissions(ctx, relation, storage.MaterializedPermissionsUpdate{
+			Reset: true,
+			Permissions: []storage.MaterializedPermission{
+				{ObjectID: "a", User: "user:anne"},
+				{ObjectID: "b", User: "user:anne"},
+			},
+		})
+		require.NoError(t, err)
+
+		relation.ModelID = ulid.Make().String()
+		err = backend.WriteMaterializedPermissions(ctx, relation, storage.MaterializedPermissionsUpdate{
+			Reset:       true,
+			Permissions: []storage.MaterializedPermission{{ObjectID: "b", User: "user:bob"}},
+		})
+		require.NoError(t, err)
+
+		read, err := backend.ReadMaterializedRelation(ctx, store, "folder", "owner")
+		require.NoError(t, err)
+		require.Equal(t, relation.ModelID, read.ModelID)
+
+		for objectID, expected := range map[string]bool{"a": false, "b": false} {
+			found, err := backend.ReadMaterializedPermission(ctx, store, "folder", objectID, "owner", []string{"user:anne"})
+			require.NoError(t, err)
+			require.Equal(t, expected, found, objectID)
+		}
+
+		found, err := backend.ReadMaterializedPermission(ctx, store, "folder", "b", "owner", []string{"user:bob"})
+		require.NoError(t, err)
+		require.True(t, found)
+
+		// the relations of other stores are unaffected
+		found, err = backend.ReadMaterializedPermission(ctx, ulid.Make().String(), "folder", "b", "owner", []string{"user:bob"})
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
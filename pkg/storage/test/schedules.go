@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func SchedulesTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	backend, ok := datastore.(storage.ScheduleBackend)
+	if !ok {
+		t.Skip("datastore does not implement storage.ScheduleBackend")
+	}
+
+	ctx := context.Background()
+
+	t.Run("write_read_and_record_run", func(t *testing.T) {
+		store := ulid.Make().String()
+		schedule := &storage.Schedule{Store: store, ID: ulid.Make().String(), Payload: []byte(`{"cron":"@daily"}`)}
+		require.NoError(t, backend.WriteSchedule(ctx, schedule))
+
+		read, err := backend.ReadSchedule(ctx, store, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, schedule.ID, read.ID)
+		require.JSONEq(t, `{"cron":"@daily"}`, string(read.Payload))
+		require.True(t, read.LastRunAt.IsZero())
+		require.Empty(t, read.LastJobID)
+
+		runAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		jobID := ulid.Make().String()
+		require.NoError(t, backend.RecordScheduleRun(ctx, store, schedule.ID, runAt, jobID))
+
+		// the run is not overwritten by a change of the schedule
+		schedule.Payload = []byte(`{"cron":"@weekly"}`)
+		require.NoError(t, backend.WriteSchedule(ctx, schedule))
+
+		read, err = backend.ReadSchedule(ctx, store, schedule.ID)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"cron":"@weekly"}`, string(read.Payload))
+		require.True(t, runAt.Equal(read.LastRunAt), read.LastRunAt)
+		require.Equal(t, jobID, read.LastJobID)
+
+		_, err = backend.ReadSchedule(ctx, ulid.Make().String(), schedule.ID)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		err = backend.RecordScheduleRun(ctx, store, ulid.Make().String(), runAt, jobID)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("list_and_delete", func(t *testing.T) {
+		store := ulid.Make().String()
+		first := ulid.Make().String()
+		second := ulid.Make().String()
+		other := ulid.Make().String()
+		require.NoError(t, backend.WriteSchedule(ctx, &storage.Schedule{Store: store, ID: second, Payload: []byte(`{}`)}))
+		require.NoError(t, backend.WriteSchedule(ctx, &storage.Schedule{Store: store, ID: first, Payload: []byte(`{}`)}))
+		require.NoError(t, backend.WriteSchedule(ctx, &storage.Schedule{Store: other, ID: ulid.Make().String(), Payload: []byte(`{}`)}))
+
+		schedules, err := backend.ListSchedules(ctx, store)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		require.Equal(t, first, schedules[0].ID)
+		require.Equal(t, second, schedules[1].ID)
+
+		// other tests may have written schedules
+		schedules, err = backend.ListSchedules(ctx, "")
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(schedules), 3)
+
+		require.NoError(t, backend.DeleteSchedule(ctx, store, first))
+		err = backend.DeleteSchedule(ctx, store, first)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		schedules, err = backend.ListSchedules(ctx, store)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		require.Equal(t, second, schedules[0].ID)
+	})
+
+	t.Run("lease", func(t *testing.T) {
+		name := "lease-" + ulid.Make().String()
+
+		acquired, err := backend.AcquireLease(ctx, name, "first", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = backend.AcquireLease(ctx, name, "second", time.Hour)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		// extended by its holder
+		acquired, err = backend.AcquireLease(ctx, name, "first", time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		// acquired by another holder once expired
+		require.Eventually(t, func() bool {
+			acquired, err := backend.AcquireLease(ctx, name, "second", time.Hour)
+			require.NoError(t, err)
+			return acquired
+		}, 5*time.Second, 10*time.Millisecond)
+
+		acquired, err = backend.AcquireLease(ctx, name, "first", time.Hour)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+}
@@ -36,8 +36,10 @@ func RunAllTests(t *testing.T, ds storage.OpenFGADatastore) {
 	// Tuples.
 	t.Run("TestTupleWriteAndRead", func(t *testing.T) { TupleWritingAndReadingTest(t, ds) })
 	t.Run("TestReadChanges", func(t *testing.T) { ReadChangesTest(t, ds) })
+	t.Run("TestPruneChanges", func(t *testing.T) { PruneChangesTest(t, ds) })
 	t.Run("TestReadStartingWithUser", func(t *testing.T) { ReadStartingWithUserTest(t, ds) })
 	t.Run("TestReadAndReadPages", func(t *testing.T) { ReadAndReadPageTest(t, ds) })
+	t.Run("TestReadPageOrder", func(t *testing.T) { ReadPageOrderTest(t, ds) })
 
 	// Authorization models.
 	t.Run("TestWriteAndReadAuthorizationModel", func(t *testing.T) { WriteAndReadAuthorizationModelTest(t, ds) })
@@ -49,6 +51,11 @@ func RunAllTests(t *testing.T, ds storage.OpenFGADatastore) {
 
 	// Stores.
 	t.Run("TestStore", func(t *testing.T) { StoreTest(t, ds) })
+
+	// Jobs.
+	t.Run("TestJobs", func(t *testing.T) { JobsTest(t, ds) })
+	t.Run("TestSchedules", func(t *testing.T) { SchedulesTest(t, ds) })
+	t.Run("TestMaterializedPermissions", func(t *testing.T) { MaterializedPermissionsTest(t, ds) })
 }
 
 // BootstrapFGAStore is a utility to write an FGA model and relationship tuples to a datastore.
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -568,6 +569,30 @@ func ReadChangesTest(t *testing.T, datastore storage.OpenFGADatastore) {
 		require.Equal(t, tk1.GetUser(), docs[0].GetTupleKey().GetUser())
 	})
 
+	t.Run("write_reports_the_ulid_of_its_last_change", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		tk1 := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+		tk2 := tuple.NewTupleKey("document:1", "viewer", "user:bob")
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+
+		var lastChangeID string
+		err = datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk1)}, []*openfgav1.TupleKey{tk2},
+			storage.WithOnLastChangeID(func(id string) { lastChangeID = id }))
+		require.NoError(t, err)
+
+		opts := storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(1, ""),
+			SortDesc:   true,
+		}
+		changes, token, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, opts)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, tk2.GetUser(), changes[0].GetTupleKey().GetUser())
+		require.Equal(t, token, lastChangeID)
+	})
+
 	t.Run("sort_desc_returns_most_recent_changes_with_object_type_filter", func(t *testing.T) {
 		storeID := ulid.Make().String()
 
@@ -1036,6 +1061,61 @@ func TupleWritingAndReadingTest(t *testing.T, datastore storage.OpenFGADatastore
 		}
 	})
 
+	t.Run("last_writer_wins_when_overwrite_insert_duplicate_context_delta", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tk1 := &openfgav1.TupleKey{Object: "doc:readme", Relation: "owner", User: "10", Condition: &openfgav1.RelationshipCondition{
+			Name:    "condition1",
+			Context: testutils.MustNewStruct(t, map[string]interface{}{"param1": "ok"}),
+		}}
+		tk2 := &openfgav1.TupleKey{Object: "doc:readme", Relation: "owner", User: "10", Condition: &openfgav1.RelationshipCondition{
+			Name:    "condition1",
+			Context: testutils.MustNewStruct(t, map[string]interface{}{"param1": "bad"}),
+		}}
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertOverwrite), storage.WithRegion(1))
+		require.NoError(t, err)
+
+		// The second write replaces the first one.
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertOverwrite), storage.WithRegion(2))
+		require.NoError(t, err)
+
+		// The third write is identical, so it is ignored.
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertOverwrite), storage.WithRegion(1))
+		require.NoError(t, err)
+
+		tp, err := datastore.ReadUserTuple(ctx, storeID, storage.ReadUserTupleFilter{Object: tk2.GetObject(), Relation: tk2.GetRelation(), User: tk2.GetUser()}, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		if diff := cmp.Diff(tk2, tp.GetKey(), protocmp.Transform()); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		expectedChanges := []*openfgav1.TupleChange{
+			{
+				TupleKey:  tk1,
+				Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
+			},
+			{
+				TupleKey:  tuple.NewTupleKey(tk1.GetObject(), tk1.GetRelation(), tk1.GetUser()),
+				Operation: openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+			},
+			{
+				TupleKey:  tk2,
+				Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
+			},
+		}
+		readChangesOpts := storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+		}
+		changes, _, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, readChangesOpts)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(expectedChanges, changes, cmpIgnoreTimestamp...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
 	t.Run("inserting_a_tuple_twice_ignore_duplicate_batch", func(t *testing.T) {
 		storeID := ulid.Make().String()
 		tk1 := &openfgav1.TupleKey{Object: "doc:readme", Relation: "owner", User: "10"}
@@ -2199,6 +2279,67 @@ func ReadStartingWithUserTest(t *testing.T, datastore storage.OpenFGADatastore)
 		}
 		require.Equal(t, []string{"doc3", "doc4", "doc5", "doc6"}, actualObjectIDs)
 	})
+
+	t.Run("returns_the_tuples_of_the_latest_writes", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, tuples)
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:doc1", "viewer", "user:jon")),
+			},
+			[]*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:doc5", "viewer", "user:jon"),
+			},
+		)
+		require.NoError(t, err)
+
+		// the condition of doc4 is overwritten
+		err = datastore.Write(ctx, storeID, nil,
+			[]*openfgav1.TupleKey{
+				tuple.NewTupleKeyWithCondition("document:doc4", "viewer", "user:jon", "other", nil),
+			},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertOverwrite),
+		)
+		require.NoError(t, err)
+
+		// doc1 is written again after its deletion
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:doc1", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:doc5", "viewer", "user:jon")),
+			}, nil,
+		)
+		require.NoError(t, err)
+
+		tupleIterator, err := datastore.ReadStartingWithUser(
+			ctx,
+			storeID,
+			storage.ReadStartingWithUserFilter{
+				ObjectType: "document",
+				Relation:   "viewer",
+				UserFilter: []*openfgav1.ObjectRelation{
+					{
+						Object: "user:jon",
+					},
+				},
+			}, storage.ReadStartingWithUserOptions{},
+		)
+		require.NoError(t, err)
+
+		tupleKeys := iterateThroughAllTuples(t, tupleIterator)
+		require.Len(t, tupleKeys, 2)
+		require.Equal(t, "document:doc1", tupleKeys[0].GetObject())
+		require.Nil(t, tupleKeys[0].GetCondition())
+		require.Equal(t, "document:doc4", tupleKeys[1].GetObject())
+		require.Equal(t, "other", tupleKeys[1].GetCondition().GetName())
+	})
 }
 
 func ReadAndReadPageTest(t *testing.T, datastore storage.OpenFGADatastore) {
@@ -2329,6 +2470,36 @@ func ReadAndReadPageTest(t *testing.T, datastore storage.OpenFGADatastore) {
 				tuple.NewTupleKey("document:1", "writer", "user:github.com|bob@test.com"),
 			},
 		},
+		`filter_by_user`: {
+			filter: storage.ReadFilter{Relation: "", Object: "", User: "user:github.com|bob@test.com"},
+			expectedTuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "reader", "user:github.com|bob@test.com"),
+				tuple.NewTupleKey("folder:x", "viewer", "user:github.com|bob@test.com"),
+				tuple.NewTupleKey("document:1", "writer", "user:github.com|bob@test.com"),
+			},
+		},
+		`filter_by_condition`: {
+			filter: storage.ReadFilter{Relation: "", Object: "", User: "", Conditions: []string{"condition1"}},
+			expectedTuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:github.com|anne@test.com", "condition1", nil),
+			},
+		},
+		`filter_by_user_type_and_relation`: {
+			filter: storage.ReadFilter{Relation: "viewer", Object: "", User: "user:"},
+			expectedTuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("folder:x", "viewer", "user:github.com|anne@test.com"),
+				tuple.NewTupleKey("folder:x", "viewer", "user:github.com|bob@test.com"),
+				tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:github.com|anne@test.com", "condition1", nil),
+			},
+		},
+		`filter_by_relation`: {
+			filter: storage.ReadFilter{Relation: "writer", Object: "", User: ""},
+			expectedTuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "writer", "user:github.com|bob@test.com"),
+				tuple.NewTupleKey("document:2", "writer", "user:github.com|charlie@test.com"),
+				tuple.NewTupleKey("document:1|special", "writer", "user:github.com|charlie@test.com"),
+			},
+		},
 		`filter_by_objectID_with_special_character`: {
 			filter: storage.ReadFilter{Relation: "", Object: "document:1|special", User: ""},
 			expectedTuples: []*openfgav1.TupleKey{
@@ -2369,6 +2540,40 @@ func ReadAndReadPageTest(t *testing.T, datastore storage.OpenFGADatastore) {
 	}
 }
 
+// ReadPageOrderTest tests that ReadPage returns the tuples of all types in the order in which
+// they were written, and that the tuples deleted between pages don't shift the next pages.
+func ReadPageOrderTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	var tuples []*openfgav1.TupleKey
+	for i := 0; i < 6; i++ {
+		tk := tuple.NewTupleKey("document:"+strconv.Itoa(i), "viewer", "user:anne")
+		if i%2 == 1 {
+			tk = tuple.NewTupleKey("folder:"+strconv.Itoa(i), "viewer", "user:anne")
+		}
+		tuples = append(tuples, tk)
+		// separate writes guarantee the order of the tuples
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk}))
+	}
+
+	require.Equal(t, tuples, testutils.ConvertTuplesToTupleKeys(readWithPageSize(t, datastore, storeID, 2, storage.ReadFilter{})))
+
+	opts := storage.ReadPageOptions{Pagination: storage.NewPaginationOptions(2, "")}
+	page, continuationToken, err := datastore.ReadPage(ctx, storeID, storage.ReadFilter{}, opts)
+	require.NoError(t, err)
+	require.Equal(t, tuples[:2], testutils.ConvertTuplesToTupleKeys(page))
+
+	require.NoError(t, datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+		tuple.TupleKeyToTupleKeyWithoutCondition(tuples[0]),
+	}, nil))
+
+	opts = storage.ReadPageOptions{Pagination: storage.NewPaginationOptions(2, continuationToken)}
+	page, _, err = datastore.ReadPage(ctx, storeID, storage.ReadFilter{}, opts)
+	require.NoError(t, err)
+	require.Equal(t, tuples[2:4], testutils.ConvertTuplesToTupleKeys(page))
+}
+
 // getObjects returns all the objects from an iterator.
 // If the iterator throws an error, it fails the test.
 func getObjects(t *testing.T, tupleIterator storage.TupleIterator) []string {
@@ -2404,6 +2609,96 @@ func iterateThroughAllTuples(t *testing.T, tupleIterator storage.TupleIterator)
 
 // readChanges calls ReadChanges. It reads everything from the store, pageSize changes at a time.
 // Along the way, it makes assertions on the changes seen. It returns all changes seen.
+func PruneChangesTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	pruner, ok := datastore.(storage.ChangelogPruner)
+	if !ok {
+		t.Skip("datastore does not implement storage.ChangelogPruner")
+	}
+
+	ctx := context.Background()
+
+	writeChanges := func(t *testing.T, storeID string, user string, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			tk := tuple.NewTupleKey(fmt.Sprintf("document:%s-%d", user, i), "viewer", "user:"+user)
+			err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk})
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("keep_latest", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, "jon", 10)
+
+		pruned, err := pruner.PruneChanges(ctx, storeID, storage.PruneChangesOptions{KeepLatest: 4})
+		require.NoError(t, err)
+		require.Equal(t, int64(6), pruned)
+
+		changes := readChangesWithPageSize(t, datastore, storeID, 100, "")
+		require.Len(t, changes, 4)
+		require.Equal(t, "document:jon-6", changes[0].GetTupleKey().GetObject())
+	})
+
+	t.Run("keep_latest_more_than_written", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, "jon", 3)
+
+		pruned, err := pruner.PruneChanges(ctx, storeID, storage.PruneChangesOptions{KeepLatest: 4})
+		require.NoError(t, err)
+		require.Zero(t, pruned)
+	})
+
+	t.Run("older_than", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, "before", 3)
+
+		time.Sleep(2 * time.Millisecond)
+		olderThan := time.Now()
+		time.Sleep(2 * time.Millisecond)
+
+		writeChanges(t, storeID, "after", 2)
+
+		pruned, err := pruner.PruneChanges(ctx, storeID, storage.PruneChangesOptions{OlderThan: olderThan})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), pruned)
+
+		changes := readChangesWithPageSize(t, datastore, storeID, 100, "")
+		require.Len(t, changes, 2)
+		for _, change := range changes {
+			require.Equal(t, "user:after", change.GetTupleKey().GetUser())
+		}
+	})
+
+	t.Run("horizon_is_kept", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, "jon", 5)
+
+		_, horizon, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(2, ""),
+		})
+		require.NoError(t, err)
+
+		pruned, err := pruner.PruneChanges(ctx, storeID, storage.PruneChangesOptions{
+			KeepLatest: 1,
+			Horizon:    horizon,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), pruned)
+
+		changes := readChangesWithPageSize(t, datastore, storeID, 100, "")
+		require.Len(t, changes, 4)
+	})
+
+	t.Run("no_bounds", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, "jon", 2)
+
+		pruned, err := pruner.PruneChanges(ctx, storeID, storage.PruneChangesOptions{})
+		require.NoError(t, err)
+		require.Zero(t, pruned)
+	})
+}
+
 func readChangesWithPageSize(t *testing.T, ds storage.OpenFGADatastore, storeID string, pageSize int, objectTypeFilter string) []*openfgav1.TupleChange {
 	t.Helper()
 	var (
@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// ErrInjectedFault is the error returned by the datastore calls failed by a Fault without an Err.
+var ErrInjectedFault = errors.New("simulation: injected fault")
+
+// The datastore methods that can be targeted by a Fault.
+const (
+	MethodRead                 = "Read"
+	MethodReadPage             = "ReadPage"
+	MethodReadUserTuple        = "ReadUserTuple"
+	MethodReadUsersetTuples    = "ReadUsersetTuples"
+	MethodReadStartingWithUser = "ReadStartingWithUser"
+)
+
+// Fault makes a share of the datastore calls fail.
+type Fault struct {
+	// Method is the datastore method whose calls can fail. If empty, the calls of every method can fail.
+	Method string
+
+	// Probability is the share, between 0 and 1, of the calls that fail. Whether a call fails only
+	// depends on the seed and on the call, so a given call always fails, or never does, for a seed.
+	Probability float64
+
+	// Err is the error returned by the failed calls. Defaults to ErrInjectedFault.
+	Err error
+}
+
+// Event is a datastore call made during a simulation.
+type Event struct {
+	Method  string
+	Filter  string
+	Latency time.Duration
+	Err     error
+}
+
+func (e Event) String() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s(%s) %s: %v", e.Method, e.Filter, e.Latency, e.Err)
+	}
+	return fmt.Sprintf("%s(%s) %s", e.Method, e.Filter, e.Latency)
+}
+
+// datastore is a storage.OpenFGADatastore that schedules, delays and fails the tuple reads of the
+// datastore it wraps, and records them.
+type datastore struct {
+	storage.OpenFGADatastore
+	sim *Simulation
+}
+
+var _ storage.OpenFGADatastore = (*datastore)(nil)
+
+func (d *datastore) Read(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadOptions) (storage.TupleIterator, error) {
+	if err := d.sim.intercept(ctx, MethodRead, readFilterString(filter)); err != nil {
+		return nil, err
+	}
+	return d.OpenFGADatastore.Read(ctx, store, filter, options)
+}
+
+func (d *datastore) ReadPage(ctx context.Context, store string, filter storage.ReadFilter, options storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+	if err := d.sim.intercept(ctx, MethodReadPage, readFilterString(filter)+" from="+options.Pagination.From); err != nil {
+		return nil, "", err
+	}
+	return d.OpenFGADatastore.ReadPage(ctx, store, filter, options)
+}
+
+func (d *datastore) ReadUserTuple(ctx context.Context, store string, filter storage.ReadUserTupleFilter, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	if err := d.sim.intercept(ctx, MethodReadUserTuple, readFilterString(filter)); err != nil {
+		return nil, err
+	}
+	return d.OpenFGADatastore.ReadUserTuple(ctx, store, filter, options)
+}
+
+func (d *datastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	restrictions := make([]string, 0, len(filter.AllowedUserTypeRestrictions))
+	for _, r := range filter.AllowedUserTypeRestrictions {
+		if r.GetWildcard() != nil {
+			restrictions = append(restrictions, tuple.TypedPublicWildcard(r.GetType()))
+			continue
+		}
+		restrictions = append(restrictions, tuple.ToObjectRelationString(r.GetType(), r.GetRelation()))
+	}
+	key := fmt.Sprintf("%s#%s@[%s]", filter.Object, filter.Relation, strings.Join(restrictions, ","))
+	if err := d.sim.intercept(ctx, MethodReadUsersetTuples, key); err != nil {
+		return nil, err
+	}
+	return d.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options)
+}
+
+func (d *datastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	users := make([]string, 0, len(filter.UserFilter))
+	for _, u := range filter.UserFilter {
+		users = append(users, tuple.GetObjectRelationAsString(u))
+	}
+	key := fmt.Sprintf("%s#%s@[%s]", filter.ObjectType, filter.Relation, strings.Join(users, ","))
+	if filter.ObjectIDs != nil {
+		key += fmt.Sprintf(" ids=%v", filter.ObjectIDs.Values())
+	}
+	if err := d.sim.intercept(ctx, MethodReadStartingWithUser, key); err != nil {
+		return nil, err
+	}
+	return d.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options)
+}
+
+func readFilterString(filter storage.ReadFilter) string {
+	return fmt.Sprintf("%s#%s@%s", filter.Object, filter.Relation, filter.User)
+}
+
+// fraction deterministically maps the seed and the parts to a number in [0, 1).
+func fraction(seed uint64, parts ...string) float64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, seed)
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return float64(h.Sum64()>>11) / float64(uint64(1)<<53)
+}
+
+// latency deterministically picks a latency in [minLatency, maxLatency] for a call.
+func latency(seed uint64, minLatency, maxLatency time.Duration, method, filter string) time.Duration {
+	if maxLatency <= minLatency {
+		return minLatency
+	}
+	spread := float64(maxLatency - minLatency)
+	return minLatency + time.Duration(math.Round(fraction(seed, "latency", method, filter)*spread))
+}
@@ -0,0 +1,152 @@
+package simulation
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scheduler serializes the datastore calls of a simulation. Whenever no call is running and no
+// new call arrived for the settle duration, it releases one of the pending calls, chosen by a seeded random generator among
+// the pending calls sorted by key. Since the choice only depends on the seed and on the set of
+// pending calls, and not on the order in which the goroutines reached the datastore, the same
+// seed yields the same interleaving of the datastore calls.
+type scheduler struct {
+	settle time.Duration
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	pending []*pendingCall
+	running bool
+	closed  bool
+
+	arrived chan struct{}
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+type pendingCall struct {
+	key     string
+	release chan struct{}
+}
+
+func newScheduler(seed uint64, settle time.Duration) *scheduler {
+	s := &scheduler{
+		settle:  settle,
+		rng:     rand.New(rand.NewPCG(seed, seed)),
+		arrived: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+// wait blocks until the scheduler releases the call identified by key. Once released, the call
+// runs alone until done is called.
+func (s *scheduler) wait(ctx context.Context, key string) error {
+	call := &pendingCall{key: key, release: make(chan struct{})}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.pending = append(s.pending, call)
+	s.mu.Unlock()
+	s.notify()
+
+	select {
+	case <-call.release:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if i := slices.Index(s.pending, call); i >= 0 {
+			s.pending = slices.Delete(s.pending, i, i+1)
+			return ctx.Err()
+		}
+		// released concurrently with the cancellation
+		return nil
+	}
+}
+
+// done marks the end of the running call.
+func (s *scheduler) done() {
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *scheduler) notify() {
+	select {
+	case s.arrived <- struct{}{}:
+	default:
+	}
+}
+
+func (s *scheduler) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.arrived:
+		case <-s.stop:
+			return
+		}
+
+		// wait for the goroutines unblocked by the previous release to reach the datastore
+		settled := false
+		for !settled {
+			timer := time.NewTimer(s.settle)
+			select {
+			case <-s.arrived:
+			case <-timer.C:
+				settled = true
+			case <-s.stop:
+				timer.Stop()
+				return
+			}
+			timer.Stop()
+		}
+
+		s.releaseOne()
+	}
+}
+
+// releaseOne releases one pending call, unless a call is running.
+func (s *scheduler) releaseOne() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running || len(s.pending) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(s.pending, func(a, b *pendingCall) int {
+		return strings.Compare(a.key, b.key)
+	})
+	i := s.rng.IntN(len(s.pending))
+	close(s.pending[i].release)
+	s.pending = slices.Delete(s.pending, i, i+1)
+	s.running = true
+}
+
+// close stops the scheduler and releases the calls that are still pending.
+func (s *scheduler) close() {
+	close(s.stop)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, call := range s.pending {
+		close(call.release)
+	}
+	s.pending = nil
+}
@@ -0,0 +1,243 @@
+// Package simulation runs the Check resolver against an in-memory store whose tuple reads are
+// delayed, failed and interleaved as dictated by a seed, so that a concurrency bug found with one
+// seed can be reproduced by running the simulation again with the same seed.
+//
+// A typical test explores many seeds, and logs the seed and the trace of the failing ones:
+//
+//	for seed := uint64(0); seed < 100; seed++ {
+//		sim, err := simulation.New(model, tuples, simulation.WithSeed(seed), simulation.WithScheduler(time.Millisecond))
+//		require.NoError(t, err)
+//		outcome := sim.Check(ctx, tuple.NewTupleKey("document:1", "viewer", "user:anne"))
+//		sim.Close()
+//		require.True(t, outcome.Response.GetAllowed(), "seed %d: %v", seed, outcome.Trace)
+//	}
+//
+// Without WithScheduler, the datastore calls run concurrently and only their latencies and faults
+// are deterministic.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// Option defines an option that can be used to change the behavior of a Simulation.
+type Option func(*Simulation)
+
+// WithSeed sets the seed that drives the latencies, the faults and the scheduling of the datastore
+// calls. Defaults to 0.
+func WithSeed(seed uint64) Option {
+	return func(s *Simulation) {
+		s.seed = seed
+	}
+}
+
+// WithLatency delays every datastore call by a latency between minLatency and maxLatency, picked
+// from the seed and the call.
+func WithLatency(minLatency, maxLatency time.Duration) Option {
+	return func(s *Simulation) {
+		s.minLatency = minLatency
+		s.maxLatency = maxLatency
+	}
+}
+
+// WithFault makes a share of the datastore calls fail.
+func WithFault(fault Fault) Option {
+	return func(s *Simulation) {
+		s.faults = append(s.faults, fault)
+	}
+}
+
+// WithScheduler serializes the datastore calls and executes them in an order picked from the seed.
+// A call is only picked once no new call was made for the settle duration, which must be long
+// enough for the goroutines of the resolver to reach the datastore.
+func WithScheduler(settle time.Duration) Option {
+	return func(s *Simulation) {
+		s.settle = settle
+	}
+}
+
+// WithCheckResolverOpts sets the options of the Check resolvers.
+func WithCheckResolverOpts(opts ...graph.CheckResolverOrderedBuilderOpt) Option {
+	return func(s *Simulation) {
+		s.checkResolverOpts = opts
+	}
+}
+
+// WithMaxConcurrentReads sets the maximum number of concurrent datastore calls of a Check.
+func WithMaxConcurrentReads(maxConcurrentReads uint32) Option {
+	return func(s *Simulation) {
+		s.maxConcurrentReads = maxConcurrentReads
+	}
+}
+
+// Simulation is a store and a Check resolver whose datastore calls are controlled by a seed.
+type Simulation struct {
+	seed               uint64
+	minLatency         time.Duration
+	maxLatency         time.Duration
+	faults             []Fault
+	settle             time.Duration
+	checkResolverOpts  []graph.CheckResolverOrderedBuilderOpt
+	maxConcurrentReads uint32
+
+	storeID   string
+	modelID   string
+	typesys   *typesystem.TypeSystem
+	ds        storage.OpenFGADatastore
+	scheduler *scheduler
+	resolver  graph.CheckResolver
+	closer    graph.CheckResolverCloser
+
+	mu    sync.Mutex
+	trace []Event
+}
+
+// Outcome is the result of a Check run by a Simulation.
+type Outcome struct {
+	Response *graph.ResolveCheckResponse
+	Err      error
+
+	// Trace lists the datastore calls made by the Check, in the order they were executed.
+	Trace []Event
+}
+
+// New returns a Simulation of a store with the model, written in the DSL, and the tuples.
+func New(model string, tuples []*openfgav1.TupleKey, opts ...Option) (*Simulation, error) {
+	s := &Simulation{
+		maxConcurrentReads: config.DefaultMaxConcurrentReadsForCheck,
+		storeID:            ulid.Make().String(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	authorizationModel, err := parser.TransformDSLToProto(model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+	authorizationModel.Id = ulid.Make().String()
+	s.modelID = authorizationModel.GetId()
+	s.typesys, err = typesystem.NewAndValidate(context.Background(), authorizationModel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	mem := memory.New()
+	for start := 0; start < len(tuples); start += storage.DefaultMaxTuplesPerWrite {
+		end := min(start+storage.DefaultMaxTuplesPerWrite, len(tuples))
+		if err := mem.Write(context.Background(), s.storeID, nil, tuples[start:end]); err != nil {
+			mem.Close()
+			return nil, err
+		}
+	}
+	s.ds = &datastore{OpenFGADatastore: mem, sim: s}
+
+	s.resolver, s.closer, err = graph.NewOrderedCheckResolvers(s.checkResolverOpts...).Build()
+	if err != nil {
+		mem.Close()
+		return nil, err
+	}
+
+	if s.settle > 0 {
+		s.scheduler = newScheduler(s.seed, s.settle)
+	}
+	return s, nil
+}
+
+// Check resolves a Check against the store of the Simulation.
+func (s *Simulation) Check(ctx context.Context, tk *openfgav1.TupleKey, contextualTuples ...*openfgav1.TupleKey) *Outcome {
+	s.mu.Lock()
+	s.trace = nil
+	s.mu.Unlock()
+
+	req, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:              s.storeID,
+		AuthorizationModelID: s.modelID,
+		TupleKey:             tk,
+		ContextualTuples:     contextualTuples,
+	})
+	if err != nil {
+		return &Outcome{Err: err}
+	}
+
+	reader := storagewrappers.NewRequestStorageWrapperWithCache(
+		s.ds,
+		contextualTuples,
+		&storagewrappers.Operation{Method: apimethod.Check, Concurrency: s.maxConcurrentReads},
+		storagewrappers.DataResourceConfiguration{},
+	)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, reader)
+	ctx = typesystem.ContextWithTypesystem(ctx, s.typesys)
+
+	resp, err := s.resolver.ResolveCheck(ctx, req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &Outcome{Response: resp, Err: err, Trace: slices.Clone(s.trace)}
+}
+
+// Close releases the resources of the Simulation.
+func (s *Simulation) Close() {
+	if s.scheduler != nil {
+		s.scheduler.close()
+	}
+	s.closer()
+	s.ds.Close()
+}
+
+// intercept is called by the datastore before each call. It waits for the scheduler to release the
+// call, delays it, and returns the error of the first fault that fails it.
+func (s *Simulation) intercept(ctx context.Context, method, filter string) error {
+	if s.scheduler != nil {
+		if err := s.scheduler.wait(ctx, method+" "+filter); err != nil {
+			return err
+		}
+		defer s.scheduler.done()
+	}
+
+	event := Event{Method: method, Filter: filter, Latency: latency(s.seed, s.minLatency, s.maxLatency, method, filter)}
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != method {
+			continue
+		}
+		if fraction(s.seed, "fault", fmt.Sprint(i), method, filter) < fault.Probability {
+			event.Err = fault.Err
+			if event.Err == nil {
+				event.Err = ErrInjectedFault
+			}
+			break
+		}
+	}
+
+	s.mu.Lock()
+	s.trace = append(s.trace, event)
+	s.mu.Unlock()
+
+	if event.Latency > 0 {
+		timer := time.NewTimer(event.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return event.Err
+}
@@ -0,0 +1,113 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const model = `
+	model
+		schema 1.1
+	type user
+	type group
+		relations
+			define member: [user, group#member]
+	type document
+		relations
+			define owner: [user]
+			define editor: [user, group#member] or owner
+			define viewer: [user, group#member] or editor`
+
+var tuples = []*openfgav1.TupleKey{
+	tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+	tuple.NewTupleKey("document:1", "editor", "group:fga#member"),
+	tuple.NewTupleKey("group:eng", "member", "group:backend#member"),
+	tuple.NewTupleKey("group:fga", "member", "user:anne"),
+	tuple.NewTupleKey("group:backend", "member", "user:bob"),
+}
+
+func check(t *testing.T, user string, opts ...Option) *Outcome {
+	t.Helper()
+	sim, err := New(model, tuples, opts...)
+	require.NoError(t, err)
+	t.Cleanup(sim.Close)
+	return sim.Check(context.Background(), tuple.NewTupleKey("document:1", "viewer", user))
+}
+
+func TestSimulation(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	t.Run("resolves_check", func(t *testing.T) {
+		outcome := check(t, "user:anne")
+		require.NoError(t, outcome.Err)
+		require.True(t, outcome.Response.GetAllowed())
+		require.NotEmpty(t, outcome.Trace)
+
+		outcome = check(t, "user:charlie")
+		require.NoError(t, outcome.Err)
+		require.False(t, outcome.Response.GetAllowed())
+	})
+
+	t.Run("same_seed_same_schedule", func(t *testing.T) {
+		for seed := uint64(0); seed < 3; seed++ {
+			first := check(t, "user:charlie", WithSeed(seed), WithScheduler(2*time.Millisecond))
+			second := check(t, "user:charlie", WithSeed(seed), WithScheduler(2*time.Millisecond))
+			require.NoError(t, first.Err)
+			require.Equal(t, first.Trace, second.Trace, "seed %d", seed)
+		}
+	})
+
+	t.Run("seeds_explore_schedules", func(t *testing.T) {
+		schedules := map[string]struct{}{}
+		for seed := uint64(0); seed < 10; seed++ {
+			outcome := check(t, "user:charlie", WithSeed(seed), WithScheduler(2*time.Millisecond))
+			require.NoError(t, outcome.Err)
+			require.False(t, outcome.Response.GetAllowed())
+
+			var schedule string
+			for _, event := range outcome.Trace {
+				schedule += event.String() + "\n"
+			}
+			schedules[schedule] = struct{}{}
+		}
+		require.Greater(t, len(schedules), 1)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		outcome := check(t, "user:anne", WithSeed(1), WithLatency(time.Millisecond, 5*time.Millisecond))
+		require.NoError(t, outcome.Err)
+		for _, event := range outcome.Trace {
+			require.GreaterOrEqual(t, event.Latency, time.Millisecond)
+			require.LessOrEqual(t, event.Latency, 5*time.Millisecond)
+		}
+		require.Equal(t, latency(1, time.Millisecond, 5*time.Millisecond, outcome.Trace[0].Method, outcome.Trace[0].Filter), outcome.Trace[0].Latency)
+	})
+
+	t.Run("faults", func(t *testing.T) {
+		outcome := check(t, "user:charlie", WithFault(Fault{Probability: 1}))
+		require.ErrorIs(t, outcome.Err, ErrInjectedFault)
+
+		errUnavailable := errors.New("unavailable")
+		outcome = check(t, "user:charlie", WithFault(Fault{Method: MethodReadUsersetTuples, Probability: 1, Err: errUnavailable}))
+		require.ErrorIs(t, outcome.Err, errUnavailable)
+		for _, event := range outcome.Trace {
+			if event.Method != MethodReadUsersetTuples {
+				require.NoError(t, event.Err)
+			}
+		}
+
+		outcome = check(t, "user:charlie", WithFault(Fault{Probability: 0}))
+		require.NoError(t, outcome.Err)
+	})
+}
@@ -0,0 +1,326 @@
+// Package tuplegen generates synthetic tuples for an authorization model, with realistic
+// distributions that can be tuned for benchmarks and demos: the number of objects of each type,
+// the fan-out of the relations, the popularity of the objects and the nesting depth of the
+// usersets of the same type, e.g. of the groups in groups.
+//
+// The generation is deterministic for a given model, seed and options:
+//
+//	g, err := tuplegen.New(model, tuplegen.WithSeed(42), tuplegen.WithObjectsPerType(1000))
+//	if err != nil {
+//		return err
+//	}
+//	err = g.Generate(func(tk *openfgav1.TupleKey) error {
+//		tuples = append(tuples, tk)
+//		return nil
+//	})
+//
+// The objects of a type are named after their popularity rank, from "<type>:0", the most popular.
+package tuplegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// The defaults of the options of a Generator.
+const (
+	DefaultObjectsPerType = 100
+	DefaultUsersPerType   = 1000
+	DefaultFanOut         = 3
+	DefaultZipfExponent   = 1.2
+	DefaultNestingDepth   = 3
+	DefaultWildcardRatio  = 0.01
+)
+
+// errMaxTuples stops the generation when the maximum number of tuples is reached.
+var errMaxTuples = errors.New("maximum number of tuples reached")
+
+// Option defines an option that can be used to change the distributions of a Generator.
+type Option func(*Generator)
+
+// WithSeed sets the seed of the random generator. Defaults to 0.
+func WithSeed(seed uint64) Option {
+	return func(g *Generator) {
+		g.seed = seed
+	}
+}
+
+// WithObjectsPerType sets the number of objects of each type that has relations. Defaults to
+// DefaultObjectsPerType.
+func WithObjectsPerType(n int) Option {
+	return func(g *Generator) {
+		g.objectsPerType = n
+	}
+}
+
+// WithUsersPerType sets the number of objects of each type without relations, e.g. user. Defaults
+// to DefaultUsersPerType.
+func WithUsersPerType(n int) Option {
+	return func(g *Generator) {
+		g.usersPerType = n
+	}
+}
+
+// WithFanOut sets the average number of tuples of each object and directly assignable relation.
+// The number of tuples of an object and relation is uniformly distributed between 1 and twice the
+// fan-out minus 1. Defaults to DefaultFanOut.
+func WithFanOut(n int) Option {
+	return func(g *Generator) {
+		g.fanOut = n
+	}
+}
+
+// WithZipfExponent sets the exponent of the Zipfian distribution of the popularity of the objects
+// that the tuples grant a relation to, or that they point to through usersets and tuplesets. The
+// higher the exponent, the more the tuples concentrate on the most popular objects. An exponent
+// of 1 or less distributes them uniformly. Defaults to DefaultZipfExponent.
+func WithZipfExponent(s float64) Option {
+	return func(g *Generator) {
+		g.zipfExponent = s
+	}
+}
+
+// WithNestingDepth sets the maximum depth of the chains of tuples between objects of the same
+// type, e.g. group:1#member being a member of group:2, itself a member of group:3. These chains
+// never form cycles. A depth of 0 generates no such tuple. Defaults to DefaultNestingDepth.
+func WithNestingDepth(depth int) Option {
+	return func(g *Generator) {
+		g.nestingDepth = depth
+	}
+}
+
+// WithWildcardRatio sets the ratio of the objects of the relations assignable to a public
+// wildcard that are granted to it. Defaults to DefaultWildcardRatio.
+func WithWildcardRatio(ratio float64) Option {
+	return func(g *Generator) {
+		g.wildcardRatio = ratio
+	}
+}
+
+// WithMaxTuples stops the generation after n tuples. Defaults to 0, which doesn't bound it.
+func WithMaxTuples(n int) Option {
+	return func(g *Generator) {
+		g.maxTuples = n
+	}
+}
+
+// Generator generates the tuples of an authorization model.
+type Generator struct {
+	typesys        *typesystem.TypeSystem
+	seed           uint64
+	objectsPerType int
+	usersPerType   int
+	fanOut         int
+	zipfExponent   float64
+	nestingDepth   int
+	wildcardRatio  float64
+	maxTuples      int
+
+	rng   *rand.Rand
+	zipfs map[uint64]*rand.Zipf
+}
+
+// New returns a Generator of the tuples of model, which is validated.
+func New(model *openfgav1.AuthorizationModel, opts ...Option) (*Generator, error) {
+	g := &Generator{
+		objectsPerType: DefaultObjectsPerType,
+		usersPerType:   DefaultUsersPerType,
+		fanOut:         DefaultFanOut,
+		zipfExponent:   DefaultZipfExponent,
+		nestingDepth:   DefaultNestingDepth,
+		wildcardRatio:  DefaultWildcardRatio,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	switch {
+	case g.objectsPerType < 1:
+		return nil, fmt.Errorf("the number of objects per type must be greater than 0")
+	case g.usersPerType < 1:
+		return nil, fmt.Errorf("the number of users per type must be greater than 0")
+	case g.fanOut < 1:
+		return nil, fmt.Errorf("the fan-out must be greater than 0")
+	case g.nestingDepth < 0:
+		return nil, fmt.Errorf("the nesting depth must not be negative")
+	case g.wildcardRatio < 0 || g.wildcardRatio > 1:
+		return nil, fmt.Errorf("the wildcard ratio must be between 0 and 1")
+	case g.maxTuples < 0:
+		return nil, fmt.Errorf("the maximum number of tuples must not be negative")
+	}
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+	g.typesys = typesys
+	return g, nil
+}
+
+// Generate calls emit with each generated tuple, by type, relation and object, until it returns
+// an error, which Generate returns. Every call of Generate generates the same tuples.
+func (g *Generator) Generate(emit func(*openfgav1.TupleKey) error) error {
+	g.rng = rand.New(rand.NewPCG(g.seed, g.seed))
+	g.zipfs = map[uint64]*rand.Zipf{}
+
+	count := 0
+	emitBounded := func(tk *openfgav1.TupleKey) error {
+		if err := emit(tk); err != nil {
+			return err
+		}
+		count++
+		if g.maxTuples > 0 && count >= g.maxTuples {
+			return errMaxTuples
+		}
+		return nil
+	}
+
+	allRelations := g.typesys.GetAllRelations()
+	types := make([]string, 0, len(allRelations))
+	for objectType := range allRelations {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+
+	for _, objectType := range types {
+		relations := make([]string, 0, len(allRelations[objectType]))
+		for relation := range allRelations[objectType] {
+			relations = append(relations, relation)
+		}
+		sort.Strings(relations)
+
+		for _, relation := range relations {
+			refs, err := g.typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+			if err != nil {
+				return err
+			}
+			if err := g.generateRelation(objectType, relation, refs, emitBounded); err != nil {
+				if errors.Is(err, errMaxTuples) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) generateRelation(objectType, relation string, refs []*openfgav1.RelationReference, emit func(*openfgav1.TupleKey) error) error {
+	var wildcards, others []*openfgav1.RelationReference
+	for _, ref := range refs {
+		if ref.GetWildcard() != nil {
+			wildcards = append(wildcards, ref)
+		} else {
+			others = append(others, ref)
+		}
+	}
+	if len(wildcards) == 0 && len(others) == 0 {
+		return nil
+	}
+
+	for id := 0; id < g.population(objectType); id++ {
+		object := tuple.BuildObject(objectType, strconv.Itoa(id))
+
+		for _, ref := range wildcards {
+			if g.rng.Float64() < g.wildcardRatio {
+				if err := emit(newTupleKey(object, relation, tuple.TypedPublicWildcard(ref.GetType()), ref)); err != nil {
+					return err
+				}
+			}
+		}
+		if len(others) == 0 {
+			continue
+		}
+
+		seen := map[string]struct{}{}
+		n := 1 + g.rng.IntN(2*g.fanOut-1)
+		for i := 0; i < n; i++ {
+			ref := others[g.rng.IntN(len(others))]
+			user, ok := g.pickUser(objectType, id, ref)
+			if !ok {
+				continue
+			}
+			if _, ok := seen[user]; ok {
+				continue
+			}
+			seen[user] = struct{}{}
+			if err := emit(newTupleKey(object, relation, user, ref)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pickUser picks the user of a tuple of the object of type objectType and the id for the
+// reference, by popularity. The objects of the same type as the object are organized in levels,
+// by the remainder of their id divided by the nesting depth plus 1, and only point to the objects
+// of the next level, so that their chains are not deeper than the nesting depth.
+func (g *Generator) pickUser(objectType string, id int, ref *openfgav1.RelationReference) (string, bool) {
+	userType := ref.GetType()
+	population := g.population(userType)
+
+	var userID int
+	if userType == objectType {
+		levels := g.nestingDepth + 1
+		level := id % levels
+		if level+1 >= levels {
+			return "", false
+		}
+		// the number of objects of the next level
+		n := (population - (level + 1) + levels - 1) / levels
+		if n <= 0 {
+			return "", false
+		}
+		userID = g.popular(n)*levels + level + 1
+	} else {
+		userID = g.popular(population)
+	}
+
+	user := tuple.BuildObject(userType, strconv.Itoa(userID))
+	if ref.GetRelation() != "" {
+		user = tuple.ToObjectRelationString(user, ref.GetRelation())
+	}
+	return user, true
+}
+
+// popular returns a rank between 0 and n-1, following the Zipfian distribution of the popularity.
+func (g *Generator) popular(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	if g.zipfExponent <= 1 {
+		return g.rng.IntN(n)
+	}
+	z, ok := g.zipfs[uint64(n)]
+	if !ok {
+		z = rand.NewZipf(g.rng, g.zipfExponent, 1, uint64(n-1))
+		g.zipfs[uint64(n)] = z
+	}
+	return int(z.Uint64())
+}
+
+// population returns the number of objects of a type.
+func (g *Generator) population(objectType string) int {
+	relations, err := g.typesys.GetRelations(objectType)
+	if err != nil || len(relations) == 0 {
+		return g.usersPerType
+	}
+	return g.objectsPerType
+}
+
+func newTupleKey(object, relation, user string, ref *openfgav1.RelationReference) *openfgav1.TupleKey {
+	if ref.GetCondition() != "" {
+		return tuple.NewTupleKeyWithCondition(object, relation, user, ref.GetCondition(), nil)
+	}
+	return tuple.NewTupleKey(object, relation, user)
+}
@@ -0,0 +1,156 @@
+package tuplegen
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var model = testutils.MustTransformDSLToProtoWithID(`
+	model
+		schema 1.1
+	type user
+	type group
+		relations
+			define member: [user, group#member]
+	type folder
+		relations
+			define parent: [folder]
+			define viewer: [user, user:*, group#member] or viewer from parent
+	type document
+		relations
+			define parent: [folder]
+			define editor: [user with in_network]
+			define viewer: [user] or editor or viewer from parent
+	condition in_network(ip: string) {
+		ip == "10.0.0.1"
+	}`)
+
+func generate(t *testing.T, opts ...Option) []*openfgav1.TupleKey {
+	t.Helper()
+
+	g, err := New(model, opts...)
+	require.NoError(t, err)
+
+	var tuples []*openfgav1.TupleKey
+	require.NoError(t, g.Generate(func(tk *openfgav1.TupleKey) error {
+		tuples = append(tuples, tk)
+		return nil
+	}))
+	return tuples
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("tuples_are_valid_and_unique", func(t *testing.T) {
+		typesys, err := typesystem.New(model)
+		require.NoError(t, err)
+
+		tuples := generate(t, WithWildcardRatio(0.5))
+		require.NotEmpty(t, tuples)
+
+		seen := map[string]struct{}{}
+		var wildcards, conditions int
+		for _, tk := range tuples {
+			require.NoError(t, validation.ValidateTupleForWrite(typesys, tk), tuple.TupleKeyToString(tk))
+			_, duplicate := seen[tuple.TupleKeyToString(tk)]
+			require.False(t, duplicate, tuple.TupleKeyToString(tk))
+			seen[tuple.TupleKeyToString(tk)] = struct{}{}
+
+			if tuple.IsTypedWildcard(tk.GetUser()) {
+				wildcards++
+			}
+			if tk.GetCondition().GetName() == "in_network" {
+				conditions++
+			}
+		}
+		require.Positive(t, wildcards)
+		require.Positive(t, conditions)
+	})
+
+	t.Run("deterministic_for_a_seed", func(t *testing.T) {
+		require.Equal(t, generate(t, WithSeed(7)), generate(t, WithSeed(7)))
+		require.NotEqual(t, generate(t, WithSeed(7)), generate(t, WithSeed(8)))
+	})
+
+	t.Run("fan_out", func(t *testing.T) {
+		tuples := generate(t, WithObjectsPerType(10), WithFanOut(1), WithWildcardRatio(0))
+		perObjectRelation := map[string]int{}
+		for _, tk := range tuples {
+			perObjectRelation[tuple.ToObjectRelationString(tk.GetObject(), tk.GetRelation())]++
+		}
+		for key, n := range perObjectRelation {
+			require.Equal(t, 1, n, key)
+		}
+	})
+
+	t.Run("nesting_depth", func(t *testing.T) {
+		for _, depth := range []int{0, 1, 3} {
+			tuples := generate(t, WithObjectsPerType(50), WithFanOut(4), WithNestingDepth(depth))
+
+			// the depth of the chain of groups of which each group is a member
+			parents := map[string][]string{}
+			for _, tk := range tuples {
+				if tk.GetObject() != "" && tuple.GetType(tk.GetObject()) == "group" && tuple.IsObjectRelation(tk.GetUser()) {
+					member, _ := tuple.SplitObjectRelation(tk.GetUser())
+					parents[member] = append(parents[member], tk.GetObject())
+				}
+			}
+			var chainDepth func(group string) int
+			chainDepth = func(group string) int {
+				deepest := 0
+				for _, parent := range parents[group] {
+					deepest = max(deepest, 1+chainDepth(parent))
+				}
+				return deepest
+			}
+			deepest := 0
+			for id := 0; id < 50; id++ {
+				deepest = max(deepest, chainDepth("group:"+strconv.Itoa(id)))
+			}
+			require.Equal(t, depth, deepest, "depth %d", depth)
+		}
+	})
+
+	t.Run("zipfian_popularity", func(t *testing.T) {
+		tuples := generate(t, WithUsersPerType(1000), WithZipfExponent(1.5))
+		popularity := map[string]int{}
+		for _, tk := range tuples {
+			if tuple.GetType(tk.GetUser()) == "user" {
+				popularity[tk.GetUser()]++
+			}
+		}
+		require.Greater(t, popularity["user:0"], popularity["user:1"])
+		require.Greater(t, popularity["user:0"], 10*popularity["user:100"]+1)
+	})
+
+	t.Run("max_tuples", func(t *testing.T) {
+		require.Len(t, generate(t, WithMaxTuples(5)), 5)
+	})
+
+	t.Run("emit_errors_stop_the_generation", func(t *testing.T) {
+		g, err := New(model)
+		require.NoError(t, err)
+		errStop := errors.New("stop")
+		require.ErrorIs(t, g.Generate(func(*openfgav1.TupleKey) error { return errStop }), errStop)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(model, WithFanOut(0))
+	require.Error(t, err)
+
+	_, err = New(model, WithWildcardRatio(2))
+	require.Error(t, err)
+
+	_, err = New(&openfgav1.AuthorizationModel{SchemaVersion: typesystem.SchemaVersion1_0})
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+//go:build !wasm
+
+package typesystem
+
+import (
+	"encoding/json"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// CompilationCacheEntityType is the entity type of the entries of the compilation cache.
+const CompilationCacheEntityType = "typesystem_compilation"
+
+var _ storage.PersistentCacheItem = (*CompilationCacheEntry)(nil)
+
+// CompilationCacheEntry holds the artifacts of the compilation of a valid model, so that the
+// instances of OpenFGA that share a cache don't validate the model and compile its conditions
+// again, e.g. on a cold start.
+type CompilationCacheEntry struct {
+	// CheckedConditions are the parsed and type-checked expressions of the conditions of the model,
+	// by name.
+	CheckedConditions map[string][]byte `json:"checked_conditions"`
+}
+
+func (c *CompilationCacheEntry) CacheEntityType() string {
+	return CompilationCacheEntityType
+}
+
+// MarshalBinary encodes the entry for the caches that persist it.
+func (c *CompilationCacheEntry) MarshalBinary() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// DecodeCompilationCacheEntry decodes an entry encoded by CompilationCacheEntry.MarshalBinary.
+// It is the storage.CacheItemDecoder of the CompilationCacheEntityType.
+func DecodeCompilationCacheEntry(data []byte) (any, error) {
+	var entry CompilationCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// compilationCacheKey returns the key of the compilation of a model. It includes the version of
+// OpenFGA, since the checked expressions depend on the version of CEL and of its environment.
+func compilationCacheKey(storeID, modelID string) string {
+	return fmt.Sprintf("%s:%s:%s/%s", CompilationCacheEntityType, build.Version, storeID, modelID)
+}
+
+// compilationCacheEntry returns the compilation artifacts of t, which must be validated.
+func (t *TypeSystem) compilationCacheEntry() (*CompilationCacheEntry, error) {
+	entry := &CompilationCacheEntry{CheckedConditions: make(map[string][]byte, len(t.conditions))}
+	for name, c := range t.conditions {
+		checked, err := c.CheckedExpression()
+		if err != nil {
+			return nil, err
+		}
+		entry.CheckedConditions[name] = checked
+	}
+	return entry, nil
+}
+
+// NewFromCompilationCacheEntry creates a *TypeSystem from a model that was validated by
+// NewAndValidate, and from the artifacts of its compilation. It compiles the conditions of the
+// model from their checked expressions instead of their source, and doesn't validate the model
+// again.
+func NewFromCompilationCacheEntry(model *openfgav1.AuthorizationModel, entry *CompilationCacheEntry) (*TypeSystem, error) {
+	t, err := New(model)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, c := range t.conditions {
+		if checked, ok := entry.CheckedConditions[name]; ok {
+			c.WithCheckedExpression(checked)
+		}
+	}
+	if err := t.validateConditions(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
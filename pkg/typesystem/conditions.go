@@ -0,0 +1,30 @@
+package typesystem
+
+import (
+	"github.com/google/cel-go/cel"
+
+	"github.com/openfga/openfga/internal/condition"
+)
+
+// RegisterConditionFunction makes the CEL function name with the overloads available to the
+// conditions of the models, e.g.
+//
+//	typesystem.RegisterConditionFunction("in_region",
+//		cel.Overload("in_region_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+//			cel.BinaryBinding(inRegion),
+//		),
+//	)
+//
+// The models are validated against the registered functions when they are written, so it must be
+// called before the server starts, and the functions must stay registered for as long as the
+// models that use them are served.
+func RegisterConditionFunction(name string, overloads ...cel.FunctionOpt) error {
+	return condition.RegisterEnvOptions(cel.Function(name, overloads...))
+}
+
+// RegisterConditionEnvOptions extends the CEL environment of the conditions of the models with
+// opts, e.g. with a library of functions or with custom types. It has the same requirements as
+// RegisterConditionFunction.
+func RegisterConditionEnvOptions(opts ...cel.EnvOption) error {
+	return condition.RegisterEnvOptions(opts...)
+}
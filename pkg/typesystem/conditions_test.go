@@ -0,0 +1,52 @@
+package typesystem
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestRegisterConditionFunction(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user with in_region]
+		condition in_region(region: string) {
+			test_in_region(region, "eu")
+		}`)
+
+	_, err := NewAndValidate(context.Background(), model)
+	require.ErrorContains(t, err, "undeclared reference to 'test_in_region'")
+
+	err = RegisterConditionFunction("test_in_region",
+		cel.Overload("test_in_region_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(region, prefix ref.Val) ref.Val {
+				return types.Bool(strings.HasPrefix(string(region.(types.String)), string(prefix.(types.String))))
+			}),
+		),
+	)
+	require.NoError(t, err)
+
+	typesys, err := NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	cond, ok := typesys.GetCondition("in_region")
+	require.True(t, ok)
+
+	result, err := cond.Evaluate(context.Background(), map[string]*structpb.Value{
+		"region": structpb.NewStringValue("eu-west-1"),
+	})
+	require.NoError(t, err)
+	require.True(t, result.ConditionMet)
+}
@@ -1,3 +1,5 @@
+//go:build !wasm
+
 package typesystem
 
 import (
@@ -18,12 +20,62 @@ import (
 
 // TODO there is a duplicate cache of models elsewhere: https://github.com/openfga/openfga/issues/1045
 
+var _ storage.CacheItem = (*TypeSystem)(nil)
+
 const (
 	typesystemCacheTTL = 168 * time.Hour // 7 days.
 )
 
 type TypesystemResolverFunc func(ctx context.Context, storeID, modelID string) (*TypeSystem, error)
 
+type memoizedTypesystemResolverOptions struct {
+	compilationCache storage.InMemoryCache[any]
+}
+
+// MemoizedTypesystemResolverOpt defines an option of MemoizedTypesystemResolverFunc.
+type MemoizedTypesystemResolverOpt func(*memoizedTypesystemResolverOptions)
+
+// WithCompilationCache stores the artifacts of the compilation of the models in cache, e.g. a
+// cache shared by the instances of OpenFGA, and resolves the models found in it without validating
+// them and compiling their conditions again. The cache is not stopped with the resolver.
+func WithCompilationCache(cache storage.InMemoryCache[any]) MemoizedTypesystemResolverOpt {
+	return func(o *memoizedTypesystemResolverOptions) {
+		o.compilationCache = cache
+	}
+}
+
+// compiled returns the *TypeSystem of model from the artifacts of its compilation in the
+// compilation cache, or nil if they are not cached.
+func (o *memoizedTypesystemResolverOptions) compiled(storeID string, model *openfgav1.AuthorizationModel) *TypeSystem {
+	if o.compilationCache == nil {
+		return nil
+	}
+
+	entry, ok := o.compilationCache.Get(compilationCacheKey(storeID, model.GetId())).(*CompilationCacheEntry)
+	if !ok {
+		return nil
+	}
+	typesys, err := NewFromCompilationCacheEntry(model, entry)
+	if err != nil {
+		// e.g. the cache holds an entry of an incompatible CEL environment: compile the model again
+		return nil
+	}
+	return typesys
+}
+
+// storeCompiled stores the artifacts of the compilation of typesys in the compilation cache.
+func (o *memoizedTypesystemResolverOptions) storeCompiled(storeID string, typesys *TypeSystem) {
+	if o.compilationCache == nil {
+		return
+	}
+
+	entry, err := typesys.compilationCacheEntry()
+	if err != nil {
+		return
+	}
+	o.compilationCache.Set(compilationCacheKey(storeID, typesys.GetAuthorizationModelID()), entry, typesystemCacheTTL)
+}
+
 // MemoizedTypesystemResolverFunc does several things.
 //
 // If given a model ID: validates the model ID, and tries to fetch it from the cache.
@@ -31,7 +83,12 @@ type TypesystemResolverFunc func(ctx context.Context, storeID, modelID string) (
 //
 // If not given a model ID: fetches the latest model ID from the datastore, then sees if the model ID is in the cache.
 // If it is, returns it. Else, validates it and returns it.
-func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBackend, maxSize int) (TypesystemResolverFunc, func(), error) {
+func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBackend, maxSize int, opts ...MemoizedTypesystemResolverOpt) (TypesystemResolverFunc, func(), error) {
+	o := &memoizedTypesystemResolverOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	lookupGroup := singleflight.Group{}
 
 	// cache holds models that have already been validated.
@@ -98,9 +155,13 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 			model = v.(*openfgav1.AuthorizationModel)
 		}
 
-		typesys, err := NewAndValidate(ctx, model)
-		if err != nil {
-			return nil, fmt.Errorf("%w: %w", ErrInvalidModel, err)
+		typesys := o.compiled(storeID, model)
+		if typesys == nil {
+			typesys, err = NewAndValidate(ctx, model)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidModel, err)
+			}
+			o.storeCompiled(storeID, typesys)
 		}
 
 		cache.Set(key, typesys, typesystemCacheTTL)
@@ -1,3 +1,5 @@
+//go:build !wasm
+
 package typesystem
 
 import (
@@ -8,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
@@ -260,4 +264,62 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, modelTwo.GetId(), typesys.GetAuthorizationModelID())
 	})
+	t.Run("compilation_cache_is_shared_by_resolvers", func(t *testing.T) {
+		store := ulid.Make().String()
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+			type document
+				relations
+					define viewer: [user with in_region]
+
+			condition in_region(region: string) {
+				region == "eu"
+			}`)
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		compilationCache, err := storage.NewInMemoryLRUCache[any]()
+		require.NoError(t, err)
+		defer compilationCache.Stop()
+
+		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, model.GetId()).
+			Return(model, nil).
+			Times(1)
+
+		resolver, resolverStop, err := MemoizedTypesystemResolverFunc(mockDatastore, testCacheSize, WithCompilationCache(compilationCache))
+		require.NoError(t, err)
+		defer resolverStop()
+
+		_, err = resolver(context.Background(), store, model.GetId())
+		require.NoError(t, err)
+		entry, ok := compilationCache.Get(compilationCacheKey(store, model.GetId())).(*CompilationCacheEntry)
+		require.True(t, ok)
+		require.Contains(t, entry.CheckedConditions, "in_region")
+
+		// another resolver, e.g. of another instance, compiles the condition from the cache: an
+		// invalid source shows that it doesn't validate the model again
+		invalidModel := proto.Clone(model).(*openfgav1.AuthorizationModel)
+		invalidModel.GetConditions()["in_region"].Expression = "invalid"
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, model.GetId()).
+			Return(invalidModel, nil).
+			Times(1)
+
+		otherResolver, otherResolverStop, err := MemoizedTypesystemResolverFunc(mockDatastore, testCacheSize, WithCompilationCache(compilationCache))
+		require.NoError(t, err)
+		defer otherResolverStop()
+
+		typesys, err := otherResolver(context.Background(), store, model.GetId())
+		require.NoError(t, err)
+		cond, ok := typesys.GetCondition("in_region")
+		require.True(t, ok)
+		contextStruct, err := structpb.NewStruct(map[string]interface{}{"region": "eu"})
+		require.NoError(t, err)
+		result, err := cond.Evaluate(context.Background(), contextStruct.GetFields())
+		require.NoError(t, err)
+		require.True(t, result.ConditionMet)
+	})
 }
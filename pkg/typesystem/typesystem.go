@@ -20,8 +20,6 @@ import (
 	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/utils"
 	"github.com/openfga/openfga/pkg/server/config"
-	serverErrors "github.com/openfga/openfga/pkg/server/errors"
-	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/tuple"
 )
 
@@ -163,8 +161,6 @@ func ConditionedRelationReference(rel *openfgav1.RelationReference, condition st
 	return rel
 }
 
-var _ storage.CacheItem = (*TypeSystem)(nil)
-
 // TypeSystem is a wrapper over an [openfgav1.AuthorizationModel].
 type TypeSystem struct {
 	// [objectType] => typeDefinition.
@@ -1107,7 +1103,7 @@ func hasEntrypoints(
 		rwString = rewrite.String()
 	}
 
-	return false, false, serverErrors.HandleError("error validating model", fmt.Errorf("hasEntrypoints unknown rewrite %s for '%s#%s'", rwString, typeName, relationName))
+	return false, false, fmt.Errorf("error validating model: hasEntrypoints unknown rewrite %s for '%s#%s'", rwString, typeName, relationName)
 }
 
 // NewAndValidate is like New but also validates the model according to the following rules:
@@ -22,6 +22,7 @@ import (
 
 	checktest "github.com/openfga/openfga/internal/test/check"
 	"github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/testutils"
 	"github.com/openfga/openfga/pkg/tuple"
@@ -710,7 +711,10 @@ func GRPCReadTest(t *testing.T, client openfgav1.OpenFGAServiceClient) {
 			response, err := client.Read(context.Background(), test.input)
 			if test.err != nil {
 				require.Error(t, err)
-				assert.Equal(t, test.err, err)
+				st := status.Convert(err)
+				assert.Equal(t, status.Convert(test.err).Code(), st.Code())
+				assert.Equal(t, status.Convert(test.err).Message(), st.Message())
+				assert.NotEmpty(t, serverErrors.ErrorReason(st))
 			} else {
 				require.NoError(t, err)
 				test.validate(t, response)
@@ -875,7 +879,10 @@ func GRPCReadChangesTest(t *testing.T, client openfgav1.OpenFGAServiceClient) {
 			response, err := client.ReadChanges(context.Background(), test.input)
 			if test.err != nil {
 				require.Error(t, err)
-				assert.Equal(t, test.err, err)
+				st := status.Convert(err)
+				assert.Equal(t, status.Convert(test.err).Code(), st.Code())
+				assert.Equal(t, status.Convert(test.err).Message(), st.Message())
+				assert.NotEmpty(t, serverErrors.ErrorReason(st))
 			} else {
 				require.NoError(t, err)
 				test.validate(t, response)